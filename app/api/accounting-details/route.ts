@@ -12,7 +12,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { getAccountingDetails } from '@/app/api/lib/helpers/accountingDetails';
-import type { BillValidatorTimePeriod } from '@/shared/types/billValidator';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { accountingDetailsRoute } from '@/app/api/lib/routeSchemas/machines';
 import {
   logRouteFetch,
   logRouteError,
@@ -34,10 +38,9 @@ import { NextRequest, NextResponse } from 'next/server';
  *                                             by BillValidatorTimePeriod.
  *
  * Flow:
- * 1. Parse query parameters (machineId, timePeriod)
- * 2. Validate machine ID
- * 3. Fetch accounting details including accepted bills
- * 4. Return accounting details
+ * 1. Parse and validate query parameters (machineId, timePeriod)
+ * 2. Fetch accounting details including accepted bills
+ * 3. Return accounting details
  */
 export async function GET(req: NextRequest) {
   const functionName = 'GET /api/accounting-details';
@@ -49,36 +52,27 @@ export async function GET(req: NextRequest) {
     // ============================================================================
     // STEP 1: Parse query parameters
     // ============================================================================
-    const { searchParams } = new URL(req.url);
-    const machineId = searchParams.get('machineId');
-    const timePeriod =
-      (searchParams.get('timePeriod') as BillValidatorTimePeriod) || 'today';
-
-    // ============================================================================
-    // STEP 2: Validate machine ID
-    // ============================================================================
-    if (!machineId) {
+    const query = parseQuery(accountingDetailsRoute, req);
+    if (!query.success) {
       logRouteError(
         functionName,
         'GET',
         '/api/accounting-details',
-        'Machine ID is required',
+        'Invalid query parameters',
         user
       );
-      return NextResponse.json(
-        { success: false, error: 'Machine ID is required' },
-        { status: 400 }
-      );
+      return validationErrorResponse(query.error);
     }
+    const { machineId, timePeriod } = query.data;
 
     // ============================================================================
-    // STEP 3: Fetch accounting details including accepted bills
+    // STEP 2: Fetch accounting details including accepted bills
     // ============================================================================
     try {
       const accountingDetails = await getAccountingDetails(machineId, timePeriod);
 
       // ============================================================================
-      // STEP 4: Return accounting details
+      // STEP 3: Return accounting details
       // ============================================================================
       const duration = Date.now() - startTime;
       if (duration > 1000) {
@@ -9,6 +9,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { ActivityLog } from '@/app/api/lib/models/activityLog';
+import { deleteActivityLogRoute } from '@/app/api/lib/routeSchemas/activityLogs';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteDelete,
   logRouteError,
@@ -70,10 +75,9 @@ export async function DELETE(request: NextRequest): Promise<Response> {
       );
     }
 
-    const { searchParams } = new URL(request.url);
-    const deleteType = (searchParams.get('deleteType') || 'soft') as
-      | 'soft'
-      | 'hard';
+    const query = parseQuery(deleteActivityLogRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { deleteType } = query.data;
 
     if (deleteType === 'hard') {
       const deleteResult = await ActivityLog.deleteOne({ _id: id });
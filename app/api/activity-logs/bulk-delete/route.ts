@@ -10,6 +10,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { ActivityLog } from '@/app/api/lib/models/activityLog';
+import { bulkDeleteActivityLogsRoute } from '@/app/api/lib/routeSchemas/activityLogs';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   extractUserFromRequest,
   logRouteDelete,
@@ -17,11 +22,6 @@ import {
 } from '@/app/api/lib/utils/routeLogger';
 import { NextRequest, NextResponse } from 'next/server';
 
-type BulkDeleteBody = {
-  ids?: string[];
-  deleteType?: 'soft' | 'hard';
-};
-
 /**
  * POST /api/activity-logs/bulk-delete
  *
@@ -39,23 +39,14 @@ export async function POST(request: NextRequest): Promise<Response> {
     // ============================================================================
     // STEP 1: Parse and validate request body
     // ============================================================================
-    const body = (await request.json()) as BulkDeleteBody;
-    const ids = body.ids;
-    const deleteType = body.deleteType === 'hard' ? 'hard' : 'soft';
-
-    if (!ids || !Array.isArray(ids) || ids.length === 0) {
-      logRouteError(
-        functionName,
-        'POST',
-        '/api/activity-logs/bulk-delete',
-        'ids array is required',
-        user
-      );
-      return NextResponse.json(
-        { success: false, message: 'ids array is required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      bulkDeleteActivityLogsRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
+    const { ids, deleteType } = validation.data;
 
     // ============================================================================
     // STEP 2: Authenticate and check permissions
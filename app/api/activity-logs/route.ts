@@ -15,6 +15,16 @@ import { ActivityLog } from '@/app/api/lib/models/activityLog';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Machine } from '@/app/api/lib/models/machines';
 import User from '@/app/api/lib/models/user';
+import {
+  createActivityLogRoute,
+  listActivityLogsRoute,
+} from '@/app/api/lib/routeSchemas/activityLogs';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id';
 import { formatIPForDisplay, getIPInfo } from '@/lib/utils/ipAddress';
 import {
@@ -64,35 +74,34 @@ export async function GET(request: NextRequest) {
     request,
     async ({ user: currentUser, userRoles, isAdminOrDev }) => {
       const startTime = Date.now();
-      const { searchParams } = new URL(request.url);
-
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = parseInt(searchParams.get('limit') || '50');
+      const query = parseQuery(listActivityLogsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        page,
+        limit,
+        userId,
+        username,
+        email,
+        action,
+        resource,
+        resourceId,
+        membershipLog,
+        startDate,
+        endDate,
+        search,
+        sortBy,
+        sortOrder,
+      } = query.data;
       const skip = (page - 1) * limit;
 
-      const userId = searchParams.get('userId');
-      const username = searchParams.get('username');
-      const email = searchParams.get('email');
-      const action = searchParams.get('action');
-      const resource = searchParams.get('resource');
-      const resourceId = searchParams.get('resourceId');
-      const membershipLog = searchParams.get('membershipLog');
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
-      const search = searchParams.get('search');
-
-      const sortBy = searchParams.get('sortBy') || 'timestamp';
-      const sortOrder = searchParams.get('sortOrder') || 'desc';
-
-      const filter: Record<string, unknown> = { deletedAt: { $exists: false } };
+      const filter: Record<string, unknown> = { ...NOT_DELETED_FILTER };
       if (userId) filter.userId = userId;
       if (username) filter.username = { $regex: username, $options: 'i' };
       if (email) filter['actor.email'] = { $regex: email, $options: 'i' };
       if (action) filter.action = action;
       if (resource) filter.resource = resource;
       if (resourceId) filter.resourceId = resourceId;
-      if (membershipLog !== null)
-        filter.membershipLog = membershipLog === 'true';
+      if (membershipLog) filter.membershipLog = membershipLog === 'true';
 
       if (startDate || endDate) {
         filter.timestamp = {} as Record<string, Date>;
@@ -339,22 +348,15 @@ export async function POST(request: NextRequest) {
   const user = extractUserFromRequest(request);
 
   return withApiAuth(request, async () => {
-    const body = await request.json();
-    const { action, resource, resourceId, userId, username, userRole } = body;
-
-    if (!action || !resource || !resourceId || !userId || !username) {
-      logRouteError(
-        functionName,
-        'POST',
-        '/api/activity-logs',
-        'Missing required fields',
-        user
-      );
-      return NextResponse.json(
-        { success: false, error: 'Missing required fields' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      createActivityLogRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
+    const body = validation.data;
+    const { action, resource, resourceId, userId, username, userRole } = body;
 
     let changes = body.changes || [];
     if (body.previousData && body.newData) {
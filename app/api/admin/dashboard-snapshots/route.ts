@@ -0,0 +1,117 @@
+/**
+ * Dashboard Snapshots Admin API Route
+ *
+ * Stores the current dashboard stats of every licencee into
+ * `dashboardSnapshots` (hour and day buckets) so their evolution can be
+ * charted without re-aggregating meters (see
+ * app/api/lib/helpers/dashboardSnapshots.ts).
+ *
+ * Intended to be triggered hourly by an external scheduler. Re-running within
+ * the same hour overwrites that hour's snapshot.
+ *
+ * @module app/api/admin/dashboard-snapshots/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { takeDashboardSnapshots } from '@/app/api/lib/helpers/dashboardSnapshots';
+import { NextRequest, NextResponse } from 'next/server';
+import { takeDashboardSnapshotsRoute } from '@/app/api/lib/routeSchemas/admin';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  logRouteCreate,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+
+export const dynamic = 'force-dynamic';
+export const revalidate = 0;
+export const runtime = 'nodejs';
+
+/**
+ * POST /api/admin/dashboard-snapshots
+ *
+ * Takes a snapshot for every active licencee. Restricted to admin and
+ * developer roles.
+ *
+ * Query params:
+ * @param licencee {string} Optional. Comma-separated licencee IDs to limit the run to.
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/admin/dashboard-snapshots';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/dashboard-snapshots',
+        'Forbidden',
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      // ============================================================================
+      // STEP 1: Parse parameters
+      // ============================================================================
+      const query = parseQuery(takeDashboardSnapshotsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const licenceeIds = (query.data.licencee || '')
+        .split(',')
+        .map(id => id.trim())
+        .filter(Boolean);
+
+      // ============================================================================
+      // STEP 2: Take the snapshots
+      // ============================================================================
+      const result = await takeDashboardSnapshots(licenceeIds);
+
+      // ============================================================================
+      // STEP 3: Return summary
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteCreate(
+        functionName,
+        'POST',
+        '/api/admin/dashboard-snapshots',
+        result.written,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({
+        success: result.failed.length === 0,
+        ...result,
+        durationMs: duration,
+      });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/dashboard-snapshots',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -0,0 +1,123 @@
+/**
+ * Integrity Digest Admin API Route
+ *
+ * Emails each licencee's operations contact a summary of the integrity issues
+ * newly detected at its locations (see
+ * app/api/lib/helpers/integrityDigest.ts).
+ *
+ * Intended to be triggered daily by an external scheduler. Issues already
+ * included in a sent digest are not sent again.
+ *
+ * @module app/api/admin/integrity-digest/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { sendIntegrityDigests } from '@/app/api/lib/helpers/integrityDigest';
+import { NextRequest, NextResponse } from 'next/server';
+import { sendIntegrityDigestRoute } from '@/app/api/lib/routeSchemas/admin';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  logRouteCreate,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+
+export const dynamic = 'force-dynamic';
+export const revalidate = 0;
+export const runtime = 'nodejs';
+
+/**
+ * POST /api/admin/integrity-digest
+ *
+ * Sends the digest to every licencee with new issues. Restricted to admin and
+ * developer roles.
+ *
+ * Query params:
+ * @param licencee {string} Optional. Comma-separated licencee IDs to limit the run to.
+ * @param since    {string} Optional. ISO date of the oldest detection included (default: 7 days ago).
+ * @param dryRun   {string} Optional. 'true' builds the digests without sending them.
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/admin/integrity-digest';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/integrity-digest',
+        'Forbidden',
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      // ============================================================================
+      // STEP 1: Parse parameters
+      // ============================================================================
+      const query = parseQuery(sendIntegrityDigestRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const licenceeIds = (query.data.licencee || '')
+        .split(',')
+        .map(id => id.trim())
+        .filter(Boolean);
+      const since = query.data.since ? new Date(query.data.since) : undefined;
+
+      // ============================================================================
+      // STEP 2: Build and send the digests
+      // ============================================================================
+      const result = await sendIntegrityDigests({
+        since,
+        licenceeIds,
+        dryRun: query.data.dryRun === 'true',
+      });
+
+      // ============================================================================
+      // STEP 3: Return summary
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteCreate(
+        functionName,
+        'POST',
+        '/api/admin/integrity-digest',
+        result.sent,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({
+        success: result.failed === 0,
+        ...result,
+        durationMs: duration,
+      });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/integrity-digest',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -12,6 +12,11 @@ import { connectDB } from '@/app/api/lib/middleware/db';
 import mongoose from 'mongoose';
 import { NextResponse } from 'next/server';
 import { logRouteFetch, logRouteError } from '@/app/api/lib/utils/routeLogger';
+import {
+  getReadOnlyMessage,
+  isReadOnlyMode,
+  READ_ONLY_STATUS,
+} from '@/app/api/lib/utils/safetyMode';
 
 /**
  * GET /api/admin/migrations/rename-licencee
@@ -21,11 +26,22 @@ import { logRouteFetch, logRouteError } from '@/app/api/lib/utils/routeLogger';
  * `rel.licencee`, `assignedLicencees`, `licenceeId`, `rel.licenceeId`).
  * Returns a list of collections and field renames where at least one document
  * was modified. No request body or query parameters are required.
+ * Rejected with 423 while read-only mode is on.
  */
 export async function GET() {
   const startTime = Date.now();
   const functionName = 'GET /api/admin/migrations/rename-licencee';
 
+  if (isReadOnlyMode()) {
+    return NextResponse.json(
+      {
+        success: false,
+        error: getReadOnlyMessage('the licencee rename migration'),
+      },
+      { status: READ_ONLY_STATUS }
+    );
+  }
+
   try {
     await connectDB();
 
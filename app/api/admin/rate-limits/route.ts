@@ -0,0 +1,77 @@
+/**
+ * API Rate Limits Admin API Route
+ *
+ * Reports the API quotas in effect and how often clients were throttled on
+ * this app instance: requests refused with 429, and aggregations that waited
+ * for or were refused a slot (see app/api/lib/helpers/apiQuotas.ts).
+ *
+ * @module app/api/admin/rate-limits/route
+ */
+
+import { getApiQuotaStats } from '@/app/api/lib/helpers/apiQuotas';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { NextRequest, NextResponse } from 'next/server';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+
+export const dynamic = 'force-dynamic';
+export const runtime = 'nodejs';
+
+/**
+ * GET /api/admin/rate-limits
+ *
+ * Returns the default quota, throttling totals since the instance started,
+ * and per-client counters with each client's quota for the last hour (most
+ * throttled first). Restricted to admin and developer roles.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/admin/rate-limits';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/admin/rate-limits',
+        'Forbidden',
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      const stats = getApiQuotaStats();
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/admin/rate-limits',
+        stats.clients.length,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, ...stats });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/admin/rate-limits',
+        errorMessage,
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
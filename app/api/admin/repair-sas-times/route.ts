@@ -22,6 +22,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
 
 export const dynamic = 'force-dynamic';
 export const revalidate = 0;
@@ -35,6 +36,7 @@ type RepairMode = 'dry-run' | 'commit';
  * Repairs incorrect SAS timestamps in collection records by normalising them to
  * the 8 AM Trinidad-time gaming-day boundary and recalculating SAS metrics.
  * Run in 'dry-run' mode first to preview changes, then 'commit' to apply them.
+ * Commit mode is rejected with 423 while read-only mode is on.
  * No authentication guard in the handler — restrict at infrastructure level.
  *
  * Query params:
@@ -112,7 +114,7 @@ export async function POST(req: NextRequest) {
 
     return NextResponse.json(
       { success: false, error: errorMessage },
-      { status: 500 }
+      { status: getErrorStatus(error) }
     );
   }
 }
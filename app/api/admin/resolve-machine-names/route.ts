@@ -12,6 +12,12 @@ import { connectDB } from '@/app/api/lib/middleware/db';
 import { ActivityLog } from '@/app/api/lib/models/activityLog';
 import { Machine } from '@/app/api/lib/models/machines';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { resolveMachineNamesRoute } from '@/app/api/lib/routeSchemas/admin';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { ActivityLogDocument, GamingMachine } from '@/shared/types';
 import { NextRequest, NextResponse } from 'next/server';
 import {
@@ -61,12 +67,13 @@ export async function GET(request: NextRequest) {
 
     await connectDB();
 
-    const { searchParams } = new URL(request.url);
-    const limit = Math.min(parseInt(searchParams.get('limit') || '100'), 500);
+    const query = parseQuery(resolveMachineNamesRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const limit = Math.min(query.data.limit, 500);
 
     const total = await ActivityLog.countDocuments({
       resourceName: { $regex: /^[a-fA-F0-9]{24}$/ },
-      deletedAt: null,
+      ...NOT_DELETED_FILTER,
     });
 
     if (total === 0) {
@@ -88,7 +95,7 @@ export async function GET(request: NextRequest) {
     }
 
     const logsToResolve = await ActivityLog.find(
-      { resourceName: { $regex: /^[a-fA-F0-9]{24}$/ }, deletedAt: null },
+      { resourceName: { $regex: /^[a-fA-F0-9]{24}$/ }, ...NOT_DELETED_FILTER },
       { _id: 1, resourceName: 1 }
     )
       .limit(limit)
@@ -147,7 +154,7 @@ export async function GET(request: NextRequest) {
 
     const remaining = await ActivityLog.countDocuments({
       resourceName: { $regex: /^[a-fA-F0-9]{24}$/ },
-      deletedAt: null,
+      ...NOT_DELETED_FILTER,
     });
 
     const duration = Date.now() - startTime;
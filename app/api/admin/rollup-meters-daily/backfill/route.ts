@@ -0,0 +1,196 @@
+/**
+ * Daily Meters Rollup Backfill Admin API Route
+ *
+ * Populates `metersDaily` for historical date ranges in month-sized chunks with
+ * resume checkpoints and per-month integrity verification against raw meters.
+ * Call POST repeatedly with the same range until `done` is true; each call picks
+ * up after the last completed gaming day.
+ *
+ * @module app/api/admin/rollup-meters-daily/backfill/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { isValidGamingDay } from '@/app/api/lib/helpers/metersDaily';
+import {
+  getBackfillCheckpoint,
+  runMetersDailyBackfill,
+} from '@/app/api/lib/helpers/metersDailyBackfill';
+import { NextRequest, NextResponse } from 'next/server';
+import {
+  getBackfillCheckpointRoute,
+  runBackfillRoute,
+} from '@/app/api/lib/routeSchemas/admin';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  logRouteCreate,
+  logRouteError,
+  logRouteFetch,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+
+export const dynamic = 'force-dynamic';
+export const revalidate = 0;
+export const runtime = 'nodejs';
+
+const MAX_MONTHS_PER_CALL = 12;
+
+/**
+ * GET /api/admin/rollup-meters-daily/backfill
+ *
+ * Returns the checkpoint (progress, status, verification results) for a range.
+ *
+ * Query params:
+ * @param from {string} Required. First gaming day (YYYY-MM-DD).
+ * @param to   {string} Required. Last gaming day (YYYY-MM-DD).
+ */
+export async function GET(request: NextRequest) {
+  const functionName = 'GET /api/admin/rollup-meters-daily/backfill';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    const query = parseQuery(getBackfillCheckpointRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { from, to } = query.data;
+
+    if (!isValidGamingDay(from) || !isValidGamingDay(to)) {
+      return NextResponse.json(
+        { success: false, error: 'from and to must be in YYYY-MM-DD format' },
+        { status: 400 }
+      );
+    }
+
+    const checkpoint = await getBackfillCheckpoint(from, to);
+    logRouteFetch(
+      functionName,
+      'GET',
+      '/api/admin/rollup-meters-daily/backfill',
+      checkpoint ? 1 : 0,
+      user
+    );
+
+    return NextResponse.json({ success: true, checkpoint });
+  });
+}
+
+/**
+ * POST /api/admin/rollup-meters-daily/backfill
+ *
+ * Runs the next chunk of a backfill. Restricted to admin and developer roles.
+ *
+ * Body fields:
+ * @param from      {string}  Required. First gaming day (YYYY-MM-DD).
+ * @param to        {string}  Required. Last gaming day (YYYY-MM-DD), inclusive.
+ * @param maxMonths {number}  Optional. Months to process in this call (1-12). Defaults to 1.
+ * @param verify    {boolean} Optional. Verify each month against raw meters. Defaults to true.
+ * @param restart   {boolean} Optional. Discard the checkpoint and start over. Defaults to false.
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/admin/rollup-meters-daily/backfill';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/rollup-meters-daily/backfill',
+        'Forbidden',
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate body
+      // ============================================================================
+      const validation = parseBody(
+        runBackfillRoute,
+        await request.json().catch(() => ({}))
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+      const { from, to } = body;
+
+      if (!isValidGamingDay(from) || !isValidGamingDay(to)) {
+        return NextResponse.json(
+          { success: false, error: 'from and to must be in YYYY-MM-DD format' },
+          { status: 400 }
+        );
+      }
+      if (from > to) {
+        return NextResponse.json(
+          { success: false, error: 'from must be on or before to' },
+          { status: 400 }
+        );
+      }
+
+      const maxMonths = Math.min(
+        Math.max(body.maxMonths, 1),
+        MAX_MONTHS_PER_CALL
+      );
+
+      // ============================================================================
+      // STEP 2: Run the next backfill chunk
+      // ============================================================================
+      const result = await runMetersDailyBackfill({
+        from,
+        to,
+        maxMonths,
+        verify: body.verify,
+        restart: body.restart,
+      });
+
+      // ============================================================================
+      // STEP 3: Return progress
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteCreate(
+        functionName,
+        'POST',
+        '/api/admin/rollup-meters-daily/backfill',
+        result.machinesWritten,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, ...result, durationMs: duration });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/rollup-meters-daily/backfill',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
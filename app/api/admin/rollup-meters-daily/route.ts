@@ -0,0 +1,125 @@
+/**
+ * Daily Meters Rollup Admin API Route
+ *
+ * Materializes per-machine, per-gaming-day movement totals into the
+ * `metersDaily` collection. Reports read complete days from the rollup and only
+ * hit raw meters for partial days (see app/api/lib/helpers/metersDaily.ts).
+ *
+ * Intended to be triggered once per day after the gaming day closes (e.g. by
+ * an external scheduler). Re-running for the same day is safe — rows are upserted.
+ *
+ * @module app/api/admin/rollup-meters-daily/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getDefaultRollupDay,
+  isValidGamingDay,
+  rollupMetersForDay,
+} from '@/app/api/lib/helpers/metersDaily';
+import { NextRequest, NextResponse } from 'next/server';
+import { rollupMetersDailyRoute } from '@/app/api/lib/routeSchemas/admin';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  logRouteCreate,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+
+export const dynamic = 'force-dynamic';
+export const revalidate = 0;
+export const runtime = 'nodejs';
+
+/**
+ * POST /api/admin/rollup-meters-daily
+ *
+ * Rolls up raw meters for one gaming day. Locations whose gaming day has not
+ * finished yet are skipped. Restricted to admin and developer roles.
+ *
+ * Query params:
+ * @param date       {string} Optional. Gaming day as YYYY-MM-DD. Defaults to yesterday's gaming day.
+ * @param locationId {string} Optional. Limit the rollup to a single location.
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/admin/rollup-meters-daily';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/rollup-meters-daily',
+        'Forbidden',
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate parameters
+      // ============================================================================
+      const query = parseQuery(rollupMetersDailyRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const gamingDay = query.data.date || getDefaultRollupDay();
+      const { locationId } = query.data;
+
+      if (!isValidGamingDay(gamingDay)) {
+        return NextResponse.json(
+          { success: false, error: 'date must be in YYYY-MM-DD format' },
+          { status: 400 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Run rollup
+      // ============================================================================
+      const result = await rollupMetersForDay(
+        gamingDay,
+        locationId ? [locationId] : undefined
+      );
+
+      // ============================================================================
+      // STEP 3: Return summary
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteCreate(
+        functionName,
+        'POST',
+        '/api/admin/rollup-meters-daily',
+        result.machinesWritten,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, ...result, durationMs: duration });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/admin/rollup-meters-daily',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -23,6 +23,12 @@ import {
   syncAllLocationSmibStatuses,
 } from '@/app/api/lib/helpers/smibClassification';
 import { NextRequest, NextResponse } from 'next/server';
+import { syncSmibClassificationsRoute } from '@/app/api/lib/routeSchemas/admin';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -88,11 +94,14 @@ export async function POST(request: NextRequest) {
     try {
       await connectDB();
 
-      const { searchParams } = new URL(request.url);
-      const licenceeFilter = searchParams.get('licencee');
-
-      const body = await request.json().catch(() => ({}));
-      const licenceeParam = body.licencee || licenceeFilter;
+      const query = parseQuery(syncSmibClassificationsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const body = parseBody(
+        syncSmibClassificationsRoute,
+        await request.json().catch(() => undefined)
+      );
+      if (!body.success) return validationErrorResponse(body.error);
+      const licenceeParam = body.data?.licencee || query.data.licencee;
 
       const userAccessibleLicencees =
         await getUserAccessibleLicenceesFromToken();
@@ -6,13 +6,17 @@
  * @module app/api/analytics/charts/route
  */
 
+import { getAggregationStrategy } from '@/app/api/lib/helpers/aggregationFanOut';
 import { getChartsData } from '@/app/api/lib/helpers/reports/analytics';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import type { CurrencyCode } from '@/shared/types/currency';
 import { NextRequest, NextResponse } from 'next/server';
+import { chartsRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
-  logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
 
@@ -25,6 +29,7 @@ import {
  * @param licencee  {string}              Required. Scopes results to this licencee.
  * @param period    {'last7days'|'last30days'} Optional. Time window for the chart data. Defaults to 'last30days'.
  * @param currency  {CurrencyCode}        Optional. Display currency for converted values. Defaults to 'USD'.
+ * @param strategy  {'single'|'fanout'}   Optional. One pipeline or one per location. Defaults to AGGREGATION_STRATEGY.
  */
 export async function GET(request: NextRequest) {
   const startTime = Date.now();
@@ -32,29 +37,16 @@ export async function GET(request: NextRequest) {
   const user = extractUserFromRequest(request);
 
   return withApiAuth(request, async () => {
-    const { searchParams } = new URL(request.url);
-    const licencee = searchParams.get('licencee');
-    const period =
-      (searchParams.get('period') as 'last7days' | 'last30days') ||
-      'last30days';
-    const displayCurrency =
-      (searchParams.get('currency') as CurrencyCode) || 'USD';
-
-    if (!licencee) {
-      logRouteError(
-        functionName,
-        'GET',
-        '/api/analytics/charts',
-        'Licencee is required',
-        user
-      );
-      return NextResponse.json(
-        { message: 'Licencee is required' },
-        { status: 400 }
-      );
-    }
+    const query = parseQuery(chartsRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { licencee, period, currency: displayCurrency } = query.data;
 
-    const chartsData = await getChartsData(licencee, period, displayCurrency);
+    const chartsData = await getChartsData(
+      licencee,
+      period,
+      displayCurrency,
+      getAggregationStrategy(query.data.strategy)
+    );
     const duration = Date.now() - startTime;
     logRouteFetch(
       functionName,
@@ -0,0 +1,133 @@
+/**
+ * Analytics Chart Series API Route
+ *
+ * Chart data as a gap-filled time series at hourly, daily, weekly or monthly
+ * granularity, downsampled automatically for long ranges.
+ *
+ * @module app/api/analytics/charts/series/route
+ */
+
+import {
+  getAggregationStrategy,
+} from '@/app/api/lib/helpers/aggregationFanOut';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getChartSeries } from '@/app/api/lib/helpers/reports/chartSeries';
+import { chartSeriesRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { subDays } from 'date-fns';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/analytics/charts/series
+ *
+ * Query params:
+ * @param licencee    {string} Required. Scopes results to this licencee.
+ * @param startDate   {string} Optional. Range start (ISO). Defaults to 30 days before endDate.
+ * @param endDate     {string} Optional. Range end (ISO). Defaults to now.
+ * @param granularity {'hourly'|'daily'|'weekly'|'monthly'} Optional. Defaults to hourly up to two days, daily otherwise.
+ * @param maxPoints   {number} Optional. Point budget before downsampling (default 400, max 2000).
+ * @param currency    {CurrencyCode} Optional. Display currency. Defaults to 'USD'.
+ * @param strategy    {'single'|'fanout'} Optional. One pipeline or one per location. Defaults to AGGREGATION_STRATEGY.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/analytics/charts/series';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    const query = parseQuery(chartSeriesRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { licencee, granularity, maxPoints } = query.data;
+    const displayCurrency = query.data.currency;
+    const endDate = query.data.endDate
+      ? new Date(query.data.endDate)
+      : new Date();
+    const startDate = query.data.startDate
+      ? new Date(query.data.startDate)
+      : subDays(endDate, 30);
+
+    let invalid: string | null = null;
+    if (Number.isNaN(startDate.getTime()) || Number.isNaN(endDate.getTime())) {
+      invalid = 'startDate and endDate must be dates';
+    } else if (startDate > endDate) {
+      invalid = 'startDate must be before endDate';
+    }
+    if (invalid) {
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/analytics/charts/series',
+        invalid,
+        user
+      );
+      return NextResponse.json({ message: invalid }, { status: 400 });
+    }
+
+    const accessibleLicencees = await getUserAccessibleLicenceesFromToken();
+    if (
+      accessibleLicencees !== 'all' &&
+      !accessibleLicencees.includes(licencee)
+    ) {
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/analytics/charts/series',
+        'Unauthorized: You do not have access to this licencee',
+        user
+      );
+      return NextResponse.json(
+        { message: 'Unauthorized: You do not have access to this licencee' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      const series = await getChartSeries({
+        licencee,
+        startDate,
+        endDate,
+        granularity,
+        maxPoints,
+        displayCurrency,
+        strategy: getAggregationStrategy(query.data.strategy),
+      });
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/analytics/charts/series',
+        series.points.length,
+        user,
+        duration
+      );
+      return NextResponse.json(series);
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/analytics/charts/series',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { message: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -6,15 +6,19 @@
  * @module app/api/analytics/dashboard/route
  */
 
+import { getAggregationStrategy } from '@/app/api/lib/helpers/aggregationFanOut';
 import { getDashboardAnalytics } from '@/app/api/lib/helpers/reports/analytics';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import { convertFromUSD } from '@/lib/helpers/rates';
-import type { CurrencyCode } from '@/shared/types/currency';
 import { NextRequest, NextResponse } from 'next/server';
+import { dashboardRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
-  logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
 
@@ -31,6 +35,8 @@ import {
  *                                 When the licencee has currency conversion enabled, financial
  *                                 fields are converted from USD to this currency before returning.
  *                                 Defaults to 'USD' (no conversion applied).
+ * @param strategy  {'single'|'fanout'} Optional. Run one pipeline, or one per location and merge
+ *                                     the totals. Defaults to AGGREGATION_STRATEGY ('single').
  */
 export async function GET(request: NextRequest) {
   const startTime = Date.now();
@@ -38,26 +44,14 @@ export async function GET(request: NextRequest) {
   const user = extractUserFromRequest(request);
 
   return withApiAuth(request, async () => {
-    const { searchParams } = new URL(request.url);
-    const licencee = searchParams.get('licencee');
-    const displayCurrency =
-      (searchParams.get('currency') as CurrencyCode) || 'USD';
-
-    if (!licencee) {
-      logRouteError(
-        functionName,
-        'GET',
-        '/api/analytics/dashboard',
-        'Licencee is required',
-        user
-      );
-      return NextResponse.json(
-        { message: 'Licencee is required' },
-        { status: 400 }
-      );
-    }
+    const query = parseQuery(dashboardRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { licencee, currency: displayCurrency } = query.data;
 
-    const globalStats = await getDashboardAnalytics(licencee);
+    const globalStats = await getDashboardAnalytics(
+      licencee,
+      getAggregationStrategy(query.data.strategy)
+    );
 
     let convertedStats = globalStats;
     if (shouldApplyCurrencyConversion(licencee)) {
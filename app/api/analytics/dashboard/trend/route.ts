@@ -0,0 +1,114 @@
+/**
+ * Dashboard Trend API Route
+ *
+ * Returns the stored dashboard snapshots of a licencee over time (e.g. gross
+ * over the last 90 days) for trend charts, read from `dashboardSnapshots`
+ * instead of re-aggregating meters.
+ *
+ * @module app/api/analytics/dashboard/trend/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { getDashboardSnapshotTrend } from '@/app/api/lib/helpers/dashboardSnapshots';
+import { getUserAccessibleLicenceesFromToken } from '@/app/api/lib/helpers/licenceeFilter';
+import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
+import { convertFromUSD } from '@/lib/helpers/rates';
+import { NextRequest, NextResponse } from 'next/server';
+import { dashboardTrendRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+
+/**
+ * GET /api/analytics/dashboard/trend
+ *
+ * Query params:
+ * @param licencee    {string} Required. Licencee ID.
+ * @param granularity {'day'|'hour'} Optional. Defaults to 'day'. Hourly snapshots are kept 30 days.
+ * @param days        {number} Optional. Window length in days (1-366). Defaults to 90.
+ * @param currency    {CurrencyCode} Optional. Display currency; financial fields are
+ *                                   converted from USD when the licencee has conversion enabled.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/analytics/dashboard/trend';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    // ============================================================================
+    // STEP 1: Parse and validate parameters
+    // ============================================================================
+    const query = parseQuery(dashboardTrendRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { licencee, granularity, days } = query.data;
+    const displayCurrency = query.data.currency;
+
+    // ============================================================================
+    // STEP 2: Validate licencee access
+    // ============================================================================
+    const accessibleLicencees = await getUserAccessibleLicenceesFromToken();
+    if (
+      accessibleLicencees !== 'all' &&
+      !accessibleLicencees.includes(licencee)
+    ) {
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/analytics/dashboard/trend',
+        'Unauthorized: You do not have access to this licencee',
+        user
+      );
+      return NextResponse.json(
+        { message: 'Unauthorized: You do not have access to this licencee' },
+        { status: 403 }
+      );
+    }
+
+    // ============================================================================
+    // STEP 3: Read the series and convert currency
+    // ============================================================================
+    const trend = await getDashboardSnapshotTrend(licencee, {
+      granularity,
+      days,
+    });
+
+    const converted = shouldApplyCurrencyConversion(licencee);
+    const points = converted
+      ? trend.points.map(point => ({
+          ...point,
+          totalDrop: convertFromUSD(point.totalDrop, displayCurrency),
+          totalCancelledCredits: convertFromUSD(
+            point.totalCancelledCredits,
+            displayCurrency
+          ),
+          totalGross: convertFromUSD(point.totalGross, displayCurrency),
+        }))
+      : trend.points;
+
+    // ============================================================================
+    // STEP 4: Return
+    // ============================================================================
+    const duration = Date.now() - startTime;
+    logRouteFetch(
+      functionName,
+      'GET',
+      '/api/analytics/dashboard/trend',
+      points.length,
+      user,
+      duration
+    );
+
+    return NextResponse.json({
+      ...trend,
+      points,
+      currency: displayCurrency,
+      converted,
+    });
+  });
+}
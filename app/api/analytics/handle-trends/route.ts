@@ -13,8 +13,12 @@
 
 import { getHandleTrends } from '@/app/api/lib/helpers/trends/general';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import { TimePeriod } from '@/shared/types';
 import { NextRequest, NextResponse } from 'next/server';
+import { handleTrendsRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -47,11 +51,9 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-      const timePeriod =
-        (searchParams.get('timePeriod') as TimePeriod) || 'Today';
-      const licencee = searchParams.get('licencee');
-      const locationIds = searchParams.get('locationIds');
+      const query = parseQuery(handleTrendsRoute, req);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { timePeriod, licencee, locationIds } = query.data;
 
       // ============================================================================
       // STEP 2: Execute the core handle trends fetching logic via helper
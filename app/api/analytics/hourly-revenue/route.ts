@@ -8,7 +8,13 @@
 
 import { getHourlyRevenue } from '@/app/api/lib/helpers/trends/general';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
 import { NextRequest, NextResponse } from 'next/server';
+import { hourlyRevenueRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -32,24 +38,19 @@ export async function GET(request: NextRequest) {
   const user = extractUserFromRequest(request);
 
   return withApiAuth(request, async () => {
-    const { searchParams } = new URL(request.url);
-    const locationId = searchParams.get('locationId');
-    const timePeriod = searchParams.get('timePeriod') || '24h';
-    const startDate = searchParams.get('startDate');
-    const endDate = searchParams.get('endDate');
+    const query = parseQuery(hourlyRevenueRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { locationId, timePeriod, startDate, endDate } = query.data;
 
-    if (!locationId) {
+    if (!(await checkUserLocationAccess(locationId))) {
       logRouteError(
         functionName,
         'GET',
         '/api/analytics/hourly-revenue',
-        'Location ID is required',
+        'Forbidden',
         user
       );
-      return NextResponse.json(
-        { error: 'Location ID is required' },
-        { status: 400 }
-      );
+      return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
     }
 
     const hourlyRevenue = await getHourlyRevenue(
@@ -8,8 +8,12 @@
 
 import { getJackpotTrends } from '@/app/api/lib/helpers/trends/general';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import { TimePeriod } from '@/shared/types';
 import { NextRequest, NextResponse } from 'next/server';
+import { jackpotTrendsRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   extractUserFromRequest,
@@ -31,11 +35,9 @@ export async function GET(req: NextRequest) {
   const user = extractUserFromRequest(req);
 
   return withApiAuth(req, async () => {
-    const { searchParams } = new URL(req.url);
-    const timePeriod =
-      (searchParams.get('timePeriod') as TimePeriod) || 'Today';
-    const licencee = searchParams.get('licencee');
-    const locationIds = searchParams.get('locationIds');
+    const query = parseQuery(jackpotTrendsRoute, req);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { timePeriod, licencee, locationIds } = query.data;
 
     const jackpotTrends = await getJackpotTrends(
       timePeriod,
@@ -0,0 +1,184 @@
+/**
+ * Location Heatmap API Route
+ *
+ * Returns location activity bucketed into geohash cells for map heatmaps.
+ * It supports:
+ * - Configurable cell resolution (geohash precision)
+ * - Licencee filtering and role-based location access
+ * - Time period / custom date range per location gaming day
+ * - Reviewer money scales
+ *
+ * @module app/api/analytics/location-heatmap/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getLocationHeatmap } from '@/app/api/lib/helpers/reports/locationHeatmap';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import { locationHeatmapRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  getMoneyInScale,
+  getMoneyOutAndJackpotScale,
+} from '@/app/api/lib/utils/reviewerScale';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/analytics/location-heatmap
+ *
+ * Query params:
+ * @param precision  {number}     Optional. Geohash length 1-12 (5 ≈ 4.9 km cells). Defaults to 5.
+ * @param licencee   {string}     Optional. Scopes results to this licencee.
+ * @param timePeriod {TimePeriod} Optional. Defaults to 'Today'.
+ * @param startDate  {string}     Optional. Custom range start (with timePeriod=Custom).
+ * @param endDate    {string}     Optional. Custom range end (with timePeriod=Custom).
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build heatmap cells via `getLocationHeatmap`
+ * 4. Return cells
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/analytics/location-heatmap';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(locationHeatmapRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        licencee = '',
+        timePeriod,
+        startDate,
+        endDate,
+        precision,
+      } = query.data;
+      const customStartDate = startDate ? new Date(startDate) : undefined;
+      const customEndDate = endDate ? new Date(endDate) : undefined;
+
+      if (
+        timePeriod === 'Custom' &&
+        (!customStartDate ||
+          !customEndDate ||
+          Number.isNaN(customStartDate.getTime()) ||
+          Number.isNaN(customEndDate.getTime()))
+      ) {
+        return NextResponse.json(
+          {
+            success: false,
+            error: 'Valid startDate and endDate are required for Custom',
+          },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      if (allowedLocationIds !== 'all' && allowedLocationIds.length === 0) {
+        return NextResponse.json({
+          success: true,
+          data: { precision, cells: [], locationsWithoutCoordinates: 0 },
+        });
+      }
+
+      // ============================================================================
+      // STEP 3: Build heatmap cells
+      // ============================================================================
+      const referenceDate = customEndDate || new Date();
+      const heatmap = await getLocationHeatmap({
+        allowedLocationIds,
+        precision,
+        timePeriod,
+        customStartDate,
+        customEndDate,
+        scales: {
+          moneyInScale: getMoneyInScale(
+            userPayload as {
+              moneyInMultiplier?: number | null;
+              roles?: string[];
+              reviewerMultiplierStartTime?: Date | string | null;
+            },
+            referenceDate
+          ),
+          moneyOutScale: getMoneyOutAndJackpotScale(
+            userPayload as {
+              moneyOutAndJackpotMultiplier?: number | null;
+              roles?: string[];
+              reviewerMultiplierStartTime?: Date | string | null;
+            },
+            referenceDate
+          ),
+        },
+      });
+
+      // ============================================================================
+      // STEP 4: Return cells
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/analytics/location-heatmap',
+        heatmap.cells.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, data: heatmap });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/analytics/location-heatmap',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
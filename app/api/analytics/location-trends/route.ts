@@ -15,9 +15,13 @@
 
 import { getLocationTrends } from '@/app/api/lib/helpers/trends/locations';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import { TimePeriod } from '@/shared/types';
-import type { CurrencyCode } from '@/shared/types/currency';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
 import { NextRequest, NextResponse } from 'next/server';
+import { locationTrendsRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -45,8 +49,9 @@ import {
  * Flow:
  * 1. Connect to database
  * 2. Parse and validate request parameters (locationIds, timePeriod, licencee, startDate, endDate, currency)
- * 3. Execute the core location trends fetching logic via `getLocationTrends` helper
- * 4. Return location trends data
+ * 3. Check access to the requested locations
+ * 4. Execute the core location trends fetching logic via `getLocationTrends` helper
+ * 5. Return location trends data
  */
 export async function GET(req: NextRequest) {
   const startTime = Date.now();
@@ -58,65 +63,48 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-      const locationIds = searchParams.get('locationIds');
-      const timePeriod =
-        (searchParams.get('timePeriod') as TimePeriod) || 'Today';
-      const licencee = searchParams.get('licencee');
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
-      const displayCurrency =
-        (searchParams.get('currency') as CurrencyCode) || 'USD';
-      const granularity = searchParams.get('granularity') as
-        | 'hourly'
-        | 'minute'
-        | 'daily'
-        | 'weekly'
-        | 'monthly';
-      const status = searchParams.get('status') as
-        | 'Online'
-        | 'Offline'
-        | 'All'
-        | null;
-      const gameType = searchParams.get('gameType');
-      const searchTerm = searchParams.get('search');
-      const includeArchived = searchParams.get('includeArchived') === 'true';
+      const query = parseQuery(locationTrendsRoute, req);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { locationIds, timePeriod, granularity, status } = query.data;
+      const displayCurrency = query.data.currency;
+      const includeArchived = query.data.includeArchived === 'true';
 
-      if (!locationIds) {
-        logRouteError(
-          functionName,
-          'GET',
-          '/api/analytics/location-trends',
-          'Location IDs are required',
-          user
-        );
-        return NextResponse.json(
-          { error: 'Location IDs are required' },
-          { status: 400 }
-        );
+      // ============================================================================
+      // STEP 2: Check access to the requested locations
+      // ============================================================================
+      const requested = locationIds.split(',').map(id => id.trim());
+      for (const locationId of requested.filter(Boolean)) {
+        if (!(await checkUserLocationAccess(locationId))) {
+          logRouteError(
+            functionName,
+            'GET',
+            '/api/analytics/location-trends',
+            'Forbidden',
+            user
+          );
+          return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        }
       }
 
-      const effectiveGranularity = granularity || 'daily';
-
       // ============================================================================
-      // STEP 2: Execute the core location trends fetching logic via helper
+      // STEP 3: Execute the core location trends fetching logic via helper
       // ============================================================================
       const trendsData = await getLocationTrends(
         locationIds,
         timePeriod,
-        licencee,
-        startDateParam,
-        endDateParam,
+        query.data.licencee ?? null,
+        query.data.startDate ?? null,
+        query.data.endDate ?? null,
         displayCurrency,
-        effectiveGranularity,
+        granularity,
         status,
-        gameType,
-        searchTerm || undefined,
+        query.data.gameType,
+        query.data.search,
         includeArchived
       );
 
       // ============================================================================
-      // STEP 3: Return location trends data
+      // STEP 4: Return location trends data
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteFetch(
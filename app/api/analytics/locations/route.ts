@@ -14,8 +14,12 @@
 
 import { getTopLocationsAnalytics } from '@/app/api/lib/helpers/reports/analytics';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import type { CurrencyCode } from '@/shared/types/currency';
 import { NextRequest, NextResponse } from 'next/server';
+import { topLocationsRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -47,24 +51,9 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const licencee = searchParams.get('licencee');
-      const displayCurrency =
-        (searchParams.get('currency') as CurrencyCode) || 'USD';
-
-      if (!licencee) {
-        logRouteError(
-          functionName,
-          'GET',
-          '/api/analytics/locations',
-          'Licencee is required',
-          user
-        );
-        return NextResponse.json(
-          { message: 'Licencee is required' },
-          { status: 400 }
-        );
-      }
+      const query = parseQuery(topLocationsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee, currency: displayCurrency } = query.data;
 
       // ============================================================================
       // STEP 2: Execute the core top locations fetching logic via helper
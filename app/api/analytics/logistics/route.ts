@@ -13,6 +13,11 @@
 import { getLogisticsData } from '@/app/api/lib/helpers/logistics';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { NextRequest, NextResponse } from 'next/server';
+import { logisticsRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -44,9 +49,9 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const searchTerm = searchParams.get('searchTerm');
-      const statusFilter = searchParams.get('statusFilter');
+      const query = parseQuery(logisticsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { searchTerm, statusFilter } = query.data;
 
       // ============================================================================
       // STEP 2: Fetch logistics data
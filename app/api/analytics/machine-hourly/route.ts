@@ -15,9 +15,14 @@
 
 import { getMachineHourlyData } from '@/app/api/lib/helpers/trends/machineHourly';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import type { CurrencyCode } from '@/shared/types/currency';
-import { TimePeriod } from '@/shared/types';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import { Machine } from '@/app/api/lib/models/machines';
 import { NextRequest, NextResponse } from 'next/server';
+import { machineHourlyRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -41,8 +46,9 @@ import {
  * Flow:
  * 1. Connect to database
  * 2. Parse and validate request parameters (locationIds, machineIds, timePeriod, licencee, startDate, endDate, currency)
- * 3. Execute the core machine hourly fetching logic via `getMachineHourlyData` helper
- * 4. Return machine hourly trends data
+ * 3. Check access to the requested locations and the machines' locations
+ * 4. Execute the core machine hourly fetching logic via `getMachineHourlyData` helper
+ * 5. Return machine hourly trends data
  */
 export async function GET(req: NextRequest) {
   const startTime = Date.now();
@@ -54,16 +60,17 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-      const locationIds = searchParams.get('locationIds');
-      const machineIds = searchParams.get('machineIds');
-      const timePeriod =
-        (searchParams.get('timePeriod') as TimePeriod) || 'Today';
-      const licencee = searchParams.get('licencee');
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
-      const displayCurrency =
-        (searchParams.get('currency') as CurrencyCode) || 'USD';
+      const query = parseQuery(machineHourlyRoute, req);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        locationIds = null,
+        machineIds = null,
+        timePeriod,
+        licencee = null,
+        startDate: startDateParam = null,
+        endDate: endDateParam = null,
+        currency: displayCurrency,
+      } = query.data;
 
       if (!locationIds && !machineIds) {
         logRouteError(
@@ -80,7 +87,39 @@ export async function GET(req: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Execute the core machine hourly fetching logic via helper
+      // STEP 2: Check access to the requested locations and machines
+      // ============================================================================
+      const splitIds = (value: string | null) =>
+        (value || '')
+          .split(',')
+          .map(id => id.trim())
+          .filter(Boolean);
+      const requestedMachines = splitIds(machineIds);
+      const machineLocations =
+        requestedMachines.length > 0
+          ? await Machine.distinct('gamingLocation', {
+              _id: { $in: requestedMachines },
+            })
+          : [];
+      const requestedLocations = new Set([
+        ...splitIds(locationIds),
+        ...machineLocations.filter(Boolean).map(String),
+      ]);
+      for (const locationId of requestedLocations) {
+        if (!(await checkUserLocationAccess(locationId))) {
+          logRouteError(
+            functionName,
+            'GET',
+            '/api/analytics/machine-hourly',
+            'Forbidden',
+            user
+          );
+          return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        }
+      }
+
+      // ============================================================================
+      // STEP 3: Execute the core machine hourly fetching logic via helper
       // ============================================================================
       const machineHourlyData = await getMachineHourlyData(
         locationIds,
@@ -93,7 +132,7 @@ export async function GET(req: NextRequest) {
       );
 
       // ============================================================================
-      // STEP 3: Return machine hourly trends data
+      // STEP 4: Return machine hourly trends data
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteFetch(
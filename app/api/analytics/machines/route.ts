@@ -19,6 +19,11 @@ import {
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { NextRequest, NextResponse } from 'next/server';
+import { topMachinesRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -53,10 +58,13 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const limit = Number(searchParams.get('limit')) || 5;
-      const selectedLicencee = searchParams.get('licencee') || undefined;
-      const selectedLocation = searchParams.get('location') || undefined;
+      const query = parseQuery(topMachinesRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        limit,
+        licencee: selectedLicencee,
+        location: selectedLocation,
+      } = query.data;
 
       // ============================================================================
       // STEP 2: Authenticate user and get accessible locations
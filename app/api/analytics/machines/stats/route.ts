@@ -18,6 +18,11 @@ import {
 } from '@/app/api/lib/helpers/licenceeFilter';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { NextRequest, NextResponse } from 'next/server';
+import { machineStatsRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -50,8 +55,9 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const licencee = searchParams.get('licencee');
+      const query = parseQuery(machineStatsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee } = query.data;
       const effectiveLicencee =
         licencee && licencee.toLowerCase() !== 'all' ? licencee : null;
 
@@ -14,8 +14,13 @@
 
 import { getManufacturerPerformance } from '@/app/api/lib/helpers/reports/manufacturerPerformance';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import type { TimePeriod } from '@/shared/types';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
 import { NextRequest, NextResponse } from 'next/server';
+import { manufacturerPerformanceRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -37,8 +42,9 @@ import {
  * Flow:
  * 1. Parse and validate request parameters
  * 2. Connect to database
- * 3. Fetch manufacturer performance data
- * 4. Return manufacturer performance
+ * 3. Check access to the requested location
+ * 4. Fetch manufacturer performance data
+ * 5. Return manufacturer performance
  */
 export async function GET(request: NextRequest) {
   const startTime = Date.now();
@@ -50,15 +56,12 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      const timePeriod =
-        (searchParams.get('timePeriod') as TimePeriod) || 'Today';
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
-      const licencee = searchParams.get('licencee');
+      const query = parseQuery(manufacturerPerformanceRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { locationId, timePeriod, startDate, endDate, licencee } =
+        query.data;
 
-      if (!locationId || locationId === 'all') {
+      if (locationId === 'all') {
         logRouteError(
           functionName,
           'GET',
@@ -73,7 +76,21 @@ export async function GET(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Fetch manufacturer performance data
+      // STEP 2: Check access to the requested location
+      // ============================================================================
+      if (!(await checkUserLocationAccess(locationId))) {
+        logRouteError(
+          functionName,
+          'GET',
+          '/api/analytics/manufacturer-performance',
+          'Forbidden',
+          user
+        );
+        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+      }
+
+      // ============================================================================
+      // STEP 3: Fetch manufacturer performance data
       // ============================================================================
       const result = await getManufacturerPerformance(
         locationId,
@@ -84,7 +101,7 @@ export async function GET(request: NextRequest) {
       );
 
       // ============================================================================
-      // STEP 3: Return manufacturer performance
+      // STEP 4: Return manufacturer performance
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteFetch(
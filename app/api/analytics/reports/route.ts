@@ -11,9 +11,12 @@
  */
 
 import type { ReportConfig } from '@/shared/types/reports';
-import { generateReportData } from '@/app/api/lib/helpers/reports/general';
+import {
+  generateReportData,
+  reportConfigSchema,
+} from '@/app/api/lib/helpers/reports/general';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import { z } from 'zod';
+import type { z } from 'zod';
 import { NextRequest, NextResponse } from 'next/server';
 import {
   logRouteCreate,
@@ -21,28 +24,6 @@ import {
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
 
-/**
- * Zod schema for report configuration validation
- */
-const reportConfigSchema = z.object({
-  title: z.string(),
-  reportType: z.enum([
-    'locationPerformance',
-    'machineRevenue',
-    'fullFinancials',
-  ]),
-  dateRange: z.object({
-    start: z.string().datetime(),
-    end: z.string().datetime(),
-  }),
-  filters: z.object({
-    locationIds: z.array(z.string()).optional(),
-    manufacturers: z.array(z.string()).optional(),
-  }),
-  fields: z.array(z.string()),
-  chartType: z.enum(['bar', 'line', 'table']),
-});
-
 /**
  * Builds report configuration from validated data
  *
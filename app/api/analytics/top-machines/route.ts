@@ -13,7 +13,13 @@
 
 import { getTopMachinesByLocation } from '@/app/api/lib/helpers/reports/topMachines';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
 import { NextRequest, NextResponse } from 'next/server';
+import { locationTopMachinesRoute } from '@/app/api/lib/routeSchemas/analytics';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteError,
@@ -34,8 +40,9 @@ import {
  * Flow:
  * 1. Parse and validate request parameters
  * 2. Connect to database
- * 3. Fetch top machines data
- * 4. Return top machines
+ * 3. Check access to the requested location
+ * 4. Fetch top machines data
+ * 5. Return top machines
  */
 export async function GET(request: NextRequest) {
   const startTime = Date.now();
@@ -47,28 +54,26 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      const timePeriod = searchParams.get('timePeriod') || '24h';
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
+      const query = parseQuery(locationTopMachinesRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { locationId, timePeriod, startDate, endDate } = query.data;
 
-      if (!locationId) {
+      // ============================================================================
+      // STEP 2: Check access to the requested location
+      // ============================================================================
+      if (!(await checkUserLocationAccess(locationId))) {
         logRouteError(
           functionName,
           'GET',
           '/api/analytics/top-machines',
-          'Location ID is required',
+          'Forbidden',
           user
         );
-        return NextResponse.json(
-          { error: 'Location ID is required' },
-          { status: 400 }
-        );
+        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
       }
 
       // ============================================================================
-      // STEP 2: Fetch top machines data
+      // STEP 3: Fetch top machines data
       // ============================================================================
       const topMachines = await getTopMachinesByLocation(
         locationId,
@@ -78,7 +83,7 @@ export async function GET(request: NextRequest) {
       );
 
       // ============================================================================
-      // STEP 3: Return top machines
+      // STEP 4: Return top machines
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteFetch(
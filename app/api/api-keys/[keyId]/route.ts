@@ -0,0 +1,86 @@
+/**
+ * API Key API Route
+ *
+ * Revokes an API key (see app/api/lib/helpers/apiKeys.ts). Restricted to
+ * admin and developer roles.
+ *
+ * @module app/api/api-keys/[keyId]/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { revokeApiKey } from '@/app/api/lib/helpers/apiKeys';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * DELETE /api/api-keys/[keyId]
+ *
+ * Revokes the key (by id or name). The record is kept, with `revokedAt`
+ * and `revokedBy`; requests with the key fail with 401 from then on.
+ */
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ keyId: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'DELETE /api/api-keys/[keyId]';
+  const user = extractUserFromRequest(request);
+  const { keyId } = await params;
+  const path = `/api/api-keys/${keyId}`;
+
+  return withApiAuth(request, async ({ user: userPayload, isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(functionName, 'DELETE', path, 'Forbidden', user);
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      const apiKey = await revokeApiKey(keyId, String(userPayload._id));
+
+      try {
+        await logActivity({
+          action: 'DELETE',
+          details: `Revoked API key ${apiKey.name}`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'apiKey',
+            resourceId: apiKey._id,
+            resourceName: apiKey.name,
+            changes: [],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      logRouteFetch(
+        functionName,
+        'DELETE',
+        path,
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: apiKey });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(functionName, 'DELETE', path, errorMessage, user);
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
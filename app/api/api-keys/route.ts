@@ -0,0 +1,175 @@
+/**
+ * API Keys API Route
+ *
+ * Lists and creates API keys for machine-to-machine clients (see
+ * app/api/lib/helpers/apiKeys.ts). Keys are stored hashed; the key is
+ * returned once, by POST. Restricted to admin and developer roles, and never
+ * reachable with an API key.
+ *
+ * @module app/api/api-keys/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { createApiKey, listApiKeys } from '@/app/api/lib/helpers/apiKeys';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { createApiKeyRoute } from '@/app/api/lib/routeSchemas/apiKeys';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/api-keys
+ *
+ * Returns every key, newest first, revoked ones included (without hashes).
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/api-keys';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(functionName, 'GET', '/api/api-keys', 'Forbidden', user);
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      const keys = await listApiKeys();
+
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/api-keys',
+        keys.length,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: keys });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(functionName, 'GET', '/api/api-keys', errorMessage, user);
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * POST /api/api-keys
+ *
+ * @body {string}   name        Required. Letters, digits, `-` or `_` (max 64); unique.
+ * @body {string[]} licencees   Required. Licencee IDs the key can see.
+ * @body {string[]} scopes      Required. Endpoint groups (`machines`, `locations`, `reports`, `collections`, `members`, `vault`, `licencees`), each optionally `:read` for GET only.
+ * @body {object}   quota       Optional. `requestsPerMinute`, `burst`, `concurrentAggregations` overriding the default API quota.
+ * @body {string}   expiresAt   Optional. ISO date after which the key is refused.
+ * @body {string}   description Optional.
+ *
+ * Flow:
+ * 1. Parse request body
+ * 2. Create the key
+ * 3. Log activity
+ * 4. Return the key (shown only this once)
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/api-keys';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      logRouteError(functionName, 'POST', '/api/api-keys', 'Forbidden', user);
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      // ============================================================================
+      // STEP 1: Parse request body
+      // ============================================================================
+      const validation = parseBody(
+        createApiKeyRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+      const expiresAt = body.expiresAt ? new Date(body.expiresAt) : null;
+
+      // ============================================================================
+      // STEP 2: Create the key
+      // ============================================================================
+      const { apiKey, key } = await createApiKey({
+        name: body.name,
+        licencees: body.licencees,
+        scopes: body.scopes,
+        quota: body.quota,
+        description: body.description,
+        expiresAt,
+        createdBy: String(userPayload._id),
+      });
+
+      // ============================================================================
+      // STEP 3: Log activity
+      // ============================================================================
+      try {
+        await logActivity({
+          action: 'CREATE',
+          details: `Created API key ${apiKey.name} (${apiKey.scopes.join(', ')})`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'apiKey',
+            resourceId: apiKey._id,
+            resourceName: apiKey.name,
+            changes: [],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      // ============================================================================
+      // STEP 4: Return the key (shown only this once)
+      // ============================================================================
+      logRouteFetch(
+        functionName,
+        'POST',
+        '/api/api-keys',
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json(
+        { success: true, data: apiKey, key },
+        { status: 201 }
+      );
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(functionName, 'POST', '/api/api-keys', errorMessage, user);
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -23,6 +23,11 @@ import {
   DEFAULT_BILL_VALIDATOR_OPTIONS,
   type BillDocument,
 } from '@/app/api/lib/helpers/billValidator/validatorOperations';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { billValidatorRoute } from '@/app/api/lib/routeSchemas/machines';
 import {
   logRouteFetch,
   logRouteError,
@@ -96,10 +101,13 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       // STEP 3: Parse query parameters
       // ============================================================================
-      const searchParams = req.nextUrl.searchParams;
-      let timePeriod = (searchParams.get('timePeriod') as TimePeriod) || '7d';
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
+      const query = parseQuery(billValidatorRoute, req);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      let timePeriod: TimePeriod = query.data.timePeriod;
+      const startDate = query.data.startDate ?? null;
+      const endDate = query.data.endDate ?? null;
 
       // ============================================================================
       // STEP 4: Get machine and location data
@@ -126,7 +134,7 @@ export async function GET(req: NextRequest) {
         );
 
       if (isOnlyTechnician) {
-        timePeriod = 'LastHour' as TimePeriod;
+        timePeriod = 'LastHour';
       }
 
       // ============================================================================
@@ -17,18 +17,21 @@ import {
   detectChartDataSpan,
   resolveChartGranularity,
   buildChartAggregationPipeline,
+  composeChartBuckets,
   resolveChartNativeCurrency,
   convertChartBuckets,
   transformChartBuckets,
   type ChartBucket,
   type DataSpanResult,
 } from '@/app/api/lib/helpers/cabinets/chartOperations';
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import { DEFAULT_FINANCIAL_FORMULA } from '@/app/api/lib/utils/financialFormulas';
 import type { CurrencyCode } from '@/shared/types/currency';
-import type { GamingMachine } from '@shared/types';
+import type { GamingMachine, MovementTotals } from '@shared/types';
 import type { LocationDocument } from '@/lib/types/common';
 import {
   logRouteFetch,
@@ -195,7 +198,17 @@ export async function GET(request: NextRequest) {
       machineId, startDate, endDate, granularityConfig, dateField
     );
 
-    const chartData = (await Meters.aggregate(pipeline)) as ChartBucket[];
+    const bucketTotals = (await Meters.aggregate(pipeline)) as Array<
+      ChartBucket & MovementTotals
+    >;
+    const locationId = String(machine.gamingLocation || '');
+    const formulas = await getLocationFinancialFormulas(
+      locationId ? [locationId] : []
+    );
+    const chartData = composeChartBuckets(
+      bucketTotals,
+      formulas.get(locationId) || DEFAULT_FINANCIAL_FORMULA
+    );
     console.log(`[Cabinet Chart] Meters aggregation returned ${chartData.length} bucket(s)`);
 
     // ============================================================================
@@ -17,9 +17,14 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import { resolveMeterMatch, type MatchMode } from '@/app/api/lib/helpers/metersSearch';
+import { resolveMeterMatch } from '@/app/api/lib/helpers/metersSearch';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Meters } from '@/app/api/lib/models/meters';
+import { cabinetMetersRoute } from '@/app/api/lib/routeSchemas/cabinets';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import { NextRequest, NextResponse } from 'next/server';
 
 function escapeCsv(value: unknown): string {
@@ -54,10 +59,9 @@ const PREFERRED_COL_ORDER = [
 
 function exportCsv(
   allMeters: Record<string, unknown>[],
-  searchParams: URLSearchParams,
+  columnsParam: string | undefined,
   cabinetId: string
 ): NextResponse {
-  const columnsParam = searchParams.get('columns');
   const columns = columnsParam ? columnsParam.split(',') : PREFERRED_COL_ORDER;
 
   const csvRows: string[] = [];
@@ -119,20 +123,22 @@ export async function GET(
       return NextResponse.json({ success: false, error: 'cabinetId required' }, { status: 400 });
     }
 
-    const { searchParams } = new URL(req.url);
-    const startDateParam = searchParams.get('startDate');
-    const endDateParam = searchParams.get('endDate');
-    const search = (searchParams.get('search') || '').trim();
-    const searchColumn = (searchParams.get('searchColumn') || '').trim();
-    const matchOrdinal = Math.max(0, parseInt(searchParams.get('matchOrdinal') || '0'));
-    const matchModeParam = searchParams.get('matchMode');
-    const matchMode: MatchMode = matchModeParam === 'exact' ? 'exact' : 'contains';
-    const requestedApiPage = Math.max(1, parseInt(searchParams.get('apiPage') || '1'));
-
-    const isExport = searchParams.get('export') === 'true';
-    const exportFormat = (searchParams.get('format') || 'csv') as 'csv' | 'json';
-
-    const dateField = searchParams.get('dateField') || 'readAt';
+    const query = parseQuery(cabinetMetersRoute, req);
+    if (!query.success) {
+      return validationErrorResponse(query.error);
+    }
+    const {
+      startDate: startDateParam,
+      endDate: endDateParam,
+      matchOrdinal,
+      matchMode,
+      apiPage: requestedApiPage,
+      format: exportFormat,
+      dateField,
+    } = query.data;
+    const search = (query.data.search || '').trim();
+    const searchColumn = (query.data.searchColumn || '').trim();
+    const isExport = query.data.export === 'true';
     const dateFilter: Record<string, unknown> = {};
     if (startDateParam || endDateParam) {
       dateFilter[dateField] = {};
@@ -158,7 +164,7 @@ export async function GET(
 
       return exportFormat === 'json'
         ? NextResponse.json({ success: true, total: allMeters.length, data: allMeters })
-        : exportCsv(allMeters, searchParams, cabinetId);
+        : exportCsv(allMeters, query.data.columns, cabinetId);
     }
 
     const BATCH_SIZE = 100;
@@ -0,0 +1,237 @@
+/**
+ * Cabinet Reconfigurations API Route
+ *
+ * Game / denomination change history of a cabinet (see
+ * app/api/lib/helpers/machineReconfiguration.ts):
+ * - GET: the cabinet's meters split at each recorded change, or a
+ *   before/after comparison of one change
+ * - POST: record a change (admin/developer only)
+ *
+ * @module app/api/cabinets/[cabinetId]/reconfigurations/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  compareReconfiguration,
+  getReconfigurationSegments,
+  recordMachineReconfiguration,
+} from '@/app/api/lib/helpers/machineReconfiguration';
+import { Machine } from '@/app/api/lib/models/machines';
+import {
+  getReconfigurationsRoute,
+  recordReconfigurationRoute,
+} from '@/app/api/lib/routeSchemas/cabinets';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  extractUserFromRequest,
+  logRouteCreate,
+  logRouteError,
+  logRouteFetch,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * Resolves the cabinet's location and checks the user may see it.
+ *
+ * @returns An error response, or null when access is allowed
+ */
+async function checkCabinetAccess(
+  cabinetId: string
+): Promise<NextResponse | null> {
+  const machine = await Machine.findOne(
+    { _id: cabinetId },
+    { gamingLocation: 1 }
+  ).lean<{ gamingLocation?: string }>();
+  if (!machine) {
+    return NextResponse.json(
+      { success: false, error: 'Cabinet not found' },
+      { status: 404 }
+    );
+  }
+  if (
+    machine.gamingLocation &&
+    !(await checkUserLocationAccess(machine.gamingLocation))
+  ) {
+    return NextResponse.json(
+      { success: false, error: 'Forbidden' },
+      { status: 403 }
+    );
+  }
+  return null;
+}
+
+function parseDateParam(value: string | undefined): Date | undefined {
+  if (!value) return undefined;
+  const date = new Date(value);
+  return Number.isNaN(date.getTime()) ? undefined : date;
+}
+
+/**
+ * GET /api/cabinets/[cabinetId]/reconfigurations
+ *
+ * Query params:
+ * @param compare    {'true'} Optional. Return a before/after comparison instead of segments.
+ * @param event      {string} Optional. configurationHistory entry to compare (default: latest).
+ * @param windowDays {number} Optional. Days on each side of the change (default 30).
+ * @param from       {string} Optional. ISO start of the segment report.
+ * @param to         {string} Optional. ISO end of the segment report (default: now).
+ */
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ cabinetId: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/cabinets/[cabinetId]/reconfigurations';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    try {
+      // ============================================================================
+      // STEP 1: Resolve cabinet and check access
+      // ============================================================================
+      const { cabinetId } = await params;
+      const denied = await checkCabinetAccess(cabinetId);
+      if (denied) return denied;
+
+      // ============================================================================
+      // STEP 2: Build the report
+      // ============================================================================
+      const query = parseQuery(getReconfigurationsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { compare, event, windowDays, from, to } = query.data;
+      const data =
+        compare === 'true'
+          ? await compareReconfiguration(cabinetId, event, windowDays)
+          : await getReconfigurationSegments(cabinetId, {
+              from: parseDateParam(from),
+              to: parseDateParam(to),
+            });
+
+      // ============================================================================
+      // STEP 3: Return
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/cabinets/[cabinetId]/reconfigurations',
+        'segments' in data ? data.segments.length : 2,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Slow request: ${duration}ms`);
+      }
+      return NextResponse.json({ success: true, data });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/cabinets/[cabinetId]/reconfigurations',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
+
+/**
+ * POST /api/cabinets/[cabinetId]/reconfigurations
+ *
+ * Body fields:
+ * @param values    {object} Required. New values by field: game, gameType,
+ *                  accountingDenomination, payTableId, theoreticalRtp, maxBet.
+ * @param changedAt {string} Optional. ISO time the change took effect (default: now).
+ * @param reason    {string} Required. Why the machine was reconfigured.
+ */
+export async function POST(
+  request: NextRequest,
+  { params }: { params: Promise<{ cabinetId: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/cabinets/[cabinetId]/reconfigurations';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      // ============================================================================
+      // STEP 1: Resolve cabinet and check access
+      // ============================================================================
+      const { cabinetId } = await params;
+      const denied = await checkCabinetAccess(cabinetId);
+      if (denied) return denied;
+
+      // ============================================================================
+      // STEP 2: Record the change
+      // ============================================================================
+      const validation = parseBody(
+        recordReconfigurationRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+      const changedAt = body.changedAt ? new Date(body.changedAt) : undefined;
+      const entry = await recordMachineReconfiguration({
+        machineId: cabinetId,
+        values: body.values,
+        changedAt,
+        reason: body.reason,
+        userId: String(userPayload._id),
+        username: String(
+          userPayload.username || userPayload.emailAddress || userPayload._id
+        ),
+      });
+
+      // ============================================================================
+      // STEP 3: Return
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteCreate(
+        functionName,
+        'POST',
+        '/api/cabinets/[cabinetId]/reconfigurations',
+        1,
+        user,
+        duration
+      );
+      return NextResponse.json({ success: true, data: entry }, { status: 201 });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/cabinets/[cabinetId]/reconfigurations',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -17,6 +17,17 @@ import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Machine } from '@/app/api/lib/models/machines';
+import {
+  deleteCabinetRoute,
+  getCabinetRoute,
+  patchCabinetRoute,
+  updateCabinetRoute,
+} from '@/app/api/lib/routeSchemas/cabinets';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteUpdate,
@@ -35,8 +46,6 @@ import {
   performCabinetUpdate,
 } from '@/app/api/lib/helpers/cabinets/cabinetDetailOperations';
 import type { MachineDocument } from '@/lib/types/common';
-import type { CurrencyCode } from '@/shared/types/currency';
-import type { TimePeriod } from '@/shared/types/common';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
@@ -59,13 +68,17 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
     try {
       await connectDB();
-      const { searchParams } = new URL(request.url);
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
-      const timePeriod = searchParams.get('timePeriod') as TimePeriod;
-      const dateField = searchParams.get('dateField') || 'readAt';
-      const displayCurrency =
-        (searchParams.get('currency') as CurrencyCode) || 'USD';
+      const query = parseQuery(getCabinetRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const {
+        startDate: startDateParam,
+        endDate: endDateParam,
+        timePeriod,
+        dateField,
+        currency: displayCurrency,
+      } = query.data;
 
       // ============================================================================
       // STEP 1: Fetch and Validate Machine
@@ -126,6 +139,7 @@ export async function GET(request: NextRequest) {
         aceEnabled,
         licenceeId,
         includeJackpot,
+        financialFormula,
       } = await fetchLocationSettings(locationId);
 
       // ============================================================================
@@ -133,11 +147,11 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       const metrics = await aggregateCabinetMetrics(
         cabinetId,
-        timePeriod,
-        startDateParam,
-        endDateParam,
+        timePeriod ?? null,
+        startDateParam ?? null,
+        endDateParam ?? null,
         gameDayOffset,
-        includeJackpot,
+        financialFormula,
         userPayload as {
           moneyInMultiplier?: number | null;
           moneyOutAndJackpotMultiplier?: number | null;
@@ -213,7 +227,14 @@ export async function PUT(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       await connectDB();
-      const data = await request.json();
+      const validation = parseBody(
+        updateCabinetRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const data = validation.data;
 
       // ============================================================================
       // STEP 1: Perform Full Cabinet Update
@@ -277,7 +298,14 @@ export async function PATCH(request: NextRequest) {
   return withApiAuth(request, async ({ user: currentUser }) => {
     try {
       await connectDB();
-      const data = await request.json();
+      const validation = parseBody(
+        patchCabinetRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const data = validation.data;
 
       // ============================================================================
       // STEP 1: Route Action
@@ -383,8 +411,11 @@ export async function DELETE(request: NextRequest) {
   const user = extractUserFromRequest(request);
   const { pathname } = request.nextUrl;
   const cabinetId = pathname.split('/').pop() || '';
-  const searchParams = request.nextUrl.searchParams;
-  const hardDelete = searchParams.get('hardDelete') === 'true';
+  const query = parseQuery(deleteCabinetRoute, request);
+  if (!query.success) {
+    return validationErrorResponse(query.error);
+  }
+  const hardDelete = query.data.hardDelete === 'true';
 
   return withApiAuth(request, async ({ user: currentUser, userRoles }) => {
     try {
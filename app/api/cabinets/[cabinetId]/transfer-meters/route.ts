@@ -21,6 +21,15 @@ import {
 import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Machine } from '@/app/api/lib/models/machines';
+import {
+  transferMetersRoute,
+  transferMetersStatsRoute,
+} from '@/app/api/lib/routeSchemas/cabinets';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   extractUserFromRequest,
   logRouteError,
@@ -93,9 +102,11 @@ export async function GET(
         );
       }
 
-      const { searchParams } = new URL(request.url);
-      const fromDateTime = searchParams.get('fromDateTime') ?? undefined;
-      const toDateTime = searchParams.get('toDateTime') ?? undefined;
+      const query = parseQuery(transferMetersStatsRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const { fromDateTime, toDateTime } = query.data;
 
       const result = await getTransferMetersStats(
         cabinetId,
@@ -168,28 +179,22 @@ export async function POST(
         );
       }
 
-      const body = await request.json();
-      const fromDateTime =
-        typeof body.fromDateTime === 'string' ? body.fromDateTime : '';
-      const toDateTime =
-        typeof body.toDateTime === 'string' ? body.toDateTime : '';
-      if (!fromDateTime || !toDateTime) {
-        return NextResponse.json(
-          { success: false, error: 'fromDateTime and toDateTime are required' },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        transferMetersRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
-
-      const batchSize =
-        typeof body.batchSize === 'number' ? body.batchSize : undefined;
-      const concurrency =
-        typeof body.concurrency === 'number' ? body.concurrency : undefined;
-      const activityTotal =
-        typeof body.activityTotal === 'number' ? body.activityTotal : undefined;
-      const logActivity =
-        typeof body.logActivity === 'boolean' ? body.logActivity : undefined;
-      const cursor =
-        typeof body.cursor === 'string' ? body.cursor : undefined;
+      const {
+        fromDateTime,
+        toDateTime,
+        batchSize,
+        concurrency,
+        activityTotal,
+        logActivity,
+        cursor,
+      } = validation.data;
 
       await connectDB();
 
@@ -19,6 +19,10 @@ import {
   getUserAccessibleLicenceesFromToken,
   getUserLocationFilter,
 } from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  getLicenceeFinancialFormulas,
+  getLicenceeMachineStatus,
+} from '@/app/api/lib/helpers/licencees';
 import {
   buildBatchMetersPipeline,
   buildLocationRangeInputs,
@@ -27,6 +31,7 @@ import {
   buildPerLocationMetersPipeline,
   getDefaultMetrics,
   parseCabinetAggregationParams,
+  readMachineMetrics,
   refineOfflineStatus,
   applyReviewerScale,
   sortCabinetMachines,
@@ -42,6 +47,18 @@ import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import { cabinetAggregationRoute } from '@/app/api/lib/routeSchemas/cabinets';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { addMovementTotals } from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
+import { restrictLocationsToTenant } from '@/app/api/lib/utils/tenantScope';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import { convertFromUSD } from '@/lib/helpers/rates';
 import type { LocationDocument } from '@/lib/types/common';
@@ -69,6 +86,8 @@ import {
  * @param {string} locationId - Comma-separated location IDs to filter by
  * @param {string} gameType - Comma-separated game types to filter by
  * @param {string} search - Search query for serial, name, or SMIB
+ * @param {string} smibId - Partial SMIB id (relayId or smibBoard), case-insensitive
+ * @param {string} customName - Partial custom machine name, case-insensitive
  * @param {string} licencee - Filter machines by licencee name
  * @param {string} timePeriod - Time range preset ('Today', 'Yesterday', '7d', '30d', 'Custom', 'LastHour')
  * @param {string} currency - Target currency code for values (e.g., 'USD', 'GYD')
@@ -82,18 +101,17 @@ import {
  * @param {boolean} debug - Enabled detailed metadata in response
  *
  * Flow:
- * 1. Parse query parameters
- * 2. Validate timePeriod parameter
- * 3. Get user's accessible licencees and permissions
- * 4. Technician restriction
- * 5. Fetch locations with gameDayOffset
- * 6. Calculate gaming day ranges per location
- * 7. Aggregate machine metrics (optimized for 30d/7d vs Today/Yesterday)
- * 8. Refine offline status
- * 9. Apply currency conversion if needed
- * 10. Apply reviewer scale
- * 11. Sort and paginate
- * 12. Return aggregated machine data
+ * 1. Parse and validate query parameters
+ * 2. Get user's accessible licencees and permissions
+ * 3. Technician restriction
+ * 4. Fetch locations with gameDayOffset
+ * 5. Calculate gaming day ranges per location
+ * 6. Aggregate machine metrics (optimized for 30d/7d vs Today/Yesterday)
+ * 7. Refine offline status
+ * 8. Apply currency conversion if needed
+ * 9. Apply reviewer scale
+ * 10. Sort and paginate
+ * 11. Return aggregated machine data
  */
 export async function GET(req: NextRequest) {
   return withApiAuth(
@@ -104,8 +122,19 @@ export async function GET(req: NextRequest) {
       const user = extractUserFromRequest(req);
 
       // ============================================================================
-      // STEP 1: Parse query parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
+      const query = parseQuery(cabinetAggregationRoute, req);
+      if (!query.success) {
+        logRouteError(
+          functionName,
+          'GET',
+          '/api/cabinets/aggregation',
+          'Invalid query parameters',
+          user
+        );
+        return validationErrorResponse(query.error);
+      }
       const { searchParams } = new URL(req.url);
       const params = parseCabinetAggregationParams(searchParams);
       let { timePeriod } = params;
@@ -113,6 +142,8 @@ export async function GET(req: NextRequest) {
         locationIdArray,
         selectedGameTypes,
         searchTerm,
+        smibId,
+        customName,
         licencee,
         displayCurrency,
         onlineStatus,
@@ -126,24 +157,7 @@ export async function GET(req: NextRequest) {
       } = params;
 
       // ============================================================================
-      // STEP 2: Validate timePeriod parameter
-      // ============================================================================
-      if (!timePeriod) {
-        logRouteError(
-          functionName,
-          'GET',
-          '/api/cabinets/aggregation',
-          'timePeriod parameter is required',
-          user
-        );
-        return NextResponse.json(
-          { error: 'timePeriod parameter is required' },
-          { status: 400 }
-        );
-      }
-
-      // ============================================================================
-      // STEP 3: Get user's accessible licencees and permissions
+      // STEP 2: Get user's accessible licencees and permissions
       // ============================================================================
       const userAccessibleLicencees =
         await getUserAccessibleLicenceesFromToken();
@@ -170,11 +184,12 @@ export async function GET(req: NextRequest) {
         .map(r => r?.toLowerCase?.() ?? r)
         .some(r => r === 'admin' || r === 'developer' || r === 'owner');
       if (isAdmin && locationIdArray.length > 0) {
-        allowedLocationIds = 'all';
+        // Still narrowed to the pinned licencee in tenant isolation mode
+        allowedLocationIds = await restrictLocationsToTenant('all');
       }
 
       // ============================================================================
-      // STEP 4: Technician Restriction - Force last hour meter data
+      // STEP 3: Technician Restriction - Force last hour meter data
       // ============================================================================
       const userRolesLower = userRoles.map(
         r => r?.toLowerCase?.() ?? String(r).toLowerCase()
@@ -209,7 +224,7 @@ export async function GET(req: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 5: Fetch locations with gameDayOffset
+      // STEP 4: Fetch locations with gameDayOffset
       // ============================================================================
       let timePeriodForGamingDay: string;
       let customStartDateForGamingDay: Date | undefined;
@@ -239,22 +254,12 @@ export async function GET(req: NextRequest) {
 
       const isArchivedRequested = onlineStatus === 'archived';
       const deletedFilter: Record<string, unknown> = isArchivedRequested
-        ? { deletedAt: { $gte: new Date('2025-01-01') } }
-        : {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          };
+        ? { ...DELETED_FILTER }
+        : { ...NOT_DELETED_FILTER };
 
       const matchStage: MachineAggregationMatchStage = isArchivedRequested
         ? {}
-        : {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          };
+        : { ...NOT_DELETED_FILTER };
 
       if (locationIdArray.length > 0) {
         const filteredLocationIds = locationIdArray.filter(locId => {
@@ -296,31 +301,29 @@ export async function GET(req: NextRequest) {
           )
         )
       );
-      const licencees = await Licencee.find({ _id: { $in: licenceeIds } }).lean<
-        LicenceeDocument[]
-      >();
-      const licenceeIncludeJackpotMap = new Map(
-        licencees.map(licenceeDoc => [
-          String(licenceeDoc._id),
-          !!licenceeDoc.includeJackpot,
-        ])
+      const licenceeFormulas = await getLicenceeFinancialFormulas(
+        licenceeIds.map(String)
       );
 
       // ============================================================================
-      // STEP 6: Calculate gaming day ranges per location
+      // STEP 5: Calculate gaming day ranges per location
       // ============================================================================
       const gamingDayRanges = getGamingDayRangesForLocations(
-        buildLocationRangeInputs(locations, licenceeIncludeJackpotMap),
+        buildLocationRangeInputs(locations, licenceeFormulas),
         timePeriodForGamingDay,
         customStartDateForGamingDay,
         customEndDateForGamingDay
       );
 
       // ============================================================================
-      // STEP 7: Aggregate machine metrics
+      // STEP 6: Aggregate machine metrics
       // ============================================================================
       let allMachines: CabinetMachineResponse[] = [];
       const useSingleAggregation = timePeriod === '30d' || timePeriod === '7d';
+      // Licencee's online threshold when scoped to one, else the deployment's
+      const onlineCutoff = getOnlineCutoff(
+        await getLicenceeMachineStatus(licencee)
+      );
 
       if (useSingleAggregation) {
         const allLocationIds = locations.map(loc => String(loc._id));
@@ -328,7 +331,15 @@ export async function GET(req: NextRequest) {
         const machineMatchQuery = buildMachineMatchQuery(
           allLocationIds,
           deletedFilter,
-          { searchTerm, selectedGameTypes, onlineStatus, smibStatus },
+          {
+            searchTerm,
+            smibId,
+            customName,
+            selectedGameTypes,
+            onlineStatus,
+            smibStatus,
+            onlineCutoff,
+          },
           locations
         );
 
@@ -397,10 +408,7 @@ export async function GET(req: NextRequest) {
 
           const metricsMap = new Map<string, MachineMetrics>();
           allMetrics.forEach(metrics => {
-            metricsMap.set(
-              String(metrics._id),
-              metrics as unknown as MachineMetrics
-            );
+            metricsMap.set(String(metrics._id), readMachineMetrics(metrics));
           });
 
           const locationMap = new Map<string, LocationDocument>();
@@ -422,8 +430,9 @@ export async function GET(req: NextRequest) {
                 machine,
                 metrics,
                 location,
-                licenceeIncludeJackpotMap,
-                timePeriod
+                licenceeFormulas,
+                timePeriod,
+                onlineCutoff
               )
             );
           });
@@ -449,7 +458,15 @@ export async function GET(req: NextRequest) {
           const batchMachineMatchQuery = buildMachineMatchQuery(
             batchLocationIds,
             deletedFilter,
-            { searchTerm, selectedGameTypes, onlineStatus, smibStatus },
+            {
+              searchTerm,
+              smibId,
+              customName,
+              selectedGameTypes,
+              onlineStatus,
+              smibStatus,
+              onlineCutoff,
+            },
             batch
           );
 
@@ -516,18 +533,7 @@ export async function GET(req: NextRequest) {
             const aggRecord = agg as Record<string, unknown>;
 
             if (!gameDayRange || timePeriod === 'All Time') {
-              metricsByMachine.set(machineId, {
-                moneyIn: (aggRecord.moneyIn as number) || 0,
-                moneyOut: (aggRecord.moneyOut as number) || 0,
-                jackpot: (aggRecord.jackpot as number) || 0,
-                coinIn: (aggRecord.coinIn as number) || 0,
-                coinOut: (aggRecord.coinOut as number) || 0,
-                gamesPlayed: (aggRecord.gamesPlayed as number) || 0,
-                gamesWon: (aggRecord.gamesWon as number) || 0,
-                handPaidCancelledCredits:
-                  (aggRecord.handPaidCancelledCredits as number) || 0,
-                meterCount: (aggRecord.meterCount as number) || 0,
-              });
+              metricsByMachine.set(machineId, readMachineMetrics(aggRecord));
             } else {
               const minReadAt = new Date(aggRecord.minReadAt as Date);
               const maxReadAt = new Date(aggRecord.maxReadAt as Date);
@@ -541,23 +547,10 @@ export async function GET(req: NextRequest) {
 
               if (hasValidReadAt) {
                 const existing = metricsByMachine.get(machineId);
-                const newMetrics: MachineMetrics = {
-                  moneyIn: (aggRecord.moneyIn as number) || 0,
-                  moneyOut: (aggRecord.moneyOut as number) || 0,
-                  jackpot: (aggRecord.jackpot as number) || 0,
-                  coinIn: (aggRecord.coinIn as number) || 0,
-                  coinOut: (aggRecord.coinOut as number) || 0,
-                  gamesPlayed: (aggRecord.gamesPlayed as number) || 0,
-                  gamesWon: (aggRecord.gamesWon as number) || 0,
-                  handPaidCancelledCredits:
-                    (aggRecord.handPaidCancelledCredits as number) || 0,
-                  meterCount: (aggRecord.meterCount as number) || 0,
-                };
+                const newMetrics = readMachineMetrics(aggRecord);
 
                 if (existing) {
-                  existing.moneyIn += newMetrics.moneyIn;
-                  existing.moneyOut += newMetrics.moneyOut;
-                  existing.jackpot += newMetrics.jackpot;
+                  addMovementTotals(existing.totals, newMetrics.totals);
                   existing.meterCount += newMetrics.meterCount;
                 } else {
                   metricsByMachine.set(machineId, newMetrics);
@@ -586,8 +579,9 @@ export async function GET(req: NextRequest) {
                   machine,
                   metrics,
                   location,
-                  licenceeIncludeJackpotMap,
-                  timePeriod
+                  licenceeFormulas,
+                  timePeriod,
+                  onlineCutoff
                 )
               );
             });
@@ -596,19 +590,20 @@ export async function GET(req: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 8: Refine offline status and apply filtering
+      // STEP 7: Refine offline status and apply filtering
       // ============================================================================
       allMachines = refineOfflineStatus(
         allMachines,
         onlineStatus,
         timePeriod,
-        gamingDayRanges
+        gamingDayRanges,
+        onlineCutoff
       );
 
       let filteredMachines = allMachines;
 
       // ============================================================================
-      // STEP 9: Apply currency conversion if needed
+      // STEP 8: Apply currency conversion if needed
       // ============================================================================
       if (isAdminOrDev && shouldApplyCurrencyConversion(licencee)) {
         const db = await connectDB();
@@ -620,23 +615,13 @@ export async function GET(req: NextRequest) {
         }
 
         const licenceesData = await Licencee.find(
-          {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          },
-          { _id: 1, name: 1, includeJackpot: 1 }
+          { ...NOT_DELETED_FILTER },
+          { _id: 1, name: 1 }
         ).lean<LicenceeDocument[]>();
 
         const licenceeIdToName = new Map<string, string>();
-        const licenceeIdToIncludeJackpot = new Map<string, boolean>();
         licenceesData.forEach(lic => {
           licenceeIdToName.set(String(lic._id), lic.name as string);
-          licenceeIdToIncludeJackpot.set(
-            String(lic._id),
-            Boolean(lic.includeJackpot)
-          );
         });
 
         const { getCountryCurrency, getLicenceeCurrency, convertToUSD } =
@@ -680,75 +665,35 @@ export async function GET(req: NextRequest) {
             nativeCurrency = getLicenceeCurrency(licenceeName);
           }
 
-          const roundTwo = (v: number) => Math.round(v * 100) / 100;
-
-          if (nativeCurrency === displayCurrency) {
-            const moneyIn = roundTwo(machine.moneyIn);
-            const baseMoneyOut = roundTwo(machine.cancelledCredits);
-            const jackpot = roundTwo(machine.jackpot);
-            const includeJackpot =
-              (machineLicenceeId &&
-                licenceeIdToIncludeJackpot.get(machineLicenceeId.toString())) ||
-              false;
-            const moneyOut = baseMoneyOut + (includeJackpot ? jackpot : 0);
-            const gross = roundTwo(moneyIn - moneyOut);
-            const netGross = roundTwo(moneyIn - baseMoneyOut - jackpot);
-
-            return {
-              ...machine,
-              moneyIn,
-              moneyOut,
-              cancelledCredits: baseMoneyOut,
-              jackpot,
-              gross,
-              netGross,
-              coinIn: roundTwo(machine.coinIn),
-              coinOut: roundTwo(machine.coinOut),
-              includeJackpot,
-            };
-          }
-
-          const moneyInUSD = convertToUSD(machine.moneyIn, nativeCurrency);
-          const cancelledCreditsUSD = convertToUSD(
-            machine.cancelledCredits,
-            nativeCurrency
-          );
-          const jackpotUSD = convertToUSD(machine.jackpot, nativeCurrency);
-          const coinInUSD = convertToUSD(machine.coinIn, nativeCurrency);
-          const coinOutUSD = convertToUSD(machine.coinOut, nativeCurrency);
-
-          const moneyIn = roundTwo(convertFromUSD(moneyInUSD, displayCurrency));
-          const baseMoneyOut = roundTwo(
-            convertFromUSD(cancelledCreditsUSD, displayCurrency)
-          );
-          const jackpot = roundTwo(convertFromUSD(jackpotUSD, displayCurrency));
-          const includeJackpot =
-            (machineLicenceeId &&
-              licenceeIdToIncludeJackpot.get(machineLicenceeId.toString())) ||
-            false;
-          const moneyOut = baseMoneyOut + (includeJackpot ? jackpot : 0);
-          const gross = roundTwo(moneyIn - moneyOut);
-          const netGross = roundTwo(moneyIn - baseMoneyOut - jackpot);
+          // Figures are already composed with the licencee's formula, so each
+          // one converts on its own
+          const convert = (value: number) => {
+            const converted =
+              nativeCurrency === displayCurrency
+                ? value
+                : convertFromUSD(
+                    convertToUSD(value, nativeCurrency),
+                    displayCurrency
+                  );
+            return Math.round(converted * 100) / 100;
+          };
 
           return {
             ...machine,
-            moneyIn,
-            moneyOut,
-            cancelledCredits: roundTwo(
-              convertFromUSD(cancelledCreditsUSD, displayCurrency)
-            ),
-            jackpot,
-            gross,
-            netGross,
-            coinIn: roundTwo(convertFromUSD(coinInUSD, displayCurrency)),
-            coinOut: roundTwo(convertFromUSD(coinOutUSD, displayCurrency)),
-            includeJackpot,
+            moneyIn: convert(machine.moneyIn),
+            moneyOut: convert(machine.moneyOut),
+            cancelledCredits: convert(machine.cancelledCredits),
+            jackpot: convert(machine.jackpot),
+            gross: convert(machine.gross),
+            netGross: convert(machine.netGross),
+            coinIn: convert(machine.coinIn),
+            coinOut: convert(machine.coinOut),
           };
         });
       }
 
       // ============================================================================
-      // STEP 10: Apply reviewer multiplier
+      // STEP 9: Apply reviewer multiplier
       // ============================================================================
       const scaleReferenceDate = customEndDateForGamingDay ?? new Date();
       const moneyInScale = getMoneyInScale(
@@ -774,11 +719,10 @@ export async function GET(req: NextRequest) {
       );
 
       // ============================================================================
-      // STEP 11: Sort and paginate
+      // STEP 10: Sort and paginate
       // ============================================================================
-      const sortBy = searchParams.get('sortBy') || 'moneyIn';
-      const sortOrderRaw = searchParams.get('sortOrder') || 'desc';
-      const sortOrder = sortOrderRaw.toLowerCase() === 'asc' ? 1 : -1;
+      const { sortBy } = query.data;
+      const sortOrder = query.data.sortOrder === 'asc' ? 1 : -1;
 
       sortCabinetMachines(filteredMachines, searchTerm, sortBy, sortOrder);
 
@@ -791,7 +735,7 @@ export async function GET(req: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 12: Return aggregated machine data
+      // STEP 11: Return aggregated machine data
       // ============================================================================
       type DebugInfo = {
         userAccessibleLicencees: string[] | 'all';
@@ -20,6 +20,9 @@
  * 8. Query events with pagination via $facet (data + metadata + filter options)
  * 9. Try alternative machine identifiers if no events found
  * 10. Return events with pagination metadata and filter options
+ *
+ * Each event carries `sasEvent` (name and severity of its SAS exception
+ * code, see app/api/lib/utils/sasCodes) for display.
  */
 
 import { connectDB } from '@/app/api/lib/middleware/db';
@@ -31,6 +34,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { withSasEvents } from '@/app/api/lib/utils/sasCodes';
 
 /**
  * GET /api/cabinets/by-id/events
@@ -415,7 +419,7 @@ export async function GET(request: NextRequest) {
 
     return NextResponse.json({
       success: true,
-      events,
+      events: withSasEvents(events),
       pagination: {
         currentPage: resolvedPage,
         totalPages,
@@ -18,6 +18,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 /**
  * Main GET handler for fetching a machine by ID
@@ -70,10 +71,7 @@ export async function GET(request: NextRequest) {
     // ============================================================================
     const machine = await Machine.findOne({
       _id: id,
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
+      ...NOT_DELETED_FILTER,
     }).select(
       '_id serialNumber game gamingLocation assetStatus cabinetType createdAt updatedAt smibConfig relayId smibBoard'
     );
@@ -24,6 +24,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 /**
  * Main GET handler for fetching locations for machines
@@ -107,26 +108,11 @@ export async function GET(request: NextRequest) {
     const matchStage: MatchStage = {};
 
     // Exclude soft-deleted locations
-    // For membership-only flows, use a stricter cutoff (2025) as requested
-    const deletionCutoff = membershipOnly
-      ? new Date('2025-01-01')
-      : new Date('2025-01-01');
-
-    const basicDeletionFilter = [
-      { deletedAt: null },
-      { deletedAt: { $lt: deletionCutoff } },
-    ];
+    matchStage.deletedAt = NOT_DELETED_FILTER.deletedAt;
 
     if (membershipOnly) {
       // Check both membershipEnabled and enableMembership fields for compatibility
-      matchStage.$and = [
-        { $or: basicDeletionFilter },
-        {
-          $or: [{ membershipEnabled: true }, { enableMembership: true }],
-        },
-      ];
-    } else {
-      matchStage.$or = basicDeletionFilter;
+      matchStage.$or = [{ membershipEnabled: true }, { enableMembership: true }];
     }
 
     // Apply location filter based on user permissions
@@ -2,19 +2,18 @@
  * Machine Online Status — Batch Lookup
  *
  * Accepts a comma-separated list of machine IDs via ?ids=... and returns a map
- * of machineId → boolean (true = online within last 3 minutes).
+ * of machineId → boolean (true = online within the online threshold).
  * Machines with a relayId (SMIB) are online when recently active; WOW machines
  * (no SMIB) are always online. Machines that are neither are omitted.
  */
 
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
 import type { MachineDocument } from '@/shared/types/models';
 import { isWowMachine } from '@/shared/utils/wowMachine';
 import { NextRequest, NextResponse } from 'next/server';
 
-const THREE_MINUTES_MS = 3 * 60 * 1000;
-
 export async function GET(req: NextRequest) {
   // ============================================================================
   // STEP 1: Parse Query Params
@@ -43,7 +42,7 @@ export async function GET(req: NextRequest) {
     return NextResponse.json({}, { status: 500 });
   }
 
-  const threeMinutesAgo = new Date(Date.now() - THREE_MINUTES_MS);
+  const onlineCutoff = getOnlineCutoff();
 
   // ============================================================================
   // STEP 3: Fetch Machines
@@ -75,7 +74,7 @@ export async function GET(req: NextRequest) {
       ? new Date(machine.lastActivity as string | Date)
       : null;
     statusMap[machineId] =
-      lastActivity !== null && lastActivity >= threeMinutesAgo;
+      lastActivity !== null && lastActivity >= onlineCutoff;
   }
 
   return NextResponse.json(statusMap);
@@ -20,6 +20,17 @@ import { revalidatePath } from 'next/cache';
 import { NextRequest, NextResponse } from 'next/server';
 import type { MachinePayload } from '@/shared/types/machines';
 import type { MachineDocument } from '@/lib/types/common';
+import {
+  createCabinetRoute,
+  legacyDeleteCabinetRoute,
+  legacyUpdateCabinetRoute,
+  listCabinetsRoute,
+} from '@/app/api/lib/routeSchemas/cabinets';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -89,24 +100,29 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse Query Params
       // ============================================================================
-      const { searchParams } = request.nextUrl;
-      const id = searchParams.get('id');
-      const locationId = searchParams.get('locationId');
-      const serialNumberToCheck = searchParams.get('checkSerial');
-      const smibToCheck = searchParams.get('checkSmib');
-      const customNameToCheck = searchParams.get('checkCustomName');
-      const excludeId = searchParams.get('excludeId');
-      const showArchived = searchParams.get('archived') === 'true';
+      const query = parseQuery(listCabinetsRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const {
+        id,
+        locationId,
+        checkSerial,
+        checkSmib,
+        checkCustomName,
+        excludeId,
+      } = query.data;
+      const showArchived = query.data.archived === 'true';
 
       // ============================================================================
       // STEP 2: Availability Check
       // ============================================================================
-      if (serialNumberToCheck || smibToCheck || customNameToCheck) {
+      if (checkSerial || checkSmib || checkCustomName) {
         const available = await checkCabinetAvailability(
-          serialNumberToCheck,
-          smibToCheck,
-          customNameToCheck,
-          excludeId
+          checkSerial ?? null,
+          checkSmib ?? null,
+          checkCustomName ?? null,
+          excludeId ?? null
         );
         logRouteFetch(
           functionName,
@@ -216,23 +232,23 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse Body and Validate
       // ============================================================================
-      const data = (await request.json()) as MachinePayload;
-      if (!data.gamingLocation && data.locationId)
-        data.gamingLocation = data.locationId;
-
-      if (!data.gamingLocation) {
+      const validation = parseBody(
+        createCabinetRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/cabinets',
-          'Location required',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Location required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const data = validation.data as MachinePayload;
+      if (!data.gamingLocation && data.locationId)
+        data.gamingLocation = data.locationId;
 
       // ============================================================================
       // STEP 2: Create Cabinet
@@ -280,14 +296,12 @@ export async function PUT(request: NextRequest) {
   const startTime = Date.now();
   const user = extractUserFromRequest(request);
 
-  const id = request.nextUrl.searchParams.get('id');
-  if (!id) {
+  const query = parseQuery(legacyUpdateCabinetRoute, request);
+  if (!query.success) {
     logRouteError(functionName, 'PUT', '/api/cabinets', 'ID required', user);
-    return NextResponse.json(
-      { success: false, error: 'ID required' },
-      { status: 400 }
-    );
+    return validationErrorResponse(query.error);
   }
+  const { id } = query.data;
 
   return withApiAuth(request, async () => {
     try {
@@ -312,10 +326,16 @@ export async function PUT(request: NextRequest) {
       // ============================================================================
       // STEP 2: Update Cabinet
       // ============================================================================
-      const data = await request.json();
+      const validation = parseBody(
+        legacyUpdateCabinetRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
       const updated = await Machine.findOneAndUpdate(
         { _id: id },
-        { $set: { ...data, updatedAt: new Date() } },
+        { $set: { ...validation.data, updatedAt: new Date() } },
         { new: true }
       );
       revalidatePath('/cabinets');
@@ -342,14 +362,12 @@ export async function DELETE(request: NextRequest) {
   const startTime = Date.now();
   const user = extractUserFromRequest(request);
 
-  const id = request.nextUrl.searchParams.get('id');
-  if (!id) {
+  const query = parseQuery(legacyDeleteCabinetRoute, request);
+  if (!query.success) {
     logRouteError(functionName, 'DELETE', '/api/cabinets', 'ID required', user);
-    return NextResponse.json(
-      { success: false, error: 'ID required' },
-      { status: 400 }
-    );
+    return validationErrorResponse(query.error);
   }
+  const { id } = query.data;
 
   return withApiAuth(request, async () => {
     try {
@@ -5,7 +5,7 @@
  * It supports:
  * - Filtering by licencee
  * - Role-based location filtering
- * - Online/offline counts based on lastActivity (3 minute threshold)
+ * - Online/offline counts based on lastActivity (see utils/machineStatus)
  * - Admin/Developer: all machines for selected licencee
  * - Other roles: only machines for assigned locations
  *
@@ -16,6 +16,7 @@ import {
   getUserAccessibleLicenceesFromToken,
   getUserLocationFilter,
 } from '@/app/api/lib/helpers/licenceeFilter';
+import { getLicenceeMachineStatus } from '@/app/api/lib/helpers/licencees';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
@@ -26,6 +27,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
 import {
   createBasePipeline,
   addSearchFilter,
@@ -37,6 +39,7 @@ import {
   runStatusAndLocationCounts,
   buildLocationCountFilter,
 } from '@/app/api/lib/helpers/cabinets/statusOperations';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 /**
  * Main GET handler for fetching machine status
@@ -74,7 +77,6 @@ export async function GET(req: NextRequest) {
     const search = searchParams.get('search')?.trim();
     const onlineStatus = searchParams.get('onlineStatus');
     const gameType = searchParams.get('gameType');
-    const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
 
     // ============================================================================
     // STEP 2: Connect to database
@@ -165,10 +167,7 @@ export async function GET(req: NextRequest) {
     ) {
       const wowLocs = await Machine.distinct('gamingLocation', {
         'meta.dataSync.source': 'wow',
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
+        ...NOT_DELETED_FILTER,
       });
       wowLocationIds = wowLocs.map(id => String(id));
       if (wowLocationIds.length === 0) {
@@ -189,8 +188,11 @@ export async function GET(req: NextRequest) {
     // Apply machine type filters (non-WOW conditions)
     addMachineTypeFilter(aggregationPipeline, machineTypeFilter);
 
-    // Apply online/offline status filter
-    addOnlineStatusFilter(aggregationPipeline, onlineStatus, threeMinutesAgo);
+    // Apply online/offline status filter (licencee threshold when scoped)
+    const onlineCutoff = getOnlineCutoff(
+      await getLicenceeMachineStatus(effectiveLicencee)
+    );
+    addOnlineStatusFilter(aggregationPipeline, onlineStatus, onlineCutoff);
 
     // Apply game type filter
     addGameTypeFilter(aggregationPipeline, gameType);
@@ -201,7 +203,7 @@ export async function GET(req: NextRequest) {
 
     const counts = await runStatusAndLocationCounts(
       aggregationPipeline,
-      threeMinutesAgo,
+      onlineCutoff,
       fourHoursAgo,
       twentyFourHoursAgo
     );
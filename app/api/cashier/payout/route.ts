@@ -41,6 +41,15 @@ import { Machine } from '@/app/api/lib/models/machines';
 import PayoutModel from '@/app/api/lib/models/payout';
 import VaultShiftModel from '@/app/api/lib/models/vaultShift';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createPayoutRoute,
+  listPayoutsRoute,
+} from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -49,7 +58,6 @@ import {
 } from '@/app/api/lib/utils/routeLogger';
 import { generateMongoId } from '@/lib/utils/id';
 import type { GamingMachine, PayoutDocument } from '@shared/types';
-import type { CreatePayoutRequest } from '@/shared/types/vault';
 import { NextRequest, NextResponse } from 'next/server';
 
 export async function POST(request: NextRequest) {
@@ -62,9 +70,22 @@ export async function POST(request: NextRequest) {
       const userId = userPayload._id as string;
 
       // ============================================================================
-      // STEP 1: Parse Request
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      const body: CreatePayoutRequest = await request.json();
+      const validation = parseBody(
+        createPayoutRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        logRouteError(
+          functionName,
+          'POST',
+          '/api/cashier/payout',
+          'Invalid payout data',
+          user
+        );
+        return validationErrorResponse(validation.error);
+      }
       const {
         cashierShiftId,
         type,
@@ -74,53 +95,7 @@ export async function POST(request: NextRequest) {
         machineId,
         reason,
         notes,
-      } = body;
-
-      // Validate
-      if (!cashierShiftId || !amount || amount <= 0) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/cashier/payout',
-          'Invalid payout data',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'Invalid payout data' },
-          { status: 400 }
-        );
-      }
-
-      if (type === 'ticket' && !ticketNumber) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/cashier/payout',
-          'Ticket number required',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'Ticket number required' },
-          { status: 400 }
-        );
-      }
-
-      if (type === 'hand_pay' && !machineId) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/cashier/payout',
-          'Machine identification required for Hand Pay',
-          user
-        );
-        return NextResponse.json(
-          {
-            success: false,
-            error: 'Machine identification required for Hand Pay',
-          },
-          { status: 400 }
-        );
-      }
+      } = validation.data;
 
       // ============================================================================
       // STEP 2: Shift Check
@@ -313,13 +288,13 @@ export async function GET(request: NextRequest) {
       );
 
       // ============================================================================
-      // STEP 1: Parse query parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const cashierShiftId = searchParams.get('cashierShiftId');
-      const limit = parseInt(searchParams.get('limit') || '20');
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
+      const parsed = parseQuery(listPayoutsRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const { cashierShiftId, limit, startDate, endDate } = parsed.data;
 
       // ============================================================================
       // STEP 2: Build Query
@@ -330,8 +305,7 @@ export async function GET(request: NextRequest) {
       if (!isVM) {
         query.cashierId = userId;
       } else {
-        const queryCashierId = searchParams.get('cashierId');
-        if (queryCashierId) query.cashierId = queryCashierId;
+        if (parsed.data.cashierId) query.cashierId = parsed.data.cashierId;
       }
 
       if (cashierShiftId) query.cashierShiftId = cashierShiftId;
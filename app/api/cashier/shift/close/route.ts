@@ -26,10 +26,12 @@ import {
   calculateExpectedBalance,
   validateDenominations,
 } from '@/lib/helpers/vault/calculations';
-import type {
-  CloseCashierShiftRequest,
-  CloseCashierShiftResponse,
-} from '@/shared/types/vault';
+import type { CloseCashierShiftResponse } from '@/shared/types/vault';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { closeCashierShiftRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
@@ -69,28 +71,23 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse and validate request
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const body: CloseCashierShiftRequest = await request.json();
-      const { shiftId, physicalCount, denominations } = body;
-
-      if (!shiftId || physicalCount === undefined || !denominations) {
+      const validation = parseBody(
+        closeCashierShiftRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/cashier/shift/close',
-          'Missing required fields: shiftId, physicalCount, denominations',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          {
-            success: false,
-            error:
-              'Missing required fields: shiftId, physicalCount, denominations',
-          },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { shiftId, physicalCount, denominations } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate denominations
@@ -23,7 +23,11 @@ import FloatRequestModel from '@/app/api/lib/models/floatRequest';
 import VaultShiftModel from '@/app/api/lib/models/vaultShift';
 import { validateDenominations } from '@/lib/helpers/vault/calculations';
 import { generateMongoId } from '@/lib/utils/id';
-import type { OpenCashierShiftRequest } from '@/shared/types/vault';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { openCashierShiftRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteError,
   extractUserFromRequest,
@@ -62,28 +66,23 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse and validate request
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const body: OpenCashierShiftRequest = await request.json();
-      const { locationId, requestedFloat, denominations } = body;
-
-      if (!locationId || requestedFloat === undefined || !denominations) {
+      const validation = parseBody(
+        openCashierShiftRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/cashier/shift/open',
-          'Missing required fields: locationId, requestedFloat, denominations',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          {
-            success: false,
-            error:
-              'Missing required fields: locationId, requestedFloat, denominations',
-          },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { locationId, requestedFloat, denominations } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate denominations
@@ -19,6 +19,11 @@ import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import CashierShiftModel from '@/app/api/lib/models/cashierShift';
 import VaultShiftModel from '@/app/api/lib/models/vaultShift';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { rejectCashierShiftRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
@@ -60,22 +65,21 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
-      const { shiftId, reason = '' } = body;
-
-      if (!shiftId) {
+      const validation = parseBody(
+        rejectCashierShiftRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/cashier/shift/reject',
-          'Missing required field: shiftId',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing required field: shiftId' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { shiftId, reason } = validation.data;
 
       // ============================================================================
       // STEP 3: Get cashier shift
@@ -26,6 +26,11 @@ import CashierShiftModel from '@/app/api/lib/models/cashierShift';
 import VaultShiftModel from '@/app/api/lib/models/vaultShift';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
 import { generateMongoId } from '@/lib/utils/id';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { resolveCashierShiftRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
@@ -67,25 +72,22 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
-      const { shiftId, finalBalance, auditComment = '', denominations } = body;
-
-      if (!shiftId || finalBalance === undefined) {
+      const validation = parseBody(
+        resolveCashierShiftRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/cashier/shift/resolve',
-          'Missing required fields (shiftId, finalBalance)',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          {
-            success: false,
-            error: 'Missing required fields (shiftId, finalBalance)',
-          },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { shiftId, finalBalance, auditComment, denominations } =
+        validation.data;
 
       // ============================================================================
       // STEP 3: Get cashier shift
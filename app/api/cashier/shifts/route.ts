@@ -24,6 +24,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import CashierShiftModel from '@/app/api/lib/models/cashierShift';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listCashierShiftsRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -46,15 +51,20 @@ export async function GET(request: NextRequest) {
       );
 
       // ============================================================================
-      // STEP 1: Parse query parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const status = searchParams.get('status');
-      const locationId = searchParams.get('locationId');
-      const cashierIdFromParams = searchParams.get('cashierId');
-      const limit = parseInt(searchParams.get('limit') || '20');
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
+      const parsed = parseQuery(listCashierShiftsRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const {
+        status,
+        locationId,
+        cashierId: cashierIdFromParams,
+        limit,
+        startDate,
+        endDate,
+      } = parsed.data;
 
       // SECURITY: If not VM, you can ONLY see your own shifts
       let finalCashierId = cashierIdFromParams;
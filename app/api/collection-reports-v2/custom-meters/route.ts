@@ -10,6 +10,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Meters } from '@/app/api/lib/models/meters';
+import { customPeriodMetersRoute } from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import { NextRequest, NextResponse } from 'next/server';
 
 export async function GET(req: NextRequest) {
@@ -20,20 +25,11 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate parameters
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-      const machineId = searchParams.get('machineId');
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
-
-      if (!machineId || !startDate || !endDate) {
-        return NextResponse.json(
-          {
-            success: false,
-            error: 'machineId, startDate, and endDate are required',
-          },
-          { status: 400 }
-        );
+      const parsed = parseQuery(customPeriodMetersRoute, req);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
       }
+      const { machineId, startDate, endDate } = parsed.data;
 
       // ============================================================================
       // STEP 2: Find the single most recent meter document in the specified range
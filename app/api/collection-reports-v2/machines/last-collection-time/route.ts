@@ -13,6 +13,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
+import { lastSessionCollectionTimeRoute } from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   createSuccessResponse,
   createErrorResponse,
@@ -23,6 +28,7 @@ import {
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
 import type { ReportedMachineDocument } from '@/app/api/lib/models/reportedMachines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { NextRequest } from 'next/server';
 
 export const runtime = 'nodejs';
@@ -44,23 +50,20 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async () => {
   try {
     // ============================================================================
-    // STEP 1: Parse request parameters
+    // STEP 1: Parse and validate request parameters
     // ============================================================================
-    const { searchParams } = request.nextUrl;
-    const machineId = searchParams.get('machineId');
-    const locationId = searchParams.get('locationId');
-    const excludeSessionId = searchParams.get('excludeSessionId');
-
-    if (!machineId) {
+    const parsed = parseQuery(lastSessionCollectionTimeRoute, request);
+    if (!parsed.success) {
       logRouteError(
         functionName,
         'GET',
         '/api/collection-reports-v2/machines/last-collection-time',
-        'machineId query parameter is required',
+        'Invalid query parameters',
         user
       );
-      return createErrorResponse('machineId query parameter is required', 400);
+      return validationErrorResponse(parsed.error);
     }
+    const { machineId, locationId, excludeSessionId } = parsed.data;
 
     // ============================================================================
     // STEP 3: Find most recent and oldest submitted session machines
@@ -68,7 +71,7 @@ export async function GET(request: NextRequest) {
     const baseFilter: Record<string, unknown> = {
       machineId,
       sessionStatus: 'submitted',
-      $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+      ...NOT_DELETED_FILTER,
     };
 
     if (locationId) {
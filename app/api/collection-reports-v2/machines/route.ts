@@ -27,6 +27,15 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
+import {
+  captureMachineRoute,
+  updateCapturedMachineRoute,
+} from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   extractUserFromRequest,
   logRouteCreate,
@@ -65,7 +74,15 @@ export async function POST(req: NextRequest) {
     // ============================================================================
     // STEP 1: Parse and validate request body
     // ============================================================================
-    const body = (await req.json()) as CaptureMachinePayload;
+    const validation = parseBody(
+      captureMachineRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      logRouteError(functionName, 'POST', '/api/collection-reports-v2/machines', 'Invalid request body', user);
+      return validationErrorResponse(validation.error);
+    }
+    const body: CaptureMachinePayload = validation.data;
     const parsed = validateCapturePayload(body);
     if ('error' in parsed) {
       return NextResponse.json(
@@ -229,18 +246,23 @@ export async function PATCH(req: NextRequest) {
     try {
 
     // ============================================================================
-    // STEP 3: Parse ID and request body
+    // STEP 3: Parse and validate ID and request body
     // ============================================================================
-    const { searchParams } = new URL(req.url);
-    const reportedMachineId = searchParams.get('id');
-    if (!reportedMachineId) {
-      return NextResponse.json(
-        { success: false, error: 'id query param is required' },
-        { status: 400 }
-      );
+    const parsed = parseQuery(updateCapturedMachineRoute, req);
+    if (!parsed.success) {
+      return validationErrorResponse(parsed.error);
     }
+    const reportedMachineId = parsed.data.id;
 
-    const body = (await req.json()) as UpdateMachinePayload;
+    const validation = parseBody(
+      updateCapturedMachineRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      logRouteError(functionName, 'PATCH', '/api/collection-reports-v2/machines', 'Invalid request body', user);
+      return validationErrorResponse(validation.error);
+    }
+    const body: UpdateMachinePayload = validation.data;
 
     // ============================================================================
     // STEP 4: Fetch target document and check chronological validity
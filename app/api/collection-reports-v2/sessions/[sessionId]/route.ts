@@ -13,6 +13,11 @@ import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
 import { Meters } from '@/app/api/lib/models/meters';
 import { CollectionSessionV2 } from '@/app/api/lib/models/collectionSessionV2';
 import type { CollectionSessionV2Document } from '@/app/api/lib/models/collectionSessionV2';
+import { updateCollectionSessionRoute } from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   extractUserFromRequest,
   logRouteFetch,
@@ -36,6 +41,7 @@ import {
   getMoneyOutAndJackpotScale,
 } from '@/app/api/lib/utils/reviewerScale';
 import type { SessionMachineResponse } from '@/app/api/lib/helpers/collectionReportV2/sessionOperations';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 // ============================================================================
 // Financial field names for PATCH parsing
@@ -137,7 +143,7 @@ export async function GET(
     // STEP 3: Fetch session machines
     // ============================================================================
     const sessionMatch: Record<string, unknown> = { sessionId };
-    sessionMatch.$or = [{ deletedAt: null }, { deletedAt: { $exists: false } }];
+    sessionMatch.deletedAt = NOT_DELETED_FILTER.deletedAt;
 
     const machines = await ReportedMachine.find(sessionMatch)
       .sort({ sequenceOrder: 1 })
@@ -389,7 +395,7 @@ export async function PATCH(
   return withApiAuth(req, async () => {
   try {
     // ============================================================================
-    // STEP 1: Parse request params and body
+    // STEP 1: Parse and validate request params and body
     // ============================================================================
     sessionId = (await params).sessionId;
     if (!sessionId) {
@@ -399,11 +405,16 @@ export async function PATCH(
       );
     }
 
-    const body = await req.json() as Record<string, unknown>;
-    const { sessionStartTime, sessionEndTime } = body as {
-      sessionStartTime?: string;
-      sessionEndTime?: string;
-    };
+    const validation = parseBody(
+      updateCollectionSessionRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      logRouteError(functionName, 'PATCH', `/api/collection-reports-v2/sessions/${sessionId}`, 'Invalid request body', user);
+      return validationErrorResponse(validation.error);
+    }
+    const body = validation.data;
+    const { sessionStartTime, sessionEndTime } = body;
 
     // ============================================================================
     // STEP 3: Update session time fields on ReportedMachine docs
@@ -462,16 +473,6 @@ export async function PATCH(
       );
     }
 
-    if (
-      Object.keys(timeUpdateFields).length === 0 &&
-      Object.keys(financialUpdate).length === 0
-    ) {
-      return NextResponse.json(
-        { success: false, error: 'No valid fields to update' },
-        { status: 400 }
-      );
-    }
-
     const duration = Date.now() - startTime;
     logRouteUpdate(functionName, 'PATCH', `/api/collection-reports-v2/sessions/${sessionId}`, machinesUpdated, user, duration);
 
@@ -13,6 +13,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
+import { submitCollectionSessionRoute } from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   extractUserFromRequest,
   logRouteError,
@@ -59,7 +64,7 @@ export async function PATCH(
   return withApiAuth(req, async ({ user: userPayload }) => {
   try {
     // ============================================================================
-    // STEP 1: Parse request body
+    // STEP 1: Parse and validate request body
     // ============================================================================
     sessionId = (await params).sessionId;
     if (!sessionId) {
@@ -69,16 +74,22 @@ export async function PATCH(
       );
     }
 
-    const body = await req.json().catch(() => ({}));
-    const requestStartTime = body.sessionStartTime as string | undefined;
-    const frontendImages =
-      (body.images as
-        | Array<{
-            reportedMachineId: string;
-            imageData: string;
-            imageCapturedAt?: string;
-          }>
-        | undefined) ?? [];
+    const validation = parseBody(
+      submitCollectionSessionRoute,
+      await req.json().catch(() => ({}))
+    );
+    if (!validation.success) {
+      logRouteError(
+        functionName,
+        'PATCH',
+        `/api/collection-reports-v2/sessions/${sessionId}/submit`,
+        'Invalid request body',
+        user
+      );
+      return validationErrorResponse(validation.error);
+    }
+    const { sessionStartTime: requestStartTime, images: frontendImages } =
+      validation.data;
     const sessionEndTime = new Date();
     const sessionStartTime = requestStartTime
       ? new Date(requestStartTime)
@@ -19,6 +19,11 @@ import {
 } from '@/app/api/lib/helpers/collectionReportV2/sessionOperations';
 import { revertMachineMetersAfterSessionDelete } from '@/app/api/lib/helpers/collectionReportV2/deleteOperations';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { deleteCollectionSessionsRoute } from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import { NextRequest, NextResponse } from 'next/server';
 
 export async function POST(req: NextRequest) {
@@ -30,15 +35,14 @@ export async function POST(req: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request body
       // ============================================================================
-      const body = await req.json() as { sessionIds?: string[] };
-      const sessionIds = body.sessionIds;
-
-      if (!sessionIds || !Array.isArray(sessionIds) || sessionIds.length === 0) {
-        return NextResponse.json(
-          { success: false, error: 'sessionIds array is required' },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        deleteCollectionSessionsRoute,
+        await req.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { sessionIds } = validation.data;
 
       // ============================================================================
       // STEP 2: Check permissions (developer, owner, admin, location admin)
@@ -11,16 +11,25 @@ import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
 import { Machine } from '@/app/api/lib/models/machines';
 import { determineAllowedLocationIds } from '@/app/api/lib/helpers/collectionReport/queries';
+import {
+  createCollectionSessionRoute,
+  listCollectionSessionsRoute,
+} from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   extractUserFromRequest,
   logRouteFetch,
   logRouteCreate,
   logRouteError,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id';
 import type { MachineDocument } from '@/shared/types/models';
 import { NextRequest, NextResponse } from 'next/server';
-import type { TimePeriod } from '@/app/api/lib/types';
 import {
   extractUserPayload,
   resolveLicenceeParam,
@@ -48,23 +57,27 @@ export async function GET(req: NextRequest) {
   try {
 
     // ============================================================================
-    // STEP 2: Parse query parameters
+    // STEP 2: Parse and validate query parameters
     // ============================================================================
-    const { searchParams } = new URL(req.url);
-    const rawLicencee = searchParams.get('licencee') || undefined;
-    const licencee = resolveLicenceeParam(rawLicencee ?? null);
-    const timePeriod = (searchParams.get('timePeriod') as TimePeriod) || undefined;
-    const startDateStr = searchParams.get('startDate');
-    const endDateStr = searchParams.get('endDate');
-    const search = searchParams.get('search') || undefined;
-    const searchType = searchParams.get('searchType') || 'collector';
-    const page = Math.max(0, parseInt(searchParams.get('page') || '1', 10) - 1);
-    const limit = Math.min(100, parseInt(searchParams.get('limit') || '20', 10));
-    const sortField = searchParams.get('sortField') || 'created';
-    const sortDir = searchParams.get('sortDirection') || 'desc';
+    const parsed = parseQuery(listCollectionSessionsRoute, req);
+    if (!parsed.success) {
+      logRouteError(functionName, 'GET', '/api/collection-reports-v2/sessions', 'Invalid query parameters', user);
+      return validationErrorResponse(parsed.error);
+    }
+    const { timePeriod, search, searchType, sortField } = parsed.data;
+    const licencee = resolveLicenceeParam(parsed.data.licencee ?? null);
+    const page = parsed.data.page - 1;
+    const limit = Math.min(parsed.data.limit, 100);
 
-    const { sortKey, sortDirection } = buildSessionSortConfig(sortField, sortDir);
-    const dateFilter = buildDateFilter(timePeriod, startDateStr, endDateStr);
+    const { sortKey, sortDirection } = buildSessionSortConfig(
+      sortField,
+      parsed.data.sortDirection
+    );
+    const dateFilter = buildDateFilter(
+      timePeriod,
+      parsed.data.startDate ?? null,
+      parsed.data.endDate ?? null
+    );
 
     // ============================================================================
     // STEP 3: Determine allowed locations and build match stage
@@ -136,21 +149,17 @@ export async function POST(req: NextRequest) {
   return withApiAuth(req, async ({ user: userPayload }) => {
   try {
     // ============================================================================
-    // STEP 1: Parse request body
+    // STEP 1: Parse and validate request body
     // ============================================================================
-    const body = await req.json();
-    const { locationId, locationName, licencee } = body as {
-      locationId: string;
-      locationName: string;
-      licencee?: string;
-    };
-
-    if (!locationId || !locationName) {
-      return NextResponse.json(
-        { success: false, error: 'locationId and locationName are required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      createCollectionSessionRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      logRouteError(functionName, 'POST', '/api/collection-reports-v2/sessions', 'Invalid request body', user);
+      return validationErrorResponse(validation.error);
     }
+    const { locationId, locationName, licencee } = validation.data;
 
     const collectorUserId = String((userPayload as unknown as Record<string, string>)._id);
     const collectorName =
@@ -187,9 +196,7 @@ export async function POST(req: NextRequest) {
     // ============================================================================
     // STEP 5: Fetch and map machines
     // ============================================================================
-    const DELETION_FILTER = {
-      $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
-    };
+    const DELETION_FILTER = { ...NOT_DELETED_FILTER };
 
     const machines = await Machine.find({
       gamingLocation: locationId,
@@ -9,6 +9,11 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { uploadMeterPhotoRoute } from '@/app/api/lib/routeSchemas/collectionReportsV2';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   extractUserFromRequest,
   logRouteCreate,
@@ -38,20 +43,26 @@ export async function POST(req: NextRequest) {
     // ============================================================================
     // STEP 1: Parse form data and validate
     // ============================================================================
-    const formData = await req.formData();
-    const file = formData.get('file') as File | null;
-    const sessionId = formData.get('sessionId') as string | null;
-    const machineId = formData.get('machineId') as string | null;
-
-    if (!file || file.size === 0) {
-      return NextResponse.json(
-        { success: false, error: 'No file provided' },
-        { status: 400 }
+    const formData = await req.formData().catch(() => null);
+    const validation = parseBody(
+      uploadMeterPhotoRoute,
+      formData && Object.fromEntries(formData)
+    );
+    if (!validation.success) {
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/collection-reports-v2/upload',
+        'Invalid request body',
+        user
       );
+      return validationErrorResponse(validation.error);
     }
-    if (!sessionId || !machineId) {
+    const { file, sessionId, machineId } = validation.data;
+
+    if (file.size === 0) {
       return NextResponse.json(
-        { success: false, error: 'sessionId and machineId are required' },
+        { success: false, error: 'No file provided' },
         { status: 400 }
       );
     }
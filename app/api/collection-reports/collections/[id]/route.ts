@@ -24,6 +24,11 @@ import {
 import { Collections } from '@/app/api/lib/models/collections';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import { updateCollectionRoute } from '@/app/api/lib/routeSchemas/collectionReports';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteUpdate,
   logRouteError,
@@ -93,9 +98,23 @@ export async function PATCH(request: NextRequest) {
 
     try {
       // ============================================================================
-      // STEP 1: Parse request body
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      const updateData = await request.json();
+      const validation = parseBody(
+        updateCollectionRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        logRouteError(
+          functionName,
+          'PATCH',
+          '/api/collection-reports/collections/[id]',
+          'Invalid request body',
+          user
+        );
+        return validationErrorResponse(validation.error);
+      }
+      const updateData: Record<string, unknown> = validation.data;
 
       // ============================================================================
       // STEP 2: Validate collection ID
@@ -151,7 +170,7 @@ export async function PATCH(request: NextRequest) {
       // ============================================================================
       // STEP 4: Handle RAM clear toggle (relay-aware)
       // ============================================================================
-      const explicitSasEndTime = updateData.sasEndTime;
+      const explicitSasEndTime = validation.data.sasEndTime;
       const { unsetData } = await handleRamClearToggleWithRelayGuard(
         originalCollection,
         updateData,
@@ -161,7 +180,7 @@ export async function PATCH(request: NextRequest) {
       // ============================================================================
       // STEP 5: Remove immutable _id, extract SAS time fields, determine flags
       // ============================================================================
-      const { _id, ...safeUpdateData } = updateData as Record<string, unknown>;
+      const { _id, ...safeUpdateData } = updateData;
       if ('_id' in updateData) {
         console.warn('⚠️ API: Removed _id field from update data');
       }
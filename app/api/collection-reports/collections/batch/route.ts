@@ -17,6 +17,8 @@ import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper'
 import { propagateSingleCollectionDeletion } from '@/app/api/lib/helpers/collectionReport/collectionOperations'
 import { Collections } from '@/app/api/lib/models/collections'
 import { Machine } from '@/app/api/lib/models/machines'
+import { deleteCollectionsRoute } from '@/app/api/lib/routeSchemas/collectionReports'
+import { parseBody, validationErrorResponse } from '@/app/api/lib/routeSchemas/common'
 import {
   extractUserFromRequest,
   logRouteDelete,
@@ -54,20 +56,12 @@ export async function DELETE(req: NextRequest) {
 
   return withApiAuth(req, async () => {
     // STEP 1: Parse and validate body
-    let ids: string[]
-    let updateCabinetHistory: boolean
-    try {
-      const body = await req.json() as { ids?: unknown; updateCabinetHistory?: unknown }
-      ids = body.ids as string[]
-      updateCabinetHistory = body.updateCabinetHistory === true
-    } catch {
-      return NextResponse.json({ error: 'Invalid request body' }, { status: 400 })
-    }
-
-    if (!Array.isArray(ids) || ids.length === 0) {
-      logRouteError(functionName, 'DELETE', ROUTE_PATH, 'ids must be a non-empty array', logUser)
-      return NextResponse.json({ error: 'ids must be a non-empty array' }, { status: 400 })
+    const validation = parseBody(deleteCollectionsRoute, await req.json().catch(() => null))
+    if (!validation.success) {
+      logRouteError(functionName, 'DELETE', ROUTE_PATH, 'Invalid request body', logUser)
+      return validationErrorResponse(validation.error)
     }
+    const { ids, updateCabinetHistory } = validation.data
 
     // STEP 2: Fetch all target collections
     const collections = await Collections.find({ _id: { $in: ids } }).lean<CollectionDocument[]>()
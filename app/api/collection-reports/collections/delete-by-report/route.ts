@@ -16,6 +16,11 @@ import { Collections } from '@/app/api/lib/models/collections';
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { connectDB } from '@/app/api/lib/middleware/db';
+import { deleteReportCollectionsRoute } from '@/app/api/lib/routeSchemas/collectionReports';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteDelete,
   logRouteError,
@@ -25,6 +30,7 @@ import {
   logActivity,
   mapDeletedFieldsToChanges,
 } from '@/app/api/lib/helpers/activityLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import { getUserFromServer } from '@/app/api/lib/helpers/users';
 import type { CollectionDocument } from '@/lib/types/collection';
@@ -51,41 +57,38 @@ export async function DELETE(request: NextRequest) {
 
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const { locationReportId } = await request.json();
-
-      // ============================================================================
-      // STEP 2: Validate locationReportId
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      if (!locationReportId) {
+      const validation = parseBody(
+        deleteReportCollectionsRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'DELETE',
           '/api/collection-reports/collections/delete-by-report',
-          'Location report ID is required',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Location report ID is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { locationReportId } = validation.data;
 
       // ============================================================================
-      // STEP 3: Connect to database
+      // STEP 2: Connect to database
       // ============================================================================
       await connectDB();
 
       // ============================================================================
-      // STEP 4: Find all collections with this locationReportId
+      // STEP 3: Find all collections with this locationReportId
       // ============================================================================
       const collections = await Collections.find({ locationReportId }).lean<
         CollectionDocument[]
       >();
 
       // ============================================================================
-      // STEP 5: Get machine IDs from collections
+      // STEP 4: Get machine IDs from collections
       // ============================================================================
       const machineIds = [
         ...new Set(
@@ -94,33 +97,33 @@ export async function DELETE(request: NextRequest) {
       ];
 
       // ============================================================================
-      // STEP 6: Delete associated manual meters
+      // STEP 5: Delete associated manual meters
       // ============================================================================
       const { deleteManualMetersPerCollection } =
         await import('@/app/api/lib/helpers/collectionReport/operations');
       await deleteManualMetersPerCollection(locationReportId);
 
       // ============================================================================
-      // STEP 6.5: Fetch collection report before deleting
+      // STEP 5.5: Fetch collection report before deleting
       // ============================================================================
       const existingReport = await CollectionReport.findOne({
         locationReportId,
       }).lean<ICollectionReport>();
 
       // ============================================================================
-      // STEP 7: Delete all collections
+      // STEP 6: Delete all collections
       // ============================================================================
       const deleteResult = await Collections.deleteMany({ locationReportId });
 
       // ============================================================================
-      // STEP 8: Delete the collection report
+      // STEP 7: Delete the collection report
       // ============================================================================
       const reportDeleteResult = await CollectionReport.deleteOne({
         locationReportId,
       });
 
       // ============================================================================
-      // STEP 8.7: Propagate deletion forward and recalculate machines
+      // STEP 7.7: Propagate deletion forward and recalculate machines
       // ============================================================================
       const { updateRegularAndRamClearMeters } =
         await import('@/app/api/lib/helpers/collectionReport/reportCreation');
@@ -138,7 +141,7 @@ export async function DELETE(request: NextRequest) {
             timestamp: {
               $gt: col.timestamp || col.collectionTime || new Date(),
             },
-            deletedAt: { $exists: false },
+            ...NOT_DELETED_FILTER,
           })
             .sort({ timestamp: 1 })
             .lean<CollectionDocument>();
@@ -231,7 +234,7 @@ export async function DELETE(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 8.5: Log Activity
+      // STEP 7.5: Log Activity
       // ============================================================================
       const currentUser = await getUserFromServer();
       if (currentUser && existingReport) {
@@ -283,14 +286,14 @@ export async function DELETE(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 9: Verify deletion completed
+      // STEP 8: Verify deletion completed
       // ============================================================================
       const remainingCollections = await Collections.find({
         locationReportId,
       }).lean<CollectionDocument[]>();
 
       // ============================================================================
-      // STEP 10: Return success response
+      // STEP 9: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteDelete(
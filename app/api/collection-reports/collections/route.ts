@@ -19,11 +19,16 @@ import {
   resolvePreviousMetersForPatch,
   runPostUpdatePropagation,
 } from '@/app/api/lib/helpers/collectionReport/collectionOperations'
+import type { ApiAuthContext } from '@/app/api/lib/helpers/apiWrapper'
 import { getUserLocationFilter } from '@/app/api/lib/helpers/licenceeFilter'
+import { handleListRequest } from '@/app/api/lib/helpers/listEndpoint'
+import { collectionListResource } from '@/app/api/lib/helpers/listResources'
+import type { CollectionListItem } from '@/app/api/lib/helpers/listResources'
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users'
 import { connectDB } from '@/app/api/lib/middleware/db'
 import { Collections } from '@/app/api/lib/models/collections'
 import { Machine } from '@/app/api/lib/models/machines'
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus'
 import {
   extractUserFromRequest,
   logRouteCreate,
@@ -34,6 +39,7 @@ import {
   logRouteRequest,
   logRouteUpdate,
 } from '@/app/api/lib/utils/routeLogger'
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors'
 import type {
   CollectionDocument,
   CreateCollectionPayload,
@@ -51,6 +57,12 @@ const ROUTE_PATH = '/api/collection-reports/collections'
 // ============================================================================
 
 /**
+ * With `cursor` (empty for the first page) the standard list params apply
+ * instead (see listEndpoint): `limit`, `sort` (timestamp, createdAt,
+ * updatedAt, machineId; default -timestamp), `licencee`, `location`,
+ * `status` and `from` / `to` on `timestamp`, and the response is
+ * `{ success, data, pagination, sort, filters }`.
+ *
  * @param {string} locationReportId - Filter by location report ID
  * @param {string} location - Filter by location name or ID
  * @param {string} licencee - Filter by licencee
@@ -97,6 +109,16 @@ export async function GET(req: NextRequest) {
     const isAdmin = userRoles.includes('admin') || userRoles.includes('developer') || userRoles.includes('owner')
     const licencee = searchParams.get('licencee')
 
+    if (searchParams.has('cursor')) {
+      const page = await handleListRequest<CollectionListItem>(req, collectionListResource, {
+        user: user as ApiAuthContext['user'],
+        userRoles,
+        isAdminOrDev: isAdmin,
+      })
+      logRouteFetch(functionName, 'GET', ROUTE_PATH, page.data.length, logUser, Date.now() - startTime)
+      return NextResponse.json(page)
+    }
+
     // STEP 4: Determine allowed location IDs
     const allowedLocationIds = await getUserLocationFilter(
       isAdmin ? 'all' : userAccessibleLicencees,
@@ -155,7 +177,10 @@ export async function GET(req: NextRequest) {
   } catch (error) {
     const errorMessage = error instanceof Error ? error.message : 'Failed to fetch collections'
     logRouteError(functionName, 'GET', ROUTE_PATH, errorMessage, logUser)
-    return NextResponse.json({ error: errorMessage }, { status: 500 })
+    return NextResponse.json(
+      { error: errorMessage },
+      { status: getErrorStatus(error) }
+    )
   }
 }
 
@@ -235,7 +260,7 @@ export async function POST(req: NextRequest) {
     // Storing gross=0 causes computeTotalVariation to produce a phantom variation equal to
     // movement.gross. For offline machines collector-entered values are the source of truth
     // for both movement and SAS — variation must be $0.
-    const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000
+    const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs()
     const hasRelay = !!machine.relayId?.trim()
     const isOfflineMachine =
       hasRelay &&
@@ -349,7 +374,7 @@ export async function PATCH(req: NextRequest) {
     // the machine was offline (supplemental meter exists) even if the relay is back online now.
     if (updateData.sasMeters && patchMachineDoc) {
       const hasRelay = !!patchMachineDoc.relayId?.trim()
-      const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000
+      const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs()
       const lastActivityMs = patchMachineDoc.lastActivity
         ? Date.now() - new Date(patchMachineDoc.lastActivity as Date).getTime()
         : null
@@ -13,6 +13,11 @@
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import { wowMetersBatchRoute } from '@/app/api/lib/routeSchemas/collectionReports';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import { NextRequest, NextResponse } from 'next/server';
 
 type BatchMeterResult = {
@@ -68,30 +73,16 @@ export async function POST(request: NextRequest) {
 
   return withApiAuth(request, async () => {
     // ========================================================================
-    // STEP 1: Parse body
+    // STEP 1: Parse and validate body
     // ========================================================================
-    let body: { machineIds?: unknown; endTime?: unknown; startTime?: unknown };
-    try {
-      body = await request.json();
-    } catch {
-      return NextResponse.json({ success: false, error: 'Invalid JSON body' }, { status: 400 });
+    const validation = parseBody(wowMetersBatchRoute, await request.json().catch(() => null));
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
+    const { machineIds: ids, endTime: endTimeRaw, startTime: startTimeRaw } = validation.data;
 
-    const { machineIds, endTime: endTimeRaw, startTime: startTimeRaw } = body;
-    if (!Array.isArray(machineIds) || machineIds.length === 0) {
-      return NextResponse.json(
-        { success: false, error: 'machineIds must be a non-empty array' },
-        { status: 400 }
-      );
-    }
-
-    const ids = machineIds.filter((id): id is string => typeof id === 'string' && id.length > 0);
-    if (ids.length === 0) {
-      return NextResponse.json({ success: false, data: {} });
-    }
-
-    const endDate = endTimeRaw ? new Date(String(endTimeRaw)) : new Date();
-    const startDate = startTimeRaw ? new Date(String(startTimeRaw)) : null;
+    const endDate = endTimeRaw ? new Date(endTimeRaw) : new Date();
+    const startDate = startTimeRaw ? new Date(startTimeRaw) : null;
 
     // Resolve includeJackpot config for each machine in the batch
     type MachineLocationProjection = { _id: string; gamingLocation?: string };
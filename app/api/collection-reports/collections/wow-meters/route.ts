@@ -21,6 +21,11 @@
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import { wowMetersRoute } from '@/app/api/lib/routeSchemas/collectionReports';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import type { MeterDocument } from '@/shared/types';
 import { NextRequest, NextResponse } from 'next/server';
 
@@ -78,19 +83,17 @@ export async function GET(request: NextRequest) {
 
   return withApiAuth(request, async () => {
     // ========================================================================
-    // STEP 1: Parse request parameters
+    // STEP 1: Parse and validate request parameters
     // ========================================================================
-    const { searchParams } = request.nextUrl;
-    const machineId = searchParams.get('machineId');
-    const startTimeParam = searchParams.get('startTime');
-    const endTimeParam = searchParams.get('endTime');
-
-    if (!machineId) {
-      return NextResponse.json(
-        { success: false, error: 'machineId query parameter is required' },
-        { status: 400 }
-      );
+    const parsed = parseQuery(wowMetersRoute, request);
+    if (!parsed.success) {
+      return validationErrorResponse(parsed.error);
     }
+    const {
+      machineId,
+      startTime: startTimeParam,
+      endTime: endTimeParam,
+    } = parsed.data;
 
     const endDate = endTimeParam ? new Date(endTimeParam) : new Date();
     const startDate = startTimeParam ? new Date(startTimeParam) : null;
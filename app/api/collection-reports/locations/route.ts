@@ -16,6 +16,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { GamingLocationDocument } from '@/shared/types';
 import {
   getUserAccessibleLicenceesFromToken,
@@ -94,12 +95,7 @@ export async function GET(req: NextRequest) {
     // ============================================================================
     // STEP 4: Build query filter based on access control
     // ============================================================================
-    const deletionFilter = {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    };
+    const deletionFilter = { ...NOT_DELETED_FILTER };
 
     const allowedLocationIds = await getUserLocationFilter(
       userAccessibleLicencees,
@@ -6,6 +6,12 @@ import { Collections } from '@/app/api/lib/models/collections';
 import { appendMeterIdsToCollections } from '@/app/api/lib/helpers/collectionReport/reportCreation';
 import { generateMongoId } from '@/lib/utils/id';
 import { isWowMachine } from '@/shared/utils/wowMachine';
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus';
+import { preCreateMetersRoute } from '@/app/api/lib/routeSchemas/collectionReports';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
 import {
   logRouteRequest,
   logRouteCreate,
@@ -63,7 +69,7 @@ async function processSingleMachine(
   }
 
   const hasRelay = !!machineDoc?.relayId;
-  const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000;
+  const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
   const lastActivityMs = machineDoc?.lastActivity
     ? Date.now() - new Date(machineDoc.lastActivity).getTime()
     : null;
@@ -229,17 +235,18 @@ export async function POST(req: NextRequest) {
     const startTime = Date.now();
     logRouteRequest(FUNCTION_NAME, 'POST', ROUTE_PATH, user);
 
-    let body: { machines?: PreCreateMetersBody[] } | PreCreateMetersBody;
-    try {
-      body = await req.json();
-    } catch {
-      logRouteError(FUNCTION_NAME, 'POST', ROUTE_PATH, 'Invalid JSON body', user);
-      return NextResponse.json({ success: false, error: 'Invalid JSON body' }, { status: 400 });
+    const validation = parseBody(
+      preCreateMetersRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      logRouteError(FUNCTION_NAME, 'POST', ROUTE_PATH, 'Invalid request body', user);
+      return validationErrorResponse(validation.error);
     }
+    const body = validation.data;
 
-    const machines = Array.isArray((body as { machines?: PreCreateMetersBody[] }).machines)
-      ? (body as { machines: PreCreateMetersBody[] }).machines
-      : [body as PreCreateMetersBody];
+    const machines: PreCreateMetersBody[] =
+      'machines' in body ? body.machines : [body];
 
     if (machines.length === 0) {
       return NextResponse.json({ success: true, results: [] });
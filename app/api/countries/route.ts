@@ -37,6 +37,17 @@ import {
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Countries } from '@/app/api/lib/models/countries';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createCountryRoute,
+  deleteCountryRoute,
+  updateCountryRoute,
+} from '@/app/api/lib/routeSchemas/countries';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CountryDocument } from '@/shared/types';
 import {
   logRouteFetch,
@@ -58,9 +69,7 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       // STEP 1: Fetch all countries sorted alphabetically (excluding soft-deleted)
       // ============================================================================
-      const countries = await Countries.find({
-        $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
-      })
+      const countries = await Countries.find({ ...NOT_DELETED_FILTER })
         .sort({ name: 1 })
         .lean<CountryDocument[]>();
 
@@ -111,28 +120,30 @@ export async function POST(req: NextRequest) {
           'Forbidden - insufficient permissions',
           user
         );
-        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
       }
 
       // ============================================================================
-      // STEP 1: Parse request body
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      const body = await req.json();
-      const { name, alpha2, alpha3, isoNumeric } = body;
-
-      if (!name || !alpha2 || !alpha3 || !isoNumeric) {
+      const validation = parseBody(
+        createCountryRoute,
+        await req.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/countries',
-          'Missing required fields',
+          'Validation failed',
           user
         );
-        return NextResponse.json(
-          { error: 'Missing required fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { name, alpha2, alpha3, isoNumeric } = validation.data;
 
       // ============================================================================
       // STEP 2: Check for duplicates
@@ -150,7 +161,10 @@ export async function POST(req: NextRequest) {
           user
         );
         return NextResponse.json(
-          { error: 'Country with this code or name already exists' },
+          {
+            success: false,
+            error: 'Country with this code or name already exists',
+          },
           { status: 400 }
         );
       }
@@ -225,28 +239,30 @@ export async function PUT(req: NextRequest) {
           'Forbidden - insufficient permissions',
           user
         );
-        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
       }
 
       // ============================================================================
-      // STEP 1: Parse request body
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      const body = await req.json();
-      const { _id, name, alpha2, alpha3, isoNumeric } = body;
-
-      if (!_id) {
+      const validation = parseBody(
+        updateCountryRoute,
+        await req.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'PUT',
           '/api/countries',
-          'Country ID is required',
+          'Validation failed',
           user
         );
-        return NextResponse.json(
-          { error: 'Country ID is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { _id, name, alpha2, alpha3, isoNumeric } = validation.data;
 
       // ============================================================================
       // STEP 2: Pre-fetch existing country for before-state
@@ -262,7 +278,10 @@ export async function PUT(req: NextRequest) {
           `Country not found: ${_id}`,
           user
         );
-        return NextResponse.json({ error: 'Country not found' }, { status: 404 });
+        return NextResponse.json(
+          { success: false, error: 'Country not found' },
+          { status: 404 }
+        );
       }
 
       // ============================================================================
@@ -288,7 +307,10 @@ export async function PUT(req: NextRequest) {
           `Country not found during update: ${_id}`,
           user
         );
-        return NextResponse.json({ error: 'Country not found' }, { status: 404 });
+        return NextResponse.json(
+          { success: false, error: 'Country not found' },
+          { status: 404 }
+        );
       }
 
       const duration = Date.now() - startTime;
@@ -356,16 +378,17 @@ export async function DELETE(req: NextRequest) {
           'Forbidden - insufficient permissions',
           user
         );
-        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
       }
 
       // ============================================================================
       // STEP 1: Parse query params
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-      const countryId = searchParams.get('id');
-
-      if (!countryId) {
+      const query = parseQuery(deleteCountryRoute, req);
+      if (!query.success) {
         logRouteError(
           functionName,
           'DELETE',
@@ -373,11 +396,9 @@ export async function DELETE(req: NextRequest) {
           'Country ID is required',
           user
         );
-        return NextResponse.json(
-          { error: 'Country ID is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(query.error);
       }
+      const { id: countryId } = query.data;
 
       // ============================================================================
       // STEP 2: Soft delete country
@@ -396,7 +417,10 @@ export async function DELETE(req: NextRequest) {
           `Country not found: ${countryId}`,
           user
         );
-        return NextResponse.json({ error: 'Country not found' }, { status: 404 });
+        return NextResponse.json(
+          { success: false, error: 'Country not found' },
+          { status: 404 }
+        );
       }
 
       const duration = Date.now() - startTime;
@@ -25,7 +25,6 @@ import {
   parseShellCommand,
   queryBatch,
   resolveCollectionMatch,
-  type MatchMode,
   type ParsedShellCommand,
 } from '@/app/api/lib/helpers/dev/collectionQuery';
 import {
@@ -33,6 +32,17 @@ import {
   type DevModelEntry,
 } from '@/app/api/lib/helpers/dev/modelRegistry';
 import { describeSchema } from '@/app/api/lib/helpers/dev/schemaIntrospection';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  deleteDevDocumentsRoute,
+  listDevDocumentsRoute,
+  runDevCommandRoute,
+  updateDevDocumentsRoute,
+} from '@/app/api/lib/routeSchemas/dev';
 import { lenientParseJson } from '@/lib/utils/dev/parseJsonOption';
 import type { DevCollectionRecord, DevShellCommandResponse } from '@shared/types/dev';
 import type { ObjectId } from 'mongodb';
@@ -136,8 +146,8 @@ function applyFilterClauses(
  *
  * Flow:
  * 1. Authenticate — developer role required
- * 2. Resolve model + parse params (date range, apiPage, search, optional machine,
- *    optional filter clauses, optional sort/limit overrides)
+ * 2. Resolve model + parse and validate params (date range, apiPage, search,
+ *    optional machine, optional filter clauses, optional sort/limit overrides)
  * 3. Export mode → all matching docs as CSV/JSON
  * 4. Search seek → locate the Nth match's batch
  * 5. Query the resolved batch + total
@@ -156,45 +166,46 @@ export async function GET(
     if (!userRoles?.includes('developer')) return forbidden();
 
     // ==========================================================================
-    // STEP 2: Resolve model + parse params
+    // STEP 2: Resolve model + parse and validate params
     // ==========================================================================
     const { model } = await params;
     const resolved = resolveEntry(model);
     if ('error' in resolved) return resolved.error;
     const { entry } = resolved;
 
-    const { searchParams } = new URL(req.url);
-    const startDate = searchParams.get('startDate');
-    const endDate = searchParams.get('endDate');
-    const dateField = searchParams.get('dateField') || entry.defaultDateField;
-    const machine = searchParams.get('machine');
-    const search = (searchParams.get('search') || '').trim();
-    const searchColumn = (searchParams.get('searchColumn') || '').trim();
-    const matchOrdinal = Math.max(
-      0,
-      parseInt(searchParams.get('matchOrdinal') || '0')
-    );
-    const matchMode: MatchMode =
-      searchParams.get('matchMode') === 'exact' ? 'exact' : 'contains';
-    const requestedApiPage = Math.max(
-      1,
-      parseInt(searchParams.get('apiPage') || '1')
-    );
+    const parsedQuery = parseQuery(listDevDocumentsRoute, req);
+    if (!parsedQuery.success) {
+      return validationErrorResponse(parsedQuery.error);
+    }
+    const {
+      machine,
+      search,
+      searchColumn,
+      matchOrdinal,
+      matchMode,
+      apiPage: requestedApiPage,
+    } = parsedQuery.data;
+    const startDate = parsedQuery.data.startDate ?? null;
+    const endDate = parsedQuery.data.endDate ?? null;
+    const dateField = parsedQuery.data.dateField || entry.defaultDateField;
 
     // --- Query builder params ---
-    const filtersParam = searchParams.get('filters');
-    const filterLogic: 'and' | 'or' =
-      searchParams.get('filterLogic') === 'or' ? 'or' : 'and';
-    const sortFieldParam = searchParams.get('sortField') || '';
-    const sortDirParam = searchParams.get('sortDir') === 'asc' ? 1 : -1;
-    const limitParam = parseInt(searchParams.get('limit') || '0');
+    const {
+      filters: filtersParam,
+      filterLogic,
+      sortField: sortFieldParam,
+      limit: limitParam,
+    } = parsedQuery.data;
+    const sortDirParam = parsedQuery.data.sortDir === 'asc' ? 1 : -1;
 
     // --- JSON query params (Compass mode) ---
-    const rawFilterParam = searchParams.get('rawFilter');
-    const projectParam = searchParams.get('project');
-    const sortJsonParam = searchParams.get('sort');
-    const skipParam = parseInt(searchParams.get('skip') || '0');
-    const maxTimeMSParam = parseInt(searchParams.get('maxTimeMS') || '0');
+    const {
+      rawFilter: rawFilterParam,
+      project: projectParam,
+      sort: sortJsonParam,
+      skip: skipParam,
+      maxTimeMS: maxTimeMSParam,
+    } = parsedQuery.data;
 
     let filterClauses: FilterClause[] = [];
     if (filtersParam) {
@@ -257,8 +268,8 @@ export async function GET(
     // ==========================================================================
     // STEP 3: Export mode — all matching docs, no pagination
     // ==========================================================================
-    if (searchParams.get('export') === 'true') {
-      const format = searchParams.get('format') === 'json' ? 'json' : 'csv';
+    if (parsedQuery.data.export === 'true') {
+      const { format } = parsedQuery.data;
       const cursor = entry.model.collection
         .find(baseFilter)
         .sort(effectiveSort);
@@ -278,7 +289,7 @@ export async function GET(
 
       const filename = `${entry.key}-${new Date().toISOString().split('T')[0]}`;
       if (format === 'json') return exportJson(allDocs, filename);
-      const columnsParam = searchParams.get('columns');
+      const columnsParam = parsedQuery.data.columns;
       const columns = columnsParam
         ? columnsParam.split(',')
         : deriveExportColumns(allDocs);
@@ -351,7 +362,7 @@ export async function GET(
  *
  * Flow:
  * 1. Authenticate — developer role required
- * 2. Resolve model + parse body
+ * 2. Resolve model + parse and validate body
  * 3. Coerce the $set against the schema (editable fields only)
  * 4. updateOne / updateMany on the native collection
  */
@@ -366,26 +377,22 @@ export async function PATCH(
     if (!userRoles?.includes('developer')) return forbidden();
 
     // ==========================================================================
-    // STEP 2: Resolve model + parse body
+    // STEP 2: Resolve model + parse and validate body
     // ==========================================================================
     const { model } = await params;
     const resolved = resolveEntry(model);
     if ('error' in resolved) return resolved.error;
     const { entry } = resolved;
 
-    const body = (await req.json().catch(() => null)) as {
-      id?: string;
-      ids?: string[];
-      set?: Record<string, unknown>;
-    } | null;
-
-    const ids = body?.id ? [body.id] : (body?.ids ?? []);
-    if (ids.length === 0 || !body?.set) {
-      return NextResponse.json(
-        { success: false, error: 'id/ids and set are required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      updateDevDocumentsRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
+    const body = validation.data;
+    const ids = body.id ? [body.id] : (body.ids ?? []);
 
     // ==========================================================================
     // STEP 3: Coerce the $set against the schema (editable fields only)
@@ -419,7 +426,7 @@ export async function PATCH(
  *
  * Flow:
  * 1. Authenticate — developer role required
- * 2. Resolve model + parse body
+ * 2. Resolve model + parse and validate body
  * 3. deleteMany on the native collection
  */
 export async function DELETE(
@@ -433,23 +440,21 @@ export async function DELETE(
     if (!userRoles?.includes('developer')) return forbidden();
 
     // ==========================================================================
-    // STEP 2: Resolve model + parse body
+    // STEP 2: Resolve model + parse and validate body
     // ==========================================================================
     const { model } = await params;
     const resolved = resolveEntry(model);
     if ('error' in resolved) return resolved.error;
     const { entry } = resolved;
 
-    const body = (await req.json().catch(() => null)) as {
-      ids?: string[];
-    } | null;
-    const ids = body?.ids ?? [];
-    if (ids.length === 0) {
-      return NextResponse.json(
-        { success: false, error: 'ids are required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      deleteDevDocumentsRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
+    const { ids } = validation.data;
 
     // ==========================================================================
     // STEP 3: Hard delete
@@ -474,7 +479,7 @@ export async function DELETE(
  *
  * Flow:
  * 1. Authenticate — developer role required
- * 2. Resolve model + parse body
+ * 2. Resolve model + parse and validate body
  * 3. Parse shell command
  * 4. Execute against native collection
  * 5. Return results
@@ -490,23 +495,21 @@ export async function POST(
     if (!userRoles?.includes('developer')) return forbidden();
 
     // ==========================================================================
-    // STEP 2: Resolve model + parse body
+    // STEP 2: Resolve model + parse and validate body
     // ==========================================================================
     const { model } = await params;
     const resolved = resolveEntry(model);
     if ('error' in resolved) return resolved.error;
     const { entry } = resolved;
 
-    const body = (await req.json().catch(() => null)) as {
-      command?: string;
-    } | null;
-    const command = body?.command?.trim();
-    if (!command) {
-      return NextResponse.json(
-        { success: false, error: 'command is required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      runDevCommandRoute,
+      await req.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
+    const { command } = validation.data;
 
     // ==========================================================================
     // STEP 3: Parse shell command
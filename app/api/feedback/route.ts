@@ -62,8 +62,6 @@ import {
   createFeedbackEntry,
   feedbackSchema,
   logFeedbackCreateActivity,
-  deleteFeedbackSchema,
-  updateFeedbackSchema,
 } from '@/app/api/lib/helpers/feedbackOperations';
 import {
   handleFeedbackPatch,
@@ -73,6 +71,17 @@ import {
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { FeedbackModel } from '@/app/api/lib/models/feedback';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  deleteFeedbackRoute,
+  listFeedbackRoute,
+  patchFeedbackRoute,
+  updateFeedbackRoute,
+} from '@/app/api/lib/routeSchemas/feedback';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -264,12 +273,14 @@ export async function GET(request: NextRequest) {
     // ============================================================================
     // STEP 3: Parse query parameters and build query
     // ============================================================================
-    const { searchParams } = new URL(request.url);
-    const emailFilter = searchParams.get('email') || '';
-    const categoryFilter = searchParams.get('category') || '';
-    const statusFilter = searchParams.get('status') || '';
-    const page = parseInt(searchParams.get('page') || '1', 10);
-    const limit = parseInt(searchParams.get('limit') || '50', 10);
+    const parsed = parseQuery(listFeedbackRoute, request);
+    if (!parsed.success) {
+      return validationErrorResponse(parsed.error);
+    }
+    const { page, limit } = parsed.data;
+    const emailFilter = parsed.data.email ?? '';
+    const categoryFilter = parsed.data.category ?? '';
+    const statusFilter = parsed.data.status ?? '';
     const skip = (page - 1) * limit;
 
     const query = buildFeedbackQuery({ emailFilter, categoryFilter, statusFilter });
@@ -325,7 +336,7 @@ export async function GET(request: NextRequest) {
  * Flow:
  * 1. Connect to database
  * 2. Authenticate user and check admin role
- * 3. Parse request body and build update data
+ * 3. Parse and validate request body
  * 4. Pre-fetch existing feedback for before-state
  * 5. Execute update
  * 6. Log activity
@@ -346,11 +357,17 @@ export async function PATCH(request: NextRequest) {
     const { currentUser, userRoles } = authResult;
 
     // ============================================================================
-    // STEP 3: Parse body and delegate execution
+    // STEP 3: Parse and validate body, then delegate execution
     // ============================================================================
-    const body = await request.json();
+    const validation = parseBody(
+      patchFeedbackRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
+    }
 
-    return await handleFeedbackPatch(body, currentUser, userRoles, request, functionName, logUser, startTime);
+    return await handleFeedbackPatch(validation.data, currentUser, userRoles, request, functionName, logUser, startTime);
   } catch (e) {
     const errorMessage = e instanceof Error ? e.message : 'Failed to update feedback';
     logRouteError(functionName, 'PATCH', '/api/feedback', errorMessage, logUser);
@@ -391,14 +408,12 @@ export async function PUT(request: NextRequest) {
     // ============================================================================
     // STEP 3: Parse and validate request body
     // ============================================================================
-    const body = await request.json();
-    const validationResult = updateFeedbackSchema.safeParse(body);
-
+    const validationResult = parseBody(
+      updateFeedbackRoute,
+      await request.json().catch(() => null)
+    );
     if (!validationResult.success) {
-      return NextResponse.json(
-        { success: false, error: 'Validation failed', details: validationResult.error.errors },
-        { status: 400 }
-      );
+      return validationErrorResponse(validationResult.error);
     }
 
     // ============================================================================
@@ -449,14 +464,12 @@ export async function DELETE(request: NextRequest) {
     // ============================================================================
     // STEP 3: Parse and validate request body
     // ============================================================================
-    const body = await request.json();
-    const validationResult = deleteFeedbackSchema.safeParse(body);
-
+    const validationResult = parseBody(
+      deleteFeedbackRoute,
+      await request.json().catch(() => null)
+    );
     if (!validationResult.success) {
-      return NextResponse.json(
-        { success: false, error: 'Validation failed', details: validationResult.error.errors },
-        { status: 400 }
-      );
+      return validationErrorResponse(validationResult.error);
     }
 
     // ============================================================================
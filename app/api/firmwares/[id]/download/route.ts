@@ -42,7 +42,7 @@ export async function GET(request: NextRequest) {
 
     if (!id) {
       return NextResponse.json(
-        { error: 'Firmware ID is required' },
+        { success: false, error: 'Firmware ID is required' },
         { status: 400 }
       );
     }
@@ -54,7 +54,7 @@ export async function GET(request: NextRequest) {
       const firmware = await findFirmwareById(id);
       if (!firmware) {
         return NextResponse.json(
-          { error: 'Firmware not found' },
+          { success: false, error: 'Firmware not found' },
           { status: 404 }
         );
       }
@@ -44,7 +44,7 @@ export async function GET(request: NextRequest) {
 
     if (!id) {
       return NextResponse.json(
-        { error: 'Firmware ID is required' },
+        { success: false, error: 'Firmware ID is required' },
         { status: 400 }
       );
     }
@@ -53,7 +53,7 @@ export async function GET(request: NextRequest) {
       const firmware = await findFirmwareById(id);
       if (!firmware) {
         return NextResponse.json(
-          { error: 'Firmware not found' },
+          { success: false, error: 'Firmware not found' },
           { status: 404 }
         );
       }
@@ -116,7 +116,7 @@ export async function DELETE(request: NextRequest) {
     try {
       if (!id) {
         return NextResponse.json(
-          { message: 'Firmware ID is required' },
+          { success: false, message: 'Firmware ID is required' },
           { status: 400 }
         );
       }
@@ -127,7 +127,7 @@ export async function DELETE(request: NextRequest) {
       const firmwareToDeleteData = await findFirmwareById(id);
       if (!firmwareToDeleteData) {
         return NextResponse.json(
-          { message: 'Firmware not found' },
+          { success: false, message: 'Firmware not found' },
           { status: 404 }
         );
       }
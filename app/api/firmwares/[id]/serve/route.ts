@@ -52,7 +52,7 @@ export async function GET(request: NextRequest) {
       }).lean<FirmwareDocument>();
       if (!firmwareDoc) {
         return NextResponse.json(
-          { error: 'Firmware not found' },
+          { success: false, error: 'Firmware not found' },
           { status: 404 }
         );
       }
@@ -44,7 +44,7 @@ export async function GET(request: NextRequest) {
 
     if (!version) {
       return NextResponse.json(
-        { error: 'Firmware version is required' },
+        { success: false, error: 'Firmware version is required' },
         { status: 400 }
       );
     }
@@ -56,7 +56,7 @@ export async function GET(request: NextRequest) {
       const firmware = await findFirmwareByVersion(version);
       if (!firmware) {
         return NextResponse.json(
-          { error: `Firmware version ${version} not found` },
+          { success: false, error: `Firmware version ${version} not found` },
           { status: 404 }
         );
       }
@@ -39,7 +39,10 @@ export async function POST(request: NextRequest) {
     const user = extractUserFromRequest(request);
 
     if (!isAdminOrDev) {
-      return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
     }
 
     try {
@@ -122,7 +125,10 @@ export async function GET(request: NextRequest) {
     const user = extractUserFromRequest(request);
 
     if (!isAdminOrDev) {
-      return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
     }
 
     try {
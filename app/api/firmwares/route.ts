@@ -1,6 +1,16 @@
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Firmware } from '@/app/api/lib/models/firmware';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  listFirmwaresRoute,
+  uploadFirmwareRoute,
+} from '@/app/api/lib/routeSchemas/firmwares';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import type { FirmwareDocument } from '@/shared/types';
@@ -30,19 +40,17 @@ export async function GET(request: NextRequest) {
     const user = extractUserFromRequest(request);
     try {
       // ============================================================================
-      // STEP 1: Parse query parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const includeDeleted = searchParams.get('includeDeleted') === 'true';
+      const parsed = parseQuery(listFirmwaresRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const includeDeleted = parsed.data.includeDeleted === 'true';
 
       const query = includeDeleted
         ? {}
-        : {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          };
+        : { ...NOT_DELETED_FILTER };
 
       // ============================================================================
       // STEP 2: Fetch firmwares
@@ -100,25 +108,15 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse form data and validate
       // ============================================================================
-      const formData = await request.formData();
-      const product = formData.get('product') as string;
-      const version = formData.get('version') as string;
-      const versionDetails = formData.get('versionDetails') as string;
-      const file = formData.get('file') as File;
-
-      if (!product || !version || !file) {
-        return NextResponse.json(
-          { error: 'Product, version, and file are required' },
-          { status: 400 }
-        );
-      }
-
-      if (!file.name.endsWith('.bin')) {
-        return NextResponse.json(
-          { error: 'Only .bin files are allowed' },
-          { status: 400 }
-        );
+      const formData = await request.formData().catch(() => null);
+      const validation = parseBody(
+        uploadFirmwareRoute,
+        formData && Object.fromEntries(formData)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { product, version, versionDetails, file } = validation.data;
 
       // ============================================================================
       // STEP 3: Prepare file for upload
@@ -156,7 +154,7 @@ export async function POST(request: NextRequest) {
         fileId,
         fileName: file.name,
         fileSize: file.size,
-        deletedAt: new Date(-1),
+        deletedAt: null,
         releaseDate: new Date(),
         description: versionDetails || '',
         downloadUrl: '',
@@ -0,0 +1,232 @@
+/**
+ * Integrity Issue API Route
+ *
+ * Reads one integrity issue with its status history and comments, and works
+ * it through the queue (see integrityIssues): assign, move between statuses,
+ * comment.
+ *
+ * @module app/api/integrity-issues/[id]/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getIntegrityIssue,
+  integrityIssueUpdateSchema,
+  updateIntegrityIssue,
+} from '@/app/api/lib/helpers/integrityIssues';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  logRouteError,
+  logRouteFetch,
+  logRouteUpdate,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import type { IntegrityIssueDocument } from '@shared/types';
+import { NextRequest, NextResponse } from 'next/server';
+
+/** Issues without a location (e.g. machines missing one) are admin-only */
+async function canAccessIssue(
+  issue: IntegrityIssueDocument,
+  isAdminOrDev: boolean
+): Promise<boolean> {
+  if (isAdminOrDev) return true;
+  return issue.location ? checkUserLocationAccess(issue.location) : false;
+}
+
+/**
+ * GET /api/integrity-issues/[id]
+ *
+ * Returns the issue, 403 when its location is outside the caller's access,
+ * or 404.
+ */
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ id: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/integrity-issues/[id]';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    try {
+      const { id } = await params;
+      const issue = await getIntegrityIssue(id);
+      if (!issue) {
+        return NextResponse.json(
+          { success: false, error: 'Integrity issue not found' },
+          { status: 404 }
+        );
+      }
+      if (!(await canAccessIssue(issue, isAdminOrDev))) {
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
+      }
+
+      logRouteFetch(
+        functionName,
+        'GET',
+        `/api/integrity-issues/${id}`,
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: issue });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/integrity-issues/[id]',
+        errorMessage,
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * PATCH /api/integrity-issues/[id]
+ *
+ * Body:
+ * @param status     {string} Optional. Target status; moving to
+ *                   `investigating` claims an unassigned issue for the caller.
+ * @param assignedTo {string|null} Optional. Username to assign; null unassigns.
+ * @param note       {string} Optional. Recorded with the status change.
+ * @param comment    {string} Optional. Added to the issue's comments.
+ *
+ * Flow:
+ * 1. Validate the body
+ * 2. Check access to the issue's location
+ * 3. Apply assignment, status move and comment (409 on a move not allowed
+ *    from the current status)
+ * 4. Log activity
+ * 5. Return the updated issue
+ */
+export async function PATCH(
+  request: NextRequest,
+  { params }: { params: Promise<{ id: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'PATCH /api/integrity-issues/[id]';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, isAdminOrDev }) => {
+    try {
+      const { id } = await params;
+
+      // ============================================================================
+      // STEP 1: Validate the body
+      // ============================================================================
+      const body = await request.json().catch(() => null);
+      const validationResult = integrityIssueUpdateSchema.safeParse(body);
+      if (!validationResult.success) {
+        logRouteError(
+          functionName,
+          'PATCH',
+          '/api/integrity-issues/[id]',
+          'Validation failed',
+          user
+        );
+        return NextResponse.json(
+          {
+            success: false,
+            error: 'Validation failed',
+            details: validationResult.error.errors,
+          },
+          { status: 400 }
+        );
+      }
+      const input = validationResult.data;
+
+      // ============================================================================
+      // STEP 2: Check access to the issue's location
+      // ============================================================================
+      const current = await getIntegrityIssue(id);
+      if (!current) {
+        return NextResponse.json(
+          { success: false, error: 'Integrity issue not found' },
+          { status: 404 }
+        );
+      }
+      if (!(await canAccessIssue(current, isAdminOrDev))) {
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 3: Apply the update
+      // ============================================================================
+      const actor = String(
+        userPayload.username || userPayload.emailAddress || userPayload._id
+      );
+      const issue = await updateIntegrityIssue(id, input, actor);
+
+      // ============================================================================
+      // STEP 4: Log activity
+      // ============================================================================
+      try {
+        const changes = (['status', 'assignedTo'] as const)
+          .filter(field => issue[field] !== current[field])
+          .map(field => ({
+            field,
+            oldValue: current[field],
+            newValue: issue[field],
+          }));
+        await logActivity({
+          action: 'UPDATE',
+          details: `Updated integrity issue ${issue._id} (${issue.check})`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'integrityIssue',
+            resourceId: issue._id,
+            resourceName: issue.check,
+            changes,
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      // ============================================================================
+      // STEP 5: Return the updated issue
+      // ============================================================================
+      logRouteUpdate(
+        functionName,
+        'PATCH',
+        `/api/integrity-issues/${id}`,
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: issue });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error
+          ? error.message
+          : 'Failed to update integrity issue';
+      logRouteError(
+        functionName,
+        'PATCH',
+        '/api/integrity-issues/[id]',
+        errorMessage,
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -0,0 +1,77 @@
+/**
+ * Integrity Issues API Route
+ *
+ * Lists the issues found by the data integrity checks (`bun run integrity`)
+ * with the standard list params (see listEndpoint).
+ *
+ * @module app/api/integrity-issues/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { handleListRequest } from '@/app/api/lib/helpers/listEndpoint';
+import {
+  integrityIssueListResource,
+} from '@/app/api/lib/helpers/listResources';
+import type {
+  IntegrityIssueListItem,
+} from '@/app/api/lib/helpers/listResources';
+import {
+  logRouteError,
+  logRouteFetch,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/integrity-issues
+ *
+ * Query params: `limit`, `cursor`, `sort` (detectedAt, readAt, zScore,
+ * check; default -detectedAt), `licencee`, `location`, `status` (open,
+ * investigating, fixed, verified, confirmed, dismissed) and `from` / `to` on
+ * `detectedAt`.
+ *
+ * Returns `{ success, data, pagination, sort, filters }`.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/integrity-issues';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async auth => {
+    try {
+      const page = await handleListRequest<IntegrityIssueListItem>(
+        request,
+        integrityIssueListResource,
+        auth
+      );
+
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/integrity-issues',
+        page.data.length,
+        user,
+        duration
+      );
+      return NextResponse.json(page);
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error
+          ? error.message
+          : 'Failed to fetch integrity issues';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/integrity-issues',
+        errorMessage,
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -1,3 +1,4 @@
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type {
   AcceptedBill as AcceptedBillType,
   MachineEvent as MachineEventType,
@@ -267,10 +268,7 @@ async function countMachinesForLocation(
   try {
     return await Machine.countDocuments({
       gamingLocation: locationId,
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
+      ...NOT_DELETED_FILTER,
     });
   } catch (error) {
     console.error(
@@ -0,0 +1,354 @@
+/**
+ * Aggregation Fan-Out Helper
+ *
+ * Licencee-wide pipelines that touch every machine in one aggregation can time
+ * out on large licencees. The fan-out strategy runs the same pipeline once per
+ * location with bounded concurrency and merges the partial results in the
+ * app, so each aggregation stays small and index-friendly.
+ *
+ * Strategy selection (first match wins):
+ * - `strategy` query param / explicit override (`single` | `fanout`)
+ * - `AGGREGATION_STRATEGY` environment variable
+ * - `single` (one pipeline, previous behaviour)
+ *
+ * Per-location aggregations from every request share one process-wide pool,
+ * so concurrent dashboards do not multiply the load on the cluster:
+ * - `FAN_OUT_CONCURRENCY` caps aggregations in flight (default 4, max 16)
+ * - `FAN_OUT_MAX_QUEUE` caps aggregations waiting for a slot (default 64);
+ *   beyond it a fan-out is rejected with a 503 instead of piling up
+ * - `FAN_OUT_TARGET_LATENCY_MS` (default 2000, 0 = off) adapts the limit:
+ *   it steps down while the smoothed aggregation latency is above the
+ *   target and back up while it is under half of it
+ *
+ * Each fan-out reports its run stats (tasks, latency, wait, peak in flight,
+ * worker utilization) to the caller's `onStats`, logged by default.
+ *
+ * @module app/api/lib/helpers/aggregationFanOut
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type AggregationStrategy = 'single' | 'fanout';
+
+export type FanOutRunStats = {
+  tasks: number;
+  wallMs: number;
+  averageLatencyMs: number;
+  maxLatencyMs: number;
+  /** Average time a task waited for a pool slot */
+  averageWaitMs: number;
+  peakInFlight: number;
+  limitAtStart: number;
+  limitAtEnd: number;
+  /** Busy time over wall time × limit at start (0–1) */
+  utilization: number;
+};
+
+export type FanOutPoolStats = {
+  limit: number;
+  maxConcurrency: number;
+  inFlight: number;
+  queued: number;
+  maxQueue: number;
+  latencyEwmaMs: number | null;
+  targetLatencyMs: number;
+  rejected: number;
+};
+
+const DEFAULT_CONCURRENCY = 4;
+const MAX_CONCURRENCY = 16;
+const DEFAULT_MAX_QUEUE = 64;
+const DEFAULT_TARGET_LATENCY_MS = 2000;
+/** Weight of the newest latency in the moving average */
+const LATENCY_SMOOTHING = 0.2;
+
+const pool = {
+  limit: 0,
+  inFlight: 0,
+  queue: [] as Array<() => void>,
+  latencyEwmaMs: null as number | null,
+  completedSinceAdjust: 0,
+  rejected: 0,
+};
+
+// ============================================================================
+// Configuration
+// ============================================================================
+
+function isStrategy(value: unknown): value is AggregationStrategy {
+  return value === 'single' || value === 'fanout';
+}
+
+/**
+ * Resolves the aggregation strategy for a request.
+ *
+ * @param override - Explicit strategy (e.g. from a query param); ignored when invalid
+ */
+export function getAggregationStrategy(
+  override?: string | null
+): AggregationStrategy {
+  if (isStrategy(override)) return override;
+  const fromEnv = process.env.AGGREGATION_STRATEGY;
+  return isStrategy(fromEnv) ? fromEnv : 'single';
+}
+
+export function getFanOutConcurrency(): number {
+  const parsed = Number(process.env.FAN_OUT_CONCURRENCY);
+  if (!Number.isFinite(parsed) || parsed < 1) return DEFAULT_CONCURRENCY;
+  return Math.min(Math.floor(parsed), MAX_CONCURRENCY);
+}
+
+export function getFanOutMaxQueue(): number {
+  const parsed = Number(process.env.FAN_OUT_MAX_QUEUE);
+  if (!Number.isFinite(parsed) || parsed < 0) return DEFAULT_MAX_QUEUE;
+  return Math.floor(parsed);
+}
+
+export function getFanOutTargetLatencyMs(): number {
+  const raw = process.env.FAN_OUT_TARGET_LATENCY_MS;
+  const parsed = Number(raw);
+  if (!raw || !Number.isFinite(parsed) || parsed < 0) {
+    return DEFAULT_TARGET_LATENCY_MS;
+  }
+  return parsed;
+}
+
+// ============================================================================
+// Pool
+// ============================================================================
+
+function busyError(): Error {
+  return statusError(
+    'Aggregation queue is full; try again shortly or use strategy=single',
+    503
+  );
+}
+
+/** Current limit, clamped to the configured maximum */
+function currentLimit(): number {
+  const max = getFanOutConcurrency();
+  if (pool.limit < 1 || pool.limit > max) pool.limit = max;
+  return pool.limit;
+}
+
+/**
+ * Waits for a pool slot.
+ *
+ * @returns Time spent waiting, in ms
+ * @throws Error with `statusCode = 503` when the queue is full
+ */
+async function acquireSlot(): Promise<number> {
+  if (pool.inFlight < currentLimit()) {
+    pool.inFlight++;
+    return 0;
+  }
+  if (pool.queue.length >= getFanOutMaxQueue()) {
+    pool.rejected++;
+    throw busyError();
+  }
+  const queuedAt = Date.now();
+  await new Promise<void>(resolve => pool.queue.push(resolve));
+  return Date.now() - queuedAt;
+}
+
+function releaseSlot() {
+  pool.inFlight--;
+  while (pool.inFlight < currentLimit() && pool.queue.length > 0) {
+    pool.inFlight++;
+    pool.queue.shift()?.();
+  }
+}
+
+/**
+ * Folds a latency into the moving average and, once per `limit` completed
+ * aggregations, steps the limit down when over target or up when well under.
+ */
+function recordLatency(latencyMs: number) {
+  pool.latencyEwmaMs =
+    pool.latencyEwmaMs === null
+      ? latencyMs
+      : pool.latencyEwmaMs +
+        LATENCY_SMOOTHING * (latencyMs - pool.latencyEwmaMs);
+  pool.completedSinceAdjust++;
+
+  const target = getFanOutTargetLatencyMs();
+  const limit = currentLimit();
+  if (target === 0 || pool.completedSinceAdjust < limit) return;
+  pool.completedSinceAdjust = 0;
+  if (pool.latencyEwmaMs > target && limit > 1) {
+    pool.limit = limit - 1;
+  } else if (
+    pool.latencyEwmaMs < target / 2 &&
+    limit < getFanOutConcurrency()
+  ) {
+    pool.limit = limit + 1;
+  }
+}
+
+/**
+ * Snapshot of the process-wide pool.
+ */
+export function getFanOutPoolStats(): FanOutPoolStats {
+  return {
+    limit: currentLimit(),
+    maxConcurrency: getFanOutConcurrency(),
+    inFlight: pool.inFlight,
+    queued: pool.queue.length,
+    maxQueue: getFanOutMaxQueue(),
+    latencyEwmaMs: pool.latencyEwmaMs,
+    targetLatencyMs: getFanOutTargetLatencyMs(),
+    rejected: pool.rejected,
+  };
+}
+
+// ============================================================================
+// Execution
+// ============================================================================
+
+/**
+ * Runs `worker` over `items` with at most `concurrency` in flight.
+ *
+ * @returns Results in the same order as `items`
+ */
+export async function runBounded<T, R>(
+  items: T[],
+  concurrency: number,
+  worker: (item: T) => Promise<R>
+): Promise<R[]> {
+  const results = new Array<R>(items.length);
+  let next = 0;
+
+  const lanes = Array.from(
+    { length: Math.min(Math.max(concurrency, 1), items.length) },
+    async () => {
+      while (next < items.length) {
+        const index = next++;
+        results[index] = await worker(items[index]);
+      }
+    }
+  );
+  await Promise.all(lanes);
+
+  return results;
+}
+
+/**
+ * Runs `worker` over `items` through the process-wide pool (see the module
+ * doc), then reports the run's stats.
+ *
+ * @param onStats - Receives the run stats
+ * @returns Results in the same order as `items`
+ * @throws Error with `statusCode = 503` when the pool queue is full
+ */
+export async function runPooled<T, R>(
+  items: T[],
+  worker: (item: T) => Promise<R>,
+  onStats?: (stats: FanOutRunStats) => void
+): Promise<R[]> {
+  if (items.length > 0 && pool.queue.length >= getFanOutMaxQueue()) {
+    pool.rejected++;
+    throw busyError();
+  }
+
+  const startedAt = Date.now();
+  const limitAtStart = currentLimit();
+  let busyMs = 0;
+  let waitMs = 0;
+  let maxLatencyMs = 0;
+  let inFlight = 0;
+  let peakInFlight = 0;
+
+  // Lanes bound what one run queues at a time; the pool bounds the process
+  const lanes = getFanOutConcurrency();
+  const results = await runBounded(items, lanes, async item => {
+    waitMs += await acquireSlot();
+    inFlight++;
+    peakInFlight = Math.max(peakInFlight, inFlight);
+    const taskStartedAt = Date.now();
+    try {
+      return await worker(item);
+    } finally {
+      const latencyMs = Date.now() - taskStartedAt;
+      busyMs += latencyMs;
+      maxLatencyMs = Math.max(maxLatencyMs, latencyMs);
+      inFlight--;
+      recordLatency(latencyMs);
+      releaseSlot();
+    }
+  });
+
+  const wallMs = Date.now() - startedAt;
+  onStats?.({
+    tasks: items.length,
+    wallMs,
+    averageLatencyMs: items.length ? Math.round(busyMs / items.length) : 0,
+    maxLatencyMs,
+    averageWaitMs: items.length ? Math.round(waitMs / items.length) : 0,
+    peakInFlight,
+    limitAtStart,
+    limitAtEnd: currentLimit(),
+    utilization:
+      wallMs > 0
+        ? Math.min(1, Number((busyMs / (wallMs * limitAtStart)).toFixed(2)))
+        : 0,
+  });
+  return results;
+}
+
+function logFanOutStats(licencee: string, stats: FanOutRunStats) {
+  console.log(
+    `[fanOut] licencee=${licencee} tasks=${stats.tasks} wall=${stats.wallMs}ms avg=${stats.averageLatencyMs}ms max=${stats.maxLatencyMs}ms wait=${stats.averageWaitMs}ms peak=${stats.peakInFlight} limit=${stats.limitAtStart}->${stats.limitAtEnd} utilization=${stats.utilization}`
+  );
+}
+
+/**
+ * Runs `worker` once per location of a licencee through the pool. Deleted
+ * locations are included so the merged result matches the single-pipeline
+ * scope.
+ *
+ * @param licencee - Licencee ID
+ * @param worker - Per-location aggregation
+ * @param onStats - Receives the run stats (default: logged)
+ * @returns Partial results, one per location
+ */
+export async function fanOutByLocation<R>(
+  licencee: string,
+  worker: (locationId: string) => Promise<R>,
+  onStats: (stats: FanOutRunStats) => void = stats =>
+    logFanOutStats(licencee, stats)
+): Promise<R[]> {
+  const locations = await GamingLocations.find(
+    { 'rel.licencee': licencee },
+    { _id: 1 }
+  ).lean<Array<{ _id: string }>>();
+
+  return runPooled(
+    locations.map(location => String(location._id)),
+    worker,
+    onStats
+  );
+}
+
+/**
+ * Sums numeric fields of partial results into one object. Non-numeric
+ * fields keep the first value seen.
+ */
+export function sumPartials<T extends Record<string, unknown>>(
+  partials: T[]
+): Partial<T> {
+  return partials.reduce<Record<string, unknown>>((merged, partial) => {
+    Object.entries(partial).forEach(([key, value]) => {
+      if (typeof value === 'number') {
+        merged[key] = ((merged[key] as number) || 0) + value;
+      } else if (!(key in merged)) {
+        merged[key] = value;
+      }
+    });
+    return merged;
+  }, {}) as Partial<T>;
+}
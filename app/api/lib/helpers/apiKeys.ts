@@ -0,0 +1,401 @@
+/**
+ * API Keys Helper
+ *
+ * API keys let machine-to-machine clients (kiosk software, reporting bots)
+ * call the API without user credentials. A client sends its key in the
+ * `X-API-Key` header; `withApiAuth()` accepts it when the request has no
+ * session, so keys work on every route wrapped by it.
+ *
+ * Keys are stored in `apikeys` as a SHA-256 hash; the key itself is shown
+ * once, on creation. Each key is limited to:
+ *
+ * - **Licencees**: the key sees the locations of these licencees, as a
+ *   manager assigned to them would.
+ * - **Scopes**: endpoint groups (API_KEY_SCOPE_GROUPS), either `group` for
+ *   every method or `group:read` for GET only. Paths outside every group
+ *   (users, auth, admin, API keys, ...) are never reachable with a key.
+ *
+ * While a key's request runs, `getUserFromServer()` resolves to the key's
+ * user (see `runAsApiKey()`), so helpers that look up the caller themselves
+ * (licencee and location filters) scope to the key's licencees.
+ *
+ * Revoked and expired keys are refused. A key may carry its own quota
+ * (see apiQuotas); it is the only place per-key quotas are kept. Managed through `/api/api-keys` (admin / developer) and
+ * the `api-keys` command (scripts/api-keys.ts).
+ *
+ * @module app/api/lib/helpers/apiKeys
+ */
+
+import { ApiKey } from '@/app/api/lib/models/apiKey';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+import type { ApiKeyDocument, ApiKeyQuota } from '@shared/types';
+import { AsyncLocalStorage } from 'async_hooks';
+import { createHash, randomBytes } from 'crypto';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type CreateApiKeyInput = {
+  name: string;
+  licencees: string[];
+  scopes: string[];
+  quota?: ApiKeyQuota;
+  description?: string;
+  expiresAt?: Date | null;
+  createdBy: string | null;
+};
+
+/** A key as listed: everything but the hash */
+export type ApiKeySummary = Omit<ApiKeyDocument, 'keyHash'>;
+
+/** The user a key acts as: a manager of its licencees */
+export type ApiKeyUser = {
+  _id: string;
+  username: string;
+  roles: string[];
+  assignedLicencees: string[];
+  assignedLocations: string[];
+};
+
+export const API_KEY_HEADER = 'x-api-key';
+
+/** Endpoint groups a key can be scoped to, by path prefix */
+export const API_KEY_SCOPE_GROUPS: Record<string, string[]> = {
+  machines: [
+    '/api/machines',
+    '/api/cabinets',
+    '/api/smib',
+    '/api/firmwares',
+    '/api/bill-validator',
+    '/api/manufacturers',
+  ],
+  locations: ['/api/locations', '/api/locationAggregation', '/api/countries'],
+  reports: [
+    '/api/reports',
+    '/api/analytics',
+    '/api/metrics',
+    '/api/integrity-issues',
+    '/api/accounting-details',
+  ],
+  collections: [
+    '/api/collection-reports',
+    '/api/collection-reports-v2',
+    '/api/movement-requests',
+    '/api/schedulers',
+  ],
+  members: ['/api/members', '/api/sessions'],
+  vault: ['/api/vault', '/api/cashier'],
+  licencees: ['/api/licencees', '/api/rates'],
+};
+
+const KEY_PREFIX = 'cms_';
+const NAME_PATTERN = /^[A-Za-z0-9_-]{1,64}$/;
+const READ_METHODS = ['GET', 'HEAD'];
+/** lastUsedAt is written at most this often per key */
+const LAST_USED_INTERVAL_MS = 60 * 1000;
+const QUOTA_FIELDS: Array<keyof ApiKeyQuota> = [
+  'requestsPerMinute',
+  'burst',
+  'concurrentAggregations',
+];
+
+const lastUsedWrites = new Map<string, number>();
+const apiKeyUserContext = new AsyncLocalStorage<ApiKeyUser>();
+
+export function hashApiKey(key: string): string {
+  return createHash('sha256').update(key).digest('hex');
+}
+
+function toSummary(key: ApiKeyDocument): ApiKeySummary {
+  const { keyHash: _keyHash, ...summary } = key;
+  return summary;
+}
+
+// ============================================================================
+// Scopes
+// ============================================================================
+
+/**
+ * The endpoint group a path belongs to, or null when keys cannot reach it.
+ */
+export function getApiKeyScopeGroup(pathname: string): string | null {
+  const match = Object.entries(API_KEY_SCOPE_GROUPS).find(([, prefixes]) =>
+    prefixes.some(
+      prefix => pathname === prefix || pathname.startsWith(`${prefix}/`)
+    )
+  );
+  return match ? match[0] : null;
+}
+
+/**
+ * Whether a key's scopes allow a request.
+ *
+ * @param scopes - The key's scopes
+ * @param pathname - Request path
+ * @param method - Request method
+ */
+export function apiKeyScopeAllows(
+  scopes: string[],
+  pathname: string,
+  method: string
+): boolean {
+  const group = getApiKeyScopeGroup(pathname);
+  if (!group) return false;
+  return (
+    scopes.includes(group) ||
+    (scopes.includes(`${group}:read`) &&
+      READ_METHODS.includes(method.toUpperCase()))
+  );
+}
+
+/**
+ * @throws Error with `statusCode = 400` naming the first unknown scope
+ */
+export function validateApiKeyScopes(scopes: string[]): void {
+  if (scopes.length === 0) throw statusError('Give at least one scope', 400);
+  const unknown = scopes.find(
+    scope => !(scope.replace(/:read$/, '') in API_KEY_SCOPE_GROUPS)
+  );
+  if (unknown) {
+    throw statusError(
+      `Unknown scope '${unknown}'; use ${Object.keys(API_KEY_SCOPE_GROUPS).join(', ')} (optionally with :read)`,
+      400
+    );
+  }
+}
+
+/**
+ * @throws Error with `statusCode = 400` naming the first invalid field
+ */
+function validateApiKeyQuota(quota: ApiKeyQuota | undefined): void {
+  const invalidQuota = QUOTA_FIELDS.find(field => {
+    const value = quota?.[field];
+    return value !== undefined && (!Number.isInteger(value) || value < 0);
+  });
+  if (invalidQuota) {
+    throw statusError(
+      `quota.${invalidQuota} must be a whole number of 0 or more`,
+      400
+    );
+  }
+}
+
+// ============================================================================
+// Management
+// ============================================================================
+
+/**
+ * Creates a key.
+ *
+ * @returns The stored key (without its hash) and the key itself, which is
+ * not retrievable afterwards
+ * @throws Error with `statusCode` 400 on invalid input, 404 for an unknown
+ * licencee, 409 when the name is taken, 423 in read-only mode
+ */
+export async function createApiKey(
+  input: CreateApiKeyInput
+): Promise<{ apiKey: ApiKeySummary; key: string }> {
+  assertWritable('API key creation');
+  if (!NAME_PATTERN.test(input.name || '')) {
+    throw statusError(
+      'name must be 1-64 letters, digits, - or _ characters',
+      400
+    );
+  }
+  const licencees = Array.from(new Set(input.licencees.filter(Boolean)));
+  if (licencees.length === 0) {
+    throw statusError('Give at least one licencee', 400);
+  }
+  const scopes = Array.from(new Set(input.scopes.filter(Boolean)));
+  validateApiKeyScopes(scopes);
+  validateApiKeyQuota(input.quota);
+  if (input.expiresAt && input.expiresAt.getTime() <= Date.now()) {
+    throw statusError('expiresAt must be in the future', 400);
+  }
+
+  const found = await Licencee.find(
+    { _id: { $in: licencees } },
+    { _id: 1 }
+  ).lean<Array<{ _id: string }>>();
+  const missing = licencees.find(
+    id => !found.some(licencee => String(licencee._id) === id)
+  );
+  if (missing) throw statusError(`Licencee ${missing} not found`, 404);
+  if (await ApiKey.exists({ name: input.name })) {
+    throw statusError(`An API key named '${input.name}' already exists`, 409);
+  }
+
+  const key = `${KEY_PREFIX}${randomBytes(24).toString('base64url')}`;
+  const document = await ApiKey.create({
+    _id: await generateMongoId(),
+    name: input.name,
+    prefix: key.slice(0, KEY_PREFIX.length + 6),
+    keyHash: hashApiKey(key),
+    licencees,
+    scopes,
+    ...(input.quota ? { quota: input.quota } : {}),
+    description: input.description || '',
+    createdBy: input.createdBy,
+    expiresAt: input.expiresAt ?? null,
+  });
+  return {
+    apiKey: toSummary(document.toObject() as ApiKeyDocument),
+    key,
+  };
+}
+
+/**
+ * All keys, newest first, revoked ones included.
+ */
+export async function listApiKeys(): Promise<ApiKeySummary[]> {
+  const keys = await ApiKey.find({}, { keyHash: 0 })
+    .sort({ createdAt: -1 })
+    .lean<ApiKeySummary[]>();
+  return keys;
+}
+
+/**
+ * Revokes a key by id or name; requests with it fail from then on.
+ *
+ * @returns The revoked key
+ * @throws Error with `statusCode` 404 when no such key exists, 409 when it
+ * is already revoked, 423 in read-only mode
+ */
+export async function revokeApiKey(
+  idOrName: string,
+  revokedBy: string | null
+): Promise<ApiKeySummary> {
+  assertWritable('API key revocation');
+  const existing = await ApiKey.findOne(
+    { $or: [{ _id: idOrName }, { name: idOrName }] },
+    { keyHash: 0 }
+  ).lean<ApiKeySummary>();
+  if (!existing) throw statusError(`API key ${idOrName} not found`, 404);
+  if (existing.revokedAt) {
+    throw statusError(`API key ${existing.name} is already revoked`, 409);
+  }
+  const revoked = await ApiKey.findOneAndUpdate(
+    { _id: existing._id, revokedAt: null },
+    { $set: { revokedAt: new Date(), revokedBy } },
+    { new: true, projection: { keyHash: 0 } }
+  ).lean<ApiKeySummary>();
+  if (!revoked) {
+    throw statusError(`API key ${existing.name} is already revoked`, 409);
+  }
+  return revoked;
+}
+
+/**
+ * Sets a key's quota by id or name; `null` clears it so the key uses the
+ * default quota. Takes effect on the key's next request.
+ *
+ * @returns The updated key
+ * @throws Error with `statusCode` 400 on an invalid quota, 404 when no such
+ * key exists, 423 in read-only mode
+ */
+export async function setApiKeyQuota(
+  idOrName: string,
+  quota: ApiKeyQuota | null
+): Promise<ApiKeySummary> {
+  assertWritable('API key quota change');
+  validateApiKeyQuota(quota ?? undefined);
+  const updated = await ApiKey.findOneAndUpdate(
+    { $or: [{ _id: idOrName }, { name: idOrName }] },
+    quota ? { $set: { quota } } : { $unset: { quota: '' } },
+    { new: true, projection: { keyHash: 0 } }
+  ).lean<ApiKeySummary>();
+  if (!updated) throw statusError(`API key ${idOrName} not found`, 404);
+  return updated;
+}
+
+// ============================================================================
+// Authentication
+// ============================================================================
+
+/**
+ * Resolves the key a request carries and checks it covers the request.
+ *
+ * @param key - `X-API-Key` header value
+ * @param pathname - Request path
+ * @param method - Request method
+ * @returns The key (without its hash)
+ * @throws Error with `statusCode` 401 for an unknown, revoked or expired
+ * key, 403 when its scopes do not cover the path and method
+ */
+export async function authenticateApiKey(
+  key: string,
+  pathname: string,
+  method: string
+): Promise<ApiKeySummary> {
+  const apiKey = await ApiKey.findOne(
+    { keyHash: hashApiKey(key.trim()) },
+    { keyHash: 0 }
+  ).lean<ApiKeySummary>();
+  const now = new Date();
+  if (
+    !apiKey ||
+    apiKey.revokedAt ||
+    (apiKey.expiresAt && apiKey.expiresAt <= now)
+  ) {
+    throw statusError('Invalid API key', 401);
+  }
+  if (!apiKeyScopeAllows(apiKey.scopes, pathname, method)) {
+    throw statusError(
+      `API key ${apiKey.name} is not scoped for ${method.toUpperCase()} ${pathname}`,
+      403
+    );
+  }
+
+  const lastWrite = lastUsedWrites.get(apiKey._id) ?? 0;
+  if (now.getTime() - lastWrite > LAST_USED_INTERVAL_MS) {
+    lastUsedWrites.set(apiKey._id, now.getTime());
+    ApiKey.updateOne(
+      { _id: apiKey._id },
+      { $set: { lastUsedAt: now } }
+    ).catch(error => {
+      console.error(
+        '[apiKeys] Failed to record lastUsedAt:',
+        error instanceof Error ? error.message : error
+      );
+    });
+  }
+  return apiKey;
+}
+
+/**
+ * The user a key acts as.
+ */
+export function apiKeyUser(apiKey: ApiKeySummary): ApiKeyUser {
+  return {
+    _id: `apikey:${apiKey._id}`,
+    username: `apikey:${apiKey.name}`,
+    roles: ['manager'],
+    assignedLicencees: apiKey.licencees,
+    assignedLocations: [],
+  };
+}
+
+/**
+ * Runs a request handler as the key's user, so `getApiKeyUser()` (and
+ * through it `getUserFromServer()`) resolves to it. Without a key the
+ * handler runs as is.
+ */
+export function runAsApiKey<T>(
+  apiKey: ApiKeySummary | undefined,
+  handler: () => Promise<T>
+): Promise<T> {
+  return apiKey
+    ? apiKeyUserContext.run(apiKeyUser(apiKey), handler)
+    : handler();
+}
+
+/**
+ * The key user of the request being handled, if it authenticated with a key.
+ */
+export function getApiKeyUser(): ApiKeyUser | null {
+  return apiKeyUserContext.getStore() ?? null;
+}
@@ -0,0 +1,497 @@
+/**
+ * API Rate Limits and Quotas
+ *
+ * Protects the cluster from a single client flooding the HTTP API. Every
+ * request through `withApiAuth()` is attributed to a client and counted
+ * against its quota:
+ *
+ * - **Requests**: a token bucket refilled at `requestsPerMinute`, holding up
+ *   to `burst` requests. An empty bucket answers `429` with `Retry-After`.
+ * - **Aggregations**: at most `concurrentAggregations` of the client's
+ *   aggregations run at once. Extra ones wait for a slot up to
+ *   `API_AGGREGATION_WAIT_MS` (default 5000), then fail with `429`.
+ *
+ * The client is the API key the request authenticated with (see apiKeys),
+ * else the signed-in user, else the caller's IP. A key's quota is the one
+ * stored on the key (`api-keys set-quota`), with omitted fields taken from
+ * the default.
+ *
+ * The default quota comes from `api-quotas.json` at the project root (or the
+ * file named by `API_QUOTAS_FILE`; copy `api-quotas.example.json` to start),
+ * re-read when it changes:
+ *
+ * ```json
+ * { "default": { "requestsPerMinute": 600, "concurrentAggregations": 6 } }
+ * ```
+ *
+ * Per-key entries (`keys`) in that file are no longer read; a warning names
+ * them so they can be moved onto the keys.
+ *
+ * Omitted fields fall back to the built-in default; `burst` defaults to ten
+ * seconds' worth of requests and `0` disables a limit. Counters live in
+ * memory per app instance, so each instance enforces the quota on its own
+ * share of the traffic. `getApiQuotaStats()` reports throttling, served by
+ * `GET /api/admin/rate-limits`.
+ *
+ * @module app/api/lib/helpers/apiQuotas
+ */
+
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { getClientIP } from '@/lib/utils/ipAddress';
+import { AsyncLocalStorage } from 'async_hooks';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import type { Aggregate } from 'mongoose';
+import type { NextRequest } from 'next/server';
+import path from 'path';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ApiQuota = {
+  /** Sustained requests per minute; 0 for no limit */
+  requestsPerMinute: number;
+  /** Requests accepted at once on top of the sustained rate */
+  burst: number;
+  /** Aggregations running at once; 0 for no limit */
+  concurrentAggregations: number;
+};
+
+export type ApiQuotasConfig = {
+  default: ApiQuota;
+};
+
+export type ApiClientKind = 'key' | 'user' | 'ip';
+
+export type ApiClient = {
+  /** `key:<name>`, `user:<id>` or `ip:<address>` */
+  id: string;
+  kind: ApiClientKind;
+  quota: ApiQuota;
+};
+
+export type RequestQuotaResult =
+  | { allowed: true }
+  | { allowed: false; retryAfterSeconds: number };
+
+export type ApiClientQuotaStats = {
+  client: string;
+  kind: ApiClientKind;
+  quota: ApiQuota;
+  requests: number;
+  /** Requests refused with 429 */
+  throttled: number;
+  /** Aggregations that had to wait for a slot */
+  aggregationsQueued: number;
+  /** Aggregations refused with 429 after waiting */
+  aggregationsThrottled: number;
+  aggregationsInFlight: number;
+  lastThrottledAt: Date | null;
+};
+
+export type ApiQuotaStats = {
+  since: Date;
+  totals: {
+    requests: number;
+    throttled: number;
+    aggregationsQueued: number;
+    aggregationsThrottled: number;
+  };
+  quotas: {
+    default: ApiQuota;
+  };
+  /** Clients seen in the last hour, most throttled first */
+  clients: ApiClientQuotaStats[];
+};
+
+export const DEFAULT_API_QUOTA: ApiQuota = {
+  requestsPerMinute: 600,
+  burst: 100,
+  concurrentAggregations: 6,
+};
+
+const DEFAULT_QUOTAS_FILE = 'api-quotas.json';
+const DEFAULT_AGGREGATION_WAIT_MS = 5000;
+const QUOTA_FIELDS: Array<keyof ApiQuota> = [
+  'requestsPerMinute',
+  'burst',
+  'concurrentAggregations',
+];
+/** Idle clients are forgotten after this long */
+const CLIENT_TTL_MS = 60 * 60 * 1000;
+const PRUNE_INTERVAL_MS = 60 * 1000;
+const MAX_REPORTED_CLIENTS = 100;
+
+type ClientState = {
+  kind: ApiClientKind;
+  quota: ApiQuota;
+  tokens: number;
+  refilledAt: number;
+  lastSeenAt: number;
+  aggregationsInFlight: number;
+  waiting: Array<() => void>;
+  requests: number;
+  throttled: number;
+  aggregationsQueued: number;
+  aggregationsThrottled: number;
+  lastThrottledAt: number | null;
+};
+
+let quotasCache: {
+  file: string;
+  mtimeMs: number;
+  config: ApiQuotasConfig;
+} | null = null;
+
+const clients = new Map<string, ClientState>();
+const totals = {
+  requests: 0,
+  throttled: 0,
+  aggregationsQueued: 0,
+  aggregationsThrottled: 0,
+};
+const startedAt = new Date();
+let prunedAt = Date.now();
+let installed = false;
+
+const clientContext = new AsyncLocalStorage<ApiClient>();
+
+// ============================================================================
+// Configuration
+// ============================================================================
+
+function resolveQuota(
+  definition: Partial<ApiQuota>,
+  fallback: ApiQuota
+): ApiQuota {
+  const requestsPerMinute =
+    definition.requestsPerMinute ?? fallback.requestsPerMinute;
+  return {
+    requestsPerMinute,
+    burst:
+      definition.burst ??
+      (definition.requestsPerMinute !== undefined
+        ? Math.max(1, Math.ceil(requestsPerMinute / 6))
+        : fallback.burst),
+    concurrentAggregations:
+      definition.concurrentAggregations ?? fallback.concurrentAggregations,
+  };
+}
+
+function validateQuota(
+  definition: Partial<ApiQuota>,
+  label: string
+): string | null {
+  const invalid = QUOTA_FIELDS.find(
+    field =>
+      definition[field] !== undefined &&
+      (!Number.isInteger(definition[field]) ||
+        (definition[field] as number) < 0)
+  );
+  return invalid
+    ? `${label}: ${invalid} must be a whole number of 0 or more`
+    : null;
+}
+
+/**
+ * The default quota, cached until the quotas file changes on disk.
+ *
+ * @throws Error when the file is invalid
+ */
+export function loadApiQuotas(): ApiQuotasConfig {
+  const file = path.resolve(
+    process.cwd(),
+    process.env.API_QUOTAS_FILE || DEFAULT_QUOTAS_FILE
+  );
+  if (!fs.existsSync(file)) return { default: DEFAULT_API_QUOTA };
+
+  const { mtimeMs } = fs.statSync(file);
+  if (
+    quotasCache &&
+    quotasCache.file === file &&
+    quotasCache.mtimeMs === mtimeMs
+  ) {
+    return quotasCache.config;
+  }
+
+  const configured = JSON.parse(fs.readFileSync(file, 'utf8')) as {
+    default?: Partial<ApiQuota>;
+    keys?: Record<string, unknown>;
+  };
+  const invalid = validateQuota(configured.default || {}, 'default');
+  if (invalid) throw new Error(`${file}: ${invalid}`);
+  const legacyKeys = Object.keys(configured.keys || {});
+  if (legacyKeys.length > 0) {
+    console.warn(
+      `[apiQuotas] ${file}: ignoring per-key quotas for ${legacyKeys.join(', ')}; set them on the keys with \`api-keys set-quota\``
+    );
+  }
+
+  const config: ApiQuotasConfig = {
+    default: resolveQuota(configured.default || {}, DEFAULT_API_QUOTA),
+  };
+  quotasCache = { file, mtimeMs, config };
+  return config;
+}
+
+export function getAggregationWaitMs(): number {
+  const raw = process.env.API_AGGREGATION_WAIT_MS;
+  const parsed = Number(raw);
+  if (!raw || !Number.isFinite(parsed) || parsed < 0) {
+    return DEFAULT_AGGREGATION_WAIT_MS;
+  }
+  return parsed;
+}
+
+// ============================================================================
+// Clients
+// ============================================================================
+
+/**
+ * The client a request counts against: its API key, else the signed-in
+ * user, else the caller's IP.
+ *
+ * @param request - Incoming request
+ * @param userId - Signed-in user's id, when authenticated
+ * @param apiKey - The authenticated API key, with its stored quota
+ */
+export function identifyApiClient(
+  request: NextRequest,
+  userId: string | null,
+  apiKey?: { name: string; quota?: Partial<ApiQuota> }
+): ApiClient {
+  const config = loadApiQuotas();
+  if (apiKey) {
+    return {
+      id: `key:${apiKey.name}`,
+      kind: 'key',
+      quota: resolveQuota(apiKey.quota || {}, config.default),
+    };
+  }
+  if (userId) {
+    return { id: `user:${userId}`, kind: 'user', quota: config.default };
+  }
+  return {
+    id: `ip:${getClientIP(request) || 'unknown'}`,
+    kind: 'ip',
+    quota: config.default,
+  };
+}
+
+function clientState(client: ApiClient, now: number): ClientState {
+  let state = clients.get(client.id);
+  if (!state) {
+    state = {
+      kind: client.kind,
+      quota: client.quota,
+      tokens: client.quota.burst,
+      refilledAt: now,
+      lastSeenAt: now,
+      aggregationsInFlight: 0,
+      waiting: [],
+      requests: 0,
+      throttled: 0,
+      aggregationsQueued: 0,
+      aggregationsThrottled: 0,
+      lastThrottledAt: null,
+    };
+    clients.set(client.id, state);
+  }
+  state.lastSeenAt = now;
+  state.quota = client.quota;
+  return state;
+}
+
+/** Forgets clients idle for an hour with nothing in flight */
+function pruneClients(now: number) {
+  if (now - prunedAt < PRUNE_INTERVAL_MS) return;
+  prunedAt = now;
+  clients.forEach((state, id) => {
+    if (
+      now - state.lastSeenAt > CLIENT_TTL_MS &&
+      state.aggregationsInFlight === 0
+    ) {
+      clients.delete(id);
+    }
+  });
+}
+
+// ============================================================================
+// Requests
+// ============================================================================
+
+/**
+ * Takes one request from the client's bucket.
+ *
+ * @returns Whether the request may proceed, and otherwise when to retry
+ */
+export function consumeRequestQuota(client: ApiClient): RequestQuotaResult {
+  const now = Date.now();
+  pruneClients(now);
+  const state = clientState(client, now);
+  state.requests++;
+  totals.requests++;
+
+  const { requestsPerMinute, burst } = client.quota;
+  if (requestsPerMinute === 0) return { allowed: true };
+
+  const perMs = requestsPerMinute / 60000;
+  state.tokens = Math.min(
+    Math.max(burst, 1),
+    state.tokens + (now - state.refilledAt) * perMs
+  );
+  state.refilledAt = now;
+  if (state.tokens >= 1) {
+    state.tokens -= 1;
+    return { allowed: true };
+  }
+
+  state.throttled++;
+  totals.throttled++;
+  if (state.lastThrottledAt === null || now - state.lastThrottledAt > 60000) {
+    console.warn(
+      `[apiQuotas] Throttling ${client.id}: over ${requestsPerMinute} requests/min`
+    );
+  }
+  state.lastThrottledAt = now;
+  return {
+    allowed: false,
+    retryAfterSeconds: Math.max(
+      1,
+      Math.ceil((1 - state.tokens) / perMs / 1000)
+    ),
+  };
+}
+
+// ============================================================================
+// Aggregations
+// ============================================================================
+
+function throttledError(client: ApiClient): Error {
+  return statusError(
+    `Too many concurrent aggregations for ${client.id} (limit ${client.quota.concurrentAggregations}); try again shortly`,
+    429,
+    { retryAfterSeconds: 1 }
+  );
+}
+
+/**
+ * Waits for one of the client's aggregation slots.
+ *
+ * @throws Error with `statusCode = 429` when none frees up in time
+ */
+async function acquireAggregationSlot(client: ApiClient): Promise<void> {
+  const now = Date.now();
+  const state = clientState(client, now);
+  const limit = client.quota.concurrentAggregations;
+  if (limit === 0 || state.aggregationsInFlight < limit) {
+    state.aggregationsInFlight++;
+    return;
+  }
+
+  state.aggregationsQueued++;
+  totals.aggregationsQueued++;
+  await new Promise<void>((resolve, reject) => {
+    const waiter = () => {
+      clearTimeout(timer);
+      resolve();
+    };
+    const timer = setTimeout(() => {
+      state.waiting.splice(state.waiting.indexOf(waiter), 1);
+      state.aggregationsThrottled++;
+      totals.aggregationsThrottled++;
+      state.lastThrottledAt = Date.now();
+      console.warn(
+        `[apiQuotas] Throttling ${client.id}: over ${limit} concurrent aggregations`
+      );
+      reject(throttledError(client));
+    }, getAggregationWaitMs());
+    state.waiting.push(waiter);
+  });
+}
+
+/** Hands the slot to the next waiting aggregation, or frees it */
+function releaseAggregationSlot(client: ApiClient) {
+  const state = clients.get(client.id);
+  if (!state) return;
+  const next = state.waiting.shift();
+  if (next) next();
+  else state.aggregationsInFlight--;
+}
+
+/**
+ * Runs a request handler as the client, so the aggregations it starts count
+ * against the client's concurrent aggregation limit.
+ */
+export function runWithApiClient<T>(
+  client: ApiClient,
+  handler: () => Promise<T>
+): Promise<T> {
+  return clientContext.run(client, handler);
+}
+
+/**
+ * Applies the per-client aggregation limit to every mongoose aggregation run
+ * inside `runWithApiClient()`; others (commands, background jobs) are not
+ * limited. Safe to call repeatedly.
+ */
+export function installAggregationQuotas(): void {
+  if (installed) return;
+  installed = true;
+
+  const exec = mongoose.Aggregate.prototype.exec;
+  mongoose.Aggregate.prototype.exec = async function (
+    this: Aggregate<unknown>
+  ) {
+    const client = clientContext.getStore();
+    if (!client) return exec.call(this);
+
+    await acquireAggregationSlot(client);
+    try {
+      return await exec.call(this);
+    } finally {
+      releaseAggregationSlot(client);
+    }
+  } as typeof exec;
+}
+
+// ============================================================================
+// Stats
+// ============================================================================
+
+/**
+ * Throttling counters since the process started, and per client over the
+ * last hour.
+ */
+export function getApiQuotaStats(): ApiQuotaStats {
+  const config = loadApiQuotas();
+  return {
+    since: startedAt,
+    totals: { ...totals },
+    quotas: { default: config.default },
+    clients: Array.from(clients.entries())
+      .map(([client, state]) => ({
+        client,
+        kind: state.kind,
+        quota: state.quota,
+        requests: state.requests,
+        throttled: state.throttled,
+        aggregationsQueued: state.aggregationsQueued,
+        aggregationsThrottled: state.aggregationsThrottled,
+        aggregationsInFlight: state.aggregationsInFlight,
+        lastThrottledAt:
+          state.lastThrottledAt === null
+            ? null
+            : new Date(state.lastThrottledAt),
+      }))
+      .sort((a, b) => {
+        const refused =
+          b.throttled +
+          b.aggregationsThrottled -
+          (a.throttled + a.aggregationsThrottled);
+        return refused || b.requests - a.requests;
+      })
+      .slice(0, MAX_REPORTED_CLIENTS),
+  };
+}
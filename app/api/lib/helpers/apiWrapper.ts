@@ -1,6 +1,25 @@
 import { NextResponse, NextRequest } from 'next/server';
 import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  API_KEY_HEADER,
+  apiKeyUser,
+  authenticateApiKey,
+  runAsApiKey,
+} from '@/app/api/lib/helpers/apiKeys';
+import type { ApiKeySummary } from '@/app/api/lib/helpers/apiKeys';
+import {
+  consumeRequestQuota,
+  identifyApiClient,
+  runWithApiClient,
+} from '@/app/api/lib/helpers/apiQuotas';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
+import {
+  isShuttingDown,
+  trackInFlight,
+} from '@/app/api/lib/utils/gracefulShutdown';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { assertTenantRequestBody } from '@/app/api/lib/utils/tenantScope';
+import { withSpan } from '@/app/api/lib/utils/tracing';
 
 /**
  * Common API Response Types
@@ -30,11 +49,17 @@ export type ApiAuthContext = {
   userRoles: string[];
   isAdminOrDev: boolean;
   db?: mongo.Db;
+  /** Set when the request authenticated with an API key instead of a user */
+  apiKey?: ApiKeySummary;
 };
 
 /**
  * Higher-order function to wrap API route handlers with common logic
- * Handles database connection, authentication, and standardized error responses.
+ * Handles database connection, authentication, per-client rate limits (see
+ * apiQuotas), refusing and draining requests on shutdown (see
+ * gracefulShutdown), a trace span per handler (see tracing), tenant
+ * isolation of licencee ids in JSON bodies (see tenantScope), and
+ * standardized error responses.
  */
 export async function withApiAuth(
   req: NextRequest,
@@ -51,7 +76,17 @@ export async function withApiAuth(
       { status: 400 }
     );
   }
+  // 0. Refuse new work while the server drains for shutdown
+  if (isShuttingDown()) {
+    return NextResponse.json(
+      { success: false, error: 'Server is shutting down; retry' },
+      { status: 503, headers: { 'Retry-After': '1', Connection: 'close' } }
+    );
+  }
   try {
+    // 0b. Tenant isolation: the proxy only checks query parameters
+    await assertTenantRequestBody(req);
+
     // 1. Connect to Database (unless bypassed)
     let db: mongo.Db | undefined;
 
@@ -65,8 +100,21 @@ export async function withApiAuth(
       }
     }
 
-    // 2. Authenticate User
-    const userPayload = await getUserFromServer();
+    // 2. Authenticate User, or the API key of a machine-to-machine client
+    let userPayload: Record<string, unknown> | null =
+      await getUserFromServer();
+    let apiKey: ApiKeySummary | undefined;
+    const keyHeader = req.headers.get(API_KEY_HEADER);
+    if (!userPayload && keyHeader) {
+      if (!db) await connectDB();
+      apiKey = await authenticateApiKey(
+        keyHeader,
+        req.nextUrl.pathname,
+        req.method
+      );
+      // The key acts as a manager of its licencees
+      userPayload = apiKeyUser(apiKey);
+    }
 
     // If not public and no user, return 401
     if (!userPayload && !options.optionalAuth) {
@@ -82,20 +130,65 @@ export async function withApiAuth(
       userRoles.includes('developer') ||
       userRoles.includes('owner');
 
-    // 3. Execute Handler
-    return await handler({
-      user: userPayload as ApiAuthContext['user'],
-      userRoles,
-      isAdminOrDev,
-      db,
-    });
+    // 3. Rate limit the client (API key, user or IP)
+    const client = identifyApiClient(
+      req,
+      userPayload?._id ? String(userPayload._id) : null,
+      apiKey
+    );
+    const quota = consumeRequestQuota(client);
+    if (!quota.allowed) {
+      return NextResponse.json(
+        { success: false, error: 'Too many requests; try again later' },
+        {
+          status: 429,
+          headers: { 'Retry-After': String(quota.retryAfterSeconds) },
+        }
+      );
+    }
+
+    // 4. Execute Handler (its aggregations count against the client's cap
+    // and are traced as its child spans; a shutdown waits for it to finish).
+    // With a key, getUserFromServer() inside the handler resolves to it.
+    return await trackInFlight('request', () =>
+      runWithApiClient(client, () =>
+        runAsApiKey(apiKey, () =>
+          withSpan(
+            `api.handler ${req.method}`,
+            {
+              'http.request.method': req.method,
+              'url.path': req.nextUrl.pathname,
+              'cms.client.kind': client.kind,
+              'cms.client.id': client.id,
+            },
+            async setAttributes => {
+              const response = await handler({
+                user: userPayload as ApiAuthContext['user'],
+                userRoles,
+                isAdminOrDev,
+                db,
+                apiKey,
+              });
+              setAttributes({ 'http.response.status_code': response.status });
+              return response;
+            }
+          )
+        )
+      )
+    );
   } catch (error) {
     const message =
       error instanceof Error ? error.message : 'Internal Server Error';
+    const retryAfter = (error as Record<string, unknown>).retryAfterSeconds;
     console.error('[withApiAuth] Error:', message);
     return NextResponse.json(
       { success: false, error: message },
-      { status: 500 }
+      {
+        status: getErrorStatus(error),
+        ...(typeof retryAfter === 'number'
+          ? { headers: { 'Retry-After': String(retryAfter) } }
+          : {}),
+      }
     );
   }
 }
@@ -0,0 +1,357 @@
+/**
+ * Backup Retention Helper
+ *
+ * Commands that change data in bulk keep server-side backups grouped by run
+ * (`runId`, `collection`, `backedUpAt` on each backup document), and those
+ * runs would otherwise accumulate forever. This lists the runs in every
+ * registered backup store with their size and contents, and prunes them by
+ * policy:
+ *
+ * - `keepLast`    — keep the newest N runs per store
+ * - `maxAgeDays`  — drop runs older than this
+ * - `maxSizeMb`   — keep the newest runs that fit in this size per store
+ *
+ * A run is pruned when it falls outside any limit that is set; the newest run
+ * of each store is always kept so the last change can be undone. Limits come
+ * from `BACKUP_KEEP_LAST`, `BACKUP_MAX_AGE_DAYS` and `BACKUP_MAX_SIZE_MB`
+ * unless overridden. Tools that create backups add their collection to
+ * `BACKUP_STORES` and call `applyBackupRetention()` after a run.
+ *
+ * Targeted backups taken before single writes (see writeBackups) live in
+ * the `documents` store and are pruned the same way.
+ *
+ * Used by `scripts/backups.ts`, `scripts/normalize-deleted-at.ts` and
+ * `scripts/coerce-dates.ts`.
+ *
+ * @module app/api/lib/helpers/backupRetention
+ */
+
+import type { Connection } from 'mongoose';
+import {
+  DATE_COERCION_BACKUP_COLLECTION,
+} from '@/app/api/lib/helpers/dateCoercion';
+import {
+  DELETED_AT_BACKUP_COLLECTION,
+} from '@/app/api/lib/helpers/deletedAtNormalization';
+import { DOCUMENT_BACKUP_COLLECTION } from '@/app/api/lib/helpers/writeBackups';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type BackupStore = {
+  /** Short name used on the command line */
+  name: string;
+  collection: string;
+  /** Command that writes and restores the backups */
+  createdBy: string;
+};
+
+export const BACKUP_STORES: BackupStore[] = [
+  {
+    name: 'deleted-at',
+    collection: DELETED_AT_BACKUP_COLLECTION,
+    createdBy: 'normalize-deleted-at',
+  },
+  {
+    name: 'date-coercion',
+    collection: DATE_COERCION_BACKUP_COLLECTION,
+    createdBy: 'coerce-dates',
+  },
+  {
+    name: 'documents',
+    collection: DOCUMENT_BACKUP_COLLECTION,
+    createdBy: 'fixes, re-syncs and status changes (backupBeforeWrite)',
+  },
+];
+
+export type BackupRun = {
+  store: string;
+  collection: string;
+  runId: string;
+  backedUpAt: Date | null;
+  documents: number;
+  sizeBytes: number;
+  /** Documents per backed-up collection */
+  contents: Record<string, number>;
+  /** Operations recorded on the backups (`documents` store) */
+  operations: string[];
+};
+
+export type BackupRetentionPolicy = {
+  keepLast?: number | null;
+  maxAgeDays?: number | null;
+  maxSizeMb?: number | null;
+};
+
+/** Limits as given on the command line or in code */
+export type BackupRetentionOverrides = Partial<
+  Record<keyof BackupRetentionPolicy, number | string | null>
+>;
+
+export type BackupPruneResult = {
+  pruned: BackupRun[];
+  kept: BackupRun[];
+  deletedDocuments: number;
+  freedBytes: number;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+function readLimit(
+  value: number | string | null | undefined,
+  name: string
+): number | null {
+  if (value === null || value === undefined || value === '') return null;
+  const limit = Number(value);
+  if (!Number.isFinite(limit) || limit < 0) {
+    throw new Error(`${name} must be a non-negative number`);
+  }
+  return limit > 0 ? limit : null;
+}
+
+/**
+ * Resolves the retention policy: overrides when given, otherwise the
+ * `BACKUP_KEEP_LAST`, `BACKUP_MAX_AGE_DAYS` and `BACKUP_MAX_SIZE_MB`
+ * environment variables. Unset or 0 means no limit.
+ */
+export function getBackupRetentionPolicy(
+  overrides: BackupRetentionOverrides = {}
+): BackupRetentionPolicy {
+  return {
+    keepLast: readLimit(
+      overrides.keepLast ?? process.env.BACKUP_KEEP_LAST,
+      'keepLast'
+    ),
+    maxAgeDays: readLimit(
+      overrides.maxAgeDays ?? process.env.BACKUP_MAX_AGE_DAYS,
+      'maxAgeDays'
+    ),
+    maxSizeMb: readLimit(
+      overrides.maxSizeMb ?? process.env.BACKUP_MAX_SIZE_MB,
+      'maxSizeMb'
+    ),
+  };
+}
+
+export function hasRetentionLimits(policy: BackupRetentionPolicy): boolean {
+  return Boolean(policy.keepLast || policy.maxAgeDays || policy.maxSizeMb);
+}
+
+function formatBytes(bytes: number): string {
+  if (bytes >= 1024 * 1024) return `${(bytes / 1024 / 1024).toFixed(1)} MB`;
+  if (bytes >= 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+  return `${bytes} B`;
+}
+
+// ============================================================================
+// Listing
+// ============================================================================
+
+/**
+ * Lists the backup runs in each store.
+ *
+ * @param connection - Database connection
+ * @param storeNames - Only these stores (default: all)
+ * @returns Runs, newest first within each store
+ */
+export async function listBackupRuns(
+  connection: Connection,
+  storeNames?: string[]
+): Promise<BackupRun[]> {
+  const stores = BACKUP_STORES.filter(
+    store => !storeNames || storeNames.includes(store.name)
+  );
+  const runs: BackupRun[] = [];
+  for (const store of stores) {
+    const rows = await connection
+      .collection(store.collection)
+      .aggregate<{
+        _id: string;
+        backedUpAt: Date | null;
+        documents: number;
+        sizeBytes: number;
+        contents: Array<{ collection: string; documents: number }>;
+        operations: Array<string | null>;
+      }>(
+        [
+          {
+            $group: {
+              _id: { runId: '$runId', collection: '$collection' },
+              backedUpAt: { $min: '$backedUpAt' },
+              documents: { $sum: 1 },
+              sizeBytes: { $sum: { $bsonSize: '$$ROOT' } },
+              operation: { $first: '$operation' },
+            },
+          },
+          {
+            $group: {
+              _id: '$_id.runId',
+              backedUpAt: { $min: '$backedUpAt' },
+              documents: { $sum: '$documents' },
+              sizeBytes: { $sum: '$sizeBytes' },
+              contents: {
+                $push: {
+                  collection: '$_id.collection',
+                  documents: '$documents',
+                },
+              },
+              operations: { $addToSet: '$operation' },
+            },
+          },
+          { $sort: { backedUpAt: -1 } },
+        ],
+        { allowDiskUse: true }
+      )
+      .toArray();
+    rows.forEach(row =>
+      runs.push({
+        store: store.name,
+        collection: store.collection,
+        runId: String(row._id),
+        backedUpAt: row.backedUpAt || null,
+        documents: row.documents,
+        sizeBytes: row.sizeBytes,
+        contents: Object.fromEntries(
+          row.contents
+            .sort((a, b) => a.collection.localeCompare(b.collection))
+            .map(entry => [entry.collection, entry.documents])
+        ),
+        operations: row.operations
+          .filter((operation): operation is string => Boolean(operation))
+          .sort(),
+      })
+    );
+  }
+  return runs;
+}
+
+/**
+ * One line per run, grouped by store.
+ */
+export function formatBackupRuns(runs: BackupRun[]): string {
+  if (runs.length === 0) return 'No backups';
+  return BACKUP_STORES.filter(store => runs.some(r => r.store === store.name))
+    .map(store => {
+      const storeRuns = runs.filter(run => run.store === store.name);
+      const total = storeRuns.reduce((sum, run) => sum + run.sizeBytes, 0);
+      const lines = storeRuns.map(run => {
+        const contents = Object.entries(run.contents)
+          .map(([collection, count]) => `${collection}=${count}`)
+          .join(' ');
+        return `  ${run.runId.padEnd(16)} ${
+          run.backedUpAt ? run.backedUpAt.toISOString() : 'unknown date'
+        }  ${String(run.documents).padStart(8)} docs  ${formatBytes(
+          run.sizeBytes
+        ).padStart(9)}  ${contents}${
+          run.operations.length > 0 ? `  (${run.operations.join(', ')})` : ''
+        }`;
+      });
+      return [
+        `${store.name} (${store.collection}, ${store.createdBy}): ${storeRuns.length} run(s), ${formatBytes(total)}`,
+        ...lines,
+      ].join('\n');
+    })
+    .join('\n\n');
+}
+
+// ============================================================================
+// Pruning
+// ============================================================================
+
+/**
+ * Splits runs into those to prune and those to keep under a policy.
+ *
+ * @param runs - Runs as returned by `listBackupRuns` (newest first per store)
+ * @param policy - Retention limits
+ * @param now - Reference time for `maxAgeDays`
+ */
+export function selectBackupRunsToPrune(
+  runs: BackupRun[],
+  policy: BackupRetentionPolicy,
+  now: Date = new Date()
+): { prune: BackupRun[]; keep: BackupRun[] } {
+  const prune: BackupRun[] = [];
+  const keep: BackupRun[] = [];
+  const maxAgeMs = policy.maxAgeDays ? policy.maxAgeDays * 86400000 : null;
+  const maxBytes = policy.maxSizeMb ? policy.maxSizeMb * 1024 * 1024 : null;
+
+  BACKUP_STORES.forEach(store => {
+    let keptBytes = 0;
+    runs
+      .filter(run => run.store === store.name)
+      .forEach((run, index) => {
+        const tooMany = policy.keepLast ? index >= policy.keepLast : false;
+        const tooOld =
+          maxAgeMs !== null && run.backedUpAt
+            ? now.getTime() - run.backedUpAt.getTime() > maxAgeMs
+            : false;
+        const tooBig =
+          maxBytes !== null ? keptBytes + run.sizeBytes > maxBytes : false;
+        if (index > 0 && (tooMany || tooOld || tooBig)) {
+          prune.push(run);
+        } else {
+          keep.push(run);
+          keptBytes += run.sizeBytes;
+        }
+      });
+  });
+  return { prune, keep };
+}
+
+/**
+ * Deletes backup runs.
+ *
+ * @param connection - Database connection
+ * @param runs - Runs to delete
+ * @returns Backup documents deleted
+ */
+export async function pruneBackupRuns(
+  connection: Connection,
+  runs: BackupRun[]
+): Promise<number> {
+  if (runs.length === 0) return 0;
+  assertWritable('pruning backups');
+  let deleted = 0;
+  for (const store of BACKUP_STORES) {
+    const runIds = runs
+      .filter(run => run.store === store.name)
+      .map(run => run.runId);
+    if (runIds.length === 0) continue;
+    const result = await connection
+      .collection(store.collection)
+      .deleteMany({ runId: { $in: runIds } });
+    deleted += result.deletedCount;
+  }
+  return deleted;
+}
+
+/**
+ * Prunes a store under the configured policy; a no-op when no limit is set.
+ * Called by commands right after they write a backup run.
+ *
+ * @param connection - Database connection
+ * @param storeName - Store the command writes to
+ * @param overrides - Limits overriding the environment
+ */
+export async function applyBackupRetention(
+  connection: Connection,
+  storeName: string,
+  overrides: BackupRetentionOverrides = {}
+): Promise<BackupPruneResult> {
+  const policy = getBackupRetentionPolicy(overrides);
+  const runs = hasRetentionLimits(policy)
+    ? await listBackupRuns(connection, [storeName])
+    : [];
+  const { prune, keep } = selectBackupRunsToPrune(runs, policy);
+  const deletedDocuments = await pruneBackupRuns(connection, prune);
+  return {
+    pruned: prune,
+    kept: keep,
+    deletedDocuments,
+    freedBytes: prune.reduce((sum, run) => sum + run.sizeBytes, 0),
+  };
+}
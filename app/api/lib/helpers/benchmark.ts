@@ -0,0 +1,344 @@
+/**
+ * Pipeline Benchmark Helper
+ *
+ * Runs the core read pipelines (dashboard stats, location aggregation, meters
+ * lookup) repeatedly against the connected database, records p50/p95 latency
+ * and documents scanned, and compares the results with a stored baseline to
+ * catch query regressions after index or schema changes.
+ *
+ * Documents scanned come from the server's `metrics.queryExecutor` counters
+ * (`serverStatus`), so they include any concurrent traffic — benchmark against
+ * a quiet replica for stable numbers. They are null when the user lacks the
+ * `serverStatus` privilege.
+ *
+ * Used by `scripts/bench.ts`.
+ *
+ * @module app/api/lib/helpers/benchmark
+ */
+
+import { getLocationsWithMetrics } from '@/app/api/lib/helpers/locationAggregation';
+import { getDashboardAnalytics } from '@/app/api/lib/helpers/reports/analytics';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import mongoose from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type BenchCaseName = 'dashboard' | 'locations' | 'meters';
+
+export type BenchContext = {
+  licencee: string;
+  machineId: string;
+  timePeriod: string;
+  /** Gaming day start hour of the machine's location */
+  gameDayOffset?: number;
+};
+
+export type BenchCaseResult = {
+  name: BenchCaseName;
+  iterations: number;
+  minMs: number;
+  p50Ms: number;
+  p95Ms: number;
+  maxMs: number;
+  /** Average documents examined per run */
+  docsScanned: number | null;
+  /** Average index keys examined per run */
+  keysScanned: number | null;
+};
+
+export type BenchReport = {
+  ranAt: Date;
+  context: BenchContext;
+  cases: BenchCaseResult[];
+};
+
+export type BenchBaseline = {
+  createdAt: string;
+  context: BenchContext;
+  cases: Partial<
+    Record<
+      BenchCaseName,
+      Pick<BenchCaseResult, 'p50Ms' | 'p95Ms' | 'docsScanned'>
+    >
+  >;
+};
+
+export type BenchRegression = {
+  name: BenchCaseName;
+  metric: 'p50Ms' | 'p95Ms' | 'docsScanned';
+  baseline: number;
+  current: number;
+  changePercent: number;
+};
+
+export const BENCH_CASE_NAMES: BenchCaseName[] = [
+  'dashboard',
+  'locations',
+  'meters',
+];
+
+/** Latency changes under this many milliseconds are treated as noise */
+const MIN_LATENCY_DELTA_MS = 20;
+
+const BENCH_CASES: Record<
+  BenchCaseName,
+  (context: BenchContext) => Promise<unknown>
+> = {
+  dashboard: context => getDashboardAnalytics(context.licencee),
+  locations: context =>
+    getLocationsWithMetrics(
+      context.licencee,
+      1,
+      50,
+      false,
+      false,
+      undefined,
+      context.timePeriod,
+      undefined,
+      undefined,
+      'all'
+    ),
+  meters: context => {
+    const range = getGamingDayRangeForPeriod(
+      context.timePeriod,
+      context.gameDayOffset
+    );
+    return Meters.collection
+      .find({
+        machine: context.machineId,
+        readAt: { $gte: range.rangeStart, $lte: range.rangeEnd },
+      })
+      .sort({ readAt: -1, _id: -1 })
+      .limit(100)
+      .toArray();
+  },
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Nearest-rank percentile of a list of samples.
+ */
+export function percentile(samples: number[], p: number): number {
+  if (samples.length === 0) return 0;
+  const sorted = [...samples].sort((a, b) => a - b);
+  const rank = Math.ceil((p / 100) * sorted.length);
+  return sorted[Math.min(Math.max(rank, 1), sorted.length) - 1];
+}
+
+async function readScanCounters(): Promise<{
+  docs: number;
+  keys: number;
+} | null> {
+  const db = mongoose.connection.db;
+  if (!db) return null;
+  try {
+    const status = await db.admin().serverStatus();
+    const executor = status?.metrics?.queryExecutor;
+    if (!executor) return null;
+    return {
+      docs: Number(executor.scannedObjects),
+      keys: Number(executor.scanned),
+    };
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Picks the licencee and machine to benchmark when not given (first licencee
+ * by name, its most recently active machine) and the machine's gaming day
+ * offset.
+ */
+export async function resolveBenchContext(
+  partial: Partial<BenchContext>
+): Promise<BenchContext> {
+  const timePeriod = partial.timePeriod || '7d';
+  let licencee = partial.licencee;
+  if (!licencee) {
+    const first = await Licencee.findOne(
+      { ...NOT_DELETED_FILTER },
+      { _id: 1 }
+    )
+      .sort({ name: 1 })
+      .lean<{ _id: string }>();
+    if (!first) throw new Error('No licencees found; pass --licencee');
+    licencee = String(first._id);
+  }
+
+  let machineId = partial.machineId;
+  if (!machineId) {
+    const locationIds = await GamingLocations.distinct('_id', {
+      'rel.licencee': licencee,
+    });
+    const machine = await Machine.findOne(
+      { gamingLocation: { $in: locationIds } },
+      { _id: 1 }
+    )
+      .sort({ lastActivity: -1 })
+      .lean<{ _id: string }>();
+    if (!machine) throw new Error('No machines found; pass --machine');
+    machineId = String(machine._id);
+  }
+
+  const machine = await Machine.findOne(
+    { _id: machineId },
+    { gamingLocation: 1 }
+  ).lean<{ gamingLocation?: string }>();
+  const location = machine?.gamingLocation
+    ? await GamingLocations.findOne(
+        { _id: machine.gamingLocation },
+        { gameDayOffset: 1 }
+      ).lean<{ gameDayOffset?: number }>()
+    : null;
+
+  return {
+    licencee,
+    machineId,
+    timePeriod,
+    gameDayOffset: location?.gameDayOffset ?? undefined,
+  };
+}
+
+// ============================================================================
+// Benchmark
+// ============================================================================
+
+/**
+ * Runs each case `iterations` times (after `warmup` untimed runs).
+ *
+ * @param cases - Cases to run
+ * @param context - Licencee, machine and time period to run them with
+ * @param options - Iteration and warm-up counts
+ * @returns Latency percentiles and scan counts per case
+ */
+export async function runBenchmarks(
+  cases: BenchCaseName[],
+  context: BenchContext,
+  options: { iterations: number; warmup: number }
+): Promise<BenchReport> {
+  const results: BenchCaseResult[] = [];
+
+  for (const name of cases) {
+    const run = BENCH_CASES[name];
+    for (let i = 0; i < options.warmup; i++) {
+      await run(context);
+    }
+
+    const durations: number[] = [];
+    let docsScanned: number | null = 0;
+    let keysScanned: number | null = 0;
+    for (let i = 0; i < options.iterations; i++) {
+      const before = await readScanCounters();
+      const started = performance.now();
+      await run(context);
+      durations.push(performance.now() - started);
+      const after = await readScanCounters();
+
+      if (before && after && docsScanned !== null && keysScanned !== null) {
+        docsScanned += after.docs - before.docs;
+        keysScanned += after.keys - before.keys;
+      } else {
+        docsScanned = null;
+        keysScanned = null;
+      }
+    }
+
+    const round = (value: number) => Math.round(value * 10) / 10;
+    results.push({
+      name,
+      iterations: options.iterations,
+      minMs: round(Math.min(...durations)),
+      p50Ms: round(percentile(durations, 50)),
+      p95Ms: round(percentile(durations, 95)),
+      maxMs: round(Math.max(...durations)),
+      docsScanned:
+        docsScanned === null
+          ? null
+          : Math.round(docsScanned / options.iterations),
+      keysScanned:
+        keysScanned === null
+          ? null
+          : Math.round(keysScanned / options.iterations),
+    });
+  }
+
+  return { ranAt: new Date(), context, cases: results };
+}
+
+/**
+ * Converts a report into the baseline file format.
+ */
+export function toBenchBaseline(report: BenchReport): BenchBaseline {
+  return {
+    createdAt: report.ranAt.toISOString(),
+    context: report.context,
+    cases: Object.fromEntries(
+      report.cases.map(result => [
+        result.name,
+        {
+          p50Ms: result.p50Ms,
+          p95Ms: result.p95Ms,
+          docsScanned: result.docsScanned,
+        },
+      ])
+    ),
+  };
+}
+
+/**
+ * Lists metrics that got worse than the baseline by more than
+ * `tolerancePercent`. Latency changes under 20ms are ignored.
+ */
+export function compareWithBaseline(
+  report: BenchReport,
+  baseline: BenchBaseline,
+  tolerancePercent: number
+): BenchRegression[] {
+  const regressions: BenchRegression[] = [];
+
+  report.cases.forEach(result => {
+    const base = baseline.cases[result.name];
+    if (!base) return;
+
+    (['p50Ms', 'p95Ms', 'docsScanned'] as const).forEach(metric => {
+      const baseValue = base[metric];
+      const current = result[metric];
+      if (baseValue === null || baseValue === undefined || current === null) {
+        return;
+      }
+      if (
+        metric !== 'docsScanned' &&
+        current - baseValue < MIN_LATENCY_DELTA_MS
+      ) {
+        return;
+      }
+      const changePercent =
+        baseValue === 0
+          ? current > 0
+            ? Infinity
+            : 0
+          : ((current - baseValue) / baseValue) * 100;
+      if (changePercent > tolerancePercent) {
+        regressions.push({
+          name: result.name,
+          metric,
+          baseline: baseValue,
+          current,
+          changePercent: Math.round(changePercent * 10) / 10,
+        });
+      }
+    });
+  });
+
+  return regressions;
+}
@@ -8,9 +8,19 @@
  * @module app/api/lib/helpers/cabinetAggregation
  */
 
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
+import {
+  addMovementTotals,
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
 import type { LocationDocument } from '@/lib/types/common';
 import type {
+  FinancialFormula,
   GamingMachine,
+  MovementTotals,
 } from '@shared/types';
 import type { CurrencyCode } from '@/shared/types/currency';
 import { isWowMachine } from '@/shared/utils/wowMachine';
@@ -25,6 +35,10 @@ export type CabinetAggregationParams = {
   locationIdArray: string[];
   selectedGameTypes: string[];
   searchTerm: string;
+  /** Partial, case-insensitive match on relayId or smibBoard */
+  smibId: string;
+  /** Partial, case-insensitive match on the machine's custom name */
+  customName: string;
   licencee: string | null;
   timePeriod: string;
   displayCurrency: CurrencyCode;
@@ -39,9 +53,8 @@ export type CabinetAggregationParams = {
 };
 
 export type MachineMetrics = {
-  moneyIn: number;
-  moneyOut: number;
-  jackpot: number;
+  /** Summed meter movement, composed with the licencee's formula */
+  totals: MovementTotals;
   coinIn: number;
   coinOut: number;
   gamesPlayed: number;
@@ -107,6 +120,25 @@ type LocationWithRange = {
   includeJackpot: boolean;
 };
 
+/**
+ * Financial formula of a location's licencee (the default when it has none).
+ */
+function locationFormula(
+  location: LocationDocument,
+  licenceeFormulas: Map<string, FinancialFormula>
+): FinancialFormula {
+  const rel = (location as unknown as Record<string, unknown>).rel as
+    | Record<string, unknown>
+    | undefined;
+  const licenceeId = Array.isArray(rel?.licencee)
+    ? (rel?.licencee as string[])[0]
+    : (rel?.licencee as string | undefined);
+  return (
+    (licenceeId && licenceeFormulas.get(String(licenceeId))) ||
+    DEFAULT_FINANCIAL_FORMULA
+  );
+}
+
 // ============================================================================
 // Query Parameter Parsing
 // ============================================================================
@@ -137,6 +169,8 @@ export function parseCabinetAggregationParams(
     : [];
 
   const searchTerm = searchParams.get('search')?.trim() || '';
+  const smibId = searchParams.get('smibId')?.trim() || '';
+  const customName = searchParams.get('customName')?.trim() || '';
   const licencee = searchParams.get('licencee');
   const timePeriod = searchParams.get('timePeriod') || '';
   const displayCurrency =
@@ -162,6 +196,8 @@ export function parseCabinetAggregationParams(
     locationIdArray,
     selectedGameTypes,
     searchTerm,
+    smibId,
+    customName,
     licencee,
     timePeriod,
     displayCurrency,
@@ -180,8 +216,14 @@ export function parseCabinetAggregationParams(
 // Machine Match Query Builder
 // ============================================================================
 
+/** Escapes regex metacharacters so user input is matched literally. */
+function escapeRegex(input: string): string {
+  return input.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
 /**
- * Builds the MongoDB match query for filtering machines.
+ * Builds the MongoDB match query for filtering machines. Retired machines are
+ * always left out.
  * Eliminates duplication between the 7d/30d and batch processing branches.
  *
  * @param {string[]} locationIds - Location IDs to filter by
@@ -193,12 +235,22 @@ export function parseCabinetAggregationParams(
 export function buildMachineMatchQuery(
   locationIds: string[],
   deletedFilter: Record<string, unknown>,
-  params: Pick<CabinetAggregationParams, 'searchTerm' | 'selectedGameTypes' | 'onlineStatus' | 'smibStatus'>,
+  params: Pick<
+    CabinetAggregationParams,
+    | 'searchTerm'
+    | 'smibId'
+    | 'customName'
+    | 'selectedGameTypes'
+    | 'onlineStatus'
+    | 'smibStatus'
+  > & { onlineCutoff: Date },
   locations: LocationDocument[]
 ): Record<string, unknown> {
   const machineMatchQuery: Record<string, unknown> = {
     gamingLocation: { $in: locationIds },
-    $and: [deletedFilter] as Array<Record<string, unknown>>,
+    $and: [deletedFilter, { ...NOT_RETIRED_FILTER }] as Array<
+      Record<string, unknown>
+    >,
   };
 
   const andArray = machineMatchQuery.$and as Array<Record<string, unknown>>;
@@ -217,6 +269,23 @@ export function buildMachineMatchQuery(
     });
   }
 
+  if (params.smibId) {
+    const smibRegex = { $regex: escapeRegex(params.smibId), $options: 'i' };
+    andArray.push({
+      $or: [{ relayId: smibRegex }, { smibBoard: smibRegex }],
+    });
+  }
+
+  if (params.customName) {
+    const nameRegex = {
+      $regex: escapeRegex(params.customName),
+      $options: 'i',
+    };
+    andArray.push({
+      $or: [{ 'custom.name': nameRegex }, { 'Custom.name': nameRegex }],
+    });
+  }
+
   if (params.selectedGameTypes.length > 0) {
     andArray.push({
       $or: [
@@ -232,7 +301,7 @@ export function buildMachineMatchQuery(
   }
 
   if (params.onlineStatus !== 'all') {
-    const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+    const { onlineCutoff } = params;
     const aceEnabledLocIds = locations
       .filter(loc => loc.aceEnabled === true)
       .map(loc => String(loc._id));
@@ -245,7 +314,7 @@ export function buildMachineMatchQuery(
         ],
       });
       const onlineConds: Array<Record<string, unknown>> = [
-        { lastActivity: { $gte: threeMinutesAgo } },
+        { lastActivity: { $gte: onlineCutoff } },
         { 'meta.dataSync.source': 'wow' },
       ];
       if (aceEnabledLocIds.length > 0) {
@@ -257,7 +326,7 @@ export function buildMachineMatchQuery(
       andArray.push({ relayId: { $exists: true, $nin: [null, ''] } });
       andArray.push({
         $or: [
-          { lastActivity: { $lt: threeMinutesAgo } },
+          { lastActivity: { $lt: onlineCutoff } },
           { lastActivity: { $exists: false } },
           { lastActivity: null },
         ],
@@ -328,33 +397,30 @@ export function buildMachineMatchQuery(
  * @param {GamingMachine} machine - The machine document
  * @param {MachineMetrics} metrics - Aggregated meter metrics
  * @param {LocationDocument} location - The machine's gaming location
- * @param {Map<string, boolean>} licenceeIncludeJackpotMap - Licencee jackpot settings
+ * @param {Map<string, FinancialFormula>} licenceeFormulas - Financial formula per licencee ID
  * @param {string} timePeriod - Current time period for context
+ * @param {Date} onlineCutoff - Machines active since this date are online
  * @returns {CabinetMachineResponse} Formatted machine response
  */
 export function buildMachineResponse(
   machine: GamingMachine,
   metrics: MachineMetrics,
   location: LocationDocument,
-  licenceeIncludeJackpotMap: Map<string, boolean>,
-  timePeriod: string
+  licenceeFormulas: Map<string, FinancialFormula>,
+  timePeriod: string,
+  onlineCutoff: Date = getOnlineCutoff()
 ): CabinetMachineResponse {
   const machineId = String(machine._id);
   const locationId = String(location._id);
 
-  const licenceeId = Array.isArray(location.rel?.licencee)
-    ? location.rel.licencee[0]
-    : location.rel?.licencee;
-  const includeJackpot = licenceeId
-    ? licenceeIncludeJackpotMap.get(String(licenceeId)) || false
-    : false;
-
-  const moneyIn = metrics.moneyIn || 0;
-  const rawMoneyOut = metrics.moneyOut || 0;
-  const jackpot = metrics.jackpot || 0;
-  const moneyOut = rawMoneyOut + (includeJackpot ? jackpot : 0);
-  const gross = moneyIn - moneyOut;
-  const netGross = moneyIn - rawMoneyOut - jackpot;
+  const formula = locationFormula(location, licenceeFormulas);
+  const { moneyIn, moneyOut, jackpot, gross, netGross } =
+    calculateFinancialMetrics(metrics.totals, formula);
+  // Money Out without the jackpot share, shown as Cancelled Credits
+  const cancelledCredits = calculateFinancialMetrics(metrics.totals, {
+    ...formula,
+    includeJackpot: false,
+  }).moneyOut;
 
   const serialNumber = String(machine.serialNumber || '').trim();
   const customName = String(
@@ -364,7 +430,6 @@ export function buildMachineResponse(
   ).trim();
   const finalSerialNumber = serialNumber || customName || '';
 
-  const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
   const hasRelayId = !!(
     machine.relayId && String(machine.relayId).trim().length > 0
   );
@@ -375,7 +440,7 @@ export function buildMachineResponse(
     (hasRelayId &&
       (location.aceEnabled === true ||
         (machine.lastActivity
-          ? new Date(machine.lastActivity as Date) > threeMinutesAgo
+          ? new Date(machine.lastActivity as Date) > onlineCutoff
           : false)));
 
   return {
@@ -412,7 +477,7 @@ export function buildMachineResponse(
     online: isOnline,
     moneyIn,
     moneyOut,
-    cancelledCredits: rawMoneyOut,
+    cancelledCredits,
     gross,
     netGross,
     jackpot,
@@ -420,7 +485,7 @@ export function buildMachineResponse(
     coinOut: metrics.coinOut || 0,
     gamesPlayed: metrics.gamesPlayed || 0,
     gamesWon: metrics.gamesWon || 0,
-    includeJackpot,
+    includeJackpot: formula.includeJackpot,
     handPaidCancelledCredits: metrics.handPaidCancelledCredits || 0,
     meterCount: metrics.meterCount || 0,
     rel: location.rel,
@@ -458,9 +523,7 @@ export function buildPerLocationMetersPipeline(
     {
       $project: {
         machine: 1,
-        drop: '$movement.drop',
-        totalCancelledCredits: '$movement.totalCancelledCredits',
-        jackpot: '$movement.jackpot',
+        movement: 1,
         coinIn: 1,
         coinOut: 1,
         gamesPlayed: 1,
@@ -471,11 +534,9 @@ export function buildPerLocationMetersPipeline(
     {
       $group: {
         _id: '$machine',
-        moneyIn: { $sum: '$drop' },
-        moneyOut: { $sum: '$totalCancelledCredits' },
-        jackpot: { $sum: '$jackpot' },
-        coinIn: { $last: '$coinIn' },
-        coinOut: { $last: '$coinOut' },
+        ...buildMovementTotalsGroup(),
+        lastCoinIn: { $last: '$coinIn' },
+        lastCoinOut: { $last: '$coinOut' },
         gamesPlayed: { $last: '$gamesPlayed' },
         gamesWon: { $last: '$gamesWon' },
         handPaidCancelledCredits: { $last: '$handPaidCancelledCredits' },
@@ -512,11 +573,9 @@ export function buildBatchMetersPipeline(
     {
       $group: {
         _id: '$machine',
-        moneyIn: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        moneyOut: { $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] } },
-        jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
-        coinIn: { $last: '$coinIn' },
-        coinOut: { $last: '$coinOut' },
+        ...buildMovementTotalsGroup(),
+        lastCoinIn: { $last: '$coinIn' },
+        lastCoinOut: { $last: '$coinOut' },
         gamesPlayed: { $last: '$gamesPlayed' },
         gamesWon: { $last: '$gamesWon' },
         handPaidCancelledCredits: { $last: '$handPaidCancelledCredits' },
@@ -539,9 +598,7 @@ export function buildBatchMetersPipeline(
  */
 export function getDefaultMetrics(): MachineMetrics {
   return {
-    moneyIn: 0,
-    moneyOut: 0,
-    jackpot: 0,
+    totals: {},
     coinIn: 0,
     coinOut: 0,
     gamesPlayed: 0,
@@ -551,6 +608,26 @@ export function getDefaultMetrics(): MachineMetrics {
   };
 }
 
+/**
+ * Reads a row of either meters pipeline into machine metrics.
+ *
+ * @param {Record<string, unknown>} row - Pipeline output row
+ * @returns {MachineMetrics} Machine metrics
+ */
+export function readMachineMetrics(
+  row: Record<string, unknown>
+): MachineMetrics {
+  return {
+    totals: addMovementTotals({}, row),
+    coinIn: (row.lastCoinIn as number) || 0,
+    coinOut: (row.lastCoinOut as number) || 0,
+    gamesPlayed: (row.gamesPlayed as number) || 0,
+    gamesWon: (row.gamesWon as number) || 0,
+    handPaidCancelledCredits: (row.handPaidCancelledCredits as number) || 0,
+    meterCount: (row.meterCount as number) || 0,
+  };
+}
+
 // ============================================================================
 // Offline Status Refinement
 // ============================================================================
@@ -563,16 +640,16 @@ export function getDefaultMetrics(): MachineMetrics {
  * @param {string} onlineStatus - Requested online status filter
  * @param {string} timePeriod - Current time period
  * @param {Map<string, { rangeStart: Date; rangeEnd: Date }>} gamingDayRanges - Per-location gaming day ranges
+ * @param {Date} onlineCutoff - Machines active since this date are online
  * @returns {CabinetMachineResponse[]} Filtered and annotated machines
  */
 export function refineOfflineStatus(
   machines: CabinetMachineResponse[],
   onlineStatus: string,
   timePeriod: string,
-  gamingDayRanges: Map<string, { rangeStart: Date; rangeEnd: Date }>
+  gamingDayRanges: Map<string, { rangeStart: Date; rangeEnd: Date }>,
+  onlineCutoff: Date = getOnlineCutoff()
 ): CabinetMachineResponse[] {
-  const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
-
   let refinedMachines = machines.map(machine => {
     const lastActivity = machine.lastActivity
       ? new Date(machine.lastActivity)
@@ -582,7 +659,7 @@ export function refineOfflineStatus(
     const isOnline =
       isWowMachine(machine) ||
       aceEnabled ||
-      (lastActivity && lastActivity > threeMinutesAgo);
+      (lastActivity && lastActivity > onlineCutoff);
 
     let offlineTimeLabel: string | undefined = undefined;
     let actualOfflineTime: string | undefined = undefined;
@@ -615,7 +692,7 @@ export function refineOfflineStatus(
       const isOnline =
         isWowMachine(machine) ||
         aceEnabled ||
-        (lastActivity && lastActivity > threeMinutesAgo);
+        (lastActivity && lastActivity > onlineCutoff);
       machine.online = !!isOnline;
 
       if (isOnline) return false;
@@ -668,7 +745,7 @@ export function applyReviewerScale(
       jackpot: scaledJackpot,
       cancelledCredits: scaledCancelledCredits,
       gross: scaledMoneyIn - scaledMoneyOut,
-      netGross: scaledMoneyIn - scaledCancelledCredits - scaledJackpot,
+      netGross: scaledMoneyIn - scaledMoneyOut - scaledJackpot,
     };
   });
 }
@@ -768,25 +845,19 @@ export function sortCabinetMachines(
  * Builds location data for gaming day range calculation.
  *
  * @param {LocationDocument[]} locations - Fetched locations
- * @param {Map<string, boolean>} licenceeIncludeJackpotMap - Licencee jackpot settings
+ * @param {Map<string, FinancialFormula>} licenceeFormulas - Financial formula per licencee ID
  * @returns {LocationWithRange[]} Location data with offset and jackpot settings
  */
 export function buildLocationRangeInputs(
   locations: LocationDocument[],
-  licenceeIncludeJackpotMap: Map<string, boolean>
+  licenceeFormulas: Map<string, FinancialFormula>
 ): LocationWithRange[] {
   return locations.map(loc => {
     const locRecord = loc as unknown as Record<string, unknown>;
-    const rel = locRecord.rel as Record<string, unknown> | undefined;
-    const licenceeId = Array.isArray(rel?.licencee)
-      ? (rel?.licencee as string[])[0]
-      : (rel?.licencee as string | undefined);
     return {
       _id: String(locRecord._id),
       gameDayOffset: (locRecord.gameDayOffset as number) ?? 8,
-      includeJackpot: licenceeId
-        ? licenceeIncludeJackpotMap.get(String(licenceeId)) || false
-        : false,
+      includeJackpot: locationFormula(loc, licenceeFormulas).includeJackpot,
     };
   });
 }
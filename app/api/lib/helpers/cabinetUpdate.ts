@@ -8,6 +8,7 @@
 
 import { calculateChanges } from '@/app/api/lib/helpers/activityLogger';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { isMeterUnit } from '@/app/api/lib/utils/meterUnits';
 import type { ActivityLogChange } from '@shared/types/activityLog';
 
 export type CabinetUpdatePayload = {
@@ -20,6 +21,7 @@ export type CabinetUpdatePayload = {
   cabinetType?: string;
   locationId?: string;
   accountingDenomination?: string | number;
+  meterUnit?: string;
   gameConfig?: {
     theoreticalRtp?: string | number;
     maxBet?: string | number;
@@ -100,6 +102,9 @@ export function mapCabinetUpdateFields(data: CabinetUpdatePayload) {
         data.gameConfig.progressiveGroup;
   }
 
+  // Unit the machine reports meters in (see utils/meterUnits)
+  if (isMeterUnit(data.meterUnit)) updateFields.meterUnit = data.meterUnit;
+
   // Handle direct dot notation keys if sent (as seen in some components)
   if (data['gameConfig.accountingDenomination'] !== undefined)
     updateFields['gameConfig.accountingDenomination'] = Number(
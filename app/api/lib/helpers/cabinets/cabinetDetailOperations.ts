@@ -17,7 +17,13 @@ import {
   getMoneyInScale,
   getMoneyOutAndJackpotScale,
 } from '@/app/api/lib/utils/reviewerScale';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  resolveFinancialFormula,
+} from '@/app/api/lib/utils/financialFormulas';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import { clearMeterUnitCache } from '@/app/api/lib/utils/meterUnits';
 import {
   mapCabinetUpdateFields,
   buildCabinetActivityChanges,
@@ -38,7 +44,7 @@ import {
 import type { LocationDocument, MachineDocument } from '@/lib/types/common';
 import type { CurrencyCode } from '@/shared/types/currency';
 import type { TimePeriod } from '@/shared/types/common';
-import type { LicenceeDocument } from '@/shared/types';
+import type { FinancialFormula, LicenceeDocument } from '@/shared/types';
 import { NextRequest } from 'next/server';
 
 // ============================================================================
@@ -90,6 +96,7 @@ export async function fetchLocationSettings(
   aceEnabled: boolean;
   licenceeId: string | undefined;
   includeJackpot: boolean;
+  financialFormula: FinancialFormula;
 }> {
   const location = await GamingLocations.findOne({ _id: locationId })
     .select('name gameDayOffset rel aceEnabled country')
@@ -98,19 +105,26 @@ export async function fetchLocationSettings(
   const gameDayOffset = location?.gameDayOffset ?? 8;
   const aceEnabled = location?.aceEnabled === true;
 
-  let includeJackpot = false;
+  let financialFormula = resolveFinancialFormula(null);
   let licenceeId: string | undefined;
   const rawLicenceeId = location?.rel?.licencee;
   if (rawLicenceeId) {
     licenceeId = Array.isArray(rawLicenceeId) ? rawLicenceeId[0] : rawLicenceeId;
     const licDoc = await Licencee.findOne(
       { _id: licenceeId },
-      { includeJackpot: 1 }
+      { includeJackpot: 1, financialFormula: 1 }
     ).lean<LicenceeDocument>();
-    includeJackpot = Boolean(licDoc?.includeJackpot);
+    financialFormula = resolveFinancialFormula(licDoc);
   }
 
-  return { location, gameDayOffset, aceEnabled, licenceeId, includeJackpot };
+  return {
+    location,
+    gameDayOffset,
+    aceEnabled,
+    licenceeId,
+    includeJackpot: financialFormula.includeJackpot,
+    financialFormula,
+  };
 }
 
 // ============================================================================
@@ -123,7 +137,7 @@ export async function aggregateCabinetMetrics(
   startDateParam: string | null,
   endDateParam: string | null,
   gameDayOffset: number,
-  includeJackpot: boolean,
+  financialFormula: FinancialFormula,
   userPayload: UserForScale,
   dateField: string = 'readAt'
 ): Promise<CabinetMetrics> {
@@ -183,11 +197,7 @@ export async function aggregateCabinetMetrics(
     {
       $group: {
         _id: null,
-        moneyIn: { $sum: '$movement.drop' },
-        moneyOut: { $sum: '$movement.totalCancelledCredits' },
-        jackpot: { $sum: '$movement.jackpot' },
-        coinIn: { $sum: { $ifNull: ['$movement.coinIn', 0] } },
-        coinOut: { $sum: { $ifNull: ['$movement.coinOut', 0] } },
+        ...buildMovementTotalsGroup(),
         gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
         gamesWon: { $sum: { $ifNull: ['$movement.gamesWon', 0] } },
         handPaidCancelledCredits: {
@@ -202,12 +212,16 @@ export async function aggregateCabinetMetrics(
     const moneyInScale = getMoneyInScale(userPayload, endDate);
     const moneyOutScale = getMoneyOutAndJackpotScale(userPayload, endDate);
 
-    metrics.moneyIn = raw.moneyIn * moneyInScale;
-    metrics.jackpot = raw.jackpot * moneyOutScale;
-    const rawCancelled = raw.moneyOut * moneyOutScale;
-    metrics.moneyOut = rawCancelled + (includeJackpot ? metrics.jackpot : 0);
-    metrics.gross = metrics.moneyIn - metrics.moneyOut;
-    metrics.netGross = metrics.gross - metrics.jackpot;
+    const financials = calculateFinancialMetrics(raw, financialFormula, {
+      moneyInScale,
+      moneyOutScale,
+    });
+
+    metrics.moneyIn = financials.moneyIn;
+    metrics.jackpot = financials.jackpot;
+    metrics.moneyOut = financials.moneyOut;
+    metrics.gross = financials.gross;
+    metrics.netGross = financials.netGross;
     metrics.coinIn = raw.coinIn;
     metrics.coinOut = raw.coinOut;
     metrics.gamesPlayed = raw.gamesPlayed;
@@ -281,7 +295,11 @@ export async function convertCabinetCurrency(
     handPaidCancelledCredits: metrics.handPaidCancelledCredits,
   };
   converted.gross = converted.moneyIn - converted.moneyOut;
-  converted.netGross = converted.gross - converted.jackpot;
+  // Converted as is: Money Out may already include the jackpot
+  converted.netGross = convertFromUSD(
+    convertToUSD(metrics.netGross, nativeCurrency),
+    displayCurrency
+  );
 
   return converted;
 }
@@ -362,6 +380,12 @@ export async function performCabinetUpdate(
     { $set: updateFields },
     { new: true }
   );
+  if (
+    'meterUnit' in updateFields ||
+    'gameConfig.accountingDenomination' in updateFields
+  ) {
+    clearMeterUnitCache();
+  }
 
   console.log(
     `[PUT /api/cabinets/${cabinetId}] Updated machine status fields:`,
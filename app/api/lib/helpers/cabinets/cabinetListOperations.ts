@@ -12,6 +12,11 @@ import { Machine } from '@/app/api/lib/models/machines';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { getUserFromServer } from '@/app/api/lib/helpers/users';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id/generation';
 import type { GamingMachine } from '@/shared/types';
 import type { MachinePayload } from '@/shared/types/machines';
@@ -26,12 +31,7 @@ import type { MachinePayload } from '@/shared/types/machines';
  * @returns {Record<string, unknown>} MongoDB filter excluding recently deleted documents
  */
 export function getActiveFilter(): Record<string, unknown> {
-  return {
-    $or: [
-      { deletedAt: null },
-      { deletedAt: { $lt: new Date('2025-01-01') } },
-    ],
-  };
+  return { ...NOT_DELETED_FILTER };
 }
 
 // ============================================================================
@@ -116,12 +116,9 @@ export async function getCabinetsByLocation(
   const query: Record<string, unknown> = { gamingLocation: locationId };
 
   if (showArchived) {
-    query.deletedAt = { $gte: new Date('2025-01-01') };
+    query.deletedAt = DELETED_FILTER.deletedAt;
   } else {
-    query.$or = [
-      { deletedAt: null },
-      { deletedAt: { $lt: new Date('2025-01-01') } },
-    ];
+    query.deletedAt = NOT_DELETED_FILTER.deletedAt;
   }
 
   const cabinets = await Machine.find(query).lean<GamingMachine[]>();
@@ -133,13 +130,13 @@ export async function getCabinetsByLocation(
 // ============================================================================
 
 function sortCabinetsByOnlineStatus(cabinets: GamingMachine[]): GamingMachine[] {
-  const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+  const onlineCutoff = getOnlineCutoff();
 
   return [...cabinets].sort((a, b) => {
     const aOnline =
-      a.lastActivity && new Date(a.lastActivity) >= threeMinutesAgo;
+      a.lastActivity && new Date(a.lastActivity) >= onlineCutoff;
     const bOnline =
-      b.lastActivity && new Date(b.lastActivity) >= threeMinutesAgo;
+      b.lastActivity && new Date(b.lastActivity) >= onlineCutoff;
 
     if (aOnline && !bOnline) return -1;
     if (!aOnline && bOnline) return 1;
@@ -260,7 +257,7 @@ function buildNewCabinetDocument(
         : new Date(),
     createdAt: new Date(),
     updatedAt: new Date(),
-    deletedAt: new Date(-1),
+    deletedAt: null,
     gamingBoard: String(data.gamingBoard || ''),
     machineStatus: String(data.assetStatus || data.status || 'Active'),
     machineType: String(data.cabinetType || ''),
@@ -11,6 +11,11 @@
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
 import {
   convertFromUSD,
   convertToUSD,
@@ -19,7 +24,11 @@ import {
 } from '@/lib/helpers/rates';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
 import type { CurrencyCode } from '@/shared/types/currency';
-import type { LicenceeDocument } from '@shared/types';
+import type {
+  FinancialFormula,
+  LicenceeDocument,
+  MovementTotals,
+} from '@shared/types';
 import type { LocationDocument } from '@/lib/types/common';
 import type { PipelineStage } from 'mongoose';
 
@@ -341,11 +350,7 @@ export function buildChartAggregationPipeline(
   pipeline.push({
     $group: {
       _id: groupStageId,
-      drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-      totalCancelledCredits: {
-        $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-      },
-      jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
+      ...buildMovementTotalsGroup(),
     },
   });
 
@@ -353,11 +358,7 @@ export function buildChartAggregationPipeline(
     _id: 0,
     day: '$_id.day',
     time: '$_id.time',
-    drop: 1,
-    totalCancelledCredits: 1,
-    gross: {
-      $subtract: [{ $subtract: ['$drop', '$jackpot'] }, '$totalCancelledCredits'],
-    },
+    ...Object.fromEntries(METER_MOVEMENT_FIELDS.map(field => [field, 1])),
   };
 
   if (useMonthly && groupId.month) {
@@ -454,6 +455,32 @@ export function convertChartBuckets(
 // Data Transformation
 // ============================================================================
 
+/**
+ * Composes drop, cancelled credits and gross for each bucket from its summed
+ * movement fields. Gross is the net figure (jackpot taken off) the chart has
+ * always shown.
+ *
+ * @param {Array<ChartBucket & MovementTotals>} rows - Buckets from the pipeline
+ * @param {FinancialFormula} formula - Formula of the machine's licencee
+ * @returns {ChartBucket[]} Buckets with the composed figures
+ */
+export function composeChartBuckets(
+  rows: Array<ChartBucket & MovementTotals>,
+  formula: FinancialFormula
+): ChartBucket[] {
+  return rows.map(row => {
+    const metrics = calculateFinancialMetrics(row, formula);
+    return {
+      day: row.day,
+      time: row.time,
+      ...(row.month ? { month: row.month } : {}),
+      drop: metrics.moneyIn,
+      totalCancelledCredits: metrics.moneyOut,
+      gross: metrics.netGross,
+    };
+  });
+}
+
 /**
  * Transforms chart buckets ensuring all required fields have default values.
  *
@@ -0,0 +1,311 @@
+/**
+ * Machine Write Operations
+ *
+ * Validated create/update of a machine's core assignment fields (game, custom
+ * name, location, SMIB id) for the `/api/machines` endpoints, so internal
+ * tools stop editing machines directly in the database.
+ *
+ * Updates use optimistic concurrency on the document's `__v`, exposed as
+ * `version`: the caller sends the version it read, the update only applies if
+ * it still matches, and each update increments it. A stale version gets a 409
+ * with the current version. The machine is backed up before each update and
+ * every change is written to the activity log.
+ *
+ * @module app/api/lib/helpers/cabinets/machineWriteOperations
+ */
+
+import { z } from 'zod';
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  checkCabinetAvailability,
+  createCabinet,
+} from '@/app/api/lib/helpers/cabinets/cabinetListOperations';
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type { MachinePayload } from '@/shared/types/machines';
+
+// ============================================================================
+// Schemas & Types
+// ============================================================================
+
+const trimmed = (label: string) =>
+  z.string().trim().min(1, `${label} cannot be empty`).max(100);
+
+export const machineCreateSchema = z
+  .object({
+    serialNumber: trimmed('Serial number'),
+    gamingLocation: trimmed('Location'),
+    game: trimmed('Game').optional(),
+    gameType: trimmed('Game type').optional(),
+    customName: trimmed('Custom name').optional(),
+    smibId: trimmed('SMIB id').optional(),
+  })
+  .strict();
+
+export const machineUpdateSchema = z
+  .object({
+    version: z.number().int().min(0),
+    game: trimmed('Game').optional(),
+    customName: trimmed('Custom name').optional(),
+    gamingLocation: trimmed('Location').optional(),
+    /** Empty string unassigns the SMIB */
+    smibId: z.string().trim().max(100).optional(),
+  })
+  .strict()
+  .refine(
+    data =>
+      data.game !== undefined ||
+      data.customName !== undefined ||
+      data.gamingLocation !== undefined ||
+      data.smibId !== undefined,
+    { message: 'Nothing to update' }
+  );
+
+/** The fields these endpoints read and write, plus the version */
+export const machineRecordSchema = z.object({
+  _id: z.string(),
+  serialNumber: z.string(),
+  game: z.string(),
+  gameType: z.string(),
+  customName: z.string(),
+  gamingLocation: z.string(),
+  smibId: z.string(),
+  version: z.number().int(),
+  updatedAt: z.date().optional(),
+});
+
+export type MachineCreateInput = z.infer<typeof machineCreateSchema>;
+export type MachineUpdateInput = z.infer<typeof machineUpdateSchema>;
+export type MachineRecord = z.infer<typeof machineRecordSchema>;
+
+export type MachineWriteUser = { _id: string; username: string };
+
+type StoredMachine = {
+  _id: string;
+  serialNumber?: string;
+  game?: string;
+  gameType?: string;
+  custom?: { name?: string };
+  gamingLocation?: string;
+  relayId?: string;
+  __v?: number;
+  updatedAt?: Date;
+};
+
+const MACHINE_RECORD_PROJECTION = {
+  _id: 1,
+  serialNumber: 1,
+  game: 1,
+  gameType: 1,
+  'custom.name': 1,
+  gamingLocation: 1,
+  relayId: 1,
+  __v: 1,
+  updatedAt: 1,
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+function toMachineRecord(machine: StoredMachine): MachineRecord {
+  return {
+    _id: String(machine._id),
+    serialNumber: machine.serialNumber || '',
+    game: machine.game || '',
+    gameType: machine.gameType || '',
+    customName: machine.custom?.name || '',
+    gamingLocation: machine.gamingLocation || '',
+    smibId: machine.relayId || '',
+    version: machine.__v ?? 0,
+    updatedAt: machine.updatedAt,
+  };
+}
+
+/** Filter matching a stored `__v`; version 0 also matches a missing `__v` */
+function versionFilter(version: number): Record<string, unknown> {
+  return version === 0 ? { __v: { $in: [0, null] } } : { __v: version };
+}
+
+async function assertActiveLocation(locationId: string): Promise<string> {
+  const location = await GamingLocations.findOne(
+    { _id: locationId, ...NOT_DELETED_FILTER },
+    { name: 1 }
+  ).lean<{ name?: string }>();
+  if (!location) throw statusError(`Location ${locationId} not found`, 404);
+  return location.name || locationId;
+}
+
+// ============================================================================
+// Read
+// ============================================================================
+
+/**
+ * Loads a machine's editable fields and version.
+ *
+ * @returns The machine, or null when it does not exist or is deleted
+ */
+export async function getMachineRecord(
+  machineId: string
+): Promise<MachineRecord | null> {
+  const machine = await Machine.findOne(
+    { _id: machineId, ...NOT_DELETED_FILTER },
+    MACHINE_RECORD_PROJECTION
+  ).lean<StoredMachine>();
+  return machine ? toMachineRecord(machine) : null;
+}
+
+// ============================================================================
+// Create
+// ============================================================================
+
+/**
+ * Creates a machine at an existing location through the regular cabinet
+ * creation path (serial/SMIB deduplication, defaults, activity log).
+ *
+ * @throws Error with `statusCode` 404 (location) or 409 (serial/SMIB in use)
+ */
+export async function createMachineRecord(
+  input: MachineCreateInput
+): Promise<MachineRecord> {
+  assertWritable('creating a machine');
+  await assertActiveLocation(input.gamingLocation);
+
+  const result = await createCabinet({
+    serialNumber: input.serialNumber,
+    gamingLocation: input.gamingLocation,
+    game: input.game,
+    gameType: input.gameType,
+    custom: input.customName ? { name: input.customName } : undefined,
+    smibBoard: input.smibId,
+  } as MachinePayload);
+  if (!result.success) throw statusError(result.error, 409);
+
+  const created = await getMachineRecord(result.machineId);
+  if (!created) throw statusError('Machine was not saved', 500);
+  return created;
+}
+
+// ============================================================================
+// Update
+// ============================================================================
+
+/**
+ * Applies a partial update if `input.version` still matches the stored
+ * version, then increments it.
+ *
+ * @param machineId - Machine to update
+ * @param input - Validated update, including the version the caller read
+ * @param user - Acting user, for the activity log
+ * @returns The updated machine with its new version
+ * @throws Error with `statusCode` 404 (machine/location), 409 (stale version,
+ *   includes `currentVersion`; or SMIB id in use)
+ */
+export async function updateMachineRecord(
+  machineId: string,
+  input: MachineUpdateInput,
+  user: MachineWriteUser
+): Promise<MachineRecord> {
+  assertWritable('updating a machine');
+
+  const current = await getMachineRecord(machineId);
+  if (!current) throw statusError(`Machine ${machineId} not found`, 404);
+  if (current.version !== input.version) {
+    throw statusError(
+      `Machine was changed by someone else (version ${current.version}); reload and retry`,
+      409,
+      { currentVersion: current.version }
+    );
+  }
+
+  // Step 1: Work out what actually changes
+  const set: Record<string, unknown> = {};
+  const changes: Array<{
+    field: string;
+    oldValue: unknown;
+    newValue: unknown;
+  }> = [];
+  const track = (
+    field: string,
+    path: string,
+    oldValue: string,
+    newValue: string | undefined
+  ) => {
+    if (newValue === undefined || newValue === oldValue) return;
+    set[path] = newValue;
+    changes.push({ field, oldValue, newValue });
+  };
+
+  track('game', 'game', current.game, input.game);
+  track('customName', 'custom.name', current.customName, input.customName);
+  track(
+    'gamingLocation',
+    'gamingLocation',
+    current.gamingLocation,
+    input.gamingLocation
+  );
+  const smibId =
+    input.smibId === undefined ? undefined : input.smibId.toLowerCase();
+  track('smibId', 'relayId', current.smibId, smibId);
+  if (changes.length === 0) return current;
+
+  // Step 2: Validate the new location and SMIB assignment
+  if (set.gamingLocation) {
+    await assertActiveLocation(String(set.gamingLocation));
+  }
+  if (set.relayId !== undefined) {
+    if (
+      set.relayId &&
+      !(await checkCabinetAvailability(null, smibId || '', null, machineId))
+    ) {
+      throw statusError(`SMIB ${smibId} is assigned to another machine`, 409);
+    }
+    set.smibBoard = set.relayId;
+    set.smbId = set.relayId;
+  }
+
+  // Step 3: Back up, then conditional write
+  await backupBeforeWrite({
+    operation: 'machine-update',
+    collection: Machine.collection.collectionName,
+    filter: { _id: machineId },
+    by: user.username,
+  });
+  const result = await Machine.updateOne(
+    { _id: machineId, ...NOT_DELETED_FILTER, ...versionFilter(input.version) },
+    { $set: { ...set, updatedAt: new Date() }, $inc: { __v: 1 } }
+  );
+  if (result.matchedCount === 0) {
+    const latest = await getMachineRecord(machineId);
+    throw statusError(
+      'Machine was changed by someone else; reload and retry',
+      409,
+      { currentVersion: latest?.version }
+    );
+  }
+
+  // Step 4: Activity log
+  const name = current.serialNumber || current.customName || machineId;
+  await logActivity({
+    action: 'update',
+    details: `Updated machine ${name}: ${changes
+      .map(change => change.field)
+      .join(', ')}`,
+    userId: user._id,
+    username: user.username,
+    metadata: {
+      resource: 'machine',
+      resourceId: machineId,
+      resourceName: name,
+      changes,
+    },
+  });
+
+  const updated = await getMachineRecord(machineId);
+  if (!updated) throw statusError(`Machine ${machineId} not found`, 404);
+  return updated;
+}
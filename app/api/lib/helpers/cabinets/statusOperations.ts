@@ -7,7 +7,13 @@
  * @module app/api/lib/helpers/cabinets/statusOperations
  */
 
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { Machine } from '@/app/api/lib/models/machines';
+import { anyIdTypeIn, isObjectIdHex } from '@/app/api/lib/utils/mongoIds';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import type { PipelineStage } from 'mongoose';
 
 // ============================================================================
@@ -28,7 +34,7 @@ export type StatusCounts = {
 
 /**
  * Creates the base aggregation pipeline with machine-location lookup.
- * Filters out soft-deleted machines and locations.
+ * Filters out soft-deleted machines and locations, and retired machines.
  *
  * @param {boolean} showArchived - When true, only returns archived (soft-deleted) machines
  * @returns {PipelineStage[]} Base pipeline stages
@@ -37,26 +43,16 @@ export function createBasePipeline(
   showArchived: boolean = false
 ): PipelineStage[] {
   const machineDeletionFilter = showArchived
-    ? { deletedAt: { $gte: new Date('2025-01-01') } }
-    : {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      };
+    ? { ...DELETED_FILTER }
+    : { ...NOT_DELETED_FILTER };
 
   const locationDeletionFilter = showArchived
-    ? { deletedAt: { $gte: new Date('2025-01-01') } }
-    : {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      };
+    ? { ...DELETED_FILTER }
+    : { ...NOT_DELETED_FILTER };
 
   return [
     {
-      $match: machineDeletionFilter,
+      $match: { ...machineDeletionFilter, ...NOT_RETIRED_FILTER },
     },
     {
       $lookup: {
@@ -102,7 +98,7 @@ export function addSearchFilter(
 ): void {
   if (!search) return;
 
-  const isObjectIdFormat = /^[0-9a-fA-F]{24}$/.test(search);
+  const isObjectIdFormat = isObjectIdHex(search);
   const searchRegex = { $regex: search, $options: 'i' };
   const searchConditions: Record<string, unknown>[] = [
     { 'locationDetails.name': searchRegex },
@@ -114,13 +110,9 @@ export function addSearchFilter(
   ];
 
   if (isObjectIdFormat) {
-    searchConditions.push({ 'locationDetails._id': search });
-    try {
-      const { ObjectId } = require('mongodb');
-      searchConditions.push({ _id: new ObjectId(search) });
-    } catch {
-      // ignore ObjectId conversion errors
-    }
+    // Machines and locations may store _id as a string or an ObjectId
+    searchConditions.push({ 'locationDetails._id': anyIdTypeIn([search]) });
+    searchConditions.push({ _id: anyIdTypeIn([search]) });
   } else {
     searchConditions.push({ 'locationDetails._id': searchRegex });
   }
@@ -717,13 +709,8 @@ export function buildLocationCountFilter(
   wowLocationIds: string[] | null = null
 ): Record<string, unknown>[] {
   const deletionFilter = showArchived
-    ? { deletedAt: { $gte: new Date('2025-01-01') } }
-    : {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      };
+    ? { ...DELETED_FILTER }
+    : { ...NOT_DELETED_FILTER };
 
   const filter: Record<string, unknown>[] = [deletionFilter];
 
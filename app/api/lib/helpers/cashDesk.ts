@@ -0,0 +1,653 @@
+/**
+ * Cash Desk Helper
+ *
+ * Records each location's cash desk day in `cashdeskdays` — the opening
+ * float, top-ups, closing float and every manual payout — and reconciles
+ * the desk against the machines for the same gaming day:
+ *
+ * - **Payouts**: desk payouts of type `cancelled-credit` (hand pays, ticket
+ *   redemptions) against the machines' `totalCancelledCredits` meters, and
+ *   `jackpot` payouts against the `jackpot` meters. A payout recorded against
+ *   a machine is also compared machine by machine; payouts without one only
+ *   count in the location totals.
+ * - **Float**: the closing float against opening + top-ups - payouts.
+ *
+ * Meter totals come from metersDaily where the day is rolled up, else raw
+ * meters (see getMovementTotalsWithRollup), over each location's gaming day.
+ * A location is flagged when a variance is above the threshold (default 1,
+ * in dollars) or when its machines paid out with no desk record.
+ *
+ * Entered with the `cash-desk` command (scripts/cash-desk.ts); the report is
+ * also served by `/api/reports/cash-desk`.
+ *
+ * @module app/api/lib/helpers/cashDesk
+ */
+
+import {
+  DEFAULT_GAME_DAY_OFFSET,
+  getMovementTotalsWithRollup,
+  getRollupWindow,
+  isValidGamingDay,
+} from '@/app/api/lib/helpers/metersDaily';
+import { CashDeskDay } from '@/app/api/lib/models/cashDeskDay';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+import { generateMongoId } from '@/lib/utils/id';
+import type { CashDeskDayDocument, CashDeskDayPayout } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type RecordCashDeskFloatInput = {
+  locationId: string;
+  gamingDay: string;
+  openingFloat?: number;
+  /** Added to the day's top-ups */
+  floatAdded?: number;
+  closingFloat?: number;
+  notes?: string;
+  recordedBy: string | null;
+};
+
+export type RecordCashDeskPayoutInput = {
+  locationId: string;
+  gamingDay: string;
+  amount: number;
+  type?: CashDeskDayPayout['type'];
+  /** Machine ID or serial number at the location */
+  machine?: string;
+  note?: string;
+  recordedBy: string | null;
+};
+
+export type CashDeskStatus =
+  | 'balanced'
+  | 'over'
+  | 'short'
+  | 'no-desk-record'
+  | 'no-activity';
+
+export type CashDeskMachineRow = {
+  machineId: string;
+  serialNumber: string;
+  deskCancelledCredits: number;
+  meterCancelledCredits: number;
+  /** Desk minus meters */
+  variance: number;
+};
+
+export type CashDeskReconciliationRow = {
+  locationId: string;
+  locationName: string;
+  licenceeId: string | null;
+  gamingDay: string;
+  hasDeskRecord: boolean;
+  payoutCount: number;
+  deskCancelledCredits: number;
+  meterCancelledCredits: number;
+  /** Desk minus meters; positive when the desk paid more than the machines */
+  cancelledCreditVariance: number;
+  deskJackpot: number;
+  meterJackpot: number;
+  jackpotVariance: number;
+  openingFloat: number | null;
+  floatAdded: number;
+  closingFloat: number | null;
+  /** opening + added - payouts; null without an opening float */
+  expectedClosingFloat: number | null;
+  /** closing - expected; null without both floats */
+  floatVariance: number | null;
+  status: CashDeskStatus;
+  flagged: boolean;
+  /** Machines with desk payouts or cancelled credits, largest variance first */
+  machines: CashDeskMachineRow[];
+  /** Desk payouts not recorded against a machine */
+  unattributedPayouts: number;
+};
+
+export type CashDeskReconciliationParams = {
+  /** Gaming day, YYYY-MM-DD */
+  day: string;
+  allowedLocationIds: 'all' | string[];
+  licenceeId?: string;
+  /** Flag variances above this amount (default 1) */
+  threshold?: number;
+  flaggedOnly?: boolean;
+};
+
+export type CashDeskReconciliationReport = {
+  day: string;
+  threshold: number;
+  generatedAt: Date;
+  totals: {
+    deskCancelledCredits: number;
+    meterCancelledCredits: number;
+    cancelledCreditVariance: number;
+    flagged: number;
+  };
+  rows: CashDeskReconciliationRow[];
+};
+
+export const DEFAULT_CASH_DESK_THRESHOLD = 1;
+
+type DeskLocation = {
+  _id: string;
+  name?: string;
+  gameDayOffset?: number;
+  rel?: { licencee?: string | string[] };
+};
+
+type DeskMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  gamingLocation: string;
+};
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+function assertAmount(name: string, value: number | undefined): void {
+  if (value !== undefined && (!Number.isFinite(value) || value < 0)) {
+    throw statusError(`${name} must be a number of 0 or more`, 400);
+  }
+}
+
+function machineLabel(machine: DeskMachine): string {
+  return (
+    machine.serialNumber?.trim() ||
+    machine.origSerialNumber?.trim() ||
+    machine.custom?.name ||
+    String(machine._id)
+  );
+}
+
+async function assertDeskLocation(
+  locationId: string,
+  gamingDay: string
+): Promise<void> {
+  if (!isValidGamingDay(gamingDay)) {
+    throw statusError('gamingDay must be YYYY-MM-DD', 400);
+  }
+  if (
+    !(await GamingLocations.exists({ _id: locationId, ...NOT_DELETED_FILTER }))
+  ) {
+    throw statusError(`Location ${locationId} not found`, 404);
+  }
+}
+
+// ============================================================================
+// Recording
+// ============================================================================
+
+/**
+ * A location's cash desk day, or null when nothing was recorded.
+ */
+export async function getCashDeskDay(
+  locationId: string,
+  gamingDay: string
+): Promise<CashDeskDayDocument | null> {
+  return CashDeskDay.findOne({
+    location: locationId,
+    gamingDay,
+  }).lean<CashDeskDayDocument>();
+}
+
+/**
+ * Records the float of a cash desk day: sets the opening and closing floats
+ * given and adds `floatAdded` to the day's top-ups. Creates the day when
+ * needed.
+ *
+ * @throws Error with `statusCode` 400 on invalid amounts or day, 404 for an
+ * unknown location, 423 in read-only mode
+ */
+export async function recordCashDeskFloat(
+  input: RecordCashDeskFloatInput
+): Promise<CashDeskDayDocument> {
+  assertWritable('recording cash desk floats');
+  assertAmount('openingFloat', input.openingFloat);
+  assertAmount('floatAdded', input.floatAdded);
+  assertAmount('closingFloat', input.closingFloat);
+  await assertDeskLocation(input.locationId, input.gamingDay);
+
+  const set: Record<string, unknown> = { recordedBy: input.recordedBy };
+  if (input.openingFloat !== undefined) set.openingFloat = input.openingFloat;
+  if (input.closingFloat !== undefined) set.closingFloat = input.closingFloat;
+  if (input.notes !== undefined) set.notes = input.notes;
+
+  const day = await CashDeskDay.findOneAndUpdate(
+    { location: input.locationId, gamingDay: input.gamingDay },
+    {
+      $set: set,
+      $inc: { floatAdded: input.floatAdded ?? 0 },
+      $setOnInsert: { _id: await generateMongoId(), payouts: [] },
+    },
+    { upsert: true, new: true }
+  ).lean<CashDeskDayDocument>();
+  return day as CashDeskDayDocument;
+}
+
+/**
+ * Adds a manual payout to a cash desk day, creating the day when needed.
+ *
+ * @returns The day and the recorded payout
+ * @throws Error with `statusCode` 400 on an invalid amount or day, 404 for
+ * an unknown location or a machine not at the location, 423 in read-only
+ * mode
+ */
+export async function recordCashDeskPayout(
+  input: RecordCashDeskPayoutInput
+): Promise<{ day: CashDeskDayDocument; payout: CashDeskDayPayout }> {
+  assertWritable('recording cash desk payouts');
+  if (!Number.isFinite(input.amount) || input.amount <= 0) {
+    throw statusError('amount must be a positive number', 400);
+  }
+  await assertDeskLocation(input.locationId, input.gamingDay);
+
+  let machine: DeskMachine | null = null;
+  if (input.machine) {
+    machine = await Machine.findOne(
+      {
+        gamingLocation: input.locationId,
+        ...NOT_DELETED_FILTER,
+        $or: [
+          { _id: input.machine },
+          { serialNumber: input.machine },
+          { origSerialNumber: input.machine },
+        ],
+      },
+      { _id: 1, serialNumber: 1, origSerialNumber: 1, 'custom.name': 1 }
+    ).lean<DeskMachine>();
+    if (!machine) {
+      throw statusError(
+        `Machine ${input.machine} not found at location ${input.locationId}`,
+        404
+      );
+    }
+  }
+
+  const payout: CashDeskDayPayout = {
+    _id: await generateMongoId(),
+    amount: round2(input.amount),
+    type: input.type ?? 'cancelled-credit',
+    machineId: machine ? String(machine._id) : null,
+    machineSerialNumber: machine ? machineLabel(machine) : null,
+    ...(input.note ? { note: input.note } : {}),
+    recordedBy: input.recordedBy,
+    recordedAt: new Date(),
+  };
+  const day = await CashDeskDay.findOneAndUpdate(
+    { location: input.locationId, gamingDay: input.gamingDay },
+    {
+      $push: { payouts: payout },
+      $setOnInsert: {
+        _id: await generateMongoId(),
+        openingFloat: null,
+        floatAdded: 0,
+        closingFloat: null,
+        recordedBy: input.recordedBy,
+      },
+    },
+    { upsert: true, new: true }
+  ).lean<CashDeskDayDocument>();
+  return { day: day as CashDeskDayDocument, payout };
+}
+
+/**
+ * Removes a payout recorded in error.
+ *
+ * @returns The removed payout
+ * @throws Error with `statusCode` 404 when the day has no such payout, 423
+ * in read-only mode
+ */
+export async function removeCashDeskPayout(
+  locationId: string,
+  gamingDay: string,
+  payoutId: string
+): Promise<CashDeskDayPayout> {
+  assertWritable('removing cash desk payouts');
+  const day = await CashDeskDay.findOneAndUpdate(
+    { location: locationId, gamingDay, 'payouts._id': payoutId },
+    { $pull: { payouts: { _id: payoutId } } }
+  ).lean<CashDeskDayDocument>();
+  const payout = day?.payouts.find(entry => entry._id === payoutId);
+  if (!payout) {
+    throw statusError(
+      `No payout ${payoutId} at location ${locationId} on ${gamingDay}`,
+      404
+    );
+  }
+  return payout;
+}
+
+// ============================================================================
+// Reconciliation
+// ============================================================================
+
+/**
+ * Reconciles every location in scope for a gaming day.
+ *
+ * @param params - Day, location scope and threshold
+ * @returns Rows with the largest variance first
+ * @throws Error with `statusCode = 400` on an invalid day
+ */
+export async function reconcileCashDesk(
+  params: CashDeskReconciliationParams
+): Promise<CashDeskReconciliationReport> {
+  if (!isValidGamingDay(params.day)) {
+    throw statusError('day must be YYYY-MM-DD', 400);
+  }
+  const threshold = params.threshold ?? DEFAULT_CASH_DESK_THRESHOLD;
+
+  // Step 1: Locations, their machines and desk days
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (params.allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: params.allowedLocationIds };
+  }
+  if (params.licenceeId) {
+    locationQuery['rel.licencee'] = params.licenceeId;
+  }
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    gameDayOffset: 1,
+    'rel.licencee': 1,
+  }).lean<DeskLocation[]>();
+  const locationIds = locations.map(location => String(location._id));
+
+  const [machines, deskDays] = await Promise.all([
+    Machine.find(
+      { gamingLocation: { $in: locationIds } },
+      {
+        _id: 1,
+        serialNumber: 1,
+        origSerialNumber: 1,
+        'custom.name': 1,
+        gamingLocation: 1,
+      }
+    ).lean<DeskMachine[]>(),
+    CashDeskDay.find({
+      location: { $in: locationIds },
+      gamingDay: params.day,
+    }).lean<CashDeskDayDocument[]>(),
+  ]);
+  const machinesById = new Map(
+    machines.map(machine => [String(machine._id), machine])
+  );
+  const deskDayByLocation = new Map(
+    deskDays.map(day => [String(day.location), day])
+  );
+
+  // Step 2: Cancelled credit and jackpot meters per machine for the day
+  const ranges = new Map<string, GamingDayRange>();
+  locations.forEach(location => {
+    ranges.set(
+      String(location._id),
+      getRollupWindow(
+        params.day,
+        location.gameDayOffset ?? DEFAULT_GAME_DAY_OFFSET
+      )
+    );
+  });
+  const meterTotals = await getMovementTotalsWithRollup(ranges, 'machine');
+
+  // Step 3: Compare per location and machine
+  const rows = locations.map(location => {
+    const locationId = String(location._id);
+    const licencee = location.rel?.licencee;
+    const deskDay = deskDayByLocation.get(locationId);
+    const payouts = deskDay?.payouts ?? [];
+
+    const machineRows = new Map<string, CashDeskMachineRow>();
+    const machineRow = (machineId: string) => {
+      if (!machineRows.has(machineId)) {
+        const machine = machinesById.get(machineId);
+        machineRows.set(machineId, {
+          machineId,
+          serialNumber: machine ? machineLabel(machine) : machineId,
+          deskCancelledCredits: 0,
+          meterCancelledCredits: 0,
+          variance: 0,
+        });
+      }
+      return machineRows.get(machineId) as CashDeskMachineRow;
+    };
+
+    let meterCancelledCredits = 0;
+    let meterJackpot = 0;
+    machines
+      .filter(machine => String(machine.gamingLocation) === locationId)
+      .forEach(machine => {
+        const totals = meterTotals.get(String(machine._id));
+        const cancelled = Number(totals?.totalCancelledCredits) || 0;
+        meterCancelledCredits += cancelled;
+        meterJackpot += Number(totals?.jackpot) || 0;
+        if (cancelled !== 0) {
+          machineRow(String(machine._id)).meterCancelledCredits += cancelled;
+        }
+      });
+
+    let deskCancelledCredits = 0;
+    let deskJackpot = 0;
+    let unattributedPayouts = 0;
+    payouts.forEach(payout => {
+      if (payout.type === 'jackpot') {
+        deskJackpot += payout.amount;
+        return;
+      }
+      deskCancelledCredits += payout.amount;
+      if (payout.machineId) {
+        machineRow(payout.machineId).deskCancelledCredits += payout.amount;
+      } else {
+        unattributedPayouts++;
+      }
+    });
+
+    const cancelledCreditVariance = round2(
+      deskCancelledCredits - meterCancelledCredits
+    );
+    const jackpotVariance = round2(deskJackpot - meterJackpot);
+    const paidOut = deskCancelledCredits + deskJackpot;
+    const expectedClosingFloat =
+      deskDay && deskDay.openingFloat !== null
+        ? round2(deskDay.openingFloat + (deskDay.floatAdded || 0) - paidOut)
+        : null;
+    const floatVariance =
+      expectedClosingFloat !== null && deskDay && deskDay.closingFloat !== null
+        ? round2(deskDay.closingFloat - expectedClosingFloat)
+        : null;
+
+    const hasActivity = meterCancelledCredits !== 0 || meterJackpot !== 0;
+    const status: CashDeskStatus = !deskDay
+      ? hasActivity
+        ? 'no-desk-record'
+        : 'no-activity'
+      : Math.abs(cancelledCreditVariance) <= threshold &&
+          Math.abs(jackpotVariance) <= threshold
+        ? 'balanced'
+        : cancelledCreditVariance + jackpotVariance > 0
+          ? 'over'
+          : 'short';
+    const flagged =
+      status === 'no-desk-record' ||
+      status === 'over' ||
+      status === 'short' ||
+      (floatVariance !== null && Math.abs(floatVariance) > threshold);
+
+    return {
+      locationId,
+      locationName: location.name || locationId,
+      licenceeId: (Array.isArray(licencee) ? licencee[0] : licencee) || null,
+      gamingDay: params.day,
+      hasDeskRecord: Boolean(deskDay),
+      payoutCount: payouts.length,
+      deskCancelledCredits: round2(deskCancelledCredits),
+      meterCancelledCredits: round2(meterCancelledCredits),
+      cancelledCreditVariance,
+      deskJackpot: round2(deskJackpot),
+      meterJackpot: round2(meterJackpot),
+      jackpotVariance,
+      openingFloat: deskDay?.openingFloat ?? null,
+      floatAdded: round2(deskDay?.floatAdded ?? 0),
+      closingFloat: deskDay?.closingFloat ?? null,
+      expectedClosingFloat,
+      floatVariance,
+      status,
+      flagged,
+      machines: Array.from(machineRows.values())
+        .map(row => ({
+          ...row,
+          deskCancelledCredits: round2(row.deskCancelledCredits),
+          meterCancelledCredits: round2(row.meterCancelledCredits),
+          variance: round2(
+            row.deskCancelledCredits - row.meterCancelledCredits
+          ),
+        }))
+        .sort((a, b) => Math.abs(b.variance) - Math.abs(a.variance)),
+      unattributedPayouts,
+    };
+  });
+
+  const reported = rows
+    .filter(row => row.status !== 'no-activity' || row.hasDeskRecord)
+    .filter(row => !params.flaggedOnly || row.flagged)
+    .sort(
+      (a, b) =>
+        Number(b.flagged) - Number(a.flagged) ||
+        Math.abs(b.cancelledCreditVariance) -
+          Math.abs(a.cancelledCreditVariance) ||
+        a.locationName.localeCompare(b.locationName)
+    );
+
+  return {
+    day: params.day,
+    threshold,
+    generatedAt: new Date(),
+    totals: {
+      deskCancelledCredits: round2(
+        rows.reduce((sum, row) => sum + row.deskCancelledCredits, 0)
+      ),
+      meterCancelledCredits: round2(
+        rows.reduce((sum, row) => sum + row.meterCancelledCredits, 0)
+      ),
+      cancelledCreditVariance: round2(
+        rows.reduce((sum, row) => sum + row.cancelledCreditVariance, 0)
+      ),
+      flagged: rows.filter(row => row.flagged).length,
+    },
+    rows: reported,
+  };
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+function csvCell(value: unknown): string {
+  const text = value === null || value === undefined ? '' : String(value);
+  return /[",\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+}
+
+/**
+ * One line per location followed by its machines.
+ */
+export function exportCashDeskReconciliationToCSV(
+  report: CashDeskReconciliationReport
+): string {
+  const header = [
+    'Gaming Day',
+    'Location',
+    'Machine',
+    'Status',
+    'Desk Cancelled Credits',
+    'Meter Cancelled Credits',
+    'Cancelled Credit Variance',
+    'Desk Jackpot',
+    'Meter Jackpot',
+    'Jackpot Variance',
+    'Opening Float',
+    'Float Added',
+    'Closing Float',
+    'Expected Closing Float',
+    'Float Variance',
+  ];
+  const lines = [header.join(',')];
+  report.rows.forEach(row => {
+    lines.push(
+      [
+        row.gamingDay,
+        row.locationName,
+        '',
+        row.flagged ? `${row.status} (flagged)` : row.status,
+        row.deskCancelledCredits,
+        row.meterCancelledCredits,
+        row.cancelledCreditVariance,
+        row.deskJackpot,
+        row.meterJackpot,
+        row.jackpotVariance,
+        row.openingFloat,
+        row.floatAdded,
+        row.closingFloat,
+        row.expectedClosingFloat,
+        row.floatVariance,
+      ]
+        .map(csvCell)
+        .join(',')
+    );
+    row.machines.forEach(machine =>
+      lines.push(
+        [
+          row.gamingDay,
+          row.locationName,
+          machine.serialNumber,
+          '',
+          machine.deskCancelledCredits,
+          machine.meterCancelledCredits,
+          machine.variance,
+        ]
+          .map(csvCell)
+          .join(',')
+      )
+    );
+  });
+  return lines.join('\n');
+}
+
+/**
+ * Formats a reconciliation for the terminal: flagged locations, then a
+ * summary line.
+ */
+export function formatCashDeskReconciliation(
+  report: CashDeskReconciliationReport
+): string {
+  const lines = [
+    `Cash desk reconciliation for ${report.day} (threshold ${report.threshold})`,
+  ];
+  report.rows
+    .filter(row => row.flagged)
+    .forEach(row => {
+      const float =
+        row.floatVariance !== null ? `, float ${row.floatVariance}` : '';
+      lines.push(
+        `  ${row.locationName} (${row.locationId}): ${row.status} — desk ${row.deskCancelledCredits} vs meters ${row.meterCancelledCredits} (${row.cancelledCreditVariance})${float}`
+      );
+      row.machines
+        .filter(machine => Math.abs(machine.variance) > report.threshold)
+        .forEach(machine =>
+          lines.push(
+            `    ${machine.serialNumber}: desk ${machine.deskCancelledCredits} vs meters ${machine.meterCancelledCredits} (${machine.variance})`
+          )
+        );
+    });
+  lines.push(
+    `${report.totals.flagged} flagged; desk ${report.totals.deskCancelledCredits} vs meters ${report.totals.meterCancelledCredits} (${report.totals.cancelledCreditVariance})`
+  );
+  return lines.join('\n');
+}
@@ -12,6 +12,7 @@
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CollectionDocument } from '@/lib/types/collection';
 import type { GamingMachine } from '@shared/types';
 import type { CollectionReportDocument } from '@shared/types';
@@ -74,7 +75,7 @@ export async function fixAllCollectionHistoryData(): Promise<{
       // Get all collections for this machine, sorted by timestamp
       const machineCollections = await Collections.find({
         machineId: machineId,
-        deletedAt: { $exists: false },
+        ...NOT_DELETED_FILTER,
       })
         .sort({ timestamp: 1 })
         .lean<CollectionDocument[]>();
@@ -12,6 +12,7 @@
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CollectionDocument } from '@/lib/types/collection';
 import type { CollectionReportDocument, GamingMachine } from '@shared/types';
 import { fixReportIssues } from './fixOperations';
@@ -180,9 +181,7 @@ async function checkReportForIssues(reportId: string): Promise<boolean> {
               },
             ],
           },
-          {
-            $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
-          },
+          { ...NOT_DELETED_FILTER },
           { isCompleted: true },
         ],
       })
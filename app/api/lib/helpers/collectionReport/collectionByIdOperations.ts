@@ -22,6 +22,7 @@ import { updateRegularAndRamClearMeters } from '@/app/api/lib/helpers/collection
 import { getUserFromServer } from '@/app/api/lib/helpers/users';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import { calculateMovement } from '@/lib/utils/movement';
 import type {
@@ -523,7 +524,7 @@ export async function updateMachineHistoryForPatch(
           { collectionTime: { $lt: collectionTimeForComparison } },
           { timestamp: { $lt: collectionTimeForComparison } },
         ],
-        deletedAt: { $exists: false },
+        ...NOT_DELETED_FILTER,
       },
       {
         sort: {
@@ -21,6 +21,7 @@
 import { Collections } from '@/app/api/lib/models/collections'
 import { Machine } from '@/app/api/lib/models/machines'
 import { Meters } from '@/app/api/lib/models/meters'
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id'
 import {
   calculateSasMetrics,
@@ -324,7 +325,7 @@ export async function resolvePreviousMetersForPatch(
       },
       isCompleted: true,
       locationReportId: { $exists: true, $ne: '' },
-      $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+      ...NOT_DELETED_FILTER,
       _id: { $ne: collectionId },
     })
       .sort({ timestamp: -1 })
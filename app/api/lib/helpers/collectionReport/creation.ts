@@ -17,6 +17,7 @@
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Meters } from '@/app/api/lib/models/meters';
 import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { calculateMovement } from '@/lib/utils/movement';
 import type {
   SasMetricsCalculation,
@@ -451,7 +452,7 @@ async function getPreviousCollectionMeters(
     machineId: machineId,
     isCompleted: true,
     locationReportId: { $exists: true, $ne: '' }, // Ensure it's from a completed report
-    $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+    ...NOT_DELETED_FILTER,
   })
     .sort({ timestamp: -1 })
     .lean<CollectionDocument>();
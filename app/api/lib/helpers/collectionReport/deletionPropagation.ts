@@ -7,6 +7,7 @@
 
 import { Collections } from '../../models/collections';
 import { recalculateMachineCollections } from './recalculation';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CollectionDocument } from '@/lib/types/collection';
 
 // ============================================================================
@@ -33,7 +34,7 @@ export async function propagateCRDeletionForward(
         const nextReport = await Collections.findOne({
           machineId: col.machineId,
           timestamp: { $gt: col.timestamp || col.collectionTime || new Date() },
-          deletedAt: { $exists: false },
+          ...NOT_DELETED_FILTER,
         })
           .sort({ timestamp: 1 })
           .lean<CollectionDocument>();
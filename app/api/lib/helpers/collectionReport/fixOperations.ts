@@ -10,14 +10,20 @@
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { MachineWithHistory } from '@/shared/types/machines';
 import {
   type CollectionData,
   type FixResults,
   type HistoryEntry,
 } from '@/shared/types/reports';
+import {
+  backupBeforeWrite,
+  createBackupRunId,
+} from '@/app/api/lib/helpers/writeBackups';
 import { calculateMovement } from '@/lib/utils/movement';
 import { calculateSasMetrics } from './creation';
+import { SAS_METER_TOLERANCE } from './issueChecker';
 
 function toDate(value: string | Date | undefined): Date | undefined {
   if (!value) return undefined;
@@ -142,6 +148,29 @@ export async function fixReportIssues(
     errors: [],
   };
 
+  // Back up the collections and machines the phases below may rewrite
+  const backupRunId = createBackupRunId('fix-report');
+  const machineIds = [
+    ...new Set(
+      targetCollections
+        .map(getMachineIdFromCollection)
+        .filter((id): id is string => Boolean(id))
+    ),
+  ];
+  await backupBeforeWrite({
+    operation: 'fix-report',
+    collection: Collections.collection.collectionName,
+    filter: { _id: { $in: targetCollections.map(c => c._id) } },
+    runId: backupRunId,
+  });
+  await backupBeforeWrite({
+    operation: 'fix-report',
+    collection: Machine.collection.collectionName,
+    filter: { _id: { $in: machineIds } },
+    runId: backupRunId,
+  });
+  fixResults.backupRunId = backupRunId;
+
   const startTime = Date.now();
   let lastProgressLog = 0;
 
@@ -467,6 +496,26 @@ async function fixSasTimesIssues(
       }
     }
 
+    // Stored SAS meters can drift from raw meters even with a correct window
+    if (!needsUpdate) {
+      const currentSasMetrics = await calculateSasMetrics(
+        actualMachineId,
+        expectedSasStartTime,
+        expectedSasEndTime
+      );
+      const dropDiff =
+        (collection.sasMeters?.drop ?? 0) - currentSasMetrics.drop;
+      const cancelledDiff =
+        (collection.sasMeters?.totalCancelledCredits ?? 0) -
+        currentSasMetrics.totalCancelledCredits;
+      if (
+        Math.abs(dropDiff) > SAS_METER_TOLERANCE ||
+        Math.abs(cancelledDiff) > SAS_METER_TOLERANCE
+      ) {
+        needsUpdate = true;
+      }
+    }
+
     if (needsUpdate) {
       // Recalculate SAS metrics with correct time window
       const sasMetrics = await calculateSasMetrics(
@@ -638,9 +687,7 @@ async function fixPrevMetersIssues(
               },
             ],
           },
-          {
-            $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
-          },
+          { ...NOT_DELETED_FILTER },
           // Only look for completed collections (from finalized reports)
           { isCompleted: true },
         ],
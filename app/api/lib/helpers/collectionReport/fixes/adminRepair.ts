@@ -10,8 +10,13 @@
  * @module app/api/lib/helpers/adminRepairSasTimes
  */
 
+import {
+  backupBeforeWrite,
+  createBackupRunId,
+} from '@/app/api/lib/helpers/writeBackups';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
 import { calculateSasMetrics } from '../creation';
 import type { CollectionDocument } from '@/lib/types/collection';
 
@@ -53,6 +58,9 @@ function normalizeTo8AMTrinidad(date: Date): Date {
  *    - Update collection and machine if in commit mode
  * 4. Return repair results
  *
+ * In commit mode the matched collections and their machines are backed up
+ * first.
+ *
  * @param filter - MongoDB filter for collections
  * @param mode - Repair mode: 'dry-run' or 'commit'
  * @returns Object containing repair results and summary
@@ -66,6 +74,7 @@ export async function repairSasTimesForCollections(
   count: number;
   changed: number;
   results: RepairResult[];
+  backupRunId?: string;
 }> {
   if (!filter) {
     console.error('[repairSasTimesForCollections] filter is required');
@@ -75,11 +84,32 @@ export async function repairSasTimesForCollections(
     console.error('[repairSasTimesForCollections] mode is required');
     return { success: false, mode, count: 0, changed: 0, results: [] };
   }
+  if (mode === 'commit') assertWritable('SAS time repair (commit)');
   // Fetch target collections and sort chronologically (oldest first)
   const collections = await Collections.find(filter)
     .sort({ timestamp: 1 })
     .lean<CollectionDocument[]>();
 
+  let backupRunId: string | undefined;
+  if (mode === 'commit' && collections.length > 0) {
+    backupRunId = createBackupRunId('repair-sas-times');
+    await backupBeforeWrite({
+      operation: 'repair-sas-times',
+      collection: Collections.collection.collectionName,
+      filter: { _id: { $in: collections.map(c => c._id) } },
+      runId: backupRunId,
+    });
+    const machineIds = [
+      ...new Set(collections.map(c => c.machineId).filter(Boolean)),
+    ];
+    await backupBeforeWrite({
+      operation: 'repair-sas-times',
+      collection: Machine.collection.collectionName,
+      filter: { _id: { $in: machineIds } },
+      runId: backupRunId,
+    });
+  }
+
   const results: RepairResult[] = [];
 
   for (const collection of collections) {
@@ -93,6 +123,7 @@ export async function repairSasTimesForCollections(
     count: results.length,
     changed: results.filter(r => r.changed).length,
     results,
+    backupRunId,
   };
 }
 
@@ -10,9 +10,15 @@
  * @module app/api/lib/helpers/bulkSasTimesFix
  */
 
+import {
+  backupBeforeWrite,
+  createBackupRunId,
+} from '@/app/api/lib/helpers/writeBackups';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { calculateMovement } from '@/lib/utils/movement';
 import type { CollectionDocument } from '@/lib/types/collection';
 import type { CollectionReportDocument } from '@shared/types';
@@ -34,6 +40,9 @@ import type { CollectionReportDocument } from '@shared/types';
  * 5. Rebuild collectionMetersHistory for all affected machines
  * 6. Return detailed summary of fixes
  *
+ * Each report's collections and each machine are backed up under one run ID
+ * before they are rewritten.
+ *
  * @returns Object containing processing summary and detailed results
  */
 export async function fixAllSasTimesData(): Promise<{
@@ -44,7 +53,10 @@ export async function fixAllSasTimesData(): Promise<{
   totalErrors: number;
   fixedReports: string[];
   errors: string[];
+  backupRunId: string;
 }> {
+  assertWritable('bulk SAS time fix');
+  const backupRunId = createBackupRunId('bulk-fix-sas-times');
   console.warn(`🔧 Starting bulk SAS time fix for all reports...`);
 
   // Get all collection reports, sorted by timestamp
@@ -80,6 +92,13 @@ export async function fixAllSasTimesData(): Promise<{
 
       console.warn(`   📦 Found ${collections.length} collections`);
 
+      await backupBeforeWrite({
+        operation: 'bulk-fix-sas-times',
+        collection: Collections.collection.collectionName,
+        filter: { locationReportId: report.locationReportId },
+        runId: backupRunId,
+      });
+
       let reportHasIssues = false;
       let collectionsFixedInReport = 0;
 
@@ -157,7 +176,10 @@ export async function fixAllSasTimesData(): Promise<{
 
   // Rebuild collectionMetersHistory for all machines
   console.warn(`\n🔄 Rebuilding collectionMetersHistory for all machines...`);
-  const totalHistoryRebuilt = await rebuildAllMachineHistories(allReports);
+  const totalHistoryRebuilt = await rebuildAllMachineHistories(
+    allReports,
+    backupRunId
+  );
 
   // Final summary
   console.warn(`\n🎉 BULK FIX COMPLETED:`);
@@ -178,6 +200,7 @@ export async function fixAllSasTimesData(): Promise<{
     totalErrors,
     fixedReports,
     errors,
+    backupRunId,
   };
 }
 
@@ -250,7 +273,7 @@ async function checkCollectionIssues(
     const previousCollections = (await Collections.find({
       machineId: machineId,
       timestamp: { $lt: new Date(report.timestamp) },
-      deletedAt: { $exists: false },
+      ...NOT_DELETED_FILTER,
     })
       .sort({ timestamp: -1 })
       .limit(1)) as CollectionDocument[];
@@ -403,7 +426,7 @@ async function fixPrevInOut(
   const previousCollections = (await Collections.find({
     machineId: machineId,
     timestamp: { $lt: new Date(report.timestamp) },
-    deletedAt: { $exists: false },
+    ...NOT_DELETED_FILTER,
   })
     .sort({ timestamp: -1 })
     .limit(1)) as CollectionDocument[];
@@ -506,7 +529,7 @@ async function fixSasTimes(
   const previousCollections = (await Collections.find({
     machineId: machineId,
     timestamp: { $lt: new Date(collection.timestamp) },
-    deletedAt: { $exists: false },
+    ...NOT_DELETED_FILTER,
   })
     .sort({ timestamp: -1 })
     .limit(1)) as CollectionDocument[];
@@ -527,10 +550,12 @@ async function fixSasTimes(
  * Rebuild collectionMetersHistory for all machines affected by the reports
  *
  * @param allReports - All collection reports processed
+ * @param backupRunId - Run ID the machine backups are recorded under
  * @returns Total number of history entries rebuilt
  */
 async function rebuildAllMachineHistories(
-  allReports: CollectionReportDocument[]
+  allReports: CollectionReportDocument[],
+  backupRunId: string
 ): Promise<number> {
   if (!allReports || !Array.isArray(allReports)) {
     console.error('[rebuildAllMachineHistories] allReports is required');
@@ -558,7 +583,7 @@ async function rebuildAllMachineHistories(
         // Rebuild history based on actual collections for this machine
         const machineCollections = (await Collections.find({
           machineId: machineId,
-          deletedAt: { $exists: false },
+          ...NOT_DELETED_FILTER,
         }).sort({ timestamp: 1 })) as CollectionDocument[];
 
         const newHistory = machineCollections.map((collection, index) => {
@@ -582,6 +607,13 @@ async function rebuildAllMachineHistories(
           };
         });
 
+        await backupBeforeWrite({
+          operation: 'bulk-fix-sas-times',
+          collection: Machine.collection.collectionName,
+          filter: { _id: machineId },
+          runId: backupRunId,
+        });
+
         // Update machine with rebuilt history
         // CRITICAL: Use findOneAndUpdate with _id instead of findByIdAndUpdate (repo rule)
         await Machine.findOneAndUpdate(
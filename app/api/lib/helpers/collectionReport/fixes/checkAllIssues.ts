@@ -8,6 +8,7 @@
 import { Collections } from '@/app/api/lib/models/collections';
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { calculateMovement } from '@/lib/utils/movement';
 import type { CollectionDocument } from '@/lib/types/collection';
 import type { GamingMachine, CollectionReportDocument } from '@shared/types';
@@ -101,7 +102,7 @@ export async function checkAllIssues(
       mostRecentCollectionForMachine = await Collections.findOne({
         machineId,
         $and: [
-          { $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }] },
+          { ...NOT_DELETED_FILTER },
           { isCompleted: true },
         ],
       })
@@ -140,9 +141,7 @@ export async function checkAllIssues(
                 },
               ],
             },
-            {
-              $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
-            },
+            { ...NOT_DELETED_FILTER },
             { isCompleted: true },
           ],
         })
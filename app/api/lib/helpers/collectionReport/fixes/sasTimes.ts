@@ -5,9 +5,15 @@
  * including fixing prevIn/prevOut, recalculating movement, and rebuilding machine history.
  */
 
+import {
+  backupBeforeWrite,
+  createBackupRunId,
+} from '@/app/api/lib/helpers/writeBackups';
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { calculateMovement } from '@/lib/utils/movement';
 
 /**
@@ -44,7 +50,7 @@ async function fixPreviousMeters(
   const previousCollections = await Collections.find({
     machineId,
     timestamp: { $lt: reportTimestamp },
-    deletedAt: { $exists: false },
+    ...NOT_DELETED_FILTER,
   })
     .sort({ timestamp: -1 })
     .limit(1);
@@ -125,7 +131,7 @@ function recalculateCollectionMovement(
 async function rebuildMachineHistory(machineId: string): Promise<number> {
   const machineCollections = await Collections.find({
     machineId,
-    deletedAt: { $exists: false },
+    ...NOT_DELETED_FILTER,
   }).sort({ timestamp: 1 });
 
   const newHistory = machineCollections.map((collection, index) => {
@@ -326,8 +332,10 @@ export async function fixSasTimesForReport(reportId: string): Promise<{
   errors: string[];
   processedReports: string[];
   futureReportsAffected: number;
+  backupRunId?: string;
   error?: string;
 }> {
+  assertWritable('SAS time fix');
   if (!reportId) {
     console.error('[fixSasTimesForReport] reportId is required');
     return {
@@ -366,6 +374,30 @@ export async function fixSasTimesForReport(reportId: string): Promise<{
 
   const allReportsToProcess = [currentReport, ...futureReports];
 
+  // Back up the collections and machines every report below may rewrite
+  const backupRunId = createBackupRunId('fix-sas-times');
+  const collectionsFilter = {
+    locationReportId: {
+      $in: allReportsToProcess.map(report => report.locationReportId),
+    },
+  };
+  const affectedMachineIds = await Collections.distinct(
+    'machineId',
+    collectionsFilter
+  );
+  await backupBeforeWrite({
+    operation: 'fix-sas-times',
+    collection: Collections.collection.collectionName,
+    filter: collectionsFilter,
+    runId: backupRunId,
+  });
+  await backupBeforeWrite({
+    operation: 'fix-sas-times',
+    collection: Machine.collection.collectionName,
+    filter: { _id: { $in: affectedMachineIds.filter(Boolean) } },
+    runId: backupRunId,
+  });
+
   let totalFixedCount = 0;
   let totalSkippedCount = 0;
   let totalHistoryFixedCount = 0;
@@ -440,6 +472,7 @@ export async function fixSasTimesForReport(reportId: string): Promise<{
     errors: allErrors,
     processedReports,
     futureReportsAffected: futureReports.length,
+    backupRunId,
   };
 }
 
@@ -495,8 +528,10 @@ export async function fixCollectionHistoryForReport(reportId: string): Promise<{
   machinesFixedCount: number;
   machinesWithIssues: number;
   totalMachinesInReport: number;
+  backupRunId?: string;
   error?: string;
 }> {
+  assertWritable('collection history fix');
   if (!reportId) {
     console.error('[fixCollectionHistoryForReport] reportId is required');
     return {
@@ -542,6 +577,12 @@ export async function fixCollectionHistoryForReport(reportId: string): Promise<{
     ...new Set(reportCollections.map(c => c.machineId).filter(Boolean)),
   ];
 
+  const backup = await backupBeforeWrite({
+    operation: 'fix-collection-history',
+    collection: Machine.collection.collectionName,
+    filter: { _id: { $in: machineIds } },
+  });
+
   let totalHistoryRebuilt = 0;
   let machinesFixedCount = 0;
   let machinesWithIssues = 0;
@@ -581,5 +622,6 @@ export async function fixCollectionHistoryForReport(reportId: string): Promise<{
     machinesFixedCount,
     machinesWithIssues,
     totalMachinesInReport: machineIds.length,
+    backupRunId: backup.runId,
   };
 }
@@ -13,6 +13,7 @@
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus';
 import { calculateMovement } from '@/lib/utils/movement';
 import type { GamingMachine } from '@/shared/types';
 
@@ -202,7 +203,7 @@ async function processSingleMachineChange(
   // Retrieve the Machine document to check online/offline status
   const machineDoc = await Machine.findOne({ _id: machineId }).lean<GamingMachine>();
   const hasRelay = !!machineDoc?.relayId?.trim();
-  const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000;
+  const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
   const isOffline =
     hasRelay &&
     (!machineDoc?.lastActivity ||
@@ -2,19 +2,35 @@
  * Collection Issue Checker Helper
  *
  * This file contains helper functions for checking and validating collection issues,
- * including SAS time validation, previous meters validation, and movement calculation validation.
+ * including SAS time validation, previous meters validation, movement calculation validation,
+ * and SAS meter snapshot verification against raw meters.
  */
 
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type {
   CollectionIssue,
   CollectionIssueDetails,
 } from '@/shared/types/entities';
+import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Collections } from '@/app/api/lib/models/collections';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Machine } from '@/app/api/lib/models/machines';
+import { findByAnyIdType } from '@/app/api/lib/utils/mongoIds';
+import {
+  getFanOutConcurrency,
+  runBounded,
+} from '@/app/api/lib/helpers/aggregationFanOut';
+import { calculateSasMetrics } from './creation';
 import type { CollectionDocument } from '@/lib/types/collection';
 import type { GamingMachine } from '@shared/types';
 import type { CollectionReportDocument } from '@shared/types';
 
+/**
+ * Allowed difference between the stored SAS drop / cancelled credits and the
+ * values recomputed from raw meters before a collection is flagged
+ */
+export const SAS_METER_TOLERANCE = 1;
+
 function toDate(value: string | Date | undefined): Date | undefined {
   if (!value) return undefined;
   return typeof value === 'string' ? new Date(value) : value;
@@ -398,6 +414,82 @@ function validateMovementCalculation(collection: {
   return issues;
 }
 
+/**
+ * Validates the stored SAS meter snapshot for a collection by recomputing
+ * drop and cancelled credits from raw meters between sasStartTime and sasEndTime
+ *
+ * @param collection - The collection to validate
+ * @param tolerance - Allowed difference before the snapshot is flagged
+ * @returns Array of issues found
+ */
+async function validateSasMeters(
+  collection: {
+    _id: string;
+    machineId?: string;
+    machineName?: string;
+    machineCustomName?: string;
+    sasMeters?: {
+      machine?: string;
+      drop?: number | null;
+      totalCancelledCredits?: number | null;
+      sasStartTime?: string | Date;
+      sasEndTime?: string | Date;
+    };
+  },
+  tolerance: number = SAS_METER_TOLERANCE
+): Promise<CollectionIssue[]> {
+  if (!collection) {
+    console.error('[validateSasMeters] collection is required');
+    return [];
+  }
+
+  const machineId = collection.machineId || collection.sasMeters?.machine;
+  const sasStartTime = toDate(collection.sasMeters?.sasStartTime);
+  const sasEndTime = toDate(collection.sasMeters?.sasEndTime);
+
+  // Missing or inverted windows are already reported by validateSasTimes
+  if (!machineId || !sasStartTime || !sasEndTime) return [];
+  if (sasStartTime >= sasEndTime) return [];
+
+  const recalculated = await calculateSasMetrics(
+    machineId,
+    sasStartTime,
+    sasEndTime
+  );
+  const storedDrop = collection.sasMeters?.drop ?? 0;
+  const storedCancelled = collection.sasMeters?.totalCancelledCredits ?? 0;
+  const dropDiff = storedDrop - recalculated.drop;
+  const cancelledDiff = storedCancelled - recalculated.totalCancelledCredits;
+
+  if (Math.abs(dropDiff) <= tolerance && Math.abs(cancelledDiff) <= tolerance) {
+    return [];
+  }
+
+  return [
+    {
+      collectionId: collection._id.toString(),
+      machineName:
+        collection.machineName || collection.machineCustomName || 'Unknown',
+      issueType: 'sas_meters_mismatch',
+      details: {
+        current: {
+          sasDrop: storedDrop,
+          sasCancelledCredits: storedCancelled,
+          sasStartTime: formatSasTime(sasStartTime),
+          sasEndTime: formatSasTime(sasEndTime),
+        },
+        expected: {
+          sasDrop: recalculated.drop,
+          sasCancelledCredits: recalculated.totalCancelledCredits,
+          sasStartTime: formatSasTime(sasStartTime),
+          sasEndTime: formatSasTime(sasEndTime),
+        },
+        explanation: `Stored SAS meters differ from the raw meters between ${formatSasTime(sasStartTime)} and ${formatSasTime(sasEndTime)} by more than ${tolerance} (drop ${storedDrop.toFixed(2)} vs ${recalculated.drop.toFixed(2)}, cancelled credits ${storedCancelled.toFixed(2)} vs ${recalculated.totalCancelledCredits.toFixed(2)}).`,
+      },
+    },
+  ];
+}
+
 /**
  * Checks collection history issues at machine level
  *
@@ -420,23 +512,12 @@ async function checkCollectionHistoryIssues(
       throw new Error('Database connection not available');
     }
 
-    // Convert string IDs to ObjectIds for query
-    const machineObjectIds = machineIds
-      .map(id => {
-        try {
-          return new mongoose.default.Types.ObjectId(id);
-        } catch {
-          return null;
-        }
-      })
-      .filter((id): id is NonNullable<typeof id> => id !== null);
-
-    const machinesWithHistory = await Machine.find({
-      _id: { $in: machineObjectIds },
-      collectionMetersHistory: { $exists: true, $ne: [] },
-    })
-      .lean<GamingMachine[]>()
-      .exec();
+    // Machine ids may be stored as strings or ObjectIds; match both
+    const machinesWithHistory = await findByAnyIdType<GamingMachine>(
+      Machine,
+      machineIds,
+      { match: { collectionMetersHistory: { $exists: true, $ne: [] } } }
+    );
 
     for (const machine of machinesWithHistory) {
       const history = machine.collectionMetersHistory || [];
@@ -603,9 +684,7 @@ export async function checkCollectionReportIssues(
             },
           ],
         },
-        {
-          $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
-        },
+        { ...NOT_DELETED_FILTER },
         { isCompleted: true },
       ],
     })
@@ -623,6 +702,10 @@ export async function checkCollectionReportIssues(
     // Validate movement calculation
     const movementIssues = validateMovementCalculation(collection);
     issues.push(...movementIssues);
+
+    // Validate stored SAS meters against raw meters
+    const sasMeterIssues = await validateSasMeters(collection);
+    issues.push(...sasMeterIssues);
   }
 
   // Check collection history issues
@@ -654,6 +737,168 @@ export async function checkCollectionReportIssues(
   };
 }
 
+export type SasMeterVerificationScope = {
+  /** Only this report's collections */
+  reportId?: string;
+  /** Only collections timestamped on or after this date */
+  since?: Date;
+  /** Only collections timestamped before this date */
+  until?: Date;
+  location?: string;
+  /** Only locations of this licencee */
+  licencee?: string;
+  /** Check this many randomly chosen reports within the scope */
+  sample?: number;
+};
+
+export type SasMeterVerification = {
+  tolerance: number;
+  since: string | null;
+  until: string | null;
+  reportId: string | null;
+  location: string | null;
+  licencee: string | null;
+  sample: number | null;
+  /** Reports picked when sampling */
+  sampledReports: string[] | null;
+  checked: number;
+  issues: CollectionIssue[];
+};
+
+/**
+ * Recomputes the SAS meters of completed collections from raw meters and
+ * returns the ones whose stored drop / cancelled credits differ beyond
+ * tolerance. Used by the verify-sas-meters command.
+ *
+ * The scope narrows the collections checked; with `sample`, that many
+ * collection reports are drawn at random from the scope and only their
+ * collections are checked, for a quick spot check.
+ *
+ * @param scope - Report, date range, location, licencee and sample filters
+ * @param tolerance - Allowed difference (default SAS_METER_TOLERANCE)
+ * @returns Promise<SasMeterVerification>
+ */
+export async function verifySasMeterSnapshots({
+  tolerance = SAS_METER_TOLERANCE,
+  ...scope
+}: SasMeterVerificationScope & {
+  tolerance?: number;
+}): Promise<SasMeterVerification> {
+  const { reportId, since, until, location, licencee, sample } = scope;
+  const timestamp: Record<string, Date> = {};
+  if (since) timestamp.$gte = since;
+  if (until) timestamp.$lt = until;
+
+  let locationIds: string[] | undefined = location ? [location] : undefined;
+  if (licencee) {
+    const licenceeLocations = await GamingLocations.find(
+      { 'rel.licencee': licencee },
+      { _id: 1 }
+    ).lean<Array<{ _id: string }>>();
+    const ids = licenceeLocations.map(loc => String(loc._id));
+    locationIds = locationIds
+      ? locationIds.filter(id => ids.includes(id))
+      : ids;
+  }
+
+  let reportIds: string[] | undefined = reportId ? [reportId] : undefined;
+  if (sample && !reportId) {
+    const sampled = await CollectionReport.aggregate<{
+      locationReportId: string;
+    }>([
+      {
+        $match: {
+          ...(locationIds ? { location: { $in: locationIds } } : {}),
+          ...(since || until ? { timestamp } : {}),
+        },
+      },
+      { $sample: { size: sample } },
+      { $project: { _id: 0, locationReportId: 1 } },
+    ]);
+    reportIds = sampled.map(report => report.locationReportId);
+  }
+
+  const collections = await Collections.find({
+    isCompleted: true,
+    ...NOT_DELETED_FILTER,
+    'sasMeters.sasStartTime': { $ne: null },
+    'sasMeters.sasEndTime': { $ne: null },
+    ...(reportIds
+      ? { locationReportId: { $in: reportIds } }
+      : { locationReportId: { $exists: true, $ne: '' } }),
+    ...(locationIds ? { location: { $in: locationIds } } : {}),
+    ...(since || until ? { timestamp } : {}),
+  })
+    .sort({ timestamp: 1 })
+    .lean<CollectionDocument[]>();
+
+  const results = await runBounded(
+    collections,
+    getFanOutConcurrency(),
+    collection => validateSasMeters(collection, tolerance)
+  );
+
+  return {
+    tolerance,
+    since: since ? since.toISOString() : null,
+    until: until ? until.toISOString() : null,
+    reportId: reportId ?? null,
+    location: location ?? null,
+    licencee: licencee ?? null,
+    sample: sample && !reportId ? sample : null,
+    sampledReports: sample && !reportId ? (reportIds ?? []) : null,
+    checked: collections.length,
+    issues: results.flat(),
+  };
+}
+
+/**
+ * Describes the scope of a verification, e.g. "3 random reports of licencee
+ * abc, collections 2026-09-01..2026-10-01"
+ */
+function describeSasMeterScope(verification: SasMeterVerification): string {
+  if (verification.reportId) return `report ${verification.reportId}`;
+  const parts: string[] = [];
+  if (verification.sample !== null) {
+    parts.push(`${verification.sample} random report(s)`);
+  }
+  if (verification.location) parts.push(`location ${verification.location}`);
+  if (verification.licencee) parts.push(`licencee ${verification.licencee}`);
+  const from = verification.since?.slice(0, 10);
+  // `until` is exclusive; show the last day included
+  const to = verification.until
+    ? new Date(Date.parse(verification.until) - 1).toISOString().slice(0, 10)
+    : undefined;
+  if (from && to) parts.push(`collections ${from}..${to}`);
+  else if (from) parts.push(`collections since ${from}`);
+  else if (to) parts.push(`collections before ${to}`);
+  return parts.length > 0 ? parts.join(', ') : 'all collections';
+}
+
+/**
+ * Formats a SAS meter verification as plain text for the command line
+ */
+export function formatSasMeterVerification(
+  verification: SasMeterVerification
+): string {
+  const lines = [
+    `SAS meter verification for ${describeSasMeterScope(verification)} (tolerance ${verification.tolerance})`,
+    `Checked ${verification.checked} collection(s), ${verification.issues.length} mismatch(es)`,
+  ];
+  if (verification.sampledReports) {
+    lines.push(
+      `Sampled reports: ${verification.sampledReports.join(', ') || 'none'}`
+    );
+  }
+  verification.issues.forEach(issue => {
+    const { current, expected } = issue.details;
+    lines.push(
+      `  ${issue.collectionId}  ${issue.machineName}  drop ${current?.sasDrop ?? 0} -> ${expected?.sasDrop ?? 0}  cancelled ${current?.sasCancelledCredits ?? 0} -> ${expected?.sasCancelledCredits ?? 0}`
+    );
+  });
+  return lines.join('\n');
+}
+
 /**
  * Investigates the most recent collection report for issues
  *
@@ -11,6 +11,7 @@
 
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CollectionDocument } from '@/lib/types/collection';
 import type { GamingMachine } from '@shared/types';
 
@@ -107,7 +108,7 @@ export async function fixMachineCollectionHistory(
       // Get all actual collections for this machine, sorted chronologically
       let actualCollections = await Collections.find({
         machineId: machineId,
-        deletedAt: { $exists: false },
+        ...NOT_DELETED_FILTER,
       })
         .sort({
           collectionTime: 1,
@@ -203,7 +204,7 @@ export async function fixMachineCollectionHistory(
           // Refresh actual collections after deduplication
           actualCollections = await Collections.find({
             machineId: machineId,
-            deletedAt: { $exists: false },
+            ...NOT_DELETED_FILTER,
           })
             .sort({
               collectionTime: 1,
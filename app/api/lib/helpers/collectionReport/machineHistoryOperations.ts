@@ -5,16 +5,14 @@ import {
   ReportedMachine,
   type ReportedMachineDocument,
 } from '@/app/api/lib/models/reportedMachines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { ICollectionReport } from '@/lib/types/api';
 import type { CollectionDocument } from '@/lib/types/collection';
 import type { MachineReportHistoryEntry } from '@shared/types/collectionReportHistory';
 import type { GamingMachine } from '@shared/types/entities';
 
-const DELETED_CUTOFF = new Date('2025-01-01');
-
 function isActiveRecord(deletedAt: Date | null | undefined): boolean {
-  if (!deletedAt) return true;
-  return deletedAt < DELETED_CUTOFF;
+  return !deletedAt;
 }
 
 function resolveMachineGross(collection: CollectionDocument): number {
@@ -219,7 +217,7 @@ export async function getMachineReportHistory(
   const v2Filter: Record<string, unknown> = {
     machineId,
     sessionStatus: 'submitted',
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: DELETED_CUTOFF } }],
+    ...NOT_DELETED_FILTER,
   };
 
   if (allowedLocationIds !== 'all') {
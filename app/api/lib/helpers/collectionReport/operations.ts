@@ -8,8 +8,10 @@
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus';
 import { recalculateMachineCollections } from './recalculation';
 import { computeTotalVariation } from './calculations';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CreateCollectionReportPayload } from '@/lib/types/api';
 import type { CollectionDocument } from '@/lib/types/collection';
 import { GamingLocations } from '../../models/gaminglocations';
@@ -282,9 +284,7 @@ async function findPreviousCollectionForRevert(
           { timestamp: { $lt: currentCollectionTime } },
         ],
       },
-      {
-        $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
-      },
+      { ...NOT_DELETED_FILTER },
       { isCompleted: true },
     ],
   })
@@ -562,7 +562,7 @@ export async function deleteManualMetersPerCollection(
     ).lean<Array<{ _id: string; relayId?: string | null; lastActivity?: Date | string | null }>>();
     const machineMap = new Map(machineDocs.map(doc => [String(doc._id), doc]));
 
-    const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000;
+    const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
 
     // ============================================================================
     // Process all collections in parallel. Each machine/collection is independent
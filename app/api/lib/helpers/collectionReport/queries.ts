@@ -20,6 +20,7 @@ import { connectDB } from '@/app/api/lib/middleware/db';
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import type { TimePeriod } from '@/app/api/lib/types';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { resolveLicenceeId } from '@/lib/utils/licencee';
 import { PipelineStage } from 'mongoose';
 import type { GamingLocationDocument } from '@shared/types';
@@ -102,9 +103,7 @@ export async function fetchLocationsWithMachines(
     return { locations: [] };
   }
 
-  const matchCriteria: Record<string, unknown> = {
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
-  };
+  const matchCriteria: Record<string, unknown> = { ...NOT_DELETED_FILTER };
 
   // Apply location filter based on user permissions
   if (allowedLocationIds !== 'all') {
@@ -143,12 +142,7 @@ export async function fetchLocationsWithMachines(
         as: 'machines',
         pipeline: [
           {
-            $match: {
-              $or: [
-                { deletedAt: null },
-                { deletedAt: { $lt: new Date('2025-01-01') } },
-              ],
-            },
+            $match: { ...NOT_DELETED_FILTER },
           },
           {
             $project: {
@@ -388,12 +382,7 @@ export async function determineAllowedLocationIds(
       {
         'rel.licencee': { $in: userLicencees },
         $and: [
-          {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          },
+          { ...NOT_DELETED_FILTER },
         ],
       },
       { _id: 1 }
@@ -426,12 +415,7 @@ export async function determineAllowedLocationIds(
       {
         'rel.licencee': { $in: userLicencees },
         $and: [
-          {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          },
+          { ...NOT_DELETED_FILTER },
         ],
       },
       { _id: 1 }
@@ -1,8 +1,10 @@
 import mongoose from 'mongoose';
 import { Collections } from '@/app/api/lib/models/collections';
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus';
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
 
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { GamingMachine } from '@/shared/types';
 
 type CollectionSnapshot = {
@@ -76,7 +78,7 @@ export async function recalculateMachineCollections(
   }
 
   const isNoSmibMachine = !machine.relayId;
-  const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000;
+  const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
   const isOffline =
     !!machine.relayId &&
     (!machine.lastActivity ||
@@ -88,13 +90,13 @@ export async function recalculateMachineCollections(
   // ==========================================================================
   const v1Collections = await Collections.find({
     machineId,
-    $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+    ...NOT_DELETED_FILTER,
   }).lean<CollectionSnapshot[]>();
 
   const v2Sessions = await ReportedMachine.find({
     machineId,
     sessionStatus: 'submitted',
-    $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+    ...NOT_DELETED_FILTER,
   })
     .sort({ sasEndTime: 1 })
     .lean<V2SessionSnapshot[]>();
@@ -0,0 +1,255 @@
+/**
+ * Collection Report Regeneration Helper
+ *
+ * Recomputes a collection report's aggregate fields from its member
+ * collections after machine data was corrected, so the stored totals stop
+ * disagreeing with the collections they summarise. Uses the same rules as
+ * report creation (`calculateCollectionReportTotals`): drop and cancelled are
+ * the sums of `movement.metersIn` / `movement.metersOut`, SAS gross is the sum
+ * of `sasMeters.gross`, and the report's `includeJackpot` adds jackpots to
+ * cancelled (and takes them off SAS gross). `totalVariation` comes from
+ * `computeTotalVariation`.
+ *
+ * `previewReportRegeneration()` only reads and returns a field-by-field diff;
+ * `applyReportRegeneration()` writes it if the report was not changed in
+ * between. Used by the `regenerate-report` command.
+ *
+ * @module app/api/lib/helpers/collectionReport/regeneration
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { CollectionReport } from '@/app/api/lib/models/collectionReport';
+import { Collections } from '@/app/api/lib/models/collections';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type { CollectionDocument } from '@/lib/types/collection';
+import { computeTotalVariation } from './calculations';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export const REGENERATED_FIELDS = [
+  'totalDrop',
+  'totalCancelled',
+  'totalGross',
+  'totalSasGross',
+  'totalVariation',
+  'machinesCollected',
+] as const;
+
+export type RegeneratedField = (typeof REGENERATED_FIELDS)[number];
+
+export type RegenerationFieldDiff = {
+  field: RegeneratedField;
+  stored: number | string | null;
+  recomputed: number | string;
+};
+
+export type ReportRegenerationPreview = {
+  reportId: string;
+  locationReportId: string;
+  locationName: string;
+  includeJackpot: boolean;
+  collections: number;
+  /** Fields whose recomputed value differs from the stored one */
+  changes: RegenerationFieldDiff[];
+  recomputed: Record<RegeneratedField, number | string>;
+  /** Stored `updatedAt`, used to refuse writing over a concurrent edit */
+  updatedAt: Date | null;
+};
+
+type StoredReport = {
+  _id: string;
+  locationReportId: string;
+  locationName: string;
+  location: string;
+  includeJackpot?: boolean;
+  updatedAt?: Date;
+} & Partial<Record<RegeneratedField, number | string>>;
+
+/** Differences below this are rounding noise */
+const TOLERANCE = 0.005;
+
+function round(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+function differs(
+  stored: number | string | null | undefined,
+  recomputed: number | string
+): boolean {
+  if (typeof recomputed === 'string') return String(stored ?? '') !== recomputed;
+  return (
+    typeof stored !== 'number' || Math.abs(stored - recomputed) > TOLERANCE
+  );
+}
+
+// ============================================================================
+// Preview
+// ============================================================================
+
+/**
+ * Recomputes a report's totals from its collections without writing.
+ *
+ * @param reportId - Report `_id` or `locationReportId`
+ * @returns Recomputed values and the fields that would change
+ * @throws Error with `statusCode` 404 when the report does not exist
+ */
+export async function previewReportRegeneration(
+  reportId: string
+): Promise<ReportRegenerationPreview> {
+  const report = await CollectionReport.findOne({
+    $or: [{ _id: reportId }, { locationReportId: reportId }],
+    ...NOT_DELETED_FILTER,
+  }).lean<StoredReport>();
+  if (!report) throw statusError(`Collection report ${reportId} not found`, 404);
+
+  const collections = await Collections.find(
+    { locationReportId: report.locationReportId, ...NOT_DELETED_FILTER },
+    { movement: 1, sasMeters: 1 }
+  ).lean<Pick<CollectionDocument, 'movement' | 'sasMeters'>[]>();
+
+  // Step 1: Sum the member collections
+  let drop = 0;
+  let cancelled = 0;
+  let sasGross = 0;
+  let jackpot = 0;
+  collections.forEach(collection => {
+    drop += collection.movement?.metersIn || 0;
+    cancelled += collection.movement?.metersOut || 0;
+    sasGross += collection.sasMeters?.gross || 0;
+    jackpot += collection.sasMeters?.jackpot || 0;
+  });
+
+  // Step 2: Apply the report's jackpot rule, as at creation
+  const includeJackpot = Boolean(report.includeJackpot);
+  const totalCancelled = includeJackpot ? cancelled + jackpot : cancelled;
+  const totalVariation = await computeTotalVariation(
+    report.locationReportId,
+    report.location,
+    includeJackpot
+  );
+  const recomputed: Record<RegeneratedField, number | string> = {
+    totalDrop: round(drop),
+    totalCancelled: round(totalCancelled),
+    totalGross: round(drop - totalCancelled),
+    totalSasGross: round(
+      includeJackpot ? Math.max(0, sasGross - jackpot) : sasGross
+    ),
+    totalVariation: round(totalVariation),
+    machinesCollected: String(collections.length),
+  };
+
+  // Step 3: Diff against the stored values
+  const changes = REGENERATED_FIELDS.filter(field =>
+    differs(report[field], recomputed[field])
+  ).map(field => ({
+    field,
+    stored: report[field] ?? null,
+    recomputed: recomputed[field],
+  }));
+
+  return {
+    reportId: String(report._id),
+    locationReportId: report.locationReportId,
+    locationName: report.locationName,
+    includeJackpot,
+    collections: collections.length,
+    changes,
+    recomputed,
+    updatedAt: report.updatedAt ?? null,
+  };
+}
+
+// ============================================================================
+// Apply
+// ============================================================================
+
+/**
+ * Writes a preview's changed fields to the report and logs the change.
+ *
+ * @param preview - Result of `previewReportRegeneration()`
+ * @param user - Acting user, for the activity log
+ * @returns Number of fields written (0 when nothing changed)
+ * @throws Error with `statusCode` 409 when the report changed since the preview
+ */
+export async function applyReportRegeneration(
+  preview: ReportRegenerationPreview,
+  user: { _id: string; username: string }
+): Promise<number> {
+  if (preview.changes.length === 0) return 0;
+  assertWritable('regenerating a collection report');
+
+  const set: Record<string, unknown> = {};
+  preview.changes.forEach(change => {
+    set[change.field] = change.recomputed;
+  });
+
+  await backupBeforeWrite({
+    operation: 'regenerate-report',
+    collection: CollectionReport.collection.collectionName,
+    filter: { _id: preview.reportId },
+    by: user.username,
+  });
+  const result = await CollectionReport.updateOne(
+    { _id: preview.reportId, updatedAt: preview.updatedAt },
+    { $set: set }
+  );
+  if (result.matchedCount === 0) {
+    throw statusError(
+      'Collection report changed since the preview; run it again',
+      409
+    );
+  }
+
+  await logActivity({
+    action: 'UPDATE',
+    details: `Regenerated totals of collection report for ${preview.locationName}: ${preview.changes
+      .map(change => change.field)
+      .join(', ')}`,
+    userId: user._id,
+    username: user.username,
+    metadata: {
+      resource: 'collection-report',
+      resourceId: preview.locationReportId,
+      resourceName: preview.locationName,
+      changes: preview.changes.map(change => ({
+        field: change.field,
+        oldValue: change.stored,
+        newValue: change.recomputed,
+      })),
+    },
+  });
+
+  return preview.changes.length;
+}
+
+/**
+ * Preview diff as aligned text, one line per changed field.
+ */
+export function formatRegenerationPreview(
+  preview: ReportRegenerationPreview
+): string {
+  const header = `${preview.locationName} — report ${preview.locationReportId} (${preview.collections} collections${
+    preview.includeJackpot ? ', includes jackpot' : ''
+  })`;
+  if (preview.changes.length === 0) {
+    return `${header}\nTotals are up to date`;
+  }
+  const lines = preview.changes.map(change => {
+    const delta =
+      typeof change.recomputed === 'number' &&
+      typeof change.stored === 'number'
+        ? `  (${change.recomputed - change.stored >= 0 ? '+' : ''}${round(
+            change.recomputed - change.stored
+          )})`
+        : '';
+    return `  ${change.field.padEnd(18)} ${String(change.stored ?? '—').padStart(
+      14
+    )} → ${String(change.recomputed).padStart(14)}${delta}`;
+  });
+  return [header, ...lines].join('\n');
+}
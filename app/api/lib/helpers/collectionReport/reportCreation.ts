@@ -17,7 +17,9 @@
 
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus';
 import UserModel from '@/app/api/lib/models/user';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CreateCollectionReportPayload } from '@/lib/types/api';
 import type { CollectionDocument } from '@/lib/types/collection';
 import mongoose from 'mongoose';
@@ -283,7 +285,7 @@ async function updateMachineCollectionData(
     'relayId lastActivity'
   ).lean<{ relayId?: string | null; lastActivity?: Date }>();
   const isNoSmibMachine = !machineForRelay?.relayId;
-  const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000; // 3 minutes for testing (TODO: restore 72h)
+  const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
   const isOffline =
     !!machineForRelay?.relayId &&
     (!machineForRelay.lastActivity ||
@@ -547,7 +549,7 @@ async function buildMetersForMachine(
 
   const hasRelay = !!machineDoc?.relayId;
   // TODO: restore 72h after testing: 3 * 24 * 60 * 60 * 1000
-  const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000; // 3 minutes for testing
+  const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
   const isOffline =
     hasRelay &&
     (!machineDoc?.lastActivity ||
@@ -567,7 +569,7 @@ async function buildMetersForMachine(
   if (isOffline) {
     prevMeterDoc = await Meters.findOne({
       machine: machine.machineId,
-      $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+      ...NOT_DELETED_FILTER,
     })
       .sort({ readAt: -1 })
       .lean<MeterDocument>();
@@ -1029,7 +1031,7 @@ export async function updateRegularAndRamClearMeters(
 
       const hasRelay = !!machineDoc?.relayId;
       // TODO: restore 72h after testing: 3 * 24 * 60 * 60 * 1000
-      const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000; // 3 minutes for testing
+      const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
       isOffline =
         hasRelay &&
         (!machineDoc?.lastActivity ||
@@ -1066,7 +1068,7 @@ export async function updateRegularAndRamClearMeters(
       const baseFilter = {
         meterSource: 'COLLECTION_REPORT' as const,
         readAt: { $lte: newReadAt },
-        $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+        ...NOT_DELETED_FILTER,
       };
 
       let existingMeter = await Meters.findOne({
@@ -1105,7 +1107,7 @@ export async function updateRegularAndRamClearMeters(
         machine: collectionDocument.machineId,
         _id: { $nin: [meterId, ramClearMeterId].filter(Boolean) },
         readAt: { $lt: newReadAt },
-        $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+        ...NOT_DELETED_FILTER,
       })
         .sort({ readAt: -1 })
         .lean<MeterDocument>();
@@ -11,6 +11,7 @@ import { Collections } from '@/app/api/lib/models/collections';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { fetchLocationsWithMachines } from '@/app/api/lib/helpers/collectionReport/queries';
 import { logRouteError } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CreateCollectionReportPayload } from '@/lib/types/api';
 import type { CollectionReportRow } from '@/lib/types/components';
 import type { CollectionDocument, GamingLocationDocument } from '@shared/types';
@@ -53,13 +54,13 @@ export async function filterMachinesByChronologicalOrder(
     const nextReport = await Collections.findOne({
       machineId: machine.machineId,
       timestamp: { $gt: targetTime },
-      deletedAt: { $exists: false },
+      ...NOT_DELETED_FILTER,
     }).lean<CollectionDocument>();
 
     const prevReport = await Collections.findOne({
       machineId: machine.machineId,
       timestamp: { $lt: targetTime },
-      deletedAt: { $exists: false },
+      ...NOT_DELETED_FILTER,
     }).lean<CollectionDocument>();
 
     if (nextReport && prevReport) {
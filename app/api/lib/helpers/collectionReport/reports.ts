@@ -10,6 +10,7 @@
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import UserModel from '@/app/api/lib/models/user';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { UserDocument, GamingLocationDocument } from '@shared/types';
 
 /**
@@ -161,12 +162,7 @@ export async function buildCollectionReportsLocationFilter(
           { 'rel.licencee': { $in: licencees } },
         ],
         $and: [
-          {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          },
+          { ...NOT_DELETED_FILTER },
         ],
       },
       { _id: 1 }
@@ -22,6 +22,7 @@ import {
   getMoneyInScale,
   getMoneyOutAndJackpotScale,
 } from '@/app/api/lib/utils/reviewerScale';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { CollectionReportRow } from '@/lib/types/components';
 import { PipelineStage } from 'mongoose';
 
@@ -63,11 +64,7 @@ export async function getAllCollectionReportsWithMachineCounts(
   const matchCriteria: Record<string, unknown> = {};
 
   // Apply deletedAt filter - only show active reports (filter out archived)
-  matchCriteria.$or = [
-    { deletedAt: null },
-    { deletedAt: { $exists: false } },
-    { deletedAt: { $lt: new Date('2025-01-01') } },
-  ];
+  matchCriteria.deletedAt = NOT_DELETED_FILTER.deletedAt;
 
   // Add date range filtering if provided
   if (startDate && endDate) {
@@ -287,10 +284,7 @@ export async function getAllCollectionReportsWithMachineCounts(
     {
       $match: {
         gamingLocation: { $in: uniqueLocationIds },
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
+        ...NOT_DELETED_FILTER,
       },
     },
     {
@@ -6,19 +6,14 @@
  */
 
 import { Meters } from '../../models/meters';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { MeterDocument } from '@/shared/types';
 
 // ============================================================================
 // Active meter filter (excludes soft-deleted docs)
 // ============================================================================
 
-const ACTIVE_METER_FILTER = {
-  $or: [
-    { deletedAt: null },
-    { deletedAt: { $exists: false } },
-    { deletedAt: { $lt: new Date('2025-01-01') } },
-  ],
-};
+const ACTIVE_METER_FILTER = { ...NOT_DELETED_FILTER };
 
 // ============================================================================
 // Public helper
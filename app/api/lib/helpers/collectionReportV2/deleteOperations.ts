@@ -11,6 +11,7 @@
 import { Machine } from '@/app/api/lib/models/machines';
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
 import type { ReportedMachineDocument } from '@/app/api/lib/models/reportedMachines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 // ============================================================================
 // Types
@@ -80,7 +81,7 @@ async function revertSingleMachine(
       sessionStatus: 'submitted',
       sessionId: { $ne: sessionId },
       sasEndTime: { $lt: sasEndTime },
-      $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+      ...NOT_DELETED_FILTER,
     })
       .sort({ sasEndTime: -1 })
       .select('sasMetersIn sasMetersOut manualMetersIn manualMetersOut')
@@ -10,6 +10,7 @@
  */
 
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { deleteDriveFile } from '@/lib/utils/drive';
 import { computeMovement } from '@/app/api/lib/helpers/collectionReportV2/movement';
 import {
@@ -65,37 +66,19 @@ type RecalculatedFields = {
   manualMetersOut?: number | null;
 };
 
-const VALID_STATUSES: ReportedMachineStatus[] = [
-  'pending',
-  'captured',
-  'confirmed',
-  'skipped',
-];
-
 // ============================================================================
 // Validation
 // ============================================================================
 
 /**
- * Validate the incoming capture payload. Checks required fields, status, and
- * RAM clear peak requirements.
+ * Validate the incoming capture payload, already checked against the route
+ * schema, for the RAM clear peak requirements.
  */
 export function validateCapturePayload(
   body: CaptureMachinePayload
 ): { data: ParsedCapturePayload } | { error: string; status: number } {
   const { sessionId, machineId, locationId, locationName, status } = body;
 
-  if (!sessionId || !machineId || !locationId || !locationName) {
-    return {
-      error: 'sessionId, machineId, locationId, locationName are required',
-      status: 400,
-    };
-  }
-
-  if (!VALID_STATUSES.includes(status)) {
-    return { error: 'Invalid status value', status: 400 };
-  }
-
   const ramClear = body.ramClear === true;
   const ramClearIn =
     body.ramClearMetersIn !== undefined && body.ramClearMetersIn !== null
@@ -213,7 +196,7 @@ export function buildCaptureDocumentData(
     metersMatch: body.metersMatch ?? undefined,
     sequenceOrder: Number(body.sequenceOrder) || 0,
     status: parsed.status,
-    deletedAt: null,
+    ...NOT_DELETED_FILTER,
     tempImageData:
       body.imageData?.startsWith('data:image/')
         ? body.imageData
@@ -259,7 +242,7 @@ export async function checkChronologicalEdit(
     sessionStatus: 'submitted',
     sessionId: { $ne: targetMachine.sessionId },
     sasEndTime: { $gt: targetTime },
-    $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+    ...NOT_DELETED_FILTER,
   }).lean<ReportedMachineDocument>();
 
   if (!nextReport) return null;
@@ -269,7 +252,7 @@ export async function checkChronologicalEdit(
     sessionStatus: 'submitted',
     sessionId: { $ne: targetMachine.sessionId },
     sasEndTime: { $lt: targetTime },
-    $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+    ...NOT_DELETED_FILTER,
   }).lean<ReportedMachineDocument>();
 
   if (prevReport) {
@@ -9,6 +9,7 @@
  */
 
 import { Meters } from '@/app/api/lib/models/meters';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id';
 import type { MeterDocument } from '@/shared/types';
 
@@ -190,7 +191,7 @@ export async function loadSupplementalMeterFields(
     machine: machineId,
     locationSession: { $ne: sessionId },
     readAt: { $lt: readAt },
-    $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+    ...NOT_DELETED_FILTER,
   })
     .sort({ readAt: -1 })
     .lean<MeterDocument>();
@@ -57,9 +57,11 @@
 
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus';
 import { Meters } from '@/app/api/lib/models/meters';
 import { Collections } from '@/app/api/lib/models/collections';
 import type { ReportedMachineMovement } from '@/app/api/lib/models/reportedMachines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 type PrevMeters = {
   prevSasMetersIn: number;
@@ -196,7 +198,7 @@ export async function computeMovement(
         machineId,
         sessionId: { $ne: currentSessionId },
         sessionStatus: 'submitted',
-        $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+        ...NOT_DELETED_FILTER,
       })
         .sort({ sasEndTime: -1 })
         .select('sasEndTime')
@@ -247,7 +249,7 @@ export async function computeMovement(
     lastActivity?: Date | string;
   }>();
   const hasRelay = !!machine?.relayId;
-  const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000;
+  const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
   const isOffline =
     hasRelay &&
     (!machine?.lastActivity ||
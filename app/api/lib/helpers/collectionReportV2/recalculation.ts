@@ -10,12 +10,14 @@
  */
 
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineThresholdMs } from '@/app/api/lib/utils/machineStatus';
 import {
   resolvePrevMeters,
   upsertCollectionReportMeters,
 } from '@/app/api/lib/helpers/collectionReportV2/meterDocuments';
 import { ReportedMachine } from '@/app/api/lib/models/reportedMachines';
 import type { ReportedMachineDocument } from '@/app/api/lib/models/reportedMachines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 type CascadeInput = {
   machineId: string;
@@ -64,7 +66,7 @@ export async function cascadeMachineEdit(input: CascadeInput): Promise<void> {
     }>;
   }>();
   const isNoSMIBLocation = !machineDoc?.relayId;
-  const OFFLINE_THRESHOLD_MS = 3 * 60 * 1000; // 3 minutes for testing (TODO: restore 72h)
+  const OFFLINE_THRESHOLD_MS = getOnlineThresholdMs();
   const isOffline =
     !!machineDoc?.relayId &&
     (!machineDoc.lastActivity ||
@@ -218,7 +220,7 @@ export async function propagateV2MetersToNextSession(
     sessionStatus: 'submitted',
     sessionId: { $ne: currentSessionId },
     sasEndTime: { $gt: sasEndTime },
-    $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+    ...NOT_DELETED_FILTER,
   })
     .sort({ sasEndTime: 1 })
     .lean<ReportedMachineDocument>();
@@ -13,6 +13,7 @@ import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
 import { Collections } from '@/app/api/lib/models/collections';
 import UserModel from '@/app/api/lib/models/user';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { isWowMachine } from '@/shared/utils/wowMachine';
 import { determineAllowedLocationIds } from '@/app/api/lib/helpers/collectionReport/queries';
 import { calculateDateRangeForTimePeriod } from '@/app/api/lib/helpers/collectionReport/queries';
@@ -303,11 +304,11 @@ export function buildSessionListMatchStage(
         searchKey = 'collectorName';
     }
     matchStage.$and = [
-      { $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }] },
+      { ...NOT_DELETED_FILTER },
       { [searchKey]: { $regex: search, $options: 'i' } },
     ];
   } else {
-    matchStage.$or = [{ deletedAt: null }, { deletedAt: { $exists: false } }];
+    matchStage.deletedAt = NOT_DELETED_FILTER.deletedAt;
   }
 
   return matchStage;
@@ -513,7 +514,7 @@ export async function lookupPreviousSessionEndTime(
     locationId,
     sessionStatus: 'submitted',
     sessionEndTime: { $exists: true, $ne: null },
-    $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+    ...NOT_DELETED_FILTER,
   })
     .sort({ sessionEndTime: -1 })
     .select('sessionEndTime')
@@ -536,7 +537,7 @@ export async function lookupLastSessionEndTimes(
   const v2Match: Record<string, unknown> = {
     machineId: { $in: machineIds },
     sessionStatus: 'submitted',
-    $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+    ...NOT_DELETED_FILTER,
   };
   if (excludedSessionId) {
     v2Match._id = { $nin: [excludedSessionId] };
@@ -15,6 +15,7 @@ import {
   loadSupplementalMeterFields,
   upsertCollectionReportMeters,
 } from '@/app/api/lib/helpers/collectionReportV2/meterDocuments';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id';
 import { isWowMachine } from '@/shared/utils/wowMachine';
 import {
@@ -165,7 +166,7 @@ export async function validateChronologicalSubmit(
       sessionStatus: 'submitted',
       sessionId: { $ne: sessionId },
       sasEndTime: { $gt: targetTime },
-      $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+      ...NOT_DELETED_FILTER,
     }).lean<{ _id: string }>();
 
     if (nextReport) {
@@ -174,7 +175,7 @@ export async function validateChronologicalSubmit(
         sessionStatus: 'submitted',
         sessionId: { $ne: sessionId },
         sasEndTime: { $lt: targetTime },
-        $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+        ...NOT_DELETED_FILTER,
       }).lean<{ _id: string }>();
 
       if (prevReport) {
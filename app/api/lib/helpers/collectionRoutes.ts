@@ -0,0 +1,453 @@
+/**
+ * Collection Route Planning Helper
+ *
+ * Suggests the order a collector visits their due locations on a day: the
+ * pending, non-deleted schedules (`schedulers`) assigned to the collector
+ * whose window overlaps the day (UTC), one stop per location, ordered by
+ * the location coordinates. The order starts at the given start point (or
+ * at the location with the earliest window), takes the nearest unvisited
+ * location each time, then shortens the path with 2-opt swaps. Distances
+ * are great-circle kilometres, not road distances.
+ *
+ * Locations without usable coordinates cannot be placed; they are listed
+ * after the route so the collector still sees them.
+ *
+ * Exported as CSV or GeoJSON for the field app by
+ * `scripts/collection-route.ts`.
+ *
+ * @module app/api/lib/helpers/collectionRoutes
+ */
+
+import { getLocationCoordinates } from '@/app/api/lib/helpers/reports/locationHeatmap';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import Scheduler from '@/app/api/lib/models/scheduler';
+import UserModel from '@/app/api/lib/models/user';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type Coordinates = { latitude: number; longitude: number };
+
+export type CollectionRouteStop = {
+  /** 1-based visit order; null for locations without coordinates */
+  stop: number | null;
+  locationId: string;
+  locationName: string;
+  address: string;
+  latitude: number | null;
+  longitude: number | null;
+  /** Earliest window start and latest window end of the location's schedules */
+  windowStart: Date;
+  windowEnd: Date;
+  schedulerIds: string[];
+  /** Kilometres from the previous stop (or the start point) */
+  legKm: number | null;
+  /** Kilometres from the start of the route */
+  cumulativeKm: number | null;
+};
+
+export type CollectionRoute = {
+  collectorId: string;
+  collectorName: string;
+  /** `YYYY-MM-DD` (UTC) */
+  day: string;
+  start: Coordinates | null;
+  /** Routed stops in visit order, then unrouted ones */
+  stops: CollectionRouteStop[];
+  totalKm: number;
+  /** Locations left out of the route for lack of coordinates */
+  unrouted: number;
+};
+
+export type PlanCollectionRouteParams = {
+  /** Collector user id or username */
+  collector: string;
+  /** `YYYY-MM-DD` (UTC) */
+  day: string;
+  /** Where the collector sets off; defaults to the earliest-window stop */
+  start?: Coordinates | null;
+};
+
+type RouteLocation = {
+  _id: string;
+  name?: string;
+  address?: { street?: string; city?: string };
+  geoCoords?: {
+    latitude?: number;
+    longitude?: number;
+    longtitude?: number;
+  };
+};
+
+type DueLocation = {
+  location: RouteLocation;
+  coordinates: Coordinates | null;
+  windowStart: Date;
+  windowEnd: Date;
+  schedulerIds: string[];
+};
+
+const EARTH_RADIUS_KM = 6371;
+const DAY_PATTERN = /^\d{4}-\d{2}-\d{2}$/;
+const MAX_TWO_OPT_PASSES = 50;
+
+// ============================================================================
+// Distances
+// ============================================================================
+
+/**
+ * Great-circle distance in kilometres (haversine).
+ */
+export function distanceKm(from: Coordinates, to: Coordinates): number {
+  const toRadians = (degrees: number) => (degrees * Math.PI) / 180;
+  const dLat = toRadians(to.latitude - from.latitude);
+  const dLng = toRadians(to.longitude - from.longitude);
+  const a =
+    Math.sin(dLat / 2) ** 2 +
+    Math.cos(toRadians(from.latitude)) *
+      Math.cos(toRadians(to.latitude)) *
+      Math.sin(dLng / 2) ** 2;
+  return 2 * EARTH_RADIUS_KM * Math.asin(Math.min(1, Math.sqrt(a)));
+}
+
+/**
+ * Parses `lat,lng`.
+ *
+ * @throws Error with `statusCode = 400` on malformed or out-of-range values
+ */
+export function parseCoordinates(value: string): Coordinates {
+  const [latitude, longitude] = value.split(',').map(part => Number(part));
+  if (
+    !Number.isFinite(latitude) ||
+    !Number.isFinite(longitude) ||
+    Math.abs(latitude) > 90 ||
+    Math.abs(longitude) > 180
+  ) {
+    throw statusError(`Invalid coordinates '${value}'; use lat,lng`, 400);
+  }
+  return { latitude, longitude };
+}
+
+/**
+ * Orders points from a start: nearest neighbour, then 2-opt (reversing a
+ * stretch of the path whenever that shortens it). The start stays fixed.
+ *
+ * @param start - Start point, not part of the returned order
+ * @param points - Points to visit
+ * @returns Indexes into `points` in visit order
+ */
+export function orderByDistance(
+  start: Coordinates,
+  points: Coordinates[]
+): number[] {
+  const remaining = points.map((_, index) => index);
+  const order: number[] = [];
+  let current = start;
+  while (remaining.length > 0) {
+    let nearest = 0;
+    remaining.forEach((pointIndex, index) => {
+      if (
+        distanceKm(current, points[pointIndex]) <
+        distanceKm(current, points[remaining[nearest]])
+      ) {
+        nearest = index;
+      }
+    });
+    const [next] = remaining.splice(nearest, 1);
+    order.push(next);
+    current = points[next];
+  }
+
+  const at = (position: number) =>
+    position < 0 ? start : points[order[position]];
+  for (let pass = 0; pass < MAX_TWO_OPT_PASSES; pass++) {
+    let improved = false;
+    for (let i = 0; i < order.length - 1; i++) {
+      for (let j = i + 1; j < order.length; j++) {
+        const before =
+          distanceKm(at(i - 1), at(i)) +
+          (j + 1 < order.length ? distanceKm(at(j), at(j + 1)) : 0);
+        const after =
+          distanceKm(at(i - 1), at(j)) +
+          (j + 1 < order.length ? distanceKm(at(i), at(j + 1)) : 0);
+        if (after + 1e-9 < before) {
+          order.splice(i, j - i + 1, ...order.slice(i, j + 1).reverse());
+          improved = true;
+        }
+      }
+    }
+    if (!improved) break;
+  }
+  return order;
+}
+
+// ============================================================================
+// Planning
+// ============================================================================
+
+/**
+ * Finds a collector by user id or username.
+ *
+ * @throws Error with `statusCode = 404` when no user matches
+ */
+async function resolveCollector(
+  collector: string
+): Promise<{ _id: string; name: string }> {
+  const user = await UserModel.findOne(
+    { $or: [{ _id: collector }, { username: collector }] },
+    { _id: 1, username: 1, emailAddress: 1 }
+  ).lean<{ _id: string; username?: string; emailAddress?: string }>();
+  if (!user) throw statusError(`Collector ${collector} not found`, 404);
+  return {
+    _id: String(user._id),
+    name: user.username || user.emailAddress || String(user._id),
+  };
+}
+
+/**
+ * Plans a collector's route for a day.
+ *
+ * @throws Error with `statusCode` 400 on an invalid day, 404 for an unknown
+ * collector
+ */
+export async function planCollectionRoute(
+  params: PlanCollectionRouteParams
+): Promise<CollectionRoute> {
+  if (!DAY_PATTERN.test(params.day)) {
+    throw statusError('day must be YYYY-MM-DD', 400);
+  }
+  const dayStart = new Date(`${params.day}T00:00:00.000Z`);
+  if (Number.isNaN(dayStart.getTime())) {
+    throw statusError(`Invalid day ${params.day}`, 400);
+  }
+  const dayEnd = new Date(dayStart.getTime() + 24 * 60 * 60 * 1000);
+  const collector = await resolveCollector(params.collector);
+
+  // Step 1: Due schedules, grouped by location
+  const schedules = await Scheduler.find(
+    {
+      collector: collector._id,
+      status: 'pending',
+      ...NOT_DELETED_FILTER,
+      startTime: { $lt: dayEnd },
+      endTime: { $gte: dayStart },
+    },
+    { _id: 1, location: 1, startTime: 1, endTime: 1 }
+  )
+    .sort({ startTime: 1 })
+    .lean<
+      Array<{ _id: string; location: string; startTime: Date; endTime: Date }>
+    >();
+
+  const locationIds = Array.from(new Set(schedules.map(s => s.location)));
+  const locations = await GamingLocations.find(
+    { _id: { $in: locationIds }, ...NOT_DELETED_FILTER },
+    { _id: 1, name: 1, address: 1, geoCoords: 1 }
+  ).lean<RouteLocation[]>();
+  const locationById = new Map(
+    locations.map(location => [String(location._id), location])
+  );
+
+  const due = new Map<string, DueLocation>();
+  schedules.forEach(schedule => {
+    const location = locationById.get(schedule.location);
+    if (!location) return;
+    const entry = due.get(schedule.location);
+    if (entry) {
+      entry.schedulerIds.push(String(schedule._id));
+      if (schedule.endTime > entry.windowEnd) {
+        entry.windowEnd = schedule.endTime;
+      }
+      return;
+    }
+    due.set(schedule.location, {
+      location,
+      coordinates: getLocationCoordinates(location),
+      windowStart: schedule.startTime,
+      windowEnd: schedule.endTime,
+      schedulerIds: [String(schedule._id)],
+    });
+  });
+
+  // Step 2: Order the locations with coordinates
+  const dueLocations = Array.from(due.values());
+  const routable = dueLocations.filter(entry => entry.coordinates);
+  const unroutable = dueLocations.filter(entry => !entry.coordinates);
+  const points = routable.map(entry => entry.coordinates as Coordinates);
+  const start = params.start ?? points[0] ?? null;
+  const order = start ? orderByDistance(start, points) : [];
+
+  // Step 3: Stops with leg distances
+  const toStop = (
+    entry: DueLocation,
+    stop: number | null,
+    legKm: number | null,
+    cumulativeKm: number | null
+  ): CollectionRouteStop => ({
+    stop,
+    locationId: String(entry.location._id),
+    locationName: entry.location.name || String(entry.location._id),
+    address: [entry.location.address?.street, entry.location.address?.city]
+      .filter(Boolean)
+      .join(', '),
+    latitude: entry.coordinates?.latitude ?? null,
+    longitude: entry.coordinates?.longitude ?? null,
+    windowStart: entry.windowStart,
+    windowEnd: entry.windowEnd,
+    schedulerIds: entry.schedulerIds,
+    legKm,
+    cumulativeKm,
+  });
+
+  let totalKm = 0;
+  let previous = start;
+  const stops = order.map((pointIndex, index) => {
+    const legKm = previous ? distanceKm(previous, points[pointIndex]) : 0;
+    totalKm += legKm;
+    previous = points[pointIndex];
+    return toStop(
+      routable[pointIndex],
+      index + 1,
+      Math.round(legKm * 100) / 100,
+      Math.round(totalKm * 100) / 100
+    );
+  });
+  unroutable.forEach(entry => stops.push(toStop(entry, null, null, null)));
+
+  return {
+    collectorId: collector._id,
+    collectorName: collector.name,
+    day: params.day,
+    start,
+    stops,
+    totalKm: Math.round(totalKm * 100) / 100,
+    unrouted: unroutable.length,
+  };
+}
+
+// ============================================================================
+// Export
+// ============================================================================
+
+function csvCell(value: unknown): string {
+  const text = value === null || value === undefined ? '' : String(value);
+  return /[",\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+}
+
+/**
+ * One CSV row per stop, in visit order; unrouted locations last with an
+ * empty stop number.
+ */
+export function exportCollectionRouteToCSV(route: CollectionRoute): string {
+  const header = [
+    'Stop',
+    'Location ID',
+    'Location',
+    'Address',
+    'Latitude',
+    'Longitude',
+    'Window Start',
+    'Window End',
+    'Leg (km)',
+    'Cumulative (km)',
+    'Schedule IDs',
+  ];
+  const lines = [header.join(',')];
+  route.stops.forEach(stop =>
+    lines.push(
+      [
+        stop.stop,
+        stop.locationId,
+        stop.locationName,
+        stop.address,
+        stop.latitude,
+        stop.longitude,
+        stop.windowStart.toISOString(),
+        stop.windowEnd.toISOString(),
+        stop.legKm,
+        stop.cumulativeKm,
+        stop.schedulerIds.join(' '),
+      ]
+        .map(csvCell)
+        .join(',')
+    )
+  );
+  return lines.join('\n');
+}
+
+/**
+ * GeoJSON FeatureCollection: a Point per routed stop and a LineString of
+ * the route (from the start point). Unrouted locations have no geometry and
+ * are left out; `properties.unrouted` counts them.
+ */
+export function exportCollectionRouteToGeoJSON(
+  route: CollectionRoute
+): Record<string, unknown> {
+  const routed = route.stops.filter(stop => stop.stop !== null);
+  const path = [
+    ...(route.start ? [[route.start.longitude, route.start.latitude]] : []),
+    ...routed.map(stop => [stop.longitude, stop.latitude]),
+  ];
+  return {
+    type: 'FeatureCollection',
+    properties: {
+      collectorId: route.collectorId,
+      collectorName: route.collectorName,
+      day: route.day,
+      totalKm: route.totalKm,
+      unrouted: route.unrouted,
+    },
+    features: [
+      ...routed.map(stop => ({
+        type: 'Feature',
+        geometry: {
+          type: 'Point',
+          coordinates: [stop.longitude, stop.latitude],
+        },
+        properties: {
+          stop: stop.stop,
+          locationId: stop.locationId,
+          locationName: stop.locationName,
+          address: stop.address,
+          windowStart: stop.windowStart.toISOString(),
+          windowEnd: stop.windowEnd.toISOString(),
+          legKm: stop.legKm,
+          cumulativeKm: stop.cumulativeKm,
+          schedulerIds: stop.schedulerIds,
+        },
+      })),
+      ...(path.length > 1
+        ? [
+            {
+              type: 'Feature',
+              geometry: { type: 'LineString', coordinates: path },
+              properties: { kind: 'route', totalKm: route.totalKm },
+            },
+          ]
+        : []),
+    ],
+  };
+}
+
+/**
+ * Formats a route for the terminal.
+ */
+export function formatCollectionRoute(route: CollectionRoute): string {
+  const routed = route.stops.length - route.unrouted;
+  const lines = [
+    `Route for ${route.collectorName} on ${route.day}: ${routed} stop(s), ${route.totalKm} km${route.start ? ` from ${route.start.latitude},${route.start.longitude}` : ''}`,
+  ];
+  route.stops.forEach(stop => {
+    const window = `${stop.windowStart.toISOString().slice(11, 16)}-${stop.windowEnd.toISOString().slice(11, 16)}`;
+    lines.push(
+      stop.stop === null
+        ? `   -  ${stop.locationName} (${window}) — no coordinates, not routed`
+        : `  ${String(stop.stop).padStart(2)}. ${stop.locationName} (${window}) +${stop.legKm} km = ${stop.cumulativeKm} km`
+    );
+  });
+  if (route.stops.length === 0) lines.push('  No pending schedules.');
+  return lines.join('\n');
+}
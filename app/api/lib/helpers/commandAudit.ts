@@ -0,0 +1,186 @@
+/**
+ * Command Audit Helper
+ *
+ * Records every `scripts/` command run — who ran it, on which host, with
+ * which parameters, against which database profile, how long it took, how
+ * many rows it touched and whether it succeeded — in `commandAuditLogs`, and
+ * optionally as a JSON line in an append-only file (`COMMAND_AUDIT_FILE`).
+ *
+ * Auditing never fails the command: write errors are reported as warnings.
+ *
+ * @module app/api/lib/helpers/commandAudit
+ */
+
+import { CommandAuditLog } from '@/app/api/lib/models/commandAuditLog';
+import { redactMongoUri } from '@/app/api/lib/utils/dbProfiles';
+import { generateMongoId } from '@/lib/utils/id';
+import type { CommandAuditLogDocument } from '@shared/types';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import type { Connection } from 'mongoose';
+import os from 'os';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type CommandAuditOutcome = {
+  success: boolean;
+  exitCode?: number;
+  error?: unknown;
+};
+
+export type CommandAudit = {
+  /** Database profile (or redacted URI) the command ran against */
+  setTarget: (target: string) => void;
+  /** Adds to the number of rows read or written */
+  addRows: (count: number) => void;
+  /**
+   * Writes the audit record. Uses `connection` when given, otherwise the
+   * default mongoose connection if it is still open.
+   */
+  finish: (
+    outcome: CommandAuditOutcome,
+    connection?: Connection
+  ) => Promise<void>;
+};
+
+const SENSITIVE_FLAG_PATTERN = /secret|token|password|webhook|uri|key/i;
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Redacts credentials from command arguments: connection URIs are stripped of
+ * user info and values of secret-looking flags are masked.
+ */
+export function redactCommandArgs(args: string[]): string[] {
+  return args.map((arg, index) => {
+    const equals = arg.indexOf('=');
+    if (
+      arg.startsWith('--') &&
+      equals !== -1 &&
+      SENSITIVE_FLAG_PATTERN.test(arg.slice(0, equals))
+    ) {
+      return `${arg.slice(0, equals)}=***`;
+    }
+    const previous = args[index - 1];
+    if (
+      previous?.startsWith('--') &&
+      !previous.includes('=') &&
+      !arg.startsWith('--') &&
+      SENSITIVE_FLAG_PATTERN.test(previous)
+    ) {
+      return '***';
+    }
+    return redactMongoUri(arg);
+  });
+}
+
+/**
+ * Operator running the command: `AUDIT_USER`, else the OS user.
+ */
+export function getOperator(): string {
+  if (process.env.AUDIT_USER) return process.env.AUDIT_USER;
+  try {
+    return os.userInfo().username;
+  } catch {
+    return process.env.USER || 'unknown';
+  }
+}
+
+// ============================================================================
+// Audit
+// ============================================================================
+
+/**
+ * Starts auditing a command run. Call `finish` once, before the process
+ * exits.
+ *
+ * @param command - Command name (package.json script name)
+ * @param args - Command arguments (default: `process.argv.slice(2)`)
+ */
+export function startCommandAudit(
+  command: string,
+  args: string[] = process.argv.slice(2)
+): CommandAudit {
+  const startedAt = new Date();
+  let target: string | null = null;
+  let rowCount = 0;
+  let finished = false;
+
+  return {
+    setTarget: value => {
+      target = value;
+    },
+    addRows: count => {
+      rowCount += count;
+    },
+    finish: async (outcome, connection) => {
+      if (finished) return;
+      finished = true;
+
+      const record: Omit<CommandAuditLogDocument, 'createdAt' | 'updatedAt'> =
+        {
+          _id: await generateMongoId(),
+          timestamp: startedAt,
+          username: getOperator(),
+          host: os.hostname(),
+          pid: process.pid,
+          command,
+          parameters: redactCommandArgs(args),
+          target,
+          durationMs: Date.now() - startedAt.getTime(),
+          rowCount,
+          success: outcome.success,
+          exitCode: outcome.exitCode ?? null,
+          error:
+            outcome.error === undefined
+              ? null
+              : outcome.error instanceof Error
+                ? outcome.error.message
+                : String(outcome.error),
+        };
+
+      // Step 1: Append-only file
+      const auditFile = process.env.COMMAND_AUDIT_FILE;
+      if (auditFile) {
+        try {
+          fs.appendFileSync(auditFile, `${JSON.stringify(record)}\n`, {
+            flag: 'a',
+          });
+        } catch (fileError) {
+          console.warn(
+            `[commandAudit] Could not write ${auditFile}:`,
+            fileError instanceof Error ? fileError.message : fileError
+          );
+        }
+      }
+
+      // Step 2: Database
+      try {
+        if (connection) {
+          await connection
+            .collection(CommandAuditLog.collection.name)
+            .insertOne({
+              ...record,
+              createdAt: new Date(),
+              updatedAt: new Date(),
+            } as never);
+        } else if (mongoose.connection.readyState === 1) {
+          await CommandAuditLog.create(record);
+        } else {
+          console.warn(
+            '[commandAudit] No open database connection; audit record not stored'
+          );
+        }
+      } catch (dbError) {
+        console.warn(
+          '[commandAudit] Could not store audit record:',
+          dbError instanceof Error ? dbError.message : dbError
+        );
+      }
+    },
+  };
+}
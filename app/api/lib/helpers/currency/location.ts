@@ -7,6 +7,7 @@
 
 import { Countries } from '@/app/api/lib/models/countries';
 import { Licencee } from '@/app/api/lib/models/licencee';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import {
   convertFromUSD,
@@ -51,12 +52,7 @@ export async function convertLocationCurrency(
 
   // Get currency mappings
   const licenceesData = await Licencee.find(
-    {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    },
+    { ...NOT_DELETED_FILTER },
     { _id: 1, name: 1 }
   ).lean<LicenceeDocument[]>();
 
@@ -8,6 +8,7 @@
 import { Countries } from '@/app/api/lib/models/countries';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import {
   convertFromUSD,
   convertToUSD,
@@ -67,12 +68,7 @@ export async function convertTopPerformingCurrency(
 
   // Get currency mappings
   const licenceesData = await Licencee.find(
-    {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    },
+    { ...NOT_DELETED_FILTER },
     { _id: 1, name: 1 }
   ).lean<LicenceeDocument[]>();
 
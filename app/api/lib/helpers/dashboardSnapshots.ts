@@ -0,0 +1,251 @@
+/**
+ * Dashboard Snapshots Helper
+ *
+ * The dashboard's global stats (`getDashboardAnalytics`) are computed on the
+ * fly and not kept, so there is no history to chart. `takeDashboardSnapshots()`
+ * stores them per licencee into `dashboardSnapshots`, once per hour and once
+ * per day (the day bucket holds the last snapshot taken that day), and
+ * `getDashboardSnapshotTrend()` reads the series back so "gross trend over the
+ * last 90 days" is a small indexed read instead of a meters aggregation.
+ *
+ * Snapshots are taken by `POST /api/admin/dashboard-snapshots` or the
+ * `dashboard-snapshots` command, run hourly by an external scheduler.
+ *
+ * @module app/api/lib/helpers/dashboardSnapshots
+ */
+
+import { getDashboardAnalytics } from '@/app/api/lib/helpers/reports/analytics';
+import type { DashboardAnalyticsResult } from '@/app/api/lib/helpers/reports/analytics';
+import { DashboardSnapshot } from '@/app/api/lib/models/dashboardSnapshot';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import type {
+  DashboardSnapshotDocument,
+  DashboardSnapshotGranularity,
+} from '@/shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type DashboardSnapshotRun = {
+  takenAt: Date;
+  licencees: number;
+  written: number;
+  pruned: number;
+  failed: Array<{ licencee: string; error: string }>;
+};
+
+export type DashboardSnapshotField = keyof DashboardAnalyticsResult;
+
+export type DashboardTrendPoint = {
+  bucket: Date;
+  takenAt: Date;
+} & DashboardAnalyticsResult;
+
+export type DashboardTrend = {
+  licencee: string;
+  granularity: DashboardSnapshotGranularity;
+  from: Date;
+  to: Date;
+  points: DashboardTrendPoint[];
+};
+
+export const SNAPSHOT_FIELDS: DashboardSnapshotField[] = [
+  'totalDrop',
+  'totalCancelledCredits',
+  'totalGross',
+  'totalMachines',
+  'onlineMachines',
+  'sasMachines',
+];
+
+/** Hourly snapshots older than this are removed; daily ones are kept */
+export const HOURLY_SNAPSHOT_RETENTION_DAYS = 30;
+export const MAX_TREND_DAYS = 366;
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Start of the UTC hour or day containing `date`.
+ */
+export function getSnapshotBucket(
+  date: Date,
+  granularity: DashboardSnapshotGranularity
+): Date {
+  const bucket = new Date(date);
+  bucket.setUTCMinutes(0, 0, 0);
+  if (granularity === 'day') bucket.setUTCHours(0);
+  return bucket;
+}
+
+// ============================================================================
+// Snapshot Job
+// ============================================================================
+
+/**
+ * Stores the current dashboard stats of every active licencee (or the given
+ * ones) under this hour's and this day's bucket, then prunes old hourly
+ * snapshots. Re-running within the same hour overwrites that hour's snapshot.
+ * A licencee whose aggregation fails is reported and skipped.
+ *
+ * @param licenceeIds - Limit the run to these licencees (default: all active)
+ * @returns What was written
+ */
+export async function takeDashboardSnapshots(
+  licenceeIds?: string[]
+): Promise<DashboardSnapshotRun> {
+  assertWritable('dashboard snapshots');
+  const takenAt = new Date();
+
+  const licencees = await Licencee.find(
+    {
+      ...NOT_DELETED_FILTER,
+      ...(licenceeIds?.length ? { _id: { $in: licenceeIds } } : {}),
+    },
+    { _id: 1 }
+  ).lean<Array<{ _id: string }>>();
+
+  const failed: DashboardSnapshotRun['failed'] = [];
+  let written = 0;
+  for (const licencee of licencees) {
+    const licenceeId = String(licencee._id);
+    try {
+      const stats = await getDashboardAnalytics(licenceeId);
+      const values = Object.fromEntries(
+        SNAPSHOT_FIELDS.map(field => [field, Number(stats[field]) || 0])
+      );
+      const granularities: DashboardSnapshotGranularity[] = ['hour', 'day'];
+      const result = await DashboardSnapshot.bulkWrite(
+        granularities.map(granularity => {
+          const bucket = getSnapshotBucket(takenAt, granularity);
+          return {
+            updateOne: {
+              filter: {
+                _id: `${licenceeId}:${granularity}:${bucket.toISOString()}`,
+              },
+              update: {
+                $set: { ...values, takenAt },
+                $setOnInsert: { licencee: licenceeId, granularity, bucket },
+              },
+              upsert: true,
+            },
+          };
+        })
+      );
+      written += result.upsertedCount + result.modifiedCount;
+    } catch (error) {
+      failed.push({
+        licencee: licenceeId,
+        error: error instanceof Error ? error.message : 'Unknown error',
+      });
+    }
+  }
+
+  const pruneBefore = new Date(
+    takenAt.getTime() - HOURLY_SNAPSHOT_RETENTION_DAYS * 24 * 60 * 60 * 1000
+  );
+  const pruned = await DashboardSnapshot.deleteMany({
+    granularity: 'hour',
+    bucket: { $lt: pruneBefore },
+  });
+
+  return {
+    takenAt,
+    licencees: licencees.length,
+    written,
+    pruned: pruned.deletedCount,
+    failed,
+  };
+}
+
+// ============================================================================
+// Trend Report
+// ============================================================================
+
+/**
+ * Reads a licencee's snapshot series for the last `days` days, oldest first.
+ *
+ * @param licencee - Licencee ID
+ * @param options - Granularity (default 'day') and window (default 90 days)
+ * @returns The points in the window
+ */
+export async function getDashboardSnapshotTrend(
+  licencee: string,
+  options: { granularity?: DashboardSnapshotGranularity; days?: number } = {}
+): Promise<DashboardTrend> {
+  const granularity = options.granularity ?? 'day';
+  const days = Math.min(Math.max(options.days ?? 90, 1), MAX_TREND_DAYS);
+  const to = new Date();
+  const from = getSnapshotBucket(
+    new Date(to.getTime() - days * 24 * 60 * 60 * 1000),
+    granularity
+  );
+
+  const snapshots = await DashboardSnapshot.find(
+    { licencee, granularity, bucket: { $gte: from } },
+    { _id: 0, licencee: 0, granularity: 0, createdAt: 0, updatedAt: 0 }
+  )
+    .sort({ bucket: 1 })
+    .lean<DashboardSnapshotDocument[]>();
+
+  return {
+    licencee,
+    granularity,
+    from,
+    to,
+    points: snapshots.map(snapshot => ({
+      bucket: snapshot.bucket,
+      takenAt: snapshot.takenAt,
+      totalDrop: snapshot.totalDrop,
+      totalCancelledCredits: snapshot.totalCancelledCredits,
+      totalGross: snapshot.totalGross,
+      totalMachines: snapshot.totalMachines,
+      onlineMachines: snapshot.onlineMachines,
+      sasMachines: snapshot.sasMachines,
+    })),
+  };
+}
+
+/**
+ * Text chart of one field of a trend, one bar per point, for the command.
+ *
+ * @param trend - Series from `getDashboardSnapshotTrend()`
+ * @param field - Field to chart (default totalGross)
+ * @param width - Width of the longest bar
+ */
+export function formatDashboardTrendChart(
+  trend: DashboardTrend,
+  field: DashboardSnapshotField = 'totalGross',
+  width = 50
+): string {
+  if (trend.points.length === 0) {
+    return `No ${trend.granularity} snapshots for ${trend.licencee} since ${trend.from.toISOString().slice(0, 10)}`;
+  }
+  const values = trend.points.map(point => point[field]);
+  const largest = Math.max(...values.map(value => Math.abs(value)), 1);
+  const first = values[0];
+  const last = values[values.length - 1];
+  const change = last - first;
+
+  const lines = trend.points.map(point => {
+    const value = point[field];
+    const bar = '█'.repeat(Math.round((Math.abs(value) / largest) * width));
+    const label =
+      trend.granularity === 'day'
+        ? point.bucket.toISOString().slice(0, 10)
+        : point.bucket.toISOString().slice(0, 13).replace('T', ' ');
+    return `${label}  ${value.toFixed(2).padStart(14)}  ${value < 0 ? '-' : ''}${bar}`;
+  });
+
+  return [
+    `${field} for ${trend.licencee}, ${trend.points.length} ${trend.granularity} snapshots`,
+    ...lines,
+    `Change: ${change >= 0 ? '+' : ''}${change.toFixed(2)}${
+      first !== 0 ? ` (${((change / Math.abs(first)) * 100).toFixed(1)}%)` : ''
+    }  min ${Math.min(...values).toFixed(2)}  max ${Math.max(...values).toFixed(2)}`,
+  ].join('\n');
+}
@@ -0,0 +1,423 @@
+/**
+ * Data Export Helper
+ *
+ * Writes collections to newline-delimited JSON (one file per collection plus
+ * a `manifest.json`) for sharing datasets outside the platform, scoped to a
+ * licencee's or a location's data and optionally to records since a date.
+ *
+ * With `anonymize`, members and locations are pseudonymized for vendors:
+ * member IDs, usernames, names, emails and card IDs, and location names, are
+ * replaced by salted HMAC-SHA256 pseudonyms. The same salt always gives the
+ * same pseudonym, so references stay intact across the exported collections
+ * (a session's `memberId` still matches its member's `_id`) and across runs.
+ * Contact details, addresses, identification, map coordinates and raw SMIB
+ * payloads on events are cleared. Location IDs are kept. SMIB network and
+ * MQTT passwords are never exported.
+ *
+ * `outDir` may be an `s3://bucket/prefix`, in which case each file is
+ * streamed to object storage (see objectStorage) instead of the local disk.
+ *
+ * @module app/api/lib/helpers/dataExport
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { MachineEvent } from '@/app/api/lib/models/machineEvents';
+import { Machine } from '@/app/api/lib/models/machines';
+import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import { Member } from '@/app/api/lib/models/members';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  createObjectUpload,
+  isObjectStorageUrl,
+  joinStoragePath,
+  writeObject,
+} from '@/app/api/lib/utils/objectStorage';
+import { createHmac } from 'crypto';
+import fs from 'fs';
+import type { Model } from 'mongoose';
+import type { Writable } from 'stream';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ExportCollection =
+  | 'gaminglocations'
+  | 'machines'
+  | 'members'
+  | 'machinesessions'
+  | 'meters'
+  | 'machineevents';
+
+export const EXPORT_COLLECTIONS: ExportCollection[] = [
+  'gaminglocations',
+  'machines',
+  'members',
+  'machinesessions',
+  'meters',
+  'machineevents',
+];
+
+export type DataExportOptions = {
+  /** Directory (created when missing) or `s3://bucket/prefix` to write into */
+  outDir: string;
+  collections?: ExportCollection[];
+  licenceeId?: string;
+  locationId?: string;
+  /** Only time-series records (sessions, meters, events) from this date */
+  since?: Date;
+  anonymize?: boolean;
+  /** Required with `anonymize` */
+  salt?: string;
+};
+
+export type DataExportResult = {
+  outDir: string;
+  anonymized: boolean;
+  locations: number;
+  counts: Partial<Record<ExportCollection, number>>;
+};
+
+type ExportDocument = Record<string, unknown>;
+
+export type Pseudonymizer = {
+  memberId: (value: string) => string;
+  username: (value: string) => string;
+  /** Surname replacement; first names become 'Member' */
+  name: (value: string) => string;
+  email: (value: string) => string;
+  cardId: (value: string) => string;
+  locationName: (value: string) => string;
+};
+
+// Collections whose model types differ; only find/cursor are used here
+type ExportModel = Model<ExportDocument>;
+
+const COLLECTION_MODELS: Record<ExportCollection, ExportModel> = {
+  gaminglocations: GamingLocations,
+  machines: Machine,
+  members: Member,
+  machinesessions: MachineSession,
+  meters: Meters,
+  machineevents: MachineEvent,
+};
+
+// ============================================================================
+// Pseudonymization
+// ============================================================================
+
+/**
+ * Deterministic pseudonyms for a salt. Each kind of value is hashed under
+ * its own label, so a member ID and a username never share a pseudonym.
+ */
+export function createPseudonymizer(salt: string): Pseudonymizer {
+  const hash = (kind: string, value: string, length: number) =>
+    createHmac('sha256', salt)
+      .update(`${kind}:${value}`)
+      .digest('hex')
+      .slice(0, length);
+
+  return {
+    memberId: value => (value ? hash('member', value, 24) : value),
+    username: value => (value ? `member_${hash('username', value, 10)}` : ''),
+    name: value => hash('name', value, 8),
+    email: value =>
+      value ? `${hash('email', value.toLowerCase(), 16)}@example.invalid` : '',
+    cardId: value => (value ? hash('card', value, 16) : value),
+    locationName: value =>
+      value ? `Location ${hash('location', value, 8)}` : '',
+  };
+}
+
+function anonymizeMember(doc: ExportDocument, p: Pseudonymizer) {
+  const profile = (doc.profile || {}) as Record<string, unknown>;
+  const identification = (profile.indentification || {}) as Record<
+    string,
+    unknown
+  >;
+  const fullName = `${profile.firstName || ''} ${profile.lastName || ''}`;
+  return {
+    ...doc,
+    _id: p.memberId(String(doc._id)),
+    memberId: p.memberId(String(doc.memberId || '')),
+    username: p.username(String(doc.username || '')),
+    ucardId: p.cardId(String(doc.ucardId || '')),
+    phoneNumber: '',
+    areaCode: '',
+    pin: undefined,
+    smsCode: undefined,
+    user: undefined,
+    profile: {
+      ...profile,
+      firstName: 'Member',
+      lastName: p.name(fullName.trim() || String(doc._id)),
+      email: p.email(String(profile.email || '')),
+      dob: '',
+      address: '',
+      occupation: '',
+      indentification: { ...identification, number: '' },
+    },
+  };
+}
+
+function anonymizeLocation(doc: ExportDocument, p: Pseudonymizer) {
+  return {
+    ...doc,
+    name: p.locationName(String(doc.name || '')),
+    address: {},
+    geoCoords: undefined,
+    googleMapsLink: undefined,
+    googleMapsIframe: undefined,
+  };
+}
+
+/**
+ * Raw SMIB payloads can carry card data, so they are dropped.
+ */
+function anonymizeEvent(doc: ExportDocument) {
+  const message = doc.message as
+    | { incomingMessage?: Record<string, unknown> }
+    | undefined;
+  const sequence = (doc.sequence || []) as Array<{
+    message?: Record<string, unknown>;
+  }>;
+  return {
+    ...doc,
+    message: message && {
+      ...message,
+      incomingMessage: message.incomingMessage && {
+        ...message.incomingMessage,
+        pyd: undefined,
+      },
+    },
+    sequence: sequence.map(step => ({
+      ...step,
+      message: step.message && { ...step.message, pyd: undefined },
+    })),
+  };
+}
+
+function anonymizeSession(doc: ExportDocument, p: Pseudonymizer) {
+  return {
+    ...doc,
+    memberId: p.memberId(String(doc.memberId || '')),
+    ucardId: p.cardId(String(doc.ucardId || '')),
+    user: undefined,
+  };
+}
+
+/**
+ * Removes SMIB credentials from a machine, whatever the export mode.
+ */
+function withoutSmibCredentials(doc: ExportDocument): ExportDocument {
+  const smibConfig = doc.smibConfig as
+    | { mqtt?: Record<string, unknown>; net?: Record<string, unknown> }
+    | undefined;
+  if (!smibConfig) return doc;
+  return {
+    ...doc,
+    smibConfig: {
+      ...smibConfig,
+      mqtt: smibConfig.mqtt && { ...smibConfig.mqtt, mqttPassword: undefined },
+      net: smibConfig.net && { ...smibConfig.net, netStaPwd: undefined },
+    },
+  };
+}
+
+/**
+ * Applies the export rules for a collection to one document.
+ *
+ * @param pseudonymizer - Set when the export is anonymized
+ */
+export function prepareExportDocument(
+  collection: ExportCollection,
+  doc: ExportDocument,
+  pseudonymizer: Pseudonymizer | null
+): ExportDocument {
+  if (collection === 'machines') return withoutSmibCredentials(doc);
+  if (!pseudonymizer) return doc;
+  switch (collection) {
+    case 'members':
+      return anonymizeMember(doc, pseudonymizer);
+    case 'gaminglocations':
+      return anonymizeLocation(doc, pseudonymizer);
+    case 'machinesessions':
+      return anonymizeSession(doc, pseudonymizer);
+    case 'machineevents':
+      return anonymizeEvent(doc);
+    default:
+      return doc;
+  }
+}
+
+// ============================================================================
+// Export
+// ============================================================================
+
+/**
+ * Query per collection for the locations in scope.
+ */
+function buildQuery(
+  collection: ExportCollection,
+  locationIds: string[] | null,
+  machineIds: string[] | null,
+  since: Date | undefined
+): Record<string, unknown> {
+  const inLocations = locationIds ? { $in: locationIds } : undefined;
+  switch (collection) {
+    case 'gaminglocations':
+      return inLocations ? { _id: inLocations } : {};
+    case 'machines':
+      return inLocations ? { gamingLocation: inLocations } : {};
+    case 'members':
+      return inLocations ? { gamingLocation: inLocations } : {};
+    case 'machinesessions':
+      return {
+        ...(machineIds ? { machineId: { $in: machineIds } } : {}),
+        ...(since ? { startTime: { $gte: since } } : {}),
+      };
+    case 'meters':
+      return {
+        ...(inLocations ? { location: inLocations } : {}),
+        ...(since ? { readAt: { $gte: since } } : {}),
+      };
+    case 'machineevents':
+      return {
+        ...(inLocations ? { location: inLocations } : {}),
+        ...(since ? { date: { $gte: since } } : {}),
+      };
+  }
+}
+
+/**
+ * Opens one export file for writing, on disk or in object storage.
+ */
+async function openExportFile(
+  outDir: string,
+  name: string
+): Promise<{ stream: Writable; close: () => Promise<void> }> {
+  const target = joinStoragePath(outDir, name);
+  if (isObjectStorageUrl(target)) {
+    const upload = await createObjectUpload(target, 'application/x-ndjson');
+    return {
+      stream: upload.stream,
+      close: async () => {
+        upload.stream.end();
+        await upload.done;
+      },
+    };
+  }
+  const stream = fs.createWriteStream(target);
+  return {
+    stream,
+    close: () =>
+      new Promise<void>((resolve, reject) =>
+        stream.end((error?: Error | null) =>
+          error ? reject(error) : resolve()
+        )
+      ),
+  };
+}
+
+/**
+ * Exports the selected collections to `outDir`.
+ *
+ * @throws Error when `anonymize` is set without a salt, or the licencee or
+ * location does not exist
+ */
+export async function exportCollections(
+  options: DataExportOptions
+): Promise<DataExportResult> {
+  const collections = options.collections?.length
+    ? options.collections
+    : EXPORT_COLLECTIONS;
+  if (options.anonymize && !options.salt) {
+    throw new Error('Anonymized exports need a salt');
+  }
+  const pseudonymizer = options.anonymize
+    ? createPseudonymizer(options.salt as string)
+    : null;
+
+  // Step 1: Resolve the locations and machines in scope
+  let locationIds: string[] | null = null;
+  if (options.locationId) {
+    const exists = await GamingLocations.exists({ _id: options.locationId });
+    if (!exists) throw new Error(`Location ${options.locationId} not found`);
+    locationIds = [options.locationId];
+  } else if (options.licenceeId) {
+    const exists = await Licencee.exists({ _id: options.licenceeId });
+    if (!exists) throw new Error(`Licencee ${options.licenceeId} not found`);
+    const locations = await GamingLocations.find(
+      { 'rel.licencee': options.licenceeId },
+      { _id: 1 }
+    ).lean<Array<{ _id: string }>>();
+    locationIds = locations.map(location => String(location._id));
+  }
+
+  let machineIds: string[] | null = null;
+  if (locationIds && collections.includes('machinesessions')) {
+    const machines = await Machine.find(
+      { gamingLocation: { $in: locationIds } },
+      { _id: 1 }
+    ).lean<Array<{ _id: string }>>();
+    machineIds = machines.map(machine => String(machine._id));
+  }
+
+  // Step 2: Stream each collection to NDJSON
+  if (!isObjectStorageUrl(options.outDir)) {
+    fs.mkdirSync(options.outDir, { recursive: true });
+  }
+  const counts: DataExportResult['counts'] = {};
+  for (const collection of collections) {
+    const { stream, close } = await openExportFile(
+      options.outDir,
+      `${collection}.ndjson`
+    );
+    let count = 0;
+    const cursor = COLLECTION_MODELS[collection]
+      .find(buildQuery(collection, locationIds, machineIds, options.since))
+      .lean<ExportDocument>()
+      .cursor({ batchSize: 1000 });
+    for await (const doc of cursor) {
+      const prepared = prepareExportDocument(
+        collection,
+        doc as ExportDocument,
+        pseudonymizer
+      );
+      if (!stream.write(`${JSON.stringify(prepared)}\n`)) {
+        await new Promise(resolve => stream.once('drain', resolve));
+      }
+      count++;
+    }
+    await close();
+    counts[collection] = count;
+  }
+
+  // Step 3: Manifest
+  const result: DataExportResult = {
+    outDir: options.outDir,
+    anonymized: Boolean(options.anonymize),
+    locations: locationIds ? locationIds.length : 0,
+    counts,
+  };
+  const manifest = JSON.stringify(
+    {
+      exportedAt: new Date().toISOString(),
+      anonymized: result.anonymized,
+      licencee: options.licenceeId || null,
+      location: options.locationId || null,
+      since: options.since?.toISOString() || null,
+      counts,
+    },
+    null,
+    2
+  );
+  const manifestPath = joinStoragePath(options.outDir, 'manifest.json');
+  if (isObjectStorageUrl(manifestPath)) {
+    await writeObject(manifestPath, manifest, 'application/json');
+  } else {
+    fs.writeFileSync(manifestPath, manifest);
+  }
+  return result;
+}
@@ -0,0 +1,741 @@
+/**
+ * Data Integrity Checks
+ *
+ * Configurable checks over production data, used by the `integrity` command
+ * (scripts/check-data-integrity.ts) for nightly monitoring:
+ * - `machinesWithoutLocation` — active machines with no `gamingLocation`
+ * - `invalidLocationRefs` — active machines pointing at a missing or archived location
+ * - `negativeMeters` — meter readings with a negative movement value
+ * - `meterOutliers` — meter readings whose drop or cancelled credits are more
+ *   than N standard deviations from the machine's trailing 30-day readings;
+ *   findings are stored in `integrityIssues` for review
+ * - `meterUnits` — machines whose handle or drop over the lookback window is
+ *   far off their location peers' (by `unitRatio` or more), which suggests
+ *   they report in cents or credits under the wrong `meterUnit` (see
+ *   utils/meterUnits); findings are stored in `integrityIssues` with a
+ *   suggested unit
+ *
+ * Each check fails when its count exceeds the configured threshold. Every run
+ * is stored in `integrityRuns` and compared with the previous ones (new,
+ * resolved and chronic findings per check, see integrityTrends).
+ *
+ * @module app/api/lib/helpers/dataIntegrity
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import {
+  MAX_TRACKED_ISSUE_KEYS,
+  recordIntegrityRun,
+} from '@/app/api/lib/helpers/integrityTrends';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
+import type { IntegrityTrend } from '@/app/api/lib/helpers/integrityTrends';
+import { IntegrityIssue } from '@/app/api/lib/models/integrityIssue';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import { METER_MOVEMENT_FIELDS } from '@/app/api/lib/utils/financialFormulas';
+import {
+  getMeterUnitFactors,
+  nearestDenomination,
+} from '@/app/api/lib/utils/meterUnits';
+import { isReadOnlyMode } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { generateMongoId } from '@/lib/utils/id';
+import type { PipelineStage } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type IntegrityCheckName =
+  | 'machinesWithoutLocation'
+  | 'invalidLocationRefs'
+  | 'negativeMeters'
+  | 'meterOutliers'
+  | 'meterUnits';
+
+export type IntegrityCheckResult = {
+  name: IntegrityCheckName;
+  count: number;
+  threshold: number;
+  passed: boolean;
+  sample: string[];
+};
+
+export type IntegrityReport = {
+  checkedAt: Date;
+  passed: boolean;
+  checks: IntegrityCheckResult[];
+  /** Comparison with earlier runs; absent when run tracking is off */
+  trend?: IntegrityTrend;
+};
+
+export type IntegrityOptions = {
+  checks: IntegrityCheckName[];
+  thresholds: Partial<Record<IntegrityCheckName, number>>;
+  meterLookbackDays: number;
+  sampleSize: number;
+  /** Standard deviations from the trailing mean that count as an outlier */
+  outlierStdDevs: number;
+  /** Trailing readings a machine needs before it is checked for outliers */
+  outlierMinSamples: number;
+  /** How many times above or below its peers a machine must be to be flagged */
+  unitRatio: number;
+  /** Other machines at the location needed for the meter unit check */
+  unitMinPeers: number;
+  /** Store the run in `integrityRuns` and compare it with earlier runs */
+  trackRuns: boolean;
+  /** Consecutive runs a finding must appear in to be reported as chronic */
+  chronicRuns: number;
+};
+
+export const INTEGRITY_CHECK_NAMES: IntegrityCheckName[] = [
+  'machinesWithoutLocation',
+  'invalidLocationRefs',
+  'negativeMeters',
+  'meterOutliers',
+  'meterUnits',
+];
+
+export const DEFAULT_INTEGRITY_OPTIONS: IntegrityOptions = {
+  checks: INTEGRITY_CHECK_NAMES,
+  thresholds: {},
+  meterLookbackDays: 7,
+  sampleSize: 20,
+  outlierStdDevs: 4,
+  outlierMinSamples: 20,
+  unitRatio: 20,
+  unitMinPeers: 3,
+  trackRuns: true,
+  chronicRuns: 3,
+};
+
+/** Movement fields checked for outliers */
+const OUTLIER_FIELDS = ['drop', 'totalCancelledCredits'] as const;
+const OUTLIER_TRAILING_DAYS = 30;
+
+const ACTIVE_FILTER = { ...NOT_DELETED_FILTER };
+
+/** `keys` identify each finding (up to MAX_TRACKED_ISSUE_KEYS) across runs */
+type CheckOutcome = { count: number; sample: string[]; keys: string[] };
+
+// ============================================================================
+// Checks
+// ============================================================================
+
+async function checkMachinesWithoutLocation(
+  options: IntegrityOptions
+): Promise<CheckOutcome> {
+  const query = {
+    ...ACTIVE_FILTER,
+    ...NOT_RETIRED_FILTER,
+    $and: [
+      {
+        $or: [
+          { gamingLocation: { $exists: false } },
+          { gamingLocation: null },
+          { gamingLocation: '' },
+        ],
+      },
+    ],
+  };
+  const [count, machines] = await Promise.all([
+    Machine.countDocuments(query),
+    Machine.find(query, { _id: 1 })
+      .limit(MAX_TRACKED_ISSUE_KEYS)
+      .lean<Array<{ _id: string }>>(),
+  ]);
+  const keys = machines.map(machine => String(machine._id));
+  return { count, sample: keys.slice(0, options.sampleSize), keys };
+}
+
+async function checkInvalidLocationRefs(
+  options: IntegrityOptions
+): Promise<CheckOutcome> {
+  const referenced = await Machine.aggregate<{
+    _id: string;
+    machines: string[];
+    count: number;
+  }>([
+    {
+      $match: {
+        ...ACTIVE_FILTER,
+        ...NOT_RETIRED_FILTER,
+        gamingLocation: { $nin: [null, ''] },
+      },
+    },
+    {
+      $group: {
+        _id: '$gamingLocation',
+        count: { $sum: 1 },
+        machines: { $push: '$_id' },
+      },
+    },
+  ]);
+  if (referenced.length === 0) return { count: 0, sample: [], keys: [] };
+
+  const activeLocations = await GamingLocations.find(
+    { _id: { $in: referenced.map(ref => ref._id) }, ...ACTIVE_FILTER },
+    { _id: 1 }
+  ).lean<Array<{ _id: string }>>();
+  const activeIds = new Set(activeLocations.map(location => String(location._id)));
+
+  const invalid = referenced.filter(ref => !activeIds.has(String(ref._id)));
+  return {
+    count: invalid.reduce((sum, ref) => sum + ref.count, 0),
+    sample: invalid
+      .flatMap(ref =>
+        ref.machines.map(machineId => `${machineId} -> ${ref._id}`)
+      )
+      .slice(0, options.sampleSize),
+    keys: invalid
+      .flatMap(ref => ref.machines.map(machineId => String(machineId)))
+      .slice(0, MAX_TRACKED_ISSUE_KEYS),
+  };
+}
+
+async function checkNegativeMeters(
+  options: IntegrityOptions
+): Promise<CheckOutcome> {
+  const query = {
+    readAt: {
+      $gte: new Date(Date.now() - options.meterLookbackDays * 86400000),
+    },
+    $or: METER_MOVEMENT_FIELDS.map(field => ({
+      [`movement.${field}`]: { $lt: 0 },
+    })),
+  };
+  const [count, meters] = await Promise.all([
+    Meters.countDocuments(query),
+    Meters.find(query, { _id: 1, machine: 1 })
+      .limit(MAX_TRACKED_ISSUE_KEYS)
+      .lean<Array<{ _id: string; machine?: string }>>(),
+  ]);
+  return {
+    count,
+    sample: meters
+      .slice(0, options.sampleSize)
+      .map(meter => `${meter._id} (machine ${meter.machine})`),
+    keys: meters.map(meter => String(meter._id)),
+  };
+}
+
+type MeterOutlier = {
+  _id: string;
+  machine: string;
+  location?: string;
+  readAt: Date;
+  field: (typeof OUTLIER_FIELDS)[number];
+  value: number;
+  mean: number;
+  stdDev: number;
+  zScore: number;
+  sampleSize: number;
+};
+
+/**
+ * Builds the outlier pipeline. Each reading is compared with the machine's
+ * readings over the previous 30 days; the window sums include the reading
+ * itself, so it is subtracted out before the mean and deviation are taken.
+ */
+function buildMeterOutlierPipeline(
+  lookbackStart: Date,
+  options: IntegrityOptions
+): PipelineStage[] {
+  const trailingStart = new Date(
+    lookbackStart.getTime() - OUTLIER_TRAILING_DAYS * 86400000
+  );
+  const window = {
+    range: [-OUTLIER_TRAILING_DAYS, 0],
+    unit: 'day',
+  };
+
+  const windowOutput: Record<string, unknown> = {
+    windowCount: { $count: {}, window },
+  };
+  const statFields: Record<string, unknown> = {};
+  OUTLIER_FIELDS.forEach(field => {
+    windowOutput[`${field}Sum`] = { $sum: `$${field}`, window };
+    windowOutput[`${field}SumSq`] = {
+      $sum: { $multiply: [`$${field}`, `$${field}`] },
+      window,
+    };
+    // Trailing mean and population deviation excluding this reading
+    statFields[`${field}Mean`] = {
+      $divide: [
+        { $subtract: [`$${field}Sum`, `$${field}`] },
+        '$sampleSize',
+      ],
+    };
+  });
+
+  const deviationFields: Record<string, unknown> = {};
+  OUTLIER_FIELDS.forEach(field => {
+    deviationFields[`${field}StdDev`] = {
+      $sqrt: {
+        $max: [
+          0,
+          {
+            $subtract: [
+              {
+                $divide: [
+                  {
+                    $subtract: [
+                      `$${field}SumSq`,
+                      { $multiply: [`$${field}`, `$${field}`] },
+                    ],
+                  },
+                  '$sampleSize',
+                ],
+              },
+              { $multiply: [`$${field}Mean`, `$${field}Mean`] },
+            ],
+          },
+        ],
+      },
+    };
+  });
+
+  return [
+    {
+      $match: {
+        readAt: { $gte: trailingStart },
+        ...NOT_DELETED_FILTER,
+      },
+    },
+    {
+      $project: {
+        machine: 1,
+        location: 1,
+        readAt: 1,
+        ...Object.fromEntries(
+          OUTLIER_FIELDS.map(field => [
+            field,
+            { $ifNull: [`$movement.${field}`, 0] },
+          ])
+        ),
+      },
+    },
+    {
+      $setWindowFields: {
+        partitionBy: '$machine',
+        sortBy: { readAt: 1 },
+        output: windowOutput,
+      },
+    } as PipelineStage,
+    { $match: { readAt: { $gte: lookbackStart } } },
+    { $addFields: { sampleSize: { $subtract: ['$windowCount', 1] } } },
+    { $match: { sampleSize: { $gte: options.outlierMinSamples } } },
+    { $addFields: statFields },
+    { $addFields: deviationFields },
+    // One row per flagged field; zero deviation (constant history) is skipped
+    {
+      $project: {
+        machine: 1,
+        location: 1,
+        readAt: 1,
+        sampleSize: 1,
+        candidates: OUTLIER_FIELDS.map(field => ({
+          field,
+          value: `$${field}`,
+          mean: `$${field}Mean`,
+          stdDev: `$${field}StdDev`,
+          zScore: {
+            $cond: [
+              { $gt: [`$${field}StdDev`, 0] },
+              {
+                $divide: [
+                  { $subtract: [`$${field}`, `$${field}Mean`] },
+                  `$${field}StdDev`,
+                ],
+              },
+              0,
+            ],
+          },
+        })),
+      },
+    },
+    { $unwind: '$candidates' },
+    {
+      $match: {
+        $expr: {
+          $gt: [{ $abs: '$candidates.zScore' }, options.outlierStdDevs],
+        },
+      },
+    },
+    {
+      $project: {
+        machine: 1,
+        location: 1,
+        readAt: 1,
+        sampleSize: 1,
+        field: '$candidates.field',
+        value: '$candidates.value',
+        mean: '$candidates.mean',
+        stdDev: '$candidates.stdDev',
+        zScore: '$candidates.zScore',
+      },
+    },
+  ];
+}
+
+/**
+ * Stores outliers in `integrityIssues`. Existing findings keep their review
+ * status; only the statistics are refreshed. Skipped in read-only mode.
+ */
+async function recordMeterOutliers(outliers: MeterOutlier[]): Promise<void> {
+  if (outliers.length === 0) return;
+  if (isReadOnlyMode()) {
+    console.warn(
+      '[dataIntegrity] Read-only mode is on; meter outliers not recorded'
+    );
+    return;
+  }
+
+  const round = (value: number) => Math.round(value * 100) / 100;
+  const operations = await Promise.all(
+    outliers.map(async outlier => ({
+      updateOne: {
+        filter: {
+          check: 'meterOutliers',
+          resourceId: String(outlier._id),
+          field: outlier.field,
+        },
+        update: {
+          $set: {
+            resourceType: 'meter',
+            machine: outlier.machine,
+            location: outlier.location,
+            value: outlier.value,
+            mean: round(outlier.mean),
+            stdDev: round(outlier.stdDev),
+            zScore: round(outlier.zScore),
+            sampleSize: outlier.sampleSize,
+            readAt: outlier.readAt,
+            details: `${outlier.field} ${outlier.value} is ${round(
+              Math.abs(outlier.zScore)
+            )} standard deviations from the 30-day mean ${round(outlier.mean)}`,
+          },
+          $setOnInsert: {
+            _id: await generateMongoId(),
+            status: 'open',
+            detectedAt: new Date(),
+            reviewedBy: null,
+            reviewedAt: null,
+          },
+        },
+        upsert: true,
+      },
+    }))
+  );
+  await IntegrityIssue.bulkWrite(operations, { ordered: false });
+}
+
+async function checkMeterOutliers(
+  options: IntegrityOptions
+): Promise<CheckOutcome> {
+  const lookbackStart = new Date(
+    Date.now() - options.meterLookbackDays * 86400000
+  );
+  const outliers = await Meters.aggregate<MeterOutlier>(
+    buildMeterOutlierPipeline(lookbackStart, options)
+  ).option({ allowDiskUse: true });
+
+  await recordMeterOutliers(outliers);
+
+  return {
+    count: outliers.length,
+    sample: outliers
+      .slice(0, options.sampleSize)
+      .map(
+        outlier =>
+          `${outlier._id} (machine ${outlier.machine}) ${outlier.field}=${outlier.value} z=${outlier.zScore.toFixed(1)}`
+      ),
+    keys: outliers
+      .slice(0, MAX_TRACKED_ISSUE_KEYS)
+      .map(outlier => `${outlier._id}:${outlier.field}`),
+  };
+}
+
+/** Movement fields compared with location peers for the meter unit check */
+const UNIT_FIELDS = ['coinIn', 'drop'] as const;
+
+type MachineMagnitude = {
+  _id: string;
+  location: string;
+} & Record<(typeof UNIT_FIELDS)[number], number>;
+
+type MeterUnitSuspect = {
+  machine: string;
+  location: string;
+  /** Machine total divided by the peer median (geometric mean over fields) */
+  ratio: number;
+  peerMedian: number;
+  peers: number;
+  currentFactor: number;
+  suggestion: string;
+};
+
+function median(values: number[]): number {
+  const sorted = [...values].sort((a, b) => a - b);
+  const middle = Math.floor(sorted.length / 2);
+  return sorted.length % 2
+    ? sorted[middle]
+    : (sorted[middle - 1] + sorted[middle]) / 2;
+}
+
+/**
+ * Suggests the unit that would bring a machine in line with its peers, given
+ * the factor currently applied to it.
+ */
+function suggestMeterUnit(currentFactor: number, ratio: number): string {
+  const wanted = currentFactor / ratio;
+  if (Math.abs(Math.log10(wanted / 0.01)) < 0.3) return 'cents';
+  const denomination = nearestDenomination(wanted);
+  return denomination === 1
+    ? 'dollars'
+    : `credits (accountingDenomination ${denomination})`;
+}
+
+/**
+ * Compares each machine's handle and drop over the lookback window (already
+ * converted by the meters aggregation hook) with the median of the other
+ * machines at its location. A machine is a suspect when every field with
+ * peer data is off by `unitRatio` or more in the same direction.
+ */
+async function findMeterUnitSuspects(
+  options: IntegrityOptions
+): Promise<MeterUnitSuspect[]> {
+  const lookbackStart = new Date(
+    Date.now() - options.meterLookbackDays * 86400000
+  );
+  const [magnitudes, factors] = await Promise.all([
+    Meters.aggregate<MachineMagnitude>([
+      { $match: { readAt: { $gte: lookbackStart } } },
+      {
+        $group: {
+          _id: '$machine',
+          location: { $last: '$location' },
+          ...Object.fromEntries(
+            UNIT_FIELDS.map(field => [
+              field,
+              { $sum: { $ifNull: [`$movement.${field}`, 0] } },
+            ])
+          ),
+        },
+      },
+    ]).option({ allowDiskUse: true }),
+    getMeterUnitFactors(),
+  ]);
+
+  const byLocation = new Map<string, MachineMagnitude[]>();
+  magnitudes.forEach(machine => {
+    const list = byLocation.get(String(machine.location)) ?? [];
+    list.push(machine);
+    byLocation.set(String(machine.location), list);
+  });
+
+  const suspects: MeterUnitSuspect[] = [];
+  byLocation.forEach(machines => {
+    machines.forEach(machine => {
+      const ratios: number[] = [];
+      let peerMedian = 0;
+      let peers = 0;
+      UNIT_FIELDS.forEach(field => {
+        if (machine[field] <= 0) return;
+        const peerValues = machines
+          .filter(peer => peer._id !== machine._id && peer[field] > 0)
+          .map(peer => peer[field]);
+        if (peerValues.length < options.unitMinPeers) return;
+        const fieldMedian = median(peerValues);
+        ratios.push(machine[field] / fieldMedian);
+        if (!peerMedian) {
+          peerMedian = fieldMedian;
+          peers = peerValues.length;
+        }
+      });
+      if (ratios.length === 0) return;
+
+      const tooHigh = ratios.every(ratio => ratio >= options.unitRatio);
+      const tooLow = ratios.every(ratio => ratio <= 1 / options.unitRatio);
+      if (!tooHigh && !tooLow) return;
+
+      const ratio = Math.exp(
+        ratios.reduce((sum, value) => sum + Math.log(value), 0) /
+          ratios.length
+      );
+      const currentFactor = factors.get(String(machine._id)) ?? 1;
+      suspects.push({
+        machine: String(machine._id),
+        location: String(machine.location),
+        ratio,
+        peerMedian,
+        peers,
+        currentFactor,
+        suggestion: suggestMeterUnit(currentFactor, ratio),
+      });
+    });
+  });
+
+  return suspects.sort(
+    (a, b) => Math.abs(Math.log(b.ratio)) - Math.abs(Math.log(a.ratio))
+  );
+}
+
+/**
+ * Stores meter unit suspects in `integrityIssues`, one per machine. Existing
+ * findings keep their review status. Skipped in read-only mode.
+ */
+async function recordMeterUnitSuspects(
+  suspects: MeterUnitSuspect[]
+): Promise<void> {
+  if (suspects.length === 0) return;
+  if (isReadOnlyMode()) {
+    console.warn(
+      '[dataIntegrity] Read-only mode is on; meter unit suspects not recorded'
+    );
+    return;
+  }
+
+  const round = (value: number) => Math.round(value * 100) / 100;
+  const operations = await Promise.all(
+    suspects.map(async suspect => ({
+      updateOne: {
+        filter: {
+          check: 'meterUnits',
+          resourceId: suspect.machine,
+          field: 'meterUnit',
+        },
+        update: {
+          $set: {
+            resourceType: 'machine',
+            machine: suspect.machine,
+            location: suspect.location,
+            value: round(suspect.ratio),
+            mean: round(suspect.peerMedian),
+            sampleSize: suspect.peers,
+            details: `Readings are ${round(suspect.ratio)}x the median of ${suspect.peers} machines at the location (current factor ${suspect.currentFactor}); suggested meterUnit: ${suspect.suggestion}`,
+          },
+          $setOnInsert: {
+            _id: await generateMongoId(),
+            status: 'open',
+            detectedAt: new Date(),
+            reviewedBy: null,
+            reviewedAt: null,
+          },
+        },
+        upsert: true,
+      },
+    }))
+  );
+  await IntegrityIssue.bulkWrite(operations, { ordered: false });
+}
+
+async function checkMeterUnits(
+  options: IntegrityOptions
+): Promise<CheckOutcome> {
+  const suspects = await findMeterUnitSuspects(options);
+  await recordMeterUnitSuspects(suspects);
+
+  return {
+    count: suspects.length,
+    sample: suspects
+      .slice(0, options.sampleSize)
+      .map(
+        suspect =>
+          `${suspect.machine} (location ${suspect.location}) ${suspect.ratio.toFixed(2)}x peers -> ${suspect.suggestion}`
+      ),
+    keys: suspects
+      .slice(0, MAX_TRACKED_ISSUE_KEYS)
+      .map(suspect => suspect.machine),
+  };
+}
+
+const CHECKS: Record<
+  IntegrityCheckName,
+  (options: IntegrityOptions) => Promise<CheckOutcome>
+> = {
+  machinesWithoutLocation: checkMachinesWithoutLocation,
+  invalidLocationRefs: checkInvalidLocationRefs,
+  negativeMeters: checkNegativeMeters,
+  meterOutliers: checkMeterOutliers,
+  meterUnits: checkMeterUnits,
+};
+
+// ============================================================================
+// Runner
+// ============================================================================
+
+/**
+ * Runs the selected integrity checks. Assumes a database connection is open.
+ * With `trackRuns`, the run is compared with earlier ones and stored.
+ *
+ * @param options - Checks to run, per-check thresholds and lookback
+ * @returns Report; `passed` is false when any check exceeds its threshold
+ */
+export async function runIntegrityChecks(
+  options: IntegrityOptions = DEFAULT_INTEGRITY_OPTIONS
+): Promise<IntegrityReport> {
+  const checks: IntegrityCheckResult[] = [];
+  const keys: Partial<Record<IntegrityCheckName, string[]>> = {};
+  for (const name of options.checks) {
+    const outcome = await CHECKS[name](options);
+    keys[name] = outcome.keys;
+    const threshold = options.thresholds[name] ?? 0;
+    checks.push({
+      name,
+      count: outcome.count,
+      threshold,
+      passed: outcome.count <= threshold,
+      sample: outcome.sample,
+    });
+  }
+
+  const report: IntegrityReport = {
+    checkedAt: new Date(),
+    passed: checks.every(check => check.passed),
+    checks,
+  };
+  if (options.trackRuns) {
+    report.trend = await recordIntegrityRun(report, keys, {
+      chronicRuns: options.chronicRuns,
+      sampleSize: options.sampleSize,
+    });
+  }
+
+  return report;
+}
+
+/**
+ * Formats a one-line-per-check summary of a report.
+ */
+export function formatIntegritySummary(report: IntegrityReport): string {
+  const lines = report.checks.map(
+    check =>
+      `${check.passed ? 'PASS' : 'FAIL'} ${check.name}: ${check.count} (threshold ${check.threshold})`
+  );
+  return [
+    `Data integrity ${report.passed ? 'passed' : 'FAILED'} at ${report.checkedAt.toISOString()}`,
+    ...lines,
+  ].join('\n');
+}
+
+/**
+ * Posts a report summary to a webhook. The payload carries a `text` summary
+ * (Slack/Teams compatible) plus the full report.
+ *
+ * @param url - Webhook URL
+ * @param report - Integrity report
+ */
+export async function postIntegrityWebhook(
+  url: string,
+  report: IntegrityReport
+): Promise<void> {
+  const response = await fetch(url, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ text: formatIntegritySummary(report), report }),
+  });
+  if (!response.ok) {
+    throw new Error(`Webhook responded with HTTP ${response.status}`);
+  }
+}
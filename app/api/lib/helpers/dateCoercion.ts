@@ -0,0 +1,257 @@
+/**
+ * Date Coercion Helper
+ *
+ * Converts dates stored as strings (e.g. `readAt: "2026-03-01T04:00:00Z"`)
+ * to BSON dates. String dates never match `$gte` / `$lte` range filters on
+ * dates, so those documents silently drop out of reports.
+ *
+ * Only strings `$dateFromString` can parse are converted (strings without an
+ * offset are read as UTC); unparseable strings are counted and left alone.
+ * Every converted value is first copied (id, field, old string) to
+ * `dateCoercionBackups` under the run id so a run can be reverted with
+ * `restoreDateCoercionBackup()`.
+ *
+ * Used by the `coerce-dates` command (scripts/coerce-dates.ts).
+ *
+ * @module app/api/lib/helpers/dateCoercion
+ */
+
+import type { Connection } from 'mongoose';
+import {
+  copyToBackupStore,
+  groupBackupsByCollection,
+} from '@/app/api/lib/helpers/writeBackups';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type DateCoercionField = {
+  collection: string;
+  /** Dotted path into the document */
+  field: string;
+};
+
+export type DateCoercionFieldReport = DateCoercionField & {
+  /** String values that parse as dates */
+  convertible: number;
+  /** String values that do not parse; left as they are */
+  unparseable: number;
+  /** Values converted (0 on a dry run) */
+  updated: number;
+};
+
+export type DateCoercionReport = {
+  runId: string;
+  dryRun: boolean;
+  startedAt: Date;
+  finishedAt: Date;
+  fields: DateCoercionFieldReport[];
+  /** Values that needed (or got) a conversion */
+  total: number;
+};
+
+/** Date fields range filters run on */
+export const DEFAULT_DATE_COERCION_FIELDS: DateCoercionField[] = [
+  { collection: 'meters', field: 'readAt' },
+  { collection: 'meters', field: 'createdAt' },
+  { collection: 'machineevents', field: 'date' },
+  { collection: 'machinesessions', field: 'startTime' },
+  { collection: 'machinesessions', field: 'endTime' },
+  { collection: 'collections', field: 'timestamp' },
+  { collection: 'collections', field: 'collectionTime' },
+];
+
+export const DATE_COERCION_BACKUP_COLLECTION = 'dateCoercionBackups';
+
+type BackupDocument = {
+  runId: string;
+  collection: string;
+  field: string;
+  documentId: unknown;
+  value: string;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Parses `collection:field` into a field spec.
+ *
+ * @throws Error when the field is missing
+ */
+export function parseDateCoercionField(spec: string): DateCoercionField {
+  const [collection, field] = spec.split(':').map(part => part.trim());
+  if (!collection || !field) {
+    throw new Error(`Expected collection:field, got '${spec}'`);
+  }
+  return { collection, field };
+}
+
+function parsedDate(field: string) {
+  return {
+    $dateFromString: {
+      dateString: `$${field}`,
+      onError: null,
+      onNull: null,
+    },
+  };
+}
+
+/** String values of the field that parse as dates */
+function convertibleFilter(field: string) {
+  return {
+    [field]: { $type: 'string' },
+    $expr: { $ne: [parsedDate(field), null] },
+  };
+}
+
+async function countStrings(
+  connection: Connection,
+  target: DateCoercionField
+): Promise<Pick<DateCoercionFieldReport, 'convertible' | 'unparseable'>> {
+  const collection = connection.collection(target.collection);
+  const [strings, convertible] = await Promise.all([
+    collection.countDocuments({ [target.field]: { $type: 'string' } }),
+    collection.countDocuments(convertibleFilter(target.field)),
+  ]);
+  return { convertible, unparseable: strings - convertible };
+}
+
+/**
+ * Copies the id and string value of every document about to be converted
+ * into the backup collection, server side.
+ */
+async function backupField(
+  connection: Connection,
+  target: DateCoercionField,
+  runId: string
+): Promise<void> {
+  await copyToBackupStore(
+    connection,
+    {
+      collection: target.collection,
+      filter: convertibleFilter(target.field),
+      into: DATE_COERCION_BACKUP_COLLECTION,
+      runId,
+      key: { field: target.field },
+    },
+    { value: `$${target.field}` }
+  );
+}
+
+// ============================================================================
+// Coercion
+// ============================================================================
+
+/**
+ * Counts string dates per field and, unless `dryRun`, backs up and converts
+ * the parseable ones to BSON dates.
+ *
+ * @param connection - Database connection
+ * @param options - Run id, dry run and fields (default: the known date fields)
+ * @returns Counts per field and what was changed
+ */
+export async function coerceStringDates(
+  connection: Connection,
+  options: { runId: string; dryRun: boolean; fields?: DateCoercionField[] }
+): Promise<DateCoercionReport> {
+  if (!options.dryRun) assertWritable('converting string dates');
+
+  const startedAt = new Date();
+  const fields = options.fields ?? DEFAULT_DATE_COERCION_FIELDS;
+  const results: DateCoercionFieldReport[] = [];
+
+  for (const target of fields) {
+    const counts = await countStrings(connection, target);
+    let updated = 0;
+
+    if (!options.dryRun && counts.convertible > 0) {
+      await backupField(connection, target, options.runId);
+      const result = await connection
+        .collection(target.collection)
+        .updateMany(convertibleFilter(target.field), [
+          { $set: { [target.field]: parsedDate(target.field) } },
+        ]);
+      updated = result.modifiedCount;
+    }
+
+    results.push({ ...target, ...counts, updated });
+  }
+
+  return {
+    runId: options.runId,
+    dryRun: options.dryRun,
+    startedAt,
+    finishedAt: new Date(),
+    fields: results,
+    total: results.reduce(
+      (sum, result) =>
+        sum + (options.dryRun ? result.convertible : result.updated),
+      0
+    ),
+  };
+}
+
+/**
+ * Puts back the string values a run backed up, where the field still holds
+ * the converted date.
+ *
+ * @param connection - Database connection
+ * @param runId - Run to revert
+ * @returns Values restored
+ */
+export async function restoreDateCoercionBackup(
+  connection: Connection,
+  runId: string
+): Promise<number> {
+  assertWritable('restoring a date coercion backup');
+
+  const backups = await connection
+    .collection<BackupDocument>(DATE_COERCION_BACKUP_COLLECTION)
+    .find({ runId })
+    .toArray();
+
+  let restored = 0;
+  for (const [collection, entries] of groupBackupsByCollection(backups)) {
+    const result = await connection.collection(collection).bulkWrite(
+      entries.map(entry => ({
+        updateOne: {
+          filter: {
+            _id: entry.documentId as never,
+            [entry.field]: { $type: 'date' },
+          },
+          update: { $set: { [entry.field]: entry.value } },
+        },
+      })),
+      { ordered: false }
+    );
+    restored += result.modifiedCount;
+  }
+  return restored;
+}
+
+/**
+ * One line per field with string values.
+ */
+export function formatDateCoercionReport(report: DateCoercionReport): string {
+  const width = Math.max(
+    ...report.fields.map(
+      result => `${result.collection}.${result.field}`.length
+    ),
+    0
+  );
+  const lines = report.fields
+    .filter(result => result.convertible + result.unparseable > 0)
+    .map(
+      result =>
+        `${`${result.collection}.${result.field}`.padEnd(width)}  strings=${
+          result.convertible + result.unparseable
+        } convertible=${result.convertible} unparseable=${result.unparseable}${
+          report.dryRun ? '' : `  updated=${result.updated}`
+        }`
+    );
+  return lines.length > 0 ? lines.join('\n') : 'No string dates found';
+}
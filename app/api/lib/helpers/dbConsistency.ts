@@ -0,0 +1,218 @@
+/**
+ * Cross-Database Consistency Helper
+ *
+ * Compares recent writes between two MongoDB instances (e.g. the old and new
+ * cluster while both are live after a migration). For each collection, the
+ * documents written on the source within a window are looked up on the
+ * destination by `_id` and compared field by field.
+ *
+ * Used by the `consistency` command (scripts/check-db-consistency.ts).
+ *
+ * @module app/api/lib/helpers/dbConsistency
+ */
+
+import { normalizeId } from '@/app/api/lib/utils/mongoIds';
+import type { Connection } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ConsistencyTarget = {
+  collection: string;
+  timeField: string;
+};
+
+export type CollectionConsistency = {
+  collection: string;
+  timeField: string;
+  sourceCount: number;
+  destinationCount: number;
+  missingOnDestination: number;
+  missingOnSource: number;
+  mismatched: number;
+  sample: string[];
+  consistent: boolean;
+};
+
+export type ConsistencyReport = {
+  checkedAt: Date;
+  windowStart: Date;
+  windowEnd: Date;
+  consistent: boolean;
+  collections: CollectionConsistency[];
+};
+
+export type ConsistencyOptions = {
+  windowMinutes: number;
+  settleSeconds: number;
+  sampleSize: number;
+  maxDocuments: number;
+};
+
+/**
+ * Time field used to find recent writes when none is given.
+ */
+export const DEFAULT_TIME_FIELDS: Record<string, string> = {
+  meters: 'readAt',
+  machineevents: 'date',
+  machinesessions: 'startTime',
+};
+
+export const DEFAULT_CONSISTENCY_OPTIONS: ConsistencyOptions = {
+  windowMinutes: 15,
+  settleSeconds: 30,
+  sampleSize: 10,
+  maxDocuments: 50000,
+};
+
+type RawDocument = Record<string, unknown> & { _id: unknown };
+
+// ============================================================================
+// Comparison
+// ============================================================================
+
+/**
+ * Serializes a value with sorted keys so field order does not affect equality.
+ */
+export function stableStringify(value: unknown): string {
+  if (value === null || typeof value !== 'object') return JSON.stringify(value);
+  if (value instanceof Date) return JSON.stringify(value.toISOString());
+  if (Array.isArray(value)) return `[${value.map(stableStringify).join(',')}]`;
+  if (typeof (value as { toHexString?: unknown }).toHexString === 'function') {
+    return JSON.stringify(String(value));
+  }
+  const entries = Object.keys(value as Record<string, unknown>)
+    .sort()
+    .map(
+      key =>
+        `${JSON.stringify(key)}:${stableStringify((value as Record<string, unknown>)[key])}`
+    );
+  return `{${entries.join(',')}}`;
+}
+
+/**
+ * Parses `collection` or `collection:timeField` into a target.
+ */
+export function parseConsistencyTarget(spec: string): ConsistencyTarget {
+  const [collection, timeField] = spec.split(':').map(part => part.trim());
+  return {
+    collection,
+    timeField: timeField || DEFAULT_TIME_FIELDS[collection] || 'updatedAt',
+  };
+}
+
+async function fetchWindow(
+  connection: Connection,
+  target: ConsistencyTarget,
+  windowStart: Date,
+  windowEnd: Date,
+  maxDocuments: number
+): Promise<Map<string, RawDocument>> {
+  const documents = await connection
+    .collection(target.collection)
+    .find({ [target.timeField]: { $gte: windowStart, $lt: windowEnd } })
+    .limit(maxDocuments + 1)
+    .toArray();
+  if (documents.length > maxDocuments) {
+    throw new Error(
+      `${target.collection}: more than ${maxDocuments} documents in the window; narrow --window-minutes`
+    );
+  }
+  // Key by normalized id so a string `_id` on one side matches an ObjectId
+  return new Map(
+    documents.map(document => [
+      normalizeId(document._id) ?? String(document._id),
+      document as RawDocument,
+    ])
+  );
+}
+
+// ============================================================================
+// Runner
+// ============================================================================
+
+/**
+ * Compares recent writes on each target collection between two connections.
+ * The newest `settleSeconds` are skipped so in-flight replication or dual
+ * writes are not reported as divergence.
+ *
+ * @param source - Source connection
+ * @param destination - Destination connection
+ * @param targets - Collections and the time field that marks a write
+ * @param options - Window, settle time and sampling
+ * @returns Per-collection divergence report
+ */
+export async function compareRecentWrites(
+  source: Connection,
+  destination: Connection,
+  targets: ConsistencyTarget[],
+  options: ConsistencyOptions = DEFAULT_CONSISTENCY_OPTIONS
+): Promise<ConsistencyReport> {
+  const checkedAt = new Date();
+  const windowEnd = new Date(checkedAt.getTime() - options.settleSeconds * 1000);
+  const windowStart = new Date(
+    windowEnd.getTime() - options.windowMinutes * 60000
+  );
+
+  const collections: CollectionConsistency[] = [];
+  for (const target of targets) {
+    const [sourceDocs, destinationDocs] = await Promise.all([
+      fetchWindow(source, target, windowStart, windowEnd, options.maxDocuments),
+      fetchWindow(
+        destination,
+        target,
+        windowStart,
+        windowEnd,
+        options.maxDocuments
+      ),
+    ]);
+
+    const sample: string[] = [];
+    const addSample = (entry: string) => {
+      if (sample.length < options.sampleSize) sample.push(entry);
+    };
+
+    let missingOnDestination = 0;
+    let mismatched = 0;
+    sourceDocs.forEach((document, id) => {
+      const counterpart = destinationDocs.get(id);
+      if (!counterpart) {
+        missingOnDestination++;
+        addSample(`missing on destination: ${id}`);
+      } else if (stableStringify(document) !== stableStringify(counterpart)) {
+        mismatched++;
+        addSample(`mismatch: ${id}`);
+      }
+    });
+
+    let missingOnSource = 0;
+    destinationDocs.forEach((_document, id) => {
+      if (!sourceDocs.has(id)) {
+        missingOnSource++;
+        addSample(`missing on source: ${id}`);
+      }
+    });
+
+    collections.push({
+      collection: target.collection,
+      timeField: target.timeField,
+      sourceCount: sourceDocs.size,
+      destinationCount: destinationDocs.size,
+      missingOnDestination,
+      missingOnSource,
+      mismatched,
+      sample,
+      consistent:
+        missingOnDestination === 0 && missingOnSource === 0 && mismatched === 0,
+    });
+  }
+
+  return {
+    checkedAt,
+    windowStart,
+    windowEnd,
+    consistent: collections.every(collection => collection.consistent),
+    collections,
+  };
+}
@@ -0,0 +1,291 @@
+/**
+ * deletedAt Normalization Helper
+ *
+ * One-time rewrite of the "not deleted" shapes of `deletedAt` to `null`:
+ *
+ * - the legacy `new Date(-1)` sentinel and any other date before 2025
+ * - numeric sentinels (`NumberLong(-1)`)
+ * - a missing field
+ *
+ * Queries go through the filters in softDeleteFilters, which treat these
+ * shapes as not deleted too, so a deploy never depends on this having run;
+ * the rewrite only makes the stored data consistent. Every rewritten document
+ * is first copied (id, old value) to `deletedAtBackups` under the run id so a
+ * run can be reverted with `restoreDeletedAtBackup()`.
+ *
+ * Used by the `normalize-deleted-at` command (scripts/normalize-deleted-at.ts).
+ *
+ * @module app/api/lib/helpers/deletedAtNormalization
+ */
+
+import type { Connection } from 'mongoose';
+import {
+  copyToBackupStore,
+  groupBackupsByCollection,
+} from '@/app/api/lib/helpers/writeBackups';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { DELETED_AT_CUTOFF } from '@/app/api/lib/utils/softDeleteFilters';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type DeletedAtCollectionReport = {
+  collection: string;
+  /** Dates before the cutoff, including `new Date(-1)` */
+  dateSentinels: number;
+  /** Numeric values such as `NumberLong(-1)` */
+  numericSentinels: number;
+  missing: number;
+  /** Documents rewritten to null (0 on a dry run) */
+  updated: number;
+};
+
+export type DeletedAtNormalizationReport = {
+  runId: string;
+  dryRun: boolean;
+  startedAt: Date;
+  finishedAt: Date;
+  collections: DeletedAtCollectionReport[];
+  /** Documents that needed (or got) a rewrite */
+  total: number;
+  /** Whether the active location name index was rebuilt */
+  locationIndexRebuilt: boolean;
+};
+
+/** Collections whose schema has a top-level `deletedAt` */
+export const DELETED_AT_COLLECTIONS = [
+  'activityLogs',
+  'cashdeskpayouts',
+  'collectionreports',
+  'collections',
+  'countries',
+  'firmwares',
+  'floatrequests',
+  'gaminglocations',
+  'licencees',
+  'machines',
+  'members',
+  'meters',
+  'movementrequests',
+  'reportedmachines',
+  'schedulers',
+  'shifts',
+  'users',
+  'vaultnotifications',
+];
+
+export const DELETED_AT_BACKUP_COLLECTION = 'deletedAtBackups';
+
+const LOCATION_NAME_INDEX = 'unique_active_location_name';
+
+const DATE_SENTINEL_FILTER = {
+  deletedAt: { $type: 'date', $lt: DELETED_AT_CUTOFF },
+};
+const NUMERIC_SENTINEL_FILTER = { deletedAt: { $type: 'number' } };
+const MISSING_FILTER = { deletedAt: { $exists: false } };
+
+/** Every document whose `deletedAt` should become null */
+export const LEGACY_DELETED_AT_FILTER = {
+  $or: [DATE_SENTINEL_FILTER, NUMERIC_SENTINEL_FILTER, MISSING_FILTER],
+};
+
+type BackupDocument = {
+  runId: string;
+  collection: string;
+  documentId: unknown;
+  hadField: boolean;
+  deletedAt?: unknown;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+async function countShapes(
+  connection: Connection,
+  collection: string
+): Promise<Omit<DeletedAtCollectionReport, 'updated'>> {
+  const target = connection.collection(collection);
+  const [dateSentinels, numericSentinels, missing] = await Promise.all([
+    target.countDocuments(DATE_SENTINEL_FILTER),
+    target.countDocuments(NUMERIC_SENTINEL_FILTER),
+    target.countDocuments(MISSING_FILTER),
+  ]);
+  return { collection, dateSentinels, numericSentinels, missing };
+}
+
+/**
+ * Copies the id and current `deletedAt` of every document about to be
+ * rewritten into the backup collection, server side.
+ */
+async function backupCollection(
+  connection: Connection,
+  collection: string,
+  runId: string
+): Promise<void> {
+  await copyToBackupStore(
+    connection,
+    {
+      collection,
+      filter: LEGACY_DELETED_AT_FILTER,
+      into: DELETED_AT_BACKUP_COLLECTION,
+      runId,
+    },
+    {
+      hadField: { $ne: [{ $type: '$deletedAt' }, 'missing'] },
+      deletedAt: 1,
+    }
+  );
+}
+
+/**
+ * Recreates the unique location name index so it covers `deletedAt: null`
+ * instead of the legacy sentinel range.
+ *
+ * @returns Whether the index had to be rebuilt
+ */
+async function rebuildLocationNameIndex(
+  connection: Connection
+): Promise<boolean> {
+  const locations = connection.collection('gaminglocations');
+  const indexes = await locations.indexes();
+  const existing = indexes.find(index => index.name === LOCATION_NAME_INDEX);
+  const wanted = { deletedAt: { $type: 'null' } };
+  if (
+    existing &&
+    JSON.stringify(existing.partialFilterExpression) === JSON.stringify(wanted)
+  ) {
+    return false;
+  }
+
+  if (existing) await locations.dropIndex(LOCATION_NAME_INDEX);
+  await locations.createIndex(
+    { name: 1 },
+    { name: LOCATION_NAME_INDEX, unique: true, partialFilterExpression: wanted }
+  );
+  return true;
+}
+
+// ============================================================================
+// Normalization
+// ============================================================================
+
+/**
+ * Counts the legacy `deletedAt` shapes per collection and, unless `dryRun`,
+ * backs up and rewrites them to null.
+ *
+ * @param connection - Database connection
+ * @param options - Run id, dry run and collections (default: all known)
+ * @returns Counts per collection and what was changed
+ */
+export async function normalizeDeletedAt(
+  connection: Connection,
+  options: { runId: string; dryRun: boolean; collections?: string[] }
+): Promise<DeletedAtNormalizationReport> {
+  if (!options.dryRun) assertWritable('normalizing deletedAt');
+
+  const startedAt = new Date();
+  const collections = options.collections ?? DELETED_AT_COLLECTIONS;
+  const results: DeletedAtCollectionReport[] = [];
+
+  for (const collection of collections) {
+    const shapes = await countShapes(connection, collection);
+    const pending =
+      shapes.dateSentinels + shapes.numericSentinels + shapes.missing;
+    let updated = 0;
+
+    if (!options.dryRun && pending > 0) {
+      await backupCollection(connection, collection, options.runId);
+      const result = await connection
+        .collection(collection)
+        .updateMany(LEGACY_DELETED_AT_FILTER, { $set: { deletedAt: null } });
+      updated = result.modifiedCount;
+    }
+
+    results.push({ ...shapes, updated });
+  }
+
+  const locationIndexRebuilt =
+    !options.dryRun && collections.includes('gaminglocations')
+      ? await rebuildLocationNameIndex(connection)
+      : false;
+
+  return {
+    runId: options.runId,
+    dryRun: options.dryRun,
+    startedAt,
+    finishedAt: new Date(),
+    collections: results,
+    total: results.reduce(
+      (sum, result) =>
+        sum +
+        (options.dryRun
+          ? result.dateSentinels + result.missing + result.numericSentinels
+          : result.updated),
+      0
+    ),
+    locationIndexRebuilt,
+  };
+}
+
+/**
+ * Puts back the `deletedAt` values a run backed up (unsetting the field
+ * where it was missing).
+ *
+ * @param connection - Database connection
+ * @param runId - Run to revert
+ * @returns Documents restored
+ */
+export async function restoreDeletedAtBackup(
+  connection: Connection,
+  runId: string
+): Promise<number> {
+  assertWritable('restoring a deletedAt backup');
+
+  const backups = await connection
+    .collection<BackupDocument>(DELETED_AT_BACKUP_COLLECTION)
+    .find({ runId })
+    .toArray();
+
+  let restored = 0;
+  for (const [collection, entries] of groupBackupsByCollection(backups)) {
+    const result = await connection.collection(collection).bulkWrite(
+      entries.map(entry => ({
+        updateOne: {
+          filter: { _id: entry.documentId as never, deletedAt: null },
+          update: entry.hadField
+            ? { $set: { deletedAt: entry.deletedAt } }
+            : { $unset: { deletedAt: '' } },
+        },
+      })),
+      { ordered: false }
+    );
+    restored += result.modifiedCount;
+  }
+  return restored;
+}
+
+/**
+ * One line per collection with anything to rewrite.
+ */
+export function formatDeletedAtReport(
+  report: DeletedAtNormalizationReport
+): string {
+  const width = Math.max(
+    ...report.collections.map(result => result.collection.length),
+    0
+  );
+  const lines = report.collections
+    .filter(
+      result =>
+        result.dateSentinels + result.numericSentinels + result.missing > 0
+    )
+    .map(
+      result =>
+        `${result.collection.padEnd(width)}  date=${result.dateSentinels} number=${result.numericSentinels} missing=${result.missing}${
+          report.dryRun ? '' : `  updated=${result.updated}`
+        }`
+    );
+  return lines.length > 0 ? lines.join('\n') : 'Nothing to normalize';
+}
@@ -0,0 +1,315 @@
+/**
+ * Dual-Write Conflict Helper
+ *
+ * During cutover both clusters briefly receive writes. A conflict is a
+ * document (same `_id`) written on both sides since a checkpoint whose
+ * content now differs. Conflicts are listed per collection and, when a
+ * resolution is chosen for the collection, resolved by copying the winning
+ * side's document over the other: `source-wins` overwrites the destination,
+ * `destination-wins` overwrites the source. The losing document is backed
+ * up on its own cluster before it is replaced.
+ *
+ * Documents written on only one side are not conflicts; `bun run resync`
+ * and `bun run consistency` cover those.
+ *
+ * Used by the `conflicts` command (scripts/dual-write-conflicts.ts).
+ *
+ * @module app/api/lib/helpers/dualWriteConflicts
+ */
+
+import { stableStringify } from '@/app/api/lib/helpers/dbConsistency';
+import type { ConsistencyTarget } from '@/app/api/lib/helpers/dbConsistency';
+import {
+  backupBeforeWrite,
+  createBackupRunId,
+} from '@/app/api/lib/helpers/writeBackups';
+import { normalizeId } from '@/app/api/lib/utils/mongoIds';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import type { Connection } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ConflictResolution = 'source-wins' | 'destination-wins';
+
+export type DualWriteConflict = {
+  id: string;
+  sourceWrittenAt: unknown;
+  destinationWrittenAt: unknown;
+  /** Top-level fields whose values differ */
+  fields: string[];
+};
+
+export type CollectionConflicts = {
+  collection: string;
+  timeField: string;
+  sourceWrites: number;
+  destinationWrites: number;
+  conflicts: number;
+  /** Chosen resolution; null when conflicts are only reported */
+  resolution: ConflictResolution | null;
+  resolved: number;
+  sample: DualWriteConflict[];
+};
+
+export type ConflictReport = {
+  since: Date;
+  checkedAt: Date;
+  dryRun: boolean;
+  /** Conflicts left unresolved across all collections */
+  unresolved: number;
+  /** Backup run holding the overwritten documents; null when none were */
+  backupRunId: string | null;
+  collections: CollectionConflicts[];
+};
+
+export type ConflictOptions = {
+  since: Date;
+  /** Resolution per collection; collections without one are reported only */
+  resolutions: Record<string, ConflictResolution>;
+  dryRun: boolean;
+  sampleSize: number;
+  maxDocuments: number;
+};
+
+export const CONFLICT_RESOLUTIONS: ConflictResolution[] = [
+  'source-wins',
+  'destination-wins',
+];
+
+export const DEFAULT_CONFLICT_SAMPLE_SIZE = 20;
+
+export const DEFAULT_CONFLICT_MAX_DOCUMENTS = 50000;
+
+type RawDocument = Record<string, unknown> & { _id: unknown };
+
+// ============================================================================
+// Parsing
+// ============================================================================
+
+/**
+ * Parses `machines=source-wins,meters=destination-wins` into resolutions per
+ * collection. `source` and `destination` are accepted as short forms.
+ *
+ * @throws Error on an unknown resolution
+ */
+export function parseConflictResolutions(
+  spec: string
+): Record<string, ConflictResolution> {
+  const resolutions: Record<string, ConflictResolution> = {};
+  spec
+    .split(',')
+    .filter(Boolean)
+    .forEach(entry => {
+      const [collection, value] = entry.split('=').map(part => part.trim());
+      const resolution = (
+        value && !value.endsWith('-wins') ? `${value}-wins` : value
+      ) as ConflictResolution;
+      if (!collection || !CONFLICT_RESOLUTIONS.includes(resolution)) {
+        throw new Error(
+          `Invalid resolution '${entry}'; use <collection>=source-wins|destination-wins`
+        );
+      }
+      resolutions[collection] = resolution;
+    });
+  return resolutions;
+}
+
+// ============================================================================
+// Detection & Resolution
+// ============================================================================
+
+async function fetchWrites(
+  connection: Connection,
+  target: ConsistencyTarget,
+  since: Date,
+  maxDocuments: number
+): Promise<Map<string, RawDocument>> {
+  const documents = await connection
+    .collection(target.collection)
+    .find({ [target.timeField]: { $gte: since } })
+    .limit(maxDocuments + 1)
+    .toArray();
+  if (documents.length > maxDocuments) {
+    throw new Error(
+      `${target.collection}: more than ${maxDocuments} documents written since the checkpoint; use a later --since`
+    );
+  }
+  // Key by normalized id so a string `_id` on one side matches an ObjectId
+  return new Map(
+    documents.map(document => [
+      normalizeId(document._id) ?? String(document._id),
+      document as RawDocument,
+    ])
+  );
+}
+
+function differingFields(a: RawDocument, b: RawDocument): string[] {
+  const fields = new Set([...Object.keys(a), ...Object.keys(b)]);
+  fields.delete('_id');
+  return Array.from(fields)
+    .filter(field => stableStringify(a[field]) !== stableStringify(b[field]))
+    .sort();
+}
+
+/**
+ * Backs up the losing side's document on its own connection, then replaces
+ * it with the winner's content, keeping the loser's `_id` so its type does
+ * not change.
+ */
+async function overwrite(
+  connection: Connection,
+  collection: string,
+  loser: RawDocument,
+  winner: RawDocument,
+  runId: string
+): Promise<boolean> {
+  await backupBeforeWrite(
+    {
+      operation: 'dual-write-conflict',
+      collection,
+      filter: { _id: loser._id },
+      runId,
+    },
+    connection
+  );
+  const { _id: _winnerId, ...replacement } = winner;
+  const result = await connection
+    .collection(collection)
+    .replaceOne({ _id: loser._id } as Record<string, unknown>, replacement);
+  return result.matchedCount > 0;
+}
+
+/**
+ * Finds documents written on both sides since `options.since` whose content
+ * differs, and resolves them for collections with a resolution unless
+ * `options.dryRun` is set.
+ *
+ * @param source - Source connection
+ * @param destination - Destination connection
+ * @param targets - Collections and the time field that marks a write
+ * @param options - Checkpoint, resolutions, dry run and sampling
+ * @returns Per-collection conflict report
+ */
+export async function detectDualWriteConflicts(
+  source: Connection,
+  destination: Connection,
+  targets: ConsistencyTarget[],
+  options: ConflictOptions
+): Promise<ConflictReport> {
+  const resolving = Object.keys(options.resolutions).length > 0;
+  if (resolving && !options.dryRun) {
+    assertWritable('resolving dual-write conflicts');
+  }
+  const backupRunId =
+    resolving && !options.dryRun
+      ? createBackupRunId('dual-write-conflict')
+      : null;
+
+  const checkedAt = new Date();
+  const collections: CollectionConflicts[] = [];
+  for (const target of targets) {
+    const [sourceDocs, destinationDocs] = await Promise.all([
+      fetchWrites(source, target, options.since, options.maxDocuments),
+      fetchWrites(destination, target, options.since, options.maxDocuments),
+    ]);
+    const resolution = options.resolutions[target.collection] ?? null;
+    const result: CollectionConflicts = {
+      collection: target.collection,
+      timeField: target.timeField,
+      sourceWrites: sourceDocs.size,
+      destinationWrites: destinationDocs.size,
+      conflicts: 0,
+      resolution,
+      resolved: 0,
+      sample: [],
+    };
+    collections.push(result);
+
+    for (const [id, sourceDoc] of sourceDocs) {
+      const destinationDoc = destinationDocs.get(id);
+      if (!destinationDoc) continue;
+      const fields = differingFields(sourceDoc, destinationDoc);
+      if (fields.length === 0) continue;
+
+      result.conflicts++;
+      if (result.sample.length < options.sampleSize) {
+        result.sample.push({
+          id,
+          sourceWrittenAt: sourceDoc[target.timeField],
+          destinationWrittenAt: destinationDoc[target.timeField],
+          fields,
+        });
+      }
+      if (!resolution || !backupRunId) continue;
+
+      const resolved =
+        resolution === 'source-wins'
+          ? await overwrite(
+              destination,
+              target.collection,
+              destinationDoc,
+              sourceDoc,
+              backupRunId
+            )
+          : await overwrite(
+              source,
+              target.collection,
+              sourceDoc,
+              destinationDoc,
+              backupRunId
+            );
+      if (resolved) result.resolved++;
+    }
+  }
+
+  return {
+    since: options.since,
+    checkedAt,
+    dryRun: options.dryRun,
+    unresolved: collections.reduce(
+      (sum, collection) => sum + collection.conflicts - collection.resolved,
+      0
+    ),
+    backupRunId,
+    collections,
+  };
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+function formatTime(value: unknown): string {
+  return value instanceof Date ? value.toISOString() : String(value ?? '-');
+}
+
+/**
+ * Plain-text report of a conflict run.
+ */
+export function formatConflictReport(report: ConflictReport): string {
+  const lines = [
+    `Writes on both sides since ${report.since.toISOString()}${report.dryRun ? ' (dry run)' : ''}`,
+  ];
+  report.collections.forEach(collection => {
+    const resolution = collection.resolution
+      ? report.dryRun
+        ? ` ${collection.resolution} would resolve ${collection.conflicts}`
+        : ` ${collection.resolution} resolved ${collection.resolved}`
+      : '';
+    lines.push(
+      `  ${collection.conflicts > 0 ? 'CONFLICT' : 'OK      '} ${collection.collection} (${collection.timeField}): source=${collection.sourceWrites} dest=${collection.destinationWrites} conflicts=${collection.conflicts}${resolution}`
+    );
+    collection.sample.forEach(conflict =>
+      lines.push(
+        `       ${conflict.id}: source ${formatTime(conflict.sourceWrittenAt)} / dest ${formatTime(conflict.destinationWrittenAt)}; ${conflict.fields.join(', ')}`
+      )
+    );
+  });
+  lines.push(`${report.unresolved} unresolved conflict(s)`);
+  if (report.backupRunId) {
+    lines.push(`Overwritten documents backed up in run ${report.backupRunId}`);
+  }
+  return lines.join('\n');
+}
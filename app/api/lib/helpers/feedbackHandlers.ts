@@ -27,12 +27,17 @@ type PutUpdateInput = {
   reviewedAt?: string | Date | null;
 };
 
-type PatchBody = Record<string, unknown>;
+type PatchBody = {
+  _id: string;
+  archived?: boolean;
+  status?: string;
+  notes?: string | null;
+};
 
 /**
  * Executes a PATCH update on a feedback document.
  *
- * @param {PatchBody} body - Parsed request body with _id and fields to update
+ * @param {PatchBody} body - Validated request body with _id and fields to update
  * @param {UserPayload} currentUser - Authenticated admin user
  * @param {string[]} userRoles - User role list for activity logging
  * @param {NextRequest} request - Original request for IP/user-agent extraction
@@ -52,13 +57,6 @@ export async function handleFeedbackPatch(
 ): Promise<NextResponse> {
   const { _id } = body;
 
-  if (!_id || typeof _id !== 'string') {
-    return NextResponse.json(
-      { success: false, error: 'Feedback ID is required' },
-      { status: 400 }
-    );
-  }
-
   const updateData = buildPatchUpdateData(body, currentUser);
 
   if (Object.keys(updateData).length === 0) {
@@ -63,7 +63,7 @@ type FeedbackQueryParams = {
 type PatchUpdateInput = {
   archived?: boolean;
   status?: string;
-  notes?: string;
+  notes?: string | null;
 };
 
 type PutUpdateInput = {
@@ -125,6 +125,13 @@ export const updateFeedbackSchema = z.object({
   reviewedAt: z.union([z.string(), z.date()]).optional().nullable(),
 });
 
+export const patchFeedbackSchema = z.object({
+  _id: z.string(),
+  archived: z.boolean().optional(),
+  status: z.enum(['pending', 'reviewed', 'resolved']).optional(),
+  notes: z.string().optional().nullable(),
+});
+
 export const deleteFeedbackSchema = z.object({
   _id: z.string(),
 });
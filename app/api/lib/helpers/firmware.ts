@@ -7,6 +7,7 @@
  * @module app/api/lib/helpers/firmware
  */
 
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { Firmware } from '@/lib/types/firmware';
 import { Db, GridFSBucket, ObjectId } from 'mongodb';
 import { connectDB } from '../middleware/db';
@@ -110,7 +111,7 @@ export async function findFirmwareByVersion(
 
   const firmwareDoc = await FirmwareModel.findOne({
     version: version,
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
+    ...NOT_DELETED_FILTER,
   }).lean<Firmware>();
 
   if (!firmwareDoc) {
@@ -0,0 +1,373 @@
+/**
+ * Negative Gross Investigation Helper
+ *
+ * Explains why a location's gross is negative for a range: breaks the gross
+ * down by machine and by gaming day using the licencee's financial formula,
+ * and lists the meter readings and collections pulling it down (negative
+ * per-reading gross, negative movement fields, RAM clears, negative
+ * collection movement).
+ *
+ * Used by the `why` command (scripts/why-negative-gross.ts).
+ *
+ * @module app/api/lib/helpers/grossInvestigation
+ */
+
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { Collections } from '@/app/api/lib/models/collections';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type {
+  FinancialFormula,
+  FinancialMetrics,
+  MovementTotals,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type GrossInvestigationParams = {
+  locationId: string;
+  timePeriod: string;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  /** Suspect documents to return per kind (default 10) */
+  limit?: number;
+};
+
+export type GrossBreakdownRow = FinancialMetrics & {
+  key: string;
+  label: string;
+  meterCount: number;
+};
+
+export type SuspectMeter = {
+  _id: string;
+  machine: string;
+  machineLabel: string;
+  readAt: Date;
+  gross: number;
+  reasons: string[];
+  movement: MovementTotals;
+  isRamClear?: boolean;
+  meterSource?: string;
+};
+
+export type SuspectCollection = {
+  _id: string;
+  machineId: string;
+  machineLabel: string;
+  locationReportId?: string;
+  timestamp: Date;
+  gross: number;
+  reasons: string[];
+  metersIn?: number;
+  metersOut?: number;
+  prevIn?: number;
+  prevOut?: number;
+  ramClear?: boolean;
+};
+
+export type GrossInvestigation = {
+  locationId: string;
+  locationName: string;
+  rangeStart: Date;
+  rangeEnd: Date;
+  formula: FinancialFormula;
+  totals: FinancialMetrics & { meterCount: number };
+  byMachine: GrossBreakdownRow[];
+  byDay: GrossBreakdownRow[];
+  suspectMeters: SuspectMeter[];
+  suspectCollections: SuspectCollection[];
+};
+
+type TotalsRow = MovementTotals & { _id: string; meterCount: number };
+
+/** Local time offset used for gaming days (UTC-4, as in gamingDayRange) */
+const LOCAL_UTC_OFFSET_HOURS = -4;
+const DEFAULT_LIMIT = 10;
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Aggregation expression for one reading's gross under a formula.
+ */
+function buildGrossExpression(formula: FinancialFormula): unknown {
+  const field = (name: string) => ({ $ifNull: [`$movement.${name}`, 0] });
+  const moneyOutFields = [...formula.moneyOutFields];
+  if (formula.includeJackpot && !moneyOutFields.includes('jackpot')) {
+    moneyOutFields.push('jackpot');
+  }
+  return {
+    $subtract: [
+      { $add: [0, ...formula.moneyInFields.map(field)] },
+      { $add: [0, ...moneyOutFields.map(field)] },
+    ],
+  };
+}
+
+function toBreakdownRow(
+  row: TotalsRow,
+  formula: FinancialFormula,
+  label: string
+): GrossBreakdownRow {
+  return {
+    key: String(row._id),
+    label,
+    meterCount: row.meterCount,
+    ...calculateFinancialMetrics(row, formula),
+  };
+}
+
+function getMachineLabel(machine?: {
+  _id: string;
+  serialNumber?: string;
+  custom?: { name?: string };
+}): string {
+  if (!machine) return '';
+  return machine.serialNumber?.trim() || machine.custom?.name || machine._id;
+}
+
+// ============================================================================
+// Investigation
+// ============================================================================
+
+/**
+ * Breaks down a location's gross and finds the documents driving it negative.
+ *
+ * @param params - Location, range and suspect limit
+ * @returns Breakdown by machine and day (most negative first / by date) and suspects
+ * @throws Error when the location does not exist
+ */
+export async function investigateLocationGross(
+  params: GrossInvestigationParams
+): Promise<GrossInvestigation> {
+  const limit = params.limit ?? DEFAULT_LIMIT;
+
+  // Step 1: Location, formula and gaming-day range
+  const location = await GamingLocations.findOne(
+    { _id: params.locationId },
+    { _id: 1, name: 1, gameDayOffset: 1, 'rel.licencee': 1 }
+  ).lean<{
+    _id: string;
+    name?: string;
+    gameDayOffset?: number;
+    rel?: { licencee?: string };
+  }>();
+  if (!location) {
+    throw new Error(`Location ${params.locationId} not found`);
+  }
+
+  const licenceeId = location.rel?.licencee;
+  const formula = licenceeId
+    ? (await getLicenceeFinancialFormulas([licenceeId])).get(licenceeId) ||
+      DEFAULT_FINANCIAL_FORMULA
+    : DEFAULT_FINANCIAL_FORMULA;
+  const gameDayOffset = location.gameDayOffset ?? 8;
+  const { rangeStart, rangeEnd } = getGamingDayRangeForPeriod(
+    params.timePeriod,
+    gameDayOffset,
+    params.customStartDate,
+    params.customEndDate
+  );
+
+  const meterMatch = {
+    location: params.locationId,
+    readAt: { $gte: rangeStart, $lte: rangeEnd },
+    ...NOT_DELETED_FILTER,
+  };
+  const totalsGroup = {
+    ...buildMovementTotalsGroup(),
+    meterCount: { $sum: 1 },
+  };
+  const gamingDayShiftMs = (gameDayOffset - LOCAL_UTC_OFFSET_HOURS) * 3600000;
+
+  // Step 2: Totals by machine and by gaming day, plus suspect readings
+  const [machineRows, dayRows, suspectMeters] = await Promise.all([
+    Meters.aggregate<TotalsRow>([
+      { $match: meterMatch },
+      { $group: { _id: '$machine', ...totalsGroup } },
+    ]),
+    Meters.aggregate<TotalsRow>([
+      { $match: meterMatch },
+      {
+        $group: {
+          _id: {
+            $dateToString: {
+              format: '%Y-%m-%d',
+              date: { $subtract: ['$readAt', gamingDayShiftMs] },
+            },
+          },
+          ...totalsGroup,
+        },
+      },
+      { $sort: { _id: 1 } },
+    ]),
+    Meters.aggregate<Omit<SuspectMeter, 'machineLabel' | 'reasons'>>([
+      { $match: meterMatch },
+      { $addFields: { gross: buildGrossExpression(formula) } },
+      {
+        $match: {
+          $or: [
+            { gross: { $lt: 0 } },
+            { isRamClear: true },
+            ...METER_MOVEMENT_FIELDS.map(field => ({
+              [`movement.${field}`]: { $lt: 0 },
+            })),
+          ],
+        },
+      },
+      { $sort: { gross: 1 } },
+      { $limit: limit },
+      {
+        $project: {
+          machine: 1,
+          readAt: 1,
+          gross: 1,
+          movement: 1,
+          isRamClear: 1,
+          meterSource: 1,
+        },
+      },
+    ]),
+  ]);
+
+  // Step 3: Collections with negative or reset movement
+  const suspectCollections = await Collections.find(
+    {
+      location: params.locationId,
+      timestamp: { $gte: rangeStart, $lte: rangeEnd },
+      ...NOT_DELETED_FILTER,
+      $or: [
+        { 'movement.gross': { $lt: 0 } },
+        { ramClear: true },
+        { $expr: { $lt: ['$metersIn', '$prevIn'] } },
+        { $expr: { $lt: ['$metersOut', '$prevOut'] } },
+      ],
+    },
+    {
+      _id: 1,
+      machineId: 1,
+      locationReportId: 1,
+      timestamp: 1,
+      metersIn: 1,
+      metersOut: 1,
+      prevIn: 1,
+      prevOut: 1,
+      ramClear: 1,
+      'movement.gross': 1,
+    }
+  )
+    .sort({ 'movement.gross': 1 })
+    .limit(limit)
+    .lean<
+      Array<{
+        _id: string;
+        machineId: string;
+        locationReportId?: string;
+        timestamp: Date;
+        metersIn?: number;
+        metersOut?: number;
+        prevIn?: number;
+        prevOut?: number;
+        ramClear?: boolean;
+        movement?: { gross?: number };
+      }>
+    >();
+
+  // Step 4: Machine labels
+  const machineIds = Array.from(
+    new Set([
+      ...machineRows.map(row => String(row._id)),
+      ...suspectCollections.map(collection => String(collection.machineId)),
+    ])
+  );
+  const machines = await Machine.find(
+    { _id: { $in: machineIds } },
+    { _id: 1, serialNumber: 1, 'custom.name': 1 }
+  ).lean<
+    Array<{ _id: string; serialNumber?: string; custom?: { name?: string } }>
+  >();
+  const machinesById = new Map(
+    machines.map(machine => [String(machine._id), machine])
+  );
+  const labelFor = (id: string) =>
+    getMachineLabel(machinesById.get(id)) || id;
+
+  // Step 5: Assemble
+  const totals = machineRows.reduce<MovementTotals>((sum, row) => {
+    METER_MOVEMENT_FIELDS.forEach(field => {
+      sum[field] = (sum[field] || 0) + (Number(row[field]) || 0);
+    });
+    return sum;
+  }, {});
+
+  return {
+    locationId: params.locationId,
+    locationName: location.name || params.locationId,
+    rangeStart,
+    rangeEnd,
+    formula,
+    totals: {
+      ...calculateFinancialMetrics(totals, formula),
+      meterCount: machineRows.reduce((sum, row) => sum + row.meterCount, 0),
+    },
+    byMachine: machineRows
+      .map(row => toBreakdownRow(row, formula, labelFor(String(row._id))))
+      .sort((a, b) => a.gross - b.gross),
+    byDay: dayRows.map(row => toBreakdownRow(row, formula, String(row._id))),
+    suspectMeters: suspectMeters.map(meter => {
+      const reasons: string[] = [];
+      if (meter.gross < 0) reasons.push('negative gross');
+      if (meter.isRamClear) reasons.push('RAM clear');
+      METER_MOVEMENT_FIELDS.forEach(field => {
+        if ((Number(meter.movement?.[field]) || 0) < 0) {
+          reasons.push(`negative ${field}`);
+        }
+      });
+      return {
+        ...meter,
+        _id: String(meter._id),
+        machineLabel: labelFor(String(meter.machine)),
+        reasons,
+      };
+    }),
+    suspectCollections: suspectCollections.map(collection => {
+      const reasons: string[] = [];
+      const gross = Number(collection.movement?.gross) || 0;
+      if (gross < 0) reasons.push('negative movement gross');
+      if (collection.ramClear) reasons.push('RAM clear');
+      if ((collection.metersIn ?? 0) < (collection.prevIn ?? 0)) {
+        reasons.push('metersIn below prevIn');
+      }
+      if ((collection.metersOut ?? 0) < (collection.prevOut ?? 0)) {
+        reasons.push('metersOut below prevOut');
+      }
+      return {
+        _id: String(collection._id),
+        machineId: String(collection.machineId),
+        machineLabel: labelFor(String(collection.machineId)),
+        locationReportId: collection.locationReportId,
+        timestamp: collection.timestamp,
+        gross,
+        reasons,
+        metersIn: collection.metersIn,
+        metersOut: collection.metersOut,
+        prevIn: collection.prevIn,
+        prevOut: collection.prevOut,
+        ramClear: collection.ramClear,
+      };
+    }),
+  };
+}
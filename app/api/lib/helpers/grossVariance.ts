@@ -0,0 +1,336 @@
+/**
+ * Gross Variance Helper
+ *
+ * Compares each location's gross for a gaming day with the average of the
+ * same weekday over the previous four weeks, and raises a variance alert
+ * (`varianceAlerts`) when the deviation is larger than the threshold. A
+ * sudden drop or spike against the location's usual trade is an early sign
+ * of a meter fault, a missed collection or a misconfigured machine.
+ *
+ * Gross uses the licencee's financial formula (never reviewer scales). Days
+ * without meter movement are left out of the baseline, and a location needs
+ * at least two of the four weeks to be compared; a zero baseline is skipped
+ * because no percentage can be taken from it.
+ *
+ * Used by the `gross-variance` command (scripts/gross-variance.ts).
+ *
+ * @module app/api/lib/helpers/grossVariance
+ */
+
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { VarianceAlert } from '@/app/api/lib/models/varianceAlert';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { isReadOnlyMode } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+import { generateMongoId } from '@/lib/utils/id';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type GrossVarianceParams = {
+  /** Gaming day to check, YYYY-MM-DD */
+  day: string;
+  /** Alert when |gross - baseline| / |baseline| exceeds this percent */
+  thresholdPercent: number;
+  /** Only these locations (default: all) */
+  locationIds?: string[];
+  /** Only locations of this licencee */
+  licenceeId?: string;
+  /** Count and report without recording alerts */
+  dryRun?: boolean;
+};
+
+export type GrossVarianceRow = {
+  locationId: string;
+  locationName: string;
+  licenceeId: string | null;
+  gross: number;
+  /** Average gross of the same weekday in the trailing weeks */
+  baseline: number | null;
+  /** Trailing weeks with meter movement */
+  sampleSize: number;
+  /** Signed percent; null when there is no usable baseline */
+  deviationPercent: number | null;
+  alert: boolean;
+};
+
+export type GrossVarianceReport = {
+  day: string;
+  thresholdPercent: number;
+  checkedAt: Date;
+  rows: GrossVarianceRow[];
+  alerts: GrossVarianceRow[];
+  /** Alerts written to varianceAlerts (0 on a dry run or in read-only mode) */
+  recorded: number;
+};
+
+/** Trailing same-weekday samples the baseline is taken from */
+export const GROSS_VARIANCE_WEEKS = 4;
+/** Default alert threshold (GROSS_VARIANCE_THRESHOLD_PERCENT) */
+export const DEFAULT_GROSS_VARIANCE_THRESHOLD = 50;
+
+const MIN_SAMPLES = 2;
+
+type VarianceLocation = {
+  _id: string;
+  name: string;
+  gameDayOffset?: number;
+  rel?: { licencee?: string };
+};
+
+function shiftDay(day: string, days: number): string {
+  const date = new Date(`${day}T00:00:00Z`);
+  date.setUTCDate(date.getUTCDate() + days);
+  return date.toISOString().slice(0, 10);
+}
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+/**
+ * Threshold from GROSS_VARIANCE_THRESHOLD_PERCENT, else the default.
+ */
+export function getGrossVarianceThreshold(): number {
+  const value = Number(process.env.GROSS_VARIANCE_THRESHOLD_PERCENT);
+  return Number.isFinite(value) && value > 0
+    ? value
+    : DEFAULT_GROSS_VARIANCE_THRESHOLD;
+}
+
+// ============================================================================
+// Detection
+// ============================================================================
+
+/**
+ * Checks every location in scope for the day and records an alert per
+ * location over the threshold (one per location and day; re-runs update it).
+ *
+ * @param params - Day, threshold and scope
+ * @returns Every location checked, ordered by absolute deviation
+ */
+export async function detectGrossVariance(
+  params: GrossVarianceParams
+): Promise<GrossVarianceReport> {
+  const { day, thresholdPercent } = params;
+
+  // Step 1: Locations in scope
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (params.locationIds && params.locationIds.length > 0) {
+    locationQuery._id = { $in: params.locationIds };
+  }
+  if (params.licenceeId) {
+    locationQuery['rel.licencee'] = params.licenceeId;
+  }
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    gameDayOffset: 1,
+    'rel.licencee': 1,
+  }).lean<VarianceLocation[]>();
+
+  // Step 2: The day, then the same weekday in each trailing week
+  const days = [day];
+  for (let week = 1; week <= GROSS_VARIANCE_WEEKS; week++) {
+    days.push(shiftDay(day, -7 * week));
+  }
+  const rangesByDay = days.map(gamingDay => {
+    const ranges = new Map<string, GamingDayRange>();
+    const date = new Date(`${gamingDay}T00:00:00Z`);
+    locations.forEach(location => {
+      ranges.set(
+        String(location._id),
+        getGamingDayRangeForPeriod(
+          'Custom',
+          location.gameDayOffset ?? 8,
+          date,
+          date
+        )
+      );
+    });
+    return ranges;
+  });
+  const licenceeIds = Array.from(
+    new Set(
+      locations
+        .map(location => location.rel?.licencee)
+        .filter((id): id is string => Boolean(id))
+        .map(String)
+    )
+  );
+  const [totalsByDay, formulaByLicencee] = await Promise.all([
+    Promise.all(
+      rangesByDay.map(ranges => getMovementTotalsWithRollup(ranges))
+    ),
+    getLicenceeFinancialFormulas(licenceeIds),
+  ]);
+
+  // Step 3: Compare with the baseline
+  const rows: GrossVarianceRow[] = locations.map(location => {
+    const locationId = String(location._id);
+    const licenceeId = location.rel?.licencee
+      ? String(location.rel.licencee)
+      : null;
+    const formula =
+      (licenceeId && formulaByLicencee.get(licenceeId)) ||
+      DEFAULT_FINANCIAL_FORMULA;
+    const grossOn = (index: number): number | null => {
+      const totals = totalsByDay[index].get(locationId);
+      return totals ? calculateFinancialMetrics(totals, formula).gross : null;
+    };
+
+    const gross = grossOn(0) ?? 0;
+    const samples = days
+      .slice(1)
+      .map((_, week) => grossOn(week + 1))
+      .filter((value): value is number => value !== null);
+    const baseline =
+      samples.length >= MIN_SAMPLES
+        ? samples.reduce((sum, value) => sum + value, 0) / samples.length
+        : null;
+    const deviationPercent =
+      baseline !== null && baseline !== 0
+        ? round2(((gross - baseline) / Math.abs(baseline)) * 100)
+        : null;
+
+    return {
+      locationId,
+      locationName: location.name,
+      licenceeId,
+      gross: round2(gross),
+      baseline: baseline === null ? null : round2(baseline),
+      sampleSize: samples.length,
+      deviationPercent,
+      alert:
+        deviationPercent !== null &&
+        Math.abs(deviationPercent) > thresholdPercent,
+    };
+  });
+  rows.sort(
+    (a, b) =>
+      Math.abs(b.deviationPercent ?? 0) - Math.abs(a.deviationPercent ?? 0) ||
+      a.locationName.localeCompare(b.locationName)
+  );
+  const alerts = rows.filter(row => row.alert);
+
+  // Step 4: Record alerts
+  let recorded = 0;
+  if (alerts.length > 0 && !params.dryRun) {
+    if (isReadOnlyMode()) {
+      console.warn(
+        '[grossVariance] Read-only mode is on; variance alerts not recorded'
+      );
+    } else {
+      recorded = await recordVarianceAlerts(day, thresholdPercent, alerts);
+    }
+  }
+
+  return {
+    day,
+    thresholdPercent,
+    checkedAt: new Date(),
+    rows,
+    alerts,
+    recorded,
+  };
+}
+
+/**
+ * Upserts one alert per location and day; the review status of an existing
+ * alert is kept.
+ */
+async function recordVarianceAlerts(
+  day: string,
+  thresholdPercent: number,
+  alerts: GrossVarianceRow[]
+): Promise<number> {
+  const operations = await Promise.all(
+    alerts.map(async alert => ({
+      updateOne: {
+        filter: { location: alert.locationId, gamingDay: day },
+        update: {
+          $set: {
+            locationName: alert.locationName,
+            licencee: alert.licenceeId,
+            gross: alert.gross,
+            baseline: alert.baseline,
+            sampleSize: alert.sampleSize,
+            deviationPercent: alert.deviationPercent,
+            thresholdPercent,
+            details: describeVariance(alert),
+          },
+          $setOnInsert: {
+            _id: await generateMongoId(),
+            status: 'open',
+            detectedAt: new Date(),
+            reviewedBy: null,
+            reviewedAt: null,
+          },
+        },
+        upsert: true,
+      },
+    }))
+  );
+  await VarianceAlert.bulkWrite(operations, { ordered: false });
+  return operations.length;
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+function describeVariance(row: GrossVarianceRow): string {
+  const deviation = row.deviationPercent ?? 0;
+  return `Gross ${row.gross.toFixed(2)} is ${Math.abs(deviation)}% ${
+    deviation < 0 ? 'below' : 'above'
+  } the ${row.sampleSize}-week same-weekday average ${(
+    row.baseline ?? 0
+  ).toFixed(2)}`;
+}
+
+/**
+ * One line per alert, then a summary line.
+ */
+export function formatGrossVarianceReport(report: GrossVarianceReport): string {
+  const lines = report.alerts.map(
+    alert =>
+      `${alert.locationName} (${alert.locationId}): ${describeVariance(alert)}`
+  );
+  const skipped = report.rows.filter(row => row.baseline === null).length;
+  return [
+    `Gross variance for ${report.day} (threshold ${report.thresholdPercent}%)`,
+    ...lines,
+    `${report.alerts.length} of ${report.rows.length} location(s) over the threshold${
+      skipped > 0 ? `; ${skipped} without enough history` : ''
+    }`,
+  ].join('\n');
+}
+
+/**
+ * Posts a report to a webhook. The payload carries a `text` summary
+ * (Slack/Teams compatible) plus the full report.
+ *
+ * @param url - Webhook URL
+ * @param report - Gross variance report
+ */
+export async function postGrossVarianceWebhook(
+  url: string,
+  report: GrossVarianceReport
+): Promise<void> {
+  const response = await fetch(url, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ text: formatGrossVarianceReport(report), report }),
+  });
+  if (!response.ok) {
+    throw new Error(`Webhook responded with HTTP ${response.status}`);
+  }
+}
@@ -0,0 +1,343 @@
+/**
+ * gRPC Back-Office Handlers
+ *
+ * The RPCs of the `casino.v1.BackOffice` service
+ * (proto/casino/v1/back_office.proto) for internal consumers: machine lookup
+ * by serial, location metrics for a period, and saved report templates. Each
+ * handler takes and returns plain objects in the shape `@grpc/proto-loader`
+ * produces (camelCase fields), and delegates to the helpers the HTTP API and
+ * commands use, so the answers match theirs.
+ *
+ * Callers are trusted services, not users: calls are authorised by the
+ * shared `GRPC_AUTH_TOKEN` and see every location.
+ *
+ * Served by the `grpc` command (scripts/grpc-server.ts).
+ *
+ * @module app/api/lib/helpers/grpcBackOffice
+ */
+
+import {
+  getLocationReport,
+} from '@/app/api/lib/helpers/locations/locationReport';
+import type {
+  LocationReportMachine,
+} from '@/app/api/lib/helpers/locations/locationReport';
+import { lookupMachinesBySerial } from '@/app/api/lib/helpers/machineLookup';
+import type { MachineLookupRow } from '@/app/api/lib/helpers/machineLookup';
+import {
+  getReportTemplateByName,
+  runReportTemplate,
+} from '@/app/api/lib/helpers/reports/reportTemplates';
+import { getExportProfile } from '@/app/api/lib/utils/exportProfiles';
+import { getSecret } from '@/app/api/lib/utils/secrets';
+import {
+  getErrorStatus,
+  statusError,
+} from '@/app/api/lib/utils/statusErrors';
+import { timingSafeEqual } from 'crypto';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type GrpcMachine = Omit<
+  MachineLookupRow,
+  | 'customName'
+  | 'game'
+  | 'smibId'
+  | 'locationId'
+  | 'locationName'
+  | 'licenceeId'
+  | 'licenceeName'
+  | 'lastActivity'
+  | 'collectionMeters'
+  | 'collectionTime'
+> & {
+  customName: string;
+  game: string;
+  smibId: string;
+  locationId: string;
+  locationName: string;
+  licenceeId: string;
+  licenceeName: string;
+  /** RFC 3339; empty when the machine never reported */
+  lastActivity: string;
+};
+
+export type GrpcLookupMachinesResponse = {
+  requested: number;
+  machines: GrpcMachine[];
+  notFound: string[];
+  ambiguous: string[];
+};
+
+export type GrpcLocationMetricsRequest = {
+  locationId?: string;
+  timePeriod?: string;
+  startDate?: string;
+  endDate?: string;
+};
+
+export type GrpcFinancialTotals = {
+  moneyIn: number;
+  moneyOut: number;
+  jackpot: number;
+  gross: number;
+  netGross: number;
+  drop: number;
+  totalCancelledCredits: number;
+  gamesPlayed: number;
+};
+
+export type GrpcLocationMetrics = {
+  locationId: string;
+  locationName: string;
+  licenceeId: string;
+  licenceeName: string;
+  rangeStart: string;
+  rangeEnd: string;
+  machineCounts: { total: number; online: number; offline: number };
+  totals: GrpcFinancialTotals;
+  machines: Array<{
+    machineId: string;
+    serialNumber: string;
+    customName: string;
+    assetStatus: string;
+    online: boolean;
+    totals: GrpcFinancialTotals;
+  }>;
+};
+
+export type GrpcRunReportRequest = {
+  template?: string;
+  format?: string;
+  profile?: string;
+};
+
+export type GrpcRunReportResponse = {
+  template: string;
+  reportType: string;
+  rowCount: number;
+  contentType: string;
+  content: Buffer;
+};
+
+/** gRPC status codes the handlers' errors map to */
+export const GRPC_STATUS = {
+  INVALID_ARGUMENT: 3,
+  DEADLINE_EXCEEDED: 4,
+  NOT_FOUND: 5,
+  ALREADY_EXISTS: 6,
+  PERMISSION_DENIED: 7,
+  INTERNAL: 13,
+  UNAVAILABLE: 14,
+  UNAUTHENTICATED: 16,
+} as const;
+
+function readDate(value: string | undefined, name: string): Date | undefined {
+  if (!value) return undefined;
+  const date = new Date(value);
+  if (Number.isNaN(date.getTime())) {
+    throw statusError(`${name} must be a valid date`, 400);
+  }
+  return date;
+}
+
+function financialTotals(
+  source: Pick<
+    LocationReportMachine,
+    | 'moneyIn'
+    | 'moneyOut'
+    | 'jackpot'
+    | 'gross'
+    | 'netGross'
+    | 'drop'
+    | 'totalCancelledCredits'
+    | 'gamesPlayed'
+  >
+): GrpcFinancialTotals {
+  return {
+    moneyIn: source.moneyIn,
+    moneyOut: source.moneyOut,
+    jackpot: source.jackpot,
+    gross: source.gross,
+    netGross: source.netGross,
+    drop: source.drop,
+    totalCancelledCredits: source.totalCancelledCredits,
+    gamesPlayed: source.gamesPlayed,
+  };
+}
+
+// ============================================================================
+// Authorisation & Errors
+// ============================================================================
+
+/**
+ * Checks the bearer token of a call against `GRPC_AUTH_TOKEN` (also
+ * `_FILE` / `_SECRET`).
+ *
+ * @param authorization - The call's `authorization` metadata
+ * @param expected - Already resolved token (the server resolves it once)
+ * @returns 'ok', 'invalid', or 'unconfigured' when no token is set
+ */
+export async function verifyGrpcToken(
+  authorization: string | null | undefined,
+  expected?: string
+): Promise<'ok' | 'invalid' | 'unconfigured'> {
+  expected ??= await getSecret('GRPC_AUTH_TOKEN');
+  if (!expected) return 'unconfigured';
+  const token = authorization?.match(/^Bearer\s+(.+)$/i)?.[1]?.trim();
+  if (!token) return 'invalid';
+  const a = Buffer.from(token);
+  const b = Buffer.from(expected);
+  return a.length === b.length && timingSafeEqual(a, b) ? 'ok' : 'invalid';
+}
+
+/**
+ * The gRPC status for an error thrown by a handler, from its HTTP-style
+ * `statusCode` (INTERNAL when it has none).
+ */
+export function grpcStatusForError(error: unknown): number {
+  switch (getErrorStatus(error)) {
+    case 400:
+      return GRPC_STATUS.INVALID_ARGUMENT;
+    case 403:
+      return GRPC_STATUS.PERMISSION_DENIED;
+    case 404:
+      return GRPC_STATUS.NOT_FOUND;
+    case 409:
+      return GRPC_STATUS.ALREADY_EXISTS;
+    case 503:
+      return GRPC_STATUS.UNAVAILABLE;
+    case 504:
+      return GRPC_STATUS.DEADLINE_EXCEEDED;
+    default:
+      return GRPC_STATUS.INTERNAL;
+  }
+}
+
+// ============================================================================
+// Handlers
+// ============================================================================
+
+/**
+ * `LookupMachines`: machines by serial number.
+ *
+ * @throws Error with `statusCode` 400 when the list is empty or too long
+ */
+export async function grpcLookupMachines(request: {
+  serials?: string[];
+}): Promise<GrpcLookupMachinesResponse> {
+  const result = await lookupMachinesBySerial(request.serials || [], 'all');
+  return {
+    requested: result.requested,
+    machines: result.found.map(row => ({
+      serial: row.serial,
+      machineId: row.machineId,
+      serialNumber: row.serialNumber,
+      customName: row.customName || '',
+      game: row.game || '',
+      smibId: row.smibId || '',
+      locationId: row.locationId || '',
+      locationName: row.locationName || '',
+      licenceeId: row.licenceeId || '',
+      licenceeName: row.licenceeName || '',
+      assetStatus: row.assetStatus,
+      online: row.online,
+      lastActivity: row.lastActivity ? row.lastActivity.toISOString() : '',
+      meters: row.meters,
+    })),
+    notFound: result.notFound,
+    ambiguous: result.ambiguous,
+  };
+}
+
+/**
+ * `GetLocationMetrics`: machine counts and financial totals of a location.
+ *
+ * @throws Error with `statusCode` 400 on a missing location or bad dates,
+ * 404 when the location does not exist
+ */
+export async function grpcGetLocationMetrics(
+  request: GrpcLocationMetricsRequest
+): Promise<GrpcLocationMetrics> {
+  if (!request.locationId) throw statusError('location_id is required', 400);
+  const customStartDate = readDate(request.startDate, 'start_date');
+  const customEndDate = readDate(request.endDate, 'end_date');
+  const timePeriod = request.timePeriod || '7d';
+  if (timePeriod === 'Custom' && (!customStartDate || !customEndDate)) {
+    throw statusError(
+      'time_period Custom requires start_date and end_date',
+      400
+    );
+  }
+
+  const report = await getLocationReport({
+    locationId: request.locationId,
+    timePeriod,
+    customStartDate,
+    customEndDate,
+    eventLimit: 0,
+  });
+  return {
+    locationId: report.locationId,
+    locationName: report.locationName,
+    licenceeId: report.licenceeId || '',
+    licenceeName: report.licenceeName || '',
+    rangeStart: report.rangeStart.toISOString(),
+    rangeEnd: report.rangeEnd.toISOString(),
+    machineCounts: {
+      total: report.machineCounts.total,
+      online: report.machineCounts.online,
+      offline: report.machineCounts.offline,
+    },
+    totals: financialTotals(report.totals),
+    machines: report.machines.map(machine => ({
+      machineId: machine.machineId,
+      serialNumber: machine.serialNumber,
+      customName: machine.customName || '',
+      assetStatus: machine.assetStatus,
+      online: machine.online,
+      totals: financialTotals(machine),
+    })),
+  };
+}
+
+/**
+ * `RunReport`: runs a saved report template over every location.
+ *
+ * @throws Error with `statusCode` 400 on a missing template or bad format,
+ * 404 when the template or export profile does not exist
+ */
+export async function grpcRunReport(
+  request: GrpcRunReportRequest
+): Promise<GrpcRunReportResponse> {
+  if (!request.template) throw statusError('template is required', 400);
+  const format = request.format || undefined;
+  if (format && format !== 'json' && format !== 'csv') {
+    throw statusError('format must be json or csv', 400);
+  }
+  const template = await getReportTemplateByName(request.template);
+  if (!template) {
+    throw statusError(`Template '${request.template}' not found`, 404);
+  }
+
+  const result = await runReportTemplate(
+    template,
+    {
+      allowedLocationIds: 'all',
+      profile: request.profile ? getExportProfile(request.profile) : undefined,
+    },
+    format as 'json' | 'csv' | undefined
+  );
+  return {
+    template: result.template,
+    reportType: result.reportType,
+    rowCount: result.rows.length,
+    contentType:
+      result.csv !== undefined ? 'text/csv' : 'application/json',
+    content: Buffer.from(
+      result.csv !== undefined ? result.csv : JSON.stringify(result.rows)
+    ),
+  };
+}
@@ -0,0 +1,423 @@
+/**
+ * Health Checks Helper
+ *
+ * Dependency checks behind `/healthz`, `/readyz` and the `doctor` command
+ * (scripts/doctor.ts). Each check reports `ok`, `warn` or `fail`:
+ *
+ * - `mongo`        — the database answers a ping (fail otherwise)
+ * - `collections`  — the core collections exist and can be read (fail)
+ * - `indexes`      — the indexes the models declare exist (warn when missing:
+ *   queries still work, only slower, and every instance shares the database)
+ * - `preaggregation` — `casinoMetrics` and the `metersDaily` rollup are
+ *   recent (warn when stale, since the raw data is still served)
+ *
+ * Liveness runs `mongo` only; readiness runs them all. The overall status is
+ * the worst check's. While the instance drains for shutdown (see
+ * gracefulShutdown) readiness fails at once, so the load balancer stops
+ * sending traffic, and liveness passes without touching the database. Every
+ * check is capped at HEALTH_CHECK_TIMEOUT_MS (default 3000), and readiness
+ * reports are reused for HEALTH_CACHE_MS (default 5000) so frequent probes
+ * do not each scan the database.
+ *
+ * @module app/api/lib/helpers/healthChecks
+ */
+
+import { getDefaultRollupDay } from '@/app/api/lib/helpers/metersDaily';
+import { CollectionReport } from '@/app/api/lib/models/collectionReport';
+import { Collections } from '@/app/api/lib/models/collections';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import { Member } from '@/app/api/lib/models/members';
+import { Meters } from '@/app/api/lib/models/meters';
+import { MetersDaily } from '@/app/api/lib/models/metersDaily';
+import UserModel from '@/app/api/lib/models/user';
+import { isShuttingDown } from '@/app/api/lib/utils/gracefulShutdown';
+import mongoose from 'mongoose';
+import type { Model } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type HealthStatus = 'ok' | 'warn' | 'fail';
+
+export type HealthCheckName =
+  | 'shutdown'
+  | 'mongo'
+  | 'collections'
+  | 'indexes'
+  | 'preaggregation';
+
+export type HealthCheckResult = {
+  name: HealthCheckName;
+  status: HealthStatus;
+  message: string;
+  durationMs: number;
+  details?: Record<string, unknown>;
+};
+
+export type HealthReport = {
+  status: HealthStatus;
+  kind: 'liveness' | 'readiness';
+  checkedAt: Date;
+  uptimeSeconds: number;
+  checks: HealthCheckResult[];
+};
+
+export type HealthCheckOptions = {
+  /** Opens the database connection when it is not open yet */
+  connect: () => Promise<unknown>;
+  /** casinoMetrics older than this are stale (default 60) */
+  maxMetricsAgeMinutes?: number;
+  /** Per-check time limit (default HEALTH_CHECK_TIMEOUT_MS) */
+  timeoutMs?: number;
+  /** Skip the readiness cache */
+  fresh?: boolean;
+};
+
+type MissingIndex = { collection: string; key: unknown };
+
+/** Models whose collections must be readable and whose indexes must exist */
+const CORE_MODELS: Model<unknown>[] = [
+  Machine,
+  Meters,
+  GamingLocations,
+  Licencee,
+  Collections,
+  CollectionReport,
+  Member,
+  MachineSession,
+  MetersDaily,
+  UserModel,
+] as unknown as Model<unknown>[];
+
+const STATUS_RANK: Record<HealthStatus, number> = { ok: 0, warn: 1, fail: 2 };
+const DEFAULT_TIMEOUT_MS = 3000;
+const DEFAULT_CACHE_MS = 5000;
+const DEFAULT_METRICS_AGE_MINUTES = 60;
+
+let cachedReadiness: { report: HealthReport; expiresAt: number } | null = null;
+
+function readMs(name: string, fallback: number): number {
+  const value = Number(process.env[name]);
+  return Number.isFinite(value) && value >= 0 ? value : fallback;
+}
+
+function worstStatus(statuses: HealthStatus[]): HealthStatus {
+  return statuses.reduce<HealthStatus>(
+    (worst, status) =>
+      STATUS_RANK[status] > STATUS_RANK[worst] ? status : worst,
+    'ok'
+  );
+}
+
+function getDb() {
+  const db = mongoose.connection.db;
+  if (!db) throw new Error('Database connection is not open');
+  return db;
+}
+
+/**
+ * Runs one check, turning a throw or a timeout into a `fail` result.
+ */
+async function runCheck(
+  name: HealthCheckName,
+  check: () => Promise<Omit<HealthCheckResult, 'name' | 'durationMs'>>,
+  options: HealthCheckOptions
+): Promise<HealthCheckResult> {
+  const startTime = Date.now();
+  const timeoutMs =
+    options.timeoutMs ?? readMs('HEALTH_CHECK_TIMEOUT_MS', DEFAULT_TIMEOUT_MS);
+  let timer: NodeJS.Timeout | undefined;
+  try {
+    const result = await Promise.race([
+      check(),
+      new Promise<never>((_, reject) => {
+        timer = setTimeout(
+          () => reject(new Error(`Timed out after ${timeoutMs}ms`)),
+          timeoutMs
+        );
+      }),
+    ]);
+    return { name, ...result, durationMs: Date.now() - startTime };
+  } catch (error) {
+    return {
+      name,
+      status: 'fail',
+      message: error instanceof Error ? error.message : String(error),
+      durationMs: Date.now() - startTime,
+    };
+  } finally {
+    clearTimeout(timer);
+  }
+}
+
+// ============================================================================
+// Checks
+// ============================================================================
+
+async function checkMongo(
+  connect: () => Promise<unknown>
+): Promise<Omit<HealthCheckResult, 'name' | 'durationMs'>> {
+  if (mongoose.connection.readyState !== 1) await connect();
+  const startTime = Date.now();
+  await getDb().command({ ping: 1 });
+  return {
+    status: 'ok',
+    message: 'Database answered ping',
+    details: {
+      database: mongoose.connection.name,
+      pingMs: Date.now() - startTime,
+    },
+  };
+}
+
+async function checkCollections(): Promise<
+  Omit<HealthCheckResult, 'name' | 'durationMs'>
+> {
+  const db = getDb();
+  const names = CORE_MODELS.map(model => model.collection.collectionName);
+  const existing = new Set(
+    (await db.listCollections({}, { nameOnly: true }).toArray()).map(
+      collection => collection.name
+    )
+  );
+  const missing = names.filter(name => !existing.has(name));
+  const unreadable: string[] = [];
+  await Promise.all(
+    names
+      .filter(name => existing.has(name))
+      .map(async name => {
+        try {
+          await db
+            .collection(name)
+            .findOne({}, { projection: { _id: 1 }, maxTimeMS: 1000 });
+        } catch {
+          unreadable.push(name);
+        }
+      })
+  );
+
+  const problems = [
+    ...missing.map(name => `${name} missing`),
+    ...unreadable.map(name => `${name} unreadable`),
+  ];
+  return {
+    status: problems.length > 0 ? 'fail' : 'ok',
+    message:
+      problems.length > 0
+        ? problems.join(', ')
+        : `${names.length} collections readable`,
+    details: { collections: names, missing, unreadable },
+  };
+}
+
+async function checkIndexes(): Promise<
+  Omit<HealthCheckResult, 'name' | 'durationMs'>
+> {
+  const missing: MissingIndex[] = [];
+  for (const model of CORE_MODELS) {
+    const diff = await model.diffIndexes();
+    for (const index of diff.toCreate) {
+      missing.push({ collection: model.collection.collectionName, key: index });
+    }
+  }
+  return {
+    status: missing.length > 0 ? 'warn' : 'ok',
+    message:
+      missing.length > 0
+        ? `${missing.length} declared index(es) missing`
+        : 'Declared indexes exist',
+    details: { missing },
+  };
+}
+
+async function checkPreaggregation(
+  maxAgeMinutes: number
+): Promise<Omit<HealthCheckResult, 'name' | 'durationMs'>> {
+  const db = getDb();
+  const cutoff = new Date(Date.now() - maxAgeMinutes * 60000);
+  const [metricsTotal, metricsStale, latestRollup] = await Promise.all([
+    db.collection('casinoMetrics').estimatedDocumentCount(),
+    db.collection('casinoMetrics').countDocuments(
+      {
+        $or: [
+          { lastUpdated: { $lt: cutoff } },
+          { lastUpdated: { $exists: false } },
+        ],
+      },
+      { maxTimeMS: 2000 }
+    ),
+    MetersDaily.findOne({}, { gamingDay: 1 })
+      .sort({ gamingDay: -1 })
+      .lean<{ gamingDay?: string }>(),
+  ]);
+
+  const expectedDay = getDefaultRollupDay();
+  const rollupDay = latestRollup?.gamingDay ?? null;
+  const problems: string[] = [];
+  if (metricsStale > 0) {
+    problems.push(
+      `${metricsStale} of ${metricsTotal} casinoMetrics older than ${maxAgeMinutes} min`
+    );
+  }
+  // An empty rollup means it is not in use; reads fall back to raw meters
+  if (rollupDay && rollupDay < expectedDay) {
+    problems.push(`metersDaily ends at ${rollupDay}, expected ${expectedDay}`);
+  }
+  return {
+    status: problems.length > 0 ? 'warn' : 'ok',
+    message:
+      problems.length > 0 ? problems.join('; ') : 'Pre-aggregations are fresh',
+    details: {
+      casinoMetrics: {
+        total: metricsTotal,
+        stale: metricsStale,
+        maxAgeMinutes,
+      },
+      metersDaily: {
+        latestGamingDay: rollupDay,
+        expectedGamingDay: expectedDay,
+      },
+    },
+  };
+}
+
+// ============================================================================
+// Reports
+// ============================================================================
+
+function buildReport(
+  kind: HealthReport['kind'],
+  checks: HealthCheckResult[]
+): HealthReport {
+  return {
+    status: worstStatus(checks.map(check => check.status)),
+    kind,
+    checkedAt: new Date(),
+    uptimeSeconds: Math.round(process.uptime()),
+    checks,
+  };
+}
+
+/**
+ * Liveness: the process is up and the database answers.
+ */
+export async function getLivenessReport(
+  options: HealthCheckOptions
+): Promise<HealthReport> {
+  if (isShuttingDown()) {
+    return buildReport('liveness', [
+      {
+        name: 'shutdown',
+        status: 'ok',
+        message: 'Draining for shutdown',
+        durationMs: 0,
+      },
+    ]);
+  }
+  const mongo = await runCheck(
+    'mongo',
+    () => checkMongo(options.connect),
+    options
+  );
+  return buildReport('liveness', [mongo]);
+}
+
+/**
+ * Readiness: every check. The other checks are skipped (failed) when the
+ * database does not answer.
+ */
+export async function getReadinessReport(
+  options: HealthCheckOptions
+): Promise<HealthReport> {
+  if (isShuttingDown()) {
+    return buildReport('readiness', [
+      {
+        name: 'shutdown',
+        status: 'fail',
+        message: 'Draining for shutdown',
+        durationMs: 0,
+      },
+    ]);
+  }
+  if (
+    !options.fresh &&
+    cachedReadiness &&
+    cachedReadiness.expiresAt > Date.now()
+  ) {
+    return cachedReadiness.report;
+  }
+
+  const mongo = await runCheck(
+    'mongo',
+    () => checkMongo(options.connect),
+    options
+  );
+  const checks: HealthCheckResult[] = [mongo];
+  if (mongo.status === 'fail') {
+    for (const name of ['collections', 'indexes', 'preaggregation'] as const) {
+      checks.push({
+        name,
+        status: 'fail',
+        message: 'Skipped: database unavailable',
+        durationMs: 0,
+      });
+    }
+  } else {
+    checks.push(
+      ...(await Promise.all([
+        runCheck('collections', checkCollections, options),
+        runCheck('indexes', checkIndexes, options),
+        runCheck(
+          'preaggregation',
+          () =>
+            checkPreaggregation(
+              options.maxMetricsAgeMinutes ?? DEFAULT_METRICS_AGE_MINUTES
+            ),
+          options
+        ),
+      ]))
+    );
+  }
+
+  const report = buildReport('readiness', checks);
+  cachedReadiness = {
+    report,
+    expiresAt: Date.now() + readMs('HEALTH_CACHE_MS', DEFAULT_CACHE_MS),
+  };
+  return report;
+}
+
+/**
+ * HTTP status for a report: 503 on `fail`, or on `warn` when strict.
+ */
+export function healthHttpStatus(report: HealthReport, strict = false): number {
+  if (report.status === 'fail') return 503;
+  return strict && report.status === 'warn' ? 503 : 200;
+}
+
+/**
+ * Plain-text rendering for the `doctor` command.
+ */
+export function formatHealthReport(report: HealthReport): string {
+  const lines = [
+    `${report.kind} ${report.status.toUpperCase()} (${report.checkedAt.toISOString()})`,
+  ];
+  for (const check of report.checks) {
+    lines.push(
+      `  [${check.status.padEnd(4)}] ${check.name.padEnd(14)} ${check.message} (${check.durationMs}ms)`
+    );
+    const missing = check.name === 'indexes' ? check.details?.missing : null;
+    if (Array.isArray(missing)) {
+      for (const index of missing as MissingIndex[]) {
+        lines.push(
+          `         ${index.collection}: ${JSON.stringify(index.key)}`
+        );
+      }
+    }
+  }
+  return lines.join('\n');
+}
@@ -0,0 +1,273 @@
+/**
+ * SMIB Heartbeat Helper
+ *
+ * Ingests SMIB pings (serial, timestamp, firmware) so machine online/offline
+ * status has a source we control: each accepted ping advances the machine's
+ * `lastActivity` (never backwards), records the reported firmware in
+ * `smibVersion.firmware`, and is stored in `heartbeats` (kept 30 days).
+ *
+ * The serial is matched against `relayId`, then `smibBoard`, then the
+ * machine `serialNumber`. Pings for unknown serials are still stored, with
+ * `machine: null`, so new installs show up. A reported timestamp more than
+ * five minutes in the future (or missing) is replaced by the receive time.
+ *
+ * Used by `POST /api/smib/heartbeat` and the `heartbeat-receiver` command
+ * (scripts/heartbeat-receiver.ts, HTTP and UDP).
+ *
+ * @module app/api/lib/helpers/heartbeats
+ */
+
+import { Heartbeat } from '@/app/api/lib/models/heartbeat';
+import { Machine } from '@/app/api/lib/models/machines';
+import { getSecret } from '@/app/api/lib/utils/secrets';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+import type { HeartbeatSource } from '@shared/types';
+import { timingSafeEqual } from 'crypto';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type HeartbeatPing = {
+  serial: string;
+  reportedAt: Date;
+  firmware: string | null;
+};
+
+export type HeartbeatIngestResult = {
+  received: number;
+  /** Pings matched to a machine */
+  matched: number;
+  /** Serials with no machine */
+  unknown: string[];
+  /** Pings rejected as malformed, with the reason */
+  rejected: Array<{ index: number; error: string }>;
+};
+
+/** Most pings accepted in one request or batch */
+export const MAX_HEARTBEATS_PER_BATCH = 500;
+
+const MAX_FUTURE_SKEW_MS = 5 * 60 * 1000;
+
+type HeartbeatMachine = {
+  _id: string;
+  relayId?: string;
+  smibBoard?: string;
+  serialNumber?: string;
+  smibVersion?: { firmware?: string };
+};
+
+// ============================================================================
+// Parsing & Auth
+// ============================================================================
+
+/**
+ * Reads a timestamp given as ISO string, epoch seconds or epoch ms.
+ */
+function parseTimestamp(value: unknown): Date | null {
+  if (value === undefined || value === null || value === '') return null;
+  if (typeof value === 'number' || /^\d+$/.test(String(value))) {
+    const number = Number(value);
+    const date = new Date(number < 1e12 ? number * 1000 : number);
+    return Number.isNaN(date.getTime()) ? null : date;
+  }
+  const date = new Date(String(value));
+  return Number.isNaN(date.getTime()) ? null : date;
+}
+
+/**
+ * Validates one ping. Accepts `serial` (or `relayId`), `timestamp` (or
+ * `ts`) and `firmware` (or `fw`).
+ *
+ * @param input - Parsed JSON body or UDP payload
+ * @param receivedAt - When the ping arrived
+ * @returns The ping, or an error message
+ */
+export function parseHeartbeat(
+  input: unknown,
+  receivedAt: Date
+): HeartbeatPing | { error: string } {
+  if (!input || typeof input !== 'object') return { error: 'Not an object' };
+  const raw = input as Record<string, unknown>;
+
+  const serial = String(raw.serial ?? raw.relayId ?? '').trim();
+  if (!serial || serial.length > 64) {
+    return { error: 'serial is required (at most 64 characters)' };
+  }
+
+  const reported = parseTimestamp(raw.timestamp ?? raw.ts);
+  const reportedAt =
+    reported &&
+    reported.getTime() <= receivedAt.getTime() + MAX_FUTURE_SKEW_MS
+      ? reported
+      : receivedAt;
+
+  const firmware = String(raw.firmware ?? raw.fw ?? '').trim();
+  return {
+    serial,
+    reportedAt,
+    firmware: firmware ? firmware.slice(0, 64) : null,
+  };
+}
+
+/**
+ * Parses a UDP datagram: JSON (`{"serial":..,"ts":..,"fw":..,"token":..}`)
+ * or CSV text (`serial,timestamp,firmware[,token]`).
+ *
+ * @returns The ping fields and the token sent with them, or null
+ */
+export function parseHeartbeatDatagram(
+  message: string
+): { input: Record<string, unknown>; token: string | null } | null {
+  const text = message.trim();
+  if (!text) return null;
+  if (text.startsWith('{')) {
+    try {
+      const input = JSON.parse(text) as Record<string, unknown>;
+      return {
+        input,
+        token: typeof input.token === 'string' ? input.token : null,
+      };
+    } catch {
+      return null;
+    }
+  }
+  const [serial, timestamp, firmware, token] = text.split(',');
+  return {
+    input: { serial, timestamp, firmware },
+    token: token?.trim() || null,
+  };
+}
+
+/**
+ * Checks a token against `HEARTBEAT_TOKEN` (also `_FILE` / `_SECRET`).
+ *
+ * @param token - Token sent by the SMIB
+ * @param expected - Already resolved token (the receiver resolves it once)
+ * @returns 'ok', 'invalid', or 'unconfigured' when no token is set
+ */
+export async function verifyHeartbeatToken(
+  token: string | null | undefined,
+  expected?: string
+): Promise<'ok' | 'invalid' | 'unconfigured'> {
+  expected ??= await getSecret('HEARTBEAT_TOKEN');
+  if (!expected) return 'unconfigured';
+  if (!token) return 'invalid';
+  const a = Buffer.from(token);
+  const b = Buffer.from(expected);
+  return a.length === b.length && timingSafeEqual(a, b) ? 'ok' : 'invalid';
+}
+
+// ============================================================================
+// Ingestion
+// ============================================================================
+
+/**
+ * Stores a batch of pings and advances the matched machines' lastActivity.
+ *
+ * @param inputs - Raw pings (validated here)
+ * @param source - 'http' or 'udp'
+ * @param ip - Sender address, when known
+ * @throws Error with `statusCode` 400 when the batch is empty or too large
+ */
+export async function ingestHeartbeats(
+  inputs: unknown[],
+  source: HeartbeatSource,
+  ip: string | null = null
+): Promise<HeartbeatIngestResult> {
+  if (inputs.length === 0 || inputs.length > MAX_HEARTBEATS_PER_BATCH) {
+    throw statusError(
+      `Send between 1 and ${MAX_HEARTBEATS_PER_BATCH} heartbeats per request`,
+      400
+    );
+  }
+
+  // Step 1: Validate
+  const receivedAt = new Date();
+  const rejected: HeartbeatIngestResult['rejected'] = [];
+  const pings: HeartbeatPing[] = [];
+  inputs.forEach((input, index) => {
+    const parsed = parseHeartbeat(input, receivedAt);
+    if ('error' in parsed) rejected.push({ index, error: parsed.error });
+    else pings.push(parsed);
+  });
+  if (pings.length === 0) {
+    return { received: inputs.length, matched: 0, unknown: [], rejected };
+  }
+
+  // Step 2: Match serials to machines
+  const serials = Array.from(new Set(pings.map(ping => ping.serial)));
+  const machines = await Machine.find(
+    {
+      $or: [
+        { relayId: { $in: serials } },
+        { smibBoard: { $in: serials } },
+        { serialNumber: { $in: serials } },
+      ],
+      ...NOT_DELETED_FILTER,
+    },
+    { _id: 1, relayId: 1, smibBoard: 1, serialNumber: 1, smibVersion: 1 }
+  ).lean<HeartbeatMachine[]>();
+  const bySerial = new Map<string, HeartbeatMachine>();
+  (['serialNumber', 'smibBoard', 'relayId'] as const).forEach(field => {
+    machines.forEach(machine => {
+      const value = machine[field]?.trim();
+      if (value) bySerial.set(value, machine);
+    });
+  });
+
+  // Step 3: Latest ping per machine advances lastActivity and firmware
+  const latest = new Map<string, HeartbeatPing>();
+  pings.forEach(ping => {
+    const machine = bySerial.get(ping.serial);
+    if (!machine) return;
+    const current = latest.get(String(machine._id));
+    if (!current || ping.reportedAt > current.reportedAt) {
+      latest.set(String(machine._id), ping);
+    }
+  });
+  if (latest.size > 0) {
+    await Machine.bulkWrite(
+      Array.from(latest.entries()).map(([machineId, ping]) => ({
+        updateOne: {
+          filter: { _id: machineId },
+          update: {
+            $max: { lastActivity: ping.reportedAt },
+            ...(ping.firmware
+              ? { $set: { 'smibVersion.firmware': ping.firmware } }
+              : {}),
+          },
+        },
+      })),
+      { ordered: false }
+    );
+  }
+
+  // Step 4: Store the pings
+  const documents = await Promise.all(
+    pings.map(async ping => {
+      const machine = bySerial.get(ping.serial);
+      return {
+        _id: await generateMongoId(),
+        machine: machine ? String(machine._id) : null,
+        serialNumber: ping.serial,
+        relayId: machine?.relayId || null,
+        firmware: ping.firmware,
+        reportedAt: ping.reportedAt,
+        receivedAt,
+        source,
+        ip,
+      };
+    })
+  );
+  await Heartbeat.insertMany(documents, { ordered: false });
+
+  return {
+    received: inputs.length,
+    matched: pings.filter(ping => bySerial.has(ping.serial)).length,
+    unknown: serials.filter(serial => !bySerial.has(serial)),
+    rejected,
+  };
+}
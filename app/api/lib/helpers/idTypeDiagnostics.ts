@@ -0,0 +1,153 @@
+/**
+ * Id Type Diagnostics Helper
+ *
+ * Reports how `_id` and id reference fields are stored in each collection
+ * (string, objectId, missing, …). Mixed types make string and ObjectId
+ * queries miss each other's documents; see app/api/lib/utils/mongoIds.ts for
+ * the normalization layer used when they cannot be avoided.
+ *
+ * Used by the `id-types` command (scripts/check-id-types.ts).
+ *
+ * @module app/api/lib/helpers/idTypeDiagnostics
+ */
+
+import type { Connection, PipelineStage } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type IdTypeField = {
+  collection: string;
+  field: string;
+};
+
+export type IdTypeFieldReport = IdTypeField & {
+  /** Documents examined (the sample size when sampling) */
+  examined: number;
+  /** BSON type name → document count */
+  types: Record<string, number>;
+  /** More than one id type (ignoring missing/null) */
+  mixed: boolean;
+};
+
+export type IdTypeReport = {
+  checkedAt: Date;
+  sampleSize: number | null;
+  fields: IdTypeFieldReport[];
+  mixed: boolean;
+};
+
+/** `_id` plus the references we join on most */
+export const DEFAULT_ID_TYPE_FIELDS: IdTypeField[] = [
+  { collection: 'licencees', field: '_id' },
+  { collection: 'countries', field: '_id' },
+  { collection: 'gaminglocations', field: '_id' },
+  { collection: 'gaminglocations', field: 'rel.licencee' },
+  { collection: 'gaminglocations', field: 'country' },
+  { collection: 'machines', field: '_id' },
+  { collection: 'machines', field: 'gamingLocation' },
+  { collection: 'meters', field: '_id' },
+  { collection: 'meters', field: 'machine' },
+  { collection: 'meters', field: 'location' },
+  { collection: 'collections', field: 'machineId' },
+  { collection: 'collectionreports', field: 'location' },
+];
+
+const NON_ID_TYPES = ['missing', 'null'];
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Parses `collection` (its `_id`) or `collection:field` into a field spec.
+ */
+export function parseIdTypeField(spec: string): IdTypeField {
+  const [collection, field] = spec.split(':').map(part => part.trim());
+  return { collection, field: field || '_id' };
+}
+
+async function countIdTypes(
+  connection: Connection,
+  target: IdTypeField,
+  sampleSize: number | null
+): Promise<IdTypeFieldReport> {
+  const pipeline: PipelineStage[] = [];
+  if (sampleSize) pipeline.push({ $sample: { size: sampleSize } });
+  pipeline.push({
+    $group: { _id: { $type: `$${target.field}` }, count: { $sum: 1 } },
+  });
+
+  const rows = await connection
+    .collection(target.collection)
+    .aggregate<{ _id: string; count: number }>(pipeline, {
+      allowDiskUse: true,
+    })
+    .toArray();
+
+  const types = Object.fromEntries(rows.map(row => [row._id, row.count]));
+  const idTypes = Object.keys(types).filter(
+    type => !NON_ID_TYPES.includes(type)
+  );
+
+  return {
+    ...target,
+    examined: rows.reduce((sum, row) => sum + row.count, 0),
+    types,
+    mixed: idTypes.length > 1,
+  };
+}
+
+// ============================================================================
+// Runner
+// ============================================================================
+
+/**
+ * Counts the stored BSON type of each field.
+ *
+ * @param connection - Database connection
+ * @param fields - Collection/field pairs to inspect
+ * @param sampleSize - Random documents per collection; null scans everything
+ * @returns Type distribution per field
+ */
+export async function getIdTypeReport(
+  connection: Connection,
+  fields: IdTypeField[] = DEFAULT_ID_TYPE_FIELDS,
+  sampleSize: number | null = null
+): Promise<IdTypeReport> {
+  const results: IdTypeFieldReport[] = [];
+  for (const field of fields) {
+    results.push(await countIdTypes(connection, field, sampleSize));
+  }
+
+  return {
+    checkedAt: new Date(),
+    sampleSize,
+    fields: results,
+    mixed: results.some(result => result.mixed),
+  };
+}
+
+/**
+ * One line per field, e.g. `machines.gamingLocation  string=812 objectId=4`.
+ */
+export function formatIdTypeReport(report: IdTypeReport): string {
+  const width = Math.max(
+    ...report.fields.map(
+      result => `${result.collection}.${result.field}`.length
+    ),
+    0
+  );
+  return report.fields
+    .map(result => {
+      const name = `${result.collection}.${result.field}`.padEnd(width);
+      const types = Object.entries(result.types)
+        .sort(([, a], [, b]) => b - a)
+        .map(([type, count]) => `${type}=${count}`)
+        .join(' ');
+      const status = result.mixed ? 'MIXED' : 'ok   ';
+      return `${status}  ${name}  ${types || '(empty)'}`;
+    })
+    .join('\n');
+}
@@ -0,0 +1,386 @@
+/**
+ * Integrity Digest Helper
+ *
+ * Emails each licencee's operations contact (`Licencee.contact.email`) a
+ * summary of the integrity issues detected at its locations since the last
+ * digest: counts per check and the machines and locations with the most new
+ * issues. Issues are grouped by licencee through their location; issues
+ * without one (or at a location without a licencee) are counted but not sent.
+ *
+ * An issue is marked `digestedAt` once its digest is sent, so a missed run is
+ * caught up by the next one. Licencees without a contact email are skipped
+ * and their issues stay pending until one is set (within the lookback).
+ *
+ * Intended to run daily from an external scheduler, either through
+ * `POST /api/admin/integrity-digest` or `bun run integrity-digest`.
+ *
+ * @module app/api/lib/helpers/integrityDigest
+ */
+
+import { ACTIVE_INTEGRITY_ISSUE_STATUSES } from '@/app/api/lib/helpers/integrityIssues';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { IntegrityIssue } from '@/app/api/lib/models/integrityIssue';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { sendEmail } from '@/lib/services/emailService';
+import type { IntegrityIssueDocument } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+/** Oldest undigested issue picked up by a run */
+export const DIGEST_LOOKBACK_DAYS = 7;
+
+/** Machines and locations listed as worst offenders */
+const WORST_OFFENDER_COUNT = 5;
+
+export type IntegrityDigestOffender = {
+  id: string;
+  name: string;
+  count: number;
+};
+
+export type LicenceeIntegrityDigest = {
+  licencee: string;
+  licenceeName: string;
+  contactEmail: string | null;
+  issueCount: number;
+  byCheck: Record<string, number>;
+  worstMachines: IntegrityDigestOffender[];
+  worstLocations: IntegrityDigestOffender[];
+  issueIds: string[];
+  status: 'pending' | 'sent' | 'skipped' | 'failed';
+  error?: string;
+};
+
+export type IntegrityDigestResult = {
+  since: Date;
+  generatedAt: Date;
+  dryRun: boolean;
+  /** New issues found in the window */
+  issueCount: number;
+  /** New issues that could not be tied to a licencee */
+  unassignedCount: number;
+  digests: LicenceeIntegrityDigest[];
+  sent: number;
+  skipped: number;
+  failed: number;
+};
+
+export type IntegrityDigestOptions = {
+  /** Oldest detection included (default: DIGEST_LOOKBACK_DAYS ago) */
+  since?: Date;
+  /** Limit the run to these licencees */
+  licenceeIds?: string[];
+  /** Build the digests without sending or marking issues */
+  dryRun?: boolean;
+};
+
+type DigestIssue = Pick<
+  IntegrityIssueDocument,
+  '_id' | 'check' | 'machine' | 'location'
+>;
+
+type LocationInfo = {
+  _id: string;
+  name?: string;
+  rel?: { licencee?: string };
+};
+
+type MachineInfo = {
+  _id: string;
+  serialNumber?: string;
+  custom?: { name?: string };
+};
+
+type LicenceeInfo = {
+  _id: string;
+  name: string;
+  contact?: { email?: string };
+};
+
+// ============================================================================
+// Building
+// ============================================================================
+
+/**
+ * Ranks ids by their number of issues, most first.
+ */
+function rankOffenders(
+  ids: Array<string | undefined>,
+  nameOf: (id: string) => string
+): IntegrityDigestOffender[] {
+  const counts = new Map<string, number>();
+  ids.forEach(id => {
+    if (id) counts.set(id, (counts.get(id) ?? 0) + 1);
+  });
+  return [...counts.entries()]
+    .sort((a, b) => b[1] - a[1])
+    .slice(0, WORST_OFFENDER_COUNT)
+    .map(([id, count]) => ({ id, name: nameOf(id), count }));
+}
+
+/**
+ * Groups the undigested issues detected since `since` by licencee.
+ *
+ * @returns The digests (status `pending`) and the count of issues that could
+ * not be tied to a licencee
+ */
+export async function buildIntegrityDigests({
+  since,
+  licenceeIds = [],
+}: {
+  since: Date;
+  licenceeIds?: string[];
+}): Promise<{
+  issueCount: number;
+  unassignedCount: number;
+  digests: LicenceeIntegrityDigest[];
+}> {
+  const issues = await IntegrityIssue.find(
+    {
+      digestedAt: null,
+      detectedAt: { $gte: since },
+      status: { $in: ACTIVE_INTEGRITY_ISSUE_STATUSES },
+    },
+    { check: 1, machine: 1, location: 1 }
+  ).lean<DigestIssue[]>();
+
+  const locationIds = [
+    ...new Set(issues.map(issue => issue.location).filter(Boolean)),
+  ];
+  const locations = await GamingLocations.find(
+    { _id: { $in: locationIds } },
+    { name: 1, 'rel.licencee': 1 }
+  ).lean<LocationInfo[]>();
+  const locationById = new Map(locations.map(loc => [String(loc._id), loc]));
+
+  const issuesByLicencee = new Map<string, DigestIssue[]>();
+  let unassignedCount = 0;
+  issues.forEach(issue => {
+    const licencee = issue.location
+      ? locationById.get(issue.location)?.rel?.licencee
+      : undefined;
+    if (!licencee) {
+      unassignedCount += 1;
+      return;
+    }
+    if (licenceeIds.length > 0 && !licenceeIds.includes(licencee)) return;
+    issuesByLicencee.set(licencee, [
+      ...(issuesByLicencee.get(licencee) ?? []),
+      issue,
+    ]);
+  });
+
+  const machineIds = [
+    ...new Set(
+      [...issuesByLicencee.values()]
+        .flat()
+        .map(issue => issue.machine)
+        .filter(Boolean)
+    ),
+  ];
+  const [machines, licencees] = await Promise.all([
+    Machine.find(
+      { _id: { $in: machineIds } },
+      { serialNumber: 1, 'custom.name': 1 }
+    ).lean<MachineInfo[]>(),
+    Licencee.find(
+      { _id: { $in: [...issuesByLicencee.keys()] } },
+      { name: 1, 'contact.email': 1 }
+    ).lean<LicenceeInfo[]>(),
+  ]);
+  const machineById = new Map(machines.map(m => [String(m._id), m]));
+  const licenceeById = new Map(licencees.map(l => [String(l._id), l]));
+
+  const digests = [...issuesByLicencee.entries()]
+    .map(([licencee, licenceeIssues]): LicenceeIntegrityDigest => {
+      const byCheck: Record<string, number> = {};
+      licenceeIssues.forEach(issue => {
+        byCheck[issue.check] = (byCheck[issue.check] ?? 0) + 1;
+      });
+      const info = licenceeById.get(licencee);
+      return {
+        licencee,
+        licenceeName: info?.name ?? licencee,
+        contactEmail: info?.contact?.email?.trim() || null,
+        issueCount: licenceeIssues.length,
+        byCheck,
+        worstMachines: rankOffenders(
+          licenceeIssues.map(issue => issue.machine),
+          id => {
+            const machine = machineById.get(id);
+            return machine?.serialNumber || machine?.custom?.name || id;
+          }
+        ),
+        worstLocations: rankOffenders(
+          licenceeIssues.map(issue => issue.location),
+          id => locationById.get(id)?.name || id
+        ),
+        issueIds: licenceeIssues.map(issue => issue._id),
+        status: 'pending',
+      };
+    })
+    .sort((a, b) => b.issueCount - a.issueCount);
+
+  return { issueCount: issues.length, unassignedCount, digests };
+}
+
+// ============================================================================
+// Sending
+// ============================================================================
+
+/**
+ * Builds the digests and emails each licencee's contact, marking the sent
+ * issues `digestedAt`. A failed email leaves its issues for the next run.
+ */
+export async function sendIntegrityDigests(
+  options: IntegrityDigestOptions = {}
+): Promise<IntegrityDigestResult> {
+  const dryRun = options.dryRun ?? false;
+  if (!dryRun) assertWritable('sending integrity digests');
+
+  const generatedAt = new Date();
+  const since =
+    options.since ??
+    new Date(generatedAt.getTime() - DIGEST_LOOKBACK_DAYS * 86400000);
+  const { issueCount, unassignedCount, digests } = await buildIntegrityDigests({
+    since,
+    licenceeIds: options.licenceeIds,
+  });
+
+  for (const digest of digests) {
+    if (!digest.contactEmail) {
+      digest.status = 'skipped';
+      digest.error = 'No contact email';
+      continue;
+    }
+    if (dryRun) continue;
+
+    const email = formatIntegrityDigestEmail(digest, since);
+    const result = await sendEmail({ to: digest.contactEmail, ...email });
+    if (!result.success) {
+      digest.status = 'failed';
+      const error = 'error' in result ? result.error : undefined;
+      digest.error =
+        error instanceof Error ? error.message : String(error ?? 'Not sent');
+      continue;
+    }
+    await IntegrityIssue.updateMany(
+      { _id: { $in: digest.issueIds } },
+      { $set: { digestedAt: generatedAt } }
+    );
+    digest.status = 'sent';
+  }
+
+  return {
+    since,
+    generatedAt,
+    dryRun,
+    issueCount,
+    unassignedCount,
+    digests,
+    sent: digests.filter(digest => digest.status === 'sent').length,
+    skipped: digests.filter(digest => digest.status === 'skipped').length,
+    failed: digests.filter(digest => digest.status === 'failed').length,
+  };
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/** Escapes text for HTML element content and attribute values */
+export function escapeHtml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;');
+}
+
+/**
+ * Subject, plain text and HTML of one licencee's digest email.
+ */
+export function formatIntegrityDigestEmail(
+  digest: LicenceeIntegrityDigest,
+  since: Date
+): { subject: string; text: string; html: string } {
+  const day = since.toISOString().slice(0, 10);
+  const subject = `${digest.issueCount} new data integrity issue${digest.issueCount === 1 ? '' : 's'} - ${digest.licenceeName}`;
+  const checks = Object.entries(digest.byCheck).sort((a, b) => b[1] - a[1]);
+  const offenderLines = (offenders: IntegrityDigestOffender[]) =>
+    offenders.map(offender => `  ${offender.name}: ${offender.count}`);
+
+  const text = [
+    `${digest.issueCount} new integrity issues detected for ${digest.licenceeName} since ${day}.`,
+    '',
+    'By check:',
+    ...checks.map(([check, count]) => `  ${check}: ${count}`),
+    '',
+    'Machines with the most issues:',
+    ...offenderLines(digest.worstMachines),
+    '',
+    'Locations with the most issues:',
+    ...offenderLines(digest.worstLocations),
+    '',
+    'Review them in the integrity issue queue.',
+  ].join('\n');
+
+  const rows = (entries: Array<[string, number]>) =>
+    entries
+      .map(
+        ([name, count]) =>
+          `<tr><td style="padding: 4px 12px 4px 0;">${escapeHtml(name)}</td><td style="text-align: right;">${count}</td></tr>`
+      )
+      .join('');
+  const table = (title: string, entries: Array<[string, number]>) =>
+    entries.length === 0
+      ? ''
+      : `<h3 style="color: #1a202c;">${title}</h3><table style="border-collapse: collapse; color: #4a5568;">${rows(entries)}</table>`;
+  const offenderEntries = (offenders: IntegrityDigestOffender[]) =>
+    offenders.map((offender): [string, number] => [
+      offender.name,
+      offender.count,
+    ]);
+
+  const html = `
+    <div style="font-family: sans-serif; max-width: 600px; margin: 0 auto; padding: 20px; border: 1px solid #e2e8f0;">
+      <h2 style="color: #1a202c;">Data integrity digest - ${escapeHtml(digest.licenceeName)}</h2>
+      <p style="color: #4a5568;">${digest.issueCount} new integrity issues detected since ${day}.</p>
+      ${table('By check', checks)}
+      ${table('Machines with the most issues', offenderEntries(digest.worstMachines))}
+      ${table('Locations with the most issues', offenderEntries(digest.worstLocations))}
+      <p style="color: #718096; font-size: 14px;">Review them in the integrity issue queue.</p>
+    </div>
+  `;
+
+  return { subject, text, html };
+}
+
+/**
+ * Formats a digest run as one line per licencee for the command line.
+ */
+export function formatIntegrityDigestResult(
+  result: IntegrityDigestResult
+): string {
+  const lines = [
+    `Integrity digest since ${result.since.toISOString()}${result.dryRun ? ' (dry run)' : ''}: ${result.issueCount} new issues, ${result.unassignedCount} without a licencee`,
+  ];
+  if (result.digests.length === 0) lines.push('  Nothing to send');
+  result.digests.forEach(digest => {
+    const worst = digest.worstMachines
+      .map(offender => `${offender.name} (${offender.count})`)
+      .join(', ');
+    lines.push(
+      `  ${digest.licenceeName}: ${digest.issueCount} issues -> ${digest.contactEmail ?? 'no contact'}  ${digest.status}${digest.error ? ` (${digest.error})` : ''}`
+    );
+    if (worst) lines.push(`    worst machines: ${worst}`);
+  });
+  lines.push(
+    `Sent ${result.sent}, skipped ${result.skipped}, failed ${result.failed}`
+  );
+  return lines.join('\n');
+}
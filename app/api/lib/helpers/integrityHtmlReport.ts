@@ -0,0 +1,319 @@
+/**
+ * Integrity HTML Report
+ *
+ * Renders an `integrity` run as a standalone HTML page to share with
+ * management, written next to the JSON report with `--html <path>`:
+ * - the pass/fail summary of the run and its trend against the previous run
+ * - findings per check over the recent runs in `integrityRuns` (stacked bars)
+ * - the locations with the most open or investigating `integrityIssues`
+ *
+ * Charts are drawn with Chart.js loaded from a CDN; the same figures are
+ * printed as tables below each chart, so the page still reads offline.
+ *
+ * @module app/api/lib/helpers/integrityHtmlReport
+ */
+
+import type { IntegrityReport } from '@/app/api/lib/helpers/dataIntegrity';
+import { escapeHtml } from '@/app/api/lib/helpers/integrityDigest';
+import { ACTIVE_INTEGRITY_ISSUE_STATUSES } from '@/app/api/lib/helpers/integrityIssues';
+import { getIntegrityRunHistory } from '@/app/api/lib/helpers/integrityTrends';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { IntegrityIssue } from '@/app/api/lib/models/integrityIssue';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+/** Runs shown on the findings-over-time chart */
+export const HTML_REPORT_RUNS = 30;
+
+/** Locations shown on the worst locations chart */
+const WORST_LOCATION_COUNT = 10;
+
+const CHART_JS_URL =
+  'https://cdn.jsdelivr.net/npm/chart.js@4.4.1/dist/chart.umd.min.js';
+
+/** One colour per check, reused across runs */
+const CHECK_COLOURS = [
+  '#e53e3e',
+  '#dd6b20',
+  '#d69e2e',
+  '#38a169',
+  '#3182ce',
+  '#805ad5',
+  '#d53f8c',
+];
+
+export type IntegrityLocationCount = {
+  id: string;
+  name: string;
+  count: number;
+  byCheck: Record<string, number>;
+};
+
+export type IntegrityRunCounts = {
+  checkedAt: Date;
+  checks: Array<{ name: string; count: number }>;
+};
+
+export type IntegrityHtmlReportData = {
+  report: IntegrityReport;
+  /** Recent runs, oldest first, including the current one */
+  runs: IntegrityRunCounts[];
+  worstLocations: IntegrityLocationCount[];
+};
+
+// ============================================================================
+// Building
+// ============================================================================
+
+/**
+ * Ranks locations by their open and investigating integrity issues.
+ */
+async function getWorstLocations(): Promise<IntegrityLocationCount[]> {
+  const counts = await IntegrityIssue.aggregate<{
+    _id: { location: string; check: string };
+    count: number;
+  }>([
+    {
+      $match: {
+        status: { $in: ACTIVE_INTEGRITY_ISSUE_STATUSES },
+        location: { $nin: [null, ''] },
+      },
+    },
+    {
+      $group: {
+        _id: { location: '$location', check: '$check' },
+        count: { $sum: 1 },
+      },
+    },
+  ]);
+
+  const byLocation = new Map<string, IntegrityLocationCount>();
+  counts.forEach(({ _id, count }) => {
+    const id = String(_id.location);
+    const entry = byLocation.get(id) ?? {
+      id,
+      name: id,
+      count: 0,
+      byCheck: {},
+    };
+    entry.count += count;
+    entry.byCheck[_id.check] = (entry.byCheck[_id.check] ?? 0) + count;
+    byLocation.set(id, entry);
+  });
+  const worst = [...byLocation.values()]
+    .sort((a, b) => b.count - a.count)
+    .slice(0, WORST_LOCATION_COUNT);
+
+  const locations = await GamingLocations.find(
+    { _id: { $in: worst.map(entry => entry.id) } },
+    { name: 1 }
+  ).lean<Array<{ _id: string; name?: string }>>();
+  const nameById = new Map(
+    locations.map(location => [String(location._id), location.name])
+  );
+  worst.forEach(entry => {
+    entry.name = nameById.get(entry.id) || entry.id;
+  });
+  return worst;
+}
+
+/**
+ * Collects what the HTML report shows: the recent runs (with the current one
+ * added when it was not recorded) and the worst locations. Assumes a
+ * database connection is open.
+ *
+ * @param report - Report of the run that just finished
+ * @param runCount - Runs shown on the findings-over-time chart
+ */
+export async function buildIntegrityHtmlReportData(
+  report: IntegrityReport,
+  runCount: number = HTML_REPORT_RUNS
+): Promise<IntegrityHtmlReportData> {
+  const [history, worstLocations] = await Promise.all([
+    getIntegrityRunHistory(runCount),
+    getWorstLocations(),
+  ]);
+
+  const runs: IntegrityRunCounts[] = [...history].reverse();
+  if (!report.trend?.recorded) {
+    runs.push({ checkedAt: report.checkedAt, checks: report.checks });
+  }
+
+  return {
+    report,
+    runs: runs.slice(-runCount),
+    worstLocations,
+  };
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/** JSON safe to embed in a `<script>` element */
+function scriptJson(value: unknown): string {
+  return JSON.stringify(value).replace(/</g, '\\u003c');
+}
+
+function formatRunTime(value: Date): string {
+  return new Date(value).toISOString().slice(0, 16).replace('T', ' ');
+}
+
+function table(
+  headers: string[],
+  rows: Array<Array<string | number>>
+): string {
+  const head = headers
+    .map(header => `<th>${escapeHtml(header)}</th>`)
+    .join('');
+  const body = rows
+    .map(
+      row =>
+        `<tr>${row.map(cell => `<td>${escapeHtml(String(cell))}</td>`).join('')}</tr>`
+    )
+    .join('');
+  return `<table><thead><tr>${head}</tr></thead><tbody>${body}</tbody></table>`;
+}
+
+/**
+ * Renders the report as a standalone HTML page.
+ */
+export function formatIntegrityHtmlReport(
+  data: IntegrityHtmlReportData
+): string {
+  const { report, runs, worstLocations } = data;
+  const checkedAt = new Date(report.checkedAt).toISOString();
+  const checkNames = [
+    ...new Set([
+      ...report.checks.map(check => check.name),
+      ...runs.flatMap(run => run.checks.map(check => check.name)),
+      ...worstLocations.flatMap(location => Object.keys(location.byCheck)),
+    ]),
+  ];
+  const colourOf = (name: string) =>
+    CHECK_COLOURS[checkNames.indexOf(name) % CHECK_COLOURS.length];
+
+  const countIn = (run: IntegrityRunCounts, name: string) =>
+    run.checks.find(check => check.name === name)?.count ?? 0;
+  const trendByCheck = new Map(
+    (report.trend?.checks ?? []).map(check => [check.name, check])
+  );
+
+  const summaryRows = report.checks.map(check => {
+    const trend = trendByCheck.get(check.name);
+    const change =
+      trend && trend.previousCount !== null
+        ? check.count - trend.previousCount
+        : null;
+    return [
+      check.passed ? 'PASS' : 'FAIL',
+      check.name,
+      check.count,
+      check.threshold,
+      change === null ? '-' : `${change >= 0 ? '+' : ''}${change}`,
+      trend ? trend.newCount : '-',
+      trend ? trend.resolvedCount : '-',
+      trend ? trend.chronicCount : '-',
+    ];
+  });
+
+  const overTime = {
+    labels: runs.map(run => formatRunTime(run.checkedAt)),
+    datasets: checkNames.map(name => ({
+      label: name,
+      data: runs.map(run => countIn(run, name)),
+      backgroundColor: colourOf(name),
+    })),
+  };
+  const byLocation = {
+    labels: worstLocations.map(location => location.name),
+    datasets: checkNames
+      .filter(name => worstLocations.some(location => location.byCheck[name]))
+      .map(name => ({
+        label: name,
+        data: worstLocations.map(location => location.byCheck[name] ?? 0),
+        backgroundColor: colourOf(name),
+      })),
+  };
+
+  const status = report.passed ? 'passed' : 'FAILED';
+  return `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Data integrity ${status} - ${checkedAt}</title>
+  <script src="${CHART_JS_URL}"></script>
+  <style>
+    body { font-family: sans-serif; color: #1a202c; max-width: 1000px; margin: 0 auto; padding: 24px; }
+    h1 { color: ${report.passed ? '#2f855a' : '#c53030'}; }
+    h2 { margin-top: 40px; }
+    table { border-collapse: collapse; margin-top: 16px; color: #4a5568; }
+    th, td { padding: 4px 12px; border-bottom: 1px solid #e2e8f0; text-align: left; }
+    .muted { color: #718096; font-size: 14px; }
+  </style>
+</head>
+<body>
+  <h1>Data integrity ${status}</h1>
+  <p class="muted">Checked at ${checkedAt}${report.trend?.previousRunAt ? `, compared with the run at ${new Date(report.trend.previousRunAt).toISOString()}` : ''}.</p>
+  ${table(
+    [
+      'Result',
+      'Check',
+      'Findings',
+      'Threshold',
+      'Change',
+      'New',
+      'Resolved',
+      'Chronic',
+    ],
+    summaryRows
+  )}
+
+  <h2>Findings by check over the last ${runs.length} runs</h2>
+  <canvas id="over-time" height="120"></canvas>
+  ${table(
+    ['Run', ...checkNames],
+    runs.map(run => [
+      formatRunTime(run.checkedAt),
+      ...checkNames.map(name => countIn(run, name)),
+    ])
+  )}
+
+  <h2>Locations with the most open issues</h2>
+  ${
+    worstLocations.length === 0
+      ? '<p class="muted">No open or investigating integrity issues.</p>'
+      : `<canvas id="by-location" height="120"></canvas>
+  ${table(
+    ['Location', 'Open issues'],
+    worstLocations.map(location => [location.name, location.count])
+  )}`
+  }
+
+  <script>
+    if (window.Chart) {
+      const stacked = {
+        responsive: true,
+        scales: { x: { stacked: true }, y: { stacked: true, beginAtZero: true } },
+      };
+      new Chart(document.getElementById('over-time'), {
+        type: 'bar',
+        data: ${scriptJson(overTime)},
+        options: stacked,
+      });
+      const byLocation = document.getElementById('by-location');
+      if (byLocation) {
+        new Chart(byLocation, {
+          type: 'bar',
+          data: ${scriptJson(byLocation)},
+          options: { ...stacked, indexAxis: 'y' },
+        });
+      }
+    }
+  </script>
+</body>
+</html>
+`;
+}
@@ -0,0 +1,348 @@
+/**
+ * Integrity Issue Workflow Helper
+ *
+ * Turns the findings the data integrity checks store in `integrityIssues`
+ * into a work queue: an issue is claimed (assigned to a user and moved to
+ * `investigating`), marked `fixed` once the data is corrected and `verified`
+ * after a later run confirms it, with comments along the way. Allowed moves:
+ *
+ *   open          -> investigating, confirmed, dismissed
+ *   confirmed     -> investigating, dismissed
+ *   investigating -> fixed, open (released), dismissed
+ *   fixed         -> verified, investigating (reopened)
+ *   verified      -> investigating (regressed)
+ *   dismissed     -> open
+ *
+ * Every move is appended to `statusHistory`, after the issue is copied to
+ * `documentBackups` (see writeBackups). Used by
+ * `/api/integrity-issues/[id]` and `bun run integrity -- issues`.
+ *
+ * @module app/api/lib/helpers/integrityIssues
+ */
+
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { IntegrityIssue } from '@/app/api/lib/models/integrityIssue';
+import UserModel from '@/app/api/lib/models/user';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+import type {
+  IntegrityIssueDocument,
+  IntegrityIssueStatus,
+} from '@shared/types';
+import { z } from 'zod';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const INTEGRITY_ISSUE_TRANSITIONS: Record<
+  IntegrityIssueStatus,
+  IntegrityIssueStatus[]
+> = {
+  open: ['investigating', 'confirmed', 'dismissed'],
+  confirmed: ['investigating', 'dismissed'],
+  investigating: ['fixed', 'open', 'dismissed'],
+  fixed: ['verified', 'investigating'],
+  verified: ['investigating'],
+  dismissed: ['open'],
+};
+
+/** Statuses shown as open issues on location and machine reports */
+export const ACTIVE_INTEGRITY_ISSUE_STATUSES: IntegrityIssueStatus[] = [
+  'open',
+  'investigating',
+];
+
+/** Moves that count as a review decision (set `reviewedBy` / `reviewedAt`) */
+const REVIEW_STATUSES: IntegrityIssueStatus[] = [
+  'confirmed',
+  'dismissed',
+  'fixed',
+  'verified',
+];
+
+const MAX_COMMENT_LENGTH = 2000;
+
+export const integrityIssueUpdateSchema = z
+  .object({
+    status: z
+      .enum([
+        'open',
+        'investigating',
+        'fixed',
+        'verified',
+        'confirmed',
+        'dismissed',
+      ])
+      .optional(),
+    /** Username to assign; null unassigns */
+    assignedTo: z.string().trim().min(1).max(100).nullable().optional(),
+    /** Recorded with the status change */
+    note: z.string().trim().max(MAX_COMMENT_LENGTH).optional(),
+    comment: z.string().trim().min(1).max(MAX_COMMENT_LENGTH).optional(),
+  })
+  .strict()
+  .refine(
+    input =>
+      input.status !== undefined ||
+      input.assignedTo !== undefined ||
+      input.comment !== undefined,
+    { message: 'Provide status, assignedTo or comment' }
+  );
+
+export type IntegrityIssueUpdate = z.infer<typeof integrityIssueUpdateSchema>;
+
+export type IntegrityIssueQueueFilters = {
+  statuses?: IntegrityIssueStatus[];
+  /** Username, or null for unassigned issues */
+  assignedTo?: string | null;
+  check?: string;
+  location?: string;
+  limit?: number;
+};
+
+// ============================================================================
+// Reads
+// ============================================================================
+
+export async function getIntegrityIssue(
+  id: string
+): Promise<IntegrityIssueDocument | null> {
+  return IntegrityIssue.findOne({ _id: id }).lean<IntegrityIssueDocument>();
+}
+
+/**
+ * Lists issues for the work queue, oldest detection first so the backlog is
+ * worked in order. Comments and history are left out.
+ */
+export async function listIntegrityIssueQueue(
+  filters: IntegrityIssueQueueFilters = {}
+): Promise<IntegrityIssueDocument[]> {
+  const query: Record<string, unknown> = {
+    status: { $in: filters.statuses ?? ACTIVE_INTEGRITY_ISSUE_STATUSES },
+  };
+  if (filters.assignedTo !== undefined) query.assignedTo = filters.assignedTo;
+  if (filters.check) query.check = filters.check;
+  if (filters.location) query.location = filters.location;
+
+  return IntegrityIssue.find(query, { comments: 0, statusHistory: 0 })
+    .sort({ detectedAt: 1 })
+    .limit(filters.limit ?? 50)
+    .lean<IntegrityIssueDocument[]>();
+}
+
+// ============================================================================
+// Workflow
+// ============================================================================
+
+/**
+ * Moves an issue to another status. Moving to `investigating` assigns the
+ * issue to `by` when nobody has it; releasing it back to `open` clears the
+ * assignment.
+ *
+ * @throws Error with `statusCode` 404 (unknown issue), 409 (move not allowed
+ * or the issue changed meanwhile)
+ */
+export async function transitionIntegrityIssue(
+  id: string,
+  to: IntegrityIssueStatus,
+  { by, note }: { by: string; note?: string }
+): Promise<IntegrityIssueDocument> {
+  assertWritable('updating integrity issues');
+
+  const issue = await getIntegrityIssue(id);
+  if (!issue) throw statusError('Integrity issue not found', 404);
+  const from = issue.status;
+  if (!INTEGRITY_ISSUE_TRANSITIONS[from].includes(to)) {
+    const allowed = INTEGRITY_ISSUE_TRANSITIONS[from];
+    throw statusError(
+      `Cannot move an issue from ${from} to ${to}; allowed: ${allowed.join(', ') || 'none'}`,
+      409
+    );
+  }
+
+  await backupBeforeWrite({
+    operation: 'integrity-issue-status',
+    collection: IntegrityIssue.collection.collectionName,
+    filter: { _id: id },
+    by,
+  });
+  const now = new Date();
+  const set: Record<string, unknown> = { status: to };
+  if (REVIEW_STATUSES.includes(to)) {
+    set.reviewedBy = by;
+    set.reviewedAt = now;
+  }
+  if (to === 'investigating' && !issue.assignedTo) {
+    set.assignedTo = by;
+    set.assignedAt = now;
+  }
+  if (to === 'open') {
+    set.assignedTo = null;
+    set.assignedAt = null;
+  }
+
+  // Conditional on the status read above, so concurrent moves cannot both win
+  const updated = await IntegrityIssue.findOneAndUpdate(
+    { _id: id, status: from },
+    {
+      $set: set,
+      $push: {
+        statusHistory: { from, to, by, at: now, note: note || null },
+      },
+    },
+    { new: true }
+  ).lean<IntegrityIssueDocument>();
+  if (!updated) {
+    throw statusError('The issue changed meanwhile; reload and retry', 409);
+  }
+  return updated;
+}
+
+/**
+ * Claims an issue for `username`: assigns it and moves it to
+ * `investigating`. Refused when someone else is already investigating it.
+ */
+export async function claimIntegrityIssue(
+  id: string,
+  username: string
+): Promise<IntegrityIssueDocument> {
+  const issue = await getIntegrityIssue(id);
+  if (!issue) throw statusError('Integrity issue not found', 404);
+  if (
+    issue.status === 'investigating' &&
+    issue.assignedTo &&
+    issue.assignedTo !== username
+  ) {
+    throw statusError(`Already claimed by ${issue.assignedTo}`, 409);
+  }
+  if (
+    issue.status !== 'investigating' &&
+    !INTEGRITY_ISSUE_TRANSITIONS[issue.status].includes('investigating')
+  ) {
+    throw statusError(`Cannot claim an issue that is ${issue.status}`, 409);
+  }
+
+  const assigned = await assignIntegrityIssue(id, username);
+  if (issue.status === 'investigating') return assigned;
+  return transitionIntegrityIssue(id, 'investigating', { by: username });
+}
+
+/**
+ * Assigns an issue to a user (by username), or unassigns it with null.
+ *
+ * @throws Error with `statusCode` 400 for an unknown user, 404 for an
+ * unknown issue
+ */
+export async function assignIntegrityIssue(
+  id: string,
+  assignee: string | null
+): Promise<IntegrityIssueDocument> {
+  assertWritable('updating integrity issues');
+
+  if (assignee && !(await UserModel.exists({ username: assignee }))) {
+    throw statusError(`User '${assignee}' not found`, 400);
+  }
+  const updated = await IntegrityIssue.findOneAndUpdate(
+    { _id: id },
+    {
+      $set: {
+        assignedTo: assignee,
+        assignedAt: assignee ? new Date() : null,
+      },
+    },
+    { new: true }
+  ).lean<IntegrityIssueDocument>();
+  if (!updated) throw statusError('Integrity issue not found', 404);
+  return updated;
+}
+
+export async function addIntegrityIssueComment(
+  id: string,
+  author: string,
+  body: string
+): Promise<IntegrityIssueDocument> {
+  assertWritable('commenting on integrity issues');
+
+  const text = body.trim();
+  if (!text) throw statusError('Comment is empty', 400);
+  if (text.length > MAX_COMMENT_LENGTH) {
+    throw statusError(
+      `Comment is longer than ${MAX_COMMENT_LENGTH} characters`,
+      400
+    );
+  }
+
+  const updated = await IntegrityIssue.findOneAndUpdate(
+    { _id: id },
+    {
+      $push: {
+        comments: {
+          _id: await generateMongoId(),
+          author,
+          body: text,
+          createdAt: new Date(),
+        },
+      },
+    },
+    { new: true }
+  ).lean<IntegrityIssueDocument>();
+  if (!updated) throw statusError('Integrity issue not found', 404);
+  return updated;
+}
+
+/**
+ * Applies an API update in order: assignment, status move, comment.
+ *
+ * @param by - Username of the caller
+ */
+export async function updateIntegrityIssue(
+  id: string,
+  update: IntegrityIssueUpdate,
+  by: string
+): Promise<IntegrityIssueDocument> {
+  let issue = await getIntegrityIssue(id);
+  if (!issue) throw statusError('Integrity issue not found', 404);
+
+  if (update.assignedTo !== undefined) {
+    issue = await assignIntegrityIssue(id, update.assignedTo);
+  }
+  if (update.status && update.status !== issue.status) {
+    issue = await transitionIntegrityIssue(id, update.status, {
+      by,
+      note: update.note,
+    });
+  }
+  if (update.comment) {
+    issue = await addIntegrityIssueComment(id, by, update.comment);
+  }
+  return issue;
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/**
+ * Formats one issue with its history and comments for the command line.
+ */
+export function formatIntegrityIssue(issue: IntegrityIssueDocument): string {
+  const lines = [
+    `${issue._id}  ${issue.check}  ${issue.status}${issue.assignedTo ? ` (${issue.assignedTo})` : ''}`,
+    `  ${issue.resourceType} ${issue.resourceId}${issue.location ? `  location ${issue.location}` : ''}`,
+    `  detected ${new Date(issue.detectedAt).toISOString()}`,
+  ];
+  if (issue.details) lines.push(`  ${issue.details}`);
+  (issue.statusHistory ?? []).forEach(change => {
+    lines.push(
+      `  ${new Date(change.at).toISOString()}  ${change.from} -> ${change.to} by ${change.by}${change.note ? `: ${change.note}` : ''}`
+    );
+  });
+  (issue.comments ?? []).forEach(comment => {
+    lines.push(
+      `  ${new Date(comment.createdAt).toISOString()}  ${comment.author}: ${comment.body}`
+    );
+  });
+  return lines.join('\n');
+}
@@ -0,0 +1,244 @@
+/**
+ * Integrity Run Trends
+ *
+ * Stores each `integrity` run (counts plus the ids of its findings) in
+ * `integrityRuns` and compares it with earlier runs of the same checks, so the
+ * nightly report shows whether data quality is improving:
+ * - new — findings that were not in the previous run of the check
+ * - resolved — findings of the previous run that are gone
+ * - chronic — findings present in each of the last `chronicRuns` runs
+ *
+ * Finding ids are capped at MAX_TRACKED_ISSUE_KEYS per check; a comparison
+ * involving a truncated run is marked `truncated` and is approximate.
+ *
+ * @module app/api/lib/helpers/integrityTrends
+ */
+
+import type {
+  IntegrityCheckName,
+  IntegrityReport,
+} from '@/app/api/lib/helpers/dataIntegrity';
+import { IntegrityRun } from '@/app/api/lib/models/integrityRun';
+import { isReadOnlyMode } from '@/app/api/lib/utils/safetyMode';
+import { generateMongoId } from '@/lib/utils/id';
+import type { IntegrityRunCheck, IntegrityRunDocument } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+/** Finding ids stored per check and run */
+export const MAX_TRACKED_ISSUE_KEYS = 5000;
+
+export type IntegrityCheckTrend = {
+  name: IntegrityCheckName;
+  count: number;
+  /** Count in the previous run of the check; null on its first tracked run */
+  previousCount: number | null;
+  newCount: number;
+  resolvedCount: number;
+  chronicCount: number;
+  /** First `sampleSize` ids of each list */
+  newSample: string[];
+  resolvedSample: string[];
+  chronicSample: string[];
+  truncated: boolean;
+};
+
+export type IntegrityTrend = {
+  previousRunAt: Date | null;
+  chronicRuns: number;
+  recorded: boolean;
+  checks: IntegrityCheckTrend[];
+};
+
+export type IntegrityRunSummary = Omit<IntegrityRunDocument, 'checks'> & {
+  checks: Array<Omit<IntegrityRunCheck, 'keys'>>;
+};
+
+type PreviousRun = Pick<IntegrityRunDocument, 'checkedAt'> & {
+  checks: IntegrityRunCheck[];
+};
+
+// ============================================================================
+// Comparison
+// ============================================================================
+
+/**
+ * Compares one check's findings with the same check in earlier runs.
+ *
+ * @param previousRuns - Earlier runs of the check, newest first
+ */
+function compareCheck(
+  current: IntegrityRunCheck,
+  previousRuns: PreviousRun[],
+  chronicRuns: number,
+  sampleSize: number
+): IntegrityCheckTrend {
+  const previous = previousRuns[0]?.checks[0];
+  const previousKeys = new Set(previous?.keys ?? []);
+  const currentKeys = new Set(current.keys);
+
+  const added = current.keys.filter(key => !previousKeys.has(key));
+  const resolved = (previous?.keys ?? []).filter(key => !currentKeys.has(key));
+
+  // Chronic needs the current run plus chronicRuns - 1 earlier runs
+  const history = previousRuns
+    .slice(0, Math.max(chronicRuns - 1, 0))
+    .map(run => new Set(run.checks[0]?.keys ?? []));
+  const chronic =
+    history.length < chronicRuns - 1
+      ? []
+      : current.keys.filter(key => history.every(keySet => keySet.has(key)));
+
+  return {
+    name: current.name as IntegrityCheckName,
+    count: current.count,
+    previousCount: previous ? previous.count : null,
+    newCount: added.length,
+    resolvedCount: resolved.length,
+    chronicCount: chronic.length,
+    newSample: added.slice(0, sampleSize),
+    resolvedSample: resolved.slice(0, sampleSize),
+    chronicSample: chronic.slice(0, sampleSize),
+    truncated:
+      current.truncated ||
+      previousRuns
+        .slice(0, Math.max(chronicRuns - 1, 1))
+        .some(run => run.checks[0]?.truncated),
+  };
+}
+
+/**
+ * Compares a report with the earlier runs of its checks, then stores it in
+ * `integrityRuns` (skipped in read-only mode).
+ *
+ * @param report - Report of the run that just finished
+ * @param keys - Finding ids per check
+ * @returns The run-over-run comparison
+ */
+export async function recordIntegrityRun(
+  report: IntegrityReport,
+  keys: Partial<Record<IntegrityCheckName, string[]>>,
+  {
+    chronicRuns,
+    sampleSize,
+  }: {
+    chronicRuns: number;
+    sampleSize: number;
+  }
+): Promise<IntegrityTrend> {
+  const runChecks: IntegrityRunCheck[] = report.checks.map(check => {
+    const checkKeys = keys[check.name] ?? [];
+    return {
+      name: check.name,
+      count: check.count,
+      threshold: check.threshold,
+      passed: check.passed,
+      keys: checkKeys,
+      truncated: check.count > checkKeys.length,
+    };
+  });
+
+  const previousRunsByCheck = await Promise.all(
+    runChecks.map(check =>
+      IntegrityRun.find(
+        { 'checks.name': check.name },
+        { checkedAt: 1, checks: { $elemMatch: { name: check.name } } }
+      )
+        .sort({ checkedAt: -1 })
+        .limit(Math.max(chronicRuns - 1, 1))
+        .lean<PreviousRun[]>()
+    )
+  );
+  const checks = runChecks.map((check, index) =>
+    compareCheck(check, previousRunsByCheck[index], chronicRuns, sampleSize)
+  );
+  const previousRunTimes = previousRunsByCheck
+    .map(runs => runs[0]?.checkedAt)
+    .filter((value): value is Date => Boolean(value))
+    .map(value => new Date(value).getTime());
+  const previousRunAt =
+    previousRunTimes.length > 0
+      ? new Date(Math.max(...previousRunTimes))
+      : null;
+
+  let recorded = false;
+  if (isReadOnlyMode()) {
+    console.warn(
+      '[integrityTrends] Read-only mode is on; integrity run not recorded'
+    );
+  } else {
+    await IntegrityRun.create({
+      _id: await generateMongoId(),
+      checkedAt: report.checkedAt,
+      passed: report.passed,
+      checks: runChecks,
+    });
+    recorded = true;
+  }
+
+  return { previousRunAt, chronicRuns, recorded, checks };
+}
+
+/**
+ * Lists the most recent integrity runs without their finding ids.
+ *
+ * @param limit - Number of runs, newest first
+ */
+export async function getIntegrityRunHistory(
+  limit: number
+): Promise<IntegrityRunSummary[]> {
+  return IntegrityRun.find({}, { 'checks.keys': 0 })
+    .sort({ checkedAt: -1 })
+    .limit(limit)
+    .lean<IntegrityRunSummary[]>();
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/**
+ * Formats a trend as one line per check plus samples of the new and chronic
+ * findings.
+ */
+export function formatIntegrityTrend(trend: IntegrityTrend): string {
+  const since = trend.previousRunAt
+    ? `since ${new Date(trend.previousRunAt).toISOString()}`
+    : 'first tracked run';
+  const lines = [`Trend ${since} (chronic = ${trend.chronicRuns} runs):`];
+  trend.checks.forEach(check => {
+    const change =
+      check.previousCount === null ? null : check.count - check.previousCount;
+    const delta =
+      change === null ? 'new check' : `${change >= 0 ? '+' : ''}${change}`;
+    lines.push(
+      `  ${check.name}: ${check.count} (${delta}), ${check.newCount} new, ${check.resolvedCount} resolved, ${check.chronicCount} chronic${check.truncated ? ' (approximate)' : ''}`
+    );
+    if (check.previousCount !== null && check.newSample.length > 0) {
+      lines.push(`    new: ${check.newSample.join(', ')}`);
+    }
+    if (check.chronicSample.length > 0) {
+      lines.push(`    chronic: ${check.chronicSample.join(', ')}`);
+    }
+  });
+  return lines.join('\n');
+}
+
+/**
+ * Formats run history as one line per run with the count of each check.
+ */
+export function formatIntegrityRunHistory(
+  runs: IntegrityRunSummary[]
+): string {
+  if (runs.length === 0) return 'No integrity runs recorded';
+  return runs
+    .map(run => {
+      const counts = run.checks
+        .map(check => `${check.name}=${check.count}`)
+        .join(' ');
+      return `${new Date(run.checkedAt).toISOString()}  ${run.passed ? 'PASS' : 'FAIL'}  ${counts}`;
+    })
+    .join('\n');
+}
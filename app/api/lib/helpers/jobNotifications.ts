@@ -0,0 +1,190 @@
+/**
+ * Job Notifications Helper
+ *
+ * Tells someone when a long-running job finishes instead of leaving them to
+ * watch the terminal. Migrations, detection runs and pre-aggregation backfills
+ * call `notifyJobFinished()` on completion or failure; it posts to every
+ * configured target:
+ *
+ * - `JOB_WEBHOOK_URL`   — generic JSON payload (the `JobNotification` itself)
+ * - `SLACK_WEBHOOK_URL` — Slack incoming webhook (`text` plus blocks)
+ *
+ * Both resolve through `getSecret()`, so `_FILE` / `_SECRET` references work.
+ * A notification that cannot be delivered is logged and never fails the job.
+ *
+ * @module app/api/lib/helpers/jobNotifications
+ */
+
+import { writeFile } from 'fs/promises';
+import { resolve } from 'path';
+import { getSecret } from '@/app/api/lib/utils/secrets';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type JobKind = 'migration' | 'detection' | 'pre-aggregation';
+
+export type JobNotification = {
+  /** Command or job name, e.g. 'integrity' or 'metersDaily-backfill' */
+  job: string;
+  kind: JobKind;
+  status: 'completed' | 'failed';
+  /** Database profile or range the job ran against */
+  target?: string;
+  startedAt: Date;
+  finishedAt: Date;
+  /** Summary counts, e.g. `{ failedChecks: 2, documents: 1200 }` */
+  counts: Record<string, number>;
+  /** Path or URL of the full report */
+  report?: string;
+  /** One-line outcome shown above the counts */
+  summary?: string;
+  error?: string;
+};
+
+const NOTIFY_TIMEOUT_MS = 10_000;
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/**
+ * Plain-text summary, used for logs and as the Slack fallback text.
+ */
+export function formatJobNotification(notification: JobNotification): string {
+  const seconds = Math.round(
+    (notification.finishedAt.getTime() - notification.startedAt.getTime()) /
+      1000
+  );
+  const counts = Object.entries(notification.counts)
+    .map(([name, value]) => `${name}=${value}`)
+    .join(' ');
+  return [
+    `${notification.status === 'completed' ? '✅' : '❌'} ${notification.kind} ${notification.job} ${notification.status}${
+      notification.target ? ` on ${notification.target}` : ''
+    } in ${seconds}s`,
+    notification.summary,
+    counts,
+    notification.error ? `Error: ${notification.error}` : '',
+    notification.report ? `Report: ${notification.report}` : '',
+  ]
+    .filter(Boolean)
+    .join('\n');
+}
+
+/**
+ * Slack incoming webhook payload: a header, the counts as fields and the
+ * report link or path.
+ */
+export function formatSlackJobMessage(
+  notification: JobNotification
+): Record<string, unknown> {
+  const text = formatJobNotification(notification);
+  const [headline] = text.split('\n');
+  const fields = Object.entries(notification.counts).map(([name, value]) => ({
+    type: 'mrkdwn',
+    text: `*${name}*\n${value}`,
+  }));
+  const details = [
+    notification.summary,
+    notification.error ? `*Error:* ${notification.error}` : '',
+    notification.report
+      ? /^https?:\/\//.test(notification.report)
+        ? `<${notification.report}|Full report>`
+        : `*Report:* \`${notification.report}\``
+      : '',
+  ].filter(Boolean);
+
+  return {
+    text,
+    blocks: [
+      { type: 'section', text: { type: 'mrkdwn', text: `*${headline}*` } },
+      // Slack allows at most 10 fields per section
+      ...(fields.length > 0
+        ? [{ type: 'section', fields: fields.slice(0, 10) }]
+        : []),
+      ...(details.length > 0
+        ? [
+            {
+              type: 'context',
+              elements: [{ type: 'mrkdwn', text: details.join('\n') }],
+            },
+          ]
+        : []),
+    ],
+  };
+}
+
+// ============================================================================
+// Delivery
+// ============================================================================
+
+async function post(url: string, payload: unknown): Promise<void> {
+  const response = await fetch(url, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(payload),
+    signal: AbortSignal.timeout(NOTIFY_TIMEOUT_MS),
+  });
+  if (!response.ok) {
+    throw new Error(`HTTP ${response.status}`);
+  }
+}
+
+/**
+ * Posts a finished job to the generic and Slack webhooks, whichever are
+ * configured. Never throws; delivery failures are logged as warnings.
+ *
+ * @param notification - Job outcome
+ * @returns Number of targets that accepted the notification
+ */
+export async function notifyJobFinished(
+  notification: JobNotification
+): Promise<number> {
+  const [webhookUrl, slackUrl] = await Promise.all([
+    getSecret('JOB_WEBHOOK_URL').catch(() => undefined),
+    getSecret('SLACK_WEBHOOK_URL').catch(() => undefined),
+  ]);
+  const targets: Array<{ name: string; url: string; payload: unknown }> = [];
+  if (webhookUrl) {
+    targets.push({ name: 'webhook', url: webhookUrl, payload: notification });
+  }
+  if (slackUrl) {
+    targets.push({
+      name: 'Slack',
+      url: slackUrl,
+      payload: formatSlackJobMessage(notification),
+    });
+  }
+
+  const results = await Promise.allSettled(
+    targets.map(target => post(target.url, target.payload))
+  );
+  results.forEach((result, index) => {
+    if (result.status === 'rejected') {
+      const reason = result.reason as unknown;
+      console.warn(
+        `[jobNotifications] ${targets[index].name} notification for ${notification.job} failed:`,
+        reason instanceof Error ? reason.message : reason
+      );
+    }
+  });
+  return results.filter(result => result.status === 'fulfilled').length;
+}
+
+/**
+ * Writes a job's report as JSON so the notification can point at it.
+ *
+ * @param path - File to write (relative to the working directory)
+ * @param report - Report object
+ * @returns The absolute path written
+ */
+export async function writeJobReport(
+  path: string,
+  report: unknown
+): Promise<string> {
+  const absolutePath = resolve(path);
+  await writeFile(absolutePath, JSON.stringify(report, null, 2));
+  return absolutePath;
+}
@@ -1,8 +1,17 @@
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import { User } from '@/lib/types/administration';
 import { connectDB } from '../middleware/db';
 import { GamingLocations } from '../models/gaminglocations';
 import { Licencee } from '../models/licencee';
 import UserModel from '../models/user';
+import {
+  getPinnedLicencee,
+  restrictLocationsToTenant,
+  scopeLicenceesToTenant,
+} from '../utils/tenantScope';
 import { getUserFromServer } from './users';
 import type {
   UserDocument,
@@ -14,10 +23,20 @@ import type {
  * Gets the licencees a user can access from JWT token
  * - Returns 'all' for admin/developer
  * - Returns array of licencee IDs for non-admins
+ * - In tenant isolation mode, at most the pinned licencee (see tenantScope)
  */
 export async function getUserAccessibleLicenceesFromToken(userPayloadOverride?: {
   assignedLicencees?: string[];
   roles?: string[];
+}): Promise<string[] | 'all'> {
+  return scopeLicenceesToTenant(
+    await resolveUserAccessibleLicencees(userPayloadOverride)
+  );
+}
+
+async function resolveUserAccessibleLicencees(userPayloadOverride?: {
+  assignedLicencees?: string[];
+  roles?: string[];
 }): Promise<string[] | 'all'> {
   try {
     const userPayload = userPayloadOverride || (await getUserFromServer());
@@ -166,13 +185,8 @@ async function getLicenceeLocationFilter(
   }
 
   const deletionFilter = showArchived
-    ? { deletedAt: { $gte: new Date('2025-01-01') } }
-    : {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      };
+    ? { ...DELETED_FILTER }
+    : { ...NOT_DELETED_FILTER };
 
   const locations = await GamingLocations.find(
     {
@@ -205,6 +219,7 @@ async function getLicenceeLocationFilter(
  * @param {User['roles']} [userRoles] - User's roles (to determine if they're a manager)
  * @param {boolean} [showArchived=false] - Include archived (soft-deleted) locations in results
  * @returns {Promise<string[] | 'all'>} Array of location IDs or 'all' for admins with no restrictions
+ *   (in tenant isolation mode, never 'all' and only the pinned licencee's locations)
  */
 export async function getUserLocationFilter(
   userAccessibleLicencees: 'all' | string[],
@@ -212,6 +227,31 @@ export async function getUserLocationFilter(
   userLocationPermissions: string[],
   userRoles: User['roles'] = [],
   showArchived = false
+): Promise<string[] | 'all'> {
+  // In tenant mode admins arrive with [pinned] instead of 'all'; keep their
+  // admin behaviour and let the tenant restriction do the narrowing
+  const isTenantAdmin =
+    getPinnedLicencee() !== null &&
+    (userRoles.includes('admin') ||
+      userRoles.includes('developer') ||
+      userRoles.includes('owner'));
+
+  const locationIds = await resolveUserLocationFilter(
+    isTenantAdmin ? 'all' : userAccessibleLicencees,
+    selectedLicenceeFilter,
+    userLocationPermissions,
+    userRoles,
+    showArchived
+  );
+  return restrictLocationsToTenant(locationIds);
+}
+
+async function resolveUserLocationFilter(
+  userAccessibleLicencees: 'all' | string[],
+  selectedLicenceeFilter: string | undefined,
+  userLocationPermissions: string[],
+  userRoles: User['roles'] = [],
+  showArchived = false
 ): Promise<string[] | 'all'> {
   if (!userAccessibleLicencees) {
     console.error(
@@ -291,13 +331,8 @@ export async function getUserLocationFilter(
     // Now query locations by licencee ID
     // rel.licencee is stored as a String, so we can query directly
     const specificLicenceeDeletionFilter = showArchived
-      ? { deletedAt: { $gte: new Date('2025-01-01') } }
-      : {
-          $or: [
-            { deletedAt: null },
-            { deletedAt: { $lt: new Date('2025-01-01') } },
-          ],
-        };
+      ? { ...DELETED_FILTER }
+      : { ...NOT_DELETED_FILTER };
 
     const locations = await GamingLocations.find(
       {
@@ -0,0 +1,531 @@
+/**
+ * Licencee Management Helper
+ *
+ * Creates, updates and deactivates licencees outside the web UI, so they are
+ * no longer edited by hand in the database. Every write goes through the same
+ * checks:
+ *
+ * - `licenceKey` is trimmed, upper-cased and never empty; it must be unique
+ *   across all licencees (including deleted ones). A new licencee without a
+ *   key gets a generated one.
+ * - `country` is resolved by id or name and must exist.
+ * - names are unique among licencees that are not deleted.
+ *
+ * `findLicenceeKeyProblems()` / `repairLicenceeKeys()` find and fix existing
+ * licencees with a missing, empty or placeholder key (the seed data's
+ * `LIC-CABANA-AUTO-GEN`). Each change is written to the activity log.
+ *
+ * Used by the `licencees` command (scripts/licencees.ts).
+ *
+ * @module app/api/lib/helpers/licenceeManagement
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { Countries } from '@/app/api/lib/models/countries';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { generateUniqueLicenceKey } from '@/app/api/lib/utils/licenceKey';
+import {
+  LICENCE_KEY_PLACEHOLDER,
+} from '@/app/api/lib/utils/migrationTransforms';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+import type { LicenceeDocument } from '@shared/types';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type LicenceeInput = {
+  name?: string;
+  /** Country id or name */
+  country?: string;
+  licenceKey?: string;
+  jurisdiction?: string | null;
+  contactName?: string | null;
+  contactEmail?: string | null;
+  contactPhone?: string | null;
+  startDate?: Date;
+  expiryDate?: Date | null;
+  description?: string;
+};
+
+export type LicenceeActor = {
+  userId: string;
+  username: string;
+};
+
+export type LicenceeKeyProblem = {
+  licenceeId: string;
+  name: string;
+  licenceKey: string | null;
+  problem: 'missing' | 'placeholder' | 'duplicate';
+};
+
+type LicenceeChange = {
+  field: string;
+  oldValue: unknown;
+  newValue: unknown;
+};
+
+const EMAIL_PATTERN = /^[^\s@]+@[^\s@]+\.[^\s@]+$/;
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+/**
+ * Trims and upper-cases a licence key.
+ *
+ * @throws Error with `statusCode` 400 when the key is empty
+ */
+export function normalizeLicenceKey(value: string): string {
+  const key = value.trim().toUpperCase();
+  if (!key) throw statusError('licenceKey cannot be empty', 400);
+  if (key === LICENCE_KEY_PLACEHOLDER) {
+    throw statusError(`${LICENCE_KEY_PLACEHOLDER} is a placeholder key`, 400);
+  }
+  return key;
+}
+
+async function assertLicenceKeyAvailable(
+  licenceKey: string,
+  exceptId?: string
+): Promise<void> {
+  const existing = await Licencee.findOne(
+    {
+      licenceKey,
+      ...(exceptId ? { _id: { $ne: exceptId } } : {}),
+    },
+    { _id: 1, name: 1 }
+  ).lean<{ _id: string; name: string } | null>();
+  if (existing) {
+    throw statusError(
+      `licenceKey ${licenceKey} is already used by ${existing.name} (${existing._id})`,
+      409
+    );
+  }
+}
+
+async function assertNameAvailable(
+  name: string,
+  exceptId?: string
+): Promise<void> {
+  const existing = await Licencee.findOne(
+    {
+      name,
+      ...NOT_DELETED_FILTER,
+      ...(exceptId ? { _id: { $ne: exceptId } } : {}),
+    },
+    { _id: 1 }
+  ).lean<{ _id: string } | null>();
+  if (existing) {
+    throw statusError(`A licencee named '${name}' already exists`, 409);
+  }
+}
+
+async function resolveCountryId(country: string): Promise<string> {
+  const match = await Countries.findOne(
+    { $or: [{ _id: country }, { name: country }] },
+    { _id: 1 }
+  ).lean<{ _id: string } | null>();
+  if (!match) throw statusError(`Country not found: ${country}`, 404);
+  return String(match._id);
+}
+
+function cleanOptional(value: string | null | undefined): string | undefined {
+  return value?.trim() || undefined;
+}
+
+function validateContact(input: LicenceeInput): void {
+  const email = cleanOptional(input.contactEmail);
+  if (email && !EMAIL_PATTERN.test(email)) {
+    throw statusError(`Invalid contact email: ${email}`, 400);
+  }
+}
+
+async function logLicenceeChange(
+  action: 'create' | 'update',
+  licencee: { _id: string; name: string },
+  changes: LicenceeChange[],
+  details: string,
+  actor: LicenceeActor
+): Promise<void> {
+  try {
+    await logActivity({
+      action,
+      details,
+      userId: actor.userId,
+      username: actor.username,
+      metadata: {
+        resource: 'licencee',
+        resourceId: licencee._id,
+        resourceName: licencee.name,
+        changes,
+      },
+    });
+  } catch (logError) {
+    console.error('Failed to log activity:', logError);
+  }
+}
+
+// ============================================================================
+// Lookup
+// ============================================================================
+
+/**
+ * Finds a licencee by id or exact name.
+ *
+ * @throws Error with `statusCode` 404 when none matches, 409 when a name
+ * matches several
+ */
+export async function resolveLicencee(
+  idOrName: string
+): Promise<LicenceeDocument> {
+  const byId = await Licencee.findOne({
+    _id: idOrName,
+  }).lean<LicenceeDocument | null>();
+  if (byId) return byId;
+
+  const byName = await Licencee.find({
+    name: idOrName,
+  }).lean<LicenceeDocument[]>();
+  if (byName.length === 0) {
+    throw statusError(`Licencee not found: ${idOrName}`, 404);
+  }
+  if (byName.length > 1) {
+    throw statusError(
+      `Several licencees are named '${idOrName}'; use the id (${byName
+        .map(licencee => licencee._id)
+        .join(', ')})`,
+      409
+    );
+  }
+  return byName[0];
+}
+
+// ============================================================================
+// Create / Update / Deactivate
+// ============================================================================
+
+/**
+ * Creates a licencee.
+ *
+ * @param input - Name and country are required; a key is generated if omitted
+ * @param actor - Acting user for the activity log
+ * @returns The new licencee
+ * @throws Error with `statusCode` 400 (invalid input), 404 (country not
+ * found) or 409 (name or key already used)
+ */
+export async function createLicenceeRecord(
+  input: LicenceeInput,
+  actor: LicenceeActor
+): Promise<LicenceeDocument> {
+  const name = input.name?.trim();
+  if (!name) throw statusError('name is required', 400);
+  if (!input.country) throw statusError('country is required', 400);
+  validateContact(input);
+  assertWritable('creating a licencee');
+
+  const country = await resolveCountryId(input.country);
+  await assertNameAvailable(name);
+  let licenceKey: string;
+  if (input.licenceKey !== undefined) {
+    licenceKey = normalizeLicenceKey(input.licenceKey);
+    await assertLicenceKeyAvailable(licenceKey);
+  } else {
+    licenceKey = await generateUniqueLicenceKey();
+  }
+
+  const startDate = input.startDate ?? new Date();
+  let expiryDate = input.expiryDate;
+  if (expiryDate === undefined) {
+    expiryDate = new Date(startDate);
+    expiryDate.setDate(expiryDate.getDate() + 30);
+  }
+
+  const created = await Licencee.create({
+    _id: await generateMongoId(),
+    name,
+    description: input.description?.trim() || '',
+    country,
+    licenceKey,
+    jurisdiction: cleanOptional(input.jurisdiction),
+    contact: {
+      name: cleanOptional(input.contactName),
+      email: cleanOptional(input.contactEmail),
+      phone: cleanOptional(input.contactPhone),
+    },
+    startDate,
+    expiryDate,
+    status: 'active',
+    deletedAt: null,
+  });
+  const licencee = created.toObject() as LicenceeDocument;
+
+  await logLicenceeChange(
+    'create',
+    licencee,
+    ['name', 'country', 'licenceKey', 'jurisdiction'].map(field => ({
+      field,
+      oldValue: null,
+      newValue: (licencee as Record<string, unknown>)[field] ?? null,
+    })),
+    `Created licencee "${name}"`,
+    actor
+  );
+  return licencee;
+}
+
+/**
+ * Updates a licencee's details. Fields left undefined are unchanged; null
+ * clears an optional field.
+ *
+ * @param idOrName - Licencee id or name
+ * @param input - Fields to change
+ * @param actor - Acting user for the activity log
+ * @returns The updated licencee and the changes made
+ * @throws Error with `statusCode` 400, 404 or 409 as for create
+ */
+export async function updateLicenceeRecord(
+  idOrName: string,
+  input: LicenceeInput,
+  actor: LicenceeActor
+): Promise<{ licencee: LicenceeDocument; changes: LicenceeChange[] }> {
+  validateContact(input);
+  const current = await resolveLicencee(idOrName);
+  const update: Record<string, unknown> = {};
+
+  if (input.name !== undefined) {
+    const name = input.name.trim();
+    if (!name) throw statusError('name cannot be empty', 400);
+    if (name !== current.name) {
+      await assertNameAvailable(name, current._id);
+      update.name = name;
+    }
+  }
+  if (input.country !== undefined) {
+    const country = await resolveCountryId(input.country);
+    if (country !== current.country) update.country = country;
+  }
+  if (input.licenceKey !== undefined) {
+    const licenceKey = normalizeLicenceKey(input.licenceKey);
+    if (licenceKey !== current.licenceKey) {
+      await assertLicenceKeyAvailable(licenceKey, current._id);
+      update.licenceKey = licenceKey;
+    }
+  }
+  if (input.description !== undefined) {
+    update.description = input.description.trim();
+  }
+  if (input.jurisdiction !== undefined) {
+    update.jurisdiction = cleanOptional(input.jurisdiction) ?? null;
+  }
+  const contactFields = {
+    name: input.contactName,
+    email: input.contactEmail,
+    phone: input.contactPhone,
+  };
+  Object.entries(contactFields).forEach(([field, value]) => {
+    if (value !== undefined) {
+      update[`contact.${field}`] = cleanOptional(value) ?? null;
+    }
+  });
+  if (input.startDate !== undefined) {
+    if (current.startDate) update.prevStartDate = current.startDate;
+    update.startDate = input.startDate;
+  }
+  if (input.expiryDate !== undefined) {
+    if (current.expiryDate) update.prevExpiryDate = current.expiryDate;
+    update.expiryDate = input.expiryDate;
+  }
+
+  const readCurrent = (field: string): unknown =>
+    field
+      .split('.')
+      .reduce<unknown>(
+        (value, part) =>
+          value && typeof value === 'object'
+            ? (value as Record<string, unknown>)[part]
+            : undefined,
+        current
+      ) ?? null;
+  const changes = Object.entries(update)
+    .filter(([field]) => !field.startsWith('prev'))
+    .map(([field, newValue]) => ({
+      field,
+      oldValue: readCurrent(field),
+      newValue,
+    }))
+    .filter(change => String(change.oldValue) !== String(change.newValue));
+  if (changes.length === 0) return { licencee: current, changes };
+
+  assertWritable('updating a licencee');
+  const updated = await Licencee.findOneAndUpdate(
+    { _id: current._id },
+    { $set: { ...update, updatedAt: new Date() } },
+    { new: true }
+  ).lean<LicenceeDocument | null>();
+  if (!updated) throw statusError('Licencee not found', 404);
+
+  await logLicenceeChange(
+    'update',
+    updated,
+    changes,
+    `Updated licencee "${updated.name}"`,
+    actor
+  );
+  return { licencee: updated, changes };
+}
+
+/**
+ * Marks a licencee inactive. Its locations and data are kept; use the
+ * `delete` command to archive it instead.
+ *
+ * @param idOrName - Licencee id or name
+ * @param reason - Why it is being deactivated
+ * @param actor - Acting user for the activity log
+ * @returns The updated licencee, or null when it was already inactive
+ * @throws Error with `statusCode` 400 when no reason is given, 404 when not
+ * found
+ */
+export async function deactivateLicencee(
+  idOrName: string,
+  reason: string,
+  actor: LicenceeActor
+): Promise<LicenceeDocument | null> {
+  if (!reason.trim()) throw statusError('A reason is required', 400);
+  const current = await resolveLicencee(idOrName);
+  if (current.status === 'inactive') return null;
+
+  assertWritable('deactivating a licencee');
+  await backupBeforeWrite({
+    operation: 'licencee-deactivate',
+    collection: Licencee.collection.collectionName,
+    filter: { _id: current._id },
+    by: actor.username,
+  });
+  const updated = await Licencee.findOneAndUpdate(
+    { _id: current._id },
+    { $set: { status: 'inactive', updatedAt: new Date() } },
+    { new: true }
+  ).lean<LicenceeDocument | null>();
+  if (!updated) throw statusError('Licencee not found', 404);
+
+  await logLicenceeChange(
+    'update',
+    updated,
+    [
+      {
+        field: 'status',
+        oldValue: current.status ?? 'active',
+        newValue: 'inactive',
+      },
+    ],
+    `Deactivated licencee "${updated.name}": ${reason.trim()}`,
+    actor
+  );
+  return updated;
+}
+
+// ============================================================================
+// Licence key checks
+// ============================================================================
+
+/**
+ * Finds licencees whose key is missing, empty, the seed placeholder, or
+ * shared with another licencee.
+ */
+export async function findLicenceeKeyProblems(): Promise<
+  LicenceeKeyProblem[]
+> {
+  const licencees = await Licencee.find(
+    {},
+    { _id: 1, name: 1, licenceKey: 1 }
+  ).lean<Array<{ _id: string; name: string; licenceKey?: string | null }>>();
+
+  const byKey = new Map<string, number>();
+  licencees.forEach(licencee => {
+    const key = licencee.licenceKey?.trim().toUpperCase();
+    if (key) byKey.set(key, (byKey.get(key) || 0) + 1);
+  });
+
+  return licencees.flatMap(licencee => {
+    const key = licencee.licenceKey?.trim().toUpperCase() || '';
+    let problem: LicenceeKeyProblem['problem'] | null = null;
+    if (!key) problem = 'missing';
+    else if (key === LICENCE_KEY_PLACEHOLDER) problem = 'placeholder';
+    else if ((byKey.get(key) || 0) > 1) problem = 'duplicate';
+    return problem
+      ? [
+          {
+            licenceeId: String(licencee._id),
+            name: licencee.name,
+            licenceKey: licencee.licenceKey ?? null,
+            problem,
+          },
+        ]
+      : [];
+  });
+}
+
+/**
+ * Gives each licencee with a missing or placeholder key a generated one.
+ * Duplicates are reported only: which licencee keeps the key is a decision
+ * for an operator (`update --licence-key`).
+ *
+ * @returns Licencees that got a new key
+ */
+export async function repairLicenceeKeys(
+  problems: LicenceeKeyProblem[],
+  actor: LicenceeActor
+): Promise<Array<LicenceeKeyProblem & { newKey: string }>> {
+  const repairable = problems.filter(entry => entry.problem !== 'duplicate');
+  if (repairable.length === 0) return [];
+  assertWritable('repairing licence keys');
+
+  const repaired: Array<LicenceeKeyProblem & { newKey: string }> = [];
+  for (const entry of repairable) {
+    const newKey = await generateUniqueLicenceKey();
+    const result = await Licencee.updateOne(
+      { _id: entry.licenceeId, licenceKey: entry.licenceKey },
+      { $set: { licenceKey: newKey, updatedAt: new Date() } }
+    );
+    if (result.modifiedCount === 0) continue;
+    repaired.push({ ...entry, newKey });
+    await logLicenceeChange(
+      'update',
+      { _id: entry.licenceeId, name: entry.name },
+      [{ field: 'licenceKey', oldValue: entry.licenceKey, newValue: newKey }],
+      `Assigned a licence key to licencee "${entry.name}" (was ${entry.problem})`,
+      actor
+    );
+  }
+  return repaired;
+}
+
+/**
+ * One line per licencee for the terminal.
+ */
+export function formatLicencee(licencee: LicenceeDocument): string {
+  return [
+    `${licencee._id}  ${licencee.name}`,
+    `  status: ${licencee.status || 'active'}${
+      licencee.deletedAt ? ' (deleted)' : ''
+    }`,
+    `  licenceKey: ${licencee.licenceKey}`,
+    `  country: ${licencee.country || '-'}  jurisdiction: ${
+      licencee.jurisdiction || '-'
+    }`,
+    `  contact: ${
+      [licencee.contact?.name, licencee.contact?.email, licencee.contact?.phone]
+        .filter(Boolean)
+        .join(', ') || '-'
+    }`,
+    `  start: ${
+      licencee.startDate?.toISOString().slice(0, 10) || '-'
+    }  expiry: ${licencee.expiryDate?.toISOString().slice(0, 10) || '-'}`,
+  ].join('\n');
+}
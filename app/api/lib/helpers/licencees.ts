@@ -1,9 +1,21 @@
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import { NextRequest } from 'next/server';
 import { Countries } from '../models/countries';
+import { GamingLocations } from '../models/gaminglocations';
 import { Licencee } from '../models/licencee';
-import type { CountryDocument, LicenceeDocument } from '@shared/types';
+import type {
+  CountryDocument,
+  FinancialFormula,
+  FinancialFormulaOverride,
+  LicenceeDocument,
+  MachineStatusOverride,
+} from '@shared/types';
+import {
+  resolveFinancialFormula,
+  sanitizeMovementFields,
+} from '../utils/financialFormulas';
 import { generateUniqueLicenceKey } from '../utils/licenceKey';
 import {
   calculateChanges,
@@ -86,12 +98,7 @@ export async function formatLicenceesForResponse(
  */
 export async function getAllLicencees() {
   return await Licencee.find(
-    {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    },
+    { ...NOT_DELETED_FILTER },
     {
       _id: 1,
       name: 1,
@@ -107,12 +114,79 @@ export async function getAllLicencees() {
       prevExpiryDate: 1,
       includeJackpot: 1,
       gameDayOffset: 1,
+      financialFormula: 1,
+      machineStatus: 1,
     }
   )
     .sort({ name: 1 })
     .lean<LicenceeDocument[]>();
 }
 
+/**
+ * Resolves the effective financial formula for each licencee ID
+ */
+export async function getLicenceeFinancialFormulas(
+  licenceeIds: string[]
+): Promise<Map<string, FinancialFormula>> {
+  if (licenceeIds.length === 0) return new Map();
+  const licencees = await Licencee.find(
+    { _id: { $in: licenceeIds } },
+    { includeJackpot: 1, financialFormula: 1 }
+  ).lean<LicenceeDocument[]>();
+  return new Map(
+    licencees.map(licencee => [
+      String(licencee._id),
+      resolveFinancialFormula(licencee),
+    ])
+  );
+}
+
+/**
+ * Resolves the effective financial formula for each location ID, from the
+ * licencee it belongs to (the default formula when it has none)
+ */
+export async function getLocationFinancialFormulas(
+  locationIds: string[]
+): Promise<Map<string, FinancialFormula>> {
+  if (locationIds.length === 0) return new Map();
+  const locations = await GamingLocations.find(
+    { _id: { $in: locationIds } },
+    { 'rel.licencee': 1 }
+  ).lean<Array<{ _id: string; rel?: { licencee?: string } }>>();
+  const licenceeFormulas = await getLicenceeFinancialFormulas(
+    Array.from(
+      new Set(
+        locations
+          .map(location => location.rel?.licencee)
+          .filter((id): id is string => Boolean(id))
+      )
+    )
+  );
+  return new Map(
+    locations.map(location => [
+      String(location._id),
+      licenceeFormulas.get(String(location.rel?.licencee)) ||
+        resolveFinancialFormula(null),
+    ])
+  );
+}
+
+/**
+ * Machine status override of a licencee, by ID or name (null when the
+ * licencee is unknown or has none). Reports scoped to one licencee pass it
+ * to the machineStatus utilities.
+ */
+export async function getLicenceeMachineStatus(
+  licencee: string | null | undefined
+): Promise<MachineStatusOverride | null> {
+  if (!licencee || licencee === 'all') return null;
+  const found = await Licencee.findOne(
+    { $or: [{ _id: licencee }, { name: licencee }] },
+    { machineStatus: 1 }
+  ).lean<Pick<LicenceeDocument, 'machineStatus'>>();
+  return found?.machineStatus ?? null;
+}
+
 /**
  * Creates a new licencee with activity logging
  */
@@ -233,13 +307,15 @@ export async function updateLicencee(
     name?: string;
     description?: string;
     country?: string;
-    startDate?: string;
-    expiryDate?: string;
+    startDate?: string | null;
+    expiryDate?: string | null;
     isPaid?: boolean;
-    prevStartDate?: string;
-    prevExpiryDate?: string;
+    prevStartDate?: string | null;
+    prevExpiryDate?: string | null;
     includeJackpot?: boolean;
     gameDayOffset?: number;
+    financialFormula?: FinancialFormulaOverride | null;
+    machineStatus?: MachineStatusOverride | null;
   },
   request: NextRequest
 ) {
@@ -263,6 +339,8 @@ export async function updateLicencee(
     prevExpiryDate,
     includeJackpot,
     gameDayOffset,
+    financialFormula,
+    machineStatus,
   } = data;
 
   const currentUser = await getUserFromServer();
@@ -314,6 +392,25 @@ export async function updateLicencee(
   if (gameDayOffset !== undefined) {
     updateData.gameDayOffset = Number(gameDayOffset);
   }
+  if (financialFormula !== undefined) {
+    // null resets the licencee back to the default formula
+    updateData.financialFormula = financialFormula
+      ? {
+          moneyInFields: sanitizeMovementFields(financialFormula.moneyInFields),
+          moneyOutFields: sanitizeMovementFields(
+            financialFormula.moneyOutFields
+          ),
+        }
+      : null;
+  }
+  if (machineStatus !== undefined) {
+    // null (or no threshold) falls back to the deployment's definitions
+    const minutes = Number(machineStatus?.onlineThresholdMinutes);
+    updateData.machineStatus =
+      Number.isFinite(minutes) && minutes > 0
+        ? { onlineThresholdMinutes: minutes }
+        : null;
+  }
 
   const updated = await Licencee.findOneAndUpdate({ _id }, updateData, {
     new: true,
@@ -0,0 +1,408 @@
+/**
+ * List Endpoint Helper
+ *
+ * The standard list behaviour shared by the machines, locations, collections
+ * and integrity issue endpoints:
+ *
+ * - Pagination: `limit` (default 50, max 500) and `cursor`, the opaque
+ *   `nextCursor` of the previous page. Pages are keyset based (sort value
+ *   then `_id`), so rows written between requests are neither skipped nor
+ *   repeated.
+ * - Sorting: `sort=field` ascending or `sort=-field` descending, limited to
+ *   the resource's sort fields.
+ * - Filters: `licencee`, `location` (comma-separated ids), `status`
+ *   (comma-separated, resource specific) and `from` / `to` (ISO dates,
+ *   `from` inclusive, `to` exclusive) on the resource's date field.
+ *
+ * Results are always limited to the locations the user can access. Invalid
+ * params throw with `statusCode` 400, which withApiAuth returns as is.
+ *
+ * Response: `{ success, data, pagination: { limit, nextCursor, hasMore },
+ * sort, filters }`.
+ *
+ * @module app/api/lib/helpers/listEndpoint
+ */
+
+import type { ApiAuthContext } from '@/app/api/lib/helpers/apiWrapper';
+import { getUserLocationFilter } from '@/app/api/lib/helpers/licenceeFilter';
+import { params } from '@/app/api/lib/routeSchemas/common';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { Types } from 'mongoose';
+import type { Model } from 'mongoose';
+import { z } from 'zod';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ListResource = {
+  /** Name used in errors */
+  name: string;
+  model: Model<unknown>;
+  /** Field holding the location id (`_id` for locations) */
+  locationField: string;
+  /** Field `from` / `to` apply to */
+  dateField: string;
+  sortFields: string[];
+  /** `field` or `-field` */
+  defaultSort: string;
+  /** Filter for the requested statuses; throws on an unknown status */
+  statusFilter?: (statuses: string[]) => Record<string, unknown>;
+  /** Accepted `status` values, for the API docs */
+  statusDescription?: string;
+  /** Always applied, e.g. excluding deleted documents */
+  baseFilter?: Record<string, unknown>;
+  projection?: Record<string, 0 | 1>;
+};
+
+export type ListSort = {
+  field: string;
+  direction: 1 | -1;
+};
+
+export type ListCursor = {
+  /** Sort value of the last row on the previous page */
+  value: unknown;
+  id: unknown;
+};
+
+export type ListQuery = {
+  limit: number;
+  cursor: ListCursor | null;
+  sort: ListSort;
+  licencee: string | null;
+  locations: string[];
+  statuses: string[];
+  from: Date | null;
+  to: Date | null;
+};
+
+export type ListPage<T = Record<string, unknown>> = {
+  success: true;
+  data: T[];
+  pagination: {
+    limit: number;
+    nextCursor: string | null;
+    hasMore: boolean;
+  };
+  sort: string;
+  filters: {
+    licencee: string | null;
+    location: string[];
+    status: string[];
+    from: string | null;
+    to: string | null;
+  };
+};
+
+export const DEFAULT_LIST_LIMIT = 50;
+
+export const MAX_LIST_LIMIT = 500;
+
+type EncodedValue = { t: 'date' | 'oid' | 'raw'; v: unknown };
+
+// ============================================================================
+// Schemas
+// ============================================================================
+
+/**
+ * Query params of a resource's list. `parseListQuery()` validates with it
+ * and the OpenAPI document is generated from it.
+ */
+export function listQuerySchema(resource: ListResource) {
+  const sorts = resource.sortFields.flatMap(field => [field, `-${field}`]);
+  return z.object({
+    limit: z.coerce
+      .number()
+      .int()
+      .min(1)
+      .max(MAX_LIST_LIMIT)
+      .default(DEFAULT_LIST_LIMIT)
+      .describe('Rows per page'),
+    cursor: z
+      .string()
+      .optional()
+      .describe('pagination.nextCursor of the previous page'),
+    sort: z
+      .enum(sorts as [string, ...string[]])
+      .default(resource.defaultSort)
+      .describe('Sort field; prefix - for descending'),
+    licencee: z.string().optional().describe('Licencee id'),
+    location: z.string().optional().describe('Comma-separated location ids'),
+    ...(resource.statusFilter
+      ? {
+          status: z
+            .string()
+            .optional()
+            .describe(resource.statusDescription || 'Comma-separated'),
+        }
+      : {}),
+    from: params.isoDate
+      .optional()
+      .describe(`Earliest ${resource.dateField} (inclusive, ISO date)`),
+    to: params.isoDate
+      .optional()
+      .describe(`Latest ${resource.dateField} (exclusive, ISO date)`),
+  });
+}
+
+/**
+ * Response of a list, for the OpenAPI document; matches ListPage.
+ */
+export function listPageSchema(item: z.ZodTypeAny) {
+  return z.object({
+    success: z.literal(true),
+    data: z.array(item),
+    pagination: z.object({
+      limit: z.number().int(),
+      nextCursor: z.string().nullable(),
+      hasMore: z.boolean(),
+    }),
+    sort: z.string(),
+    filters: z.object({
+      licencee: z.string().nullable(),
+      location: z.array(z.string()),
+      status: z.array(z.string()),
+      from: z.string().datetime().nullable(),
+      to: z.string().datetime().nullable(),
+    }),
+  });
+}
+
+// ============================================================================
+// Parsing
+// ============================================================================
+
+function badRequest(message: string): Error {
+  return statusError(message, 400);
+}
+
+function encodeValue(value: unknown): EncodedValue {
+  if (value instanceof Date) return { t: 'date', v: value.toISOString() };
+  if (value instanceof Types.ObjectId) return { t: 'oid', v: String(value) };
+  return { t: 'raw', v: value ?? null };
+}
+
+function decodeValue(encoded: EncodedValue): unknown {
+  if (encoded.t === 'date') return new Date(String(encoded.v));
+  if (encoded.t === 'oid') return new Types.ObjectId(String(encoded.v));
+  return encoded.v;
+}
+
+/** Reads a sort field from a row, following dotted paths (`custom.name`). */
+function readSortValue(row: Record<string, unknown>, field: string): unknown {
+  let current: unknown = row;
+  for (const segment of field.split('.')) {
+    if (current === null || typeof current !== 'object') return undefined;
+    current = (current as Record<string, unknown>)[segment];
+  }
+  return current;
+}
+
+/**
+ * Opaque cursor for the row a page ends on.
+ */
+export function encodeListCursor(
+  row: Record<string, unknown>,
+  sort: ListSort
+): string {
+  return Buffer.from(
+    JSON.stringify({
+      s: `${sort.direction === -1 ? '-' : ''}${sort.field}`,
+      v: encodeValue(readSortValue(row, sort.field)),
+      i: encodeValue(row._id),
+    })
+  ).toString('base64url');
+}
+
+function decodeListCursor(cursor: string, sort: ListSort): ListCursor {
+  let parsed: { s?: string; v?: EncodedValue; i?: EncodedValue };
+  try {
+    parsed = JSON.parse(Buffer.from(cursor, 'base64url').toString('utf8'));
+  } catch {
+    throw badRequest('Invalid cursor');
+  }
+  if (!parsed.v || !parsed.i) throw badRequest('Invalid cursor');
+  if (parsed.s !== `${sort.direction === -1 ? '-' : ''}${sort.field}`) {
+    throw badRequest('The cursor was issued for a different sort');
+  }
+  return { value: decodeValue(parsed.v), id: decodeValue(parsed.i) };
+}
+
+function parseList(value: string | undefined): string[] {
+  return (value || '')
+    .split(',')
+    .map(entry => entry.trim())
+    .filter(Boolean);
+}
+
+/**
+ * Reads the list params for a resource.
+ *
+ * @throws Error with `statusCode` 400 on an invalid param
+ */
+export function parseListQuery(
+  searchParams: URLSearchParams,
+  resource: ListResource
+): ListQuery {
+  const parsed = listQuerySchema(resource).safeParse(
+    Object.fromEntries(searchParams)
+  );
+  if (!parsed.success) {
+    const [issue] = parsed.error.errors;
+    throw badRequest(`${issue.path.join('.')}: ${issue.message}`);
+  }
+  const values = parsed.data;
+
+  const sort: ListSort = {
+    field: values.sort.replace(/^-/, ''),
+    direction: values.sort.startsWith('-') ? -1 : 1,
+  };
+
+  const from = values.from ? new Date(values.from) : null;
+  const to = values.to ? new Date(values.to) : null;
+  if (from && to && from >= to) throw badRequest('from must be before to');
+
+  const statuses = parseList(searchParams.get('status') || undefined);
+  if (statuses.length > 0 && !resource.statusFilter) {
+    throw badRequest(`${resource.name} cannot be filtered by status`);
+  }
+
+  return {
+    limit: values.limit,
+    cursor: values.cursor ? decodeListCursor(values.cursor, sort) : null,
+    sort,
+    licencee: values.licencee || null,
+    locations: parseList(values.location),
+    statuses,
+    from,
+    to,
+  };
+}
+
+// ============================================================================
+// Query
+// ============================================================================
+
+/**
+ * Rows after the cursor in sort order. Missing values sort first
+ * ascending and last descending, as MongoDB sorts them.
+ */
+function buildCursorFilter(
+  cursor: ListCursor,
+  sort: ListSort
+): Record<string, unknown> {
+  const after = sort.direction === 1 ? '$gt' : '$lt';
+  const sameValueLaterId = {
+    [sort.field]: cursor.value,
+    _id: { [after]: cursor.id },
+  };
+  if (cursor.value === null) {
+    return sort.direction === 1
+      ? { $or: [sameValueLaterId, { [sort.field]: { $ne: null } }] }
+      : sameValueLaterId;
+  }
+  const laterValue = { [sort.field]: { [after]: cursor.value } };
+  return sort.direction === 1
+    ? { $or: [laterValue, sameValueLaterId] }
+    : { $or: [laterValue, sameValueLaterId, { [sort.field]: null }] };
+}
+
+/**
+ * Runs a list query for the user, limited to their accessible locations.
+ *
+ * @param resource - Resource being listed
+ * @param query - Parsed params (see parseListQuery)
+ * @param auth - The request's auth context
+ * @returns One page with the cursor for the next
+ */
+export async function runListQuery<T = Record<string, unknown>>(
+  resource: ListResource,
+  query: ListQuery,
+  auth: ApiAuthContext
+): Promise<ListPage<T>> {
+  const allowedLocationIds = await getUserLocationFilter(
+    auth.isAdminOrDev ? 'all' : auth.user.assignedLicencees || [],
+    query.licencee || undefined,
+    auth.user.assignedLocations || [],
+    auth.userRoles
+  );
+
+  const conditions: Record<string, unknown>[] = [];
+  if (resource.baseFilter) conditions.push({ ...resource.baseFilter });
+
+  const locations =
+    allowedLocationIds === 'all'
+      ? query.locations
+      : query.locations.length > 0
+        ? query.locations.filter(id => allowedLocationIds.includes(id))
+        : allowedLocationIds;
+  if (allowedLocationIds !== 'all' || query.locations.length > 0) {
+    // An empty list matches nothing, e.g. no accessible requested location
+    conditions.push({ [resource.locationField]: { $in: locations } });
+  }
+
+  if (query.statuses.length > 0 && resource.statusFilter) {
+    conditions.push(resource.statusFilter(query.statuses));
+  }
+  if (query.from || query.to) {
+    conditions.push({
+      [resource.dateField]: {
+        ...(query.from ? { $gte: query.from } : {}),
+        ...(query.to ? { $lt: query.to } : {}),
+      },
+    });
+  }
+  if (query.cursor) {
+    conditions.push(buildCursorFilter(query.cursor, query.sort));
+  }
+
+  const rows = await resource.model
+    .find(
+      conditions.length > 0 ? { $and: conditions } : {},
+      resource.projection
+    )
+    .sort({
+      [query.sort.field]: query.sort.direction,
+      _id: query.sort.direction,
+    })
+    .limit(query.limit + 1)
+    .lean<Record<string, unknown>[]>();
+
+  const hasMore = rows.length > query.limit;
+  const data = hasMore ? rows.slice(0, query.limit) : rows;
+  return {
+    success: true,
+    data: data as T[],
+    pagination: {
+      limit: query.limit,
+      nextCursor: hasMore
+        ? encodeListCursor(data[data.length - 1], query.sort)
+        : null,
+      hasMore,
+    },
+    sort: `${query.sort.direction === -1 ? '-' : ''}${query.sort.field}`,
+    filters: {
+      licencee: query.licencee,
+      location: query.locations,
+      status: query.statuses,
+      from: query.from?.toISOString() ?? null,
+      to: query.to?.toISOString() ?? null,
+    },
+  };
+}
+
+/**
+ * Parses the request's list params and runs the query.
+ *
+ * @throws Error with `statusCode` 400 on an invalid param
+ */
+export async function handleListRequest<T = Record<string, unknown>>(
+  request: Request,
+  resource: ListResource,
+  auth: ApiAuthContext
+): Promise<ListPage<T>> {
+  const { searchParams } = new URL(request.url);
+  const query = parseListQuery(searchParams, resource);
+  return runListQuery<T>(resource, query, auth);
+}
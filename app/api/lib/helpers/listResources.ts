@@ -0,0 +1,229 @@
+/**
+ * List Resources
+ *
+ * The resources served through the shared list behaviour (see
+ * listEndpoint) and the shape of their rows. The routes list with these
+ * definitions and the OpenAPI document is generated from them, so the
+ * sort fields, filters and row types stay the same in both.
+ *
+ * @module app/api/lib/helpers/listResources
+ */
+
+import type { ListResource } from '@/app/api/lib/helpers/listEndpoint';
+import { Collections } from '@/app/api/lib/models/collections';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { IntegrityIssue } from '@/app/api/lib/models/integrityIssue';
+import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { z } from 'zod';
+
+// ============================================================================
+// Machines
+// ============================================================================
+
+export const machineListItemSchema = z.object({
+  _id: z.string(),
+  serialNumber: z.string().optional(),
+  custom: z.object({ name: z.string().optional() }).optional(),
+  game: z.string().optional(),
+  gameType: z.string().optional(),
+  gamingLocation: z.string().optional(),
+  relayId: z.string().optional(),
+  smibBoard: z.string().optional(),
+  assetStatus: z.string().optional(),
+  lastActivity: z.date().nullable().optional(),
+  createdAt: z.date().optional(),
+  updatedAt: z.date().optional(),
+});
+
+export type MachineListItem = z.infer<typeof machineListItemSchema>;
+
+/**
+ * `status` accepts `online` / `offline` (last activity within the online
+ * threshold) and stored asset statuses; several match any of them.
+ */
+export const machineListResource: ListResource = {
+  name: 'machines',
+  model: Machine,
+  locationField: 'gamingLocation',
+  dateField: 'createdAt',
+  sortFields: [
+    'serialNumber',
+    'custom.name',
+    'game',
+    'lastActivity',
+    'createdAt',
+  ],
+  defaultSort: 'serialNumber',
+  statusDescription:
+    'online, offline, or stored assetStatus values; comma-separated',
+  baseFilter: NOT_DELETED_FILTER,
+  projection: Object.fromEntries(
+    Object.keys(machineListItemSchema.shape)
+      .filter(field => field !== '_id')
+      .map(field => [field === 'custom' ? 'custom.name' : field, 1])
+  ) as Record<string, 0 | 1>,
+  statusFilter: statuses => {
+    const cutoff = getOnlineCutoff();
+    const assetStatuses = statuses.filter(
+      status => status !== 'online' && status !== 'offline'
+    );
+    return {
+      $or: [
+        ...(statuses.includes('online')
+          ? [{ lastActivity: { $gte: cutoff } }]
+          : []),
+        ...(statuses.includes('offline')
+          ? [{ lastActivity: { $lt: cutoff } }, { lastActivity: null }]
+          : []),
+        ...(assetStatuses.length > 0
+          ? [{ assetStatus: { $in: assetStatuses } }]
+          : []),
+      ],
+    };
+  },
+};
+
+// ============================================================================
+// Locations
+// ============================================================================
+
+export const locationListItemSchema = z
+  .object({
+    _id: z.string(),
+    name: z.string(),
+    status: z.string().optional(),
+    rel: z.object({ licencee: z.string().optional() }).optional(),
+    gameDayOffset: z.number().optional(),
+    createdAt: z.date().optional(),
+    updatedAt: z.date().optional(),
+  })
+  .passthrough();
+
+export type LocationListItem = z.infer<typeof locationListItemSchema>;
+
+export const locationListResource: ListResource = {
+  name: 'locations',
+  model: GamingLocations,
+  locationField: '_id',
+  dateField: 'createdAt',
+  sortFields: ['name', 'createdAt', 'updatedAt'],
+  defaultSort: 'name',
+  statusDescription: 'Stored location status values; comma-separated',
+  baseFilter: NOT_DELETED_FILTER,
+  statusFilter: statuses => ({ status: { $in: statuses } }),
+};
+
+// ============================================================================
+// Collections
+// ============================================================================
+
+export const collectionListItemSchema = z
+  .object({
+    _id: z.string(),
+    machineId: z.string().optional(),
+    machineName: z.string().optional(),
+    machineCustomName: z.string().optional(),
+    location: z.string().optional(),
+    locationReportId: z.string().optional(),
+    collector: z.string().optional(),
+    isCompleted: z.boolean().optional(),
+    timestamp: z.date().optional(),
+    createdAt: z.date().optional(),
+    updatedAt: z.date().optional(),
+  })
+  .passthrough();
+
+export type CollectionListItem = z.infer<typeof collectionListItemSchema>;
+
+export const collectionListResource: ListResource = {
+  name: 'collections',
+  model: Collections,
+  locationField: 'location',
+  dateField: 'timestamp',
+  sortFields: ['timestamp', 'createdAt', 'updatedAt', 'machineId'],
+  defaultSort: '-timestamp',
+  statusDescription: 'completed or incomplete; comma-separated',
+  baseFilter: NOT_DELETED_FILTER,
+  statusFilter: statuses => {
+    const unknown = statuses.find(
+      status => status !== 'completed' && status !== 'incomplete'
+    );
+    if (unknown) {
+      throw statusError(
+        `Unknown status '${unknown}'; use completed or incomplete`,
+        400
+      );
+    }
+    return {
+      isCompleted: { $in: statuses.map(status => status === 'completed') },
+    };
+  },
+};
+
+// ============================================================================
+// Integrity Issues
+// ============================================================================
+
+const integrityIssueStatusSchema = z.enum([
+  'open',
+  'investigating',
+  'fixed',
+  'verified',
+  'confirmed',
+  'dismissed',
+]);
+
+export const INTEGRITY_ISSUE_STATUSES: string[] =
+  integrityIssueStatusSchema.options;
+
+export const integrityIssueListItemSchema = z.object({
+  _id: z.string(),
+  check: z.string(),
+  resourceType: z.string(),
+  resourceId: z.string(),
+  machine: z.string().optional(),
+  location: z.string().optional(),
+  field: z.string().nullable(),
+  value: z.number().nullable(),
+  mean: z.number().nullable(),
+  stdDev: z.number().nullable(),
+  zScore: z.number().nullable(),
+  sampleSize: z.number().nullable(),
+  readAt: z.date().optional(),
+  details: z.string().optional(),
+  status: integrityIssueStatusSchema,
+  detectedAt: z.date(),
+  reviewedBy: z.string().nullable(),
+  reviewedAt: z.date().nullable(),
+  assignedTo: z.string().nullable(),
+  assignedAt: z.date().nullable(),
+});
+
+export type IntegrityIssueListItem = z.infer<
+  typeof integrityIssueListItemSchema
+>;
+
+export const integrityIssueListResource: ListResource = {
+  name: 'integrity issues',
+  model: IntegrityIssue,
+  locationField: 'location',
+  dateField: 'detectedAt',
+  sortFields: ['detectedAt', 'readAt', 'zScore', 'check'],
+  defaultSort: '-detectedAt',
+  statusDescription: `${INTEGRITY_ISSUE_STATUSES.join(', ')}; comma-separated`,
+  statusFilter: statuses => {
+    const unknown = statuses.find(
+      status => !INTEGRITY_ISSUE_STATUSES.includes(status)
+    );
+    if (unknown) {
+      throw statusError(
+        `Unknown status '${unknown}'; use ${INTEGRITY_ISSUE_STATUSES.join(', ')}`,
+        400
+      );
+    }
+    return { status: { $in: statuses } };
+  },
+};
@@ -1,16 +1,28 @@
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
-import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  addMovementTotals,
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
 import type {
   AggregatedLocation,
   CollectionReportDocument,
   GamingMachine,
-  LicenceeDocument,
+  MovementTotals,
 } from '@/shared/types';
 import type { PipelineStage } from 'mongoose';
 import { isWowMachine } from '@/shared/utils/wowMachine';
+import {
+  getLicenceeFinancialFormulas,
+  getLicenceeMachineStatus,
+} from './licencees';
 import { getMemberCountsPerLocation } from './membershipAggregation';
 
 /**
@@ -55,7 +67,9 @@ export const getLocationsWithMetrics = async (
     console.error('[getLocationsWithMetrics] timePeriod must be a string');
     return { rows: [], totalCount: 0 };
   }
-  const onlineThreshold = new Date(Date.now() - 3 * 60 * 1000);
+  const onlineThreshold = getOnlineCutoff(
+    await getLicenceeMachineStatus(licencee)
+  );
 
   // Build the base pipeline with location matching
   // Apply user location permissions if provided (takes precedence)
@@ -73,10 +87,7 @@ export const getLocationsWithMetrics = async (
   const basePipeline: PipelineStage[] = [
     {
       $match: {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
+        ...NOT_DELETED_FILTER,
         ...locationIdFilter,
         // Apply licencee filter directly if no specific locations provided
         ...(licencee && licencee !== 'all' && !locationIdFilter._id
@@ -312,17 +323,20 @@ export const getLocationsWithMetrics = async (
       timePeriod === 'last7days' ||
       timePeriod === 'last30days';
 
-    // Fetch licencee includeJackpot settings
-    const licencees = await Licencee.find({}, { _id: 1, includeJackpot: 1 })
-      .lean<LicenceeDocument[]>()
-      .exec();
-    const licenceeSettingsMap = new Map<string, boolean>();
-    licencees.forEach(licencee => {
-      licenceeSettingsMap.set(
-        String(licencee._id),
-        Boolean(licencee.includeJackpot)
-      );
-    });
+    // Financial formula (includeJackpot and field overrides) per licencee
+    const licenceeFormulas = await getLicenceeFinancialFormulas(
+      Array.from(
+        new Set(
+          locations
+            .map(location => location.rel?.licencee)
+            .filter(Boolean)
+            .map(String)
+        )
+      )
+    );
+    const formulaForLocation = (location: { rel?: { licencee?: unknown } }) =>
+      licenceeFormulas.get(String(location.rel?.licencee)) ||
+      DEFAULT_FINANCIAL_FORMULA;
 
     const locationsWithMetrics: AggregatedLocation[] = [];
 
@@ -362,10 +376,8 @@ export const getLocationsWithMetrics = async (
       const allMachinesData = await Machine.find(
         {
           gamingLocation: { $in: allLocationIds },
-          $or: [
-            { deletedAt: null },
-            { deletedAt: { $lt: new Date('2025-01-01') } },
-          ],
+          ...NOT_DELETED_FILTER,
+          ...NOT_RETIRED_FILTER,
         },
         {
           _id: 1,
@@ -427,29 +439,20 @@ export const getLocationsWithMetrics = async (
                 machine: '$machine',
                 hour: { $dateTrunc: { date: '$readAt', unit: 'hour' } },
               },
-              totalDrop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-              totalCancelledCredits: {
-                $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-              },
+              ...buildMovementTotalsGroup(),
               totalGamesPlayed: {
                 $sum: { $ifNull: ['$movement.gamesPlayed', 0] },
               },
-              totalCoinIn: { $sum: { $ifNull: ['$movement.coinIn', 0] } },
-              totalCoinOut: { $sum: { $ifNull: ['$movement.coinOut', 0] } },
-              totalJackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
             },
           },
         ];
 
-        const locationAggregations: Array<{
-          _id: { location: string; machine: string; hour: Date };
-          totalDrop: number;
-          totalCancelledCredits: number;
-          totalGamesPlayed: number;
-          totalCoinIn: number;
-          totalCoinOut: number;
-          totalJackpot: number;
-        }> = [];
+        const locationAggregations: Array<
+          MovementTotals & {
+            _id: { location: string; machine: string; hour: Date };
+            totalGamesPlayed: number;
+          }
+        > = [];
         const locationAggregationsCursor = Meters.aggregate(
           aggregationPipeline,
           {
@@ -462,17 +465,10 @@ export const getLocationsWithMetrics = async (
           locationAggregations.push(doc as (typeof locationAggregations)[0]);
         }
 
-        // Initialize location metrics map
+        // Initialize location totals map
         const locationMetricsMap = new Map<
           string,
-          {
-            moneyIn: number;
-            moneyOut: number;
-            gamesPlayed: number;
-            coinIn: number;
-            coinOut: number;
-            jackpot: number;
-          }
+          MovementTotals & { gamesPlayed: number }
         >();
 
         // Process buckets and filter by each location's specific range
@@ -490,23 +486,11 @@ export const getLocationsWithMetrics = async (
             // Multiplier no longer used
 
             if (!locationMetricsMap.has(locationId)) {
-              locationMetricsMap.set(locationId, {
-                moneyIn: 0,
-                moneyOut: 0,
-                gamesPlayed: 0,
-                coinIn: 0,
-                coinOut: 0,
-                jackpot: 0,
-              });
+              locationMetricsMap.set(locationId, { gamesPlayed: 0 });
             }
-            const metrics = locationMetricsMap.get(locationId)!;
-            metrics.moneyIn += (bucketAgg.totalDrop as number) || 0;
-            metrics.moneyOut +=
-              (bucketAgg.totalCancelledCredits as number) || 0;
-            metrics.gamesPlayed += (bucketAgg.totalGamesPlayed as number) || 0;
-            metrics.coinIn += (bucketAgg.totalCoinIn as number) || 0;
-            metrics.coinOut += (bucketAgg.totalCoinOut as number) || 0;
-            metrics.jackpot += (bucketAgg.totalJackpot as number) || 0;
+            const totals = locationMetricsMap.get(locationId)!;
+            addMovementTotals(totals, bucketAgg);
+            totals.gamesPlayed += (bucketAgg.totalGamesPlayed as number) || 0;
           }
         }
 
@@ -514,13 +498,8 @@ export const getLocationsWithMetrics = async (
         for (const location of locations) {
           const locationId = String(location._id);
           const machines = locationToMachines.get(locationId) || [];
-          const metrics = locationMetricsMap.get(locationId) || {
-            moneyIn: 0,
-            moneyOut: 0,
+          const totals = locationMetricsMap.get(locationId) || {
             gamesPlayed: 0,
-            coinIn: 0,
-            coinOut: 0,
-            jackpot: 0,
           };
 
           // Calculate machine status metrics
@@ -568,30 +547,20 @@ export const getLocationsWithMetrics = async (
           const sasMachines = machines.filter(m => m.isSasMachine).length;
           const nonSasMachines = totalMachines - sasMachines;
 
-          const licenceeId = location.rel?.licencee
-            ? String(location.rel.licencee)
-            : '';
-          const includeJackpot = licenceeSettingsMap.get(licenceeId) || false;
-
-          // Logic: TRUE = Low Gross (Include jackpot in deduction), FALSE = High Gross (Exclude jackpot from deduction)
-          // Metrics.moneyOut is totalCancelledCredits which is typically NET handpays.
-          const adjustedMoneyOut =
-            (metrics.moneyOut || 0) +
-            (includeJackpot ? metrics.jackpot || 0 : 0);
+          // includeJackpot TRUE = Low Gross (jackpot deducted in Money Out)
+          const formula = formulaForLocation(location);
+          const metrics = calculateFinancialMetrics(totals, formula);
 
           locationsWithMetrics.push({
             location: locationId,
             locationName: location.name || 'Unknown Location',
-            moneyIn: Math.round((metrics.moneyIn || 0) * 100) / 100,
-            moneyOut: Math.round((adjustedMoneyOut || 0) * 100) / 100,
-            gross:
-              Math.round(
-                ((metrics.moneyIn || 0) - (adjustedMoneyOut || 0)) * 100
-              ) / 100,
-            coinIn: metrics.coinIn,
-            coinOut: metrics.coinOut,
+            moneyIn: Math.round(metrics.moneyIn * 100) / 100,
+            moneyOut: Math.round(metrics.moneyOut * 100) / 100,
+            gross: Math.round(metrics.gross * 100) / 100,
+            coinIn: totals.coinIn || 0,
+            coinOut: totals.coinOut || 0,
             jackpot: metrics.jackpot,
-            includeJackpot: includeJackpot,
+            includeJackpot: formula.includeJackpot,
             totalMachines,
             onlineMachines,
             sasMachines,
@@ -602,7 +571,7 @@ export const getLocationsWithMetrics = async (
             noSMIBLocation: sasMachines === 0,
             hasSmib: sasMachines > 0,
             isWowLocation: wowMachineCount > 0,
-            gamesPlayed: metrics.gamesPlayed,
+            gamesPlayed: totals.gamesPlayed,
             rel: location.rel,
             country: location.country,
             membershipEnabled: location.membershipEnabled || false,
@@ -712,10 +681,8 @@ export const getLocationsWithMetrics = async (
         const batchAllMachines = await Machine.find(
           {
             gamingLocation: { $in: batchLocationIds },
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
+            ...NOT_DELETED_FILTER,
+            ...NOT_RETIRED_FILTER,
           },
           {
             _id: 1,
@@ -775,28 +742,18 @@ export const getLocationsWithMetrics = async (
         // Step 6: Get ALL meters for ALL machines in batch, grouped by location (1 query)
         const batchMetersByLocation = new Map<
           string,
-          {
-            totalDrop: number;
-            totalMoneyOut: number;
-            totalGamesPlayed: number;
-            totalCoinIn: number;
-            totalCoinOut: number;
-            totalJackpot: number;
-          }
+          MovementTotals & { totalGamesPlayed: number }
         >();
 
         if (batchLocationIds.length > 0) {
           // 🚀 PERFORMANCE OPTIMIZATION: Use location field directly from meters
           // Group by location AND hour to prevent data inflation from batch global date ranges
-          const batchMetersAggregation: Array<{
-            _id: { location: string; machine: string; hour: Date };
-            totalDrop: number;
-            totalMoneyOut: number;
-            totalGamesPlayed: number;
-            totalCoinIn: number;
-            totalCoinOut: number;
-            totalJackpot: number;
-          }> = [];
+          const batchMetersAggregation: Array<
+            MovementTotals & {
+              _id: { location: string; machine: string; hour: Date };
+              totalGamesPlayed: number;
+            }
+          > = [];
           const batchMetersCursor = Meters.aggregate([
             {
               $match: {
@@ -814,20 +771,10 @@ export const getLocationsWithMetrics = async (
                   machine: '$machine',
                   hour: { $dateTrunc: { date: '$readAt', unit: 'hour' } },
                 },
-                totalDrop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-                totalMoneyOut: {
-                  $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-                },
+                ...buildMovementTotalsGroup(),
                 totalGamesPlayed: {
                   $sum: { $ifNull: ['$movement.gamesPlayed', 0] },
                 },
-                totalCoinIn: { $sum: { $ifNull: ['$movement.coinIn', 0] } },
-                totalCoinOut: {
-                  $sum: { $ifNull: ['$movement.coinOut', 0] },
-                },
-                totalJackpot: {
-                  $sum: { $ifNull: ['$movement.jackpot', 0] },
-                },
               },
             },
           ]).cursor({ batchSize: 1000 });
@@ -854,22 +801,11 @@ export const getLocationsWithMetrics = async (
               // Multiplier no longer used
 
               if (!batchMetersByLocation.has(locationId)) {
-                batchMetersByLocation.set(locationId, {
-                  totalDrop: 0,
-                  totalMoneyOut: 0,
-                  totalGamesPlayed: 0,
-                  totalCoinIn: 0,
-                  totalCoinOut: 0,
-                  totalJackpot: 0,
-                });
+                batchMetersByLocation.set(locationId, { totalGamesPlayed: 0 });
               }
               const current = batchMetersByLocation.get(locationId)!;
-              current.totalDrop += (agg.totalDrop as number) || 0;
-              current.totalMoneyOut += (agg.totalMoneyOut as number) || 0;
+              addMovementTotals(current, agg);
               current.totalGamesPlayed += (agg.totalGamesPlayed as number) || 0;
-              current.totalCoinIn += (agg.totalCoinIn as number) || 0;
-              current.totalCoinOut += (agg.totalCoinOut as number) || 0;
-              current.totalJackpot += (agg.totalJackpot as number) || 0;
             }
           });
         }
@@ -878,13 +814,8 @@ export const getLocationsWithMetrics = async (
         const batchResults = batch.map(location => {
           const locationId = String(location._id);
           const machines = batchMachinesByLocation.get(locationId) || [];
-          const meterMetrics = batchMetersByLocation.get(locationId) || {
-            totalDrop: 0,
-            totalMoneyOut: 0,
+          const meterTotals = batchMetersByLocation.get(locationId) || {
             totalGamesPlayed: 0,
-            totalCoinIn: 0,
-            totalCoinOut: 0,
-            totalJackpot: 0,
           };
 
           // Calculate machine metrics
@@ -924,30 +855,20 @@ export const getLocationsWithMetrics = async (
           const nonSasMachines = totalMachines - sasMachines;
           const wowMachineCount = machines.filter(mach => isWowMachine(mach)).length;
 
-          const licenceeId = location.rel?.licencee
-            ? String(location.rel.licencee)
-            : '';
-          const includeJackpot = licenceeSettingsMap.get(licenceeId) || false;
-
-          // Logic: TRUE = Low Gross (Include jackpot in deduction), FALSE = High Gross (Exclude jackpot from deduction)
-          // meterMetrics.totalMoneyOut is totalCancelledCredits which is typically NET handpays.
-          const adjustedMoneyOut =
-            (meterMetrics.totalMoneyOut || 0) +
-            (includeJackpot ? meterMetrics.totalJackpot || 0 : 0);
+          // includeJackpot TRUE = Low Gross (jackpot deducted in Money Out)
+          const formula = formulaForLocation(location);
+          const meterMetrics = calculateFinancialMetrics(meterTotals, formula);
 
           return {
             location: locationId,
             locationName: location.name || 'Unknown Location',
-            moneyIn: Math.round((meterMetrics.totalDrop || 0) * 100) / 100,
-            moneyOut: Math.round((adjustedMoneyOut || 0) * 100) / 100,
-            gross:
-              Math.round(
-                ((meterMetrics.totalDrop || 0) - (adjustedMoneyOut || 0)) * 100
-              ) / 100,
-            coinIn: meterMetrics.totalCoinIn,
-            coinOut: meterMetrics.totalCoinOut,
-            jackpot: meterMetrics.totalJackpot,
-            includeJackpot: includeJackpot,
+            moneyIn: Math.round(meterMetrics.moneyIn * 100) / 100,
+            moneyOut: Math.round(meterMetrics.moneyOut * 100) / 100,
+            gross: Math.round(meterMetrics.gross * 100) / 100,
+            coinIn: meterTotals.coinIn || 0,
+            coinOut: meterTotals.coinOut || 0,
+            jackpot: meterMetrics.jackpot,
+            includeJackpot: formula.includeJackpot,
             totalMachines,
             onlineMachines,
             sasMachines,
@@ -958,7 +879,7 @@ export const getLocationsWithMetrics = async (
             noSMIBLocation: sasMachines === 0,
             hasSmib: sasMachines > 0,
             isWowLocation: wowMachineCount > 0,
-            gamesPlayed: meterMetrics.totalGamesPlayed,
+            gamesPlayed: meterTotals.totalGamesPlayed,
             rel: location.rel,
             country: location.country,
             membershipEnabled: location.membershipEnabled || false,
@@ -1126,6 +1047,7 @@ export const getLocationsWithMetrics = async (
         // Set default values for financial fields
         moneyIn: 0,
         moneyOut: 0,
+        gross: 0,
         coinIn: 0,
         coinOut: 0,
         jackpot: 0,
@@ -1154,10 +1076,7 @@ export const getLocationsWithMetrics = async (
               {
                 $match: {
                   $expr: { $eq: ['$gamingLocation', '$$locationId'] },
-                  $or: [
-                    { deletedAt: null },
-                    { deletedAt: { $lt: new Date('2025-01-01') } },
-                  ],
+                  ...NOT_DELETED_FILTER,
                 },
               },
               {
@@ -1250,7 +1169,6 @@ export const getLocationsWithMetrics = async (
                 0,
               ],
             },
-            gross: { $subtract: ['$moneyIn', '$moneyOut'] },
           },
         },
         // Add SAS evaluation filter if requested
@@ -3,6 +3,10 @@
  * Utilities for building MongoDB query filters and resolving licencee IDs for location data.
  */
 import { Licencee } from '@/app/api/lib/models/licencee';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import type { LicenceeDocument } from '@shared/types';
 
 export type LocationQueryFilterParams = {
@@ -16,18 +20,13 @@ export type LocationQueryFilterParams = {
 
 /**
  * Builds the deletion filter for location queries.
- * When showArchived is true, returns only archived locations (deletedAt on or after 2025-01-01).
+ * When showArchived is true, returns only archived locations (deletedAt set).
  * Otherwise returns only active (non-archived) locations.
  */
 function buildDeletionFilter(showArchived: boolean): Record<string, unknown> {
   return showArchived
-    ? { deletedAt: { $gte: new Date('2025-01-01') } }
-    : {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      };
+    ? { ...DELETED_FILTER }
+    : { ...NOT_DELETED_FILTER };
 }
 
 /**
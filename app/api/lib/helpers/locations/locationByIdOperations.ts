@@ -8,16 +8,29 @@
  * @module app/api/lib/helpers/locations/locationByIdOperations
  */
 
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
 import type {
+  FinancialFormula,
   GamingMachine,
   LicenceeDocument,
+  MovementTotals,
   TransformedCabinet,
 } from '@shared/types';
 import { getMoneyInScale, getMoneyOutAndJackpotScale } from '@/app/api/lib/utils/reviewerScale';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  resolveFinancialFormula,
+} from '@/app/api/lib/utils/financialFormulas';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import { isWowMachine } from '@/shared/utils/wowMachine';
 
 // ============================================================================
@@ -38,23 +51,15 @@ export type CabinetsFilterParams = {
   aceEnabled: boolean;
 };
 
-export type MachineMetricsRecord = {
+export type MachineMetricsRecord = MovementTotals & {
   _id: string;
-  moneyIn: number;
-  moneyOut: number;
-  jackpot: number;
   gamesPlayed: number;
   gamesWon: number;
-  gross: number;
 };
 
 type MachineMetricsMap = Map<
   string,
-  {
-    moneyIn: number;
-    moneyOut: number;
-    gross: number;
-    jackpot: number;
+  MovementTotals & {
     gamesPlayed: number;
     gamesWon: number;
   }
@@ -99,7 +104,7 @@ export type CabinetMappingContext = {
   aceEnabled: boolean;
   moneyInScale: number;
   moneyOutScale: number;
-  includeJackpotSetting: boolean;
+  financialFormula: FinancialFormula;
 };
 
 export type PaginatedCabinetResult = {
@@ -114,13 +119,6 @@ export type PaginatedCabinetResult = {
   };
 };
 
-// ============================================================================
-// Constants
-// ============================================================================
-
-const CABINET_ONLINE_THRESHOLD_MS = 3 * 60 * 1000;
-const DELETION_SOFT_CUTOFF = new Date('2025-01-01');
-
 // ============================================================================
 // 1. SMIB Auto-Tag
 // ============================================================================
@@ -137,10 +135,7 @@ export async function computeAndUpdateSmibTags(
   const activeMachinesForTag = await Machine.find(
     {
       gamingLocation: locationId,
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: DELETION_SOFT_CUTOFF } },
-      ],
+      ...NOT_DELETED_FILTER,
     },
     { _id: 1, relayId: 1, 'meta.dataSync.source': 1 }
   ).lean<{ _id: string; relayId?: string; meta?: { dataSync?: { source?: string } } }[]>();
@@ -214,35 +209,47 @@ export async function fetchLicenceeJackpotFlag(
   return Boolean(licDoc?.includeJackpot);
 }
 
+/**
+ * Resolves the effective financial formula (see financialFormulas.ts) from a
+ * location's licencee reference. Falls back to the default formula.
+ */
+export async function fetchLicenceeFinancialFormula(
+  locLicId: string | string[] | undefined
+): Promise<FinancialFormula> {
+  const licenceeId = Array.isArray(locLicId) ? locLicId[0] : locLicId;
+  if (!licenceeId) return resolveFinancialFormula(null);
+  const licDoc = await Licencee.findOne(
+    { _id: licenceeId },
+    { includeJackpot: 1, financialFormula: 1 }
+  ).lean<LicenceeDocument>();
+  return resolveFinancialFormula(licDoc);
+}
+
 // ============================================================================
 // 3. Build Machine Filter for Cabinets List
 // ============================================================================
 
 /**
  * Builds a MongoDB query filter for fetching machines belonging to a location.
- * Applies archive, online-status, and SMIB-status filters conditionally.
+ * Retired machines are always left out; archive, online-status, and
+ * SMIB-status filters are applied conditionally.
  */
 export function buildMachinesFilter(params: CabinetsFilterParams): Record<string, unknown> {
   const mMatch: Record<string, unknown> = {
-    $and: [{ gamingLocation: params.locationId }] as unknown[],
+    $and: [{ gamingLocation: params.locationId }, { ...NOT_RETIRED_FILTER }] as unknown[],
   };
   const andConditions = mMatch.$and as unknown[];
 
   if (!params.includeArchived) {
-    andConditions.push({
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: DELETION_SOFT_CUTOFF } },
-      ],
-    });
+    andConditions.push({ ...NOT_DELETED_FILTER });
   } else {
     andConditions.push({
-      deletedAt: { $gte: DELETION_SOFT_CUTOFF },
+      ...DELETED_FILTER,
     });
   }
 
   if (params.onlineStatus !== 'all') {
-    const threeMin = new Date(Date.now() - CABINET_ONLINE_THRESHOLD_MS);
+    const onlineCutoff = getOnlineCutoff();
     if (params.aceEnabled) {
       if (params.onlineStatus === 'online') {
         // All active machines are online in ACE locations
@@ -251,11 +258,11 @@ export function buildMachinesFilter(params: CabinetsFilterParams): Record<string
       }
     } else {
       if (params.onlineStatus === 'online') {
-        (mMatch as Record<string, unknown>).lastActivity = { $gte: threeMin };
+        (mMatch as Record<string, unknown>).lastActivity = { $gte: onlineCutoff };
       } else if (params.onlineStatus === 'offline') {
         andConditions.push({
           $or: [
-            { lastActivity: { $lt: threeMin } },
+            { lastActivity: { $lt: onlineCutoff } },
             { lastActivity: { $exists: false } },
             { lastActivity: null },
           ],
@@ -357,14 +364,11 @@ export async function fetchMachineMetrics(
     {
       $group: {
         _id: '$machine',
-        moneyIn: { $sum: '$movement.drop' },
-        moneyOut: { $sum: '$movement.totalCancelledCredits' },
-        jackpot: { $sum: '$movement.jackpot' },
+        ...buildMovementTotalsGroup(),
         gamesPlayed: { $sum: '$movement.gamesPlayed' },
         gamesWon: { $sum: '$movement.gamesWon' },
       },
     },
-    { $addFields: { gross: { $subtract: ['$moneyIn', '$moneyOut'] } } },
   ]).cursor({ batchSize: 1000 });
 
   for await (const doc of cursor) {
@@ -382,14 +386,8 @@ export function buildMetricsMap(
 ): MachineMetricsMap {
   const map: MachineMetricsMap = new Map();
   for (const record of records) {
-    map.set(String(record._id), {
-      moneyIn: record.moneyIn,
-      moneyOut: record.moneyOut,
-      gross: record.gross,
-      jackpot: record.jackpot,
-      gamesPlayed: record.gamesPlayed,
-      gamesWon: record.gamesWon,
-    });
+    const { _id, ...totals } = record;
+    map.set(String(_id), totals);
   }
   return map;
 }
@@ -410,10 +408,6 @@ export function mapMachinesToCabinetData(
   return machines.map(machine => {
     const currentMachineId = String(machine._id);
     const machineMeters = metricsMap.get(currentMachineId) || {
-      moneyIn: 0,
-      moneyOut: 0,
-      gross: 0,
-      jackpot: 0,
       gamesPlayed: 0,
       gamesWon: 0,
     };
@@ -426,13 +420,12 @@ export function mapMachinesToCabinetData(
     const isOnline =
       isWowMachine(machine) ||
       context.aceEnabled ||
-      (lastActivityDate &&
-        new Date(lastActivityDate) > new Date(Date.now() - CABINET_ONLINE_THRESHOLD_MS));
-    const rawMoneyIn = (Number(machineMeters.moneyIn) || 0) * context.moneyInScale;
-    const rawMoneyOut = (Number(machineMeters.moneyOut) || 0) * context.moneyOutScale;
-    const rawJackpot = (Number(machineMeters.jackpot) || 0) * context.moneyOutScale;
-    const adjustedMoneyOut = rawMoneyOut + (context.includeJackpotSetting ? rawJackpot : 0);
-    const grossProfit = rawMoneyIn - adjustedMoneyOut;
+      (lastActivityDate && new Date(lastActivityDate) > getOnlineCutoff());
+    const financials = calculateFinancialMetrics(
+      machineMeters,
+      context.financialFormula,
+      { moneyInScale: context.moneyInScale, moneyOutScale: context.moneyOutScale }
+    );
 
     return {
       _id: currentMachineId,
@@ -454,16 +447,16 @@ export function mapMachinesToCabinetData(
       status: (machine.assetStatus as string) || '',
       gameType: (machine.gameType as string) || '',
       isCronosMachine: !!machine.isCronosMachine,
-      moneyIn: rawMoneyIn,
-      moneyOut: adjustedMoneyOut,
-      gross: grossProfit,
-      jackpot: rawJackpot,
+      moneyIn: financials.moneyIn,
+      moneyOut: financials.moneyOut,
+      gross: financials.gross,
+      jackpot: financials.jackpot,
       gamesPlayed: Number(machineMeters.gamesPlayed) || 0,
       gamesWon: Number(machineMeters.gamesWon) || 0,
-      cancelledCredits: adjustedMoneyOut,
+      cancelledCredits: financials.moneyOut,
       sasMeters: (machine.sasMeters || null) as Record<string, unknown> | null,
       online: !!isOnline,
-      includeJackpot: context.includeJackpotSetting,
+      includeJackpot: context.financialFormula.includeJackpot,
       deletedAt: (machine as unknown as { deletedAt?: Date }).deletedAt || null,
       meta: machine.meta,
     };
@@ -16,8 +16,12 @@ import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Countries } from '@/app/api/lib/models/countries';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CountryDocument, GamingMachine, LicenceeDocument } from '@/shared/types';
-import type { UpdateLocationData } from '@/shared/types/entities';
+import type {
+  LocationShift,
+  UpdateLocationData,
+} from '@/shared/types/entities';
 import type { LocationDocument } from '@/shared/types/models';
 import { generateMongoId } from '@/lib/utils/id';
 import { getClientIP } from '@/lib/utils/ipAddress';
@@ -43,6 +47,7 @@ export type LocationRequestBody = {
   googleMapsIframe?: string;
   previousCollectionTime?: string;
   address?: { street?: string; city?: string };
+  shifts?: LocationShift[];
 };
 
 type UserForLogging = {
@@ -205,7 +210,7 @@ export async function buildNewLocationDocument(
     googleMapsIframe: body.googleMapsIframe || '',
     createdAt: new Date(),
     updatedAt: new Date(),
-    deletedAt: new Date(-1),
+    deletedAt: null,
   };
 }
 
@@ -317,6 +322,12 @@ export function buildLocationUpdateData(
     updateData.googleMapsLink = body.googleMapsLink;
   if (body.googleMapsIframe !== undefined)
     updateData.googleMapsIframe = body.googleMapsIframe;
+  if (Array.isArray(body.shifts))
+    updateData.shifts = body.shifts.map(shift => ({
+      name: shift.name.trim(),
+      startHour: shift.startHour,
+      endHour: shift.endHour,
+    }));
 
   return updateData;
 }
@@ -594,10 +605,7 @@ export async function findLocationById(
 ): Promise<LocationDocument | null> {
   const filter: Record<string, unknown> = { _id: id };
   if (!includeDeleted) {
-    filter.$or = [
-      { deletedAt: null },
-      { deletedAt: { $lt: new Date('2025-01-01') } },
-    ];
+    filter.deletedAt = NOT_DELETED_FILTER.deletedAt;
   }
   return GamingLocations.findOne(filter).lean<LocationDocument>();
 }
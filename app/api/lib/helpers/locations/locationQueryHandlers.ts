@@ -10,6 +10,8 @@
 import { getUserAccessibleLicenceesFromToken } from '@/app/api/lib/helpers/licenceeFilter';
 import { buildLocationQueryFilter } from '@/app/api/lib/helpers/locations';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { restrictLocationsToTenant } from '@/app/api/lib/utils/tenantScope';
 import {
   buildLocationUpdateData,
   buildNewLocationDocument,
@@ -31,6 +33,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { validateShifts } from '@/lib/utils/shiftRange';
 import { NextRequest, NextResponse } from 'next/server';
 
 // ============================================================================
@@ -104,7 +107,8 @@ export async function handleGetLocations(
       .map(id => id.trim())
       .filter(id => id);
     if (idArray.length > 0) {
-      queryFilter._id = { $in: idArray };
+      // Explicit ids replace the access filter, so re-apply tenant isolation
+      queryFilter._id = { $in: await restrictLocationsToTenant(idArray) };
     }
   }
 
@@ -128,17 +132,13 @@ export async function handleCreateLocation(
   request: NextRequest
 ): Promise<Record<string, unknown>> {
   if (!body.name) {
-    const error = new Error('Name required');
-    (error as unknown as Record<string, unknown>).statusCode = 400;
-    throw error;
+    throw statusError('Name required', 400);
   }
 
   if (body.country) {
     const countryValid = await validateCountryReference(body.country as string);
     if (!countryValid) {
-      const error = new Error('Invalid country');
-      (error as unknown as Record<string, unknown>).statusCode = 400;
-      throw error;
+      throw statusError('Invalid country', 400);
     }
   }
 
@@ -177,16 +177,19 @@ export async function handleUpdateLocation(
   request: NextRequest
 ): Promise<{ _id: string; name: string }> {
   if (!body.locationName) {
-    const error = new Error('ID required');
-    (error as unknown as Record<string, unknown>).statusCode = 400;
-    throw error;
+    throw statusError('ID required', 400);
   }
 
   const location = await findLocationById(body.locationName as string);
   if (!location) {
-    const error = new Error('Not found');
-    (error as unknown as Record<string, unknown>).statusCode = 404;
-    throw error;
+    throw statusError('Not found', 404);
+  }
+
+  if (body.shifts !== undefined) {
+    const shiftError = validateShifts(body.shifts);
+    if (shiftError) {
+      throw statusError(shiftError, 400);
+    }
   }
 
   const updateData = buildLocationUpdateData(body as LocationRequestBody);
@@ -226,9 +229,7 @@ export async function handleDeleteLocation(
 ): Promise<void> {
   const locationToDelete = await findLocationById(id, true);
   if (!locationToDelete) {
-    const error = new Error('Not found');
-    (error as unknown as Record<string, unknown>).statusCode = 404;
-    throw error;
+    throw statusError('Not found', 404);
   }
 
   const associatedMachines = await findMachinesByLocation(id);
@@ -240,9 +241,7 @@ export async function handleDeleteLocation(
       : await executeSoftDelete(id, archiveTimestamp);
 
   if (deleteError) {
-    const error = new Error('Failed to delete location');
-    (error as unknown as Record<string, unknown>).statusCode = 500;
-    throw error;
+    throw statusError('Failed to delete location', 500);
   }
 
   await logDeleteActivity(
@@ -268,23 +267,17 @@ export async function handleRestoreLocation(
   request: NextRequest
 ): Promise<void> {
   if (!id) {
-    const error = new Error('Invalid request');
-    (error as unknown as Record<string, unknown>).statusCode = 400;
-    throw error;
+    throw statusError('Invalid request', 400);
   }
 
   const location = await findLocationById(id, true);
   if (!location) {
-    const error = new Error('Not found');
-    (error as unknown as Record<string, unknown>).statusCode = 404;
-    throw error;
+    throw statusError('Not found', 404);
   }
 
   const restoreResult = await executeLocationRestore(id);
   if ('error' in restoreResult) {
-    const error = new Error('Failed to restore location');
-    (error as unknown as Record<string, unknown>).statusCode = 500;
-    throw error;
+    throw statusError('Failed to restore location', 500);
   }
 
   await logRestoreActivity(
@@ -0,0 +1,457 @@
+/**
+ * Location Report Helper
+ *
+ * Builds a full venue snapshot for the ops weekly review in one call: the
+ * machine list with lifecycle and online status, meter totals and gross per
+ * machine for a range (licencee's financial formula, metersDaily rollup with
+ * raw-meter fallback), the last collection report, open integrity issues and
+ * variance alerts, and recent SAS events at warning severity or above.
+ *
+ * Used by the `location` command (scripts/location.ts).
+ *
+ * @module app/api/lib/helpers/locations/locationReport
+ */
+
+import { ACTIVE_INTEGRITY_ISSUE_STATUSES } from '@/app/api/lib/helpers/integrityIssues';
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { normalizeAssetStatus } from '@/app/api/lib/helpers/machineLifecycle';
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import { codeSpellings } from '@/app/api/lib/helpers/reports/sasAlerts';
+import { CollectionReport } from '@/app/api/lib/models/collectionReport';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { IntegrityIssue } from '@/app/api/lib/models/integrityIssue';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { MachineEvent } from '@/app/api/lib/models/machineEvents';
+import { Machine } from '@/app/api/lib/models/machines';
+import { VarianceAlert } from '@/app/api/lib/models/varianceAlert';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { getSasCodeDictionary } from '@/app/api/lib/utils/sasCodes';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import {
+  decodeSasException,
+  sasCodesAtSeverity,
+  type SasSeverity,
+} from '@/lib/utils/sas/exceptionCodes';
+import type {
+  FinancialMetrics,
+  MachineLifecycleStatus,
+  MachineStatusOverride,
+  MovementTotals,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type LocationReportParams = {
+  locationId: string;
+  /** Time period (7d, 30d, Today, ...); 'Custom' with the dates below */
+  timePeriod: string;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  /**
+   * Most recent events to list (default DEFAULT_LOCATION_REPORT_EVENTS);
+   * 0 skips them
+   */
+  eventLimit?: number;
+};
+
+export type LocationReportMachine = FinancialMetrics & {
+  machineId: string;
+  serialNumber: string;
+  customName: string | null;
+  game: string | null;
+  smibId: string | null;
+  assetStatus: MachineLifecycleStatus;
+  online: boolean;
+  lastActivity: Date | null;
+  drop: number;
+  totalCancelledCredits: number;
+  gamesPlayed: number;
+};
+
+export type LocationReportCollection = {
+  locationReportId: string;
+  timestamp: Date;
+  collectorName: string | null;
+  totalDrop: number;
+  totalCancelled: number;
+  totalGross: number;
+  amountCollected: number;
+  variance: number;
+  machinesCollected: string | null;
+};
+
+export type LocationReportIssue = {
+  source: 'integrity' | 'variance';
+  check: string;
+  resourceId: string;
+  details: string;
+  detectedAt: Date;
+};
+
+export type LocationReportEvent = {
+  date: Date;
+  machineId: string;
+  serialNumber: string;
+  code: string;
+  name: string;
+  severity: SasSeverity;
+};
+
+export type LocationReport = {
+  generatedAt: Date;
+  locationId: string;
+  locationName: string;
+  licenceeId: string | null;
+  licenceeName: string | null;
+  rangeStart: Date;
+  rangeEnd: Date;
+  machineCounts: {
+    total: number;
+    online: number;
+    offline: number;
+    byStatus: Record<MachineLifecycleStatus, number>;
+  };
+  totals: FinancialMetrics & {
+    drop: number;
+    totalCancelledCredits: number;
+    gamesPlayed: number;
+  };
+  machines: LocationReportMachine[];
+  lastCollection: LocationReportCollection | null;
+  openIssues: LocationReportIssue[];
+  recentEvents: LocationReportEvent[];
+};
+
+export const DEFAULT_LOCATION_REPORT_EVENTS = 25;
+
+type ReportMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  Custom?: { name?: string };
+  game?: string;
+  relayId?: string;
+  smibBoard?: string;
+  assetStatus?: string;
+  lastActivity?: Date;
+};
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the location report.
+ *
+ * @param params - Location, range and event limit
+ * @returns The venue snapshot
+ * @throws Error with `statusCode` 404 when the location does not exist
+ */
+export async function getLocationReport(
+  params: LocationReportParams
+): Promise<LocationReport> {
+  const { locationId } = params;
+
+  // Step 1: Location, licencee, formula and gaming-day range
+  const location = await GamingLocations.findOne(
+    { _id: locationId },
+    { _id: 1, name: 1, gameDayOffset: 1, 'rel.licencee': 1 }
+  ).lean<{
+    _id: string;
+    name?: string;
+    gameDayOffset?: number;
+    rel?: { licencee?: string };
+  }>();
+  if (!location) {
+    throw statusError(`Location ${locationId} not found`, 404);
+  }
+  const licenceeId = location.rel?.licencee
+    ? String(location.rel.licencee)
+    : null;
+  const [licencee, formulas] = await Promise.all([
+    licenceeId
+      ? Licencee.findOne(
+          { _id: licenceeId },
+          { name: 1, machineStatus: 1 }
+        ).lean<{ name?: string; machineStatus?: MachineStatusOverride }>()
+      : null,
+    getLicenceeFinancialFormulas(licenceeId ? [licenceeId] : []),
+  ]);
+  const formula =
+    (licenceeId && formulas.get(licenceeId)) || DEFAULT_FINANCIAL_FORMULA;
+  const range = getGamingDayRangeForPeriod(
+    params.timePeriod,
+    location.gameDayOffset ?? 8,
+    params.customStartDate,
+    params.customEndDate
+  );
+
+  // Step 2: Machines and their meter totals for the range
+  const machines = await Machine.find(
+    { gamingLocation: locationId, ...NOT_DELETED_FILTER },
+    {
+      _id: 1,
+      serialNumber: 1,
+      origSerialNumber: 1,
+      'custom.name': 1,
+      'Custom.name': 1,
+      game: 1,
+      relayId: 1,
+      smibBoard: 1,
+      assetStatus: 1,
+      lastActivity: 1,
+    }
+  ).lean<ReportMachine[]>();
+  const machineIds = machines.map(machine => String(machine._id));
+  const totalsByMachine = await getMovementTotalsWithRollup(
+    new Map([[locationId, range]]),
+    'machine'
+  );
+
+  const onlineSince = getOnlineCutoff(licencee?.machineStatus).getTime();
+  const byStatus: Record<MachineLifecycleStatus, number> = {
+    active: 0,
+    'in-repair': 0,
+    storage: 0,
+    retired: 0,
+  };
+  const locationTotals: MovementTotals & { gamesPlayed: number } = {
+    gamesPlayed: 0,
+  };
+  const serialById = new Map<string, string>();
+  const machineRows: LocationReportMachine[] = machines.map(machine => {
+    const machineId = String(machine._id);
+    const serialNumber =
+      machine.serialNumber?.trim() ||
+      machine.origSerialNumber?.trim() ||
+      machineId;
+    serialById.set(machineId, serialNumber);
+    const assetStatus = normalizeAssetStatus(machine.assetStatus);
+    byStatus[assetStatus]++;
+    const lastActivity = machine.lastActivity
+      ? new Date(machine.lastActivity)
+      : null;
+
+    const totals = totalsByMachine.get(machineId);
+    METER_MOVEMENT_FIELDS.forEach(field => {
+      locationTotals[field] =
+        (locationTotals[field] || 0) + (Number(totals?.[field]) || 0);
+    });
+    locationTotals.gamesPlayed += Number(totals?.gamesPlayed) || 0;
+    const metrics = calculateFinancialMetrics(totals || {}, formula);
+
+    return {
+      machineId,
+      serialNumber,
+      customName: machine.custom?.name || machine.Custom?.name || null,
+      game: machine.game || null,
+      smibId: machine.relayId || machine.smibBoard || null,
+      assetStatus,
+      online: lastActivity !== null && lastActivity.getTime() > onlineSince,
+      lastActivity,
+      drop: round2(Number(totals?.drop) || 0),
+      totalCancelledCredits: round2(Number(totals?.totalCancelledCredits) || 0),
+      gamesPlayed: Number(totals?.gamesPlayed) || 0,
+      moneyIn: round2(metrics.moneyIn),
+      moneyOut: round2(metrics.moneyOut),
+      jackpot: round2(metrics.jackpot),
+      gross: round2(metrics.gross),
+      netGross: round2(metrics.netGross),
+    };
+  });
+  machineRows.sort((a, b) => a.serialNumber.localeCompare(b.serialNumber));
+  const online = machineRows.filter(machine => machine.online).length;
+  const metrics = calculateFinancialMetrics(locationTotals, formula);
+
+  // Step 3: Last collection report, open issues and recent SAS events
+  const dictionary = getSasCodeDictionary();
+  const codes = sasCodesAtSeverity('warning', dictionary);
+  const eventLimit = params.eventLimit ?? DEFAULT_LOCATION_REPORT_EVENTS;
+  const [lastReport, integrityIssues, varianceAlerts, events] =
+    await Promise.all([
+      CollectionReport.findOne({ location: locationId, ...NOT_DELETED_FILTER })
+        .sort({ timestamp: -1 })
+        .lean(),
+      IntegrityIssue.find({
+        status: { $in: ACTIVE_INTEGRITY_ISSUE_STATUSES },
+        $or: [{ location: locationId }, { machine: { $in: machineIds } }],
+      })
+        .sort({ detectedAt: -1 })
+        .lean<
+          Array<{
+            check: string;
+            resourceId: string;
+            details?: string;
+            detectedAt: Date;
+          }>
+        >(),
+      VarianceAlert.find({ location: locationId, status: 'open' })
+        .sort({ gamingDay: -1 })
+        .lean<
+          Array<{ gamingDay: string; details?: string; detectedAt: Date }>
+        >(),
+      eventLimit === 0
+        ? []
+        : MachineEvent.find(
+            {
+              $or: [
+                { location: locationId },
+                { machine: { $in: machineIds } },
+              ],
+              date: { $gte: range.rangeStart, $lte: range.rangeEnd },
+              command: { $in: codes.flatMap(codeSpellings) },
+            },
+            { machine: 1, command: 1, date: 1 }
+          )
+            .sort({ date: -1 })
+            .limit(eventLimit)
+            .lean<Array<{ machine: string; command?: string; date: Date }>>(),
+    ]);
+
+  const openIssues: LocationReportIssue[] = [
+    ...varianceAlerts.map(alert => ({
+      source: 'variance' as const,
+      check: 'grossVariance',
+      resourceId: alert.gamingDay,
+      details: alert.details || '',
+      detectedAt: alert.detectedAt,
+    })),
+    ...integrityIssues.map(issue => ({
+      source: 'integrity' as const,
+      check: issue.check,
+      resourceId: issue.resourceId,
+      details: issue.details || '',
+      detectedAt: issue.detectedAt,
+    })),
+  ];
+
+  const recentEvents: LocationReportEvent[] = [];
+  events.forEach(event => {
+    const decoded = decodeSasException(event.command, dictionary);
+    if (!decoded) return;
+    recentEvents.push({
+      date: event.date,
+      machineId: event.machine,
+      serialNumber: serialById.get(event.machine) || event.machine,
+      code: decoded.code,
+      name: decoded.name,
+      severity: decoded.severity,
+    });
+  });
+
+  return {
+    generatedAt: new Date(),
+    locationId,
+    locationName: location.name || locationId,
+    licenceeId,
+    licenceeName: licencee?.name || null,
+    rangeStart: range.rangeStart,
+    rangeEnd: range.rangeEnd,
+    machineCounts: {
+      total: machineRows.length,
+      online,
+      offline: machineRows.length - online,
+      byStatus,
+    },
+    totals: {
+      drop: round2(Number(locationTotals.drop) || 0),
+      totalCancelledCredits: round2(
+        Number(locationTotals.totalCancelledCredits) || 0
+      ),
+      gamesPlayed: locationTotals.gamesPlayed,
+      moneyIn: round2(metrics.moneyIn),
+      moneyOut: round2(metrics.moneyOut),
+      jackpot: round2(metrics.jackpot),
+      gross: round2(metrics.gross),
+      netGross: round2(metrics.netGross),
+    },
+    machines: machineRows,
+    lastCollection: lastReport
+      ? {
+          locationReportId: lastReport.locationReportId,
+          timestamp: lastReport.timestamp,
+          collectorName: lastReport.collectorName || null,
+          totalDrop: lastReport.totalDrop,
+          totalCancelled: lastReport.totalCancelled,
+          totalGross: lastReport.totalGross,
+          amountCollected: lastReport.amountCollected,
+          variance: lastReport.variance,
+          machinesCollected: lastReport.machinesCollected || null,
+        }
+      : null,
+    openIssues,
+    recentEvents,
+  };
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+function money(value: number): string {
+  return value.toFixed(2).padStart(12);
+}
+
+/**
+ * Formats the report for the terminal, one section per heading.
+ */
+export function formatLocationReport(report: LocationReport): string {
+  const { machineCounts, totals, lastCollection } = report;
+  const statusCounts = Object.entries(machineCounts.byStatus)
+    .filter(([, count]) => count > 0)
+    .map(([status, count]) => `${count} ${status}`)
+    .join(', ');
+
+  const lines = [
+    `${report.locationName} (${report.locationId})${
+      report.licenceeName ? ` - ${report.licenceeName}` : ''
+    }`,
+    `${report.rangeStart.toISOString()} .. ${report.rangeEnd.toISOString()}`,
+    '',
+    `Machines: ${machineCounts.total} (${machineCounts.online} online, ${machineCounts.offline} offline${
+      statusCounts ? `; ${statusCounts}` : ''
+    })`,
+    ...report.machines.map(
+      machine =>
+        `  ${machine.serialNumber.padEnd(20)} ${(machine.customName || machine.game || '-').padEnd(24)} ${machine.assetStatus.padEnd(10)} ${(machine.online ? 'online' : 'offline').padEnd(8)} drop ${money(machine.drop)}  gross ${money(machine.gross)}`
+    ),
+    '',
+    'Meter totals:',
+    `  Drop ${totals.drop.toFixed(2)}, cancelled ${totals.totalCancelledCredits.toFixed(2)}, jackpot ${totals.jackpot.toFixed(2)}, games ${totals.gamesPlayed}`,
+    `  Money in ${totals.moneyIn.toFixed(2)}, money out ${totals.moneyOut.toFixed(2)}, gross ${totals.gross.toFixed(2)}`,
+    '',
+    'Last collection report:',
+    lastCollection
+      ? `  ${lastCollection.locationReportId} on ${new Date(lastCollection.timestamp).toISOString()}${
+          lastCollection.collectorName ? ` by ${lastCollection.collectorName}` : ''
+        }: drop ${lastCollection.totalDrop.toFixed(2)}, gross ${lastCollection.totalGross.toFixed(2)}, collected ${lastCollection.amountCollected.toFixed(2)}, variance ${lastCollection.variance.toFixed(2)}`
+      : '  none',
+    '',
+    `Open issues (${report.openIssues.length}):`,
+    ...report.openIssues.map(
+      issue =>
+        `  [${issue.check}] ${issue.resourceId}: ${issue.details || '-'}`
+    ),
+    '',
+    `Recent events at warning or above (${report.recentEvents.length}):`,
+    ...report.recentEvents.map(
+      event =>
+        `  ${new Date(event.date).toISOString()}  ${event.serialNumber.padEnd(20)} ${event.severity.padEnd(8)} ${event.name} (0x${event.code})`
+    ),
+  ];
+  return lines.join('\n');
+}
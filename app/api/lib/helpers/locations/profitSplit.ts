@@ -0,0 +1,210 @@
+/**
+ * Location Profit Split Helper
+ *
+ * Gross at each location is split between the licencee and the venue owner
+ * on a negotiated percentage. The split is kept per location as a dated list
+ * (`profitSplits`), each entry applying from its `effectiveFrom` gaming day
+ * until the next one, so a renegotiated split does not rewrite past
+ * settlements. A location without entries falls back to its `profitShare`
+ * (the venue percentage collection reports use, default 50).
+ *
+ * Setting a split that is already in effect also updates `profitShare`, so
+ * collection reports and settlements agree.
+ *
+ * @module app/api/lib/helpers/locations/profitSplit
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type { LocationProfitSplit } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ProfitSplitInput = {
+  venueShare: number;
+  /** YYYY-MM-DD; defaults to today */
+  effectiveFrom?: string;
+  venueOwner?: string;
+  payeeReference?: string;
+};
+
+export type LocationProfitSplitConfig = {
+  locationId: string;
+  locationName: string;
+  /** Entries oldest first */
+  splits: LocationProfitSplit[];
+  /** Split in effect today */
+  current: LocationProfitSplit;
+};
+
+/** Venue share when a location has neither splits nor a profitShare */
+export const DEFAULT_VENUE_SHARE = 50;
+
+const DAY_PATTERN = /^\d{4}-\d{2}-\d{2}$/;
+/** effectiveFrom of the split built from `profitShare` */
+const OPEN_START_DAY = '1970-01-01';
+
+type SplitLocation = {
+  _id: string;
+  name: string;
+  profitShare?: number;
+  profitSplits?: LocationProfitSplit[];
+};
+
+function today(): string {
+  return new Date().toISOString().slice(0, 10);
+}
+
+// ============================================================================
+// Resolution
+// ============================================================================
+
+/**
+ * The location's splits, oldest first. Without any, a single open-ended
+ * entry built from `profitShare`.
+ */
+export function getEffectiveSplits(location: {
+  profitShare?: number;
+  profitSplits?: LocationProfitSplit[];
+}): LocationProfitSplit[] {
+  if (location.profitSplits && location.profitSplits.length > 0) {
+    return [...location.profitSplits].sort((a, b) =>
+      a.effectiveFrom.localeCompare(b.effectiveFrom)
+    );
+  }
+  return [
+    {
+      effectiveFrom: OPEN_START_DAY,
+      venueShare:
+        typeof location.profitShare === 'number'
+          ? location.profitShare
+          : DEFAULT_VENUE_SHARE,
+    },
+  ];
+}
+
+/**
+ * The split in effect on a gaming day. Days before the first entry use the
+ * first entry.
+ *
+ * @param splits - From `getEffectiveSplits()`
+ * @param day - YYYY-MM-DD
+ */
+export function getSplitOnDay(
+  splits: LocationProfitSplit[],
+  day: string
+): LocationProfitSplit {
+  const applicable = splits.filter(split => split.effectiveFrom <= day);
+  return applicable[applicable.length - 1] || splits[0];
+}
+
+// ============================================================================
+// Read / Write
+// ============================================================================
+
+/**
+ * @throws Error with `statusCode` 404 when the location does not exist
+ */
+export async function getLocationProfitSplit(
+  locationId: string
+): Promise<LocationProfitSplitConfig> {
+  const location = await GamingLocations.findOne(
+    { _id: locationId, ...NOT_DELETED_FILTER },
+    { _id: 1, name: 1, profitShare: 1, profitSplits: 1 }
+  ).lean<SplitLocation | null>();
+  if (!location) throw statusError('Location not found', 404);
+
+  const splits = getEffectiveSplits(location);
+  return {
+    locationId: String(location._id),
+    locationName: location.name,
+    splits,
+    current: getSplitOnDay(splits, today()),
+  };
+}
+
+/**
+ * Adds a split from a gaming day onwards, replacing an entry for the same
+ * day. Venue owner and payee reference default to the preceding split's.
+ *
+ * @param locationId - Location to configure
+ * @param input - Venue share and when it starts
+ * @param updatedBy - Acting user id
+ * @returns The updated configuration, the saved split and the one it
+ *   replaced, if any
+ * @throws Error with `statusCode` 400 (invalid share or date) or 404
+ */
+export async function setLocationProfitSplit(
+  locationId: string,
+  input: ProfitSplitInput,
+  updatedBy: string
+): Promise<{
+  config: LocationProfitSplitConfig;
+  split: LocationProfitSplit;
+  replaced: LocationProfitSplit | null;
+}> {
+  const venueShare = Number(input.venueShare);
+  if (!Number.isFinite(venueShare) || venueShare < 0 || venueShare > 100) {
+    throw statusError('venueShare must be a percentage between 0 and 100', 400);
+  }
+  const effectiveFrom = input.effectiveFrom || today();
+  if (
+    !DAY_PATTERN.test(effectiveFrom) ||
+    Number.isNaN(new Date(effectiveFrom).getTime())
+  ) {
+    throw statusError('effectiveFrom must be YYYY-MM-DD', 400);
+  }
+
+  const location = await GamingLocations.findOne(
+    { _id: locationId, ...NOT_DELETED_FILTER },
+    { _id: 1, name: 1, profitShare: 1, profitSplits: 1 }
+  ).lean<SplitLocation | null>();
+  if (!location) throw statusError('Location not found', 404);
+  assertWritable('setting a profit split');
+
+  // Keep the profitShare the location had until now as the earlier split
+  const existing = getEffectiveSplits(location);
+  const replaced =
+    existing.find(split => split.effectiveFrom === effectiveFrom) || null;
+  // Payee details carry over from the split this one follows
+  const previous = getSplitOnDay(existing, effectiveFrom);
+  const entry: LocationProfitSplit = {
+    effectiveFrom,
+    venueShare,
+    venueOwner: input.venueOwner?.trim() || previous.venueOwner,
+    payeeReference: input.payeeReference?.trim() || previous.payeeReference,
+    updatedBy,
+    updatedAt: new Date(),
+  };
+  const splits = [
+    ...existing.filter(split => split.effectiveFrom !== effectiveFrom),
+    entry,
+  ].sort((a, b) => a.effectiveFrom.localeCompare(b.effectiveFrom));
+
+  const current = getSplitOnDay(splits, today());
+  await GamingLocations.updateOne(
+    { _id: location._id },
+    {
+      $set: {
+        profitSplits: splits,
+        profitShare: current.venueShare,
+        updatedAt: new Date(),
+      },
+    }
+  );
+
+  return {
+    config: {
+      locationId: String(location._id),
+      locationName: location.name,
+      splits,
+      current,
+    },
+    split: entry,
+    replaced,
+  };
+}
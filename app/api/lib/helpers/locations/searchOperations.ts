@@ -11,12 +11,30 @@
 import { Countries } from '@/app/api/lib/models/countries';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
-import { Meters } from '@/app/api/lib/models/meters';
+import { anyIdTypeIn, isObjectIdHex } from '@/app/api/lib/utils/mongoIds';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
 import { getMemberCountsPerLocation } from '@/app/api/lib/helpers/membershipAggregation';
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import { convertFromUSD, convertToUSD, getCountryCurrency } from '@/lib/helpers/rates';
-import type { CountryDocument, LicenceeDocument, TimePeriod } from '@/shared/types';
+import type {
+  CountryDocument,
+  FinancialFormula,
+  LicenceeDocument,
+  MovementTotals,
+  TimePeriod,
+} from '@/shared/types';
 import type { CurrencyCode } from '@/shared/types/currency';
 
 // ============================================================================
@@ -25,7 +43,7 @@ import type { CurrencyCode } from '@/shared/types/currency';
 
 type LocationRecord = Record<string, unknown>;
 
-type MetersByLocation = Map<string, { totalMoneyIn: number; totalMoneyOut: number; totalJackpot: number }>;
+type MetersByLocation = Map<string, MovementTotals>;
 
 export type LocationResult = {
   _id: string;
@@ -76,7 +94,6 @@ type MachineTypeFilterBuilders = {
 // Constants
 // ============================================================================
 
-const DELETION_SOFT_CUTOFF = new Date('2025-01-01');
 
 // ============================================================================
 // 1. Build Location Match Filter
@@ -104,13 +121,8 @@ export function buildLocationSearchFilter(params: {
   wowLocationIds?: string[] | null;
 }): { $and: Array<Record<string, unknown>> } {
   const deletionFilter = params.showArchived
-    ? { deletedAt: { $gte: DELETION_SOFT_CUTOFF } }
-    : {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: DELETION_SOFT_CUTOFF } },
-        ],
-      };
+    ? { ...DELETED_FILTER }
+    : { ...NOT_DELETED_FILTER };
 
   const locationMatch: { $and: Array<Record<string, unknown>>; [key: string]: unknown } = {
     $and: [deletionFilter],
@@ -118,11 +130,10 @@ export function buildLocationSearchFilter(params: {
 
   if (params.search) {
     const trimmedSearch = params.search.trim();
-    const isObjectIdFormat = /^[0-9a-fA-F]{24}$/.test(trimmedSearch);
-    if (isObjectIdFormat) {
+    if (isObjectIdHex(trimmedSearch)) {
       locationMatch.$and.push({
         $or: [
-          { _id: trimmedSearch },
+          { _id: anyIdTypeIn([trimmedSearch]) },
           { name: { $regex: params.search, $options: 'i' } },
         ],
       });
@@ -356,13 +367,9 @@ export async function fetchMachineStats(
                 ],
               },
               ...(showArchived
-                ? { deletedAt: { $gte: DELETION_SOFT_CUTOFF } }
-                : {
-                    $or: [
-                      { deletedAt: null },
-                      { deletedAt: { $lt: DELETION_SOFT_CUTOFF } },
-                    ],
-                  }),
+                ? { ...DELETED_FILTER }
+                : { ...NOT_DELETED_FILTER }),
+              ...NOT_RETIRED_FILTER,
             },
           },
           {
@@ -397,7 +404,7 @@ export async function fetchMachineStats(
                         {
                           $gte: [
                             { $convert: { input: '$lastActivity', to: 'date', onError: new Date(0) } },
-                            new Date(Date.now() - 3 * 60 * 1000),
+                            getOnlineCutoff(),
                           ],
                         },
                         { $eq: ['$meta.dataSync.source', 'wow'] },
@@ -433,7 +440,7 @@ export async function fetchMachineStats(
  * @param {TimePeriod} timePeriod - Time range for metrics
  * @param {Date} [customStartDate] - Custom range start
  * @param {Date} [customEndDate] - Custom range end
- * @returns {Promise<{ metersByLocation: MetersByLocation; memberCountMap: Map<string, number>; licenceeIncludeJackpotMap: Map<string, boolean>; allLocationIds: string[]; globalEnd: Date }>}
+ * @returns {Promise<{ metersByLocation: MetersByLocation; memberCountMap: Map<string, number>; licenceeFormulaMap: Map<string, FinancialFormula>; allLocationIds: string[]; globalEnd: Date }>}
  */
 export async function computeFinancialMetrics(
   matchingLocations: LocationRecord[],
@@ -443,7 +450,7 @@ export async function computeFinancialMetrics(
 ): Promise<{
   metersByLocation: MetersByLocation;
   memberCountMap: Map<string, number>;
-  licenceeIncludeJackpotMap: Map<string, boolean>;
+  licenceeFormulaMap: Map<string, FinancialFormula>;
   allLocationIds: string[];
   globalEnd: Date;
 }> {
@@ -456,65 +463,25 @@ export async function computeFinancialMetrics(
     gamingDayRanges.set(locationId, { ...gamingDayRange, gameDayOffset });
   });
 
-  // Step 2: Get global date range for initial meter query
-  let globalStart = new Date();
+  // Step 2: Get global end for reviewer scale resolution
   let globalEnd = new Date(0);
   gamingDayRanges.forEach(range => {
-    if (range.rangeStart < globalStart) globalStart = range.rangeStart;
     if (range.rangeEnd > globalEnd) globalEnd = range.rangeEnd;
   });
 
   // Step 3: Get ALL location IDs
   const allLocationIds = matchingLocations.map(location => String(location._id));
 
-  // Step 5: Get ALL meters grouped by location with hourly bucketing
-  const metersByLocation: MetersByLocation = new Map();
-
-  if (allLocationIds.length > 0) {
-    const cursor = Meters.aggregate([
-      {
-        $match: {
-          location: { $in: allLocationIds },
-          readAt: { $gte: globalStart, $lte: globalEnd },
-        },
-      },
-      {
-        $group: {
-          _id: {
-            location: '$location',
-            hour: { $dateTrunc: { date: '$readAt', unit: 'hour' } },
-          },
-          totalMoneyIn: { $sum: { $ifNull: ['$movement.drop', 0] } },
-          totalMoneyOut: { $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] } },
-          totalJackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
-        },
-      },
-    ]).cursor({ batchSize: 1000 });
-
-    for await (const doc of cursor) {
-      const locationId = String(doc._id.location);
-      const bucketHour = new Date(doc._id.hour);
-      const gamingDayRange = gamingDayRanges.get(locationId);
-      if (!gamingDayRange) continue;
-
-      const isWithinRange = bucketHour >= gamingDayRange.rangeStart && bucketHour <= gamingDayRange.rangeEnd;
-      if (isWithinRange) {
-        if (!metersByLocation.has(locationId)) {
-          metersByLocation.set(locationId, { totalMoneyIn: 0, totalMoneyOut: 0, totalJackpot: 0 });
-        }
-        const currentMetrics = metersByLocation.get(locationId);
-        if (!currentMetrics) continue;
-        currentMetrics.totalMoneyIn += (doc.totalMoneyIn as number) || 0;
-        currentMetrics.totalMoneyOut += (doc.totalMoneyOut as number) || 0;
-        currentMetrics.totalJackpot += (doc.totalJackpot as number) || 0;
-      }
-    }
-  }
+  // Step 5: Sum movement per location (daily rollup for complete days, raw meters otherwise)
+  const metersByLocation: MetersByLocation = await getMovementTotalsWithRollup(
+    gamingDayRanges,
+    'location'
+  );
 
   // Step 6: Fetch member counts per location
   const memberCountMap = await getMemberCountsPerLocation(allLocationIds);
 
-  // Step 7: Fetch licencee settings (includeJackpot)
+  // Step 7: Fetch licencee settings (includeJackpot + financial formula)
   const licenceeIds = Array.from(
     new Set(
       matchingLocations
@@ -525,12 +492,9 @@ export async function computeFinancialMetrics(
         .filter(Boolean) as string[]
     )
   );
-  const licencees = await Licencee.find({ _id: { $in: licenceeIds } }).lean<LicenceeDocument[]>();
-  const licenceeIncludeJackpotMap = new Map(
-    licencees.map(licencee => [String(licencee._id), !!licencee.includeJackpot])
-  );
+  const licenceeFormulaMap = await getLicenceeFinancialFormulas(licenceeIds);
 
-  return { metersByLocation, memberCountMap, licenceeIncludeJackpotMap, allLocationIds, globalEnd };
+  return { metersByLocation, memberCountMap, licenceeFormulaMap, allLocationIds, globalEnd };
 }
 
 // ============================================================================
@@ -545,17 +509,13 @@ function buildLocationResult(params: {
   location: LocationRecord;
   locationId: string;
   metersByLocation: MetersByLocation;
-  licenceeIncludeJackpotMap: Map<string, boolean>;
+  licenceeFormulaMap: Map<string, FinancialFormula>;
   syncAll: boolean;
   moneyInScale: number;
   moneyOutScale: number;
   memberCountMap: Map<string, number>;
 }): LocationResult {
-  const financialData = params.metersByLocation.get(params.locationId) || {
-    totalMoneyIn: 0,
-    totalMoneyOut: 0,
-    totalJackpot: 0,
-  };
+  const financialData = params.metersByLocation.get(params.locationId) || {};
 
   const membershipEnabled = Boolean(
     params.location.membershipEnabled || (params.location as { enableMembership?: boolean }).enableMembership
@@ -563,13 +523,14 @@ function buildLocationResult(params: {
 
   const locationRel = params.location.rel as { licencee?: string } | null;
   const licenceeId = locationRel?.licencee ? String(locationRel.licencee) : '';
-  const includeJackpot = params.licenceeIncludeJackpotMap.get(licenceeId) || false;
+  const financialFormula =
+    params.licenceeFormulaMap.get(licenceeId) || DEFAULT_FINANCIAL_FORMULA;
+  const includeJackpot = financialFormula.includeJackpot;
 
-  const rawMoneyIn = (financialData.totalMoneyIn || 0) * params.moneyInScale;
-  const rawMoneyOut = (financialData.totalMoneyOut || 0) * params.moneyOutScale;
-  const jackpot = (financialData.totalJackpot || 0) * params.moneyOutScale;
-
-  const moneyOutValue = rawMoneyOut + (includeJackpot ? jackpot : 0);
+  const financials = calculateFinancialMetrics(financialData, financialFormula, {
+    moneyInScale: params.moneyInScale,
+    moneyOutScale: params.moneyOutScale,
+  });
 
   const machineStats = (params.location.machineStats as MachineStatsDoc[] | undefined)?.[0];
   const machineCount = machineStats?.totalMachines || 0;
@@ -603,11 +564,11 @@ function buildLocationResult(params: {
       ? machineCount
       : machineStats?.onlineMachines || 0,
     aceEnabled: Boolean(params.location.aceEnabled),
-    moneyIn: rawMoneyIn,
-    moneyOut: moneyOutValue,
-    jackpot,
+    moneyIn: financials.moneyIn,
+    moneyOut: financials.moneyOut,
+    jackpot: financials.jackpot,
     includeJackpot,
-    gross: rawMoneyIn - moneyOutValue,
+    gross: financials.gross,
     isLocalServer: Boolean(params.location.isLocalServer),
     hasSmib: computedFull || computedSemiVal,
     noSMIBLocation: computedNone,
@@ -634,7 +595,7 @@ export function buildLocationResults(params: {
   matchingLocations: LocationRecord[];
   metersByLocation: MetersByLocation;
   memberCountMap: Map<string, number>;
-  licenceeIncludeJackpotMap: Map<string, boolean>;
+  licenceeFormulaMap: Map<string, FinancialFormula>;
   syncAll: boolean;
   moneyInScale: number;
   moneyOutScale: number;
@@ -722,12 +683,7 @@ export async function applyCurrencyConversion(
 
   // Fetch licencee data for currency mapping
   const licenceesData = await Licencee.find(
-    {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lte: new Date('1971-01-01') } },
-      ],
-    },
+    { ...NOT_DELETED_FILTER },
     { _id: 1, name: 1 }
   ).lean<LicenceeDocument[]>();
 
@@ -0,0 +1,436 @@
+/**
+ * Machine Decommissioning Helper
+ *
+ * Retires a machine for good, capturing the state the regulator needs:
+ *
+ * 1. The machine must be allowed to move to `retired` (from `storage`, see
+ *    machineLifecycle) and must have a final collection: its latest completed
+ *    collection, with no play or drop recorded in `meters` after it
+ * 2. Its last SAS meters and that final collection are stored on the machine
+ *    as `decommission`, with the retirement time, reason and user, in the same
+ *    conditional update that sets `assetStatus: 'retired'`
+ * 3. The decommission certificate is rendered from that record as text or
+ *    XML for submission
+ *
+ * Retired machines are left out of meters reports, the uncollected drop
+ * report and the machine integrity checks (`NOT_RETIRED_FILTER`).
+ *
+ * Used by `bun run machine -- retire` and `bun run machine -- certificate`.
+ *
+ * @module app/api/lib/helpers/machineDecommission
+ */
+
+import { resolveMachine } from '@/app/api/lib/helpers/machineDetails';
+import type { DetailsMachine } from '@/app/api/lib/helpers/machineDetails';
+import {
+  changeMachineAssetStatus,
+  normalizeAssetStatus,
+  validateStatusTransition,
+} from '@/app/api/lib/helpers/machineLifecycle';
+import { Collections } from '@/app/api/lib/models/collections';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type {
+  MachineDecommissionMeters,
+  MachineDecommissionRecord,
+  MachineLifecycleStatus,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type CertificateFormat = 'text' | 'xml';
+
+export const CERTIFICATE_FORMATS: CertificateFormat[] = ['text', 'xml'];
+
+export type MachineRetirementPlan = {
+  machineId: string;
+  serialNumber: string;
+  status: MachineLifecycleStatus;
+  finalMeters: MachineDecommissionMeters;
+  finalMetersAt: Date | null;
+  finalCollection: MachineDecommissionRecord['finalCollection'] | null;
+  /** Meter readings with play or drop after the final collection */
+  readingsSinceCollection: number;
+  locationId: string | null;
+  licenceeId: string | null;
+  /** Why the machine cannot be retired yet; empty when it can */
+  problems: string[];
+};
+
+export type RetirementActor = {
+  userId: string;
+  username: string;
+};
+
+export type DecommissionCertificate = MachineDecommissionRecord & {
+  machineId: string;
+  serialNumber: string;
+  manufacturer: string;
+  game: string;
+  smibBoard: string;
+  locationName: string;
+  licenceeName: string;
+  licenceKey: string;
+  generatedAt: Date;
+};
+
+/** Meter fields of the certificate: label, XML element, amount or count */
+const CERTIFICATE_METERS: Array<{
+  field: keyof MachineDecommissionMeters;
+  label: string;
+  element: string;
+  cents: boolean;
+}> = [
+  { field: 'coinIn', label: 'Coin in', element: 'CoinIn', cents: true },
+  { field: 'coinOut', label: 'Coin out', element: 'CoinOut', cents: true },
+  { field: 'drop', label: 'Drop', element: 'Drop', cents: true },
+  {
+    field: 'cancelledCredits',
+    label: 'Cancelled credits',
+    element: 'CancelledCredits',
+    cents: true,
+  },
+  { field: 'handPaid', label: 'Hand paid', element: 'HandPaid', cents: true },
+  { field: 'jackpot', label: 'Jackpot', element: 'Jackpot', cents: true },
+  {
+    field: 'gamesPlayed',
+    label: 'Games played',
+    element: 'GamesPlayed',
+    cents: false,
+  },
+];
+
+type DecommissionedMachine = DetailsMachine & {
+  decommission?: MachineDecommissionRecord;
+};
+
+function finalMetersOf(machine: DetailsMachine): MachineDecommissionMeters {
+  const sas = machine.sasMeters ?? {};
+  return {
+    coinIn: sas.coinIn ?? 0,
+    coinOut: sas.coinOut ?? 0,
+    drop: sas.drop ?? 0,
+    cancelledCredits: sas.totalCancelledCredits ?? 0,
+    handPaid: sas.totalHandPaidCancelledCredits ?? 0,
+    jackpot: sas.jackpot ?? 0,
+    gamesPlayed: sas.gamesPlayed ?? 0,
+  };
+}
+
+function certificateNumber(serialNumber: string, retiredAt: Date): string {
+  const day = retiredAt.toISOString().slice(0, 10).replace(/-/g, '');
+  return `DC-${day}-${serialNumber.replace(/[^A-Za-z0-9]/g, '')}`;
+}
+
+// ============================================================================
+// Retirement
+// ============================================================================
+
+/**
+ * Checks whether a machine can be retired and gathers its final meters and
+ * collection. Only reads.
+ *
+ * @param serialOrId - Machine serial number or id
+ * @throws Error with `statusCode` 404 (not found) or 400 (ambiguous serial)
+ */
+export async function planMachineRetirement(
+  serialOrId: string
+): Promise<MachineRetirementPlan> {
+  const machine = await resolveMachine(serialOrId);
+  const machineId = String(machine._id);
+  const status = normalizeAssetStatus(machine.assetStatus);
+  const problems: string[] = [];
+
+  const invalid = validateStatusTransition(status, 'retired');
+  if (invalid) problems.push(invalid);
+
+  const collection = await Collections.findOne(
+    {
+      machineId,
+      isCompleted: true,
+      locationReportId: { $nin: ['', null] },
+      ...NOT_DELETED_FILTER,
+    },
+    { _id: 1, locationReportId: 1, timestamp: 1, metersIn: 1, metersOut: 1 }
+  )
+    .sort({ timestamp: -1 })
+    .lean<{
+      _id: string;
+      locationReportId: string;
+      timestamp: Date;
+      metersIn?: number;
+      metersOut?: number;
+    }>();
+
+  let readingsSinceCollection = 0;
+  if (!collection) {
+    problems.push('No completed collection; collect the machine first');
+  } else {
+    readingsSinceCollection = await Meters.countDocuments({
+      machine: machineId,
+      readAt: { $gt: collection.timestamp },
+      $or: [
+        { 'movement.drop': { $gt: 0 } },
+        { 'movement.gamesPlayed': { $gt: 0 } },
+      ],
+    });
+    if (readingsSinceCollection > 0) {
+      problems.push(
+        `${readingsSinceCollection} meter reading(s) with play or drop after the last collection on ${new Date(collection.timestamp).toISOString()}; collect the machine again first`
+      );
+    }
+  }
+
+  const location = machine.gamingLocation
+    ? await GamingLocations.findOne(
+        { _id: machine.gamingLocation },
+        { 'rel.licencee': 1 }
+      ).lean<{ rel?: { licencee?: string | string[] } }>()
+    : null;
+  const licencee = location?.rel?.licencee;
+
+  return {
+    machineId,
+    serialNumber: machine.serialNumber || machine.origSerialNumber || '',
+    status,
+    finalMeters: finalMetersOf(machine),
+    finalMetersAt: machine.lastSasMeterAt ?? machine.lastActivity ?? null,
+    finalCollection: collection
+      ? {
+          collectionId: String(collection._id),
+          locationReportId: collection.locationReportId,
+          collectedAt: collection.timestamp,
+          metersIn: collection.metersIn ?? 0,
+          metersOut: collection.metersOut ?? 0,
+        }
+      : null,
+    readingsSinceCollection,
+    locationId: machine.gamingLocation || null,
+    licenceeId: (Array.isArray(licencee) ? licencee[0] : licencee) || null,
+    problems,
+  };
+}
+
+/**
+ * Retires a machine: re-checks the plan, then records the final meters and
+ * collection with the status change (see changeMachineAssetStatus, which
+ * backs the machine up and writes the activity log).
+ *
+ * @param serialOrId - Machine serial number or id
+ * @param reason - Why the machine is being decommissioned
+ * @param actor - Acting user
+ * @returns The stored decommission record
+ * @throws Error with `statusCode` 400 (no reason, ambiguous serial), 404 (not
+ * found) or 409 (not retirable yet, or changed concurrently)
+ */
+export async function retireMachine(
+  serialOrId: string,
+  reason: string,
+  actor: RetirementActor
+): Promise<MachineDecommissionRecord> {
+  if (!reason.trim()) throw statusError('A reason is required', 400);
+  const plan = await planMachineRetirement(serialOrId);
+  if (plan.problems.length > 0 || !plan.finalCollection) {
+    throw statusError(
+      `Machine ${plan.serialNumber || plan.machineId} cannot be retired: ${plan.problems.join('; ')}`,
+      409
+    );
+  }
+
+  const retiredAt = new Date();
+  const record: MachineDecommissionRecord = {
+    certificateNumber: certificateNumber(
+      plan.serialNumber || plan.machineId,
+      retiredAt
+    ),
+    retiredAt,
+    reason: reason.trim(),
+    userId: actor.userId,
+    username: actor.username,
+    finalMeters: plan.finalMeters,
+    finalMetersAt: plan.finalMetersAt,
+    finalCollection: plan.finalCollection,
+    locationId: plan.locationId,
+    licenceeId: plan.licenceeId,
+  };
+  await changeMachineAssetStatus(
+    {
+      machineId: plan.machineId,
+      to: 'retired',
+      reason: record.reason,
+      userId: actor.userId,
+      username: actor.username,
+    },
+    { decommission: record }
+  );
+  return record;
+}
+
+// ============================================================================
+// Certificate
+// ============================================================================
+
+/**
+ * Loads the decommission certificate of a retired machine.
+ *
+ * @throws Error with `statusCode` 404 when the machine is unknown or was not
+ * retired through the decommissioning workflow
+ */
+export async function getDecommissionCertificate(
+  serialOrId: string
+): Promise<DecommissionCertificate> {
+  const resolved = await resolveMachine(serialOrId);
+  const machine = await Machine.findOne({
+    _id: resolved._id,
+  }).lean<DecommissionedMachine>();
+  const record = machine?.decommission;
+  if (!machine || !record?.certificateNumber) {
+    throw statusError(
+      `Machine ${serialOrId} has no decommission record; retire it with 'machine retire'`,
+      404
+    );
+  }
+
+  const [location, licencee] = await Promise.all([
+    record.locationId
+      ? GamingLocations.findOne({ _id: record.locationId }, { name: 1 }).lean<{
+          name?: string;
+        }>()
+      : null,
+    record.licenceeId
+      ? Licencee.findOne(
+          { _id: record.licenceeId },
+          { name: 1, licenceKey: 1 }
+        ).lean<{ name?: string; licenceKey?: string }>()
+      : null,
+  ]);
+
+  return {
+    ...record,
+    machineId: String(machine._id),
+    serialNumber: machine.serialNumber || machine.origSerialNumber || '',
+    manufacturer: machine.manufacturer || machine.manuf || '',
+    game: machine.game || '',
+    smibBoard: machine.relayId || machine.smibBoard || '',
+    locationName: location?.name || record.locationId || '',
+    licenceeName: licencee?.name || record.licenceeId || '',
+    licenceKey: licencee?.licenceKey || '',
+    generatedAt: new Date(),
+  };
+}
+
+function escapeXml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&apos;');
+}
+
+function iso(value: Date | null | undefined): string {
+  return value ? new Date(value).toISOString() : '';
+}
+
+function meterValue(value: number, cents: boolean): string {
+  return cents ? value.toFixed(2) : String(Math.round(value));
+}
+
+/**
+ * Renders the certificate as plain text for printing or as XML for
+ * electronic submission.
+ */
+export function renderDecommissionCertificate(
+  certificate: DecommissionCertificate,
+  format: CertificateFormat
+): string {
+  const { finalCollection, finalMeters } = certificate;
+  if (format === 'xml') {
+    const lines = [
+      '<?xml version="1.0" encoding="UTF-8"?>',
+      `<DecommissionCertificate number="${escapeXml(certificate.certificateNumber)}" generated="${certificate.generatedAt.toISOString()}">`,
+      `  <Licensee licenceKey="${escapeXml(certificate.licenceKey)}">${escapeXml(certificate.licenceeName)}</Licensee>`,
+      `  <Location id="${escapeXml(certificate.locationId ?? '')}">${escapeXml(certificate.locationName)}</Location>`,
+      `  <Machine id="${escapeXml(certificate.machineId)}" serialNumber="${escapeXml(certificate.serialNumber)}" manufacturer="${escapeXml(certificate.manufacturer)}" game="${escapeXml(certificate.game)}" smib="${escapeXml(certificate.smibBoard)}"/>`,
+      `  <Retirement date="${iso(certificate.retiredAt)}" by="${escapeXml(certificate.username)}">${escapeXml(certificate.reason)}</Retirement>`,
+      `  <FinalMeters readAt="${iso(certificate.finalMetersAt)}">`,
+      ...CERTIFICATE_METERS.map(
+        ({ field, element, cents }) =>
+          `    <${element}>${meterValue(finalMeters[field], cents)}</${element}>`
+      ),
+      '  </FinalMeters>',
+      `  <FinalCollection id="${escapeXml(finalCollection.collectionId)}" report="${escapeXml(finalCollection.locationReportId)}" date="${iso(finalCollection.collectedAt)}">`,
+      `    <MetersIn>${finalCollection.metersIn}</MetersIn>`,
+      `    <MetersOut>${finalCollection.metersOut}</MetersOut>`,
+      '  </FinalCollection>',
+      '</DecommissionCertificate>',
+    ];
+    return `${lines.join('\n')}\n`;
+  }
+
+  const row = (label: string, value: string) =>
+    `  ${label.padEnd(20)} ${value}`;
+  const lines = [
+    'GAMING MACHINE DECOMMISSION CERTIFICATE',
+    `Certificate ${certificate.certificateNumber}`,
+    '',
+    row('Licensee', `${certificate.licenceeName} (${certificate.licenceKey})`),
+    row('Location', certificate.locationName),
+    row('Serial number', certificate.serialNumber),
+    row('Machine id', certificate.machineId),
+    row('Manufacturer', certificate.manufacturer),
+    row('Game', certificate.game),
+    row('SMIB', certificate.smibBoard),
+    '',
+    row('Retired', iso(certificate.retiredAt)),
+    row('Retired by', certificate.username),
+    row('Reason', certificate.reason),
+    '',
+    `Final meters (as of ${iso(certificate.finalMetersAt) || 'unknown'})`,
+    ...CERTIFICATE_METERS.map(({ field, label, cents }) =>
+      row(label, meterValue(finalMeters[field], cents))
+    ),
+    '',
+    'Final collection',
+    row('Report', finalCollection.locationReportId),
+    row('Collected', iso(finalCollection.collectedAt)),
+    row('Meters in', String(finalCollection.metersIn)),
+    row('Meters out', String(finalCollection.metersOut)),
+    '',
+    `Generated ${certificate.generatedAt.toISOString()}`,
+  ];
+  return `${lines.join('\n')}\n`;
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/**
+ * Formats a retirement plan for the command line.
+ */
+export function formatRetirementPlan(plan: MachineRetirementPlan): string {
+  const lines = [
+    `Machine ${plan.serialNumber || plan.machineId} (${plan.machineId}), ${plan.status}`,
+    `  Final meters (${iso(plan.finalMetersAt) || 'never reported'}): ${CERTIFICATE_METERS.map(
+      ({ field, label, cents }) =>
+        `${label.toLowerCase()} ${meterValue(plan.finalMeters[field], cents)}`
+    ).join(', ')}`,
+    plan.finalCollection
+      ? `  Final collection: report ${plan.finalCollection.locationReportId} on ${iso(plan.finalCollection.collectedAt)} (in ${plan.finalCollection.metersIn}, out ${plan.finalCollection.metersOut})`
+      : '  Final collection: none',
+  ];
+  if (plan.problems.length > 0) {
+    lines.push('  Cannot retire:');
+    plan.problems.forEach(problem => lines.push(`    - ${problem}`));
+  } else {
+    lines.push('  Ready to retire');
+  }
+  return lines.join('\n');
+}
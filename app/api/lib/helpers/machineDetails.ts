@@ -0,0 +1,519 @@
+/**
+ * Machine Details Helper
+ *
+ * Everything about one cabinet in a single typed result, for diagnosing a
+ * machine without stitching queries together: its configuration and
+ * assignment, current SAS and collection meters, meter movement and gross
+ * for a range (licencee's financial formula, metersDaily rollup with
+ * raw-meter fallback), its most recent events, collection history entries
+ * and open integrity issues.
+ *
+ * The machine is found by serial number (`serialNumber` or
+ * `origSerialNumber`, as typed or in upper or lower case) or by `_id`.
+ *
+ * Used by the `machine` command (scripts/machine.ts).
+ *
+ * @module app/api/lib/helpers/machineDetails
+ */
+
+import { ACTIVE_INTEGRITY_ISSUE_STATUSES } from '@/app/api/lib/helpers/integrityIssues';
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { normalizeAssetStatus } from '@/app/api/lib/helpers/machineLifecycle';
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { IntegrityIssue } from '@/app/api/lib/models/integrityIssue';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { MachineEvent } from '@/app/api/lib/models/machineEvents';
+import { Machine } from '@/app/api/lib/models/machines';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import { isMachineOnline } from '@/app/api/lib/utils/machineStatus';
+import { getSasCodeDictionary } from '@/app/api/lib/utils/sasCodes';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import {
+  decodeSasException,
+  type SasSeverity,
+} from '@/lib/utils/sas/exceptionCodes';
+import type {
+  FinancialMetrics,
+  MachineLifecycleStatus,
+  MachineStatusOverride,
+  MeterMovementField,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MachineDetailsParams = {
+  /** Serial number or machine `_id` */
+  serialOrId: string;
+  /** Time period (7d, 30d, Today, ...); 'Custom' with the dates below */
+  timePeriod: string;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  /** Most recent events to list (default DEFAULT_MACHINE_EVENTS) */
+  eventLimit?: number;
+  /** Latest collection history entries (default DEFAULT_MACHINE_HISTORY) */
+  historyLimit?: number;
+};
+
+export type MachineDetailsConfig = {
+  serialNumber: string | null;
+  origSerialNumber: string | null;
+  customName: string | null;
+  game: string | null;
+  gameType: string | null;
+  manufacturer: string | null;
+  cabinetType: string | null;
+  smibId: string | null;
+  smibFirmware: string | null;
+  sasVersion: string | null;
+  accountingDenomination: number | null;
+  theoreticalRtp: number | null;
+  payTableId: string | null;
+  maxBet: string | null;
+  meterUnit: string;
+  assetStatus: MachineLifecycleStatus;
+};
+
+export type MachineDetailsEvent = {
+  date: Date | null;
+  eventType: string | null;
+  logLevel: string | null;
+  description: string | null;
+  command: string | null;
+  /** Decoded SAS exception, when the command is one */
+  sasName: string | null;
+  sasSeverity: SasSeverity | null;
+};
+
+export type MachineDetailsHistoryEntry = {
+  timestamp: Date | null;
+  locationReportId: string | null;
+  metersIn: number;
+  metersOut: number;
+  prevMetersIn: number;
+  prevMetersOut: number;
+};
+
+export type MachineDetailsIssue = {
+  check: string;
+  field: string | null;
+  details: string;
+  detectedAt: Date;
+};
+
+export type MachineDetails = {
+  generatedAt: Date;
+  machineId: string;
+  locationId: string | null;
+  locationName: string | null;
+  licenceeId: string | null;
+  licenceeName: string | null;
+  online: boolean;
+  lastActivity: Date | null;
+  config: MachineDetailsConfig;
+  sasMeters: Record<string, number>;
+  lastSasMeterAt: Date | null;
+  collectionMeters: { metersIn: number; metersOut: number };
+  collectionTime: Date | null;
+  previousCollectionTime: Date | null;
+  rangeStart: Date | null;
+  rangeEnd: Date | null;
+  /** Movement for the range; null when the machine has no location */
+  movement:
+    | (FinancialMetrics &
+        Record<MeterMovementField, number> & { gamesPlayed: number })
+    | null;
+  events: MachineDetailsEvent[];
+  collectionHistory: MachineDetailsHistoryEntry[];
+  openIssues: MachineDetailsIssue[];
+};
+
+export const DEFAULT_MACHINE_EVENTS = 20;
+export const DEFAULT_MACHINE_HISTORY = 10;
+
+const SAS_METER_FIELDS = [
+  'coinIn',
+  'coinOut',
+  'drop',
+  'jackpot',
+  'totalCancelledCredits',
+  'totalHandPaidCancelledCredits',
+  'totalWonCredits',
+  'gamesPlayed',
+  'gamesWon',
+  'currentCredits',
+];
+
+export type DetailsMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  Custom?: { name?: string };
+  game?: string;
+  gameType?: string;
+  manufacturer?: string;
+  manuf?: string;
+  cabinetType?: string;
+  relayId?: string;
+  smibBoard?: string;
+  smibVersion?: { firmware?: string };
+  sasVersion?: string;
+  gameConfig?: {
+    accountingDenomination?: number;
+    theoreticalRtp?: number;
+    payTableId?: string;
+    maxBet?: string;
+  };
+  meterUnit?: string;
+  assetStatus?: string;
+  gamingLocation?: string;
+  lastActivity?: Date;
+  sasMeters?: Record<string, number | undefined>;
+  lastSasMeterAt?: Date;
+  collectionMeters?: { metersIn?: number; metersOut?: number };
+  collectionTime?: Date;
+  previousCollectionTime?: Date;
+  collectionMetersHistory?: Array<{
+    timestamp?: Date;
+    locationReportId?: string;
+    metersIn?: number;
+    metersOut?: number;
+    prevMetersIn?: number;
+    prevMetersOut?: number;
+  }>;
+};
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+// ============================================================================
+// Details
+// ============================================================================
+
+/**
+ * Finds a machine by serial number or `_id`.
+ *
+ * @throws Error with `statusCode` 404 when nothing matches, 400 when the
+ * serial matches several machines
+ */
+export async function resolveMachine(
+  serialOrId: string
+): Promise<DetailsMachine> {
+  const value = serialOrId.trim();
+  const variants = Array.from(
+    new Set([value, value.toUpperCase(), value.toLowerCase()])
+  );
+  const machines = await Machine.find({
+    ...NOT_DELETED_FILTER,
+    $or: [
+      { _id: value },
+      { serialNumber: { $in: variants } },
+      { origSerialNumber: { $in: variants } },
+    ],
+  }).lean<DetailsMachine[]>();
+
+  const byId = machines.find(machine => String(machine._id) === value);
+  if (byId) return byId;
+  if (machines.length === 0) {
+    throw statusError(`No machine with serial or id ${value}`, 404);
+  }
+  if (machines.length > 1) {
+    throw statusError(
+      `Serial ${value} matches ${machines.length} machines (${machines
+        .map(machine => machine._id)
+        .join(', ')}); pass the machine id`,
+      400
+    );
+  }
+  return machines[0];
+}
+
+/**
+ * Builds the machine deep-dive.
+ *
+ * @param params - Machine, range and list limits
+ * @returns Config, meters, movement, events, history and open issues
+ */
+export async function getMachineDetails(
+  params: MachineDetailsParams
+): Promise<MachineDetails> {
+  // Step 1: Machine, location and licencee
+  const machine = await resolveMachine(params.serialOrId);
+  const machineId = String(machine._id);
+  const locationId = machine.gamingLocation
+    ? String(machine.gamingLocation)
+    : null;
+  const location = locationId
+    ? await GamingLocations.findOne(
+        { _id: locationId },
+        { _id: 1, name: 1, gameDayOffset: 1, 'rel.licencee': 1 }
+      ).lean<{
+        _id: string;
+        name?: string;
+        gameDayOffset?: number;
+        rel?: { licencee?: string };
+      }>()
+    : null;
+  const licenceeId = location?.rel?.licencee
+    ? String(location.rel.licencee)
+    : null;
+  const [licencee, formulas] = await Promise.all([
+    licenceeId
+      ? Licencee.findOne(
+          { _id: licenceeId },
+          { name: 1, machineStatus: 1 }
+        ).lean<{ name?: string; machineStatus?: MachineStatusOverride }>()
+      : null,
+    getLicenceeFinancialFormulas(licenceeId ? [licenceeId] : []),
+  ]);
+  const formula =
+    (licenceeId && formulas.get(licenceeId)) || DEFAULT_FINANCIAL_FORMULA;
+
+  // Step 2: Movement for the range, events and open issues
+  const range =
+    location && locationId
+      ? getGamingDayRangeForPeriod(
+          params.timePeriod,
+          location.gameDayOffset ?? 8,
+          params.customStartDate,
+          params.customEndDate
+        )
+      : null;
+  const [totalsByMachine, events, issues] = await Promise.all([
+    range && locationId
+      ? getMovementTotalsWithRollup(new Map([[locationId, range]]), 'machine')
+      : null,
+    MachineEvent.find(
+      { machine: machineId },
+      {
+        date: 1,
+        eventType: 1,
+        eventLogLevel: 1,
+        description: 1,
+        command: 1,
+        createdAt: 1,
+      }
+    )
+      .sort({ date: -1 })
+      .limit(params.eventLimit ?? DEFAULT_MACHINE_EVENTS)
+      .lean<
+        Array<{
+          date?: Date;
+          createdAt?: Date;
+          eventType?: string;
+          eventLogLevel?: string;
+          description?: string;
+          command?: string;
+        }>
+      >(),
+    IntegrityIssue.find({
+      status: { $in: ACTIVE_INTEGRITY_ISSUE_STATUSES },
+      $or: [{ machine: machineId }, { resourceId: machineId }],
+    })
+      .sort({ detectedAt: -1 })
+      .lean<
+        Array<{
+          check: string;
+          field?: string | null;
+          details?: string;
+          detectedAt: Date;
+        }>
+      >(),
+  ]);
+
+  let movement: MachineDetails['movement'] = null;
+  if (totalsByMachine) {
+    const totals = totalsByMachine.get(machineId);
+    const metrics = calculateFinancialMetrics(totals || {}, formula);
+    const fields = Object.fromEntries(
+      METER_MOVEMENT_FIELDS.map(field => [
+        field,
+        round2(Number(totals?.[field]) || 0),
+      ])
+    ) as Record<MeterMovementField, number>;
+    movement = {
+      ...fields,
+      gamesPlayed: Number(totals?.gamesPlayed) || 0,
+      moneyIn: round2(metrics.moneyIn),
+      moneyOut: round2(metrics.moneyOut),
+      jackpot: round2(metrics.jackpot),
+      gross: round2(metrics.gross),
+      netGross: round2(metrics.netGross),
+    };
+  }
+
+  // Step 3: Shape the result
+  const dictionary = getSasCodeDictionary();
+  const lastActivity = machine.lastActivity
+    ? new Date(machine.lastActivity)
+    : null;
+  const sas = machine.sasMeters || {};
+
+  return {
+    generatedAt: new Date(),
+    machineId,
+    locationId,
+    locationName: location?.name || null,
+    licenceeId,
+    licenceeName: licencee?.name || null,
+    online: isMachineOnline(lastActivity, licencee?.machineStatus),
+    lastActivity,
+    config: {
+      serialNumber: machine.serialNumber?.trim() || null,
+      origSerialNumber: machine.origSerialNumber?.trim() || null,
+      customName: machine.custom?.name || machine.Custom?.name || null,
+      game: machine.game || null,
+      gameType: machine.gameType || null,
+      manufacturer: machine.manufacturer || machine.manuf || null,
+      cabinetType: machine.cabinetType || null,
+      smibId: machine.relayId || machine.smibBoard || null,
+      smibFirmware: machine.smibVersion?.firmware || null,
+      sasVersion: machine.sasVersion || null,
+      accountingDenomination:
+        machine.gameConfig?.accountingDenomination ?? null,
+      theoreticalRtp: machine.gameConfig?.theoreticalRtp ?? null,
+      payTableId: machine.gameConfig?.payTableId || null,
+      maxBet: machine.gameConfig?.maxBet || null,
+      meterUnit: machine.meterUnit || 'dollars',
+      assetStatus: normalizeAssetStatus(machine.assetStatus),
+    },
+    sasMeters: Object.fromEntries(
+      SAS_METER_FIELDS.map(field => [field, Number(sas[field]) || 0])
+    ),
+    lastSasMeterAt: machine.lastSasMeterAt
+      ? new Date(machine.lastSasMeterAt)
+      : null,
+    collectionMeters: {
+      metersIn: Number(machine.collectionMeters?.metersIn) || 0,
+      metersOut: Number(machine.collectionMeters?.metersOut) || 0,
+    },
+    collectionTime: machine.collectionTime
+      ? new Date(machine.collectionTime)
+      : null,
+    previousCollectionTime: machine.previousCollectionTime
+      ? new Date(machine.previousCollectionTime)
+      : null,
+    rangeStart: range?.rangeStart ?? null,
+    rangeEnd: range?.rangeEnd ?? null,
+    movement,
+    events: events.map(event => {
+      const decoded = decodeSasException(event.command, dictionary);
+      return {
+        date: event.date || event.createdAt || null,
+        eventType: event.eventType || null,
+        logLevel: event.eventLogLevel || null,
+        description: event.description || null,
+        command: event.command || null,
+        sasName: decoded?.name ?? null,
+        sasSeverity: decoded?.severity ?? null,
+      };
+    }),
+    collectionHistory: (machine.collectionMetersHistory || [])
+      .slice()
+      .sort(
+        (a, b) =>
+          new Date(b.timestamp || 0).getTime() -
+          new Date(a.timestamp || 0).getTime()
+      )
+      .slice(0, params.historyLimit ?? DEFAULT_MACHINE_HISTORY)
+      .map(entry => ({
+        timestamp: entry.timestamp ? new Date(entry.timestamp) : null,
+        locationReportId: entry.locationReportId || null,
+        metersIn: Number(entry.metersIn) || 0,
+        metersOut: Number(entry.metersOut) || 0,
+        prevMetersIn: Number(entry.prevMetersIn) || 0,
+        prevMetersOut: Number(entry.prevMetersOut) || 0,
+      })),
+    openIssues: issues.map(issue => ({
+      check: issue.check,
+      field: issue.field ?? null,
+      details: issue.details || '',
+      detectedAt: issue.detectedAt,
+    })),
+  };
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+function formatDate(value: Date | null): string {
+  return value ? new Date(value).toISOString() : '-';
+}
+
+/**
+ * Formats the deep-dive for the terminal, one section per heading.
+ */
+export function formatMachineDetails(details: MachineDetails): string {
+  const { config, movement } = details;
+  const configLines = Object.entries(config).map(
+    ([key, value]) => `  ${key.padEnd(24)} ${value ?? '-'}`
+  );
+
+  const lines = [
+    `${config.serialNumber || details.machineId} (${details.machineId})`,
+    `${details.locationName || '(no location)'}${
+      details.licenceeName ? ` - ${details.licenceeName}` : ''
+    }; ${details.online ? 'online' : 'offline'}, last activity ${formatDate(details.lastActivity)}`,
+    '',
+    'Configuration:',
+    ...configLines,
+    '',
+    `SAS meters (last read ${formatDate(details.lastSasMeterAt)}):`,
+    ...Object.entries(details.sasMeters).map(
+      ([field, value]) => `  ${field.padEnd(30)} ${value}`
+    ),
+    '',
+    `Collection meters: in ${details.collectionMeters.metersIn}, out ${details.collectionMeters.metersOut} (collected ${formatDate(details.collectionTime)}, previous ${formatDate(details.previousCollectionTime)})`,
+    '',
+  ];
+
+  if (movement) {
+    lines.push(
+      `Movement ${formatDate(details.rangeStart)} .. ${formatDate(details.rangeEnd)}:`,
+      ...METER_MOVEMENT_FIELDS.map(
+        field => `  ${field.padEnd(30)} ${movement[field].toFixed(2)}`
+      ),
+      `  ${'gamesPlayed'.padEnd(30)} ${movement.gamesPlayed}`,
+      `  Money in ${movement.moneyIn.toFixed(2)}, money out ${movement.moneyOut.toFixed(2)}, gross ${movement.gross.toFixed(2)}`
+    );
+  } else {
+    lines.push('Movement: machine has no location');
+  }
+
+  lines.push(
+    '',
+    `Recent events (${details.events.length}):`,
+    ...details.events.map(
+      event =>
+        `  ${formatDate(event.date)}  ${(event.logLevel || '-').padEnd(8)} ${
+          event.sasName
+            ? `${event.sasName} [${event.sasSeverity}]`
+            : event.description || event.eventType || event.command || '-'
+        }`
+    ),
+    '',
+    `Collection history (${details.collectionHistory.length}):`,
+    ...details.collectionHistory.map(
+      entry =>
+        `  ${formatDate(entry.timestamp)}  in ${entry.prevMetersIn} -> ${entry.metersIn}, out ${entry.prevMetersOut} -> ${entry.metersOut}  report ${entry.locationReportId ?? '-'}`
+    ),
+    '',
+    `Open integrity issues (${details.openIssues.length}):`,
+    ...details.openIssues.map(
+      issue =>
+        `  [${issue.check}${issue.field ? `:${issue.field}` : ''}] ${issue.details || '-'}`
+    )
+  );
+  return lines.join('\n');
+}
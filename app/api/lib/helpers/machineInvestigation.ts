@@ -13,6 +13,7 @@ import type { MachineWithHistory } from '../types';
 import { Collections } from '../models/collections';
 import { Machine } from '../models/machines';
 import { CollectionReport } from '../models/collectionReport';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 type HistoryAnalysisEntry = {
   entryIndex: number;
@@ -114,7 +115,7 @@ export async function investigateSpecificMachine(machineId: string): Promise<{
   // Get all collections for this machine, sorted by timestamp
   const collections = await Collections.find({
     machineId: machineId,
-    $or: [{ deletedAt: { $exists: false } }, { deletedAt: null }],
+    ...NOT_DELETED_FILTER,
   })
     .sort({ timestamp: 1 })
     .lean<CollectionDocument[]>();
@@ -0,0 +1,189 @@
+/**
+ * Machine Lifecycle Helper
+ *
+ * Moves a machine's `assetStatus` through its lifecycle:
+ *
+ *   active ⇄ in-repair ⇄ storage → retired
+ *   active ⇄ storage
+ *
+ * Retired is terminal. Legacy values (`functional`, `Active`, empty) are
+ * treated as `active`. Every change appends an entry (from, to, reason, who,
+ * when) to the machine's `statusHistory` and is written to the activity log.
+ * Every machine aggregation (cabinet and location totals, dashboard and
+ * online/offline counts, the utilization, idle, meters and uncollected drop
+ * reports) and the machine integrity checks exclude retired machines
+ * (`NOT_RETIRED_FILTER`). Retiring through machineDecommission also records
+ * the final meters and collection.
+ *
+ * Used by the `machine-status` command (scripts/machine-status.ts).
+ *
+ * @module app/api/lib/helpers/machineLifecycle
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { Machine } from '@/app/api/lib/models/machines';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type { MachineLifecycleStatus } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const MACHINE_LIFECYCLE_STATUSES: MachineLifecycleStatus[] = [
+  'active',
+  'in-repair',
+  'storage',
+  'retired',
+];
+
+/** Allowed next statuses for each status */
+export const MACHINE_STATUS_TRANSITIONS: Record<
+  MachineLifecycleStatus,
+  MachineLifecycleStatus[]
+> = {
+  active: ['in-repair', 'storage'],
+  'in-repair': ['active', 'storage'],
+  storage: ['active', 'in-repair', 'retired'],
+  retired: [],
+};
+
+/** Machine filter that leaves out retired machines */
+export const NOT_RETIRED_FILTER = { assetStatus: { $ne: 'retired' } };
+
+export type MachineStatusChange = {
+  machineId: string;
+  to: MachineLifecycleStatus;
+  reason: string;
+  userId: string;
+  username: string;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Maps a stored `assetStatus` to a lifecycle status; unknown and legacy
+ * values count as `active`.
+ */
+export function normalizeAssetStatus(
+  value: string | null | undefined
+): MachineLifecycleStatus {
+  const normalized = (value || '')
+    .trim()
+    .toLowerCase()
+    .replace(/[\s_]+/g, '-');
+  return MACHINE_LIFECYCLE_STATUSES.includes(
+    normalized as MachineLifecycleStatus
+  )
+    ? (normalized as MachineLifecycleStatus)
+    : 'active';
+}
+
+/**
+ * @returns An error message when the transition is not allowed, else null
+ */
+export function validateStatusTransition(
+  from: MachineLifecycleStatus,
+  to: MachineLifecycleStatus
+): string | null {
+  if (!MACHINE_LIFECYCLE_STATUSES.includes(to)) {
+    return `Status must be one of ${MACHINE_LIFECYCLE_STATUSES.join(', ')}`;
+  }
+  if (from === to) return `Machine is already ${to}`;
+  const allowed = MACHINE_STATUS_TRANSITIONS[from];
+  if (!allowed.includes(to)) {
+    return allowed.length === 0
+      ? `A ${from} machine cannot change status`
+      : `Cannot go from ${from} to ${to} (allowed: ${allowed.join(', ')})`;
+  }
+  return null;
+}
+
+// ============================================================================
+// Transition
+// ============================================================================
+
+/**
+ * Changes a machine's lifecycle status after validating the transition.
+ * The update is conditional on the status read, so a concurrent change makes
+ * it fail instead of being overwritten.
+ *
+ * @param change - Machine, target status, reason and acting user
+ * @param set - Further fields written with the status (e.g. `decommission`)
+ * @returns Previous and new status
+ * @throws Error with `statusCode` 400 (invalid), 404 (not found) or 409 (changed concurrently)
+ */
+export async function changeMachineAssetStatus(
+  change: MachineStatusChange,
+  set: Record<string, unknown> = {}
+): Promise<{ from: MachineLifecycleStatus; to: MachineLifecycleStatus }> {
+  if (!change.reason.trim()) throw statusError('A reason is required', 400);
+  assertWritable('changing machine status');
+
+  const machine = await Machine.findOne(
+    { _id: change.machineId },
+    { _id: 1, assetStatus: 1, serialNumber: 1 }
+  ).lean<{ _id: string; assetStatus?: string; serialNumber?: string }>();
+  if (!machine) {
+    throw statusError(`Machine ${change.machineId} not found`, 404);
+  }
+
+  const storedStatus = machine.assetStatus;
+  const from = normalizeAssetStatus(storedStatus);
+  const invalid = validateStatusTransition(from, change.to);
+  if (invalid) throw statusError(invalid, 400);
+
+  await backupBeforeWrite({
+    operation: 'machine-status',
+    collection: Machine.collection.collectionName,
+    filter: { _id: change.machineId },
+    by: change.username,
+  });
+  const changedAt = new Date();
+  const result = await Machine.updateOne(
+    {
+      _id: change.machineId,
+      assetStatus:
+        storedStatus === undefined ? { $exists: false } : storedStatus,
+    },
+    {
+      $set: { ...set, assetStatus: change.to },
+      $push: {
+        statusHistory: {
+          from,
+          to: change.to,
+          reason: change.reason.trim(),
+          userId: change.userId,
+          username: change.username,
+          changedAt,
+        },
+      },
+    }
+  );
+  if (result.matchedCount === 0) {
+    throw statusError(
+      'Machine status changed concurrently; reload and retry',
+      409
+    );
+  }
+
+  await logActivity({
+    action: 'update',
+    details: `Changed machine ${
+      machine.serialNumber || change.machineId
+    } status from ${from} to ${change.to}: ${change.reason.trim()}`,
+    userId: change.userId,
+    username: change.username,
+    metadata: {
+      resource: 'machine',
+      resourceId: change.machineId,
+      resourceName: machine.serialNumber || change.machineId,
+      changes: [{ field: 'assetStatus', oldValue: from, newValue: change.to }],
+    },
+  });
+
+  return { from, to: change.to };
+}
@@ -0,0 +1,358 @@
+/**
+ * Machine Lookup Helper
+ *
+ * Looks up a batch of machines by serial number — a list pasted from a
+ * spreadsheet or read from a file — and returns, for each one, its location,
+ * licencee, status and meter summary, plus the serials that matched nothing.
+ *
+ * Serials are matched on `serialNumber` or `origSerialNumber` as typed or
+ * in upper or lower case. A serial shared by several machines returns all
+ * of them. Archived machines are not matched.
+ *
+ * Used by `POST /api/machines/lookup` and the `machines:lookup` command
+ * (scripts/lookup-machines.ts).
+ *
+ * @module app/api/lib/helpers/machineLookup
+ */
+
+import { normalizeAssetStatus } from '@/app/api/lib/helpers/machineLifecycle';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { isMachineOnline } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type {
+  MachineLifecycleStatus,
+  MachineStatusOverride,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MachineLookupRow = {
+  /** Serial as requested */
+  serial: string;
+  machineId: string;
+  serialNumber: string;
+  customName: string | null;
+  game: string | null;
+  smibId: string | null;
+  locationId: string | null;
+  locationName: string | null;
+  licenceeId: string | null;
+  licenceeName: string | null;
+  assetStatus: MachineLifecycleStatus;
+  online: boolean;
+  lastActivity: Date | null;
+  /** Lifetime SAS meters */
+  meters: {
+    coinIn: number;
+    coinOut: number;
+    drop: number;
+    totalCancelledCredits: number;
+    jackpot: number;
+    gamesPlayed: number;
+  };
+  collectionMeters: { metersIn: number; metersOut: number };
+  collectionTime: Date | null;
+};
+
+export type MachineLookupResult = {
+  requested: number;
+  found: MachineLookupRow[];
+  /** Requested serials with no matching machine */
+  notFound: string[];
+  /** Requested serials matching more than one machine */
+  ambiguous: string[];
+};
+
+/** Largest batch accepted in one lookup */
+export const MAX_LOOKUP_SERIALS = 5000;
+
+type LookupMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  Custom?: { name?: string };
+  game?: string;
+  relayId?: string;
+  smibBoard?: string;
+  gamingLocation?: string;
+  assetStatus?: string;
+  lastActivity?: Date;
+  sasMeters?: Record<string, number | undefined>;
+  collectionMeters?: { metersIn?: number; metersOut?: number };
+  collectionTime?: Date;
+};
+
+// ============================================================================
+// Lookup
+// ============================================================================
+
+/**
+ * Splits pasted or file text into serials: one per line, or separated by
+ * commas, semicolons, tabs or spaces. Blank entries and repeats are dropped.
+ */
+export function parseSerialList(text: string): string[] {
+  const seen = new Set<string>();
+  return text
+    .split(/[\s,;]+/)
+    .map(serial => serial.trim())
+    .filter(serial => {
+      const key = serial.toUpperCase();
+      if (!serial || seen.has(key)) return false;
+      seen.add(key);
+      return true;
+    });
+}
+
+/**
+ * Looks up machines for a list of serials.
+ *
+ * @param serials - Serial numbers to look up
+ * @param allowedLocationIds - Locations the caller may see ('all' for no limit)
+ * @returns Matched machines in request order, and the serials not found
+ * @throws Error with `statusCode` 400 when the list is empty or too long
+ */
+export async function lookupMachinesBySerial(
+  serials: string[],
+  allowedLocationIds: string[] | 'all' = 'all'
+): Promise<MachineLookupResult> {
+  const requested = parseSerialList(serials.join('\n'));
+  if (requested.length === 0) {
+    throw statusError('Pass at least one serial number', 400);
+  }
+  if (requested.length > MAX_LOOKUP_SERIALS) {
+    throw statusError(
+      `At most ${MAX_LOOKUP_SERIALS} serial numbers can be looked up at once`,
+      400
+    );
+  }
+
+  // Step 1: Find the machines (as typed, upper- and lower-case)
+  const variants = Array.from(
+    new Set(
+      requested.flatMap(serial => [
+        serial,
+        serial.toUpperCase(),
+        serial.toLowerCase(),
+      ])
+    )
+  );
+  const query: Record<string, unknown> = {
+    ...NOT_DELETED_FILTER,
+    $or: [
+      { serialNumber: { $in: variants } },
+      { origSerialNumber: { $in: variants } },
+    ],
+  };
+  if (allowedLocationIds !== 'all') {
+    query.gamingLocation = { $in: allowedLocationIds };
+  }
+  const machines = await Machine.find(query, {
+    _id: 1,
+    serialNumber: 1,
+    origSerialNumber: 1,
+    'custom.name': 1,
+    'Custom.name': 1,
+    game: 1,
+    relayId: 1,
+    smibBoard: 1,
+    gamingLocation: 1,
+    assetStatus: 1,
+    lastActivity: 1,
+    sasMeters: 1,
+    collectionMeters: 1,
+    collectionTime: 1,
+  }).lean<LookupMachine[]>();
+
+  // Step 2: Resolve locations and licencees
+  const locationIds = Array.from(
+    new Set(
+      machines
+        .map(machine => String(machine.gamingLocation || ''))
+        .filter(Boolean)
+    )
+  );
+  const locations = await GamingLocations.find(
+    { _id: { $in: locationIds } },
+    { _id: 1, name: 1, 'rel.licencee': 1 }
+  ).lean<Array<{ _id: string; name?: string; rel?: { licencee?: string } }>>();
+  const locationById = new Map(
+    locations.map(location => [String(location._id), location])
+  );
+  const licenceeIds = Array.from(
+    new Set(
+      locations
+        .map(location => location.rel?.licencee)
+        .filter((id): id is string => Boolean(id))
+        .map(String)
+    )
+  );
+  const licencees = await Licencee.find(
+    { _id: { $in: licenceeIds } },
+    { _id: 1, name: 1, machineStatus: 1 }
+  ).lean<
+    Array<{
+      _id: string;
+      name?: string;
+      machineStatus?: MachineStatusOverride;
+    }>
+  >();
+  const licenceeById = new Map(
+    licencees.map(licencee => [String(licencee._id), licencee])
+  );
+
+  // Step 3: Match each requested serial, keeping request order
+  const found: MachineLookupRow[] = [];
+  const notFound: string[] = [];
+  const ambiguous: string[] = [];
+  requested.forEach(serial => {
+    const key = serial.toUpperCase();
+    const matches = machines.filter(
+      machine =>
+        machine.serialNumber?.trim().toUpperCase() === key ||
+        machine.origSerialNumber?.trim().toUpperCase() === key
+    );
+    if (matches.length === 0) {
+      notFound.push(serial);
+      return;
+    }
+    if (matches.length > 1) ambiguous.push(serial);
+
+    matches.forEach(machine => {
+      const locationId = machine.gamingLocation
+        ? String(machine.gamingLocation)
+        : null;
+      const location = locationId ? locationById.get(locationId) : undefined;
+      const licenceeId = location?.rel?.licencee
+        ? String(location.rel.licencee)
+        : null;
+      const licencee = licenceeId ? licenceeById.get(licenceeId) : undefined;
+      const lastActivity = machine.lastActivity
+        ? new Date(machine.lastActivity)
+        : null;
+      const sas = machine.sasMeters || {};
+
+      found.push({
+        serial,
+        machineId: String(machine._id),
+        serialNumber:
+          machine.serialNumber?.trim() ||
+          machine.origSerialNumber?.trim() ||
+          serial,
+        customName: machine.custom?.name || machine.Custom?.name || null,
+        game: machine.game || null,
+        smibId: machine.relayId || machine.smibBoard || null,
+        locationId,
+        locationName: location?.name || null,
+        licenceeId,
+        licenceeName: licencee?.name || null,
+        assetStatus: normalizeAssetStatus(machine.assetStatus),
+        online: isMachineOnline(lastActivity, licencee?.machineStatus),
+        lastActivity,
+        meters: {
+          coinIn: Number(sas.coinIn) || 0,
+          coinOut: Number(sas.coinOut) || 0,
+          drop: Number(sas.drop) || 0,
+          totalCancelledCredits: Number(sas.totalCancelledCredits) || 0,
+          jackpot: Number(sas.jackpot) || 0,
+          gamesPlayed: Number(sas.gamesPlayed) || 0,
+        },
+        collectionMeters: {
+          metersIn: Number(machine.collectionMeters?.metersIn) || 0,
+          metersOut: Number(machine.collectionMeters?.metersOut) || 0,
+        },
+        collectionTime: machine.collectionTime
+          ? new Date(machine.collectionTime)
+          : null,
+      });
+    });
+  });
+
+  return { requested: requested.length, found, notFound, ambiguous };
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+const CSV_COLUMNS: Array<[string, (row: MachineLookupRow) => unknown]> = [
+  ['Serial', row => row.serial],
+  ['Machine ID', row => row.machineId],
+  ['Custom Name', row => row.customName],
+  ['Game', row => row.game],
+  ['SMIB ID', row => row.smibId],
+  ['Location', row => row.locationName],
+  ['Location ID', row => row.locationId],
+  ['Licencee', row => row.licenceeName],
+  ['Status', row => row.assetStatus],
+  ['Online', row => (row.online ? 'yes' : 'no')],
+  ['Last Activity', row => row.lastActivity?.toISOString()],
+  ['Coin In', row => row.meters.coinIn],
+  ['Coin Out', row => row.meters.coinOut],
+  ['Drop', row => row.meters.drop],
+  ['Cancelled Credits', row => row.meters.totalCancelledCredits],
+  ['Jackpot', row => row.meters.jackpot],
+  ['Games Played', row => row.meters.gamesPlayed],
+  ['Collection Meters In', row => row.collectionMeters.metersIn],
+  ['Collection Meters Out', row => row.collectionMeters.metersOut],
+  ['Last Collection', row => row.collectionTime?.toISOString()],
+];
+
+function csvCell(value: unknown): string {
+  const text = value === null || value === undefined ? '' : String(value);
+  return /[",\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+}
+
+/**
+ * One CSV row per machine found, then one `NOT FOUND` row per missing
+ * serial so the file lines up with the pasted list.
+ */
+export function exportMachineLookupToCSV(result: MachineLookupResult): string {
+  const lines = [CSV_COLUMNS.map(([header]) => header).join(',')];
+  result.found.forEach(row =>
+    lines.push(CSV_COLUMNS.map(([, value]) => csvCell(value(row))).join(','))
+  );
+  result.notFound.forEach(serial =>
+    lines.push(
+      [
+        csvCell(serial),
+        'NOT FOUND',
+        ...CSV_COLUMNS.slice(2).map(() => ''),
+      ].join(',')
+    )
+  );
+  return lines.join('\n');
+}
+
+/**
+ * Formats a lookup for the terminal.
+ */
+export function formatMachineLookup(result: MachineLookupResult): string {
+  const matched = result.requested - result.notFound.length;
+  const lines = [
+    `Found ${result.found.length} machine(s) for ${matched} of ${result.requested} serial(s)`,
+    ...result.found.map(row =>
+      [
+        `  ${row.serial.padEnd(20)}`,
+        (row.locationName || '(no location)').padEnd(24),
+        (row.licenceeName || '-').padEnd(16),
+        row.assetStatus.padEnd(10),
+        (row.online ? 'online' : 'offline').padEnd(8),
+        `drop ${row.meters.drop.toFixed(2)}`,
+        `cancelled ${row.meters.totalCancelledCredits.toFixed(2)}`,
+      ].join(' ')
+    ),
+  ];
+  if (result.notFound.length > 0) {
+    lines.push(`Not found: ${result.notFound.join(', ')}`);
+  }
+  if (result.ambiguous.length > 0) {
+    lines.push(`Ambiguous (several machines): ${result.ambiguous.join(', ')}`);
+  }
+  return lines.join('\n');
+}
@@ -0,0 +1,357 @@
+/**
+ * Machine Move Helper
+ *
+ * Reassigns machines to another location in bulk — a list of serial numbers,
+ * or every machine at a source location — instead of editing each document.
+ * `planMachineMove()` resolves and validates the move without writing, so it
+ * doubles as the dry run; `executeMachineMove()` applies it.
+ *
+ * Each machine's `gamingLocation` update is conditional on the location it
+ * was planned from, so a machine moved concurrently is skipped rather than
+ * overwritten. One completed `movementrequests` entry is recorded per source
+ * location (the same shape the movement request modal creates) and each move
+ * is written to the activity log. The planned machines are backed up before
+ * any of them is moved.
+ *
+ * Used by the `machines:move` command (scripts/move-machines.ts).
+ *
+ * @module app/api/lib/helpers/machineMove
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { MovementRequest } from '@/app/api/lib/models/movementrequests';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type MachineMoveRequest = {
+  toLocationId: string;
+  /** Serial numbers to move (matched on serialNumber or origSerialNumber) */
+  serials?: string[];
+  /** Move every machine at this location instead */
+  fromLocationId?: string;
+};
+
+export type PlannedMachineMove = {
+  machineId: string;
+  serialNumber: string;
+  fromLocationId: string;
+  fromLocationName: string;
+};
+
+export type MachineMovePlan = {
+  toLocationId: string;
+  toLocationName: string;
+  moves: PlannedMachineMove[];
+  /** Requested serials with no matching machine */
+  notFound: string[];
+  /** Serials matching more than one machine; left out of the move */
+  ambiguous: string[];
+  /** Machines already at the target location */
+  alreadyThere: string[];
+};
+
+export type MachineMoveResult = {
+  moved: PlannedMachineMove[];
+  /** Machines whose location changed after planning; not moved */
+  skipped: PlannedMachineMove[];
+  movementRequestIds: string[];
+  /** Backup run holding the machines as they were before the move */
+  backupRunId: string;
+};
+
+export type MachineMoveActor = {
+  reason: string;
+  userId: string;
+  username: string;
+};
+
+type MoveCandidate = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  gamingLocation?: string;
+};
+
+function getSerial(machine: MoveCandidate): string {
+  return (
+    machine.serialNumber?.trim() ||
+    machine.origSerialNumber?.trim() ||
+    String(machine._id)
+  );
+}
+
+// ============================================================================
+// Plan
+// ============================================================================
+
+/**
+ * Resolves which machines a move covers, without writing anything.
+ *
+ * @param request - Target location and either serials or a source location
+ * @returns The machines to move and any serials that could not be used
+ * @throws Error with `statusCode` 400 (invalid request) or 404 (location not found)
+ */
+export async function planMachineMove(
+  request: MachineMoveRequest
+): Promise<MachineMovePlan> {
+  const serials = Array.from(
+    new Set((request.serials || []).map(serial => serial.trim()))
+  ).filter(Boolean);
+  if (serials.length === 0 && !request.fromLocationId) {
+    throw statusError('Pass serial numbers or a source location', 400);
+  }
+  if (serials.length > 0 && request.fromLocationId) {
+    throw statusError(
+      'Pass serial numbers or a source location, not both',
+      400
+    );
+  }
+  if (request.fromLocationId === request.toLocationId) {
+    throw statusError('Source and target location are the same', 400);
+  }
+
+  // Step 1: Validate the locations
+  const softDeleteFilter = { ...NOT_DELETED_FILTER };
+  const locationIds = [request.toLocationId, request.fromLocationId].filter(
+    (id): id is string => Boolean(id)
+  );
+  const locations = await GamingLocations.find(
+    { _id: { $in: locationIds }, ...softDeleteFilter },
+    { _id: 1, name: 1 }
+  ).lean<Array<{ _id: string; name?: string }>>();
+  const locationNames = new Map(
+    locations.map(location => [
+      String(location._id),
+      location.name || String(location._id),
+    ])
+  );
+  locationIds.forEach(id => {
+    if (!locationNames.has(id)) {
+      throw statusError(`Location ${id} not found`, 404);
+    }
+  });
+
+  // Step 2: Find the machines
+  const machineFilter = request.fromLocationId
+    ? { gamingLocation: request.fromLocationId }
+    : {
+        $or: [
+          { serialNumber: { $in: serials } },
+          { origSerialNumber: { $in: serials } },
+        ],
+      };
+  const machines = await Machine.find(
+    { ...machineFilter, ...softDeleteFilter },
+    { _id: 1, serialNumber: 1, origSerialNumber: 1, gamingLocation: 1 }
+  ).lean<MoveCandidate[]>();
+
+  // Step 3: Match serials to machines
+  const notFound: string[] = [];
+  const ambiguous: string[] = [];
+  let selected = machines;
+  if (serials.length > 0) {
+    selected = [];
+    serials.forEach(serial => {
+      const matches = machines.filter(
+        machine =>
+          machine.serialNumber?.trim() === serial ||
+          machine.origSerialNumber?.trim() === serial
+      );
+      if (matches.length === 0) notFound.push(serial);
+      else if (matches.length > 1) ambiguous.push(serial);
+      else if (!selected.includes(matches[0])) selected.push(matches[0]);
+    });
+  }
+
+  // Step 4: Look up source location names
+  const unknownSources = Array.from(
+    new Set(
+      selected
+        .map(machine => String(machine.gamingLocation || ''))
+        .filter(id => id && !locationNames.has(id))
+    )
+  );
+  if (unknownSources.length > 0) {
+    const sources = await GamingLocations.find(
+      { _id: { $in: unknownSources } },
+      { _id: 1, name: 1 }
+    ).lean<Array<{ _id: string; name?: string }>>();
+    sources.forEach(location =>
+      locationNames.set(
+        String(location._id),
+        location.name || String(location._id)
+      )
+    );
+  }
+
+  const alreadyThere: string[] = [];
+  const moves: PlannedMachineMove[] = [];
+  selected.forEach(machine => {
+    const fromLocationId = String(machine.gamingLocation || '');
+    if (fromLocationId === request.toLocationId) {
+      alreadyThere.push(getSerial(machine));
+      return;
+    }
+    moves.push({
+      machineId: String(machine._id),
+      serialNumber: getSerial(machine),
+      fromLocationId,
+      fromLocationName:
+        locationNames.get(fromLocationId) || fromLocationId || '(none)',
+    });
+  });
+
+  return {
+    toLocationId: request.toLocationId,
+    toLocationName: locationNames.get(request.toLocationId) as string,
+    moves,
+    notFound,
+    ambiguous,
+    alreadyThere,
+  };
+}
+
+// ============================================================================
+// Execute
+// ============================================================================
+
+/**
+ * Applies a planned move: updates each machine's location, records one
+ * completed movement request per source location and logs each move.
+ *
+ * @param plan - From `planMachineMove()`
+ * @param actor - Reason and acting user
+ * @returns Machines moved, machines skipped and the movement requests created
+ * @throws Error with `statusCode` 400 when no reason is given
+ */
+export async function executeMachineMove(
+  plan: MachineMovePlan,
+  actor: MachineMoveActor
+): Promise<MachineMoveResult> {
+  if (!actor.reason.trim()) throw statusError('A reason is required', 400);
+  assertWritable('moving machines');
+
+  const backup = await backupBeforeWrite({
+    operation: 'machine-move',
+    collection: Machine.collection.collectionName,
+    filter: { _id: { $in: plan.moves.map(move => move.machineId) } },
+    by: actor.username,
+  });
+
+  const moved: PlannedMachineMove[] = [];
+  const skipped: PlannedMachineMove[] = [];
+
+  // Step 1: Move each machine, guarded on its planned source location
+  for (const move of plan.moves) {
+    const result = await Machine.updateOne(
+      {
+        _id: move.machineId,
+        gamingLocation: move.fromLocationId || { $in: [null, ''] },
+      },
+      { $set: { gamingLocation: plan.toLocationId, updatedAt: new Date() } }
+    );
+    if (result.matchedCount === 0) {
+      skipped.push(move);
+      continue;
+    }
+    moved.push(move);
+
+    await logActivity({
+      action: 'update',
+      details: `Moved machine ${move.serialNumber} from ${move.fromLocationName} to ${plan.toLocationName}: ${actor.reason.trim()}`,
+      userId: actor.userId,
+      username: actor.username,
+      metadata: {
+        resource: 'machine',
+        resourceId: move.machineId,
+        resourceName: move.serialNumber,
+        changes: [
+          {
+            field: 'gamingLocation',
+            oldValue: move.fromLocationId,
+            newValue: plan.toLocationId,
+          },
+        ],
+      },
+    });
+  }
+
+  // Step 2: One completed movement request per source location
+  const bySource = new Map<string, PlannedMachineMove[]>();
+  moved.forEach(move => {
+    bySource.set(move.fromLocationId, [
+      ...(bySource.get(move.fromLocationId) || []),
+      move,
+    ]);
+  });
+
+  const movementRequestIds: string[] = [];
+  for (const [fromLocationId, moves] of bySource) {
+    const now = new Date();
+    const _id = await generateMongoId();
+    await MovementRequest.create({
+      _id,
+      variance: 0,
+      previousBalance: 0,
+      currentBalance: 0,
+      amountToCollect: 0,
+      amountCollected: 0,
+      amountUncollected: 0,
+      partnerProfit: 0,
+      taxes: 0,
+      advance: 0,
+      locationName: moves[0].fromLocationName,
+      locationFrom: moves[0].fromLocationName,
+      locationTo: plan.toLocationName,
+      locationId: fromLocationId || plan.toLocationId,
+      locationFromId: fromLocationId || undefined,
+      locationToId: plan.toLocationId,
+      selectedMachines: moves.map(move => move.machineId),
+      requestTo: actor.userId,
+      reason: actor.reason.trim(),
+      cabinetIn: moves.map(move => move.serialNumber).join(','),
+      status: 'completed',
+      createdBy: actor.userId,
+      movementType: 'machine',
+      installationType: 'move',
+      timestamp: now,
+      createdAt: now,
+      updatedAt: now,
+    });
+    movementRequestIds.push(_id);
+  }
+
+  return { moved, skipped, movementRequestIds, backupRunId: backup.runId };
+}
+
+/**
+ * Formats a plan for the terminal (the dry-run output).
+ */
+export function formatMachineMovePlan(plan: MachineMovePlan): string {
+  const lines = [
+    `Move ${plan.moves.length} machine(s) to ${plan.toLocationName} (${plan.toLocationId})`,
+    ...plan.moves.map(
+      move =>
+        `  ${move.serialNumber.padEnd(20)} ${move.fromLocationName} -> ${plan.toLocationName}`
+    ),
+  ];
+  if (plan.alreadyThere.length > 0) {
+    lines.push(`Already there: ${plan.alreadyThere.join(', ')}`);
+  }
+  if (plan.notFound.length > 0) {
+    lines.push(`Not found: ${plan.notFound.join(', ')}`);
+  }
+  if (plan.ambiguous.length > 0) {
+    lines.push(`Ambiguous (several machines): ${plan.ambiguous.join(', ')}`);
+  }
+  return lines.join('\n');
+}
@@ -0,0 +1,583 @@
+/**
+ * Machine Reconfiguration Helper
+ *
+ * Records game / denomination changes (a game conversion, a new paytable, a
+ * denomination change) on a machine and reports its meters split at those
+ * changes, so performance before and after a conversion can be compared.
+ *
+ * Each change is appended to the machine's `configurationHistory` (what
+ * changed, when it took effect, why, who), applied to the machine's current
+ * configuration and written to the activity log; the machine is backed up
+ * first. Reports aggregate the machine's meters between consecutive changes
+ * with the licencee's financial formula and give per-day averages, since
+ * segments differ in length.
+ *
+ * Used by the `reconfigure` command (scripts/reconfigure-machine.ts) and
+ * `/api/cabinets/[cabinetId]/reconfigurations`.
+ *
+ * @module app/api/lib/helpers/machineReconfiguration
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  resolveFinancialFormula,
+} from '@/app/api/lib/utils/financialFormulas';
+import { clearMeterUnitCache } from '@/app/api/lib/utils/meterUnits';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+import type {
+  FinancialFormula,
+  LicenceeDocument,
+  MachineConfigField,
+  MachineReconfigurationEntry,
+  MovementTotals,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+/** Machine document path of each reconfigurable field */
+export const MACHINE_CONFIG_FIELDS: Record<MachineConfigField, string> = {
+  game: 'game',
+  gameType: 'gameType',
+  accountingDenomination: 'gameConfig.accountingDenomination',
+  payTableId: 'gameConfig.payTableId',
+  theoreticalRtp: 'gameConfig.theoreticalRtp',
+  maxBet: 'gameConfig.maxBet',
+};
+
+const NUMERIC_FIELDS: MachineConfigField[] = [
+  'accountingDenomination',
+  'theoreticalRtp',
+];
+
+export const DEFAULT_COMPARISON_WINDOW_DAYS = 30;
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+export type MachineReconfigurationInput = {
+  machineId: string;
+  values: Partial<Record<MachineConfigField, string | number>>;
+  /** When the new configuration took effect (default: now) */
+  changedAt?: Date;
+  reason: string;
+  userId: string;
+  username: string;
+};
+
+type SegmentFigures = {
+  moneyIn: number;
+  moneyOut: number;
+  jackpot: number;
+  gross: number;
+  handle: number;
+  gamesPlayed: number;
+};
+
+export type ReconfigurationSegment = {
+  start: Date;
+  end: Date;
+  days: number;
+  /** Reconfiguration that opened the segment, if any */
+  event: MachineReconfigurationEntry | null;
+  readings: number;
+  totals: SegmentFigures;
+  perDay: SegmentFigures;
+};
+
+export type ReconfigurationReport = {
+  machineId: string;
+  serialNumber?: string;
+  from: Date;
+  to: Date;
+  segments: ReconfigurationSegment[];
+};
+
+export type ReconfigurationComparison = {
+  machineId: string;
+  serialNumber?: string;
+  event: MachineReconfigurationEntry;
+  before: ReconfigurationSegment;
+  after: ReconfigurationSegment;
+  /** Percentage change of the per-day figures (null when before is 0) */
+  change: Record<keyof SegmentFigures, number | null>;
+};
+
+type MachineConfigDoc = {
+  _id: string;
+  serialNumber?: string;
+  gamingLocation?: string;
+  game?: string;
+  gameType?: string;
+  gameConfig?: Record<string, unknown>;
+  configurationHistory?: MachineReconfigurationEntry[];
+};
+
+function readConfigValue(
+  machine: MachineConfigDoc,
+  field: MachineConfigField
+): string | number | null {
+  const path = MACHINE_CONFIG_FIELDS[field];
+  const value = path.startsWith('gameConfig.')
+    ? machine.gameConfig?.[path.slice('gameConfig.'.length)]
+    : machine[field as 'game' | 'gameType'];
+  return value === undefined || value === null || value === ''
+    ? null
+    : (value as string | number);
+}
+
+function sortedHistory(
+  machine: MachineConfigDoc
+): MachineReconfigurationEntry[] {
+  return [...(machine.configurationHistory || [])].sort(
+    (a, b) => new Date(a.changedAt).getTime() - new Date(b.changedAt).getTime()
+  );
+}
+
+async function loadMachine(machineId: string): Promise<MachineConfigDoc> {
+  const machine = await Machine.findOne(
+    { _id: machineId },
+    {
+      serialNumber: 1,
+      gamingLocation: 1,
+      game: 1,
+      gameType: 1,
+      gameConfig: 1,
+      configurationHistory: 1,
+    }
+  ).lean<MachineConfigDoc>();
+  if (!machine) throw statusError(`Machine ${machineId} not found`, 404);
+  return machine;
+}
+
+// ============================================================================
+// Recording
+// ============================================================================
+
+/**
+ * Records a reconfiguration and applies it to the machine. Only fields whose
+ * value actually changes are kept. The change must take effect after the
+ * last recorded one, and the update is conditional on the values read, so a
+ * concurrent edit makes it fail instead of being overwritten.
+ *
+ * @param input - Machine, new values, effective time, reason and acting user
+ * @returns The history entry
+ * @throws Error with `statusCode` 400 (invalid), 404 (not found) or 409 (changed concurrently)
+ */
+export async function recordMachineReconfiguration(
+  input: MachineReconfigurationInput
+): Promise<MachineReconfigurationEntry> {
+  const reason = input.reason.trim();
+  if (!reason) throw statusError('A reason is required', 400);
+  const changedAt = input.changedAt ?? new Date();
+  if (Number.isNaN(changedAt.getTime())) {
+    throw statusError('Invalid effective date', 400);
+  }
+  if (changedAt.getTime() > Date.now()) {
+    throw statusError(
+      'A reconfiguration cannot take effect in the future',
+      400
+    );
+  }
+  assertWritable('recording a machine reconfiguration');
+
+  const machine = await loadMachine(input.machineId);
+  const history = sortedHistory(machine);
+  const last = history[history.length - 1];
+  if (last && new Date(last.changedAt).getTime() >= changedAt.getTime()) {
+    throw statusError(
+      `Must take effect after the last reconfiguration (${new Date(
+        last.changedAt
+      ).toISOString()})`,
+      400
+    );
+  }
+
+  const changes: MachineReconfigurationEntry['changes'] = [];
+  for (const [key, raw] of Object.entries(input.values)) {
+    const field = key as MachineConfigField;
+    if (!(field in MACHINE_CONFIG_FIELDS)) {
+      throw statusError(`Unknown configuration field '${key}'`, 400);
+    }
+    if (raw === undefined || raw === '') continue;
+    let newValue: string | number = raw;
+    if (NUMERIC_FIELDS.includes(field)) {
+      newValue = Number(raw);
+      if (!Number.isFinite(newValue) || newValue < 0) {
+        throw statusError(`${field} must be a non-negative number`, 400);
+      }
+      if (field === 'accountingDenomination' && newValue === 0) {
+        throw statusError('accountingDenomination must be above 0', 400);
+      }
+    } else {
+      newValue = String(raw).trim();
+    }
+    const oldValue = readConfigValue(machine, field);
+    if (oldValue !== null && String(oldValue) === String(newValue)) continue;
+    changes.push({ field, oldValue, newValue });
+  }
+  if (changes.length === 0) {
+    throw statusError(
+      'Nothing changes; the machine already has these values',
+      400
+    );
+  }
+
+  const entry: MachineReconfigurationEntry = {
+    _id: await generateMongoId(),
+    changedAt,
+    changes,
+    reason,
+    userId: input.userId,
+    username: input.username,
+    recordedAt: new Date(),
+  };
+
+  const guard: Record<string, unknown> = { _id: input.machineId };
+  const update: Record<string, unknown> = {};
+  changes.forEach(change => {
+    const path = MACHINE_CONFIG_FIELDS[change.field];
+    guard[path] =
+      change.oldValue === null ? { $in: [null, ''] } : change.oldValue;
+    update[path] = change.newValue;
+  });
+
+  await backupBeforeWrite({
+    operation: 'machine-reconfiguration',
+    collection: Machine.collection.collectionName,
+    filter: { _id: input.machineId },
+    by: input.username,
+  });
+  const result = await Machine.updateOne(guard, {
+    $set: update,
+    $push: { configurationHistory: entry },
+  });
+  if (result.matchedCount === 0) {
+    throw statusError(
+      'Machine configuration changed concurrently; reload and retry',
+      409
+    );
+  }
+  if (changes.some(change => change.field === 'accountingDenomination')) {
+    clearMeterUnitCache();
+  }
+
+  const summary = changes
+    .map(
+      change =>
+        `${change.field} ${change.oldValue ?? '(unset)'} -> ${change.newValue}`
+    )
+    .join(', ');
+  await logActivity({
+    action: 'update',
+    details: `Reconfigured machine ${
+      machine.serialNumber || input.machineId
+    } from ${changedAt.toISOString()}: ${summary} (${reason})`,
+    userId: input.userId,
+    username: input.username,
+    metadata: {
+      resource: 'machine',
+      resourceId: input.machineId,
+      resourceName: machine.serialNumber || input.machineId,
+      changes: changes.map(change => ({
+        field: change.field,
+        oldValue: change.oldValue,
+        newValue: change.newValue,
+      })),
+    },
+  });
+
+  return entry;
+}
+
+// ============================================================================
+// Reports
+// ============================================================================
+
+/**
+ * Financial formula of the licencee owning the machine's location.
+ */
+export async function resolveMachineFormula(machine: {
+  gamingLocation?: string;
+}): Promise<FinancialFormula> {
+  if (!machine.gamingLocation) return resolveFinancialFormula(null);
+  const location = await GamingLocations.findOne(
+    { _id: machine.gamingLocation },
+    { rel: 1 }
+  ).lean<{ rel?: { licencee?: string | string[] } }>();
+  const rawLicencee = location?.rel?.licencee;
+  const licenceeId = Array.isArray(rawLicencee) ? rawLicencee[0] : rawLicencee;
+  if (!licenceeId) return resolveFinancialFormula(null);
+  const licencee = await Licencee.findOne(
+    { _id: licenceeId },
+    { includeJackpot: 1, financialFormula: 1 }
+  ).lean<LicenceeDocument>();
+  return resolveFinancialFormula(licencee);
+}
+
+/**
+ * Aggregates the machine's meters between consecutive boundaries with a
+ * single `$bucket`.
+ */
+async function aggregateSegments(
+  machine: MachineConfigDoc,
+  boundaries: Date[],
+  history: MachineReconfigurationEntry[]
+): Promise<ReconfigurationSegment[]> {
+  const formula = await resolveMachineFormula(machine);
+  const buckets = await Meters.aggregate<
+    { _id: Date | string; readings: number; gamesPlayed: number } & MovementTotals
+  >([
+    {
+      $match: {
+        machine: String(machine._id),
+        readAt: {
+          $gte: boundaries[0],
+          $lt: boundaries[boundaries.length - 1],
+        },
+      },
+    },
+    {
+      $bucket: {
+        groupBy: '$readAt',
+        boundaries,
+        default: 'outside',
+        output: {
+          readings: { $sum: 1 },
+          gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
+          ...buildMovementTotalsGroup(),
+        },
+      },
+    },
+  ]);
+
+  return boundaries.slice(0, -1).map((start, index) => {
+    const end = boundaries[index + 1];
+    const bucket = buckets.find(
+      candidate =>
+        candidate._id instanceof Date &&
+        candidate._id.getTime() === start.getTime()
+    );
+    const metrics = calculateFinancialMetrics(bucket ?? {}, formula);
+    const totals: SegmentFigures = {
+      moneyIn: metrics.moneyIn,
+      moneyOut: metrics.moneyOut,
+      jackpot: metrics.jackpot,
+      gross: metrics.gross,
+      handle: Number(bucket?.coinIn) || 0,
+      gamesPlayed: Number(bucket?.gamesPlayed) || 0,
+    };
+    const days = Math.max((end.getTime() - start.getTime()) / DAY_MS, 1 / 24);
+    const perDay = Object.fromEntries(
+      Object.entries(totals).map(([key, value]) => [key, value / days])
+    ) as SegmentFigures;
+
+    return {
+      start,
+      end,
+      days: Math.round(days * 100) / 100,
+      event:
+        history.find(
+          entry => new Date(entry.changedAt).getTime() === start.getTime()
+        ) ?? null,
+      readings: bucket?.readings ?? 0,
+      totals,
+      perDay,
+    };
+  });
+}
+
+/**
+ * Splits a machine's meters over `[from, to)` at each recorded
+ * reconfiguration in that range.
+ *
+ * @param machineId - Machine ID
+ * @param range - Defaults to 30 days before the first reconfiguration (or the
+ *   last 90 days when there is none) up to now
+ */
+export async function getReconfigurationSegments(
+  machineId: string,
+  range: { from?: Date; to?: Date } = {}
+): Promise<ReconfigurationReport> {
+  const machine = await loadMachine(machineId);
+  const history = sortedHistory(machine);
+
+  const to = range.to ?? new Date();
+  const from =
+    range.from ??
+    (history.length > 0
+      ? new Date(
+          new Date(history[0].changedAt).getTime() -
+            DEFAULT_COMPARISON_WINDOW_DAYS * DAY_MS
+        )
+      : new Date(to.getTime() - 90 * DAY_MS));
+  if (from.getTime() >= to.getTime()) {
+    throw statusError('from must be before to', 400);
+  }
+
+  const inside = history
+    .map(entry => new Date(entry.changedAt))
+    .filter(date => date.getTime() > from.getTime() && date < to);
+  const boundaries = [from, ...inside, to];
+
+  return {
+    machineId,
+    serialNumber: machine.serialNumber,
+    from,
+    to,
+    segments: await aggregateSegments(machine, boundaries, history),
+  };
+}
+
+/**
+ * Compares a machine's per-day performance in the `windowDays` before and
+ * after one reconfiguration. Each window stops at the neighbouring
+ * reconfiguration so another change does not blur the comparison.
+ *
+ * @param machineId - Machine ID
+ * @param eventId - `configurationHistory` entry ID (default: the latest)
+ * @param windowDays - Window on each side (default 30)
+ */
+export async function compareReconfiguration(
+  machineId: string,
+  eventId?: string,
+  windowDays: number = DEFAULT_COMPARISON_WINDOW_DAYS
+): Promise<ReconfigurationComparison> {
+  const machine = await loadMachine(machineId);
+  const history = sortedHistory(machine);
+  const index = eventId
+    ? history.findIndex(entry => entry._id === eventId)
+    : history.length - 1;
+  if (index === -1) {
+    throw statusError(
+      eventId
+        ? `Reconfiguration ${eventId} not found on machine ${machineId}`
+        : `Machine ${machineId} has no recorded reconfiguration`,
+      404
+    );
+  }
+
+  const event = history[index];
+  const changedAt = new Date(event.changedAt);
+  const window = Math.max(windowDays, 1) * DAY_MS;
+  const previous = history[index - 1];
+  const next = history[index + 1];
+  const beforeStart = new Date(
+    Math.max(
+      changedAt.getTime() - window,
+      previous ? new Date(previous.changedAt).getTime() : 0
+    )
+  );
+  const afterEnd = new Date(
+    Math.min(
+      changedAt.getTime() + window,
+      next ? new Date(next.changedAt).getTime() : Infinity,
+      Date.now()
+    )
+  );
+  if (afterEnd.getTime() <= changedAt.getTime()) {
+    throw statusError('The reconfiguration has no data after it yet', 400);
+  }
+
+  const [before, after] = await aggregateSegments(
+    machine,
+    [beforeStart, changedAt, afterEnd],
+    history
+  );
+  const change = Object.fromEntries(
+    (Object.keys(before.perDay) as Array<keyof SegmentFigures>).map(key => [
+      key,
+      before.perDay[key] === 0
+        ? null
+        : Math.round(
+            ((after.perDay[key] - before.perDay[key]) /
+              Math.abs(before.perDay[key])) *
+              1000
+          ) / 10,
+    ])
+  ) as ReconfigurationComparison['change'];
+
+  return {
+    machineId,
+    serialNumber: machine.serialNumber,
+    event,
+    before,
+    after,
+    change,
+  };
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+function describeEvent(event: MachineReconfigurationEntry): string {
+  return event.changes
+    .map(
+      change =>
+        `${change.field} ${change.oldValue ?? '(unset)'} -> ${change.newValue}`
+    )
+    .join(', ');
+}
+
+/**
+ * Text table of a segment report for the command.
+ */
+export function formatReconfigurationReport(
+  report: ReconfigurationReport
+): string {
+  const lines = [
+    `Machine ${report.serialNumber || report.machineId}: ${report.segments.length} segment(s) from ${report.from.toISOString()} to ${report.to.toISOString()}`,
+  ];
+  report.segments.forEach(segment => {
+    lines.push(
+      `${segment.start.toISOString().slice(0, 16)} .. ${segment.end
+        .toISOString()
+        .slice(0, 16)}  ${String(segment.days).padStart(6)}d  gross/day ${segment.perDay.gross.toFixed(2).padStart(10)}  handle/day ${segment.perDay.handle.toFixed(2).padStart(11)}  games/day ${segment.perDay.gamesPlayed.toFixed(0).padStart(6)}  (${segment.readings} readings)`
+    );
+    if (segment.event) {
+      lines.push(
+        `  ^ ${describeEvent(segment.event)} — ${segment.event.reason} [${segment.event._id}]`
+      );
+    }
+  });
+  return lines.join('\n');
+}
+
+/**
+ * Text summary of a before/after comparison for the command.
+ */
+export function formatReconfigurationComparison(
+  comparison: ReconfigurationComparison
+): string {
+  const keys = Object.keys(comparison.before.perDay) as Array<
+    keyof SegmentFigures
+  >;
+  return [
+    `Machine ${comparison.serialNumber || comparison.machineId}, reconfigured ${new Date(
+      comparison.event.changedAt
+    ).toISOString()}: ${describeEvent(comparison.event)}`,
+    `Before: ${comparison.before.days}d (${comparison.before.readings} readings)  After: ${comparison.after.days}d (${comparison.after.readings} readings)`,
+    `${'per day'.padEnd(12)} ${'before'.padStart(12)} ${'after'.padStart(12)} ${'change'.padStart(9)}`,
+    ...keys.map(key => {
+      const change = comparison.change[key];
+      return `${key.padEnd(12)} ${comparison.before.perDay[key]
+        .toFixed(2)
+        .padStart(12)} ${comparison.after.perDay[key]
+        .toFixed(2)
+        .padStart(12)} ${(change === null
+        ? 'n/a'
+        : `${change >= 0 ? '+' : ''}${change}%`
+      ).padStart(9)}`;
+    }),
+  ].join('\n');
+}
@@ -0,0 +1,228 @@
+/**
+ * Machine Views Helper
+ *
+ * Named, per-user saved views of the machine list: the list filters
+ * (licencee, locations, statuses), the columns to show and the sort, stored
+ * in `machineviews`. Running a view lists machines exactly as
+ * `GET /api/machines` would with those params (see listEndpoint), within the
+ * user's own location access, and keeps only the view's columns — so a route
+ * collector can pull up their route machines by name (e.g. `my-route`).
+ *
+ * Used by `/api/machines/views` and `scripts/machine-views.ts`.
+ *
+ * @module app/api/lib/helpers/machineViews
+ */
+
+import type { ApiAuthContext } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseListQuery,
+  runListQuery,
+} from '@/app/api/lib/helpers/listEndpoint';
+import type { ListPage } from '@/app/api/lib/helpers/listEndpoint';
+import { machineListResource } from '@/app/api/lib/helpers/listResources';
+import { MachineView } from '@/app/api/lib/models/machineView';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+import type { MachineViewDocument, MachineViewFilters } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type SaveMachineViewInput = {
+  userId: string;
+  name: string;
+  description?: string;
+  filters?: Partial<MachineViewFilters>;
+  columns?: string[];
+  sort?: string;
+};
+
+export type MachineViewPage = ListPage & {
+  view: string;
+  columns: string[];
+};
+
+/** Fields a view can show (the machine list row fields) */
+export const MACHINE_VIEW_COLUMNS = Object.keys(
+  machineListResource.projection || {}
+);
+
+export const DEFAULT_MACHINE_VIEW_COLUMNS = [
+  'serialNumber',
+  'custom.name',
+  'game',
+  'gamingLocation',
+  'assetStatus',
+  'lastActivity',
+];
+
+const VIEW_NAME_PATTERN = /^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$/;
+
+function readPath(row: Record<string, unknown>, path: string): unknown {
+  return path
+    .split('.')
+    .reduce<unknown>(
+      (value, key) =>
+        value && typeof value === 'object'
+          ? (value as Record<string, unknown>)[key]
+          : undefined,
+      row
+    );
+}
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+/**
+ * Validates a view before it is saved.
+ *
+ * @returns Error message, or null when valid
+ */
+export function validateMachineView(
+  input: SaveMachineViewInput
+): string | null {
+  if (!VIEW_NAME_PATTERN.test(input?.name || '')) {
+    return 'name must be 1-64 letters, digits, "-" or "_"';
+  }
+  const columns = input.columns || [];
+  const unknownColumn = columns.find(
+    column => !MACHINE_VIEW_COLUMNS.includes(column)
+  );
+  if (unknownColumn) {
+    return `Unknown column '${unknownColumn}'; use ${MACHINE_VIEW_COLUMNS.join(', ')}`;
+  }
+  const sortField = (input.sort || '').replace(/^-/, '');
+  if (input.sort && !machineListResource.sortFields.includes(sortField)) {
+    return `sort must be one of ${machineListResource.sortFields.join(', ')} (prefix - for descending)`;
+  }
+  const { locations, statuses } = input.filters || {};
+  if (
+    (locations !== undefined && !Array.isArray(locations)) ||
+    (statuses !== undefined && !Array.isArray(statuses))
+  ) {
+    return 'filters.locations and filters.statuses must be arrays';
+  }
+  return null;
+}
+
+// ============================================================================
+// Storage
+// ============================================================================
+
+export async function listMachineViews(
+  userId: string
+): Promise<MachineViewDocument[]> {
+  return MachineView.find({ userId })
+    .sort({ name: 1 })
+    .lean<MachineViewDocument[]>();
+}
+
+export async function getMachineView(
+  userId: string,
+  name: string
+): Promise<MachineViewDocument | null> {
+  return MachineView.findOne({ userId, name }).lean<MachineViewDocument>();
+}
+
+/**
+ * Creates a view, or replaces the configuration of the user's view with the
+ * same name. Omitted columns and sort use the defaults.
+ *
+ * @throws Error with `statusCode = 400` when the view is invalid
+ */
+export async function saveMachineView(
+  input: SaveMachineViewInput
+): Promise<{ view: MachineViewDocument; created: boolean }> {
+  const validationError = validateMachineView(input);
+  if (validationError) throw statusError(validationError, 400);
+  assertWritable('saving machine views');
+
+  const clean = (values: string[] | undefined) =>
+    Array.from(new Set((values || []).map(value => value.trim()))).filter(
+      Boolean
+    );
+  const existing = await getMachineView(input.userId, input.name);
+  const view = await MachineView.findOneAndUpdate(
+    { userId: input.userId, name: input.name },
+    {
+      $set: {
+        description: input.description || '',
+        filters: {
+          licencee: input.filters?.licencee || null,
+          locations: clean(input.filters?.locations),
+          statuses: clean(input.filters?.statuses),
+        },
+        columns:
+          input.columns && input.columns.length > 0
+            ? clean(input.columns)
+            : DEFAULT_MACHINE_VIEW_COLUMNS,
+        sort: input.sort || machineListResource.defaultSort,
+      },
+      $setOnInsert: { _id: await generateMongoId() },
+    },
+    { upsert: true, new: true }
+  ).lean<MachineViewDocument>();
+
+  return { view: view as MachineViewDocument, created: !existing };
+}
+
+/**
+ * @returns The deleted view, or null when the user has no view by that name
+ */
+export async function deleteMachineView(
+  userId: string,
+  name: string
+): Promise<MachineViewDocument | null> {
+  assertWritable('deleting machine views');
+  return MachineView.findOneAndDelete({
+    userId,
+    name,
+  }).lean<MachineViewDocument>();
+}
+
+// ============================================================================
+// Running
+// ============================================================================
+
+/**
+ * Lists the machines of a view for the user, one page at a time.
+ *
+ * @param view - Saved view
+ * @param auth - The user's auth context (location access)
+ * @param page - `limit` and the `cursor` from the previous page
+ * @returns The page with only the view's columns (dotted names as keys)
+ * @throws Error with `statusCode = 400` on an invalid limit or cursor
+ */
+export async function runMachineView(
+  view: MachineViewDocument,
+  auth: ApiAuthContext,
+  page: { limit?: string | null; cursor?: string | null } = {}
+): Promise<MachineViewPage> {
+  const params = new URLSearchParams({ sort: view.sort });
+  if (view.filters.licencee) params.set('licencee', view.filters.licencee);
+  if (view.filters.locations.length > 0) {
+    params.set('location', view.filters.locations.join(','));
+  }
+  if (view.filters.statuses.length > 0) {
+    params.set('status', view.filters.statuses.join(','));
+  }
+  if (page.limit) params.set('limit', page.limit);
+  if (page.cursor) params.set('cursor', page.cursor);
+
+  const query = parseListQuery(params, machineListResource);
+  const result = await runListQuery(machineListResource, query, auth);
+  return {
+    ...result,
+    data: result.data.map(row => ({
+      _id: row._id,
+      ...Object.fromEntries(
+        view.columns.map(column => [column, readPath(row, column) ?? null])
+      ),
+    })),
+    view: view.name,
+    columns: view.columns,
+  };
+}
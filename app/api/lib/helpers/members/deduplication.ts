@@ -0,0 +1,618 @@
+/**
+ * Member Deduplication Helper
+ *
+ * Finds members who signed up more than once and merges them.
+ *
+ * `findDuplicateMembers()` groups probable duplicates among members that are
+ * not deleted, matching on any of:
+ * - email, trimmed and lowercased
+ * - phone number, digits only (last 10, at least 7)
+ * - first and last name (letters only, lowercased) plus date of birth
+ * Matches chain, so A~B on email and B~C on phone make one group. A key
+ * shared by more than `MAX_MEMBERS_PER_KEY` members (placeholder emails,
+ * venue phone numbers) is ignored and reported instead.
+ *
+ * `planMemberMerge()` validates a merge without writing; `executeMemberMerge()`
+ * applies it: sessions and accepted bills are re-pointed to the surviving
+ * member, points are added to the survivor and each duplicate is archived
+ * (`deletedAt` set, `mergedInto` the survivor, points zeroed), one duplicate
+ * at a time. The points moved are those the duplicate held when it was
+ * archived, not those seen when planning. Merges are refused while a
+ * duplicate is logged in, has an open session or holds a credit balance,
+ * and across locations unless allowed. The members, sessions and accepted
+ * bills involved are backed up before a merge writes.
+ *
+ * Used by the `members:dedupe` command (scripts/member-dedupe.ts).
+ *
+ * @module app/api/lib/helpers/members/deduplication
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  backupBeforeWrite,
+  createBackupRunId,
+} from '@/app/api/lib/helpers/writeBackups';
+import { AcceptedBill } from '@/app/api/lib/models/acceptedBills';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import { Member } from '@/app/api/lib/models/members';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type DuplicateMatch = 'email' | 'phone' | 'name+dob';
+
+export const MAX_MEMBERS_PER_KEY = 20;
+
+export type DuplicateMember = {
+  memberId: string;
+  username: string;
+  name: string;
+  email: string;
+  phone: string;
+  dob: string;
+  locationId: string;
+  createdAt: Date | null;
+  lastLogin: Date | null;
+  sessions: number;
+  points: number;
+};
+
+export type DuplicateGroup = {
+  members: DuplicateMember[];
+  matchedOn: DuplicateMatch[];
+  crossLocation: boolean;
+  /** Most sessions, then earliest signup */
+  suggestedSurvivorId: string;
+};
+
+export type DuplicateScan = {
+  scanned: number;
+  groups: DuplicateGroup[];
+  /** Keys shared by too many members to be a real match */
+  ignoredKeys: Array<{ match: DuplicateMatch; value: string; members: number }>;
+};
+
+export type DuplicateScanScope = {
+  licenceeId?: string;
+  locationId?: string;
+};
+
+export type MemberMergePlan = {
+  survivor: DuplicateMember;
+  duplicates: Array<DuplicateMember & { acceptedBills: number }>;
+  /** Points moved to the survivor */
+  points: number;
+};
+
+export type MemberMergeResult = {
+  merged: string[];
+  /** Duplicates that changed since planning; not merged */
+  skipped: string[];
+  sessionsMoved: number;
+  acceptedBillsMoved: number;
+  pointsMoved: number;
+  /** Backup run holding the documents as they were before the merge */
+  backupRunId: string;
+};
+
+export type MemberMergeActor = {
+  reason: string;
+  userId: string;
+  username: string;
+};
+
+type CountRow = { _id: string; count: number };
+
+type MemberRecord = {
+  _id: string;
+  username?: string;
+  phoneNumber?: string;
+  gamingLocation?: string;
+  createdAt?: Date;
+  lastLogin?: Date;
+  loggedIn?: boolean;
+  points?: number;
+  uaccount?: number;
+  nonRestricted?: number;
+  restricted?: number;
+  profile?: {
+    firstName?: string;
+    lastName?: string;
+    email?: string;
+    dob?: string;
+  };
+};
+
+const MEMBER_PROJECTION = {
+  _id: 1,
+  username: 1,
+  phoneNumber: 1,
+  gamingLocation: 1,
+  createdAt: 1,
+  lastLogin: 1,
+  loggedIn: 1,
+  points: 1,
+  uaccount: 1,
+  nonRestricted: 1,
+  restricted: 1,
+  'profile.firstName': 1,
+  'profile.lastName': 1,
+  'profile.email': 1,
+  'profile.dob': 1,
+};
+
+// ============================================================================
+// Normalization
+// ============================================================================
+
+export function normalizeEmail(value: string | undefined): string | null {
+  const email = (value || '').trim().toLowerCase();
+  return email.includes('@') ? email : null;
+}
+
+export function normalizePhone(value: string | undefined): string | null {
+  const digits = (value || '').replace(/\D/g, '');
+  return digits.length >= 7 ? digits.slice(-10) : null;
+}
+
+export function normalizeNameDob(
+  firstName: string | undefined,
+  lastName: string | undefined,
+  dob: string | undefined
+): string | null {
+  const clean = (value: string | undefined) =>
+    (value || '').toLowerCase().replace(/[^\p{L}]/gu, '');
+  const first = clean(firstName);
+  const last = clean(lastName);
+  const rawDob = (dob || '').trim();
+  if (!first || !last || !rawDob) return null;
+  const parsed = new Date(rawDob);
+  const day = Number.isNaN(parsed.getTime())
+    ? rawDob.replace(/\D/g, '')
+    : parsed.toISOString().slice(0, 10);
+  return day ? `${first}|${last}|${day}` : null;
+}
+
+function toDuplicateMember(
+  member: MemberRecord,
+  sessions: number
+): DuplicateMember {
+  return {
+    memberId: String(member._id),
+    username: member.username || '',
+    name: `${member.profile?.firstName || ''} ${
+      member.profile?.lastName || ''
+    }`.trim(),
+    email: member.profile?.email || '',
+    phone: member.phoneNumber || '',
+    dob: member.profile?.dob || '',
+    locationId: member.gamingLocation || '',
+    createdAt: member.createdAt || null,
+    lastLogin: member.lastLogin || null,
+    sessions,
+    points: member.points || 0,
+  };
+}
+
+async function countSessions(
+  memberIds: string[]
+): Promise<Map<string, number>> {
+  if (memberIds.length === 0) return new Map();
+  const rows = await MachineSession.aggregate<CountRow>([
+    { $match: { memberId: { $in: memberIds } } },
+    { $group: { _id: '$memberId', count: { $sum: 1 } } },
+  ]);
+  return new Map(rows.map(row => [String(row._id), row.count]));
+}
+
+// ============================================================================
+// Detection
+// ============================================================================
+
+/**
+ * Groups probable duplicate members.
+ *
+ * @param scope - Optional licencee or location to limit the scan to
+ * @returns Groups, largest first
+ */
+export async function findDuplicateMembers(
+  scope: DuplicateScanScope = {}
+): Promise<DuplicateScan> {
+  // Step 1: Members in scope
+  const query: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (scope.locationId) {
+    query.gamingLocation = scope.locationId;
+  } else if (scope.licenceeId) {
+    const locations = await GamingLocations.find(
+      { 'rel.licencee': scope.licenceeId },
+      { _id: 1 }
+    ).lean<Array<{ _id: string }>>();
+    query.gamingLocation = {
+      $in: locations.map(location => String(location._id)),
+    };
+  }
+  const members = await Member.find(query, MEMBER_PROJECTION).lean<
+    MemberRecord[]
+  >();
+
+  // Step 2: Index members by each normalized key
+  const byKey = new Map<string, number[]>();
+  members.forEach((member, index) => {
+    const keys: Array<[DuplicateMatch, string | null]> = [
+      ['email', normalizeEmail(member.profile?.email)],
+      ['phone', normalizePhone(member.phoneNumber)],
+      [
+        'name+dob',
+        normalizeNameDob(
+          member.profile?.firstName,
+          member.profile?.lastName,
+          member.profile?.dob
+        ),
+      ],
+    ];
+    keys.forEach(([match, value]) => {
+      if (!value) return;
+      const key = `${match}\u0000${value}`;
+      byKey.set(key, [...(byKey.get(key) || []), index]);
+    });
+  });
+
+  // Step 3: Union members sharing a key
+  const parent = members.map((_, index) => index);
+  const find = (index: number): number => {
+    while (parent[index] !== index) {
+      parent[index] = parent[parent[index]];
+      index = parent[index];
+    }
+    return index;
+  };
+  const matchesByRoot = new Map<number, Set<DuplicateMatch>>();
+  const ignoredKeys: DuplicateScan['ignoredKeys'] = [];
+  const linkedKeys: Array<[DuplicateMatch, number[]]> = [];
+  byKey.forEach((indexes, key) => {
+    if (indexes.length < 2) return;
+    const [match, value] = key.split('\u0000') as [DuplicateMatch, string];
+    if (indexes.length > MAX_MEMBERS_PER_KEY) {
+      ignoredKeys.push({ match, value, members: indexes.length });
+      return;
+    }
+    indexes.slice(1).forEach(index => {
+      parent[find(index)] = find(indexes[0]);
+    });
+    linkedKeys.push([match, indexes]);
+  });
+  linkedKeys.forEach(([match, indexes]) => {
+    const root = find(indexes[0]);
+    const matches = matchesByRoot.get(root) || new Set<DuplicateMatch>();
+    matches.add(match);
+    matchesByRoot.set(root, matches);
+  });
+
+  // Step 4: Build groups with session counts
+  const groupIndexes = new Map<number, number[]>();
+  members.forEach((_, index) => {
+    const root = find(index);
+    if (!matchesByRoot.has(root)) return;
+    groupIndexes.set(root, [...(groupIndexes.get(root) || []), index]);
+  });
+  const grouped = Array.from(groupIndexes.values()).flat();
+  const sessions = await countSessions(
+    grouped.map(index => String(members[index]._id))
+  );
+
+  const groups: DuplicateGroup[] = Array.from(groupIndexes.entries()).map(
+    ([root, indexes]) => {
+      const groupMembers = indexes
+        .map(index =>
+          toDuplicateMember(
+            members[index],
+            sessions.get(String(members[index]._id)) || 0
+          )
+        )
+        .sort(
+          (a, b) =>
+            b.sessions - a.sessions ||
+            (a.createdAt?.getTime() ?? Infinity) -
+              (b.createdAt?.getTime() ?? Infinity)
+        );
+      return {
+        members: groupMembers,
+        matchedOn: Array.from(matchesByRoot.get(root) || []),
+        crossLocation:
+          new Set(groupMembers.map(member => member.locationId)).size > 1,
+        suggestedSurvivorId: groupMembers[0].memberId,
+      };
+    }
+  );
+  groups.sort((a, b) => b.members.length - a.members.length);
+
+  return { scanned: members.length, groups, ignoredKeys };
+}
+
+// ============================================================================
+// Merge
+// ============================================================================
+
+/**
+ * Validates a merge without writing anything.
+ *
+ * @param survivorId - Member to keep
+ * @param duplicateIds - Members to merge into it and archive
+ * @param options.allowCrossLocation - Allow duplicates from other locations
+ * @throws Error with `statusCode` 400 (invalid request), 404 (member not
+ * found) or 409 (a duplicate is in use or holds a balance)
+ */
+export async function planMemberMerge(
+  survivorId: string,
+  duplicateIds: string[],
+  options: { allowCrossLocation?: boolean } = {}
+): Promise<MemberMergePlan> {
+  const ids = Array.from(new Set(duplicateIds.map(id => id.trim()))).filter(
+    Boolean
+  );
+  if (ids.length === 0) throw statusError('Pass the duplicates to merge', 400);
+  if (ids.includes(survivorId)) {
+    throw statusError('The survivor cannot also be a duplicate', 400);
+  }
+
+  // Step 1: Load the members
+  const members = await Member.find(
+    { _id: { $in: [survivorId, ...ids] }, ...NOT_DELETED_FILTER },
+    MEMBER_PROJECTION
+  ).lean<MemberRecord[]>();
+  const byId = new Map(members.map(member => [String(member._id), member]));
+  [survivorId, ...ids].forEach(id => {
+    if (!byId.has(id)) throw statusError(`Member ${id} not found`, 404);
+  });
+  const survivor = byId.get(survivorId) as MemberRecord;
+
+  // Step 2: Guards
+  const problems: string[] = [];
+  ids.forEach(id => {
+    const member = byId.get(id) as MemberRecord;
+    if (member.loggedIn) problems.push(`${id} is logged in`);
+    const balance =
+      (member.uaccount || 0) +
+      (member.nonRestricted || 0) +
+      (member.restricted || 0);
+    if (balance !== 0) problems.push(`${id} holds a credit balance`);
+    if (
+      !options.allowCrossLocation &&
+      member.gamingLocation !== survivor.gamingLocation
+    ) {
+      problems.push(`${id} belongs to another location`);
+    }
+  });
+  const openSessions = await MachineSession.distinct('memberId', {
+    memberId: { $in: ids },
+    endTime: null,
+  });
+  openSessions.forEach(id => problems.push(`${id} has an open session`));
+  if (problems.length > 0) {
+    throw statusError(`Merge refused: ${problems.join('; ')}`, 409);
+  }
+
+  // Step 3: What will move
+  const [sessions, bills] = await Promise.all([
+    countSessions([survivorId, ...ids]),
+    AcceptedBill.aggregate<CountRow>([
+      { $match: { member: { $in: ids } } },
+      { $group: { _id: '$member', count: { $sum: 1 } } },
+    ]),
+  ]);
+  const billsById = new Map(bills.map(row => [String(row._id), row.count]));
+  const duplicates = ids.map(id => ({
+    ...toDuplicateMember(byId.get(id) as MemberRecord, sessions.get(id) || 0),
+    acceptedBills: billsById.get(id) || 0,
+  }));
+
+  return {
+    survivor: toDuplicateMember(survivor, sessions.get(survivorId) || 0),
+    duplicates,
+    points: duplicates.reduce((total, member) => total + member.points, 0),
+  };
+}
+
+/**
+ * Applies a planned merge. Before each duplicate the survivor is checked to
+ * still be active; once it is not, the remaining duplicates are skipped. Each
+ * duplicate is archived first, guarded on it still being active, logged out
+ * and without a balance, and its points are zeroed in the same write; its
+ * sessions and accepted bills are then re-pointed and the points it held at
+ * that moment added to the survivor before the next duplicate.
+ *
+ * @param plan - From `planMemberMerge()`
+ * @param actor - Reason and acting user
+ * @throws Error with `statusCode` 400 when no reason is given
+ */
+export async function executeMemberMerge(
+  plan: MemberMergePlan,
+  actor: MemberMergeActor
+): Promise<MemberMergeResult> {
+  if (!actor.reason.trim()) throw statusError('A reason is required', 400);
+  assertWritable('merging members');
+
+  const survivorId = plan.survivor.memberId;
+  const duplicateIds = plan.duplicates.map(duplicate => duplicate.memberId);
+  const backupRunId = createBackupRunId('member-merge');
+  const backups = [
+    {
+      collection: Member.collection.collectionName,
+      filter: { _id: { $in: [survivorId, ...duplicateIds] } },
+    },
+    {
+      collection: MachineSession.collection.collectionName,
+      filter: { memberId: { $in: duplicateIds } },
+    },
+    {
+      collection: AcceptedBill.collection.collectionName,
+      filter: { member: { $in: duplicateIds } },
+    },
+  ];
+  for (const backup of backups) {
+    await backupBeforeWrite({
+      operation: 'member-merge',
+      ...backup,
+      runId: backupRunId,
+      by: actor.username,
+    });
+  }
+
+  const result: MemberMergeResult = {
+    merged: [],
+    skipped: [],
+    sessionsMoved: 0,
+    acceptedBillsMoved: 0,
+    pointsMoved: 0,
+    backupRunId,
+  };
+
+  for (const [index, duplicate] of plan.duplicates.entries()) {
+    const survivorActive = await Member.exists({
+      _id: survivorId,
+      ...NOT_DELETED_FILTER,
+    });
+    if (!survivorActive) {
+      result.skipped.push(
+        ...plan.duplicates.slice(index).map(member => member.memberId)
+      );
+      break;
+    }
+
+    const now = new Date();
+    // Returns the member as it was, so the points moved are exactly the
+    // points this write zeroed
+    const archived = await Member.findOneAndUpdate(
+      {
+        _id: duplicate.memberId,
+        ...NOT_DELETED_FILTER,
+        loggedIn: { $ne: true },
+        uaccount: { $in: [0, null] },
+        nonRestricted: { $in: [0, null] },
+        restricted: { $in: [0, null] },
+      },
+      {
+        $set: {
+          deletedAt: now,
+          mergedInto: survivorId,
+          points: 0,
+          updatedAt: now,
+        },
+      },
+      { new: false, projection: { points: 1 } }
+    ).lean<{ points?: number } | null>();
+    if (!archived) {
+      result.skipped.push(duplicate.memberId);
+      continue;
+    }
+
+    const [sessions, bills] = await Promise.all([
+      MachineSession.updateMany(
+        { memberId: duplicate.memberId },
+        { $set: { memberId: survivorId } }
+      ),
+      AcceptedBill.updateMany(
+        { member: duplicate.memberId },
+        { $set: { member: survivorId } }
+      ),
+    ]);
+    result.merged.push(duplicate.memberId);
+    result.sessionsMoved += sessions.modifiedCount;
+    result.acceptedBillsMoved += bills.modifiedCount;
+    // Moved with each duplicate so a failure part-way never loses points
+    const points = archived.points || 0;
+    if (points !== 0) {
+      await Member.updateOne(
+        { _id: survivorId },
+        { $inc: { points }, $set: { updatedAt: now } }
+      );
+      result.pointsMoved += points;
+    }
+
+    await logActivity({
+      action: 'update',
+      details: `Merged member ${duplicate.username || duplicate.memberId} into ${plan.survivor.username || survivorId} (${sessions.modifiedCount} sessions, ${bills.modifiedCount} accepted bills): ${actor.reason.trim()}`,
+      userId: actor.userId,
+      username: actor.username,
+      metadata: {
+        resource: 'member',
+        resourceId: duplicate.memberId,
+        resourceName: duplicate.username || duplicate.memberId,
+        changes: [
+          { field: 'mergedInto', oldValue: null, newValue: survivorId },
+          { field: 'deletedAt', oldValue: null, newValue: now },
+          { field: 'points', oldValue: points, newValue: 0 },
+        ],
+      },
+    });
+  }
+
+  return result;
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+function describeMember(member: DuplicateMember): string {
+  return [
+    member.memberId,
+    member.username,
+    member.name,
+    member.email,
+    member.phone,
+    `${member.sessions} sessions`,
+  ]
+    .filter(Boolean)
+    .join(' | ');
+}
+
+/**
+ * Formats a scan for the terminal.
+ */
+export function formatDuplicateScan(scan: DuplicateScan): string {
+  const lines = [
+    `${scan.groups.length} duplicate group(s) among ${scan.scanned} members`,
+  ];
+  scan.groups.forEach((group, index) => {
+    lines.push(
+      '',
+      `#${index + 1} matched on ${group.matchedOn.join(', ')}${
+        group.crossLocation ? ' (several locations)' : ''
+      }`
+    );
+    group.members.forEach(member =>
+      lines.push(
+        `  ${member.memberId === group.suggestedSurvivorId ? '*' : ' '} ${describeMember(member)}`
+      )
+    );
+  });
+  if (scan.ignoredKeys.length > 0) {
+    lines.push(
+      '',
+      `Ignored (shared by more than ${MAX_MEMBERS_PER_KEY} members): ${scan.ignoredKeys
+        .map(key => `${key.match} ${key.value} (${key.members})`)
+        .join(', ')}`
+    );
+  }
+  return lines.join('\n');
+}
+
+/**
+ * Formats a merge plan for the terminal (the dry-run output).
+ */
+export function formatMemberMergePlan(plan: MemberMergePlan): string {
+  return [
+    `Keep    ${describeMember(plan.survivor)}`,
+    ...plan.duplicates.map(
+      member =>
+        `Archive ${describeMember(member)} | ${member.acceptedBills} accepted bills`
+    ),
+    `Points moved to the survivor: ${plan.points}`,
+  ].join('\n');
+}
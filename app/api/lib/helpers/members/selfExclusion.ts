@@ -0,0 +1,424 @@
+/**
+ * Self-Exclusion Helper
+ *
+ * Keeps the list of self-excluded members (`selfexclusions`: member, start
+ * date, optional end date, reason) and checks it against play: a session
+ * recorded for a member that starts on or after their exclusion start (and
+ * before its end, if any) is a breach for compliance to follow up.
+ *
+ * Used by `/api/members/self-exclusions` and
+ * `scripts/self-exclusion-check.ts`.
+ *
+ * @module app/api/lib/helpers/members/selfExclusion
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import { Member } from '@/app/api/lib/models/members';
+import { SelfExclusion } from '@/app/api/lib/models/selfExclusion';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { generateMongoId } from '@/lib/utils/id';
+import type { SelfExclusionDocument } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type SelfExclusionEntry = SelfExclusionDocument & {
+  memberName: string;
+  active: boolean;
+};
+
+export type AddSelfExclusionInput = {
+  memberId: string;
+  startDate?: Date;
+  endDate?: Date | null;
+  reason?: string;
+  recordedBy?: string | null;
+  /** Locations whose members the caller may exclude */
+  allowedLocationIds?: 'all' | string[];
+};
+
+export type SelfExclusionBreach = {
+  exclusionId: string;
+  memberId: string;
+  memberName: string;
+  excludedFrom: Date;
+  excludedUntil: Date | null;
+  sessionId: string;
+  machineId: string;
+  machineSerialNumber: string;
+  locationId: string;
+  locationName: string;
+  startTime: Date;
+  endTime: Date | null;
+};
+
+export type SelfExclusionReport = {
+  checkedAt: Date;
+  /** Only sessions starting from this date were checked */
+  since: Date | null;
+  exclusionsChecked: number;
+  membersInBreach: number;
+  breaches: SelfExclusionBreach[];
+};
+
+export type SelfExclusionScope = {
+  /** Only sessions on this licencee's machines */
+  licenceeId?: string;
+  /** Only sessions on this location's machines */
+  locationId?: string;
+  since?: Date;
+};
+
+type MemberName = {
+  _id: string;
+  username?: string;
+  profile?: { firstName?: string; lastName?: string };
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+function memberDisplayName(member: MemberName | undefined, id: string) {
+  const name = `${member?.profile?.firstName || ''} ${
+    member?.profile?.lastName || ''
+  }`.trim();
+  return name || member?.username || id;
+}
+
+async function getMemberNames(ids: string[]): Promise<Map<string, string>> {
+  const members = await Member.find(
+    { _id: { $in: ids } },
+    { _id: 1, username: 1, 'profile.firstName': 1, 'profile.lastName': 1 }
+  ).lean<MemberName[]>();
+  const byId = new Map(members.map(member => [String(member._id), member]));
+  return new Map(ids.map(id => [id, memberDisplayName(byId.get(id), id)]));
+}
+
+/**
+ * Whether an exclusion covers a moment (default now).
+ */
+export function isExclusionActive(
+  exclusion: Pick<SelfExclusionDocument, 'startDate' | 'endDate'>,
+  at: Date = new Date()
+): boolean {
+  return (
+    new Date(exclusion.startDate) <= at &&
+    (!exclusion.endDate || new Date(exclusion.endDate) > at)
+  );
+}
+
+// ============================================================================
+// List
+// ============================================================================
+
+/**
+ * Lists exclusions recorded for members of the given locations.
+ *
+ * @param params.allowedLocationIds - Member locations in scope
+ * @param params.activeOnly - Only exclusions in force now
+ * @returns Exclusions, most recent start first
+ */
+export async function listSelfExclusions(params: {
+  allowedLocationIds: 'all' | string[];
+  activeOnly?: boolean;
+}): Promise<SelfExclusionEntry[]> {
+  const now = new Date();
+  const query: Record<string, unknown> = {};
+  if (params.allowedLocationIds !== 'all') {
+    query.gamingLocation = { $in: params.allowedLocationIds };
+  }
+  if (params.activeOnly) {
+    query.startDate = { $lte: now };
+    query.$or = [{ endDate: null }, { endDate: { $gt: now } }];
+  }
+  const exclusions = await SelfExclusion.find(query)
+    .sort({ startDate: -1 })
+    .lean<SelfExclusionDocument[]>();
+  const names = await getMemberNames(
+    Array.from(new Set(exclusions.map(exclusion => exclusion.member)))
+  );
+  return exclusions.map(exclusion => ({
+    ...exclusion,
+    memberName: names.get(exclusion.member) || exclusion.member,
+    active: isExclusionActive(exclusion, now),
+  }));
+}
+
+/**
+ * Records a self-exclusion for a member.
+ *
+ * @throws Error with statusCode 400 for invalid dates, 403 when the member
+ * is outside `allowedLocationIds`, 404 when the member does not exist, or
+ * 409 when it overlaps an existing exclusion
+ */
+export async function addSelfExclusion(
+  input: AddSelfExclusionInput
+): Promise<SelfExclusionDocument> {
+  const startDate = input.startDate || new Date();
+  const endDate = input.endDate || null;
+  if (Number.isNaN(startDate.getTime())) {
+    throw statusError('startDate is not a valid date', 400);
+  }
+  if (endDate && (Number.isNaN(endDate.getTime()) || endDate <= startDate)) {
+    throw statusError('endDate must be a valid date after startDate', 400);
+  }
+  assertWritable('recording self-exclusions');
+
+  const member = await Member.findOne(
+    { _id: input.memberId, ...NOT_DELETED_FILTER },
+    { _id: 1, gamingLocation: 1 }
+  ).lean<{ _id: string; gamingLocation?: string }>();
+  if (!member) {
+    throw statusError(`Member ${input.memberId} not found`, 404);
+  }
+  if (
+    input.allowedLocationIds &&
+    input.allowedLocationIds !== 'all' &&
+    !input.allowedLocationIds.includes(member.gamingLocation || '')
+  ) {
+    throw statusError('Forbidden', 403);
+  }
+
+  const overlapping = await SelfExclusion.exists({
+    member: input.memberId,
+    ...(endDate ? { startDate: { $lt: endDate } } : {}),
+    $or: [{ endDate: null }, { endDate: { $gt: startDate } }],
+  });
+  if (overlapping) {
+    throw statusError(
+      `Member ${input.memberId} already has a self-exclusion in that period`,
+      409
+    );
+  }
+
+  const created = await SelfExclusion.create({
+    _id: await generateMongoId(),
+    member: input.memberId,
+    gamingLocation: member.gamingLocation || null,
+    startDate,
+    endDate,
+    reason: input.reason?.trim() || '',
+    recordedBy: input.recordedBy || null,
+  });
+  return created.toObject() as SelfExclusionDocument;
+}
+
+// ============================================================================
+// Breach detection
+// ============================================================================
+
+/**
+ * Finds sessions recorded for self-excluded members while their exclusion
+ * was in force.
+ *
+ * @param scope - Licencee or location of the machines played, and a start
+ * date for the sessions checked
+ * @returns Breaches ordered by session start
+ */
+export async function findSelfExclusionBreaches(
+  scope: SelfExclusionScope = {}
+): Promise<SelfExclusionReport> {
+  const checkedAt = new Date();
+
+  // Step 1: Machines in scope
+  let machineIds: string[] | null = null;
+  if (scope.locationId || scope.licenceeId) {
+    const locationIds = scope.locationId
+      ? [scope.locationId]
+      : (
+          await GamingLocations.find(
+            { 'rel.licencee': scope.licenceeId },
+            { _id: 1 }
+          ).lean<Array<{ _id: string }>>()
+        ).map(location => String(location._id));
+    const machines = await Machine.find(
+      { gamingLocation: { $in: locationIds } },
+      { _id: 1 }
+    ).lean<Array<{ _id: string }>>();
+    machineIds = machines.map(machine => String(machine._id));
+  }
+
+  // Step 2: Exclusions, and sessions inside each exclusion window
+  const exclusions = await SelfExclusion.find(
+    scope.since
+      ? { $or: [{ endDate: null }, { endDate: { $gt: scope.since } }] }
+      : {}
+  ).lean<SelfExclusionDocument[]>();
+
+  const breaches: SelfExclusionBreach[] = [];
+  for (const exclusion of exclusions) {
+    const from =
+      scope.since && scope.since > new Date(exclusion.startDate)
+        ? scope.since
+        : new Date(exclusion.startDate);
+    const startTime: Record<string, Date> = { $gte: from };
+    if (exclusion.endDate) startTime.$lt = new Date(exclusion.endDate);
+    const query: Record<string, unknown> = {
+      memberId: exclusion.member,
+      startTime,
+    };
+    if (machineIds) query.machineId = { $in: machineIds };
+
+    const sessions = await MachineSession.find(query, {
+      _id: 1,
+      machineId: 1,
+      machineSerialNumber: 1,
+      startTime: 1,
+      endTime: 1,
+    })
+      .sort({ startTime: 1 })
+      .lean<
+        Array<{
+          _id: string;
+          machineId: string;
+          machineSerialNumber?: string;
+          startTime: Date;
+          endTime?: Date | null;
+        }>
+      >();
+    sessions.forEach(session =>
+      breaches.push({
+        exclusionId: exclusion._id,
+        memberId: exclusion.member,
+        memberName: exclusion.member,
+        excludedFrom: exclusion.startDate,
+        excludedUntil: exclusion.endDate || null,
+        sessionId: String(session._id),
+        machineId: session.machineId,
+        machineSerialNumber: session.machineSerialNumber || '',
+        locationId: '',
+        locationName: '',
+        startTime: session.startTime,
+        endTime: session.endTime || null,
+      })
+    );
+  }
+
+  // Step 3: Member, machine location and location names
+  const memberIds = Array.from(new Set(breaches.map(b => b.memberId)));
+  const names = await getMemberNames(memberIds);
+  const machines = await Machine.find(
+    { _id: { $in: Array.from(new Set(breaches.map(b => b.machineId))) } },
+    { _id: 1, gamingLocation: 1, serialNumber: 1 }
+  ).lean<
+    Array<{ _id: string; gamingLocation?: string; serialNumber?: string }>
+  >();
+  const machineById = new Map(machines.map(m => [String(m._id), m]));
+  const locations = await GamingLocations.find(
+    {
+      _id: {
+        $in: Array.from(
+          new Set(machines.map(m => m.gamingLocation).filter(Boolean))
+        ),
+      },
+    },
+    { _id: 1, name: 1 }
+  ).lean<Array<{ _id: string; name?: string }>>();
+  const locationNames = new Map(
+    locations.map(location => [String(location._id), location.name || ''])
+  );
+  breaches.forEach(breach => {
+    const machine = machineById.get(breach.machineId);
+    breach.memberName = names.get(breach.memberId) || breach.memberId;
+    breach.machineSerialNumber =
+      breach.machineSerialNumber || machine?.serialNumber || '';
+    breach.locationId = machine?.gamingLocation || '';
+    breach.locationName = locationNames.get(breach.locationId) || '';
+  });
+
+  return {
+    checkedAt,
+    since: scope.since || null,
+    exclusionsChecked: exclusions.length,
+    membersInBreach: memberIds.length,
+    breaches: breaches.sort(
+      (a, b) =>
+        new Date(a.startTime).getTime() - new Date(b.startTime).getTime()
+    ),
+  };
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+/**
+ * Formats a one-line-per-breach summary of a report.
+ */
+export function formatSelfExclusionReport(report: SelfExclusionReport): string {
+  const lines = report.breaches.map(
+    breach =>
+      `${new Date(breach.startTime).toISOString()} ${breach.memberName} (${breach.memberId}) on ${breach.machineSerialNumber || breach.machineId} at ${breach.locationName || breach.locationId || 'unknown location'}, excluded since ${new Date(breach.excludedFrom).toISOString().slice(0, 10)}`
+  );
+  return [
+    `Self-exclusion check at ${report.checkedAt.toISOString()}: ${report.breaches.length} session(s) by ${report.membersInBreach} excluded member(s) (${report.exclusionsChecked} exclusion(s) checked)`,
+    ...lines,
+  ].join('\n');
+}
+
+/**
+ * Converts the breaches to CSV for the compliance file.
+ */
+export function exportSelfExclusionBreachesToCSV(
+  report: SelfExclusionReport
+): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const iso = (value: Date | null) =>
+    value ? new Date(value).toISOString() : '';
+  const header = [
+    'Member ID',
+    'Member',
+    'Excluded From',
+    'Excluded Until',
+    'Session ID',
+    'Machine ID',
+    'Serial Number',
+    'Location ID',
+    'Location',
+    'Session Start',
+    'Session End',
+  ];
+  const lines = report.breaches.map(breach =>
+    [
+      breach.memberId,
+      quote(breach.memberName),
+      iso(breach.excludedFrom),
+      iso(breach.excludedUntil),
+      breach.sessionId,
+      breach.machineId,
+      quote(breach.machineSerialNumber),
+      breach.locationId,
+      quote(breach.locationName),
+      iso(breach.startTime),
+      iso(breach.endTime),
+    ].join(',')
+  );
+  return [header.join(','), ...lines].join('\n');
+}
+
+/**
+ * Posts a report to a webhook. The payload carries a `text` summary
+ * (Slack/Teams compatible) plus the full report.
+ *
+ * @param url - Webhook URL
+ * @param report - Self-exclusion report
+ */
+export async function postSelfExclusionWebhook(
+  url: string,
+  report: SelfExclusionReport
+): Promise<void> {
+  const response = await fetch(url, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ text: formatSelfExclusionReport(report), report }),
+  });
+  if (!response.ok) {
+    throw new Error(`Webhook responded with HTTP ${response.status}`);
+  }
+}
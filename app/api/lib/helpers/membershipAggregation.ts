@@ -1,3 +1,4 @@
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 /**
  * Aggregates member counts for a list of location IDs.
  * Filters out members deleted after a specific threshold (e.g., legacy data cleanup).
@@ -19,11 +20,7 @@ export async function getMemberCountsPerLocation(
     {
       $match: {
         gamingLocation: { $in: locationIds.map(String) },
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $exists: false } },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
+        ...NOT_DELETED_FILTER,
       },
     },
     {
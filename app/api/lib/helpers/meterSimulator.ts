@@ -0,0 +1,268 @@
+/**
+ * Meter Ingestion Simulator
+ *
+ * Generates synthetic SAS meter readings for a fleet of fake machines and
+ * writes them in batches at a target rate (machines × readings per minute),
+ * to load-test indexes, the `metersDaily` rollup and pre-aggregation before
+ * the SMIB fleet grows.
+ *
+ * Synthetic machines and locations use ids starting with
+ * `SIMULATED_ID_PREFIX` so their meters can be told apart and removed with
+ * `deleteSimulatedMeters`. Documents are written through the raw collection
+ * so Mongoose validation does not skew the measured write rate.
+ *
+ * Used by the `simulate-meters` command (scripts/simulate-meters.ts).
+ *
+ * @module app/api/lib/helpers/meterSimulator
+ */
+
+import { percentile } from '@/app/api/lib/helpers/benchmark';
+import { Meters } from '@/app/api/lib/models/meters';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { Types } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const SIMULATED_ID_PREFIX = 'sim-';
+
+export type MeterSimulationOptions = {
+  machines: number;
+  locations: number;
+  readingsPerMinute: number;
+  /** Live mode: how long to generate load */
+  durationMinutes: number;
+  /**
+   * Backfill mode: write this many hours of history as fast as possible,
+   * with `readAt` spread over the past instead of pacing to the rate
+   */
+  backfillHours: number | null;
+  batchSize: number;
+  /** Distinguishes runs; part of every synthetic id */
+  runId: string;
+};
+
+export type MeterSimulationProgress = {
+  inserted: number;
+  elapsedMs: number;
+  docsPerSecond: number;
+};
+
+export type MeterSimulationResult = MeterSimulationProgress & {
+  batches: number;
+  targetDocsPerSecond: number;
+  batchP50Ms: number;
+  batchP95Ms: number;
+  /** Live mode: batches that could not keep up with the target rate */
+  lagBatches: number;
+};
+
+type SimulatedMachine = {
+  machine: string;
+  location: string;
+  /** Cumulative SAS meters, advanced on every reading */
+  totals: Record<MeterField, number>;
+};
+
+type MeterField =
+  | 'coinIn'
+  | 'coinOut'
+  | 'drop'
+  | 'totalCancelledCredits'
+  | 'jackpot'
+  | 'gamesPlayed'
+  | 'gamesWon';
+
+export const DEFAULT_SIMULATION_OPTIONS: Omit<MeterSimulationOptions, 'runId'> =
+  {
+    machines: 100,
+    locations: 10,
+    readingsPerMinute: 1,
+    durationMinutes: 5,
+    backfillHours: null,
+    batchSize: 500,
+  };
+
+// ============================================================================
+// Document generation
+// ============================================================================
+
+function randomInt(max: number): number {
+  return Math.floor(Math.random() * max);
+}
+
+/**
+ * Creates the synthetic fleet, spread round-robin over the locations.
+ */
+export function createSimulatedFleet(
+  options: Pick<MeterSimulationOptions, 'machines' | 'locations' | 'runId'>
+): SimulatedMachine[] {
+  return Array.from({ length: options.machines }, (_, index) => ({
+    machine: `${SIMULATED_ID_PREFIX}${options.runId}-m${index}`,
+    location: `${SIMULATED_ID_PREFIX}${options.runId}-l${
+      index % Math.max(options.locations, 1)
+    }`,
+    totals: {
+      coinIn: 0,
+      coinOut: 0,
+      drop: 0,
+      totalCancelledCredits: 0,
+      jackpot: 0,
+      gamesPlayed: 0,
+      gamesWon: 0,
+    },
+  }));
+}
+
+/**
+ * Builds the next reading for a machine: random movement since the previous
+ * reading, added to its running totals.
+ */
+export function buildSimulatedMeter(
+  machine: SimulatedMachine,
+  readAt: Date
+): Record<string, unknown> {
+  const gamesPlayed = randomInt(40);
+  const coinIn = gamesPlayed * (1 + randomInt(5));
+  const coinOut = Math.floor(coinIn * (0.8 + Math.random() * 0.15));
+  const movement: Record<MeterField, number> = {
+    coinIn,
+    coinOut,
+    drop: randomInt(4) === 0 ? 20 * (1 + randomInt(5)) : 0,
+    totalCancelledCredits: randomInt(20) === 0 ? randomInt(200) : 0,
+    jackpot: randomInt(500) === 0 ? 1000 : 0,
+    gamesPlayed,
+    gamesWon: Math.floor(gamesPlayed * 0.3),
+  };
+  (Object.keys(movement) as MeterField[]).forEach(field => {
+    machine.totals[field] += movement[field];
+  });
+
+  return {
+    _id: new Types.ObjectId().toHexString(),
+    machine: machine.machine,
+    location: machine.location,
+    movement: {
+      ...movement,
+      totalHandPaidCancelledCredits: 0,
+      totalWonCredits: coinOut,
+      currentCredits: 0,
+    },
+    ...machine.totals,
+    totalHandPaidCancelledCredits: 0,
+    totalWonCredits: machine.totals.coinOut,
+    currentCredits: 0,
+    meterSource: 'OTHER',
+    isSupplemental: false,
+    readAt,
+    createdAt: new Date(),
+    updatedAt: new Date(),
+  };
+}
+
+// ============================================================================
+// Runner
+// ============================================================================
+
+function sleep(ms: number): Promise<void> {
+  return new Promise(resolve => setTimeout(resolve, ms));
+}
+
+/**
+ * Generates and inserts synthetic meters.
+ *
+ * Live mode paces batches so the fleet writes `machines × readingsPerMinute`
+ * documents per minute with `readAt` = now. Backfill mode writes
+ * `backfillHours` of readings unpaced, oldest first.
+ *
+ * @param options - Fleet size, rate and mode
+ * @param onProgress - Called after each batch
+ */
+export async function runMeterSimulation(
+  options: MeterSimulationOptions,
+  onProgress?: (progress: MeterSimulationProgress) => void
+): Promise<MeterSimulationResult> {
+  assertWritable('meter simulation');
+
+  const fleet = createSimulatedFleet(options);
+  const docsPerMinute = options.machines * options.readingsPerMinute;
+  const targetDocsPerSecond = docsPerMinute / 60;
+  const intervalMs = 60000 / Math.max(options.readingsPerMinute, 1e-9);
+  const minutes = options.backfillHours
+    ? options.backfillHours * 60
+    : options.durationMinutes;
+  const totalDocs = Math.floor(docsPerMinute * minutes);
+  const historyStart = options.backfillHours
+    ? Date.now() - options.backfillHours * 3600000
+    : null;
+
+  const startedAt = Date.now();
+  const batchDurations: number[] = [];
+  let inserted = 0;
+  let lagBatches = 0;
+
+  while (inserted < totalDocs) {
+    // Step 1: Build the next batch, cycling through the fleet
+    const size = Math.min(options.batchSize, totalDocs - inserted);
+    const batch = Array.from({ length: size }, (_, offset) => {
+      const sequence = inserted + offset;
+      const machine = fleet[sequence % fleet.length];
+      const round = Math.floor(sequence / fleet.length);
+      const readAt =
+        historyStart === null
+          ? new Date()
+          : new Date(historyStart + round * intervalMs);
+      return buildSimulatedMeter(machine, readAt);
+    });
+
+    // Step 2: Insert and time the batch
+    const batchStarted = performance.now();
+    await Meters.collection.insertMany(batch as never[], { ordered: false });
+    batchDurations.push(performance.now() - batchStarted);
+    inserted += size;
+
+    const elapsedMs = Date.now() - startedAt;
+    onProgress?.({
+      inserted,
+      elapsedMs,
+      docsPerSecond: inserted / Math.max(elapsedMs / 1000, 0.001),
+    });
+
+    // Step 3: Live mode waits until the target rate catches up
+    if (historyStart === null && targetDocsPerSecond > 0) {
+      const dueAtMs = (inserted / targetDocsPerSecond) * 1000;
+      if (dueAtMs > elapsedMs) await sleep(dueAtMs - elapsedMs);
+      else lagBatches++;
+    }
+  }
+
+  const elapsedMs = Date.now() - startedAt;
+  const round = (value: number) => Math.round(value * 10) / 10;
+  return {
+    inserted,
+    elapsedMs,
+    docsPerSecond: round(inserted / Math.max(elapsedMs / 1000, 0.001)),
+    batches: batchDurations.length,
+    targetDocsPerSecond: round(targetDocsPerSecond),
+    batchP50Ms: round(percentile(batchDurations, 50)),
+    batchP95Ms: round(percentile(batchDurations, 95)),
+    lagBatches,
+  };
+}
+
+/**
+ * Removes synthetic meters, for one run or all runs.
+ *
+ * @param runId - Run to remove; omit to remove every simulated meter
+ * @returns Number of meters deleted
+ */
+export async function deleteSimulatedMeters(runId?: string): Promise<number> {
+  assertWritable('deleting simulated meters');
+  const prefix = `${SIMULATED_ID_PREFIX}${runId ? `${runId}-` : ''}`;
+  const escaped = prefix.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+  const result = await Meters.collection.deleteMany({
+    machine: { $regex: `^${escaped}` },
+  });
+  return result.deletedCount;
+}
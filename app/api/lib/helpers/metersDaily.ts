@@ -0,0 +1,330 @@
+/**
+ * Daily Meters Rollup Helper
+ *
+ * Materializes per-machine, per-gaming-day movement totals into the
+ * `metersDaily` collection and exposes read helpers that prefer the rollup for
+ * fully covered gaming days, falling back to raw `meters` for partial days
+ * (today, range edges, or days that have not been rolled up yet).
+ *
+ * Gaming days follow each location's `gameDayOffset` (default 8 AM, UTC-4), so
+ * a rollup row stores the exact `rangeStart`/`rangeEnd` window it covers.
+ *
+ * @module app/api/lib/helpers/metersDaily
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Meters } from '@/app/api/lib/models/meters';
+import { MetersDaily } from '@/app/api/lib/models/metersDaily';
+import {
+  buildMovementTotalsGroup,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRange } from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+import type { MetersDailyDocument, MovementTotals } from '@/shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type DailyMovementTotals = MovementTotals & {
+  gamesPlayed: number;
+  gamesWon: number;
+};
+
+export type MeterRollupResult = {
+  gamingDay: string;
+  locationsProcessed: number;
+  locationsSkipped: number;
+  machinesWritten: number;
+};
+
+type RollupGroupKey = 'machine' | 'location';
+
+const DEFAULT_TIMEZONE_OFFSET = -4;
+export const DEFAULT_GAME_DAY_OFFSET = 8;
+const GAMING_DAY_PATTERN = /^\d{4}-\d{2}-\d{2}$/;
+
+// ============================================================================
+// Gaming Day Helpers
+// ============================================================================
+
+/**
+ * Validates a `YYYY-MM-DD` gaming day string.
+ */
+export function isValidGamingDay(gamingDay: string): boolean {
+  if (!GAMING_DAY_PATTERN.test(gamingDay)) return false;
+  return !Number.isNaN(new Date(`${gamingDay}T00:00:00Z`).getTime());
+}
+
+/**
+ * Returns yesterday's gaming day (Trinidad time) as `YYYY-MM-DD` — the most
+ * recent day that is guaranteed to be complete for the default offset.
+ */
+export function getDefaultRollupDay(now: Date = new Date()): string {
+  const localNow = new Date(now.getTime() + DEFAULT_TIMEZONE_OFFSET * 3600000);
+  if (localNow.getUTCHours() < DEFAULT_GAME_DAY_OFFSET) {
+    localNow.setUTCDate(localNow.getUTCDate() - 1);
+  }
+  localNow.setUTCDate(localNow.getUTCDate() - 1);
+  return localNow.toISOString().slice(0, 10);
+}
+
+/**
+ * Resolves the UTC window of a gaming day for a location offset.
+ */
+export function getRollupWindow(
+  gamingDay: string,
+  gameDayOffset: number
+): GamingDayRange {
+  return getGamingDayRange(new Date(`${gamingDay}T00:00:00Z`), gameDayOffset);
+}
+
+// ============================================================================
+// Rollup Job
+// ============================================================================
+
+/**
+ * Fetches active locations with their gaming day offset for rollup work.
+ *
+ * @param locationIds - Optional subset of locations (default: all active)
+ */
+export async function fetchRollupLocations(
+  locationIds?: string[]
+): Promise<Array<{ _id: string; gameDayOffset?: number }>> {
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (locationIds && locationIds.length > 0) {
+    locationQuery._id = { $in: locationIds };
+  }
+
+  return GamingLocations.find(locationQuery, {
+    _id: 1,
+    gameDayOffset: 1,
+  }).lean<Array<{ _id: string; gameDayOffset?: number }>>();
+}
+
+/**
+ * Rolls up raw meters into `metersDaily` for a single gaming day.
+ * Locations whose gaming day has not finished yet are skipped so the rollup
+ * only ever holds complete days. Safe to re-run — rows are upserted.
+ *
+ * @param gamingDay - Gaming day as `YYYY-MM-DD`
+ * @param locationIds - Optional subset of locations (default: all active)
+ * @returns Counts of processed/skipped locations and written machine rows
+ */
+export async function rollupMetersForDay(
+  gamingDay: string,
+  locationIds?: string[]
+): Promise<MeterRollupResult> {
+  assertWritable('metersDaily rollup');
+  const locations = await fetchRollupLocations(locationIds);
+
+  const now = new Date();
+  let locationsProcessed = 0;
+  let locationsSkipped = 0;
+  let machinesWritten = 0;
+
+  for (const location of locations) {
+    const locationId = String(location._id);
+    const window = getRollupWindow(
+      gamingDay,
+      location.gameDayOffset ?? DEFAULT_GAME_DAY_OFFSET
+    );
+
+    if (window.rangeEnd > now) {
+      locationsSkipped++;
+      continue;
+    }
+
+    const cursor = Meters.aggregate([
+      {
+        $match: {
+          location: locationId,
+          readAt: { $gte: window.rangeStart, $lte: window.rangeEnd },
+        },
+      },
+      {
+        $group: {
+          _id: '$machine',
+          ...buildMovementTotalsGroup(),
+          gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
+          gamesWon: { $sum: { $ifNull: ['$movement.gamesWon', 0] } },
+          meterCount: { $sum: 1 },
+        },
+      },
+    ]).cursor({ batchSize: 1000 });
+
+    const operations: Array<Record<string, unknown>> = [];
+    for await (const doc of cursor) {
+      const machineId = String(doc._id);
+      const movement: DailyMovementTotals = {
+        gamesPlayed: Number(doc.gamesPlayed) || 0,
+        gamesWon: Number(doc.gamesWon) || 0,
+      };
+      METER_MOVEMENT_FIELDS.forEach(field => {
+        movement[field] = Number(doc[field]) || 0;
+      });
+
+      operations.push({
+        updateOne: {
+          filter: { _id: `${machineId}_${gamingDay}` },
+          update: {
+            $set: {
+              machine: machineId,
+              location: locationId,
+              gamingDay,
+              rangeStart: window.rangeStart,
+              rangeEnd: window.rangeEnd,
+              movement,
+              meterCount: Number(doc.meterCount) || 0,
+              rolledUpAt: now,
+            },
+          },
+          upsert: true,
+        },
+      });
+    }
+
+    if (operations.length > 0) {
+      await MetersDaily.bulkWrite(operations, { ordered: false });
+      machinesWritten += operations.length;
+    }
+    locationsProcessed++;
+  }
+
+  return {
+    gamingDay,
+    locationsProcessed,
+    locationsSkipped,
+    machinesWritten,
+  };
+}
+
+// ============================================================================
+// Rollup-Preferring Reads
+// ============================================================================
+
+function addTotals(
+  target: Map<string, DailyMovementTotals>,
+  key: string,
+  source: Record<string, unknown>
+) {
+  const current = target.get(key) || { gamesPlayed: 0, gamesWon: 0 };
+  METER_MOVEMENT_FIELDS.forEach(field => {
+    current[field] = (current[field] || 0) + (Number(source[field]) || 0);
+  });
+  current.gamesPlayed += Number(source.gamesPlayed) || 0;
+  current.gamesWon += Number(source.gamesWon) || 0;
+  target.set(key, current);
+}
+
+/** Adds the raw meters matching any of `clauses` into `totals` */
+async function addRawTotals(
+  totals: Map<string, DailyMovementTotals>,
+  clauses: Record<string, unknown>[],
+  groupBy: RollupGroupKey
+) {
+  const cursor = Meters.aggregate([
+    { $match: { $or: clauses } },
+    {
+      $group: {
+        _id: groupBy === 'machine' ? '$machine' : '$location',
+        ...buildMovementTotalsGroup(),
+        gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
+        gamesWon: { $sum: { $ifNull: ['$movement.gamesWon', 0] } },
+      },
+    },
+  ]).cursor({ batchSize: 1000 });
+
+  for await (const doc of cursor) {
+    addTotals(totals, String(doc._id), doc);
+  }
+}
+
+/**
+ * Sums movement totals per machine or per location for the given per-location
+ * gaming day ranges. Complete days inside a range are read from `metersDaily`;
+ * everything else (partial days, days not yet rolled up) comes from raw meters.
+ *
+ * @param ranges - Map of locationId → gaming day range
+ * @param groupBy - Group results by 'machine' or 'location' (default: 'location')
+ * @returns Map of machine/location ID → summed movement totals
+ */
+export async function getMovementTotalsWithRollup(
+  ranges: Map<string, GamingDayRange>,
+  groupBy: RollupGroupKey = 'location'
+): Promise<Map<string, DailyMovementTotals>> {
+  const totals = new Map<string, DailyMovementTotals>();
+  if (ranges.size === 0) return totals;
+
+  // Step 1: Read rollup rows fully inside each location's range
+  const rollupClauses = Array.from(ranges.entries()).map(
+    ([locationId, range]) => ({
+      location: locationId,
+      rangeStart: { $gte: range.rangeStart },
+      rangeEnd: { $lte: range.rangeEnd },
+    })
+  );
+  const rollupRows = await MetersDaily.find(
+    { $or: rollupClauses },
+    { machine: 1, location: 1, rangeStart: 1, rangeEnd: 1, movement: 1 }
+  ).lean<MetersDailyDocument[]>();
+
+  const coveredWindows = new Map<string, Map<number, Date>>();
+  rollupRows.forEach(row => {
+    const key = groupBy === 'machine' ? row.machine : row.location;
+    addTotals(totals, key, row.movement);
+
+    const windows = coveredWindows.get(row.location) || new Map<number, Date>();
+    windows.set(new Date(row.rangeStart).getTime(), new Date(row.rangeEnd));
+    coveredWindows.set(row.location, windows);
+  });
+
+  // Step 2: Fall back to raw meters outside the covered windows
+  const rawClauses = Array.from(ranges.entries()).map(([locationId, range]) => {
+    const windows = coveredWindows.get(locationId);
+    const clause: Record<string, unknown> = {
+      location: locationId,
+      readAt: { $gte: range.rangeStart, $lte: range.rangeEnd },
+    };
+    if (windows && windows.size > 0) {
+      clause.$nor = Array.from(windows.entries()).map(([start, end]) => ({
+        readAt: { $gte: new Date(start), $lte: end },
+      }));
+    }
+    return clause;
+  });
+
+  await addRawTotals(totals, rawClauses, groupBy);
+  return totals;
+}
+
+/**
+ * Sums movement totals like getMovementTotalsWithRollup, but from raw meters
+ * only. A meter that arrives after its day was rolled up is missing from the
+ * rollup until the day is rolled up again; reports that are filed use this
+ * so late meters always count.
+ *
+ * @param ranges - Map of locationId → gaming day range
+ * @param groupBy - Group results by 'machine' or 'location' (default: 'location')
+ * @returns Map of machine/location ID → summed movement totals
+ */
+export async function getMovementTotalsFromMeters(
+  ranges: Map<string, GamingDayRange>,
+  groupBy: RollupGroupKey = 'location'
+): Promise<Map<string, DailyMovementTotals>> {
+  const totals = new Map<string, DailyMovementTotals>();
+  if (ranges.size === 0) return totals;
+
+  await addRawTotals(
+    totals,
+    Array.from(ranges.entries()).map(([locationId, range]) => ({
+      location: locationId,
+      readAt: { $gte: range.rangeStart, $lte: range.rangeEnd },
+    })),
+    groupBy
+  );
+  return totals;
+}
@@ -0,0 +1,322 @@
+/**
+ * Daily Meters Rollup Backfill Helper
+ *
+ * Populates `metersDaily` for historical ranges month by month. Progress is
+ * persisted after every gaming day in `rollupCheckpoints`, so an interrupted or
+ * chunked backfill resumes from the last completed day. After each month the
+ * rollup is verified against raw meter totals and mismatches are recorded on
+ * the checkpoint. The job notification targets (jobNotifications) hear about
+ * the chunk that completes the range, or any chunk that fails.
+ *
+ * @module app/api/lib/helpers/metersDailyBackfill
+ */
+
+import {
+  DEFAULT_GAME_DAY_OFFSET,
+  fetchRollupLocations,
+  getDefaultRollupDay,
+  getRollupWindow,
+  rollupMetersForDay,
+} from '@/app/api/lib/helpers/metersDaily';
+import { notifyJobFinished } from '@/app/api/lib/helpers/jobNotifications';
+import { Meters } from '@/app/api/lib/models/meters';
+import { MetersDaily } from '@/app/api/lib/models/metersDaily';
+import { RollupCheckpoint } from '@/app/api/lib/models/rollupCheckpoint';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import {
+  buildMovementTotalsGroup,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import type {
+  RollupCheckpointDocument,
+  RollupVerificationMismatch,
+  RollupVerificationResult,
+} from '@/shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MetersDailyBackfillOptions = {
+  from: string;
+  to: string;
+  maxMonths?: number;
+  verify?: boolean;
+  restart?: boolean;
+};
+
+export type MetersDailyBackfillResult = {
+  checkpointId: string;
+  status: RollupCheckpointDocument['status'];
+  from: string;
+  to: string;
+  lastCompletedDay: string | null;
+  daysProcessed: number;
+  machinesWritten: number;
+  done: boolean;
+  verification: RollupVerificationResult[];
+};
+
+const BACKFILL_JOB = 'metersDaily-backfill';
+const VERIFICATION_TOLERANCE = 0.01;
+
+// ============================================================================
+// Day Arithmetic
+// ============================================================================
+
+function addDays(gamingDay: string, days: number): string {
+  const date = new Date(`${gamingDay}T00:00:00Z`);
+  date.setUTCDate(date.getUTCDate() + days);
+  return date.toISOString().slice(0, 10);
+}
+
+function endOfMonth(gamingDay: string): string {
+  const date = new Date(`${gamingDay}T00:00:00Z`);
+  const lastDay = new Date(
+    Date.UTC(date.getUTCFullYear(), date.getUTCMonth() + 1, 0)
+  );
+  return lastDay.toISOString().slice(0, 10);
+}
+
+// ============================================================================
+// Verification
+// ============================================================================
+
+/**
+ * Compares rolled-up totals to raw meter totals per location for a span of
+ * gaming days. Any movement field differing by more than the tolerance is
+ * reported as a mismatch.
+ *
+ * @param firstDay - First gaming day (YYYY-MM-DD)
+ * @param lastDay - Last gaming day (YYYY-MM-DD)
+ * @returns Verification result keyed by month (YYYY-MM of firstDay)
+ */
+export async function verifyRollupAgainstRaw(
+  firstDay: string,
+  lastDay: string
+): Promise<RollupVerificationResult> {
+  const locations = await fetchRollupLocations();
+
+  const rawClauses = locations.map(location => {
+    const offset = location.gameDayOffset ?? DEFAULT_GAME_DAY_OFFSET;
+    return {
+      location: String(location._id),
+      readAt: {
+        $gte: getRollupWindow(firstDay, offset).rangeStart,
+        $lte: getRollupWindow(lastDay, offset).rangeEnd,
+      },
+    };
+  });
+
+  const rawTotals = new Map<string, Record<string, number>>();
+  if (rawClauses.length > 0) {
+    const rawCursor = Meters.aggregate([
+      { $match: { $or: rawClauses } },
+      { $group: { _id: '$location', ...buildMovementTotalsGroup() } },
+    ]).cursor({ batchSize: 1000 });
+    for await (const doc of rawCursor) {
+      rawTotals.set(String(doc._id), doc);
+    }
+  }
+
+  const rollupTotals = new Map<string, Record<string, number>>();
+  const rollupCursor = MetersDaily.aggregate([
+    { $match: { gamingDay: { $gte: firstDay, $lte: lastDay } } },
+    { $group: { _id: '$location', ...buildMovementTotalsGroup() } },
+  ]).cursor({ batchSize: 1000 });
+  for await (const doc of rollupCursor) {
+    rollupTotals.set(String(doc._id), doc);
+  }
+
+  const mismatches: RollupVerificationMismatch[] = [];
+  const locationIds = new Set([...rawTotals.keys(), ...rollupTotals.keys()]);
+  locationIds.forEach(locationId => {
+    const raw = rawTotals.get(locationId) || {};
+    const rollup = rollupTotals.get(locationId) || {};
+    METER_MOVEMENT_FIELDS.forEach(field => {
+      const rawValue = Number(raw[field]) || 0;
+      const rollupValue = Number(rollup[field]) || 0;
+      if (Math.abs(rawValue - rollupValue) > VERIFICATION_TOLERANCE) {
+        mismatches.push({
+          location: locationId,
+          field,
+          raw: rawValue,
+          rollup: rollupValue,
+        });
+      }
+    });
+  });
+
+  return {
+    month: firstDay.slice(0, 7),
+    locationsChecked: locationIds.size,
+    mismatches,
+    verifiedAt: new Date(),
+  };
+}
+
+// ============================================================================
+// Backfill Runner
+// ============================================================================
+
+/**
+ * Fetches the checkpoint for a backfill range (or null if never started).
+ */
+export async function getBackfillCheckpoint(
+  from: string,
+  to: string
+): Promise<RollupCheckpointDocument | null> {
+  return RollupCheckpoint.findOne({
+    _id: `${BACKFILL_JOB}:${from}:${to}`,
+  }).lean<RollupCheckpointDocument>();
+}
+
+/**
+ * Runs the next chunk of a backfill. Processes up to `maxMonths` calendar
+ * months starting after the checkpoint's last completed day, verifying each
+ * month once it is fully rolled up. Call repeatedly until `done` is true.
+ *
+ * @param options - Range (YYYY-MM-DD, inclusive), chunk size, verify/restart flags
+ * @returns Progress summary for this chunk
+ */
+export async function runMetersDailyBackfill(
+  options: MetersDailyBackfillOptions
+): Promise<MetersDailyBackfillResult> {
+  assertWritable('metersDaily backfill');
+  const { from, maxMonths = 1, verify = true, restart = false } = options;
+  const latestCompleteDay = getDefaultRollupDay();
+  const to = options.to > latestCompleteDay ? latestCompleteDay : options.to;
+  const checkpointId = `${BACKFILL_JOB}:${from}:${options.to}`;
+
+  // Step 1: Load or (re)create checkpoint
+  let checkpoint = await RollupCheckpoint.findOne({
+    _id: checkpointId,
+  }).lean<RollupCheckpointDocument>();
+
+  if (!checkpoint || restart) {
+    checkpoint = await RollupCheckpoint.findOneAndUpdate(
+      { _id: checkpointId },
+      {
+        $set: {
+          job: BACKFILL_JOB,
+          from,
+          to: options.to,
+          lastCompletedDay: null,
+          status: 'running',
+          verification: [],
+        },
+        $unset: { lastError: '' },
+      },
+      { upsert: true, new: true }
+    ).lean<RollupCheckpointDocument>();
+  }
+
+  if (!checkpoint) {
+    throw new Error('Failed to initialise backfill checkpoint');
+  }
+
+  const startedAt = new Date();
+  const reportLink = `/api/admin/rollup-meters-daily/backfill?from=${from}&to=${options.to}`;
+  let lastCompletedDay = checkpoint.lastCompletedDay;
+  let daysProcessed = 0;
+  let machinesWritten = 0;
+  const verification: RollupVerificationResult[] = [];
+  let nextDay = lastCompletedDay ? addDays(lastCompletedDay, 1) : from;
+
+  // Step 2: Process month chunks
+  try {
+    for (
+      let monthsProcessed = 0;
+      monthsProcessed < maxMonths && nextDay <= to;
+      monthsProcessed++
+    ) {
+      const monthStart = nextDay;
+      const monthEnd = endOfMonth(monthStart) < to ? endOfMonth(monthStart) : to;
+
+      for (let day = monthStart; day <= monthEnd; day = addDays(day, 1)) {
+        const result = await rollupMetersForDay(day);
+        machinesWritten += result.machinesWritten;
+        daysProcessed++;
+        lastCompletedDay = day;
+        await RollupCheckpoint.updateOne(
+          { _id: checkpointId },
+          { $set: { lastCompletedDay: day, status: 'running' } }
+        );
+      }
+
+      if (verify) {
+        const monthResult = await verifyRollupAgainstRaw(monthStart, monthEnd);
+        verification.push(monthResult);
+        await RollupCheckpoint.updateOne(
+          { _id: checkpointId },
+          { $push: { verification: monthResult } }
+        );
+      }
+
+      nextDay = addDays(monthEnd, 1);
+    }
+  } catch (error) {
+    const errorMessage =
+      error instanceof Error ? error.message : 'Unknown error';
+    await RollupCheckpoint.updateOne(
+      { _id: checkpointId },
+      { $set: { status: 'failed', lastError: errorMessage } }
+    );
+    await notifyJobFinished({
+      job: BACKFILL_JOB,
+      kind: 'pre-aggregation',
+      status: 'failed',
+      target: `${from}..${options.to}`,
+      startedAt,
+      finishedAt: new Date(),
+      counts: { daysProcessed, machinesWritten },
+      summary: `Stopped after ${lastCompletedDay ?? 'no completed day'}`,
+      report: reportLink,
+      error: errorMessage,
+    });
+    throw error;
+  }
+
+  // Step 3: Mark completion once the whole requested range is covered
+  const done = nextDay > options.to;
+  const status: RollupCheckpointDocument['status'] = done
+    ? 'completed'
+    : 'running';
+  await RollupCheckpoint.updateOne({ _id: checkpointId }, { $set: { status } });
+
+  if (done) {
+    const completed = await getBackfillCheckpoint(from, options.to);
+    const months = completed?.verification ?? verification;
+    await notifyJobFinished({
+      job: BACKFILL_JOB,
+      kind: 'pre-aggregation',
+      status: 'completed',
+      target: `${from}..${options.to}`,
+      startedAt,
+      finishedAt: new Date(),
+      counts: {
+        daysProcessed,
+        machinesWritten,
+        monthsVerified: months.length,
+        mismatches: months.reduce(
+          (sum, month) => sum + month.mismatches.length,
+          0
+        ),
+      },
+      summary: 'Range fully rolled up',
+      report: reportLink,
+    });
+  }
+
+  return {
+    checkpointId,
+    status,
+    from,
+    to: options.to,
+    lastCompletedDay,
+    daysProcessed,
+    machinesWritten,
+    done,
+    verification,
+  };
+}
@@ -0,0 +1,264 @@
+/**
+ * Migration Delta Re-sync Helper
+ *
+ * After the bulk copy the source keeps receiving writes. A re-sync copies
+ * only the documents created or updated since a given time from the source
+ * to the destination, upserting by `_id`, so the destination can be topped
+ * up repeatedly before cutover. Each run reports the time it started; pass
+ * it as the next run's `since` so no write falls between two runs.
+ *
+ * Changes are found by timestamp fields (`updatedAt` / `createdAt`, or the
+ * collection's own time field such as `readAt` for meters), not the oplog,
+ * so hard deletes on the source are not carried over. The collection's
+ * migration transforms (see migrationTransforms) are applied before writing.
+ * Destination documents about to be replaced are first copied to
+ * `documentBackups` under one run per re-sync (see writeBackups).
+ *
+ * Used by the `resync` command (scripts/resync.ts).
+ *
+ * @module app/api/lib/helpers/migrationResync
+ */
+
+import { DEFAULT_TIME_FIELDS } from '@/app/api/lib/helpers/dbConsistency';
+import {
+  backupBeforeWrite,
+  createBackupRunId,
+} from '@/app/api/lib/helpers/writeBackups';
+import {
+  applyMigrationTransforms,
+  getMigrationProjection,
+  loadMigrationTransformRules,
+} from '@/app/api/lib/utils/migrationTransforms';
+import type {
+  MigrationTransformRules,
+} from '@/app/api/lib/utils/migrationTransforms';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import type { Connection, mongo } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ResyncTarget = {
+  collection: string;
+  /** A document changed when any of these is at or after `since` */
+  timeFields: string[];
+};
+
+export type ResyncOptions = {
+  since: Date;
+  /** Documents per bulk write */
+  batchSize: number;
+  /** Apply the collection's migration transforms (default true) */
+  transforms: boolean;
+  dryRun: boolean;
+};
+
+export type CollectionResync = {
+  collection: string;
+  timeFields: string[];
+  /** Changed documents read from the source */
+  read: number;
+  inserted: number;
+  updated: number;
+  unchanged: number;
+  /** Destination documents copied to `documentBackups` before being replaced */
+  backedUp: number;
+};
+
+export type ResyncReport = {
+  since: Date;
+  /** When the run started; the `since` for the next run */
+  startedAt: Date;
+  finishedAt: Date;
+  dryRun: boolean;
+  /** `documentBackups` run on the destination; null on a dry run */
+  backupRunId: string | null;
+  collections: CollectionResync[];
+};
+
+/** Collections the machines-meters migration exports */
+export const DEFAULT_RESYNC_COLLECTIONS = [
+  'licencees',
+  'gaminglocations',
+  'machines',
+  'meters',
+  'users',
+];
+
+export const DEFAULT_RESYNC_BATCH_SIZE = 1000;
+
+const DEFAULT_CHANGE_FIELDS = ['updatedAt', 'createdAt'];
+
+type RawDocument = Record<string, unknown> & { _id: unknown };
+
+// ============================================================================
+// Targets
+// ============================================================================
+
+/**
+ * Parses `collection` or `collection:field` into a target. Without a field,
+ * the collection's time field (see DEFAULT_TIME_FIELDS) or `updatedAt` /
+ * `createdAt` is used.
+ */
+export function parseResyncTarget(spec: string): ResyncTarget {
+  const [collection, field] = spec.split(':').map(part => part.trim());
+  if (!collection) throw new Error(`Invalid collection '${spec}'`);
+  const timeFields = field
+    ? [field]
+    : DEFAULT_TIME_FIELDS[collection]
+      ? [DEFAULT_TIME_FIELDS[collection]]
+      : DEFAULT_CHANGE_FIELDS;
+  return { collection, timeFields };
+}
+
+// ============================================================================
+// Runner
+// ============================================================================
+
+async function writeBatch(
+  destination: Connection,
+  collection: string,
+  documents: RawDocument[],
+  result: CollectionResync,
+  backupRunId: string
+) {
+  // Only documents already in the destination are overwritten
+  const backup = await backupBeforeWrite(
+    {
+      operation: 'resync',
+      collection,
+      filter: { _id: { $in: documents.map(document => document._id) } },
+      runId: backupRunId,
+    },
+    destination
+  );
+  result.backedUp += backup.documents;
+  const bulk = await destination
+    .collection(collection)
+    .bulkWrite(
+      documents.map(document => ({
+        replaceOne: {
+          filter: { _id: document._id },
+          replacement: document,
+          upsert: true,
+        },
+      })) as mongo.AnyBulkWriteOperation[],
+      { ordered: false }
+    );
+  result.inserted += bulk.upsertedCount;
+  result.updated += bulk.modifiedCount;
+  result.unchanged += bulk.matchedCount - bulk.modifiedCount;
+}
+
+/**
+ * Copies each target's documents changed since `options.since` from the
+ * source to the destination, in `_id` order and batches of
+ * `options.batchSize`. A dry run only counts them.
+ *
+ * @param source - Source connection
+ * @param destination - Destination connection
+ * @param targets - Collections to re-sync
+ * @param options - Cut-off time, batch size, transforms and dry run
+ * @param rules - Transform rules (default: the rules file)
+ */
+export async function resyncChangedDocuments(
+  source: Connection,
+  destination: Connection,
+  targets: ResyncTarget[],
+  options: ResyncOptions,
+  rules: MigrationTransformRules = loadMigrationTransformRules()
+): Promise<ResyncReport> {
+  if (!options.dryRun) assertWritable('re-syncing migrated documents');
+
+  const startedAt = new Date();
+  const backupRunId = createBackupRunId('resync');
+  const collections: CollectionResync[] = [];
+  for (const target of targets) {
+    const result: CollectionResync = {
+      collection: target.collection,
+      timeFields: target.timeFields,
+      read: 0,
+      inserted: 0,
+      updated: 0,
+      unchanged: 0,
+      backedUp: 0,
+    };
+    collections.push(result);
+
+    // Writes landing while the run is in progress are left for the next run
+    const window = { $gte: options.since, $lt: startedAt };
+    const filter =
+      target.timeFields.length === 1
+        ? { [target.timeFields[0]]: window }
+        : { $or: target.timeFields.map(field => ({ [field]: window })) };
+    const projection = options.transforms
+      ? getMigrationProjection(target.collection, [], rules)
+      : undefined;
+    const cursor = source
+      .collection(target.collection)
+      .find(filter, projection ? { projection } : {})
+      .sort({ _id: 1 })
+      .batchSize(options.batchSize);
+
+    let batch: RawDocument[] = [];
+    const flush = async () => {
+      result.read += batch.length;
+      if (!options.dryRun && batch.length > 0) {
+        const documents = options.transforms
+          ? await applyMigrationTransforms(target.collection, batch, rules)
+          : batch;
+        await writeBatch(
+          destination,
+          target.collection,
+          documents as RawDocument[],
+          result,
+          backupRunId
+        );
+      }
+      batch = [];
+    };
+    for await (const document of cursor) {
+      batch.push(document as RawDocument);
+      if (batch.length >= options.batchSize) await flush();
+    }
+    await flush();
+  }
+
+  return {
+    since: options.since,
+    startedAt,
+    finishedAt: new Date(),
+    dryRun: options.dryRun,
+    backupRunId: options.dryRun ? null : backupRunId,
+    collections,
+  };
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+/**
+ * Plain-text report of a re-sync run.
+ */
+export function formatResyncReport(report: ResyncReport): string {
+  const lines = report.collections.map(collection =>
+    report.dryRun
+      ? `  ${collection.collection} (${collection.timeFields.join(' | ')}): ${collection.read} changed`
+      : `  ${collection.collection} (${collection.timeFields.join(' | ')}): read=${collection.read} inserted=${collection.inserted} updated=${collection.updated} unchanged=${collection.unchanged} backedUp=${collection.backedUp}`
+  );
+  const read = report.collections.reduce(
+    (sum, collection) => sum + collection.read,
+    0
+  );
+  return [
+    `Changes ${report.since.toISOString()} → ${report.startedAt.toISOString()}${report.dryRun ? ' (dry run)' : ''}`,
+    ...lines,
+    `${read} document(s) ${report.dryRun ? 'would be copied' : 'copied'}`,
+    ...(report.backupRunId
+      ? [`Replaced documents backed up under run ${report.backupRunId}`]
+      : []),
+    `Next run: --since ${report.startedAt.toISOString()}`,
+  ].join('\n');
+}
@@ -141,56 +141,3 @@ export function extractMQTTConfig(cabinet: MachineDocument): MQTTConfig {
     otaURL: cabinet.smibConfig?.ota?.otaURL || 'No Value Provided',
   };
 }
-
-/**
- * Validates MQTT config publish request body
- *
- * @param body - Request body
- * @returns Validation error message or null if valid
- */
-export function validateMQTTConfigPublish(body: unknown): string | null {
-  if (!body || typeof body !== 'object') {
-    return 'Request body is required';
-  }
-
-  const bodyObj = body as Record<string, unknown>;
-
-  if (!bodyObj.relayId) {
-    return 'relayId is required';
-  }
-
-  if (!bodyObj.config) {
-    return 'config object is required';
-  }
-
-  const config = bodyObj.config as Record<string, unknown>;
-  if (!config.typ || !config.comp) {
-    return "config must include 'typ' and 'comp' fields";
-  }
-
-  return null;
-}
-
-/**
- * Validates MQTT config request body
- *
- * @param body - Request body
- * @returns Validation error message or null if valid
- */
-export function validateMQTTConfigRequest(body: unknown): string | null {
-  if (!body || typeof body !== 'object') {
-    return 'Request body is required';
-  }
-
-  const bodyObj = body as Record<string, unknown>;
-
-  if (!bodyObj.relayId) {
-    return 'relayId is required';
-  }
-
-  if (!bodyObj.component) {
-    return 'component is required';
-  }
-
-  return null;
-}
@@ -0,0 +1,181 @@
+/**
+ * OpenAPI Document
+ *
+ * Generates the OpenAPI 3.0 document served at `/openapi.json` from the
+ * route schemas in app/api/lib/routeSchemas. Handlers validate their query
+ * and body with the same schemas, so the spec changes with the handlers;
+ * payloads typed only in TypeScript are left open and name the type they
+ * follow. `bun run openapi:check` verifies every documented operation
+ * still has a handler and every `withApiAuth` handler is documented.
+ *
+ * To document a route, declare it with `defineRoute()` in the module for
+ * its area; routes with a query or body get the 400 response their
+ * validation returns when they do not declare one.
+ *
+ * @module app/api/lib/helpers/openapi
+ */
+
+import * as routeSchemas from '@/app/api/lib/routeSchemas';
+import { errors, isRouteSchema } from '@/app/api/lib/routeSchemas/common';
+import type { RouteSchema } from '@/app/api/lib/routeSchemas/common';
+import { zodToJsonSchema } from '@/app/api/lib/utils/zodJsonSchema';
+import type { JsonSchema } from '@/app/api/lib/utils/zodJsonSchema';
+import packageJson from '@/package.json';
+import { z } from 'zod';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+type OpenApiParameter = {
+  name: string;
+  in: 'path' | 'query';
+  required: boolean;
+  description?: string;
+  schema: JsonSchema;
+};
+
+// ============================================================================
+// Operations
+// ============================================================================
+
+/** Adds the 400 a route's query / body validation returns, if undeclared */
+function withValidationResponse(route: RouteSchema): RouteSchema {
+  if ((!route.query && !route.body) || route.responses[400]) return route;
+  return {
+    ...route,
+    responses: { ...route.responses, 400: errors.badRequest },
+  };
+}
+
+/** Every declared route schema, ordered by path and method */
+export const API_OPERATIONS: RouteSchema[] = Object.values(routeSchemas)
+  .flatMap(area => Object.values(area))
+  .filter(isRouteSchema)
+  .map(withValidationResponse)
+  .sort(
+    (a, b) => a.path.localeCompare(b.path) || a.method.localeCompare(b.method)
+  );
+
+// ============================================================================
+// Document
+// ============================================================================
+
+/**
+ * `GET /api/machines/{machineId}` -> `getMachinesMachineId`
+ */
+function operationId(operation: RouteSchema): string {
+  const words = operation.path
+    .split('/')
+    .filter(segment => segment && segment !== 'api')
+    .flatMap(segment => segment.replace(/[{}]/g, '').split('-'));
+  return (
+    operation.method.toLowerCase() +
+    words.map(word => word[0].toUpperCase() + word.slice(1)).join('')
+  );
+}
+
+function buildParameters(operation: RouteSchema): OpenApiParameter[] {
+  const pathParameters = Array.from(
+    operation.path.matchAll(/\{(\w+)\}/g),
+    match => ({
+      name: match[1],
+      in: 'path' as const,
+      required: true,
+      schema: { type: 'string' },
+    })
+  );
+  if (!operation.query) return pathParameters;
+
+  const query = zodToJsonSchema(operation.query);
+  const queryParameters = Object.entries(query.properties || {}).map(
+    ([name, { description, ...schema }]) => ({
+      name,
+      in: 'query' as const,
+      required: query.required?.includes(name) ?? false,
+      ...(description ? { description } : {}),
+      schema,
+    })
+  );
+  return [...pathParameters, ...queryParameters];
+}
+
+function jsonContent(schema: z.ZodTypeAny) {
+  return { 'application/json': { schema: zodToJsonSchema(schema) } };
+}
+
+function bodyContent(schema: z.ZodTypeAny, multipart?: boolean) {
+  return multipart
+    ? { 'multipart/form-data': { schema: zodToJsonSchema(schema) } }
+    : jsonContent(schema);
+}
+
+/**
+ * The OpenAPI 3.0 document for the given operations.
+ *
+ * @param operations - Operations to document (default: API_OPERATIONS)
+ */
+export function buildOpenApiDocument(
+  operations: RouteSchema[] = API_OPERATIONS
+) {
+  const paths: Record<string, Record<string, unknown>> = {};
+  operations.forEach(operation => {
+    const parameters = buildParameters(operation);
+    paths[operation.path] = {
+      ...paths[operation.path],
+      [operation.method.toLowerCase()]: {
+        operationId: operationId(operation),
+        tags: [operation.tag],
+        summary: operation.summary,
+        ...(operation.description
+          ? { description: operation.description }
+          : {}),
+        ...(parameters.length > 0 ? { parameters } : {}),
+        ...(operation.body
+          ? {
+              requestBody: {
+                required: !operation.body.isOptional(),
+                content: bodyContent(operation.body, operation.multipart),
+              },
+            }
+          : {}),
+        responses: Object.fromEntries(
+          Object.entries(operation.responses).map(([status, response]) => [
+            status,
+            {
+              description: response.description,
+              ...(response.schema
+                ? {
+                    content: {
+                      ...jsonContent(response.schema),
+                      ...(operation.csv && status === '200'
+                        ? { 'text/csv': { schema: { type: 'string' } } }
+                        : {}),
+                    },
+                  }
+                : {}),
+            },
+          ])
+        ),
+        ...(operation.public ? { security: [] } : {}),
+      },
+    };
+  });
+
+  return {
+    openapi: '3.0.3',
+    info: {
+      title: 'Casino Management System API',
+      version: packageJson.version,
+    },
+    paths,
+    components: {
+      securitySchemes: {
+        cookieAuth: { type: 'apiKey', in: 'cookie', name: 'token' },
+        bearerAuth: { type: 'http', scheme: 'bearer', bearerFormat: 'JWT' },
+        apiKeyAuth: { type: 'apiKey', in: 'header', name: 'X-API-Key' },
+      },
+    },
+    security: [{ cookieAuth: [] }, { bearerAuth: [] }, { apiKeyAuth: [] }],
+  };
+}
@@ -0,0 +1,412 @@
+/**
+ * Regulator Submission Helper
+ *
+ * Builds the gaming commission's monthly per-machine meter submission for a
+ * licencee and renders it as a fixed-width file or XML. Movement is summed
+ * over each location's gaming days in the calendar month (rollup first, raw
+ * meters otherwise) for every machine registered during the month.
+ *
+ * The submission is validated before rendering; a file is only produced
+ * when it passes:
+ * - every registered machine must have meter movement for the month
+ * - no meter value may be negative
+ * - serial numbers must be present and fit their fixed-width column
+ *
+ * Fixed-width layout (amounts in cents, zero-padded, right-aligned; text
+ * space-padded, left-aligned), one record per line:
+ * - `H` licence key (20), month YYYYMM (6), generated YYYYMMDD (8),
+ *   detail record count (8)
+ * - `D` location ID (24), serial number (20), coin in, coin out, drop,
+ *   cancelled credits, hand paid, jackpot (15 each), games played (12)
+ * - `T` detail record count (8), then the column totals as in `D`
+ *
+ * @module app/api/lib/helpers/regulatorSubmission
+ */
+
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type SubmissionFormat = 'fixed' | 'xml';
+
+export const SUBMISSION_FORMATS: SubmissionFormat[] = ['fixed', 'xml'];
+
+export type SubmissionMeters = {
+  coinIn: number;
+  coinOut: number;
+  drop: number;
+  cancelledCredits: number;
+  handPaid: number;
+  jackpot: number;
+  gamesPlayed: number;
+};
+
+export type SubmissionMachine = SubmissionMeters & {
+  machineId: string;
+  serialNumber: string;
+  locationId: string;
+  locationName: string;
+  /** False when the machine has no meter movement in the month */
+  reported: boolean;
+};
+
+export type MonthlySubmission = {
+  licenceeId: string;
+  licenceeName: string;
+  licenceKey: string;
+  /** YYYY-MM */
+  month: string;
+  generatedAt: Date;
+  machines: SubmissionMachine[];
+  totals: SubmissionMeters;
+};
+
+export type SubmissionIssue = {
+  machineId: string;
+  serialNumber: string;
+  locationName: string;
+  problem: string;
+};
+
+const METER_COLUMNS: Array<{
+  field: keyof SubmissionMeters;
+  label: string;
+  element: string;
+  width: number;
+  cents: boolean;
+}> = [
+  {
+    field: 'coinIn',
+    label: 'coin in',
+    element: 'CoinIn',
+    width: 15,
+    cents: true,
+  },
+  {
+    field: 'coinOut',
+    label: 'coin out',
+    element: 'CoinOut',
+    width: 15,
+    cents: true,
+  },
+  {
+    field: 'drop',
+    label: 'drop',
+    element: 'Drop',
+    width: 15,
+    cents: true,
+  },
+  {
+    field: 'cancelledCredits',
+    label: 'cancelled credits',
+    element: 'CancelledCredits',
+    width: 15,
+    cents: true,
+  },
+  {
+    field: 'handPaid',
+    label: 'hand paid',
+    element: 'HandPaid',
+    width: 15,
+    cents: true,
+  },
+  {
+    field: 'jackpot',
+    label: 'jackpot',
+    element: 'Jackpot',
+    width: 15,
+    cents: true,
+  },
+  {
+    field: 'gamesPlayed',
+    label: 'games played',
+    element: 'GamesPlayed',
+    width: 12,
+    cents: false,
+  },
+];
+
+const LOCATION_WIDTH = 24;
+const SERIAL_WIDTH = 20;
+const LICENCE_KEY_WIDTH = 20;
+const COUNT_WIDTH = 8;
+
+function emptyMeters(): SubmissionMeters {
+  return {
+    coinIn: 0,
+    coinOut: 0,
+    drop: 0,
+    cancelledCredits: 0,
+    handPaid: 0,
+    jackpot: 0,
+    gamesPlayed: 0,
+  };
+}
+
+// ============================================================================
+// Build & Validate
+// ============================================================================
+
+/**
+ * Collects the monthly meter summary for a licencee's machines.
+ *
+ * @param licenceeId - Licencee ID
+ * @param month - Calendar month, YYYY-MM
+ * @throws Error when the licencee does not exist
+ */
+export async function buildMonthlySubmission(
+  licenceeId: string,
+  month: string
+): Promise<MonthlySubmission> {
+  const licencee = await Licencee.findOne(
+    { _id: licenceeId },
+    { _id: 1, name: 1, licenceKey: 1 }
+  ).lean<{ _id: string; name: string; licenceKey?: string }>();
+  if (!licencee) throw new Error(`Licencee ${licenceeId} not found`);
+
+  const [year, monthNumber] = month.split('-').map(Number);
+  const monthStart = new Date(Date.UTC(year, monthNumber - 1, 1));
+  const monthEnd = new Date(Date.UTC(year, monthNumber, 0));
+
+  // Step 1: Locations and their month ranges
+  const locations = await GamingLocations.find(
+    { 'rel.licencee': licenceeId, ...NOT_DELETED_FILTER },
+    { _id: 1, name: 1, gameDayOffset: 1 }
+  ).lean<Array<{ _id: string; name?: string; gameDayOffset?: number }>>();
+  const ranges = new Map<string, GamingDayRange>();
+  locations.forEach(location => {
+    ranges.set(
+      String(location._id),
+      getGamingDayRangeForPeriod(
+        'Custom',
+        location.gameDayOffset ?? 8,
+        monthStart,
+        monthEnd
+      )
+    );
+  });
+  const locationNames = new Map(
+    locations.map(location => [
+      String(location._id),
+      location.name || String(location._id),
+    ])
+  );
+
+  // Step 2: Machines registered at some point during the month
+  const monthRangeEnd = new Date(Date.UTC(year, monthNumber, 1));
+  const [machines, totals] = await Promise.all([
+    Machine.find(
+      {
+        gamingLocation: { $in: Array.from(ranges.keys()) },
+        $and: [
+          {
+            $or: [
+              { ...NOT_DELETED_FILTER },
+              { deletedAt: { $gte: monthStart } },
+            ],
+          },
+          {
+            $or: [
+              { createdAt: { $exists: false } },
+              { createdAt: { $lt: monthRangeEnd } },
+            ],
+          },
+        ],
+      },
+      { _id: 1, serialNumber: 1, origSerialNumber: 1, gamingLocation: 1 }
+    ).lean<
+      Array<{
+        _id: string;
+        serialNumber?: string;
+        origSerialNumber?: string;
+        gamingLocation: string;
+      }>
+    >(),
+    getMovementTotalsWithRollup(ranges, 'machine'),
+  ]);
+
+  // Step 3: Per-machine meters and totals
+  const submissionTotals = emptyMeters();
+  const rows: SubmissionMachine[] = machines.map(machine => {
+    const movement = totals.get(String(machine._id));
+    const meters: SubmissionMeters = movement
+      ? {
+          coinIn: movement.coinIn || 0,
+          coinOut: movement.coinOut || 0,
+          drop: movement.drop || 0,
+          cancelledCredits: movement.totalCancelledCredits || 0,
+          handPaid: movement.totalHandPaidCancelledCredits || 0,
+          jackpot: movement.jackpot || 0,
+          gamesPlayed: movement.gamesPlayed || 0,
+        }
+      : emptyMeters();
+    METER_COLUMNS.forEach(({ field }) => {
+      submissionTotals[field] += meters[field];
+    });
+    return {
+      ...meters,
+      machineId: String(machine._id),
+      serialNumber:
+        machine.serialNumber?.trim() || machine.origSerialNumber?.trim() || '',
+      locationId: machine.gamingLocation,
+      locationName:
+        locationNames.get(machine.gamingLocation) || machine.gamingLocation,
+      reported: Boolean(movement),
+    };
+  });
+  rows.sort(
+    (a, b) =>
+      a.locationName.localeCompare(b.locationName) ||
+      a.serialNumber.localeCompare(b.serialNumber)
+  );
+
+  return {
+    licenceeId,
+    licenceeName: licencee.name,
+    licenceKey: licencee.licenceKey || licenceeId,
+    month,
+    generatedAt: new Date(),
+    machines: rows,
+    totals: submissionTotals,
+  };
+}
+
+/**
+ * Checks a submission before it is rendered.
+ *
+ * @returns Problems found; an empty list means the file can be filed
+ */
+export function validateSubmission(
+  submission: MonthlySubmission
+): SubmissionIssue[] {
+  const issues: SubmissionIssue[] = [];
+  const issue = (machine: SubmissionMachine, problem: string) =>
+    issues.push({
+      machineId: machine.machineId,
+      serialNumber: machine.serialNumber,
+      locationName: machine.locationName,
+      problem,
+    });
+
+  submission.machines.forEach(machine => {
+    if (!machine.reported) issue(machine, 'No meter movement for the month');
+    if (!machine.serialNumber) issue(machine, 'Missing serial number');
+    if (machine.serialNumber.length > SERIAL_WIDTH) {
+      issue(machine, `Serial number longer than ${SERIAL_WIDTH} characters`);
+    }
+    METER_COLUMNS.forEach(({ field, label, width, cents }) => {
+      const value = machine[field];
+      if (value < 0) issue(machine, `Negative ${label} (${value})`);
+      const digits = String(Math.round(cents ? value * 100 : value));
+      if (digits.length > width) {
+        issue(machine, `${label} does not fit ${width} digits`);
+      }
+    });
+  });
+  return issues;
+}
+
+// ============================================================================
+// Rendering
+// ============================================================================
+
+function text(value: string, width: number): string {
+  return value.slice(0, width).padEnd(width, ' ');
+}
+
+function digits(value: number, width: number, cents: boolean): string {
+  return String(Math.round(cents ? value * 100 : value)).padStart(width, '0');
+}
+
+function meterFields(meters: SubmissionMeters): string {
+  return METER_COLUMNS.map(({ field, width, cents }) =>
+    digits(meters[field], width, cents)
+  ).join('');
+}
+
+function escapeXml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&apos;');
+}
+
+function meterElements(meters: SubmissionMeters, indent: string): string[] {
+  return METER_COLUMNS.map(
+    ({ field, element, cents }) =>
+      `${indent}<${element}>${
+        cents ? meters[field].toFixed(2) : Math.round(meters[field])
+      }</${element}>`
+  );
+}
+
+/**
+ * Renders a validated submission in the commission's fixed-width layout.
+ */
+export function renderFixedWidthSubmission(
+  submission: MonthlySubmission
+): string {
+  const count = submission.machines.length;
+  const generated = submission.generatedAt
+    .toISOString()
+    .slice(0, 10)
+    .replace(/-/g, '');
+  const lines = [
+    'H' +
+      text(submission.licenceKey, LICENCE_KEY_WIDTH) +
+      submission.month.replace('-', '') +
+      generated +
+      digits(count, COUNT_WIDTH, false),
+    ...submission.machines.map(
+      machine =>
+        'D' +
+        text(machine.locationId, LOCATION_WIDTH) +
+        text(machine.serialNumber, SERIAL_WIDTH) +
+        meterFields(machine)
+    ),
+    'T' + digits(count, COUNT_WIDTH, false) + meterFields(submission.totals),
+  ];
+  return `${lines.join('\r\n')}\r\n`;
+}
+
+/**
+ * Renders a validated submission as XML.
+ */
+export function renderXmlSubmission(submission: MonthlySubmission): string {
+  const lines = [
+    '<?xml version="1.0" encoding="UTF-8"?>',
+    `<MonthlySubmission licenceKey="${escapeXml(submission.licenceKey)}" month="${submission.month}" generated="${submission.generatedAt.toISOString()}" machineCount="${submission.machines.length}">`,
+    ...submission.machines.flatMap(machine => [
+      `  <Machine location="${escapeXml(machine.locationId)}" serialNumber="${escapeXml(machine.serialNumber)}">`,
+      ...meterElements(machine, '    '),
+      '  </Machine>',
+    ]),
+    '  <Totals>',
+    ...meterElements(submission.totals, '    '),
+    '  </Totals>',
+    '</MonthlySubmission>',
+  ];
+  return `${lines.join('\n')}\n`;
+}
+
+/**
+ * Renders a submission in the requested format.
+ */
+export function renderSubmission(
+  submission: MonthlySubmission,
+  format: SubmissionFormat
+): string {
+  return format === 'xml'
+    ? renderXmlSubmission(submission)
+    : renderFixedWidthSubmission(submission);
+}
@@ -0,0 +1,110 @@
+/**
+ * Machine Utilization Tests
+ *
+ * Covers that retired machines are left out of the utilization report: they
+ * are neither listed nor counted in their location's average, even when they
+ * still have sessions in the period.
+ */
+
+const mockLocationFind = jest.fn();
+const mockMachineFind = jest.fn();
+const mockSessionAggregate = jest.fn();
+
+jest.mock('@/app/api/lib/models/gaminglocations', () => ({
+  GamingLocations: {
+    find: (filter: unknown, projection: unknown) =>
+      mockLocationFind(filter, projection),
+  },
+}));
+
+jest.mock('@/app/api/lib/models/machines', () => ({
+  Machine: {
+    find: (filter: unknown, projection: unknown) =>
+      mockMachineFind(filter, projection),
+  },
+}));
+
+jest.mock('@/app/api/lib/models/machineSessions', () => ({
+  MachineSession: {
+    aggregate: (pipeline: unknown) => mockSessionAggregate(pipeline),
+  },
+}));
+
+import { getMachineUtilizationReport } from '../machineUtilization';
+
+const HOUR_MS = 60 * 60 * 1000;
+
+const machines = [
+  { _id: 'm-active', serialNumber: 'A-1', gamingLocation: 'loc-1' },
+  {
+    _id: 'm-retired',
+    serialNumber: 'R-1',
+    gamingLocation: 'loc-1',
+    assetStatus: 'retired',
+  },
+];
+
+/** Applies the query's assetStatus exclusion the way MongoDB would */
+function findMachines(filter: { assetStatus?: { $ne?: string } }) {
+  const excluded = filter.assetStatus?.$ne;
+  return {
+    lean: jest
+      .fn()
+      .mockResolvedValue(
+        machines.filter(
+          machine => !excluded || machine.assetStatus !== excluded
+        )
+      ),
+  };
+}
+
+beforeEach(() => {
+  mockLocationFind.mockReset().mockReturnValue({
+    lean: jest
+      .fn()
+      .mockResolvedValue([{ _id: 'loc-1', name: 'Main', gameDayOffset: 8 }]),
+  });
+  mockMachineFind.mockReset().mockImplementation(findMachines);
+  // Both machines have sessions; only the machine query decides who counts
+  mockSessionAggregate.mockReset().mockResolvedValue([
+    { _id: 'm-active', sessions: 2, durationMs: 6 * HOUR_MS },
+    { _id: 'm-retired', sessions: 10, durationMs: 40 * HOUR_MS },
+  ]);
+});
+
+describe('getMachineUtilizationReport', () => {
+  const params = {
+    allowedLocationIds: 'all' as const,
+    timePeriod: 'Custom',
+    customStartDate: new Date('2026-01-01T00:00:00Z'),
+    customEndDate: new Date('2026-01-02T00:00:00Z'),
+  };
+
+  it('queries machines without the retired ones', async () => {
+    await getMachineUtilizationReport(params);
+
+    expect(mockMachineFind).toHaveBeenCalledWith(
+      expect.objectContaining({ assetStatus: { $ne: 'retired' } }),
+      expect.anything()
+    );
+  });
+
+  it('leaves a retired machine out of the rows and the average', async () => {
+    const [location] = await getMachineUtilizationReport(params);
+
+    expect(location.machineCount).toBe(1);
+    expect(location.machines.map(row => row.machineId)).toEqual(['m-active']);
+    expect(location.machines[0].sessions).toBe(2);
+    expect(location.averageOccupancyHoursPerDay).toBe(
+      location.machines[0].occupancyHoursPerDay
+    );
+    expect(location.underutilizedCount).toBe(0);
+  });
+
+  it('only aggregates sessions of machines still in service', async () => {
+    await getMachineUtilizationReport(params);
+
+    const [{ $match }] = mockSessionAggregate.mock.calls[0][0];
+    expect($match.machineId).toEqual({ $in: ['m-active'] });
+  });
+});
@@ -7,14 +7,44 @@
  * @module app/api/lib/helpers/analytics
  */
 
+import {
+  fanOutByLocation,
+  getAggregationStrategy,
+  sumPartials,
+} from '@/app/api/lib/helpers/aggregationFanOut';
+import type { AggregationStrategy } from '@/app/api/lib/helpers/aggregationFanOut';
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Countries } from '@/app/api/lib/models/countries';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildActiveAssetStatusExpression,
+  getOnlineCutoff,
+} from '@/app/api/lib/utils/machineStatus';
+import {
+  anyIdTypeIn,
+  anyIdTypeLookup,
+  mixedIdLookup,
+} from '@/app/api/lib/utils/mongoIds';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  resolveFinancialFormula,
+} from '@/app/api/lib/utils/financialFormulas';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import { convertFromUSD } from '@/lib/helpers/rates';
-import type { CountryDocument, LicenceeDocument } from '@/shared/types';
+import type {
+  CountryDocument,
+  FinancialFormula,
+  LicenceeDocument,
+  MachineStatusOverride,
+  MovementTotals,
+} from '@/shared/types';
 import type { MachineAnalytics } from '@/shared/types/reports';
 import type { CurrencyCode } from '@/shared/types/currency';
 import { subDays } from 'date-fns';
@@ -41,7 +71,8 @@ function buildMachineAnalyticsPipeline(
     );
     return [];
   }
-  const pipeline: PipelineStage[] = [];
+  // Stage 0: Leave out retired machines
+  const pipeline: PipelineStage[] = [{ $match: { ...NOT_RETIRED_FILTER } }];
 
   // Stage 1: Filter machines by allowed locations (supports legacy field names)
   if (allowedLocationIds !== 'all') {
@@ -192,7 +223,8 @@ function buildMachineStatsMatchStage(
     return {};
   }
   const matchStage: Record<string, unknown> = {
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
+    ...NOT_DELETED_FILTER,
+    ...NOT_RETIRED_FILTER,
   };
 
   if (allowedLocationIds !== 'all') {
@@ -225,7 +257,7 @@ export async function getMachineStatsForAnalytics(
     throw new Error('Database connection failed');
   }
 
-  const onlineThreshold = new Date(Date.now() - 3 * 60 * 1000);
+  const onlineThreshold = getOnlineCutoff();
   const machineMatchStage = buildMachineStatsMatchStage(allowedLocationIds);
 
   // Use aggregation to properly implement ACE logic and get counts in one pass.
@@ -234,14 +266,11 @@ export async function getMachineStatsForAnalytics(
   // unscaled financials would bypass the reviewer scale.
   const countsResult = await Machine.aggregate<MachineStatsCounts>([
     { $match: machineMatchStage },
-    {
-      $lookup: {
-        from: 'gaminglocations',
-        localField: 'gamingLocation',
-        foreignField: '_id',
-        as: 'locationDetails',
-      },
-    },
+    mixedIdLookup({
+      from: 'gaminglocations',
+      localField: 'gamingLocation',
+      as: 'locationDetails',
+    }),
     {
       $unwind: { path: '$locationDetails', preserveNullAndEmptyArrays: true },
     },
@@ -342,30 +371,35 @@ export type DashboardAnalyticsResult = {
   sasMachines: number;
 };
 
+/** Machine counts and summed SAS meter fields from the dashboard pipeline */
+type DashboardTotals = MovementTotals & {
+  totalMachines: number;
+  onlineMachines: number;
+  sasMachines: number;
+};
+
 /**
  * Builds aggregation pipeline for dashboard analytics
  *
  * @param licencee - Licencee ID to filter by
+ * @param onlineThreshold - Machines active since this date are online
  * @returns Aggregation pipeline stages
  */
 function buildDashboardAnalyticsPipeline(
   licencee: string,
-  includeJackpot: boolean = false
+  onlineThreshold: Date = getOnlineCutoff()
 ): PipelineStage[] {
   if (!licencee) {
     console.error('[buildDashboardAnalyticsPipeline] licencee is required');
     return [];
   }
-  const onlineThreshold = new Date(Date.now() - 3 * 60 * 1000);
   return [
-    {
-      $lookup: {
-        from: 'gaminglocations',
-        localField: 'gamingLocation',
-        foreignField: '_id',
-        as: 'locationDetails',
-      },
-    },
+    { $match: { ...NOT_RETIRED_FILTER } },
+    mixedIdLookup({
+      from: 'gaminglocations',
+      localField: 'gamingLocation',
+      as: 'locationDetails',
+    }),
     {
       $unwind: '$locationDetails',
     },
@@ -377,11 +411,7 @@ function buildDashboardAnalyticsPipeline(
     {
       $group: {
         _id: null,
-        totalDrop: { $sum: { $ifNull: ['$sasMeters.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$sasMeters.totalCancelledCredits', 0] },
-        },
-        totalJackpot: { $sum: { $ifNull: ['$sasMeters.jackpot', 0] } },
+        ...buildMovementTotalsGroup('sasMeters'),
         totalMachines: { $sum: 1 },
         onlineMachines: {
           $sum: {
@@ -421,39 +451,40 @@ function buildDashboardAnalyticsPipeline(
       },
     },
     {
-      $project: {
-        _id: 0,
-        totalDrop: 1,
-        totalJackpot: 1,
-        totalMachines: 1,
-        onlineMachines: 1,
-        sasMachines: 1,
-        totalCancelledCredits: {
-          $add: [
-            '$totalCancelledCredits',
-            {
-              $cond: [{ $eq: [includeJackpot, true] }, '$totalJackpot', 0],
-            },
-          ],
-        },
-      },
-    },
-    {
-      $addFields: {
-        totalGross: { $subtract: ['$totalDrop', '$totalCancelledCredits'] },
-      },
+      $project: { _id: 0 },
     },
   ];
 }
 
+/**
+ * Composes the dashboard figures from the summed meters with the licencee's
+ * formula
+ */
+function composeDashboardAnalytics(
+  totals: DashboardTotals | undefined,
+  formula: FinancialFormula
+): DashboardAnalyticsResult {
+  const metrics = calculateFinancialMetrics(totals || {}, formula);
+  return {
+    totalDrop: metrics.moneyIn,
+    totalCancelledCredits: metrics.moneyOut,
+    totalGross: metrics.gross,
+    totalMachines: totals?.totalMachines || 0,
+    onlineMachines: totals?.onlineMachines || 0,
+    sasMachines: totals?.sasMachines || 0,
+  };
+}
+
 /**
  * Fetches dashboard analytics data
  *
  * @param licencee - Licencee ID to filter by
+ * @param strategy - `single` pipeline or per-location `fanout` (see aggregationFanOut)
  * @returns Dashboard analytics result
  */
 export async function getDashboardAnalytics(
-  licencee: string
+  licencee: string,
+  strategy: AggregationStrategy = getAggregationStrategy()
 ): Promise<DashboardAnalyticsResult> {
   if (!licencee) {
     console.error('[getDashboardAnalytics] licencee is required');
@@ -467,25 +498,35 @@ export async function getDashboardAnalytics(
     };
   }
 
-  const licenceeDoc = await Licencee.findOne({ _id: licencee }).lean<Record<
-    string,
-    unknown
-  > | null>();
-  const includeJackpot = !!licenceeDoc?.includeJackpot;
-
-  const pipeline = buildDashboardAnalyticsPipeline(licencee, includeJackpot);
-  const statsResult = await Machine.aggregate(pipeline);
+  const licenceeDoc = await Licencee.findOne({
+    _id: licencee,
+  }).lean<LicenceeDocument | null>();
+  const formula = resolveFinancialFormula(licenceeDoc);
 
-  return (
-    statsResult[0] || {
-      totalDrop: 0,
-      totalCancelledCredits: 0,
-      totalGross: 0,
-      totalMachines: 0,
-      onlineMachines: 0,
-      sasMachines: 0,
-    }
+  const pipeline = buildDashboardAnalyticsPipeline(
+    licencee,
+    getOnlineCutoff(
+      licenceeDoc?.machineStatus as MachineStatusOverride | undefined
+    )
   );
+
+  if (strategy === 'fanout') {
+    const partials = await fanOutByLocation(licencee, async locationId => {
+      const [partial] = await Machine.aggregate<DashboardTotals>([
+        { $match: { gamingLocation: anyIdTypeIn([locationId]) } },
+        ...pipeline,
+      ]);
+      return partial;
+    });
+    return composeDashboardAnalytics(
+      sumPartials(partials.filter(Boolean)) as DashboardTotals,
+      formula
+    );
+  }
+
+  const [statsResult] = await Machine.aggregate<DashboardTotals>(pipeline);
+
+  return composeDashboardAnalytics(statsResult, formula);
 }
 
 /**
@@ -494,13 +535,17 @@ export async function getDashboardAnalytics(
  * @param licenceeId - Licencee ObjectId
  * @param startDate - Start date for filtering
  * @param endDate - End date for filtering
- * @returns MongoDB aggregation pipeline stages
+ * @param bucket - Expression grouping readings into points (default: UTC day)
+ * @returns MongoDB aggregation pipeline stages, one row of summed movement
+ * fields per bucket (composed by `aggregateChartRows`)
  */
-function buildChartsPipeline(
+export function buildChartsPipeline(
   licenceeId: string,
   startDate: Date,
   endDate: Date,
-  includeJackpot: boolean = false
+  bucket: Record<string, unknown> = {
+    $dateToString: { format: '%Y-%m-%d', date: '$readAt' },
+  }
 ): PipelineStage[] {
   if (!licenceeId) {
     console.error('[buildChartsPipeline] licenceeId is required');
@@ -534,15 +579,13 @@ function buildChartsPipeline(
     {
       $unwind: '$machineDetails',
     },
-    // Stage 4: Join with gaming locations to get location details
-    {
-      $lookup: {
-        from: 'gaminglocations',
-        localField: 'machineDetails.gamingLocation',
-        foreignField: '_id',
-        as: 'locationDetails',
-      },
-    },
+    // Stage 4: Join with gaming locations to get location details (runs per
+    // meter record, so the join has to use the _id index)
+    ...anyIdTypeLookup({
+      from: 'gaminglocations',
+      localField: 'machineDetails.gamingLocation',
+      as: 'locationDetails',
+    }),
     // Stage 5: Flatten the location details array
     {
       $unwind: '$locationDetails',
@@ -553,46 +596,44 @@ function buildChartsPipeline(
         'locationDetails.rel.licencee': licenceeId,
       },
     },
-    // Stage 7: Group by date to aggregate daily financial metrics
+    // Stage 7: Group by bucket to sum the movement fields
     {
       $group: {
-        _id: { $dateToString: { format: '%Y-%m-%d', date: '$readAt' } },
-        totalDrop: { $sum: { $ifNull: ['$drop', 0] } },
-        cancelledCredits: {
-          $sum: { $ifNull: ['$totalCancelledCredits', 0] },
-        },
-        totalJackpot: { $sum: { $ifNull: ['$jackpot', 0] } },
+        _id: bucket,
+        ...buildMovementTotalsGroup(),
       },
     },
     // Stage 8: Sort by date for chronological order
     {
       $sort: { _id: 1 },
     },
-    // Stage 9: Project final chart data structure
+    // Stage 9: Key each bucket by its date
     {
-      $project: {
-        _id: 0,
-        date: '$_id',
-        totalDrop: '$totalDrop',
-        totalJackpot: '$totalJackpot',
-        cancelledCredits: {
-          $add: [
-            '$cancelledCredits',
-            {
-              $cond: [{ $eq: [includeJackpot, true] }, '$totalJackpot', 0],
-            },
-          ],
-        },
-      },
+      $addFields: { date: '$_id' },
     },
     {
-      $addFields: {
-        gross: { $subtract: ['$totalDrop', '$cancelledCredits'] },
-      },
+      $project: { _id: 0 },
     },
   ];
 }
 
+/**
+ * Composes a chart row from a bucket's summed movement fields
+ */
+function composeChartRow(
+  row: Record<string, unknown>,
+  formula: FinancialFormula
+): Record<string, unknown> {
+  const metrics = calculateFinancialMetrics(row as MovementTotals, formula);
+  return {
+    date: row.date,
+    totalDrop: metrics.moneyIn,
+    totalJackpot: metrics.jackpot,
+    cancelledCredits: metrics.moneyOut,
+    gross: metrics.gross,
+  };
+}
+
 /**
  * Apply currency conversion to chart series data
  *
@@ -601,7 +642,7 @@ function buildChartsPipeline(
  * @param displayCurrency - Target currency code
  * @returns Converted series data
  */
-function applyChartsCurrencyConversion(
+export function applyChartsCurrencyConversion(
   series: Array<Record<string, unknown>>,
   licencee: string | null,
   displayCurrency: CurrencyCode
@@ -639,18 +680,79 @@ function applyChartsCurrencyConversion(
   });
 }
 
+/**
+ * Runs the charts pipeline for a licencee, one row per bucket in date order.
+ *
+ * @param licencee - Licencee identifier
+ * @param startDate - Range start
+ * @param endDate - Range end
+ * @param strategy - `single` pipeline or per-location `fanout`
+ * @param bucket - Grouping expression (see buildChartsPipeline)
+ * @returns Unconverted rows keyed by `date`
+ */
+export async function aggregateChartRows(
+  licencee: string,
+  startDate: Date,
+  endDate: Date,
+  strategy: AggregationStrategy = getAggregationStrategy(),
+  bucket?: Record<string, unknown>
+): Promise<Array<Record<string, unknown>>> {
+  const licenceeDoc2 = await Licencee.findOne({
+    _id: licencee,
+  }).lean<LicenceeDocument | null>();
+  const formula = resolveFinancialFormula(licenceeDoc2);
+
+  const chartsPipeline = buildChartsPipeline(
+    licencee,
+    startDate,
+    endDate,
+    bucket
+  );
+  // Use cursor for Meters aggregation
+  const series: Array<Record<string, unknown>> = [];
+  if (strategy === 'fanout') {
+    // Same pipeline per location, then merge the rows by date
+    const partials = await fanOutByLocation(licencee, locationId =>
+      Meters.aggregate<Record<string, unknown>>([
+        { $match: { location: anyIdTypeIn([locationId]) } },
+        ...chartsPipeline,
+      ])
+    );
+    const byDate = new Map<string, Array<Record<string, unknown>>>();
+    partials.flat().forEach(row => {
+      const date = String(row.date);
+      byDate.set(date, [...(byDate.get(date) || []), row]);
+    });
+    return Array.from(byDate.keys())
+      .sort()
+      .map(date =>
+        composeChartRow(sumPartials(byDate.get(date) || []), formula)
+      );
+  }
+
+  const seriesCursor = Meters.aggregate(chartsPipeline).cursor({
+    batchSize: 1000,
+  });
+  for await (const doc of seriesCursor) {
+    series.push(composeChartRow(doc as Record<string, unknown>, formula));
+  }
+  return series;
+}
+
 /**
  * Get charts data for analytics
  *
  * @param licencee - Licencee identifier
  * @param period - Time period ('last7days' or 'last30days')
  * @param displayCurrency - Display currency code
+ * @param strategy - `single` pipeline or per-location `fanout` (see aggregationFanOut)
  * @returns Chart series data with currency conversion applied
  */
 export async function getChartsData(
   licencee: string,
   period: 'last7days' | 'last30days',
-  displayCurrency: CurrencyCode
+  displayCurrency: CurrencyCode,
+  strategy: AggregationStrategy = getAggregationStrategy()
 ): Promise<{
   series: Array<Record<string, unknown>>;
   currency: CurrencyCode;
@@ -672,28 +774,13 @@ export async function getChartsData(
   const endDate = new Date();
   const startDate =
     period === 'last7days' ? subDays(endDate, 7) : subDays(endDate, 30);
-  const licenceeId = licencee;
 
-  const licenceeDoc2 = await Licencee.findOne({ _id: licencee }).lean<Record<
-    string,
-    unknown
-  > | null>();
-  const includeJackpot = !!licenceeDoc2?.includeJackpot;
-
-  const chartsPipeline = buildChartsPipeline(
-    licenceeId,
+  const series = await aggregateChartRows(
+    licencee,
     startDate,
     endDate,
-    includeJackpot
+    strategy
   );
-  // Use cursor for Meters aggregation
-  const series: Array<Record<string, unknown>> = [];
-  const seriesCursor = Meters.aggregate(chartsPipeline).cursor({
-    batchSize: 1000,
-  });
-  for await (const doc of seriesCursor) {
-    series.push(doc as Record<string, unknown>);
-  }
 
   const convertedSeries = applyChartsCurrencyConversion(
     series,
@@ -755,14 +842,12 @@ export async function getTopLocationsAnalytics(
   }
 
   const locationsPipeline: PipelineStage[] = [
-    {
-      $lookup: {
-        from: 'gaminglocations',
-        localField: 'gamingLocation',
-        foreignField: '_id',
-        as: 'locationDetails',
-      },
-    },
+    { $match: { ...NOT_RETIRED_FILTER } },
+    mixedIdLookup({
+      from: 'gaminglocations',
+      localField: 'gamingLocation',
+      as: 'locationDetails',
+    }),
     {
       $unwind: '$locationDetails',
     },
@@ -777,7 +862,7 @@ export async function getTopLocationsAnalytics(
         machineCount: { $sum: 1 },
         onlineMachines: {
           $sum: {
-            $cond: [{ $eq: ['$assetStatus', 'active'] }, 1, 0],
+            $cond: [buildActiveAssetStatusExpression(), 1, 0],
           },
         },
         sasMachines: {
@@ -830,12 +915,7 @@ export async function getTopLocationsAnalytics(
   );
 
   // Use cursor for Meters aggregation
-  const allTopMetersAggregation: Array<{
-    _id: string;
-    totalDrop: number;
-    totalCancelledCredits: number;
-    totalJackpot: number;
-  }> = [];
+  const allTopMetersAggregation: Array<MovementTotals & { _id: string }> = [];
   const allTopMetersCursor = Meters.aggregate([
     {
       $match: {
@@ -845,11 +925,7 @@ export async function getTopLocationsAnalytics(
     {
       $group: {
         _id: '$location',
-        totalDrop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
-        totalJackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
+        ...buildMovementTotalsGroup(),
       },
     },
   ]).cursor({ batchSize: 1000 });
@@ -859,69 +935,26 @@ export async function getTopLocationsAnalytics(
   }
 
   // Create map for fast lookup
-  const topFinancialMetricsMap = new Map<
-    string,
-    { totalDrop: number; totalCancelledCredits: number; totalJackpot: number }
-  >();
-  allTopMetersAggregation.forEach(agg => {
-    topFinancialMetricsMap.set(String(agg._id), {
-      totalDrop: (agg.totalDrop as number) || 0,
-      totalCancelledCredits: (agg.totalCancelledCredits as number) || 0,
-      totalJackpot: (agg.totalJackpot as number) || 0,
-    });
-  });
-
-  // Fetch licencee settings (includeJackpot) for all top locations
-  const topLocationLicenceeIds = [
-    ...new Set(
-      topLocations
-        .map(loc =>
-          String(
-            (
-              loc as Record<string, unknown> & {
-                locationInfo?: { rel?: { licencee?: string } };
-              }
-            ).locationInfo?.rel?.licencee
-          )
-        )
-        .filter(id => id && id !== 'undefined')
-    ),
-  ];
-  const licenceesForTopLocationsSettings = await Licencee.find(
-    { _id: { $in: topLocationLicenceeIds } },
-    { _id: 1, includeJackpot: 1 }
-  ).lean<LicenceeDocument[]>();
-  const licenceeSettingsMap = new Map(
-    licenceesForTopLocationsSettings.map(l => [
-      String(l._id),
-      !!(l as { includeJackpot?: boolean }).includeJackpot,
-    ])
+  const topMovementTotalsMap = new Map<string, MovementTotals>(
+    allTopMetersAggregation.map(agg => [String(agg._id), agg])
   );
+  const topLocationFormulas =
+    await getLocationFinancialFormulas(allTopLocationIds);
 
   // Combine location data with financial metrics
   let topLocationsWithMetrics = topLocations.map(location => {
     const locationId = location.id?.toString() || location._id?.toString();
-    const financialMetrics = topFinancialMetricsMap.get(locationId) || {
-      totalDrop: 0,
-      totalCancelledCredits: 0,
-      totalJackpot: 0,
-    };
-
-    const licenceeId = String(location.locationInfo?.rel?.licencee);
-    const includeJackpot = licenceeSettingsMap.get(licenceeId) || false;
-
-    // financialMetrics.totalCancelledCredits is usually the NET payout (Excl. Jackpot)
-    const moneyOut =
-      financialMetrics.totalCancelledCredits +
-      (includeJackpot ? financialMetrics.totalJackpot || 0 : 0);
-
-    const gross = financialMetrics.totalDrop - moneyOut;
+    const financialMetrics = calculateFinancialMetrics(
+      topMovementTotalsMap.get(locationId) || {},
+      topLocationFormulas.get(locationId) || DEFAULT_FINANCIAL_FORMULA
+    );
+    const gross = financialMetrics.gross;
 
     return {
       id: locationId,
       name: location.locationInfo?.name || location.name,
-      totalDrop: financialMetrics.totalDrop,
-      cancelledCredits: moneyOut,
+      totalDrop: financialMetrics.moneyIn,
+      cancelledCredits: financialMetrics.moneyOut,
       gross: gross,
       machineCount: location.machineCount,
       onlineMachines: location.onlineMachines,
@@ -946,12 +979,7 @@ export async function getTopLocationsAnalytics(
       await import('@/lib/helpers/rates');
 
     const licenceesData = await Licencee.find(
-      {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      },
+      { ...NOT_DELETED_FILTER },
       { _id: 1, name: 1 }
     )
       .lean<LicenceeDocument[]>()
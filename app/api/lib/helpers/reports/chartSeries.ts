@@ -0,0 +1,259 @@
+/**
+ * Chart Series Helper
+ *
+ * Serves the analytics charts as a time series at hourly, daily, weekly or
+ * monthly granularity with a consistent schema (`ChartSeries`): one point
+ * per bucket between the range start and end, buckets without meter
+ * readings filled with zeros, so the UI never has to patch gaps.
+ *
+ * Long ranges are downsampled automatically: when the requested (or
+ * default) granularity would produce more than `maxPoints` buckets, the
+ * next coarser one is used and `downsampled` is set. Buckets are UTC; weeks
+ * start on Monday.
+ *
+ * @module app/api/lib/helpers/reports/chartSeries
+ */
+
+import type {
+  AggregationStrategy,
+} from '@/app/api/lib/helpers/aggregationFanOut';
+import {
+  aggregateChartRows,
+  applyChartsCurrencyConversion,
+} from '@/app/api/lib/helpers/reports/analytics';
+import {
+  shouldApplyCurrencyConversion,
+} from '@/lib/helpers/currencyConversion';
+import type {
+  ChartSeries,
+  ChartSeriesGranularity,
+  ChartSeriesPoint,
+} from '@/shared/types/analytics';
+import type { CurrencyCode } from '@/shared/types/currency';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ChartSeriesParams = {
+  licencee: string;
+  startDate: Date;
+  endDate: Date;
+  /** Omit to pick one from the range length */
+  granularity?: ChartSeriesGranularity;
+  /** Default DEFAULT_CHART_MAX_POINTS */
+  maxPoints?: number;
+  displayCurrency: CurrencyCode;
+  strategy?: AggregationStrategy;
+};
+
+/** Finest to coarsest */
+export const CHART_SERIES_GRANULARITIES: ChartSeriesGranularity[] = [
+  'hourly',
+  'daily',
+  'weekly',
+  'monthly',
+];
+
+export const DEFAULT_CHART_MAX_POINTS = 400;
+export const MAX_CHART_MAX_POINTS = 2000;
+
+const HOUR_MS = 60 * 60 * 1000;
+const DAY_MS = 24 * HOUR_MS;
+
+const TRUNCATE_UNITS: Record<ChartSeriesGranularity, string> = {
+  hourly: 'hour',
+  daily: 'day',
+  weekly: 'week',
+  monthly: 'month',
+};
+
+const SERIES_FIELDS = [
+  'totalDrop',
+  'cancelledCredits',
+  'totalJackpot',
+  'gross',
+] as const;
+
+// ============================================================================
+// Buckets
+// ============================================================================
+
+/**
+ * Start of the bucket containing a date.
+ */
+export function truncateToBucket(
+  date: Date,
+  granularity: ChartSeriesGranularity
+): Date {
+  const truncated = new Date(date);
+  truncated.setUTCMinutes(0, 0, 0);
+  if (granularity === 'hourly') return truncated;
+
+  truncated.setUTCHours(0);
+  if (granularity === 'weekly') {
+    // getUTCDay: 0 = Sunday; weeks start on Monday
+    truncated.setUTCDate(
+      truncated.getUTCDate() - ((truncated.getUTCDay() + 6) % 7)
+    );
+  } else if (granularity === 'monthly') {
+    truncated.setUTCDate(1);
+  }
+  return truncated;
+}
+
+function nextBucket(date: Date, granularity: ChartSeriesGranularity): Date {
+  const next = new Date(date);
+  if (granularity === 'hourly') next.setUTCHours(next.getUTCHours() + 1);
+  else if (granularity === 'daily') next.setUTCDate(next.getUTCDate() + 1);
+  else if (granularity === 'weekly') next.setUTCDate(next.getUTCDate() + 7);
+  else next.setUTCMonth(next.getUTCMonth() + 1);
+  return next;
+}
+
+/**
+ * Bucket starts covering the range, oldest first.
+ */
+export function getChartBuckets(
+  startDate: Date,
+  endDate: Date,
+  granularity: ChartSeriesGranularity
+): Date[] {
+  const buckets: Date[] = [];
+  for (
+    let bucket = truncateToBucket(startDate, granularity);
+    bucket <= endDate;
+    bucket = nextBucket(bucket, granularity)
+  ) {
+    buckets.push(bucket);
+  }
+  return buckets;
+}
+
+function countBuckets(
+  startDate: Date,
+  endDate: Date,
+  granularity: ChartSeriesGranularity
+): number {
+  const span = endDate.getTime() - startDate.getTime();
+  if (granularity === 'hourly') return Math.ceil(span / HOUR_MS) + 1;
+  if (granularity === 'daily') return Math.ceil(span / DAY_MS) + 1;
+  if (granularity === 'weekly') return Math.ceil(span / (7 * DAY_MS)) + 1;
+  return Math.ceil(span / (28 * DAY_MS)) + 1;
+}
+
+/**
+ * Granularity to serve: the requested one (hourly up to two days, daily
+ * otherwise), made coarser until the range fits in `maxPoints` buckets.
+ */
+export function resolveSeriesGranularity(
+  startDate: Date,
+  endDate: Date,
+  requested: ChartSeriesGranularity | undefined,
+  maxPoints: number
+): { granularity: ChartSeriesGranularity; downsampled: boolean } {
+  const initial =
+    requested ||
+    (endDate.getTime() - startDate.getTime() <= 2 * DAY_MS
+      ? 'hourly'
+      : 'daily');
+  let index = CHART_SERIES_GRANULARITIES.indexOf(initial);
+  while (
+    index < CHART_SERIES_GRANULARITIES.length - 1 &&
+    countBuckets(startDate, endDate, CHART_SERIES_GRANULARITIES[index]) >
+      maxPoints
+  ) {
+    index++;
+  }
+  const granularity = CHART_SERIES_GRANULARITIES[index];
+  return { granularity, downsampled: granularity !== initial };
+}
+
+/**
+ * Groups readings by bucket start, as an ISO string so rows from a fan-out
+ * merge by key.
+ */
+function bucketExpression(granularity: ChartSeriesGranularity) {
+  return {
+    $dateToString: {
+      format: '%Y-%m-%dT%H:%M:%S.000Z',
+      date: {
+        $dateTrunc: {
+          date: '$readAt',
+          unit: TRUNCATE_UNITS[granularity],
+          ...(granularity === 'weekly' ? { startOfWeek: 'monday' } : {}),
+        },
+      },
+    },
+  };
+}
+
+/**
+ * One point per bucket, zero where the rows have none.
+ */
+export function fillChartGaps(
+  rows: Array<Record<string, unknown>>,
+  buckets: Date[]
+): ChartSeriesPoint[] {
+  const byBucket = new Map(rows.map(row => [String(row.date), row]));
+  return buckets.map(bucket => {
+    const key = bucket.toISOString();
+    const row = byBucket.get(key);
+    const point = { bucket: key } as ChartSeriesPoint;
+    SERIES_FIELDS.forEach(field => {
+      const value = row?.[field];
+      point[field] = typeof value === 'number' ? value : 0;
+    });
+    return point;
+  });
+}
+
+// ============================================================================
+// Series
+// ============================================================================
+
+/**
+ * Builds the chart series for a licencee and range.
+ *
+ * @param params - Licencee, range, granularity and point budget
+ * @returns Gap-filled series in the display currency
+ */
+export async function getChartSeries(
+  params: ChartSeriesParams
+): Promise<ChartSeries> {
+  const { licencee, startDate, endDate, displayCurrency } = params;
+  const maxPoints = Math.min(
+    Math.max(params.maxPoints || DEFAULT_CHART_MAX_POINTS, 1),
+    MAX_CHART_MAX_POINTS
+  );
+  const { granularity, downsampled } = resolveSeriesGranularity(
+    startDate,
+    endDate,
+    params.granularity,
+    maxPoints
+  );
+
+  const rows = await aggregateChartRows(
+    licencee,
+    startDate,
+    endDate,
+    params.strategy,
+    bucketExpression(granularity)
+  );
+  const points = fillChartGaps(
+    applyChartsCurrencyConversion(rows, licencee, displayCurrency),
+    getChartBuckets(startDate, endDate, granularity)
+  );
+
+  return {
+    granularity,
+    requestedGranularity: params.granularity ?? null,
+    downsampled,
+    maxPoints,
+    start: startDate.toISOString(),
+    end: endDate.toISOString(),
+    points,
+    currency: displayCurrency,
+    converted: shouldApplyCurrencyConversion(licencee),
+  };
+}
@@ -0,0 +1,340 @@
+/**
+ * Collection Schedule Compliance Helper
+ *
+ * Compares the collections scheduled in `schedulers` with the collection
+ * reports actually submitted for each location. A schedule is on time when a
+ * report lands inside its window (or up to an hour before it), late when one
+ * lands within `lateHours` after the window closes, and missed otherwise.
+ * Each report satisfies at most one schedule. Schedules whose late window has
+ * not closed yet are pending and left out of the compliance percentage.
+ *
+ * Compliance is summarized per licencee per calendar month (UTC) of the
+ * scheduled start.
+ *
+ * @module app/api/lib/helpers/reports/collectionCompliance
+ */
+
+import { CollectionReport } from '@/app/api/lib/models/collectionReport';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import Scheduler from '@/app/api/lib/models/scheduler';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ScheduleOutcome = 'on-time' | 'late' | 'missed' | 'pending';
+
+export type ScheduleComplianceRow = {
+  schedulerId: string;
+  locationId: string;
+  locationName: string;
+  licenceeId: string;
+  licenceeName: string;
+  collector: string;
+  startTime: Date;
+  endTime: Date;
+  outcome: ScheduleOutcome;
+  /** Matching collection report, if any */
+  collectedAt: Date | null;
+  locationReportId: string | null;
+  /** Hours after the window closed, for late collections */
+  hoursLate: number | null;
+};
+
+export type MonthlyCompliance = {
+  licenceeId: string;
+  licenceeName: string;
+  /** `YYYY-MM` */
+  month: string;
+  scheduled: number;
+  onTime: number;
+  late: number;
+  missed: number;
+  pending: number;
+  /** On-time share of settled schedules; null when none are settled */
+  compliancePercent: number | null;
+};
+
+export type CollectionComplianceReport = {
+  startDate: Date;
+  endDate: Date;
+  lateHours: number;
+  months: MonthlyCompliance[];
+  /** Late and missed schedules, oldest first */
+  exceptions: ScheduleComplianceRow[];
+};
+
+export type CollectionComplianceParams = {
+  allowedLocationIds: 'all' | string[];
+  startDate: Date;
+  endDate: Date;
+  lateHours?: number;
+};
+
+type ComplianceLocation = {
+  _id: string;
+  name?: string;
+  rel?: { licencee?: string };
+};
+
+type ComplianceSchedule = {
+  _id: string;
+  location: string;
+  collector: string;
+  startTime: Date;
+  endTime: Date;
+};
+
+type ComplianceCollection = {
+  location: string;
+  timestamp: Date;
+  locationReportId: string;
+};
+
+export const DEFAULT_LATE_HOURS = 24;
+
+/** Reports this long before a window opens still count as on time */
+const EARLY_GRACE_MS = 60 * 60 * 1000;
+const HOUR_MS = 60 * 60 * 1000;
+
+// ============================================================================
+// Matching
+// ============================================================================
+
+/**
+ * Matches one location's schedules (by start time) to its collection reports
+ * (by timestamp). Each report is used once, by the earliest schedule it fits.
+ */
+function matchSchedules(
+  schedules: ComplianceSchedule[],
+  collections: ComplianceCollection[],
+  lateMs: number,
+  now: Date
+): Array<{
+  schedule: ComplianceSchedule;
+  outcome: ScheduleOutcome;
+  collection: ComplianceCollection | null;
+}> {
+  const used = new Set<number>();
+
+  return schedules.map(schedule => {
+    const windowStart = schedule.startTime.getTime() - EARLY_GRACE_MS;
+    const windowEnd = schedule.endTime.getTime();
+    const lateEnd = windowEnd + lateMs;
+
+    const index = collections.findIndex((collection, candidate) => {
+      if (used.has(candidate)) return false;
+      const at = collection.timestamp.getTime();
+      return at >= windowStart && at <= lateEnd;
+    });
+
+    if (index !== -1) {
+      used.add(index);
+      const collection = collections[index];
+      return {
+        schedule,
+        collection,
+        outcome:
+          collection.timestamp.getTime() <= windowEnd ? 'on-time' : 'late',
+      };
+    }
+
+    return {
+      schedule,
+      collection: null,
+      outcome: lateEnd > now.getTime() ? 'pending' : 'missed',
+    };
+  });
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the compliance report for schedules starting within the range.
+ *
+ * @param params - Location scope, date range and late tolerance
+ * @returns Monthly compliance per licencee and the late/missed schedules
+ */
+export async function getCollectionComplianceReport(
+  params: CollectionComplianceParams
+): Promise<CollectionComplianceReport> {
+  const { allowedLocationIds, startDate, endDate } = params;
+  const lateHours = params.lateHours ?? DEFAULT_LATE_HOURS;
+  const lateMs = lateHours * HOUR_MS;
+  const now = new Date();
+  const empty: CollectionComplianceReport = {
+    startDate,
+    endDate,
+    lateHours,
+    months: [],
+    exceptions: [],
+  };
+
+  // Step 1: Schedules in range (cancelled ones are not expected)
+  const scheduleQuery: Record<string, unknown> = {
+    startTime: { $gte: startDate, $lte: endDate },
+    status: { $ne: 'canceled' },
+    ...NOT_DELETED_FILTER,
+  };
+  if (allowedLocationIds !== 'all') {
+    scheduleQuery.location = { $in: allowedLocationIds };
+  }
+  const schedules = await Scheduler.find(scheduleQuery, {
+    _id: 1,
+    location: 1,
+    collector: 1,
+    startTime: 1,
+    endTime: 1,
+  })
+    .sort({ startTime: 1 })
+    .lean<ComplianceSchedule[]>();
+  if (schedules.length === 0) return empty;
+
+  // Step 2: Locations, licencees and collection reports for those schedules
+  const locationIds = Array.from(
+    new Set(schedules.map(schedule => String(schedule.location)))
+  );
+  const [locations, collections] = await Promise.all([
+    GamingLocations.find(
+      { _id: { $in: locationIds } },
+      { _id: 1, name: 1, 'rel.licencee': 1 }
+    ).lean<ComplianceLocation[]>(),
+    CollectionReport.find(
+      {
+        location: { $in: locationIds },
+        timestamp: {
+          $gte: new Date(startDate.getTime() - EARLY_GRACE_MS),
+          $lte: new Date(
+            Math.max(...schedules.map(s => s.endTime.getTime())) + lateMs
+          ),
+        },
+        ...NOT_DELETED_FILTER,
+      },
+      { location: 1, timestamp: 1, locationReportId: 1 }
+    )
+      .sort({ timestamp: 1 })
+      .lean<ComplianceCollection[]>(),
+  ]);
+
+  const locationsById = new Map(
+    locations.map(location => [String(location._id), location])
+  );
+  const licenceeIds = Array.from(
+    new Set(
+      locations
+        .map(location => location.rel?.licencee)
+        .filter((id): id is string => Boolean(id))
+    )
+  );
+  const licencees =
+    licenceeIds.length === 0
+      ? []
+      : await Licencee.find(
+          { _id: { $in: licenceeIds } },
+          { _id: 1, name: 1 }
+        ).lean<Array<{ _id: string; name?: string }>>();
+  const licenceeNames = new Map(
+    licencees.map(licencee => [String(licencee._id), licencee.name || ''])
+  );
+
+  const schedulesByLocation = new Map<string, ComplianceSchedule[]>();
+  schedules.forEach(schedule => {
+    const locationId = String(schedule.location);
+    const list = schedulesByLocation.get(locationId) || [];
+    list.push(schedule);
+    schedulesByLocation.set(locationId, list);
+  });
+  const collectionsByLocation = new Map<string, ComplianceCollection[]>();
+  collections.forEach(collection => {
+    const locationId = String(collection.location);
+    const list = collectionsByLocation.get(locationId) || [];
+    list.push(collection);
+    collectionsByLocation.set(locationId, list);
+  });
+
+  // Step 3: Match per location and tally per licencee per month
+  const months = new Map<string, MonthlyCompliance>();
+  const exceptions: ScheduleComplianceRow[] = [];
+
+  schedulesByLocation.forEach((locationSchedules, locationId) => {
+    const location = locationsById.get(locationId);
+    const licenceeId = location?.rel?.licencee || '';
+    const licenceeName = licenceeNames.get(licenceeId) || licenceeId;
+
+    matchSchedules(
+      locationSchedules,
+      collectionsByLocation.get(locationId) || [],
+      lateMs,
+      now
+    ).forEach(({ schedule, outcome, collection }) => {
+      const month = schedule.startTime.toISOString().slice(0, 7);
+      const key = `${licenceeId}|${month}`;
+      const summary = months.get(key) || {
+        licenceeId,
+        licenceeName,
+        month,
+        scheduled: 0,
+        onTime: 0,
+        late: 0,
+        missed: 0,
+        pending: 0,
+        compliancePercent: null,
+      };
+      summary.scheduled++;
+      if (outcome === 'on-time') summary.onTime++;
+      else if (outcome === 'late') summary.late++;
+      else if (outcome === 'missed') summary.missed++;
+      else summary.pending++;
+      months.set(key, summary);
+
+      if (outcome === 'late' || outcome === 'missed') {
+        exceptions.push({
+          schedulerId: String(schedule._id),
+          locationId,
+          locationName: location?.name || locationId,
+          licenceeId,
+          licenceeName,
+          collector: schedule.collector,
+          startTime: schedule.startTime,
+          endTime: schedule.endTime,
+          outcome,
+          collectedAt: collection?.timestamp || null,
+          locationReportId: collection?.locationReportId || null,
+          hoursLate: collection
+            ? Math.round(
+                ((collection.timestamp.getTime() -
+                  schedule.endTime.getTime()) /
+                  HOUR_MS) *
+                  10
+              ) / 10
+            : null,
+        });
+      }
+    });
+  });
+
+  // Step 4: Compliance percentages
+  const monthRows = Array.from(months.values())
+    .map(summary => {
+      const settled = summary.scheduled - summary.pending;
+      return {
+        ...summary,
+        compliancePercent:
+          settled > 0
+            ? Math.round((summary.onTime / settled) * 1000) / 10
+            : null,
+      };
+    })
+    .sort(
+      (a, b) =>
+        a.licenceeName.localeCompare(b.licenceeName) ||
+        a.month.localeCompare(b.month)
+    );
+  exceptions.sort((a, b) => a.startTime.getTime() - b.startTime.getTime());
+
+  return { ...empty, months: monthRows, exceptions };
+}
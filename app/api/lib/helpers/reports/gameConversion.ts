@@ -0,0 +1,536 @@
+/**
+ * Game Conversion ROI Report Helper
+ *
+ * Compares a machine's average daily gross, coin in and occupancy over the N
+ * days before and after a game conversion, so management can see whether a
+ * swap paid off. The conversion date defaults to the machine's latest
+ * recorded game change (see machineReconfiguration).
+ *
+ * Both windows are split into 24-hour days counted from the conversion time.
+ * Gross follows the licencee's financial formula; occupancy is the share of
+ * the day the machine spent in member sessions. Days without meter readings
+ * (the cabinet off while it was converted, say) are left out of the averages.
+ *
+ * Each figure gets a significance hint from Welch's t-test on the daily
+ * values: a small p-value means the difference is unlikely to be day-to-day
+ * noise. It is a hint only; seasonality, promotions and neighbouring changes
+ * are not accounted for, and changes inside either window are reported as
+ * warnings.
+ *
+ * @module app/api/lib/helpers/reports/gameConversion
+ */
+
+import { resolveMachineFormula } from '@/app/api/lib/helpers/machineReconfiguration';
+import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+} from '@/app/api/lib/utils/financialFormulas';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import type {
+  MachineReconfigurationEntry,
+  MovementTotals,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DEFAULT_CONVERSION_WINDOW_DAYS = 30;
+
+export const MAX_CONVERSION_WINDOW_DAYS = 180;
+
+/** Days with data needed on each side before a hint is given */
+const MIN_DAYS_PER_SIDE = 7;
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+export type ConversionMetric = 'gross' | 'coinIn' | 'occupancyPercent';
+
+export type ConversionSignificance =
+  | 'strong'
+  | 'likely'
+  | 'weak'
+  | 'none'
+  | 'insufficient-data';
+
+export type GameConversionDay = {
+  start: Date;
+  side: 'before' | 'after';
+  readings: number;
+  gross: number;
+  coinIn: number;
+  occupancyPercent: number;
+};
+
+export type GameConversionWindow = {
+  start: Date;
+  end: Date;
+  days: number;
+  /** Days with at least one meter reading; the averages use these */
+  daysWithData: number;
+};
+
+export type GameConversionFigure = {
+  metric: ConversionMetric;
+  /** Average per day with data */
+  before: number;
+  after: number;
+  /** Percentage change (null when before is 0) */
+  change: number | null;
+  /** Two-sided p-value of Welch's t-test (null without enough data) */
+  pValue: number | null;
+  significance: ConversionSignificance;
+};
+
+export type GameConversionReport = {
+  machineId: string;
+  serialNumber: string;
+  game: string;
+  conversionDate: Date;
+  /** Reconfiguration recorded at the conversion, if any */
+  event: MachineReconfigurationEntry | null;
+  before: GameConversionWindow;
+  after: GameConversionWindow;
+  figures: GameConversionFigure[];
+  daily: GameConversionDay[];
+  warnings: string[];
+};
+
+export type GameConversionParams = {
+  machineId: string;
+  /** Default: the machine's latest recorded game change */
+  conversionDate?: Date;
+  /** Days on each side (default 30) */
+  days?: number;
+};
+
+type ConversionMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  game?: string;
+  gamingLocation?: string;
+  configurationHistory?: MachineReconfigurationEntry[];
+};
+
+const FIGURE_LABELS: Record<ConversionMetric, string> = {
+  gross: 'gross/day',
+  coinIn: 'coin in/day',
+  occupancyPercent: 'occupancy %',
+};
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+// ============================================================================
+// Statistics
+// ============================================================================
+
+/** Natural log of the gamma function (Lanczos approximation) */
+function logGamma(x: number): number {
+  const coefficients = [
+    76.18009172947146, -86.50532032941677, 24.01409824083091,
+    -1.231739572450155, 0.1208650973866179e-2, -0.5395239384953e-5,
+  ];
+  let y = x;
+  const tmp = x + 5.5 - (x + 0.5) * Math.log(x + 5.5);
+  let series = 1.000000000190015;
+  coefficients.forEach(coefficient => {
+    y += 1;
+    series += coefficient / y;
+  });
+  return -tmp + Math.log((2.5066282746310005 * series) / x);
+}
+
+/** Continued fraction of the regularized incomplete beta function */
+function betaContinuedFraction(x: number, a: number, b: number): number {
+  const tiny = 1e-30;
+  let c = 1;
+  let d = 1 - ((a + b) * x) / (a + 1);
+  if (Math.abs(d) < tiny) d = tiny;
+  d = 1 / d;
+  let result = d;
+  for (let m = 1; m <= 200; m++) {
+    const m2 = 2 * m;
+    let term = (m * (b - m) * x) / ((a + m2 - 1) * (a + m2));
+    d = 1 + term * d;
+    c = 1 + term / c;
+    d = 1 / (Math.abs(d) < tiny ? tiny : d);
+    c = Math.abs(c) < tiny ? tiny : c;
+    result *= d * c;
+    term = (-(a + m) * (a + b + m) * x) / ((a + m2) * (a + m2 + 1));
+    d = 1 + term * d;
+    c = 1 + term / c;
+    d = 1 / (Math.abs(d) < tiny ? tiny : d);
+    c = Math.abs(c) < tiny ? tiny : c;
+    const delta = d * c;
+    result *= delta;
+    if (Math.abs(delta - 1) < 1e-10) break;
+  }
+  return result;
+}
+
+/** Regularized incomplete beta function I_x(a, b) */
+function incompleteBeta(x: number, a: number, b: number): number {
+  if (x <= 0) return 0;
+  if (x >= 1) return 1;
+  const front = Math.exp(
+    logGamma(a + b) -
+      logGamma(a) -
+      logGamma(b) +
+      a * Math.log(x) +
+      b * Math.log(1 - x)
+  );
+  return x < (a + 1) / (a + b + 2)
+    ? (front * betaContinuedFraction(x, a, b)) / a
+    : 1 - (front * betaContinuedFraction(1 - x, b, a)) / b;
+}
+
+function meanAndVariance(values: number[]): {
+  mean: number;
+  variance: number;
+} {
+  const mean = values.reduce((sum, value) => sum + value, 0) / values.length;
+  const variance =
+    values.reduce((sum, value) => sum + (value - mean) ** 2, 0) /
+    Math.max(values.length - 1, 1);
+  return { mean, variance };
+}
+
+/**
+ * Two-sided p-value of Welch's t-test between two samples, or null when
+ * either has fewer than MIN_DAYS_PER_SIDE values.
+ */
+export function welchTTestPValue(
+  before: number[],
+  after: number[]
+): number | null {
+  if (before.length < MIN_DAYS_PER_SIDE || after.length < MIN_DAYS_PER_SIDE) {
+    return null;
+  }
+  const a = meanAndVariance(before);
+  const b = meanAndVariance(after);
+  const errorA = a.variance / before.length;
+  const errorB = b.variance / after.length;
+  const standardError = Math.sqrt(errorA + errorB);
+  if (standardError === 0) return a.mean === b.mean ? 1 : 0;
+
+  const t = (b.mean - a.mean) / standardError;
+  const df =
+    (errorA + errorB) ** 2 /
+    (errorA ** 2 / (before.length - 1) + errorB ** 2 / (after.length - 1));
+  return incompleteBeta(df / (df + t * t), df / 2, 0.5);
+}
+
+function significanceOf(pValue: number | null): ConversionSignificance {
+  if (pValue === null) return 'insufficient-data';
+  if (pValue < 0.01) return 'strong';
+  if (pValue < 0.05) return 'likely';
+  if (pValue < 0.1) return 'weak';
+  return 'none';
+}
+
+// ============================================================================
+// Daily figures
+// ============================================================================
+
+/**
+ * Meter movement per day, aggregated with a single `$bucket`.
+ */
+async function aggregateDailyMeters(
+  machine: ConversionMachine,
+  boundaries: Date[]
+): Promise<Array<{ readings: number; gross: number; coinIn: number }>> {
+  const formula = await resolveMachineFormula(machine);
+  const buckets = await Meters.aggregate<
+    { _id: Date | string; readings: number } & MovementTotals
+  >([
+    {
+      $match: {
+        machine: String(machine._id),
+        readAt: {
+          $gte: boundaries[0],
+          $lt: boundaries[boundaries.length - 1],
+        },
+      },
+    },
+    {
+      $bucket: {
+        groupBy: '$readAt',
+        boundaries,
+        default: 'outside',
+        output: { readings: { $sum: 1 }, ...buildMovementTotalsGroup() },
+      },
+    },
+  ]);
+
+  return boundaries.slice(0, -1).map(start => {
+    const bucket = buckets.find(
+      candidate =>
+        candidate._id instanceof Date &&
+        candidate._id.getTime() === start.getTime()
+    );
+    return {
+      readings: bucket?.readings ?? 0,
+      gross: calculateFinancialMetrics(bucket ?? {}, formula).gross,
+      coinIn: Number(bucket?.coinIn) || 0,
+    };
+  });
+}
+
+/**
+ * Hours in session per day, with sessions clipped to each day; open sessions
+ * count up to now.
+ */
+async function aggregateDailySessionHours(
+  machineId: string,
+  boundaries: Date[]
+): Promise<number[]> {
+  const start = boundaries[0];
+  const end = boundaries[boundaries.length - 1];
+  const sessions = await MachineSession.find(
+    {
+      machineId,
+      startTime: { $lt: end },
+      $or: [{ endTime: null }, { endTime: { $gt: start } }],
+    },
+    { startTime: 1, endTime: 1 }
+  ).lean<Array<{ startTime?: Date; endTime?: Date | null }>>();
+
+  const now = Date.now();
+  const hours = new Array<number>(boundaries.length - 1).fill(0);
+  sessions.forEach(session => {
+    if (!session.startTime) return;
+    const sessionStart = new Date(session.startTime).getTime();
+    const sessionEnd = session.endTime
+      ? new Date(session.endTime).getTime()
+      : now;
+    for (let index = 0; index < hours.length; index++) {
+      const overlap =
+        Math.min(sessionEnd, boundaries[index + 1].getTime()) -
+        Math.max(sessionStart, boundaries[index].getTime());
+      if (overlap > 0) hours[index] += overlap / (60 * 60 * 1000);
+    }
+  });
+  return hours;
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the before/after report of one game conversion.
+ *
+ * @param params - Machine, conversion date and window
+ * @throws Error with `statusCode` 404 (unknown machine, or no recorded game
+ * change and no date given) or 400 (invalid window, conversion in the future
+ * or less than a day ago)
+ */
+export async function getGameConversionReport(
+  params: GameConversionParams
+): Promise<GameConversionReport> {
+  const days = Math.floor(params.days ?? DEFAULT_CONVERSION_WINDOW_DAYS);
+  if (!(days >= 1 && days <= MAX_CONVERSION_WINDOW_DAYS)) {
+    throw statusError(
+      `days must be between 1 and ${MAX_CONVERSION_WINDOW_DAYS}`,
+      400
+    );
+  }
+
+  // Step 1: Machine and conversion date
+  const machine = await Machine.findOne(
+    { _id: params.machineId },
+    {
+      serialNumber: 1,
+      origSerialNumber: 1,
+      game: 1,
+      gamingLocation: 1,
+      configurationHistory: 1,
+    }
+  ).lean<ConversionMachine>();
+  if (!machine) {
+    throw statusError(`Machine ${params.machineId} not found`, 404);
+  }
+  const history = [...(machine.configurationHistory || [])].sort(
+    (a, b) => new Date(a.changedAt).getTime() - new Date(b.changedAt).getTime()
+  );
+
+  let conversionDate = params.conversionDate;
+  if (!conversionDate) {
+    const lastConversion = history
+      .filter(entry => entry.changes.some(change => change.field === 'game'))
+      .pop();
+    if (!lastConversion) {
+      throw statusError(
+        `Machine ${params.machineId} has no recorded game change; pass a conversion date`,
+        404
+      );
+    }
+    conversionDate = new Date(lastConversion.changedAt);
+  }
+  if (Number.isNaN(conversionDate.getTime())) {
+    throw statusError('Invalid conversion date', 400);
+  }
+  const conversionTime = conversionDate.getTime();
+  const afterDays = Math.min(
+    days,
+    Math.floor((Date.now() - conversionTime) / DAY_MS)
+  );
+  if (afterDays < 1) {
+    throw statusError(
+      'Less than a full day has passed since the conversion',
+      400
+    );
+  }
+
+  // Step 2: Daily meters and occupancy
+  const boundaries = Array.from(
+    { length: days + afterDays + 1 },
+    (_, index) => new Date(conversionTime + (index - days) * DAY_MS)
+  );
+  const [meters, sessionHours] = await Promise.all([
+    aggregateDailyMeters(machine, boundaries),
+    aggregateDailySessionHours(String(machine._id), boundaries),
+  ]);
+  const daily: GameConversionDay[] = meters.map((day, index) => ({
+    start: boundaries[index],
+    side: index < days ? 'before' : 'after',
+    readings: day.readings,
+    gross: round2(day.gross),
+    coinIn: round2(day.coinIn),
+    occupancyPercent: round2((sessionHours[index] / 24) * 100),
+  }));
+
+  // Step 3: Averages and significance over days with data
+  const withData = daily.filter(day => day.readings > 0);
+  const beforeDays = withData.filter(day => day.side === 'before');
+  const afterDaysWithData = withData.filter(day => day.side === 'after');
+  const figures = (Object.keys(FIGURE_LABELS) as ConversionMetric[]).map(
+    metric => {
+      const beforeValues = beforeDays.map(day => day[metric]);
+      const afterValues = afterDaysWithData.map(day => day[metric]);
+      const before = beforeValues.length
+        ? meanAndVariance(beforeValues).mean
+        : 0;
+      const after = afterValues.length ? meanAndVariance(afterValues).mean : 0;
+      const pValue = welchTTestPValue(beforeValues, afterValues);
+      return {
+        metric,
+        before: round2(before),
+        after: round2(after),
+        change:
+          before === 0
+            ? null
+            : Math.round(((after - before) / Math.abs(before)) * 1000) / 10,
+        pValue: pValue === null ? null : Math.round(pValue * 10000) / 10000,
+        significance: significanceOf(pValue),
+      };
+    }
+  );
+
+  // Step 4: Warnings
+  const windowStart = boundaries[0].getTime();
+  const windowEnd = boundaries[boundaries.length - 1].getTime();
+  const event =
+    history.find(
+      entry =>
+        Math.abs(new Date(entry.changedAt).getTime() - conversionTime) <
+        DAY_MS
+    ) ?? null;
+  const warnings = history
+    .filter(entry => {
+      const changedAt = new Date(entry.changedAt).getTime();
+      return (
+        entry !== event && changedAt > windowStart && changedAt < windowEnd
+      );
+    })
+    .map(
+      entry =>
+        `Another reconfiguration on ${new Date(entry.changedAt).toISOString()} (${entry.changes
+          .map(change => change.field)
+          .join(', ')}) falls inside the comparison`
+    );
+  if (afterDays < days) {
+    warnings.push(
+      `Only ${afterDays} day(s) since the conversion; the after window is shorter`
+    );
+  }
+  const missing = daily.length - withData.length;
+  if (missing > 0) {
+    warnings.push(`${missing} day(s) without meter readings left out`);
+  }
+
+  return {
+    machineId: String(machine._id),
+    serialNumber:
+      machine.serialNumber?.trim() ||
+      machine.origSerialNumber?.trim() ||
+      String(machine._id),
+    game: machine.game || '',
+    conversionDate,
+    event,
+    before: {
+      start: boundaries[0],
+      end: conversionDate,
+      days,
+      daysWithData: beforeDays.length,
+    },
+    after: {
+      start: conversionDate,
+      end: boundaries[boundaries.length - 1],
+      days: afterDays,
+      daysWithData: afterDaysWithData.length,
+    },
+    figures,
+    daily,
+    warnings,
+  };
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+const SIGNIFICANCE_LABELS: Record<ConversionSignificance, string> = {
+  strong: 'significant (p < 0.01)',
+  likely: 'likely real (p < 0.05)',
+  weak: 'weak evidence (p < 0.1)',
+  none: 'could be noise',
+  'insufficient-data': `too few days (< ${MIN_DAYS_PER_SIDE})`,
+};
+
+/**
+ * Text summary of a conversion report for the command.
+ */
+export function formatGameConversionReport(
+  report: GameConversionReport
+): string {
+  const lines = [
+    `Machine ${report.serialNumber}, converted ${report.conversionDate.toISOString()}${
+      report.event
+        ? `: ${report.event.changes
+            .map(
+              change =>
+                `${change.field} ${change.oldValue ?? '(unset)'} -> ${change.newValue}`
+            )
+            .join(', ')}`
+        : ''
+    }`,
+    `Before: ${report.before.days}d (${report.before.daysWithData} with data)  After: ${report.after.days}d (${report.after.daysWithData} with data)`,
+    `${'average'.padEnd(12)} ${'before'.padStart(12)} ${'after'.padStart(12)} ${'change'.padStart(9)}  significance`,
+    ...report.figures.map(figure => {
+      const change =
+        figure.change === null
+          ? 'n/a'
+          : `${figure.change >= 0 ? '+' : ''}${figure.change}%`;
+      return `${FIGURE_LABELS[figure.metric].padEnd(12)} ${figure.before.toFixed(2).padStart(12)} ${figure.after.toFixed(2).padStart(12)} ${change.padStart(9)}  ${SIGNIFICANCE_LABELS[figure.significance]}`;
+    }),
+  ];
+  report.warnings.forEach(warning => lines.push(`! ${warning}`));
+  return lines.join('\n');
+}
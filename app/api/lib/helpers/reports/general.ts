@@ -1,5 +1,28 @@
 import { type ReportConfig, type ReportData } from '@/shared/types/reports';
 import { isWithinInterval } from 'date-fns';
+import { z } from 'zod';
+
+/**
+ * Zod schema for report configuration validation
+ */
+export const reportConfigSchema = z.object({
+  title: z.string(),
+  reportType: z.enum([
+    'locationPerformance',
+    'machineRevenue',
+    'fullFinancials',
+  ]),
+  dateRange: z.object({
+    start: z.string().datetime(),
+    end: z.string().datetime(),
+  }),
+  filters: z.object({
+    locationIds: z.array(z.string()).optional(),
+    manufacturers: z.array(z.string()).optional(),
+  }),
+  fields: z.array(z.string()),
+  chartType: z.enum(['bar', 'line', 'table']),
+});
 
 type Reportable = Record<string, unknown>;
 
@@ -0,0 +1,369 @@
+/**
+ * Idle Machines Report Helper
+ *
+ * Lists machines in active lifecycle status whose meters have not moved for
+ * at least 24 hours, bucketed by how long they have been idle (24h, 72h,
+ * 7d) and grouped by location, with the last meter reading, last movement,
+ * last machine event and last SMIB activity — the field team's dispatch list.
+ *
+ * A reading counts as movement when any of coinIn, drop, gamesPlayed or
+ * totalCancelledCredits is above zero. Movement and events are looked up over
+ * the last `lookbackDays` (default 30); older ones are reported as null.
+ *
+ * @module app/api/lib/helpers/reports/idleMachines
+ */
+
+import {
+  normalizeAssetStatus,
+  NOT_RETIRED_FILTER,
+} from '@/app/api/lib/helpers/machineLifecycle';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { MachineEvent } from '@/app/api/lib/models/machineEvents';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type IdleBucket = '24h' | '72h' | '7d';
+
+/** Idle hours at which a machine enters each bucket, longest first */
+export const IDLE_BUCKET_HOURS: Record<IdleBucket, number> = {
+  '7d': 168,
+  '72h': 72,
+  '24h': 24,
+};
+
+export const DEFAULT_IDLE_LOOKBACK_DAYS = 30;
+
+const MOVEMENT_FIELDS = [
+  'coinIn',
+  'drop',
+  'gamesPlayed',
+  'totalCancelledCredits',
+];
+
+export type IdleMachine = {
+  machineId: string;
+  serialNumber: string;
+  game: string;
+  bucket: IdleBucket;
+  /** Hours since the last movement (or since the lookback start when none) */
+  idleHours: number;
+  lastMovementAt: Date | null;
+  lastReadAt: Date | null;
+  lastEventAt: Date | null;
+  lastActivity: Date | null;
+  locationId: string;
+  locationName: string;
+};
+
+export type IdleLocationGroup = {
+  locationId: string;
+  locationName: string;
+  licenceeId: string;
+  activeMachines: number;
+  idle: Record<IdleBucket, number>;
+  machines: IdleMachine[];
+};
+
+export type IdleMachinesReport = {
+  generatedAt: Date;
+  lookbackDays: number;
+  activeMachines: number;
+  idle: Record<IdleBucket, number>;
+  locations: IdleLocationGroup[];
+};
+
+export type IdleMachinesParams = {
+  allowedLocationIds: 'all' | string[];
+  lookbackDays?: number;
+  /** Only report machines idle at least this long (default '24h') */
+  minBucket?: IdleBucket;
+};
+
+type IdleLocation = {
+  _id: string;
+  name?: string;
+  rel?: { licencee?: string | string[] };
+};
+
+type IdleCandidate = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  game?: string;
+  gamingLocation: string;
+  assetStatus?: string;
+  lastActivity?: Date;
+};
+
+function emptyCounts(): Record<IdleBucket, number> {
+  return { '24h': 0, '72h': 0, '7d': 0 };
+}
+
+/**
+ * Bucket for a number of idle hours, or null when under 24 hours.
+ */
+export function getIdleBucket(idleHours: number): IdleBucket | null {
+  const bucket = (Object.keys(IDLE_BUCKET_HOURS) as IdleBucket[]).find(
+    key => idleHours >= IDLE_BUCKET_HOURS[key]
+  );
+  return bucket ?? null;
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the idle machine report for the locations in scope.
+ *
+ * @param params - Location scope, lookback and minimum bucket
+ * @returns Idle machines per location (most idle first) with bucket counts
+ */
+export async function getIdleMachinesReport(
+  params: IdleMachinesParams
+): Promise<IdleMachinesReport> {
+  const now = new Date();
+  const lookbackDays = Math.min(
+    Math.max(params.lookbackDays ?? DEFAULT_IDLE_LOOKBACK_DAYS, 7),
+    90
+  );
+  const lookbackStart = new Date(now.getTime() - lookbackDays * 86400000);
+  const minHours = IDLE_BUCKET_HOURS[params.minBucket ?? '24h'];
+
+  // Step 1: Locations and active machines in scope
+  const softDeleteFilter = { ...NOT_DELETED_FILTER };
+  const locationQuery: Record<string, unknown> = { ...softDeleteFilter };
+  if (params.allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: params.allowedLocationIds };
+  }
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    'rel.licencee': 1,
+  }).lean<IdleLocation[]>();
+
+  const candidates =
+    locations.length === 0
+      ? []
+      : await Machine.find(
+          {
+            gamingLocation: {
+              $in: locations.map(location => String(location._id)),
+            },
+            ...softDeleteFilter,
+            ...NOT_RETIRED_FILTER,
+          },
+          {
+            _id: 1,
+            serialNumber: 1,
+            origSerialNumber: 1,
+            'custom.name': 1,
+            game: 1,
+            gamingLocation: 1,
+            assetStatus: 1,
+            lastActivity: 1,
+          }
+        ).lean<IdleCandidate[]>();
+  const machines = candidates.filter(
+    machine => normalizeAssetStatus(machine.assetStatus) === 'active'
+  );
+  const machineIds = machines.map(machine => String(machine._id));
+
+  // Step 2: Last reading and last movement per machine in the lookback
+  const readings =
+    machineIds.length === 0
+      ? []
+      : await Meters.aggregate<{
+          _id: string;
+          lastReadAt: Date;
+          lastMovementAt: Date | null;
+        }>([
+          {
+            $match: {
+              machine: { $in: machineIds },
+              readAt: { $gte: lookbackStart, $lte: now },
+            },
+          },
+          {
+            $group: {
+              _id: '$machine',
+              lastReadAt: { $max: '$readAt' },
+              lastMovementAt: {
+                $max: {
+                  $cond: [
+                    {
+                      $or: MOVEMENT_FIELDS.map(field => ({
+                        $gt: [{ $ifNull: [`$movement.${field}`, 0] }, 0],
+                      })),
+                    },
+                    '$readAt',
+                    null,
+                  ],
+                },
+              },
+            },
+          },
+        ]).option({ allowDiskUse: true });
+  const readingsByMachine = new Map(
+    readings.map(reading => [String(reading._id), reading])
+  );
+
+  const idleMachines = machines
+    .map(machine => {
+      const reading = readingsByMachine.get(String(machine._id));
+      const lastMovementAt = reading?.lastMovementAt ?? null;
+      const idleHours =
+        (now.getTime() - (lastMovementAt ?? lookbackStart).getTime()) /
+        3600000;
+      return { machine, reading, lastMovementAt, idleHours };
+    })
+    .filter(entry => entry.idleHours >= minHours);
+
+  // Step 3: Last reading before the lookback for machines without one inside
+  const missingReadIds = idleMachines
+    .filter(entry => !entry.reading)
+    .map(entry => String(entry.machine._id));
+  const olderReadings =
+    missingReadIds.length === 0
+      ? []
+      : await Meters.aggregate<{ _id: string; lastReadAt: Date }>([
+          { $match: { machine: { $in: missingReadIds } } },
+          { $sort: { machine: 1, readAt: 1 } },
+          { $group: { _id: '$machine', lastReadAt: { $last: '$readAt' } } },
+        ]).option({ allowDiskUse: true });
+  const olderReadAt = new Map(
+    olderReadings.map(reading => [String(reading._id), reading.lastReadAt])
+  );
+
+  // Step 4: Last machine event in the lookback
+  const idleIds = idleMachines.map(entry => String(entry.machine._id));
+  const events =
+    idleIds.length === 0
+      ? []
+      : await MachineEvent.aggregate<{ _id: string; lastEventAt: Date }>([
+          {
+            $match: {
+              machine: { $in: idleIds },
+              date: { $gte: lookbackStart },
+            },
+          },
+          { $group: { _id: '$machine', lastEventAt: { $max: '$date' } } },
+        ]);
+  const lastEventAt = new Map(
+    events.map(event => [String(event._id), event.lastEventAt])
+  );
+
+  // Step 5: Group by location, most idle first
+  const locationsById = new Map(
+    locations.map(location => [String(location._id), location])
+  );
+  const groups = new Map<string, IdleLocationGroup>();
+  const totals = emptyCounts();
+
+  machines.forEach(machine => {
+    const locationId = String(machine.gamingLocation);
+    if (!groups.has(locationId)) {
+      const location = locationsById.get(locationId);
+      const licencee = location?.rel?.licencee;
+      groups.set(locationId, {
+        locationId,
+        locationName: location?.name || locationId,
+        licenceeId: (Array.isArray(licencee) ? licencee[0] : licencee) || '',
+        activeMachines: 0,
+        idle: emptyCounts(),
+        machines: [],
+      });
+    }
+    (groups.get(locationId) as IdleLocationGroup).activeMachines++;
+  });
+
+  idleMachines.forEach(({ machine, reading, lastMovementAt, idleHours }) => {
+    const machineId = String(machine._id);
+    const bucket = getIdleBucket(idleHours) as IdleBucket;
+    const group = groups.get(
+      String(machine.gamingLocation)
+    ) as IdleLocationGroup;
+    group.idle[bucket]++;
+    totals[bucket]++;
+    group.machines.push({
+      machineId,
+      serialNumber:
+        machine.serialNumber?.trim() ||
+        machine.origSerialNumber?.trim() ||
+        machine.custom?.name ||
+        machineId,
+      game: machine.game || '',
+      bucket,
+      idleHours: Math.round(idleHours * 10) / 10,
+      lastMovementAt,
+      lastReadAt: reading?.lastReadAt ?? olderReadAt.get(machineId) ?? null,
+      lastEventAt: lastEventAt.get(machineId) ?? null,
+      lastActivity: machine.lastActivity ?? null,
+      locationId: group.locationId,
+      locationName: group.locationName,
+    });
+  });
+
+  const locationGroups = Array.from(groups.values())
+    .filter(group => group.machines.length > 0)
+    .map(group => ({
+      ...group,
+      machines: group.machines.sort((a, b) => b.idleHours - a.idleHours),
+    }))
+    .sort(
+      (a, b) =>
+        b.idle['7d'] - a.idle['7d'] ||
+        b.machines.length - a.machines.length ||
+        a.locationName.localeCompare(b.locationName)
+    );
+
+  return {
+    generatedAt: now,
+    lookbackDays,
+    activeMachines: machines.length,
+    idle: totals,
+    locations: locationGroups,
+  };
+}
+
+/**
+ * Exports the idle machines as CSV for dispatch, one row per machine.
+ */
+export function exportIdleMachinesToCSV(report: IdleMachinesReport): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const date = (value: Date | null) =>
+    value ? new Date(value).toISOString() : '';
+  const header = [
+    'Location',
+    'Serial Number',
+    'Game',
+    'Idle Bucket',
+    'Idle Hours',
+    'Last Movement',
+    'Last Meter Read',
+    'Last Event',
+    'Last Activity',
+  ];
+  const lines = report.locations.flatMap(location =>
+    location.machines.map(machine =>
+      [
+        quote(machine.locationName),
+        quote(machine.serialNumber),
+        quote(machine.game),
+        machine.bucket,
+        machine.idleHours,
+        date(machine.lastMovementAt),
+        date(machine.lastReadAt),
+        date(machine.lastEventAt),
+        date(machine.lastActivity),
+      ].join(',')
+    )
+  );
+  return [header.join(','), ...lines].join('\n');
+}
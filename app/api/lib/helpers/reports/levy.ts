@@ -0,0 +1,302 @@
+/**
+ * Levy Report Helper
+ *
+ * Computes the gaming levy owed per licencee for a calendar month: gross is
+ * aggregated over each location's gaming days in the month (using the
+ * licencee's financial formula, never reviewer scales), then the rate from
+ * the levy schedule (app/api/lib/utils/levySchedule) is applied and rounded
+ * with the schedule's rule. Negative gross owes nothing; a positive month
+ * owes at least the rule's minimum. Licencees without a rate for the month
+ * are returned as unscheduled so they can be followed up before filing.
+ *
+ * Locations deleted during the month still owe for the days they operated.
+ * Gross is read from raw meters rather than the daily rollup, so meters that
+ * arrive after their day was rolled up are never left out of a filing.
+ *
+ * @module app/api/lib/helpers/reports/levy
+ */
+
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { getMovementTotalsFromMeters } from '@/app/api/lib/helpers/metersDaily';
+import { Countries } from '@/app/api/lib/models/countries';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import {
+  loadLevySchedule,
+  resolveLevyRate,
+  roundLevy,
+  type LevyRounding,
+} from '@/app/api/lib/utils/levySchedule';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type LevyRow = {
+  licenceeId: string;
+  licenceeName: string;
+  jurisdiction: string | null;
+  locationCount: number;
+  moneyIn: number;
+  moneyOut: number;
+  gross: number;
+  /** Percent of gross; null when unscheduled */
+  rate: number | null;
+  rateSource: 'licencee' | 'jurisdiction' | null;
+  effectiveFrom: string | null;
+  rounding: LevyRounding | null;
+  decimals: number;
+  levyOwed: number;
+  /** No rate in the schedule for this licencee and month */
+  unscheduled: boolean;
+};
+
+export type LevyReport = {
+  generatedAt: Date;
+  /** YYYY-MM */
+  month: string;
+  /** False when no schedule file is configured */
+  scheduleConfigured: boolean;
+  rows: LevyRow[];
+  totalGross: number;
+  totalLevyOwed: number;
+  unscheduled: number;
+};
+
+export type LevyReportParams = {
+  allowedLocationIds: 'all' | string[];
+  /** YYYY-MM */
+  month: string;
+};
+
+type LevyLocation = {
+  _id: string;
+  gameDayOffset?: number;
+  rel?: { licencee?: string };
+};
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the levy report for a month.
+ *
+ * @param params - Location scope and month
+ * @returns One row per licencee, ordered by levy owed (descending)
+ */
+export async function getLevyReport(
+  params: LevyReportParams
+): Promise<LevyReport> {
+  const { allowedLocationIds, month } = params;
+  const schedule = loadLevySchedule();
+  const [year, monthNumber] = month.split('-').map(Number);
+  const monthStart = new Date(Date.UTC(year, monthNumber - 1, 1));
+  const monthEnd = new Date(Date.UTC(year, monthNumber, 0));
+
+  // Step 1: Locations in scope with their licencee, including those deleted
+  // during the month
+  const locationQuery: Record<string, unknown> = {
+    'rel.licencee': { $exists: true, $nin: [null, ''] },
+    $or: [{ ...NOT_DELETED_FILTER }, { deletedAt: { $gte: monthStart } }],
+  };
+  if (allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: allowedLocationIds };
+  }
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    gameDayOffset: 1,
+    'rel.licencee': 1,
+  }).lean<LevyLocation[]>();
+
+  // Step 2: Month range per location (each has its own gaming day)
+  const ranges = new Map<string, GamingDayRange>();
+  locations.forEach(location => {
+    ranges.set(
+      String(location._id),
+      getGamingDayRangeForPeriod(
+        'Custom',
+        location.gameDayOffset ?? 8,
+        monthStart,
+        monthEnd
+      )
+    );
+  });
+  const licenceeIds = Array.from(
+    new Set(locations.map(location => String(location.rel?.licencee)))
+  );
+
+  // Step 3: Totals, formulas, licencee names and countries
+  const [totals, formulaByLicencee, licenceeDocs] = await Promise.all([
+    getMovementTotalsFromMeters(ranges, 'location'),
+    getLicenceeFinancialFormulas(licenceeIds),
+    Licencee.find(
+      { _id: { $in: licenceeIds } },
+      { _id: 1, name: 1, country: 1, jurisdiction: 1 }
+    ).lean<
+      Array<{
+        _id: string;
+        name: string;
+        country?: string;
+        jurisdiction?: string;
+      }>
+    >(),
+  ]);
+  const countryIds = licenceeDocs
+    .map(licencee => licencee.country)
+    .filter((id): id is string => Boolean(id));
+  const countries = await Countries.find(
+    { _id: { $in: countryIds } },
+    { _id: 1, name: 1 }
+  ).lean<Array<{ _id: string; name: string }>>();
+  const countryNames = new Map(
+    countries.map(country => [String(country._id), country.name])
+  );
+  const licenceeById = new Map(
+    licenceeDocs.map(licencee => [String(licencee._id), licencee])
+  );
+
+  // Step 4: Gross per licencee
+  const grossByLicencee = new Map<
+    string,
+    { locationCount: number; moneyIn: number; moneyOut: number; gross: number }
+  >();
+  locations.forEach(location => {
+    const licenceeId = String(location.rel?.licencee);
+    const entry = grossByLicencee.get(licenceeId) || {
+      locationCount: 0,
+      moneyIn: 0,
+      moneyOut: 0,
+      gross: 0,
+    };
+    entry.locationCount++;
+    const locationTotals = totals.get(String(location._id));
+    if (locationTotals) {
+      const metrics = calculateFinancialMetrics(
+        locationTotals,
+        formulaByLicencee.get(licenceeId) || DEFAULT_FINANCIAL_FORMULA
+      );
+      entry.moneyIn += metrics.moneyIn;
+      entry.moneyOut += metrics.moneyOut;
+      entry.gross += metrics.gross;
+    }
+    grossByLicencee.set(licenceeId, entry);
+  });
+
+  // Step 5: Apply rates and rounding
+  const rows: LevyRow[] = Array.from(grossByLicencee.entries()).map(
+    ([licenceeId, entry]) => {
+      const licencee = licenceeById.get(licenceeId);
+      // A licencee's own jurisdiction takes precedence over its country
+      const country =
+        licencee?.jurisdiction ||
+        (licencee?.country
+          ? countryNames.get(String(licencee.country)) || null
+          : null);
+      const resolved = schedule
+        ? resolveLevyRate(schedule, licenceeId, country, month)
+        : null;
+
+      let levyOwed = 0;
+      if (resolved && entry.gross > 0) {
+        levyOwed = Math.max(
+          roundLevy(
+            (entry.gross * resolved.rate) / 100,
+            resolved.rounding,
+            resolved.decimals
+          ),
+          resolved.minimum
+        );
+      }
+
+      return {
+        licenceeId,
+        licenceeName: licencee?.name || licenceeId,
+        jurisdiction: resolved?.jurisdiction ?? country,
+        ...entry,
+        rate: resolved?.rate ?? null,
+        rateSource: resolved?.source ?? null,
+        effectiveFrom: resolved?.effectiveFrom ?? null,
+        rounding: resolved?.rounding ?? null,
+        decimals: resolved?.decimals ?? 2,
+        levyOwed,
+        unscheduled: !resolved,
+      };
+    }
+  );
+  rows.sort(
+    (a, b) =>
+      b.levyOwed - a.levyOwed || a.licenceeName.localeCompare(b.licenceeName)
+  );
+
+  return {
+    generatedAt: new Date(),
+    month,
+    scheduleConfigured: schedule !== null,
+    rows,
+    totalGross: rows.reduce((total, row) => total + row.gross, 0),
+    totalLevyOwed: rows.reduce((total, row) => total + row.levyOwed, 0),
+    unscheduled: rows.filter(row => row.unscheduled).length,
+  };
+}
+
+// ============================================================================
+// Export
+// ============================================================================
+
+/**
+ * Serializes the report to CSV for filing: one line per licencee with the
+ * rate and rounding applied, then a total line.
+ */
+export function exportLevyToCSV(report: LevyReport): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const header = [
+    'Month',
+    'Licencee',
+    'Licencee ID',
+    'Jurisdiction',
+    'Locations',
+    'Gross',
+    'Rate %',
+    'Rate Source',
+    'Effective From',
+    'Rounding',
+    'Levy Owed',
+  ];
+  const lines = report.rows.map(row =>
+    [
+      report.month,
+      quote(row.licenceeName),
+      row.licenceeId,
+      quote(row.jurisdiction || ''),
+      row.locationCount,
+      row.gross.toFixed(2),
+      row.rate === null ? 'UNSCHEDULED' : row.rate,
+      row.rateSource || '',
+      row.effectiveFrom || '',
+      row.rounding || '',
+      row.levyOwed.toFixed(row.decimals),
+    ].join(',')
+  );
+  const total = [
+    report.month,
+    quote('TOTAL'),
+    '',
+    '',
+    report.rows.reduce((count, row) => count + row.locationCount, 0),
+    report.totalGross.toFixed(2),
+    '',
+    '',
+    '',
+    '',
+    report.totalLevyOwed.toFixed(2),
+  ].join(',');
+  return [header.join(','), ...lines, total].join('\n');
+}
@@ -0,0 +1,318 @@
+/**
+ * Licencee Leaderboard Helper
+ *
+ * Ranks licencees by gross for a period and compares each against the
+ * immediately preceding period of the same length. Also reports machine
+ * utilization (gross per machine per day) and the number of offline machines.
+ *
+ * @module app/api/lib/helpers/reports/licenceeLeaderboard
+ */
+
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+import type { FinancialScales } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type LeaderboardTrend = 'up' | 'down' | 'flat';
+
+export type LicenceeLeaderboardRow = {
+  rank: number;
+  licenceeId: string;
+  licenceeName: string;
+  locationCount: number;
+  machineCount: number;
+  offlineMachines: number;
+  moneyIn: number;
+  moneyOut: number;
+  gross: number;
+  previousGross: number;
+  grossDelta: number;
+  grossDeltaPercent: number | null;
+  trend: LeaderboardTrend;
+  grossPerMachinePerDay: number;
+};
+
+export type LicenceeLeaderboardParams = {
+  allowedLocationIds: 'all' | string[];
+  timePeriod: string;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  scales?: FinancialScales;
+};
+
+type LeaderboardLocation = {
+  _id: string;
+  gameDayOffset?: number;
+  rel?: { licencee?: string };
+};
+
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Range of the same length ending where the given range starts.
+ */
+function getPreviousRange(range: GamingDayRange): GamingDayRange {
+  const length = range.rangeEnd.getTime() - range.rangeStart.getTime();
+  return {
+    rangeStart: new Date(range.rangeStart.getTime() - length),
+    rangeEnd: new Date(range.rangeStart),
+  };
+}
+
+function getTrend(current: number, previous: number): LeaderboardTrend {
+  if (current > previous) return 'up';
+  if (current < previous) return 'down';
+  return 'flat';
+}
+
+// ============================================================================
+// Leaderboard
+// ============================================================================
+
+/**
+ * Builds the licencee leaderboard for the given locations and period.
+ *
+ * @param params - Location scope, period and reviewer scales
+ * @returns Rows ranked by gross (descending)
+ */
+export async function getLicenceeLeaderboard(
+  params: LicenceeLeaderboardParams
+): Promise<LicenceeLeaderboardRow[]> {
+  const {
+    allowedLocationIds,
+    timePeriod,
+    customStartDate,
+    customEndDate,
+    scales,
+  } = params;
+
+  // Step 1: Locations in scope, grouped by licencee
+  const locationQuery: Record<string, unknown> = {
+    'rel.licencee': { $exists: true, $nin: [null, ''] },
+    ...NOT_DELETED_FILTER,
+  };
+  if (allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: allowedLocationIds };
+  }
+
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    gameDayOffset: 1,
+    'rel.licencee': 1,
+  }).lean<LeaderboardLocation[]>();
+  if (locations.length === 0) return [];
+
+  const locationIds = locations.map(location => String(location._id));
+  const licenceeByLocation = new Map(
+    locations.map(location => [
+      String(location._id),
+      String(location.rel?.licencee),
+    ])
+  );
+  const licenceeIds = Array.from(new Set(licenceeByLocation.values()));
+
+  // Step 2: Current and previous ranges per location
+  const currentRanges = new Map<string, GamingDayRange>();
+  const previousRanges = new Map<string, GamingDayRange>();
+  locations.forEach(location => {
+    const range = getGamingDayRangeForPeriod(
+      timePeriod,
+      location.gameDayOffset ?? 8,
+      customStartDate,
+      customEndDate
+    );
+    currentRanges.set(String(location._id), range);
+    previousRanges.set(String(location._id), getPreviousRange(range));
+  });
+
+  // Step 3: Totals, machine stats, formulas and names
+  // Deployment threshold, so licencees are ranked on the same definition
+  const offlineCutoff = getOnlineCutoff();
+  const [
+    currentTotals,
+    previousTotals,
+    machineStats,
+    formulaByLicencee,
+    licenceeDocs,
+  ] = await Promise.all([
+    getMovementTotalsWithRollup(currentRanges, 'location'),
+    getMovementTotalsWithRollup(previousRanges, 'location'),
+    Machine.aggregate<{ _id: string; total: number; offline: number }>([
+      {
+        $match: {
+          gamingLocation: { $in: locationIds },
+          ...NOT_DELETED_FILTER,
+          ...NOT_RETIRED_FILTER,
+        },
+      },
+      {
+        $group: {
+          _id: '$gamingLocation',
+          total: { $sum: 1 },
+          offline: {
+            $sum: {
+              $cond: [
+                {
+                  $or: [
+                    { $eq: [{ $ifNull: ['$lastActivity', null] }, null] },
+                    { $lt: ['$lastActivity', offlineCutoff] },
+                  ],
+                },
+                1,
+                0,
+              ],
+            },
+          },
+        },
+      },
+    ]),
+    getLicenceeFinancialFormulas(licenceeIds),
+    Licencee.find({ _id: { $in: licenceeIds } }, { _id: 1, name: 1 }).lean<
+      Array<{ _id: string; name: string }>
+    >(),
+  ]);
+
+  const nameByLicencee = new Map(
+    licenceeDocs.map(licencee => [String(licencee._id), licencee.name])
+  );
+  const machineStatsByLocation = new Map(
+    machineStats.map(row => [String(row._id), row])
+  );
+
+  // Step 4: Accumulate per licencee
+  const rows = new Map<string, LicenceeLeaderboardRow>();
+  let periodDays = 1;
+  locations.forEach(location => {
+    const locationId = String(location._id);
+    const licenceeId = licenceeByLocation.get(locationId) as string;
+    const formula =
+      formulaByLicencee.get(licenceeId) || DEFAULT_FINANCIAL_FORMULA;
+
+    const range = currentRanges.get(locationId) as GamingDayRange;
+    periodDays = Math.max(
+      periodDays,
+      Math.ceil((range.rangeEnd.getTime() - range.rangeStart.getTime()) / DAY_MS)
+    );
+
+    let row = rows.get(licenceeId);
+    if (!row) {
+      row = {
+        rank: 0,
+        licenceeId,
+        licenceeName: nameByLicencee.get(licenceeId) || licenceeId,
+        locationCount: 0,
+        machineCount: 0,
+        offlineMachines: 0,
+        moneyIn: 0,
+        moneyOut: 0,
+        gross: 0,
+        previousGross: 0,
+        grossDelta: 0,
+        grossDeltaPercent: null,
+        trend: 'flat',
+        grossPerMachinePerDay: 0,
+      };
+      rows.set(licenceeId, row);
+    }
+
+    row.locationCount++;
+    const stats = machineStatsByLocation.get(locationId);
+    row.machineCount += stats?.total || 0;
+    row.offlineMachines += stats?.offline || 0;
+
+    const current = currentTotals.get(locationId);
+    if (current) {
+      const metrics = calculateFinancialMetrics(current, formula, scales);
+      row.moneyIn += metrics.moneyIn;
+      row.moneyOut += metrics.moneyOut;
+      row.gross += metrics.gross;
+    }
+    const previous = previousTotals.get(locationId);
+    if (previous) {
+      row.previousGross += calculateFinancialMetrics(
+        previous,
+        formula,
+        scales
+      ).gross;
+    }
+  });
+
+  // Step 5: Deltas, utilization and ranking
+  return Array.from(rows.values())
+    .map(row => ({
+      ...row,
+      grossDelta: row.gross - row.previousGross,
+      grossDeltaPercent:
+        row.previousGross !== 0
+          ? ((row.gross - row.previousGross) / Math.abs(row.previousGross)) *
+            100
+          : null,
+      trend: getTrend(row.gross, row.previousGross),
+      grossPerMachinePerDay:
+        row.machineCount > 0 ? row.gross / row.machineCount / periodDays : 0,
+    }))
+    .sort((a, b) => b.gross - a.gross)
+    .map((row, index) => ({ ...row, rank: index + 1 }));
+}
+
+// ============================================================================
+// Export
+// ============================================================================
+
+/**
+ * Serializes leaderboard rows to CSV.
+ */
+export function exportLeaderboardToCSV(rows: LicenceeLeaderboardRow[]): string {
+  const header = [
+    'Rank',
+    'Licencee',
+    'Locations',
+    'Machines',
+    'Offline Machines',
+    'Money In',
+    'Money Out',
+    'Gross',
+    'Previous Gross',
+    'Gross Delta',
+    'Gross Delta %',
+    'Trend',
+    'Gross / Machine / Day',
+  ];
+  const lines = rows.map(row =>
+    [
+      row.rank,
+      `"${row.licenceeName.replace(/"/g, '""')}"`,
+      row.locationCount,
+      row.machineCount,
+      row.offlineMachines,
+      row.moneyIn.toFixed(2),
+      row.moneyOut.toFixed(2),
+      row.gross.toFixed(2),
+      row.previousGross.toFixed(2),
+      row.grossDelta.toFixed(2),
+      row.grossDeltaPercent === null ? '' : row.grossDeltaPercent.toFixed(2),
+      row.trend,
+      row.grossPerMachinePerDay.toFixed(2),
+    ].join(',')
+  );
+  return [header.join(','), ...lines].join('\n');
+}
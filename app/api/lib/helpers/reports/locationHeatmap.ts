@@ -0,0 +1,243 @@
+/**
+ * Location Heatmap Helper
+ *
+ * Buckets locations into geohash cells and sums machine counts and financial
+ * metrics per cell for map heatmaps. Locations without valid coordinates are
+ * counted separately and left out of the cells.
+ *
+ * @module app/api/lib/helpers/reports/locationHeatmap
+ */
+
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import {
+  decodeGeohashBounds,
+  encodeGeohash,
+} from '@/app/api/lib/utils/geohash';
+import type { GeohashBounds } from '@/app/api/lib/utils/geohash';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+import type { FinancialScales } from '@shared/types';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type LocationHeatmapCell = {
+  cell: string;
+  center: { latitude: number; longitude: number };
+  bounds: GeohashBounds;
+  locationCount: number;
+  machineCount: number;
+  moneyIn: number;
+  moneyOut: number;
+  gross: number;
+};
+
+export type LocationHeatmapResult = {
+  precision: number;
+  cells: LocationHeatmapCell[];
+  locationsWithoutCoordinates: number;
+};
+
+export type LocationHeatmapParams = {
+  allowedLocationIds: 'all' | string[];
+  precision: number;
+  timePeriod: string;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  scales?: FinancialScales;
+};
+
+type HeatmapLocation = {
+  _id: string;
+  gameDayOffset?: number;
+  geoCoords?: {
+    latitude?: number;
+    longitude?: number;
+    longtitude?: number;
+  };
+  rel?: { licencee?: string };
+};
+
+// ============================================================================
+// Coordinates
+// ============================================================================
+
+/**
+ * Reads a location's coordinates, falling back to the legacy misspelled
+ * `longtitude` field. Returns null for missing or out-of-range values.
+ */
+export function getLocationCoordinates(
+  location: Pick<HeatmapLocation, 'geoCoords'>
+): { latitude: number; longitude: number } | null {
+  const latitude = Number(location.geoCoords?.latitude);
+  const longitude = Number(
+    location.geoCoords?.longitude ?? location.geoCoords?.longtitude
+  );
+
+  if (
+    !Number.isFinite(latitude) ||
+    !Number.isFinite(longitude) ||
+    Math.abs(latitude) > 90 ||
+    Math.abs(longitude) > 180 ||
+    (latitude === 0 && longitude === 0)
+  ) {
+    return null;
+  }
+  return { latitude, longitude };
+}
+
+// ============================================================================
+// Heatmap
+// ============================================================================
+
+/**
+ * Builds geohash-bucketed heatmap cells for the given locations and period.
+ *
+ * @param params - Location scope, geohash precision, period and reviewer scales
+ * @returns Cells sorted by gross (descending)
+ */
+export async function getLocationHeatmap(
+  params: LocationHeatmapParams
+): Promise<LocationHeatmapResult> {
+  const {
+    allowedLocationIds,
+    precision,
+    timePeriod,
+    customStartDate,
+    customEndDate,
+    scales,
+  } = params;
+
+  // Step 1: Fetch locations in scope
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: allowedLocationIds };
+  }
+
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    gameDayOffset: 1,
+    geoCoords: 1,
+    'rel.licencee': 1,
+  }).lean<HeatmapLocation[]>();
+
+  const cellByLocation = new Map<string, string>();
+  let locationsWithoutCoordinates = 0;
+  locations.forEach(location => {
+    const coordinates = getLocationCoordinates(location);
+    if (!coordinates) {
+      locationsWithoutCoordinates++;
+      return;
+    }
+    cellByLocation.set(
+      String(location._id),
+      encodeGeohash(coordinates.latitude, coordinates.longitude, precision)
+    );
+  });
+
+  const mappedLocations = locations.filter(location =>
+    cellByLocation.has(String(location._id))
+  );
+  if (mappedLocations.length === 0) {
+    return { precision, cells: [], locationsWithoutCoordinates };
+  }
+  const locationIds = mappedLocations.map(location => String(location._id));
+
+  // Step 2: Machine counts and movement totals per location
+  const ranges = new Map<string, GamingDayRange>();
+  mappedLocations.forEach(location => {
+    ranges.set(
+      String(location._id),
+      getGamingDayRangeForPeriod(
+        timePeriod,
+        location.gameDayOffset ?? 8,
+        customStartDate,
+        customEndDate
+      )
+    );
+  });
+
+  const licenceeIds = Array.from(
+    new Set(
+      mappedLocations
+        .map(location => location.rel?.licencee)
+        .filter((value): value is string => Boolean(value))
+    )
+  );
+
+  const [machineCounts, totalsByLocation, formulaByLicencee] =
+    await Promise.all([
+      Machine.aggregate<{ _id: string; count: number }>([
+        {
+          $match: {
+            gamingLocation: { $in: locationIds },
+            ...NOT_DELETED_FILTER,
+            ...NOT_RETIRED_FILTER,
+          },
+        },
+        { $group: { _id: '$gamingLocation', count: { $sum: 1 } } },
+      ]),
+      getMovementTotalsWithRollup(ranges, 'location'),
+      getLicenceeFinancialFormulas(licenceeIds),
+    ]);
+
+  const machineCountByLocation = new Map(
+    machineCounts.map(row => [String(row._id), row.count])
+  );
+
+  // Step 3: Bucket into cells
+  const cells = new Map<string, LocationHeatmapCell>();
+  mappedLocations.forEach(location => {
+    const locationId = String(location._id);
+    const cell = cellByLocation.get(locationId) as string;
+
+    let entry = cells.get(cell);
+    if (!entry) {
+      const bounds = decodeGeohashBounds(cell);
+      entry = {
+        cell,
+        center: {
+          latitude: (bounds.minLatitude + bounds.maxLatitude) / 2,
+          longitude: (bounds.minLongitude + bounds.maxLongitude) / 2,
+        },
+        bounds,
+        locationCount: 0,
+        machineCount: 0,
+        moneyIn: 0,
+        moneyOut: 0,
+        gross: 0,
+      };
+      cells.set(cell, entry);
+    }
+
+    entry.locationCount++;
+    entry.machineCount += machineCountByLocation.get(locationId) || 0;
+
+    const totals = totalsByLocation.get(locationId);
+    if (totals) {
+      const formula =
+        formulaByLicencee.get(String(location.rel?.licencee)) ||
+        DEFAULT_FINANCIAL_FORMULA;
+      const metrics = calculateFinancialMetrics(totals, formula, scales);
+      entry.moneyIn += metrics.moneyIn;
+      entry.moneyOut += metrics.moneyOut;
+      entry.gross += metrics.gross;
+    }
+  });
+
+  return {
+    precision,
+    cells: Array.from(cells.values()).sort((a, b) => b.gross - a.gross),
+    locationsWithoutCoordinates,
+  };
+}
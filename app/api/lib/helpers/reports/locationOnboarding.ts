@@ -0,0 +1,228 @@
+/**
+ * Location Onboarding Report Helper
+ *
+ * Door-to-floor check for a newly opened location: every machine on the
+ * location with its first-ever meter reading, whether it has a SMIB
+ * (`relayId`) and whether it is SAS-capable (`isSasMachine` / `sasVersion`),
+ * so the install team can confirm full reporting coverage before sign-off.
+ *
+ * A machine is `reporting` once it has a SMIB and at least one meter reading;
+ * otherwise it is `no-smib` or `no-meters`. SAS capability is reported
+ * alongside but does not block coverage, since non-SAS machines report bill
+ * meters only.
+ *
+ * @module app/api/lib/helpers/reports/locationOnboarding
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type OnboardingStatus = 'reporting' | 'no-meters' | 'no-smib';
+
+export type OnboardingMachine = {
+  machineId: string;
+  serialNumber: string;
+  game: string;
+  smibId: string | null;
+  hasSmib: boolean;
+  sasCapable: boolean;
+  sasVersion: string | null;
+  firstMeterAt: Date | null;
+  lastMeterAt: Date | null;
+  meterCount: number;
+  lastActivity: Date | null;
+  status: OnboardingStatus;
+};
+
+export type LocationOnboardingReport = {
+  locationId: string;
+  locationName: string;
+  generatedAt: Date;
+  machineCount: number;
+  reporting: number;
+  withSmib: number;
+  sasCapable: number;
+  /** Share of machines reporting, 0-100 */
+  coveragePercent: number;
+  /** True when every machine on the location is reporting */
+  fullCoverage: boolean;
+  machines: OnboardingMachine[];
+};
+
+type OnboardingCandidate = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  game?: string;
+  relayId?: string;
+  isSasMachine?: boolean;
+  sasVersion?: string;
+  lastActivity?: Date;
+};
+
+/** Order machines are listed in: problems first */
+const STATUS_ORDER: Record<OnboardingStatus, number> = {
+  'no-smib': 0,
+  'no-meters': 1,
+  reporting: 2,
+};
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the onboarding status of every machine on a location.
+ *
+ * @param locationId - Location to check
+ * @returns Per-machine first meter, SMIB and SAS status with coverage totals
+ * @throws 404 when the location does not exist
+ */
+export async function getLocationOnboardingReport(
+  locationId: string
+): Promise<LocationOnboardingReport> {
+  const softDeleteFilter = { ...NOT_DELETED_FILTER };
+
+  // Step 1: Location and its machines
+  const location = await GamingLocations.findOne(
+    { _id: locationId, ...softDeleteFilter },
+    { _id: 1, name: 1 }
+  ).lean<{ _id: string; name?: string }>();
+  if (!location) throw statusError('Location not found', 404);
+
+  const machines = await Machine.find(
+    { gamingLocation: locationId, ...softDeleteFilter },
+    {
+      _id: 1,
+      serialNumber: 1,
+      origSerialNumber: 1,
+      'custom.name': 1,
+      game: 1,
+      relayId: 1,
+      isSasMachine: 1,
+      sasVersion: 1,
+      lastActivity: 1,
+    }
+  ).lean<OnboardingCandidate[]>();
+  const machineIds = machines.map(machine => String(machine._id));
+
+  // Step 2: First and last meter reading per machine
+  const readings =
+    machineIds.length === 0
+      ? []
+      : await Meters.aggregate<{
+          _id: string;
+          firstMeterAt: Date;
+          lastMeterAt: Date;
+          meterCount: number;
+        }>([
+          { $match: { machine: { $in: machineIds } } },
+          {
+            $group: {
+              _id: '$machine',
+              firstMeterAt: { $min: '$readAt' },
+              lastMeterAt: { $max: '$readAt' },
+              meterCount: { $sum: 1 },
+            },
+          },
+        ]).option({ allowDiskUse: true });
+  const readingsByMachine = new Map(
+    readings.map(reading => [String(reading._id), reading])
+  );
+
+  // Step 3: Per-machine status
+  const rows: OnboardingMachine[] = machines.map(machine => {
+    const machineId = String(machine._id);
+    const reading = readingsByMachine.get(machineId);
+    const smibId = machine.relayId?.trim() || null;
+    const sasVersion = machine.sasVersion?.trim() || null;
+    const status: OnboardingStatus = !smibId
+      ? 'no-smib'
+      : reading
+        ? 'reporting'
+        : 'no-meters';
+    return {
+      machineId,
+      serialNumber:
+        machine.serialNumber?.trim() ||
+        machine.origSerialNumber?.trim() ||
+        machine.custom?.name ||
+        machineId,
+      game: machine.game || '',
+      smibId,
+      hasSmib: Boolean(smibId),
+      sasCapable: Boolean(machine.isSasMachine || sasVersion),
+      sasVersion,
+      firstMeterAt: reading?.firstMeterAt ?? null,
+      lastMeterAt: reading?.lastMeterAt ?? null,
+      meterCount: reading?.meterCount ?? 0,
+      lastActivity: machine.lastActivity ?? null,
+      status,
+    };
+  });
+  rows.sort(
+    (a, b) =>
+      STATUS_ORDER[a.status] - STATUS_ORDER[b.status] ||
+      a.serialNumber.localeCompare(b.serialNumber)
+  );
+
+  // Step 4: Totals
+  const reporting = rows.filter(row => row.status === 'reporting').length;
+  return {
+    locationId: String(location._id),
+    locationName: location.name || String(location._id),
+    generatedAt: new Date(),
+    machineCount: rows.length,
+    reporting,
+    withSmib: rows.filter(row => row.hasSmib).length,
+    sasCapable: rows.filter(row => row.sasCapable).length,
+    coveragePercent:
+      rows.length === 0 ? 0 : Math.round((reporting / rows.length) * 1000) / 10,
+    fullCoverage: rows.length > 0 && reporting === rows.length,
+    machines: rows,
+  };
+}
+
+/**
+ * Exports the onboarding report as CSV, one row per machine.
+ */
+export function exportLocationOnboardingToCSV(
+  report: LocationOnboardingReport
+): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const date = (value: Date | null) =>
+    value ? new Date(value).toISOString() : '';
+  const header = [
+    'Serial Number',
+    'Game',
+    'SMIB ID',
+    'SAS Capable',
+    'SAS Version',
+    'First Meter',
+    'Last Meter',
+    'Meter Readings',
+    'Status',
+  ];
+  const lines = report.machines.map(machine =>
+    [
+      quote(machine.serialNumber),
+      quote(machine.game),
+      quote(machine.smibId || ''),
+      machine.sasCapable ? 'yes' : 'no',
+      quote(machine.sasVersion || ''),
+      date(machine.firstMeterAt),
+      date(machine.lastMeterAt),
+      machine.meterCount,
+      machine.status,
+    ].join(',')
+  );
+  return [header.join(','), ...lines].join('\n');
+}
@@ -11,11 +11,27 @@
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Meters } from '@/app/api/lib/models/meters';
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
+import {
+  addMovementTotals,
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
 import {
   fetchLocationsWithMachinesForSmib,
   syncAllLocationSmibStatuses,
 } from '@/app/api/lib/helpers/smibClassification';
-import type { GamingMachine, LicenceeDocument } from '@shared/types';
+import type {
+  FinancialFormula,
+  GamingMachine,
+  LicenceeDocument,
+  MovementTotals,
+} from '@shared/types';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import type { LocationDocument } from '@/lib/types/common';
 import type { CurrencyCode } from '@/shared/types/currency';
 import type { AggregatedLocation } from '@/shared/types/entities';
@@ -49,17 +65,11 @@ export type ReportLocationsParams = {
   customEndDate: Date | undefined;
 };
 
-type MetersBucket = {
+type MetersBucket = MovementTotals & {
   _id: { location: string; machine: string; hour: Date };
-  totalDrop: number;
-  totalCancelledCredits: number;
-  totalJackpot: number;
 };
 
-type MetricsMap = Map<
-  string,
-  { totalDrop: number; totalCancelledCredits: number; totalJackpot: number }
->;
+type MetricsMap = Map<string, MovementTotals>;
 
 // ============================================================================
 // STEP 1: Parse and validate request parameters
@@ -191,13 +201,8 @@ export function buildLocationMatchStage(params: {
   const { showArchived, allowedLocationIds, specificLocations, licencee, searchTerm, machineTypeFilter, isAdminOrDev, wowLocationIds } = params;
 
   const locationMatchStage: Record<string, unknown> = showArchived
-    ? { deletedAt: { $gte: new Date('2025-01-01') } }
-    : {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      };
+    ? { ...DELETED_FILTER }
+    : { ...NOT_DELETED_FILTER };
 
   if (!showArchived && allowedLocationIds !== 'all') {
     let intersection = allowedLocationIds;
@@ -475,8 +480,8 @@ export async function applySmibClassification(
 // ============================================================================
 
 /**
- * Aggregates meter readings (drop, cancelled credits, jackpot) for each location
- * using a cursor-based aggregation filtered by gaming day ranges per location.
+ * Sums the meter movement fields for each location using a cursor-based
+ * aggregation filtered by gaming day ranges per location.
  */
 export async function computeLocationMetrics(
   allLocationIds: string[],
@@ -506,11 +511,7 @@ export async function computeLocationMetrics(
           location: '$location',
           hour: { $dateTrunc: { date: '$readAt', unit: 'hour' } },
         },
-        totalDrop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
-        totalJackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
+        ...buildMovementTotalsGroup(),
       },
     },
   ])
@@ -537,55 +538,14 @@ export async function computeLocationMetrics(
     }
 
     if (!metricsMap.has(locId)) {
-      metricsMap.set(locId, {
-        totalDrop: 0,
-        totalCancelledCredits: 0,
-        totalJackpot: 0,
-      });
+      metricsMap.set(locId, {});
     }
-    const current = metricsMap.get(locId)!;
-    current.totalDrop += doc.totalDrop || 0;
-    current.totalCancelledCredits += doc.totalCancelledCredits || 0;
-    current.totalJackpot += doc.totalJackpot || 0;
+    addMovementTotals(metricsMap.get(locId)!, doc);
   }
 
   return metricsMap;
 }
 
-// ============================================================================
-// STEP 4: Fetch licencee include-jackpot settings
-// ============================================================================
-
-/**
- * Fetches the includeJackpot setting for all licencees referenced by the locations.
- */
-export async function getLicenceeJackpotSettings(
-  locations: LocationDocument[]
-): Promise<Map<string, boolean>> {
-  const licenceeIds = Array.from(
-    new Set(
-      locations
-        .map(loc => {
-          const licenceeRef = loc.rel?.licencee;
-          return Array.isArray(licenceeRef)
-            ? licenceeRef[0] ? String(licenceeRef[0]) : ''
-            : licenceeRef ? String(licenceeRef) : '';
-        })
-        .filter(Boolean)
-    )
-  );
-
-  if (licenceeIds.length === 0) return new Map();
-
-  const licencees = await Licencee.find({
-    _id: { $in: licenceeIds },
-  }).lean<LicenceeDocument[]>();
-
-  return new Map(
-    licencees.map(l => [String(l._id), !!l.includeJackpot])
-  );
-}
-
 // ============================================================================
 // STEP 4/5: Build aggregated location results
 // ============================================================================
@@ -599,33 +559,21 @@ export function buildLocationResults(
   locationToMachines: Map<string, GamingMachine[]>,
   metricsMap: MetricsMap,
   memberCountMap: Map<string, number>,
-  licenceeIncludeJackpotMap: Map<string, boolean>,
+  locationFormulas: Map<string, FinancialFormula>,
   moneyInScale: number,
   moneyOutScale: number
 ): AggregatedLocation[] {
-  const onlineThreshold = Date.now() - 3 * 60 * 1000;
+  const onlineThreshold = getOnlineCutoff().getTime();
 
   return locations.map(loc => {
     const locId = String(loc._id);
     const machines = locationToMachines.get(locId) || [];
-    const metrics = metricsMap.get(locId) || {
-      totalDrop: 0,
-      totalCancelledCredits: 0,
-      totalJackpot: 0,
-    };
-
-    const scaledDrop = (metrics.totalDrop || 0) * moneyInScale;
-    const scaledCancelled =
-      (metrics.totalCancelledCredits || 0) * moneyOutScale;
-    const scaledJackpot = (metrics.totalJackpot || 0) * moneyOutScale;
-
-    const licenceeRef = loc.rel?.licencee;
-    const licenceeId = Array.isArray(licenceeRef)
-      ? licenceeRef[0] ? String(licenceeRef[0]) : ''
-      : licenceeRef ? String(licenceeRef) : '';
-    const includeJackpot = licenceeId
-      ? licenceeIncludeJackpotMap.get(licenceeId) || false
-      : false;
+    const formula = locationFormulas.get(locId) || DEFAULT_FINANCIAL_FORMULA;
+    const metrics = calculateFinancialMetrics(
+      metricsMap.get(locId) || {},
+      formula,
+      { moneyInScale, moneyOutScale }
+    );
 
     // WOW machines have no relay/activity but always count as online.
     const wowMachineCount = machines.filter(m => isWowMachine(m)).length;
@@ -654,19 +602,11 @@ export function buildLocationResults(
       _id: locId,
       location: locId,
       locationName: loc.name,
-      includeJackpot,
-      moneyIn: Math.round(scaledDrop * 100) / 100,
-      moneyOut:
-        Math.round(
-          (scaledCancelled + (includeJackpot ? scaledJackpot : 0)) * 100
-        ) / 100,
-      gross:
-        Math.round(
-          (scaledDrop -
-            (scaledCancelled + (includeJackpot ? scaledJackpot : 0))) *
-            100
-        ) / 100,
-      jackpot: Math.round(scaledJackpot * 100) / 100,
+      includeJackpot: formula.includeJackpot,
+      moneyIn: Math.round(metrics.moneyIn * 100) / 100,
+      moneyOut: Math.round(metrics.moneyOut * 100) / 100,
+      gross: Math.round(metrics.gross * 100) / 100,
+      jackpot: Math.round(metrics.jackpot * 100) / 100,
       totalMachines: machines.length,
       onlineMachines,
       sasMachines: machines.filter(m => m.isSasMachine).length,
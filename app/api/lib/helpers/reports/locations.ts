@@ -7,10 +7,13 @@
  * @module app/api/lib/helpers/locationsReport
  */
 
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Countries } from '@/app/api/lib/models/countries';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import {
   convertFromUSD,
@@ -62,12 +65,7 @@ export async function applyLocationsCurrencyConversion(
     await connectDB();
 
     // Get licencee details for currency mapping
-    const licenceesData = await Licencee.find({
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    })
+    const licenceesData = await Licencee.find({ ...NOT_DELETED_FILTER })
       .select('_id name')
       .lean<LicenceeDocument[]>();
 
@@ -177,7 +175,8 @@ export async function handleSummaryMode(
     {
       $match: {
         gamingLocation: { $in: allLocationIds },
-        deletedAt: { $in: [null, new Date(-1)] },
+        ...NOT_DELETED_FILTER,
+        ...NOT_RETIRED_FILTER,
       },
     },
     {
@@ -196,7 +195,7 @@ export async function handleSummaryMode(
                   {
                     $gt: [
                       '$lastActivity',
-                      new Date(Date.now() - 3 * 60 * 1000),
+                      getOnlineCutoff(),
                     ],
                   },
                   { $eq: ['$meta.dataSync.source', 'wow'] },
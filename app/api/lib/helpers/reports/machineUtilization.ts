@@ -0,0 +1,270 @@
+/**
+ * Machine Utilization Helper
+ *
+ * Computes per-machine occupancy from `machinesessions`: hours in session per
+ * day, average session length and sessions per day for a period. Sessions are
+ * clipped to each location's gaming-day range; open sessions count up to now.
+ * Machines whose occupancy falls well below their location's average are
+ * flagged as underutilized. Sessions are aggregated once per location through
+ * the shared fan-out pool (see aggregationFanOut).
+ *
+ * @module app/api/lib/helpers/reports/machineUtilization
+ */
+
+import { runPooled } from '@/app/api/lib/helpers/aggregationFanOut';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MachineUtilizationRow = {
+  machineId: string;
+  serialNumber: string;
+  game: string;
+  sessions: number;
+  sessionHours: number;
+  occupancyHoursPerDay: number;
+  occupancyPercent: number;
+  averageSessionMinutes: number;
+  sessionsPerDay: number;
+  underutilized: boolean;
+};
+
+export type LocationUtilization = {
+  locationId: string;
+  locationName: string;
+  rangeStart: Date;
+  rangeEnd: Date;
+  days: number;
+  machineCount: number;
+  averageOccupancyHoursPerDay: number;
+  underutilizedCount: number;
+  machines: MachineUtilizationRow[];
+};
+
+export type MachineUtilizationParams = {
+  allowedLocationIds: 'all' | string[];
+  timePeriod: string;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  underutilizedRatio?: number;
+};
+
+type UtilizationLocation = {
+  _id: string;
+  name?: string;
+  gameDayOffset?: number;
+};
+
+type UtilizationMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  game?: string;
+  gamingLocation: string;
+};
+
+type SessionAggregate = {
+  _id: string;
+  sessions: number;
+  durationMs: number;
+};
+
+export const DEFAULT_UNDERUTILIZED_RATIO = 0.5;
+
+const HOUR_MS = 60 * 60 * 1000;
+const DAY_MS = 24 * HOUR_MS;
+
+// ============================================================================
+// Session Aggregation
+// ============================================================================
+
+/**
+ * Sums session count and in-range duration per machine for one location.
+ */
+async function aggregateSessions(
+  machineIds: string[],
+  rangeStart: Date,
+  rangeEnd: Date,
+  now: Date
+): Promise<Map<string, SessionAggregate>> {
+  if (machineIds.length === 0) return new Map();
+
+  const results = await MachineSession.aggregate<SessionAggregate>([
+    {
+      $match: {
+        machineId: { $in: machineIds },
+        startTime: { $lt: rangeEnd },
+        $or: [{ endTime: null }, { endTime: { $gt: rangeStart } }],
+      },
+    },
+    {
+      $project: {
+        machineId: 1,
+        clippedStart: { $max: ['$startTime', rangeStart] },
+        clippedEnd: {
+          $min: [{ $ifNull: ['$endTime', now] }, rangeEnd],
+        },
+      },
+    },
+    {
+      $group: {
+        _id: '$machineId',
+        sessions: { $sum: 1 },
+        durationMs: {
+          $sum: {
+            $max: [{ $subtract: ['$clippedEnd', '$clippedStart'] }, 0],
+          },
+        },
+      },
+    },
+  ]);
+
+  return new Map(results.map(row => [String(row._id), row]));
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the utilization report per location.
+ *
+ * @param params - Location scope, period and underutilization ratio
+ * @returns Locations with per-machine utilization, least-used machines first
+ */
+export async function getMachineUtilizationReport(
+  params: MachineUtilizationParams
+): Promise<LocationUtilization[]> {
+  const {
+    allowedLocationIds,
+    timePeriod,
+    customStartDate,
+    customEndDate,
+    underutilizedRatio = DEFAULT_UNDERUTILIZED_RATIO,
+  } = params;
+
+  // Step 1: Locations and machines in scope
+  const softDeleteFilter = { ...NOT_DELETED_FILTER };
+  const locationQuery: Record<string, unknown> = { ...softDeleteFilter };
+  if (allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: allowedLocationIds };
+  }
+
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    gameDayOffset: 1,
+  }).lean<UtilizationLocation[]>();
+  if (locations.length === 0) return [];
+
+  const machines = await Machine.find(
+    {
+      gamingLocation: { $in: locations.map(location => String(location._id)) },
+      ...softDeleteFilter,
+      ...NOT_RETIRED_FILTER,
+    },
+    {
+      _id: 1,
+      serialNumber: 1,
+      origSerialNumber: 1,
+      'custom.name': 1,
+      game: 1,
+      gamingLocation: 1,
+    }
+  ).lean<UtilizationMachine[]>();
+
+  const machinesByLocation = new Map<string, UtilizationMachine[]>();
+  machines.forEach(machine => {
+    const locationId = String(machine.gamingLocation);
+    const list = machinesByLocation.get(locationId) || [];
+    list.push(machine);
+    machinesByLocation.set(locationId, list);
+  });
+
+  // Step 2: Per-location session aggregation
+  const now = new Date();
+  const reports = await runPooled(locations, async location => {
+    const locationId = String(location._id);
+    const locationMachines = machinesByLocation.get(locationId) || [];
+    const { rangeStart, rangeEnd } = getGamingDayRangeForPeriod(
+      timePeriod,
+      location.gameDayOffset ?? 8,
+      customStartDate,
+      customEndDate
+    );
+    const effectiveEnd = rangeEnd > now ? now : rangeEnd;
+    const days = Math.max(
+      (effectiveEnd.getTime() - rangeStart.getTime()) / DAY_MS,
+      1 / 24
+    );
+
+    const sessionsByMachine = await aggregateSessions(
+      locationMachines.map(machine => String(machine._id)),
+      rangeStart,
+      rangeEnd,
+      now
+    );
+
+    const rows: MachineUtilizationRow[] = locationMachines.map(machine => {
+      const aggregate = sessionsByMachine.get(String(machine._id));
+      const sessions = aggregate?.sessions || 0;
+      const sessionHours = (aggregate?.durationMs || 0) / HOUR_MS;
+      const occupancyHoursPerDay = sessionHours / days;
+      return {
+        machineId: String(machine._id),
+        serialNumber:
+          machine.serialNumber?.trim() ||
+          machine.origSerialNumber?.trim() ||
+          machine.custom?.name ||
+          String(machine._id),
+        game: machine.game || '',
+        sessions,
+        sessionHours,
+        occupancyHoursPerDay,
+        occupancyPercent: (occupancyHoursPerDay / 24) * 100,
+        averageSessionMinutes:
+          sessions > 0 ? (sessionHours * 60) / sessions : 0,
+        sessionsPerDay: sessions / days,
+        underutilized: false,
+      };
+    });
+
+    // Step 3: Flag machines below the location average
+    const averageOccupancyHoursPerDay =
+      rows.length > 0
+        ? rows.reduce((sum, row) => sum + row.occupancyHoursPerDay, 0) /
+          rows.length
+        : 0;
+    rows.forEach(row => {
+      row.underutilized =
+        averageOccupancyHoursPerDay > 0 &&
+        row.occupancyHoursPerDay <
+          averageOccupancyHoursPerDay * underutilizedRatio;
+    });
+    rows.sort((a, b) => a.occupancyHoursPerDay - b.occupancyHoursPerDay);
+
+    return {
+      locationId,
+      locationName: location.name || locationId,
+      rangeStart,
+      rangeEnd,
+      days,
+      machineCount: rows.length,
+      averageOccupancyHoursPerDay,
+      underutilizedCount: rows.filter(row => row.underutilized).length,
+      machines: rows,
+    };
+  });
+
+  return reports
+    .filter(report => report.machineCount > 0)
+    .sort((a, b) => a.locationName.localeCompare(b.locationName));
+}
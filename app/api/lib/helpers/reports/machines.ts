@@ -7,10 +7,21 @@
  * @module app/api/lib/helpers/cabinetsReport
  */
 
+import { getLicenceeMachineStatus } from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { Countries } from '@/app/api/lib/models/countries';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+  resolveFinancialFormula,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import {
   convertFromUSD,
@@ -20,8 +31,10 @@ import {
 import { getGamingDayRangesForLocations } from '@/lib/utils/gamingDayRange';
 import type {
   CountryDocument,
+  FinancialFormula,
   GamingLocationDocument,
   LicenceeDocument,
+  MovementTotals,
 } from '@/shared/types';
 import type { CurrencyCode } from '@/shared/types/currency';
 import { isWowMachine } from '@/shared/utils/wowMachine';
@@ -58,19 +71,34 @@ function getLicenceeFilter(locationMatchStage: Record<string, unknown>): string
 }
 
 /**
- * Build a map of licenceeId -> includeJackpot boolean
+ * Build a map of licenceeId -> effective financial formula
  */
-async function buildLicenceeJackpotMap(): Promise<Map<string, boolean>> {
-  const licencees = await Licencee.find({}, { _id: 1, includeJackpot: 1 }).lean<
-    LicenceeDocument[]
-  >();
-  const map = new Map<string, boolean>();
+async function buildLicenceeFormulaMap(): Promise<
+  Map<string, FinancialFormula>
+> {
+  const licencees = await Licencee.find(
+    {},
+    { _id: 1, includeJackpot: 1, financialFormula: 1 }
+  ).lean<LicenceeDocument[]>();
+  const map = new Map<string, FinancialFormula>();
   licencees.forEach(licenceeItem => {
-    map.set(String(licenceeItem._id), Boolean(licenceeItem.includeJackpot));
+    map.set(String(licenceeItem._id), resolveFinancialFormula(licenceeItem));
   });
   return map;
 }
 
+/**
+ * `$project` fields reading the summed movement of a `meterData` lookup
+ */
+function projectMeterTotals(): Record<string, unknown> {
+  return Object.fromEntries(
+    METER_MOVEMENT_FIELDS.map(field => [
+      field,
+      { $ifNull: [`$meterData.${field}`, 0] },
+    ])
+  );
+}
+
 /**
  * Fetches machine stats (online/offline counts and financial totals)
  */
@@ -103,7 +131,9 @@ export async function getMachineStats(
     throw new Error('[getMachineStats] timePeriod is required');
   }
 
-  const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+  const onlineCutoff = getOnlineCutoff(
+    await getLicenceeMachineStatus(licencee)
+  );
 
   // Build base aggregation pipeline that respects the location/machine filter
   const aggregationPipeline: PipelineStage[] = [
@@ -149,7 +179,7 @@ export async function getMachineStats(
           {
             relayId: { $exists: true, $nin: [null, ''] },
             $or: [
-              { lastActivity: { $exists: true, $gte: threeMinutesAgo } },
+              { lastActivity: { $exists: true, $gte: onlineCutoff } },
               { 'locationDetails.aceEnabled': true },
             ],
           },
@@ -259,16 +289,7 @@ export async function getMachineStats(
           },
           // Sum up the movement data
           {
-            $group: {
-              _id: null,
-              drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-              moneyOut: {
-                $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-              },
-              coinIn: { $sum: { $ifNull: ['$movement.coinIn', 0] } },
-              coinOut: { $sum: { $ifNull: ['$movement.coinOut', 0] } },
-              jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
-            },
+            $group: { _id: null, ...buildMovementTotalsGroup() },
           },
         ],
         as: 'meterData',
@@ -282,9 +303,7 @@ export async function getMachineStats(
     },
     {
       $project: {
-        drop: { $ifNull: ['$meterData.drop', 0] },
-        moneyOut: { $ifNull: ['$meterData.moneyOut', 0] },
-        jackpot: { $ifNull: ['$meterData.jackpot', 0] },
+        ...projectMeterTotals(),
         licenceeId: { $ifNull: ['$locationDetails.rel.licencee', null] },
         collectorDenomination: 1,
         'gameConfig.accountingDenomination': 1,
@@ -292,10 +311,14 @@ export async function getMachineStats(
     },
   ];
 
-  // Build licencee jackpot settings map
-  const licenceeJackpotMap = await buildLicenceeJackpotMap();
+  // Build licencee formula map
+  const licenceeFormulaMap = await buildLicenceeFormulaMap();
+  const scales = {
+    moneyInScale: moneyInMult !== null ? 1 - moneyInMult : 1,
+    moneyOutScale: moneyOutMult !== null ? 1 - moneyOutMult : 1,
+  };
 
-  // Use cursor to compute totals with per-machine jackpot adjustment
+  // Use cursor to compute totals with each machine's licencee formula
   let totalDrop = 0;
   let totalMoneyOut = 0;
   let totalGross = 0;
@@ -304,20 +327,15 @@ export async function getMachineStats(
     batchSize: 1000,
   });
   for await (const doc of financialCursor) {
-    const drop = doc.drop || 0;
-    const moneyOut = doc.moneyOut || 0;
-    const jackpotVal = doc.jackpot || 0;
     const licId = doc.licenceeId ? String(doc.licenceeId) : '';
-    const includesJackpot = licenceeJackpotMap.get(licId) || false;
-    const adjustedMoneyOut = includesJackpot ? moneyOut + jackpotVal : moneyOut;
-
-    const moneyInScale = moneyInMult !== null ? 1 - moneyInMult : 1;
-    const moneyOutScale = moneyOutMult !== null ? 1 - moneyOutMult : 1;
-    const scaledDrop = drop * moneyInScale;
-    const scaledMoneyOut = adjustedMoneyOut * moneyOutScale;
-    totalDrop += scaledDrop;
-    totalMoneyOut += scaledMoneyOut;
-    totalGross += scaledDrop - scaledMoneyOut;
+    const metrics = calculateFinancialMetrics(
+      doc,
+      licenceeFormulaMap.get(licId) || DEFAULT_FINANCIAL_FORMULA,
+      scales
+    );
+    totalDrop += metrics.moneyIn;
+    totalMoneyOut += metrics.moneyOut;
+    totalGross += metrics.gross;
   }
 
   const totals = {
@@ -416,13 +434,7 @@ export async function getMachineStats(
               },
             },
             {
-              $group: {
-                _id: null,
-                drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-                moneyOut: {
-                  $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-                },
-              },
+              $group: { _id: null, ...buildMovementTotalsGroup() },
             },
           ],
           as: 'meterData',
@@ -436,14 +448,7 @@ export async function getMachineStats(
       },
       {
         $project: {
-          drop: { $ifNull: ['$meterData.drop', 0] },
-          moneyOut: { $ifNull: ['$meterData.moneyOut', 0] },
-          gross: {
-            $subtract: [
-              { $ifNull: ['$meterData.drop', 0] },
-              { $ifNull: ['$meterData.moneyOut', 0] },
-            ],
-          },
+          ...projectMeterTotals(),
           licenceeId: {
             $ifNull: [
               '$locationDetails.rel.licencee',
@@ -474,18 +479,21 @@ export async function getMachineStats(
         nativeCurrency = countryName ? getCountryCurrency(countryName) : 'USD';
       }
 
-      const moneyInScale = moneyInMult !== null ? 1 - moneyInMult : 1;
-      const moneyOutScale = moneyOutMult !== null ? 1 - moneyOutMult : 1;
-
       const r = (v: number) => Math.round(v * 100) / 100;
       const toDisplay = (val: number) =>
         nativeCurrency !== displayCurrency
           ? r(convertFromUSD(convertToUSD(val, nativeCurrency), displayCurrency))
           : r(val);
 
-      totalDropUSD += toDisplay(machine.drop || 0) * moneyInScale;
-      totalMoneyOutUSD += toDisplay(machine.moneyOut || 0) * moneyOutScale;
-      totalGrossUSD += toDisplay(machine.drop || 0) * moneyInScale - toDisplay(machine.moneyOut || 0) * moneyOutScale;
+      const metrics = calculateFinancialMetrics(
+        machine,
+        licenceeFormulaMap.get(machineLicenceeId || '') ||
+          DEFAULT_FINANCIAL_FORMULA,
+        scales
+      );
+      totalDropUSD += toDisplay(metrics.moneyIn);
+      totalMoneyOutUSD += toDisplay(metrics.moneyOut);
+      totalGrossUSD += toDisplay(metrics.moneyIn) - toDisplay(metrics.moneyOut);
     }
 
     convertedTotals = {
@@ -636,14 +644,8 @@ export async function getOverviewMachines(
           {
             $group: {
               _id: null,
-              drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-              moneyOut: {
-                $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-              },
-              coinIn: { $sum: { $ifNull: ['$movement.coinIn', 0] } },
-              coinOut: { $sum: { $ifNull: ['$movement.coinOut', 0] } },
+              ...buildMovementTotalsGroup(),
               gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
-              jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
             },
           },
         ],
@@ -675,12 +677,9 @@ export async function getOverviewMachines(
         locationName: '$locationDetails.name',
         aceEnabled: '$locationDetails.aceEnabled',
         licenceeId: { $ifNull: ['$locationDetails.rel.licencee', null] },
-        // Denomination scaling fields
+        // Summed movement, composed with the licencee formula below
         rawDrop: { $ifNull: ['$meterData.drop', 0] },
-        rawMoneyOut: { $ifNull: ['$meterData.moneyOut', 0] },
-        rawCoinIn: { $ifNull: ['$meterData.coinIn', 0] },
-        rawCoinOut: { $ifNull: ['$meterData.coinOut', 0] },
-        rawJackpot: { $ifNull: ['$meterData.jackpot', 0] },
+        meterTotals: projectMeterTotals(),
         gamesPlayed: { $ifNull: ['$meterData.gamesPlayed', 0] },
       },
     },
@@ -701,10 +700,12 @@ export async function getOverviewMachines(
     machines.push(doc);
   }
 
-  const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+  const onlineCutoff = getOnlineCutoff(
+    await getLicenceeMachineStatus(getLicenceeFilter(locationMatchStage))
+  );
 
   // Build licencee jackpot settings map for adjusting moneyOut
-  const licenceeJackpotMap = await buildLicenceeJackpotMap();
+  const licenceeFormulaMap = await buildLicenceeFormulaMap();
 
   const transformedMachines = machines.map(machine => {
     const gameConfig = machine.gameConfig as
@@ -723,7 +724,7 @@ export async function getOverviewMachines(
       (hasRelay &&
         !!(
           machine.aceEnabled ||
-          (lastActivity && lastActivity > threeMinutesAgo)
+          (lastActivity && lastActivity > onlineCutoff)
         ));
 
     let offlineTimeLabel: string | undefined = undefined;
@@ -740,28 +741,27 @@ export async function getOverviewMachines(
       offlineTimeLabel = 'Never';
     }
 
-    // Adjust moneyOut with jackpot based on licencee setting
+    // Compose Money In/Out and Gross with the licencee formula
     const licId = machine.licenceeId ? String(machine.licenceeId) : '';
-    const includesJackpot = licenceeJackpotMap.get(licId) || false;
+    const formula = licenceeFormulaMap.get(licId) || DEFAULT_FINANCIAL_FORMULA;
+    const includesJackpot = formula.includeJackpot;
 
     const moneyInScale = moneyInMult !== null ? 1 - moneyInMult : 1;
     const moneyOutScale = moneyOutMult !== null ? 1 - moneyOutMult : 1;
-    const jackpotVal =
-      Math.round((Number(machine.rawJackpot) || 0) * moneyOutScale * 100) / 100;
-    const rawMoneyOut =
-      Math.round((Number(machine.rawMoneyOut) || 0) * moneyOutScale * 100) /
-      100;
-    const adjustedMoneyOut = includesJackpot
-      ? rawMoneyOut + jackpotVal
-      : rawMoneyOut;
-    const dropVal =
-      Math.round((Number(machine.rawDrop) || 0) * moneyInScale * 100) / 100;
-    const adjustedGross = Math.round((dropVal - adjustedMoneyOut) * 100) / 100;
+    const totals = (machine.meterTotals || {}) as MovementTotals;
+    const metrics = calculateFinancialMetrics(totals, formula, {
+      moneyInScale,
+      moneyOutScale,
+    });
+    const jackpotVal = Math.round(metrics.jackpot * 100) / 100;
+    const adjustedMoneyOut = Math.round(metrics.moneyOut * 100) / 100;
+    const dropVal = Math.round(metrics.moneyIn * 100) / 100;
+    const adjustedGross = Math.round(metrics.gross * 100) / 100;
 
     const coinInVal =
-      Math.round((Number(machine.rawCoinIn) || 0) * moneyInScale * 100) / 100;
+      Math.round((Number(totals.coinIn) || 0) * moneyInScale * 100) / 100;
     const coinOutVal =
-      Math.round((Number(machine.rawCoinOut) || 0) * moneyOutScale * 100) / 100;
+      Math.round((Number(totals.coinOut) || 0) * moneyOutScale * 100) / 100;
     const netWinVal = Math.round((coinInVal - coinOutVal) * 100) / 100;
 
     const holdPct = coinInVal > 0 ? Math.round((adjustedGross / coinInVal) * 100 * 100) / 100 : 0;
@@ -872,9 +872,12 @@ export async function getAllMachines(
   }
 
   const searchTerm = searchParams.get('search');
-  const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+  const onlineCutoff = getOnlineCutoff(
+    await getLicenceeMachineStatus(getLicenceeFilter(locationMatchStage))
+  );
   const machineMatchStage: Record<string, unknown> = {
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
+    ...NOT_DELETED_FILTER,
+    ...NOT_RETIRED_FILTER,
   };
 
   if (searchTerm && searchTerm.trim()) {
@@ -980,14 +983,8 @@ export async function getAllMachines(
           {
             $group: {
               _id: null,
-              drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-              moneyOut: {
-                $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-              },
-              coinIn: { $sum: { $ifNull: ['$movement.coinIn', 0] } },
-              coinOut: { $sum: { $ifNull: ['$movement.coinOut', 0] } },
+              ...buildMovementTotalsGroup(),
               gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
-              jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
             },
           },
         ],
@@ -1019,12 +1016,9 @@ export async function getAllMachines(
         locationName: '$locationDetails.name',
         aceEnabled: '$locationDetails.aceEnabled',
         licenceeId: { $ifNull: ['$locationDetails.rel.licencee', null] },
-        // Denomination scaling fields
+        // Summed movement, composed with the licencee formula below
         rawDrop: { $ifNull: ['$meterData.drop', 0] },
-        rawMoneyOut: { $ifNull: ['$meterData.moneyOut', 0] },
-        rawCoinIn: { $ifNull: ['$meterData.coinIn', 0] },
-        rawCoinOut: { $ifNull: ['$meterData.coinOut', 0] },
-        rawJackpot: { $ifNull: ['$meterData.jackpot', 0] },
+        meterTotals: projectMeterTotals(),
         gamesPlayed: { $ifNull: ['$meterData.gamesPlayed', 0] },
       },
     }
@@ -1039,35 +1033,34 @@ export async function getAllMachines(
   }
 
   // Build licencee jackpot settings map
-  const licenceeJackpotMap = await buildLicenceeJackpotMap();
+  const licenceeFormulaMap = await buildLicenceeFormulaMap();
 
   const transformedMachines = machines.map(machine => {
     const gameConfig = machine.gameConfig as
       | { theoreticalRtp?: number }
       | undefined;
 
-    // Adjust moneyOut with jackpot based on licencee setting
+    // Compose Money In/Out and Gross with the licencee formula
     const licId = machine.licenceeId ? String(machine.licenceeId) : '';
-    const includesJackpot = licenceeJackpotMap.get(licId) || false;
+    const formula = licenceeFormulaMap.get(licId) || DEFAULT_FINANCIAL_FORMULA;
+    const includesJackpot = formula.includeJackpot;
 
     const moneyInScale = moneyInMult !== null ? 1 - moneyInMult : 1;
     const moneyOutScale = moneyOutMult !== null ? 1 - moneyOutMult : 1;
-    const jackpotVal =
-      Math.round((Number(machine.rawJackpot) || 0) * moneyOutScale * 100) / 100;
-    const rawMoneyOut =
-      Math.round((Number(machine.rawMoneyOut) || 0) * moneyOutScale * 100) /
-      100;
-    const adjustedMoneyOut = includesJackpot
-      ? rawMoneyOut + jackpotVal
-      : rawMoneyOut;
-    const dropVal =
-      Math.round((Number(machine.rawDrop) || 0) * moneyInScale * 100) / 100;
-    const adjustedGross = Math.round((dropVal - adjustedMoneyOut) * 100) / 100;
+    const totals = (machine.meterTotals || {}) as MovementTotals;
+    const metrics = calculateFinancialMetrics(totals, formula, {
+      moneyInScale,
+      moneyOutScale,
+    });
+    const jackpotVal = Math.round(metrics.jackpot * 100) / 100;
+    const adjustedMoneyOut = Math.round(metrics.moneyOut * 100) / 100;
+    const dropVal = Math.round(metrics.moneyIn * 100) / 100;
+    const adjustedGross = Math.round(metrics.gross * 100) / 100;
 
     const coinInVal =
-      Math.round((Number(machine.rawCoinIn) || 0) * moneyInScale * 100) / 100;
+      Math.round((Number(totals.coinIn) || 0) * moneyInScale * 100) / 100;
     const coinOutVal =
-      Math.round((Number(machine.rawCoinOut) || 0) * moneyOutScale * 100) / 100;
+      Math.round((Number(totals.coinOut) || 0) * moneyOutScale * 100) / 100;
     const netWinVal = Math.round((coinInVal - coinOutVal) * 100) / 100;
 
     const holdPct = coinInVal > 0 ? Math.round((adjustedGross / coinInVal) * 100 * 100) / 100 : 0;
@@ -1083,7 +1076,7 @@ export async function getAllMachines(
       (hasRelay &&
         !!(
           machine.aceEnabled ||
-          (lastActivity && lastActivity > threeMinutesAgo)
+          (lastActivity && lastActivity > onlineCutoff)
         ));
 
     return {
@@ -1196,16 +1189,14 @@ export async function getOfflineMachines(
   const locationId = searchParams.get('locationId');
   const durationFilter =
     searchParams.get('duration') || searchParams.get('offlineDuration');
-  const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+  const onlineCutoff = getOnlineCutoff(
+    await getLicenceeMachineStatus(getLicenceeFilter(locationMatchStage))
+  );
 
   const machineMatchStage: Record<string, unknown> = {
     $and: [
-      {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      },
+      { ...NOT_DELETED_FILTER },
+      { ...NOT_RETIRED_FILTER },
       // Only include machines with a valid relayId — no-SMIB machines cannot report connectivity
       { relayId: { $exists: true, $nin: [null, ''] } },
       // Machines at aceEnabled locations are always online — exclude from offline results
@@ -1224,7 +1215,7 @@ export async function getOfflineMachines(
   } else {
     // Default: Must have been online at some point, but not recently
     // This matches the original logic for 'offline'
-    andArray.push({ lastActivity: { $exists: true, $lt: threeMinutesAgo } });
+    andArray.push({ lastActivity: { $exists: true, $lt: onlineCutoff } });
 
     if (durationFilter && durationFilter !== 'all') {
       const now = new Date();
@@ -1244,7 +1235,7 @@ export async function getOfflineMachines(
           durationThreshold = new Date(now.getTime() - 7 * 24 * 60 * 60 * 1000);
           break;
         default:
-          durationThreshold = threeMinutesAgo;
+          durationThreshold = onlineCutoff;
       }
 
       andArray.push({
@@ -1354,14 +1345,8 @@ export async function getOfflineMachines(
           {
             $group: {
               _id: null,
-              drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-              moneyOut: {
-                $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-              },
-              coinIn: { $sum: { $ifNull: ['$movement.coinIn', 0] } },
-              coinOut: { $sum: { $ifNull: ['$movement.coinOut', 0] } },
+              ...buildMovementTotalsGroup(),
               gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
-              jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
             },
           },
         ],
@@ -1394,12 +1379,9 @@ export async function getOfflineMachines(
         locationName: '$locationDetails.name',
         aceEnabled: '$locationDetails.aceEnabled',
         licenceeId: { $ifNull: ['$locationDetails.rel.licencee', null] },
-        // Denomination scaling fields
+        // Summed movement, composed with the licencee formula below
         rawDrop: { $ifNull: ['$meterData.drop', 0] },
-        rawMoneyOut: { $ifNull: ['$meterData.moneyOut', 0] },
-        rawCoinIn: { $ifNull: ['$meterData.coinIn', 0] },
-        rawCoinOut: { $ifNull: ['$meterData.coinOut', 0] },
-        rawJackpot: { $ifNull: ['$meterData.jackpot', 0] },
+        meterTotals: projectMeterTotals(),
         gamesPlayed: { $ifNull: ['$meterData.gamesPlayed', 0] },
       },
     },
@@ -1485,7 +1467,7 @@ export async function getOfflineMachines(
   }
 
   // Build licencee jackpot settings map
-  const licenceeJackpotMap = await buildLicenceeJackpotMap();
+  const licenceeFormulaMap = await buildLicenceeFormulaMap();
 
   const transformedMachines = machines.map(machine => {
     const gameConfig = machine.gameConfig as
@@ -1504,7 +1486,7 @@ export async function getOfflineMachines(
       (hasRelay &&
         !!(
           machine.aceEnabled ||
-          (lastActivity && lastActivity > threeMinutesAgo)
+          (lastActivity && lastActivity > onlineCutoff)
         ));
 
     let offlineTimeLabel: string | undefined = undefined;
@@ -1521,28 +1503,27 @@ export async function getOfflineMachines(
       offlineTimeLabel = 'Never';
     }
 
-    // Adjust moneyOut with jackpot based on licencee setting
+    // Compose Money In/Out and Gross with the licencee formula
     const licId = machine.licenceeId ? String(machine.licenceeId) : '';
-    const includesJackpot = licenceeJackpotMap.get(licId) || false;
+    const formula = licenceeFormulaMap.get(licId) || DEFAULT_FINANCIAL_FORMULA;
+    const includesJackpot = formula.includeJackpot;
 
     const moneyInScale = moneyInMult !== null ? 1 - moneyInMult : 1;
     const moneyOutScale = moneyOutMult !== null ? 1 - moneyOutMult : 1;
-    const jackpotVal =
-      Math.round((Number(machine.rawJackpot) || 0) * moneyOutScale * 100) / 100;
-    const rawMoneyOut =
-      Math.round((Number(machine.rawMoneyOut) || 0) * moneyOutScale * 100) /
-      100;
-    const adjustedMoneyOut = includesJackpot
-      ? rawMoneyOut + jackpotVal
-      : rawMoneyOut;
-    const dropVal =
-      Math.round((Number(machine.rawDrop) || 0) * moneyInScale * 100) / 100;
-    const adjustedGross = Math.round((dropVal - adjustedMoneyOut) * 100) / 100;
+    const totals = (machine.meterTotals || {}) as MovementTotals;
+    const metrics = calculateFinancialMetrics(totals, formula, {
+      moneyInScale,
+      moneyOutScale,
+    });
+    const jackpotVal = Math.round(metrics.jackpot * 100) / 100;
+    const adjustedMoneyOut = Math.round(metrics.moneyOut * 100) / 100;
+    const dropVal = Math.round(metrics.moneyIn * 100) / 100;
+    const adjustedGross = Math.round(metrics.gross * 100) / 100;
 
     const coinInVal =
-      Math.round((Number(machine.rawCoinIn) || 0) * moneyInScale * 100) / 100;
+      Math.round((Number(totals.coinIn) || 0) * moneyInScale * 100) / 100;
     const coinOutVal =
-      Math.round((Number(machine.rawCoinOut) || 0) * moneyOutScale * 100) / 100;
+      Math.round((Number(totals.coinOut) || 0) * moneyOutScale * 100) / 100;
     const netWinVal = Math.round((coinInVal - coinOutVal) * 100) / 100;
 
     const holdPct = coinInVal > 0 ? Math.round((adjustedGross / coinInVal) * 100 * 100) / 100 : 0;
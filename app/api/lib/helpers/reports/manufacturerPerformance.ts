@@ -8,9 +8,16 @@
  */
 
 // Note: Db type from mongodb not imported to avoid mongoose/mongodb version mismatch
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { Meters } from '@/app/api/lib/models/meters';
 import type { TimePeriod } from '@/app/api/lib/types';
 import { getDatesForTimePeriod } from '@/app/api/lib/utils/dates';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import type { MovementTotals } from '@shared/types';
 import type { PipelineStage } from 'mongoose';
 
 /**
@@ -26,6 +33,16 @@ export type ManufacturerDataItem = {
   totalGross: number;
 };
 
+/**
+ * Manufacturer row from the pipeline, before the financial formula is applied
+ */
+type ManufacturerTotals = MovementTotals & {
+  _id: string;
+  totalMachines: number;
+  totalHandle: number;
+  totalWin: number;
+};
+
 /**
  * Manufacturer performance result item
  */
@@ -167,11 +184,7 @@ function buildManufacturerPerformancePipeline(
             ],
           },
         },
-        totalDrop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
-        totalGross: { $sum: { $ifNull: ['$movement.gross', 0] } },
+        ...buildMovementTotalsGroup(),
       },
     },
     {
@@ -320,12 +333,30 @@ export async function getManufacturerPerformance(
     licencee
   );
 
-  const manufacturerData: ManufacturerDataItem[] = [];
+  const manufacturerTotals: ManufacturerTotals[] = [];
   const cursor = Meters.aggregate(pipeline).cursor({ batchSize: 1000 });
   for await (const doc of cursor) {
-    manufacturerData.push(doc as ManufacturerDataItem);
+    manufacturerTotals.push(doc as ManufacturerTotals);
   }
 
+  // Compose drop, cancelled credits and gross with the location's formula
+  const formulas = await getLocationFinancialFormulas([locationId]);
+  const formula = formulas.get(locationId) || DEFAULT_FINANCIAL_FORMULA;
+  const manufacturerData: ManufacturerDataItem[] = manufacturerTotals.map(
+    item => {
+      const metrics = calculateFinancialMetrics(item, formula);
+      return {
+        _id: item._id,
+        totalMachines: item.totalMachines,
+        totalHandle: item.totalHandle,
+        totalWin: item.totalWin,
+        totalDrop: metrics.moneyIn,
+        totalCancelledCredits: metrics.moneyOut,
+        totalGross: metrics.gross,
+      };
+    }
+  );
+
   const totals = calculateManufacturerTotals(manufacturerData);
   return calculateManufacturerPercentages(manufacturerData, totals);
 }
@@ -8,6 +8,7 @@
  * @module app/api/lib/helpers/metersReport
  */
 
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getGamingDayRangesForLocations } from '@/lib/utils/gamingDayRange';
 import type {
   ParsedMetersReportParams,
@@ -19,6 +20,7 @@ export type TransformedMeterData = MetersReportData;
 import type { TimePeriod } from '@/shared/types/common';
 import type { CurrencyCode } from '@/shared/types/currency';
 import type { GamingLocationDocument, GamingMachine } from '@/shared/types';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
@@ -220,10 +222,7 @@ export async function fetchLocationData(
   const locationsData = await GamingLocations.find(
     {
       _id: { $in: locationIds },
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
+      ...NOT_DELETED_FILTER,
     },
     { _id: 1, name: 1, gameDayOffset: 1, rel: 1, country: 1 }
   )
@@ -299,7 +298,7 @@ export function calculateGamingDayRanges(
 }
 
 /**
- * Fetch machines data for the selected locations
+ * Fetch machines data for the selected locations (retired machines excluded)
  *
  * @param {string[]} locationList - List of location IDs to filter by
  * @param {string | null} licencee - Optional licencee ID to filter by
@@ -315,7 +314,8 @@ export async function fetchMachinesData(
   }
   // Build query filter for machines
   const machineMatchStage: Record<string, unknown> = {
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
+    ...NOT_DELETED_FILTER,
+    ...NOT_RETIRED_FILTER,
   };
 
   // Add location filter if specific locations are selected
@@ -0,0 +1,365 @@
+/**
+ * Profit Split Settlement Helper
+ *
+ * Splits each location's gross for a period between the licencee and the
+ * venue owner using the location's configured profit split
+ * (app/api/lib/helpers/locations/profitSplit). When the split changes inside
+ * the period, the location is settled in one row per split, each over the
+ * gaming days that split applied to. Gross uses the licencee's financial
+ * formula (never reviewer scales); a loss is shared in the same proportion.
+ *
+ * @module app/api/lib/helpers/reports/profitSplit
+ */
+
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import {
+  getEffectiveSplits,
+  getSplitOnDay,
+} from '@/app/api/lib/helpers/locations/profitSplit';
+import { getMovementTotalsWithRollup } from '@/app/api/lib/helpers/metersDaily';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+import type { LocationProfitSplit } from '@shared/types';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type ProfitSplitRow = {
+  locationId: string;
+  locationName: string;
+  licenceeId: string | null;
+  licenceeName: string | null;
+  /** First gaming day this row covers, YYYY-MM-DD */
+  from: string;
+  /** Last gaming day this row covers, YYYY-MM-DD */
+  to: string;
+  /** Venue owner's percent of gross */
+  venueShare: number;
+  venueOwner: string | null;
+  payeeReference: string | null;
+  moneyIn: number;
+  moneyOut: number;
+  gross: number;
+  venueAmount: number;
+  licenceeAmount: number;
+};
+
+export type ProfitSplitReport = {
+  generatedAt: Date;
+  from: string;
+  to: string;
+  rows: ProfitSplitRow[];
+  totalGross: number;
+  totalVenueAmount: number;
+  totalLicenceeAmount: number;
+};
+
+export type ProfitSplitReportParams = {
+  allowedLocationIds: 'all' | string[];
+  /** First gaming day, YYYY-MM-DD */
+  from: string;
+  /** Last gaming day, YYYY-MM-DD */
+  to: string;
+};
+
+type SplitReportLocation = {
+  _id: string;
+  name: string;
+  gameDayOffset?: number;
+  profitShare?: number;
+  profitSplits?: LocationProfitSplit[];
+  rel?: { licencee?: string };
+};
+
+type SplitSegment = {
+  from: string;
+  to: string;
+  split: LocationProfitSplit;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+function toDay(date: Date): string {
+  return date.toISOString().slice(0, 10);
+}
+
+function dayBefore(day: string): string {
+  const date = new Date(`${day}T00:00:00Z`);
+  date.setUTCDate(date.getUTCDate() - 1);
+  return toDay(date);
+}
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+/**
+ * Cuts the period at every split that starts inside it.
+ */
+function getSplitSegments(
+  splits: LocationProfitSplit[],
+  from: string,
+  to: string
+): SplitSegment[] {
+  const segments: SplitSegment[] = [
+    { from, to, split: getSplitOnDay(splits, from) },
+  ];
+  splits
+    .filter(split => split.effectiveFrom > from && split.effectiveFrom <= to)
+    .forEach(split => {
+      segments[segments.length - 1].to = dayBefore(split.effectiveFrom);
+      segments.push({ from: split.effectiveFrom, to, split });
+    });
+  return segments;
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the settlement for a period.
+ *
+ * @param params - Location scope and gaming day range
+ * @returns One row per location and split, ordered by location name
+ */
+export async function getProfitSplitReport(
+  params: ProfitSplitReportParams
+): Promise<ProfitSplitReport> {
+  const { allowedLocationIds, from, to } = params;
+
+  // Step 1: Locations in scope with their splits
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: allowedLocationIds };
+  }
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    gameDayOffset: 1,
+    profitShare: 1,
+    profitSplits: 1,
+    'rel.licencee': 1,
+  }).lean<SplitReportLocation[]>();
+
+  // Step 2: Segments per location; the nth segments are queried together
+  // because movement totals are keyed by location
+  const segmentsByLocation = new Map<string, SplitSegment[]>();
+  const rounds: Array<Map<string, GamingDayRange>> = [];
+  locations.forEach(location => {
+    const locationId = String(location._id);
+    const segments = getSplitSegments(getEffectiveSplits(location), from, to);
+    segmentsByLocation.set(locationId, segments);
+    segments.forEach((segment, index) => {
+      if (!rounds[index]) rounds[index] = new Map();
+      rounds[index].set(
+        locationId,
+        getGamingDayRangeForPeriod(
+          'Custom',
+          location.gameDayOffset ?? 8,
+          new Date(`${segment.from}T00:00:00Z`),
+          new Date(`${segment.to}T00:00:00Z`)
+        )
+      );
+    });
+  });
+  const licenceeIds = Array.from(
+    new Set(
+      locations
+        .map(location => location.rel?.licencee)
+        .filter((id): id is string => Boolean(id))
+        .map(String)
+    )
+  );
+
+  // Step 3: Totals per round, formulas and licencee names
+  const [roundTotals, formulaByLicencee, licenceeDocs] = await Promise.all([
+    Promise.all(
+      rounds.map(ranges => getMovementTotalsWithRollup(ranges, 'location'))
+    ),
+    getLicenceeFinancialFormulas(licenceeIds),
+    Licencee.find({ _id: { $in: licenceeIds } }, { _id: 1, name: 1 }).lean<
+      Array<{ _id: string; name: string }>
+    >(),
+  ]);
+  const licenceeNames = new Map(
+    licenceeDocs.map(licencee => [String(licencee._id), licencee.name])
+  );
+
+  // Step 4: Split gross per segment
+  const rows: ProfitSplitRow[] = [];
+  locations.forEach(location => {
+    const locationId = String(location._id);
+    const licenceeId = location.rel?.licencee
+      ? String(location.rel.licencee)
+      : null;
+    const formula =
+      (licenceeId && formulaByLicencee.get(licenceeId)) ||
+      DEFAULT_FINANCIAL_FORMULA;
+
+    segmentsByLocation.get(locationId)?.forEach((segment, index) => {
+      const totals = roundTotals[index].get(locationId);
+      const metrics = totals
+        ? calculateFinancialMetrics(totals, formula)
+        : { moneyIn: 0, moneyOut: 0, gross: 0 };
+      const gross = round2(metrics.gross);
+      const venueAmount = round2((gross * segment.split.venueShare) / 100);
+
+      rows.push({
+        locationId,
+        locationName: location.name,
+        licenceeId,
+        licenceeName: licenceeId
+          ? licenceeNames.get(licenceeId) || licenceeId
+          : null,
+        from: segment.from,
+        to: segment.to,
+        venueShare: segment.split.venueShare,
+        venueOwner: segment.split.venueOwner || null,
+        payeeReference: segment.split.payeeReference || null,
+        moneyIn: metrics.moneyIn,
+        moneyOut: metrics.moneyOut,
+        gross,
+        venueAmount,
+        licenceeAmount: round2(gross - venueAmount),
+      });
+    });
+  });
+  rows.sort(
+    (a, b) =>
+      a.locationName.localeCompare(b.locationName) ||
+      a.from.localeCompare(b.from)
+  );
+
+  return {
+    generatedAt: new Date(),
+    from,
+    to,
+    rows,
+    totalGross: round2(rows.reduce((total, row) => total + row.gross, 0)),
+    totalVenueAmount: round2(
+      rows.reduce((total, row) => total + row.venueAmount, 0)
+    ),
+    totalLicenceeAmount: round2(
+      rows.reduce((total, row) => total + row.licenceeAmount, 0)
+    ),
+  };
+}
+
+// ============================================================================
+// Export
+// ============================================================================
+
+const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+
+/**
+ * Serializes the settlement to CSV: one line per location and split, then a
+ * total line.
+ */
+export function exportProfitSplitToCSV(report: ProfitSplitReport): string {
+  const header = [
+    'Location',
+    'Location ID',
+    'Licencee',
+    'From',
+    'To',
+    'Venue Share %',
+    'Venue Owner',
+    'Money In',
+    'Money Out',
+    'Gross',
+    'Venue Amount',
+    'Licencee Amount',
+  ];
+  const lines = report.rows.map(row =>
+    [
+      quote(row.locationName),
+      row.locationId,
+      quote(row.licenceeName || ''),
+      row.from,
+      row.to,
+      row.venueShare,
+      quote(row.venueOwner || ''),
+      row.moneyIn.toFixed(2),
+      row.moneyOut.toFixed(2),
+      row.gross.toFixed(2),
+      row.venueAmount.toFixed(2),
+      row.licenceeAmount.toFixed(2),
+    ].join(',')
+  );
+  const total = [
+    quote('TOTAL'),
+    '',
+    '',
+    report.from,
+    report.to,
+    '',
+    '',
+    '',
+    '',
+    report.totalGross.toFixed(2),
+    report.totalVenueAmount.toFixed(2),
+    report.totalLicenceeAmount.toFixed(2),
+  ].join(',');
+  return [header.join(','), ...lines, total].join('\n');
+}
+
+/**
+ * Serializes the amounts due to venue owners for accounts payable: one line
+ * per payee and location with a non-zero amount. A negative amount is the
+ * venue's share of a loss, to be recovered rather than paid.
+ */
+export function exportPayablesToCSV(report: ProfitSplitReport): string {
+  const header = [
+    'Payee',
+    'Payee Reference',
+    'Location',
+    'Location ID',
+    'Period From',
+    'Period To',
+    'Amount Due',
+  ];
+  const payables = new Map<
+    string,
+    { row: ProfitSplitRow; from: string; to: string; amount: number }
+  >();
+  report.rows.forEach(row => {
+    const key = `${row.locationId}|${row.venueOwner}|${row.payeeReference}`;
+    const entry = payables.get(key);
+    if (entry) {
+      entry.to = row.to;
+      entry.amount = round2(entry.amount + row.venueAmount);
+    } else {
+      payables.set(key, {
+        row,
+        from: row.from,
+        to: row.to,
+        amount: row.venueAmount,
+      });
+    }
+  });
+  const lines = Array.from(payables.values())
+    .filter(entry => entry.amount !== 0)
+    .map(({ row, from, to, amount }) =>
+      [
+        quote(row.venueOwner || row.locationName),
+        quote(row.payeeReference || ''),
+        quote(row.locationName),
+        row.locationId,
+        from,
+        to,
+        amount.toFixed(2),
+      ].join(',')
+    );
+  return [header.join(','), ...lines].join('\n');
+}
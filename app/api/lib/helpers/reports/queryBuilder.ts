@@ -0,0 +1,380 @@
+/**
+ * Ad Hoc Query Builder Helper
+ *
+ * Builds and runs aggregation pipelines from a structured spec, so analysts
+ * can pick a base entity, filters, group-by fields and metrics instead of
+ * hand-writing pipelines. Only the fields in `QUERY_BUILDER_CATALOG` are
+ * accepted; the generated pipeline can be printed in shell syntax for reuse.
+ * In tenant isolation mode the location scope is validated against the
+ * pinned licencee before the pipeline is built.
+ *
+ * Used by `/api/reports/query-builder` and the interactive
+ * `scripts/query-builder.ts` command.
+ *
+ * @module app/api/lib/helpers/reports/queryBuilder
+ */
+
+import { getLicenceeMachineStatus } from '@/app/api/lib/helpers/licencees';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import {
+  assertTenantLicencee,
+  assertTenantLocations,
+} from '@/app/api/lib/utils/tenantScope';
+import type { Model, PipelineStage } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type QueryBuilderEntity = 'machines' | 'locations' | 'meters';
+
+export type QueryBuilderSpec = {
+  entity: QueryBuilderEntity;
+  filters?: {
+    licencee?: string;
+    status?: string;
+    startDate?: string;
+    endDate?: string;
+  };
+  groupBy?: string[];
+  metrics?: string[];
+  limit?: number;
+};
+
+type CatalogField = { label: string; expression: unknown };
+
+type EntityCatalog = {
+  label: string;
+  dateField: string;
+  requiresDateRange: boolean;
+  statusValues: string[];
+  groupBy: Record<string, CatalogField>;
+  metrics: Record<string, CatalogField>;
+};
+
+export type QueryBuilderResult = {
+  pipeline: PipelineStage[];
+  shell: string;
+  rows: Record<string, unknown>[];
+};
+
+export const MAX_QUERY_BUILDER_LIMIT = 1000;
+const MAX_METER_RANGE_DAYS = 93;
+const SOFT_DELETE_FILTER = { ...NOT_DELETED_FILTER };
+
+function sumOf(path: string): CatalogField {
+  return {
+    label: `Sum of ${path}`,
+    expression: { $sum: { $ifNull: [`$${path}`, 0] } },
+  };
+}
+
+export const QUERY_BUILDER_CATALOG: Record<
+  QueryBuilderEntity,
+  EntityCatalog
+> = {
+  machines: {
+    label: 'Machines',
+    dateField: 'createdAt',
+    requiresDateRange: false,
+    statusValues: ['online', 'offline'],
+    groupBy: {
+      location: { label: 'Location', expression: '$gamingLocation' },
+      manufacturer: { label: 'Manufacturer', expression: '$manufacturer' },
+      game: { label: 'Game', expression: '$game' },
+      gameType: { label: 'Game type', expression: '$gameType' },
+      cabinetType: { label: 'Cabinet type', expression: '$cabinetType' },
+      assetStatus: { label: 'Asset status', expression: '$assetStatus' },
+    },
+    metrics: {
+      count: { label: 'Machine count', expression: { $sum: 1 } },
+      sasMachines: {
+        label: 'SAS machines',
+        expression: { $sum: { $cond: ['$isSasMachine', 1, 0] } },
+      },
+    },
+  },
+  locations: {
+    label: 'Locations',
+    dateField: 'createdAt',
+    requiresDateRange: false,
+    statusValues: [],
+    groupBy: {
+      licencee: { label: 'Licencee', expression: '$rel.licencee' },
+      country: { label: 'Country', expression: '$country' },
+      city: { label: 'City', expression: '$address.city' },
+      status: { label: 'Status', expression: '$status' },
+    },
+    metrics: {
+      count: { label: 'Location count', expression: { $sum: 1 } },
+      membershipEnabled: {
+        label: 'Membership enabled',
+        expression: { $sum: { $cond: ['$membershipEnabled', 1, 0] } },
+      },
+    },
+  },
+  meters: {
+    label: 'Meters',
+    dateField: 'readAt',
+    requiresDateRange: true,
+    statusValues: [],
+    groupBy: {
+      location: { label: 'Location', expression: '$location' },
+      machine: { label: 'Machine', expression: '$machine' },
+      day: {
+        label: 'Day (UTC)',
+        expression: {
+          $dateToString: {
+            date: '$readAt',
+            format: '%Y-%m-%d',
+            timezone: 'UTC',
+          },
+        },
+      },
+      hour: {
+        label: 'Hour (UTC)',
+        expression: {
+          $dateToString: {
+            date: '$readAt',
+            format: '%Y-%m-%dT%H',
+            timezone: 'UTC',
+          },
+        },
+      },
+    },
+    metrics: {
+      count: { label: 'Reading count', expression: { $sum: 1 } },
+      drop: sumOf('movement.drop'),
+      totalCancelledCredits: sumOf('movement.totalCancelledCredits'),
+      jackpot: sumOf('movement.jackpot'),
+      coinIn: sumOf('movement.coinIn'),
+      coinOut: sumOf('movement.coinOut'),
+      gamesPlayed: sumOf('movement.gamesPlayed'),
+    },
+  },
+};
+
+const ENTITY_MODELS: Record<QueryBuilderEntity, Model<unknown>> = {
+  machines: Machine as Model<unknown>,
+  locations: GamingLocations as Model<unknown>,
+  meters: Meters as Model<unknown>,
+};
+
+// ============================================================================
+// Validation & Pipeline Construction
+// ============================================================================
+
+function getSpecMetrics(spec: QueryBuilderSpec): string[] {
+  return spec.metrics && spec.metrics.length > 0 ? spec.metrics : ['count'];
+}
+
+/**
+ * Validates a spec against the catalog.
+ *
+ * @returns Error message, or null when valid
+ */
+export function validateQuerySpec(spec: QueryBuilderSpec): string | null {
+  const catalog = QUERY_BUILDER_CATALOG[spec?.entity];
+  if (!catalog) {
+    return `entity must be one of ${Object.keys(QUERY_BUILDER_CATALOG).join(', ')}`;
+  }
+
+  const unknownGroup = (spec.groupBy || []).find(key => !catalog.groupBy[key]);
+  if (unknownGroup) {
+    return `Unknown group-by field '${unknownGroup}' for ${spec.entity}`;
+  }
+
+  const metrics = getSpecMetrics(spec);
+  const unknownMetric = metrics.find(key => !catalog.metrics[key]);
+  if (unknownMetric) {
+    return `Unknown metric '${unknownMetric}' for ${spec.entity}`;
+  }
+
+  const { status, startDate, endDate } = spec.filters || {};
+  if (status && spec.entity === 'meters') {
+    return 'The status filter does not apply to meters';
+  }
+  if (
+    status &&
+    catalog.statusValues.length > 0 &&
+    !catalog.statusValues.includes(status)
+  ) {
+    return `status must be one of ${catalog.statusValues.join(', ')}`;
+  }
+
+  const start = startDate ? new Date(startDate) : null;
+  const end = endDate ? new Date(endDate) : null;
+  if (
+    (start && Number.isNaN(start.getTime())) ||
+    (end && Number.isNaN(end.getTime()))
+  ) {
+    return 'startDate and endDate must be valid dates';
+  }
+  if (catalog.requiresDateRange) {
+    if (!start || !end) {
+      return `${spec.entity} queries require startDate and endDate`;
+    }
+    if ((end.getTime() - start.getTime()) / 86400000 > MAX_METER_RANGE_DAYS) {
+      return `${spec.entity} queries are limited to ${MAX_METER_RANGE_DAYS} days`;
+    }
+  }
+
+  return null;
+}
+
+/**
+ * Resolves the location IDs a spec is scoped to (licencee filter ∩ access).
+ */
+async function resolveScopedLocations(
+  licencee: string | undefined,
+  allowedLocationIds: 'all' | string[]
+): Promise<'all' | string[]> {
+  if (!licencee) return allowedLocationIds;
+
+  const licenceeLocations = await GamingLocations.find(
+    { 'rel.licencee': licencee, ...SOFT_DELETE_FILTER },
+    { _id: 1 }
+  ).lean<Array<{ _id: string }>>();
+  const ids = licenceeLocations.map(location => String(location._id));
+  return allowedLocationIds === 'all'
+    ? ids
+    : ids.filter(id => allowedLocationIds.includes(id));
+}
+
+/**
+ * Builds the aggregation pipeline for a validated spec.
+ *
+ * @param spec - Query spec
+ * @param allowedLocationIds - Locations the caller may see
+ * @returns Pipeline stages
+ */
+export async function buildQueryPipeline(
+  spec: QueryBuilderSpec,
+  allowedLocationIds: 'all' | string[] = 'all'
+): Promise<PipelineStage[]> {
+  const catalog = QUERY_BUILDER_CATALOG[spec.entity];
+  const filters = spec.filters || {};
+  const match: Record<string, unknown> = {};
+
+  // Step 1: Location scope (validated against the tenant, if pinned)
+  assertTenantLicencee(filters.licencee);
+  const scopedLocations = await assertTenantLocations(
+    await resolveScopedLocations(filters.licencee, allowedLocationIds)
+  );
+  if (scopedLocations !== 'all') {
+    const locationField =
+      spec.entity === 'machines'
+        ? 'gamingLocation'
+        : spec.entity === 'locations'
+          ? '_id'
+          : 'location';
+    match[locationField] = { $in: scopedLocations };
+  }
+
+  // Step 2: Soft delete, status and date filters
+  if (spec.entity !== 'meters') {
+    Object.assign(match, SOFT_DELETE_FILTER);
+  }
+  if (filters.status) {
+    if (spec.entity === 'machines') {
+      // Online status is evaluated at pipeline build time
+      const onlineCutoff = getOnlineCutoff(
+        await getLicenceeMachineStatus(filters.licencee)
+      );
+      match.lastActivity =
+        filters.status === 'online'
+          ? { $gte: onlineCutoff }
+          : { $not: { $gte: onlineCutoff } };
+    } else {
+      match.status = filters.status;
+    }
+  }
+  if (filters.startDate || filters.endDate) {
+    const range: Record<string, Date> = {};
+    if (filters.startDate) range.$gte = new Date(filters.startDate);
+    if (filters.endDate) range.$lte = new Date(filters.endDate);
+    match[catalog.dateField] = range;
+  }
+
+  // Step 3: Group, sort and shape
+  const groupBy = spec.groupBy || [];
+  const metrics = getSpecMetrics(spec);
+  const group: Record<string, unknown> = {
+    _id:
+      groupBy.length > 0
+        ? Object.fromEntries(
+            groupBy.map(key => [key, catalog.groupBy[key].expression])
+          )
+        : null,
+  };
+  metrics.forEach(key => {
+    group[key] = catalog.metrics[key].expression;
+  });
+
+  const project: Record<string, unknown> = { _id: 0 };
+  groupBy.forEach(key => {
+    project[key] = `$_id.${key}`;
+  });
+  metrics.forEach(key => {
+    project[key] = 1;
+  });
+
+  return [
+    { $match: match },
+    { $group: group },
+    { $sort: { [metrics[0]]: -1 } },
+    {
+      $limit: Math.min(
+        Math.max(Math.floor(spec.limit || 100), 1),
+        MAX_QUERY_BUILDER_LIMIT
+      ),
+    },
+    { $project: project },
+  ] as PipelineStage[];
+}
+
+/**
+ * Formats a pipeline as a mongo shell `aggregate` call for reuse.
+ */
+export function formatPipelineForShell(
+  entity: QueryBuilderEntity,
+  pipeline: PipelineStage[]
+): string {
+  const json = JSON.stringify(
+    pipeline,
+    function (this: Record<string, unknown>, key, value) {
+      const raw = this[key];
+      return raw instanceof Date ? `__ISODATE__${raw.toISOString()}` : value;
+    },
+    2
+  );
+  const collection = ENTITY_MODELS[entity].collection.name;
+  const body = json.replace(/"__ISODATE__([^"]+)"/g, 'ISODate("$1")');
+  return `db.getCollection('${collection}').aggregate(${body})`;
+}
+
+/**
+ * Builds and runs a spec.
+ *
+ * @param spec - Validated query spec
+ * @param allowedLocationIds - Locations the caller may see
+ * @returns Pipeline, its shell form and the result rows
+ */
+export async function runQuerySpec(
+  spec: QueryBuilderSpec,
+  allowedLocationIds: 'all' | string[] = 'all'
+): Promise<QueryBuilderResult> {
+  const pipeline = await buildQueryPipeline(spec, allowedLocationIds);
+  const rows = await ENTITY_MODELS[spec.entity]
+    .aggregate<Record<string, unknown>>(pipeline)
+    .allowDiskUse(true);
+  return {
+    pipeline,
+    shell: formatPipelineForShell(spec.entity, pipeline),
+    rows,
+  };
+}
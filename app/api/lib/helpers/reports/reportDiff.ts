@@ -0,0 +1,450 @@
+/**
+ * Report Diff Helper
+ *
+ * Compares two exported runs of a report — CSV downloads, `report-templates
+ * run` output or API JSON responses — for audit sign-off (this month vs last
+ * month, pre-fix vs post-fix). Rows are matched on key columns and the diff
+ * lists rows added, rows removed and, for rows in both, numeric values that
+ * moved by more than the tolerance and text values that differ.
+ *
+ * JSON input may be an array of rows, `{ data: [...] }`, or any object whose
+ * rows sit at a dotted `rowsPath` (e.g. `data.machines`). Nested objects are
+ * flattened to dotted columns (`movement.coinIn`).
+ *
+ * Used by the `report-diff` command (scripts/report-diff.ts).
+ *
+ * @module app/api/lib/helpers/reports/reportDiff
+ */
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ReportRow = Record<string, string | number | null>;
+
+export type ReportDiffOptions = {
+  /** Columns identifying a row; detected when omitted */
+  keyColumns?: string[];
+  /** Columns left out of the comparison */
+  ignoreColumns?: string[];
+  /** Absolute difference a number may move by (default 0.01) */
+  tolerance?: number;
+  /** Relative difference in percent a number may also move by */
+  tolerancePercent?: number;
+};
+
+export type ReportValueChange = {
+  column: string;
+  before: string | number | null;
+  after: string | number | null;
+  /** after - before, for numeric columns */
+  delta: number | null;
+  /** Delta as a percentage of before, when before is non-zero */
+  deltaPercent: number | null;
+};
+
+export type ReportRowChange = {
+  key: string;
+  changes: ReportValueChange[];
+};
+
+export type ReportDiff = {
+  keyColumns: string[];
+  tolerance: number;
+  tolerancePercent: number | null;
+  beforeRows: number;
+  afterRows: number;
+  added: ReportRow[];
+  removed: ReportRow[];
+  changed: ReportRowChange[];
+  unchanged: number;
+  /** Keys found more than once in a file; only the first row is compared */
+  duplicateKeys: string[];
+  /** Columns present in only one file */
+  columnsOnlyBefore: string[];
+  columnsOnlyAfter: string[];
+};
+
+export const DEFAULT_DIFF_TOLERANCE = 0.01;
+
+/** Columns tried, in order, when no key is given */
+const KEY_CANDIDATES = [
+  '_id',
+  'id',
+  'machineId',
+  'locationId',
+  'licenceeId',
+  'serialNumber',
+  'Serial Number',
+  'location',
+  'Location',
+  'name',
+  'Name',
+];
+
+const NUMERIC_PATTERN = /^-?[$]?-?[\d,]*\.?\d+%?$/;
+
+// ============================================================================
+// Parsing
+// ============================================================================
+
+/**
+ * Splits CSV text into records, handling quoted fields, escaped quotes and
+ * line breaks inside quotes.
+ */
+function parseCsvRecords(content: string): string[][] {
+  const records: string[][] = [];
+  let record: string[] = [];
+  let field = '';
+  let quoted = false;
+
+  for (let i = 0; i < content.length; i++) {
+    const char = content[i];
+    if (quoted) {
+      if (char === '"' && content[i + 1] === '"') {
+        field += '"';
+        i++;
+      } else if (char === '"') {
+        quoted = false;
+      } else {
+        field += char;
+      }
+    } else if (char === '"') {
+      quoted = true;
+    } else if (char === ',') {
+      record.push(field);
+      field = '';
+    } else if (char === '\n' || char === '\r') {
+      if (char === '\r' && content[i + 1] === '\n') i++;
+      record.push(field);
+      records.push(record);
+      record = [];
+      field = '';
+    } else {
+      field += char;
+    }
+  }
+  if (field !== '' || record.length > 0) {
+    record.push(field);
+    records.push(record);
+  }
+  return records.filter(row => row.some(value => value.trim() !== ''));
+}
+
+/**
+ * Reads a cell as a number when it looks like one (`1,234.50`, `$12`, `4%`).
+ */
+function toValue(raw: unknown): string | number | null {
+  if (raw === null || raw === undefined || raw === '') return null;
+  if (typeof raw === 'number') return Number.isFinite(raw) ? raw : null;
+  if (typeof raw === 'boolean') return String(raw);
+  const text = String(raw).trim();
+  if (NUMERIC_PATTERN.test(text)) {
+    const value = Number(text.replace(/[$,%]/g, ''));
+    if (Number.isFinite(value)) return value;
+  }
+  return text;
+}
+
+function flattenRow(
+  value: Record<string, unknown>,
+  prefix = '',
+  row: ReportRow = {}
+): ReportRow {
+  Object.entries(value).forEach(([key, entry]) => {
+    const column = prefix ? `${prefix}.${key}` : key;
+    if (
+      entry &&
+      typeof entry === 'object' &&
+      !Array.isArray(entry) &&
+      !(entry instanceof Date)
+    ) {
+      flattenRow(entry as Record<string, unknown>, column, row);
+    } else {
+      row[column] = Array.isArray(entry)
+        ? JSON.stringify(entry)
+        : toValue(entry);
+    }
+  });
+  return row;
+}
+
+/**
+ * Parses an exported report into flat rows.
+ *
+ * @param content - File contents
+ * @param format - 'csv' or 'json'
+ * @param rowsPath - Dotted path to the rows inside a JSON document
+ * @throws Error when the rows cannot be found
+ */
+export function parseReportRows(
+  content: string,
+  format: 'csv' | 'json',
+  rowsPath?: string
+): ReportRow[] {
+  if (format === 'csv') {
+    const [header, ...records] = parseCsvRecords(
+      content.replace(/^\uFEFF/, '')
+    );
+    if (!header) return [];
+    return records.map(record =>
+      Object.fromEntries(
+        header.map((column, index) => [column.trim(), toValue(record[index])])
+      )
+    );
+  }
+
+  let rows: unknown = JSON.parse(content);
+  if (rowsPath) {
+    rowsPath.split('.').forEach(part => {
+      rows = (rows as Record<string, unknown> | undefined)?.[part];
+    });
+  } else if (!Array.isArray(rows)) {
+    const document = rows as Record<string, unknown>;
+    rows = Array.isArray(document.data) ? document.data : document.rows;
+  }
+  if (!Array.isArray(rows)) {
+    throw new Error(
+      rowsPath
+        ? `No array of rows at '${rowsPath}'`
+        : 'No array of rows found; pass the path to them'
+    );
+  }
+  return rows.map(row =>
+    row && typeof row === 'object'
+      ? flattenRow(row as Record<string, unknown>)
+      : { value: toValue(row) }
+  );
+}
+
+// ============================================================================
+// Diff
+// ============================================================================
+
+/**
+ * Picks key columns: the first candidate present and unique in both files.
+ */
+export function detectKeyColumns(
+  before: ReportRow[],
+  after: ReportRow[]
+): string[] {
+  const isUnique = (rows: ReportRow[], column: string) =>
+    rows.every(row => row[column] !== null && row[column] !== undefined) &&
+    new Set(rows.map(row => String(row[column]))).size === rows.length;
+
+  const columns = Object.keys(before[0] || after[0] || {});
+  const candidate = [...KEY_CANDIDATES, ...columns].find(
+    column =>
+      columns.includes(column) &&
+      isUnique(before, column) &&
+      isUnique(after, column)
+  );
+  if (!candidate) {
+    throw new Error('No unique key column found; pass the key columns');
+  }
+  return [candidate];
+}
+
+function rowKey(row: ReportRow, keyColumns: string[]): string {
+  return keyColumns.map(column => String(row[column] ?? '')).join(' | ');
+}
+
+function indexRows(
+  rows: ReportRow[],
+  keyColumns: string[],
+  duplicates: Set<string>
+): Map<string, ReportRow> {
+  const index = new Map<string, ReportRow>();
+  rows.forEach(row => {
+    const key = rowKey(row, keyColumns);
+    if (index.has(key)) duplicates.add(key);
+    else index.set(key, row);
+  });
+  return index;
+}
+
+/**
+ * Compares two report runs row by row.
+ *
+ * @param before - Rows of the earlier (baseline) run
+ * @param after - Rows of the later run
+ * @param options - Key columns, ignored columns and tolerances
+ */
+export function diffReportRows(
+  before: ReportRow[],
+  after: ReportRow[],
+  options: ReportDiffOptions = {}
+): ReportDiff {
+  const tolerance = options.tolerance ?? DEFAULT_DIFF_TOLERANCE;
+  const tolerancePercent = options.tolerancePercent ?? null;
+  const keyColumns = options.keyColumns?.length
+    ? options.keyColumns
+    : detectKeyColumns(before, after);
+  const ignored = new Set([...(options.ignoreColumns || []), ...keyColumns]);
+
+  const beforeColumns = new Set(before.flatMap(row => Object.keys(row)));
+  const afterColumns = new Set(after.flatMap(row => Object.keys(row)));
+  const missingKeys = keyColumns.filter(
+    column => !beforeColumns.has(column) && !afterColumns.has(column)
+  );
+  if (missingKeys.length > 0) {
+    throw new Error(`Key column(s) not found: ${missingKeys.join(', ')}`);
+  }
+  const sharedColumns = Array.from(beforeColumns).filter(
+    column => afterColumns.has(column) && !ignored.has(column)
+  );
+
+  const duplicates = new Set<string>();
+  const beforeIndex = indexRows(before, keyColumns, duplicates);
+  const afterIndex = indexRows(after, keyColumns, duplicates);
+
+  const added: ReportRow[] = [];
+  const removed: ReportRow[] = [];
+  const changed: ReportRowChange[] = [];
+  let unchanged = 0;
+
+  beforeIndex.forEach((row, key) => {
+    if (!afterIndex.has(key)) removed.push(row);
+  });
+  afterIndex.forEach((afterRow, key) => {
+    const beforeRow = beforeIndex.get(key);
+    if (!beforeRow) {
+      added.push(afterRow);
+      return;
+    }
+
+    const changes: ReportValueChange[] = [];
+    sharedColumns.forEach(column => {
+      const a = beforeRow[column] ?? null;
+      const b = afterRow[column] ?? null;
+      if (typeof a === 'number' && typeof b === 'number') {
+        const delta = b - a;
+        const deltaPercent = a !== 0 ? (delta / Math.abs(a)) * 100 : null;
+        const beyondAbsolute = Math.abs(delta) > tolerance;
+        const beyondPercent =
+          tolerancePercent === null ||
+          deltaPercent === null ||
+          Math.abs(deltaPercent) > tolerancePercent;
+        if (beyondAbsolute && beyondPercent) {
+          changes.push({
+            column,
+            before: a,
+            after: b,
+            delta: Math.round(delta * 100) / 100,
+            deltaPercent:
+              deltaPercent === null
+                ? null
+                : Math.round(deltaPercent * 100) / 100,
+          });
+        }
+      } else if (String(a ?? '') !== String(b ?? '')) {
+        changes.push({
+          column,
+          before: a,
+          after: b,
+          delta: null,
+          deltaPercent: null,
+        });
+      }
+    });
+
+    if (changes.length > 0) changed.push({ key, changes });
+    else unchanged++;
+  });
+
+  return {
+    keyColumns,
+    tolerance,
+    tolerancePercent,
+    beforeRows: before.length,
+    afterRows: after.length,
+    added,
+    removed,
+    changed,
+    unchanged,
+    duplicateKeys: Array.from(duplicates),
+    columnsOnlyBefore: Array.from(beforeColumns).filter(
+      column => !afterColumns.has(column)
+    ),
+    columnsOnlyAfter: Array.from(afterColumns).filter(
+      column => !beforeColumns.has(column)
+    ),
+  };
+}
+
+/**
+ * True when the runs differ in any row or value.
+ */
+export function hasReportDifferences(diff: ReportDiff): boolean {
+  return (
+    diff.added.length > 0 || diff.removed.length > 0 || diff.changed.length > 0
+  );
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/**
+ * Formats a diff for the terminal / sign-off record.
+ */
+export function formatReportDiff(
+  diff: ReportDiff,
+  labels: { before: string; after: string } = {
+    before: 'before',
+    after: 'after',
+  }
+): string {
+  const formatValue = (value: string | number | null) =>
+    value === null ? '(empty)' : String(value);
+  const lines = [
+    `Before: ${labels.before} (${diff.beforeRows} rows)`,
+    `After:  ${labels.after} (${diff.afterRows} rows)`,
+    `Key: ${diff.keyColumns.join(' + ')}; tolerance ${diff.tolerance}${
+      diff.tolerancePercent !== null ? ` and ${diff.tolerancePercent}%` : ''
+    }`,
+    `Added ${diff.added.length}, removed ${diff.removed.length}, changed ${diff.changed.length}, unchanged ${diff.unchanged}`,
+  ];
+
+  if (diff.added.length > 0) {
+    lines.push('', 'Added:');
+    diff.added.forEach(row =>
+      lines.push(`  + ${rowKey(row, diff.keyColumns)}`)
+    );
+  }
+  if (diff.removed.length > 0) {
+    lines.push('', 'Removed:');
+    diff.removed.forEach(row =>
+      lines.push(`  - ${rowKey(row, diff.keyColumns)}`)
+    );
+  }
+  if (diff.changed.length > 0) {
+    lines.push('', 'Changed:');
+    diff.changed.forEach(row => {
+      lines.push(`  ~ ${row.key}`);
+      row.changes.forEach(change => {
+        const delta =
+          change.delta === null
+            ? ''
+            : ` (${change.delta > 0 ? '+' : ''}${change.delta}${
+                change.deltaPercent !== null ? `, ${change.deltaPercent}%` : ''
+              })`;
+        lines.push(
+          `      ${change.column}: ${formatValue(change.before)} -> ${formatValue(change.after)}${delta}`
+        );
+      });
+    });
+  }
+  if (diff.columnsOnlyBefore.length > 0) {
+    lines.push('', `Columns only before: ${diff.columnsOnlyBefore.join(', ')}`);
+  }
+  if (diff.columnsOnlyAfter.length > 0) {
+    lines.push(`Columns only after: ${diff.columnsOnlyAfter.join(', ')}`);
+  }
+  if (diff.duplicateKeys.length > 0) {
+    lines.push(
+      `Duplicate keys (first row compared): ${diff.duplicateKeys.join(', ')}`
+    );
+  }
+  return lines.join('\n');
+}
@@ -0,0 +1,341 @@
+/**
+ * Report Templates Helper
+ *
+ * Saves a configured report (report type, parameters, output format) as a
+ * named template in `reporttemplates` and re-runs it by name, so recurring
+ * reports don't have to be rebuilt by hand each time.
+ *
+ * Used by `/api/reports/templates` and `scripts/report-templates.ts`.
+ *
+ * @module app/api/lib/helpers/reports/reportTemplates
+ */
+
+import {
+  exportLeaderboardToCSV,
+  getLicenceeLeaderboard,
+} from '@/app/api/lib/helpers/reports/licenceeLeaderboard';
+import { getMachineUtilizationReport } from '@/app/api/lib/helpers/reports/machineUtilization';
+import {
+  runQuerySpec,
+  validateQuerySpec,
+} from '@/app/api/lib/helpers/reports/queryBuilder';
+import type { QueryBuilderSpec } from '@/app/api/lib/helpers/reports/queryBuilder';
+import { getShiftReport } from '@/app/api/lib/helpers/reports/shiftReport';
+import { ReportTemplate } from '@/app/api/lib/models/reportTemplate';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import {
+  applyExportProfileToRows,
+  profiledExportToCsv,
+  profiledExportToObjects,
+} from '@/lib/utils/export/profiles';
+import type { ExportProfile } from '@/lib/utils/export/profiles';
+import { generateMongoId } from '@/lib/utils/id';
+import type {
+  FinancialScales,
+  ReportTemplateDocument,
+  ReportTemplateType,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const REPORT_TEMPLATE_TYPES: ReportTemplateType[] = [
+  'query-builder',
+  'licencee-leaderboard',
+  'machine-utilization',
+  'shifts',
+];
+
+export type SaveReportTemplateInput = {
+  name: string;
+  description?: string;
+  reportType: ReportTemplateType;
+  parameters: Record<string, unknown>;
+  outputFormat?: 'json' | 'csv';
+  createdBy?: string | null;
+};
+
+export type RunReportTemplateOptions = {
+  allowedLocationIds: 'all' | string[];
+  scales?: FinancialScales;
+  /** Export profile shaping the rows (columns, labels, number formats) */
+  profile?: ExportProfile;
+};
+
+export type ReportTemplateResult = {
+  template: string;
+  reportType: ReportTemplateType;
+  outputFormat: 'json' | 'csv';
+  rows: Record<string, unknown>[];
+  csv?: string;
+  /** Profile applied to `rows` and `csv`, when one was requested */
+  profile?: string;
+};
+
+const TEMPLATE_NAME_PATTERN = /^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$/;
+
+function readDate(value: unknown): Date | undefined {
+  if (typeof value !== 'string' || !value) return undefined;
+  const date = new Date(value);
+  return Number.isNaN(date.getTime()) ? undefined : date;
+}
+
+function readString(value: unknown): string | undefined {
+  return typeof value === 'string' && value ? value : undefined;
+}
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+/**
+ * Validates a template before it is saved.
+ *
+ * @returns Error message, or null when valid
+ */
+export function validateReportTemplate(
+  input: SaveReportTemplateInput
+): string | null {
+  if (!TEMPLATE_NAME_PATTERN.test(input?.name || '')) {
+    return 'name must be 1-64 letters, digits, "-" or "_"';
+  }
+  if (!REPORT_TEMPLATE_TYPES.includes(input.reportType)) {
+    return `reportType must be one of ${REPORT_TEMPLATE_TYPES.join(', ')}`;
+  }
+  if (
+    input.outputFormat &&
+    input.outputFormat !== 'json' &&
+    input.outputFormat !== 'csv'
+  ) {
+    return 'outputFormat must be json or csv';
+  }
+  const parameters = input.parameters;
+  if (!parameters || typeof parameters !== 'object') {
+    return 'parameters must be an object';
+  }
+
+  switch (input.reportType) {
+    case 'query-builder':
+      return validateQuerySpec(parameters as unknown as QueryBuilderSpec);
+    case 'shifts':
+      return readString(parameters.locationId)
+        ? null
+        : 'shifts templates require parameters.locationId';
+    default:
+      if (
+        parameters.timePeriod === 'Custom' &&
+        (!readDate(parameters.startDate) || !readDate(parameters.endDate))
+      ) {
+        return 'Custom templates require valid startDate and endDate';
+      }
+      return null;
+  }
+}
+
+// ============================================================================
+// Storage
+// ============================================================================
+
+export async function listReportTemplates(): Promise<ReportTemplateDocument[]> {
+  return ReportTemplate.find({})
+    .sort({ name: 1 })
+    .lean<ReportTemplateDocument[]>();
+}
+
+export async function getReportTemplateByName(
+  name: string
+): Promise<ReportTemplateDocument | null> {
+  return ReportTemplate.findOne({ name }).lean<ReportTemplateDocument>();
+}
+
+/**
+ * Creates a template, or replaces the configuration of an existing one with
+ * the same name.
+ *
+ * @throws Error with `statusCode = 400` when the template is invalid
+ */
+export async function saveReportTemplate(
+  input: SaveReportTemplateInput
+): Promise<{ template: ReportTemplateDocument; created: boolean }> {
+  const validationError = validateReportTemplate(input);
+  if (validationError) throw statusError(validationError, 400);
+  assertWritable('saving report templates');
+
+  const existing = await getReportTemplateByName(input.name);
+  const template = await ReportTemplate.findOneAndUpdate(
+    { name: input.name },
+    {
+      $set: {
+        description: input.description || '',
+        reportType: input.reportType,
+        parameters: input.parameters,
+        outputFormat: input.outputFormat || 'json',
+      },
+      $setOnInsert: {
+        _id: await generateMongoId(),
+        createdBy: input.createdBy || null,
+      },
+    },
+    { upsert: true, new: true }
+  ).lean<ReportTemplateDocument>();
+
+  return { template: template as ReportTemplateDocument, created: !existing };
+}
+
+/**
+ * @returns The deleted template, or null when no template has that name
+ */
+export async function deleteReportTemplate(
+  name: string
+): Promise<ReportTemplateDocument | null> {
+  assertWritable('deleting report templates');
+  return ReportTemplate.findOneAndDelete({
+    name,
+  }).lean<ReportTemplateDocument>();
+}
+
+// ============================================================================
+// Running
+// ============================================================================
+
+/**
+ * Serializes flat rows to CSV; nested values are written as JSON.
+ */
+export function rowsToCsv(rows: Record<string, unknown>[]): string {
+  const columns = Array.from(new Set(rows.flatMap(row => Object.keys(row))));
+  const escape = (value: unknown) => {
+    if (value === null || value === undefined) return '';
+    const text =
+      value instanceof Date
+        ? value.toISOString()
+        : typeof value === 'object'
+          ? JSON.stringify(value)
+          : String(value);
+    return /[",\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+  };
+  const lines = rows.map(row => columns.map(key => escape(row[key])).join(','));
+  return [columns.join(','), ...lines].join('\n');
+}
+
+/**
+ * Runs a saved template with the caller's location scope.
+ *
+ * @param template - Saved template
+ * @param options - Accessible locations, reviewer scales and export profile
+ * @param formatOverride - Output format to use instead of the saved one
+ * @throws Error with `statusCode` 400/403/404 for bad parameters or scope
+ */
+export async function runReportTemplate(
+  template: ReportTemplateDocument,
+  options: RunReportTemplateOptions,
+  formatOverride?: 'json' | 'csv'
+): Promise<ReportTemplateResult> {
+  const { allowedLocationIds, scales, profile } = options;
+  const parameters = template.parameters || {};
+  const outputFormat = formatOverride || template.outputFormat || 'json';
+  const timePeriod = readString(parameters.timePeriod) || '7d';
+  const customStartDate = readDate(parameters.startDate);
+  const customEndDate = readDate(parameters.endDate);
+  const locationId = readString(parameters.locationId);
+
+  if (
+    locationId &&
+    allowedLocationIds !== 'all' &&
+    !allowedLocationIds.includes(locationId)
+  ) {
+    throw statusError('Forbidden', 403);
+  }
+
+  let rows: Record<string, unknown>[] = [];
+  let csv: string | undefined;
+
+  switch (template.reportType) {
+    case 'query-builder': {
+      const result = await runQuerySpec(
+        parameters as unknown as QueryBuilderSpec,
+        allowedLocationIds
+      );
+      rows = result.rows;
+      break;
+    }
+    case 'licencee-leaderboard': {
+      const leaderboard = await getLicenceeLeaderboard({
+        allowedLocationIds,
+        timePeriod,
+        customStartDate,
+        customEndDate,
+        scales,
+      });
+      rows = leaderboard;
+      if (outputFormat === 'csv') csv = exportLeaderboardToCSV(leaderboard);
+      break;
+    }
+    case 'machine-utilization': {
+      const scoped = locationId ? [locationId] : allowedLocationIds;
+      const locations =
+        scoped !== 'all' && scoped.length === 0
+          ? []
+          : await getMachineUtilizationReport({
+              allowedLocationIds: scoped,
+              timePeriod,
+              customStartDate,
+              customEndDate,
+              underutilizedRatio:
+                typeof parameters.underutilizedRatio === 'number'
+                  ? parameters.underutilizedRatio
+                  : undefined,
+            });
+      rows = locations.flatMap(location =>
+        location.machines.map(machine => ({
+          locationId: location.locationId,
+          locationName: location.locationName,
+          ...machine,
+        }))
+      );
+      break;
+    }
+    case 'shifts': {
+      const report = await getShiftReport({
+        locationId: locationId as string,
+        range: readString(parameters.range) || 'shift:today',
+        shiftName: readString(parameters.shift),
+        customStartDate,
+        customEndDate,
+        scales,
+      });
+      if (!report) throw statusError('Location not found', 404);
+      rows = report.rows.map(({ movement, ...row }) => ({
+        ...row,
+        ...movement,
+      }));
+      break;
+    }
+  }
+
+  await ReportTemplate.updateOne(
+    { _id: template._id },
+    { $set: { lastRunAt: new Date() } }
+  );
+
+  if (profile) {
+    const profiled = applyExportProfileToRows(rows, profile);
+    return {
+      template: template.name,
+      reportType: template.reportType,
+      outputFormat,
+      rows: profiledExportToObjects(profiled),
+      csv: outputFormat === 'csv' ? profiledExportToCsv(profiled) : undefined,
+      profile: profile.name,
+    };
+  }
+
+  return {
+    template: template.name,
+    reportType: template.reportType,
+    outputFormat,
+    rows,
+    csv: outputFormat === 'csv' ? csv || rowsToCsv(rows) : undefined,
+  };
+}
@@ -0,0 +1,276 @@
+/**
+ * SAS Alerts Report Helper
+ *
+ * Counts machine events by SAS exception code over a date range, keeping
+ * codes at or above a severity (default warning), and ranks the machines
+ * raising them — critical first — so the floor team sees tilts, door
+ * openings and hardware faults by name instead of raw codes. Names and
+ * severities come from the SAS code dictionary (app/api/lib/utils/sasCodes).
+ *
+ * @module app/api/lib/helpers/reports/sasAlerts
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { MachineEvent } from '@/app/api/lib/models/machineEvents';
+import { Machine } from '@/app/api/lib/models/machines';
+import { getSasCodeDictionary } from '@/app/api/lib/utils/sasCodes';
+import {
+  decodeSasException,
+  sasCodesAtSeverity,
+  type SasSeverity,
+} from '@/lib/utils/sas/exceptionCodes';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DEFAULT_SAS_ALERT_HOURS = 24;
+export const MAX_SAS_ALERT_MACHINES = 200;
+
+export type SasAlertCode = {
+  code: string;
+  name: string;
+  severity: SasSeverity;
+  count: number;
+  machines: number;
+  lastSeen: Date;
+};
+
+export type SasAlertMachine = {
+  machineId: string;
+  serialNumber: string;
+  locationId: string;
+  locationName: string;
+  critical: number;
+  warning: number;
+  info: number;
+  total: number;
+  /** Most frequent code on this machine */
+  topCode: string;
+  topCodeName: string;
+  lastSeen: Date;
+};
+
+export type SasAlertsReport = {
+  generatedAt: Date;
+  from: Date;
+  to: Date;
+  minSeverity: SasSeverity;
+  totals: Record<SasSeverity, number> & { events: number };
+  codes: SasAlertCode[];
+  machines: SasAlertMachine[];
+};
+
+export type SasAlertsParams = {
+  allowedLocationIds: 'all' | string[];
+  from: Date;
+  to: Date;
+  minSeverity?: SasSeverity;
+  /** Only this machine */
+  machineId?: string;
+};
+
+type CodeMachineGroup = {
+  _id: { command: string; machine: string };
+  count: number;
+  lastSeen: Date;
+  location: string | null;
+};
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Spellings a code may be stored under on events ('0x1A', '1a', ...).
+ */
+export function codeSpellings(code: string): string[] {
+  return [code, code.toLowerCase()].flatMap(value => [value, `0x${value}`]);
+}
+
+/**
+ * Builds the SAS alerts report.
+ *
+ * @param params - Accessible locations, date range and minimum severity
+ */
+export async function getSasAlertsReport(
+  params: SasAlertsParams
+): Promise<SasAlertsReport> {
+  const minSeverity = params.minSeverity || 'warning';
+  const dictionary = getSasCodeDictionary();
+  const codes = sasCodesAtSeverity(minSeverity, dictionary);
+
+  // Step 1: Count events per code and machine
+  const match: Record<string, unknown> = {
+    date: { $gte: params.from, $lte: params.to },
+    command: { $in: codes.flatMap(codeSpellings) },
+  };
+  if (params.allowedLocationIds !== 'all') {
+    match.location = { $in: params.allowedLocationIds };
+  }
+  if (params.machineId) match.machine = params.machineId;
+
+  const groups = await MachineEvent.aggregate<CodeMachineGroup>([
+    { $match: match },
+    {
+      $group: {
+        _id: { command: '$command', machine: '$machine' },
+        count: { $sum: 1 },
+        lastSeen: { $max: '$date' },
+        location: { $last: '$location' },
+      },
+    },
+  ]);
+
+  // Step 2: Roll up by code and by machine
+  const byCode = new Map<string, SasAlertCode & { machineIds: Set<string> }>();
+  const byMachine = new Map<
+    string,
+    Omit<SasAlertMachine, 'serialNumber' | 'locationName' | 'topCodeName'> & {
+      codeCounts: Map<string, number>;
+    }
+  >();
+  const totals = { events: 0, info: 0, warning: 0, critical: 0 };
+
+  groups.forEach(group => {
+    const decoded = decodeSasException(group._id.command, dictionary);
+    if (!decoded) return;
+    totals.events += group.count;
+    totals[decoded.severity] += group.count;
+
+    const codeEntry = byCode.get(decoded.code) || {
+      code: decoded.code,
+      name: decoded.name,
+      severity: decoded.severity,
+      count: 0,
+      machines: 0,
+      lastSeen: group.lastSeen,
+      machineIds: new Set<string>(),
+    };
+    codeEntry.count += group.count;
+    codeEntry.machineIds.add(group._id.machine);
+    if (group.lastSeen > codeEntry.lastSeen) {
+      codeEntry.lastSeen = group.lastSeen;
+    }
+    byCode.set(decoded.code, codeEntry);
+
+    const machineEntry = byMachine.get(group._id.machine) || {
+      machineId: group._id.machine,
+      locationId: group.location || '',
+      critical: 0,
+      warning: 0,
+      info: 0,
+      total: 0,
+      topCode: decoded.code,
+      lastSeen: group.lastSeen,
+      codeCounts: new Map<string, number>(),
+    };
+    machineEntry[decoded.severity] += group.count;
+    machineEntry.total += group.count;
+    machineEntry.codeCounts.set(
+      decoded.code,
+      (machineEntry.codeCounts.get(decoded.code) || 0) + group.count
+    );
+    if (group.lastSeen > machineEntry.lastSeen) {
+      machineEntry.lastSeen = group.lastSeen;
+    }
+    byMachine.set(group._id.machine, machineEntry);
+  });
+
+  // Step 3: Rank machines and resolve serials and location names
+  const rankedMachines = Array.from(byMachine.values())
+    .sort(
+      (a, b) =>
+        b.critical - a.critical || b.warning - a.warning || b.total - a.total
+    )
+    .slice(0, MAX_SAS_ALERT_MACHINES);
+
+  const machineDocs = await Machine.find(
+    { _id: { $in: rankedMachines.map(machine => machine.machineId) } },
+    { serialNumber: 1, gamingLocation: 1 }
+  ).lean<
+    Array<{ _id: string; serialNumber?: string; gamingLocation?: string }>
+  >();
+  const machineById = new Map(
+    machineDocs.map(machine => [String(machine._id), machine])
+  );
+  const locationIds = new Set(
+    rankedMachines.map(
+      machine =>
+        machine.locationId ||
+        machineById.get(machine.machineId)?.gamingLocation ||
+        ''
+    )
+  );
+  const locations = await GamingLocations.find(
+    { _id: { $in: Array.from(locationIds).filter(Boolean) } },
+    { name: 1 }
+  ).lean<Array<{ _id: string; name?: string }>>();
+  const locationNames = new Map(
+    locations.map(location => [String(location._id), location.name || ''])
+  );
+
+  const machines: SasAlertMachine[] = rankedMachines.map(machine => {
+    const { codeCounts, ...counts } = machine;
+    const topCode = Array.from(codeCounts.entries()).sort(
+      (a, b) => b[1] - a[1]
+    )[0][0];
+    const doc = machineById.get(machine.machineId);
+    const locationId = machine.locationId || doc?.gamingLocation || '';
+    return {
+      ...counts,
+      serialNumber: doc?.serialNumber || machine.machineId,
+      locationId,
+      locationName: locationNames.get(locationId) || locationId,
+      topCode,
+      topCodeName: byCode.get(topCode)?.name || topCode,
+    };
+  });
+
+  return {
+    generatedAt: new Date(),
+    from: params.from,
+    to: params.to,
+    minSeverity,
+    totals,
+    codes: Array.from(byCode.values())
+      .map(({ machineIds, ...code }) => ({
+        ...code,
+        machines: machineIds.size,
+      }))
+      .sort((a, b) => b.count - a.count),
+    machines,
+  };
+}
+
+/**
+ * Converts the machine ranking to CSV.
+ */
+export function exportSasAlertsToCSV(report: SasAlertsReport): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const header = [
+    'Location',
+    'Serial Number',
+    'Critical',
+    'Warning',
+    'Info',
+    'Total',
+    'Top Code',
+    'Top Event',
+    'Last Seen',
+  ];
+  const lines = report.machines.map(machine =>
+    [
+      quote(machine.locationName),
+      quote(machine.serialNumber),
+      machine.critical,
+      machine.warning,
+      machine.info,
+      machine.total,
+      machine.topCode,
+      quote(machine.topCodeName),
+      new Date(machine.lastSeen).toISOString(),
+    ].join(',')
+  );
+  return [header.join(','), ...lines].join('\n');
+}
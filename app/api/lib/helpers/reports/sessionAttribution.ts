@@ -0,0 +1,379 @@
+/**
+ * Session Attribution Report Helper
+ *
+ * Attributes machine meter movement to members for carded sessions: every
+ * meter reading on the session's machine after the session started and up
+ * to when it ended (now, for open sessions) counts towards the member. Per
+ * member the report gives handle (coin in), coin out, drop, actual win
+ * (`coinIn - coinOut`, house perspective) and theoretical win
+ * (`coinIn × (1 - theoreticalRtp)` using each machine's game configuration),
+ * for loyalty program tiering. Drop is the licencee's Money In (see
+ * financialFormulas).
+ *
+ * Machines without a usable `gameConfig.theoreticalRtp` use the default RTP
+ * passed in (90% unless set); those sessions are counted per member. RTPs
+ * stored as percentages (e.g. 92) are read as 0.92.
+ *
+ * @module app/api/lib/helpers/reports/sessionAttribution
+ */
+
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { Machine } from '@/app/api/lib/models/machines';
+import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import { Member } from '@/app/api/lib/models/members';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import type { MovementTotals } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DEFAULT_THEORETICAL_RTP = 0.9;
+export const DEFAULT_ATTRIBUTION_DAYS = 30;
+
+export type MemberAttribution = {
+  memberId: string;
+  memberName: string;
+  username: string;
+  locationId: string;
+  sessions: number;
+  /** Sessions on machines without an RTP, valued at the default RTP */
+  sessionsAtDefaultRtp: number;
+  playMinutes: number;
+  gamesPlayed: number;
+  coinIn: number;
+  coinOut: number;
+  drop: number;
+  jackpot: number;
+  actualWin: number;
+  theoreticalWin: number;
+  averageBet: number;
+  lastPlayed: Date | null;
+};
+
+export type SessionAttributionReport = {
+  generatedAt: Date;
+  from: Date;
+  to: Date;
+  defaultRtp: number;
+  sessions: number;
+  members: MemberAttribution[];
+};
+
+export type SessionAttributionParams = {
+  allowedLocationIds: 'all' | string[];
+  /** Sessions starting in this range */
+  from: Date;
+  to: Date;
+  defaultRtp?: number;
+  /** Only this member */
+  memberId?: string;
+};
+
+export type AttributedSession = {
+  memberId: string;
+  machineId: string;
+  startTime: Date;
+  endTime?: Date | null;
+  movement?: (MovementTotals & { gamesPlayed: number }) | null;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/**
+ * Reads a machine's RTP as a fraction, or null when it is not usable.
+ */
+export function normalizeRtp(value: unknown): number | null {
+  const rtp = Number(value);
+  if (!Number.isFinite(rtp) || rtp <= 0) return null;
+  const fraction = rtp > 1 ? rtp / 100 : rtp;
+  return fraction < 1 ? fraction : null;
+}
+
+/**
+ * Streams carded sessions starting in a range, each with the sum of the
+ * meter movement on its machine during the session. Shared with the top
+ * members report.
+ *
+ * @param options.machineIds - Only sessions on these machines (null: all)
+ * @param options.now - End of the window for open sessions
+ */
+export function streamAttributedSessions(options: {
+  machineIds: string[] | null;
+  from: Date;
+  to: Date;
+  memberId?: string;
+  now?: Date;
+}): AsyncIterable<AttributedSession> {
+  const now = options.now || new Date();
+  const sessionMatch: Record<string, unknown> = {
+    startTime: { $gte: options.from, $lte: options.to },
+    memberId: options.memberId || { $nin: [null, '', 'ANONYMOUS'] },
+  };
+  if (options.machineIds) {
+    sessionMatch.machineId = { $in: options.machineIds };
+  }
+  return MachineSession.aggregate<AttributedSession>([
+    { $match: sessionMatch },
+    {
+      $lookup: {
+        from: Meters.collection.name,
+        let: {
+          machine: '$machineId',
+          start: '$startTime',
+          end: { $ifNull: ['$endTime', now] },
+        },
+        pipeline: [
+          {
+            $match: {
+              $expr: {
+                $and: [
+                  { $eq: ['$machine', '$$machine'] },
+                  { $gt: ['$readAt', '$$start'] },
+                  { $lte: ['$readAt', '$$end'] },
+                ],
+              },
+            },
+          },
+          {
+            $group: {
+              _id: null,
+              ...buildMovementTotalsGroup(),
+              gamesPlayed: {
+                $sum: { $ifNull: ['$movement.gamesPlayed', 0] },
+              },
+            },
+          },
+        ],
+        as: 'movement',
+      },
+    },
+    {
+      $project: {
+        memberId: 1,
+        machineId: 1,
+        startTime: 1,
+        endTime: 1,
+        movement: { $first: '$movement' },
+      },
+    },
+  ]).cursor({ batchSize: 1000 });
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the per-member attribution for carded sessions in a range.
+ *
+ * @param params - Accessible locations, date range and default RTP
+ * @returns Members ordered by theoretical win (descending)
+ */
+export async function getSessionAttributionReport(
+  params: SessionAttributionParams
+): Promise<SessionAttributionReport> {
+  const defaultRtp = params.defaultRtp ?? DEFAULT_THEORETICAL_RTP;
+  const now = new Date();
+
+  // Step 1: Machines in scope, with their RTP and location formula
+  const machineQuery: Record<string, unknown> = {};
+  if (params.allowedLocationIds !== 'all') {
+    machineQuery.gamingLocation = { $in: params.allowedLocationIds };
+  }
+  const machines = await Machine.find(machineQuery, {
+    _id: 1,
+    gamingLocation: 1,
+    'gameConfig.theoreticalRtp': 1,
+  }).lean<
+    Array<{
+      _id: string;
+      gamingLocation?: string;
+      gameConfig?: { theoreticalRtp?: number };
+    }>
+  >();
+  const rtpByMachine = new Map(
+    machines.map(machine => [
+      String(machine._id),
+      normalizeRtp(machine.gameConfig?.theoreticalRtp),
+    ])
+  );
+  const locationByMachine = new Map(
+    machines.map(machine => [
+      String(machine._id),
+      String(machine.gamingLocation || ''),
+    ])
+  );
+  const formulas = await getLocationFinancialFormulas(
+    Array.from(new Set(locationByMachine.values())).filter(Boolean)
+  );
+
+  // Step 2: Carded sessions with the meter movement in their window
+  const cursor = streamAttributedSessions({
+    machineIds:
+      params.allowedLocationIds === 'all'
+        ? null
+        : Array.from(rtpByMachine.keys()),
+    from: params.from,
+    to: params.to,
+    memberId: params.memberId,
+    now,
+  });
+
+  // Step 3: Accumulate per member
+  const byMember = new Map<string, MemberAttribution>();
+  let sessionCount = 0;
+  for await (const session of cursor) {
+    sessionCount++;
+    const memberId = String(session.memberId);
+    const entry = byMember.get(memberId) || {
+      memberId,
+      memberName: memberId,
+      username: '',
+      locationId: '',
+      sessions: 0,
+      sessionsAtDefaultRtp: 0,
+      playMinutes: 0,
+      gamesPlayed: 0,
+      coinIn: 0,
+      coinOut: 0,
+      drop: 0,
+      jackpot: 0,
+      actualWin: 0,
+      theoreticalWin: 0,
+      averageBet: 0,
+      lastPlayed: null,
+    };
+    const movement = session.movement;
+    const machineRtp = rtpByMachine.get(String(session.machineId));
+    const rtp = machineRtp ?? defaultRtp;
+    const end = session.endTime ? new Date(session.endTime) : now;
+    const start = new Date(session.startTime);
+
+    entry.sessions++;
+    if (machineRtp === null || machineRtp === undefined) {
+      entry.sessionsAtDefaultRtp++;
+    }
+    entry.playMinutes += Math.max(0, end.getTime() - start.getTime()) / 60000;
+    if (movement) {
+      const metrics = calculateFinancialMetrics(
+        movement,
+        formulas.get(locationByMachine.get(String(session.machineId)) || '') ||
+          DEFAULT_FINANCIAL_FORMULA
+      );
+      const coinIn = movement.coinIn || 0;
+      entry.gamesPlayed += movement.gamesPlayed || 0;
+      entry.coinIn += coinIn;
+      entry.coinOut += movement.coinOut || 0;
+      entry.drop += metrics.moneyIn;
+      entry.jackpot += metrics.jackpot;
+      entry.theoreticalWin += coinIn * (1 - rtp);
+    }
+    if (!entry.lastPlayed || end > entry.lastPlayed) entry.lastPlayed = end;
+    byMember.set(memberId, entry);
+  }
+
+  // Step 4: Member names and derived figures
+  const memberDocs = await Member.find(
+    { _id: { $in: Array.from(byMember.keys()) } },
+    {
+      _id: 1,
+      username: 1,
+      gamingLocation: 1,
+      'profile.firstName': 1,
+      'profile.lastName': 1,
+    }
+  ).lean<
+    Array<{
+      _id: string;
+      username?: string;
+      gamingLocation?: string;
+      profile?: { firstName?: string; lastName?: string };
+    }>
+  >();
+  memberDocs.forEach(member => {
+    const entry = byMember.get(String(member._id));
+    if (!entry) return;
+    const name = `${member.profile?.firstName || ''} ${
+      member.profile?.lastName || ''
+    }`.trim();
+    entry.memberName = name || member.username || entry.memberId;
+    entry.username = member.username || '';
+    entry.locationId = member.gamingLocation || '';
+  });
+
+  const members = Array.from(byMember.values())
+    .map(entry => ({
+      ...entry,
+      playMinutes: Math.round(entry.playMinutes),
+      actualWin: entry.coinIn - entry.coinOut,
+      theoreticalWin: Math.round(entry.theoreticalWin * 100) / 100,
+      averageBet:
+        entry.gamesPlayed > 0
+          ? Math.round((entry.coinIn / entry.gamesPlayed) * 100) / 100
+          : 0,
+    }))
+    .sort((a, b) => b.theoreticalWin - a.theoreticalWin);
+
+  return {
+    generatedAt: new Date(),
+    from: params.from,
+    to: params.to,
+    defaultRtp,
+    sessions: sessionCount,
+    members,
+  };
+}
+
+/**
+ * Converts the member attribution to CSV.
+ */
+export function exportSessionAttributionToCSV(
+  report: SessionAttributionReport
+): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const header = [
+    'Member ID',
+    'Member',
+    'Username',
+    'Sessions',
+    'Sessions At Default RTP',
+    'Play Minutes',
+    'Games Played',
+    'Coin In',
+    'Coin Out',
+    'Drop',
+    'Jackpot',
+    'Actual Win',
+    'Theoretical Win',
+    'Average Bet',
+    'Last Played',
+  ];
+  const lines = report.members.map(member =>
+    [
+      member.memberId,
+      quote(member.memberName),
+      quote(member.username),
+      member.sessions,
+      member.sessionsAtDefaultRtp,
+      member.playMinutes,
+      member.gamesPlayed,
+      member.coinIn.toFixed(2),
+      member.coinOut.toFixed(2),
+      member.drop.toFixed(2),
+      member.jackpot.toFixed(2),
+      member.actualWin.toFixed(2),
+      member.theoreticalWin.toFixed(2),
+      member.averageBet.toFixed(2),
+      member.lastPlayed ? new Date(member.lastPlayed).toISOString() : '',
+    ].join(',')
+  );
+  return [header.join(','), ...lines].join('\n');
+}
@@ -0,0 +1,184 @@
+/**
+ * Shift Report Helper
+ *
+ * Aggregates meter movements per shift per day for a location, for finance
+ * reconciliation by shift. Shift definitions come from the location's
+ * `shifts` field and fall back to 6am–2pm / 2pm–10pm / 10pm–6am.
+ *
+ * @module app/api/lib/helpers/reports/shiftReport
+ */
+
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import { DEFAULT_SHIFTS, resolveShiftWindows } from '@/lib/utils/shiftRange';
+import type { ShiftRangeOptions } from '@/lib/utils/shiftRange';
+import type {
+  FinancialScales,
+  GamingLocationDocument,
+  LocationShift,
+  MovementTotals,
+  ShiftWindow,
+} from '@shared/types';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type ShiftReportRow = {
+  shiftDay: string;
+  shift: string;
+  start: Date;
+  end: Date;
+  inProgress: boolean;
+  movement: MovementTotals;
+  gamesPlayed: number;
+  moneyIn: number;
+  moneyOut: number;
+  gross: number;
+};
+
+export type ShiftReport = {
+  locationId: string;
+  locationName: string;
+  shifts: LocationShift[];
+  rows: ShiftReportRow[];
+};
+
+export type ShiftReportParams = ShiftRangeOptions & {
+  locationId: string;
+  range: string;
+  shiftName?: string;
+  scales?: FinancialScales;
+};
+
+type HourlyBucket = { _id: string; [field: string]: string | number };
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the shift report for one location.
+ *
+ * @param params - Location, range (`shift:` syntax or time period) and options
+ * @returns Report rows ordered by shift start, or null when the location does not exist
+ */
+export async function getShiftReport(
+  params: ShiftReportParams
+): Promise<ShiftReport | null> {
+  const { locationId, range, shiftName, scales, ...rangeOptions } = params;
+
+  // Step 1: Location and its shift definitions
+  const location = await GamingLocations.findOne(
+    { _id: locationId },
+    { _id: 1, name: 1, shifts: 1, 'rel.licencee': 1 }
+  ).lean<GamingLocationDocument>();
+  if (!location) return null;
+
+  const shifts =
+    location.shifts && location.shifts.length > 0
+      ? location.shifts
+      : DEFAULT_SHIFTS;
+  let windows: ShiftWindow[];
+  try {
+    windows = resolveShiftWindows(range, shifts, rangeOptions).filter(
+      window => !shiftName || window.shift === shiftName
+    );
+  } catch (rangeError) {
+    throw statusError(
+      rangeError instanceof Error ? rangeError.message : 'Invalid range',
+      400
+    );
+  }
+  if (windows.length === 0) {
+    return {
+      locationId,
+      locationName: location.name,
+      shifts,
+      rows: [],
+    };
+  }
+
+  // Step 2: Hourly movement totals across the whole range
+  const rangeStart = windows[0].start;
+  const rangeEnd = windows.reduce(
+    (latest, window) => (window.end > latest ? window.end : latest),
+    windows[0].end
+  );
+
+  const hourlyTotals = new Map<number, HourlyBucket>();
+  const cursor = Meters.aggregate<HourlyBucket>([
+    {
+      $match: {
+        location: locationId,
+        readAt: { $gte: rangeStart, $lt: rangeEnd },
+      },
+    },
+    {
+      $group: {
+        _id: {
+          $dateToString: {
+            date: '$readAt',
+            format: '%Y-%m-%dT%H',
+            timezone: 'UTC',
+          },
+        },
+        ...buildMovementTotalsGroup(),
+        gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
+      },
+    },
+  ]).cursor({ batchSize: 1000 });
+  for await (const bucket of cursor) {
+    hourlyTotals.set(Date.parse(`${bucket._id}:00:00Z`), bucket);
+  }
+
+  // Step 3: Roll hourly buckets into shift windows
+  const licenceeId = location.rel?.licencee;
+  const formula = licenceeId
+    ? (await getLicenceeFinancialFormulas([licenceeId])).get(licenceeId) ||
+      DEFAULT_FINANCIAL_FORMULA
+    : DEFAULT_FINANCIAL_FORMULA;
+  const now = new Date();
+
+  const rows = windows.map(window => {
+    const movement: MovementTotals = {};
+    let gamesPlayed = 0;
+    hourlyTotals.forEach((bucket, hourStart) => {
+      if (hourStart < window.start.getTime() || hourStart >= window.end.getTime())
+        return;
+      METER_MOVEMENT_FIELDS.forEach(field => {
+        movement[field] = (movement[field] || 0) + (Number(bucket[field]) || 0);
+      });
+      gamesPlayed += Number(bucket.gamesPlayed) || 0;
+    });
+
+    const metrics = calculateFinancialMetrics(movement, formula, scales);
+    return {
+      shiftDay: window.shiftDay,
+      shift: window.shift,
+      start: window.start,
+      end: window.end,
+      inProgress: window.start <= now && now < window.end,
+      movement,
+      gamesPlayed,
+      moneyIn: metrics.moneyIn,
+      moneyOut: metrics.moneyOut,
+      gross: metrics.gross,
+    };
+  });
+
+  return {
+    locationId,
+    locationName: location.name,
+    shifts,
+    rows,
+  };
+}
@@ -0,0 +1,382 @@
+/**
+ * SMIB Firmware Inventory Helper
+ *
+ * Groups SMIB-equipped machines (those with a `relayId`) by the firmware
+ * they report in `smibVersion`, per licencee and location, and flags machines
+ * running a version below the configured minimum — or reporting none — so the
+ * field team gets a list of SMIBs to update.
+ *
+ * The minimum comes from the `minimumVersion` parameter, falling back to the
+ * `SMIB_MIN_FIRMWARE_VERSION` environment variable. Without either, only
+ * machines with an unknown version are flagged.
+ *
+ * @module app/api/lib/helpers/reports/smibFirmware
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type FirmwareUpdateReason = 'below-minimum' | 'unknown';
+
+export type FirmwareVersionCount = {
+  version: string;
+  count: number;
+  belowMinimum: boolean;
+};
+
+export type FirmwareLocationGroup = {
+  locationId: string;
+  locationName: string;
+  machineCount: number;
+  needingUpdate: number;
+  versions: FirmwareVersionCount[];
+};
+
+export type FirmwareLicenceeGroup = {
+  licenceeId: string;
+  licenceeName: string;
+  machineCount: number;
+  needingUpdate: number;
+  versions: FirmwareVersionCount[];
+  locations: FirmwareLocationGroup[];
+};
+
+export type FirmwareUpdateMachine = {
+  machineId: string;
+  serialNumber: string;
+  smibId: string;
+  firmware: string;
+  reason: FirmwareUpdateReason;
+  licenceeId: string;
+  licenceeName: string;
+  locationId: string;
+  locationName: string;
+  lastActivity: Date | null;
+};
+
+export type SmibFirmwareReport = {
+  minimumVersion: string | null;
+  machineCount: number;
+  needingUpdate: number;
+  licencees: FirmwareLicenceeGroup[];
+  machinesNeedingUpdate: FirmwareUpdateMachine[];
+};
+
+export type SmibFirmwareParams = {
+  allowedLocationIds: 'all' | string[];
+  minimumVersion?: string | null;
+};
+
+type FirmwareLocation = {
+  _id: string;
+  name?: string;
+  rel?: { licencee?: string };
+};
+
+type FirmwareMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  relayId?: string;
+  gamingLocation: string;
+  lastActivity?: Date;
+  smibVersion?: { firmware?: string; version?: string };
+};
+
+/** Label used when a machine reports no firmware version */
+export const UNKNOWN_FIRMWARE = 'unknown';
+
+// ============================================================================
+// Version comparison
+// ============================================================================
+
+/**
+ * Numeric parts of a version string (`v1.4.10-beta` → `[1, 4, 10]`).
+ */
+function parseVersionParts(version: string): number[] {
+  return (version.match(/\d+/g) || []).map(Number);
+}
+
+/**
+ * Compares two firmware versions part by part; missing parts count as 0.
+ *
+ * @returns Negative when `a < b`, positive when `a > b`, 0 when equal
+ */
+export function compareFirmwareVersions(a: string, b: string): number {
+  const partsA = parseVersionParts(a);
+  const partsB = parseVersionParts(b);
+  for (let i = 0; i < Math.max(partsA.length, partsB.length); i++) {
+    const difference = (partsA[i] || 0) - (partsB[i] || 0);
+    if (difference !== 0) return difference;
+  }
+  return 0;
+}
+
+/**
+ * Resolves the minimum firmware version: explicit value, then
+ * `SMIB_MIN_FIRMWARE_VERSION`.
+ */
+export function getMinimumFirmwareVersion(
+  override?: string | null
+): string | null {
+  const value = override?.trim() || process.env.SMIB_MIN_FIRMWARE_VERSION;
+  return value?.trim() || null;
+}
+
+function getFirmwareVersion(machine: FirmwareMachine): string {
+  const firmware =
+    machine.smibVersion?.firmware?.trim() ||
+    machine.smibVersion?.version?.trim();
+  return firmware && parseVersionParts(firmware).length > 0
+    ? firmware
+    : UNKNOWN_FIRMWARE;
+}
+
+function getUpdateReason(
+  version: string,
+  minimumVersion: string | null
+): FirmwareUpdateReason | null {
+  if (version === UNKNOWN_FIRMWARE) return 'unknown';
+  if (minimumVersion && compareFirmwareVersions(version, minimumVersion) < 0) {
+    return 'below-minimum';
+  }
+  return null;
+}
+
+function toVersionCounts(
+  counts: Map<string, number>,
+  minimumVersion: string | null
+): FirmwareVersionCount[] {
+  return Array.from(counts.entries())
+    .map(([version, count]) => ({
+      version,
+      count,
+      belowMinimum: getUpdateReason(version, minimumVersion) !== null,
+    }))
+    .sort((a, b) =>
+      a.version === UNKNOWN_FIRMWARE
+        ? 1
+        : b.version === UNKNOWN_FIRMWARE
+          ? -1
+          : compareFirmwareVersions(b.version, a.version)
+    );
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the firmware inventory for the locations in scope.
+ *
+ * @param params - Location scope and minimum firmware version
+ * @returns Version counts per licencee and location, plus the machines to update
+ */
+export async function getSmibFirmwareReport(
+  params: SmibFirmwareParams
+): Promise<SmibFirmwareReport> {
+  const minimumVersion = getMinimumFirmwareVersion(params.minimumVersion);
+
+  // Step 1: Locations and SMIB machines in scope
+  const softDeleteFilter = { ...NOT_DELETED_FILTER };
+  const locationQuery: Record<string, unknown> = { ...softDeleteFilter };
+  if (params.allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: params.allowedLocationIds };
+  }
+
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    'rel.licencee': 1,
+  }).lean<FirmwareLocation[]>();
+
+  const machines =
+    locations.length === 0
+      ? []
+      : await Machine.find(
+          {
+            gamingLocation: {
+              $in: locations.map(location => String(location._id)),
+            },
+            relayId: { $nin: [null, ''] },
+            ...softDeleteFilter,
+          },
+          {
+            _id: 1,
+            serialNumber: 1,
+            origSerialNumber: 1,
+            'custom.name': 1,
+            relayId: 1,
+            gamingLocation: 1,
+            lastActivity: 1,
+            smibVersion: 1,
+          }
+        ).lean<FirmwareMachine[]>();
+
+  const licenceeIds = Array.from(
+    new Set(
+      locations
+        .map(location => location.rel?.licencee)
+        .filter((id): id is string => Boolean(id))
+    )
+  );
+  const licencees =
+    licenceeIds.length === 0
+      ? []
+      : await Licencee.find(
+          { _id: { $in: licenceeIds } },
+          { _id: 1, name: 1 }
+        ).lean<Array<{ _id: string; name?: string }>>();
+  const licenceeNames = new Map(
+    licencees.map(licencee => [String(licencee._id), licencee.name || ''])
+  );
+  const locationsById = new Map(
+    locations.map(location => [String(location._id), location])
+  );
+
+  // Step 2: Count versions per licencee and location
+  const licenceeGroups = new Map<
+    string,
+    {
+      group: FirmwareLicenceeGroup;
+      versions: Map<string, number>;
+      locations: Map<
+        string,
+        { group: FirmwareLocationGroup; versions: Map<string, number> }
+      >;
+    }
+  >();
+  const machinesNeedingUpdate: FirmwareUpdateMachine[] = [];
+
+  machines.forEach(machine => {
+    const locationId = String(machine.gamingLocation);
+    const location = locationsById.get(locationId);
+    const licenceeId = location?.rel?.licencee || '';
+    const licenceeName = licenceeNames.get(licenceeId) || licenceeId;
+    const locationName = location?.name || locationId;
+    const version = getFirmwareVersion(machine);
+    const reason = getUpdateReason(version, minimumVersion);
+
+    let licenceeEntry = licenceeGroups.get(licenceeId);
+    if (!licenceeEntry) {
+      licenceeEntry = {
+        group: {
+          licenceeId,
+          licenceeName,
+          machineCount: 0,
+          needingUpdate: 0,
+          versions: [],
+          locations: [],
+        },
+        versions: new Map(),
+        locations: new Map(),
+      };
+      licenceeGroups.set(licenceeId, licenceeEntry);
+    }
+    let locationEntry = licenceeEntry.locations.get(locationId);
+    if (!locationEntry) {
+      locationEntry = {
+        group: {
+          locationId,
+          locationName,
+          machineCount: 0,
+          needingUpdate: 0,
+          versions: [],
+        },
+        versions: new Map(),
+      };
+      licenceeEntry.locations.set(locationId, locationEntry);
+    }
+
+    [licenceeEntry, locationEntry].forEach(entry => {
+      entry.group.machineCount++;
+      if (reason) entry.group.needingUpdate++;
+      entry.versions.set(version, (entry.versions.get(version) || 0) + 1);
+    });
+
+    if (reason) {
+      machinesNeedingUpdate.push({
+        machineId: String(machine._id),
+        serialNumber:
+          machine.serialNumber?.trim() ||
+          machine.origSerialNumber?.trim() ||
+          machine.custom?.name ||
+          String(machine._id),
+        smibId: machine.relayId || '',
+        firmware: version,
+        reason,
+        licenceeId,
+        licenceeName,
+        locationId,
+        locationName,
+        lastActivity: machine.lastActivity || null,
+      });
+    }
+  });
+
+  // Step 3: Assemble, licencees and locations by name
+  const groups = Array.from(licenceeGroups.values())
+    .map(entry => ({
+      ...entry.group,
+      versions: toVersionCounts(entry.versions, minimumVersion),
+      locations: Array.from(entry.locations.values())
+        .map(locationEntry => ({
+          ...locationEntry.group,
+          versions: toVersionCounts(locationEntry.versions, minimumVersion),
+        }))
+        .sort((a, b) => a.locationName.localeCompare(b.locationName)),
+    }))
+    .sort((a, b) => a.licenceeName.localeCompare(b.licenceeName));
+
+  machinesNeedingUpdate.sort(
+    (a, b) =>
+      a.licenceeName.localeCompare(b.licenceeName) ||
+      a.locationName.localeCompare(b.locationName) ||
+      a.serialNumber.localeCompare(b.serialNumber)
+  );
+
+  return {
+    minimumVersion,
+    machineCount: machines.length,
+    needingUpdate: machinesNeedingUpdate.length,
+    licencees: groups,
+    machinesNeedingUpdate,
+  };
+}
+
+/**
+ * Exports the machines needing a firmware update as CSV for the field team.
+ */
+export function exportFirmwareUpdatesToCSV(
+  machines: FirmwareUpdateMachine[]
+): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const header = [
+    'Licencee',
+    'Location',
+    'Serial Number',
+    'SMIB ID',
+    'Firmware',
+    'Reason',
+    'Last Activity',
+  ];
+  const lines = machines.map(machine =>
+    [
+      quote(machine.licenceeName),
+      quote(machine.locationName),
+      quote(machine.serialNumber),
+      quote(machine.smibId),
+      quote(machine.firmware),
+      machine.reason,
+      machine.lastActivity ? new Date(machine.lastActivity).toISOString() : '',
+    ].join(',')
+  );
+  return [header.join(','), ...lines].join('\n');
+}
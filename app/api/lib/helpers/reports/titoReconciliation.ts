@@ -0,0 +1,393 @@
+/**
+ * TITO Reconciliation Report Helper
+ *
+ * Reconciles ticket-in / ticket-out meter movement against cancelled credits
+ * and drop per location per gaming day, for machines that report TITO
+ * meters (`movement.ticketIn` / `movement.ticketOut`), so the cage team can
+ * chase tickets that do not balance:
+ *
+ * - Out: tickets printed should equal cancelled credits not paid by hand,
+ *   `outVariance = ticketOut - (cancelled - handPaidCancelled)`
+ * - In: tickets redeemed are part of drop, so `inExcess = ticketIn - drop`
+ *   should never be positive
+ *
+ * A machine-day is flagged when |outVariance| or inExcess is above the
+ * threshold (default 1.00, in meter units); location-days list their flagged
+ * machines. Readings without TITO meters are left out. Drop and cancelled
+ * credits are the licencee's Money In/Out (see financialFormulas), without
+ * the jackpot share since jackpots are never paid by ticket. Readings are
+ * aggregated once per location through the shared fan-out pool (see
+ * aggregationFanOut).
+ *
+ * @module app/api/lib/helpers/reports/titoReconciliation
+ */
+
+import { runPooled } from '@/app/api/lib/helpers/aggregationFanOut';
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { FinancialFormula, MovementTotals } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DEFAULT_TITO_THRESHOLD = 1;
+
+/** UTC offset used for gaming days (matches gamingDayRange) */
+const TIMEZONE_OFFSET_HOURS = -4;
+const HOUR_MS = 60 * 60 * 1000;
+
+export type TitoTotals = {
+  ticketIn: number;
+  ticketOut: number;
+  drop: number;
+  cancelledCredits: number;
+  handPaidCancelledCredits: number;
+  /** ticketOut - (cancelledCredits - handPaidCancelledCredits) */
+  outVariance: number;
+  /** ticketIn - drop; positive means more tickets in than drop */
+  inExcess: number;
+};
+
+export type TitoMachineDay = TitoTotals & {
+  machineId: string;
+  serialNumber: string;
+  reasons: string[];
+};
+
+export type TitoLocationDay = TitoTotals & {
+  locationId: string;
+  locationName: string;
+  /** Gaming day, YYYY-MM-DD */
+  day: string;
+  machines: number;
+  flagged: boolean;
+  flaggedMachines: TitoMachineDay[];
+};
+
+export type TitoReconciliationReport = {
+  generatedAt: Date;
+  threshold: number;
+  days: TitoLocationDay[];
+  flaggedDays: number;
+  flaggedMachineDays: number;
+};
+
+export type TitoReconciliationParams = {
+  allowedLocationIds: 'all' | string[];
+  timePeriod: string;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  threshold?: number;
+};
+
+type TitoLocation = {
+  _id: string;
+  name?: string;
+  gameDayOffset?: number;
+  rel?: { licencee?: string };
+};
+
+type MachineDayAggregate = MovementTotals & {
+  _id: { machine: string; day: string };
+  ticketIn: number;
+  ticketOut: number;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+function withVariances(
+  totals: Omit<TitoTotals, 'outVariance' | 'inExcess'>
+): TitoTotals {
+  return {
+    ...totals,
+    outVariance:
+      totals.ticketOut -
+      (totals.cancelledCredits - totals.handPaidCancelledCredits),
+    inExcess: totals.ticketIn - totals.drop,
+  };
+}
+
+/**
+ * Drop and cancelled credits of a machine-day under the licencee formula.
+ */
+function machineDayTotals(
+  row: MachineDayAggregate,
+  formula: FinancialFormula
+): TitoTotals {
+  const { moneyIn, moneyOut } = calculateFinancialMetrics(row, {
+    ...formula,
+    includeJackpot: false,
+  });
+  return withVariances({
+    ticketIn: row.ticketIn,
+    ticketOut: row.ticketOut,
+    drop: moneyIn,
+    cancelledCredits: moneyOut,
+    handPaidCancelledCredits: row.totalHandPaidCancelledCredits || 0,
+  });
+}
+
+function imbalanceReasons(totals: TitoTotals, threshold: number): string[] {
+  const reasons: string[] = [];
+  if (Math.abs(totals.outVariance) > threshold) {
+    reasons.push(
+      totals.outVariance > 0
+        ? 'Tickets out above cancelled credits'
+        : 'Cancelled credits not covered by tickets out'
+    );
+  }
+  if (totals.inExcess > threshold) reasons.push('Tickets in above drop');
+  return reasons;
+}
+
+/**
+ * Sums TITO meters per machine per gaming day for one location.
+ */
+async function aggregateMachineDays(
+  locationId: string,
+  rangeStart: Date,
+  rangeEnd: Date,
+  gameDayOffset: number
+): Promise<MachineDayAggregate[]> {
+  const dayShiftMs = (TIMEZONE_OFFSET_HOURS - gameDayOffset) * HOUR_MS;
+  return Meters.aggregate<MachineDayAggregate>([
+    {
+      $match: {
+        location: locationId,
+        readAt: { $gte: rangeStart, $lte: rangeEnd },
+        $or: [
+          { 'movement.ticketIn': { $exists: true } },
+          { 'movement.ticketOut': { $exists: true } },
+        ],
+      },
+    },
+    {
+      $group: {
+        _id: {
+          machine: '$machine',
+          day: {
+            $dateToString: {
+              format: '%Y-%m-%d',
+              date: { $add: ['$readAt', dayShiftMs] },
+            },
+          },
+        },
+        ticketIn: { $sum: { $ifNull: ['$movement.ticketIn', 0] } },
+        ticketOut: { $sum: { $ifNull: ['$movement.ticketOut', 0] } },
+        ...buildMovementTotalsGroup(),
+      },
+    },
+  ]);
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the TITO reconciliation report.
+ *
+ * @param params - Location scope, period and imbalance threshold
+ * @returns Location-days, newest first, flagged ones first within a day
+ */
+export async function getTitoReconciliationReport(
+  params: TitoReconciliationParams
+): Promise<TitoReconciliationReport> {
+  const threshold = params.threshold ?? DEFAULT_TITO_THRESHOLD;
+
+  // Step 1: Locations in scope
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (params.allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: params.allowedLocationIds };
+  }
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    gameDayOffset: 1,
+    'rel.licencee': 1,
+  }).lean<TitoLocation[]>();
+  const formulas = await getLicenceeFinancialFormulas(
+    Array.from(
+      new Set(
+        locations
+          .map(location => location.rel?.licencee)
+          .filter((id): id is string => Boolean(id))
+          .map(String)
+      )
+    )
+  );
+
+  // Step 2: Machine-day totals per location (each has its own gaming day)
+  const perLocation = await runPooled(locations, async location => {
+    const gameDayOffset = location.gameDayOffset ?? 8;
+    const { rangeStart, rangeEnd } = getGamingDayRangeForPeriod(
+      params.timePeriod,
+      gameDayOffset,
+      params.customStartDate,
+      params.customEndDate
+    );
+    const rows = await aggregateMachineDays(
+      String(location._id),
+      rangeStart,
+      rangeEnd,
+      gameDayOffset
+    );
+    return { location, rows };
+  });
+
+  // Step 3: Serial numbers for the machines involved
+  const machineIds = Array.from(
+    new Set(perLocation.flatMap(({ rows }) => rows.map(row => row._id.machine)))
+  );
+  const machineDocs = await Machine.find(
+    { _id: { $in: machineIds } },
+    { serialNumber: 1, origSerialNumber: 1 }
+  ).lean<
+    Array<{ _id: string; serialNumber?: string; origSerialNumber?: string }>
+  >();
+  const serialById = new Map(
+    machineDocs.map(machine => [
+      String(machine._id),
+      machine.serialNumber?.trim() ||
+        machine.origSerialNumber?.trim() ||
+        String(machine._id),
+    ])
+  );
+
+  // Step 4: Roll up to location-days and flag imbalances
+  const days: TitoLocationDay[] = [];
+  perLocation.forEach(({ location, rows }) => {
+    const byDay = new Map<string, MachineDayAggregate[]>();
+    rows.forEach(row => {
+      byDay.set(row._id.day, [...(byDay.get(row._id.day) || []), row]);
+    });
+
+    const formula =
+      formulas.get(String(location.rel?.licencee)) ||
+      DEFAULT_FINANCIAL_FORMULA;
+    byDay.forEach((machineRows, day) => {
+      const machines: TitoMachineDay[] = machineRows.map(row => {
+        const totals = machineDayTotals(row, formula);
+        return {
+          ...totals,
+          machineId: row._id.machine,
+          serialNumber: serialById.get(row._id.machine) || row._id.machine,
+          reasons: imbalanceReasons(totals, threshold),
+        };
+      });
+      const sum = (field: keyof Omit<TitoTotals, 'outVariance' | 'inExcess'>) =>
+        machines.reduce((total, machine) => total + machine[field], 0);
+      const totals = withVariances({
+        ticketIn: sum('ticketIn'),
+        ticketOut: sum('ticketOut'),
+        drop: sum('drop'),
+        cancelledCredits: sum('cancelledCredits'),
+        handPaidCancelledCredits: sum('handPaidCancelledCredits'),
+      });
+      const flaggedMachines = machines
+        .filter(machine => machine.reasons.length > 0)
+        .sort(
+          (a, b) =>
+            Math.abs(b.outVariance) +
+            Math.max(b.inExcess, 0) -
+            (Math.abs(a.outVariance) + Math.max(a.inExcess, 0))
+        );
+
+      days.push({
+        ...totals,
+        locationId: String(location._id),
+        locationName: location.name || String(location._id),
+        day,
+        machines: machines.length,
+        flagged:
+          flaggedMachines.length > 0 ||
+          imbalanceReasons(totals, threshold).length > 0,
+        flaggedMachines,
+      });
+    });
+  });
+
+  days.sort(
+    (a, b) =>
+      b.day.localeCompare(a.day) ||
+      Number(b.flagged) - Number(a.flagged) ||
+      a.locationName.localeCompare(b.locationName)
+  );
+
+  return {
+    generatedAt: new Date(),
+    threshold,
+    days,
+    flaggedDays: days.filter(day => day.flagged).length,
+    flaggedMachineDays: days.reduce(
+      (total, day) => total + day.flaggedMachines.length,
+      0
+    ),
+  };
+}
+
+/**
+ * Converts the report to CSV: one line per location-day, then one per
+ * flagged machine under it.
+ */
+export function exportTitoReconciliationToCSV(
+  report: TitoReconciliationReport
+): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const amount = (value: number) => value.toFixed(2);
+  const header = [
+    'Day',
+    'Location',
+    'Serial Number',
+    'Ticket In',
+    'Ticket Out',
+    'Drop',
+    'Cancelled Credits',
+    'Hand Paid',
+    'Out Variance',
+    'In Excess',
+    'Flagged',
+    'Reasons',
+  ];
+  const line = (
+    day: TitoLocationDay,
+    totals: TitoTotals,
+    serialNumber: string,
+    flagged: boolean,
+    reasons: string[]
+  ) =>
+    [
+      day.day,
+      quote(day.locationName),
+      quote(serialNumber),
+      amount(totals.ticketIn),
+      amount(totals.ticketOut),
+      amount(totals.drop),
+      amount(totals.cancelledCredits),
+      amount(totals.handPaidCancelledCredits),
+      amount(totals.outVariance),
+      amount(totals.inExcess),
+      flagged ? 'yes' : 'no',
+      quote(reasons.join('; ')),
+    ].join(',');
+
+  const lines = report.days.flatMap(day => [
+    line(day, day, '', day.flagged, []),
+    ...day.flaggedMachines.map(machine =>
+      line(day, machine, machine.serialNumber, true, machine.reasons)
+    ),
+  ]);
+  return [header.join(','), ...lines].join('\n');
+}
@@ -7,12 +7,46 @@
  * @module app/api/lib/helpers/topMachines
  */
 
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { Meters } from '@/app/api/lib/models/meters';
 // Note: Db type from mongodb not imported to avoid mongoose/mongodb version mismatch
 import type { TimePeriod } from '@/app/api/lib/types';
 import { getDatesForTimePeriod } from '@/app/api/lib/utils/dates';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import type { MovementTotals } from '@shared/types';
 import type { PipelineStage } from 'mongoose';
 
+/** Per-machine movement totals from `buildTopMachinesPipeline` */
+type TopMachineTotals = MovementTotals & {
+  id: string;
+  name: string;
+  gamesPlayed: number;
+  count: number;
+};
+
+/** Per-machine movement totals from `buildTopMachinesDetailedPipeline` */
+type TopMachineDetailedTotals = MovementTotals & {
+  locationId: string;
+  locationName: string;
+  serialNumber: string;
+  customName: string;
+  machineDocumentId: string;
+  machineId: string;
+  game: string;
+  manufacturer: string;
+  avgWagerPerGame: number;
+  gamesPlayed: number;
+};
+
+function round2(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
 /**
  * Calculates date range for top machines query
  *
@@ -97,10 +131,7 @@ function buildTopMachinesPipeline(
     {
       $group: {
         _id: '$machine',
-        totalDrop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
+        ...buildMovementTotalsGroup(),
         totalGamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
         count: { $sum: 1 },
       },
@@ -123,36 +154,11 @@ function buildTopMachinesPipeline(
       $project: {
         id: '$_id',
         name: { $ifNull: ['$machineInfo.serialNumber', 'Unknown Machine'] },
-        revenue: { $subtract: ['$totalDrop', '$totalCancelledCredits'] },
-        drop: '$totalDrop',
-        cancelledCredits: '$totalCancelledCredits',
+        ...Object.fromEntries(METER_MOVEMENT_FIELDS.map(field => [field, 1])),
         gamesPlayed: '$totalGamesPlayed',
         count: 1,
-        hold: {
-          $cond: [
-            { $gt: ['$totalDrop', 0] },
-            {
-              $multiply: [
-                {
-                  $divide: [
-                    { $subtract: ['$totalDrop', '$totalCancelledCredits'] },
-                    '$totalDrop',
-                  ],
-                },
-                100,
-              ],
-            },
-            0,
-          ],
-        },
       },
     },
-    {
-      $sort: { revenue: -1 },
-    },
-    {
-      $limit: 5,
-    },
   ];
 }
 
@@ -186,12 +192,31 @@ export async function getTopMachinesByLocation(
   const pipeline = buildTopMachinesPipeline(locationId, start, end);
 
   // Use cursor for Meters aggregation
-  const results: unknown[] = [];
+  const totals: TopMachineTotals[] = [];
   const cursor = Meters.aggregate(pipeline).cursor({ batchSize: 1000 });
   for await (const doc of cursor) {
-    results.push(doc);
+    totals.push(doc as TopMachineTotals);
   }
-  return results;
+
+  // Compose revenue and hold with the location's formula, then rank
+  const formulas = await getLocationFinancialFormulas([locationId]);
+  const formula = formulas.get(locationId) || DEFAULT_FINANCIAL_FORMULA;
+  return totals
+    .map(machine => {
+      const metrics = calculateFinancialMetrics(machine, formula);
+      return {
+        id: machine.id,
+        name: machine.name,
+        revenue: metrics.gross,
+        drop: metrics.moneyIn,
+        cancelledCredits: metrics.moneyOut,
+        gamesPlayed: machine.gamesPlayed,
+        count: machine.count,
+        hold: metrics.moneyIn > 0 ? (metrics.gross / metrics.moneyIn) * 100 : 0,
+      };
+    })
+    .sort((a, b) => b.revenue - a.revenue)
+    .slice(0, 5);
 }
 
 /**
@@ -202,7 +227,6 @@ export async function getTopMachinesByLocation(
  * @param endDate - End date
  * @param licencee - Optional licencee to filter by
  * @param locationIds - Optional comma-separated location IDs
- * @param limit - Limit for results
  * @returns Aggregation pipeline stages
  */
 function buildTopMachinesDetailedPipeline(
@@ -210,8 +234,7 @@ function buildTopMachinesDetailedPipeline(
   startDate: Date,
   endDate: Date,
   licencee?: string | null,
-  locationIds?: string | null,
-  limit: number = 5
+  locationIds?: string | null
 ): PipelineStage[] {
   if (!startDate || !endDate) {
     console.error(
@@ -303,16 +326,7 @@ function buildTopMachinesDetailedPipeline(
             ],
           },
         },
-        handle: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        winLoss: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
-          },
-        },
-        jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
+        ...buildMovementTotalsGroup(),
         avgWagerPerGame: {
           $avg: {
             $cond: [
@@ -327,39 +341,9 @@ function buildTopMachinesDetailedPipeline(
             ],
           },
         },
-        actualHold: {
-          $avg: {
-            $cond: [
-              { $gt: [{ $ifNull: ['$movement.drop', 0] }, 0] },
-              {
-                $multiply: [
-                  {
-                    $divide: [
-                      {
-                        $subtract: [
-                          { $ifNull: ['$movement.drop', 0] },
-                          { $ifNull: ['$movement.totalCancelledCredits', 0] },
-                        ],
-                      },
-                      { $ifNull: ['$movement.drop', 1] },
-                    ],
-                  },
-                  100,
-                ],
-              },
-              0,
-            ],
-          },
-        },
         gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
       },
     },
-    {
-      $sort: { handle: -1 },
-    },
-    {
-      $limit: limit,
-    },
     {
       $project: {
         _id: 0,
@@ -445,12 +429,8 @@ function buildTopMachinesDetailedPipeline(
         },
         game: { $ifNull: ['$game', '(game name not provided)'] },
         manufacturer: { $ifNull: ['$manufacturer', 'Not Specified'] },
-        handle: { $round: ['$handle', 2] },
-        totalDrop: { $round: ['$handle', 2] },
-        winLoss: { $round: ['$winLoss', 2] },
-        jackpot: { $round: ['$jackpot', 2] },
+        ...Object.fromEntries(METER_MOVEMENT_FIELDS.map(field => [field, 1])),
         avgWagerPerGame: { $round: ['$avgWagerPerGame', 2] },
-        actualHold: { $round: ['$actualHold', 2] },
         gamesPlayed: '$gamesPlayed',
       },
     }
@@ -487,15 +467,46 @@ export async function getTopMachinesDetailed(
     startDate!,
     endDate!,
     licencee,
-    locationIds,
-    limit
+    locationIds
   );
 
   // Use cursor for Meters aggregation
-  const results: unknown[] = [];
+  const totals: TopMachineDetailedTotals[] = [];
   const cursor = Meters.aggregate(pipeline).cursor({ batchSize: 1000 });
   for await (const doc of cursor) {
-    results.push(doc);
+    totals.push(doc as TopMachineDetailedTotals);
   }
-  return results;
+
+  // Compose handle, win/loss and hold with each location's formula, then rank
+  const formulas = await getLocationFinancialFormulas([
+    ...new Set(totals.map(machine => String(machine.locationId))),
+  ]);
+  return totals
+    .map(machine => {
+      const metrics = calculateFinancialMetrics(
+        machine,
+        formulas.get(String(machine.locationId)) || DEFAULT_FINANCIAL_FORMULA
+      );
+      return {
+        locationId: machine.locationId,
+        locationName: machine.locationName,
+        serialNumber: machine.serialNumber,
+        customName: machine.customName,
+        machineDocumentId: machine.machineDocumentId,
+        machineId: machine.machineId,
+        game: machine.game,
+        manufacturer: machine.manufacturer,
+        handle: round2(metrics.moneyIn),
+        totalDrop: round2(metrics.moneyIn),
+        winLoss: round2(metrics.gross),
+        jackpot: round2(metrics.jackpot),
+        avgWagerPerGame: machine.avgWagerPerGame,
+        actualHold: round2(
+          metrics.moneyIn > 0 ? (metrics.gross / metrics.moneyIn) * 100 : 0
+        ),
+        gamesPlayed: machine.gamesPlayed,
+      };
+    })
+    .sort((a, b) => b.handle - a.handle)
+    .slice(0, limit);
 }
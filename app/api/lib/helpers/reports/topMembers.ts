@@ -0,0 +1,441 @@
+/**
+ * Top Members Report Helper
+ *
+ * Ranks members per location by activity over a period — amount wagered
+ * (coin in during their carded sessions) and time played — and flags members
+ * who exceed the responsible-gaming thresholds so compliance can follow up:
+ * - `hours-per-day`: more than the maximum play time on one day
+ * - `weekly-loss`: lost (`coinIn - coinOut`) more than the maximum in a week
+ *
+ * Sessions are attributed as in the session attribution report and bucketed
+ * by their start in Trinidad time (UTC-4): calendar day, and ISO week
+ * (Monday–Sunday). The thresholds come from the `maxHoursPerDay` /
+ * `maxWeeklyLoss` parameters, falling back to `RG_MAX_HOURS_PER_DAY` /
+ * `RG_MAX_WEEKLY_LOSS`; a threshold that is unset or 0 is not checked.
+ *
+ * @module app/api/lib/helpers/reports/topMembers
+ */
+
+import {
+  streamAttributedSessions,
+} from '@/app/api/lib/helpers/reports/sessionAttribution';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Member } from '@/app/api/lib/models/members';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DEFAULT_TOP_MEMBERS_LIMIT = 10;
+export const DEFAULT_TOP_MEMBERS_DAYS = 7;
+
+const LOCAL_OFFSET_MS = -4 * 3600000;
+
+export type TopMembersSort = 'wagered' | 'time';
+
+export type ResponsibleGamingThresholds = {
+  maxHoursPerDay: number | null;
+  maxWeeklyLoss: number | null;
+};
+
+export type ResponsibleGamingFlag = {
+  type: 'hours-per-day' | 'weekly-loss';
+  /** Day (YYYY-MM-DD) or ISO week (YYYY-Www) */
+  period: string;
+  value: number;
+  threshold: number;
+};
+
+export type TopMember = {
+  rank: number;
+  memberId: string;
+  memberName: string;
+  username: string;
+  sessions: number;
+  playMinutes: number;
+  coinIn: number;
+  coinOut: number;
+  /** Member loss over the period, `coinIn - coinOut` */
+  netLoss: number;
+  lastPlayed: Date | null;
+  flags: ResponsibleGamingFlag[];
+};
+
+export type TopMembersLocation = {
+  locationId: string;
+  locationName: string;
+  activeMembers: number;
+  flaggedMembers: number;
+  members: TopMember[];
+};
+
+export type TopMembersReport = {
+  generatedAt: Date;
+  from: Date;
+  to: Date;
+  sortBy: TopMembersSort;
+  limit: number;
+  thresholds: ResponsibleGamingThresholds;
+  flaggedMembers: number;
+  locations: TopMembersLocation[];
+};
+
+export type TopMembersParams = {
+  allowedLocationIds: 'all' | string[];
+  /** Sessions starting in this range */
+  from: Date;
+  to: Date;
+  /** Members per location (default 10) */
+  limit?: number;
+  sortBy?: TopMembersSort;
+  thresholds: ResponsibleGamingThresholds;
+  /** Only members with at least one flag */
+  flaggedOnly?: boolean;
+};
+
+type MemberActivity = {
+  memberId: string;
+  locationId: string;
+  sessions: number;
+  playMinutes: number;
+  coinIn: number;
+  coinOut: number;
+  lastPlayed: Date | null;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+function readThreshold(
+  override: string | null | undefined,
+  paramName: string,
+  envName: string
+): number | null {
+  const raw = override?.trim() || process.env[envName]?.trim();
+  if (!raw) return null;
+  const value = Number(raw);
+  if (!Number.isFinite(value) || value < 0) {
+    const source = override?.trim() ? paramName : envName;
+    throw statusError(`${source} must be a non-negative number`, 400);
+  }
+  return value > 0 ? value : null;
+}
+
+/**
+ * Resolves the responsible-gaming thresholds: the overrides when given,
+ * otherwise `RG_MAX_HOURS_PER_DAY` and `RG_MAX_WEEKLY_LOSS`.
+ */
+export function getResponsibleGamingThresholds(overrides?: {
+  maxHoursPerDay?: string | null;
+  maxWeeklyLoss?: string | null;
+}): ResponsibleGamingThresholds {
+  return {
+    maxHoursPerDay: readThreshold(
+      overrides?.maxHoursPerDay,
+      'maxHoursPerDay',
+      'RG_MAX_HOURS_PER_DAY'
+    ),
+    maxWeeklyLoss: readThreshold(
+      overrides?.maxWeeklyLoss,
+      'maxWeeklyLoss',
+      'RG_MAX_WEEKLY_LOSS'
+    ),
+  };
+}
+
+/** Trinidad calendar day of a timestamp, as YYYY-MM-DD */
+function localDayKey(date: Date): string {
+  return new Date(date.getTime() + LOCAL_OFFSET_MS).toISOString().slice(0, 10);
+}
+
+/** ISO week of a timestamp in Trinidad time, as YYYY-Www */
+function localWeekKey(date: Date): string {
+  const local = new Date(date.getTime() + LOCAL_OFFSET_MS);
+  const day = new Date(
+    Date.UTC(local.getUTCFullYear(), local.getUTCMonth(), local.getUTCDate())
+  );
+  // Thursday of the same week decides the ISO year
+  day.setUTCDate(day.getUTCDate() + 3 - ((day.getUTCDay() + 6) % 7));
+  const yearStart = Date.UTC(day.getUTCFullYear(), 0, 1);
+  const week = Math.ceil(((day.getTime() - yearStart) / 86400000 + 1) / 7);
+  return `${day.getUTCFullYear()}-W${String(week).padStart(2, '0')}`;
+}
+
+function addToBucket(
+  buckets: Map<string, Map<string, number>>,
+  memberId: string,
+  key: string,
+  amount: number
+) {
+  const perMember = buckets.get(memberId) || new Map<string, number>();
+  perMember.set(key, (perMember.get(key) || 0) + amount);
+  buckets.set(memberId, perMember);
+}
+
+function collectFlags(
+  memberId: string,
+  minutesByDay: Map<string, Map<string, number>>,
+  lossByWeek: Map<string, Map<string, number>>,
+  thresholds: ResponsibleGamingThresholds
+): ResponsibleGamingFlag[] {
+  const flags: ResponsibleGamingFlag[] = [];
+  const { maxHoursPerDay, maxWeeklyLoss } = thresholds;
+  if (maxHoursPerDay !== null) {
+    minutesByDay.get(memberId)?.forEach((minutes, day) => {
+      const hours = Math.round((minutes / 60) * 100) / 100;
+      if (hours > maxHoursPerDay) {
+        flags.push({
+          type: 'hours-per-day',
+          period: day,
+          value: hours,
+          threshold: maxHoursPerDay,
+        });
+      }
+    });
+  }
+  if (maxWeeklyLoss !== null) {
+    lossByWeek.get(memberId)?.forEach((loss, week) => {
+      if (loss > maxWeeklyLoss) {
+        flags.push({
+          type: 'weekly-loss',
+          period: week,
+          value: Math.round(loss * 100) / 100,
+          threshold: maxWeeklyLoss,
+        });
+      }
+    });
+  }
+  return flags.sort((a, b) => a.period.localeCompare(b.period));
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the top members per location with responsible-gaming flags.
+ *
+ * Members are grouped under the location of the machines they played; time
+ * and losses used for the flags are summed across all locations in scope.
+ *
+ * @param params - Accessible locations, date range, limit and thresholds
+ * @returns Locations by name, each with its top members
+ */
+export async function getTopMembersReport(
+  params: TopMembersParams
+): Promise<TopMembersReport> {
+  const limit = params.limit ?? DEFAULT_TOP_MEMBERS_LIMIT;
+  const sortBy = params.sortBy ?? 'wagered';
+  const now = new Date();
+
+  // Step 1: Machines in scope, with their location
+  const machineQuery: Record<string, unknown> = {};
+  if (params.allowedLocationIds !== 'all') {
+    machineQuery.gamingLocation = { $in: params.allowedLocationIds };
+  }
+  const machines = await Machine.find(machineQuery, {
+    _id: 1,
+    gamingLocation: 1,
+  }).lean<Array<{ _id: string; gamingLocation?: string }>>();
+  const locationByMachine = new Map(
+    machines.map(machine => [
+      String(machine._id),
+      String(machine.gamingLocation || ''),
+    ])
+  );
+
+  // Step 2: Accumulate per member and location, and per day / week
+  const activity = new Map<string, MemberActivity>();
+  const minutesByDay = new Map<string, Map<string, number>>();
+  const lossByWeek = new Map<string, Map<string, number>>();
+  const cursor = streamAttributedSessions({
+    machineIds:
+      params.allowedLocationIds === 'all'
+        ? null
+        : Array.from(locationByMachine.keys()),
+    from: params.from,
+    to: params.to,
+    now,
+  });
+  for await (const session of cursor) {
+    const memberId = String(session.memberId);
+    const locationId = locationByMachine.get(String(session.machineId));
+    if (!locationId) continue;
+
+    const key = `${locationId}:${memberId}`;
+    const entry = activity.get(key) || {
+      memberId,
+      locationId,
+      sessions: 0,
+      playMinutes: 0,
+      coinIn: 0,
+      coinOut: 0,
+      lastPlayed: null,
+    };
+    const start = new Date(session.startTime);
+    const end = session.endTime ? new Date(session.endTime) : now;
+    const minutes = Math.max(0, end.getTime() - start.getTime()) / 60000;
+    const coinIn = session.movement?.coinIn || 0;
+    const coinOut = session.movement?.coinOut || 0;
+
+    entry.sessions++;
+    entry.playMinutes += minutes;
+    entry.coinIn += coinIn;
+    entry.coinOut += coinOut;
+    if (!entry.lastPlayed || end > entry.lastPlayed) entry.lastPlayed = end;
+    activity.set(key, entry);
+
+    addToBucket(minutesByDay, memberId, localDayKey(start), minutes);
+    addToBucket(lossByWeek, memberId, localWeekKey(start), coinIn - coinOut);
+  }
+
+  // Step 3: Flags per member
+  const flagsByMember = new Map<string, ResponsibleGamingFlag[]>();
+  new Set(Array.from(activity.values()).map(a => a.memberId)).forEach(
+    memberId =>
+      flagsByMember.set(
+        memberId,
+        collectFlags(memberId, minutesByDay, lossByWeek, params.thresholds)
+      )
+  );
+
+  // Step 4: Rank per location
+  const byLocation = new Map<string, MemberActivity[]>();
+  activity.forEach(entry => {
+    const list = byLocation.get(entry.locationId) || [];
+    list.push(entry);
+    byLocation.set(entry.locationId, list);
+  });
+
+  const rankValue = (entry: MemberActivity) =>
+    sortBy === 'time' ? entry.playMinutes : entry.coinIn;
+  const selected = new Map<string, MemberActivity[]>();
+  byLocation.forEach((entries, locationId) => {
+    const candidates = params.flaggedOnly
+      ? entries.filter(e => (flagsByMember.get(e.memberId) || []).length > 0)
+      : entries;
+    selected.set(
+      locationId,
+      candidates
+        .sort((a, b) => rankValue(b) - rankValue(a))
+        .slice(0, limit)
+    );
+  });
+
+  // Step 5: Member and location names
+  const memberIds = new Set<string>();
+  selected.forEach(entries => entries.forEach(e => memberIds.add(e.memberId)));
+  const [memberDocs, locationDocs] = await Promise.all([
+    Member.find(
+      { _id: { $in: Array.from(memberIds) } },
+      { _id: 1, username: 1, 'profile.firstName': 1, 'profile.lastName': 1 }
+    ).lean<
+      Array<{
+        _id: string;
+        username?: string;
+        profile?: { firstName?: string; lastName?: string };
+      }>
+    >(),
+    GamingLocations.find(
+      { _id: { $in: Array.from(byLocation.keys()) } },
+      { _id: 1, name: 1 }
+    ).lean<Array<{ _id: string; name?: string }>>(),
+  ]);
+  const memberById = new Map(memberDocs.map(m => [String(m._id), m]));
+  const locationNames = new Map(
+    locationDocs.map(l => [String(l._id), l.name || String(l._id)])
+  );
+
+  const locations: TopMembersLocation[] = Array.from(byLocation.entries())
+    .map(([locationId, entries]) => ({
+      locationId,
+      locationName: locationNames.get(locationId) || locationId,
+      activeMembers: entries.length,
+      flaggedMembers: entries.filter(
+        e => (flagsByMember.get(e.memberId) || []).length > 0
+      ).length,
+      members: (selected.get(locationId) || []).map((entry, index) => {
+        const member = memberById.get(entry.memberId);
+        const name = `${member?.profile?.firstName || ''} ${
+          member?.profile?.lastName || ''
+        }`.trim();
+        return {
+          rank: index + 1,
+          memberId: entry.memberId,
+          memberName: name || member?.username || entry.memberId,
+          username: member?.username || '',
+          sessions: entry.sessions,
+          playMinutes: Math.round(entry.playMinutes),
+          coinIn: entry.coinIn,
+          coinOut: entry.coinOut,
+          netLoss: entry.coinIn - entry.coinOut,
+          lastPlayed: entry.lastPlayed,
+          flags: flagsByMember.get(entry.memberId) || [],
+        };
+      }),
+    }))
+    .filter(location => location.members.length > 0)
+    .sort((a, b) => a.locationName.localeCompare(b.locationName));
+
+  return {
+    generatedAt: new Date(),
+    from: params.from,
+    to: params.to,
+    sortBy,
+    limit,
+    thresholds: params.thresholds,
+    flaggedMembers: Array.from(flagsByMember.values()).filter(
+      flags => flags.length > 0
+    ).length,
+    locations,
+  };
+}
+
+/**
+ * Converts the top members to CSV, one line per member and location.
+ */
+export function exportTopMembersToCSV(report: TopMembersReport): string {
+  const quote = (value: string) => `"${value.replace(/"/g, '""')}"`;
+  const header = [
+    'Location ID',
+    'Location',
+    'Rank',
+    'Member ID',
+    'Member',
+    'Username',
+    'Sessions',
+    'Play Minutes',
+    'Coin In',
+    'Coin Out',
+    'Net Loss',
+    'Last Played',
+    'Flags',
+  ];
+  const lines = report.locations.flatMap(location =>
+    location.members.map(member =>
+      [
+        location.locationId,
+        quote(location.locationName),
+        member.rank,
+        member.memberId,
+        quote(member.memberName),
+        quote(member.username),
+        member.sessions,
+        member.playMinutes,
+        member.coinIn.toFixed(2),
+        member.coinOut.toFixed(2),
+        member.netLoss.toFixed(2),
+        member.lastPlayed ? new Date(member.lastPlayed).toISOString() : '',
+        quote(
+          member.flags
+            .map(flag => `${flag.type} ${flag.period} (${flag.value})`)
+            .join('; ')
+        ),
+      ].join(',')
+    )
+  );
+  return [header.join(','), ...lines].join('\n');
+}
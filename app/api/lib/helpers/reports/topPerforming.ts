@@ -1,12 +1,38 @@
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
 import type { QueryFilter, TimePeriod } from '@/lib/types/api';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
 // Note: Db type from mongodb not imported to avoid mongoose/mongodb version mismatch
 import type { PipelineStage } from 'mongoose';
 import type { TopPerformingTab } from '@/shared/types/reports';
+import type { MovementTotals } from '@shared/types';
+
+/** Rows returned per tab */
+const TOP_PERFORMING_LIMIT = 5;
+
+/** Location or machine row with its summed movement fields */
+type TopPerformingTotals = {
+  name: string;
+  locationId: string;
+  totalGamesPlayed: number;
+  totals: MovementTotals;
+  [key: string]: unknown;
+};
+
+/** Collects the summed movement fields of a `$group` under `totals` */
+const MOVEMENT_TOTALS_PROJECTION = Object.fromEntries(
+  METER_MOVEMENT_FIELDS.map(field => [field, `$${field}`])
+);
 
 /**
- * Fetches the top 5 performing locations or Cabinets based on total moneyIn (drop).
+ * Fetches the top 5 performing locations or Cabinets by Money In, composed
+ * with each location's financial formula.
  *
  * @param db - MongoDB database instance.
  * @param activeTab - The current tab the user is on ("locations" or "Cabinets").
@@ -51,20 +77,55 @@ export async function getTopPerformingMetrics(
       ? aggregateMetersForTop5Machines(filter, licencee)
       : aggregateMetersForTop5Locations(filter, licencee);
 
-  const result: Record<string, unknown>[] = [];
+  const rows: TopPerformingTotals[] = [];
   const cursor = Meters.aggregate(aggregationQuery).cursor({ batchSize: 1000 });
   for await (const doc of cursor) {
-    result.push(doc);
+    rows.push(doc as TopPerformingTotals);
   }
-  return result;
+
+  // Compose Money In/Out with each location's formula, then rank by Money In
+  const formulas = await getLocationFinancialFormulas([
+    ...new Set(rows.map(row => row.locationId)),
+  ]);
+  const ranked = rows
+    .map(({ totals, ...row }) => {
+      const metrics = calculateFinancialMetrics(
+        totals,
+        formulas.get(row.locationId) || DEFAULT_FINANCIAL_FORMULA
+      );
+      return {
+        ...row,
+        ...(activeTab === 'Cabinets'
+          ? {
+              totalCoinIn: Number(totals.coinIn) || 0,
+              totalCoinOut: Number(totals.coinOut) || 0,
+              totalCancelledCredits: metrics.moneyOut,
+            }
+          : {}),
+        totalDrop: metrics.moneyIn,
+        totalJackpot: metrics.jackpot,
+      };
+    })
+    .sort((a, b) => b.totalDrop - a.totalDrop);
+
+  // A serial number can report under more than one location; keep its best
+  const seen = new Set<string>();
+  return ranked
+    .filter(row => {
+      const key = String(activeTab === 'Cabinets' ? row.name : row.locationId);
+      if (seen.has(key)) return false;
+      seen.add(key);
+      return true;
+    })
+    .slice(0, TOP_PERFORMING_LIMIT);
 }
 
 /**
- * Aggregates meters for the top 5 performing locations.
+ * Aggregates meter movement per location for the top 5 locations.
  *
  * @param filter - MongoDB filter object for date range.
  * @param licencee - (Optional) Licencee filter to restrict results.
- * @returns MongoDB aggregation pipeline for top 5 locations.
+ * @returns MongoDB aggregation pipeline of per-location movement totals.
  */
 function aggregateMetersForTop5Locations(
   filter: QueryFilter,
@@ -79,9 +140,8 @@ function aggregateMetersForTop5Locations(
     {
       $group: {
         _id: '$location',
-        totalDrop: { $sum: '$movement.drop' },
+        ...buildMovementTotalsGroup(),
         totalGamesPlayed: { $sum: '$movement.gamesPlayed' },
-        totalJackpot: { $sum: '$movement.jackpot' },
       },
     },
     {
@@ -111,42 +171,19 @@ function aggregateMetersForTop5Locations(
         _id: 0,
         name: '$locationDetails.name',
         locationId: { $toString: '$locationDetails._id' },
-        totalDrop: 1,
+        totals: MOVEMENT_TOTALS_PROJECTION,
         totalGamesPlayed: 1,
-        totalJackpot: 1,
       },
     },
-    { $sort: { totalDrop: -1 } },
-    {
-      $group: {
-        _id: '$locationId',
-        name: { $first: '$name' },
-        totalDrop: { $sum: '$totalDrop' },
-        totalGamesPlayed: { $sum: '$totalGamesPlayed' },
-        totalJackpot: { $sum: '$totalJackpot' },
-      },
-    },
-    {
-      $project: {
-        _id: 0,
-        name: 1,
-        locationId: '$_id',
-        totalDrop: 1,
-        totalGamesPlayed: 1,
-        totalJackpot: 1,
-      },
-    },
-    { $sort: { totalDrop: -1 } },
-    { $limit: 5 },
   ];
 }
 
 /**
- * Aggregates meters for the top 5 performing machines.
+ * Aggregates meter movement per machine and location for the top 5 machines.
  *
  * @param filter - MongoDB filter object for date range.
  * @param licencee - (Optional) Licencee filter to restrict results.
- * @returns MongoDB aggregation pipeline for top 5 machines.
+ * @returns MongoDB aggregation pipeline of per-machine movement totals.
  */
 function aggregateMetersForTop5Machines(
   filter: QueryFilter,
@@ -161,12 +198,8 @@ function aggregateMetersForTop5Machines(
     {
       $group: {
         _id: { machine: '$machine', location: '$location' },
-        totalCoinIn: { $sum: '$movement.coinIn' },
-        totalCoinOut: { $sum: '$movement.coinOut' },
-        totalDrop: { $sum: '$movement.drop' },
-        totalCancelledCredits: { $sum: '$movement.totalCancelledCredits' },
+        ...buildMovementTotalsGroup(),
         totalGamesPlayed: { $sum: '$movement.gamesPlayed' },
-        totalJackpot: { $sum: '$movement.jackpot' },
       },
     },
     {
@@ -210,52 +243,9 @@ function aggregateMetersForTop5Machines(
         location: '$locationDetails.name',
         locationId: { $toString: '$locationDetails._id' },
         machineId: { $toString: '$machineDetails._id' },
-        totalCoinIn: 1,
-        totalCoinOut: 1,
-        totalDrop: 1,
-        totalCancelledCredits: 1,
-        totalGamesPlayed: 1,
-        totalJackpot: 1,
-      },
-    },
-    { $sort: { name: 1, totalDrop: -1 } },
-    {
-      $group: {
-        _id: '$name',
-        machine: { $first: '$name' },
-        customName: { $first: '$customName' },
-        assetNumber: { $first: '$assetNumber' },
-        game: { $first: '$game' },
-        location: { $first: '$location' },
-        locationId: { $first: '$locationId' },
-        machineId: { $first: '$machineId' },
-        totalCoinIn: { $first: '$totalCoinIn' },
-        totalCoinOut: { $first: '$totalCoinOut' },
-        totalDrop: { $first: '$totalDrop' },
-        totalCancelledCredits: { $first: '$totalCancelledCredits' },
-        totalGamesPlayed: { $first: '$totalGamesPlayed' },
-        totalJackpot: { $first: '$totalJackpot' },
-      },
-    },
-    {
-      $project: {
-        _id: 0,
-        name: '$machine',
-        customName: 1,
-        assetNumber: 1,
-        game: 1,
-        location: '$location',
-        locationId: 1,
-        machineId: 1,
-        totalCoinIn: 1,
-        totalCoinOut: 1,
-        totalDrop: 1,
-        totalCancelledCredits: 1,
+        totals: MOVEMENT_TOTALS_PROJECTION,
         totalGamesPlayed: 1,
-        totalJackpot: 1,
       },
     },
-    { $sort: { totalDrop: -1 } },
-    { $limit: 5 },
   ];
 }
@@ -0,0 +1,436 @@
+/**
+ * Uncollected Drop Report Helper
+ *
+ * Meter movement of each machine since its last completed collection — the
+ * cash sitting in the machine now — summed per location, so cash logistics
+ * can see which venues are building up cash fastest.
+ *
+ * A machine's last collection is its latest completed collection
+ * (`isCompleted` with a `locationReportId`), falling back to the machine's
+ * `collectionTime`. Movement is summed from `meters` readings after it.
+ * Machines never collected are counted over the last `maxDays` (default 90)
+ * and flagged. Retired machines are left out. Money In / Out and Gross follow the licencee's financial
+ * formula (see financialFormulas) and the caller's reviewer scales.
+ *
+ * Locations are ranked by uncollected drop per day: the sum, over their
+ * machines, of drop divided by the days since that machine's collection.
+ * Readings are aggregated once per location through the shared fan-out pool
+ * (see aggregationFanOut).
+ *
+ * @module app/api/lib/helpers/reports/uncollectedDrop
+ */
+
+import { runPooled } from '@/app/api/lib/helpers/aggregationFanOut';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
+import { Collections } from '@/app/api/lib/models/collections';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Licencee } from '@/app/api/lib/models/licencee';
+import { Machine } from '@/app/api/lib/models/machines';
+import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  resolveFinancialFormula,
+} from '@/app/api/lib/utils/financialFormulas';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import type {
+  FinancialScales,
+  LicenceeDocument,
+  MovementTotals,
+} from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DEFAULT_UNCOLLECTED_MAX_DAYS = 90;
+
+export type UncollectedMachine = {
+  machineId: string;
+  serialNumber: string;
+  game: string;
+  locationId: string;
+  locationName: string;
+  /** Last completed collection; null when never collected */
+  lastCollectedAt: Date | null;
+  /** Start of the counted movement (the collection or `maxDays` ago) */
+  since: Date;
+  daysSince: number;
+  lastReadAt: Date | null;
+  moneyIn: number;
+  moneyOut: number;
+  gross: number;
+  gamesPlayed: number;
+  /** Money In per day since the collection */
+  dropPerDay: number;
+};
+
+export type UncollectedLocation = {
+  locationId: string;
+  locationName: string;
+  licenceeId: string;
+  machines: number;
+  neverCollected: number;
+  /** Oldest last collection among the machines */
+  oldestCollectedAt: Date | null;
+  moneyIn: number;
+  moneyOut: number;
+  gross: number;
+  dropPerDay: number;
+  machineRows: UncollectedMachine[];
+};
+
+export type UncollectedDropReport = {
+  generatedAt: Date;
+  maxDays: number;
+  totals: { moneyIn: number; moneyOut: number; gross: number };
+  /** Fastest-filling first */
+  locations: UncollectedLocation[];
+};
+
+export type UncollectedDropParams = {
+  allowedLocationIds: 'all' | string[];
+  maxDays?: number;
+  scales?: FinancialScales;
+};
+
+type ReportLocation = {
+  _id: string;
+  name?: string;
+  rel?: { licencee?: string | string[] };
+};
+
+type ReportMachine = {
+  _id: string;
+  serialNumber?: string;
+  origSerialNumber?: string;
+  custom?: { name?: string };
+  game?: string;
+  gamingLocation: string;
+  collectionTime?: Date;
+};
+
+type MachineMovement = MovementTotals & {
+  _id: string;
+  gamesPlayed: number;
+  lastReadAt: Date;
+};
+
+function round(value: number): number {
+  return Math.round(value * 100) / 100;
+}
+
+// ============================================================================
+// Report
+// ============================================================================
+
+/**
+ * Builds the uncollected drop report for the locations in scope.
+ *
+ * @param params - Location scope, never-collected window and reviewer scales
+ * @returns Locations fastest-filling first, each with its machines (most
+ * drop first)
+ */
+export async function getUncollectedDropReport(
+  params: UncollectedDropParams
+): Promise<UncollectedDropReport> {
+  const now = new Date();
+  const maxDays = Math.min(
+    Math.max(params.maxDays ?? DEFAULT_UNCOLLECTED_MAX_DAYS, 1),
+    365
+  );
+  const floor = new Date(now.getTime() - maxDays * 86400000);
+  const scales = params.scales ?? { moneyInScale: 1, moneyOutScale: 1 };
+
+  // Step 1: Locations and machines in scope
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
+  if (params.allowedLocationIds !== 'all') {
+    locationQuery._id = { $in: params.allowedLocationIds };
+  }
+  const locations = await GamingLocations.find(locationQuery, {
+    _id: 1,
+    name: 1,
+    'rel.licencee': 1,
+  }).lean<ReportLocation[]>();
+  const machines =
+    locations.length === 0
+      ? []
+      : await Machine.find(
+          {
+            gamingLocation: {
+              $in: locations.map(location => String(location._id)),
+            },
+            ...NOT_DELETED_FILTER,
+            ...NOT_RETIRED_FILTER,
+          },
+          {
+            _id: 1,
+            serialNumber: 1,
+            origSerialNumber: 1,
+            'custom.name': 1,
+            game: 1,
+            gamingLocation: 1,
+            collectionTime: 1,
+          }
+        ).lean<ReportMachine[]>();
+  const machineIds = machines.map(machine => String(machine._id));
+
+  // Step 2: Last completed collection per machine
+  const collections =
+    machineIds.length === 0
+      ? []
+      : await Collections.aggregate<{ _id: string; lastCollectedAt: Date }>([
+          {
+            $match: {
+              machineId: { $in: machineIds },
+              isCompleted: true,
+              locationReportId: { $nin: ['', null] },
+              ...NOT_DELETED_FILTER,
+            },
+          },
+          {
+            $group: {
+              _id: '$machineId',
+              lastCollectedAt: { $max: '$timestamp' },
+            },
+          },
+        ]);
+  const lastCollectedAt = new Map(
+    collections.map(row => [String(row._id), row.lastCollectedAt])
+  );
+  const sinceByMachine = new Map(
+    machines.map(machine => {
+      const collectedAt =
+        lastCollectedAt.get(String(machine._id)) ??
+        machine.collectionTime ??
+        null;
+      return [
+        String(machine._id),
+        {
+          collectedAt,
+          since: collectedAt && collectedAt > floor ? collectedAt : floor,
+        },
+      ];
+    })
+  );
+
+  // Step 3: Movement since each collection, one pipeline per location
+  const machinesByLocation = new Map<string, ReportMachine[]>();
+  machines.forEach(machine => {
+    const locationId = String(machine.gamingLocation);
+    machinesByLocation.set(locationId, [
+      ...(machinesByLocation.get(locationId) ?? []),
+      machine,
+    ]);
+  });
+  const partials = await runPooled(
+    Array.from(machinesByLocation.values()),
+    locationMachines =>
+      Meters.aggregate<MachineMovement>([
+        {
+          $match: {
+            $or: locationMachines.map(machine => ({
+              machine: String(machine._id),
+              readAt: {
+                $gt: sinceByMachine.get(String(machine._id))?.since ?? floor,
+                $lte: now,
+              },
+            })),
+          },
+        },
+        {
+          $group: {
+            _id: '$machine',
+            ...buildMovementTotalsGroup(),
+            gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
+            lastReadAt: { $max: '$readAt' },
+          },
+        },
+      ]).option({ allowDiskUse: true })
+  );
+  const movementByMachine = new Map(
+    partials.flat().map(row => [String(row._id), row])
+  );
+
+  // Step 4: Financial formula per licencee
+  const licenceeOf = (location?: ReportLocation) => {
+    const licencee = location?.rel?.licencee;
+    return (Array.isArray(licencee) ? licencee[0] : licencee) || '';
+  };
+  const licenceeIds = Array.from(
+    new Set(locations.map(licenceeOf).filter(Boolean))
+  );
+  const licencees =
+    licenceeIds.length === 0
+      ? []
+      : await Licencee.find(
+          { _id: { $in: licenceeIds } },
+          { _id: 1, includeJackpot: 1, financialFormula: 1 }
+        ).lean<LicenceeDocument[]>();
+  const formulaByLicencee = new Map(
+    licencees.map(licencee => [
+      String(licencee._id),
+      resolveFinancialFormula(licencee),
+    ])
+  );
+
+  // Step 5: Machine rows grouped by location, fastest-filling first
+  const locationsById = new Map(
+    locations.map(location => [String(location._id), location])
+  );
+  const totals = { moneyIn: 0, moneyOut: 0, gross: 0 };
+  const groups: UncollectedLocation[] = [];
+
+  machinesByLocation.forEach((locationMachines, locationId) => {
+    const location = locationsById.get(locationId);
+    const licenceeId = licenceeOf(location);
+    const formula =
+      formulaByLicencee.get(licenceeId) ?? resolveFinancialFormula(null);
+    const group: UncollectedLocation = {
+      locationId,
+      locationName: location?.name || locationId,
+      licenceeId,
+      machines: locationMachines.length,
+      neverCollected: 0,
+      oldestCollectedAt: null,
+      moneyIn: 0,
+      moneyOut: 0,
+      gross: 0,
+      dropPerDay: 0,
+      machineRows: [],
+    };
+
+    locationMachines.forEach(machine => {
+      const machineId = String(machine._id);
+      const { collectedAt, since } = sinceByMachine.get(machineId) as {
+        collectedAt: Date | null;
+        since: Date;
+      };
+      const movement = movementByMachine.get(machineId);
+      const metrics = calculateFinancialMetrics(
+        movement ?? ({} as MovementTotals),
+        formula,
+        scales
+      );
+      const daysSince = Math.max(
+        (now.getTime() - since.getTime()) / 86400000,
+        1 / 24
+      );
+
+      if (!collectedAt) group.neverCollected++;
+      else if (
+        !group.oldestCollectedAt ||
+        collectedAt < group.oldestCollectedAt
+      ) {
+        group.oldestCollectedAt = collectedAt;
+      }
+      group.moneyIn += metrics.moneyIn;
+      group.moneyOut += metrics.moneyOut;
+      group.gross += metrics.gross;
+      group.dropPerDay += metrics.moneyIn / daysSince;
+      group.machineRows.push({
+        machineId,
+        serialNumber:
+          machine.serialNumber?.trim() ||
+          machine.origSerialNumber?.trim() ||
+          machine.custom?.name ||
+          machineId,
+        game: machine.game || '',
+        locationId,
+        locationName: group.locationName,
+        lastCollectedAt: collectedAt,
+        since,
+        daysSince: Math.round(daysSince * 10) / 10,
+        lastReadAt: movement?.lastReadAt ?? null,
+        moneyIn: round(metrics.moneyIn),
+        moneyOut: round(metrics.moneyOut),
+        gross: round(metrics.gross),
+        gamesPlayed: Number(movement?.gamesPlayed) || 0,
+        dropPerDay: round(metrics.moneyIn / daysSince),
+      });
+    });
+
+    totals.moneyIn += group.moneyIn;
+    totals.moneyOut += group.moneyOut;
+    totals.gross += group.gross;
+    groups.push({
+      ...group,
+      moneyIn: round(group.moneyIn),
+      moneyOut: round(group.moneyOut),
+      gross: round(group.gross),
+      dropPerDay: round(group.dropPerDay),
+      machineRows: group.machineRows.sort((a, b) => b.moneyIn - a.moneyIn),
+    });
+  });
+
+  return {
+    generatedAt: now,
+    maxDays,
+    totals: {
+      moneyIn: round(totals.moneyIn),
+      moneyOut: round(totals.moneyOut),
+      gross: round(totals.gross),
+    },
+    locations: groups.sort(
+      (a, b) =>
+        b.dropPerDay - a.dropPerDay ||
+        b.moneyIn - a.moneyIn ||
+        a.locationName.localeCompare(b.locationName)
+    ),
+  };
+}
+
+// ============================================================================
+// Export
+// ============================================================================
+
+function csvCell(value: unknown): string {
+  const text = value === null || value === undefined ? '' : String(value);
+  return /[",\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+}
+
+/**
+ * One CSV row per machine, locations in report order.
+ */
+export function exportUncollectedDropToCSV(
+  report: UncollectedDropReport
+): string {
+  const header = [
+    'Location',
+    'Location Drop Per Day',
+    'Serial Number',
+    'Game',
+    'Last Collected',
+    'Days Since',
+    'Money In',
+    'Money Out',
+    'Gross',
+    'Games Played',
+    'Drop Per Day',
+    'Last Reading',
+  ];
+  const lines = [header.join(',')];
+  report.locations.forEach(location =>
+    location.machineRows.forEach(machine =>
+      lines.push(
+        [
+          location.locationName,
+          location.dropPerDay,
+          machine.serialNumber,
+          machine.game,
+          machine.lastCollectedAt?.toISOString() ?? 'never',
+          machine.daysSince,
+          machine.moneyIn,
+          machine.moneyOut,
+          machine.gross,
+          machine.gamesPlayed,
+          machine.dropPerDay,
+          machine.lastReadAt?.toISOString(),
+        ]
+          .map(csvCell)
+          .join(',')
+      )
+    )
+  );
+  return lines.join('\n');
+}
@@ -0,0 +1,336 @@
+/**
+ * Schema Lint Helper
+ *
+ * Checks stored documents against the fields and types the app expects,
+ * since Mongoose only validates on write through its own models and older
+ * data was written by other systems. Each collection lists its expected
+ * fields; a document violates a field when it is:
+ *
+ * - `missing`    — a required field is absent or null
+ * - `wrong-type` — the value has another BSON type (e.g. `readAt` stored as
+ *   a string); null is allowed for optional fields
+ *
+ * Counts are reported per collection, field and violation, with the types
+ * found and one example `_id`.
+ *
+ * Used by the `schema:lint` command (scripts/schema-lint.ts).
+ *
+ * @module app/api/lib/helpers/schemaLint
+ */
+
+import type { Connection, PipelineStage } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type SchemaFieldType =
+  | 'string'
+  | 'number'
+  | 'date'
+  | 'boolean'
+  | 'object'
+  | 'array';
+
+export type SchemaFieldDefinition = {
+  /** Dotted path into the document */
+  field: string;
+  type: SchemaFieldType;
+  required?: boolean;
+};
+
+export type SchemaViolation = 'missing' | 'wrong-type';
+
+export type SchemaViolationCount = {
+  collection: string;
+  field: string;
+  violation: SchemaViolation;
+  expected: SchemaFieldType;
+  count: number;
+  /** BSON types found in the violating documents */
+  foundTypes: string[];
+  exampleId: string | null;
+};
+
+export type SchemaCollectionReport = {
+  collection: string;
+  /** Documents examined (the sample size when sampling) */
+  examined: number;
+  /** Documents with at least one violation */
+  invalidDocuments: number;
+  violations: SchemaViolationCount[];
+};
+
+export type SchemaLintReport = {
+  checkedAt: Date;
+  sampleSize: number | null;
+  collections: SchemaCollectionReport[];
+  totalViolations: number;
+};
+
+/** BSON `$type` names accepted for each expected type */
+const BSON_TYPES: Record<SchemaFieldType, string[]> = {
+  string: ['string'],
+  number: ['double', 'int', 'long', 'decimal'],
+  date: ['date'],
+  boolean: ['bool'],
+  object: ['object'],
+  array: ['array'],
+};
+
+/** Expected fields of the collections reports and sync depend on */
+export const SCHEMA_DEFINITIONS: Record<string, SchemaFieldDefinition[]> = {
+  machines: [
+    { field: '_id', type: 'string', required: true },
+    { field: 'gamingLocation', type: 'string', required: true },
+    { field: 'serialNumber', type: 'string' },
+    { field: 'relayId', type: 'string' },
+    { field: 'game', type: 'string' },
+    { field: 'sasVersion', type: 'string' },
+    { field: 'lastActivity', type: 'date' },
+    { field: 'sasMeters', type: 'object' },
+    { field: 'sasMeters.coinIn', type: 'number' },
+    { field: 'sasMeters.coinOut', type: 'number' },
+    { field: 'sasMeters.drop', type: 'number' },
+    { field: 'createdAt', type: 'date' },
+    { field: 'deletedAt', type: 'date' },
+  ],
+  meters: [
+    { field: '_id', type: 'string', required: true },
+    { field: 'machine', type: 'string', required: true },
+    { field: 'location', type: 'string', required: true },
+    { field: 'readAt', type: 'date', required: true },
+    { field: 'movement', type: 'object' },
+    { field: 'movement.coinIn', type: 'number' },
+    { field: 'movement.coinOut', type: 'number' },
+    { field: 'movement.drop', type: 'number' },
+    { field: 'movement.totalCancelledCredits', type: 'number' },
+    { field: 'movement.jackpot', type: 'number' },
+    { field: 'movement.gamesPlayed', type: 'number' },
+    { field: 'isRamClear', type: 'boolean' },
+    { field: 'createdAt', type: 'date' },
+    { field: 'deletedAt', type: 'date' },
+  ],
+  gaminglocations: [
+    { field: '_id', type: 'string', required: true },
+    { field: 'name', type: 'string', required: true },
+    { field: 'rel.licencee', type: 'string' },
+    { field: 'country', type: 'string' },
+    { field: 'gameDayOffset', type: 'number' },
+    { field: 'profitShare', type: 'number' },
+    { field: 'previousCollectionTime', type: 'date' },
+    { field: 'createdAt', type: 'date' },
+    { field: 'deletedAt', type: 'date' },
+  ],
+  collections: [
+    { field: '_id', type: 'string', required: true },
+    { field: 'machineId', type: 'string', required: true },
+    { field: 'location', type: 'string', required: true },
+    { field: 'metersIn', type: 'number' },
+    { field: 'metersOut', type: 'number' },
+    { field: 'prevIn', type: 'number' },
+    { field: 'prevOut', type: 'number' },
+    { field: 'isCompleted', type: 'boolean' },
+    { field: 'ramClear', type: 'boolean' },
+    { field: 'locationReportId', type: 'string' },
+    { field: 'timestamp', type: 'date' },
+    { field: 'collectionTime', type: 'date' },
+    { field: 'sasMeters', type: 'object' },
+    { field: 'sasMeters.sasStartTime', type: 'date' },
+    { field: 'sasMeters.sasEndTime', type: 'date' },
+    { field: 'deletedAt', type: 'date' },
+  ],
+  members: [
+    { field: '_id', type: 'string', required: true },
+    { field: 'gamingLocation', type: 'string', required: true },
+    { field: 'username', type: 'string', required: true },
+    { field: 'profile.firstName', type: 'string', required: true },
+    { field: 'profile.lastName', type: 'string', required: true },
+    { field: 'points', type: 'number' },
+    { field: 'uaccount', type: 'number' },
+    { field: 'loggedIn', type: 'boolean' },
+    { field: 'lastLogin', type: 'date' },
+    { field: 'createdAt', type: 'date' },
+    { field: 'deletedAt', type: 'date' },
+  ],
+};
+
+export const SCHEMA_LINT_COLLECTIONS = Object.keys(SCHEMA_DEFINITIONS);
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+/** `$type` of the field, or 'missing' */
+function typeOf(field: string) {
+  return { $type: `$${field}` };
+}
+
+function violationExpression(
+  definition: SchemaFieldDefinition,
+  violation: SchemaViolation
+) {
+  const allowed = BSON_TYPES[definition.type];
+  if (violation === 'missing') {
+    return { $in: [typeOf(definition.field), ['missing', 'null']] };
+  }
+  return {
+    $not: [
+      { $in: [typeOf(definition.field), [...allowed, 'missing', 'null']] },
+    ],
+  };
+}
+
+function violationsFor(definition: SchemaFieldDefinition): SchemaViolation[] {
+  return definition.required ? ['missing', 'wrong-type'] : ['wrong-type'];
+}
+
+async function lintCollection(
+  connection: Connection,
+  collection: string,
+  definitions: SchemaFieldDefinition[],
+  sampleSize: number | null
+): Promise<SchemaCollectionReport> {
+  const checks = definitions.flatMap(definition =>
+    violationsFor(definition).map(violation => ({
+      definition,
+      violation,
+      condition: violationExpression(definition, violation),
+    }))
+  );
+
+  const group: Record<string, unknown> = {
+    _id: null,
+    examined: { $sum: 1 },
+    invalidDocuments: {
+      $sum: {
+        $cond: [{ $or: checks.map(check => check.condition) }, 1, 0],
+      },
+    },
+  };
+  checks.forEach((check, index) => {
+    group[`count${index}`] = { $sum: { $cond: [check.condition, 1, 0] } };
+    group[`types${index}`] = {
+      $addToSet: {
+        $cond: [check.condition, typeOf(check.definition.field), null],
+      },
+    };
+    // $max skips nulls, so this picks one violating document's id
+    group[`example${index}`] = {
+      $max: { $cond: [check.condition, '$_id', null] },
+    };
+  });
+
+  const pipeline: PipelineStage[] = [];
+  if (sampleSize) pipeline.push({ $sample: { size: sampleSize } });
+  pipeline.push({ $group: group } as PipelineStage.Group);
+
+  const [row] = await connection
+    .collection(collection)
+    .aggregate<Record<string, unknown>>(pipeline, { allowDiskUse: true })
+    .toArray();
+
+  const violations: SchemaViolationCount[] = row
+    ? checks
+        .map((check, index) => ({
+          collection,
+          field: check.definition.field,
+          violation: check.violation,
+          expected: check.definition.type,
+          count: Number(row[`count${index}`] || 0),
+          foundTypes: ((row[`types${index}`] as Array<string | null>) || [])
+            .filter((type): type is string => type !== null)
+            .sort(),
+          exampleId:
+            row[`example${index}`] === null ||
+            row[`example${index}`] === undefined
+              ? null
+              : String(row[`example${index}`]),
+        }))
+        .filter(violation => violation.count > 0)
+    : [];
+
+  return {
+    collection,
+    examined: Number(row?.examined || 0),
+    invalidDocuments: Number(row?.invalidDocuments || 0),
+    violations,
+  };
+}
+
+// ============================================================================
+// Runner
+// ============================================================================
+
+/**
+ * Scans collections for documents that miss required fields or store a
+ * field with the wrong type.
+ *
+ * @param connection - Database connection
+ * @param collections - Collections to check (default: all defined)
+ * @param sampleSize - Random documents per collection; null scans everything
+ * @returns Violation counts per collection and field
+ * @throws Error when a collection has no definition
+ */
+export async function getSchemaLintReport(
+  connection: Connection,
+  collections: string[] = SCHEMA_LINT_COLLECTIONS,
+  sampleSize: number | null = null
+): Promise<SchemaLintReport> {
+  const unknown = collections.filter(name => !SCHEMA_DEFINITIONS[name]);
+  if (unknown.length > 0) {
+    throw new Error(
+      `No schema definition for ${unknown.join(', ')}. Available: ${SCHEMA_LINT_COLLECTIONS.join(', ')}`
+    );
+  }
+
+  const results: SchemaCollectionReport[] = [];
+  for (const collection of collections) {
+    results.push(
+      await lintCollection(
+        connection,
+        collection,
+        SCHEMA_DEFINITIONS[collection],
+        sampleSize
+      )
+    );
+  }
+
+  return {
+    checkedAt: new Date(),
+    sampleSize,
+    collections: results,
+    totalViolations: results.reduce(
+      (sum, result) =>
+        sum + result.violations.reduce((total, v) => total + v.count, 0),
+      0
+    ),
+  };
+}
+
+/**
+ * A line per collection, then one per violation, e.g.
+ * `  wrong-type  meters.readAt  4,210  expected date, found string`.
+ */
+export function formatSchemaLintReport(report: SchemaLintReport): string {
+  return report.collections
+    .map(result => {
+      const header = `${result.violations.length > 0 ? 'FAIL' : 'ok  '}  ${
+        result.collection
+      }: ${result.examined} examined, ${result.invalidDocuments} invalid`;
+      const lines = result.violations.map(
+        violation =>
+          `      ${violation.violation.padEnd(10)}  ${violation.field.padEnd(
+            28
+          )}  ${String(violation.count).padStart(8)}  expected ${
+            violation.expected
+          }${
+            violation.violation === 'wrong-type'
+              ? `, found ${violation.foundTypes.join('/')}`
+              : ''
+          }${violation.exampleId ? ` (e.g. ${violation.exampleId})` : ''}`
+      );
+      return [header, ...lines].join('\n');
+    })
+    .join('\n');
+}
@@ -9,7 +9,7 @@
 
 import { GamingLocations } from '../models/gaminglocations';
 import { Machine } from '../models/machines';
-import { differenceInMinutes } from 'date-fns';
+import { getOnlineCutoff } from '../utils/machineStatus';
 import type { GamingLocationDocument } from '@shared/types';
 
 /**
@@ -54,7 +54,7 @@ export type SMIBDiscoveryResult = {
  *
  * @param lastActivity - Last activity date
  * @param updatedAt - Updated at date
- * @returns True if machine is online (activity within last 3 minutes)
+ * @returns True if machine is online (activity within the online threshold)
  */
 function isMachineOnline(
   lastActivity?: Date | string | null,
@@ -70,8 +70,7 @@ function isMachineOnline(
   const hasValidLastActivity =
     !!lastActivityDate && !Number.isNaN(lastActivityDate.getTime());
   const isOnline =
-    hasValidLastActivity &&
-    differenceInMinutes(new Date(), lastActivityDate) <= 3;
+    hasValidLastActivity && lastActivityDate >= getOnlineCutoff();
 
   return {
     isOnline,
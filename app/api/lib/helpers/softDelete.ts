@@ -0,0 +1,261 @@
+/**
+ * Soft Delete Helper
+ *
+ * Deletes and restores machines and locations by setting `deletedAt` to the
+ * deletion time and clearing it back to `null`, instead of hand-setting the
+ * legacy `-1` sentinel (see deletedAtNormalization).
+ *
+ * Cascade rules:
+ * - A location cannot be deleted while it has machines that are not deleted.
+ * - A machine cannot be restored while its location is deleted.
+ *
+ * Deleted means `deletedAt` is a real deletion date (see softDeleteFilters),
+ * so legacy sentinels on older documents still count as not deleted.
+ *
+ * The document is backed up before `deletedAt` changes and every change is
+ * written to the activity log. Used by the `delete` and
+ * `undelete` commands (scripts/soft-delete.ts).
+ *
+ * @module app/api/lib/helpers/softDelete
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { backupBeforeWrite } from '@/app/api/lib/helpers/writeBackups';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { Machine } from '@/app/api/lib/models/machines';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+  isDeleted,
+} from '@/app/api/lib/utils/softDeleteFilters';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type SoftDeleteResource = 'machine' | 'location';
+
+export type SoftDeleteRequest = {
+  resource: SoftDeleteResource;
+  id: string;
+  reason: string;
+  userId: string;
+  username: string;
+};
+
+export type SoftDeleteResult = {
+  resource: SoftDeleteResource;
+  id: string;
+  name: string;
+  deletedAt: Date | null;
+};
+
+type SoftDeletable = {
+  _id: string;
+  name?: string;
+  serialNumber?: string;
+  gamingLocation?: string;
+  deletedAt?: Date | number | null;
+};
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+async function findResource(
+  resource: SoftDeleteResource,
+  id: string
+): Promise<SoftDeletable> {
+  const document =
+    resource === 'machine'
+      ? await Machine.findOne(
+          { _id: id },
+          { _id: 1, serialNumber: 1, gamingLocation: 1, deletedAt: 1 }
+        ).lean<SoftDeletable>()
+      : await GamingLocations.findOne(
+          { _id: id },
+          { _id: 1, name: 1, deletedAt: 1 }
+        ).lean<SoftDeletable>();
+  if (!document) throw statusError(`${resource} ${id} not found`, 404);
+  return document;
+}
+
+/**
+ * Backs up the document before its `deletedAt` changes.
+ */
+async function backupResource(
+  request: SoftDeleteRequest,
+  operation: 'soft-delete' | 'restore'
+): Promise<void> {
+  const model = request.resource === 'machine' ? Machine : GamingLocations;
+  await backupBeforeWrite({
+    operation,
+    collection: model.collection.collectionName,
+    filter: { _id: request.id },
+    by: request.username,
+  });
+}
+
+/**
+ * Sets `deletedAt` on the document matching the filter.
+ *
+ * @returns Whether a document matched
+ */
+async function setDeletedAt(
+  resource: SoftDeleteResource,
+  filter: Record<string, unknown>,
+  deletedAt: Date | null
+): Promise<boolean> {
+  const result =
+    resource === 'machine'
+      ? await Machine.updateOne(filter, { $set: { deletedAt } })
+      : await GamingLocations.updateOne(filter, { $set: { deletedAt } });
+  return result.matchedCount > 0;
+}
+
+// ============================================================================
+// Delete & Restore
+// ============================================================================
+
+/**
+ * Soft-deletes a machine or location. Locations with machines that are not
+ * deleted are refused; delete or move those machines first.
+ *
+ * @param request - Resource, id, reason and acting user
+ * @returns The deleted resource and its new `deletedAt`
+ * @throws Error with `statusCode` 400 (no reason), 404 (not found) or 409 (already deleted / has active machines)
+ */
+export async function softDeleteResource(
+  request: SoftDeleteRequest
+): Promise<SoftDeleteResult> {
+  if (!request.reason.trim()) throw statusError('A reason is required', 400);
+  assertWritable(`deleting a ${request.resource}`);
+
+  const document = await findResource(request.resource, request.id);
+  if (isDeleted(document.deletedAt)) {
+    throw statusError(
+      `${request.resource} ${request.id} is already deleted`,
+      409
+    );
+  }
+
+  if (request.resource === 'location') {
+    const activeMachines = await Machine.countDocuments({
+      gamingLocation: request.id,
+      ...NOT_DELETED_FILTER,
+    });
+    if (activeMachines > 0) {
+      throw statusError(
+        `Location ${request.id} still has ${activeMachines} active machine(s); delete or move them first`,
+        409
+      );
+    }
+  }
+
+  await backupResource(request, 'soft-delete');
+  const deletedAt = new Date();
+  const matched = await setDeletedAt(
+    request.resource,
+    { _id: request.id, ...NOT_DELETED_FILTER },
+    deletedAt
+  );
+  if (!matched) {
+    throw statusError(
+      `${request.resource} ${request.id} changed concurrently; retry`,
+      409
+    );
+  }
+
+  const name = document.name || document.serialNumber || request.id;
+  await logActivity({
+    action: 'delete',
+    details: `Deleted ${request.resource} ${name}: ${request.reason.trim()}`,
+    userId: request.userId,
+    username: request.username,
+    metadata: {
+      resource: request.resource,
+      resourceId: request.id,
+      resourceName: name,
+      changes: [
+        {
+          field: 'deletedAt',
+          oldValue: document.deletedAt ?? null,
+          newValue: deletedAt,
+        },
+      ],
+    },
+  });
+
+  return { resource: request.resource, id: request.id, name, deletedAt };
+}
+
+/**
+ * Restores a soft-deleted machine or location by clearing `deletedAt`.
+ * Machines whose location is deleted are refused; restore the location first.
+ * Restoring a location does not restore its machines.
+ *
+ * @param request - Resource, id, reason and acting user
+ * @returns The restored resource
+ * @throws Error with `statusCode` 400 (no reason), 404 (not found) or 409 (not deleted / location deleted)
+ */
+export async function restoreResource(
+  request: SoftDeleteRequest
+): Promise<SoftDeleteResult> {
+  if (!request.reason.trim()) throw statusError('A reason is required', 400);
+  assertWritable(`restoring a ${request.resource}`);
+
+  const document = await findResource(request.resource, request.id);
+  if (!isDeleted(document.deletedAt)) {
+    throw statusError(`${request.resource} ${request.id} is not deleted`, 409);
+  }
+
+  if (request.resource === 'machine' && document.gamingLocation) {
+    const location = await GamingLocations.findOne(
+      { _id: document.gamingLocation },
+      { deletedAt: 1 }
+    ).lean<{ deletedAt?: Date | number | null }>();
+    if (location && isDeleted(location.deletedAt)) {
+      throw statusError(
+        `Location ${document.gamingLocation} is deleted; restore it first`,
+        409
+      );
+    }
+  }
+
+  await backupResource(request, 'restore');
+  const matched = await setDeletedAt(
+    request.resource,
+    { _id: request.id, ...DELETED_FILTER },
+    null
+  );
+  if (!matched) {
+    throw statusError(
+      `${request.resource} ${request.id} changed concurrently; retry`,
+      409
+    );
+  }
+
+  const name = document.name || document.serialNumber || request.id;
+  await logActivity({
+    action: 'restore',
+    details: `Restored ${request.resource} ${name}: ${request.reason.trim()}`,
+    userId: request.userId,
+    username: request.username,
+    metadata: {
+      resource: request.resource,
+      resourceId: request.id,
+      resourceName: name,
+      changes: [
+        {
+          field: 'deletedAt',
+          oldValue: document.deletedAt ?? null,
+          newValue: null,
+        },
+      ],
+    },
+  });
+
+  return { resource: request.resource, id: request.id, name, deletedAt: null };
+}
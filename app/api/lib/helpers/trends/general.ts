@@ -8,10 +8,19 @@
  */
 
 // Note: Db type from mongodb not imported to avoid mongoose/mongodb version mismatch
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { CollectionReport } from '@/app/api/lib/models/collectionReport';
 import { Meters } from '@/app/api/lib/models/meters';
 import type { TimePeriod } from '@/app/api/lib/types';
 import { getDatesForTimePeriod } from '@/app/api/lib/utils/dates';
+import {
+  addFinancialMetrics,
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
+import type { FinancialMetrics, MovementTotals } from '@shared/types';
 import type { PipelineStage } from 'mongoose';
 
 /**
@@ -30,6 +39,46 @@ export type PlaysTrendItem = {
   gamesPlayed: number;
 };
 
+/**
+ * Movement totals of one licencee in one time bucket
+ */
+type LicenceeBucketTotals = MovementTotals & {
+  _id: { time: string; licencee?: string | null };
+};
+
+/**
+ * Composes each licencee's bucket totals with its own financial formula and
+ * adds them up per time bucket, in the order the buckets arrive.
+ *
+ * @param rows - Totals grouped by time bucket and licencee
+ * @returns Metrics per time bucket
+ */
+async function composeBucketMetrics(
+  rows: LicenceeBucketTotals[]
+): Promise<Map<string, FinancialMetrics>> {
+  const licenceeIds = Array.from(
+    new Set(
+      rows
+        .map(row => row._id.licencee)
+        .filter((id): id is string => Boolean(id))
+    )
+  );
+  const formulas = await getLicenceeFinancialFormulas(licenceeIds);
+
+  const byTime = new Map<string, FinancialMetrics>();
+  rows.forEach(row => {
+    const formula =
+      formulas.get(String(row._id.licencee)) || DEFAULT_FINANCIAL_FORMULA;
+    const metrics = calculateFinancialMetrics(row, formula);
+    const current = byTime.get(row._id.time);
+    byTime.set(
+      row._id.time,
+      current ? addFinancialMetrics(current, metrics) : metrics
+    );
+  });
+  return byTime;
+}
+
 /**
  * Builds aggregation pipeline for win/loss trends
  *
@@ -101,35 +150,25 @@ function buildWinLossTrendsPipeline(
   const dateFormat =
     timePeriod === 'Today' || timePeriod === 'Yesterday' ? '%H:00' : '%Y-%m-%d';
 
+  // Raw movement totals per licencee; win/loss is composed with each
+  // licencee's formula afterwards (see composeBucketMetrics)
   pipeline.push(
     {
       $group: {
         _id: {
-          $dateToString: {
-            format: dateFormat,
-            date: '$readAt',
-          },
-        },
-        winLoss: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
+          time: {
+            $dateToString: {
+              format: dateFormat,
+              date: '$readAt',
+            },
           },
+          licencee: '$locationDetails.rel.licencee',
         },
-        count: { $sum: 1 },
+        ...buildMovementTotalsGroup(),
       },
     },
     {
-      $sort: { _id: 1 },
-    },
-    {
-      $project: {
-        _id: 0,
-        time: '$_id',
-        winLoss: '$winLoss',
-      },
+      $sort: { '_id.time': 1 },
     }
   );
 
@@ -267,12 +306,16 @@ export async function getWinLossTrends(
   );
 
   // Use cursor for Meters aggregation
-  const results: WinLossTrendItem[] = [];
+  const rows: LicenceeBucketTotals[] = [];
   const cursor = Meters.aggregate(pipeline).cursor({ batchSize: 1000 });
   for await (const doc of cursor) {
-    results.push(doc as WinLossTrendItem);
+    rows.push(doc as LicenceeBucketTotals);
   }
-  return results;
+  const byTime = await composeBucketMetrics(rows);
+  return Array.from(byTime, ([time, metrics]) => ({
+    time,
+    winLoss: metrics.gross,
+  }));
 }
 
 /**
@@ -422,10 +465,7 @@ function buildHourlyRevenuePipeline(
       $match: {
         location: locationId,
         readAt: { $gte: start, $lte: end },
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
+        ...NOT_DELETED_FILTER,
       },
     },
     // Stage 2: Group by hour and date to aggregate daily revenue metrics
@@ -601,28 +641,25 @@ function buildHandleTrendsPipeline(
   const dateFormat =
     timePeriod === 'Today' || timePeriod === 'Yesterday' ? '%H:00' : '%Y-%m-%d';
 
+  // Raw movement totals per licencee; handle is each licencee's Money In
+  // (see composeBucketMetrics)
   pipeline.push(
     {
       $group: {
         _id: {
-          $dateToString: {
-            format: dateFormat,
-            date: '$readAt',
+          time: {
+            $dateToString: {
+              format: dateFormat,
+              date: '$readAt',
+            },
           },
+          licencee: '$locationDetails.rel.licencee',
         },
-        handle: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        count: { $sum: 1 },
+        ...buildMovementTotalsGroup(),
       },
     },
     {
-      $sort: { _id: 1 },
-    },
-    {
-      $project: {
-        _id: 0,
-        time: '$_id',
-        handle: '$handle',
-      },
+      $sort: { '_id.time': 1 },
     }
   );
 
@@ -661,12 +698,16 @@ export async function getHandleTrends(
   );
 
   // Use cursor for Meters aggregation
-  const results: HandleTrendItem[] = [];
+  const rows: LicenceeBucketTotals[] = [];
   const cursor = Meters.aggregate(pipeline).cursor({ batchSize: 1000 });
   for await (const doc of cursor) {
-    results.push(doc as HandleTrendItem);
+    rows.push(doc as LicenceeBucketTotals);
   }
-  return results;
+  const byTime = await composeBucketMetrics(rows);
+  return Array.from(byTime, ([time, metrics]) => ({
+    time,
+    handle: metrics.moneyIn,
+  }));
 }
 
 /**
@@ -8,9 +8,16 @@
  */
 
 // Note: Db type from mongodb not imported to avoid mongoose/mongodb version mismatch
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { Meters } from '@/app/api/lib/models/meters';
 import type { TimePeriod } from '@/app/api/lib/types';
 import { getDatesForTimePeriod } from '@/app/api/lib/utils/dates';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import type { FinancialFormula, MovementTotals } from '@shared/types';
 import type { PipelineStage } from 'mongoose';
 
 /**
@@ -25,13 +32,37 @@ export type HourlyDataItem = {
 };
 
 /**
- * Daily revenue item type
+ * Movement totals of one location, optionally per day or hour
  */
-type DailyRevenueItem = {
-  _id: { day: string };
-  dailyRevenue: number;
+type LocationTotalsRow = MovementTotals & {
+  _id: { location: string; day?: string; hour?: number };
 };
 
+/**
+ * Gross of a location's totals under its licencee's formula
+ */
+function locationMetrics(
+  row: LocationTotalsRow,
+  formulas: Map<string, FinancialFormula>
+) {
+  return calculateFinancialMetrics(
+    row,
+    formulas.get(String(row._id.location)) || DEFAULT_FINANCIAL_FORMULA
+  );
+}
+
+async function aggregateLocationTotals(
+  pipeline: PipelineStage[]
+): Promise<LocationTotalsRow[]> {
+  // Use cursor for Meters aggregation
+  const rows: LocationTotalsRow[] = [];
+  const cursor = Meters.aggregate(pipeline).cursor({ batchSize: 1000 });
+  for await (const doc of cursor) {
+    rows.push(doc as LocationTotalsRow);
+  }
+  return rows;
+}
+
 /**
  * Calculates previous period date range
  *
@@ -128,15 +159,8 @@ function buildCurrentPeriodRevenuePipeline(
     },
     {
       $group: {
-        _id: null,
-        totalRevenue: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
-          },
-        },
+        _id: { location: '$location' },
+        ...buildMovementTotalsGroup(),
       },
     },
   ];
@@ -172,16 +196,10 @@ function buildPreviousPeriodPipeline(
     {
       $group: {
         _id: {
+          location: '$location',
           day: { $dateToString: { format: '%Y-%m-%d', date: '$readAt' } },
         },
-        dailyRevenue: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
-          },
-        },
+        ...buildMovementTotalsGroup(),
       },
     },
   ];
@@ -247,6 +265,8 @@ function buildHourlyTrendsPipeline(
     } as PipelineStage);
   }
 
+  // Raw movement totals; revenue, drop and cancelled credits are composed
+  // with each location's formula in getHourlyTrends
   pipeline.push(
     {
       $group: {
@@ -254,31 +274,10 @@ function buildHourlyTrendsPipeline(
           location: '$location',
           hour: { $hour: '$readAt' },
         },
-        revenue: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
-          },
-        },
-        drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        cancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
+        ...buildMovementTotalsGroup(),
       },
     },
-    { $sort: { '_id.location': 1, '_id.hour': 1 } },
-    {
-      $project: {
-        _id: 0,
-        location: '$_id.location',
-        hour: '$_id.hour',
-        revenue: '$revenue',
-        drop: '$drop',
-        cancelledCredits: '$cancelledCredits',
-      },
-    }
+    { $sort: { '_id.location': 1, '_id.hour': 1 } }
   );
 
   return pipeline;
@@ -421,56 +420,41 @@ export async function getHourlyTrends(
     ? locationIds.split(',').map(id => id.trim())
     : [locationId!];
 
-  const currentPipeline = buildCurrentPeriodRevenuePipeline(
-    targetLocations,
-    startDate,
-    endDate
+  const formulas = await getLocationFinancialFormulas(targetLocations);
+
+  const currentRows = await aggregateLocationTotals(
+    buildCurrentPeriodRevenuePipeline(targetLocations, startDate, endDate)
+  );
+  const currentPeriodRevenue = currentRows.reduce(
+    (sum, row) => sum + locationMetrics(row, formulas).gross,
+    0
   );
-  // Use cursor for Meters aggregation
-  const currentResult: Array<{ totalRevenue?: number }> = [];
-  const currentCursor = Meters.aggregate(currentPipeline).cursor({
-    batchSize: 1000,
-  });
-  for await (const doc of currentCursor) {
-    currentResult.push(doc as { totalRevenue?: number });
-  }
-  const currentPeriodRevenue = currentResult[0]?.totalRevenue || 0;
 
   const days = 7;
   const { prevStart, prevEnd } = getPreviousPeriod(startDate, endDate, days);
-  const prevPipeline = buildPreviousPeriodPipeline(
-    targetLocations,
-    prevStart,
-    prevEnd
+  const prevRows = await aggregateLocationTotals(
+    buildPreviousPeriodPipeline(targetLocations, prevStart, prevEnd)
   );
-  // Use cursor for Meters aggregation
-  const prevResult: DailyRevenueItem[] = [];
-  const prevCursor = Meters.aggregate(prevPipeline).cursor({ batchSize: 1000 });
-  for await (const doc of prevCursor) {
-    prevResult.push(doc as DailyRevenueItem);
-  }
-  const prevDays = prevResult.length;
-  const prevTotal = prevResult.reduce(
-    (sum: number, dayRevenue: DailyRevenueItem) =>
-      sum + (dayRevenue.dailyRevenue || 0),
+  const prevDays = new Set(prevRows.map(row => row._id.day)).size;
+  const prevTotal = prevRows.reduce(
+    (sum, row) => sum + locationMetrics(row, formulas).gross,
     0
   );
   const previousPeriodAverage = prevDays > 0 ? prevTotal / prevDays : 0;
 
-  const hourlyPipeline = buildHourlyTrendsPipeline(
-    targetLocations,
-    startDate,
-    endDate,
-    licencee
+  const hourlyRows = await aggregateLocationTotals(
+    buildHourlyTrendsPipeline(targetLocations, startDate, endDate, licencee)
   );
-  // Use cursor for Meters aggregation
-  const hourlyData: HourlyDataItem[] = [];
-  const hourlyCursor = Meters.aggregate(hourlyPipeline).cursor({
-    batchSize: 1000,
+  const hourlyData: HourlyDataItem[] = hourlyRows.map(row => {
+    const metrics = locationMetrics(row, formulas);
+    return {
+      location: row._id.location,
+      hour: row._id.hour ?? 0,
+      revenue: metrics.gross,
+      drop: metrics.moneyIn,
+      cancelledCredits: metrics.moneyOut,
+    };
   });
-  for await (const doc of hourlyCursor) {
-    hourlyData.push(doc as HourlyDataItem);
-  }
 
   return {
     currentPeriodRevenue,
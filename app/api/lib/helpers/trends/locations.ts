@@ -8,11 +8,24 @@
  * @module app/api/lib/helpers/locationTrends
  */
 
+import {
+  getLicenceeMachineStatus,
+  getLocationFinancialFormulas,
+} from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { Countries } from '@/app/api/lib/models/countries';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import {
   convertFromUSD,
@@ -26,6 +39,7 @@ import type {
   GamingLocationDocument,
   GamingMachine,
   LicenceeDocument,
+  MovementTotals,
   TimePeriod,
 } from '@/shared/types';
 import type { CurrencyCode } from '@/shared/types/currency';
@@ -50,6 +64,18 @@ export type DailyTrendItem = {
   netGross?: number;
 };
 
+/**
+ * Location bucket from the trends pipeline, before the formula is applied
+ */
+type LocationTrendTotals = MovementTotals & {
+  day: string;
+  time?: string;
+  location: string;
+  handle: number;
+  winLoss: number;
+  plays: number;
+};
+
 /**
  * Determine aggregation granularity for custom time ranges
  */
@@ -267,18 +293,6 @@ function buildLocationTrendsPipeline(
     {
       $unwind: { path: '$locationDetails', preserveNullAndEmptyArrays: true },
     },
-    // Lookup licencee to get includeJackpot setting
-    {
-      $lookup: {
-        from: 'licencees',
-        localField: 'locationDetails.rel.licencee',
-        foreignField: '_id',
-        as: 'licenceeDetails',
-      },
-    },
-    {
-      $unwind: { path: '$licenceeDetails', preserveNullAndEmptyArrays: true },
-    },
   ];
 
   if (licencee && licencee !== 'all') {
@@ -405,20 +419,8 @@ function buildLocationTrendsPipeline(
             ],
           },
         },
-        jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
         plays: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
-        drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
-        gross: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
-          },
-        },
+        ...buildMovementTotalsGroup(),
       },
     } as PipelineStage,
     { $sort: { '_id.day': 1, '_id.time': 1 } } as PipelineStage,
@@ -430,33 +432,8 @@ function buildLocationTrendsPipeline(
         location: '$_id.location',
         handle: 1,
         winLoss: 1,
-        jackpot: 1,
         plays: 1,
-        drop: 1,
-        totalCancelledCredits: 1,
-        gross: 1,
-        netGross: {
-          $cond: [
-            {
-              $eq: [
-                { $ifNull: ['$licenceeDetails.includeJackpot', false] },
-                true,
-              ],
-            },
-            {
-              $subtract: [
-                {
-                  $subtract: [
-                    { $ifNull: ['$movement.drop', 0] },
-                    { $ifNull: ['$movement.totalCancelledCredits', 0] },
-                  ],
-                },
-                { $ifNull: ['$movement.jackpot', 0] },
-              ],
-            },
-            { $literal: undefined },
-          ],
-        },
+        ...Object.fromEntries(METER_MOVEMENT_FIELDS.map(field => [field, 1])),
       },
     } as PipelineStage
   );
@@ -480,12 +457,7 @@ async function getLocationCurrencies(
   }
 
   const licenceesData = await Licencee.find(
-    {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    },
+    { ...NOT_DELETED_FILTER },
     { _id: 1, name: 1 }
   )
     .lean<LicenceeDocument[]>()
@@ -1078,17 +1050,15 @@ export async function getLocationTrends(
   // Implement the same Archive logic as the Location Detail API
   const machineQuery: Record<string, unknown> = {
     gamingLocation: { $in: targetLocations },
+    ...NOT_RETIRED_FILTER,
   };
 
   if (!includeArchived) {
-    // Only Active machines (null or legacy date < 2025)
-    (machineQuery as { $or?: unknown[] }).$or = [
-      { deletedAt: null },
-      { deletedAt: { $lt: new Date('2025-01-01') } },
-    ];
+    // Only Active machines
+    machineQuery.deletedAt = NOT_DELETED_FILTER.deletedAt;
   } else {
     // Show everything (Active AND Archived)
-    // Archived is >= 2025-01-01
+    // Archived has deletedAt set
     (machineQuery as { $or?: unknown[] }).$or = [
       { deletedAt: null },
       { deletedAt: { $exists: true } },
@@ -1121,13 +1091,17 @@ export async function getLocationTrends(
   // Apply Status logic (Online/Offline/Never Online)
   const normalizedStatus = status?.toLowerCase();
   if (normalizedStatus === 'online') {
-    const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
-    andClauses.push({ lastActivity: { $gte: threeMinutesAgo } });
+    const onlineCutoff = getOnlineCutoff(
+      await getLicenceeMachineStatus(licencee)
+    );
+    andClauses.push({ lastActivity: { $gte: onlineCutoff } });
   } else if (normalizedStatus === 'offline') {
-    const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+    const onlineCutoff = getOnlineCutoff(
+      await getLicenceeMachineStatus(licencee)
+    );
     andClauses.push({
       $or: [
-        { lastActivity: { $lt: threeMinutesAgo } },
+        { lastActivity: { $lt: onlineCutoff } },
         { lastActivity: { $exists: false } },
         { lastActivity: null },
       ],
@@ -1167,15 +1141,37 @@ export async function getLocationTrends(
   );
 
   // Use cursor for Meters aggregation (even though grouped, still use cursor for consistency)
-  const dailyData: DailyTrendItem[] = [];
+  const dailyTotals: LocationTrendTotals[] = [];
   const dailyDataCursor = Meters.aggregate(pipeline).cursor({
     batchSize: 1000,
   });
 
   for await (const doc of dailyDataCursor) {
-    dailyData.push(doc as DailyTrendItem);
+    dailyTotals.push(doc as LocationTrendTotals);
   }
 
+  // Compose drop, cancelled credits and gross with each location's formula;
+  // net gross is only reported for licencees that include jackpot
+  const formulas = await getLocationFinancialFormulas(targetLocations);
+  const dailyData: DailyTrendItem[] = dailyTotals.map(item => {
+    const formula =
+      formulas.get(String(item.location)) || DEFAULT_FINANCIAL_FORMULA;
+    const metrics = calculateFinancialMetrics(item, formula);
+    return {
+      day: item.day,
+      time: item.time,
+      location: item.location,
+      handle: item.handle,
+      winLoss: item.winLoss,
+      jackpot: metrics.jackpot,
+      plays: item.plays,
+      drop: metrics.moneyIn,
+      totalCancelledCredits: metrics.moneyOut,
+      gross: metrics.gross,
+      netGross: formula.includeJackpot ? metrics.netGross : undefined,
+    };
+  });
+
   console.log(
     `[Location Trends] Meters aggregation returned ${dailyData.length} bucket(s)`
   );
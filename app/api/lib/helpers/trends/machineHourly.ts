@@ -8,10 +8,17 @@
  * @module app/api/lib/helpers/machineHourly
  */
 
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { Countries } from '@/app/api/lib/models/countries';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import {
   convertFromUSD,
@@ -23,6 +30,7 @@ import type {
   CountryDocument,
   GamingLocationDocument,
   LicenceeDocument,
+  MovementTotals,
   TimePeriod,
 } from '@/shared/types';
 import type { StackedData } from '@/shared/types/analytics';
@@ -43,6 +51,18 @@ export type HourlyDataItem = {
   gross: number;
 };
 
+/**
+ * Movement totals of one machine hour, before the formula is applied
+ */
+type MachineHourTotals = MovementTotals & {
+  hour: number;
+  machine: string;
+  location: string;
+  handle: number;
+  winLoss: number;
+  plays: number;
+};
+
 /**
  * Build aggregation pipeline for machine hourly data
  */
@@ -118,20 +138,8 @@ function buildMachineHourlyPipeline(
             ],
           },
         },
-        jackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
         plays: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
-        drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
-        gross: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
-          },
-        },
+        ...buildMovementTotalsGroup(),
       },
     } as PipelineStage,
     { $sort: { '_id.hour': 1, '_id.machine': 1 } } as PipelineStage,
@@ -143,11 +151,14 @@ function buildMachineHourlyPipeline(
         location: '$_id.location',
         handle: 1,
         winLoss: 1,
-        jackpot: 1,
         plays: 1,
         drop: 1,
         totalCancelledCredits: 1,
-        gross: 1,
+        totalHandPaidCancelledCredits: 1,
+        jackpot: 1,
+        coinIn: 1,
+        coinOut: 1,
+        totalWonCredits: 1,
       },
     } as PipelineStage
   );
@@ -284,12 +295,7 @@ async function getLocationCurrenciesForMachineHourly(
   }
 
   const licenceesData = await Licencee.find(
-    {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    },
+    { ...NOT_DELETED_FILTER },
     { _id: 1, name: 1 }
   )
     .lean<LicenceeDocument[]>()
@@ -501,14 +507,37 @@ export async function getMachineHourlyData(
   );
 
   // Use cursor for Meters aggregation
-  const hourlyData: HourlyDataItem[] = [];
+  const hourlyTotals: MachineHourTotals[] = [];
   const hourlyDataCursor = Meters.aggregate(pipeline).cursor({
     batchSize: 1000,
   });
   for await (const doc of hourlyDataCursor) {
-    hourlyData.push(doc as HourlyDataItem);
+    hourlyTotals.push(doc as MachineHourTotals);
   }
 
+  // Compose drop, cancelled credits and gross with each location's formula
+  const formulas = await getLocationFinancialFormulas([
+    ...new Set(hourlyTotals.map(item => String(item.location))),
+  ]);
+  const hourlyData: HourlyDataItem[] = hourlyTotals.map(item => {
+    const metrics = calculateFinancialMetrics(
+      item,
+      formulas.get(String(item.location)) || DEFAULT_FINANCIAL_FORMULA
+    );
+    return {
+      hour: item.hour,
+      machine: item.machine,
+      location: item.location,
+      handle: item.handle,
+      winLoss: item.winLoss,
+      jackpot: metrics.jackpot,
+      plays: item.plays,
+      drop: metrics.moneyIn,
+      totalCancelledCredits: metrics.moneyOut,
+      gross: metrics.gross,
+    };
+  });
+
   // Group data by location
   const locationHourlyData = groupHourlyDataByLocation(hourlyData);
 
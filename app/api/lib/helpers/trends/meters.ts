@@ -8,11 +8,25 @@
  * @module app/api/lib/helpers/meterTrends
  */
 
+import {
+  getLicenceeFinancialFormulas,
+  getLicenceeMachineStatus,
+} from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { Countries } from '@/app/api/lib/models/countries';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Licencee } from '@/app/api/lib/models/licencee';
 import { Machine } from '@/app/api/lib/models/machines';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  addMovementTotals,
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { shouldApplyCurrencyConversion } from '@/lib/helpers/currencyConversion';
 import {
   convertFromUSD,
@@ -22,28 +36,27 @@ import {
 import { getGamingDayRangesForLocations } from '@/lib/utils/gamingDayRange';
 import type {
   CountryDocument,
+  FinancialFormula,
   GamingLocationDocument,
   GamingMachine,
   LicenceeDocument,
+  MovementTotals,
 } from '@/shared/types';
 import type { CurrencyCode } from '@/shared/types/currency';
 // Note: Db type from mongodb not imported to avoid mongoose/mongodb version mismatch
 // Functions accept 'any' for db parameter to handle version differences
 import { ObjectId } from 'mongodb';
 import type { PipelineStage } from 'mongoose';
+import { findByAnyIdType } from '@/app/api/lib/utils/mongoIds';
 import { getUserLocationFilter } from '../licenceeFilter';
 
 /**
- * Meter trend metric item
+ * Meter trend metric item (raw movement totals, denomination applied)
  */
-type MeterTrendMetric = {
+type MeterTrendMetric = MovementTotals & {
   day: string;
   time: string;
-  drop: number;
-  totalCancelledCredits: number;
-  gross: number;
   gamesPlayed: number;
-  jackpot: number;
   licencee?: string | null;
   country?: string | null;
   location?: string;
@@ -74,6 +87,26 @@ type LocationData = {
   rel?: { licencee?: unknown };
 };
 
+/**
+ * Movement fields of a pipeline row, multiplied by the machine's
+ * accounting denomination
+ */
+function scaleMovementTotals(
+  row: Record<string, unknown>,
+  denom: number
+): MovementTotals {
+  const totals = addMovementTotals({}, row);
+  METER_MOVEMENT_FIELDS.forEach(field => {
+    totals[field] = (totals[field] || 0) * denom;
+  });
+  return totals;
+}
+
+/** Keeps every summed movement field in a `$project` stage */
+const MOVEMENT_TOTALS_PROJECTION = Object.fromEntries(
+  METER_MOVEMENT_FIELDS.map(field => [field, 1])
+);
+
 /**
  * Validates custom date range
  *
@@ -324,36 +357,18 @@ function buildLocationMetricsPipeline(
           day: '$day',
           time: '$time',
         },
-        totalDrop: { $sum: '$movement.drop' },
-        totalCancelledCredits: {
-          $sum: '$movement.totalCancelledCredits',
-        },
-        totalJackpot: { $sum: '$movement.jackpot' },
+        ...buildMovementTotalsGroup(),
         totalGamesPlayed: { $sum: '$movement.gamesPlayed' },
       },
     },
     {
       $project: {
         _id: 0,
+        machine: '$_id.machine',
         day: '$_id.day',
         time: '$_id.time',
-        drop: { $ifNull: ['$totalDrop', 0] },
-        totalCancelledCredits: {
-          $ifNull: ['$totalCancelledCredits', 0],
-        },
-        gross: {
-          $subtract: [
-            {
-              $subtract: [
-                { $ifNull: ['$totalDrop', 0] },
-                { $ifNull: ['$totalJackpot', 0] },
-              ],
-            },
-            { $ifNull: ['$totalCancelledCredits', 0] },
-          ],
-        },
+        ...MOVEMENT_TOTALS_PROJECTION,
         gamesPlayed: { $ifNull: ['$totalGamesPlayed', 0] },
-        jackpot: { $ifNull: ['$totalJackpot', 0] },
       },
     },
     { $sort: { day: 1, time: 1 } },
@@ -465,11 +480,7 @@ async function processLocationMetricsSingleAggregation(
           day: '$day',
           time: '$time',
         },
-        totalDrop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        totalCancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
-        totalJackpot: { $sum: { $ifNull: ['$movement.jackpot', 0] } },
+        ...buildMovementTotalsGroup(),
         totalGamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
         minReadAt: { $min: '$readAt' }, // Keep for filtering by gaming day range
         maxReadAt: { $max: '$readAt' }, // Keep for filtering by gaming day range
@@ -481,16 +492,8 @@ async function processLocationMetricsSingleAggregation(
         machine: '$_id.machine',
         day: '$_id.day',
         time: '$_id.time',
-        drop: '$totalDrop',
-        totalCancelledCredits: '$totalCancelledCredits',
-        gross: {
-          $subtract: [
-            { $subtract: ['$totalDrop', '$totalJackpot'] },
-            '$totalCancelledCredits',
-          ],
-        },
+        ...MOVEMENT_TOTALS_PROJECTION,
         gamesPlayed: '$totalGamesPlayed',
-        jackpot: '$totalJackpot',
         minReadAt: 1,
         maxReadAt: 1,
       },
@@ -510,15 +513,11 @@ async function processLocationMetricsSingleAggregation(
   }).cursor({ batchSize: 5000 });
 
   for await (const metricDoc of cursor) {
-    const metric = metricDoc as {
+    const metric = metricDoc as MovementTotals & {
       machine: string;
       day: string;
       time: string;
-      drop?: number;
-      totalCancelledCredits?: number;
-      gross?: number;
       gamesPlayed?: number;
-      jackpot?: number;
       minReadAt: Date;
       maxReadAt: Date;
     };
@@ -552,31 +551,24 @@ async function processLocationMetricsSingleAggregation(
     const location = locationById.get(locationId);
     if (!location) continue;
 
-    const denom = denomMap.get(machineId) || 1;
-    const drop = (metric.drop || 0) * denom;
-    const totalCancelledCredits = (metric.totalCancelledCredits || 0) * denom;
-    const jackpot = (metric.jackpot || 0) * denom;
-    const gross = (metric.gross || 0) * denom;
+    const totals = scaleMovementTotals(
+      metric,
+      denomMap.get(machineId) || 1
+    );
 
     // Group by location/day/time
     const key = `${locationId}_${metric.day}_${metric.time}`;
     const existing = locationMetricsMap.get(key);
 
     if (existing) {
-      existing.drop += drop;
-      existing.totalCancelledCredits += totalCancelledCredits;
-      existing.gross += gross;
+      addMovementTotals(existing, totals);
       existing.gamesPlayed += metric.gamesPlayed || 0;
-      existing.jackpot += jackpot;
     } else {
       locationMetricsMap.set(key, {
+        ...totals,
         day: metric.day,
         time: metric.time,
-        drop: drop,
-        totalCancelledCredits: totalCancelledCredits,
-        gross: gross,
         gamesPlayed: metric.gamesPlayed || 0,
-        jackpot: jackpot,
         licencee:
           typeof location.rel?.licencee === 'string'
             ? location.rel.licencee
@@ -656,31 +648,25 @@ async function processLocationMetricsBatches(
           shouldUseMinute
         );
 
-        type PipelineMetric = {
+        type PipelineMetric = MovementTotals & {
           machine: string;
           day: string;
           time: string;
-          drop: number;
-          totalCancelledCredits: number;
-          gross: number;
           gamesPlayed: number;
-          jackpot: number;
         };
 
-        const results: PipelineMetric[] = [];
+        const results: MeterTrendMetric[] = [];
         const resultsCursor = Meters.aggregate<PipelineMetric>(pipeline, {
           allowDiskUse: true,
           hint: { machine: 1, readAt: 1 },
         }).cursor({ batchSize: 5000 });
 
         for await (const doc of resultsCursor) {
-          const denom = denomMap.get(doc.machine) || 1;
           results.push({
-            ...doc,
-            drop: doc.drop * denom,
-            totalCancelledCredits: doc.totalCancelledCredits * denom,
-            gross: doc.gross * denom,
-            jackpot: doc.jackpot * denom,
+            ...scaleMovementTotals(doc, denomMap.get(doc.machine) || 1),
+            day: doc.day,
+            time: doc.time,
+            gamesPlayed: doc.gamesPlayed,
           });
         }
 
@@ -739,25 +725,12 @@ async function loadCurrencyMetadata(
   );
 
   if (licenceeIds.length > 0) {
-    // Use Mongoose model with lean() for read-only query
-    const licenceeDocs = await Licencee.find(
-      {
-        _id: {
-          $in: licenceeIds
-            .map(id => {
-              try {
-                return new ObjectId(id);
-              } catch {
-                return null;
-              }
-            })
-            .filter((id): id is ObjectId => id !== null),
-        },
-      },
-      { name: 1 }
-    )
-      .lean<LicenceeDocument[]>()
-      .exec();
+    // Ids may be stored as strings or ObjectIds; match both
+    const licenceeDocs = await findByAnyIdType<LicenceeDocument>(
+      Licencee,
+      licenceeIds,
+      { projection: { name: 1 } }
+    );
 
     licenceeDocs.forEach(doc => {
       licenceeIdToName.set(String(doc._id), doc.name);
@@ -773,25 +746,12 @@ async function loadCurrencyMetadata(
   );
 
   if (countryIds.length > 0) {
-    // Use Mongoose model with lean() for read-only query
-    const countryDocs = await Countries.find(
-      {
-        _id: {
-          $in: countryIds
-            .map(id => {
-              try {
-                return new ObjectId(id);
-              } catch {
-                return null;
-              }
-            })
-            .filter((id): id is ObjectId => id !== null),
-        },
-      },
-      { name: 1 }
-    )
-      .lean<CountryDocument[]>()
-      .exec();
+    // Ids may be stored as strings or ObjectIds; match both
+    const countryDocs = await findByAnyIdType<CountryDocument>(
+      Countries,
+      countryIds,
+      { projection: { name: 1 } }
+    );
 
     countryDocs.forEach(doc => {
       countryIdToName.set(String(doc._id), doc.name);
@@ -809,6 +769,7 @@ async function loadCurrencyMetadata(
  * @param displayCurrency - Display currency code
  * @param licenceeIdToName - Map of licencee ID to name
  * @param countryIdToName - Map of country ID to name
+ * @param formulas - Financial formula per licencee ID
  * @returns Array of aggregated metrics
  */
 function aggregateMetricsWithConversion(
@@ -817,6 +778,7 @@ function aggregateMetricsWithConversion(
   displayCurrency: CurrencyCode,
   licenceeIdToName: Map<string, string>,
   countryIdToName: Map<string, string>,
+  formulas: Map<string, FinancialFormula>,
   moneyInScale: number,
   moneyOutScale: number
 ): AggregatedMetric[] {
@@ -868,6 +830,12 @@ function aggregateMetricsWithConversion(
     const time = metric.time ?? '00:00';
     const key = `${day}__${time}`;
     const gamesPlayedValue = Number(metric.gamesPlayed ?? 0);
+    const metrics = calculateFinancialMetrics(
+      metric,
+      (metric.licencee && formulas.get(metric.licencee)) ||
+        DEFAULT_FINANCIAL_FORMULA,
+      { moneyInScale, moneyOutScale }
+    );
 
     if (shouldConvert) {
       let nativeCurrency = 'USD';
@@ -881,55 +849,39 @@ function aggregateMetricsWithConversion(
           : 'USD';
       }
 
-      const dropUSD = convertToUSD(Number(metric.drop) || 0, nativeCurrency);
-      const cancelledUSD = convertToUSD(
-        Number(metric.totalCancelledCredits) || 0,
-        nativeCurrency
-      );
-      const jackpotUSD = convertToUSD(
-        Number(metric.jackpot) || 0,
-        nativeCurrency
-      );
-
-      const convertedDrop = nativeCurrency !== displayCurrency
-        ? Math.round(convertFromUSD(dropUSD, displayCurrency) * 100) / 100
-        : Number(metric.drop) || 0;
-      const convertedCancelled = nativeCurrency !== displayCurrency
-        ? Math.round(convertFromUSD(cancelledUSD, displayCurrency) * 100) / 100
-        : Number(metric.totalCancelledCredits) || 0;
-      const convertedJackpot = nativeCurrency !== displayCurrency
-        ? Math.round(convertFromUSD(jackpotUSD, displayCurrency) * 100) / 100
-        : Number(metric.jackpot) || 0;
+      const convert = (value: number) =>
+        nativeCurrency !== displayCurrency
+          ? Math.round(
+              convertFromUSD(
+                convertToUSD(value, nativeCurrency),
+                displayCurrency
+              ) * 100
+            ) / 100
+          : value;
 
-      const scaledDrop = convertedDrop * moneyInScale;
-      const scaledCancelled = convertedCancelled * moneyOutScale;
-      const scaledJackpot = convertedJackpot * moneyOutScale;
+      const convertedMoneyIn = convert(metrics.moneyIn);
+      const convertedMoneyOut = convert(metrics.moneyOut);
 
       accumulator(
         key,
         day,
         time,
-        scaledDrop,
-        scaledCancelled,
-        scaledDrop - scaledCancelled - scaledJackpot,
+        convertedMoneyIn,
+        convertedMoneyOut,
+        convertedMoneyIn - convertedMoneyOut,
         gamesPlayedValue,
-        scaledJackpot
+        convert(metrics.jackpot)
       );
     } else {
-      const dropValue = (Number(metric.drop) || 0) * moneyInScale;
-      const cancelledValue =
-        (Number(metric.totalCancelledCredits) || 0) * moneyOutScale;
-      const jackpotValue = (Number(metric.jackpot) || 0) * moneyOutScale;
-
       accumulator(
         key,
         day,
         time,
-        dropValue,
-        cancelledValue,
-        dropValue - cancelledValue - jackpotValue,
+        metrics.moneyIn,
+        metrics.moneyOut,
+        metrics.gross,
         gamesPlayedValue,
-        jackpotValue
+        metrics.jackpot
       );
     }
   }
@@ -1012,9 +964,7 @@ export async function getMeterTrends(
     }
   }
 
-  const locationQuery: Record<string, unknown> = {
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
-  };
+  const locationQuery: Record<string, unknown> = { ...NOT_DELETED_FILTER };
 
   if (licencee) {
     if (!locationQuery.$and) {
@@ -1115,7 +1065,8 @@ export async function getMeterTrends(
   // Build machine query with filters
   const machineQuery: Record<string, unknown> = {
     gamingLocation: { $in: locationIdStrings },
-    $or: [{ deletedAt: null }, { deletedAt: { $lt: new Date('2025-01-01') } }],
+    ...NOT_DELETED_FILTER,
+    ...NOT_RETIRED_FILTER,
   };
 
   // Apply game type filter
@@ -1138,10 +1089,12 @@ export async function getMeterTrends(
 
   // Apply status filter
   if (onlineStatus && onlineStatus !== 'all') {
-    const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+    const onlineCutoff = getOnlineCutoff(
+      await getLicenceeMachineStatus(licencee)
+    );
 
     if (onlineStatus === 'online' || onlineStatus === 'Online') {
-      machineQuery.lastActivity = { $gte: threeMinutesAgo };
+      machineQuery.lastActivity = { $gte: onlineCutoff };
     } else if (onlineStatus === 'offline' || onlineStatus === 'Offline') {
       // Use $and to combine with existing query filters
       if (!machineQuery.$and) {
@@ -1151,7 +1104,7 @@ export async function getMeterTrends(
       (machineQuery.$and as unknown[]).push({
         $or: [
           { lastActivity: { $exists: false } },
-          { lastActivity: { $lt: threeMinutesAgo } },
+          { lastActivity: { $lt: onlineCutoff } },
         ],
       });
     }
@@ -1194,17 +1147,21 @@ export async function getMeterTrends(
     _id: 1,
     gamingLocation: 1,
     'gameConfig.accountingDenomination': 1,
+    meterUnit: 1,
   }).lean<GamingMachine[]>();
 
+  // Machines with an explicit meterUnit are already converted to dollars by
+  // the meters aggregation hook (see utils/meterUnits)
   const denomMap = new Map<string, number>();
   machineDocs.forEach(m => {
     const machine = m as {
       _id: unknown;
       gameConfig?: { accountingDenomination?: number };
+      meterUnit?: string;
     };
     denomMap.set(
       String(m._id),
-      machine.gameConfig?.accountingDenomination || 1
+      machine.meterUnit ? 1 : machine.gameConfig?.accountingDenomination || 1
     );
   });
 
@@ -1246,12 +1203,23 @@ export async function getMeterTrends(
     countryIdToName = metadata.countryIdToName;
   }
 
+  const formulas = await getLicenceeFinancialFormulas(
+    Array.from(
+      new Set(
+        metricsPerLocation
+          .map(metric => metric.licencee)
+          .filter((id): id is string => Boolean(id))
+      )
+    )
+  );
+
   const aggregatedMetrics = aggregateMetricsWithConversion(
     metricsPerLocation,
     shouldConvert,
     displayCurrency,
     licenceeIdToName,
     countryIdToName,
+    formulas,
     moneyInScale,
     moneyOutScale
   );
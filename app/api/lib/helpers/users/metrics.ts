@@ -7,16 +7,48 @@
  * @module app/api/lib/helpers/userMetrics
  */
 
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { fetchRollupLocations } from '@/app/api/lib/helpers/metersDaily';
 import { connectDB } from '@/app/api/lib/middleware/db';
+import { Meters } from '@/app/api/lib/models/meters';
+import UserModel from '@/app/api/lib/models/user';
+import {
+  addMovementTotals,
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import { anyIdTypeIn, normalizeId } from '@/app/api/lib/utils/mongoIds';
+import { loadMetricsTimeframes } from '@/app/api/lib/utils/metricsTimeframes';
+import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import type { MovementTotals, UserDocument } from '@shared/types';
+
+export type HourlyMetricsBucket = {
+  /** Start of the hour (UTC) */
+  hour: Date;
+  drop: number;
+  cancelledCredits: number;
+  gross: number;
+};
+
+export type TodayHourlyMetrics = {
+  /** 24 buckets per location, from the start of its gaming day */
+  locations: Array<{ location: string; buckets: HourlyMetricsBucket[] }>;
+  /** All locations summed per hour */
+  total: HourlyMetricsBucket[];
+};
+
+const HOUR_MS = 60 * 60 * 1000;
 
 /**
- * Timeframe key mapping
+ * Breakdowns the metrics route can add, and the time period each needs
  */
-const timeframeKeyMap: Record<string, string> = {
-  '7d': 'last7Days',
-  '30d': 'last30Days',
-  Today: 'Today',
-  Yesterday: 'Yesterday',
+const granularityPeriods: Record<string, string> = {
+  hourly: 'Today',
 };
 
 /**
@@ -30,11 +62,39 @@ export function validateTimePeriod(timePeriod: string | null): string | null {
     return 'Missing timePeriod parameter';
   }
 
-  if (!timeframeKeyMap[timePeriod]) {
+  // Configured timeframes (see metricsTimeframes), built-ins included
+  const timeframes = loadMetricsTimeframes();
+  if (!timeframes[timePeriod]) {
     return `Invalid timePeriod parameter. Expected one of: ${Object.keys(
-      timeframeKeyMap
+      timeframes
+    ).join(', ')}`;
+  }
+
+  return null;
+}
+
+/**
+ * Validates the optional granularity parameter against the time period
+ *
+ * @param granularity - Granularity string (e.g. 'hourly')
+ * @param timePeriod - Validated time period
+ * @returns Validation error message or null if valid
+ */
+export function validateGranularity(
+  granularity: string | null,
+  timePeriod: string
+): string | null {
+  if (!granularity) return null;
+
+  const period = granularityPeriods[granularity];
+  if (!period) {
+    return `Invalid granularity parameter. Expected one of: ${Object.keys(
+      granularityPeriods
     ).join(', ')}`;
   }
+  if (period !== timePeriod) {
+    return `granularity=${granularity} requires timePeriod=${period}`;
+  }
 
   return null;
 }
@@ -62,3 +122,117 @@ export async function getUserMetrics(userId: string): Promise<unknown | null> {
 
   return metricsForLocations || null;
 }
+
+/**
+ * Breaks Today down into hourly drop / cancelled credits / gross for the
+ * locations a user can access. casinoMetrics only stores period totals, so
+ * the buckets are computed from meters with one `$dateTrunc` group over all
+ * locations (each with its own gaming day range) rather than one query per
+ * hour.
+ *
+ * @param userId - User whose location access scopes the breakdown
+ * @returns Buckets per location and in total, or null when the user does not exist
+ */
+export async function getUserTodayHourlyMetrics(
+  userId: string
+): Promise<TodayHourlyMetrics | null> {
+  const user = await UserModel.findOne(
+    { _id: userId },
+    { _id: 1, roles: 1, assignedLicencees: 1, assignedLocations: 1 }
+  ).lean<UserDocument>();
+  if (!user) return null;
+
+  // Step 1: Locations in scope and their gaming day ranges
+  const accessibleLicencees = await getUserAccessibleLicenceesFromToken({
+    roles: user.roles || [],
+    assignedLicencees: user.assignedLicencees || [],
+  });
+  const allowedLocations = await getUserLocationFilter(
+    accessibleLicencees,
+    undefined,
+    user.assignedLocations || [],
+    user.roles || []
+  );
+  const locations =
+    allowedLocations === 'all'
+      ? await fetchRollupLocations()
+      : await fetchRollupLocations(allowedLocations);
+  if (locations.length === 0) return { locations: [], total: [] };
+
+  const ranges = locations.map(location => ({
+    location: String(location._id),
+    ...getGamingDayRangeForPeriod('Today', location.gameDayOffset ?? 8),
+  }));
+
+  // Step 2: One group per location and hour
+  const rows = await Meters.aggregate<
+    MovementTotals & { _id: { location: unknown; hour: Date } }
+  >([
+    {
+      $match: {
+        $or: ranges.map(range => ({
+          location: anyIdTypeIn([range.location]),
+          readAt: { $gte: range.rangeStart, $lte: range.rangeEnd },
+        })),
+      },
+    },
+    {
+      $group: {
+        _id: {
+          location: '$location',
+          hour: { $dateTrunc: { date: '$readAt', unit: 'hour' } },
+        },
+        ...buildMovementTotalsGroup(),
+      },
+    },
+  ]);
+
+  const byKey = new Map<string, MovementTotals>();
+  rows.forEach(row => {
+    const location = normalizeId(row._id.location) ?? String(row._id.location);
+    const key = `${location}|${new Date(row._id.hour).getTime()}`;
+    byKey.set(key, addMovementTotals(byKey.get(key) || {}, row));
+  });
+  const formulas = await getLocationFinancialFormulas(
+    ranges.map(range => range.location)
+  );
+
+  // Step 3: 24 buckets per location (hours without meters are zero)
+  const totals = new Map<number, HourlyMetricsBucket>();
+  const perLocation = ranges.map(range => {
+    const formula = formulas.get(range.location) || DEFAULT_FINANCIAL_FORMULA;
+    const start = Math.floor(range.rangeStart.getTime() / HOUR_MS) * HOUR_MS;
+    const buckets = Array.from({ length: 24 }, (_unused, index) => {
+      const hour = start + index * HOUR_MS;
+      const metrics = calculateFinancialMetrics(
+        byKey.get(`${range.location}|${hour}`) || {},
+        formula
+      );
+      const bucket = {
+        hour: new Date(hour),
+        drop: metrics.moneyIn,
+        cancelledCredits: metrics.moneyOut,
+        gross: metrics.gross,
+      };
+      const total = totals.get(hour) || {
+        hour: new Date(hour),
+        drop: 0,
+        cancelledCredits: 0,
+        gross: 0,
+      };
+      total.drop += bucket.drop;
+      total.cancelledCredits += bucket.cancelledCredits;
+      total.gross += bucket.gross;
+      totals.set(hour, total);
+      return bucket;
+    });
+    return { location: range.location, buckets };
+  });
+
+  return {
+    locations: perLocation,
+    total: Array.from(totals.keys())
+      .sort((a, b) => a - b)
+      .map(hour => totals.get(hour) as HourlyMetricsBucket),
+  };
+}
@@ -0,0 +1,597 @@
+/**
+ * Pre-aggregated Metrics Freshness Helper
+ *
+ * Monitors the `casinoMetrics` collection written by the pre-aggregation
+ * workers. Reports users whose `lastUpdated` is older than a threshold and
+ * compares a sample of stored "Yesterday" totals against values freshly
+ * computed from meters, so drift in the pre-aggregation is caught early.
+ * `crossCheckUserMetrics()` does the same for chosen users (or a sample)
+ * across the Today / 7d / 30d timeframes, for the `metrics-drift` command.
+ *
+ * @module app/api/lib/helpers/users/metricsFreshness
+ */
+
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getLicenceeFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import {
+  fetchRollupLocations,
+  getMovementTotalsWithRollup,
+} from '@/app/api/lib/helpers/metersDaily';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import mongoose from 'mongoose';
+import UserModel from '@/app/api/lib/models/user';
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '@/app/api/lib/utils/financialFormulas';
+import {
+  getMetricsTimeframeRange,
+  loadMetricsTimeframes,
+} from '@/app/api/lib/utils/metricsTimeframes';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+import type { UserDocument } from '@shared/types';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MetricsFreshnessOptions = {
+  maxAgeMinutes: number;
+  sampleSize: number;
+  maxStalePercent: number;
+  maxDriftPercent: number;
+};
+
+export type StaleUserMetrics = {
+  userId: string;
+  lastUpdated: Date | null;
+  ageMinutes: number | null;
+};
+
+export type MetricsDriftSample = {
+  userId: string;
+  field: 'moneyIn' | 'moneyOut' | 'gross';
+  stored: number;
+  fresh: number;
+  driftPercent: number;
+};
+
+export type MetricsFreshnessReport = {
+  healthy: boolean;
+  checkedAt: Date;
+  totalUsers: number;
+  staleUsers: StaleUserMetrics[];
+  stalePercent: number;
+  sampledUsers: number;
+  drift: MetricsDriftSample[];
+  maxObservedDriftPercent: number;
+  thresholds: MetricsFreshnessOptions;
+};
+
+type StoredTotals = { moneyIn: number; moneyOut: number; gross: number };
+
+type CasinoMetricsRecord = {
+  userId: string;
+  lastUpdated?: Date | string | null;
+  Yesterday?: unknown;
+};
+
+const DRIFT_FIELDS: Array<keyof StoredTotals> = ['moneyIn', 'moneyOut', 'gross'];
+
+/** Timeframe name (see metricsTimeframes), e.g. Today, 7d, MTD */
+export type MetricsTimeframe = string;
+
+export type MetricsCrossCheckOptions = {
+  /** Users to check by id; with `usernames` empty, a random sample is used */
+  userIds: string[];
+  usernames: string[];
+  sampleSize: number;
+  timeframes: MetricsTimeframe[];
+  maxDriftPercent: number;
+};
+
+export type TimeframeDrift = {
+  timeframe: MetricsTimeframe;
+  /** null when the casinoMetrics document has no entry for the timeframe */
+  stored: StoredTotals | null;
+  fresh: StoredTotals;
+  driftPercent: StoredTotals;
+  maxDriftPercent: number;
+};
+
+export type UserMetricsCrossCheck = {
+  userId: string;
+  username: string;
+  /** false when the user has no casinoMetrics document */
+  hasMetrics: boolean;
+  lastUpdated: Date | null;
+  timeframes: TimeframeDrift[];
+};
+
+export type MetricsCrossCheckReport = {
+  healthy: boolean;
+  checkedAt: Date;
+  users: UserMetricsCrossCheck[];
+  /** Worst drift and number of users over the limit, per timeframe */
+  byTimeframe: Array<{
+    timeframe: MetricsTimeframe;
+    users: number;
+    driftedUsers: number;
+    maxDriftPercent: number;
+  }>;
+  maxObservedDriftPercent: number;
+  /** How far the shared location sets cut the meter aggregations */
+  computation: FreshTotalsStats;
+  thresholds: Pick<MetricsCrossCheckOptions, 'maxDriftPercent'>;
+};
+
+export const DEFAULT_CROSS_CHECK_OPTIONS: MetricsCrossCheckOptions = {
+  userIds: [],
+  usernames: [],
+  sampleSize: 10,
+  timeframes: ['Today', '7d', '30d'],
+  maxDriftPercent: 1,
+};
+
+export const DEFAULT_FRESHNESS_OPTIONS: MetricsFreshnessOptions = {
+  maxAgeMinutes: 60,
+  sampleSize: 5,
+  maxStalePercent: 0,
+  maxDriftPercent: 1,
+};
+
+/** Users a freshness check recomputes at most */
+export const MAX_FRESHNESS_SAMPLE_SIZE = 50;
+
+// ============================================================================
+// Stored Value Extraction
+// ============================================================================
+
+/**
+ * Reads moneyIn/moneyOut/gross from a stored timeframe entry. Entries are
+ * either a single totals object or an array of per-location totals.
+ */
+function extractStoredTotals(entry: unknown): StoredTotals | null {
+  if (!entry || typeof entry !== 'object') return null;
+
+  const rows = Array.isArray(entry) ? entry : [entry];
+  return rows.reduce<StoredTotals>(
+    (sum, row) => {
+      const record = (row || {}) as Record<string, unknown>;
+      sum.moneyIn += Number(record.moneyIn) || 0;
+      sum.moneyOut += Number(record.moneyOut) || 0;
+      sum.gross += Number(record.gross) || 0;
+      return sum;
+    },
+    { moneyIn: 0, moneyOut: 0, gross: 0 }
+  );
+}
+
+// ============================================================================
+// Fresh Computation
+// ============================================================================
+
+/**
+ * Per-run cache for fresh totals. Many users share the same location access,
+ * so meters are aggregated once per location and timeframe and each user's
+ * totals are composed from the per-location results; users with identical
+ * (or overlapping) location sets do not repeat an aggregation.
+ */
+type FreshTotalsCache = {
+  /** Location set key per user; null when the user does not exist */
+  userSets: Map<string, string | null>;
+  /** Active locations per location set key ('all' or sorted ids) */
+  locationSets: Map<string, Array<{ _id: string; gameDayOffset?: number }>>;
+  /** Per-location totals, per timeframe */
+  locationTotals: Map<string, Map<string, StoredTotals>>;
+  aggregations: number;
+};
+
+export type FreshTotalsStats = {
+  users: number;
+  uniqueLocationSets: number;
+  /** Meter aggregations run (one per timeframe and batch of new locations) */
+  aggregations: number;
+};
+
+function createFreshTotalsCache(): FreshTotalsCache {
+  return {
+    userSets: new Map(),
+    locationSets: new Map(),
+    locationTotals: new Map(),
+    aggregations: 0,
+  };
+}
+
+function getFreshTotalsStats(cache: FreshTotalsCache): FreshTotalsStats {
+  return {
+    users: cache.userSets.size,
+    uniqueLocationSets: cache.locationSets.size,
+    aggregations: cache.aggregations,
+  };
+}
+
+/**
+ * Resolves the key of the location set a user can access.
+ */
+async function resolveUserLocationSet(
+  userId: string,
+  cache: FreshTotalsCache
+): Promise<string | null> {
+  if (cache.userSets.has(userId)) return cache.userSets.get(userId) ?? null;
+
+  const user = await UserModel.findOne(
+    { _id: userId },
+    { _id: 1, roles: 1, assignedLicencees: 1, assignedLocations: 1 }
+  ).lean<UserDocument>();
+  let setKey: string | null = null;
+  if (user) {
+    const accessibleLicencees = await getUserAccessibleLicenceesFromToken({
+      roles: user.roles || [],
+      assignedLicencees: user.assignedLicencees || [],
+    });
+    const allowedLocations = await getUserLocationFilter(
+      accessibleLicencees,
+      undefined,
+      user.assignedLocations || [],
+      user.roles || []
+    );
+    // fetchRollupLocations reads all active locations for an empty list too
+    setKey =
+      allowedLocations === 'all' || allowedLocations.length === 0
+        ? 'all'
+        : Array.from(new Set(allowedLocations.map(String))).sort().join(',');
+  }
+
+  cache.userSets.set(userId, setKey);
+  if (setKey !== null && !cache.locationSets.has(setKey)) {
+    cache.locationSets.set(
+      setKey,
+      setKey === 'all'
+        ? await fetchRollupLocations()
+        : await fetchRollupLocations(setKey.split(','))
+    );
+  }
+  return setKey;
+}
+
+/**
+ * Aggregates the timeframe's totals for locations not yet in the cache.
+ */
+async function fillLocationTotals(
+  locations: Array<{ _id: string; gameDayOffset?: number }>,
+  timePeriod: string,
+  cache: FreshTotalsCache
+): Promise<Map<string, StoredTotals>> {
+  let cached = cache.locationTotals.get(timePeriod);
+  if (!cached) {
+    cached = new Map();
+    cache.locationTotals.set(timePeriod, cached);
+  }
+  const known = cached;
+  const missing = locations.filter(
+    location => !known.has(String(location._id))
+  );
+  if (missing.length === 0) return known;
+
+  const timeframe = loadMetricsTimeframes()[timePeriod];
+  if (!timeframe) throw new Error(`Unknown timeframe '${timePeriod}'`);
+  const ranges = new Map<string, GamingDayRange>();
+  missing.forEach(location => {
+    ranges.set(
+      String(location._id),
+      getMetricsTimeframeRange(timeframe, location.gameDayOffset ?? 8)
+    );
+  });
+
+  cache.aggregations++;
+  const [totalsByLocation, locationDocs] = await Promise.all([
+    getMovementTotalsWithRollup(ranges, 'location'),
+    GamingLocations.find(
+      { _id: { $in: Array.from(ranges.keys()) } },
+      { _id: 1, 'rel.licencee': 1 }
+    ).lean<Array<{ _id: string; rel?: { licencee?: string } }>>(),
+  ]);
+
+  const licenceeIds = Array.from(
+    new Set(
+      locationDocs
+        .map(location => location.rel?.licencee)
+        .filter((value): value is string => Boolean(value))
+    )
+  );
+  const formulaByLicencee = await getLicenceeFinancialFormulas(licenceeIds);
+
+  // Locations without meters are cached as zero so they are not re-read
+  ranges.forEach((_range, locationId) =>
+    known.set(locationId, { moneyIn: 0, moneyOut: 0, gross: 0 })
+  );
+  locationDocs.forEach(location => {
+    const totals = totalsByLocation.get(String(location._id));
+    if (!totals) return;
+    const formula =
+      formulaByLicencee.get(String(location.rel?.licencee)) ||
+      DEFAULT_FINANCIAL_FORMULA;
+    const metrics = calculateFinancialMetrics(totals, formula);
+    known.set(String(location._id), {
+      moneyIn: metrics.moneyIn,
+      moneyOut: metrics.moneyOut,
+      gross: metrics.gross,
+    });
+  });
+  return known;
+}
+
+/**
+ * Computes money in/out/gross for a timeframe across the locations a user
+ * can access, using the same formula resolution as the live reports.
+ *
+ * @param userId - User whose location access scopes the totals
+ * @param timePeriod - Timeframe name (see metricsTimeframes), e.g. 'Yesterday'
+ * @param cache - Per-run cache shared by the users being checked
+ */
+async function computeFreshTotals(
+  userId: string,
+  timePeriod: string,
+  cache: FreshTotalsCache
+): Promise<StoredTotals | null> {
+  const setKey = await resolveUserLocationSet(userId, cache);
+  if (setKey === null) return null;
+
+  const locations = cache.locationSets.get(setKey) || [];
+  const totalsByLocation = await fillLocationTotals(
+    locations,
+    timePeriod,
+    cache
+  );
+  return locations.reduce<StoredTotals>(
+    (sum, location) => {
+      const totals = totalsByLocation.get(String(location._id));
+      if (!totals) return sum;
+      sum.moneyIn += totals.moneyIn;
+      sum.moneyOut += totals.moneyOut;
+      sum.gross += totals.gross;
+      return sum;
+    },
+    { moneyIn: 0, moneyOut: 0, gross: 0 }
+  );
+}
+
+function driftPercent(stored: number, fresh: number): number {
+  if (stored === fresh) return 0;
+  const base = Math.max(Math.abs(fresh), Math.abs(stored), 1);
+  return (Math.abs(stored - fresh) / base) * 100;
+}
+
+// ============================================================================
+// Status Report
+// ============================================================================
+
+/**
+ * Builds the freshness report for `casinoMetrics`.
+ * `healthy` is false when the stale share or the worst sampled drift exceeds
+ * the configured limits.
+ *
+ * @param options - Staleness/drift thresholds and sample size
+ * @returns Freshness report
+ */
+export async function getMetricsFreshnessReport(
+  options: MetricsFreshnessOptions = DEFAULT_FRESHNESS_OPTIONS
+): Promise<MetricsFreshnessReport> {
+  const db = await connectDB();
+  if (!db) {
+    throw new Error('Database connection failed');
+  }
+
+  const now = new Date();
+  const records = await db
+    .collection<CasinoMetricsRecord>('casinoMetrics')
+    .find({}, { projection: { _id: 0, userId: 1, lastUpdated: 1 } })
+    .toArray();
+
+  // Step 1: Staleness scan
+  const cutoff = now.getTime() - options.maxAgeMinutes * 60000;
+  const staleUsers: StaleUserMetrics[] = [];
+  records.forEach(record => {
+    const lastUpdated = record.lastUpdated
+      ? new Date(record.lastUpdated)
+      : null;
+    const isValid = lastUpdated && !Number.isNaN(lastUpdated.getTime());
+    if (!isValid || lastUpdated.getTime() < cutoff) {
+      staleUsers.push({
+        userId: String(record.userId),
+        lastUpdated: isValid ? lastUpdated : null,
+        ageMinutes: isValid
+          ? Math.round((now.getTime() - lastUpdated.getTime()) / 60000)
+          : null,
+      });
+    }
+  });
+  const stalePercent =
+    records.length > 0 ? (staleUsers.length / records.length) * 100 : 0;
+
+  // Step 2: Drift check on a sample of fresh records
+  const staleIds = new Set(staleUsers.map(stale => stale.userId));
+  const sampleIds = records
+    .map(record => String(record.userId))
+    .filter(userId => !staleIds.has(userId))
+    .sort(() => Math.random() - 0.5)
+    .slice(0, options.sampleSize);
+
+  const drift: MetricsDriftSample[] = [];
+  let maxObservedDriftPercent = 0;
+  const cache = createFreshTotalsCache();
+  for (const userId of sampleIds) {
+    const storedRecord = await db
+      .collection<CasinoMetricsRecord>('casinoMetrics')
+      .findOne({ userId }, { projection: { _id: 0, Yesterday: 1 } });
+    const stored = extractStoredTotals(storedRecord?.Yesterday);
+    if (!stored) continue;
+
+    const fresh = await computeFreshTotals(userId, 'Yesterday', cache);
+    if (!fresh) continue;
+
+    DRIFT_FIELDS.forEach(field => {
+      const percent = driftPercent(stored[field], fresh[field]);
+      maxObservedDriftPercent = Math.max(maxObservedDriftPercent, percent);
+      if (percent > 0) {
+        drift.push({
+          userId,
+          field,
+          stored: stored[field],
+          fresh: fresh[field],
+          driftPercent: Number(percent.toFixed(2)),
+        });
+      }
+    });
+  }
+
+  return {
+    healthy:
+      stalePercent <= options.maxStalePercent &&
+      maxObservedDriftPercent <= options.maxDriftPercent,
+    checkedAt: now,
+    totalUsers: records.length,
+    staleUsers,
+    stalePercent: Number(stalePercent.toFixed(2)),
+    sampledUsers: sampleIds.length,
+    drift,
+    maxObservedDriftPercent: Number(maxObservedDriftPercent.toFixed(2)),
+    thresholds: options,
+  };
+}
+
+// ============================================================================
+// Cross-check
+// ============================================================================
+
+/**
+ * Recomputes the Today/7d/30d totals of chosen users (by id or username, or
+ * a random sample) straight from meters and compares each with the stored
+ * casinoMetrics entry. `healthy` is false when any user/timeframe drifts more
+ * than `maxDriftPercent` or a chosen user has no stored entry.
+ *
+ * Today's stored totals lag by up to the worker interval, so small Today
+ * drift on a busy day is expected; compare against `lastUpdated`.
+ *
+ * @param options - Users, timeframes and drift limit
+ * @returns Drift per user and timeframe, plus per-timeframe summaries
+ */
+export async function crossCheckUserMetrics(
+  options: MetricsCrossCheckOptions = DEFAULT_CROSS_CHECK_OPTIONS
+): Promise<MetricsCrossCheckReport> {
+  const metrics = mongoose.connection.collection<
+    CasinoMetricsRecord & Record<string, unknown>
+  >('casinoMetrics');
+  const configured = loadMetricsTimeframes();
+  const unknown = options.timeframes.find(timeframe => !configured[timeframe]);
+  if (unknown) throw new Error(`Unknown timeframe '${unknown}'`);
+
+  // Step 1: Resolve the users to check
+  let users: Array<{ _id: string; username?: string }>;
+  if (options.userIds.length > 0 || options.usernames.length > 0) {
+    users = await UserModel.find(
+      {
+        $or: [
+          { _id: { $in: options.userIds } },
+          { username: { $in: options.usernames } },
+        ],
+      },
+      { _id: 1, username: 1 }
+    ).lean<Array<{ _id: string; username?: string }>>();
+  } else {
+    const sample = await metrics
+      .aggregate<{ userId: string }>([
+        { $sample: { size: options.sampleSize } },
+        { $project: { _id: 0, userId: 1 } },
+      ])
+      .toArray();
+    users = await UserModel.find(
+      { _id: { $in: sample.map(record => String(record.userId)) } },
+      { _id: 1, username: 1 }
+    ).lean<Array<{ _id: string; username?: string }>>();
+  }
+
+  // Step 2: Compare stored and fresh totals per user and timeframe
+  const results: UserMetricsCrossCheck[] = [];
+  const cache = createFreshTotalsCache();
+  for (const user of users) {
+    const userId = String(user._id);
+    const stored = await metrics.findOne({ userId });
+    const lastUpdated = stored?.lastUpdated
+      ? new Date(stored.lastUpdated)
+      : null;
+
+    const timeframes: TimeframeDrift[] = [];
+    for (const timeframe of options.timeframes) {
+      const fresh = await computeFreshTotals(userId, timeframe, cache);
+      if (!fresh) continue;
+      const storedTotals = extractStoredTotals(
+        stored?.[configured[timeframe].key]
+      );
+      const percents = {} as StoredTotals;
+      DRIFT_FIELDS.forEach(field => {
+        percents[field] = storedTotals
+          ? Number(
+              driftPercent(storedTotals[field], fresh[field]).toFixed(2)
+            )
+          : 100;
+      });
+      timeframes.push({
+        timeframe,
+        stored: storedTotals,
+        fresh,
+        driftPercent: percents,
+        maxDriftPercent: Math.max(
+          ...DRIFT_FIELDS.map(field => percents[field])
+        ),
+      });
+    }
+
+    results.push({
+      userId,
+      username: user.username || userId,
+      hasMetrics: Boolean(stored),
+      lastUpdated,
+      timeframes,
+    });
+  }
+
+  // Step 3: Summarise per timeframe
+  const byTimeframe = options.timeframes.map(timeframe => {
+    const entries = results.flatMap(result =>
+      result.timeframes.filter(entry => entry.timeframe === timeframe)
+    );
+    return {
+      timeframe,
+      users: entries.length,
+      driftedUsers: entries.filter(
+        entry => entry.maxDriftPercent > options.maxDriftPercent
+      ).length,
+      maxDriftPercent: Math.max(
+        0,
+        ...entries.map(entry => entry.maxDriftPercent)
+      ),
+    };
+  });
+  const maxObservedDriftPercent = Math.max(
+    0,
+    ...byTimeframe.map(entry => entry.maxDriftPercent)
+  );
+
+  return {
+    healthy:
+      maxObservedDriftPercent <= options.maxDriftPercent &&
+      results.every(result => result.hasMetrics),
+    checkedAt: new Date(),
+    users: results,
+    byTimeframe,
+    maxObservedDriftPercent,
+    computation: getFreshTotalsStats(cache),
+    thresholds: { maxDriftPercent: options.maxDriftPercent },
+  };
+}
@@ -1,5 +1,9 @@
 import CashierShiftModel from '@/app/api/lib/models/cashierShift';
 import UserModel from '@/app/api/lib/models/user';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import { getCurrentDbConnectionString, getJwtSecret } from '@/lib/utils/auth';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import {
@@ -21,6 +25,7 @@ import { connectDB } from '../../middleware/db';
 import { apiLogger, LogContext } from '../../services/loggerService';
 import { comparePassword, hashPassword } from '../../utils/validation';
 import { logActivity, mapDeletedFieldsToChanges } from '../activityLogger';
+import { getApiKeyUser } from '../apiKeys';
 
 /**
  * Validates database context from JWT token
@@ -71,7 +76,9 @@ function validateDatabaseContext(
 }
 
 /**
- * Server-side function to get user from JWT token in cookies or Authorization header
+ * Server-side function to get user from JWT token in cookies or Authorization header.
+ * Without a token, inside a request authenticated with an API key it returns
+ * the key's user (see apiKeys `runAsApiKey`).
  */
 export async function getUserFromServer(): Promise<JWTPayload | null> {
   // Try to get token from cookies first
@@ -93,7 +100,7 @@ export async function getUserFromServer(): Promise<JWTPayload | null> {
     }
   }
 
-  if (!token) return null;
+  if (!token) return getApiKeyUser();
 
   try {
     const { payload } = await jwtVerify(
@@ -294,27 +301,20 @@ export async function getUserIdFromServer(): Promise<string | null> {
  */
 export async function getAllUsers() {
   return await UserModel.find(
-    {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $exists: false } },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    },
+    { ...NOT_DELETED_FILTER },
     '-password'
   ).lean<LeanUserDocument[]>();
 }
 
 /**
- * Retrieves deleted users from 2025 and later
+ * Retrieves soft-deleted users
  * This is used when filtering for deleted users
  */
 export async function getDeletedUsers() {
   try {
-    const year2025Start = new Date('2025-01-01T00:00:00.000Z');
     return await UserModel.find(
       {
-        deletedAt: { $gte: year2025Start },
+        ...DELETED_FILTER,
       },
       '-password'
     ).lean<LeanUserDocument[]>();
@@ -621,7 +621,7 @@ export async function createUser(
         : null,
       tempPasswordChanged: isCashier && hasTemp ? false : true, // Cashiers must change on first login
       tempPassword: tempPassword || null, // Store plain text temp password
-      deletedAt: new Date(-1), // SMIB boards require all fields to be present
+      deletedAt: null, // SMIB boards require all fields to be present
     });
   } catch (dbError) {
     // Handle MongoDB duplicate key errors (E11000)
@@ -14,6 +14,7 @@ import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { SoftCountModel } from '@/app/api/lib/models/softCount';
 import VaultShiftModel from '@/app/api/lib/models/vaultShift';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getGamingDayRange } from '@/lib/utils/gamingDayRange';
 import type {
   CashierShiftDocument,
@@ -243,7 +244,7 @@ export async function generateEndOfDayReport(
   // ============================================================================
   const machinesMatchQuery = {
     gamingLocation: locationId,
-    deletedAt: { $exists: false },
+    ...NOT_DELETED_FILTER,
   };
   const allMachines = await Machine.find(machinesMatchQuery)
     .select('_id assetNumber custom.name')
@@ -192,21 +192,25 @@ export async function enrichExpenseMachineDetails(
   }
 }
 
+type ExpenseFilters = {
+  locationId?: string;
+  startDate?: string;
+  endDate?: string;
+  category?: string;
+};
+
 /**
- * Build a MongoDB query for expense transactions from search params
+ * Build a MongoDB query for expense transactions from the list filters
  *
- * @param {URLSearchParams} searchParams - Request URL search params
+ * @param {ExpenseFilters} filters - Parsed query of `GET /api/vault/expense`
  * @param {'all' | string[]} allowedLocs - User's accessible locations
  * @returns {{ query: Record<string, unknown>; error?: string }} Query and optional error
  */
 export function buildExpenseQuery(
-  searchParams: URLSearchParams,
+  filters: ExpenseFilters,
   allowedLocs: string[] | 'all'
 ): { query: Record<string, unknown>; error?: string } {
-  const locationId = searchParams.get('locationId');
-  const startDate = searchParams.get('startDate');
-  const endDate = searchParams.get('endDate');
-  const category = searchParams.get('category');
+  const { locationId, startDate, endDate, category } = filters;
 
   const query: Record<string, unknown> = { type: 'expense' };
   let error: string | undefined;
@@ -213,38 +213,6 @@ export async function fetchFloatRequestsWithDetails(
   return { data, total };
 }
 
-// ============================================================================
-// Validation
-// ============================================================================
-
-/**
- * Validate required fields for creating a float request.
- * @param body - Parsed request body
- * @returns Error response object or null if valid
- */
-export type FloatRequestBody = {
-  type: string;
-  amount: number;
-  denominations?: unknown[];
-  reason?: string;
-  locationId: string;
-  cashierShiftId: string;
-};
-
-export function validateFloatRequestBody(body: FloatRequestBody): {
-  valid: boolean;
-  error?: string;
-  status?: number;
-} {
-  const { type, amount, locationId, cashierShiftId } = body;
-
-  if (!type || !amount || !locationId || !cashierShiftId) {
-    return { valid: false, error: 'Missing required fields', status: 400 };
-  }
-
-  return { valid: true };
-}
-
 // ============================================================================
 // Vault Shift
 // ============================================================================
@@ -13,6 +13,7 @@
  */
 
 import { FloatRequest } from '@/app/api/lib/models/floatRequests';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import {
   type CreateFloatRequestRequest,
   type Denomination,
@@ -64,9 +65,7 @@ export async function fetchFloatRequests(
   } = params;
 
   // Build match stage
-  const matchStage: Record<string, unknown> = {
-    deletedAt: new Date('1969-12-31T23:59:59.999+0000'),
-  };
+  const matchStage: Record<string, unknown> = { ...NOT_DELETED_FILTER };
 
   // Apply location filter
   if (allowedLocationIds !== 'all') {
@@ -304,7 +303,7 @@ export async function createFloatRequest(
     approvedTotalAmount: 0,
     acknowledgedByCashier: false,
     acknowledgedByManager: false,
-    deletedAt: new Date('1969-12-31T23:59:59.999+0000'),
+    deletedAt: null,
   });
 
   return floatRequest.toObject() as FloatRequestDocument;
@@ -0,0 +1,313 @@
+/**
+ * Write Backups Helper
+ *
+ * Shared backup logic for every path that changes data. Backups are copied
+ * server side (`$merge`) into a backup collection, grouped by run id, and
+ * listed, pruned and restored through `bun run backups` (see backupRetention).
+ *
+ * - `copyToBackupStore()` — the copy used by the bulk rewrites
+ *   (normalize-deleted-at, coerce-dates), which keep only the fields they
+ *   change in their own stores
+ * - `backupBeforeWrite()` — targeted backup of the documents a write is about
+ *   to modify, whole, into `documentBackups`. Each backup document records
+ *   the operation, the acting user and when it was taken; report fixes and
+ *   regenerations, SAS time fixes, migration re-syncs, conflict resolution,
+ *   status changes, machine moves, updates and reconfigurations, soft
+ *   deletes and member merges call it before writing
+ *
+ * `WRITE_BACKUP_MODE` controls the targeted backups: `required` (default)
+ * refuses the write when the backup cannot be taken, `best-effort` logs and
+ * carries on, `off` skips them. Writes touching more than
+ * `WRITE_BACKUP_MAX_DOCUMENTS` documents (default 10000) are refused in
+ * `required` mode; back those up with mongodump instead.
+ *
+ * @module app/api/lib/helpers/writeBackups
+ */
+
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+import mongoose from 'mongoose';
+import type { Connection } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DOCUMENT_BACKUP_COLLECTION = 'documentBackups';
+
+export type WriteBackupMode = 'required' | 'best-effort' | 'off';
+
+export type WriteBackupPolicy = {
+  mode: WriteBackupMode;
+  /** Most documents one backup may copy */
+  maxDocuments: number;
+};
+
+const DEFAULT_MAX_DOCUMENTS = 10000;
+
+export type WriteBackupTarget = {
+  /** What the write does, e.g. 'fix-report' or 'machine-status' */
+  operation: string;
+  collection: string;
+  /** Selects the documents about to be modified */
+  filter: Record<string, unknown>;
+  /** Groups the backups of one operation (default: a new run id) */
+  runId?: string;
+  /** Acting user (default: unknown) */
+  by?: string;
+};
+
+export type WriteBackupRecord = {
+  runId: string;
+  operation: string;
+  collection: string;
+  /** Documents backed up */
+  documents: number;
+  /** Why nothing was backed up, when the policy allowed skipping it */
+  skipped: string | null;
+};
+
+type DocumentBackup = {
+  runId: string;
+  collection: string;
+  documentId: unknown;
+  operation: string;
+  by: string | null;
+  document: Record<string, unknown>;
+  backedUpAt: Date;
+};
+
+// ============================================================================
+// Policy
+// ============================================================================
+
+/**
+ * Resolves the targeted backup policy from `WRITE_BACKUP_MODE` and
+ * `WRITE_BACKUP_MAX_DOCUMENTS`.
+ *
+ * @throws Error for an unknown mode or an invalid limit
+ */
+export function getWriteBackupPolicy(): WriteBackupPolicy {
+  const mode = (process.env.WRITE_BACKUP_MODE || 'required').trim();
+  if (mode !== 'required' && mode !== 'best-effort' && mode !== 'off') {
+    throw new Error(
+      `WRITE_BACKUP_MODE must be required, best-effort or off, got '${mode}'`
+    );
+  }
+  const limit = process.env.WRITE_BACKUP_MAX_DOCUMENTS;
+  const maxDocuments = limit ? Number(limit) : DEFAULT_MAX_DOCUMENTS;
+  if (!Number.isInteger(maxDocuments) || maxDocuments < 1) {
+    throw new Error('WRITE_BACKUP_MAX_DOCUMENTS must be a positive integer');
+  }
+  return { mode, maxDocuments };
+}
+
+/**
+ * Run id for a new backup: the operation and the current time.
+ */
+export function createBackupRunId(operation: string): string {
+  return `${operation}-${Date.now()}`;
+}
+
+// ============================================================================
+// Copying
+// ============================================================================
+
+/**
+ * Copies the documents matching `filter` into a backup collection, server
+ * side. Each backup is keyed by run, collection, `key` and the document id,
+ * so copying the same document twice in a run keeps the first copy.
+ *
+ * @param connection - Database connection
+ * @param source - Collection, filter and run to copy under
+ * @param fields - Fields of the backup document (aggregation expressions)
+ * @returns Documents of the collection held under the run and key
+ */
+export async function copyToBackupStore(
+  connection: Connection,
+  source: {
+    collection: string;
+    filter: Record<string, unknown>;
+    into: string;
+    runId: string;
+    /** Extra constant parts of the backup key, e.g. `{ field }` */
+    key?: Record<string, string>;
+  },
+  fields: Record<string, unknown>
+): Promise<number> {
+  const { collection, filter, into, runId, key = {} } = source;
+  const literalKey = Object.fromEntries(
+    Object.entries(key).map(([name, value]) => [name, { $literal: value }])
+  );
+  await connection
+    .collection(collection)
+    .aggregate(
+      [
+        { $match: filter },
+        {
+          $project: {
+            _id: {
+              runId: { $literal: runId },
+              collection: { $literal: collection },
+              ...literalKey,
+              documentId: '$_id',
+            },
+            runId: { $literal: runId },
+            collection: { $literal: collection },
+            ...literalKey,
+            documentId: '$_id',
+            ...fields,
+            backedUpAt: '$$NOW',
+          },
+        },
+        { $merge: { into, whenMatched: 'keepExisting' } },
+      ],
+      { allowDiskUse: true }
+    )
+    .toArray();
+  return connection
+    .collection(into)
+    .countDocuments({ runId, collection, ...key });
+}
+
+/**
+ * Groups a run's backup documents by the collection they were taken from.
+ */
+export function groupBackupsByCollection<T extends { collection: string }>(
+  backups: T[]
+): Map<string, T[]> {
+  const byCollection = new Map<string, T[]>();
+  backups.forEach(backup => {
+    const list = byCollection.get(backup.collection) || [];
+    list.push(backup);
+    byCollection.set(backup.collection, list);
+  });
+  return byCollection;
+}
+
+// ============================================================================
+// Targeted backups
+// ============================================================================
+
+/**
+ * Backs up the documents a write is about to modify into `documentBackups`,
+ * following the policy (see getWriteBackupPolicy). Call it right before the
+ * write, after validation.
+ *
+ * @param target - Operation, collection and filter of the documents
+ * @param connection - Database connection (default: the mongoose connection)
+ * @returns What was backed up
+ * @throws Error in `required` mode when the backup fails or is too large
+ */
+export async function backupBeforeWrite(
+  target: WriteBackupTarget,
+  connection: Connection = mongoose.connection
+): Promise<WriteBackupRecord> {
+  const policy = getWriteBackupPolicy();
+  const runId = target.runId ?? createBackupRunId(target.operation);
+  const record: WriteBackupRecord = {
+    runId,
+    operation: target.operation,
+    collection: target.collection,
+    documents: 0,
+    skipped: null,
+  };
+  if (policy.mode === 'off') return { ...record, skipped: 'backups off' };
+
+  const skipOrThrow = (reason: string): WriteBackupRecord => {
+    const message = `Backup before ${target.operation} on ${target.collection}: ${reason}`;
+    if (policy.mode === 'required') {
+      throw new Error(
+        `${message}. Set WRITE_BACKUP_MODE=best-effort to write without it`
+      );
+    }
+    console.warn(`[writeBackups] ${message}; writing without a backup`);
+    return { ...record, skipped: reason };
+  };
+
+  assertWritable(`backing up ${target.collection} before ${target.operation}`);
+  const matching = await connection
+    .collection(target.collection)
+    .countDocuments(target.filter);
+  if (matching === 0) return record;
+  if (matching > policy.maxDocuments) {
+    return skipOrThrow(
+      `${matching} documents exceed WRITE_BACKUP_MAX_DOCUMENTS (${policy.maxDocuments})`
+    );
+  }
+
+  try {
+    const documents = await copyToBackupStore(
+      connection,
+      {
+        collection: target.collection,
+        filter: target.filter,
+        into: DOCUMENT_BACKUP_COLLECTION,
+        runId,
+      },
+      {
+        operation: { $literal: target.operation },
+        by: { $literal: target.by ?? null },
+        document: '$$ROOT',
+      }
+    );
+    return { ...record, documents };
+  } catch (error) {
+    return skipOrThrow(error instanceof Error ? error.message : String(error));
+  }
+}
+
+/**
+ * Puts back the documents of a `documentBackups` run as they were before the
+ * write, recreating any that were deleted since.
+ *
+ * @param connection - Database connection
+ * @param runId - Run to restore
+ * @returns Documents restored
+ */
+export async function restoreDocumentBackup(
+  connection: Connection,
+  runId: string
+): Promise<number> {
+  assertWritable('restoring a document backup');
+
+  const backups = await connection
+    .collection<DocumentBackup>(DOCUMENT_BACKUP_COLLECTION)
+    .find({ runId })
+    .toArray();
+
+  let restored = 0;
+  for (const [collection, entries] of groupBackupsByCollection(backups)) {
+    const result = await connection.collection(collection).bulkWrite(
+      entries.map(entry => ({
+        replaceOne: {
+          filter: { _id: entry.documentId as never },
+          replacement: entry.document,
+          upsert: true,
+        },
+      })),
+      { ordered: false }
+    );
+    restored += result.modifiedCount + result.upsertedCount;
+  }
+  return restored;
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+/**
+ * One line per backup, e.g. "collections: 12 backed up (run fix-report-…)".
+ */
+export function formatWriteBackups(records: WriteBackupRecord[]): string {
+  return records
+    .map(
+      record =>
+        `${record.collection}: ${
+          record.skipped
+            ? `not backed up (${record.skipped})`
+            : `${record.documents} backed up`
+        } (run ${record.runId})`
+    )
+    .join('\n');
+}
@@ -11,11 +11,28 @@
  * - Server-side only execution
  * - Connection state management
  * - Error handling and cleanup
+ * - `maxTimeMS` on every aggregation (see queryTimeout)
+ * - Per-client concurrent aggregation limit (see apiQuotas)
+ * - In-flight aggregations tracked for graceful shutdown (see gracefulShutdown)
+ * - A trace span per aggregation when tracing is on (see tracing)
  *
  * @module app/api/lib/middleware/db
  */
 
 import mongoose from 'mongoose';
+import type { ConnectOptions } from 'mongoose';
+import { installAggregationQuotas } from '@/app/api/lib/helpers/apiQuotas';
+import {
+  DEFAULT_CONNECT_OPTIONS,
+  resolveDbProfile,
+} from '@/app/api/lib/utils/dbProfiles';
+import { installInFlightTracking } from '@/app/api/lib/utils/gracefulShutdown';
+import {
+  getApiMaxTimeMS,
+  getApiQueryTag,
+  installQueryTimeouts,
+} from '@/app/api/lib/utils/queryTimeout';
+import { installAggregationTracing } from '@/app/api/lib/utils/tracing';
 
 const mongooseCache: {
   conn: mongoose.Connection | null;
@@ -39,6 +56,29 @@ function getMongodbUri(): string {
   return uri || '';
 }
 
+/**
+ * Resolve the connection target. When `DB_PROFILE` is set the named profile
+ * (see app/api/lib/utils/dbProfiles.ts) supplies the URI and options;
+ * otherwise MONGODB_URI is used with the default options.
+ */
+async function getConnectionTarget(): Promise<{
+  uri: string;
+  options: ConnectOptions;
+  cacheKey: string;
+}> {
+  const profileName = process.env.DB_PROFILE;
+  if (profileName) {
+    const profile = await resolveDbProfile(profileName);
+    return {
+      uri: profile.uri,
+      options: profile.options,
+      cacheKey: `${profileName}|${profile.uri}|${profile.options.dbName || ''}`,
+    };
+  }
+  const uri = getMongodbUri();
+  return { uri, options: DEFAULT_CONNECT_OPTIONS, cacheKey: uri };
+}
+
 /**
  * Close existing MongoDB connection
  */
@@ -72,7 +112,11 @@ export async function connectDB(): Promise<mongo.Db> {
     throw new Error('connectDB can only be called on the server-side');
   }
 
-  const MONGODB_URI = getMongodbUri();
+  const {
+    uri: MONGODB_URI,
+    options: connectOptions,
+    cacheKey,
+  } = await getConnectionTarget();
 
   if (!MONGODB_URI) {
     throw new Error('MONGODB_URI not set in environment variables');
@@ -82,7 +126,7 @@ export async function connectDB(): Promise<mongo.Db> {
   if (
     mongooseCache.conn &&
     mongooseCache.connectionString &&
-    mongooseCache.connectionString !== MONGODB_URI
+    mongooseCache.connectionString !== cacheKey
   ) {
     await closeConnection();
   }
@@ -92,17 +136,21 @@ export async function connectDB(): Promise<mongo.Db> {
   }
 
   if (!mongooseCache.promise) {
-    mongooseCache.connectionString = MONGODB_URI;
+    mongooseCache.connectionString = cacheKey;
+    // Server-side time limit on every aggregation (QUERY_MAX_TIME_MS)
+    installQueryTimeouts({
+      maxTimeMS: getApiMaxTimeMS(),
+      tag: getApiQueryTag(),
+    });
+    // A span per aggregation (OTEL_EXPORTER_OTLP_ENDPOINT)
+    installAggregationTracing();
+    // Concurrent aggregations per API client (see apiQuotas)
+    installAggregationQuotas();
+    // Aggregations a shutdown waits for
+    installInFlightTracking();
 
     mongooseCache.promise = mongoose
-      .connect(MONGODB_URI, {
-        bufferCommands: false,
-        connectTimeoutMS: 30000,
-        serverSelectionTimeoutMS: 30000,
-        socketTimeoutMS: 120000, // 2 minute socket timeout to prevent hanging
-        maxPoolSize: 10, // Limit connection pool size
-        minPoolSize: 2, // Maintain minimum connections
-      })
+      .connect(MONGODB_URI, connectOptions)
       .then(mongooseInstance => {
         return mongooseInstance.connection;
       })
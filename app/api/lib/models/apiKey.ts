@@ -0,0 +1,32 @@
+import { Schema, model, models } from 'mongoose';
+
+const ApiKeySchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    name: { type: String, required: true },
+    prefix: { type: String, required: true },
+    keyHash: { type: String, required: true },
+    licencees: { type: [String], default: [] },
+    scopes: { type: [String], default: [] },
+    quota: {
+      requestsPerMinute: { type: Number },
+      burst: { type: Number },
+      concurrentAggregations: { type: Number },
+    },
+    description: { type: String, default: '' },
+    createdBy: { type: String, default: null },
+    expiresAt: { type: Date, default: null },
+    lastUsedAt: { type: Date, default: null },
+    revokedAt: { type: Date, default: null },
+    revokedBy: { type: String, default: null },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+ApiKeySchema.index({ keyHash: 1 }, { unique: true });
+ApiKeySchema.index({ name: 1 }, { unique: true });
+
+export const ApiKey = models.ApiKey || model('ApiKey', ApiKeySchema, 'apikeys');
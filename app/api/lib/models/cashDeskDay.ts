@@ -0,0 +1,47 @@
+import { Schema, model, models } from 'mongoose';
+
+const CashDeskDayPayoutSchema = new Schema(
+  {
+    _id: { type: String, required: true },
+    amount: {
+      type: Number,
+      required: true,
+      min: [0, 'Payout amount must be positive'],
+    },
+    type: {
+      type: String,
+      enum: ['cancelled-credit', 'jackpot'],
+      default: 'cancelled-credit',
+    },
+    machineId: { type: String, default: null },
+    machineSerialNumber: { type: String, default: null },
+    note: { type: String },
+    recordedBy: { type: String, default: null },
+    recordedAt: { type: Date, default: Date.now },
+  }
+);
+
+const CashDeskDaySchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    location: { type: String, required: true },
+    /** Gaming day, YYYY-MM-DD */
+    gamingDay: { type: String, required: true },
+    openingFloat: { type: Number, default: null },
+    floatAdded: { type: Number, default: 0 },
+    closingFloat: { type: Number, default: null },
+    payouts: { type: [CashDeskDayPayoutSchema], default: [] },
+    notes: { type: String },
+    recordedBy: { type: String, default: null },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+CashDeskDaySchema.index({ location: 1, gamingDay: 1 }, { unique: true });
+CashDeskDaySchema.index({ gamingDay: 1 });
+
+export const CashDeskDay =
+  models.CashDeskDay || model('CashDeskDay', CashDeskDaySchema, 'cashdeskdays');
@@ -29,7 +29,7 @@ const payoutSchema = new Schema(
     verifiedAt: { type: Date },
     deletedAt: {
       type: Date,
-      default: new Date('1969-12-31T23:59:59.999+0000'),
+      default: null,
     },
     createdAt: { type: Date },
     updatedAt: { type: Date },
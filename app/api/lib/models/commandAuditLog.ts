@@ -0,0 +1,31 @@
+import { Schema, model, models } from 'mongoose';
+
+const CommandAuditLogSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    timestamp: { type: Date, default: Date.now, required: true },
+    username: { type: String, required: true },
+    host: { type: String, required: true },
+    pid: { type: Number },
+    command: { type: String, required: true },
+    parameters: { type: [String], default: [] },
+    target: { type: String, default: null },
+    durationMs: { type: Number, required: true },
+    rowCount: { type: Number, default: 0 },
+    success: { type: Boolean, required: true },
+    exitCode: { type: Number, default: null },
+    error: { type: String, default: null },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+CommandAuditLogSchema.index({ timestamp: -1 });
+CommandAuditLogSchema.index({ command: 1, timestamp: -1 });
+CommandAuditLogSchema.index({ username: 1, timestamp: -1 });
+
+export const CommandAuditLog =
+  models.CommandAuditLog ||
+  model('CommandAuditLog', CommandAuditLogSchema, 'commandAuditLogs');
@@ -0,0 +1,30 @@
+import { Schema, model, models } from 'mongoose';
+
+const DashboardSnapshotSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    licencee: { type: String, required: true },
+    granularity: { type: String, enum: ['hour', 'day'], required: true },
+    bucket: { type: Date, required: true },
+    takenAt: { type: Date, required: true },
+    totalDrop: { type: Number, default: 0 },
+    totalCancelledCredits: { type: Number, default: 0 },
+    totalGross: { type: Number, default: 0 },
+    totalMachines: { type: Number, default: 0 },
+    onlineMachines: { type: Number, default: 0 },
+    sasMachines: { type: Number, default: 0 },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+DashboardSnapshotSchema.index(
+  { licencee: 1, granularity: 1, bucket: 1 },
+  { unique: true }
+);
+
+export const DashboardSnapshot =
+  models.DashboardSnapshot ||
+  model('DashboardSnapshot', DashboardSnapshotSchema, 'dashboardSnapshots');
@@ -63,7 +63,7 @@ const floatRequestSchema = new Schema(
     acknowledgedAt: { type: Date },
     deletedAt: {
       type: Date,
-      default: new Date('1969-12-31T23:59:59.999+0000'),
+      default: null,
     },
     createdAt: { type: Date },
     updatedAt: { type: Date },
@@ -69,7 +69,7 @@ const GamingLocationsSchema = new Schema(
     updatedAt: Date,
     deletedAt: {
       type: Date,
-      default: () => new Date(-1),
+      default: null,
     },
     status: String,
     statusHistory: [Schema.Types.Mixed],
@@ -84,6 +84,31 @@ const GamingLocationsSchema = new Schema(
       type: String,
       default: '',
     },
+    shifts: {
+      type: [
+        {
+          _id: false,
+          name: String,
+          startHour: Number,
+          endHour: Number,
+        },
+      ],
+      default: undefined,
+    },
+    profitSplits: {
+      type: [
+        {
+          _id: false,
+          effectiveFrom: String,
+          venueShare: Number,
+          venueOwner: String,
+          payeeReference: String,
+          updatedBy: String,
+          updatedAt: Date,
+        },
+      ],
+      default: undefined,
+    },
   },
   {
     timestamps: true,
@@ -96,7 +121,7 @@ GamingLocationsSchema.index(
   {
     name: 'unique_active_location_name',
     unique: true,
-    partialFilterExpression: { deletedAt: { $lt: new Date('2025-01-01') } },
+    partialFilterExpression: { deletedAt: { $type: 'null' } },
   }
 );
 
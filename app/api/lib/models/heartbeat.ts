@@ -0,0 +1,32 @@
+import { Schema, model, models } from 'mongoose';
+
+/** Days heartbeats are kept before MongoDB expires them */
+const HEARTBEAT_RETENTION_DAYS = 30;
+
+const HeartbeatSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    machine: { type: String, default: null },
+    serialNumber: { type: String, required: true },
+    relayId: { type: String, default: null },
+    firmware: { type: String, default: null },
+    reportedAt: { type: Date, required: true },
+    receivedAt: { type: Date, required: true },
+    source: { type: String, enum: ['http', 'udp'], required: true },
+    ip: { type: String, default: null },
+  },
+  { versionKey: false }
+);
+
+HeartbeatSchema.index({ machine: 1, receivedAt: -1 });
+HeartbeatSchema.index({ serialNumber: 1, receivedAt: -1 });
+HeartbeatSchema.index(
+  { receivedAt: 1 },
+  { expireAfterSeconds: HEARTBEAT_RETENTION_DAYS * 86400 }
+);
+
+export const Heartbeat =
+  models.Heartbeat || model('Heartbeat', HeartbeatSchema, 'heartbeats');
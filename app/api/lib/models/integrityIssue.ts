@@ -0,0 +1,77 @@
+import { Schema, model, models } from 'mongoose';
+
+const INTEGRITY_ISSUE_STATUSES = [
+  'open',
+  'investigating',
+  'fixed',
+  'verified',
+  'confirmed',
+  'dismissed',
+];
+
+const StatusChangeSchema = new Schema(
+  {
+    from: { type: String, enum: INTEGRITY_ISSUE_STATUSES, required: true },
+    to: { type: String, enum: INTEGRITY_ISSUE_STATUSES, required: true },
+    by: { type: String, required: true },
+    at: { type: Date, required: true },
+    note: { type: String, default: null },
+  },
+  { _id: false }
+);
+
+const CommentSchema = new Schema({
+  _id: { type: String, required: true },
+  author: { type: String, required: true },
+  body: { type: String, required: true },
+  createdAt: { type: Date, required: true },
+});
+
+const IntegrityIssueSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    check: { type: String, required: true },
+    resourceType: { type: String, required: true },
+    resourceId: { type: String, required: true },
+    machine: { type: String },
+    location: { type: String },
+    field: { type: String, default: null },
+    value: { type: Number, default: null },
+    mean: { type: Number, default: null },
+    stdDev: { type: Number, default: null },
+    zScore: { type: Number, default: null },
+    sampleSize: { type: Number, default: null },
+    readAt: { type: Date },
+    details: { type: String },
+    status: {
+      type: String,
+      enum: INTEGRITY_ISSUE_STATUSES,
+      default: 'open',
+    },
+    detectedAt: { type: Date, default: Date.now },
+    reviewedBy: { type: String, default: null },
+    reviewedAt: { type: Date, default: null },
+    assignedTo: { type: String, default: null },
+    assignedAt: { type: Date, default: null },
+    statusHistory: { type: [StatusChangeSchema], default: [] },
+    comments: { type: [CommentSchema], default: [] },
+    digestedAt: { type: Date, default: null },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+IntegrityIssueSchema.index(
+  { check: 1, resourceId: 1, field: 1 },
+  { unique: true }
+);
+IntegrityIssueSchema.index({ status: 1, detectedAt: -1 });
+IntegrityIssueSchema.index({ assignedTo: 1, status: 1 });
+IntegrityIssueSchema.index({ machine: 1, readAt: -1 });
+IntegrityIssueSchema.index({ digestedAt: 1, detectedAt: -1 });
+
+export const IntegrityIssue =
+  models.IntegrityIssue ||
+  model('IntegrityIssue', IntegrityIssueSchema, 'integrityIssues');
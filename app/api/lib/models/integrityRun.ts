@@ -0,0 +1,39 @@
+import { Schema, model, models } from 'mongoose';
+
+/** Days integrity runs are kept before MongoDB expires them */
+const INTEGRITY_RUN_RETENTION_DAYS = 180;
+
+const IntegrityRunCheckSchema = new Schema(
+  {
+    name: { type: String, required: true },
+    count: { type: Number, required: true },
+    threshold: { type: Number, default: 0 },
+    passed: { type: Boolean, required: true },
+    keys: { type: [String], default: [] },
+    truncated: { type: Boolean, default: false },
+  },
+  { _id: false }
+);
+
+const IntegrityRunSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    checkedAt: { type: Date, required: true },
+    passed: { type: Boolean, required: true },
+    checks: { type: [IntegrityRunCheckSchema], default: [] },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+IntegrityRunSchema.index({ 'checks.name': 1, checkedAt: -1 });
+IntegrityRunSchema.index(
+  { checkedAt: 1 },
+  { expireAfterSeconds: INTEGRITY_RUN_RETENTION_DAYS * 86400 }
+);
+
+export const IntegrityRun =
+  models.IntegrityRun ||
+  model('IntegrityRun', IntegrityRunSchema, 'integrityRuns');
@@ -10,7 +10,13 @@ const LicenceeSchema = new Schema(
     prevStartDate: { type: Date },
     prevExpiryDate: { type: Date },
     isPaid: { type: Boolean },
-    licenceKey: { type: String, unique: true, required: true },
+    licenceKey: { type: String, unique: true, required: true, trim: true },
+    jurisdiction: { type: String },
+    contact: {
+      name: { type: String },
+      email: { type: String },
+      phone: { type: String },
+    },
     status: { type: String, default: 'active' },
     deletedAt: { type: Date, default: null },
     createdAt: { type: Date },
@@ -28,6 +34,13 @@ const LicenceeSchema = new Schema(
       type: Number,
       default: 8,
     },
+    financialFormula: {
+      moneyInFields: { type: [String], default: undefined },
+      moneyOutFields: { type: [String], default: undefined },
+    },
+    machineStatus: {
+      onlineThresholdMinutes: { type: Number, default: undefined },
+    },
   },
   { timestamps: true, versionKey: false }
 );
@@ -0,0 +1,26 @@
+import { Schema, model, models } from 'mongoose';
+
+const MachineViewSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    userId: { type: String, required: true },
+    name: { type: String, required: true },
+    description: { type: String, default: '' },
+    filters: {
+      licencee: { type: String, default: null },
+      locations: { type: [String], default: [] },
+      statuses: { type: [String], default: [] },
+    },
+    columns: { type: [String], default: [] },
+    sort: { type: String, required: true },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+MachineViewSchema.index({ userId: 1, name: 1 }, { unique: true });
+
+export const MachineView =
+  models.MachineView || model('MachineView', MachineViewSchema, 'machineviews');
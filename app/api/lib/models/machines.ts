@@ -104,6 +104,63 @@ export const machineSchema = new Schema(
       },
     ],
     assetStatus: String,
+    statusHistory: [
+      {
+        _id: false,
+        from: String,
+        to: String,
+        reason: String,
+        userId: String,
+        username: String,
+        changedAt: Date,
+      },
+    ],
+    // Final meters and collection captured at retirement (see machineDecommission)
+    decommission: {
+      certificateNumber: String,
+      retiredAt: Date,
+      reason: String,
+      userId: String,
+      username: String,
+      finalMeters: {
+        coinIn: Number,
+        coinOut: Number,
+        drop: Number,
+        cancelledCredits: Number,
+        handPaid: Number,
+        jackpot: Number,
+        gamesPlayed: Number,
+      },
+      finalMetersAt: Date,
+      finalCollection: {
+        collectionId: String,
+        locationReportId: String,
+        collectedAt: Date,
+        metersIn: Number,
+        metersOut: Number,
+      },
+      locationId: String,
+      licenceeId: String,
+    },
+    // Game / denomination changes, oldest first (see machineReconfiguration)
+    configurationHistory: [
+      {
+        _id: String,
+        changedAt: Date,
+        changes: [
+          {
+            _id: false,
+            field: String,
+            oldValue: Schema.Types.Mixed,
+            newValue: Schema.Types.Mixed,
+          },
+        ],
+        reason: String,
+        userId: String,
+        username: String,
+        recordedAt: Date,
+      },
+    ],
     cabinetType: String,
     gamingBoard: String,
     manuf: String,
@@ -218,6 +275,8 @@ export const machineSchema = new Schema(
     ],
 
     collectorDenomination: Number,
+    // Unit the machine reports meters in: 'dollars' (default), 'cents' or 'credits'
+    meterUnit: { type: String, enum: ['dollars', 'cents', 'credits'] },
   },
   { timestamps: true }
 );
@@ -231,5 +290,6 @@ machineSchema.index({ machineId: 1 });
 machineSchema.index({ relayId: 1 });
 machineSchema.index({ 'custom.name': 1 });
 machineSchema.index({ lastSasMeterAt: -1 });
+machineSchema.index({ meterUnit: 1 }, { sparse: true });
 
 export const Machine = models['machines'] ?? model('machines', machineSchema);
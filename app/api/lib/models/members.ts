@@ -109,7 +109,7 @@ const memberSchema = new Schema(
     billsIn: { type: Number, default: 0 },
     createdAt: { type: Date, default: Date.now },
     currentSession: { type: String, default: '' },
-    deletedAt: { type: Date, default: () => new Date(-1) },
+    deletedAt: { type: Date, default: null },
     endBillMeters: { type: billMetersSchema, default: null },
     endMeters: { type: metersSchema, default: null },
     endTime: { type: Date, default: null },
@@ -130,6 +130,7 @@ const memberSchema = new Schema(
     machineId: { type: String, default: '' },
     machineSerialNumber: { type: String, default: '' },
     memberId: { type: String, default: '' },
+    mergedInto: { type: String, default: null },
     nonRestricted: { type: Number, default: 0 },
     numFailedLoginAttempts: { type: Number, default: 0 },
     phoneNumber: { type: String, default: '' },
@@ -1,4 +1,6 @@
 import { Schema, model, models, Query, Aggregate } from 'mongoose';
+import { applyMeterUnitStages } from '@/app/api/lib/utils/meterUnits';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 const MetersSchema = new Schema(
   {
@@ -20,6 +22,9 @@ const MetersSchema = new Schema(
       currentCredits: { type: Number, default: 0 },
       gamesPlayed: { type: Number, default: 0 },
       gamesWon: { type: Number, default: 0 },
+      // TITO meters; only recorded for ticket-enabled machines
+      ticketIn: { type: Number },
+      ticketOut: { type: Number },
     },
     coinIn: { type: Number, default: 0 },
     coinOut: { type: Number, default: 0 },
@@ -31,6 +36,8 @@ const MetersSchema = new Schema(
     currentCredits: { type: Number, default: 0 },
     gamesPlayed: { type: Number, default: 0 },
     gamesWon: { type: Number, default: 0 },
+    ticketIn: { type: Number },
+    ticketOut: { type: Number },
     meterSource: {
       type: String,
       enum: ['COLLECTION_REPORT', 'SAS_READ', 'WOW_SYNC', 'OTHER'],
@@ -54,13 +61,7 @@ MetersSchema.index({ locationSession: 1, readAt: 1 });
 MetersSchema.pre(
   'find',
   function (this: Query<unknown, unknown>, next: () => void) {
-    this.where({
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $exists: false } },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    });
+    this.where({ ...NOT_DELETED_FILTER });
     next();
   }
 );
@@ -68,13 +69,7 @@ MetersSchema.pre(
 MetersSchema.pre(
   'findOne',
   function (this: Query<unknown, unknown>, next: () => void) {
-    this.where({
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $exists: false } },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    });
+    this.where({ ...NOT_DELETED_FILTER });
     next();
   }
 );
@@ -82,13 +77,7 @@ MetersSchema.pre(
 MetersSchema.pre(
   'countDocuments',
   function (this: Query<unknown, unknown>, next: () => void) {
-    this.where({
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $exists: false } },
-        { deletedAt: { $lt: new Date('2025-01-01') } },
-      ],
-    });
+    this.where({ ...NOT_DELETED_FILTER });
     next();
   }
 );
@@ -97,16 +86,15 @@ MetersSchema.pre(
   'aggregate',
   function (this: Aggregate<unknown>, next: () => void) {
     this.pipeline().unshift({
-      $match: {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $exists: false } },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      },
+      $match: { ...NOT_DELETED_FILTER },
     });
     next();
   }
 );
 
+// Converts readings of machines that report in cents or credits to dollars
+MetersSchema.pre('aggregate', async function (this: Aggregate<unknown>) {
+  await applyMeterUnitStages(this.pipeline());
+});
+
 export const Meters = models['meters'] || model('meters', MetersSchema);
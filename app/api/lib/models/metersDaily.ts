@@ -0,0 +1,36 @@
+import { Schema, model, models } from 'mongoose';
+
+const MetersDailySchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    machine: { type: String, required: true },
+    location: { type: String, required: true },
+    gamingDay: { type: String, required: true },
+    rangeStart: { type: Date, required: true },
+    rangeEnd: { type: Date, required: true },
+    movement: {
+      drop: { type: Number, default: 0 },
+      totalCancelledCredits: { type: Number, default: 0 },
+      totalHandPaidCancelledCredits: { type: Number, default: 0 },
+      jackpot: { type: Number, default: 0 },
+      coinIn: { type: Number, default: 0 },
+      coinOut: { type: Number, default: 0 },
+      totalWonCredits: { type: Number, default: 0 },
+      gamesPlayed: { type: Number, default: 0 },
+      gamesWon: { type: Number, default: 0 },
+    },
+    meterCount: { type: Number, default: 0 },
+    rolledUpAt: { type: Date, default: Date.now },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+MetersDailySchema.index({ machine: 1, gamingDay: 1 }, { unique: true });
+MetersDailySchema.index({ location: 1, rangeStart: 1 });
+MetersDailySchema.index({ machine: 1, rangeStart: 1 });
+
+export const MetersDaily =
+  models.MetersDaily || model('MetersDaily', MetersDailySchema, 'metersDaily');
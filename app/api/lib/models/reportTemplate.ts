@@ -0,0 +1,37 @@
+import { Schema, model, models } from 'mongoose';
+
+const ReportTemplateSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    name: { type: String, required: true },
+    description: { type: String, default: '' },
+    reportType: {
+      type: String,
+      enum: [
+        'query-builder',
+        'licencee-leaderboard',
+        'machine-utilization',
+        'shifts',
+      ],
+      required: true,
+    },
+    parameters: { type: Schema.Types.Mixed, default: {} },
+    outputFormat: {
+      type: String,
+      enum: ['json', 'csv'],
+      default: 'json',
+    },
+    createdBy: { type: String, default: null },
+    lastRunAt: { type: Date, default: null },
+  },
+  { timestamps: true, versionKey: false, minimize: false }
+);
+
+ReportTemplateSchema.index({ name: 1 }, { unique: true });
+
+export const ReportTemplate =
+  models.ReportTemplate ||
+  model('ReportTemplate', ReportTemplateSchema, 'reporttemplates');
@@ -0,0 +1,44 @@
+import { Schema, model, models } from 'mongoose';
+
+const RollupCheckpointSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    job: { type: String, required: true },
+    from: { type: String, required: true },
+    to: { type: String, required: true },
+    lastCompletedDay: { type: String, default: null },
+    status: {
+      type: String,
+      enum: ['running', 'completed', 'failed'],
+      default: 'running',
+    },
+    verification: [
+      {
+        _id: false,
+        month: { type: String, required: true },
+        locationsChecked: { type: Number, default: 0 },
+        mismatches: [
+          {
+            _id: false,
+            location: { type: String },
+            field: { type: String },
+            raw: { type: Number },
+            rollup: { type: Number },
+          },
+        ],
+        verifiedAt: { type: Date, default: Date.now },
+      },
+    ],
+    lastError: { type: String },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+RollupCheckpointSchema.index({ job: 1 });
+
+export const RollupCheckpoint =
+  models.RollupCheckpoint ||
+  model('RollupCheckpoint', RollupCheckpointSchema, 'rollupCheckpoints');
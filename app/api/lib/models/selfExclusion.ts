@@ -0,0 +1,24 @@
+import { Schema, model, models } from 'mongoose';
+
+const SelfExclusionSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    member: { type: String, required: true },
+    gamingLocation: { type: String, default: null },
+    startDate: { type: Date, required: true },
+    endDate: { type: Date, default: null },
+    reason: { type: String, default: '' },
+    recordedBy: { type: String, default: null },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+SelfExclusionSchema.index({ member: 1, startDate: -1 });
+SelfExclusionSchema.index({ gamingLocation: 1, startDate: -1 });
+
+export const SelfExclusion =
+  models.SelfExclusion ||
+  model('SelfExclusion', SelfExclusionSchema, 'selfexclusions');
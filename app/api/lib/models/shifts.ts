@@ -35,7 +35,7 @@ const shiftSchema = new Schema(
     notes: { type: String },
     deletedAt: {
       type: Date,
-      default: new Date('1969-12-31T23:59:59.999+0000'),
+      default: null,
     },
     createdAt: { type: Date },
     updatedAt: { type: Date },
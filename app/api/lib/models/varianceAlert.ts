@@ -0,0 +1,37 @@
+import { Schema, model, models } from 'mongoose';
+
+const VarianceAlertSchema = new Schema(
+  {
+    _id: {
+      type: String,
+      required: true,
+    },
+    location: { type: String, required: true },
+    locationName: { type: String },
+    licencee: { type: String, default: null },
+    /** Gaming day, YYYY-MM-DD */
+    gamingDay: { type: String, required: true },
+    gross: { type: Number, required: true },
+    baseline: { type: Number, required: true },
+    sampleSize: { type: Number, required: true },
+    deviationPercent: { type: Number, required: true },
+    thresholdPercent: { type: Number, required: true },
+    details: { type: String },
+    status: {
+      type: String,
+      enum: ['open', 'confirmed', 'dismissed'],
+      default: 'open',
+    },
+    detectedAt: { type: Date, default: Date.now },
+    reviewedBy: { type: String, default: null },
+    reviewedAt: { type: Date, default: null },
+  },
+  { timestamps: true, versionKey: false }
+);
+
+VarianceAlertSchema.index({ location: 1, gamingDay: 1 }, { unique: true });
+VarianceAlertSchema.index({ status: 1, detectedAt: -1 });
+
+export const VarianceAlert =
+  models.VarianceAlert ||
+  model('VarianceAlert', VarianceAlertSchema, 'varianceAlerts');
@@ -0,0 +1,178 @@
+/**
+ * Route Schemas: Activity Logs
+ *
+ * `/api/activity-logs/**`: the audit trail of changes made in the app.
+ *
+ * @module app/api/lib/routeSchemas/activityLogs
+ */
+
+import {
+  dataResponse,
+  defineRoute,
+  errors,
+  messageError,
+  messageResponseSchema,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const activityLogSchema = typedObject('ActivityLogDocument (shared/types)');
+
+const deleteTypeSchema = z
+  .enum(['soft', 'hard'])
+  .default('soft')
+  .describe('soft sets deletedAt; hard removes the entry');
+
+const developerOnly = messageError('Developer role only');
+const adminOrDeveloperOnly = messageError('Admin and developer roles only');
+
+// ============================================================================
+// Activity Logs
+// ============================================================================
+
+export const listActivityLogsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/activity-logs',
+  tag: 'Activity Logs',
+  summary: 'List activity log entries',
+  description:
+    'Managers and location admins see entries about their licencees or locations. With search and no username, email or resourceId filter, results are ranked by relevance.',
+  query: z.object({
+    page: params.page.default(1),
+    limit: params.limit.default(50),
+    userId: z.string().optional().describe("The acting user's id"),
+    username: z.string().optional().describe('Case-insensitive match'),
+    email: z.string().optional().describe('Case-insensitive match'),
+    action: z.string().optional().describe('e.g. CREATE, UPDATE'),
+    resource: z.string().optional().describe('e.g. machine, user'),
+    resourceId: z.string().optional(),
+    membershipLog: params.flag,
+    startDate: params.isoDate.optional().describe('Earliest timestamp'),
+    endDate: params.isoDate.optional().describe('Latest timestamp'),
+    search: params.search,
+    sortBy: z.string().default('timestamp'),
+    sortOrder: z.enum(['asc', 'desc']).default('desc'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of entries',
+      schema: dataResponse(
+        z.object({
+          activities: z.array(activityLogSchema),
+          pagination: z.object({
+            currentPage: z.number(),
+            totalPages: z.number(),
+            totalCount: z.number(),
+            limit: z.number(),
+          }),
+        })
+      ),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const createActivityLogRoute = defineRoute({
+  method: 'POST',
+  path: '/api/activity-logs',
+  tag: 'Activity Logs',
+  summary: 'Record an activity log entry',
+  description:
+    'Changes are computed from previousData and newData when either is given.',
+  body: z.object({
+    action: z.string().min(1),
+    resource: z.string().min(1),
+    resourceId: z.coerce.string().min(1),
+    userId: z.string().min(1),
+    username: z.string().min(1),
+    userRole: z.string().nullish().describe('Default: user'),
+    resourceName: z.string().nullish(),
+    details: z.string().nullish(),
+    description: z.string().nullish(),
+    actor: z
+      .object({ id: z.string(), email: z.string(), role: z.string() })
+      .nullish()
+      .describe('Default: from userId, username and userRole'),
+    changes: z
+      .array(
+        z.object({
+          field: z.string(),
+          oldValue: z.unknown(),
+          newValue: z.unknown(),
+        })
+      )
+      .nullish(),
+    previousData: z.record(z.unknown()).nullish(),
+    newData: z.record(z.unknown()).nullish(),
+  }),
+  responses: {
+    200: {
+      description: 'Recorded',
+      schema: dataResponse(z.object({ activityLog: activityLogSchema })),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const clearActivityLogsRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/activity-logs',
+  tag: 'Activity Logs',
+  summary: 'Remove every activity log entry',
+  responses: {
+    200: { description: 'Cleared', schema: messageResponseSchema },
+    401: errors.unauthorized,
+    403: developerOnly,
+  },
+});
+
+export const bulkDeleteActivityLogsRoute = defineRoute({
+  method: 'POST',
+  path: '/api/activity-logs/bulk-delete',
+  tag: 'Activity Logs',
+  summary: 'Delete activity log entries',
+  description: 'Admin and developer roles only.',
+  body: z.object({
+    ids: z.array(z.string()).min(1),
+    deleteType: deleteTypeSchema,
+  }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: dataResponse(
+        z.object({
+          deletedCount: z.number(),
+          deleteType: z.enum(['soft', 'hard']),
+        })
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOrDeveloperOnly,
+    404: messageError('None of the entries exist'),
+  },
+});
+
+export const deleteActivityLogRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/activity-logs/{id}',
+  tag: 'Activity Logs',
+  summary: 'Delete an activity log entry',
+  description: 'Admin and developer roles only.',
+  query: z.object({ deleteType: deleteTypeSchema }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({ success: z.literal(true) }),
+    },
+    401: errors.unauthorized,
+    403: adminOrDeveloperOnly,
+    404: messageError('Not found'),
+  },
+});
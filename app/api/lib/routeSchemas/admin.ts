@@ -0,0 +1,310 @@
+/**
+ * Route Schemas: Admin
+ *
+ * `/api/admin/**`: jobs admins and developers run by hand — dashboard
+ * snapshots, integrity digests, API quotas and the daily meters rollup —
+ * and the `/api/migration/**` export from the production source.
+ *
+ * @module app/api/lib/routeSchemas/admin
+ */
+
+import {
+  adminOnly,
+  defineRoute,
+  errors,
+  messageError,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Snapshots & Digests
+// ============================================================================
+
+export const takeDashboardSnapshotsRoute = defineRoute({
+  method: 'POST',
+  path: '/api/admin/dashboard-snapshots',
+  tag: 'Admin',
+  summary: 'Take dashboard snapshots now',
+  query: z.object({ licencee: params.licencees }),
+  responses: {
+    200: {
+      description: 'Run summary; success is false when any failed',
+      schema: typedObject('DashboardSnapshotRun (helpers/dashboardSnapshots)'),
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const sendIntegrityDigestRoute = defineRoute({
+  method: 'POST',
+  path: '/api/admin/integrity-digest',
+  tag: 'Admin',
+  summary: 'Send the integrity issue digests',
+  query: z.object({
+    licencee: params.licencees,
+    since: params.isoDate
+      .optional()
+      .describe('Oldest detection included (default: 7 days ago)'),
+    dryRun: params.flag,
+  }),
+  responses: {
+    200: {
+      description: 'Digest summary; success is false when any failed',
+      schema: typedObject('IntegrityDigestResult (helpers/integrityDigest)'),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const rateLimitsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/admin/rate-limits',
+  tag: 'Admin',
+  summary: 'API quotas and throttling on this instance',
+  responses: {
+    200: {
+      description: 'Quota stats',
+      schema: typedObject('ApiQuotaStats (helpers/apiQuotas)'),
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+// ============================================================================
+// Daily Meters Rollup
+// ============================================================================
+
+export const rollupMetersDailyRoute = defineRoute({
+  method: 'POST',
+  path: '/api/admin/rollup-meters-daily',
+  tag: 'Admin',
+  summary: 'Roll up one gaming day of meters',
+  query: z.object({
+    date: params.gamingDay
+      .optional()
+      .describe("Gaming day, YYYY-MM-DD (default: yesterday's)"),
+    locationId: params.locationId,
+  }),
+  responses: {
+    200: {
+      description: 'Rollup result',
+      schema: typedObject('MeterRollupResult (helpers/metersDaily)'),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const getBackfillCheckpointRoute = defineRoute({
+  method: 'GET',
+  path: '/api/admin/rollup-meters-daily/backfill',
+  tag: 'Admin',
+  summary: 'Backfill checkpoint for a range',
+  query: z.object({ from: params.gamingDay, to: params.gamingDay }),
+  responses: {
+    200: {
+      description: 'Checkpoint, null before the first run',
+      schema: z.object({
+        success: z.literal(true),
+        checkpoint: z.record(z.unknown()).nullable(),
+      }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const runBackfillRoute = defineRoute({
+  method: 'POST',
+  path: '/api/admin/rollup-meters-daily/backfill',
+  tag: 'Admin',
+  summary: 'Run the next chunk of a rollup backfill',
+  body: z.object({
+    from: params.gamingDay,
+    to: params.gamingDay.describe('Last gaming day, inclusive'),
+    maxMonths: z.coerce
+      .number()
+      .int()
+      .default(1)
+      .describe('Months processed by this call, clamped to 1-12'),
+    verify: z.boolean().default(true),
+    restart: z.boolean().default(false),
+  }),
+  responses: {
+    200: {
+      description: 'Chunk result',
+      schema: typedObject(
+        'MetersDailyBackfillResult (helpers/metersDailyBackfill)'
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+// ============================================================================
+// Maintenance
+// ============================================================================
+
+export const createIndexesInfoRoute = defineRoute({
+  method: 'GET',
+  path: '/api/admin/create-indexes',
+  tag: 'Admin',
+  summary: 'Collections the index job covers',
+  description: 'No side effects; POST creates the indexes.',
+  responses: {
+    200: {
+      description: 'Hint and collections',
+      schema: z.object({
+        message: z.string(),
+        collections: z.array(z.string()),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const createIndexesRoute = defineRoute({
+  method: 'POST',
+  path: '/api/admin/create-indexes',
+  tag: 'Admin',
+  summary: 'Create the database indexes',
+  description: 'Safe to re-run; existing indexes are skipped.',
+  responses: {
+    200: {
+      description: 'Per-collection results',
+      schema: z.object({
+        success: z.literal(true),
+        message: z.string(),
+        results: z.array(z.string()),
+      }),
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const resolveMachineNamesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/admin/resolve-machine-names',
+  tag: 'Admin',
+  summary: 'Replace machine ids in activity log names with machine names',
+  description:
+    'Developer role only. Call until remaining is 0; each call updates up to limit entries.',
+  query: z.object({
+    limit: params.limit.default(100).describe('Entries per call, at most 500'),
+  }),
+  responses: {
+    200: {
+      description: 'Progress',
+      schema: z.object({
+        success: z.literal(true),
+        updated: z.number(),
+        total: z.number(),
+        remaining: z.number(),
+      }),
+    },
+    401: errors.unauthorized,
+    403: messageError('Developer role only'),
+  },
+});
+
+// ============================================================================
+// SMIB Classification
+// ============================================================================
+
+export const smibSyncStatusRoute = defineRoute({
+  method: 'GET',
+  path: '/api/admin/smib-sync',
+  tag: 'Admin',
+  summary: 'When location SMIB classifications were last synced',
+  public: true,
+  responses: {
+    200: {
+      description: 'Sync status',
+      schema: z.object({
+        lastSync: z.date().nullable(),
+        isStale: z.boolean(),
+        staleAfterHours: z.number(),
+      }),
+    },
+  },
+});
+
+export const syncSmibClassificationsRoute = defineRoute({
+  method: 'POST',
+  path: '/api/admin/smib-sync',
+  tag: 'Admin',
+  summary: 'Reclassify the SMIB status of the accessible locations',
+  description:
+    'A location is fullSMIBs when all of its machines have a relay id, semiSMIBs when some do and noSMIBLocation otherwise.',
+  query: z.object({ licencee: params.licencee }),
+  body: z
+    .object({ licencee: params.licencee.describe('Overrides the query') })
+    .optional(),
+  responses: {
+    200: {
+      description: 'Counts',
+      schema: z.object({
+        success: z.literal(true),
+        synced: z.number(),
+        unchanged: z.number(),
+        total: z.number(),
+        durationMs: z.number(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Migration
+// ============================================================================
+
+export const migrateMachinesMetersRoute = defineRoute({
+  method: 'POST',
+  path: '/api/migration/machines-meters',
+  tag: 'Admin',
+  summary: "Export a licencee's data from the production source",
+  description:
+    'Writes one JSON file per collection to migration_exports, covering today and yesterday.',
+  body: z
+    .object({
+      licenceeName: z.string().min(1).default('Cabana'),
+      migrateMeters: z.boolean().default(true),
+      includeCollectionOptions: z
+        .boolean()
+        .default(false)
+        .describe('Also export indexes, validators, collation and views'),
+    })
+    .default({}),
+  responses: {
+    200: {
+      description: 'Export summary with the run log',
+      schema: z.object({
+        success: z.literal(true),
+        licencee: z.string(),
+        exportPath: z.string(),
+        counts: z.record(z.number()),
+        collectionOptions: z
+          .object({
+            collections: z.number(),
+            views: z.number(),
+            indexes: z.number(),
+          })
+          .optional(),
+        logs: z.array(z.string()),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
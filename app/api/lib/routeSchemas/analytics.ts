@@ -0,0 +1,459 @@
+/**
+ * Route Schemas: Analytics
+ *
+ * `/api/analytics/**`: dashboard totals and charts, trends, location and
+ * machine analytics and configured reports.
+ *
+ * @module app/api/lib/routeSchemas/analytics
+ */
+
+import { MAX_TREND_DAYS } from '@/app/api/lib/helpers/dashboardSnapshots';
+import {
+  CHART_SERIES_GRANULARITIES,
+  DEFAULT_CHART_MAX_POINTS,
+  MAX_CHART_MAX_POINTS,
+} from '@/app/api/lib/helpers/reports/chartSeries';
+import { reportConfigSchema } from '@/app/api/lib/helpers/reports/general';
+import {
+  CURRENCIES,
+  dataResponse,
+  defineRoute,
+  errors,
+  params,
+  trendQuery,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  MAX_GEOHASH_PRECISION,
+  MIN_GEOHASH_PRECISION,
+} from '@/app/api/lib/utils/geohash';
+import type { ChartSeriesGranularity } from '@/shared/types/analytics';
+import { z } from 'zod';
+
+// ============================================================================
+// Analytics
+// ============================================================================
+
+export const chartsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/charts',
+  tag: 'Analytics',
+  summary: 'Dashboard chart data',
+  query: z.object({
+    licencee: params.requiredLicencee,
+    period: z.enum(['last7days', 'last30days']).default('last30days'),
+    currency: params.currency,
+    strategy: params.strategy,
+  }),
+  responses: {
+    200: {
+      description: 'Chart data',
+      schema: typedObject('getChartsData (helpers/reports/analytics)'),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const chartSeriesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/charts/series',
+  tag: 'Analytics',
+  summary: 'Chart series, downsampled to a point budget',
+  query: z.object({
+    licencee: params.requiredLicencee,
+    startDate: z
+      .string()
+      .optional()
+      .describe('ISO start (default: 30 days before endDate)'),
+    endDate: params.endDate,
+    granularity: z
+      .enum(
+        CHART_SERIES_GRANULARITIES as [
+          ChartSeriesGranularity,
+          ...ChartSeriesGranularity[],
+        ]
+      )
+      .optional()
+      .describe('Default: hourly up to two days, daily otherwise'),
+    maxPoints: z.coerce
+      .number()
+      .int()
+      .default(DEFAULT_CHART_MAX_POINTS)
+      .describe(`Point budget, clamped to 1-${MAX_CHART_MAX_POINTS}`),
+    currency: params.currency,
+    strategy: params.strategy,
+  }),
+  responses: {
+    200: {
+      description: 'Series',
+      schema: typedObject('ChartSeries (shared/types/analytics)'),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const dashboardRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/dashboard',
+  tag: 'Analytics',
+  summary: 'Dashboard totals',
+  query: z.object({
+    licencee: params.requiredLicencee,
+    currency: params.currency,
+    strategy: params.strategy,
+  }),
+  responses: {
+    200: {
+      description: 'Totals',
+      schema: z.object({
+        globalStats: typedObject(
+          'DashboardAnalyticsResult (helpers/reports/analytics)'
+        ),
+        currency: z.enum(CURRENCIES),
+        converted: z.boolean(),
+      }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const dashboardTrendRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/dashboard/trend',
+  tag: 'Analytics',
+  summary: 'Dashboard totals over time, from stored snapshots',
+  query: z.object({
+    licencee: params.requiredLicencee,
+    granularity: z
+      .enum(['day', 'hour'])
+      .default('day')
+      .describe('Hourly snapshots are kept 30 days'),
+    days: z.coerce
+      .number()
+      .int()
+      .default(90)
+      .describe(`Window length, clamped to 1-${MAX_TREND_DAYS}`),
+    currency: params.currency,
+  }),
+  responses: {
+    200: {
+      description: 'Snapshot points',
+      schema: typedObject('DashboardTrend (helpers/dashboardSnapshots)'),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+/** A `GET /api/analytics/*-trends` route */
+function trendRoute(path: string, summary: string, itemType: string) {
+  return defineRoute({
+    method: 'GET',
+    path,
+    tag: 'Analytics',
+    summary,
+    query: trendQuery,
+    responses: {
+      200: {
+        description: 'Trend points',
+        schema: dataResponse(
+          z.array(typedObject(`${itemType} (helpers/trends/general)`))
+        ).extend({
+          timePeriod: z.string(),
+          locationIds: z.array(z.string()).nullable(),
+        }),
+      },
+      400: errors.badRequest,
+      401: errors.unauthorized,
+    },
+  });
+}
+
+export const handleTrendsRoute = trendRoute(
+  '/api/analytics/handle-trends',
+  'Handle (coin in) over time',
+  'HandleTrendItem'
+);
+
+export const jackpotTrendsRoute = trendRoute(
+  '/api/analytics/jackpot-trends',
+  'Jackpots over time',
+  'JackpotTrendItem'
+);
+
+export const playsTrendsRoute = trendRoute(
+  '/api/analytics/plays-trends',
+  'Games played over time',
+  'PlaysTrendItem'
+);
+
+export const winLossTrendsRoute = trendRoute(
+  '/api/analytics/winloss-trends',
+  'Win / loss over time',
+  'WinLossTrendItem'
+);
+
+export const hourlyRevenueRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/hourly-revenue',
+  tag: 'Analytics',
+  summary: "A location's revenue by hour",
+  query: z.object({
+    locationId: params.requiredLocationId,
+    timePeriod: z.string().default('24h'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+  }),
+  responses: {
+    200: {
+      description: 'Hourly revenue',
+      schema: z.array(
+        typedObject('HourlyRevenueItem (helpers/trends/general)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const locationHeatmapRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/location-heatmap',
+  tag: 'Analytics',
+  summary: 'Location financials aggregated into geohash cells',
+  query: z.object({
+    precision: z.coerce
+      .number()
+      .int()
+      .min(MIN_GEOHASH_PRECISION)
+      .max(MAX_GEOHASH_PRECISION)
+      .default(5)
+      .describe('Geohash length (5 is about 4.9 km cells)'),
+    licencee: params.licencee,
+    timePeriod: params.timePeriod('Today'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+  }),
+  responses: {
+    200: {
+      description: 'Cells',
+      schema: dataResponse(
+        typedObject('LocationHeatmapResult (helpers/reports/locationHeatmap)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const locationTrendsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/location-trends',
+  tag: 'Analytics',
+  summary: 'Per-location financials over time',
+  query: z.object({
+    locationIds: z.string().describe('Comma-separated location ids'),
+    timePeriod: params.timePeriod('Today'),
+    licencee: params.licencee,
+    startDate: params.startDate,
+    endDate: params.endDate,
+    currency: params.currency,
+    granularity: z
+      .enum(['hourly', 'minute', 'daily', 'weekly', 'monthly'])
+      .default('daily'),
+    status: z.enum(['Online', 'Offline', 'All']).optional(),
+    gameType: z.string().optional(),
+    search: z.string().optional(),
+    includeArchived: z.enum(['true', 'false']).optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Trends',
+      schema: typedObject('getLocationTrends (helpers/trends/locations)'),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const topLocationsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/locations',
+  tag: 'Analytics',
+  summary: 'Top locations',
+  query: z.object({
+    licencee: params.requiredLicencee,
+    currency: params.currency,
+  }),
+  responses: {
+    200: {
+      description: 'Locations',
+      schema: typedObject(
+        'getTopLocationsAnalytics (helpers/reports/analytics)'
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const logisticsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/logistics',
+  tag: 'Analytics',
+  summary: 'Machine movement requests',
+  query: z.object({
+    searchTerm: z
+      .string()
+      .optional()
+      .describe('Matches cabinet, location and moved-by user'),
+    statusFilter: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Movements',
+      schema: dataResponse(
+        z.array(typedObject('LogisticsEntry (shared/types/reports)'))
+      ).extend({ message: z.string() }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const machineHourlyRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/machine-hourly',
+  tag: 'Analytics',
+  summary: 'Machine financials by hour',
+  description: 'At least one of locationIds or machineIds is required.',
+  query: z.object({
+    locationIds: params.locationIds,
+    machineIds: z.string().optional().describe('Comma-separated machine ids'),
+    timePeriod: params.timePeriod('Today'),
+    licencee: params.licencee,
+    startDate: params.startDate,
+    endDate: params.endDate,
+    currency: params.currency,
+  }),
+  responses: {
+    200: {
+      description: 'Hourly data',
+      schema: typedObject(
+        'getMachineHourlyData (helpers/trends/machineHourly)'
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const topMachinesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/machines',
+  tag: 'Analytics',
+  summary: 'Top machines by revenue',
+  query: z.object({
+    limit: z.coerce.number().int().min(1).default(5),
+    licencee: params.licencee,
+    location: z.string().optional().describe('Location id'),
+  }),
+  responses: {
+    200: {
+      description: 'Machines',
+      schema: z.object({
+        machines: z.array(
+          typedObject('MachineAnalytics (shared/types/reports)')
+        ),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const machineStatsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/machines/stats',
+  tag: 'Analytics',
+  summary: 'Machine counts: total, online and SAS',
+  query: z.object({
+    licencee: z
+      .string()
+      .optional()
+      .describe("Licencee id; 'all' or omitted for every accessible one"),
+  }),
+  responses: {
+    200: {
+      description: 'Counts',
+      schema: typedObject('MachineStatsResult (helpers/reports/analytics)'),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const manufacturerPerformanceRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/manufacturer-performance',
+  tag: 'Analytics',
+  summary: "A location's performance by manufacturer",
+  query: z.object({
+    locationId: z.string().describe("Location id; 'all' is refused"),
+    timePeriod: params.timePeriod('Today'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    licencee: params.licencee,
+  }),
+  responses: {
+    200: {
+      description: 'Per manufacturer',
+      schema: z.array(
+        typedObject(
+          'ManufacturerPerformanceItem (helpers/reports/manufacturerPerformance)'
+        )
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const generateReportRoute = defineRoute({
+  method: 'POST',
+  path: '/api/analytics/reports',
+  tag: 'Analytics',
+  summary: 'Generate a configured report',
+  body: reportConfigSchema,
+  responses: {
+    200: {
+      description: 'Report data',
+      schema: typedObject('ReportData (shared/types/reports)'),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const locationTopMachinesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/analytics/top-machines',
+  tag: 'Analytics',
+  summary: "A location's top machines",
+  query: z.object({
+    locationId: params.requiredLocationId,
+    timePeriod: z.string().default('24h'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+  }),
+  responses: {
+    200: {
+      description: 'Machines',
+      schema: z.array(z.record(z.unknown())),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
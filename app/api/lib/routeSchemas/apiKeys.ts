@@ -0,0 +1,120 @@
+/**
+ * Route Schemas: API Keys
+ *
+ * `/api/api-keys/**`: keys for machine-to-machine clients.
+ *
+ * @module app/api/lib/routeSchemas/apiKeys
+ */
+
+import { API_KEY_SCOPE_GROUPS } from '@/app/api/lib/helpers/apiKeys';
+import {
+  adminOnly,
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const apiKeyQuotaSchema = z.object({
+  requestsPerMinute: z.number().int().min(1).optional(),
+  burst: z.number().int().min(1).optional(),
+  concurrentAggregations: z.number().int().min(1).optional(),
+});
+
+const apiKeySchema = z.object({
+  _id: z.string(),
+  name: z.string(),
+  prefix: z.string().describe('First characters of the key'),
+  licencees: z.array(z.string()),
+  scopes: z.array(z.string()).describe('`group` or `group:read`'),
+  quota: apiKeyQuotaSchema.optional(),
+  description: z.string().optional(),
+  createdBy: z.string().nullable(),
+  expiresAt: z.date().nullable(),
+  lastUsedAt: z.date().nullable(),
+  revokedAt: z.date().nullable(),
+  revokedBy: z.string().nullable(),
+  createdAt: z.date(),
+  updatedAt: z.date(),
+});
+
+// ============================================================================
+// API Keys
+// ============================================================================
+
+export const listApiKeysRoute = defineRoute({
+  method: 'GET',
+  path: '/api/api-keys',
+  tag: 'API Keys',
+  summary: 'List API keys',
+  description:
+    'Newest first, revoked keys included, without hashes. Admin and developer roles only; not callable with an API key.',
+  responses: {
+    200: { description: 'Keys', schema: dataResponse(z.array(apiKeySchema)) },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const createApiKeyRoute = defineRoute({
+  method: 'POST',
+  path: '/api/api-keys',
+  tag: 'API Keys',
+  summary: 'Create an API key',
+  description:
+    'The key is returned once, in `key`; only its hash is stored. Admin and developer roles only.',
+  body: z.object({
+    name: z
+      .string()
+      .regex(/^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$/)
+      .describe('Unique'),
+    licencees: z.array(z.string()).min(1).describe('Licencees the key can see'),
+    scopes: z
+      .array(z.string())
+      .min(1)
+      .describe(
+        `Endpoint groups (${Object.keys(API_KEY_SCOPE_GROUPS).join(', ')}), each optionally suffixed :read for GET only`
+      ),
+    quota: apiKeyQuotaSchema
+      .optional()
+      .describe('Overrides of the default API quota'),
+    expiresAt: z
+      .string()
+      .refine(value => !Number.isNaN(new Date(value).getTime()), {
+        message: 'must be a valid date',
+      })
+      .optional()
+      .describe('ISO date after which the key is refused'),
+    description: z.string().optional(),
+  }),
+  responses: {
+    201: {
+      description: 'Created',
+      schema: dataResponse(apiKeySchema).extend({ key: z.string() }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+    409: { description: 'Name in use', schema: errorResponseSchema },
+  },
+});
+
+export const revokeApiKeyRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/api-keys/{keyId}',
+  tag: 'API Keys',
+  summary: 'Revoke an API key',
+  description:
+    'By id or name. The record is kept with revokedAt and revokedBy; requests with the key fail with 401.',
+  responses: {
+    200: { description: 'Revoked', schema: dataResponse(apiKeySchema) },
+    401: errors.unauthorized,
+    403: adminOnly,
+    404: errors.notFound,
+  },
+});
@@ -0,0 +1,540 @@
+/**
+ * Route Schemas: Cabinets
+ *
+ * `/api/cabinets/**`: machine (cabinet) details, meters, charts and
+ * configuration changes; the cabinet list with its metrics, and the raw
+ * meter and meter-transfer tools.
+ *
+ * @module app/api/lib/routeSchemas/cabinets
+ */
+
+import { DEFAULT_COMPARISON_WINDOW_DAYS } from '@/app/api/lib/helpers/machineReconfiguration';
+import {
+  adminOnly,
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageResponseSchema,
+  params,
+  TIME_PERIODS,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const reconfigurationEntrySchema = z.object({
+  _id: z.string(),
+  changedAt: z.date(),
+  changes: z.array(
+    z.object({
+      field: z.string(),
+      oldValue: z.union([z.string(), z.number()]).nullable(),
+      newValue: z.union([z.string(), z.number()]),
+    })
+  ),
+  reason: z.string(),
+  userId: z.string(),
+  username: z.string(),
+  recordedAt: z.date(),
+});
+
+const cabinetSchema = typedObject('GamingMachine (shared/types)');
+
+const numeric = z.union([z.string(), z.number()]);
+
+/**
+ * Editor fields mapped onto the machine (helpers/cabinetUpdate). Dotted keys
+ * such as `gameConfig.maxBet` and `custom.name` are also accepted.
+ */
+const cabinetUpdateSchema = z
+  .object({
+    assetNumber: z.string().describe('Serial number'),
+    installedGame: z.string(),
+    gameType: z.string(),
+    manufacturer: z.string(),
+    status: z.string().describe('Asset status'),
+    machineStatus: z.string(),
+    cabinetType: z.string(),
+    locationId: z.string().describe('Moves the machine'),
+    accountingDenomination: numeric,
+    meterUnit: z.string(),
+    gameConfig: z
+      .object({
+        theoreticalRtp: numeric,
+        maxBet: numeric,
+        payTableId: z.string(),
+        additionalId: z.string(),
+        gameOptions: z.string(),
+        progressiveGroup: z.string(),
+      })
+      .partial()
+      .passthrough(),
+    custom: z.object({ name: z.string() }),
+    collectionTime: z.string().describe('ISO date'),
+    collectionMeters: z
+      .object({ metersIn: numeric, metersOut: numeric })
+      .partial(),
+    collectionMultiplier: numeric,
+    collectorDenomination: numeric,
+    isCronosMachine: z.boolean(),
+    smbId: z.string(),
+    smibBoard: z.string(),
+    relayId: z.string(),
+    collectionSettings: z
+      .object({
+        lastMetersIn: numeric,
+        lastMetersOut: numeric,
+        lastCollectionTime: z.string().describe('ISO date'),
+      })
+      .partial()
+      .passthrough(),
+  })
+  .partial()
+  .passthrough();
+
+const cabinetAccessErrors = {
+  401: errors.unauthorized,
+  403: errors.forbidden,
+  404: errors.notFound,
+};
+
+const transferMetersAccess =
+  'Owner, admin and developer roles, with access to the location.';
+
+// ============================================================================
+// Cabinets
+// ============================================================================
+
+export const listCabinetsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cabinets',
+  tag: 'Machines',
+  summary: "One cabinet, a location's cabinets, or an availability check",
+  description:
+    'Any of checkSerial, checkSmib or checkCustomName runs the availability check; otherwise id or locationId is required.',
+  query: z.object({
+    id: z.string().optional().describe('Cabinet id'),
+    locationId: z.string().optional(),
+    archived: params.flag.describe('Deleted cabinets of the location'),
+    checkSerial: z.string().optional(),
+    checkSmib: z.string().optional(),
+    checkCustomName: z.string().optional(),
+    excludeId: z
+      .string()
+      .optional()
+      .describe('Cabinet to ignore in the availability check'),
+  }),
+  responses: {
+    200: {
+      description: 'Availability, the cabinet, or the cabinets',
+      schema: z.union([
+        z.object({ success: z.literal(true), available: z.boolean() }),
+        dataResponse(cabinetSchema),
+        dataResponse(z.array(cabinetSchema)),
+      ]),
+    },
+    400: errors.badRequest,
+    ...cabinetAccessErrors,
+  },
+});
+
+export const createCabinetRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cabinets',
+  tag: 'Machines',
+  summary: 'Create a cabinet',
+  description: 'Rejected when the serial number or SMIB is already in use.',
+  body: z
+    .object({
+      serialNumber: z.string().min(1),
+      gamingLocation: z.string().optional(),
+      locationId: z.string().optional().describe('Alias of gamingLocation'),
+      game: z.string().optional(),
+      gameType: z.string().optional(),
+      manufacturer: z.string().optional(),
+      cabinetType: z.string().optional(),
+      smibBoard: z.string().optional(),
+      relayId: z.string().optional(),
+      custom: z.object({ name: z.string() }).optional(),
+    })
+    .passthrough()
+    .refine(data => Boolean(data.gamingLocation || data.locationId), {
+      message: 'Location required',
+      path: ['gamingLocation'],
+    }),
+  responses: {
+    201: { description: 'Created', schema: dataResponse(cabinetSchema) },
+    400: {
+      description: 'Validation failed, or the cabinet already exists',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const legacyUpdateCabinetRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/cabinets',
+  tag: 'Machines',
+  summary: 'Set fields on a cabinet (legacy)',
+  description:
+    'The body is set on the machine as sent. Prefer PUT /api/cabinets/{cabinetId}.',
+  query: z.object({ id: z.string().describe('Cabinet id') }),
+  body: typedObject('Partial GamingMachine (shared/types)'),
+  responses: {
+    200: { description: 'Updated', schema: dataResponse(cabinetSchema) },
+    400: errors.validation,
+    ...cabinetAccessErrors,
+  },
+});
+
+export const legacyDeleteCabinetRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/cabinets',
+  tag: 'Machines',
+  summary: 'Delete a cabinet (legacy)',
+  description: 'Soft delete. Prefer DELETE /api/cabinets/{cabinetId}.',
+  query: z.object({ id: z.string().describe('Cabinet id') }),
+  responses: {
+    200: { description: 'Deleted', schema: messageResponseSchema },
+    400: errors.validation,
+    ...cabinetAccessErrors,
+  },
+});
+
+export const cabinetAggregationRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cabinets/aggregation',
+  tag: 'Machines',
+  summary: 'Cabinets with their metrics for a period',
+  description:
+    "Limited to the user's locations, converted to the display currency. Technicians only see the last hour.",
+  query: z.object({
+    timePeriod: z
+      .enum(TIME_PERIODS)
+      .describe('Custom uses startDate and endDate'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    licencee: params.licencee,
+    locationId: params.locationIds,
+    locationIds: params.locationIds.describe('Same as locationId'),
+    gameType: z.string().optional().describe('Comma-separated game types'),
+    gameTypes: z.string().optional().describe('Same as gameType'),
+    search: params.search,
+    smibId: z.string().optional(),
+    customName: z.string().optional(),
+    currency: params.currency,
+    onlineStatus: z
+      .string()
+      .toLowerCase()
+      .default('all')
+      .describe('all, online, offline, never-online or archived'),
+    smibStatus: z
+      .string()
+      .toLowerCase()
+      .default('all')
+      .describe('all, smib or no-smib'),
+    membership: z
+      .string()
+      .toLowerCase()
+      .default('all')
+      .describe('all, enabled or disabled'),
+    sortBy: z.string().default('moneyIn'),
+    sortOrder: z.enum(['asc', 'desc']).default('desc'),
+    page: params.page.default(1),
+    limit: params.limit.describe('Rows per page (default: all)'),
+    debug: params.flag,
+  }),
+  responses: {
+    200: {
+      description: 'Cabinets; paginated when a limit is set',
+      schema: dataResponse(
+        z.array(
+          typedObject('CabinetMachineResponse (helpers/cabinetAggregation)')
+        )
+      ).extend({
+        pagination: z
+          .object({
+            page: z.number(),
+            limit: z.number(),
+            total: z.number(),
+            totalPages: z.number(),
+          })
+          .optional(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Cabinet Detail
+// ============================================================================
+
+export const getCabinetRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cabinets/{cabinetId}',
+  tag: 'Machines',
+  summary: 'A cabinet with its metrics for a period',
+  description:
+    'Without timePeriod or a date range the metrics are zero. Converted to the display currency.',
+  query: z.object({
+    timePeriod: params.optionalTimePeriod,
+    startDate: params.startDate,
+    endDate: params.endDate,
+    dateField: z
+      .enum(['readAt', 'createdAt'])
+      .default('readAt')
+      .describe('Meter date the period applies to'),
+    currency: params.currency,
+  }),
+  responses: {
+    200: { description: 'Cabinet', schema: dataResponse(cabinetSchema) },
+    400: errors.badRequest,
+    ...cabinetAccessErrors,
+  },
+});
+
+export const updateCabinetRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/cabinets/{cabinetId}',
+  tag: 'Machines',
+  summary: 'Edit a cabinet',
+  description:
+    'A new serial number or game is copied to the collections of the machine.',
+  body: cabinetUpdateSchema,
+  responses: {
+    200: { description: 'Updated', schema: dataResponse(cabinetSchema) },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const patchCabinetRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/cabinets/{cabinetId}',
+  tag: 'Machines',
+  summary: 'Edit or restore a cabinet',
+  description:
+    'action restore undeletes the cabinet and ignores the other fields.',
+  body: cabinetUpdateSchema.extend({
+    action: z.literal('restore').optional(),
+  }),
+  responses: {
+    200: { description: 'Updated', schema: dataResponse(cabinetSchema) },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const deleteCabinetRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/cabinets/{cabinetId}',
+  tag: 'Machines',
+  summary: 'Delete a cabinet',
+  description:
+    'Soft delete by default. A hard delete needs a developer, owner, admin or location admin.',
+  query: z.object({ hardDelete: params.flag }),
+  responses: {
+    200: { description: 'Deleted', schema: messageResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description: 'Role cannot hard delete',
+      schema: errorResponseSchema,
+    },
+    404: errors.notFound,
+  },
+});
+
+// ============================================================================
+// Raw Meters
+// ============================================================================
+
+export const cabinetMetersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cabinets/{cabinetId}/meters',
+  tag: 'Machines',
+  summary: "A cabinet's raw meter documents",
+  description:
+    'Newest first in pages of 100, including archived meters. search seeks to the page holding the matchOrdinal-th match. Developer role only.',
+  query: z.object({
+    startDate: params.startDate,
+    endDate: params.endDate,
+    dateField: z.enum(['readAt', 'createdAt']).default('readAt'),
+    apiPage: params.page.default(1).describe('1-based page of 100'),
+    search: params.search,
+    searchColumn: z.string().optional().describe('Column to search'),
+    matchOrdinal: z.coerce.number().int().min(0).default(0),
+    matchMode: z.enum(['contains', 'exact']).default('contains'),
+    export: params.flag.describe('Every matching meter at once'),
+    format: z.enum(['csv', 'json']).default('csv').describe('Export format'),
+    columns: z
+      .string()
+      .optional()
+      .describe('Comma-separated CSV columns; movement.* for movement fields'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of meters, or the export',
+      schema: dataResponse(
+        z.array(typedObject('Meter document (models/meters)'))
+      ).extend({
+        total: z
+          .number()
+          .nullable()
+          .describe('null without a date range; use hasMore'),
+        apiPage: z.number().optional(),
+        hasMore: z.boolean().optional(),
+        matchIndex: z.number().optional(),
+        matchCount: z.number().optional(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: { description: 'Developer role only', schema: errorResponseSchema },
+  },
+});
+
+// ============================================================================
+// Transfer Meters
+// ============================================================================
+
+export const transferMetersStatsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cabinets/{cabinetId}/transfer-meters',
+  tag: 'Machines',
+  summary: 'Meters still recorded against a previous location',
+  description: `How many of the cabinet's meters carry a location other than its current one, with the defaults for a transfer. ${transferMetersAccess}`,
+  query: z.object({
+    fromDateTime: z.string().optional().describe('ISO start of the window'),
+    toDateTime: z.string().optional().describe('ISO end of the window'),
+  }),
+  responses: {
+    200: {
+      description: 'Stats',
+      schema: dataResponse(typedObject('TransferMetersStats (shared/types)')),
+    },
+    400: errors.validation,
+    ...cabinetAccessErrors,
+  },
+});
+
+export const transferMetersRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cabinets/{cabinetId}/transfer-meters',
+  tag: 'Machines',
+  summary: "Move a batch of meters to the cabinet's location",
+  description: `Meters read in the window are updated one batch per call; send nextCursor back until remaining is 0. ${transferMetersAccess}`,
+  body: z.object({
+    fromDateTime: z.string().min(1).describe('ISO start of the window'),
+    toDateTime: z.string().min(1).describe('ISO end of the window'),
+    batchSize: z.number().int().min(1).optional(),
+    concurrency: z.number().int().min(1).optional(),
+    cursor: z.string().optional().describe('nextCursor of the last batch'),
+    activityTotal: z
+      .number()
+      .optional()
+      .describe('Total to record in the activity log'),
+    logActivity: z.boolean().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Batch done',
+      schema: dataResponse(
+        typedObject('TransferMetersBatchResult (shared/types)')
+      ),
+    },
+    400: errors.validation,
+    ...cabinetAccessErrors,
+  },
+});
+
+// ============================================================================
+// Reconfigurations
+// ============================================================================
+
+export const getReconfigurationsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cabinets/{cabinetId}/reconfigurations',
+  tag: 'Machines',
+  summary: "A machine's meters split at its reconfigurations",
+  description:
+    'With compare=true, a before/after comparison around one change instead.',
+  query: z.object({
+    compare: z.enum(['true', 'false']).optional(),
+    event: z
+      .string()
+      .optional()
+      .describe('configurationHistory entry to compare (default: latest)'),
+    windowDays: z.coerce
+      .number()
+      .int()
+      .min(1)
+      .default(DEFAULT_COMPARISON_WINDOW_DAYS)
+      .describe('Days on each side of the change'),
+    from: z.string().optional().describe('ISO start of the segment report'),
+    to: z.string().optional().describe('ISO end (default: now)'),
+  }),
+  responses: {
+    200: {
+      description: 'Segments, or the comparison',
+      schema: dataResponse(
+        typedObject(
+          'ReconfigurationReport | ReconfigurationComparison (helpers/machineReconfiguration)'
+        )
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
+
+export const recordReconfigurationRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cabinets/{cabinetId}/reconfigurations',
+  tag: 'Machines',
+  summary: 'Record a game or denomination change',
+  description:
+    'Only fields whose value changes are kept; the change must take effect after the last recorded one. Admin and developer roles only.',
+  body: z.object({
+    values: z
+      .object({
+        game: z.string(),
+        gameType: z.string(),
+        accountingDenomination: z.coerce.number().min(0),
+        payTableId: z.string(),
+        theoreticalRtp: z.coerce.number().min(0),
+        maxBet: z.coerce.number().min(0),
+      })
+      .partial(),
+    changedAt: z
+      .string()
+      .optional()
+      .describe('ISO date the change took effect (default: now)'),
+    reason: z.string().min(1),
+  }),
+  responses: {
+    201: {
+      description: 'Recorded',
+      schema: dataResponse(reconfigurationEntrySchema),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+    404: errors.notFound,
+    409: {
+      description: 'The configuration changed concurrently',
+      schema: errorResponseSchema,
+    },
+  },
+});
@@ -0,0 +1,289 @@
+/**
+ * Route Schemas: Collection Reports
+ *
+ * `/api/collection-reports/**`: collections and the reports they make up.
+ *
+ * @module app/api/lib/routeSchemas/collectionReports
+ */
+
+import {
+  listPageSchema,
+  listQuerySchema,
+} from '@/app/api/lib/helpers/listEndpoint';
+import {
+  collectionListItemSchema,
+  collectionListResource,
+} from '@/app/api/lib/helpers/listResources';
+import {
+  adminOnly,
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  LIST_DESCRIPTION,
+  messageResponseSchema,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const collectionSchema = typedObject(
+  'CollectionDocument (lib/types/collection)'
+);
+
+const meterReading = z.number().nullable();
+
+/** Meters to pre-fill a WOW machine's collection with */
+const wowMetersSchema = z.object({
+  metersIn: meterReading,
+  metersOut: meterReading.describe(
+    "Includes the jackpot when the machine's licencee includes jackpots"
+  ),
+  prevIn: meterReading,
+  prevOut: meterReading,
+  hasPrevious: z.boolean(),
+  currentReadAt: z.string().nullable(),
+  jackpot: meterReading.optional(),
+});
+
+const wowMetersWindow = {
+  startTime: params.isoDate
+    .optional()
+    .describe(
+      'Window start. When sent, previous meters are read at it instead of from the last collection'
+    ),
+  endTime: params.isoDate.optional().describe('Report end. Default: now'),
+};
+
+// ============================================================================
+// Collections
+// ============================================================================
+
+export const listCollectionsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/collection-reports/collections',
+  tag: 'Collections',
+  summary: 'List collections',
+  description: `${LIST_DESCRIPTION} Pass cursor (empty for the first page); without it the endpoint returns the legacy array response.`,
+  query: listQuerySchema(collectionListResource),
+  responses: {
+    200: {
+      description: 'One page of collections',
+      schema: listPageSchema(collectionListItemSchema),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const updateCollectionRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/collection-reports/collections/{id}',
+  tag: 'Collections',
+  summary: 'Edit a collection',
+  description:
+    "Corrects a collection in place. Meter or time changes recalculate its movement and SAS meters, the machine's collection history entry and the collections after it. Other collection fields are set as sent; the collector is never changed.",
+  body: z
+    .object({
+      metersIn: z.number().optional(),
+      metersOut: z.number().optional(),
+      prevIn: z.number().optional(),
+      prevOut: z.number().optional(),
+      timestamp: params.isoDate.optional(),
+      collectionTime: params.isoDate.optional(),
+      sasStartTime: params.isoDate.optional(),
+      sasEndTime: params.isoDate.optional(),
+      ramClear: z
+        .boolean()
+        .optional()
+        .describe('Turning it on is refused for machines with a SMIB'),
+      ramClearMetersIn: z.number().optional(),
+      ramClearMetersOut: z.number().optional(),
+      ramClearCoinIn: z.number().optional(),
+      ramClearCoinOut: z.number().optional(),
+      notes: z.string().optional(),
+    })
+    .passthrough(),
+  responses: {
+    200: {
+      description:
+        'The updated collection; warning is set when only the recalculation failed',
+      schema: dataResponse(collectionSchema).extend({
+        warning: z.string().optional(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description: 'The location is not allowed',
+      schema: errorResponseSchema,
+    },
+    404: { description: 'Collection not found', schema: errorResponseSchema },
+  },
+});
+
+export const deleteCollectionsRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/collection-reports/collections/batch',
+  tag: 'Collections',
+  summary: 'Delete collections',
+  description:
+    "Each machine's later collections are recalculated. Unknown ids are skipped.",
+  body: z.object({
+    ids: z.array(z.string()).min(1),
+    updateCabinetHistory: z
+      .boolean()
+      .default(false)
+      .describe("Also remove them from the machines' collection history"),
+  }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({ success: z.literal(true), deleted: z.number() }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const deleteReportCollectionsRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/collection-reports/collections/delete-by-report',
+  tag: 'Collections',
+  summary: 'Delete a report with its collections',
+  description:
+    "Also deletes the report's manual meters and recalculates each machine's later collections.",
+  body: z.object({
+    locationReportId: z.string().min(1).describe('Not the report _id'),
+  }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: messageResponseSchema.extend({
+        deletedCollections: z.number(),
+        deletedReport: z.number(),
+        updatedMachines: z.number(),
+        verification: z.object({
+          remainingCollections: z.number(),
+          remainingHistoryEntries: z.number(),
+        }),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+// ============================================================================
+// WOW Meters
+// ============================================================================
+
+export const wowMetersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/collection-reports/collections/wow-meters',
+  tag: 'Collections',
+  summary: "A WOW machine's meters for a collection",
+  description:
+    "Current meters come from the latest WOW sync at or before endTime. Previous meters come from the machine's last collection.",
+  query: z.object({
+    machineId: z.string().min(1),
+    ...wowMetersWindow,
+  }),
+  responses: {
+    200: { description: 'Meters', schema: dataResponse(wowMetersSchema) },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const wowMetersBatchRoute = defineRoute({
+  method: 'POST',
+  path: '/api/collection-reports/collections/wow-meters/batch',
+  tag: 'Collections',
+  summary: 'Meters for several WOW machines',
+  description: 'The batch form of the wow-meters GET.',
+  body: z.object({
+    machineIds: z.array(z.string().min(1)).min(1),
+    ...wowMetersWindow,
+  }),
+  responses: {
+    200: {
+      description: 'Meters by machine id',
+      schema: dataResponse(
+        z.record(
+          wowMetersSchema.extend({
+            baselineAt: z
+              .string()
+              .nullable()
+              .describe('Time of prevIn; saved as the SAS start time'),
+          })
+        )
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Meters
+// ============================================================================
+
+const preCreateMetersMachineSchema = z.object({
+  machineId: z.string().min(1),
+  locationId: z.string().min(1),
+  collectionId: z
+    .string()
+    .optional()
+    .describe('Collection to link the meters to'),
+  sessionId: z.string().optional(),
+  metersIn: z.number(),
+  metersOut: z.number(),
+  prevMetersIn: z.number(),
+  prevMetersOut: z.number(),
+  ramClear: z.boolean().optional(),
+  ramClearMetersIn: z.number().optional(),
+  ramClearMetersOut: z.number().optional(),
+  sasEndTime: params.isoDate.optional(),
+});
+
+export const preCreateMetersRoute = defineRoute({
+  method: 'POST',
+  path: '/api/collection-reports/pre-create-meters',
+  tag: 'Collections',
+  summary: 'Write the meters of machines being collected',
+  description:
+    'For machines without a live SMIB, so the report has meters to read. WOW machines and machines with an online SMIB are skipped.',
+  body: z.union([
+    preCreateMetersMachineSchema,
+    z.object({ machines: z.array(preCreateMetersMachineSchema) }),
+  ]),
+  responses: {
+    200: {
+      description: 'Result per machine; success is false when any failed',
+      schema: z.object({
+        success: z.boolean(),
+        results: z.array(
+          z.object({
+            machineId: z.string(),
+            customName: z.string(),
+            success: z.boolean(),
+            created: z.boolean(),
+            skipped: z.boolean(),
+            updated: z.boolean().optional(),
+            error: z.string().optional(),
+          })
+        ),
+        total: z.number().optional(),
+        created: z.boolean().optional(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
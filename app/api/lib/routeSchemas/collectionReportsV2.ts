@@ -0,0 +1,500 @@
+/**
+ * Route Schemas: Collection Reports V2
+ *
+ * `/api/collection-reports-v2/**`: photo-based collection sessions, one
+ * reported machine per captured cabinet, and their meter photos.
+ *
+ * @module app/api/lib/routeSchemas/collectionReportsV2
+ */
+
+import {
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  params,
+  typedObject,
+  uploadedFile,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const reportedMachineSchema = typedObject(
+  'ReportedMachineDocument (models/reportedMachines)'
+);
+
+const REPORTED_MACHINE_STATUSES = [
+  'pending',
+  'captured',
+  'confirmed',
+  'skipped',
+] as const;
+
+const sessionNotFound = {
+  description: 'Session not found',
+  schema: errorResponseSchema,
+};
+
+const captureNotFound = {
+  description: 'Machine capture not found',
+  schema: errorResponseSchema,
+};
+
+const deleteRolesOnly = {
+  description: 'Developer, owner, admin and location admin roles only',
+  schema: errorResponseSchema,
+};
+
+const sessionDetailSchema = typedObject(
+  'SessionDetailResponse (helpers/collectionReportV2)'
+).extend({
+  financials: typedObject(
+    'CollectionSessionV2Document (models/collectionSessionV2)'
+  ).nullable(),
+});
+
+const meterValue = z.number().nullable();
+
+/** Unanswered is sent as null and read as omitted */
+const metersMatch = z
+  .boolean()
+  .nullish()
+  .transform(value => value ?? undefined)
+  .describe('Whether the photo matches the SAS meters; omit until answered');
+
+/** Meter fields shared by a capture and its edits */
+const captureMeters = {
+  sasMetersIn: meterValue,
+  sasMetersOut: meterValue,
+  manualMetersIn: meterValue
+    .optional()
+    .describe('Read from the photo; defaults to the SAS meters on a match'),
+  manualMetersOut: meterValue.optional(),
+  softMetersIn: meterValue
+    .optional()
+    .describe('Not counted by SAS; added to the machine gross'),
+  softMetersOut: meterValue.optional(),
+  metersMatch,
+  sasStartTime: params.isoDate.optional(),
+  sasEndTime: params.isoDate.optional().describe('Default: now'),
+  ramClear: z
+    .boolean()
+    .optional()
+    .describe('Requires ramClearMetersIn and ramClearMetersOut'),
+  ramClearMetersIn: meterValue
+    .optional()
+    .describe('Peak before the reset; at least the previous SAS meter'),
+  ramClearMetersOut: meterValue.optional(),
+  status: z.enum(REPORTED_MACHINE_STATUSES),
+  imageData: z
+    .string()
+    .optional()
+    .describe('data:image/ URL; uploaded to Google Drive on submit'),
+};
+
+// ============================================================================
+// Sessions
+// ============================================================================
+
+export const listCollectionSessionsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/collection-reports-v2/sessions',
+  tag: 'Collection Reports V2',
+  summary: 'List collection sessions',
+  description:
+    'One row per session, totalled over its machines, for the locations the user can access.',
+  query: z.object({
+    licencee: params.licencee.describe('Licencee id or name; all for every'),
+    timePeriod: params.optionalTimePeriod,
+    startDate: params.startDate,
+    endDate: params.endDate,
+    search: params.search,
+    searchType: z
+      .enum(['collector', 'location', 'sessionId', 'locationId'])
+      .default('collector')
+      .describe('Field that search matches'),
+    page: params.page.default(1),
+    limit: params.limit.default(20).describe('Rows per page. At most 100'),
+    sortField: z
+      .enum([
+        'created',
+        'location',
+        'collector',
+        'matched',
+        'machineGross',
+        'sasGross',
+        'variation',
+      ])
+      .default('created'),
+    sortDirection: z.enum(['asc', 'desc']).default('desc'),
+  }),
+  responses: {
+    200: {
+      description: 'A page of sessions',
+      schema: dataResponse(
+        z.array(typedObject('SessionListItem (helpers/collectionReportV2)'))
+      ).extend({
+        pagination: z.object({
+          total: z.number(),
+          page: z.number(),
+          limit: z.number(),
+          totalPages: z.number(),
+        }),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const createCollectionSessionRoute = defineRoute({
+  method: 'POST',
+  path: '/api/collection-reports-v2/sessions',
+  tag: 'Collection Reports V2',
+  summary: 'Start a collection session',
+  description:
+    "Adds every machine at the location to the session as pending, with the caller as collector. The session starts where the location's last session ended.",
+  body: z.object({
+    locationId: z.string().min(1),
+    locationName: z.string().min(1),
+    licencee: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'The new session',
+      schema: dataResponse(
+        z.object({
+          sessionId: z.string(),
+          locationId: z.string(),
+          locationName: z.string(),
+          licencee: z.string(),
+          collector: z.string(),
+          collectorName: z.string(),
+          machinesTotal: z.number(),
+          machines: z.array(
+            typedObject('V2MachineEntry (helpers/collectionReportV2)')
+          ),
+          reportedMachineIds: z.array(z.string()),
+        })
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const getCollectionSessionRoute = defineRoute({
+  method: 'GET',
+  path: '/api/collection-reports-v2/sessions/{sessionId}',
+  tag: 'Collection Reports V2',
+  summary: 'Get a collection session',
+  description:
+    'Amounts are scaled for reviewers, like the rest of the reports.',
+  responses: {
+    200: {
+      description: 'The session with its machines and financials',
+      schema: dataResponse(sessionDetailSchema),
+    },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: sessionNotFound,
+  },
+});
+
+export const updateCollectionSessionRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/collection-reports-v2/sessions/{sessionId}',
+  tag: 'Collection Reports V2',
+  summary: 'Edit a collection session',
+  description:
+    "Times are set on each of the session's machines; the financial fields are saved to the session's financials.",
+  body: z
+    .object({
+      sessionStartTime: params.isoDate.optional(),
+      sessionEndTime: params.isoDate.optional(),
+      amountToCollect: z.number().optional(),
+      amountCollected: z.number().optional(),
+      amountUncollected: z.number().optional(),
+      previousBalance: z.number().optional(),
+      currentBalance: z.number().optional(),
+      partnerProfit: z.number().optional(),
+      taxes: z.number().optional(),
+      advance: z.number().optional(),
+      balanceCorrection: z.number().optional(),
+      balanceCorrectionReas: z.string().optional(),
+      variance: z.union([z.number(), z.string()]).optional(),
+      varianceReason: z.string().optional(),
+      reasonShortagePayment: z.string().optional(),
+      locationProfitPerc: z.number().optional(),
+      includeJackpot: z.boolean().optional(),
+    })
+    .refine(body => Object.keys(body).length > 0, {
+      message: 'No valid fields to update',
+    }),
+  responses: {
+    200: {
+      description: 'Updated',
+      schema: dataResponse(
+        z.object({
+          sessionId: z.string(),
+          machinesUpdated: z.number(),
+          sessionStartTime: z.string().optional(),
+          sessionEndTime: z.string().optional(),
+          financialsUpdated: z.boolean(),
+        })
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: sessionNotFound,
+  },
+});
+
+export const deleteCollectionSessionRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/collection-reports-v2/sessions/{sessionId}',
+  tag: 'Collection Reports V2',
+  summary: 'Delete a collection session',
+  description:
+    "Permanent. Also deletes the session's meters and Drive photos, and reverts its machines' collection meters.",
+  responses: {
+    200: {
+      description: 'Deleted; count is the number of machines removed',
+      schema: dataResponse(
+        z.object({ sessionId: z.string(), count: z.number() })
+      ),
+    },
+    401: errors.unauthorized,
+    403: deleteRolesOnly,
+    404: sessionNotFound,
+  },
+});
+
+export const deleteCollectionSessionsRoute = defineRoute({
+  method: 'POST',
+  path: '/api/collection-reports-v2/sessions/bulk-delete',
+  tag: 'Collection Reports V2',
+  summary: 'Delete collection sessions',
+  description:
+    'The bulk form of the session DELETE. Unknown session ids are skipped.',
+  body: z.object({ sessionIds: z.array(z.string()).min(1) }),
+  responses: {
+    200: {
+      description: 'Deleted; count is the number of machines removed',
+      schema: dataResponse(z.object({ count: z.number() })),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: deleteRolesOnly,
+  },
+});
+
+export const submitCollectionSessionRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/collection-reports-v2/sessions/{sessionId}/submit',
+  tag: 'Collection Reports V2',
+  summary: 'Submit a collection session',
+  description:
+    'Ends the session now, uploads its photos to Google Drive and saves the captured meters to the machines. Server-sent events (text/event-stream): phase messages, then done with the result or error. Refused when a later session for one of its machines is already submitted.',
+  body: z.object({
+    sessionStartTime: params.isoDate.optional().describe('Default: now'),
+    images: z
+      .array(
+        z.object({
+          reportedMachineId: z.string(),
+          imageData: z.string().describe('data:image/ URL'),
+          imageCapturedAt: params.isoDate.optional(),
+        })
+      )
+      .default([])
+      .describe('Photos not sent with the captures'),
+  }),
+  responses: {
+    200: { description: 'Event stream' },
+    400: {
+      description: 'Validation failed, or a later session is submitted',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Machines
+// ============================================================================
+
+export const captureMachineRoute = defineRoute({
+  method: 'POST',
+  path: '/api/collection-reports-v2/machines',
+  tag: 'Collection Reports V2',
+  summary: "Save a machine's capture",
+  description:
+    "Movement is worked out from the previous collection. When the meters do not match, the SAS meters are replaced with the machine's.",
+  body: z.object({
+    sessionId: z.string().min(1),
+    machineId: z.string().min(1),
+    machineName: z.string().optional(),
+    machineCustomName: z.string().optional(),
+    serialNumber: z.string().optional(),
+    manufacturer: z.string().optional(),
+    game: z.string().optional(),
+    locationId: z.string().min(1),
+    locationName: z.string().min(1),
+    licencee: z.string().optional(),
+    collector: z.string().default('').describe('Default: the caller'),
+    collectorName: z.string().optional(),
+    sequenceOrder: z.number().default(0),
+    ...captureMeters,
+  }),
+  responses: {
+    200: {
+      description: 'The saved capture',
+      schema: dataResponse(reportedMachineSchema),
+    },
+    400: {
+      description:
+        'Validation failed, or the RAM clear meters are missing or below the previous meters',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const updateCapturedMachineRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/collection-reports-v2/machines',
+  tag: 'Collection Reports V2',
+  summary: "Edit a machine's capture",
+  description:
+    "Meter changes recalculate the movement. Once the session is submitted, the machine's collection meters and later sessions are updated too.",
+  query: z.object({
+    id: z.string().min(1).describe('Reported machine id'),
+  }),
+  body: z.object({
+    ...captureMeters,
+    status: captureMeters.status.optional(),
+    sasMetersIn: meterValue.optional(),
+    sasMetersOut: meterValue.optional(),
+    imageCapturedAt: params.isoDate.optional(),
+    notes: z.string().optional(),
+    removeImage: z.boolean().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'The updated capture',
+      schema: dataResponse(reportedMachineSchema),
+    },
+    400: {
+      description:
+        'Validation failed, nothing to update, or a later session is submitted',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+    404: captureNotFound,
+  },
+});
+
+export const lastSessionCollectionTimeRoute = defineRoute({
+  method: 'GET',
+  path: '/api/collection-reports-v2/machines/last-collection-time',
+  tag: 'Collection Reports V2',
+  summary: "A machine's last submitted collection",
+  description:
+    "Falls back to the machine's collection time and meters when no session has been submitted for it.",
+  query: z.object({
+    machineId: z.string().min(1),
+    locationId: params.locationId,
+    excludeSessionId: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Collection times and meters',
+      schema: dataResponse(
+        z.object({
+          collectionTime: z.string().nullable(),
+          firstCollectionTime: z.string().nullable(),
+          metersIn: z.number().nullable(),
+          metersOut: z.number().nullable(),
+          hasPreviousCollection: z.boolean(),
+        })
+      ).extend({ message: z.string(), timestamp: z.string() }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const customPeriodMetersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/collection-reports-v2/custom-meters',
+  tag: 'Collection Reports V2',
+  summary: "A machine's SAS meters for a custom period",
+  description: 'The latest meter reading in the period; 0 when there is none.',
+  query: z.object({
+    machineId: z.string().min(1),
+    startDate: params.isoDate,
+    endDate: params.isoDate,
+  }),
+  responses: {
+    200: {
+      description: 'Meters',
+      schema: dataResponse(
+        z.object({ sasMetersIn: z.number(), sasMetersOut: z.number() })
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Photos
+// ============================================================================
+
+export const uploadMeterPhotoRoute = defineRoute({
+  method: 'POST',
+  path: '/api/collection-reports-v2/upload',
+  tag: 'Collection Reports V2',
+  summary: 'Upload a meter photo',
+  description: 'Stored in GridFS. JPEG, PNG, WebP or HEIC, up to 10 MB.',
+  multipart: true,
+  body: z.object({
+    file: uploadedFile,
+    sessionId: z.string().min(1),
+    machineId: z.string().min(1),
+  }),
+  responses: {
+    200: {
+      description: 'Uploaded',
+      schema: dataResponse(
+        z.object({
+          imageFileId: z.string(),
+          imageName: z.string(),
+          imageSize: z.number(),
+        })
+      ),
+    },
+    400: {
+      description:
+        'Validation failed, or the file is empty, not an image or too large',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const getDriveFileRoute = defineRoute({
+  method: 'GET',
+  path: '/api/collection-reports-v2/drive-files/{fileId}',
+  tag: 'Collection Reports V2',
+  summary: 'Download a submitted meter photo',
+  description: 'Proxied from Google Drive and cached by the browser.',
+  responses: {
+    200: { description: 'The file, with its own content type' },
+    401: errors.unauthorized,
+  },
+});
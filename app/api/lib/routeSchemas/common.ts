@@ -0,0 +1,271 @@
+/**
+ * Route Schemas: Common
+ *
+ * Every API route is described by a route schema declared with
+ * `defineRoute()` in the module for its area (`routeSchemas/<area>.ts`):
+ * method, path, summary and the zod schemas of its query, body and
+ * responses. Handlers validate their input with the same schemas through
+ * `parseQuery()` / `parseBody()`, and the OpenAPI document
+ * (helpers/openapi) is generated from every route schema the area modules
+ * export, so the spec cannot drift from what the handlers accept.
+ *
+ * Query strings are validated as an object of their (last) values, so
+ * numeric and boolean params use `z.coerce` / string enums. Multipart
+ * bodies are validated the same way, as an object of their form fields.
+ * Unknown params and body fields are ignored rather than rejected.
+ *
+ * This module also holds the building blocks the area modules share: the
+ * error responses, common query params and response wrappers.
+ *
+ * @module app/api/lib/routeSchemas/common
+ */
+
+import { NextResponse } from 'next/server';
+import { z } from 'zod';
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type ApiMethod = 'GET' | 'POST' | 'PUT' | 'PATCH' | 'DELETE';
+
+export type RouteSchema = {
+  method: ApiMethod;
+  /** OpenAPI path; `{param}` for a `[param]` route segment */
+  path: string;
+  tag: string;
+  summary: string;
+  description?: string;
+  query?: z.AnyZodObject;
+  body?: z.ZodTypeAny;
+  responses: Record<number, { description: string; schema?: z.ZodTypeAny }>;
+  /** Callable without a session */
+  public?: boolean;
+  /** The 200 response can also be `text/csv` (`format=csv`) */
+  csv?: boolean;
+  /** The body is `multipart/form-data` rather than JSON */
+  multipart?: boolean;
+};
+
+type RouteWithQuery = RouteSchema & { query: z.AnyZodObject };
+type RouteWithBody = RouteSchema & { body: z.ZodTypeAny };
+
+// ============================================================================
+// Definition & Validation
+// ============================================================================
+
+const definedRoutes = new WeakSet<object>();
+
+/**
+ * Declares a route schema. Only declared schemas are picked up by the
+ * OpenAPI document.
+ */
+export function defineRoute<T extends RouteSchema>(route: T): T {
+  definedRoutes.add(route);
+  return route;
+}
+
+export function isRouteSchema(value: unknown): value is RouteSchema {
+  return (
+    typeof value === 'object' && value !== null && definedRoutes.has(value)
+  );
+}
+
+/**
+ * Validates a request's query string against its route schema. Empty
+ * params (`?startDate=`) count as absent, as they did when handlers read
+ * them with `searchParams.get(name) || fallback`.
+ */
+export function parseQuery<T extends RouteWithQuery>(
+  route: T,
+  request: Request
+): z.SafeParseReturnType<unknown, z.infer<T['query']>> {
+  const { searchParams } = new URL(request.url);
+  const values = Object.fromEntries(
+    [...searchParams].filter(([, value]) => value !== '')
+  );
+  return route.query.safeParse(values);
+}
+
+/**
+ * Validates a parsed JSON body against its route schema. Multipart bodies
+ * are passed as `Object.fromEntries(await request.formData())`.
+ */
+export function parseBody<T extends RouteWithBody>(
+  route: T,
+  body: unknown
+): z.SafeParseReturnType<unknown, z.infer<T['body']>> {
+  return route.body.safeParse(body);
+}
+
+/**
+ * The 400 response for a failed `parseQuery()` / `parseBody()`.
+ */
+export function validationErrorResponse(error: z.ZodError) {
+  return NextResponse.json(
+    { success: false, error: 'Validation failed', details: error.errors },
+    { status: 400 }
+  );
+}
+
+// ============================================================================
+// Responses
+// ============================================================================
+
+export const errorResponseSchema = z.object({
+  success: z.literal(false),
+  error: z.string(),
+});
+
+export const validationErrorSchema = errorResponseSchema.extend({
+  details: z
+    .array(
+      z.object({
+        path: z.array(z.union([z.string(), z.number()])),
+        message: z.string(),
+      })
+    )
+    .describe('zod validation issues'),
+});
+
+export const errors = {
+  badRequest: {
+    description: 'Invalid params',
+    schema: validationErrorSchema.partial({ details: true }),
+  },
+  validation: {
+    description: 'Validation failed',
+    schema: validationErrorSchema,
+  },
+  unauthorized: {
+    description: 'Not signed in',
+    schema: errorResponseSchema,
+  },
+  forbidden: {
+    description: 'No access to the location',
+    schema: errorResponseSchema,
+  },
+  notFound: { description: 'Not found', schema: errorResponseSchema },
+};
+
+/** `{ success: false, message }`, the error shape of the older routes */
+export const messageErrorSchema = z.object({
+  success: z.literal(false),
+  message: z.string(),
+});
+
+export function messageError(description: string) {
+  return { description, schema: messageErrorSchema };
+}
+
+export const adminOnly = {
+  description: 'Admin and developer roles only',
+  schema: errorResponseSchema,
+};
+
+/**
+ * Response whose `data` is typed by a TypeScript type rather than a zod
+ * schema; the description names the type and where it is declared.
+ */
+export function typedObject(typeName: string) {
+  return z.object({}).passthrough().describe(typeName);
+}
+
+export function dataResponse(data: z.ZodTypeAny) {
+  return z.object({ success: z.literal(true), data });
+}
+
+/** `{ success: true, message }` */
+export const messageResponseSchema = z.object({
+  success: z.literal(true),
+  message: z.string(),
+});
+
+/** A file field of a multipart body */
+export const uploadedFile = z.instanceof(File).describe('File');
+
+export const LIST_DESCRIPTION =
+  'Cursor pagination, sort and filters per Documentation/backend/api/list-conventions.md; results are limited to the locations the user can access.';
+
+// ============================================================================
+// Query Parameters
+// ============================================================================
+
+export const TIME_PERIODS = [
+  'Today',
+  'Yesterday',
+  '7d',
+  '30d',
+  'last7days',
+  'last30days',
+  'Quarterly',
+  'All Time',
+  'LastHour',
+  'Custom',
+] as const;
+
+export const CURRENCIES = ['USD', 'TTD', 'GYD', 'BBD'] as const;
+
+export const params = {
+  licencee: z.string().optional().describe('Licencee id'),
+  requiredLicencee: z.string().describe('Licencee id'),
+  licencees: z.string().optional().describe('Comma-separated licencee ids'),
+  locationId: z.string().optional().describe('Limits results to one location'),
+  requiredLocationId: z.string().describe('Location id'),
+  locationIds: z
+    .string()
+    .optional()
+    .describe('Comma-separated location ids'),
+  timePeriod: (fallback: (typeof TIME_PERIODS)[number]) =>
+    z
+      .enum(TIME_PERIODS)
+      .default(fallback)
+      .describe('Custom uses startDate and endDate'),
+  optionalTimePeriod: z
+    .enum(TIME_PERIODS)
+    .optional()
+    .describe('Custom uses startDate and endDate'),
+  isoDate: z
+    .string()
+    .refine(value => !Number.isNaN(new Date(value).getTime()), {
+      message: 'must be a valid date',
+    }),
+  startDate: z.string().optional().describe('ISO start'),
+  endDate: z.string().optional().describe('ISO end (default: now)'),
+  currency: z
+    .enum(CURRENCIES)
+    .default('USD')
+    .describe('Display currency for converted amounts'),
+  strategy: z
+    .enum(['single', 'fanout'])
+    .optional()
+    .describe(
+      'One pipeline, or one per location (default: AGGREGATION_STRATEGY)'
+    ),
+  format: z
+    .enum(['json', 'csv'])
+    .default('json')
+    .describe('csv returns a download instead of JSON'),
+  gamingDay: z
+    .string()
+    .regex(/^\d{4}-\d{2}-\d{2}$/)
+    .describe('Gaming day, YYYY-MM-DD'),
+  flag: z.enum(['true', 'false']).optional(),
+  page: z.coerce.number().int().min(1).optional().describe('1-based page'),
+  limit: z.coerce.number().int().min(1).optional().describe('Rows per page'),
+  search: z.string().optional().describe('Free-text search'),
+};
+
+/** Location-scoped report filters: licencee, one location and format */
+export const reportScopeQuery = z.object({
+  licencee: params.licencee,
+  locationId: params.locationId,
+  format: params.format,
+});
+
+/** Trend filters: time period, licencee and locations */
+export const trendQuery = z.object({
+  timePeriod: params.timePeriod('Today'),
+  licencee: params.licencee,
+  locationIds: params.locationIds,
+});
@@ -0,0 +1,113 @@
+/**
+ * Route Schemas: Countries
+ *
+ * `/api/countries`: the countries reference list.
+ *
+ * @module app/api/lib/routeSchemas/countries
+ */
+
+import {
+  adminOnly,
+  defineRoute,
+  errors,
+  messageResponseSchema,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const countrySchema = typedObject('CountryDocument (shared/types)');
+
+const countryResponseSchema = z.object({
+  success: z.literal(true),
+  country: countrySchema,
+});
+
+// ============================================================================
+// Countries
+// ============================================================================
+
+export const listCountriesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/countries',
+  tag: 'Countries',
+  summary: 'List countries',
+  description: 'Sorted by name, without deleted countries.',
+  responses: {
+    200: {
+      description: 'Countries',
+      schema: z.object({
+        success: z.literal(true),
+        countries: z.array(countrySchema),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const createCountryRoute = defineRoute({
+  method: 'POST',
+  path: '/api/countries',
+  tag: 'Countries',
+  summary: 'Add a country',
+  description:
+    'The alpha-2 code, uppercased, is the id. Admin and developer roles only.',
+  body: z.object({
+    name: z.string().min(1),
+    alpha2: z.string().min(1).describe('ISO 3166-1 alpha-2 code'),
+    alpha3: z.string().min(1).describe('ISO 3166-1 alpha-3 code'),
+    isoNumeric: z.string().min(1).describe('ISO 3166-1 numeric code'),
+  }),
+  responses: {
+    200: { description: 'Created', schema: countryResponseSchema },
+    400: {
+      description: 'Validation failed, or the code or name is taken',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const updateCountryRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/countries',
+  tag: 'Countries',
+  summary: 'Edit a country',
+  description: 'Admin and developer roles only.',
+  body: z.object({
+    _id: z.string().min(1).describe('Country id (alpha-2 code)'),
+    name: z.string().optional(),
+    alpha2: z.string().optional(),
+    alpha3: z.string().optional(),
+    isoNumeric: z.string().optional(),
+  }),
+  responses: {
+    200: { description: 'Updated', schema: countryResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+    404: errors.notFound,
+  },
+});
+
+export const deleteCountryRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/countries',
+  tag: 'Countries',
+  summary: 'Delete a country',
+  description: 'Soft delete. Admin and developer roles only.',
+  query: z.object({
+    id: z.string().describe('Country id (alpha-2 code)'),
+  }),
+  responses: {
+    200: { description: 'Deleted', schema: messageResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+    404: errors.notFound,
+  },
+});
@@ -0,0 +1,258 @@
+/**
+ * Route Schemas: Dev
+ *
+ * `/api/dev/**`: the developer DB explorer, which browses, edits and
+ * hard-deletes the documents of an allow-listed set of models.
+ *
+ * @module app/api/lib/routeSchemas/dev
+ */
+
+import {
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  params,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const developerOnly = {
+  description: 'Developer role only',
+  schema: errorResponseSchema,
+};
+
+const unknownModel = {
+  description: 'The model is not in the explorer',
+  schema: errorResponseSchema,
+};
+
+const documentSchema = z
+  .record(z.unknown())
+  .describe('Raw document, soft-deleted ones included');
+
+const jsonParam = (description: string) =>
+  z.string().optional().describe(`JSON; ${description}`);
+
+// ============================================================================
+// Models
+// ============================================================================
+
+export const listDevModelsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/dev/collections',
+  tag: 'Dev',
+  summary: 'List the explorable models',
+  responses: {
+    200: {
+      description: 'Models',
+      schema: z.object({
+        success: z.literal(true),
+        models: z.array(
+          z.object({ key: z.string(), label: z.string(), group: z.string() })
+        ),
+      }),
+    },
+    401: errors.unauthorized,
+    403: developerOnly,
+  },
+});
+
+export const getDevModelSchemaRoute = defineRoute({
+  method: 'GET',
+  path: '/api/dev/collections/{model}/schema',
+  tag: 'Dev',
+  summary: "Describe a model's fields",
+  responses: {
+    200: {
+      description: 'Fields and date fields',
+      schema: z.object({
+        success: z.literal(true),
+        fields: z.array(
+          z.object({
+            path: z.string(),
+            kind: z.enum([
+              'string',
+              'number',
+              'boolean',
+              'date',
+              'objectId',
+              'array',
+              'embedded',
+              'mixed',
+            ]),
+            required: z.boolean(),
+            editable: z.boolean(),
+            enumValues: z.array(z.string()).optional(),
+          })
+        ),
+        dateFields: z.array(z.string()),
+        defaultDateField: z.string().nullable(),
+      }),
+    },
+    401: errors.unauthorized,
+    403: developerOnly,
+    404: unknownModel,
+  },
+});
+
+// ============================================================================
+// Documents
+// ============================================================================
+
+export const listDevDocumentsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/dev/collections/{model}',
+  tag: 'Dev',
+  summary: "Browse a model's documents",
+  description:
+    'Batched. rawFilter replaces the date range and filters when sent. export=true downloads every match instead.',
+  csv: true,
+  query: z.object({
+    startDate: params.startDate,
+    endDate: params.endDate,
+    dateField: z
+      .string()
+      .optional()
+      .describe("Date range field. Default: the model's"),
+    machine: z.string().optional().describe('Only documents of this machine'),
+    search: z.string().trim().default(''),
+    searchColumn: z.string().trim().default(''),
+    matchOrdinal: z.coerce
+      .number()
+      .int()
+      .min(0)
+      .default(0)
+      .describe('Which search match to return the batch of'),
+    matchMode: z.enum(['contains', 'exact']).default('contains'),
+    apiPage: z.coerce.number().int().min(1).default(1),
+    filters: jsonParam(
+      'array of { field, op, value } clauses; malformed JSON is ignored'
+    ),
+    filterLogic: z.enum(['and', 'or']).default('and'),
+    sortField: z.string().optional(),
+    sortDir: z.enum(['asc', 'desc']).default('desc'),
+    limit: z.coerce
+      .number()
+      .int()
+      .min(0)
+      .default(0)
+      .describe('0 uses the batch size'),
+    rawFilter: jsonParam('MongoDB filter'),
+    project: jsonParam('MongoDB projection'),
+    sort: jsonParam('MongoDB sort; replaces sortField'),
+    skip: z.coerce.number().int().min(0).default(0),
+    maxTimeMS: z.coerce.number().int().min(0).default(0),
+    export: params.flag,
+    format: z.enum(['csv', 'json']).default('csv').describe('Export format'),
+    columns: z
+      .string()
+      .optional()
+      .describe('Comma-separated CSV export columns'),
+  }),
+  responses: {
+    200: {
+      description: 'A batch of documents',
+      schema: z.object({
+        success: z.literal(true),
+        data: z.array(documentSchema),
+        total: z.number().nullable(),
+        apiPage: z.number(),
+        hasMore: z.boolean(),
+        matchIndex: z.number().describe('-1 without a match'),
+        matchCount: z.number(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: developerOnly,
+    404: unknownModel,
+  },
+});
+
+export const runDevCommandRoute = defineRoute({
+  method: 'POST',
+  path: '/api/dev/collections/{model}',
+  tag: 'Dev',
+  summary: 'Run a read-only shell command',
+  description:
+    'find(), aggregate(), countDocuments() or distinct() on the collection, in shell syntax.',
+  body: z.object({ command: z.string().trim().min(1) }),
+  responses: {
+    200: {
+      description: 'Result documents',
+      schema: z.object({
+        success: z.literal(true),
+        data: z.array(documentSchema),
+        total: z.number(),
+        commandType: z.enum(['find', 'aggregate', 'count', 'distinct']),
+      }),
+    },
+    400: {
+      description: 'Validation failed, or the command is not supported',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+    403: developerOnly,
+    404: unknownModel,
+  },
+});
+
+export const updateDevDocumentsRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/dev/collections/{model}',
+  tag: 'Dev',
+  summary: 'Edit documents',
+  description:
+    'Sets the same fields on each document. Values are coerced to the schema; fields that are not editable are dropped.',
+  body: z
+    .object({
+      id: z.string().min(1).optional(),
+      ids: z.array(z.string()).optional().describe('Used when id is not sent'),
+      set: z.record(z.unknown()),
+    })
+    .refine(body => body.id || body.ids?.length, {
+      message: 'id or ids is required',
+      path: ['ids'],
+    }),
+  responses: {
+    200: {
+      description: 'Updated',
+      schema: z.object({
+        success: z.literal(true),
+        modifiedCount: z.number(),
+      }),
+    },
+    400: {
+      description: 'Validation failed, or no editable fields were sent',
+      schema: errors.badRequest.schema,
+    },
+    401: errors.unauthorized,
+    403: developerOnly,
+    404: unknownModel,
+  },
+});
+
+export const deleteDevDocumentsRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/dev/collections/{model}',
+  tag: 'Dev',
+  summary: 'Hard-delete documents',
+  description: 'Bypasses soft delete.',
+  body: z.object({ ids: z.array(z.string()).min(1) }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({
+        success: z.literal(true),
+        deletedCount: z.number(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: developerOnly,
+    404: unknownModel,
+  },
+});
@@ -0,0 +1,151 @@
+/**
+ * Route Schemas: Feedback
+ *
+ * `/api/feedback/**`: feedback submitted from the app and its review.
+ *
+ * @module app/api/lib/routeSchemas/feedback
+ */
+
+import {
+  deleteFeedbackSchema,
+  feedbackSchema,
+  patchFeedbackSchema,
+  updateFeedbackSchema,
+} from '@/app/api/lib/helpers/feedbackOperations';
+import {
+  adminOnly,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageResponseSchema,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const feedbackEntrySchema = typedObject('FeedbackDocument (shared/types)');
+
+const feedbackUpdatedSchema = messageResponseSchema.extend({
+  feedback: feedbackEntrySchema,
+});
+
+const reviewersOnly = {
+  description: 'Admin, developer and owner roles only',
+  schema: errorResponseSchema,
+};
+
+// ============================================================================
+// Feedback
+// ============================================================================
+
+export const submitFeedbackRoute = defineRoute({
+  method: 'POST',
+  path: '/api/feedback',
+  tag: 'Feedback',
+  summary: 'Submit feedback',
+  description: 'Either email or username is required.',
+  body: feedbackSchema,
+  public: true,
+  responses: {
+    201: {
+      description: 'Received',
+      schema: z.object({
+        success: z.literal(true),
+        message: z.string(),
+        feedbackId: z.string(),
+      }),
+    },
+    400: errors.validation,
+  },
+});
+
+export const listFeedbackRoute = defineRoute({
+  method: 'GET',
+  path: '/api/feedback',
+  tag: 'Feedback',
+  summary: 'List feedback',
+  description:
+    'Newest first. Archived feedback is only listed with status archived.',
+  query: z.object({
+    email: z
+      .string()
+      .optional()
+      .describe('Submitter email (partial match) or feedback id'),
+    category: feedbackSchema.shape.category.optional(),
+    status: z.enum(['pending', 'reviewed', 'resolved', 'archived']).optional(),
+    page: params.page.default(1),
+    limit: params.limit.default(50),
+  }),
+  responses: {
+    200: {
+      description: 'Feedback',
+      schema: z.object({
+        success: z.literal(true),
+        data: z.array(feedbackEntrySchema),
+        pagination: z.object({
+          page: z.number(),
+          limit: z.number(),
+          totalCount: z.number(),
+          totalPages: z.number(),
+        }),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: reviewersOnly,
+  },
+});
+
+export const patchFeedbackRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/feedback',
+  tag: 'Feedback',
+  summary: 'Review feedback',
+  description:
+    'Archive, set the status or add notes; reviewed and resolved record the reviewer. At least one field.',
+  body: patchFeedbackSchema,
+  responses: {
+    200: { description: 'Updated', schema: feedbackUpdatedSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: reviewersOnly,
+    404: errors.notFound,
+  },
+});
+
+export const updateFeedbackRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/feedback',
+  tag: 'Feedback',
+  summary: 'Update feedback',
+  description:
+    'The reviewer is recorded when the status becomes reviewed or resolved, unless given.',
+  body: updateFeedbackSchema,
+  responses: {
+    200: { description: 'Updated', schema: feedbackUpdatedSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: reviewersOnly,
+    404: errors.notFound,
+  },
+});
+
+export const deleteFeedbackRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/feedback',
+  tag: 'Feedback',
+  summary: 'Delete feedback',
+  description: 'Permanent. Admin and developer roles only.',
+  body: deleteFeedbackSchema,
+  responses: {
+    200: { description: 'Deleted', schema: messageResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+    404: errors.notFound,
+  },
+});
@@ -0,0 +1,190 @@
+/**
+ * Route Schemas: Firmwares
+ *
+ * `/api/firmwares/**`: SMIB firmware binaries, stored in GridFS, and the
+ * downloads the over-the-air update flow points SMIBs at.
+ *
+ * @module app/api/lib/routeSchemas/firmwares
+ */
+
+import {
+  adminOnly,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageError,
+  params,
+  typedObject,
+  uploadedFile,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const firmwareSchema = typedObject('FirmwareDocument (shared/types)');
+
+const firmwareNotFound = {
+  description: 'Firmware not found',
+  schema: errorResponseSchema,
+};
+
+const firmwareBinary = {
+  description: 'The firmware binary, as an application/octet-stream download',
+};
+
+// ============================================================================
+// Firmwares
+// ============================================================================
+
+export const listFirmwaresRoute = defineRoute({
+  method: 'GET',
+  path: '/api/firmwares',
+  tag: 'Firmwares',
+  summary: 'List firmwares',
+  description: 'Newest first.',
+  query: z.object({
+    includeDeleted: params.flag.describe('Also list deleted firmwares'),
+  }),
+  responses: {
+    200: { description: 'Firmwares', schema: z.array(firmwareSchema) },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const uploadFirmwareRoute = defineRoute({
+  method: 'POST',
+  path: '/api/firmwares',
+  tag: 'Firmwares',
+  summary: 'Upload a firmware',
+  multipart: true,
+  body: z.object({
+    product: z.string().min(1),
+    version: z.string().min(1),
+    versionDetails: z.string().optional(),
+    file: uploadedFile.refine(file => file.name.endsWith('.bin'), {
+      message: 'must be a .bin file',
+    }),
+  }),
+  responses: {
+    201: { description: 'The new firmware', schema: firmwareSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const getFirmwareFileRoute = defineRoute({
+  method: 'GET',
+  path: '/api/firmwares/{id}',
+  tag: 'Firmwares',
+  summary: 'Download a firmware',
+  responses: {
+    200: firmwareBinary,
+    401: errors.unauthorized,
+    404: firmwareNotFound,
+  },
+});
+
+export const deleteFirmwareRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/firmwares/{id}',
+  tag: 'Firmwares',
+  summary: 'Delete a firmware',
+  description: 'Soft delete; the binary is kept in GridFS.',
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({ message: z.string() }),
+    },
+    401: errors.unauthorized,
+    404: messageError('Firmware not found'),
+  },
+});
+
+export const downloadFirmwareRoute = defineRoute({
+  method: 'GET',
+  path: '/api/firmwares/{id}/download',
+  tag: 'Firmwares',
+  summary: 'Download a firmware',
+  description: 'Used by the firmware list.',
+  responses: {
+    200: firmwareBinary,
+    401: errors.unauthorized,
+    404: firmwareNotFound,
+  },
+});
+
+export const downloadFirmwareVersionRoute = defineRoute({
+  method: 'GET',
+  path: '/api/firmwares/download/{version}',
+  tag: 'Firmwares',
+  summary: 'Download a firmware by version',
+  description: 'Used by SMIBs during an update; the response is not cached.',
+  responses: {
+    200: firmwareBinary,
+    401: errors.unauthorized,
+    404: firmwareNotFound,
+  },
+});
+
+export const serveFirmwareRoute = defineRoute({
+  method: 'GET',
+  path: '/api/firmwares/{id}/serve',
+  tag: 'Firmwares',
+  summary: 'Publish a firmware as a static file',
+  description:
+    'Writes the binary to public/firmwares for SMIBs to fetch; the file is removed after 30 minutes.',
+  responses: {
+    200: {
+      description: 'The static file',
+      schema: z.object({
+        success: z.literal(true),
+        fileName: z.string(),
+        staticUrl: z.string(),
+        size: z.number().describe('Bytes'),
+      }),
+    },
+    401: errors.unauthorized,
+    404: firmwareNotFound,
+  },
+});
+
+// ============================================================================
+// Migration
+// ============================================================================
+
+export const firmwareMigrationStatusRoute = defineRoute({
+  method: 'GET',
+  path: '/api/firmwares/migrate',
+  tag: 'Firmwares',
+  summary: 'Check for firmwares in the old schema',
+  responses: {
+    200: {
+      description: 'Migration status',
+      schema: z.object({
+        needsMigration: z.boolean(),
+        message: z.string(),
+      }),
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const migrateFirmwaresRoute = defineRoute({
+  method: 'POST',
+  path: '/api/firmwares/migrate',
+  tag: 'Firmwares',
+  summary: 'Move firmwares to the current schema',
+  description: 'Does nothing when no firmware needs it.',
+  responses: {
+    200: {
+      description: 'Migrated, or nothing to migrate',
+      schema: z.object({ message: z.string() }),
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
@@ -0,0 +1,33 @@
+/**
+ * Route Schemas
+ *
+ * Central export point for the route schema modules, one namespace per
+ * area. The OpenAPI document (helpers/openapi) documents every route schema
+ * exported here, so a new area module is added to this list.
+ */
+
+export * as activityLogs from './activityLogs';
+export * as admin from './admin';
+export * as analytics from './analytics';
+export * as apiKeys from './apiKeys';
+export * as cabinets from './cabinets';
+export * as collectionReports from './collectionReports';
+export * as collectionReportsV2 from './collectionReportsV2';
+export * as countries from './countries';
+export * as dev from './dev';
+export * as feedback from './feedback';
+export * as firmwares from './firmwares';
+export * as integrityIssues from './integrityIssues';
+export * as licencees from './licencees';
+export * as locations from './locations';
+export * as machines from './machines';
+export * as members from './members';
+export * as metrics from './metrics';
+export * as movementRequests from './movementRequests';
+export * as mqtt from './mqtt';
+export * as reports from './reports';
+export * as schedulers from './schedulers';
+export * as sessions from './sessions';
+export * as smib from './smib';
+export * as users from './users';
+export * as vault from './vault';
@@ -0,0 +1,106 @@
+/**
+ * Route Schemas: Integrity Issues
+ *
+ * `/api/integrity-issues/**`: data integrity issues and their workflow.
+ *
+ * @module app/api/lib/routeSchemas/integrityIssues
+ */
+
+import { integrityIssueUpdateSchema } from '@/app/api/lib/helpers/integrityIssues';
+import {
+  listPageSchema,
+  listQuerySchema,
+} from '@/app/api/lib/helpers/listEndpoint';
+import {
+  integrityIssueListItemSchema,
+  integrityIssueListResource,
+} from '@/app/api/lib/helpers/listResources';
+import {
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  LIST_DESCRIPTION,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const integrityIssueResponseSchema = z.object({
+  success: z.literal(true),
+  data: integrityIssueListItemSchema.extend({
+    statusHistory: z.array(
+      z.object({
+        from: z.string(),
+        to: z.string(),
+        by: z.string(),
+        at: z.date(),
+        note: z.string().nullable(),
+      })
+    ),
+    comments: z.array(
+      z.object({
+        _id: z.string(),
+        author: z.string(),
+        body: z.string(),
+        createdAt: z.date(),
+      })
+    ),
+  }),
+});
+
+// ============================================================================
+// Integrity Issues
+// ============================================================================
+
+export const listIntegrityIssuesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/integrity-issues',
+  tag: 'Integrity',
+  summary: 'List data integrity issues',
+  description: LIST_DESCRIPTION,
+  query: listQuerySchema(integrityIssueListResource),
+  responses: {
+    200: {
+      description: 'One page of issues',
+      schema: listPageSchema(integrityIssueListItemSchema),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const getIntegrityIssueRoute = defineRoute({
+  method: 'GET',
+  path: '/api/integrity-issues/{id}',
+  tag: 'Integrity',
+  summary: 'An integrity issue with its status history and comments',
+  responses: {
+    200: { description: 'The issue', schema: integrityIssueResponseSchema },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
+
+export const updateIntegrityIssueRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/integrity-issues/{id}',
+  tag: 'Integrity',
+  summary: 'Assign, move or comment on an integrity issue',
+  description:
+    'Moves follow open -> investigating -> fixed -> verified (plus confirmed, dismissed and reopening); moving to investigating claims an unassigned issue for the caller.',
+  body: integrityIssueUpdateSchema,
+  responses: {
+    200: { description: 'Updated', schema: integrityIssueResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+    409: {
+      description: 'The move is not allowed from the current status',
+      schema: errorResponseSchema,
+    },
+  },
+});
@@ -0,0 +1,165 @@
+/**
+ * Route Schemas: Licencees
+ *
+ * `/api/licencees`: the licencees the user can access, and their
+ * administration.
+ *
+ * @module app/api/lib/routeSchemas/licencees
+ */
+
+import {
+  defineRoute,
+  errors,
+  messageError,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { METER_MOVEMENT_FIELDS } from '@/app/api/lib/utils/financialFormulas';
+import type { MeterMovementField } from '@shared/types';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const licenceeSchema = typedObject('Licencee (lib/types/common/licencee)');
+
+const licenceeResponseSchema = z.object({
+  success: z.literal(true),
+  licencee: licenceeSchema,
+});
+
+const movementFieldsSchema = z
+  .array(
+    z.enum(
+      METER_MOVEMENT_FIELDS as [MeterMovementField, ...MeterMovementField[]]
+    )
+  )
+  .optional();
+
+/** Empty or null clears the date */
+const clearableDate = z.string().nullable().optional();
+
+const adminOnly = messageError('Admin and developer roles only');
+
+// ============================================================================
+// Licencees
+// ============================================================================
+
+export const listLicenceesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/licencees',
+  tag: 'Licencees',
+  summary: 'List licencees',
+  description: 'Only the licencees the user is assigned, unless unrestricted.',
+  query: z.object({
+    licencee: z
+      .string()
+      .optional()
+      .describe('Licencee id or name; all for no filter'),
+    page: params.page.default(1),
+    limit: params.limit.default(50).describe('Rows per page. At most 100'),
+  }),
+  responses: {
+    200: {
+      description: 'Licencees',
+      schema: z.object({
+        licencees: z.array(licenceeSchema),
+        pagination: z.object({
+          page: z.number(),
+          limit: z.number(),
+          total: z.number(),
+          totalPages: z.number(),
+        }),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const createLicenceeRoute = defineRoute({
+  method: 'POST',
+  path: '/api/licencees',
+  tag: 'Licencees',
+  summary: 'Create a licencee',
+  description:
+    'A licence key is generated; the expiry defaults to 30 days after the start. Admin and developer roles only.',
+  body: z.object({
+    name: z.string().min(1),
+    country: z.string().min(1).describe('Country id'),
+    description: z.string().optional(),
+    startDate: params.isoDate.optional().describe('Default: now'),
+    expiryDate: params.isoDate.optional(),
+    includeJackpot: z.boolean().optional(),
+    gameDayOffset: z.coerce
+      .number()
+      .optional()
+      .describe('Hour the gaming day starts'),
+  }),
+  responses: {
+    201: { description: 'Created', schema: licenceeResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const updateLicenceeRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/licencees',
+  tag: 'Licencees',
+  summary: 'Edit a licencee',
+  description:
+    'Only the fields sent are changed. Admin and developer roles only.',
+  body: z.object({
+    _id: z.string().min(1),
+    name: z.string().optional(),
+    description: z.string().optional(),
+    country: z.string().optional(),
+    startDate: clearableDate,
+    expiryDate: clearableDate,
+    isPaid: z.boolean().optional(),
+    prevStartDate: clearableDate,
+    prevExpiryDate: clearableDate,
+    includeJackpot: z.boolean().optional(),
+    gameDayOffset: z.coerce.number().optional(),
+    financialFormula: z
+      .object({
+        moneyInFields: movementFieldsSchema,
+        moneyOutFields: movementFieldsSchema,
+      })
+      .nullable()
+      .optional()
+      .describe('null resets to the default formula'),
+    machineStatus: z
+      .object({ onlineThresholdMinutes: z.coerce.number().optional() })
+      .nullable()
+      .optional()
+      .describe("null falls back to the deployment's definitions"),
+  }),
+  responses: {
+    200: { description: 'Updated', schema: licenceeResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const deleteLicenceeRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/licencees',
+  tag: 'Licencees',
+  summary: 'Delete a licencee',
+  description: 'Soft delete. Admin and developer roles only.',
+  body: z.object({ _id: z.string().min(1) }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({ success: z.literal(true) }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
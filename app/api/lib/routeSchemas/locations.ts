@@ -0,0 +1,350 @@
+/**
+ * Route Schemas: Locations
+ *
+ * `/api/locations/**`: the location list, search and administration, a
+ * location's cabinets and its profit splits.
+ *
+ * @module app/api/lib/routeSchemas/locations
+ */
+
+import {
+  listPageSchema,
+  listQuerySchema,
+} from '@/app/api/lib/helpers/listEndpoint';
+import {
+  locationListItemSchema,
+  locationListResource,
+} from '@/app/api/lib/helpers/listResources';
+import {
+  adminOnly,
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  LIST_DESCRIPTION,
+  messageError,
+  messageResponseSchema,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const locationSchema = typedObject('LocationDocument (shared/types)');
+
+const locationBodySchema = z
+  .object({
+    name: z.string(),
+    country: z.string().describe('Country id'),
+    address: z.object({
+      street: z.string().optional(),
+      city: z.string().optional(),
+    }),
+    rel: z.object({
+      licencee: z
+        .union([z.string(), z.array(z.string())])
+        .optional()
+        .describe('Licencee id(s)'),
+    }),
+    profitShare: z.number().describe('Percent (default: 50)'),
+    gameDayOffset: z
+      .number()
+      .describe('Hour the gaming day starts (default: 8)'),
+    isLocalServer: z.boolean(),
+    geoCoords: z.object({
+      latitude: z.number().optional(),
+      longitude: z.number().optional(),
+    }),
+    billValidatorOptions: z
+      .record(z.boolean())
+      .describe('Accepted denominations, e.g. denom5'),
+    membershipEnabled: z.boolean(),
+    aceEnabled: z.boolean().describe('Always online'),
+    locationMembershipSettings: typedObject(
+      'LocationMembershipSettings (shared/types)'
+    ),
+    googleMapsLink: z.string(),
+    googleMapsIframe: z.string(),
+    previousCollectionTime: params.isoDate.nullable(),
+    shifts: z
+      .array(
+        z.object({
+          name: z.string(),
+          startHour: z.number(),
+          endHour: z.number(),
+        })
+      )
+      .describe('Used by the shift reports'),
+  })
+  .partial();
+
+const accessDenied = {
+  description: 'Admin and developer roles only',
+  schema: errorResponseSchema,
+};
+
+const profitSplitConfigResponse = dataResponse(
+  typedObject('LocationProfitSplitConfig (helpers/locations/profitSplit)')
+);
+
+// ============================================================================
+// Locations
+// ============================================================================
+
+export const listLocationsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/locations',
+  tag: 'Locations',
+  summary: 'List locations',
+  description: `${LIST_DESCRIPTION} Pass cursor (empty for the first page); without it the endpoint returns the legacy { locations } response.`,
+  query: listQuerySchema(locationListResource),
+  responses: {
+    200: {
+      description: 'One page of locations',
+      schema: listPageSchema(locationListItemSchema),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const searchLocationsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/locations/search-all',
+  tag: 'Locations',
+  summary: 'Search locations with their financials',
+  description:
+    'Every accessible location with machine counts and money in, money out and gross for the period, converted to the display currency.',
+  query: z.object({
+    licencee: params.licencee,
+    search: params.search.describe('Location name or id'),
+    currency: params.currency,
+    timePeriod: params.timePeriod('30d'),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+    machineTypeFilter: z
+      .string()
+      .optional()
+      .describe(
+        'Comma-separated: LocalServersOnly, SMIBLocationsOnly, NoSMIBLocation, FullSMIBs, SemiSMIBs, MembershipOnly, MissingCoordinates, HasCoordinates, WowOnly'
+      ),
+    onlineStatus: z
+      .string()
+      .toLowerCase()
+      .default('all')
+      .describe(
+        'all, online, offline, neveronline, offlinelongest or offlineshortest'
+      ),
+    archived: params.flag.describe('Include deleted locations'),
+    includeDeleted: params.flag.describe('Same as archived'),
+    syncAll: params.flag.describe('Re-classify SMIB status first'),
+  }),
+  responses: {
+    200: {
+      description: 'Locations',
+      schema: z.array(
+        typedObject('LocationResult (helpers/locations/searchOperations)')
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const membershipCountRoute = defineRoute({
+  method: 'GET',
+  path: '/api/locations/membership-count',
+  tag: 'Locations',
+  summary: 'Number of locations with membership enabled',
+  query: z.object({
+    licencee: params.licencee,
+    locationId: params.locationId,
+  }),
+  responses: {
+    200: {
+      description: 'Count',
+      schema: z.object({ membershipCount: z.number() }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const getLocationRoute = defineRoute({
+  method: 'GET',
+  path: '/api/locations/{locationId}',
+  tag: 'Locations',
+  summary: "A location, or its cabinets' metrics for a period",
+  description:
+    'nameOnly returns the name and licencee; basicInfo, or no params, the location; otherwise the cabinet list, which needs timePeriod.',
+  query: z.object({
+    nameOnly: params.flag,
+    basicInfo: params.flag,
+    timePeriod: params.optionalTimePeriod,
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+    licencee: params.licencee,
+    search: params.search,
+    onlineStatus: z
+      .string()
+      .default('all')
+      .describe('all, online, offline or never-online'),
+    smibStatus: z
+      .string()
+      .toLowerCase()
+      .default('all')
+      .describe('all, smib or no-smib'),
+    includeArchived: params.flag.describe('Include deleted machines'),
+    page: params.page.default(1),
+    limit: params.limit.describe('Rows per page (default: all)'),
+  }),
+  responses: {
+    200: {
+      description: 'Location or cabinets',
+      schema: z.union([
+        z.object({ success: z.literal(true), location: locationSchema }),
+        dataResponse(
+          z.array(typedObject('TransformedCabinet (shared/types)'))
+        ).extend({
+          pagination: z.object({
+            page: z.number(),
+            limit: z.number(),
+            total: z.number(),
+            totalPages: z.number(),
+            hasNextPage: z.boolean(),
+            hasPrevPage: z.boolean(),
+          }),
+        }),
+      ]),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: messageError('Not found'),
+  },
+});
+
+export const createLocationRoute = defineRoute({
+  method: 'POST',
+  path: '/api/locations',
+  tag: 'Locations',
+  summary: 'Create a location',
+  description: 'Admin and developer roles only.',
+  body: locationBodySchema.extend({ name: z.string().min(1) }),
+  responses: {
+    201: {
+      description: 'Created',
+      schema: z.object({ success: z.literal(true), location: locationSchema }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: accessDenied,
+  },
+});
+
+export const updateLocationRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/locations',
+  tag: 'Locations',
+  summary: 'Edit a location',
+  description:
+    'Only the fields sent are changed. Admin and developer roles only.',
+  body: locationBodySchema.extend({
+    locationName: z.string().min(1).describe('Location id'),
+  }),
+  responses: {
+    200: {
+      description: 'Updated',
+      schema: messageResponseSchema.extend({ locationId: z.string() }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: accessDenied,
+    404: messageError('Not found'),
+  },
+});
+
+export const deleteLocationRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/locations',
+  tag: 'Locations',
+  summary: 'Delete a location and its machines',
+  description:
+    'Soft delete unless hardDelete. Admins, developers and location admins.',
+  query: z.object({
+    id: z.string().describe('Location id'),
+    hardDelete: params.flag,
+  }),
+  responses: {
+    200: { description: 'Deleted', schema: messageResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description: 'Admins, developers and location admins only',
+      schema: errorResponseSchema,
+    },
+    404: messageError('Not found'),
+  },
+});
+
+export const restoreLocationRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/locations',
+  tag: 'Locations',
+  summary: 'Restore a deleted location and its machines',
+  description: 'Admin and developer roles only.',
+  body: z.object({
+    id: z.string().min(1).describe('Location id'),
+    action: z.literal('restore'),
+  }),
+  responses: {
+    200: { description: 'Restored', schema: messageResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: accessDenied,
+    404: messageError('Not found'),
+  },
+});
+
+// ============================================================================
+// Profit Split
+// ============================================================================
+
+export const getProfitSplitRoute = defineRoute({
+  method: 'GET',
+  path: '/api/locations/{locationId}/profit-split',
+  tag: 'Locations',
+  summary: "A location's profit split history and current split",
+  responses: {
+    200: { description: 'Split', schema: profitSplitConfigResponse },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
+
+export const setProfitSplitRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/locations/{locationId}/profit-split',
+  tag: 'Locations',
+  summary: 'Set the venue share from a gaming day on',
+  description: 'Admin and developer roles only.',
+  body: z.object({
+    venueShare: z.number().min(0).max(100),
+    effectiveFrom: z
+      .string()
+      .optional()
+      .describe('YYYY-MM-DD (default: today)'),
+    venueOwner: z.string().optional().describe('Payee name'),
+    payeeReference: z.string().optional().describe('Supplier reference'),
+  }),
+  responses: {
+    200: { description: 'Updated split', schema: profitSplitConfigResponse },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+    404: errors.notFound,
+  },
+});
@@ -0,0 +1,352 @@
+/**
+ * Route Schemas: Machines
+ *
+ * `/api/machines/**`: the machine list and editor, batch lookup, status
+ * definitions and saved machine views; plus the per-machine accounting and
+ * bill validator reads and the manufacturer list.
+ *
+ * @module app/api/lib/routeSchemas/machines
+ */
+
+import {
+  machineCreateSchema,
+  machineRecordSchema,
+  machineUpdateSchema,
+} from '@/app/api/lib/helpers/cabinets/machineWriteOperations';
+import {
+  listPageSchema,
+  listQuerySchema,
+} from '@/app/api/lib/helpers/listEndpoint';
+import {
+  machineListItemSchema,
+  machineListResource,
+} from '@/app/api/lib/helpers/listResources';
+import { MAX_LOOKUP_SERIALS } from '@/app/api/lib/helpers/machineLookup';
+import {
+  DEFAULT_MACHINE_VIEW_COLUMNS,
+  MACHINE_VIEW_COLUMNS,
+} from '@/app/api/lib/helpers/machineViews';
+import {
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  LIST_DESCRIPTION,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const machineResponseSchema = z.object({
+  success: z.literal(true),
+  data: machineRecordSchema,
+});
+
+const machineViewSchema = z.object({
+  _id: z.string(),
+  userId: z.string(),
+  name: z.string(),
+  description: z.string().optional(),
+  filters: z.object({
+    licencee: z.string().nullable(),
+    locations: z.array(z.string()),
+    statuses: z.array(z.string()),
+  }),
+  columns: z.array(z.string()),
+  sort: z.string(),
+  createdAt: z.date(),
+  updatedAt: z.date(),
+});
+
+// ============================================================================
+// Machines
+// ============================================================================
+
+export const listMachinesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/machines',
+  tag: 'Machines',
+  summary: 'List machines',
+  description: `${LIST_DESCRIPTION} Deleted machines are excluded.`,
+  query: listQuerySchema(machineListResource),
+  responses: {
+    200: {
+      description: 'One page of machines',
+      schema: listPageSchema(machineListItemSchema),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const createMachineRoute = defineRoute({
+  method: 'POST',
+  path: '/api/machines',
+  tag: 'Machines',
+  summary: 'Create a machine',
+  body: machineCreateSchema,
+  responses: {
+    201: { description: 'Created', schema: machineResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: { description: 'Location not found', schema: errorResponseSchema },
+    409: {
+      description: 'Serial number or SMIB id in use',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const getMachineRoute = defineRoute({
+  method: 'GET',
+  path: '/api/machines/{machineId}',
+  tag: 'Machines',
+  summary: "A machine's editable fields and version",
+  responses: {
+    200: { description: 'The machine', schema: machineResponseSchema },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
+
+export const updateMachineRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/machines/{machineId}',
+  tag: 'Machines',
+  summary: 'Update a machine',
+  description:
+    'Send back the version from GET; a stale version fails with 409 and the current version.',
+  body: machineUpdateSchema,
+  responses: {
+    200: { description: 'Updated', schema: machineResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+    409: {
+      description: 'The machine changed since it was read',
+      schema: errorResponseSchema.extend({ currentVersion: z.number() }),
+    },
+  },
+});
+
+export const lookupMachinesRoute = defineRoute({
+  method: 'POST',
+  path: '/api/machines/lookup',
+  tag: 'Machines',
+  summary: 'Look up machines by serial number',
+  description: `Up to ${MAX_LOOKUP_SERIALS} serials; each is reported as found, not found or ambiguous.`,
+  body: z.object({
+    serials: z.array(z.coerce.string()).optional(),
+    text: z
+      .string()
+      .optional()
+      .describe('Pasted list, one per line or comma / space separated'),
+    licencee: params.licencee,
+    format: params.format,
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Lookup result',
+      schema: dataResponse(
+        typedObject('MachineLookupResult (helpers/machineLookup)')
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const machineStatusDefinitionsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/machines/status-definitions',
+  tag: 'Machines',
+  summary: 'Machine status definitions',
+  description: "With licencee, the licencee's override applied.",
+  query: z.object({ licencee: params.licencee }),
+  responses: {
+    200: {
+      description: 'Status definitions',
+      schema: dataResponse(
+        typedObject('MachineStatusDefinitions (shared/types/models)')
+      ),
+    },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const listManufacturersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/manufacturers',
+  tag: 'Machines',
+  summary: 'Manufacturer names in use, sorted',
+  responses: {
+    200: { description: 'Manufacturers', schema: z.array(z.string()) },
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Accounting & Bill Validator
+// ============================================================================
+
+export const accountingDetailsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/accounting-details',
+  tag: 'Machines',
+  summary: "A machine's accounting details and accepted bills",
+  query: z.object({
+    machineId: z.string().min(1),
+    timePeriod: z
+      .enum(['today', 'yesterday', '7d', '30d', 'custom'])
+      .default('today'),
+  }),
+  responses: {
+    200: {
+      description: 'Accounting details',
+      schema: dataResponse(
+        typedObject('getAccountingDetails (helpers/accountingDetails)')
+      ),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const billValidatorRoute = defineRoute({
+  method: 'GET',
+  path: '/api/bill-validator/{machineId}',
+  tag: 'Machines',
+  summary: "A machine's bill validator totals by denomination",
+  description:
+    'Technician-only sessions always get the last hour. startDate and endDate together override timePeriod.',
+  query: z.object({
+    timePeriod: params.timePeriod('7d'),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Bill validator data',
+      schema: dataResponse(
+        typedObject('ProcessedBillData (helpers/billValidator)')
+      ).extend({
+        currentBalance: z.number(),
+        totalBills: z.number(),
+        dataVersion: z.string(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Machine Views
+// ============================================================================
+
+export const listMachineViewsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/machines/views',
+  tag: 'Machine Views',
+  summary: "The caller's saved machine views",
+  responses: {
+    200: {
+      description: 'Views',
+      schema: dataResponse(z.array(machineViewSchema)),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const saveMachineViewRoute = defineRoute({
+  method: 'POST',
+  path: '/api/machines/views',
+  tag: 'Machine Views',
+  summary: 'Save a machine view',
+  body: z.object({
+    name: z
+      .string()
+      .regex(/^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$/)
+      .describe("Replaces the caller's view of that name"),
+    filters: z
+      .object({
+        licencee: z.string().nullable().optional(),
+        locations: z.array(z.string()).optional(),
+        statuses: z
+          .array(z.string())
+          .optional()
+          .describe('online, offline or stored asset statuses'),
+      })
+      .optional(),
+    columns: z
+      .array(z.enum(MACHINE_VIEW_COLUMNS as [string, ...string[]]))
+      .optional()
+      .describe(`Default: ${DEFAULT_MACHINE_VIEW_COLUMNS.join(', ')}`),
+    sort: z
+      .string()
+      .optional()
+      .describe('A machine list sort field; prefix - for descending'),
+    description: z.string().optional(),
+  }),
+  responses: {
+    200: { description: 'Replaced', schema: dataResponse(machineViewSchema) },
+    201: { description: 'Created', schema: dataResponse(machineViewSchema) },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const getMachineViewRoute = defineRoute({
+  method: 'GET',
+  path: '/api/machines/views/{name}',
+  tag: 'Machine Views',
+  summary: 'A saved machine view',
+  responses: {
+    200: { description: 'The view', schema: dataResponse(machineViewSchema) },
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const deleteMachineViewRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/machines/views/{name}',
+  tag: 'Machine Views',
+  summary: 'Delete a saved machine view',
+  responses: {
+    200: { description: 'Deleted', schema: dataResponse(machineViewSchema) },
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const runMachineViewRoute = defineRoute({
+  method: 'GET',
+  path: '/api/machines/views/{name}/run',
+  tag: 'Machine Views',
+  summary: 'Run a saved machine view',
+  description: `Pages like GET /api/machines, limited to the view's columns. ${LIST_DESCRIPTION}`,
+  query: listQuerySchema(machineListResource).pick({
+    limit: true,
+    cursor: true,
+  }),
+  responses: {
+    200: {
+      description: 'One page of machines',
+      schema: listPageSchema(z.record(z.unknown())).extend({
+        view: z.string(),
+        columns: z.array(z.string()),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
@@ -0,0 +1,478 @@
+/**
+ * Route Schemas: Members
+ *
+ * `/api/members/**`: the member register, play sessions and self-exclusions.
+ *
+ * @module app/api/lib/routeSchemas/members
+ */
+
+import {
+  adminOnly,
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageResponseSchema,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const selfExclusionSchema = z.object({
+  _id: z.string(),
+  member: z.string(),
+  gamingLocation: z.string().nullable(),
+  startDate: z.date(),
+  endDate: z.date().nullable().describe('null: indefinite'),
+  reason: z.string(),
+  recordedBy: z.string().nullable(),
+  createdAt: z.date(),
+  updatedAt: z.date(),
+});
+
+const memberSchema = typedObject('CasinoMember (shared/types)');
+
+const nonBlank = z.string().trim().min(1, 'cannot be blank');
+
+const memberNotFound = {
+  description: 'Member not found',
+  schema: errorResponseSchema,
+};
+
+const pageQuery = {
+  page: z.coerce.number().int().min(1).default(1),
+  limit: z.coerce.number().int().min(1).default(10),
+};
+
+const sessionMetrics = z.array(
+  typedObject('Session row; grouped rows add sessionCount and totalDuration')
+);
+
+// ============================================================================
+// Members
+// ============================================================================
+
+export const listMembersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members',
+  tag: 'Members',
+  summary: 'List members with their win/loss',
+  description:
+    "Limited to the caller's locations. Money in, money out and win/loss are summed over each member's sessions and converted to the display currency.",
+  query: z.object({
+    ...pageQuery,
+    search: z
+      .string()
+      .default('')
+      .describe('Matches first name, last name, username or id'),
+    sortBy: z
+      .string()
+      .default('createdAt')
+      .describe('Any member field; name sorts by first name'),
+    sortOrder: z.enum(['asc', 'desc']).default('desc'),
+    startDate: params.isoDate.optional().describe('Created on or after'),
+    endDate: params.isoDate.optional().describe('Created on or before'),
+    winLossFilter: z.enum(['positive', 'negative', 'all']).optional(),
+    locationFilter: z
+      .string()
+      .optional()
+      .describe('Comma-separated location ids, or all'),
+    currency: params.currency,
+    licencee: params.licencee,
+  }),
+  responses: {
+    200: {
+      description: 'One page of members',
+      schema: dataResponse(
+        z.object({
+          members: z.array(memberSchema),
+          pagination: z.object({
+            currentPage: z.number(),
+            totalPages: z.number(),
+            totalMembers: z.number(),
+            hasNextPage: z.boolean(),
+            hasPrevPage: z.boolean(),
+          }),
+        })
+      ).extend({ currency: z.string(), converted: z.boolean() }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const createMemberRoute = defineRoute({
+  method: 'POST',
+  path: '/api/members',
+  tag: 'Members',
+  summary: 'Register a member',
+  body: z.object({
+    username: nonBlank,
+    profile: z
+      .object({ firstName: nonBlank, lastName: nonBlank })
+      .passthrough()
+      .describe('Contact, address and identification'),
+    gamingLocation: z.string().optional().describe('Default: default'),
+  }),
+  responses: {
+    201: { description: 'The new member', schema: memberSchema },
+    400: {
+      description: 'Validation failed, or the username is taken',
+      schema: z.union([errors.validation.schema, errorResponseSchema]),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const getMemberRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/{id}',
+  tag: 'Members',
+  summary: 'Get a member',
+  description: 'Adds the name of the member location.',
+  responses: {
+    200: { description: 'The member', schema: memberSchema },
+    401: errors.unauthorized,
+    404: memberNotFound,
+  },
+});
+
+export const updateMemberRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/members/{id}',
+  tag: 'Members',
+  summary: 'Edit a member',
+  description:
+    'Only the fields sent are changed. Admin and developer roles only.',
+  body: z.object({
+    username: nonBlank.optional(),
+    profile: z
+      .object({
+        firstName: nonBlank.optional(),
+        lastName: nonBlank.optional(),
+        email: z.string().optional(),
+        occupation: z.string().optional(),
+        address: z.string().optional(),
+        gender: z.string().optional(),
+        dob: z.string().optional(),
+      })
+      .optional(),
+    phoneNumber: z.string().optional(),
+    points: z.number().optional(),
+    uaccount: z.number().optional(),
+    gamingLocation: nonBlank.optional(),
+  }),
+  responses: {
+    200: { description: 'The updated member', schema: memberSchema },
+    400: {
+      description: 'Validation failed, or the username or email is taken',
+      schema: z.union([errors.validation.schema, errorResponseSchema]),
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+    404: memberNotFound,
+  },
+});
+
+export const deleteMemberRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/members/{id}',
+  tag: 'Members',
+  summary: 'Delete a member',
+  description: 'Soft delete. Admin, owner and developer roles only.',
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({ message: z.string() }),
+    },
+    401: errors.unauthorized,
+    403: {
+      description: 'Admin, owner and developer roles only',
+      schema: errorResponseSchema,
+    },
+    404: memberNotFound,
+  },
+});
+
+export const checkMemberUniqueRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/check-unique',
+  tag: 'Members',
+  summary: 'Check a username and email are free',
+  description: 'Either value may be omitted; it is then reported available.',
+  query: z.object({
+    username: z.string().optional(),
+    email: z.string().optional(),
+    excludeId: z.string().optional().describe('Member being edited'),
+  }),
+  responses: {
+    200: {
+      description: 'Availability',
+      schema: z.object({
+        usernameAvailable: z.boolean(),
+        emailAvailable: z.boolean(),
+        username: z.string().nullable(),
+        email: z.string().nullable(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const membersSummaryRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/summary',
+  tag: 'Members',
+  summary: 'Members report with totals',
+  description:
+    'dateFilter limits the sessions counted, not the members listed. Members are not filtered by licencee.',
+  query: z.object({
+    ...pageQuery,
+    dateFilter: z
+      .enum(['all', 'yesterday', 'week', 'month', 'custom'])
+      .default('all')
+      .describe('custom uses startDate and endDate'),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+    search: z
+      .string()
+      .default('')
+      .describe('Matches name, phone number or username'),
+    location: z
+      .string()
+      .optional()
+      .describe('Comma-separated location ids, or all'),
+    licencee: params.licencee.describe('Accepted but not applied'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of members and the totals',
+      schema: dataResponse(
+        z.object({
+          members: z.array(typedObject('Member summary row')),
+          summary: z.object({
+            totalMembers: z.number(),
+            totalLocations: z.number(),
+            activeMembers: z.number().describe('Logged in within 30 days'),
+            recentMembers: z
+              .number()
+              .describe('Joined within 7 days, on this page'),
+          }),
+          pagination: z.object({
+            page: z.number(),
+            limit: z.number(),
+            total: z.number(),
+            totalPages: z.number(),
+            hasNext: z.boolean(),
+            hasPrev: z.boolean(),
+          }),
+        })
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const membersDebugRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/debug',
+  tag: 'Members',
+  summary: 'Member counts by deletedAt, with samples',
+  responses: {
+    200: {
+      description: 'Counts and five sample members',
+      schema: dataResponse(
+        z.object({
+          counts: z.object({
+            totalMembers: z.number(),
+            membersWithDeletedAt: z.number(),
+            membersWithoutDeletedAt: z.number(),
+            membersWithNullDeletedAt: z.number(),
+          }),
+          sampleMembers: z.array(memberSchema),
+        })
+      ),
+    },
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+export const sendMemberVerificationSmsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/send-verification-sms',
+  tag: 'Members',
+  summary: 'Text a verification code to a member',
+  description:
+    'Sends a new code through the SMS gateway and stores it on the member. Send failures return 400, or 500 when the gateway is not configured.',
+  query: z.object({
+    memberId: z.string().min(1),
+    phoneNumber: z
+      .string()
+      .regex(/^\+?[1-9]\d{1,14}$/, 'must be an E.164 phone number'),
+  }),
+  responses: {
+    200: {
+      description: 'Sent',
+      schema: messageResponseSchema.extend({
+        data: z.object({
+          messageId: z.string(),
+          phone: z.string(),
+          status: z.string(),
+          statusDescription: z.string(),
+        }),
+      }),
+    },
+    400: {
+      description: 'Validation failed, or the gateway rejected the message',
+      schema: z.union([errors.validation.schema, errorResponseSchema]),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Member Sessions
+// ============================================================================
+
+export const listMemberSessionsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/{id}/sessions',
+  tag: 'Members',
+  summary: "A member's sessions, one per row or grouped",
+  description:
+    'startDate and endDate together override timePeriod; endDate is inclusive. Grouped views return every group on one page.',
+  query: z.object({
+    ...pageQuery,
+    filter: z
+      .enum(['session', 'day', 'week', 'month'])
+      .default('session')
+      .describe('session lists sessions; the others sum them per period'),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+    timePeriod: z
+      .string()
+      .optional()
+      .describe('today, yesterday, 7d or 30d; anything else is all time'),
+    currency: params.currency,
+    licencee: params.licencee.describe('Enables currency conversion'),
+  }),
+  responses: {
+    200: {
+      description: 'Sessions',
+      schema: dataResponse(
+        z.object({
+          sessions: sessionMetrics,
+          pagination: z.object({
+            currentPage: z.number(),
+            totalPages: z.number(),
+            totalSessions: z.number(),
+            hasNextPage: z.boolean(),
+            hasPrevPage: z.boolean(),
+          }),
+        })
+      ).extend({ currency: z.string(), converted: z.boolean() }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const listMemberMachineEventsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/{id}/sessions/{machineId}/events',
+  tag: 'Members',
+  summary: "A machine's events, with the filter options",
+  description:
+    'Events are read for the whole machine; the member id is not applied.',
+  query: z.object({
+    ...pageQuery,
+    eventType: z.string().optional().describe('Exact match'),
+    event: z.string().optional().describe('Partial match on description'),
+    game: z.string().optional().describe('Partial match on game name'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of events and the filter options',
+      schema: dataResponse(
+        z.object({
+          events: z.array(typedObject('MachineEvent with sasEvent')),
+          pagination: z.object({
+            currentPage: z.number(),
+            totalPages: z.number(),
+            totalEvents: z.number(),
+            hasNextPage: z.boolean(),
+            hasPrevPage: z.boolean(),
+          }),
+          filters: z.object({
+            eventTypes: z.array(z.string()),
+            events: z.array(z.string()),
+            games: z.array(z.string()),
+          }),
+        })
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Self-Exclusions
+// ============================================================================
+
+export const listSelfExclusionsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/members/self-exclusions',
+  tag: 'Members',
+  summary: 'Member self-exclusions',
+  query: z.object({
+    licencee: params.licencee,
+    locationId: params.locationId,
+    active: params.flag.describe('Only exclusions in force now'),
+  }),
+  responses: {
+    200: {
+      description: 'Exclusions',
+      schema: dataResponse(
+        z.array(
+          typedObject('SelfExclusionEntry (helpers/members/selfExclusion)')
+        )
+      ),
+    },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const addSelfExclusionRoute = defineRoute({
+  method: 'POST',
+  path: '/api/members/self-exclusions',
+  tag: 'Members',
+  summary: 'Record a self-exclusion',
+  body: z.object({
+    memberId: z.string().min(1),
+    startDate: params.isoDate.optional().describe('Default: now'),
+    endDate: params.isoDate
+      .nullable()
+      .optional()
+      .describe('Omit for an indefinite exclusion'),
+    reason: z.string().optional(),
+  }),
+  responses: {
+    201: {
+      description: 'Recorded',
+      schema: dataResponse(selfExclusionSchema),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
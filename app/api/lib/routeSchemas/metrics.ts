@@ -0,0 +1,159 @@
+/**
+ * Route Schemas: Metrics
+ *
+ * `/api/metrics/**`: health of the pre-aggregated user metrics and the
+ * top machine, performer and location rankings.
+ *
+ * @module app/api/lib/routeSchemas/metrics
+ */
+
+import {
+  DEFAULT_FRESHNESS_OPTIONS,
+  MAX_FRESHNESS_SAMPLE_SIZE,
+} from '@/app/api/lib/helpers/users/metricsFreshness';
+import {
+  adminOnly,
+  CURRENCIES,
+  dataResponse,
+  defineRoute,
+  errors,
+  params,
+  TIME_PERIODS,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Pre-aggregated Metrics
+// ============================================================================
+
+const freshnessReportSchema = typedObject(
+  'MetricsFreshnessReport (helpers/users/metricsFreshness)'
+);
+
+export const preaggregateStatusRoute = defineRoute({
+  method: 'GET',
+  path: '/api/metrics/preaggregate-status',
+  tag: 'Metrics',
+  summary: 'Freshness and drift of the pre-aggregated user metrics',
+  description: 'Admin and developer roles only. 503 when unhealthy.',
+  query: z.object({
+    maxAgeMinutes: z.coerce
+      .number()
+      .min(0)
+      .default(DEFAULT_FRESHNESS_OPTIONS.maxAgeMinutes),
+    sampleSize: z.coerce
+      .number()
+      .int()
+      .min(0)
+      .default(DEFAULT_FRESHNESS_OPTIONS.sampleSize)
+      .describe(
+        `Users recomputed for drift, at most ${MAX_FRESHNESS_SAMPLE_SIZE}`
+      ),
+    maxStalePercent: z.coerce
+      .number()
+      .min(0)
+      .max(100)
+      .default(DEFAULT_FRESHNESS_OPTIONS.maxStalePercent),
+    maxDriftPercent: z.coerce
+      .number()
+      .min(0)
+      .max(100)
+      .default(DEFAULT_FRESHNESS_OPTIONS.maxDriftPercent),
+  }),
+  responses: {
+    200: { description: 'Healthy', schema: freshnessReportSchema },
+    401: errors.unauthorized,
+    403: adminOnly,
+    503: { description: 'Stale or drifting', schema: freshnessReportSchema },
+  },
+});
+
+// ============================================================================
+// Rankings
+// ============================================================================
+
+export const topMachinesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/metrics/top-machines',
+  tag: 'Metrics',
+  summary: 'Top machines by drop, with their meters',
+  query: z.object({
+    timePeriod: params.timePeriod('Today'),
+    licencee: params.licencee,
+    locationIds: params.locationIds,
+    limit: z.coerce.number().int().min(1).default(5),
+  }),
+  responses: {
+    200: {
+      description: 'Top machines and the filters applied',
+      schema: dataResponse(
+        z.array(typedObject('getTopMachinesDetailed (helpers/reports)'))
+      ).extend({
+        timePeriod: z.enum(TIME_PERIODS),
+        locationIds: z.array(z.string()).nullable(),
+        limit: z.number(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const topPerformerRoute = defineRoute({
+  method: 'GET',
+  path: '/api/metrics/top-performers',
+  tag: 'Metrics',
+  summary: "A location's top machine by drop",
+  query: z.object({
+    locationId: params.requiredLocationId,
+    timePeriod: params.timePeriod('Today'),
+    licencee: params.licencee,
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+  }),
+  responses: {
+    200: {
+      description: 'The top performer; null when the location had no play',
+      schema: z.object({
+        locationId: z.string(),
+        timePeriod: z.enum(TIME_PERIODS),
+        topPerformer: z.record(z.unknown()).nullable(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const topPerformingRoute = defineRoute({
+  method: 'GET',
+  path: '/api/metrics/top-performing',
+  tag: 'Metrics',
+  summary: 'Top locations or cabinets by drop',
+  description:
+    'Amounts are converted to currency only for admins viewing all licencees.',
+  query: z.object({
+    activeTab: z.enum(['locations', 'Cabinets']).default('locations'),
+    timePeriod: params.timePeriod('7d'),
+    licencee: z
+      .string()
+      .optional()
+      .describe('Licencee id or name; all for every licencee'),
+    currency: params.currency,
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Ranked items',
+      schema: z.object({
+        activeTab: z.enum(['locations', 'Cabinets']),
+        timePeriod: z.enum(TIME_PERIODS),
+        data: z.array(
+          typedObject('TopPerformingItem (helpers/currency/topPerforming)')
+        ),
+        currency: z.enum(CURRENCIES),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
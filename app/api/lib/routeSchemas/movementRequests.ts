@@ -0,0 +1,145 @@
+/**
+ * Route Schemas: Movement Requests
+ *
+ * `/api/movement-requests/**`: requests to move machines between locations.
+ *
+ * @module app/api/lib/routeSchemas/movementRequests
+ */
+
+import {
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageError,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const MOVEMENT_REQUEST_STATUSES = ['pending', 'completed'] as const;
+
+const movementRequestSchema = typedObject(
+  'MovementRequest (shared/types/movement)'
+);
+
+const movementRequestFields = {
+  locationName: z.string(),
+  locationFrom: z.string().describe('Source location name'),
+  locationTo: z.string().describe('Destination location name'),
+  locationId: z.string().describe('Source location id'),
+  locationFromId: z.string().optional(),
+  locationToId: z.string().optional(),
+  movementType: z.string().describe('machine or smib'),
+  installationType: z.string(),
+  reason: z.string().optional(),
+  requestTo: z.string().describe('Recipient user id'),
+  selectedMachines: z.array(z.string()).optional(),
+  cabinetIn: z
+    .string()
+    .optional()
+    .describe('Comma-separated serial or asset numbers'),
+};
+
+// ============================================================================
+// Movement Requests
+// ============================================================================
+
+export const listMovementRequestsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/movement-requests',
+  tag: 'Movement Requests',
+  summary: 'List movement requests',
+  description:
+    "Newest first, with location, creator, recipient and machine names. Limited to the user's locations and the requests they created or received.",
+  query: z.object({
+    licencee: z.string().optional().describe('Licencee id; all for no filter'),
+  }),
+  responses: {
+    200: { description: 'Requests', schema: z.array(movementRequestSchema) },
+    401: errors.unauthorized,
+  },
+});
+
+export const createMovementRequestRoute = defineRoute({
+  method: 'POST',
+  path: '/api/movement-requests',
+  tag: 'Movement Requests',
+  summary: 'Request a machine move',
+  description:
+    'The creator and timestamp are set from the session. Technicians, managers, location admins and developers.',
+  body: z.object({
+    _id: z.string().min(1),
+    ...movementRequestFields,
+    variance: z.number(),
+    previousBalance: z.number(),
+    currentBalance: z.number(),
+    amountToCollect: z.number(),
+    amountCollected: z.number(),
+    amountUncollected: z.number(),
+    partnerProfit: z.number(),
+    taxes: z.number(),
+    advance: z.number(),
+    status: z.enum(MOVEMENT_REQUEST_STATUSES).default('pending'),
+  }),
+  responses: {
+    201: { description: 'Created', schema: movementRequestSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description: 'Role cannot request moves',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const updateMovementRequestRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/movement-requests/{id}',
+  tag: 'Movement Requests',
+  summary: 'Edit a movement request',
+  description:
+    'Admins, developers, the creator, the recipient, and location admins, technicians and managers of the destination. Only the fields sent are changed.',
+  body: z
+    .object(movementRequestFields)
+    .partial()
+    .extend({
+      status: z.enum(MOVEMENT_REQUEST_STATUSES).optional(),
+      approvedBy: z.string().optional(),
+      approvedBySecond: z.string().optional(),
+    }),
+  responses: {
+    200: {
+      description: 'Updated; null when the request was deleted',
+      schema: movementRequestSchema.nullable(),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: messageError('Not allowed to edit this request'),
+    404: messageError('Not found'),
+  },
+});
+
+export const deleteMovementRequestRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/movement-requests/{id}',
+  tag: 'Movement Requests',
+  summary: 'Delete a movement request',
+  description:
+    'Admins, developers and the creator; a hard delete is for developers only.',
+  query: z.object({
+    deleteType: z.enum(['soft', 'hard']).default('soft'),
+  }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({ success: z.literal(true) }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: messageError('Not allowed to delete this request'),
+    404: messageError('Not found'),
+  },
+});
@@ -0,0 +1,180 @@
+/**
+ * Route Schemas: MQTT
+ *
+ * `/api/mqtt/**`: reading and pushing SMIB configuration over MQTT, the
+ * live config stream and SMIB discovery.
+ *
+ * @module app/api/lib/routeSchemas/mqtt
+ */
+
+import {
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageResponseSchema,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const SMIB_CONFIG_COMPONENTS = ['mqtt', 'ota', 'coms', 'net', 'app'] as const;
+
+const relayIdSchema = z.string().min(1).describe('SMIB relay id');
+
+const usageSchema = z.object({
+  success: z.literal(true),
+  message: z.string(),
+  usage: z.record(z.unknown()),
+  example: z.record(z.unknown()),
+});
+
+// ============================================================================
+// Configuration
+// ============================================================================
+
+export const getMqttConfigRoute = defineRoute({
+  method: 'GET',
+  path: '/api/mqtt/config',
+  tag: 'SMIB',
+  summary: "A cabinet's stored SMIB configuration",
+  query: z.object({ cabinetId: z.string().min(1) }),
+  responses: {
+    200: {
+      description: 'Configuration, formatted for display',
+      schema: dataResponse(typedObject('MQTTConfig (helpers/mqtt)')),
+    },
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const publishMqttConfigRoute = defineRoute({
+  method: 'POST',
+  path: '/api/mqtt/config/publish',
+  tag: 'SMIB',
+  summary: 'Publish a config update to a SMIB',
+  body: z.object({
+    relayId: relayIdSchema,
+    config: z
+      .object({ typ: z.string().min(1), comp: z.string().min(1) })
+      .passthrough()
+      .describe('typ cfg, comp the component, then its settings'),
+  }),
+  responses: {
+    200: {
+      description: 'Published',
+      schema: messageResponseSchema.extend({
+        relayId: z.string(),
+        config: z.record(z.unknown()),
+        timestamp: z.string(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const publishMqttConfigUsageRoute = defineRoute({
+  method: 'GET',
+  path: '/api/mqtt/config/publish',
+  tag: 'SMIB',
+  summary: 'Usage and example configs for the publish endpoint',
+  responses: {
+    200: { description: 'Usage', schema: usageSchema },
+    401: errors.unauthorized,
+  },
+});
+
+export const requestMqttConfigRoute = defineRoute({
+  method: 'POST',
+  path: '/api/mqtt/config/request',
+  tag: 'SMIB',
+  summary: 'Ask a SMIB to report one config component',
+  description: 'The reply arrives on GET /api/mqtt/config/subscribe.',
+  body: z.object({
+    relayId: relayIdSchema,
+    component: z.enum(SMIB_CONFIG_COMPONENTS),
+  }),
+  responses: {
+    200: {
+      description: 'Request sent',
+      schema: messageResponseSchema.extend({
+        relayId: z.string(),
+        component: z.enum(SMIB_CONFIG_COMPONENTS),
+        timestamp: z.string(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const requestMqttConfigUsageRoute = defineRoute({
+  method: 'GET',
+  path: '/api/mqtt/config/request',
+  tag: 'SMIB',
+  summary: 'Usage for the config request endpoint',
+  responses: {
+    200: { description: 'Usage', schema: usageSchema },
+    401: errors.unauthorized,
+  },
+});
+
+export const subscribeMqttConfigRoute = defineRoute({
+  method: 'GET',
+  path: '/api/mqtt/config/subscribe',
+  tag: 'SMIB',
+  summary: "Stream a SMIB's config replies",
+  description:
+    'Server-sent events (text/event-stream); each data line is a JSON message.',
+  query: z.object({ relayId: relayIdSchema }),
+  responses: {
+    200: { description: 'Event stream' },
+    401: errors.unauthorized,
+  },
+});
+
+export const updateMachineConfigRoute = defineRoute({
+  method: 'POST',
+  path: '/api/mqtt/update-machine-config',
+  tag: 'SMIB',
+  summary: "Save a SMIB's reported configuration on its machine",
+  body: z.object({
+    relayId: relayIdSchema,
+    smibConfig: z.record(z.unknown()).optional(),
+    smibVersion: z.record(z.unknown()).optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Updated',
+      schema: dataResponse(typedObject('GamingMachine (shared/types)')).extend({
+        machineId: z.string(),
+      }),
+    },
+    401: errors.unauthorized,
+    404: {
+      description: 'No machine has that relay id',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+// ============================================================================
+// Discovery
+// ============================================================================
+
+export const discoverSmibsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/mqtt/discover-smibs',
+  tag: 'SMIB',
+  summary: 'Machines with a SMIB, and whether each is online',
+  responses: {
+    200: {
+      description: 'SMIBs',
+      schema: typedObject('SMIBDiscoveryResult (helpers/smibDiscovery)'),
+    },
+    401: errors.unauthorized,
+  },
+});
@@ -0,0 +1,934 @@
+/**
+ * Route Schemas: Reports
+ *
+ * `/api/reports/**`: the financial, compliance and operations reports, the
+ * query builder and saved report templates.
+ *
+ * @module app/api/lib/routeSchemas/reports
+ */
+
+import { DEFAULT_CASH_DESK_THRESHOLD } from '@/app/api/lib/helpers/cashDesk';
+import { DEFAULT_LATE_HOURS } from '@/app/api/lib/helpers/reports/collectionCompliance';
+import {
+  DEFAULT_CONVERSION_WINDOW_DAYS,
+  MAX_CONVERSION_WINDOW_DAYS,
+} from '@/app/api/lib/helpers/reports/gameConversion';
+import {
+  DEFAULT_IDLE_LOOKBACK_DAYS,
+  IDLE_BUCKET_HOURS,
+  type IdleBucket,
+} from '@/app/api/lib/helpers/reports/idleMachines';
+import { DEFAULT_UNDERUTILIZED_RATIO } from '@/app/api/lib/helpers/reports/machineUtilization';
+import {
+  MAX_QUERY_BUILDER_LIMIT,
+  QUERY_BUILDER_CATALOG,
+  type QueryBuilderEntity,
+} from '@/app/api/lib/helpers/reports/queryBuilder';
+import { REPORT_TEMPLATE_TYPES } from '@/app/api/lib/helpers/reports/reportTemplates';
+import { DEFAULT_SAS_ALERT_HOURS } from '@/app/api/lib/helpers/reports/sasAlerts';
+import {
+  DEFAULT_ATTRIBUTION_DAYS,
+  DEFAULT_THEORETICAL_RTP,
+} from '@/app/api/lib/helpers/reports/sessionAttribution';
+import { DEFAULT_TITO_THRESHOLD } from '@/app/api/lib/helpers/reports/titoReconciliation';
+import {
+  DEFAULT_TOP_MEMBERS_DAYS,
+  DEFAULT_TOP_MEMBERS_LIMIT,
+} from '@/app/api/lib/helpers/reports/topMembers';
+import { DEFAULT_UNCOLLECTED_MAX_DAYS } from '@/app/api/lib/helpers/reports/uncollectedDrop';
+import {
+  CURRENCIES,
+  dataResponse,
+  defineRoute,
+  errors,
+  params,
+  reportScopeQuery,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  SAS_SEVERITIES,
+  type SasSeverity,
+} from '@/lib/utils/sas/exceptionCodes';
+import type { ReportTemplateType } from '@shared/types';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const querySpecSchema = z.object({
+  entity: z.enum(
+    Object.keys(QUERY_BUILDER_CATALOG) as [
+      QueryBuilderEntity,
+      ...QueryBuilderEntity[],
+    ]
+  ),
+  filters: z
+    .object({
+      licencee: z.string().optional(),
+      status: z.string().optional(),
+      startDate: z.string().optional(),
+      endDate: z.string().optional(),
+    })
+    .optional(),
+  groupBy: z.array(z.string()).optional(),
+  metrics: z.array(z.string()).optional(),
+  limit: z
+    .number()
+    .optional()
+    .describe(`Rows returned, 1-${MAX_QUERY_BUILDER_LIMIT} (default: 100)`),
+});
+
+const reportTemplateTypeSchema = z.enum(
+  REPORT_TEMPLATE_TYPES as [ReportTemplateType, ...ReportTemplateType[]]
+);
+
+const reportTemplateSchema = z.object({
+  _id: z.string(),
+  name: z.string(),
+  description: z.string().optional(),
+  reportType: reportTemplateTypeSchema,
+  parameters: z.record(z.unknown()),
+  outputFormat: z.enum(['json', 'csv']),
+  createdBy: z.string().nullable().optional(),
+  lastRunAt: z.date().nullable().optional(),
+  createdAt: z.date(),
+  updatedAt: z.date(),
+});
+
+const reportTemplateSaveSchema = z.object({
+  name: z.string().regex(/^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$/),
+  reportType: reportTemplateTypeSchema,
+  parameters: z
+    .record(z.unknown())
+    .describe('Report parameters; for query-builder, the query spec'),
+  outputFormat: z.enum(['json', 'csv']).default('json'),
+  description: z.string().optional(),
+});
+
+// ============================================================================
+// Reports
+// ============================================================================
+
+export const locationsReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/locations',
+  tag: 'Reports',
+  summary: 'Location financials',
+  description:
+    'Per-location money in / out and gross, SMIB classification, machine counts and online status. summary=true returns totals only.',
+  query: z.object({
+    timePeriod: params.timePeriod('7d'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    licencee: params.licencee,
+    currency: params.currency,
+    search: z.string().optional(),
+    machineTypeFilter: z.string().optional(),
+    onlineStatus: z.string().default('all'),
+    locations: params.locationIds,
+    showAllLocations: z.enum(['true', 'false']).optional(),
+    archived: z.enum(['true', 'false']).optional(),
+    summary: z.enum(['true', 'false']).optional(),
+    page: z.coerce.number().int().min(1).default(1),
+    limit: z.coerce
+      .number()
+      .int()
+      .min(1)
+      .default(50)
+      .describe('At most 100'),
+    sortBy: z.string().default('gross'),
+    sortOrder: z.enum(['asc', 'desc']).default('desc'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of locations',
+      schema: z.object({
+        data: z.array(z.record(z.unknown())),
+        pagination: z.record(z.unknown()),
+        currency: z.enum(CURRENCIES),
+        converted: z.boolean(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const machinesReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/machines',
+  tag: 'Reports',
+  summary: 'Machine financials',
+  description:
+    'type selects the view (machine list, stats or offline machines) over the period.',
+  query: z.object({
+    type: z.string().optional(),
+    timePeriod: params.timePeriod('Today'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    licencee: params.licencee,
+    locationId: params.locationId,
+    onlineStatus: z.string().default('all'),
+    search: z.string().optional(),
+    smibId: z.string().optional(),
+    customName: z.string().optional(),
+    currency: params.currency,
+    page: z.coerce.number().int().min(1).default(1),
+    limit: z.coerce
+      .number()
+      .int()
+      .min(1)
+      .default(10)
+      .describe('At most 500'),
+  }),
+  responses: {
+    200: {
+      description: 'Machines and pagination, or stats',
+      schema: typedObject(
+        'getAllMachines / getMachineStats (helpers/reports/machines)'
+      ),
+    },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const metersReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/meters',
+  tag: 'Reports',
+  summary: 'Last meter reading per machine',
+  query: z.object({
+    locations: z.string().describe('Comma-separated location ids, or all'),
+    timePeriod: params.timePeriod('Today'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    licencee: params.licencee,
+    search: z.string().optional(),
+    includeHourlyData: z.enum(['true', 'false']).optional(),
+    hourlyDataMachineIds: z
+      .string()
+      .optional()
+      .describe('Comma-separated machine ids the hourly data is limited to'),
+    granularity: z
+      .string()
+      .optional()
+      .describe('minute or hourly; other values pick from the period'),
+    currency: params.currency,
+    page: z.coerce.number().int().min(1).default(1),
+    limit: z.coerce.number().int().min(1).default(10),
+  }),
+  responses: {
+    200: {
+      description: 'One page of machines',
+      schema: typedObject('getLastMeterPerMachine (helpers/reports/meters)'),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const cashierPerformanceReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/cashier-performance',
+  tag: 'Reports',
+  summary: 'Cashier shift performance',
+  query: z.object({
+    locationId: params.locationId,
+    startDate: params.startDate,
+    endDate: params.endDate,
+    cashierId: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Per-cashier figures',
+      schema: z.object({
+        success: z.literal(true),
+        performance: z.array(z.record(z.unknown())),
+        summary: z.record(z.unknown()),
+        filters: z.record(z.unknown()),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const vaultActivityReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/vault-activity',
+  tag: 'Reports',
+  summary: 'Vault transactions',
+  query: z.object({
+    locationId: params.locationId,
+    startDate: params.startDate,
+    endDate: params.endDate,
+    type: z.string().optional(),
+    performedBy: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Transactions',
+      schema: z.object({
+        success: z.literal(true),
+        activities: z.array(z.record(z.unknown())),
+        summary: z.record(z.unknown()),
+        filters: z.record(z.unknown()),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const cashDeskReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/cash-desk',
+  tag: 'Reports',
+  summary: 'Cash desk reconciliation for a gaming day',
+  query: reportScopeQuery.extend({
+    day: params.gamingDay
+      .optional()
+      .describe("Gaming day, YYYY-MM-DD (default: yesterday's)"),
+    threshold: z.coerce
+      .number()
+      .min(0)
+      .default(DEFAULT_CASH_DESK_THRESHOLD)
+      .describe('Flag variances above this amount'),
+    flaggedOnly: z.enum(['true', 'false']).optional(),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Reconciliation',
+      schema: dataResponse(
+        typedObject('CashDeskReconciliationReport (helpers/cashDesk)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const collectionComplianceReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/collection-compliance',
+  tag: 'Reports',
+  summary: 'Scheduled collections done on time, late or missed',
+  query: z.object({
+    licencee: params.licencee,
+    locationId: params.locationId,
+    startDate: z
+      .string()
+      .optional()
+      .describe('Default: the 1st of the month two months ago'),
+    endDate: params.endDate,
+    lateHours: z.coerce
+      .number()
+      .min(0)
+      .default(DEFAULT_LATE_HOURS)
+      .describe('Hours after the window a collection counts as late'),
+  }),
+  responses: {
+    200: {
+      description: 'Compliance report',
+      schema: dataResponse(
+        typedObject(
+          'CollectionComplianceReport (helpers/reports/collectionCompliance)'
+        )
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const exportProfilesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/export-profiles',
+  tag: 'Reports',
+  summary: "Export profiles for the caller's licencees",
+  responses: {
+    200: {
+      description: 'Profiles',
+      schema: dataResponse(
+        z.array(typedObject('ExportProfile (utils/exportProfiles)'))
+      ),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const gameConversionReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/game-conversion',
+  tag: 'Reports',
+  summary: "A machine's performance before and after a game conversion",
+  query: z.object({
+    machineId: z.string(),
+    conversionDate: z
+      .string()
+      .optional()
+      .describe("ISO time (default: the machine's latest game change)"),
+    days: z.coerce
+      .number()
+      .int()
+      .min(1)
+      .max(MAX_CONVERSION_WINDOW_DAYS)
+      .default(DEFAULT_CONVERSION_WINDOW_DAYS)
+      .describe('Days on each side'),
+  }),
+  responses: {
+    200: {
+      description: 'Comparison',
+      schema: dataResponse(
+        typedObject('GameConversionReport (helpers/reports/gameConversion)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
+
+export const idleMachinesReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/idle-machines',
+  tag: 'Reports',
+  summary: 'Machines without play, by idle bucket',
+  query: reportScopeQuery.extend({
+    minBucket: z
+      .enum(Object.keys(IDLE_BUCKET_HOURS) as [IdleBucket, ...IdleBucket[]])
+      .default('24h'),
+    lookbackDays: z.coerce
+      .number()
+      .int()
+      .default(DEFAULT_IDLE_LOOKBACK_DAYS)
+      .describe('Clamped to 7-90'),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Idle machines',
+      schema: dataResponse(
+        typedObject('IdleMachinesReport (helpers/reports/idleMachines)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const levyReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/levy',
+  tag: 'Reports',
+  summary: 'Monthly gaming levy per location',
+  query: z.object({
+    month: z
+      .string()
+      .regex(/^\d{4}-(0[1-9]|1[0-2])$/)
+      .describe('YYYY-MM'),
+    licencee: params.licencee,
+    format: params.format,
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Levy report',
+      schema: dataResponse(typedObject('LevyReport (helpers/reports/levy)')),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const licenceeLeaderboardRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/licencee-leaderboard',
+  tag: 'Reports',
+  summary: 'Licencees ranked against the previous period',
+  query: z.object({
+    timePeriod: params.timePeriod('7d'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    format: params.format,
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Leaderboard',
+      schema: dataResponse(
+        z.array(
+          typedObject(
+            'LicenceeLeaderboardRow (helpers/reports/licenceeLeaderboard)'
+          )
+        )
+      ).extend({ timePeriod: z.string() }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const locationOnboardingReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/location-onboarding',
+  tag: 'Reports',
+  summary: 'Setup checklist for a new location',
+  query: z.object({
+    locationId: params.requiredLocationId,
+    format: params.format,
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Checklist',
+      schema: dataResponse(
+        typedObject(
+          'LocationOnboardingReport (helpers/reports/locationOnboarding)'
+        )
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const machineUtilizationReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/machine-utilization',
+  tag: 'Reports',
+  summary: 'Machine occupancy per location',
+  query: z.object({
+    licencee: params.licencee,
+    locationId: params.locationId,
+    timePeriod: params.timePeriod('7d'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    underutilizedRatio: z.coerce
+      .number()
+      .min(0)
+      .max(1)
+      .default(DEFAULT_UNDERUTILIZED_RATIO)
+      .describe('Flag machines below this share of the location average'),
+  }),
+  responses: {
+    200: {
+      description: 'Utilization per location',
+      schema: dataResponse(
+        z.array(
+          typedObject(
+            'LocationUtilization (helpers/reports/machineUtilization)'
+          )
+        )
+      ).extend({ timePeriod: z.string() }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const profitSplitReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/profit-split',
+  tag: 'Reports',
+  summary: 'Venue and operator shares of gross per location',
+  query: z.object({
+    from: params.gamingDay,
+    to: params.gamingDay,
+    licencee: params.licencee,
+    locationId: params.locationId,
+    format: z
+      .enum(['json', 'csv', 'payables'])
+      .default('json')
+      .describe('payables: the accounts payable CSV'),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Profit split',
+      schema: dataResponse(
+        typedObject('ProfitSplitReport (helpers/reports/profitSplit)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const sasAlertsReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/sas-alerts',
+  tag: 'Reports',
+  summary: 'SAS events by severity',
+  query: reportScopeQuery.extend({
+    machineId: z.string().optional(),
+    minSeverity: z
+      .enum(SAS_SEVERITIES as [SasSeverity, ...SasSeverity[]])
+      .default('warning'),
+    startDate: z
+      .string()
+      .optional()
+      .describe(`ISO start (default: ${DEFAULT_SAS_ALERT_HOURS} hours ago)`),
+    endDate: params.endDate,
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Alerts',
+      schema: dataResponse(
+        typedObject('SasAlertsReport (helpers/reports/sasAlerts)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const sessionAttributionReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/session-attribution',
+  tag: 'Reports',
+  summary: 'Machine play attributed to member sessions',
+  query: reportScopeQuery.extend({
+    memberId: z.string().optional(),
+    startDate: z
+      .string()
+      .optional()
+      .describe(`ISO start (default: ${DEFAULT_ATTRIBUTION_DAYS} days ago)`),
+    endDate: params.endDate,
+    defaultRtp: z
+      .string()
+      .optional()
+      .describe(
+        `RTP for machines without one, as 0.9 or 90 (default: ${DEFAULT_THEORETICAL_RTP})`
+      ),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Attribution',
+      schema: dataResponse(
+        typedObject(
+          'SessionAttributionReport (helpers/reports/sessionAttribution)'
+        )
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const shiftReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/shifts',
+  tag: 'Reports',
+  summary: 'Per-shift figures for a location',
+  query: z.object({
+    locationId: params.requiredLocationId,
+    range: z
+      .string()
+      .default('shift:today')
+      .describe(
+        'shift:today|yesterday|current|<N>d|YYYY-MM-DD[..YYYY-MM-DD], or a time period'
+      ),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    shift: z.string().optional().describe('Only this shift name'),
+  }),
+  responses: {
+    200: {
+      description: 'Shift report',
+      schema: dataResponse(
+        typedObject('ShiftReport (helpers/reports/shiftReport)')
+      ).extend({ range: z.record(z.unknown()) }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
+
+export const smibFirmwareReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/smib-firmware',
+  tag: 'Reports',
+  summary: 'SMIB firmware versions and machines needing updates',
+  query: reportScopeQuery.extend({
+    minimumVersion: z
+      .string()
+      .optional()
+      .describe(
+        'Flag versions below this (default: SMIB_MIN_FIRMWARE_VERSION)'
+      ),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Firmware report',
+      schema: dataResponse(
+        typedObject('SmibFirmwareReport (helpers/reports/smibFirmware)')
+      ),
+    },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const titoReconciliationReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/tito-reconciliation',
+  tag: 'Reports',
+  summary: 'Ticket in / out against drop and cancelled credits',
+  query: reportScopeQuery.extend({
+    timePeriod: params.timePeriod('7d'),
+    startDate: params.startDate,
+    endDate: params.endDate,
+    threshold: z.coerce
+      .number()
+      .min(0)
+      .default(DEFAULT_TITO_THRESHOLD)
+      .describe('Imbalance tolerated before flagging, in meter units'),
+    flaggedOnly: z.enum(['true', 'false']).optional(),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Reconciliation',
+      schema: dataResponse(
+        typedObject(
+          'TitoReconciliationReport (helpers/reports/titoReconciliation)'
+        )
+      ).extend({ timePeriod: z.string() }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const topMembersReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/top-members',
+  tag: 'Reports',
+  summary: 'Top members per location, with responsible-gaming flags',
+  query: reportScopeQuery.extend({
+    startDate: z
+      .string()
+      .optional()
+      .describe(`ISO start (default: ${DEFAULT_TOP_MEMBERS_DAYS} days ago)`),
+    endDate: params.endDate,
+    limit: z.coerce
+      .number()
+      .int()
+      .min(1)
+      .max(100)
+      .default(DEFAULT_TOP_MEMBERS_LIMIT)
+      .describe('Members per location'),
+    sortBy: z.enum(['wagered', 'time']).default('wagered'),
+    flaggedOnly: z.enum(['true', 'false']).optional(),
+    maxHoursPerDay: z
+      .string()
+      .optional()
+      .describe('Overrides RG_MAX_HOURS_PER_DAY'),
+    maxWeeklyLoss: z
+      .string()
+      .optional()
+      .describe('Overrides RG_MAX_WEEKLY_LOSS'),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Top members',
+      schema: dataResponse(
+        typedObject('TopMembersReport (helpers/reports/topMembers)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+
+export const uncollectedDropReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/uncollected-drop',
+  tag: 'Reports',
+  summary: 'Drop since each machine was last collected',
+  query: reportScopeQuery.extend({
+    maxDays: z.coerce
+      .number()
+      .int()
+      .default(DEFAULT_UNCOLLECTED_MAX_DAYS)
+      .describe('Days counted for machines never collected, 1-365'),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Uncollected drop',
+      schema: dataResponse(
+        typedObject('UncollectedDropReport (helpers/reports/uncollectedDrop)')
+      ),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+  },
+});
+// ============================================================================
+// Query Builder
+// ============================================================================
+
+export const queryBuilderCatalogRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/query-builder',
+  tag: 'Reports',
+  summary: 'Entities, group-bys and metrics the query builder accepts',
+  responses: {
+    200: {
+      description: 'Catalog',
+      schema: z.object({
+        success: z.literal(true),
+        entities: z.array(z.record(z.unknown())),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const runQueryBuilderRoute = defineRoute({
+  method: 'POST',
+  path: '/api/reports/query-builder',
+  tag: 'Reports',
+  summary: 'Run an ad-hoc aggregation',
+  description:
+    'Results are limited to the locations the user can access. dryRun returns the pipeline without running it.',
+  body: z.object({
+    spec: querySpecSchema,
+    dryRun: z.boolean().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Pipeline and rows',
+      schema: z.object({
+        success: z.literal(true),
+        pipeline: z.array(z.record(z.unknown())),
+        shell: z.string().describe('The pipeline as a mongosh command'),
+        rows: z.array(z.record(z.unknown())),
+      }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Report Templates
+// ============================================================================
+
+export const listReportTemplatesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/templates',
+  tag: 'Reports',
+  summary: 'Saved report templates',
+  responses: {
+    200: {
+      description: 'Templates',
+      schema: dataResponse(z.array(reportTemplateSchema)),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const saveReportTemplateRoute = defineRoute({
+  method: 'POST',
+  path: '/api/reports/templates',
+  tag: 'Reports',
+  summary: 'Save a report template',
+  body: reportTemplateSaveSchema,
+  responses: {
+    200: {
+      description: 'Replaced',
+      schema: dataResponse(reportTemplateSchema),
+    },
+    201: {
+      description: 'Created',
+      schema: dataResponse(reportTemplateSchema),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+  },
+});
+
+export const getReportTemplateRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/templates/{name}',
+  tag: 'Reports',
+  summary: 'A saved report template',
+  responses: {
+    200: {
+      description: 'The template',
+      schema: dataResponse(reportTemplateSchema),
+    },
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const deleteReportTemplateRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/reports/templates/{name}',
+  tag: 'Reports',
+  summary: 'Delete a report template',
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: dataResponse(reportTemplateSchema),
+    },
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const runReportTemplateRoute = defineRoute({
+  method: 'GET',
+  path: '/api/reports/templates/{name}/run',
+  tag: 'Reports',
+  summary: 'Run a report template',
+  query: z.object({
+    format: z
+      .enum(['json', 'csv'])
+      .optional()
+      .describe("Default: the template's output format"),
+    profile: z
+      .string()
+      .optional()
+      .describe('Export profile shaping the output'),
+  }),
+  csv: true,
+  responses: {
+    200: {
+      description: 'Rows',
+      schema: z.object({
+        success: z.literal(true),
+        template: z.string(),
+        reportType: z.string(),
+        outputFormat: z.enum(['json', 'csv']),
+        rows: z.array(z.record(z.unknown())),
+        profile: z.string().optional(),
+      }),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
@@ -0,0 +1,92 @@
+/**
+ * Route Schemas: Schedulers
+ *
+ * `/api/schedulers/**`: collection schedules assigned to collectors.
+ *
+ * @module app/api/lib/routeSchemas/schedulers
+ */
+
+import {
+  dataResponse,
+  defineRoute,
+  errors,
+  messageResponseSchema,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const SCHEDULER_STATUSES = ['pending', 'completed', 'canceled'] as const;
+
+const schedulerSchema = typedObject('Scheduler (lib/types/api)');
+
+const allOr = (description: string) =>
+  z.string().optional().describe(`${description}; all for no filter`);
+
+// ============================================================================
+// Schedulers
+// ============================================================================
+
+export const listSchedulersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/schedulers',
+  tag: 'Schedulers',
+  summary: 'List collection schedules',
+  description:
+    'Newest start first, with location, collector and creator names.',
+  query: z.object({
+    licencee: allOr('Licencee id'),
+    location: allOr('Location id'),
+    collector: allOr('Collector user id'),
+    status: allOr(SCHEDULER_STATUSES.join(', ')),
+    startDate: params.isoDate.optional().describe('Earliest start time'),
+    endDate: params.isoDate.optional().describe('Latest start time'),
+  }),
+  responses: {
+    200: { description: 'Schedules', schema: z.array(schedulerSchema) },
+    401: errors.unauthorized,
+  },
+});
+
+export const updateSchedulerRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/schedulers/{schedulerId}',
+  tag: 'Schedulers',
+  summary: 'Edit a collection schedule',
+  description:
+    'Managers, admins, location admins, owners and developers. At least one field.',
+  body: z.object({
+    startTime: params.isoDate.optional(),
+    endTime: params.isoDate.optional(),
+    status: z.enum(SCHEDULER_STATUSES).optional(),
+  }),
+  responses: {
+    200: { description: 'Updated', schema: dataResponse(schedulerSchema) },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
+
+export const deleteSchedulerRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/schedulers/{schedulerId}',
+  tag: 'Schedulers',
+  summary: 'Delete a collection schedule',
+  description:
+    'Managers, admins, location admins, owners and developers. Soft delete.',
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: messageResponseSchema.extend({ data: z.null() }),
+    },
+    401: errors.unauthorized,
+    403: errors.forbidden,
+    404: errors.notFound,
+  },
+});
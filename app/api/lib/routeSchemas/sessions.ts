@@ -0,0 +1,128 @@
+/**
+ * Route Schemas: Sessions
+ *
+ * `/api/sessions/**`: machine play sessions and the events recorded during
+ * them.
+ *
+ * @module app/api/lib/routeSchemas/sessions
+ */
+
+import {
+  dataResponse,
+  defineRoute,
+  errors,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Sessions
+// ============================================================================
+
+export const listSessionsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/sessions',
+  tag: 'Sessions',
+  summary: 'List machine sessions',
+  description: 'startDate and endDate together override dateFilter.',
+  query: z.object({
+    page: z.coerce.number().int().min(1).default(1),
+    limit: z.coerce.number().int().min(1).default(10),
+    search: z
+      .string()
+      .default('')
+      .describe('Matches session, machine or member id'),
+    sortBy: z.string().default('startTime'),
+    sortOrder: z.enum(['asc', 'desc']).default('desc'),
+    licencee: z.string().default('').describe('Licencee name'),
+    dateFilter: z
+      .enum(['today', 'yesterday', 'week', 'month', 'all'])
+      .default('all'),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+  }),
+  responses: {
+    200: {
+      description: 'One page of sessions',
+      schema: dataResponse(
+        z.object({
+          sessions: z.array(z.record(z.unknown())),
+          pagination: z.object({
+            currentPage: z.number(),
+            totalPages: z.number(),
+            totalSessions: z.number(),
+            hasNextPage: z.boolean(),
+            hasPrevPage: z.boolean(),
+          }),
+        })
+      ),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const getSessionRoute = defineRoute({
+  method: 'GET',
+  path: '/api/sessions/{sessionId}',
+  tag: 'Sessions',
+  summary: 'A session with its member and membership settings',
+  responses: {
+    200: {
+      description: 'The session',
+      schema: dataResponse(z.record(z.unknown())),
+    },
+    400: errors.badRequest,
+    401: errors.unauthorized,
+    404: errors.notFound,
+  },
+});
+
+export const listSessionEventsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/sessions/{sessionId}/{machineId}/events',
+  tag: 'Sessions',
+  summary: "A session's machine events, with the filter options",
+  description:
+    'startDate and endDate together override timePeriod. command jumps to the page holding the latest event with that code.',
+  query: z.object({
+    page: z.coerce.number().int().min(1).default(1),
+    limit: z.coerce
+      .number()
+      .int()
+      .min(1)
+      .default(20)
+      .describe('At most 100'),
+    timePeriod: params.optionalTimePeriod,
+    eventType: z.string().optional().describe('Partial match'),
+    type: z.string().optional().describe('Event log level'),
+    event: z.string().optional().describe('Partial match on description'),
+    game: z.string().optional().describe('Partial match on game name'),
+    command: z.string().optional().describe('Event code to seek to'),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+  }),
+  responses: {
+    200: {
+      description: 'One page of events and the filter options',
+      schema: dataResponse(
+        z.object({
+          events: z.array(typedObject('MachineEvent with sasEvent')),
+          pagination: z.object({
+            currentPage: z.number(),
+            hasMore: z.boolean(),
+            hasPrevPage: z.boolean(),
+            cursorResolved: z.boolean(),
+          }),
+          filters: z.object({
+            eventTypes: z.array(z.string()),
+            eventLogLevels: z.array(z.string()),
+            descriptions: z.array(z.string()),
+            games: z.array(z.string()),
+          }),
+        })
+      ),
+    },
+    401: errors.unauthorized,
+  },
+});
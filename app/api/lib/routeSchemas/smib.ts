@@ -0,0 +1,161 @@
+/**
+ * Route Schemas: SMIB
+ *
+ * `/api/smib/**`: pings sent by the SMIBs themselves and the commands
+ * users send to a SMIB over MQTT.
+ *
+ * @module app/api/lib/routeSchemas/smib
+ */
+
+import { MAX_HEARTBEATS_PER_BATCH } from '@/app/api/lib/helpers/heartbeats';
+import {
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageResponseSchema,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { z } from 'zod';
+
+// ============================================================================
+// Heartbeats
+// ============================================================================
+
+const heartbeatPingSchema = z.object({
+  serial: z.string().describe('SMIB relay id or machine serial'),
+  timestamp: z.union([z.string(), z.number()]).optional(),
+  firmware: z.string().optional(),
+});
+
+/**
+ * Pings are validated one by one by `ingestHeartbeats()`, so one bad ping
+ * is reported in `rejected` instead of failing its batch; the body schema
+ * documents the payload and the handler does not parse with it.
+ */
+export const heartbeatRoute = defineRoute({
+  method: 'POST',
+  path: '/api/smib/heartbeat',
+  tag: 'Machines',
+  summary: 'SMIB heartbeat pings',
+  description: `Authenticated with Authorization: Bearer <HEARTBEAT_TOKEN> rather than a session; 503 while no token is configured. A single ping or a batch of up to ${MAX_HEARTBEATS_PER_BATCH}.`,
+  public: true,
+  body: z.union([
+    heartbeatPingSchema,
+    z.object({ heartbeats: z.array(heartbeatPingSchema) }),
+  ]),
+  responses: {
+    200: {
+      description: 'Matched, unknown and rejected serials',
+      schema: dataResponse(
+        typedObject('HeartbeatIngestResult (helpers/heartbeats)')
+      ),
+    },
+    400: errors.badRequest,
+    401: { description: 'Bad token', schema: errorResponseSchema },
+    503: { description: 'Not configured', schema: errorResponseSchema },
+  },
+});
+
+// ============================================================================
+// Commands
+// ============================================================================
+
+const relayIdSchema = z.string().min(1).describe('SMIB relay id');
+
+const commandSentSchema = messageResponseSchema.extend({
+  relayId: z.string(),
+});
+
+const machineNotFound = {
+  description: 'No machine has that relay id',
+  schema: errorResponseSchema,
+};
+
+export const requestSmibMetersRoute = defineRoute({
+  method: 'POST',
+  path: '/api/smib/meters',
+  tag: 'SMIB',
+  summary: 'Ask a SMIB to report its meters',
+  body: z.object({ relayId: relayIdSchema }),
+  responses: {
+    200: { description: 'Request sent', schema: commandSentSchema },
+    401: errors.unauthorized,
+    404: machineNotFound,
+  },
+});
+
+export const updateSmibMetersRoute = defineRoute({
+  method: 'POST',
+  path: '/api/smib/update-meters',
+  tag: 'SMIB',
+  summary: 'Send a SMIB the Update Meters command',
+  body: z.object({ relayId: relayIdSchema }),
+  responses: {
+    200: { description: 'Command sent', schema: commandSentSchema },
+    401: errors.unauthorized,
+    404: machineNotFound,
+  },
+});
+
+export const restartSmibRoute = defineRoute({
+  method: 'POST',
+  path: '/api/smib/restart',
+  tag: 'SMIB',
+  summary: 'Restart a SMIB',
+  body: z.object({ relayId: relayIdSchema }),
+  responses: {
+    200: { description: 'Command sent', schema: commandSentSchema },
+    401: errors.unauthorized,
+    404: machineNotFound,
+  },
+});
+
+const NVS_ACTIONS = [
+  'clear_nvs',
+  'clear_nvs_meters',
+  'clear_nvs_bv',
+  'clear_nvs_door',
+] as const;
+
+export const smibNvsActionRoute = defineRoute({
+  method: 'POST',
+  path: '/api/smib/nvs-action',
+  tag: 'SMIB',
+  summary: "Clear all or part of a SMIB's NVS storage",
+  body: z.object({
+    relayId: relayIdSchema,
+    action: z.enum(NVS_ACTIONS),
+  }),
+  responses: {
+    200: {
+      description: 'Command sent',
+      schema: commandSentSchema.extend({ action: z.enum(NVS_ACTIONS) }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const smibOtaUpdateRoute = defineRoute({
+  method: 'POST',
+  path: '/api/smib/ota-update',
+  tag: 'SMIB',
+  summary: 'Start an over-the-air firmware update on a SMIB',
+  description:
+    'Points the SMIB at the firmware download URL, then sends the update command.',
+  body: z.object({
+    relayId: relayIdSchema,
+    firmwareId: z.string().min(1),
+  }),
+  responses: {
+    200: {
+      description: 'Update started',
+      schema: commandSentSchema.extend({
+        firmwareId: z.string(),
+        fileName: z.string(),
+        firmwareBinUrl: z.string(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
@@ -0,0 +1,258 @@
+/**
+ * Route Schemas: Users
+ *
+ * `/api/users/**`: user administration, plus the token check the login flow
+ * runs before reporting success.
+ *
+ * @module app/api/lib/routeSchemas/users
+ */
+
+import {
+  defineRoute,
+  errors,
+  messageError,
+  messageErrorSchema,
+  params,
+  typedObject,
+} from '@/app/api/lib/routeSchemas/common';
+import { validateEmail } from '@/lib/utils/validation/email';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+const userSchema = typedObject('User, without the password (shared/types)');
+
+const userResponseSchema = z.object({
+  success: z.literal(true),
+  user: userSchema,
+});
+
+const multiplierSchema = z.number().nullable().optional();
+
+/**
+ * Fields a user update may carry. Dotted paths such as
+ * `profile.contact.phone` are also accepted and passed through as sent.
+ */
+const userUpdateSchema = z
+  .object({
+    username: z.string().min(1).optional(),
+    emailAddress: z.string().optional(),
+    password: z.string().optional().describe('Hashed before storage'),
+    roles: z
+      .array(z.string())
+      .optional()
+      .describe('Changing the roles signs the user out'),
+    profile: z.record(z.unknown()).optional(),
+    isEnabled: z.boolean().optional(),
+    profilePicture: z.string().nullable().optional(),
+    assignedLocations: z.array(z.string()).optional(),
+    assignedLicencees: z.array(z.string()).optional(),
+    moneyInMultiplier: multiplierSchema,
+    moneyOutAndJackpotMultiplier: multiplierSchema,
+    reviewerMultiplierStartTime: z.string().nullable().optional(),
+  })
+  .passthrough();
+
+const userUpdateDescription =
+  'Only the fields sent are changed. Assignments and reviewer multipliers are dropped unless the caller is an admin, owner or developer.';
+
+const userNotFound = messageError('User not found');
+
+// ============================================================================
+// Users
+// ============================================================================
+
+export const listUsersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/users',
+  tag: 'Users',
+  summary: 'List users',
+  description:
+    "Limited to the caller's licencees and locations. Deleted users need an admin, developer, manager or location admin; cashiers also allow vault managers.",
+  query: z.object({
+    status: z.enum(['all', 'active', 'disabled', 'deleted']).default('all'),
+    role: z
+      .string()
+      .optional()
+      .describe('Role name, or all; cashier also adds float balances'),
+    licencee: params.licencee,
+    search: params.search,
+    searchMode: z
+      .enum(['username', 'email', '_id', 'all'])
+      .default('username'),
+    variance: z
+      .enum(['variance', 'no-variance'])
+      .optional()
+      .describe('Cashiers only: with or without a float discrepancy'),
+    page: params.page.default(1),
+    limit: params.limit.default(50).describe('Rows per page. At most 1000'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of users',
+      schema: z.object({
+        success: z.literal(true),
+        users: z.array(userSchema),
+        pagination: z.object({
+          page: z.number(),
+          limit: z.number(),
+          total: z.number(),
+          totalPages: z.number(),
+        }),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: messageError('Role cannot list these users'),
+  },
+});
+
+export const createUserRoute = defineRoute({
+  method: 'POST',
+  path: '/api/users',
+  tag: 'Users',
+  summary: 'Create a user',
+  description:
+    'The password must meet the strength rules. Managers and vault managers can only assign some roles.',
+  body: z.object({
+    username: z.string().min(1),
+    emailAddress: z.string().refine(validateEmail, {
+      message: 'must be a valid email',
+    }),
+    password: z.string().min(1),
+    roles: z.array(z.string()).default([]),
+    profile: z
+      .object({ gender: z.string().min(1) })
+      .passthrough()
+      .describe('Name, contact, address and identification'),
+    isEnabled: z.boolean().default(true),
+    profilePicture: z.string().nullable().default(null),
+    assignedLocations: z.array(z.string()).optional(),
+    assignedLicencees: z.array(z.string()).optional(),
+    tempPassword: z.string().optional(),
+    moneyInMultiplier: multiplierSchema,
+    moneyOutAndJackpotMultiplier: multiplierSchema,
+    reviewerMultiplierStartTime: z.string().nullable().optional(),
+  }),
+  responses: {
+    201: { description: 'Created', schema: userResponseSchema },
+    400: {
+      description: 'Validation failed, or the password is too weak',
+      schema: z.union([errors.validation.schema, messageErrorSchema]),
+    },
+    401: errors.unauthorized,
+    409: messageError('Username or email already exists'),
+  },
+});
+
+export const updateUserRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/users',
+  tag: 'Users',
+  summary: 'Edit a user',
+  description: userUpdateDescription,
+  body: userUpdateSchema.extend({ _id: z.string().min(1) }),
+  responses: {
+    200: { description: 'Updated', schema: userResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: userNotFound,
+    409: messageError('Username or email already exists'),
+  },
+});
+
+export const deleteUserRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/users',
+  tag: 'Users',
+  summary: 'Delete a user',
+  description:
+    'Soft delete. Location admins cannot delete managers, admins or developers.',
+  body: z.object({ _id: z.string().min(1) }),
+  responses: {
+    200: {
+      description: 'Deleted',
+      schema: z.object({ success: z.literal(true) }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: userNotFound,
+  },
+});
+
+export const getUserRoute = defineRoute({
+  method: 'GET',
+  path: '/api/users/{id}',
+  tag: 'Users',
+  summary: 'Get a user',
+  responses: {
+    200: { description: 'User', schema: userResponseSchema },
+    401: errors.unauthorized,
+    404: userNotFound,
+  },
+});
+
+export const replaceUserRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/users/{id}',
+  tag: 'Users',
+  summary: 'Edit a user by id',
+  description: `${userUpdateDescription} An _id in the body is ignored. Admin and developer roles only.`,
+  body: userUpdateSchema,
+  responses: {
+    200: { description: 'Updated', schema: userResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: messageError('Admin and developer roles only'),
+    404: userNotFound,
+  },
+});
+
+export const patchUserRoute = defineRoute({
+  method: 'PATCH',
+  path: '/api/users/{id}',
+  tag: 'Users',
+  summary: 'Patch a user by id',
+  description: `${userUpdateDescription} An _id in the body is ignored. Admin and developer roles only.`,
+  body: userUpdateSchema,
+  responses: {
+    200: { description: 'Updated', schema: userResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: messageError('Admin and developer roles only'),
+    404: userNotFound,
+  },
+});
+
+// ============================================================================
+// Token Check
+// ============================================================================
+
+export const testCurrentUserRoute = defineRoute({
+  method: 'GET',
+  path: '/api/test-current-user',
+  tag: 'Users',
+  summary: 'Check the session token',
+  description:
+    'Called during login to confirm the auth cookie was set before reporting success.',
+  responses: {
+    200: {
+      description: 'Token valid',
+      schema: z.object({
+        success: z.literal(true),
+        message: z.string(),
+        userId: z.string(),
+      }),
+    },
+    401: {
+      description: 'No valid token',
+      schema: z.object({
+        success: z.literal(false),
+        message: z.string(),
+        userId: z.null(),
+      }),
+    },
+  },
+});
@@ -0,0 +1,1722 @@
+/**
+ * Route Schemas: Vault
+ *
+ * `/api/cashier/**` and `/api/vault/**`: the vault shift a vault manager runs
+ * for a location, and the cashier shifts and payouts funded from it.
+ *
+ * @module app/api/lib/routeSchemas/vault
+ */
+
+import {
+  dataResponse,
+  defineRoute,
+  errorResponseSchema,
+  errors,
+  messageResponseSchema,
+  params,
+  typedObject,
+  uploadedFile,
+} from '@/app/api/lib/routeSchemas/common';
+import type { Denomination } from '@/shared/types/vault';
+import { z } from 'zod';
+
+// ============================================================================
+// Resource Schemas
+// ============================================================================
+
+/**
+ * Note counts. Values outside the configured denominations are rejected by
+ * `validateDenominations()` with the offending values listed.
+ */
+const denominationsSchema = z.array(
+  z.object({
+    denomination: z
+      .number()
+      .describe('1, 2, 5, 10, 20, 50, 100, 500, 1000 or 5000'),
+    quantity: z.number().int().min(0),
+  })
+) as z.ZodType<Denomination[]>;
+
+const cashierShiftSchema = typedObject('CashierShift (shared/types/vault)');
+
+const vaultShiftSchema = typedObject('VaultShift (shared/types/vault)');
+
+const vaultTransactionSchema = typedObject(
+  'VaultTransaction (shared/types/vault)'
+);
+
+const vaultManagerOnly = {
+  description: 'Vault manager, manager, admin and developer roles only',
+  schema: errorResponseSchema,
+};
+
+const shiftNotFound = {
+  description: 'Cashier shift not found',
+  schema: errorResponseSchema,
+};
+
+const vaultShiftNotFound = {
+  description: 'Vault shift not found',
+  schema: errorResponseSchema,
+};
+
+const vaultNotReconciled = {
+  description: 'The vault shift is not reconciled',
+  schema: errorResponseSchema,
+};
+
+const paginationSchema = z.object({
+  page: z.number(),
+  limit: z.number(),
+  total: z.number(),
+  totalPages: z.number(),
+});
+
+const FLOAT_REQUEST_STATUSES = [
+  'pending',
+  'approved',
+  'approved_vm',
+  'denied',
+  'edited',
+  'cancelled',
+] as const;
+
+/** 400: the zod issues, or a rule the handler checks against stored data */
+function invalidRequest(description: string) {
+  return {
+    description,
+    schema: z.union([
+      errors.validation.schema,
+      errorResponseSchema.extend({ details: z.array(z.string()).optional() }),
+    ]),
+  };
+}
+
+// ============================================================================
+// Vault Shifts
+// ============================================================================
+
+export const vaultBalanceRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/balance',
+  tag: 'Vault',
+  summary: "A location's vault balance",
+  description:
+    "From the active vault shift, with the cashier floats and today's machine drop. Without one, the closing balance of the last shift.",
+  query: z.object({ locationId: params.requiredLocationId }),
+  responses: {
+    200: {
+      description: 'Balance',
+      schema: dataResponse(typedObject('VaultBalance (shared/types/vault)')),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+  },
+});
+
+export const initializeVaultRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/initialize',
+  tag: 'Vault',
+  summary: 'Open a vault shift',
+  description:
+    'Opens with the closing balance and denominations of the last shift unless both openingBalance and denominations are sent.',
+  body: z.object({
+    locationId: z.string().min(1),
+    openingBalance: z.number().min(0).optional(),
+    denominations: denominationsSchema
+      .optional()
+      .describe('Must add up to openingBalance'),
+    notes: z.string().optional(),
+  }),
+  responses: {
+    201: {
+      description: 'Opened',
+      schema: messageResponseSchema.extend({
+        vaultShift: vaultShiftSchema,
+        transaction: vaultTransactionSchema,
+      }),
+    },
+    400: invalidRequest('Validation failed, or the denominations are wrong'),
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+    409: {
+      description: 'The location already has an active vault shift',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const reconcileVaultRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/reconcile',
+  tag: 'Vault',
+  summary: 'Record a vault count',
+  description:
+    'Sets the balance to the counted amount and marks the shift reconciled, which cashier shifts and payouts require.',
+  body: z.object({
+    vaultShiftId: z.string().min(1),
+    newBalance: z.number().min(0),
+    denominations: denominationsSchema.describe('Must add up to newBalance'),
+    reason: z.string().optional().describe('Reason for the adjustment'),
+    comment: z.string().optional().describe('Used when no reason is sent'),
+  }),
+  responses: {
+    200: {
+      description: 'Reconciled',
+      schema: z.object({
+        success: z.literal(true),
+        vaultShift: vaultShiftSchema,
+        transaction: vaultTransactionSchema,
+        adjustment: z.number().describe('New balance minus the previous one'),
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, the denominations are wrong, or the shift is closed'
+    ),
+    401: errors.unauthorized,
+    403: {
+      description: 'Not a vault manager, or not assigned to the location',
+      schema: errorResponseSchema,
+    },
+    404: vaultShiftNotFound,
+  },
+});
+
+export const closeVaultShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/shift/close',
+  tag: 'Vault',
+  summary: 'Close a vault shift',
+  description:
+    'The shift must be reconciled, and every cashier shift it funded closed or reviewed.',
+  body: z.object({
+    vaultShiftId: z.string().min(1),
+    closingBalance: z.number().min(0),
+    denominations: denominationsSchema,
+  }),
+  responses: {
+    200: {
+      description: 'Closed',
+      schema: z.object({
+        success: z.literal(true),
+        vaultShift: vaultShiftSchema,
+        transaction: vaultTransactionSchema,
+      }),
+    },
+    400: {
+      description:
+        'Validation failed, the denominations are wrong, or the shift cannot close yet',
+      schema: z.union([
+        errors.validation.schema,
+        errorResponseSchema.extend({
+          details: z.array(z.string()).optional(),
+          active: z
+            .array(z.string())
+            .optional()
+            .describe('Cashiers with an active shift'),
+          pending: z
+            .array(z.string())
+            .optional()
+            .describe('Cashiers waiting for review'),
+        }),
+      ]),
+    },
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+    404: vaultShiftNotFound,
+  },
+});
+
+export const getShiftRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/shifts/{id}',
+  tag: 'Vault',
+  summary: 'Get a shift record',
+  description: 'Reads the older shift log, not vault or cashier shifts.',
+  responses: {
+    200: {
+      description: 'Shift',
+      schema: dataResponse(z.object({ shift: typedObject('Shift (models)') })),
+    },
+    401: errors.unauthorized,
+    404: { description: 'Shift not found', schema: errorResponseSchema },
+  },
+});
+
+// ============================================================================
+// Vault Cash
+// ============================================================================
+
+/** 400 for the cash movements made from the caller's active vault shift */
+const cashMovementInvalid = invalidRequest(
+  'Validation failed, the denominations do not add up, or the caller has no active vault shift'
+);
+
+const cashMovementForbidden = {
+  description: 'Not a vault manager, or not assigned to the location',
+  schema: errorResponseSchema,
+};
+
+export const addVaultCashRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/add-cash',
+  tag: 'Vault',
+  summary: 'Add cash to the vault',
+  description: "Added to the caller's active vault shift.",
+  body: z.object({
+    source: z.string().min(1).describe('Bank, Owner Deposit or Machine'),
+    amount: z.number().positive(),
+    denominations: denominationsSchema.describe('Must add up to amount'),
+    notes: z.string().optional(),
+    bankDetails: z
+      .record(z.string())
+      .optional()
+      .describe('Bank name, account and transit details'),
+    machineIds: z
+      .array(z.string())
+      .optional()
+      .describe('Machines the cash came from'),
+  }),
+  responses: {
+    200: {
+      description: 'Added',
+      schema: z.object({
+        success: z.literal(true),
+        transaction: vaultTransactionSchema,
+      }),
+    },
+    400: cashMovementInvalid,
+    401: errors.unauthorized,
+    403: cashMovementForbidden,
+  },
+});
+
+export const removeVaultCashRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/remove-cash',
+  tag: 'Vault',
+  summary: 'Remove cash from the vault',
+  description: "Taken from the caller's active vault shift.",
+  body: z.object({
+    reason: z.string().min(1),
+    amount: z.number().positive(),
+    denominations: denominationsSchema.describe('Must add up to amount'),
+    notes: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Removed',
+      schema: z.object({
+        success: z.literal(true),
+        transaction: vaultTransactionSchema,
+      }),
+    },
+    400: cashMovementInvalid,
+    401: errors.unauthorized,
+    403: cashMovementForbidden,
+  },
+});
+
+export const recordSoftCountRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/soft-counts',
+  tag: 'Vault',
+  summary: 'Record a soft count',
+  description:
+    "The counted machine cash leaves the caller's active vault shift for the count room.",
+  body: z.object({
+    amount: z.number().positive(),
+    denominations: denominationsSchema.describe('Must add up to amount'),
+    notes: z.string().optional(),
+    isEndOfDay: z.boolean().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Recorded',
+      schema: z.object({
+        success: z.literal(true),
+        softCount: typedObject('SoftCount (shared/types/vault)'),
+        transaction: vaultTransactionSchema,
+      }),
+    },
+    400: cashMovementInvalid,
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+  },
+});
+
+// ============================================================================
+// Expenses
+// ============================================================================
+
+/** A multipart field holding JSON, checked against `schema` once parsed */
+function jsonField<T extends z.ZodTypeAny>(schema: T) {
+  return z
+    .string()
+    .transform((value, ctx) => {
+      try {
+        return JSON.parse(value) as unknown;
+      } catch {
+        ctx.addIssue({ code: z.ZodIssueCode.custom, message: 'must be JSON' });
+        return z.NEVER;
+      }
+    })
+    .pipe(schema);
+}
+
+export const createExpenseRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/expense',
+  tag: 'Vault',
+  summary: 'Pay an expense from the vault',
+  description:
+    "Paid from the caller's active vault shift, which must hold the amount. The receipt is stored in GridFS.",
+  multipart: true,
+  body: z.object({
+    category: z.string().min(1).describe('Also the payee'),
+    amount: z.coerce.number().positive(),
+    denominations: jsonField(denominationsSchema)
+      .transform(denoms => denoms.filter(denom => denom.quantity > 0))
+      .describe('JSON; must add up to amount'),
+    description: z.string().optional(),
+    date: params.isoDate.optional().describe('Default: now'),
+    file: uploadedFile.optional().describe('Receipt image or PDF'),
+    bankDetails: jsonField(z.record(z.unknown())).optional().describe('JSON'),
+    expenseDetails: jsonField(z.record(z.unknown()))
+      .optional()
+      .describe('JSON; machine repairs list machineIds'),
+  }),
+  responses: {
+    200: {
+      description: 'Paid',
+      schema: z.object({
+        success: z.literal(true),
+        transaction: vaultTransactionSchema,
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, no active vault shift, or the vault cannot cover it'
+    ),
+    401: errors.unauthorized,
+    403: cashMovementForbidden,
+  },
+});
+
+export const listExpensesRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/expense',
+  tag: 'Vault',
+  summary: 'List expenses',
+  description:
+    'Newest first, at most 500. Without a locationId, every location the caller can access.',
+  query: z.object({
+    locationId: params.locationId,
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+    category: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Expenses',
+      schema: z.object({
+        success: z.literal(true),
+        expenses: z.array(vaultTransactionSchema),
+        count: z.number(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: cashMovementForbidden,
+  },
+});
+
+// ============================================================================
+// Collection Sessions
+// ============================================================================
+
+const collectionSessionSchema = typedObject(
+  'IVaultCollectionSession (models/vault-collection-session)'
+);
+
+const COLLECTION_SESSION_TYPES = ['soft_count', 'machine_collection'] as const;
+
+const sessionEntrySchema = z.object({
+  machineId: z.string().min(1),
+  machineName: z.string().optional(),
+  source: z.enum(['manual', 'meter']).default('manual'),
+  totalAmount: z.number().min(0).default(0),
+  denominations: denominationsSchema.default([]),
+  meters: z
+    .object({
+      billIn: z.number().optional(),
+      ticketIn: z.number().optional(),
+      totalIn: z.number().optional(),
+    })
+    .default({}),
+  expectedDrop: z.number().default(0),
+  variance: z.number().default(0),
+  notes: z.string().default(''),
+  isEndOfDay: z.boolean().default(false),
+  collectedAt: params.isoDate.optional().describe('Default: now'),
+});
+
+/** Sent with every action; start finds or creates the session by them */
+const sessionTarget = z.object({
+  locationId: z.string().min(1),
+  vaultShiftId: z.string().min(1),
+  type: z.enum(COLLECTION_SESSION_TYPES).default('soft_count'),
+  isEndOfDay: z.boolean().default(false),
+});
+
+export const getCollectionSessionRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/collection-session',
+  tag: 'Vault',
+  summary: "A vault shift's collection session",
+  description:
+    'The newest match. Without a status, only an active session unless isEndOfDay is true.',
+  query: z.object({
+    vaultShiftId: z.string().min(1),
+    locationId: params.requiredLocationId,
+    status: z.enum(['active', 'completed', 'cancelled']).optional(),
+    type: z.enum(COLLECTION_SESSION_TYPES).default('soft_count'),
+    isEndOfDay: params.flag,
+  }),
+  responses: {
+    200: {
+      description: 'The session, or null',
+      schema: z.object({
+        success: z.literal(true),
+        session: collectionSessionSchema.nullable(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const updateCollectionSessionRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/collection-session',
+  tag: 'Vault',
+  summary: 'Start or edit a collection session',
+  description:
+    'start resumes the active session when there is one. addEntry replaces the entry of the same machine.',
+  body: z.discriminatedUnion('action', [
+    sessionTarget.extend({ action: z.literal('start') }),
+    sessionTarget.extend({
+      action: z.literal('addEntry'),
+      sessionId: z.string().min(1),
+      entryData: sessionEntrySchema,
+    }),
+    sessionTarget.extend({
+      action: z.literal('removeEntry'),
+      sessionId: z.string().min(1),
+      machineId: z.string().min(1),
+    }),
+    sessionTarget.extend({
+      action: z.literal('cancel'),
+      sessionId: z.string().min(1),
+    }),
+  ]),
+  responses: {
+    200: {
+      description: 'The session',
+      schema: z.object({
+        success: z.literal(true),
+        session: collectionSessionSchema.nullable(),
+        message: z.string().optional(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: {
+      description: 'No such session, or it is no longer active',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const finalizeCollectionSessionRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/collection-session/finalize',
+  tag: 'Vault',
+  summary: 'Finalize a collection session',
+  description:
+    'Books every entry into the vault shift as a transaction; soft counts also get a soft count record.',
+  body: z.object({
+    sessionId: z.string().min(1),
+    locationId: z.string().min(1),
+    vaultShiftId: z.string().min(1),
+  }),
+  responses: {
+    200: {
+      description: 'Finalized',
+      schema: z.object({
+        success: z.literal(true),
+        totalCollected: z.number(),
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, the session is empty, or the vault shift is not active'
+    ),
+    401: errors.unauthorized,
+    404: {
+      description: 'No such active session',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+// ============================================================================
+// End of Day
+// ============================================================================
+
+const endOfDayQuery = z.object({
+  locationId: params.requiredLocationId,
+  date: params.isoDate.describe('Day of the report'),
+});
+
+const endOfDayReportSchema = typedObject(
+  'EndOfDayReport (helpers/vault/endOfDay)'
+);
+
+const locationForbidden = {
+  description: 'The caller cannot manage this location',
+  schema: errorResponseSchema,
+};
+
+export const getEndOfDayReportRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/end-of-day',
+  tag: 'Vault',
+  summary: 'End-of-day report',
+  query: endOfDayQuery,
+  responses: {
+    200: { description: 'Report', schema: dataResponse(endOfDayReportSchema) },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: locationForbidden,
+  },
+});
+
+export const exportEndOfDayReportRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/end-of-day',
+  tag: 'Vault',
+  summary: 'Export the end-of-day report',
+  body: endOfDayQuery.extend({
+    format: z
+      .enum(['CSV', 'JSON'])
+      .default('CSV')
+      .describe('CSV returns a download instead of JSON'),
+  }),
+  responses: {
+    200: {
+      description: 'The report as JSON, or a text/csv download',
+      schema: dataResponse(endOfDayReportSchema),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: locationForbidden,
+  },
+});
+
+// ============================================================================
+// Float Requests
+// ============================================================================
+
+const floatRequestSchema = typedObject('FloatRequest (shared/types/vault)');
+
+const floatRequestNotFound = {
+  description: 'Float request not found',
+  schema: errorResponseSchema,
+};
+
+export const listFloatRequestsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/float-request',
+  tag: 'Vault',
+  summary: 'List float requests',
+  description: 'Cashiers only see their own requests.',
+  query: z.object({
+    status: z.enum([...FLOAT_REQUEST_STATUSES, 'all']).default('pending'),
+    locationId: params.locationId,
+    cashierId: z
+      .string()
+      .optional()
+      .describe('Vault managers, managers, admins and developers only'),
+    startDate: params.isoDate.optional().describe('Requested on or after'),
+    endDate: params.isoDate.optional().describe('Requested on or before'),
+    page: params.page.default(1),
+    limit: params.limit.default(20).describe('Rows per page. At most 100'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of requests',
+      schema: z.object({
+        success: z.literal(true),
+        data: z.array(
+          typedObject(
+            'FloatRequest (shared/types/vault) with the cashier and processor'
+          )
+        ),
+        pagination: paginationSchema,
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const createFloatRequestRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/float-request',
+  tag: 'Vault',
+  summary: 'Ask the vault for more float, or to take some back',
+  description:
+    "Sent to the vault manager of the location's active vault shift.",
+  body: z.object({
+    type: z.enum(['increase', 'decrease']),
+    amount: z.number().positive(),
+    denominations: denominationsSchema.default([]),
+    reason: z.string().optional(),
+    locationId: z.string().min(1),
+    cashierShiftId: z.string().min(1),
+  }),
+  responses: {
+    200: {
+      description: 'Submitted',
+      schema: messageResponseSchema.extend({
+        floatRequest: floatRequestSchema,
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, or the location has no active vault shift'
+    ),
+    401: errors.unauthorized,
+  },
+});
+
+export const cancelFloatRequestRoute = defineRoute({
+  method: 'DELETE',
+  path: '/api/vault/float-request',
+  tag: 'Vault',
+  summary: 'Withdraw a float request',
+  description: 'Only requests not yet confirmed can be withdrawn.',
+  body: z.object({ requestId: z.string().min(1) }),
+  responses: {
+    200: { description: 'Withdrawn', schema: messageResponseSchema },
+    400: invalidRequest('Validation failed, or the request is already settled'),
+    401: errors.unauthorized,
+    403: {
+      description: 'Neither the requesting cashier nor a vault manager',
+      schema: errorResponseSchema,
+    },
+    404: floatRequestNotFound,
+  },
+});
+
+export const approveFloatRequestRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/float-request/approve',
+  tag: 'Vault',
+  summary: 'Approve, edit or deny a float request',
+  description:
+    'An approved increase waits for the cashier to confirm receipt; an approved decrease is settled immediately. Denying a request to open a shift cancels the shift.',
+  body: z.object({
+    requestId: z.string().min(1),
+    status: z.enum(['approved', 'edited', 'denied']),
+    approvedAmount: z.number().min(0).optional().describe('Required to edit'),
+    approvedDenominations: denominationsSchema
+      .optional()
+      .describe('Required to edit; must add up to approvedAmount'),
+    vmNotes: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Processed',
+      schema: z.object({
+        success: z.literal(true),
+        status: z.enum(['approved', 'approved_vm', 'denied']),
+        floatRequest: floatRequestSchema,
+        cashierShift: cashierShiftSchema
+          .optional()
+          .describe('Approved decreases only'),
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, the request is not pending, the edit does not add up, or the vault cannot cover it'
+    ),
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+    404: {
+      description: 'Float request or vault shift not found',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const confirmFloatRequestRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/float-request/confirm',
+  tag: 'Vault',
+  summary: 'Confirm an approved float request',
+  description:
+    'The cashier confirms receipt of an increase; a vault manager confirms a decrease. Moves the cash between the vault and the shift.',
+  body: z.object({
+    requestId: z.string().min(1),
+    notes: z.string().optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Settled',
+      schema: z.object({
+        success: z.literal(true),
+        floatRequest: floatRequestSchema,
+        cashierShift: cashierShiftSchema,
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, or the request is not waiting for confirmation'
+    ),
+    401: errors.unauthorized,
+    403: {
+      description: 'The caller cannot confirm this request',
+      schema: errorResponseSchema,
+    },
+    404: floatRequestNotFound,
+  },
+});
+
+/** The older float request records, kept apart from `FloatRequest` above */
+const legacyFloatRequestResponse = dataResponse(
+  z.object({
+    floatRequest: typedObject('FloatRequestDocument (shared/types/models)'),
+  })
+);
+
+export const getLegacyFloatRequestRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/float-requests/{id}',
+  tag: 'Vault',
+  summary: 'Get a float request record',
+  description: 'Reads the older float request records.',
+  responses: {
+    200: { description: 'Float request', schema: legacyFloatRequestResponse },
+    401: errors.unauthorized,
+    404: floatRequestNotFound,
+  },
+});
+
+export const editLegacyFloatRequestRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/vault/float-requests/{id}',
+  tag: 'Vault',
+  summary: 'Change the denominations of a float request record',
+  description: 'Edits the older float request records.',
+  body: z.object({ requestedDenom: denominationsSchema }),
+  responses: {
+    200: { description: 'Updated', schema: legacyFloatRequestResponse },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description: 'The caller cannot edit this request',
+      schema: errorResponseSchema,
+    },
+    404: floatRequestNotFound,
+  },
+});
+
+// ============================================================================
+// Inter-Location Transfers
+// ============================================================================
+
+const transferSchema = typedObject(
+  'InterLocationTransfer (shared/types/vault)'
+);
+
+const transferForbidden = {
+  description:
+    'Not an admin, manager or developer, or not assigned to the locations',
+  schema: errorResponseSchema,
+};
+
+export const listTransfersRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/transfers',
+  tag: 'Vault',
+  summary: "List a location's transfers",
+  description: 'Sent and received, newest first.',
+  query: z.object({
+    locationId: params.requiredLocationId,
+    page: params.page.default(1),
+    limit: params.limit.default(20).describe('Rows per page. At most 100'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of transfers',
+      schema: z.object({
+        success: z.literal(true),
+        transfers: z.array(transferSchema),
+        pagination: paginationSchema,
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description: 'The caller is not assigned to the location',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const createTransferRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/transfers',
+  tag: 'Vault',
+  summary: 'Request a transfer between location vaults',
+  description: 'The transfer waits for approval.',
+  body: z
+    .object({
+      fromLocationId: z.string().min(1),
+      toLocationId: z.string().min(1),
+      amount: z.number().positive(),
+      denominations: denominationsSchema,
+      notes: z.string().optional(),
+    })
+    .refine(body => body.fromLocationId !== body.toLocationId, {
+      message: 'must differ from fromLocationId',
+      path: ['toLocationId'],
+    }),
+  responses: {
+    200: {
+      description: 'Requested',
+      schema: z.object({
+        success: z.literal(true),
+        transfer: transferSchema,
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: transferForbidden,
+  },
+});
+
+export const approveTransferRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/transfers/approve',
+  tag: 'Vault',
+  summary: 'Approve or deny a transfer',
+  description:
+    "An approved transfer is added to the destination's active vault shift.",
+  body: z.object({
+    transferId: z.string().min(1),
+    approved: z.boolean(),
+    notes: z.string().optional().describe('Appended to the transfer notes'),
+  }),
+  responses: {
+    200: {
+      description: 'Processed',
+      schema: z.object({
+        success: z.literal(true),
+        transfer: transferSchema,
+        transaction: vaultTransactionSchema
+          .optional()
+          .describe('Approved transfers only'),
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, the transfer is not pending, or the destination has no active vault shift'
+    ),
+    401: errors.unauthorized,
+    403: {
+      description: 'Admin, manager and developer roles only',
+      schema: errorResponseSchema,
+    },
+    404: { description: 'Transfer not found', schema: errorResponseSchema },
+  },
+});
+
+// ============================================================================
+// Cashier Shifts
+// ============================================================================
+
+export const currentCashierShiftRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cashier/shift/current',
+  tag: 'Cashier',
+  summary: "The caller's open or pending shift",
+  description:
+    "Also reports whether the cashier's location has an active, reconciled vault shift. Without a shift only those flags are returned.",
+  responses: {
+    200: {
+      description: 'Shift state',
+      schema: z.object({
+        success: z.literal(true),
+        shift: cashierShiftSchema.nullable(),
+        currentBalance: z
+          .number()
+          .optional()
+          .describe('Active shifts only; 0 otherwise'),
+        status: z.string().optional(),
+        hasActiveVaultShift: z.boolean(),
+        isVaultReconciled: z.boolean(),
+        isStale: z
+          .boolean()
+          .optional()
+          .describe('Opened before the current gaming day'),
+        pendingVmApproval: typedObject('FloatRequest (shared/types/vault)')
+          .nullable()
+          .optional()
+          .describe('Float movement approved by the vault manager'),
+        pendingRequest: typedObject('FloatRequest (shared/types/vault)')
+          .nullable()
+          .optional(),
+      }),
+    },
+    401: errors.unauthorized,
+  },
+});
+
+export const listCashierShiftsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cashier/shifts',
+  tag: 'Cashier',
+  summary: 'List cashier shifts',
+  description:
+    'Newest first, with the cashier and location names. Cashiers only see their own shifts.',
+  query: z.object({
+    status: z
+      .string()
+      .optional()
+      .describe('Shift status, or comma-separated statuses'),
+    locationId: params.locationId,
+    cashierId: z
+      .string()
+      .optional()
+      .describe('Vault managers, managers, admins and developers only'),
+    limit: z.coerce.number().int().min(1).default(20),
+    startDate: params.isoDate.optional().describe('Created on or after'),
+    endDate: params.isoDate.optional().describe('Created on or before'),
+  }),
+  responses: {
+    200: {
+      description: 'Shifts',
+      schema: z.object({
+        success: z.literal(true),
+        shifts: z.array(
+          typedObject(
+            'CashierShift (shared/types/vault) with cashierName, cashierUsername and locationName'
+          )
+        ),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const openCashierShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cashier/shift/open',
+  tag: 'Cashier',
+  summary: 'Request a float and open a shift',
+  description:
+    'The shift waits for the vault manager to approve the float. The location needs an active, reconciled vault shift and must be assigned to the cashier.',
+  body: z.object({
+    locationId: z.string().min(1),
+    requestedFloat: z.number().min(0),
+    denominations: denominationsSchema.describe(
+      'Must add up to requestedFloat'
+    ),
+  }),
+  responses: {
+    201: {
+      description: 'Shift pending approval',
+      schema: messageResponseSchema.extend({
+        shift: cashierShiftSchema,
+        floatRequest: typedObject('FloatRequest (shared/types/vault)'),
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, the denominations are wrong, or the location has no active vault shift'
+    ),
+    401: errors.unauthorized,
+    403: {
+      description:
+        'Not a cashier, the vault is not reconciled, or the location is not assigned',
+      schema: errorResponseSchema,
+    },
+    409: {
+      description: 'The cashier already has an open or pending shift',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const closeCashierShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cashier/shift/close',
+  tag: 'Cashier',
+  summary: 'Submit the closing count',
+  description:
+    'Blind close: the shift always goes to the vault manager for review and the expected balance is not returned.',
+  body: z.object({
+    shiftId: z.string().min(1),
+    physicalCount: z.number().min(0),
+    denominations: denominationsSchema.describe(
+      'Must add up to physicalCount'
+    ),
+  }),
+  responses: {
+    200: {
+      description: 'Submitted for review',
+      schema: messageResponseSchema.extend({
+        status: z.literal('pending_review'),
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, the denominations are wrong, or the shift is not active'
+    ),
+    401: errors.unauthorized,
+    403: {
+      description: "Not a cashier, or not the caller's shift",
+      schema: errorResponseSchema,
+    },
+    404: shiftNotFound,
+  },
+});
+
+export const cancelCashierShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cashier/shift/cancel',
+  tag: 'Cashier',
+  summary: "Withdraw the caller's pending request",
+  description:
+    'A shift waiting to open is cancelled with its float request; a shift waiting for review goes back to active.',
+  responses: {
+    200: { description: 'Withdrawn', schema: messageResponseSchema },
+    401: errors.unauthorized,
+    404: {
+      description: 'No shift waiting to open or for review',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const resolveCashierShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cashier/shift/resolve',
+  tag: 'Cashier',
+  summary: 'Accept a closing count and close the shift',
+  description:
+    'The final balance is returned to the vault; it may differ from the count submitted by the cashier.',
+  body: z.object({
+    shiftId: z.string().min(1),
+    finalBalance: z.number().min(0),
+    auditComment: z
+      .string()
+      .default('')
+      .describe('Expected whenever the balance is overridden'),
+    denominations: denominationsSchema
+      .optional()
+      .describe('Replace the counted denominations'),
+  }),
+  responses: {
+    200: {
+      description: 'Closed',
+      schema: messageResponseSchema.extend({ shift: cashierShiftSchema }),
+    },
+    400: invalidRequest('Validation failed, or the shift is not under review'),
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+    404: shiftNotFound,
+  },
+});
+
+export const rejectCashierShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cashier/shift/reject',
+  tag: 'Cashier',
+  summary: 'Send a closing count back for a recount',
+  description: 'The shift goes back to active.',
+  body: z.object({
+    shiftId: z.string().min(1),
+    reason: z.string().default(''),
+  }),
+  responses: {
+    200: {
+      description: 'Back to active',
+      schema: messageResponseSchema.extend({ shift: cashierShiftSchema }),
+    },
+    400: invalidRequest('Validation failed, or the shift is not under review'),
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+    404: shiftNotFound,
+  },
+});
+
+export const cashierShiftHistoryRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/cashier-shift/history',
+  tag: 'Vault',
+  summary: "A cashier's shift history",
+  description: 'Newest first. Cashiers can only read their own.',
+  query: z.object({
+    cashierId: z.string().min(1),
+    locationId: params.locationId,
+    limit: params.limit.default(50),
+    skip: z.coerce.number().int().min(0).default(0),
+    variance: params.flag.describe('Only shifts with a discrepancy'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of shifts',
+      schema: z.object({
+        success: z.literal(true),
+        shifts: z.array(cashierShiftSchema),
+        total: z.number(),
+        pagination: z.object({
+          total: z.number(),
+          limit: z.number(),
+          skip: z.number(),
+          hasMore: z.boolean(),
+        }),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description: "A cashier asked for another cashier's shifts",
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const directOpenCashierShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/cashier-shift/direct-open',
+  tag: 'Vault',
+  summary: 'Open a shift for a cashier',
+  description:
+    'Funds the shift from the reconciled vault shift at once, recording an approved float request, instead of waiting for the cashier to ask.',
+  body: z.object({
+    locationId: z.string().min(1),
+    cashierId: z.string().min(1),
+    amount: z.number().positive(),
+    denominations: denominationsSchema.describe('Must add up to amount'),
+    notes: z.string().optional(),
+  }),
+  responses: {
+    201: {
+      description: 'Opened',
+      schema: messageResponseSchema.extend({ shift: cashierShiftSchema }),
+    },
+    400: invalidRequest(
+      'Validation failed, no active vault shift, or the vault cannot cover it'
+    ),
+    401: errors.unauthorized,
+    403: {
+      description: 'Role not allowed, or the vault shift is not reconciled',
+      schema: errorResponseSchema,
+    },
+    409: {
+      description: 'The cashier already has an open or pending shift',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const forceCloseCashierShiftRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/cashier-shift/force-close',
+  tag: 'Vault',
+  summary: "Close a cashier's shift for them",
+  description:
+    'The shift goes to pending_review with the discrepancy against the count.',
+  body: z
+    .object({
+      locationId: z.string().min(1),
+      shiftId: z.string().optional(),
+      cashierId: z
+        .string()
+        .optional()
+        .describe("Closes the cashier's open shift at the location"),
+      physicalCount: z.number().min(0),
+      denominations: denominationsSchema.optional(),
+      notes: z.string().optional(),
+    })
+    .refine(body => body.shiftId || body.cashierId, {
+      message: 'shiftId or cashierId is required',
+      path: ['shiftId'],
+    }),
+  responses: {
+    200: { description: 'Closed', schema: messageResponseSchema },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+    404: { description: 'No open shift', schema: errorResponseSchema },
+  },
+});
+
+// ============================================================================
+// Payouts
+// ============================================================================
+
+export const listPayoutsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/cashier/payout',
+  tag: 'Cashier',
+  summary: 'List payouts',
+  description: 'Newest first. Cashiers only see their own payouts.',
+  query: z.object({
+    cashierShiftId: z.string().optional(),
+    cashierId: z
+      .string()
+      .optional()
+      .describe('Vault managers, managers, admins and developers only'),
+    limit: z.coerce.number().int().min(1).default(20),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+  }),
+  responses: {
+    200: {
+      description: 'Payouts',
+      schema: z.object({
+        success: z.literal(true),
+        payouts: z.array(typedObject('PayoutDocument (shared/types)')),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const createPayoutRoute = defineRoute({
+  method: 'POST',
+  path: '/api/cashier/payout',
+  tag: 'Cashier',
+  summary: 'Redeem a ticket or pay a hand pay',
+  description:
+    "Paid from the caller's active shift, which must hold the amount. The vault shift must be reconciled.",
+  body: z
+    .object({
+      cashierShiftId: z.string().min(1),
+      type: z.enum(['ticket', 'hand_pay']),
+      amount: z.number().positive(),
+      ticketNumber: z.string().optional().describe('Required for a ticket'),
+      printedAt: params.isoDate.optional().describe('Ticket print time'),
+      machineId: z.string().optional().describe('Required for a hand pay'),
+      reason: z.string().optional().describe('Hand pay reason'),
+      notes: z.string().optional(),
+    })
+    .refine(body => body.type !== 'ticket' || body.ticketNumber, {
+      message: 'required for a ticket',
+      path: ['ticketNumber'],
+    })
+    .refine(body => body.type !== 'hand_pay' || body.machineId, {
+      message: 'required for a hand pay',
+      path: ['machineId'],
+    }),
+  responses: {
+    201: {
+      description: 'Paid',
+      schema: z.object({
+        success: z.literal(true),
+        payout: typedObject('PayoutDocument (shared/types)'),
+        newBalance: z.number().describe('Shift float after the payout'),
+      }),
+    },
+    400: invalidRequest('Validation failed, or the float is too low'),
+    401: errors.unauthorized,
+    403: vaultNotReconciled,
+    404: {
+      description: 'The caller has no such active shift',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+// ============================================================================
+// Vault Payouts
+// ============================================================================
+
+const payoutSchema = typedObject('PayoutDocument (shared/types)');
+
+const cashDeskPayoutResponse = dataResponse(
+  z.object({ payout: payoutSchema })
+);
+
+const payoutNotFound = {
+  description: 'Payout not found',
+  schema: errorResponseSchema,
+};
+
+export const createVaultPayoutRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/payout',
+  tag: 'Vault',
+  summary: 'Redeem a ticket or pay a hand pay',
+  description:
+    "Paid from the caller's active shift, which must hold the amount. Developer, admin, manager and cashier roles only.",
+  body: z.object({
+    cashierShiftId: z.string().min(1),
+    type: z.enum(['ticket', 'hand_pay']),
+    amount: z.number().positive(),
+    ticketNumber: z.string().optional().describe('Ticket payouts'),
+    printedAt: params.isoDate.optional().describe('Ticket print time'),
+    machineId: z.string().optional().describe('Hand pays'),
+    reason: z.string().optional().describe('Hand pay reason'),
+    notes: z.string().optional(),
+  }),
+  responses: {
+    201: {
+      description: 'Paid',
+      schema: z.object({
+        success: z.literal(true),
+        payout: payoutSchema,
+        transaction: vaultTransactionSchema,
+      }),
+    },
+    400: invalidRequest('Validation failed, or the float is too low'),
+    401: errors.unauthorized,
+    403: {
+      description:
+        'Role not allowed, or the shift belongs to another cashier or location',
+      schema: errorResponseSchema,
+    },
+    404: {
+      description: 'No such active shift',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const listVaultPayoutsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/payouts',
+  tag: 'Vault',
+  summary: "List a location's payouts",
+  description:
+    'Newest first, with the cashier name. Vault manager, manager, admin, developer and cashier roles only.',
+  query: z.object({
+    locationId: params.requiredLocationId,
+    page: params.page.default(1),
+    limit: params.limit.default(20).describe('Rows per page. At most 100'),
+    search: params.search.describe('Ticket number, notes, cashier or amount'),
+    type: z.enum(['all', 'ticket', 'hand_pay']).default('all'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of payouts',
+      schema: z.object({
+        success: z.literal(true),
+        payouts: z.array(payoutSchema),
+        pagination: paginationSchema,
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: vaultManagerOnly,
+  },
+});
+
+export const getCashDeskPayoutRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/payouts/{id}',
+  tag: 'Vault',
+  summary: 'Get a cash desk payout',
+  description: 'Reads the older cashdeskpayouts collection.',
+  responses: {
+    200: { description: 'Payout', schema: cashDeskPayoutResponse },
+    401: errors.unauthorized,
+    404: payoutNotFound,
+  },
+});
+
+export const updateCashDeskPayoutRoute = defineRoute({
+  method: 'PUT',
+  path: '/api/vault/payouts/{id}',
+  tag: 'Vault',
+  summary: 'Edit a cash desk payout',
+  description: 'Only the fields sent are changed.',
+  body: z.object({
+    amount: z.number().min(0).optional(),
+    notes: z.string().optional(),
+    status: z.string().optional(),
+  }),
+  responses: {
+    200: { description: 'Updated', schema: cashDeskPayoutResponse },
+    400: errors.validation,
+    401: errors.unauthorized,
+    404: payoutNotFound,
+  },
+});
+
+// ============================================================================
+// Transactions
+// ============================================================================
+
+export const listVaultTransactionsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/transactions',
+  tag: 'Vault',
+  summary: 'List vault transactions',
+  description:
+    'Newest first, with performer, cashier and machine names. Across locations, each row also has its location name.',
+  query: z.object({
+    locationId: z
+      .string()
+      .optional()
+      .describe('A location, or all (default) for every location allowed'),
+    page: params.page.default(1),
+    limit: params.limit.default(20).describe('Rows per page. At most 100'),
+    type: z
+      .string()
+      .optional()
+      .describe('Transaction type, a comma-separated list of them, or all'),
+    status: z
+      .string()
+      .optional()
+      .describe('voided, or any other value but all for the rest'),
+    search: params.search.describe('Notes, audit comment, performer or amount'),
+  }),
+  responses: {
+    200: {
+      description: 'One page of transactions',
+      schema: z.object({
+        success: z.literal(true),
+        items: z.array(vaultTransactionSchema),
+        total: z.number(),
+        pagination: paginationSchema,
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: {
+      description:
+        'Vault manager, manager, admin and developer roles only, or the location is not allowed',
+      schema: errorResponseSchema,
+    },
+  },
+});
+
+export const vaultActivityLogRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/activity-log',
+  tag: 'Vault',
+  summary: 'Vault transactions by person, machine or shift',
+  description:
+    'Newest first, with the performer name. Users other than vault managers, managers, admins and developers only see their own.',
+  query: z.object({
+    locationId: params.locationId,
+    userId: z.string().optional().describe('Performed by'),
+    cashierId: z
+      .string()
+      .optional()
+      .describe('Performed by; wins over userId'),
+    machineId: z.string().optional().describe('From or to the machine'),
+    cashierShiftId: z.string().optional(),
+    type: z.string().optional().describe('Transaction type'),
+    startDate: params.isoDate.optional(),
+    endDate: params.isoDate.optional(),
+    limit: params.limit.default(50),
+    skip: z.coerce.number().int().min(0).default(0),
+  }),
+  responses: {
+    200: {
+      description: 'One page of transactions',
+      schema: z.object({
+        success: z.literal(true),
+        activities: z.array(vaultTransactionSchema),
+        totalCount: z.number(),
+        pagination: z.object({
+          total: z.number(),
+          limit: z.number(),
+          skip: z.number(),
+          hasMore: z.boolean(),
+        }),
+      }),
+    },
+    400: invalidRequest(
+      'Validation failed, or none of locationId, userId, cashierId, machineId and cashierShiftId was given'
+    ),
+    401: errors.unauthorized,
+  },
+});
+
+// ============================================================================
+// Metrics & Overview
+// ============================================================================
+
+/** Gaming day range, from the location's gaming day start */
+const gamingDayRangeQuery = {
+  timePeriod: params.timePeriod('Today'),
+  startDate: params.isoDate.optional().describe('Custom only'),
+  endDate: params.isoDate.optional().describe('Custom only'),
+};
+
+const locationDenied = {
+  description: 'The location is not allowed',
+  schema: errorResponseSchema,
+};
+
+export const vaultMetricsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/metrics',
+  tag: 'Vault',
+  summary: "A location's vault cash flow",
+  description:
+    'Vault opens and reconciliations are left out of cash in and out.',
+  query: z.object({
+    locationId: params.requiredLocationId,
+    ...gamingDayRangeQuery,
+  }),
+  responses: {
+    200: {
+      description: 'Metrics over the range',
+      schema: z.object({
+        success: z.literal(true),
+        metrics: z.object({
+          totalCashIn: z.number(),
+          totalCashOut: z.number(),
+          netCashFlow: z.number(),
+          payouts: z.number(),
+          payoutsCount: z.number(),
+          totalMachineBalance: z.number().describe('Machine drop'),
+          totalCashierFloats: z.number(),
+          expenses: z.number(),
+        }),
+        rangeStart: z.string(),
+        rangeEnd: z.string(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: locationDenied,
+  },
+});
+
+export const vaultMetricsBreakdownRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/metrics/breakdown',
+  tag: 'Vault',
+  summary: 'The transactions behind a vault metric',
+  description: 'Newest first; voided transactions are left out.',
+  query: z.object({
+    locationId: params.requiredLocationId,
+    type: z.enum(['in', 'out', 'payout']),
+    ...gamingDayRangeQuery,
+  }),
+  responses: {
+    200: {
+      description: 'Transactions',
+      schema: dataResponse(z.array(vaultTransactionSchema)),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: locationDenied,
+  },
+});
+
+export const globalVaultOverviewRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/overview/global',
+  tag: 'Vault',
+  summary: 'Vault overview across locations',
+  description:
+    'Every membership location, or those of one licencee. Without any, the totals are zero and the range is left out.',
+  query: z.object({
+    licenceeId: params.licencee.describe('Licencee id, or all'),
+    licencee: params.licencee.describe('Alias of licenceeId'),
+    ...gamingDayRangeQuery,
+  }),
+  responses: {
+    200: {
+      description: 'Overview',
+      schema: dataResponse(
+        z.object({
+          vaultBalance: z.object({
+            balance: z.number(),
+            denominations: denominationsSchema,
+            totalCashOnPremises: z.number().optional(),
+            machineMoneyIn: z.number().optional(),
+            cashierFloats: z.number().optional(),
+          }),
+          metrics: z.object({
+            totalCashIn: z.number(),
+            totalCashOut: z.number(),
+            netCashFlow: z.number(),
+            payouts: z.number().optional(),
+            payoutsCount: z.number().optional(),
+            discrepancies: z.number(),
+            pendingReviews: z.number(),
+          }),
+          transactions: z
+            .array(vaultTransactionSchema)
+            .describe('The latest 20, whatever the range'),
+          pendingShifts: z.array(cashierShiftSchema),
+          floatRequests: z.array(floatRequestSchema),
+          cashDesks: z.array(typedObject('CashDesk (shared/types/vault)')),
+          rangeStart: z.string().optional(),
+          rangeEnd: z.string().optional(),
+        })
+      ),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+    403: adminOnly,
+  },
+});
+
+// ============================================================================
+// Notifications
+// ============================================================================
+
+export const listVaultNotificationsRoute = defineRoute({
+  method: 'GET',
+  path: '/api/vault/notifications',
+  tag: 'Vault',
+  summary: "The caller's vault notifications",
+  description: 'Recent notifications at the location, with unread counts.',
+  query: z.object({ locationId: params.requiredLocationId }),
+  responses: {
+    200: {
+      description: 'Notifications',
+      schema: z.object({
+        success: z.literal(true),
+        notifications: z.array(
+          typedObject('IVaultNotification (models/vaultNotification)')
+        ),
+        unreadCount: z.number(),
+        pendingFloatRequests: z.number(),
+        pendingShiftReviews: z.number(),
+      }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
+
+export const updateVaultNotificationsRoute = defineRoute({
+  method: 'POST',
+  path: '/api/vault/notifications',
+  tag: 'Vault',
+  summary: 'Mark notifications read, or dismiss them',
+  description: 'Dismissing hides them from the caller only.',
+  body: z.object({
+    action: z.enum(['mark_read', 'dismiss']),
+    notificationIds: z.array(z.string()),
+  }),
+  responses: {
+    200: {
+      description: 'Done',
+      schema: z.object({ success: z.literal(true) }),
+    },
+    400: errors.validation,
+    401: errors.unauthorized,
+  },
+});
@@ -0,0 +1,77 @@
+/**
+ * Financial Formula Tests
+ *
+ * Covers how calculateFinancialMetrics composes Money Out, Gross and Net
+ * Gross, in particular that the jackpot is taken off Net Gross exactly once
+ * whether or not the licencee includes it in Money Out.
+ */
+
+import {
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+} from '../financialFormulas';
+
+const totals = { drop: 1000, totalCancelledCredits: 300, jackpot: 50 };
+
+describe('calculateFinancialMetrics', () => {
+  it('leaves the jackpot out of Money Out when includeJackpot is off', () => {
+    expect(calculateFinancialMetrics(totals)).toEqual({
+      moneyIn: 1000,
+      moneyOut: 300,
+      jackpot: 50,
+      gross: 700,
+      netGross: 650,
+    });
+  });
+
+  it('subtracts the jackpot once when includeJackpot is on', () => {
+    expect(
+      calculateFinancialMetrics(totals, {
+        ...DEFAULT_FINANCIAL_FORMULA,
+        includeJackpot: true,
+      })
+    ).toEqual({
+      moneyIn: 1000,
+      moneyOut: 350,
+      jackpot: 50,
+      gross: 650,
+      netGross: 650,
+    });
+  });
+
+  it('treats jackpot in the Money Out fields like includeJackpot', () => {
+    const metrics = calculateFinancialMetrics(totals, {
+      ...DEFAULT_FINANCIAL_FORMULA,
+      moneyOutFields: ['totalCancelledCredits', 'jackpot'],
+    });
+
+    expect(metrics.moneyOut).toBe(350);
+    expect(metrics.netGross).toBe(650);
+  });
+
+  it('applies reviewer scales before composing', () => {
+    const metrics = calculateFinancialMetrics(
+      totals,
+      { ...DEFAULT_FINANCIAL_FORMULA, includeJackpot: true },
+      { moneyInScale: 0.5, moneyOutScale: 0.5 }
+    );
+
+    expect(metrics).toEqual({
+      moneyIn: 500,
+      moneyOut: 175,
+      jackpot: 25,
+      gross: 325,
+      netGross: 325,
+    });
+  });
+
+  it('treats missing fields as zero', () => {
+    expect(calculateFinancialMetrics({})).toEqual({
+      moneyIn: 0,
+      moneyOut: 0,
+      jackpot: 0,
+      gross: 0,
+      netGross: 0,
+    });
+  });
+});
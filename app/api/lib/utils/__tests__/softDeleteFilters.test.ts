@@ -0,0 +1,30 @@
+/**
+ * Soft Delete Filter Tests
+ *
+ * Covers that isDeleted agrees with DELETED_FILTER: only a Date from the
+ * cutoff on counts as deleted, so a stored string or number never does.
+ */
+
+import { DELETED_AT_CUTOFF, isDeleted } from '../softDeleteFilters';
+
+describe('isDeleted', () => {
+  it('counts dates from the cutoff on as deleted', () => {
+    expect(isDeleted(DELETED_AT_CUTOFF)).toBe(true);
+    expect(isDeleted(new Date('2025-06-01T00:00:00.000Z'))).toBe(true);
+  });
+
+  it('treats legacy sentinels and earlier dates as not deleted', () => {
+    expect(isDeleted(new Date(-1))).toBe(false);
+    expect(isDeleted(new Date('2024-12-31T23:59:59.999Z'))).toBe(false);
+    expect(isDeleted(-1)).toBe(false);
+  });
+
+  it('treats null and a missing value as not deleted', () => {
+    expect(isDeleted(null)).toBe(false);
+    expect(isDeleted(undefined)).toBe(false);
+  });
+
+  it('treats strings as not deleted, like the $gte filters', () => {
+    expect(isDeleted('2025-06-01T00:00:00.000Z')).toBe(false);
+  });
+});
@@ -0,0 +1,222 @@
+/**
+ * Tenant Scope Tests
+ *
+ * Covers the licencee and location narrowing applied in tenant isolation
+ * mode (TENANT_LICENCEE_ID / --tenant), the licencee ids checked in JSON
+ * request bodies, and that the helpers are no-ops when the mode is off.
+ */
+
+// Shared across the isolated module registries loadTenantScope() creates
+const mockFind = jest.fn();
+
+jest.mock('@/app/api/lib/models/gaminglocations', () => ({
+  GamingLocations: {
+    find: (filter: unknown, projection: unknown) =>
+      mockFind(filter, projection),
+  },
+}));
+
+/** Fresh module per test so the cached tenant locations never leak */
+async function loadTenantScope() {
+  let scope: typeof import('../tenantScope') | undefined;
+  await jest.isolateModulesAsync(async () => {
+    scope = await import('../tenantScope');
+  });
+  return scope as typeof import('../tenantScope');
+}
+
+function mockTenantLocations(ids: string[]) {
+  mockFind.mockReturnValue({
+    lean: jest.fn().mockResolvedValue(ids.map(_id => ({ _id }))),
+  });
+}
+
+const originalArgv = process.argv;
+
+beforeEach(() => {
+  delete process.env.TENANT_LICENCEE_ID;
+  process.argv = [...originalArgv];
+  mockFind.mockReset();
+});
+
+afterAll(() => {
+  delete process.env.TENANT_LICENCEE_ID;
+  process.argv = originalArgv;
+});
+
+describe('scopeLicenceesToTenant', () => {
+  it('returns the accessible licencees unchanged when the mode is off', async () => {
+    const { scopeLicenceesToTenant } = await loadTenantScope();
+
+    expect(scopeLicenceesToTenant('all')).toBe('all');
+    expect(scopeLicenceesToTenant(['lic-a', 'lic-b'])).toEqual([
+      'lic-a',
+      'lic-b',
+    ]);
+  });
+
+  it("narrows an admin's 'all' to the pinned licencee", async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    const { scopeLicenceesToTenant } = await loadTenantScope();
+
+    expect(scopeLicenceesToTenant('all')).toEqual(['lic-a']);
+  });
+
+  it('keeps only the pinned licencee from an assigned list', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    const { scopeLicenceesToTenant } = await loadTenantScope();
+
+    expect(scopeLicenceesToTenant(['lic-b', 'lic-a', 'lic-c'])).toEqual([
+      'lic-a',
+    ]);
+  });
+
+  it('returns no licencees when the user is not assigned the tenant', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    const { scopeLicenceesToTenant } = await loadTenantScope();
+
+    expect(scopeLicenceesToTenant(['lic-b'])).toEqual([]);
+    expect(scopeLicenceesToTenant([])).toEqual([]);
+  });
+
+  it('takes --tenant over TENANT_LICENCEE_ID', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    process.argv = [...originalArgv, '--tenant', 'lic-b'];
+    const { scopeLicenceesToTenant } = await loadTenantScope();
+
+    expect(scopeLicenceesToTenant('all')).toEqual(['lic-b']);
+  });
+
+  it('ignores a blank TENANT_LICENCEE_ID', async () => {
+    process.env.TENANT_LICENCEE_ID = '  ';
+    const { scopeLicenceesToTenant } = await loadTenantScope();
+
+    expect(scopeLicenceesToTenant('all')).toBe('all');
+  });
+});
+
+describe('restrictLocationsToTenant', () => {
+  it('returns the filter unchanged without querying when the mode is off', async () => {
+    const { restrictLocationsToTenant } = await loadTenantScope();
+
+    await expect(restrictLocationsToTenant('all')).resolves.toBe('all');
+    await expect(
+      restrictLocationsToTenant(['loc-1', 'loc-9'])
+    ).resolves.toEqual(['loc-1', 'loc-9']);
+    expect(mockFind).not.toHaveBeenCalled();
+  });
+
+  it("replaces 'all' with the tenant's locations", async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    mockTenantLocations(['loc-1', 'loc-2']);
+    const { restrictLocationsToTenant } = await loadTenantScope();
+
+    await expect(restrictLocationsToTenant('all')).resolves.toEqual([
+      'loc-1',
+      'loc-2',
+    ]);
+    expect(mockFind).toHaveBeenCalledWith(
+      { 'rel.licencee': 'lic-a' },
+      { _id: 1 }
+    );
+  });
+
+  it('drops locations of other licencees', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    mockTenantLocations(['loc-1', 'loc-2']);
+    const { restrictLocationsToTenant } = await loadTenantScope();
+
+    await expect(
+      restrictLocationsToTenant(['loc-2', 'loc-9', 'loc-1'])
+    ).resolves.toEqual(['loc-2', 'loc-1']);
+    await expect(restrictLocationsToTenant(['loc-9'])).resolves.toEqual([]);
+  });
+
+  it('matches ObjectId-like ids by their string form', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    mockFind.mockReturnValue({
+      lean: jest.fn().mockResolvedValue([{ _id: { toString: () => 'loc-1' } }]),
+    });
+    const { restrictLocationsToTenant } = await loadTenantScope();
+
+    await expect(restrictLocationsToTenant(['loc-1'])).resolves.toEqual([
+      'loc-1',
+    ]);
+  });
+
+  it('caches the tenant locations between calls', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    mockTenantLocations(['loc-1']);
+    const { restrictLocationsToTenant } = await loadTenantScope();
+
+    await restrictLocationsToTenant('all');
+    await restrictLocationsToTenant(['loc-1']);
+    expect(mockFind).toHaveBeenCalledTimes(1);
+  });
+
+  it('returns no locations when the tenant has none', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    mockTenantLocations([]);
+    const { restrictLocationsToTenant } = await loadTenantScope();
+
+    await expect(restrictLocationsToTenant('all')).resolves.toEqual([]);
+    await expect(restrictLocationsToTenant(['loc-1'])).resolves.toEqual([]);
+  });
+});
+
+describe('assertTenantRequestBody', () => {
+  function jsonRequest(body: unknown) {
+    return new Request('http://localhost/api/reports', {
+      method: 'POST',
+      headers: { 'content-type': 'application/json' },
+      body: JSON.stringify(body),
+    });
+  }
+
+  it('accepts any body when the mode is off', async () => {
+    const { assertTenantRequestBody } = await loadTenantScope();
+
+    await expect(
+      assertTenantRequestBody(jsonRequest({ licencee: 'lic-b' }))
+    ).resolves.toBeUndefined();
+  });
+
+  it('rejects a foreign licencee in any licencee field', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    const { assertTenantRequestBody } = await loadTenantScope();
+
+    await expect(
+      assertTenantRequestBody(jsonRequest({ licenceeId: 'lic-b' }))
+    ).rejects.toMatchObject({ statusCode: 403 });
+    await expect(
+      assertTenantRequestBody(jsonRequest({ licencees: ['lic-a', 'lic-b'] }))
+    ).rejects.toMatchObject({ statusCode: 403 });
+    await expect(
+      assertTenantRequestBody(jsonRequest({ licencee: 'lic-a,lic-b' }))
+    ).rejects.toMatchObject({ statusCode: 403 });
+  });
+
+  it('accepts the pinned licencee and leaves the body readable', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    const { assertTenantRequestBody } = await loadTenantScope();
+    const request = jsonRequest({ licencee: 'lic-a', licencees: 'all' });
+
+    await expect(assertTenantRequestBody(request)).resolves.toBeUndefined();
+    await expect(request.json()).resolves.toEqual({
+      licencee: 'lic-a',
+      licencees: 'all',
+    });
+  });
+
+  it('ignores bodies that are not JSON', async () => {
+    process.env.TENANT_LICENCEE_ID = 'lic-a';
+    const { assertTenantRequestBody } = await loadTenantScope();
+    const request = new Request('http://localhost/api/reports', {
+      method: 'POST',
+      headers: { 'content-type': 'text/plain' },
+      body: 'licencee=lic-b',
+    });
+
+    await expect(assertTenantRequestBody(request)).resolves.toBeUndefined();
+  });
+});
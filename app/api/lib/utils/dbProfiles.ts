@@ -0,0 +1,245 @@
+/**
+ * Database Connection Profiles
+ *
+ * Named connection profiles (prod, staging, reporting replica, ...) that bundle
+ * the URI source, database name, read preference and timeouts. URIs come from an
+ * environment variable (`uriEnv`) or a secret reference (`uriSecret`, see
+ * app/api/lib/utils/secrets.ts); a literal `uri` is only accepted when it has no
+ * inline credentials. Profiles live in
+ * `db-profiles.json` at the project root (override with `DB_PROFILES_FILE`); see
+ * `db-profiles.example.json` for the format.
+ *
+ * Selection, shared by the app, API migration routes and `scripts/`:
+ * - `--env <name>` / `--env=<name>` CLI flag (scripts)
+ * - `DB_PROFILE` environment variable (app + scripts)
+ * - Neither set → legacy behaviour (`MONGODB_URI` with default options)
+ *
+ * @module app/api/lib/utils/dbProfiles
+ */
+
+import fs from 'fs';
+import mongoose from 'mongoose';
+import path from 'path';
+import type { ConnectOptions } from 'mongoose';
+import { installCommandQueryControls } from '@/app/api/lib/utils/queryTimeout';
+import {
+  assertNoInlineCredentials,
+  getSecret,
+  resolveSecret,
+} from '@/app/api/lib/utils/secrets';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type DbReadPreference =
+  | 'primary'
+  | 'primaryPreferred'
+  | 'secondary'
+  | 'secondaryPreferred'
+  | 'nearest';
+
+export type DbProfileConfig = {
+  uriEnv?: string;
+  uriSecret?: string;
+  uri?: string;
+  dbName?: string;
+  readPreference?: DbReadPreference;
+  connectTimeoutMS?: number;
+  serverSelectionTimeoutMS?: number;
+  socketTimeoutMS?: number;
+  maxPoolSize?: number;
+  minPoolSize?: number;
+};
+
+export type ResolvedDbProfile = {
+  name: string;
+  uri: string;
+  options: ConnectOptions;
+};
+
+const DEFAULT_PROFILES_FILE = 'db-profiles.json';
+
+const READ_PREFERENCES: DbReadPreference[] = [
+  'primary',
+  'primaryPreferred',
+  'secondary',
+  'secondaryPreferred',
+  'nearest',
+];
+
+export const DEFAULT_CONNECT_OPTIONS: ConnectOptions = {
+  bufferCommands: false,
+  connectTimeoutMS: 30000,
+  serverSelectionTimeoutMS: 30000,
+  socketTimeoutMS: 120000, // 2 minute socket timeout to prevent hanging
+  maxPoolSize: 10, // Limit connection pool size
+  minPoolSize: 2, // Maintain minimum connections
+};
+
+let profilesCache: {
+  file: string;
+  profiles: Record<string, DbProfileConfig>;
+} | null = null;
+
+// ============================================================================
+// Profile Selection
+// ============================================================================
+
+/**
+ * Resolves the selected profile name from `--env` (CLI) or `DB_PROFILE`.
+ *
+ * @param argv - Process arguments (default: process.argv)
+ * @returns Profile name, or null when no profile is selected
+ */
+export function getSelectedDbProfileName(
+  argv: string[] = process.argv
+): string | null {
+  const flagIndex = argv.findIndex(
+    arg => arg === '--env' || arg.startsWith('--env=')
+  );
+  if (flagIndex !== -1) {
+    const flag = argv[flagIndex];
+    const value = flag.includes('=')
+      ? flag.slice(flag.indexOf('=') + 1)
+      : argv[flagIndex + 1];
+    if (value && !value.startsWith('--')) return value;
+  }
+  return process.env.DB_PROFILE || null;
+}
+
+// ============================================================================
+// Profile Loading
+// ============================================================================
+
+/**
+ * Reads and caches the profiles file.
+ */
+export function loadDbProfiles(): Record<string, DbProfileConfig> {
+  const file = path.resolve(
+    process.cwd(),
+    process.env.DB_PROFILES_FILE || DEFAULT_PROFILES_FILE
+  );
+  if (profilesCache && profilesCache.file === file) {
+    return profilesCache.profiles;
+  }
+
+  if (!fs.existsSync(file)) {
+    throw new Error(
+      `Database profiles file not found: ${file}. Copy db-profiles.example.json to get started.`
+    );
+  }
+
+  const parsed = JSON.parse(fs.readFileSync(file, 'utf8')) as {
+    profiles?: Record<string, DbProfileConfig>;
+  };
+  const profiles = parsed.profiles || {};
+  Object.entries(profiles).forEach(([name, profile]) => {
+    if (profile.uri) {
+      assertNoInlineCredentials(`database profile '${name}'`, profile.uri);
+    }
+  });
+  profilesCache = { file, profiles };
+  return profiles;
+}
+
+/**
+ * Resolves a named profile into a connection URI and mongoose options.
+ * The URI comes from `uriSecret`, then the secret named by `uriEnv` (which also
+ * honours `<uriEnv>_FILE` / `<uriEnv>_SECRET`), then a literal credential-free `uri`.
+ *
+ * @param name - Profile name (e.g. 'prod', 'staging', 'reporting')
+ * @returns Resolved URI and connect options
+ */
+export async function resolveDbProfile(
+  name: string
+): Promise<ResolvedDbProfile> {
+  const profiles = loadDbProfiles();
+  const profile = profiles[name];
+  if (!profile) {
+    throw new Error(
+      `Unknown database profile '${name}'. Available: ${Object.keys(profiles).join(', ') || 'none'}`
+    );
+  }
+
+  const uri =
+    (profile.uriSecret && (await resolveSecret(profile.uriSecret))) ||
+    (profile.uriEnv && (await getSecret(profile.uriEnv))) ||
+    profile.uri;
+  if (!uri) {
+    throw new Error(
+      `Database profile '${name}' has no URI. Set ${profile.uriEnv || 'uriEnv'} in the environment.`
+    );
+  }
+
+  if (
+    profile.readPreference &&
+    !READ_PREFERENCES.includes(profile.readPreference)
+  ) {
+    throw new Error(
+      `Database profile '${name}' has invalid readPreference '${profile.readPreference}'`
+    );
+  }
+
+  const options: ConnectOptions = { ...DEFAULT_CONNECT_OPTIONS };
+  if (profile.dbName) options.dbName = profile.dbName;
+  if (profile.readPreference) options.readPreference = profile.readPreference;
+  if (profile.connectTimeoutMS !== undefined)
+    options.connectTimeoutMS = profile.connectTimeoutMS;
+  if (profile.serverSelectionTimeoutMS !== undefined)
+    options.serverSelectionTimeoutMS = profile.serverSelectionTimeoutMS;
+  if (profile.socketTimeoutMS !== undefined)
+    options.socketTimeoutMS = profile.socketTimeoutMS;
+  if (profile.maxPoolSize !== undefined)
+    options.maxPoolSize = profile.maxPoolSize;
+  if (profile.minPoolSize !== undefined)
+    options.minPoolSize = profile.minPoolSize;
+
+  return { name, uri, options };
+}
+
+/**
+ * Resolves the connection for a command or script: the selected profile when
+ * `--env`/`DB_PROFILE` is given, otherwise MONGODB_URI with default options.
+ *
+ * @param argv - Process arguments (default: process.argv)
+ * @returns Resolved URI and connect options
+ */
+export async function resolveCommandConnection(
+  argv: string[] = process.argv
+): Promise<ResolvedDbProfile> {
+  const profileName = getSelectedDbProfileName(argv);
+  if (profileName) return resolveDbProfile(profileName);
+
+  const uri = await getSecret('MONGODB_URI');
+  if (!uri) {
+    throw new Error(
+      'No database selected. Pass --env <profile>, set DB_PROFILE, or set MONGODB_URI.'
+    );
+  }
+  return { name: 'MONGODB_URI', uri, options: DEFAULT_CONNECT_OPTIONS };
+}
+
+/**
+ * Connects the default mongoose connection for a command or script, with the
+ * command's aggregation time limit (`--max-time-ms`, `COMMAND_MAX_TIME_MS`)
+ * and Ctrl-C cancellation of its server-side operations (see queryTimeout).
+ *
+ * @param argv - Process arguments (default: process.argv)
+ * @returns The resolved profile that was connected
+ */
+export async function connectCommandDatabase(
+  argv: string[] = process.argv
+): Promise<ResolvedDbProfile> {
+  const target = await resolveCommandConnection(argv);
+  await mongoose.connect(target.uri, target.options);
+  installCommandQueryControls(mongoose.connection, argv);
+  return target;
+}
+
+/**
+ * Strips credentials from a connection URI for logging.
+ */
+export function redactMongoUri(uri: string): string {
+  return uri.replace(/\/\/([^@/]+)@/, '//***@');
+}
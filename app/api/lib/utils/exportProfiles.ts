@@ -0,0 +1,102 @@
+/**
+ * Export Profile Configuration
+ *
+ * Loads export profiles (see lib/utils/export/profiles.ts) from
+ * `export-profiles.json` at the project root, or the file named by
+ * `EXPORT_PROFILES_FILE`; copy `export-profiles.example.json` to start. The
+ * file is optional: without it no profiles are offered and exports keep
+ * their default columns.
+ *
+ * @module app/api/lib/utils/exportProfiles
+ */
+
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+import {
+  validateExportProfile,
+  type ExportProfile,
+} from '@/lib/utils/export/profiles';
+import fs from 'fs';
+import path from 'path';
+
+const DEFAULT_EXPORT_PROFILES_FILE = 'export-profiles.json';
+
+let profilesCache: {
+  file: string;
+  mtimeMs: number;
+  profiles: Record<string, ExportProfile>;
+} | null = null;
+
+/**
+ * Reads the profiles file, cached until it changes on disk.
+ *
+ * @returns Profiles by name (empty when the file does not exist)
+ * @throws Error when the file is invalid
+ */
+export function loadExportProfiles(): Record<string, ExportProfile> {
+  const file = path.resolve(
+    process.cwd(),
+    process.env.EXPORT_PROFILES_FILE || DEFAULT_EXPORT_PROFILES_FILE
+  );
+  if (!fs.existsSync(file)) return {};
+
+  const { mtimeMs } = fs.statSync(file);
+  if (
+    profilesCache &&
+    profilesCache.file === file &&
+    profilesCache.mtimeMs === mtimeMs
+  ) {
+    return profilesCache.profiles;
+  }
+
+  const parsed = JSON.parse(fs.readFileSync(file, 'utf8')) as {
+    profiles?: Record<string, Omit<ExportProfile, 'name'>>;
+  };
+  const profiles: Record<string, ExportProfile> = {};
+  Object.entries(parsed.profiles || {}).forEach(([name, config]) => {
+    const profile = { ...config, name };
+    const invalid = validateExportProfile(profile);
+    if (invalid) throw new Error(`${file}: ${invalid}`);
+    profiles[name] = profile;
+  });
+
+  profilesCache = { file, mtimeMs, profiles };
+  return profiles;
+}
+
+/**
+ * Profiles offered to a user: those without a licencee restriction plus
+ * those naming one of the user's licencees.
+ *
+ * @param accessibleLicencees - From `getUserAccessibleLicenceesFromToken()`
+ */
+export function getExportProfilesForLicencees(
+  accessibleLicencees: string[] | 'all'
+): ExportProfile[] {
+  return Object.values(loadExportProfiles()).filter(
+    profile =>
+      accessibleLicencees === 'all' ||
+      !profile.licencees?.length ||
+      profile.licencees.some(licencee =>
+        accessibleLicencees.includes(licencee)
+      )
+  );
+}
+
+/**
+ * Looks up a profile by name.
+ *
+ * @param name - Profile name
+ * @param accessibleLicencees - Caller's licencees, to refuse profiles of others
+ * @throws Error with `statusCode` 404 when the profile does not exist or is
+ * not offered to the caller
+ */
+export function getExportProfile(
+  name: string,
+  accessibleLicencees: string[] | 'all' = 'all'
+): ExportProfile {
+  const profile = getExportProfilesForLicencees(accessibleLicencees).find(
+    candidate => candidate.name === name
+  );
+  if (!profile) throw statusError(`Export profile '${name}' not found`, 404);
+  return profile;
+}
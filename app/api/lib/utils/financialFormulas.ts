@@ -0,0 +1,230 @@
+/**
+ * Financial Formula Definitions
+ *
+ * Single source of truth for how Money In, Money Out, Gross and Net Gross are
+ * derived from meter movement fields. Pipelines sum the raw movement fields with
+ * `buildMovementTotalsGroup` and compose the metrics in memory with
+ * `calculateFinancialMetrics`, so a licencee-level override changes every report
+ * the same way.
+ *
+ * Default formula (see CLAUDE.md → Key Financial Metrics):
+ * - Money In  = movement.drop
+ * - Money Out = movement.totalCancelledCredits (+ jackpot when includeJackpot)
+ * - Gross     = Money In - Money Out
+ * - Net Gross = Money In - Money Out without jackpot - Jackpot (the jackpot is
+ *   taken off once, whether or not Money Out includes it)
+ *
+ * @module app/api/lib/utils/financialFormulas
+ */
+
+import type {
+  FinancialFormula,
+  FinancialFormulaOverride,
+  FinancialMetricDefinition,
+  FinancialMetricName,
+  FinancialMetrics,
+  FinancialScales,
+  LicenceeDocument,
+  MeterMovementField,
+  MovementTotals,
+} from '@shared/types';
+
+// ============================================================================
+// Metric Definitions
+// ============================================================================
+
+export const METER_MOVEMENT_FIELDS: MeterMovementField[] = [
+  'drop',
+  'totalCancelledCredits',
+  'totalHandPaidCancelledCredits',
+  'jackpot',
+  'coinIn',
+  'coinOut',
+  'totalWonCredits',
+];
+
+export const FINANCIAL_METRIC_DEFINITIONS: Record<
+  FinancialMetricName,
+  FinancialMetricDefinition
+> = {
+  moneyIn: {
+    name: 'moneyIn',
+    label: 'Money In',
+    description: 'Physical cash inserted into the machine (Drop).',
+  },
+  moneyOut: {
+    name: 'moneyOut',
+    label: 'Money Out',
+    description:
+      'Manual payouts (cancelled credits), plus jackpot when the licencee includes it.',
+  },
+  gross: {
+    name: 'gross',
+    label: 'Gross',
+    description: 'Money In minus Money Out.',
+  },
+  netGross: {
+    name: 'netGross',
+    label: 'Net Gross',
+    description:
+      'Money In minus Money Out (without jackpot) minus Jackpot.',
+  },
+};
+
+export const DEFAULT_FINANCIAL_FORMULA: FinancialFormula = {
+  moneyInFields: ['drop'],
+  moneyOutFields: ['totalCancelledCredits'],
+  includeJackpot: false,
+};
+
+// ============================================================================
+// Formula Resolution
+// ============================================================================
+
+/**
+ * Keeps only known movement field names, dropping duplicates and typos so a bad
+ * licencee override can never silently zero out a metric.
+ */
+export function sanitizeMovementFields(fields: unknown): MeterMovementField[] {
+  if (!Array.isArray(fields)) {
+    return [];
+  }
+  const allowed = new Set<string>(METER_MOVEMENT_FIELDS);
+  const sanitized = fields.filter(
+    (field): field is MeterMovementField =>
+      typeof field === 'string' && allowed.has(field)
+  );
+  return Array.from(new Set(sanitized));
+}
+
+/**
+ * Resolves the effective formula for a licencee. Overrides on the licencee
+ * document replace the default field lists; `includeJackpot` keeps using the
+ * existing licencee flag.
+ *
+ * @param licencee - Licencee settings (or null/undefined for the default formula)
+ * @returns Effective financial formula
+ */
+export function resolveFinancialFormula(
+  licencee?: Pick<LicenceeDocument, 'includeJackpot' | 'financialFormula'> | null
+): FinancialFormula {
+  if (!licencee) {
+    return DEFAULT_FINANCIAL_FORMULA;
+  }
+
+  const override: FinancialFormulaOverride = licencee.financialFormula || {};
+  const moneyInFields = sanitizeMovementFields(override.moneyInFields);
+  const moneyOutFields = sanitizeMovementFields(override.moneyOutFields);
+
+  return {
+    moneyInFields:
+      moneyInFields.length > 0
+        ? moneyInFields
+        : DEFAULT_FINANCIAL_FORMULA.moneyInFields,
+    moneyOutFields:
+      moneyOutFields.length > 0
+        ? moneyOutFields
+        : DEFAULT_FINANCIAL_FORMULA.moneyOutFields,
+    includeJackpot: Boolean(licencee.includeJackpot),
+  };
+}
+
+// ============================================================================
+// Pipeline Builders
+// ============================================================================
+
+/**
+ * Builds `$group` accumulators that sum every movement field used by any formula.
+ * Spread into a `$group` stage: `{ _id: '$machine', ...buildMovementTotalsGroup() }`.
+ *
+ * @param prefix - Path to the movement sub-document (default: 'movement')
+ * @returns `$group` accumulator map keyed by movement field name
+ */
+export function buildMovementTotalsGroup(
+  prefix: string = 'movement'
+): Record<MeterMovementField, { $sum: { $ifNull: [string, number] } }> {
+  return METER_MOVEMENT_FIELDS.reduce(
+    (group, field) => {
+      group[field] = { $sum: { $ifNull: [`$${prefix}.${field}`, 0] } };
+      return group;
+    },
+    {} as Record<MeterMovementField, { $sum: { $ifNull: [string, number] } }>
+  );
+}
+
+// ============================================================================
+// Metric Calculation
+// ============================================================================
+
+function sumFields(totals: MovementTotals, fields: MeterMovementField[]) {
+  return fields.reduce((sum, field) => sum + (Number(totals[field]) || 0), 0);
+}
+
+/**
+ * Composes the named financial metrics from summed movement totals.
+ * Reviewer scales are applied to the raw components before composition so
+ * gross/netGross stay consistent with the displayed Money In/Out.
+ *
+ * @param totals - Summed movement fields (from `buildMovementTotalsGroup`)
+ * @param formula - Effective formula (default when omitted)
+ * @param scales - Optional reviewer scales (see reviewerScale.ts)
+ * @returns Money In, Money Out, Jackpot, Gross and Net Gross
+ */
+export function calculateFinancialMetrics(
+  totals: MovementTotals,
+  formula: FinancialFormula = DEFAULT_FINANCIAL_FORMULA,
+  scales: FinancialScales = { moneyInScale: 1, moneyOutScale: 1 }
+): FinancialMetrics {
+  const moneyIn = sumFields(totals, formula.moneyInFields) * scales.moneyInScale;
+  const jackpot = (Number(totals.jackpot) || 0) * scales.moneyOutScale;
+  // Money Out without any jackpot share, so Net Gross takes it off once
+  const baseMoneyOut =
+    sumFields(
+      totals,
+      formula.moneyOutFields.filter(field => field !== 'jackpot')
+    ) * scales.moneyOutScale;
+  const moneyOutIncludesJackpot =
+    formula.includeJackpot || formula.moneyOutFields.includes('jackpot');
+  const moneyOut = baseMoneyOut + (moneyOutIncludesJackpot ? jackpot : 0);
+
+  return {
+    moneyIn,
+    moneyOut,
+    jackpot,
+    gross: moneyIn - moneyOut,
+    netGross: moneyIn - baseMoneyOut - jackpot,
+  };
+}
+
+/**
+ * Adds the movement fields of `source` onto `target`, e.g. to merge bucket
+ * rows from `buildMovementTotalsGroup` before composing metrics.
+ *
+ * @returns `target`
+ */
+export function addMovementTotals(
+  target: MovementTotals,
+  source: Record<string, unknown>
+): MovementTotals {
+  METER_MOVEMENT_FIELDS.forEach(field => {
+    target[field] = (target[field] || 0) + (Number(source[field]) || 0);
+  });
+  return target;
+}
+
+/**
+ * Adds two sets of metrics, e.g. to total rows composed with different
+ * licencee formulas.
+ */
+export function addFinancialMetrics(
+  a: FinancialMetrics,
+  b: FinancialMetrics
+): FinancialMetrics {
+  return {
+    moneyIn: a.moneyIn + b.moneyIn,
+    moneyOut: a.moneyOut + b.moneyOut,
+    jackpot: a.jackpot + b.jackpot,
+    gross: a.gross + b.gross,
+    netGross: a.netGross + b.netGross,
+  };
+}
@@ -0,0 +1,95 @@
+/**
+ * Geohash Utility
+ *
+ * Minimal geohash encoder/decoder used to bucket locations into map cells for
+ * heatmaps. Precision is the geohash length (1-12); each extra character makes
+ * cells roughly 4-8x smaller (precision 4 ≈ 39 km, 5 ≈ 4.9 km, 6 ≈ 1.2 km).
+ *
+ * @module app/api/lib/utils/geohash
+ */
+
+const BASE32 = '0123456789bcdefghjkmnpqrstuvwxyz';
+
+export const MIN_GEOHASH_PRECISION = 1;
+export const MAX_GEOHASH_PRECISION = 12;
+
+export type GeohashBounds = {
+  minLatitude: number;
+  maxLatitude: number;
+  minLongitude: number;
+  maxLongitude: number;
+};
+
+/**
+ * Encodes a coordinate into a geohash of the given precision.
+ */
+export function encodeGeohash(
+  latitude: number,
+  longitude: number,
+  precision: number
+): string {
+  let latRange: [number, number] = [-90, 90];
+  let lngRange: [number, number] = [-180, 180];
+  let hash = '';
+  let bit = 0;
+  let charIndex = 0;
+  let isLongitudeBit = true;
+
+  while (hash.length < precision) {
+    const range = isLongitudeBit ? lngRange : latRange;
+    const value = isLongitudeBit ? longitude : latitude;
+    const mid = (range[0] + range[1]) / 2;
+
+    charIndex <<= 1;
+    if (value >= mid) {
+      charIndex |= 1;
+      if (isLongitudeBit) lngRange = [mid, range[1]];
+      else latRange = [mid, range[1]];
+    } else if (isLongitudeBit) {
+      lngRange = [range[0], mid];
+    } else {
+      latRange = [range[0], mid];
+    }
+
+    isLongitudeBit = !isLongitudeBit;
+    if (++bit === 5) {
+      hash += BASE32[charIndex];
+      bit = 0;
+      charIndex = 0;
+    }
+  }
+
+  return hash;
+}
+
+/**
+ * Decodes a geohash into its cell bounds.
+ */
+export function decodeGeohashBounds(hash: string): GeohashBounds {
+  let latRange: [number, number] = [-90, 90];
+  let lngRange: [number, number] = [-180, 180];
+  let isLongitudeBit = true;
+
+  for (const char of hash) {
+    const charIndex = BASE32.indexOf(char);
+    if (charIndex === -1) {
+      throw new Error(`Invalid geohash character '${char}'`);
+    }
+    for (let shift = 4; shift >= 0; shift--) {
+      const bitSet = (charIndex >> shift) & 1;
+      const range = isLongitudeBit ? lngRange : latRange;
+      const mid = (range[0] + range[1]) / 2;
+      const next: [number, number] = bitSet ? [mid, range[1]] : [range[0], mid];
+      if (isLongitudeBit) lngRange = next;
+      else latRange = next;
+      isLongitudeBit = !isLongitudeBit;
+    }
+  }
+
+  return {
+    minLatitude: latRange[0],
+    maxLatitude: latRange[1],
+    minLongitude: lngRange[0],
+    maxLongitude: lngRange[1],
+  };
+}
@@ -0,0 +1,259 @@
+/**
+ * Graceful Shutdown
+ *
+ * Lets a redeploy finish the work already running instead of killing it.
+ * On SIGTERM (or SIGINT) a server:
+ *
+ * 1. Stops accepting work: `withApiAuth()` answers new requests with 503 and
+ *    `Connection: close`, `/readyz` fails so the load balancer drains the
+ *    instance, and the gRPC server stops taking calls.
+ * 2. Waits up to `SHUTDOWN_DRAIN_TIMEOUT_MS` (default 30000) for the tracked
+ *    requests, calls and aggregations to finish.
+ * 3. Kills what is still running on the server by its query tag (see
+ *    queryTimeout), so MongoDB does not keep running orphaned pipelines.
+ * 4. Closes the Mongo connections and exits.
+ *
+ * The state lives on `globalThis` because Next.js bundles instrumentation
+ * and route handlers separately; both must see the same counters.
+ *
+ * @module app/api/lib/utils/gracefulShutdown
+ */
+
+import { cancelServerOperations } from '@/app/api/lib/utils/queryTimeout';
+import mongoose from 'mongoose';
+import type { Aggregate } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type InFlightKind = 'request' | 'call' | 'aggregation';
+
+export type DrainOptions = {
+  /** Log prefix, e.g. `server` or `grpc` */
+  label: string;
+  /** Query tag to kill leftovers by (see queryTimeout) */
+  tag?: string;
+  /** Stops taking new work; not awaited past the drain timeout */
+  stopAccepting?: () => Promise<void> | void;
+  /** Called when work is still running at the drain timeout */
+  onTimeout?: () => void;
+  /** Runs after draining, before Mongo is closed (e.g. a final write) */
+  beforeClose?: (result: DrainResult) => Promise<void>;
+  /** Default SHUTDOWN_DRAIN_TIMEOUT_MS */
+  timeoutMs?: number;
+};
+
+export type DrainResult = {
+  drained: boolean;
+  waitedMs: number;
+  /** Work still running when the timeout expired */
+  remaining: Record<InFlightKind, number>;
+  /** Server operations killed after the timeout */
+  killed: number;
+};
+
+type ShutdownState = {
+  shuttingDown: boolean;
+  inFlight: Record<InFlightKind, number>;
+  waiters: Array<() => void>;
+  trackingInstalled: boolean;
+  signalsInstalled: boolean;
+};
+
+export const DEFAULT_DRAIN_TIMEOUT_MS = 30000;
+
+const STATE_KEY = Symbol.for('cms.gracefulShutdown');
+
+function getState(): ShutdownState {
+  const store = globalThis as unknown as Record<symbol, ShutdownState>;
+  store[STATE_KEY] ??= {
+    shuttingDown: false,
+    inFlight: { request: 0, call: 0, aggregation: 0 },
+    waiters: [],
+    trackingInstalled: false,
+    signalsInstalled: false,
+  };
+  return store[STATE_KEY];
+}
+
+function totalInFlight(state: ShutdownState): number {
+  return Object.values(state.inFlight).reduce((sum, count) => sum + count, 0);
+}
+
+/**
+ * Drain timeout: `SHUTDOWN_DRAIN_TIMEOUT_MS`, default 30000.
+ */
+export function getDrainTimeoutMs(): number {
+  const raw = process.env.SHUTDOWN_DRAIN_TIMEOUT_MS;
+  if (!raw) return DEFAULT_DRAIN_TIMEOUT_MS;
+  const value = Number(raw);
+  return Number.isInteger(value) && value >= 0
+    ? value
+    : DEFAULT_DRAIN_TIMEOUT_MS;
+}
+
+// ============================================================================
+// Tracking
+// ============================================================================
+
+/**
+ * True once a shutdown started; new work should be refused.
+ */
+export function isShuttingDown(): boolean {
+  return getState().shuttingDown;
+}
+
+/**
+ * Counts of work running now, by kind.
+ */
+export function getInFlight(): Record<InFlightKind, number> {
+  return { ...getState().inFlight };
+}
+
+/**
+ * Runs `work`, counting it as in flight until it settles.
+ */
+export async function trackInFlight<T>(
+  kind: InFlightKind,
+  work: () => Promise<T>
+): Promise<T> {
+  const state = getState();
+  state.inFlight[kind] += 1;
+  try {
+    return await work();
+  } finally {
+    state.inFlight[kind] -= 1;
+    if (totalInFlight(state) === 0) {
+      state.waiters.splice(0).forEach(resolve => resolve());
+    }
+  }
+}
+
+/**
+ * Counts every mongoose aggregation as in flight. Safe to call repeatedly.
+ */
+export function installInFlightTracking(): void {
+  const state = getState();
+  if (state.trackingInstalled) return;
+  state.trackingInstalled = true;
+
+  const exec = mongoose.Aggregate.prototype.exec;
+  mongoose.Aggregate.prototype.exec = function (this: Aggregate<unknown>) {
+    return trackInFlight('aggregation', () => exec.call(this));
+  } as typeof exec;
+}
+
+// ============================================================================
+// Shutdown
+// ============================================================================
+
+/**
+ * Stops taking work, waits for what is running, then closes Mongo.
+ */
+export async function drainAndClose(
+  options: DrainOptions
+): Promise<DrainResult> {
+  const state = getState();
+  state.shuttingDown = true;
+  const startTime = Date.now();
+  const timeoutMs = options.timeoutMs ?? getDrainTimeoutMs();
+  console.log(
+    `[${options.label}] Shutting down: draining ${totalInFlight(state)} in-flight operation(s) (up to ${timeoutMs}ms)...`
+  );
+
+  Promise.resolve(options.stopAccepting?.()).catch(error => {
+    console.error(
+      `[${options.label}] Failed to stop accepting work:`,
+      error instanceof Error ? error.message : error
+    );
+  });
+
+  let timer: NodeJS.Timeout | undefined;
+  const drained =
+    totalInFlight(state) === 0 ||
+    (await Promise.race([
+      new Promise<boolean>(resolve =>
+        state.waiters.push(() => resolve(true))
+      ),
+      new Promise<boolean>(resolve => {
+        timer = setTimeout(() => resolve(false), timeoutMs);
+      }),
+    ]));
+  clearTimeout(timer);
+
+  const remaining = getInFlight();
+  let killed = 0;
+  if (!drained) {
+    console.warn(
+      `[${options.label}] Drain timed out with ${JSON.stringify(remaining)} still running`
+    );
+    options.onTimeout?.();
+    if (options.tag) {
+      killed = await cancelServerOperations(
+        mongoose.connection,
+        options.tag
+      ).catch(error => {
+        console.error(
+          `[${options.label}] Could not kill server operations:`,
+          error instanceof Error ? error.message : error
+        );
+        return 0;
+      });
+    }
+  }
+
+  const result = {
+    drained,
+    waitedMs: Date.now() - startTime,
+    remaining,
+    killed,
+  };
+  await options.beforeClose?.(result).catch(error => {
+    console.error(
+      `[${options.label}] Shutdown hook failed:`,
+      error instanceof Error ? error.message : error
+    );
+  });
+  await mongoose.disconnect().catch(error => {
+    console.error(
+      `[${options.label}] Failed to close Mongo connections:`,
+      error instanceof Error ? error.message : error
+    );
+  });
+  console.log(
+    `[${options.label}] Stopped after ${result.waitedMs}ms (${drained ? 'drained' : `killed ${killed} server operation(s)`})`
+  );
+  return result;
+}
+
+/**
+ * Drains and exits on the given signals (default SIGTERM and SIGINT).
+ * Installs once per process; a second signal exits immediately.
+ */
+export function installShutdownHandlers(
+  options: DrainOptions & { signals?: NodeJS.Signals[] }
+): void {
+  const state = getState();
+  if (state.signalsInstalled) return;
+  state.signalsInstalled = true;
+
+  const handle = (signal: NodeJS.Signals) => {
+    if (state.shuttingDown) {
+      console.warn(`[${options.label}] ${signal} again: exiting now`);
+      process.exit(1);
+    }
+    console.log(`[${options.label}] Received ${signal}`);
+    drainAndClose(options)
+      .catch(error => {
+        console.error(
+          `[${options.label}] Shutdown failed:`,
+          error instanceof Error ? error.message : error
+        );
+      })
+      .finally(() => process.exit(0));
+  };
+  for (const signal of options.signals ?? ['SIGTERM', 'SIGINT']) {
+    process.on(signal, handle);
+  }
+}
@@ -0,0 +1,263 @@
+/**
+ * Levy Schedule Configuration
+ *
+ * Loads the regulator levy schedule from `levy-schedule.json` at the project
+ * root, or the file named by `LEVY_SCHEDULE_FILE`; copy
+ * `levy-schedule.example.json` to start. Rates are percentages of gross,
+ * set per jurisdiction (country name) and optionally overridden per
+ * licencee, each with the month it takes effect from:
+ *
+ * ```json
+ * {
+ *   "rounding": "half-up",
+ *   "decimals": 2,
+ *   "jurisdictions": {
+ *     "Trinidad and Tobago": { "rates": [{ "from": "2026-01", "rate": 10 }] }
+ *   },
+ *   "licencees": {
+ *     "<licenceeId>": { "rates": [{ "from": "2026-07", "rate": 12.5 }] }
+ *   }
+ * }
+ * ```
+ *
+ * Rounding (`half-up`, `half-even`, `up`, `down`) and `decimals` can be set
+ * globally, per jurisdiction or per licencee; `minimum` sets a floor on the
+ * amount owed. The file is optional: without it no levy is computed.
+ *
+ * @module app/api/lib/utils/levySchedule
+ */
+
+import fs from 'fs';
+import path from 'path';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type LevyRounding = 'half-up' | 'half-even' | 'up' | 'down';
+
+export type LevyRate = {
+  /** First month the rate applies to, YYYY-MM */
+  from: string;
+  /** Percent of gross */
+  rate: number;
+};
+
+export type LevyRule = {
+  rates?: LevyRate[];
+  rounding?: LevyRounding;
+  decimals?: number;
+  /** Smallest amount owed for a month with positive gross */
+  minimum?: number;
+};
+
+export type LevySchedule = {
+  rounding?: LevyRounding;
+  decimals?: number;
+  jurisdictions: Record<string, LevyRule>;
+  licencees: Record<string, LevyRule & { jurisdiction?: string }>;
+};
+
+/** Rate resolved for one licencee and month */
+export type ResolvedLevyRate = {
+  jurisdiction: string | null;
+  /** Where the rate came from */
+  source: 'licencee' | 'jurisdiction';
+  rate: number;
+  effectiveFrom: string;
+  rounding: LevyRounding;
+  decimals: number;
+  minimum: number;
+};
+
+export const LEVY_ROUNDINGS: LevyRounding[] = [
+  'half-up',
+  'half-even',
+  'up',
+  'down',
+];
+
+const DEFAULT_LEVY_SCHEDULE_FILE = 'levy-schedule.json';
+const MONTH_PATTERN = /^\d{4}-(0[1-9]|1[0-2])$/;
+
+let scheduleCache: {
+  file: string;
+  mtimeMs: number;
+  schedule: LevySchedule | null;
+} | null = null;
+
+// ============================================================================
+// Loading
+// ============================================================================
+
+function validateRule(rule: LevyRule, label: string): string | null {
+  for (const entry of rule.rates || []) {
+    if (!MONTH_PATTERN.test(entry.from)) {
+      return `${label}: rate 'from' must be YYYY-MM (got '${entry.from}')`;
+    }
+    if (
+      typeof entry.rate !== 'number' ||
+      !Number.isFinite(entry.rate) ||
+      entry.rate < 0 ||
+      entry.rate > 100
+    ) {
+      return `${label}: rate must be a percentage between 0 and 100`;
+    }
+  }
+  if (rule.rounding && !LEVY_ROUNDINGS.includes(rule.rounding)) {
+    return `${label}: unknown rounding '${rule.rounding}'`;
+  }
+  if (
+    rule.decimals !== undefined &&
+    (!Number.isInteger(rule.decimals) || rule.decimals < 0)
+  ) {
+    return `${label}: decimals must be a whole number`;
+  }
+  if (rule.minimum !== undefined && !(rule.minimum >= 0)) {
+    return `${label}: minimum must be zero or more`;
+  }
+  return null;
+}
+
+/**
+ * Reads the schedule file, cached until it changes on disk.
+ *
+ * @returns The schedule, or null when the file does not exist
+ * @throws Error when the file is invalid
+ */
+export function loadLevySchedule(): LevySchedule | null {
+  const file = path.resolve(
+    process.cwd(),
+    process.env.LEVY_SCHEDULE_FILE || DEFAULT_LEVY_SCHEDULE_FILE
+  );
+  if (!fs.existsSync(file)) return null;
+
+  const { mtimeMs } = fs.statSync(file);
+  if (
+    scheduleCache &&
+    scheduleCache.file === file &&
+    scheduleCache.mtimeMs === mtimeMs
+  ) {
+    return scheduleCache.schedule;
+  }
+
+  const parsed = JSON.parse(
+    fs.readFileSync(file, 'utf8')
+  ) as Partial<LevySchedule>;
+  const schedule: LevySchedule = {
+    rounding: parsed.rounding,
+    decimals: parsed.decimals,
+    jurisdictions: parsed.jurisdictions || {},
+    licencees: parsed.licencees || {},
+  };
+  const invalid =
+    validateRule(schedule, 'schedule') ||
+    Object.entries(schedule.jurisdictions)
+      .map(([name, rule]) => validateRule(rule, `jurisdiction '${name}'`))
+      .find(Boolean) ||
+    Object.entries(schedule.licencees)
+      .map(([id, rule]) => validateRule(rule, `licencee '${id}'`))
+      .find(Boolean);
+  if (invalid) throw new Error(`${file}: ${invalid}`);
+
+  scheduleCache = { file, mtimeMs, schedule };
+  return schedule;
+}
+
+// ============================================================================
+// Resolution & Rounding
+// ============================================================================
+
+function rateForMonth(
+  rates: LevyRate[] | undefined,
+  month: string
+): LevyRate | null {
+  const applicable = (rates || [])
+    .filter(entry => entry.from <= month)
+    .sort((a, b) => b.from.localeCompare(a.from));
+  return applicable[0] || null;
+}
+
+/**
+ * Finds the rate for a licencee and month: the licencee's own rates first,
+ * then its jurisdiction's (the schedule's `jurisdiction` for the licencee,
+ * else its country name, matched case-insensitively).
+ *
+ * @param schedule - Loaded schedule
+ * @param licenceeId - Licencee ID
+ * @param country - Licencee country name, when known
+ * @param month - YYYY-MM
+ * @returns The rate, or null when the schedule has none for the month
+ */
+export function resolveLevyRate(
+  schedule: LevySchedule,
+  licenceeId: string,
+  country: string | null,
+  month: string
+): ResolvedLevyRate | null {
+  const licenceeRule: LevySchedule['licencees'][string] =
+    schedule.licencees[licenceeId] || {};
+  const jurisdictionName = licenceeRule.jurisdiction || country;
+  const jurisdictionKey = jurisdictionName
+    ? Object.keys(schedule.jurisdictions).find(
+        name => name.toLowerCase() === jurisdictionName.toLowerCase()
+      )
+    : undefined;
+  const jurisdictionRule: LevyRule = jurisdictionKey
+    ? schedule.jurisdictions[jurisdictionKey]
+    : {};
+
+  const licenceeRate = rateForMonth(licenceeRule.rates, month);
+  const rate = licenceeRate || rateForMonth(jurisdictionRule.rates, month);
+  if (!rate) return null;
+
+  return {
+    jurisdiction: jurisdictionKey || jurisdictionName || null,
+    source: licenceeRate ? 'licencee' : 'jurisdiction',
+    rate: rate.rate,
+    effectiveFrom: rate.from,
+    rounding:
+      licenceeRule.rounding ||
+      jurisdictionRule.rounding ||
+      schedule.rounding ||
+      'half-up',
+    decimals:
+      licenceeRule.decimals ??
+      jurisdictionRule.decimals ??
+      schedule.decimals ??
+      2,
+    minimum: licenceeRule.minimum ?? jurisdictionRule.minimum ?? 0,
+  };
+}
+
+/**
+ * Rounds an amount to `decimals` places using a levy rounding rule.
+ */
+export function roundLevy(
+  amount: number,
+  rounding: LevyRounding,
+  decimals: number
+): number {
+  const factor = 10 ** decimals;
+  // Trim float noise so 1.005 * 100 is 100.5 rather than 100.49999
+  const scaled = Number((amount * factor).toFixed(8));
+  let rounded: number;
+  switch (rounding) {
+    case 'up':
+      rounded = Math.ceil(scaled);
+      break;
+    case 'down':
+      rounded = Math.floor(scaled);
+      break;
+    case 'half-even': {
+      const floor = Math.floor(scaled);
+      const diff = scaled - floor;
+      rounded =
+        diff > 0.5 || (diff === 0.5 && floor % 2 !== 0) ? floor + 1 : floor;
+      break;
+    }
+    default:
+      rounded = Math.round(scaled);
+  }
+  return rounded / factor;
+}
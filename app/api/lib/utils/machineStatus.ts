@@ -0,0 +1,131 @@
+/**
+ * Machine Status Definitions
+ *
+ * Single source of truth for how machines are classified, so every report,
+ * pipeline and command agrees:
+ *
+ * - Online:  `lastActivity` within the online threshold — 3 minutes by
+ *   default, `MACHINE_ONLINE_THRESHOLD_MINUTES` for the deployment, or the
+ *   licencee's `machineStatus.onlineThresholdMinutes` when a report is
+ *   scoped to one licencee (see getLicenceeMachineStatus)
+ * - Offline: anything else, including machines that never reported
+ * - Active:  `assetStatus` is not in-repair, storage or retired; legacy
+ *   values (`functional`, `Active`, unset) count as active
+ *
+ * @module app/api/lib/utils/machineStatus
+ */
+
+import type {
+  MachineLifecycleStatus,
+  MachineStatusDefinitions,
+  MachineStatusOverride,
+} from '@shared/types';
+
+// ============================================================================
+// Definitions
+// ============================================================================
+
+export const DEFAULT_ONLINE_THRESHOLD_MINUTES = 3;
+
+/** Lifecycle statuses that take a machine out of service */
+export const INACTIVE_ASSET_STATUSES: MachineLifecycleStatus[] = [
+  'in-repair',
+  'storage',
+  'retired',
+];
+
+/** Stored values read as active besides 'active' itself */
+export const LEGACY_ACTIVE_ASSET_STATUSES = ['functional', 'Active', ''];
+
+function parseMinutes(value: unknown): number | null {
+  if (value === null || value === undefined || value === '') return null;
+  const minutes = Number(value);
+  return Number.isFinite(minutes) && minutes > 0 ? minutes : null;
+}
+
+/**
+ * Effective definitions: the licencee override, else the deployment's
+ * MACHINE_ONLINE_THRESHOLD_MINUTES, else the default.
+ */
+export function getMachineStatusDefinitions(
+  override?: MachineStatusOverride | null
+): MachineStatusDefinitions {
+  const licenceeMinutes = parseMinutes(override?.onlineThresholdMinutes);
+  const envMinutes = parseMinutes(
+    process.env.MACHINE_ONLINE_THRESHOLD_MINUTES
+  );
+  return {
+    onlineThresholdMinutes:
+      licenceeMinutes ?? envMinutes ?? DEFAULT_ONLINE_THRESHOLD_MINUTES,
+    source: licenceeMinutes ? 'licencee' : envMinutes ? 'env' : 'default',
+    inactiveAssetStatuses: INACTIVE_ASSET_STATUSES,
+    legacyActiveAssetStatuses: LEGACY_ACTIVE_ASSET_STATUSES,
+  };
+}
+
+// ============================================================================
+// Online / Offline
+// ============================================================================
+
+/**
+ * How long after its last activity a machine still counts as online.
+ */
+export function getOnlineThresholdMs(
+  override?: MachineStatusOverride | null
+): number {
+  return getMachineStatusDefinitions(override).onlineThresholdMinutes * 60000;
+}
+
+/**
+ * Machines with `lastActivity` at or after this date are online.
+ */
+export function getOnlineCutoff(override?: MachineStatusOverride | null): Date {
+  return new Date(Date.now() - getOnlineThresholdMs(override));
+}
+
+/**
+ * Whether a machine with this last activity counts as online.
+ */
+export function isMachineOnline(
+  lastActivity: Date | string | null | undefined,
+  override?: MachineStatusOverride | null
+): boolean {
+  if (!lastActivity) return false;
+  const time = new Date(lastActivity).getTime();
+  return !Number.isNaN(time) && time >= getOnlineCutoff(override).getTime();
+}
+
+// ============================================================================
+// Asset Status
+// ============================================================================
+
+/**
+ * Whether a stored `assetStatus` counts as active.
+ */
+export function isActiveAssetStatus(value: string | null | undefined): boolean {
+  const normalized = (value || '')
+    .trim()
+    .toLowerCase()
+    .replace(/[\s_]+/g, '-');
+  return !INACTIVE_ASSET_STATUSES.includes(
+    normalized as MachineLifecycleStatus
+  );
+}
+
+/**
+ * Aggregation expression that is true when the `assetStatus` at `field`
+ * counts as active (same rule as isActiveAssetStatus).
+ *
+ * @param field - Field path, e.g. '$assetStatus'
+ */
+export function buildActiveAssetStatusExpression(field = '$assetStatus') {
+  const trimmed = { $trim: { input: { $toLower: { $ifNull: [field, ''] } } } };
+  const normalized = {
+    $replaceAll: {
+      input: { $replaceAll: { input: trimmed, find: ' ', replacement: '-' } },
+      find: '_',
+      replacement: '-',
+    },
+  };
+  return { $not: [{ $in: [normalized, INACTIVE_ASSET_STATUSES] }] };
+}
@@ -0,0 +1,214 @@
+/**
+ * Meter Unit Normalization
+ *
+ * Meter readings are treated as dollars everywhere, but some machines report
+ * their meters in cents, or in credits of their accounting denomination. A
+ * machine's `meterUnit` records which, and `getMeterUnitFactor()` turns it
+ * into the multiplier that brings its readings to dollars:
+ *
+ * - `dollars` (or unset) — 1
+ * - `cents` — 0.01
+ * - `credits` — `gameConfig.accountingDenomination` (1 when unset)
+ *
+ * The `meters` model inserts `buildMeterUnitStages()` into every
+ * `Meters.aggregate()`, so dashboards, reports, trends and the metersDaily
+ * rollup all see dollars. `Meters.find()` and raw `collection('meters')` reads
+ * are not normalized; use `normalizeMeterValues()` on those.
+ *
+ * The `meterUnits` integrity check flags machines whose normalized magnitude
+ * suggests the unit is wrong (see dataIntegrity).
+ *
+ * @module app/api/lib/utils/meterUnits
+ */
+
+import { Machine } from '@/app/api/lib/models/machines';
+import { METER_MOVEMENT_FIELDS } from '@/app/api/lib/utils/financialFormulas';
+import type { PipelineStage } from 'mongoose';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MeterUnit = 'dollars' | 'cents' | 'credits';
+
+export const METER_UNITS: MeterUnit[] = ['dollars', 'cents', 'credits'];
+
+/** Denominations a credits-reporting machine is expected to use */
+export const STANDARD_DENOMINATIONS = [
+  0.01, 0.02, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 25, 50, 100,
+];
+
+const FACTOR_FIELD = '_meterUnitFactor';
+const FACTORS_TTL_MS = 60_000;
+
+let cachedFactors: { factors: Map<string, number>; at: number } | null = null;
+
+type MachineUnitFields = {
+  meterUnit?: string | null;
+  gameConfig?: { accountingDenomination?: number | null };
+};
+
+// ============================================================================
+// Factors
+// ============================================================================
+
+export function isMeterUnit(value: unknown): value is MeterUnit {
+  return METER_UNITS.includes(value as MeterUnit);
+}
+
+/**
+ * @param machine - Machine with `meterUnit` and `gameConfig.accountingDenomination`
+ * @returns Multiplier that converts the machine's readings to dollars
+ */
+export function getMeterUnitFactor(machine: MachineUnitFields): number {
+  if (machine.meterUnit === 'cents') return 0.01;
+  if (machine.meterUnit === 'credits') {
+    const denomination = Number(machine.gameConfig?.accountingDenomination);
+    return denomination > 0 ? denomination : 1;
+  }
+  return 1;
+}
+
+/**
+ * Factors of every machine that does not report in dollars, cached for a
+ * minute. Machines without a factor (the vast majority) are left out.
+ */
+export async function getMeterUnitFactors(): Promise<Map<string, number>> {
+  if (cachedFactors && Date.now() - cachedFactors.at < FACTORS_TTL_MS) {
+    return cachedFactors.factors;
+  }
+
+  const machines = await Machine.find(
+    { meterUnit: { $in: ['cents', 'credits'] } },
+    { _id: 1, meterUnit: 1, 'gameConfig.accountingDenomination': 1 }
+  ).lean<Array<MachineUnitFields & { _id: string }>>();
+
+  const factors = new Map<string, number>();
+  machines.forEach(machine => {
+    const factor = getMeterUnitFactor(machine);
+    if (factor !== 1) factors.set(String(machine._id), factor);
+  });
+  cachedFactors = { factors, at: Date.now() };
+  return factors;
+}
+
+/**
+ * Drops the cached factors; call after changing a machine's `meterUnit` or
+ * accounting denomination so aggregations pick it up immediately.
+ */
+export function clearMeterUnitCache(): void {
+  cachedFactors = null;
+}
+
+// ============================================================================
+// Normalization
+// ============================================================================
+
+/**
+ * Stages that multiply the money fields of each reading (`movement.*` and the
+ * cumulative top-level fields) by its machine's factor. Empty when every
+ * machine reports in dollars.
+ */
+export async function buildMeterUnitStages(): Promise<PipelineStage[]> {
+  const factors = await getMeterUnitFactors();
+  if (factors.size === 0) return [];
+
+  const machineIds = Array.from(factors.keys());
+  const values = Array.from(factors.values());
+  const scaled = (path: string) => ({
+    $cond: [
+      { $eq: [`$${FACTOR_FIELD}`, 1] },
+      `$${path}`,
+      { $multiply: [`$${path}`, `$${FACTOR_FIELD}`] },
+    ],
+  });
+
+  return [
+    {
+      $set: {
+        [FACTOR_FIELD]: {
+          $let: {
+            vars: { index: { $indexOfArray: [machineIds, '$machine'] } },
+            in: {
+              $cond: [
+                { $gte: ['$$index', 0] },
+                { $arrayElemAt: [values, '$$index'] },
+                1,
+              ],
+            },
+          },
+        },
+      },
+    },
+    {
+      $set: Object.fromEntries(
+        METER_MOVEMENT_FIELDS.flatMap(field => [
+          [`movement.${field}`, scaled(`movement.${field}`)],
+          [field, scaled(field)],
+        ])
+      ),
+    },
+    { $unset: FACTOR_FIELD },
+  ];
+}
+
+/**
+ * Inserts the normalization stages into a meters pipeline after its leading
+ * `$match` / `$sort` / `$limit` / `$skip` stages, so index use is unchanged.
+ *
+ * @param pipeline - Pipeline to modify in place
+ */
+export async function applyMeterUnitStages(
+  pipeline: PipelineStage[]
+): Promise<void> {
+  const stages = await buildMeterUnitStages();
+  if (stages.length === 0) return;
+
+  const leading = ['$match', '$sort', '$limit', '$skip'];
+  let index = 0;
+  while (
+    index < pipeline.length &&
+    leading.some(stage => stage in pipeline[index])
+  ) {
+    index++;
+  }
+  pipeline.splice(index, 0, ...stages);
+}
+
+/**
+ * Normalizes the money fields of a reading loaded outside an aggregation.
+ *
+ * @param values - `movement` or a whole meter document
+ * @param factor - From `getMeterUnitFactor()` or `getMeterUnitFactors()`
+ * @returns A copy with the money fields multiplied by the factor
+ */
+export function normalizeMeterValues<T extends Record<string, unknown>>(
+  values: T,
+  factor: number
+): T {
+  if (factor === 1) return values;
+  const normalized: Record<string, unknown> = { ...values };
+  METER_MOVEMENT_FIELDS.forEach(field => {
+    if (typeof normalized[field] === 'number') {
+      normalized[field] = (normalized[field] as number) * factor;
+    }
+  });
+  if (normalized.movement && typeof normalized.movement === 'object') {
+    normalized.movement = normalizeMeterValues(
+      normalized.movement as Record<string, unknown>,
+      factor
+    );
+  }
+  return normalized as T;
+}
+
+/**
+ * Closest standard denomination to a value, for suggesting a credits factor.
+ */
+export function nearestDenomination(value: number): number {
+  return STANDARD_DENOMINATIONS.reduce((best, candidate) =>
+    Math.abs(Math.log(candidate / value)) < Math.abs(Math.log(best / value))
+      ? candidate
+      : best
+  );
+}
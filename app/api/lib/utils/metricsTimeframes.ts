@@ -0,0 +1,274 @@
+/**
+ * casinoMetrics Timeframes Configuration
+ *
+ * The timeframes pre-aggregated into `casinoMetrics`, keyed by name. Today,
+ * Yesterday, 7d and 30d are built in; `metrics-timeframes.json` at the
+ * project root (or the file named by `METRICS_TIMEFRAMES_FILE`; copy
+ * `metrics-timeframes.example.json` to start) adds more, or redefines a
+ * built-in, without code changes:
+ *
+ * ```json
+ * {
+ *   "MTD": { "period": "monthToDate" },
+ *   "previousMonth": { "period": "previousMonth" },
+ *   "14d": { "key": "last14Days", "days": 14 },
+ *   "carnival": { "start": "2026-02-13", "end": "2026-02-18" }
+ * }
+ * ```
+ *
+ * Each timeframe has exactly one of `period` (a built-in window), `days`
+ * (the last N gaming days, today included) or `start` / `end` (fixed
+ * gaming days, YYYY-MM-DD). `key` is the field holding it in the metrics
+ * document; it defaults to the timeframe name (the built-ins keep their
+ * existing `last7Days` / `last30Days` keys). Ranges follow each location's
+ * gaming day.
+ *
+ * @module app/api/lib/utils/metricsTimeframes
+ */
+
+import fs from 'fs';
+import path from 'path';
+import {
+  getGamingDayRange,
+  getGamingDayRangeForPeriod,
+} from '@/lib/utils/gamingDayRange';
+import type { GamingDayRange } from '@/lib/utils/gamingDayRange';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MetricsTimeframePeriod =
+  | 'Today'
+  | 'Yesterday'
+  | '7d'
+  | '30d'
+  | 'monthToDate'
+  | 'previousMonth';
+
+export type MetricsTimeframeDefinition = {
+  /** Field holding the timeframe in the metrics document (default: name) */
+  key?: string;
+  period?: MetricsTimeframePeriod;
+  /** Last N gaming days, today included */
+  days?: number;
+  /** First and last gaming day, YYYY-MM-DD */
+  start?: string;
+  end?: string;
+};
+
+/** Timeframe with its document key resolved */
+export type MetricsTimeframeConfig = MetricsTimeframeDefinition & {
+  name: string;
+  key: string;
+};
+
+export const METRICS_TIMEFRAME_PERIODS: MetricsTimeframePeriod[] = [
+  'Today',
+  'Yesterday',
+  '7d',
+  '30d',
+  'monthToDate',
+  'previousMonth',
+];
+
+export const BUILT_IN_METRICS_TIMEFRAMES: Record<
+  string,
+  MetricsTimeframeDefinition
+> = {
+  Today: { key: 'Today', period: 'Today' },
+  Yesterday: { key: 'Yesterday', period: 'Yesterday' },
+  '7d': { key: 'last7Days', period: '7d' },
+  '30d': { key: 'last30Days', period: '30d' },
+};
+
+const DEFAULT_TIMEFRAMES_FILE = 'metrics-timeframes.json';
+const DATE_PATTERN = /^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$/;
+const DAY_MS = 24 * 60 * 60 * 1000;
+
+let timeframesCache: {
+  file: string;
+  mtimeMs: number;
+  timeframes: Record<string, MetricsTimeframeConfig>;
+} | null = null;
+
+// ============================================================================
+// Loading
+// ============================================================================
+
+function validateDefinition(
+  definition: MetricsTimeframeDefinition,
+  label: string
+): string | null {
+  const kinds = [
+    definition.period !== undefined,
+    definition.days !== undefined,
+    definition.start !== undefined || definition.end !== undefined,
+  ].filter(Boolean).length;
+  if (kinds !== 1) {
+    return `${label}: give exactly one of period, days or start/end`;
+  }
+  if (
+    definition.period !== undefined &&
+    !METRICS_TIMEFRAME_PERIODS.includes(definition.period)
+  ) {
+    return `${label}: unknown period '${definition.period}'`;
+  }
+  if (
+    definition.days !== undefined &&
+    (!Number.isInteger(definition.days) || definition.days < 1)
+  ) {
+    return `${label}: days must be a whole number of 1 or more`;
+  }
+  if (definition.start !== undefined || definition.end !== undefined) {
+    if (
+      !DATE_PATTERN.test(definition.start || '') ||
+      !DATE_PATTERN.test(definition.end || '')
+    ) {
+      return `${label}: start and end must both be YYYY-MM-DD`;
+    }
+    if ((definition.start as string) > (definition.end as string)) {
+      return `${label}: start must not be after end`;
+    }
+  }
+  if (definition.key !== undefined && !definition.key.trim()) {
+    return `${label}: key must not be empty`;
+  }
+  return null;
+}
+
+function withKeys(
+  definitions: Record<string, MetricsTimeframeDefinition>
+): Record<string, MetricsTimeframeConfig> {
+  return Object.fromEntries(
+    Object.entries(definitions).map(([name, definition]) => [
+      name,
+      { ...definition, name, key: definition.key || name },
+    ])
+  );
+}
+
+/**
+ * Built-in timeframes merged with the timeframes file, cached until the
+ * file changes on disk.
+ *
+ * @returns Timeframes by name
+ * @throws Error when the file is invalid
+ */
+export function loadMetricsTimeframes(): Record<
+  string,
+  MetricsTimeframeConfig
+> {
+  const file = path.resolve(
+    process.cwd(),
+    process.env.METRICS_TIMEFRAMES_FILE || DEFAULT_TIMEFRAMES_FILE
+  );
+  if (!fs.existsSync(file)) return withKeys(BUILT_IN_METRICS_TIMEFRAMES);
+
+  const { mtimeMs } = fs.statSync(file);
+  if (
+    timeframesCache &&
+    timeframesCache.file === file &&
+    timeframesCache.mtimeMs === mtimeMs
+  ) {
+    return timeframesCache.timeframes;
+  }
+
+  const configured = JSON.parse(fs.readFileSync(file, 'utf8')) as Record<
+    string,
+    MetricsTimeframeDefinition
+  >;
+  const invalid = Object.entries(configured)
+    .map(([name, definition]) => validateDefinition(definition, `'${name}'`))
+    .find(Boolean);
+  if (invalid) throw new Error(`${file}: ${invalid}`);
+
+  const timeframes = withKeys({
+    ...BUILT_IN_METRICS_TIMEFRAMES,
+    ...configured,
+  });
+  const keys = Object.values(timeframes).map(timeframe => timeframe.key);
+  const duplicate = keys.find((key, index) => keys.indexOf(key) !== index);
+  if (duplicate) {
+    throw new Error(`${file}: two timeframes use the key '${duplicate}'`);
+  }
+
+  timeframesCache = { file, mtimeMs, timeframes };
+  return timeframes;
+}
+
+// ============================================================================
+// Ranges
+// ============================================================================
+
+/**
+ * UTC midnight of a calendar date, as the gaming day helpers expect.
+ */
+function calendarDate(year: number, month: number, day: number): Date {
+  return new Date(Date.UTC(year, month, day));
+}
+
+/**
+ * Range of a timeframe for a location.
+ *
+ * @param timeframe - Timeframe (see loadMetricsTimeframes)
+ * @param gameDayOffset - Location's gaming day start hour (default 8)
+ * @returns Gaming day aligned range in UTC
+ */
+export function getMetricsTimeframeRange(
+  timeframe: MetricsTimeframeDefinition,
+  gameDayOffset: number = 8
+): GamingDayRange {
+  if (timeframe.start && timeframe.end) {
+    return {
+      rangeStart: getGamingDayRange(new Date(timeframe.start), gameDayOffset)
+        .rangeStart,
+      rangeEnd: getGamingDayRange(new Date(timeframe.end), gameDayOffset)
+        .rangeEnd,
+    };
+  }
+
+  const period = timeframe.period;
+  if (
+    period === 'Today' ||
+    period === 'Yesterday' ||
+    period === '7d' ||
+    period === '30d'
+  ) {
+    return getGamingDayRangeForPeriod(period, gameDayOffset);
+  }
+
+  const today = getGamingDayRangeForPeriod('Today', gameDayOffset);
+  if (timeframe.days) {
+    return {
+      rangeStart: new Date(
+        today.rangeStart.getTime() - (timeframe.days - 1) * DAY_MS
+      ),
+      rangeEnd: today.rangeEnd,
+    };
+  }
+
+  // Local noon of the current gaming day falls on its calendar date in UTC
+  // for any timezone within ±12h
+  const current = new Date(
+    today.rangeStart.getTime() + (12 - gameDayOffset) * 60 * 60 * 1000
+  );
+  const year = current.getUTCFullYear();
+  const month = current.getUTCMonth();
+  if (period === 'monthToDate') {
+    return {
+      rangeStart: getGamingDayRange(calendarDate(year, month, 1), gameDayOffset)
+        .rangeStart,
+      rangeEnd: today.rangeEnd,
+    };
+  }
+  // previousMonth: day 0 of this month is the last day of the previous one
+  return {
+    rangeStart: getGamingDayRange(
+      calendarDate(year, month - 1, 1),
+      gameDayOffset
+    ).rangeStart,
+    rangeEnd: getGamingDayRange(calendarDate(year, month, 0), gameDayOffset)
+      .rangeEnd,
+  };
+}
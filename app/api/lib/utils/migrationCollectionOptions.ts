@@ -0,0 +1,372 @@
+/**
+ * Migration Collection Options
+ *
+ * Documents alone do not carry a collection's shape: its indexes, validator,
+ * collation, capped size or, for views, the pipeline. The migration export
+ * reads these from the source into `collection-options.json` next to the
+ * documents, and `bun run migration:options` recreates them on the
+ * destination, reporting which collections, views and indexes it created.
+ *
+ * Options that cannot change after creation (collation, capped, size, max,
+ * timeseries) are only applied when the destination collection does not
+ * exist yet; a differing existing collection is reported, not recreated.
+ * Validators are updated in place with `collMod`.
+ *
+ * @module app/api/lib/utils/migrationCollectionOptions
+ */
+
+import fs from 'fs/promises';
+import type { mongo } from 'mongoose';
+import path from 'path';
+import { assertWritable } from '@/app/api/lib/utils/safetyMode';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MigrationIndexDefinition = {
+  name: string;
+  key: Record<string, unknown>;
+  /** Index options (unique, sparse, partialFilterExpression, ...) */
+  [option: string]: unknown;
+};
+
+export type MigrationCollectionDefinition = {
+  /** Collection or view name, the same on source and destination */
+  collection: string;
+  type: 'collection' | 'view';
+  /** Copied creation options (see COPIED_COLLECTION_OPTIONS) */
+  options: Record<string, unknown>;
+  /** Index definitions, without the default _id index */
+  indexes: MigrationIndexDefinition[];
+};
+
+export type MigrationCollectionResult = {
+  collection: string;
+  type: 'collection' | 'view';
+  /** Whether the collection or view was created on the destination */
+  created: boolean;
+  validatorUpdated: boolean;
+  indexesCreated: string[];
+  indexesExisting: string[];
+  indexesFailed: Array<{ name: string; error: string }>;
+  warnings: string[];
+};
+
+/** File the export writes the definitions to */
+export const COLLECTION_OPTIONS_FILE = 'collection-options.json';
+
+/** listCollections options copied to the destination */
+export const COPIED_COLLECTION_OPTIONS = [
+  'validator',
+  'validationLevel',
+  'validationAction',
+  'collation',
+  'capped',
+  'size',
+  'max',
+  'timeseries',
+  'expireAfterSeconds',
+  'viewOn',
+  'pipeline',
+];
+
+/** Options a collection only takes at creation */
+const CREATION_ONLY_OPTIONS = [
+  'collation',
+  'capped',
+  'size',
+  'max',
+  'timeseries',
+  'expireAfterSeconds',
+];
+
+/** Index fields that are metadata, not creation options */
+const INDEX_METADATA_FIELDS = ['v', 'ns', 'key', 'name'];
+
+function pickOptions(options: Record<string, unknown> = {}) {
+  const picked: Record<string, unknown> = {};
+  COPIED_COLLECTION_OPTIONS.forEach(option => {
+    if (options[option] !== undefined) picked[option] = options[option];
+  });
+  return picked;
+}
+
+function indexOptions(index: MigrationIndexDefinition) {
+  const options: Record<string, unknown> = { name: index.name };
+  Object.entries(index).forEach(([field, value]) => {
+    if (!INDEX_METADATA_FIELDS.includes(field)) options[field] = value;
+  });
+  return options;
+}
+
+function sameKey(a: Record<string, unknown>, b: Record<string, unknown>) {
+  return JSON.stringify(a) === JSON.stringify(b);
+}
+
+// ============================================================================
+// Reading (source)
+// ============================================================================
+
+/**
+ * Reads a collection's creation options and indexes, or null when the
+ * collection does not exist.
+ *
+ * @param db - Source database
+ * @param collection - Collection name
+ */
+export async function readCollectionDefinition(
+  db: mongo.Db,
+  collection: string
+): Promise<MigrationCollectionDefinition | null> {
+  const [info] = await db.listCollections({ name: collection }).toArray();
+  if (!info) return null;
+
+  const type = info.type === 'view' ? 'view' : 'collection';
+  const indexes =
+    type === 'view'
+      ? []
+      : (
+          (await db
+            .collection(collection)
+            .indexes()) as unknown as MigrationIndexDefinition[]
+        ).filter(index => index.name !== '_id_');
+  return {
+    collection,
+    type,
+    options: pickOptions(
+      (info as { options?: Record<string, unknown> }).options
+    ),
+    indexes,
+  };
+}
+
+/**
+ * Definitions of every view in the source database.
+ */
+export async function readViewDefinitions(
+  db: mongo.Db
+): Promise<MigrationCollectionDefinition[]> {
+  const views = await db.listCollections({ type: 'view' }).toArray();
+  return views.map(view => ({
+    collection: view.name,
+    type: 'view' as const,
+    options: pickOptions(
+      (view as { options?: Record<string, unknown> }).options
+    ),
+    indexes: [],
+  }));
+}
+
+/**
+ * Writes the definitions to `collection-options.json` in the export directory.
+ *
+ * @returns The file path
+ */
+export async function writeCollectionDefinitions(
+  dir: string,
+  definitions: MigrationCollectionDefinition[]
+): Promise<string> {
+  const file = path.join(dir, COLLECTION_OPTIONS_FILE);
+  await fs.writeFile(file, JSON.stringify(definitions, null, 2));
+  return file;
+}
+
+/**
+ * Reads the definitions an export wrote.
+ */
+export async function readCollectionDefinitionsFile(
+  dir: string
+): Promise<MigrationCollectionDefinition[]> {
+  const raw = await fs.readFile(
+    path.join(dir, COLLECTION_OPTIONS_FILE),
+    'utf8'
+  );
+  const parsed = JSON.parse(raw) as unknown;
+  if (!Array.isArray(parsed)) {
+    throw new Error(`${COLLECTION_OPTIONS_FILE} must be an array`);
+  }
+  return parsed as MigrationCollectionDefinition[];
+}
+
+// ============================================================================
+// Applying (destination)
+// ============================================================================
+
+/**
+ * Creates missing collections and views, updates validators and creates
+ * missing indexes on the destination. Collections are applied before views
+ * so a view's source exists first.
+ *
+ * @param db - Destination database
+ * @param definitions - Definitions from the export
+ * @param dryRun - Report what would change without writing
+ */
+export async function applyCollectionDefinitions(
+  db: mongo.Db,
+  definitions: MigrationCollectionDefinition[],
+  dryRun = false
+): Promise<MigrationCollectionResult[]> {
+  if (!dryRun) assertWritable('applying migration collection options');
+
+  const ordered = [
+    ...definitions.filter(definition => definition.type !== 'view'),
+    ...definitions.filter(definition => definition.type === 'view'),
+  ];
+  const results: MigrationCollectionResult[] = [];
+  for (const definition of ordered) {
+    const result: MigrationCollectionResult = {
+      collection: definition.collection,
+      type: definition.type,
+      created: false,
+      validatorUpdated: false,
+      indexesCreated: [],
+      indexesExisting: [],
+      indexesFailed: [],
+      warnings: [],
+    };
+    results.push(result);
+
+    // Step 1: Collection or view
+    const [existing] = await db
+      .listCollections({ name: definition.collection })
+      .toArray();
+    const existingOptions =
+      (existing as { options?: Record<string, unknown> } | undefined)
+        ?.options || {};
+    if (!existing) {
+      if (!dryRun) {
+        await db.createCollection(
+          definition.collection,
+          definition.options as mongo.CreateCollectionOptions
+        );
+      }
+      result.created = true;
+    } else if (definition.type === 'view') {
+      const { viewOn, pipeline } = definition.options;
+      const current = {
+        viewOn: existingOptions.viewOn,
+        pipeline: existingOptions.pipeline,
+      };
+      if (!sameKey(current, { viewOn, pipeline })) {
+        result.warnings.push('view exists with a different definition');
+      }
+    } else {
+      CREATION_ONLY_OPTIONS.forEach(option => {
+        if (
+          definition.options[option] !== undefined &&
+          !sameKey(
+            { value: existingOptions[option] },
+            { value: definition.options[option] }
+          )
+        ) {
+          result.warnings.push(
+            `${option} differs; it is only set when the collection is created`
+          );
+        }
+      });
+      if (
+        definition.options.validator &&
+        !sameKey(
+          { validator: existingOptions.validator },
+          { validator: definition.options.validator }
+        )
+      ) {
+        if (!dryRun) {
+          await db.command({
+            collMod: definition.collection,
+            validator: definition.options.validator,
+            ...(definition.options.validationLevel
+              ? { validationLevel: definition.options.validationLevel }
+              : {}),
+            ...(definition.options.validationAction
+              ? { validationAction: definition.options.validationAction }
+              : {}),
+          });
+        }
+        result.validatorUpdated = true;
+      }
+    }
+    if (definition.type === 'view') continue;
+
+    // Step 2: Indexes, matched by name or key
+    const currentIndexes = existing
+      ? ((await db
+          .collection(definition.collection)
+          .indexes()) as unknown as MigrationIndexDefinition[])
+      : [];
+    for (const index of definition.indexes) {
+      const match = currentIndexes.find(
+        current =>
+          current.name === index.name || sameKey(current.key, index.key)
+      );
+      if (match) {
+        result.indexesExisting.push(index.name);
+        continue;
+      }
+      if (dryRun) {
+        result.indexesCreated.push(index.name);
+        continue;
+      }
+      try {
+        await db
+          .collection(definition.collection)
+          .createIndex(
+            index.key as mongo.IndexSpecification,
+            indexOptions(index) as mongo.CreateIndexesOptions
+          );
+        result.indexesCreated.push(index.name);
+      } catch (error) {
+        result.indexesFailed.push({
+          name: index.name,
+          error: error instanceof Error ? error.message : String(error),
+        });
+      }
+    }
+  }
+  return results;
+}
+
+// ============================================================================
+// Output
+// ============================================================================
+
+/**
+ * Plain-text report of an apply run.
+ */
+export function formatCollectionDefinitionResults(
+  results: MigrationCollectionResult[],
+  dryRun = false
+): string {
+  const verb = dryRun ? 'would create' : 'created';
+  const lines = results.map(result => {
+    const parts = [
+      `${result.collection} (${result.type})${result.created ? ` ${verb}` : ''}`,
+    ];
+    if (result.validatorUpdated) {
+      parts.push(dryRun ? 'validator would update' : 'validator updated');
+    }
+    if (result.type === 'collection') {
+      parts.push(
+        `indexes ${verb}: ${result.indexesCreated.join(', ') || '-'}; existing: ${result.indexesExisting.length}`
+      );
+    }
+    result.indexesFailed.forEach(failure =>
+      parts.push(`FAILED ${failure.name}: ${failure.error}`)
+    );
+    result.warnings.forEach(warning => parts.push(`warning: ${warning}`));
+    return parts.join('\n    ');
+  });
+  const created = results.reduce(
+    (sum, result) => sum + result.indexesCreated.length,
+    0
+  );
+  const failed = results.reduce(
+    (sum, result) => sum + result.indexesFailed.length,
+    0
+  );
+  return [
+    ...lines,
+    `${results.length} collection(s); ${created} index(es) ${verb}, ${failed} failed`,
+  ].join('\n');
+}
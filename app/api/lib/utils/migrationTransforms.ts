@@ -0,0 +1,339 @@
+/**
+ * Migration Transforms
+ *
+ * Rewrites documents on their way out of the source database during a
+ * migration, before they are written to the export. Each collection can have:
+ *
+ * - Declarative rules from `migration-transforms.json` at the project root, or
+ *   the file named by `MIGRATION_TRANSFORMS_FILE`; copy
+ *   `migration-transforms.example.json` to start:
+ *
+ * ```json
+ * {
+ *   "machines": {
+ *     "drop": ["legacyFlags"],
+ *     "rename": { "assetNo": "assetNumber" },
+ *     "coerce": { "sasVersion": "string", "installDate": "date" }
+ *   }
+ * }
+ * ```
+ *
+ * - A code hook in `MIGRATION_TRANSFORM_HOOKS`, for rewrites a rule cannot
+ *   express (e.g. generating a licence key for licencees without one).
+ *
+ * `fields` keeps only the listed fields; it is also passed to the source
+ * query as a projection (see getMigrationProjection), so unused legacy
+ * fields of large collections such as meters are never transferred.
+ *
+ * Rules apply in the order fields, drop, rename, coerce, then the hook runs.
+ * Field names may be dotted paths into nested objects. The file is optional:
+ * without it only the hooks run.
+ *
+ * @module app/api/lib/utils/migrationTransforms
+ */
+
+import fs from 'fs';
+import path from 'path';
+import { generateUniqueLicenceKey } from '@/app/api/lib/utils/licenceKey';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type MigrationCoercion = 'string' | 'number' | 'boolean' | 'date';
+
+export type MigrationTransformRule = {
+  /** Only these fields (and _id) are read from the source and copied */
+  fields?: string[];
+  /** Fields removed from each document */
+  drop?: string[];
+  /** Old field name → new field name */
+  rename?: Record<string, string>;
+  /** Field → type its value is converted to */
+  coerce?: Record<string, MigrationCoercion>;
+};
+
+/** Rules keyed by exported collection name */
+export type MigrationTransformRules = Record<string, MigrationTransformRule>;
+
+export type MigrationDocument = Record<string, unknown>;
+
+export type MigrationDocumentHook = (
+  document: MigrationDocument
+) => MigrationDocument | Promise<MigrationDocument>;
+
+export const MIGRATION_COERCIONS: MigrationCoercion[] = [
+  'string',
+  'number',
+  'boolean',
+  'date',
+];
+
+/** Licence key the seed data uses for licencees that still need one */
+export const LICENCE_KEY_PLACEHOLDER = 'LIC-CABANA-AUTO-GEN';
+
+/**
+ * Code hooks per exported collection, run after the declarative rules.
+ */
+export const MIGRATION_TRANSFORM_HOOKS: Record<string, MigrationDocumentHook> =
+  {
+    licencees: async document => {
+      const key = document.licenceKey;
+      if (typeof key === 'string' && key && key !== LICENCE_KEY_PLACEHOLDER) {
+        return document;
+      }
+      return { ...document, licenceKey: await generateUniqueLicenceKey() };
+    },
+  };
+
+const DEFAULT_TRANSFORMS_FILE = 'migration-transforms.json';
+
+let rulesCache: {
+  file: string;
+  mtimeMs: number;
+  rules: MigrationTransformRules;
+} | null = null;
+
+// ============================================================================
+// Loading
+// ============================================================================
+
+function validateRule(
+  rule: MigrationTransformRule,
+  label: string
+): string | null {
+  if (
+    rule.fields !== undefined &&
+    (!Array.isArray(rule.fields) ||
+      rule.fields.some(field => typeof field !== 'string' || !field))
+  ) {
+    return `${label}: fields must be a list of field names`;
+  }
+  if (rule.drop !== undefined && !Array.isArray(rule.drop)) {
+    return `${label}: drop must be a list of field names`;
+  }
+  for (const [from, to] of Object.entries(rule.rename || {})) {
+    if (typeof to !== 'string' || !to) {
+      return `${label}: rename of '${from}' needs a new field name`;
+    }
+  }
+  for (const [field, type] of Object.entries(rule.coerce || {})) {
+    if (!MIGRATION_COERCIONS.includes(type)) {
+      return `${label}: unknown type '${type}' for '${field}'`;
+    }
+  }
+  return null;
+}
+
+/**
+ * Reads the rules file, cached until it changes on disk.
+ *
+ * @returns Rules per collection; empty when the file does not exist
+ * @throws Error when the file is invalid
+ */
+export function loadMigrationTransformRules(): MigrationTransformRules {
+  const file = path.resolve(
+    process.cwd(),
+    process.env.MIGRATION_TRANSFORMS_FILE || DEFAULT_TRANSFORMS_FILE
+  );
+  if (!fs.existsSync(file)) return {};
+
+  const { mtimeMs } = fs.statSync(file);
+  if (
+    rulesCache &&
+    rulesCache.file === file &&
+    rulesCache.mtimeMs === mtimeMs
+  ) {
+    return rulesCache.rules;
+  }
+
+  const rules = JSON.parse(
+    fs.readFileSync(file, 'utf8')
+  ) as MigrationTransformRules;
+  const invalid = Object.entries(rules)
+    .map(([collection, rule]) => validateRule(rule, `'${collection}'`))
+    .find(Boolean);
+  if (invalid) throw new Error(`${file}: ${invalid}`);
+
+  rulesCache = { file, mtimeMs, rules };
+  return rules;
+}
+
+// ============================================================================
+// Transforms
+// ============================================================================
+
+/** Copies plain objects and arrays; dates, ids and other values are shared */
+function clonePlain<T>(value: T): T {
+  if (Array.isArray(value)) return value.map(clonePlain) as T;
+  if (value && Object.getPrototypeOf(value) === Object.prototype) {
+    return Object.fromEntries(
+      Object.entries(value).map(([key, entry]) => [key, clonePlain(entry)])
+    ) as T;
+  }
+  return value;
+}
+
+function getParent(
+  document: MigrationDocument,
+  field: string,
+  create: boolean
+): { parent: MigrationDocument; key: string } | null {
+  const parts = field.split('.');
+  let parent = document;
+  for (const part of parts.slice(0, -1)) {
+    const next = parent[part];
+    if (next && typeof next === 'object' && !Array.isArray(next)) {
+      parent = next as MigrationDocument;
+    } else if (create) {
+      parent = parent[part] = {};
+    } else {
+      return null;
+    }
+  }
+  return { parent, key: parts[parts.length - 1] };
+}
+
+/**
+ * Converts a value to a type. Null and undefined are left as they are.
+ *
+ * @throws Error when the value cannot be converted
+ */
+export function coerceMigrationValue(
+  value: unknown,
+  type: MigrationCoercion
+): unknown {
+  if (value === null || value === undefined) return value;
+  switch (type) {
+    case 'string':
+      return value instanceof Date ? value.toISOString() : String(value);
+    case 'number': {
+      const number = value instanceof Date ? value.getTime() : Number(value);
+      if (value === '' || !Number.isFinite(number)) {
+        throw new Error(`cannot convert '${String(value)}' to a number`);
+      }
+      return number;
+    }
+    case 'boolean':
+      if (typeof value === 'boolean') return value;
+      if (value === 'true' || value === 1 || value === '1') return true;
+      if (value === 'false' || value === 0 || value === '0') return false;
+      throw new Error(`cannot convert '${String(value)}' to a boolean`);
+    case 'date': {
+      const date = new Date(value as string | number | Date);
+      if (Number.isNaN(date.getTime())) {
+        throw new Error(`cannot convert '${String(value)}' to a date`);
+      }
+      return date;
+    }
+  }
+}
+
+/**
+ * Applies one collection's declarative rules to a document. The input is not
+ * modified.
+ *
+ * @param document - Source document
+ * @param rule - Rules for its collection
+ * @returns The rewritten document
+ * @throws Error when a value cannot be coerced
+ */
+export function transformMigrationDocument(
+  document: MigrationDocument,
+  rule: MigrationTransformRule
+): MigrationDocument {
+  let result = clonePlain(document);
+
+  if (rule.fields) {
+    const kept: MigrationDocument = { _id: result._id };
+    for (const field of rule.fields) {
+      const source = getParent(result, field, false);
+      if (!source || !(source.key in source.parent)) continue;
+      const target = getParent(kept, field, true);
+      if (target) target.parent[target.key] = source.parent[source.key];
+    }
+    result = kept;
+  }
+
+  for (const field of rule.drop || []) {
+    const target = getParent(result, field, false);
+    if (target) delete target.parent[target.key];
+  }
+
+  for (const [from, to] of Object.entries(rule.rename || {})) {
+    const source = getParent(result, from, false);
+    if (!source || !(source.key in source.parent)) continue;
+    const value = source.parent[source.key];
+    delete source.parent[source.key];
+    const target = getParent(result, to, true);
+    if (target) target.parent[target.key] = value;
+  }
+
+  for (const [field, type] of Object.entries(rule.coerce || {})) {
+    const target = getParent(result, field, false);
+    if (!target || !(target.key in target.parent)) continue;
+    try {
+      target.parent[target.key] = coerceMigrationValue(
+        target.parent[target.key],
+        type
+      );
+    } catch (error) {
+      throw new Error(
+        `${field} of document ${String(result._id)}: ${
+          error instanceof Error ? error.message : error
+        }`
+      );
+    }
+  }
+
+  return result;
+}
+
+/**
+ * Source query projection for a collection with a `fields` rule, or
+ * undefined to read whole documents.
+ *
+ * @param collection - Exported collection name (the rules file key)
+ * @param required - Fields the migration itself reads, always included
+ * @param rules - Rules to use (default: the rules file)
+ */
+export function getMigrationProjection(
+  collection: string,
+  required: string[] = [],
+  rules: MigrationTransformRules = loadMigrationTransformRules()
+): Record<string, 1> | undefined {
+  const fields = rules[collection]?.fields;
+  if (!fields) return undefined;
+  // MongoDB refuses a projection holding both a field and its subfield
+  const all = Array.from(new Set(['_id', ...fields, ...required]));
+  return Object.fromEntries(
+    all
+      .filter(field => !all.some(other => field.startsWith(`${other}.`)))
+      .map(field => [field, 1 as const])
+  );
+}
+
+/**
+ * Runs a collection's rules and hook over its documents.
+ *
+ * @param collection - Exported collection name (the rules file key)
+ * @param documents - Documents read from the source
+ * @param rules - Rules to apply (default: the rules file)
+ * @returns The rewritten documents, in the same order
+ */
+export async function applyMigrationTransforms<T>(
+  collection: string,
+  documents: T[],
+  rules: MigrationTransformRules = loadMigrationTransformRules()
+): Promise<MigrationDocument[]> {
+  const rule = rules[collection];
+  const hook = MIGRATION_TRANSFORM_HOOKS[collection];
+  const transformed: MigrationDocument[] = [];
+  for (const document of documents as MigrationDocument[]) {
+    const rewritten = rule
+      ? transformMigrationDocument(document, rule)
+      : document;
+    transformed.push(hook ? await hook(rewritten) : rewritten);
+  }
+  return transformed;
+}
@@ -0,0 +1,201 @@
+/**
+ * Mixed `_id` Type Utilities
+ *
+ * Our schemas declare `_id` (and references like `gamingLocation`, `machine`,
+ * `rel.licencee`) as String, but older and migrated documents still carry
+ * ObjectIds. A string never equals an ObjectId in a query or `$lookup`, so
+ * those documents are silently missed. These helpers match both forms.
+ *
+ * Note: Mongoose casts `find()` filters to the schema type, so an ObjectId in
+ * a `find({ _id })` on a String `_id` is turned back into a string. Use
+ * `findByAnyIdType` (aggregation, no casting) or a raw collection query when
+ * ObjectId-typed documents must be found.
+ *
+ * @module app/api/lib/utils/mongoIds
+ */
+
+import { Types } from 'mongoose';
+import type { Model, PipelineStage } from 'mongoose';
+
+// ============================================================================
+// Normalization
+// ============================================================================
+
+export const OBJECT_ID_PATTERN = /^[0-9a-fA-F]{24}$/;
+
+/**
+ * Whether a value is a 24-character hex string that can be an ObjectId.
+ */
+export function isObjectIdHex(value: unknown): value is string {
+  return typeof value === 'string' && OBJECT_ID_PATTERN.test(value);
+}
+
+/**
+ * Normalizes an id of any stored form (string, ObjectId, `{ $oid }`,
+ * populated `{ _id }`, number) to its string form.
+ *
+ * @returns The string id, or null when the value is empty or not an id
+ */
+export function normalizeId(value: unknown): string | null {
+  if (value === null || value === undefined) return null;
+  if (typeof value === 'string') return value.trim() || null;
+  if (typeof value === 'number') return String(value);
+  if (value instanceof Types.ObjectId) return value.toHexString();
+  if (typeof value === 'object') {
+    const record = value as Record<string, unknown>;
+    if (typeof record.$oid === 'string') return record.$oid;
+    if ('_id' in record) return normalizeId(record._id);
+    if (record._bsontype === 'ObjectId' || record._bsontype === 'ObjectID') {
+      return String(value);
+    }
+  }
+  return null;
+}
+
+/**
+ * Every stored form an id may have: the string, plus the ObjectId when the
+ * string is valid hex.
+ */
+export function idVariants(value: unknown): Array<string | Types.ObjectId> {
+  const id = normalizeId(value);
+  if (!id) return [];
+  return isObjectIdHex(id) ? [id, new Types.ObjectId(id)] : [id];
+}
+
+/**
+ * `$in` condition matching the given ids in either stored form. Only
+ * effective in aggregations and raw collection queries (see module note).
+ */
+export function anyIdTypeIn(values: unknown[]): {
+  $in: Array<string | Types.ObjectId>;
+} {
+  return { $in: values.flatMap(value => idVariants(value)) };
+}
+
+// ============================================================================
+// Queries
+// ============================================================================
+
+/**
+ * Fetches documents by id regardless of whether their `_id` is stored as a
+ * string or an ObjectId. Runs as an aggregation to bypass query casting.
+ *
+ * @param model - Model to query
+ * @param ids - Ids in any form
+ * @param options - Extra `$match` conditions and a projection
+ */
+export async function findByAnyIdType<T>(
+  model: Model<unknown>,
+  ids: unknown[],
+  options: {
+    match?: Record<string, unknown>;
+    projection?: Record<string, 0 | 1>;
+  } = {}
+): Promise<T[]> {
+  const match = anyIdTypeIn(ids);
+  if (match.$in.length === 0) return [];
+
+  const pipeline: PipelineStage[] = [
+    { $match: { _id: match, ...options.match } },
+  ];
+  if (options.projection) pipeline.push({ $project: options.projection });
+  return model.aggregate<T>(pipeline);
+}
+
+/**
+ * `$lookup` stage that joins on an id stored as a string on one side and an
+ * ObjectId on the other. The string comparison cannot use an index, so keep
+ * it to small foreign collections (locations, licencees, countries) and to
+ * pipelines with one row per machine or location; per meter or session use
+ * `anyIdTypeLookup`.
+ *
+ * @param options.from - Foreign collection
+ * @param options.localField - Field holding the reference
+ * @param options.foreignField - Foreign field (default `_id`)
+ * @param options.as - Output array field
+ * @param options.match - Extra conditions on the foreign documents
+ * @param options.pipeline - Extra stages to run on the joined documents
+ */
+export function mixedIdLookup(options: {
+  from: string;
+  localField: string;
+  foreignField?: string;
+  as: string;
+  match?: Record<string, unknown>;
+  pipeline?: PipelineStage.Lookup['$lookup']['pipeline'];
+}): PipelineStage.Lookup {
+  const foreignField = `$${options.foreignField || '_id'}`;
+  return {
+    $lookup: {
+      from: options.from,
+      let: { lookupId: `$${options.localField}` },
+      pipeline: [
+        {
+          $match: {
+            $expr: {
+              $or: [
+                { $eq: [foreignField, '$$lookupId'] },
+                {
+                  $eq: [
+                    { $toString: foreignField },
+                    { $toString: '$$lookupId' },
+                  ],
+                },
+              ],
+            },
+            ...options.match,
+          },
+        },
+        ...(options.pipeline || []),
+      ],
+      as: options.as,
+    },
+  };
+}
+
+/**
+ * `$lookup` stages joining on an id stored as a string on one side and an
+ * ObjectId on the other while still using the foreign field's index: the
+ * reference is first expanded to both forms (as `idVariants` does), then
+ * joined by equality. Spread the result into the pipeline.
+ *
+ * @param options.from - Foreign collection
+ * @param options.localField - Field holding the reference
+ * @param options.foreignField - Foreign field (default `_id`)
+ * @param options.as - Output array field
+ */
+export function anyIdTypeLookup(options: {
+  from: string;
+  localField: string;
+  foreignField?: string;
+  as: string;
+}): PipelineStage[] {
+  const reference = `$${options.localField}`;
+  const variantsField = `${options.as}LookupIds`;
+  return [
+    {
+      $addFields: {
+        [variantsField]: [
+          { $toString: reference },
+          {
+            $convert: {
+              input: reference,
+              to: 'objectId',
+              onError: null,
+              onNull: null,
+            },
+          },
+        ],
+      },
+    },
+    {
+      $lookup: {
+        from: options.from,
+        localField: variantsField,
+        foreignField: options.foreignField || '_id',
+        as: options.as,
+      },
+    },
+    { $unset: variantsField },
+  ];
+}
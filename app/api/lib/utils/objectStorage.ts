@@ -0,0 +1,246 @@
+/**
+ * Object Storage
+ *
+ * Lets commands write large outputs straight to an S3-compatible bucket (AWS
+ * S3, MinIO) instead of the local disk, and read them back, wherever they take
+ * a path: a destination of the form `s3://<bucket>/<key>` is streamed with a
+ * multipart upload, so nothing is staged on disk or held in memory beyond one
+ * part per concurrent upload.
+ *
+ * Configuration:
+ * - `OBJECT_STORAGE_ENDPOINT` — MinIO or other S3-compatible endpoint (unset
+ *   for AWS S3); path-style addressing is used when set
+ * - `OBJECT_STORAGE_REGION` — default `us-east-1`
+ * - `OBJECT_STORAGE_ACCESS_KEY_ID` / `OBJECT_STORAGE_SECRET_ACCESS_KEY` —
+ *   resolved through `getSecret()`; unset uses the AWS default credential chain
+ * - `OBJECT_STORAGE_PART_SIZE_MB` — multipart part size (default 16, min 5)
+ *
+ * Uses the optional `@aws-sdk/client-s3` and `@aws-sdk/lib-storage`
+ * packages, loaded only when an `s3://` location is used.
+ *
+ * @module app/api/lib/utils/objectStorage
+ */
+
+import fs from 'fs';
+import path from 'path';
+import { PassThrough, Readable } from 'stream';
+import type { S3Client } from '@aws-sdk/client-s3';
+import { importOptional } from '@/app/api/lib/utils/optionalImport';
+import { getSecret } from '@/app/api/lib/utils/secrets';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+type AwsS3Module = typeof import('@aws-sdk/client-s3');
+type AwsStorageModule = typeof import('@aws-sdk/lib-storage');
+
+export type ObjectLocation = {
+  bucket: string;
+  key: string;
+};
+
+export type ObjectUpload = {
+  /** Write the object's content here, then end it */
+  stream: PassThrough;
+  /** Resolves once the upload is complete */
+  done: Promise<void>;
+};
+
+const AWS_S3_MODULE = '@aws-sdk/client-s3';
+const AWS_STORAGE_MODULE = '@aws-sdk/lib-storage';
+const OBJECT_URL_PATTERN = /^s3:\/\/([^/]+)\/?(.*)$/;
+const MIN_PART_SIZE_MB = 5;
+const DEFAULT_PART_SIZE_MB = 16;
+const UPLOAD_QUEUE_SIZE = 4;
+
+let clientPromise: Promise<S3Client> | null = null;
+
+// ============================================================================
+// Locations
+// ============================================================================
+
+/**
+ * Whether a path names a bucket object (`s3://bucket/key`).
+ */
+export function isObjectStorageUrl(value: string): boolean {
+  return OBJECT_URL_PATTERN.test(value);
+}
+
+/**
+ * @throws Error when the value is not `s3://<bucket>/<key>`
+ */
+export function parseObjectStorageUrl(value: string): ObjectLocation {
+  const match = value.match(OBJECT_URL_PATTERN);
+  if (!match) throw new Error(`Not an object storage URL: ${value}`);
+  return { bucket: match[1], key: match[2] };
+}
+
+/**
+ * Appends a name to a local directory or `s3://` prefix.
+ */
+export function joinStoragePath(base: string, name: string): string {
+  if (isObjectStorageUrl(base)) {
+    return `${base.replace(/\/+$/, '')}/${name}`;
+  }
+  return path.join(base, name);
+}
+
+// ============================================================================
+// Client
+// ============================================================================
+
+function getPartSizeBytes(): number {
+  const configured = Number(process.env.OBJECT_STORAGE_PART_SIZE_MB);
+  const megabytes =
+    Number.isFinite(configured) && configured > 0
+      ? Math.max(configured, MIN_PART_SIZE_MB)
+      : DEFAULT_PART_SIZE_MB;
+  return Math.round(megabytes * 1024 * 1024);
+}
+
+async function createClient(): Promise<S3Client> {
+  const s3 = await importOptional<AwsS3Module>(AWS_S3_MODULE, 'Object storage');
+  const [accessKeyId, secretAccessKey] = await Promise.all([
+    getSecret('OBJECT_STORAGE_ACCESS_KEY_ID'),
+    getSecret('OBJECT_STORAGE_SECRET_ACCESS_KEY'),
+  ]);
+  const endpoint = process.env.OBJECT_STORAGE_ENDPOINT?.trim();
+  return new s3.S3Client({
+    region: process.env.OBJECT_STORAGE_REGION?.trim() || 'us-east-1',
+    ...(endpoint ? { endpoint, forcePathStyle: true } : {}),
+    ...(accessKeyId && secretAccessKey
+      ? { credentials: { accessKeyId, secretAccessKey } }
+      : {}),
+  });
+}
+
+function getClient(): Promise<S3Client> {
+  if (!clientPromise) {
+    clientPromise = createClient().catch(error => {
+      clientPromise = null;
+      throw error;
+    });
+  }
+  return clientPromise;
+}
+
+// ============================================================================
+// Upload & Download
+// ============================================================================
+
+/**
+ * Starts a streamed multipart upload to `s3://bucket/key`. Write to
+ * `stream` and end it; `done` settles when the object is complete. A failed
+ * upload is aborted so no orphaned parts are left in the bucket.
+ *
+ * @param url - Destination object
+ * @param contentType - Stored content type
+ */
+export async function createObjectUpload(
+  url: string,
+  contentType?: string
+): Promise<ObjectUpload> {
+  const { bucket, key } = parseObjectStorageUrl(url);
+  if (!key) throw new Error(`Object storage URL has no key: ${url}`);
+  const [client, storage] = await Promise.all([
+    getClient(),
+    importOptional<AwsStorageModule>(AWS_STORAGE_MODULE, 'Object storage'),
+  ]);
+
+  const stream = new PassThrough();
+  const upload = new storage.Upload({
+    client,
+    params: {
+      Bucket: bucket,
+      Key: key,
+      Body: stream,
+      ContentType: contentType,
+    },
+    partSize: getPartSizeBytes(),
+    queueSize: UPLOAD_QUEUE_SIZE,
+    leavePartsOnError: false,
+  });
+  const done = upload.done().then(
+    () => undefined,
+    error => {
+      stream.destroy();
+      throw error;
+    }
+  );
+  // Surfaced through `done`; avoid an unhandled rejection before it is awaited
+  done.catch(() => undefined);
+  return { stream, done };
+}
+
+/**
+ * Uploads a small object in one call.
+ */
+export async function writeObject(
+  url: string,
+  content: string | Buffer,
+  contentType?: string
+): Promise<void> {
+  const upload = await createObjectUpload(url, contentType);
+  upload.stream.end(content);
+  await upload.done;
+}
+
+/**
+ * Opens an object for streaming reads.
+ *
+ * @throws Error when the object does not exist or cannot be read
+ */
+export async function openObjectReadStream(url: string): Promise<Readable> {
+  const { bucket, key } = parseObjectStorageUrl(url);
+  const [client, s3] = await Promise.all([
+    getClient(),
+    importOptional<AwsS3Module>(AWS_S3_MODULE, 'Object storage'),
+  ]);
+  const response = await client.send(
+    new s3.GetObjectCommand({ Bucket: bucket, Key: key })
+  );
+  if (!(response.Body instanceof Readable)) {
+    throw new Error(`Object ${url} has no readable body`);
+  }
+  return response.Body;
+}
+
+/**
+ * Reads a whole object as UTF-8 text.
+ */
+export async function readObjectText(url: string): Promise<string> {
+  const chunks: Buffer[] = [];
+  for await (const chunk of await openObjectReadStream(url)) {
+    chunks.push(Buffer.isBuffer(chunk) ? chunk : Buffer.from(chunk));
+  }
+  return Buffer.concat(chunks).toString('utf8');
+}
+
+// ============================================================================
+// Local or bucket
+// ============================================================================
+
+/**
+ * Writes a file to a local path or an `s3://` object.
+ */
+export async function writeStorageFile(
+  target: string,
+  content: string | Buffer,
+  contentType?: string
+): Promise<void> {
+  if (isObjectStorageUrl(target)) {
+    await writeObject(target, content, contentType);
+  } else {
+    fs.writeFileSync(target, content);
+  }
+}
+
+/**
+ * Reads a local file or an `s3://` object as UTF-8 text.
+ */
+export async function readStorageFile(source: string): Promise<string> {
+  return isObjectStorageUrl(source)
+    ? readObjectText(source)
+    : fs.readFileSync(source, 'utf8');
+}
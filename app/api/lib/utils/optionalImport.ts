@@ -0,0 +1,29 @@
+/**
+ * Optional Imports
+ *
+ * Loads a package from `optionalDependencies` at runtime. The import is
+ * hidden from the bundler (`webpackIgnore`), so builds succeed whether or
+ * not the package is installed, and a missing package surfaces only when
+ * the feature that needs it is used. Callers pass the module's real type,
+ * e.g. `importOptional<typeof import('@aws-sdk/client-s3')>(...)`.
+ *
+ * @module app/api/lib/utils/optionalImport
+ */
+
+/**
+ * Imports an optional package.
+ *
+ * @param name - Package name
+ * @param feature - What needs it, for the error message
+ * @throws Error naming the package when it is not installed
+ */
+export async function importOptional<T>(
+  name: string,
+  feature: string
+): Promise<T> {
+  try {
+    return (await import(/* webpackIgnore: true */ name)) as T;
+  } catch {
+    throw new Error(`${feature} requires the optional '${name}' package`);
+  }
+}
@@ -0,0 +1,270 @@
+/**
+ * Query Time Limits and Cancellation
+ *
+ * Every mongoose aggregation gets a server-side `maxTimeMS`, so a runaway
+ * pipeline is stopped by MongoDB instead of pinning the cluster. Pipelines
+ * that already pass their own `maxTimeMS` keep it. The limit is:
+ *
+ * - API: `QUERY_MAX_TIME_MS` (default 120000, the socket timeout)
+ * - Commands: `--max-time-ms N`, else `COMMAND_MAX_TIME_MS` (default 300000)
+ * - `0` disables the limit
+ *
+ * A pipeline that runs out of time fails with a `QueryTimeoutError`
+ * (`statusCode` 504) naming the collection and the limit, instead of the
+ * driver's generic error. Commands also tag their aggregations with a
+ * `comment` and, on Ctrl-C, kill the tagged operations on the server before
+ * exiting — otherwise the server keeps running them after the client leaves.
+ * A second Ctrl-C exits immediately. API aggregations carry a per-process
+ * tag too, so a graceful shutdown can kill the ones still running after its
+ * drain timeout (see gracefulShutdown).
+ *
+ * `installQueryTimeouts()` is called by `connectDB()` and
+ * `connectCommandDatabase()`; it applies to every model whenever it was
+ * compiled.
+ *
+ * @module app/api/lib/utils/queryTimeout
+ */
+
+import mongoose from 'mongoose';
+import type { Aggregate, Connection } from 'mongoose';
+import os from 'os';
+import path from 'path';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export const DEFAULT_API_MAX_TIME_MS = 120000;
+export const DEFAULT_COMMAND_MAX_TIME_MS = 300000;
+
+/** HTTP status returned for queries stopped by their time limit */
+export const QUERY_TIMEOUT_STATUS = 504;
+
+/** MongoDB error codes for `maxTimeMS` expiry and killed operations */
+const MAX_TIME_EXPIRED_CODE = 50;
+const INTERRUPTED_CODES = [11600, 11601, 11602];
+
+export type QueryTimeoutSettings = {
+  /** Server-side limit per aggregation in ms; 0 for none */
+  maxTimeMS: number;
+  /** `comment` attached to each aggregation so it can be found and killed */
+  tag?: string;
+};
+
+export class QueryTimeoutError extends Error {
+  statusCode = QUERY_TIMEOUT_STATUS;
+  collection: string;
+  maxTimeMS: number;
+
+  constructor(collection: string, maxTimeMS: number) {
+    super(
+      `Query on ${collection} exceeded the ${maxTimeMS}ms time limit (maxTimeMS) and was stopped by the server`
+    );
+    this.name = 'QueryTimeoutError';
+    this.collection = collection;
+    this.maxTimeMS = maxTimeMS;
+  }
+}
+
+let settings: QueryTimeoutSettings = { maxTimeMS: DEFAULT_API_MAX_TIME_MS };
+let installed = false;
+let cancelHandlerInstalled = false;
+
+// ============================================================================
+// Settings
+// ============================================================================
+
+function parseLimit(value: string | undefined): number | undefined {
+  if (value === undefined || value === '') return undefined;
+  const limit = Number(value);
+  if (!Number.isInteger(limit) || limit < 0) {
+    throw new Error(`Invalid query time limit: ${value}`);
+  }
+  return limit;
+}
+
+/**
+ * Resolves a command's time limit from `--max-time-ms`, then
+ * `COMMAND_MAX_TIME_MS`.
+ *
+ * @param argv - Process arguments (default: process.argv)
+ */
+export function getCommandMaxTimeMS(argv: string[] = process.argv): number {
+  const index = argv.findIndex(
+    arg => arg === '--max-time-ms' || arg.startsWith('--max-time-ms=')
+  );
+  const flag =
+    index === -1
+      ? undefined
+      : argv[index].includes('=')
+        ? argv[index].slice(argv[index].indexOf('=') + 1)
+        : argv[index + 1];
+  return (
+    parseLimit(flag) ??
+    parseLimit(process.env.COMMAND_MAX_TIME_MS) ??
+    DEFAULT_COMMAND_MAX_TIME_MS
+  );
+}
+
+/**
+ * Time limit for API requests: `QUERY_MAX_TIME_MS`, default 120000.
+ */
+export function getApiMaxTimeMS(): number {
+  return parseLimit(process.env.QUERY_MAX_TIME_MS) ?? DEFAULT_API_MAX_TIME_MS;
+}
+
+/**
+ * Tag of this command process's aggregations.
+ *
+ * @param argv - Process arguments (default: process.argv)
+ */
+export function getCommandQueryTag(argv: string[] = process.argv): string {
+  const command = path.basename(argv[1] || 'command').replace(/\.[jt]s$/, '');
+  return `cms-command:${command}:${process.pid}`;
+}
+
+/**
+ * Tag of this server process's API aggregations.
+ */
+export function getApiQueryTag(): string {
+  return `cms-api:${os.hostname()}:${process.pid}`;
+}
+
+/**
+ * True for MongoDB's "operation exceeded time limit" error.
+ */
+export function isTimeLimitError(error: unknown): boolean {
+  const { code, codeName } = (error || {}) as {
+    code?: number;
+    codeName?: string;
+  };
+  return code === MAX_TIME_EXPIRED_CODE || codeName === 'MaxTimeMSExpired';
+}
+
+/**
+ * True for an operation killed on the server (e.g. by Ctrl-C cancellation).
+ */
+export function isInterruptedError(error: unknown): boolean {
+  const { code } = (error || {}) as { code?: number };
+  return typeof code === 'number' && INTERRUPTED_CODES.includes(code);
+}
+
+// ============================================================================
+// Installation
+// ============================================================================
+
+/**
+ * Applies the time limit (and tag) to every mongoose aggregation. Safe to
+ * call repeatedly; later calls replace the settings.
+ *
+ * @param next - Limit and optional tag
+ */
+export function installQueryTimeouts(next: QueryTimeoutSettings): void {
+  settings = next;
+  if (installed) return;
+  installed = true;
+
+  const exec = mongoose.Aggregate.prototype.exec;
+  mongoose.Aggregate.prototype.exec = async function (
+    this: Aggregate<unknown>
+  ) {
+    const options = this.options as { maxTimeMS?: number; comment?: unknown };
+    if (options.maxTimeMS === undefined && settings.maxTimeMS > 0) {
+      this.option({ maxTimeMS: settings.maxTimeMS });
+    }
+    if (options.comment === undefined && settings.tag) {
+      this.option({ comment: settings.tag });
+    }
+
+    try {
+      return await exec.call(this);
+    } catch (error) {
+      const model = this.model();
+      const collection =
+        model?.collection?.collectionName || model?.modelName || 'unknown';
+      if (isTimeLimitError(error)) {
+        throw new QueryTimeoutError(
+          collection,
+          (this.options as { maxTimeMS?: number }).maxTimeMS ?? 0
+        );
+      }
+      if (isInterruptedError(error)) {
+        throw new Error(`Query on ${collection} was cancelled on the server`);
+      }
+      throw error;
+    }
+  } as typeof exec;
+}
+
+/**
+ * Installs the time limit, tag and Ctrl-C cancellation for a command.
+ *
+ * @param connection - Connection the command's aggregations run on
+ * @param argv - Process arguments (default: process.argv)
+ * @returns The applied settings
+ */
+export function installCommandQueryControls(
+  connection: Connection,
+  argv: string[] = process.argv
+): QueryTimeoutSettings {
+  const applied = {
+    maxTimeMS: getCommandMaxTimeMS(argv),
+    tag: getCommandQueryTag(argv),
+  };
+  installQueryTimeouts(applied);
+
+  if (!cancelHandlerInstalled) {
+    cancelHandlerInstalled = true;
+    let cancelling = false;
+    process.on('SIGINT', () => {
+      if (cancelling) process.exit(130);
+      cancelling = true;
+      console.error(
+        '\nCancelling: stopping in-flight queries on the server (Ctrl-C again to exit now)...'
+      );
+      cancelServerOperations(connection, applied.tag)
+        .then(count => {
+          console.error(`Killed ${count} server operation(s).`);
+        })
+        .catch(error => {
+          console.error(
+            'Could not kill server operations:',
+            error instanceof Error ? error.message : error
+          );
+        })
+        .finally(() => process.exit(130));
+    });
+  }
+
+  return applied;
+}
+
+// ============================================================================
+// Cancellation
+// ============================================================================
+
+/**
+ * Kills the server operations carrying a tag (needs the `inprog` and
+ * `killop` privileges, or ownership of the operations).
+ *
+ * @param connection - Connection to run `currentOp` / `killOp` on
+ * @param tag - `comment` the operations were started with
+ * @returns Number of operations killed
+ */
+export async function cancelServerOperations(
+  connection: Connection,
+  tag: string
+): Promise<number> {
+  const admin = connection.db?.admin();
+  if (!admin) return 0;
+
+  const result = (await admin.command({
+    currentOp: true,
+    'command.comment': tag,
+  })) as { inprog?: Array<{ opid: number | string }> };
+  const operations = result.inprog || [];
+  for (const operation of operations) {
+    await admin.command({ killOp: 1, op: operation.opid });
+  }
+  return operations.length;
+}
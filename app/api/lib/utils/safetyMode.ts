@@ -0,0 +1,119 @@
+/**
+ * Read-Only Mode & Destructive Operation Safety
+ *
+ * Read-only mode blocks data writes from migrations, `metersDaily`
+ * pre-aggregation, data fixes and destructive commands. It is on when the
+ * process was started with `--read-only` (scripts) or `READ_ONLY_MODE=true`
+ * (app and scripts). Writes call `assertWritable()`, which throws a 423 error
+ * while it is on.
+ *
+ * Destructive commands call `confirmDestructiveOperation()`, which prints a
+ * banner naming the target database and asks the operator to type the
+ * profile name back, unless `--yes` is passed. Without a terminal and without
+ * `--yes` the command refuses to run.
+ *
+ * @module app/api/lib/utils/safetyMode
+ */
+
+import readline from 'readline/promises';
+import type { ResolvedDbProfile } from '@/app/api/lib/utils/dbProfiles';
+import { redactMongoUri } from '@/app/api/lib/utils/dbProfiles';
+
+// ============================================================================
+// Read-only mode
+// ============================================================================
+
+/** HTTP status returned when a write is blocked by read-only mode */
+export const READ_ONLY_STATUS = 423;
+
+/**
+ * @param argv - Process arguments (default: `process.argv`)
+ * @returns Whether writes are blocked
+ */
+export function isReadOnlyMode(argv: string[] = process.argv): boolean {
+  return (
+    argv.includes('--read-only') ||
+    process.env.READ_ONLY_MODE?.toLowerCase() === 'true'
+  );
+}
+
+export function getReadOnlyMessage(operation: string): string {
+  return `Read-only mode is on; ${operation} is blocked`;
+}
+
+/**
+ * Throws when read-only mode is on.
+ *
+ * @param operation - Short description of the blocked write
+ * @throws Error with `statusCode = 423`
+ */
+export function assertWritable(operation: string): void {
+  if (!isReadOnlyMode()) return;
+  const error = new Error(getReadOnlyMessage(operation));
+  (error as unknown as Record<string, unknown>).statusCode = READ_ONLY_STATUS;
+  throw error;
+}
+
+// ============================================================================
+// Destructive command confirmation
+// ============================================================================
+
+/**
+ * Formats the banner shown before a destructive command runs.
+ */
+export function formatTargetBanner(
+  target: ResolvedDbProfile,
+  action: string
+): string {
+  const dbName = target.options.dbName ? ` / ${target.options.dbName}` : '';
+  const line = '='.repeat(72);
+  return [
+    line,
+    `  DESTRUCTIVE: ${action}`,
+    `  Target profile: ${target.name.toUpperCase()}`,
+    `  Database:       ${redactMongoUri(target.uri)}${dbName}`,
+    line,
+  ].join('\n');
+}
+
+/**
+ * Confirms a destructive command before it writes. Blocked outright in
+ * read-only mode; otherwise prints the target banner and requires either
+ * `--yes` or the operator typing the profile name.
+ *
+ * @param target - Connected database profile
+ * @param action - What the command is about to do
+ * @param argv - Process arguments (default: `process.argv`)
+ * @throws Error when blocked, run non-interactively without `--yes`, or declined
+ */
+export async function confirmDestructiveOperation(
+  target: ResolvedDbProfile,
+  action: string,
+  argv: string[] = process.argv
+): Promise<void> {
+  assertWritable(action);
+
+  console.warn(formatTargetBanner(target, action));
+  if (argv.includes('--yes') || argv.includes('-y')) return;
+
+  if (!process.stdin.isTTY) {
+    throw new Error(
+      'Refusing to run a destructive command non-interactively; pass --yes'
+    );
+  }
+
+  const rl = readline.createInterface({
+    input: process.stdin,
+    output: process.stdout,
+  });
+  try {
+    const answer = await rl.question(
+      `Type the profile name (${target.name}) to continue: `
+    );
+    if (answer.trim() !== target.name) {
+      throw new Error('Aborted: confirmation did not match');
+    }
+  } finally {
+    rl.close();
+  }
+}
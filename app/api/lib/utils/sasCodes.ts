@@ -0,0 +1,88 @@
+/**
+ * SAS Code Dictionary Configuration
+ *
+ * Merges the built-in SAS exception dictionary (lib/utils/sas/exceptionCodes)
+ * with deployment overrides from `sas-codes.json` at the project root, or
+ * the file named by `SAS_CODES_FILE`; copy `sas-codes.example.json` to
+ * start. Entries there add vendor-specific codes or rename / re-grade
+ * built-in ones. The file is optional.
+ *
+ * @module app/api/lib/utils/sasCodes
+ */
+
+import {
+  decodeSasException,
+  SAS_EXCEPTION_CODES,
+  SAS_SEVERITIES,
+  normalizeSasCode,
+  type SasExceptionDefinition,
+  type SasExceptionInfo,
+} from '@/lib/utils/sas/exceptionCodes';
+import fs from 'fs';
+import path from 'path';
+
+const DEFAULT_SAS_CODES_FILE = 'sas-codes.json';
+
+let dictionaryCache: {
+  file: string;
+  mtimeMs: number;
+  dictionary: Record<string, SasExceptionDefinition>;
+} | null = null;
+
+/**
+ * Built-in codes plus the overrides file, cached until it changes on disk.
+ *
+ * @throws Error when the file is invalid
+ */
+export function getSasCodeDictionary(): Record<
+  string,
+  SasExceptionDefinition
+> {
+  const file = path.resolve(
+    process.cwd(),
+    process.env.SAS_CODES_FILE || DEFAULT_SAS_CODES_FILE
+  );
+  if (!fs.existsSync(file)) return SAS_EXCEPTION_CODES;
+
+  const { mtimeMs } = fs.statSync(file);
+  if (
+    dictionaryCache &&
+    dictionaryCache.file === file &&
+    dictionaryCache.mtimeMs === mtimeMs
+  ) {
+    return dictionaryCache.dictionary;
+  }
+
+  const parsed = JSON.parse(fs.readFileSync(file, 'utf8')) as {
+    codes?: Record<string, Partial<SasExceptionDefinition>>;
+  };
+  const dictionary = { ...SAS_EXCEPTION_CODES };
+  Object.entries(parsed.codes || {}).forEach(([rawCode, entry]) => {
+    const code = normalizeSasCode(rawCode);
+    if (!code) throw new Error(`${file}: '${rawCode}' is not a SAS code`);
+    const severity = entry.severity ?? dictionary[code]?.severity ?? 'info';
+    if (!SAS_SEVERITIES.includes(severity)) {
+      throw new Error(`${file}: unknown severity '${severity}' for ${code}`);
+    }
+    const name = entry.name ?? dictionary[code]?.name;
+    if (!name) throw new Error(`${file}: code ${code} needs a name`);
+    dictionary[code] = { name, severity };
+  });
+
+  dictionaryCache = { file, mtimeMs, dictionary };
+  return dictionary;
+}
+
+/**
+ * Attaches the decoded exception (`sasEvent`) to machine events by their
+ * `command` code; events without a code get `sasEvent: null`.
+ */
+export function withSasEvents<T extends { command?: string | null }>(
+  events: T[]
+): Array<T & { sasEvent: SasExceptionInfo | null }> {
+  const dictionary = getSasCodeDictionary();
+  return events.map(event => ({
+    ...event,
+    sasEvent: decodeSasException(event.command, dictionary),
+  }));
+}
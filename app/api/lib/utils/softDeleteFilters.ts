@@ -0,0 +1,46 @@
+/**
+ * Soft Delete Filters
+ *
+ * Single definition of "deleted" for every query, pipeline and command:
+ * a document is deleted when `deletedAt` is a date from 2025 on. Everything
+ * else counts as not deleted:
+ *
+ * - `null` or a missing field
+ * - the legacy `new Date(-1)` sentinel (1969) and any other date before 2025
+ * - numeric sentinels (`NumberLong(-1)`)
+ * - strings, even ISO dates: `$gte` on a Date never matches a string
+ *
+ * `normalize-deleted-at` rewrites those legacy shapes to `null`, but the
+ * filters keep accepting them so nothing disappears between a deploy and that
+ * run. Once every environment has a successful `normalize-deleted-at` run
+ * recorded in `commandAuditLogs`, they can be tightened to
+ * `{ deletedAt: null }`.
+ *
+ * Spread the filters into a query (`{ ...NOT_DELETED_FILTER, machine }`)
+ * rather than passing the shared object itself, so callers never mutate it.
+ *
+ * @module app/api/lib/utils/softDeleteFilters
+ */
+
+/** `deletedAt` dates from this day on are real deletions */
+export const DELETED_AT_CUTOFF = new Date('2025-01-01T00:00:00.000Z');
+
+/** Matches documents that are not deleted, including legacy sentinels */
+export const NOT_DELETED_FILTER = {
+  deletedAt: { $not: { $gte: DELETED_AT_CUTOFF } },
+};
+
+/** Matches documents that are deleted */
+export const DELETED_FILTER = { deletedAt: { $gte: DELETED_AT_CUTOFF } };
+
+/**
+ * Whether a stored `deletedAt` marks the document as deleted. Agrees with
+ * DELETED_FILTER: only a Date from the cutoff on counts.
+ */
+export function isDeleted(
+  value: Date | string | number | null | undefined
+): boolean {
+  return (
+    value instanceof Date && value.getTime() >= DELETED_AT_CUTOFF.getTime()
+  );
+}
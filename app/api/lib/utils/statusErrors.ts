@@ -0,0 +1,37 @@
+/**
+ * Status Errors
+ *
+ * Errors that carry the HTTP status a route should answer with. Helpers throw
+ * them with `statusError()`; routes and `withApiAuth` read the status back
+ * with `getErrorStatus()`, which falls back to 500 for any other error.
+ *
+ * @module app/api/lib/utils/statusErrors
+ */
+
+export type StatusError = Error & { statusCode: number };
+
+/**
+ * Creates an error carrying an HTTP status.
+ *
+ * @param message - Error message, returned to the caller as is
+ * @param statusCode - HTTP status for the response
+ * @param extra - Additional fields for the route to read (e.g. `current`)
+ */
+export function statusError(
+  message: string,
+  statusCode: number,
+  extra: Record<string, unknown> = {}
+): StatusError {
+  return Object.assign(new Error(message), { ...extra, statusCode });
+}
+
+/**
+ * Reads the HTTP status an error carries.
+ *
+ * @param error - Caught value
+ * @param fallback - Status for errors without one
+ */
+export function getErrorStatus(error: unknown, fallback = 500): number {
+  const statusCode = (error as Record<string, unknown> | null)?.statusCode;
+  return typeof statusCode === 'number' ? statusCode : fallback;
+}
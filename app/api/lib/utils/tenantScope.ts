@@ -0,0 +1,194 @@
+/**
+ * Tenant Isolation Mode
+ *
+ * For deployments serving a single licencee that must never see another
+ * licencee's data. The mode is on when the process was started with
+ * `TENANT_LICENCEE_ID=<id>` (app and scripts) or `--tenant <id>` (scripts).
+ * While it is on:
+ *
+ * - `getUserAccessibleLicenceesFromToken()` returns at most the pinned
+ *   licencee (admins get `[pinned]` instead of `'all'`)
+ * - `getUserLocationFilter()` / `checkUserLocationAccess()` only return
+ *   locations of the pinned licencee, via `restrictLocationsToTenant()`
+ * - the query builder validates its location scope against the tenant's
+ *   locations before running (`assertTenantLocations()`)
+ * - the proxy rejects API requests whose `licencee` / `licencees` /
+ *   `licenceeId` query parameter names another licencee, and the raw
+ *   `/api/dev` routes
+ * - `withApiAuth()` rejects the same fields in a JSON request body, which
+ *   the proxy cannot read (`assertTenantRequestBody()`)
+ *
+ * Violations throw (or return) 403.
+ *
+ * @module app/api/lib/utils/tenantScope
+ */
+
+import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import { statusError } from '@/app/api/lib/utils/statusErrors';
+
+// ============================================================================
+// Configuration
+// ============================================================================
+
+/** HTTP status returned when a request reaches outside the pinned tenant */
+export const TENANT_VIOLATION_STATUS = 403;
+
+const TENANT_LOCATIONS_TTL_MS = 60_000;
+
+/** Request fields that carry a licencee id (mirrors the proxy's list) */
+const LICENCEE_FIELDS = ['licencee', 'licencees', 'licenceeId'];
+
+let cachedLocations: { licencee: string; ids: Set<string>; at: number } | null =
+  null;
+
+/**
+ * @param argv - Process arguments (default: `process.argv`)
+ * @returns The pinned licencee id, or null when isolation mode is off
+ */
+export function getPinnedLicencee(
+  argv: string[] = process.argv
+): string | null {
+  const index = argv.indexOf('--tenant');
+  const fromArgs = index === -1 ? undefined : argv[index + 1];
+  const pinned = (fromArgs || process.env.TENANT_LICENCEE_ID || '').trim();
+  return pinned || null;
+}
+
+// ============================================================================
+// Licencee scope
+// ============================================================================
+
+/**
+ * Narrows a user's accessible licencees to the pinned one.
+ *
+ * @param accessible - Result of the regular access resolution
+ * @returns Unchanged when the mode is off; otherwise `[pinned]` or `[]`
+ */
+export function scopeLicenceesToTenant(
+  accessible: string[] | 'all'
+): string[] | 'all' {
+  const pinned = getPinnedLicencee();
+  if (!pinned) return accessible;
+  if (accessible === 'all') return [pinned];
+  return accessible.includes(pinned) ? [pinned] : [];
+}
+
+/**
+ * Throws when a licencee id other than the pinned one is requested.
+ *
+ * @param licencee - Requested licencee (ignored when empty or 'all')
+ * @throws Error with `statusCode = 403`
+ */
+export function assertTenantLicencee(licencee?: string | null): void {
+  const pinned = getPinnedLicencee();
+  if (!pinned || !licencee || licencee === 'all') return;
+  if (licencee !== pinned) {
+    throw statusError(
+      `Tenant isolation: licencee ${licencee} is outside this deployment`,
+      TENANT_VIOLATION_STATUS
+    );
+  }
+}
+
+/**
+ * Throws when a top-level `licencee` / `licencees` / `licenceeId` field of a
+ * JSON request body names a licencee other than the pinned one. Values may be
+ * ids, comma-separated ids or arrays of ids. Reads a clone, so the handler
+ * can still read the body.
+ *
+ * @param request - Incoming request
+ * @throws Error with `statusCode = 403`
+ */
+export async function assertTenantRequestBody(request: Request): Promise<void> {
+  if (!getPinnedLicencee()) return;
+  if (!request.headers.get('content-type')?.includes('application/json')) {
+    return;
+  }
+  const body: unknown = await request
+    .clone()
+    .json()
+    .catch(() => null);
+  if (!body || typeof body !== 'object' || Array.isArray(body)) return;
+
+  LICENCEE_FIELDS.flatMap(field => {
+    const value = (body as Record<string, unknown>)[field];
+    return Array.isArray(value) ? value : [value];
+  })
+    .filter((value): value is string => typeof value === 'string')
+    .flatMap(value => value.split(','))
+    .forEach(value => assertTenantLicencee(value.trim()));
+}
+
+// ============================================================================
+// Location scope
+// ============================================================================
+
+/**
+ * Location ids of the pinned licencee, including archived ones, cached for a
+ * minute.
+ */
+export async function getTenantLocationIds(): Promise<Set<string> | null> {
+  const pinned = getPinnedLicencee();
+  if (!pinned) return null;
+  if (
+    cachedLocations &&
+    cachedLocations.licencee === pinned &&
+    Date.now() - cachedLocations.at < TENANT_LOCATIONS_TTL_MS
+  ) {
+    return cachedLocations.ids;
+  }
+
+  const locations = await GamingLocations.find(
+    { 'rel.licencee': pinned },
+    { _id: 1 }
+  ).lean<Array<{ _id: string }>>();
+  cachedLocations = {
+    licencee: pinned,
+    ids: new Set(locations.map(location => String(location._id))),
+    at: Date.now(),
+  };
+  return cachedLocations.ids;
+}
+
+/**
+ * Narrows a location filter to the pinned licencee's locations: `'all'`
+ * becomes the tenant's ids and foreign ids are dropped.
+ *
+ * @param locationIds - Result of the regular location filter
+ * @returns Unchanged when the mode is off
+ */
+export async function restrictLocationsToTenant(
+  locationIds: string[] | 'all'
+): Promise<string[] | 'all'> {
+  const tenantLocations = await getTenantLocationIds();
+  if (!tenantLocations) return locationIds;
+  if (locationIds === 'all') return Array.from(tenantLocations);
+  return locationIds.filter(id => tenantLocations.has(String(id)));
+}
+
+/**
+ * Validates a location scope before a query runs: every id must belong to
+ * the pinned licencee. Use where dropping ids silently would hide a bug.
+ *
+ * @param locationIds - Location scope about to be queried
+ * @returns The scope, with `'all'` replaced by the tenant's ids
+ * @throws Error with `statusCode = 403` when an id belongs to another licencee
+ */
+export async function assertTenantLocations(
+  locationIds: string[] | 'all'
+): Promise<string[] | 'all'> {
+  const tenantLocations = await getTenantLocationIds();
+  if (!tenantLocations) return locationIds;
+  if (locationIds === 'all') return Array.from(tenantLocations);
+
+  const foreign = locationIds.filter(id => !tenantLocations.has(String(id)));
+  if (foreign.length > 0) {
+    throw statusError(
+      `Tenant isolation: ${foreign.length} location(s) outside this deployment (${foreign
+        .slice(0, 5)
+        .join(', ')})`,
+      TENANT_VIOLATION_STATUS
+    );
+  }
+  return locationIds;
+}
@@ -0,0 +1,223 @@
+/**
+ * Request Tracing (OpenTelemetry)
+ *
+ * Spans for each API handler (`withApiAuth()`), each gRPC call and each
+ * mongoose aggregation, exported over OTLP so slow requests show which
+ * pipelines they waited on. Aggregation spans carry the collection, the
+ * pipeline name (collection and stage shape, e.g. `meters:$match>$group`),
+ * the stage count and the returned rows.
+ *
+ * Off unless `OTEL_EXPORTER_OTLP_ENDPOINT` (or
+ * `OTEL_EXPORTER_OTLP_TRACES_ENDPOINT`) is set; `OTEL_SDK_DISABLED=true`
+ * turns it off again. The exporter, headers, sampler and service name come
+ * from the standard `OTEL_*` variables, read by the SDK. Uses the optional
+ * `@opentelemetry/api` and `@opentelemetry/sdk-node` packages; without them
+ * tracing logs one warning and stays off.
+ *
+ * `startTracing()` runs from `instrumentation.ts` (web server) and the gRPC
+ * server; `installAggregationTracing()` from `connectDB()` and the gRPC
+ * server.
+ *
+ * @module app/api/lib/utils/tracing
+ */
+
+import mongoose from 'mongoose';
+import type { Aggregate, PipelineStage } from 'mongoose';
+import { importOptional } from '@/app/api/lib/utils/optionalImport';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type SpanAttributes = Record<string, string | number | boolean>;
+
+type OtelApi = typeof import('@opentelemetry/api');
+type OtelSdk = typeof import('@opentelemetry/sdk-node');
+
+const API_MODULE = '@opentelemetry/api';
+const SDK_MODULE = '@opentelemetry/sdk-node';
+const TRACER_NAME = 'casino-management-system';
+
+type TracingState = {
+  api: OtelApi | null;
+  sdk: { shutdown: () => Promise<void> } | null;
+  aggregationsInstalled: boolean;
+};
+
+const STATE_KEY = Symbol.for('cms.tracing');
+
+/** On `globalThis`: instrumentation and route bundles share one SDK */
+function getState(): TracingState {
+  const store = globalThis as unknown as Record<symbol, TracingState>;
+  store[STATE_KEY] ??= { api: null, sdk: null, aggregationsInstalled: false };
+  return store[STATE_KEY];
+}
+
+/**
+ * True when an OTLP endpoint is configured and the SDK is not disabled.
+ */
+export function isTracingConfigured(): boolean {
+  return (
+    process.env.OTEL_SDK_DISABLED !== 'true' &&
+    Boolean(
+      process.env.OTEL_EXPORTER_OTLP_ENDPOINT ||
+        process.env.OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+    )
+  );
+}
+
+// ============================================================================
+// Setup
+// ============================================================================
+
+/**
+ * Starts the OpenTelemetry SDK with the OTLP exporter. No-op when tracing is
+ * not configured or already started.
+ *
+ * @param serviceName - Used when `OTEL_SERVICE_NAME` is not set
+ * @returns Whether tracing is on
+ */
+export async function startTracing(serviceName: string): Promise<boolean> {
+  const state = getState();
+  if (state.sdk) return true;
+  if (!isTracingConfigured()) return false;
+
+  try {
+    const [api, sdk] = await Promise.all([
+      importOptional<OtelApi>(API_MODULE, 'Tracing'),
+      importOptional<OtelSdk>(SDK_MODULE, 'Tracing'),
+    ]);
+    const nodeSdk = new sdk.NodeSDK(
+      process.env.OTEL_SERVICE_NAME ? {} : { serviceName }
+    );
+    nodeSdk.start();
+    state.api = api;
+    state.sdk = nodeSdk;
+    console.log(
+      `[tracing] Exporting spans to ${
+        process.env.OTEL_EXPORTER_OTLP_TRACES_ENDPOINT ||
+        process.env.OTEL_EXPORTER_OTLP_ENDPOINT
+      }`
+    );
+    return true;
+  } catch (error) {
+    console.warn(
+      '[tracing] Disabled:',
+      error instanceof Error ? error.message : error
+    );
+    return false;
+  }
+}
+
+/**
+ * Flushes pending spans and stops the SDK (on shutdown).
+ */
+export async function stopTracing(): Promise<void> {
+  const state = getState();
+  if (!state.sdk) return;
+  const sdk = state.sdk;
+  state.sdk = null;
+  state.api = null;
+  await sdk.shutdown().catch(error => {
+    console.error(
+      '[tracing] Failed to flush spans:',
+      error instanceof Error ? error.message : error
+    );
+  });
+}
+
+// ============================================================================
+// Spans
+// ============================================================================
+
+/**
+ * Runs `work` inside a span (a child of the active one). Errors are recorded
+ * on the span and rethrown. Runs `work` directly when tracing is off.
+ *
+ * @param name - Span name; keep it low-cardinality (no ids)
+ * @param attributes - Initial attributes
+ * @param work - Receives a setter for attributes known only at the end
+ * @param kind - 'server' for incoming requests and calls, 'client' for
+ * database calls
+ */
+export async function withSpan<T>(
+  name: string,
+  attributes: SpanAttributes,
+  work: (setAttributes: (attributes: SpanAttributes) => void) => Promise<T>,
+  kind: 'internal' | 'server' | 'client' = 'internal'
+): Promise<T> {
+  const api = getState().api;
+  if (!api) return work(() => undefined);
+
+  const spanKind = {
+    internal: api.SpanKind.INTERNAL,
+    server: api.SpanKind.SERVER,
+    client: api.SpanKind.CLIENT,
+  }[kind];
+  return api.trace
+    .getTracer(TRACER_NAME)
+    .startActiveSpan(name, { attributes, kind: spanKind }, async span => {
+      try {
+        return await work(next => span.setAttributes(next));
+      } catch (error) {
+        const err = error instanceof Error ? error : new Error(String(error));
+        span.recordException(err);
+        span.setStatus({
+          code: api.SpanStatusCode.ERROR,
+          message: err.message,
+        });
+        throw error;
+      } finally {
+        span.end();
+      }
+    });
+}
+
+/**
+ * Name of a pipeline for traces: collection and stage operators.
+ */
+export function getPipelineName(
+  collection: string,
+  pipeline: PipelineStage[]
+): string {
+  const stages = pipeline.map(stage => Object.keys(stage)[0] || '?');
+  return `${collection}:${stages.join('>')}`;
+}
+
+/**
+ * Wraps every mongoose aggregation in a `mongo.aggregate` span. Safe to
+ * call repeatedly; costs nothing while tracing is off.
+ */
+export function installAggregationTracing(): void {
+  const state = getState();
+  if (state.aggregationsInstalled) return;
+  state.aggregationsInstalled = true;
+
+  const exec = mongoose.Aggregate.prototype.exec;
+  mongoose.Aggregate.prototype.exec = function (this: Aggregate<unknown>) {
+    if (!getState().api) return exec.call(this);
+
+    const model = this.model();
+    const collection =
+      model?.collection?.collectionName || model?.modelName || 'unknown';
+    const pipeline = this.pipeline();
+    return withSpan(
+      `mongo.aggregate ${collection}`,
+      {
+        'db.system': 'mongodb',
+        'db.operation.name': 'aggregate',
+        'db.collection.name': collection,
+        'cms.pipeline.name': getPipelineName(collection, pipeline),
+        'cms.pipeline.stages': pipeline.length,
+      },
+      async setAttributes => {
+        const result = await exec.call(this);
+        if (Array.isArray(result)) {
+          setAttributes({ 'db.response.returned_rows': result.length });
+        }
+        return result;
+      },
+      'client'
+    );
+  } as typeof exec;
+}
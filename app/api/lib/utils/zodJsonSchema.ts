@@ -0,0 +1,152 @@
+/**
+ * Zod to JSON Schema
+ *
+ * Converts the zod schemas the API validates requests with (and types its
+ * responses with) into JSON Schema for the OpenAPI document, so the spec is
+ * generated from the same definitions the handlers use.
+ *
+ * Covers the zod types this codebase uses: strings (length, email, url,
+ * uuid, datetime, regex), numbers (int, min/max), booleans, dates (as
+ * date-time strings), literals, enums, arrays, objects (strict, strip or
+ * passthrough), records, unions (plain or discriminated), optional, nullable,
+ * default, effects and pipes (refine / transform / pipe, converted as their
+ * input). Anything else converts to an unconstrained schema.
+ *
+ * @module app/api/lib/utils/zodJsonSchema
+ */
+
+import { z } from 'zod';
+
+export type JsonSchema = {
+  type?: string;
+  format?: string;
+  description?: string;
+  enum?: unknown[];
+  default?: unknown;
+  minLength?: number;
+  maxLength?: number;
+  pattern?: string;
+  minimum?: number;
+  maximum?: number;
+  minItems?: number;
+  maxItems?: number;
+  items?: JsonSchema;
+  properties?: Record<string, JsonSchema>;
+  required?: string[];
+  additionalProperties?: boolean | JsonSchema;
+  anyOf?: JsonSchema[];
+  nullable?: boolean;
+};
+
+function convertString(schema: z.ZodString): JsonSchema {
+  const result: JsonSchema = { type: 'string' };
+  schema._def.checks.forEach(check => {
+    if (check.kind === 'min') result.minLength = check.value;
+    if (check.kind === 'max') result.maxLength = check.value;
+    if (check.kind === 'length') {
+      result.minLength = check.value;
+      result.maxLength = check.value;
+    }
+    if (check.kind === 'email') result.format = 'email';
+    if (check.kind === 'url') result.format = 'uri';
+    if (check.kind === 'uuid') result.format = 'uuid';
+    if (check.kind === 'datetime') result.format = 'date-time';
+    if (check.kind === 'regex') result.pattern = check.regex.source;
+  });
+  return result;
+}
+
+function convertNumber(schema: z.ZodNumber): JsonSchema {
+  const result: JsonSchema = { type: schema.isInt ? 'integer' : 'number' };
+  if (schema.minValue !== null) result.minimum = schema.minValue;
+  if (schema.maxValue !== null) result.maximum = schema.maxValue;
+  return result;
+}
+
+function convertObject(schema: z.AnyZodObject): JsonSchema {
+  const properties: Record<string, JsonSchema> = {};
+  const required: string[] = [];
+  Object.entries(schema.shape as Record<string, z.ZodTypeAny>).forEach(
+    ([key, field]) => {
+      properties[key] = zodToJsonSchema(field);
+      if (!field.isOptional()) required.push(key);
+    }
+  );
+  const unknownKeys = schema._def.unknownKeys;
+  return {
+    type: 'object',
+    properties,
+    ...(required.length > 0 ? { required } : {}),
+    ...(unknownKeys === 'strict' ? { additionalProperties: false } : {}),
+    ...(unknownKeys === 'passthrough' ? { additionalProperties: true } : {}),
+  };
+}
+
+function convert(schema: z.ZodTypeAny): JsonSchema {
+  if (schema instanceof z.ZodString) return convertString(schema);
+  if (schema instanceof z.ZodNumber) return convertNumber(schema);
+  if (schema instanceof z.ZodBoolean) return { type: 'boolean' };
+  if (schema instanceof z.ZodDate) {
+    return { type: 'string', format: 'date-time' };
+  }
+  if (schema instanceof z.ZodNull) return { nullable: true };
+  if (schema instanceof z.ZodLiteral) return { enum: [schema.value] };
+  if (schema instanceof z.ZodEnum) {
+    return { type: 'string', enum: [...schema.options] };
+  }
+  if (schema instanceof z.ZodNativeEnum) {
+    return { enum: Object.values(schema.enum) };
+  }
+  if (schema instanceof z.ZodArray) {
+    const result: JsonSchema = {
+      type: 'array',
+      items: zodToJsonSchema(schema.element),
+    };
+    if (schema._def.minLength) result.minItems = schema._def.minLength.value;
+    if (schema._def.maxLength) result.maxItems = schema._def.maxLength.value;
+    return result;
+  }
+  if (schema instanceof z.ZodObject) return convertObject(schema);
+  if (schema instanceof z.ZodRecord) {
+    return {
+      type: 'object',
+      additionalProperties: zodToJsonSchema(schema.valueSchema),
+    };
+  }
+  if (
+    schema instanceof z.ZodUnion ||
+    schema instanceof z.ZodDiscriminatedUnion
+  ) {
+    return {
+      anyOf: (schema.options as z.ZodTypeAny[]).map(option =>
+        zodToJsonSchema(option)
+      ),
+    };
+  }
+  if (schema instanceof z.ZodOptional) return zodToJsonSchema(schema.unwrap());
+  if (schema instanceof z.ZodNullable) {
+    return { ...zodToJsonSchema(schema.unwrap()), nullable: true };
+  }
+  if (schema instanceof z.ZodDefault) {
+    return {
+      ...zodToJsonSchema(schema._def.innerType),
+      default: schema._def.defaultValue(),
+    };
+  }
+  if (schema instanceof z.ZodEffects) {
+    return zodToJsonSchema(schema.innerType());
+  }
+  if (schema instanceof z.ZodPipeline) return zodToJsonSchema(schema._def.in);
+  return {};
+}
+
+/**
+ * JSON Schema (OpenAPI 3.0 dialect) for a zod schema. The schema's
+ * `.describe()` text becomes its description.
+ */
+export function zodToJsonSchema(schema: z.ZodTypeAny): JsonSchema {
+  const result = convert(schema);
+  return schema.description
+    ? { ...result, description: schema.description }
+    : result;
+}
@@ -43,6 +43,17 @@ import {
   updateLicencee as updateLicenceeHelper,
 } from '@/app/api/lib/helpers/licencees';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createLicenceeRoute,
+  deleteLicenceeRoute,
+  listLicenceesRoute,
+  updateLicenceeRoute,
+} from '@/app/api/lib/routeSchemas/licencees';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -56,8 +67,12 @@ export async function GET(request: NextRequest) {
     const startTime = Date.now();
     const functionName = 'GET /api/licencees';
     const user = extractUserFromRequest(request);
-    const { searchParams } = new URL(request.url);
-    const licenceeFilter = searchParams.get('licencee');
+    const query = parseQuery(listLicenceesRoute, request);
+    if (!query.success) {
+      return validationErrorResponse(query.error);
+    }
+    const { licencee: licenceeFilter, page } = query.data;
+    const limit = Math.min(query.data.limit, 100);
 
     const userLicenceeAccess = await getUserAccessibleLicenceesFromToken();
     const licencees = await getAllLicencees();
@@ -85,9 +100,6 @@ export async function GET(request: NextRequest) {
       });
     }
 
-    const page = parseInt(searchParams.get('page') || '1');
-    const requestedLimit = parseInt(searchParams.get('limit') || '50');
-    const limit = Math.min(requestedLimit, 100);
     const skip = (page - 1) * limit;
 
     const totalCount = formattedLicencees.length;
@@ -128,17 +140,15 @@ export async function POST(request: NextRequest) {
       );
     }
 
-    const body = await request.json();
-    const { name, country } = body;
-
-    if (!name || !country) {
-      return NextResponse.json(
-        { success: false, message: 'Name and country are required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      createLicenceeRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
 
-    const licencee = await createLicenceeHelper(body, request);
+    const licencee = await createLicenceeHelper(validation.data, request);
     const duration = Date.now() - startTime;
     logRouteCreate(functionName, 'POST', '/api/licencees', 1, user, duration);
     return NextResponse.json({ success: true, licencee }, { status: 201 });
@@ -158,17 +168,18 @@ export async function PUT(request: NextRequest) {
       );
     }
 
-    const body = await request.json();
-    const { _id } = body;
-
-    if (!_id) {
-      return NextResponse.json(
-        { success: false, message: 'ID is required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      updateLicenceeRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
 
-    const updatedLicencee = await updateLicenceeHelper(body, request);
+    const updatedLicencee = await updateLicenceeHelper(
+      validation.data,
+      request
+    );
     const duration = Date.now() - startTime;
     logRouteUpdate(functionName, 'PUT', '/api/licencees', 1, user, duration);
     return NextResponse.json({ success: true, licencee: updatedLicencee });
@@ -184,17 +195,15 @@ export async function DELETE(request: NextRequest) {
       );
     }
 
-    const body = await request.json();
-    const { _id } = body;
-
-    if (!_id) {
-      return NextResponse.json(
-        { success: false, message: 'Licencee ID is required' },
-        { status: 400 }
-      );
+    const validation = parseBody(
+      deleteLicenceeRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
+      return validationErrorResponse(validation.error);
     }
 
-    await deleteLicenceeHelper(_id, request);
+    await deleteLicenceeHelper(validation.data._id, request);
     return NextResponse.json({ success: true });
   });
 }
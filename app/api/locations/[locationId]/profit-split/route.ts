@@ -0,0 +1,231 @@
+/**
+ * Location Profit Split API Route
+ *
+ * Reads and sets the negotiated split of a location's gross between the
+ * licencee and the venue owner. Splits are dated: a new split applies from
+ * its `effectiveFrom` gaming day, so earlier settlements are unchanged.
+ * It supports:
+ * - Split history and the split in effect today (GET)
+ * - Adding or replacing a split from a gaming day (PUT, admin/developer)
+ *
+ * @module app/api/locations/[locationId]/profit-split/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  getLocationProfitSplit,
+  setLocationProfitSplit,
+} from '@/app/api/lib/helpers/locations/profitSplit';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { setProfitSplitRoute } from '@/app/api/lib/routeSchemas/locations';
+import {
+  logRouteFetch,
+  logRouteError,
+  logRouteUpdate,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/locations/[locationId]/profit-split
+ *
+ * URL params:
+ * @param {string} locationId - Required (path). The location whose split to read.
+ *
+ * Flow:
+ * 1. Check location access
+ * 2. Load the split history via `getLocationProfitSplit`
+ */
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ locationId: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/locations/[locationId]/profit-split';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    try {
+      // ============================================================================
+      // STEP 1: Check location access
+      // ============================================================================
+      const { locationId } = await params;
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+      if (!(await checkUserLocationAccess(locationId))) {
+        return NextResponse.json(
+          { success: false, error: 'Unauthorized' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Load split history
+      // ============================================================================
+      const config = await getLocationProfitSplit(locationId);
+
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/locations/[locationId]/profit-split',
+        config.splits.length,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: config });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/locations/[locationId]/profit-split',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
+
+/**
+ * PUT /api/locations/[locationId]/profit-split
+ *
+ * Restricted to admin/developer roles.
+ *
+ * @body {number} venueShare - Required. Venue owner's percent of gross (0-100).
+ * @body {string} [effectiveFrom] - First gaming day, YYYY-MM-DD (default: today).
+ * @body {string} [venueOwner] - Payee name for accounts payable.
+ * @body {string} [payeeReference] - Supplier / vendor reference for accounts payable.
+ *
+ * Flow:
+ * 1. Check role and location access
+ * 2. Save the split via `setLocationProfitSplit`
+ * 3. Log activity
+ */
+export async function PUT(
+  request: NextRequest,
+  { params }: { params: Promise<{ locationId: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'PUT /api/locations/[locationId]/profit-split';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, isAdminOrDev }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Check role and location access
+      // ============================================================================
+      const { locationId } = await params;
+      if (!isAdminOrDev) {
+        return NextResponse.json(
+          { success: false, error: 'Unauthorized' },
+          { status: 403 }
+        );
+      }
+      const validation = parseBody(
+        setProfitSplitRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+      if (!(await checkUserLocationAccess(locationId))) {
+        return NextResponse.json(
+          { success: false, error: 'Unauthorized' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Save the split
+      // ============================================================================
+      const { config, split, replaced } = await setLocationProfitSplit(
+        locationId,
+        {
+          venueShare: body.venueShare,
+          effectiveFrom: body.effectiveFrom,
+          venueOwner: body.venueOwner,
+          payeeReference: body.payeeReference,
+        },
+        String(userPayload._id)
+      );
+
+      // ============================================================================
+      // STEP 3: Log activity
+      // ============================================================================
+      try {
+        await logActivity({
+          action: 'UPDATE',
+          details: `Set profit split for location "${config.locationName}" to ${split.venueShare}% venue share from ${split.effectiveFrom}`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'location',
+            resourceId: config.locationId,
+            resourceName: config.locationName,
+            changes: [
+              {
+                field: 'profitSplits',
+                oldValue: replaced,
+                newValue: split,
+              },
+            ],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      logRouteUpdate(
+        functionName,
+        'PUT',
+        '/api/locations/[locationId]/profit-split',
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: config });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'PUT',
+        '/api/locations/[locationId]/profit-split',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -38,6 +38,7 @@ import {
   sortCabinetData,
   paginateAndTransformCabinets,
   fetchLicenceeJackpotFlag,
+  fetchLicenceeFinancialFormula,
   computeReviewerScales,
 } from '@/app/api/lib/helpers/locations/locationByIdOperations';
 import type {
@@ -47,8 +48,12 @@ import type {
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Machine } from '@/app/api/lib/models/machines';
 import type { GamingMachine, LocationDocument } from '@shared/types';
-import type { TimePeriod } from '@shared/types';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { getLocationRoute } from '@/app/api/lib/routeSchemas/locations';
 import {
   logRouteFetch,
   logRouteError,
@@ -92,9 +97,12 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse Query Params and Check Name-Only / Basic-Info Modes
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const nameOnly = searchParams.get('nameOnly') === 'true';
-      const basicInfo = searchParams.get('basicInfo') === 'true';
+      const query = parseQuery(getLocationRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const nameOnly = query.data.nameOnly === 'true';
+      const basicInfo = query.data.basicInfo === 'true';
 
       if (nameOnly) {
         const location = await GamingLocations.findOne(
@@ -163,7 +171,7 @@ export async function GET(request: NextRequest) {
         (locationCheck as Record<string, unknown>).noSMIBLocation = smibTags.noSMIBLocation;
       }
 
-      if (basicInfo || !searchParams.toString().length) {
+      if (basicInfo || !request.nextUrl.search) {
         const includeJackpot = await fetchLicenceeJackpotFlag(locationCheck.rel?.licencee);
         logRouteFetch(functionName, 'GET', '/api/locations/[locationId]', 1, user, Date.now() - startTime);
         return NextResponse.json({
@@ -175,16 +183,17 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 3: Parse Cabinet-List Parameters
       // ============================================================================
-      const licencee = searchParams.get('licencee');
-      const searchTerm = searchParams.get('search');
-      const timePeriod = searchParams.get('timePeriod') as TimePeriod;
-      const customStart = searchParams.get('startDate');
-      const customEnd = searchParams.get('endDate');
-      const onlineStatus = searchParams.get('onlineStatus') || 'all';
-      const smibStatus = (searchParams.get('smibStatus') || 'all').toLowerCase();
-      const limitParam = searchParams.get('limit');
-      const limit = limitParam ? parseInt(limitParam) : undefined;
-      const page = parseInt(searchParams.get('page') || '1');
+      const {
+        licencee,
+        search: searchTerm,
+        timePeriod,
+        startDate: customStart,
+        endDate: customEnd,
+        onlineStatus,
+        smibStatus,
+        limit,
+        page,
+      } = query.data;
       const skip = limit ? (page - 1) * limit : 0;
 
       if (!timePeriod) {
@@ -211,7 +220,7 @@ export async function GET(request: NextRequest) {
         customEnd ? new Date(customEnd) : undefined
       );
 
-      const includeJackpotSetting = await fetchLicenceeJackpotFlag(locationCheck.rel?.licencee);
+      const financialFormula = await fetchLicenceeFinancialFormula(locationCheck.rel?.licencee);
       const { moneyInScale, moneyOutScale } = computeReviewerScales(
         userPayload as {
           moneyInMultiplier?: number | null;
@@ -225,7 +234,7 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 4: Build Machine Filter and Fetch Machines
       // ============================================================================
-      const includeArchived = searchParams.get('includeArchived') === 'true';
+      const includeArchived = query.data.includeArchived === 'true';
       const mMatch = buildMachinesFilter({
         locationId,
         includeArchived,
@@ -291,7 +300,7 @@ export async function GET(request: NextRequest) {
         aceEnabled: !!locationCheck.aceEnabled,
         moneyInScale,
         moneyOutScale,
-        includeJackpotSetting,
+        financialFormula,
       };
       const cabinetItems: CabinetItemData[] = mapMachinesToCabinetData(
         filteredMachines,
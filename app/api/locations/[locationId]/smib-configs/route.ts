@@ -12,11 +12,13 @@
 
 import { Machine } from '@/app/api/lib/models/machines';
 import { connectDB } from '@/app/api/lib/middleware/db';
+import { isMachineOnline } from '@/app/api/lib/utils/machineStatus';
 import {
   logRouteFetch,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { GamingMachine } from '@shared/types';
 import { NextRequest, NextResponse } from 'next/server';
 
@@ -48,7 +50,7 @@ export async function GET(request: NextRequest) {
 
     const machines = await Machine.find({
       gamingLocation: locationId,
-      deletedAt: null,
+      ...NOT_DELETED_FILTER,
       $or: [
         { relayId: { $exists: true, $ne: '' } },
         { smibBoard: { $exists: true, $ne: '' } },
@@ -83,10 +85,7 @@ export async function GET(request: NextRequest) {
     // ============================================================================
     const configs = machines.map(machine => {
       const relayId = (machine.relayId || machine.smibBoard || '').toString();
-      const isOnline = machine.lastActivity
-        ? new Date().getTime() - new Date(machine.lastActivity).getTime() <
-          3 * 60 * 1000
-        : false;
+      const isOnline = isMachineOnline(machine.lastActivity);
 
       return {
         relayId,
@@ -15,6 +15,7 @@ import { getUserFromServer } from '@/app/api/lib/helpers/users';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import { Machine } from '@/app/api/lib/models/machines';
 import { mqttService } from '@/app/api/lib/services/mqttService';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import {
   logRouteCreate,
@@ -59,7 +60,7 @@ export async function POST(request: NextRequest) {
 
     const machines = await Machine.find({
       gamingLocation: locationId,
-      deletedAt: null,
+      ...NOT_DELETED_FILTER,
       $or: [
         { relayId: { $exists: true, $ne: '' } },
         { smibBoard: { $exists: true, $ne: '' } },
@@ -12,11 +12,17 @@ import {
 } from '@/app/api/lib/helpers/licenceeFilter';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { membershipCountRoute } from '@/app/api/lib/routeSchemas/locations';
 import {
   logRouteFetch,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
@@ -42,9 +48,11 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       // STEP 1: Parse request parameters
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-      const licencee = searchParams.get('licencee');
-      const locationId = searchParams.get('locationId');
+      const parsed = parseQuery(membershipCountRoute, req);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const { licencee, locationId } = parsed.data;
 
       // ============================================================================
       // STEP 2: Resolve user accessible licencees and permissions
@@ -81,12 +89,7 @@ export async function GET(req: NextRequest) {
       // ============================================================================
       const query: Record<string, unknown> = {
         $and: [
-          {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
-          },
+          { ...NOT_DELETED_FILTER },
           // Check both membershipEnabled and enableMembership fields for compatibility
           {
             $or: [{ membershipEnabled: true }, { enableMembership: true }],
@@ -5,6 +5,7 @@
  * Admin/developer access is required for write operations (POST, PUT, DELETE, PATCH).
  *
  * GET    /api/locations  - List locations filtered by user permissions and licencee
+ *                           (paginated with the standard list params when `cursor` is passed)
  * POST   /api/locations  - Create a new location
  * PUT    /api/locations  - Update an existing location
  * DELETE /api/locations  - Soft-delete or hard-delete a location (and its machines)
@@ -22,6 +23,20 @@ import {
   handleDeleteLocation,
   handleRestoreLocation,
 } from '@/app/api/lib/helpers/locations/locationQueryHandlers';
+import { handleListRequest } from '@/app/api/lib/helpers/listEndpoint';
+import { locationListResource } from '@/app/api/lib/helpers/listResources';
+import type { LocationListItem } from '@/app/api/lib/helpers/listResources';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createLocationRoute,
+  deleteLocationRoute,
+  restoreLocationRoute,
+  updateLocationRoute,
+} from '@/app/api/lib/routeSchemas/locations';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -33,6 +48,7 @@ import {
 import { revalidatePath } from 'next/cache';
 import { NextRequest, NextResponse } from 'next/server';
 import { apiLogger } from '@/app/api/lib/services/loggerService';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
 
 // ============================================================================
 // GET /api/locations
@@ -41,6 +57,11 @@ import { apiLogger } from '@/app/api/lib/services/loggerService';
 /**
  * Returns the list of gaming locations accessible to the current user.
  *
+ * With `cursor` (empty for the first page) the standard list params apply
+ * instead (see listEndpoint): `limit`, `sort` (name, createdAt, updatedAt),
+ * `licencee`, `location`, `status` and `from` / `to` on `createdAt`, and the
+ * response is `{ success, data, pagination, sort, filters }`.
+ *
  * Query params:
  * @param {string} [licencee] - Filter by licencee ID.
  * @param {string} [ids] - Comma-separated location IDs.
@@ -52,12 +73,31 @@ export async function GET(request: NextRequest) {
   const functionName = 'GET /api/locations';
   const user = extractUserFromRequest(request);
 
-  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+  return withApiAuth(request, async auth => {
+    const { user: userPayload, userRoles } = auth;
     const context = apiLogger.createContext(request, '/api/locations');
     apiLogger.startLogging();
 
     try {
       const { searchParams } = new URL(request.url);
+      if (searchParams.has('cursor')) {
+        const page = await handleListRequest<LocationListItem>(
+          request,
+          locationListResource,
+          auth
+        );
+        const duration = Date.now() - startTime;
+        logRouteFetch(
+          functionName,
+          'GET',
+          '/api/locations',
+          page.data.length,
+          user,
+          duration
+        );
+        return NextResponse.json(page, { status: 200 });
+      }
+
       const results = await handleGetLocations(
         {
           licencee: searchParams.get('licencee'),
@@ -92,9 +132,13 @@ export async function GET(request: NextRequest) {
         error instanceof Error ? error.message : 'Failed to fetch locations';
       logRouteError(functionName, 'GET', '/api/locations', errorMessage, user);
       console.error(`[Locations GET API] Error:`, error);
+      const statusCode = getErrorStatus(error) === 400 ? 400 : 500;
       return NextResponse.json(
-        { success: false, message: 'Failed' },
-        { status: 500 }
+        {
+          success: false,
+          message: statusCode === 400 ? errorMessage : 'Failed',
+        },
+        { status: statusCode }
       );
     }
   });
@@ -129,9 +173,15 @@ export async function POST(request: NextRequest) {
     if (!isAdminOrDev) return accessDeniedResponse(functionName, 'POST', user);
 
     try {
-      const body = await request.json();
+      const validation = parseBody(
+        createLocationRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
       const newLocation = await handleCreateLocation(
-        body as Record<string, unknown>,
+        validation.data,
         currentUser as { _id: string; emailAddress: string } | null,
         request
       );
@@ -146,8 +196,7 @@ export async function POST(request: NextRequest) {
       );
     } catch (error) {
       const message = error instanceof Error ? error.message : 'Unknown error';
-      const errCode = (error as Record<string, unknown>).statusCode;
-      const statusCode = errCode === 400 ? 400 : 500;
+      const statusCode = getErrorStatus(error) === 400 ? 400 : 500;
       logRouteError(functionName, 'POST', '/api/locations', message, user);
       console.error(`[Locations POST API] Error:`, error);
       return NextResponse.json({ success: false, message }, { status: statusCode });
@@ -175,6 +224,7 @@ export async function POST(request: NextRequest) {
  * @body {boolean} [membershipEnabled] - Toggle membership system.
  * @body {boolean} [aceEnabled] - Toggle ACE mode.
  * @body {object} [locationMembershipSettings] - Updated membership config.
+ * @body {object[]} [shifts] - Shift definitions `{ name, startHour, endHour }` used by shift reports.
  */
 export async function PUT(request: NextRequest) {
   const startTime = Date.now();
@@ -185,9 +235,15 @@ export async function PUT(request: NextRequest) {
     if (!isAdminOrDev) return accessDeniedResponse(functionName, 'PUT', user);
 
     try {
-      const body = await request.json();
+      const validation = parseBody(
+        updateLocationRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
       const result = await handleUpdateLocation(
-        body as Record<string, unknown>,
+        validation.data,
         currentUser as { _id: string; emailAddress: string } | null,
         request
       );
@@ -202,8 +258,7 @@ export async function PUT(request: NextRequest) {
       );
     } catch (error) {
       const message = error instanceof Error ? error.message : 'Unknown error';
-      const errCode = (error as Record<string, unknown>).statusCode;
-      const statusCode = typeof errCode === 'number' ? errCode : 500;
+      const statusCode = getErrorStatus(error);
       logRouteError(functionName, 'PUT', '/api/locations', message, user);
       console.error(`[Locations PUT API] Error:`, error);
       return NextResponse.json({ success: false, message }, { status: statusCode });
@@ -237,17 +292,14 @@ export async function DELETE(request: NextRequest) {
         return accessDeniedResponse(functionName, 'DELETE', user);
 
       try {
-        const { searchParams } = new URL(request.url);
-        const id = searchParams.get('id');
-        if (!id) {
+        const query = parseQuery(deleteLocationRoute, request);
+        if (!query.success) {
           logRouteError(functionName, 'DELETE', '/api/locations', 'ID required', user);
-          return NextResponse.json(
-            { success: false, message: 'ID required' },
-            { status: 400 }
-          );
+          return validationErrorResponse(query.error);
         }
+        const { id } = query.data;
 
-        const hardDelete = searchParams.get('hardDelete') === 'true';
+        const hardDelete = query.data.hardDelete === 'true';
         const isAuthorizedForHardDelete = userRoles
           .map(r => r.toLowerCase())
           .some(r =>
@@ -272,8 +324,7 @@ export async function DELETE(request: NextRequest) {
         );
       } catch (error) {
         const message = error instanceof Error ? error.message : 'Unknown error';
-        const errCode = (error as Record<string, unknown>).statusCode;
-        const statusCode = typeof errCode === 'number' ? errCode : 500;
+        const statusCode = getErrorStatus(error);
         logRouteError(functionName, 'DELETE', '/api/locations', message, user);
         console.error(`[Locations DELETE API] Error:`, error);
         return NextResponse.json({ success: false, message }, { status: statusCode });
@@ -302,15 +353,15 @@ export async function PATCH(request: NextRequest) {
     if (!isAdminOrDev) return accessDeniedResponse(functionName, 'PATCH', user);
 
     try {
-      const { id, action } = await request.json();
-
-      if (!id || action !== 'restore') {
+      const validation = parseBody(
+        restoreLocationRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(functionName, 'PATCH', '/api/locations', 'Invalid request', user);
-        return NextResponse.json(
-          { success: false, message: 'Invalid' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { id } = validation.data;
 
       await handleRestoreLocation(
         id,
@@ -328,8 +379,7 @@ export async function PATCH(request: NextRequest) {
       );
     } catch (error) {
       const message = error instanceof Error ? error.message : 'Unknown error';
-      const errCode = (error as Record<string, unknown>).statusCode;
-      const statusCode = typeof errCode === 'number' ? errCode : 500;
+      const statusCode = getErrorStatus(error);
       logRouteError(functionName, 'PATCH', '/api/locations', message, user);
       console.error(`[Locations PATCH API] Error:`, error);
       return NextResponse.json({ success: false, message }, { status: statusCode });
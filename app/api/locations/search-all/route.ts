@@ -24,6 +24,11 @@ import {
   fetchLocationsWithMachinesForSmib,
   syncAllLocationSmibStatuses,
 } from '@/app/api/lib/helpers/smibClassification';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { searchLocationsRoute } from '@/app/api/lib/routeSchemas/locations';
 import {
   getMoneyInScale,
   getMoneyOutAndJackpotScale,
@@ -44,8 +49,7 @@ import {
 } from '@/app/api/lib/helpers/locations/searchOperations';
 import { Machine } from '@/app/api/lib/models/machines';
 import { NextRequest, NextResponse } from 'next/server';
-import type { TimePeriod } from '@/shared/types';
-import type { CurrencyCode } from '@/shared/types/currency';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 
 /**
  * Main GET handler for searching all locations
@@ -72,26 +76,25 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const licencee = searchParams.get('licencee') || '';
-      const search = searchParams.get('search')?.trim() || '';
-      const displayCurrency =
-        (searchParams.get('currency') as CurrencyCode) || 'USD';
-      const timePeriod =
-        (searchParams.get('timePeriod') as TimePeriod) || '30d';
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
-      const customStartDate = startDateParam
-        ? new Date(startDateParam)
-        : undefined;
-      const customEndDate = endDateParam ? new Date(endDateParam) : undefined;
-      const machineTypeFilter = searchParams.get('machineTypeFilter');
-      const onlineStatus =
-        searchParams.get('onlineStatus')?.toLowerCase() || 'all';
+      const query = parseQuery(searchLocationsRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const {
+        currency: displayCurrency,
+        timePeriod,
+        startDate,
+        endDate,
+        onlineStatus,
+      } = query.data;
+      const licencee = query.data.licencee ?? '';
+      const search = query.data.search?.trim() ?? '';
+      const customStartDate = startDate ? new Date(startDate) : undefined;
+      const customEndDate = endDate ? new Date(endDate) : undefined;
+      const machineTypeFilter = query.data.machineTypeFilter ?? null;
       const showArchived =
-        searchParams.get('archived') === 'true' ||
-        searchParams.get('includeDeleted') === 'true';
-      const syncAll = searchParams.get('syncAll') === 'true';
+        query.data.archived === 'true' || query.data.includeDeleted === 'true';
+      const syncAll = query.data.syncAll === 'true';
 
       // ============================================================================
       // STEP 2: Resolve user's accessible licencees and permissions
@@ -126,10 +129,7 @@ export async function GET(request: NextRequest) {
       ) {
         const wowLocs = await Machine.distinct('gamingLocation', {
           'meta.dataSync.source': 'wow',
-          $or: [
-            { deletedAt: null },
-            { deletedAt: { $lt: new Date('2025-01-01') } },
-          ],
+          ...NOT_DELETED_FILTER,
         });
         wowLocationIds = wowLocs.map(id => String(id));
       }
@@ -157,7 +157,7 @@ export async function GET(request: NextRequest) {
       const {
         metersByLocation,
         memberCountMap,
-        licenceeIncludeJackpotMap,
+        licenceeFormulaMap,
         allLocationIds,
         globalEnd,
       } = await computeFinancialMetrics(
@@ -202,7 +202,7 @@ export async function GET(request: NextRequest) {
         matchingLocations,
         metersByLocation,
         memberCountMap,
-        licenceeIncludeJackpotMap,
+        licenceeFormulaMap,
         syncAll,
         moneyInScale,
         moneyOutScale,
@@ -0,0 +1,229 @@
+/**
+ * Machine Detail API Route
+ *
+ * Reads and updates a machine's core assignment fields (game, custom name,
+ * location, SMIB id) for internal tools, with optimistic concurrency: GET
+ * returns a `version`, PATCH must send it back and fails with 409 when the
+ * machine changed in between.
+ * It supports:
+ * - GET: Editable fields and current version
+ * - PATCH: Validated partial update with version check and activity log
+ *
+ * @module app/api/machines/[machineId]/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getMachineRecord,
+  machineUpdateSchema,
+  updateMachineRecord,
+} from '@/app/api/lib/helpers/cabinets/machineWriteOperations';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  logRouteFetch,
+  logRouteUpdate,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { revalidatePath } from 'next/cache';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/machines/[machineId]
+ *
+ * Flow:
+ * 1. Load the machine (404 when missing or deleted)
+ * 2. Check access to its location
+ * 3. Return the editable fields and version
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/machines/[machineId]';
+  const user = extractUserFromRequest(request);
+  const machineId = request.nextUrl.pathname.split('/').pop() || '';
+
+  return withApiAuth(request, async () => {
+    try {
+      // ============================================================================
+      // STEP 1: Load the machine
+      // ============================================================================
+      const machine = await getMachineRecord(machineId);
+      if (!machine) {
+        logRouteError(
+          functionName,
+          'GET',
+          '/api/machines/[machineId]',
+          `Not found: ${machineId}`,
+          user
+        );
+        return NextResponse.json(
+          { success: false, error: 'Machine not found' },
+          { status: 404 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Check access to its location
+      // ============================================================================
+      if (!(await checkUserLocationAccess(machine.gamingLocation))) {
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 3: Return
+      // ============================================================================
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/machines/[machineId]',
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: machine });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Failed to fetch machine';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/machines/[machineId]',
+        errorMessage,
+        user
+      );
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * PATCH /api/machines/[machineId]
+ *
+ * Body:
+ * @param version        {number} Required. The version from GET.
+ * @param game           {string} Optional.
+ * @param customName     {string} Optional.
+ * @param gamingLocation {string} Optional. Moves the machine to this location.
+ * @param smibId         {string} Optional. '' unassigns the SMIB.
+ *
+ * Flow:
+ * 1. Validate the body
+ * 2. Check access to the current and target locations
+ * 3. Apply the update via `updateMachineRecord` (409 on a stale version)
+ * 4. Return the machine with its new version
+ */
+export async function PATCH(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'PATCH /api/machines/[machineId]';
+  const user = extractUserFromRequest(request);
+  const machineId = request.nextUrl.pathname.split('/').pop() || '';
+
+  return withApiAuth(request, async ({ user: userPayload }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Validate the body
+      // ============================================================================
+      const body = await request.json().catch(() => null);
+      const validationResult = machineUpdateSchema.safeParse(body);
+      if (!validationResult.success) {
+        logRouteError(
+          functionName,
+          'PATCH',
+          '/api/machines/[machineId]',
+          'Validation failed',
+          user
+        );
+        return NextResponse.json(
+          {
+            success: false,
+            error: 'Validation failed',
+            details: validationResult.error.errors,
+          },
+          { status: 400 }
+        );
+      }
+      const input = validationResult.data;
+
+      // ============================================================================
+      // STEP 2: Check access to the current and target locations
+      // ============================================================================
+      const current = await getMachineRecord(machineId);
+      if (!current) {
+        return NextResponse.json(
+          { success: false, error: 'Machine not found' },
+          { status: 404 }
+        );
+      }
+      const locationIds = [current.gamingLocation, input.gamingLocation];
+      for (const locationId of locationIds) {
+        if (locationId && !(await checkUserLocationAccess(locationId))) {
+          logRouteError(
+            functionName,
+            'PATCH',
+            '/api/machines/[machineId]',
+            'Forbidden',
+            user
+          );
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+      }
+
+      // ============================================================================
+      // STEP 3: Apply the update
+      // ============================================================================
+      const machine = await updateMachineRecord(machineId, input, {
+        _id: String(userPayload._id),
+        username: String(
+          userPayload.emailAddress || userPayload.username || userPayload._id
+        ),
+      });
+
+      // ============================================================================
+      // STEP 4: Return
+      // ============================================================================
+      revalidatePath('/cabinets');
+      const duration = Date.now() - startTime;
+      logRouteUpdate(
+        functionName,
+        'PATCH',
+        '/api/machines/[machineId]',
+        1,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+      return NextResponse.json({ success: true, data: machine });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Failed to update machine';
+      logRouteError(
+        functionName,
+        'PATCH',
+        '/api/machines/[machineId]',
+        errorMessage,
+        user
+      );
+      const currentVersion = (error as Record<string, unknown>).currentVersion;
+      return NextResponse.json(
+        {
+          success: false,
+          error: errorMessage,
+          ...(currentVersion !== undefined ? { currentVersion } : {}),
+        },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -0,0 +1,153 @@
+/**
+ * Machine Batch Lookup API Route
+ *
+ * Looks up a pasted list of serial numbers in one request and returns each
+ * machine's location, licencee, status and meter summary, plus the serials
+ * that matched nothing.
+ * It supports:
+ * - Role-based licencee and location access
+ * - A serial array or raw pasted text (lines, commas, spaces)
+ * - CSV export (`format=csv`) with a `NOT FOUND` row per missing serial
+ *
+ * @module app/api/machines/lookup/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportMachineLookupToCSV,
+  lookupMachinesBySerial,
+  parseSerialList,
+} from '@/app/api/lib/helpers/machineLookup';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { lookupMachinesRoute } from '@/app/api/lib/routeSchemas/machines';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * POST /api/machines/lookup
+ *
+ * Body:
+ * @param serials  {string[]} Serial numbers to look up, or
+ * @param text     {string}   Pasted list (one per line, or comma/space separated)
+ * @param licencee {string}   Optional. Scopes results to this licencee.
+ * @param format   {string}   Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse and validate the serial list
+ * 2. Resolve the user's accessible locations
+ * 3. Look up the machines via `lookupMachinesBySerial`
+ * 4. Return JSON or CSV
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/machines/lookup';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate the serial list
+      // ============================================================================
+      const validation = parseBody(
+        lookupMachinesRoute,
+        await request.json().catch(() => ({}))
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+      const serials = [
+        ...(body.serials ?? []),
+        ...(body.text ? parseSerialList(body.text) : []),
+      ];
+      if (serials.length === 0) {
+        return NextResponse.json(
+          { success: false, error: 'serials or text is required' },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        body.licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      // ============================================================================
+      // STEP 3: Look up the machines
+      // ============================================================================
+      const result = await lookupMachinesBySerial(serials, allowedLocationIds);
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'POST',
+        '/api/machines/lookup',
+        result.found.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (body.format === 'csv') {
+        return new NextResponse(exportMachineLookupToCSV(result), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': 'attachment; filename="machine-lookup.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: result });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/machines/lookup',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
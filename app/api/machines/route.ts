@@ -0,0 +1,168 @@
+/**
+ * Machines API Route
+ *
+ * GET lists machines with the standard list params (see listEndpoint).
+ *
+ * POST is validated machine creation for internal tools. Accepts only the core
+ * assignment fields (serial number, location, game, custom name, SMIB id);
+ * everything else gets the regular cabinet defaults.
+ * It supports:
+ * - Schema validation (400 with details)
+ * - Location access checks
+ * - Serial/SMIB deduplication (409)
+ *
+ * @module app/api/machines/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  createMachineRecord,
+  machineCreateSchema,
+} from '@/app/api/lib/helpers/cabinets/machineWriteOperations';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import { handleListRequest } from '@/app/api/lib/helpers/listEndpoint';
+import { machineListResource } from '@/app/api/lib/helpers/listResources';
+import type { MachineListItem } from '@/app/api/lib/helpers/listResources';
+import {
+  logRouteCreate,
+  logRouteError,
+  logRouteFetch,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { revalidatePath } from 'next/cache';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/machines
+ *
+ * Query params: `limit`, `cursor`, `sort` (serialNumber, custom.name, game,
+ * lastActivity, createdAt; default serialNumber), `licencee`, `location`,
+ * `status` and `from` / `to` on `createdAt`.
+ *
+ * Returns `{ success, data, pagination, sort, filters }`; deleted machines
+ * are excluded.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/machines';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async auth => {
+    try {
+      const page = await handleListRequest<MachineListItem>(
+        request,
+        machineListResource,
+        auth
+      );
+
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/machines',
+        page.data.length,
+        user,
+        duration
+      );
+      return NextResponse.json(page);
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Failed to fetch machines';
+      logRouteError(functionName, 'GET', '/api/machines', errorMessage, user);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
+
+/**
+ * POST /api/machines
+ *
+ * Body:
+ * @param serialNumber   {string} Required.
+ * @param gamingLocation {string} Required. Location id.
+ * @param game           {string} Optional.
+ * @param gameType       {string} Optional. Defaults to 'slot'.
+ * @param customName     {string} Optional. Defaults to the serial number.
+ * @param smibId         {string} Optional. SMIB relay id.
+ *
+ * Flow:
+ * 1. Validate the body
+ * 2. Check access to the location
+ * 3. Create the machine via `createMachineRecord`
+ * 4. Return it with `version: 0`
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/machines';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    try {
+      // ============================================================================
+      // STEP 1: Validate the body
+      // ============================================================================
+      const body = await request.json().catch(() => null);
+      const validationResult = machineCreateSchema.safeParse(body);
+      if (!validationResult.success) {
+        logRouteError(
+          functionName,
+          'POST',
+          '/api/machines',
+          'Validation failed',
+          user
+        );
+        return NextResponse.json(
+          {
+            success: false,
+            error: 'Validation failed',
+            details: validationResult.error.errors,
+          },
+          { status: 400 }
+        );
+      }
+      const input = validationResult.data;
+
+      // ============================================================================
+      // STEP 2: Check access to the location
+      // ============================================================================
+      if (!(await checkUserLocationAccess(input.gamingLocation))) {
+        logRouteError(functionName, 'POST', '/api/machines', 'Forbidden', user);
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 3: Create the machine
+      // ============================================================================
+      const machine = await createMachineRecord(input);
+
+      // ============================================================================
+      // STEP 4: Return
+      // ============================================================================
+      revalidatePath('/cabinets');
+      const duration = Date.now() - startTime;
+      logRouteCreate(functionName, 'POST', '/api/machines', 1, user, duration);
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+      return NextResponse.json(
+        { success: true, data: machine },
+        { status: 201 }
+      );
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Failed to create machine';
+      logRouteError(functionName, 'POST', '/api/machines', errorMessage, user);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -0,0 +1,88 @@
+/**
+ * Machine Status Definitions API Route
+ *
+ * Returns the online/offline and asset status definitions reports use, so
+ * the UI labels machines the same way the server counts them: the online
+ * threshold (and whether it comes from the default, the deployment or the
+ * licencee) and the asset statuses that take a machine out of service.
+ *
+ * @module app/api/machines/status-definitions/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getLicenceeMachineStatus } from '@/app/api/lib/helpers/licencees';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { machineStatusDefinitionsRoute } from '@/app/api/lib/routeSchemas/machines';
+import { getMachineStatusDefinitions } from '@/app/api/lib/utils/machineStatus';
+import { NextRequest, NextResponse } from 'next/server';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+
+/**
+ * GET /api/machines/status-definitions
+ *
+ * Query params:
+ * @param licencee {string} Optional. Licencee ID; applies its override.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/machines/status-definitions';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    // ============================================================================
+    // STEP 1: Validate licencee access
+    // ============================================================================
+    const query = parseQuery(machineStatusDefinitionsRoute, request);
+    if (!query.success) return validationErrorResponse(query.error);
+    const { licencee } = query.data;
+    const effectiveLicencee =
+      licencee && licencee.toLowerCase() !== 'all' ? licencee : null;
+
+    if (effectiveLicencee) {
+      const accessibleLicencees = await getUserAccessibleLicenceesFromToken();
+      if (
+        accessibleLicencees !== 'all' &&
+        !accessibleLicencees.includes(effectiveLicencee)
+      ) {
+        logRouteError(
+          functionName,
+          'GET',
+          '/api/machines/status-definitions',
+          'Unauthorized: You do not have access to this licencee',
+          user
+        );
+        return NextResponse.json(
+          { message: 'Unauthorized: You do not have access to this licencee' },
+          { status: 403 }
+        );
+      }
+    }
+
+    // ============================================================================
+    // STEP 2: Resolve and return the definitions
+    // ============================================================================
+    const definitions = getMachineStatusDefinitions(
+      await getLicenceeMachineStatus(effectiveLicencee)
+    );
+
+    logRouteFetch(
+      functionName,
+      'GET',
+      '/api/machines/status-definitions',
+      1,
+      user,
+      Date.now() - startTime
+    );
+    return NextResponse.json({ success: true, data: definitions });
+  });
+}
@@ -0,0 +1,156 @@
+/**
+ * Machine View API Route
+ *
+ * Reads or deletes one of the caller's saved machine views by name.
+ *
+ * @module app/api/machines/views/[name]/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  deleteMachineView,
+  getMachineView,
+} from '@/app/api/lib/helpers/machineViews';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/machines/views/[name]
+ *
+ * Returns the view definition, or 404 when the caller has no view by that
+ * name.
+ */
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ name: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/machines/views/[name]';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload }) => {
+    try {
+      const { name } = await params;
+      const view = await getMachineView(String(userPayload._id), name);
+      if (!view) {
+        return NextResponse.json(
+          { success: false, error: 'View not found' },
+          { status: 404 }
+        );
+      }
+
+      logRouteFetch(
+        functionName,
+        'GET',
+        `/api/machines/views/${name}`,
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: view });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/machines/views/[name]',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * DELETE /api/machines/views/[name]
+ *
+ * Flow:
+ * 1. Delete the view
+ * 2. Log activity
+ * 3. Return the deleted view
+ */
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ name: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'DELETE /api/machines/views/[name]';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload }) => {
+    try {
+      const { name } = await params;
+
+      // ============================================================================
+      // STEP 1: Delete the view
+      // ============================================================================
+      const view = await deleteMachineView(String(userPayload._id), name);
+      if (!view) {
+        return NextResponse.json(
+          { success: false, error: 'View not found' },
+          { status: 404 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Log activity
+      // ============================================================================
+      try {
+        await logActivity({
+          action: 'DELETE',
+          details: `Deleted machine view ${view.name}`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'machineView',
+            resourceId: view._id,
+            resourceName: view.name,
+            changes: [],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      // ============================================================================
+      // STEP 3: Return the deleted view
+      // ============================================================================
+      logRouteFetch(
+        functionName,
+        'DELETE',
+        `/api/machines/views/${name}`,
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: view });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'DELETE',
+        '/api/machines/views/[name]',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
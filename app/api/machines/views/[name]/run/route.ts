@@ -0,0 +1,101 @@
+/**
+ * Machine View Run API Route
+ *
+ * Lists the machines of one of the caller's saved views, within the
+ * caller's location access.
+ *
+ * @module app/api/machines/views/[name]/run/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getMachineView,
+  runMachineView,
+} from '@/app/api/lib/helpers/machineViews';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { runMachineViewRoute } from '@/app/api/lib/routeSchemas/machines';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/machines/views/[name]/run
+ *
+ * @param limit Optional. Page size, as for `GET /api/machines`.
+ * @param cursor Optional. `nextCursor` from the previous page.
+ *
+ * Flow:
+ * 1. Load the view
+ * 2. Run it
+ * 3. Return the page with the view's columns
+ */
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ name: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/machines/views/[name]/run';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async auth => {
+    try {
+      // ============================================================================
+      // STEP 1: Load the view
+      // ============================================================================
+      const { name } = await params;
+      const view = await getMachineView(String(auth.user._id), name);
+      if (!view) {
+        return NextResponse.json(
+          { success: false, error: 'View not found' },
+          { status: 404 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Run it
+      // ============================================================================
+      const query = parseQuery(runMachineViewRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { searchParams } = new URL(request.url);
+      const page = await runMachineView(view, auth, {
+        limit: searchParams.get('limit'),
+        cursor: searchParams.get('cursor'),
+      });
+
+      // ============================================================================
+      // STEP 3: Return the page
+      // ============================================================================
+      logRouteFetch(
+        functionName,
+        'GET',
+        `/api/machines/views/${name}/run`,
+        page.data.length,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json(page);
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/machines/views/[name]/run',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
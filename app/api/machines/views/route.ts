@@ -0,0 +1,170 @@
+/**
+ * Machine Views API Route
+ *
+ * Lists and saves the caller's named machine views (filters, columns and
+ * sort; see app/api/lib/helpers/machineViews.ts). Views belong to the user
+ * who saved them.
+ *
+ * @module app/api/machines/views/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  listMachineViews,
+  saveMachineView,
+} from '@/app/api/lib/helpers/machineViews';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { saveMachineViewRoute } from '@/app/api/lib/routeSchemas/machines';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/machines/views
+ *
+ * Returns the caller's views, by name.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/machines/views';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload }) => {
+    try {
+      const views = await listMachineViews(String(userPayload._id));
+
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/machines/views',
+        views.length,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json({ success: true, data: views });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/machines/views',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * POST /api/machines/views
+ *
+ * @body {string}   name        Required. Letters, digits, `-` or `_` (max 64); replaces the caller's view of that name.
+ * @body {object}   filters     Optional. `licencee`, `locations` (IDs) and `statuses`, as the machine list filters.
+ * @body {string[]} columns     Optional. Machine list fields to show; defaults to serial number, name, game, location, status and last activity.
+ * @body {string}   sort        Optional. A machine list sort field, `-` prefixed for descending.
+ * @body {string}   description Optional.
+ *
+ * Flow:
+ * 1. Parse request body
+ * 2. Save the view
+ * 3. Log activity
+ * 4. Return the view (201 when created)
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/machines/views';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request body
+      // ============================================================================
+      const validation = parseBody(
+        saveMachineViewRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+
+      // ============================================================================
+      // STEP 2: Save the view
+      // ============================================================================
+      const { view, created } = await saveMachineView({
+        userId: String(userPayload._id),
+        name: body.name,
+        description: body.description,
+        filters: body.filters,
+        columns: body.columns,
+        sort: body.sort,
+      });
+
+      // ============================================================================
+      // STEP 3: Log activity
+      // ============================================================================
+      try {
+        await logActivity({
+          action: created ? 'CREATE' : 'UPDATE',
+          details: `${created ? 'Created' : 'Updated'} machine view ${view.name}`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'machineView',
+            resourceId: view._id,
+            resourceName: view.name,
+            changes: [],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      // ============================================================================
+      // STEP 4: Return the view
+      // ============================================================================
+      logRouteFetch(
+        functionName,
+        'POST',
+        '/api/machines/views',
+        1,
+        user,
+        Date.now() - startTime
+      );
+      return NextResponse.json(
+        { success: true, data: view },
+        { status: created ? 201 : 200 }
+      );
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/machines/views',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -17,6 +17,11 @@ import {
 } from '@/app/api/lib/helpers/activityLogger';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Member } from '@/app/api/lib/models/members';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { updateMemberRoute } from '@/app/api/lib/routeSchemas/members';
 import {
   logRouteFetch,
   logRouteUpdate,
@@ -114,7 +119,7 @@ export async function GET(request: NextRequest) {
  * @param username         {string}  Member's login/display username; must be unique.
  * @param phoneNumber      {string}  Member's contact phone number.
  * @param points           {number}  Current loyalty points balance.
- * @param uaccount         {unknown} Universal account reference object.
+ * @param uaccount         {number}  Universal account number.
  * @param gamingLocation   {string}  ID of the gaming location this member belongs to.
  */
 export async function PUT(request: NextRequest) {
@@ -125,17 +130,22 @@ export async function PUT(request: NextRequest) {
   return withApiAuth(request, async ({ user: currentUser, userRoles, isAdminOrDev }) => {
     try {
       // ============================================================================
-      // STEP 1: Parse route parameters and request body
+      // STEP 1: Parse route parameters and validate request body
       // ============================================================================
       const { pathname } = request.nextUrl;
       const memberId = pathname.split('/').pop();
-      const body = await request.json();
+      const validation = parseBody(updateMemberRoute, await request.json().catch(() => null));
+      if (!validation.success) {
+        logRouteError(functionName, 'PUT', '/api/members/[id]', 'Invalid request body', user);
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
 
       // ============================================================================
       // STEP 2: Validate member ID
       // ============================================================================
       if (!memberId) {
-        return NextResponse.json({ error: 'Member ID is required' }, { status: 400 });
+        return NextResponse.json({ success: false, error: 'Member ID is required' }, { status: 400 });
       }
 
       // ============================================================================
@@ -148,7 +158,7 @@ export async function PUT(request: NextRequest) {
       if (!isAdminOrDeveloper) {
         logRouteError(functionName, 'PUT', '/api/members/[id]', 'Forbidden - insufficient permissions', user);
         return NextResponse.json(
-          { error: 'Forbidden: Only administrators and developers can edit members' },
+          { success: false, error: 'Forbidden: Only administrators and developers can edit members' },
           { status: 403 }
         );
       }
@@ -160,7 +170,7 @@ export async function PUT(request: NextRequest) {
 
       if (!member) {
         logRouteError(functionName, 'PUT', '/api/members/[id]', `Member not found: ${memberId}`, user);
-        return NextResponse.json({ error: 'Member not found' }, { status: 404 });
+        return NextResponse.json({ success: false, error: 'Member not found' }, { status: 404 });
       }
 
       const originalMemberData = member.toObject();
@@ -182,28 +192,13 @@ export async function PUT(request: NextRequest) {
           };
         }
 
-        const trimString = (value: unknown): string =>
-          typeof value === 'string' ? value.trim() : '';
-
-        if (body.profile.firstName !== undefined) {
-          const trimmed = trimString(body.profile.firstName);
-          if (!trimmed) {
-            return NextResponse.json({ error: 'First name cannot be blank' }, { status: 400 });
-          }
-        }
-        if (body.profile.lastName !== undefined) {
-          const trimmed = trimString(body.profile.lastName);
-          if (!trimmed) {
-            return NextResponse.json({ error: 'Last name cannot be blank' }, { status: 400 });
-          }
-        }
         if (body.profile.email !== undefined) {
-          const trimmedEmail = trimString(body.profile.email);
+          const trimmedEmail = body.profile.email.trim();
           if (trimmedEmail) {
             const exists = await checkMemberFieldUniqueness('profile.email', trimmedEmail, memberId);
             if (exists) {
               logRouteError(functionName, 'PUT', '/api/members/[id]', 'Email address already in use', user);
-              return NextResponse.json({ error: 'Email address already in use' }, { status: 400 });
+              return NextResponse.json({ success: false, error: 'Email address already in use' }, { status: 400 });
             }
           }
         }
@@ -218,20 +213,16 @@ export async function PUT(request: NextRequest) {
       }
 
       if (body.username !== undefined) {
-        const trimmedUsername = typeof body.username === 'string' ? body.username.trim() : '';
-        if (!trimmedUsername) {
-          return NextResponse.json({ error: 'Username is required and cannot be blank' }, { status: 400 });
-        }
-        const exists = await checkMemberFieldUniqueness('username', trimmedUsername, memberId);
+        const exists = await checkMemberFieldUniqueness('username', body.username, memberId);
         if (exists) {
           logRouteError(functionName, 'PUT', '/api/members/[id]', 'Username already exists', user);
-          return NextResponse.json({ error: 'Username already exists' }, { status: 400 });
+          return NextResponse.json({ success: false, error: 'Username already exists' }, { status: 400 });
         }
-        member.username = trimmedUsername;
+        member.username = body.username;
       }
 
       if (body.phoneNumber !== undefined) {
-        member.phoneNumber = typeof body.phoneNumber === 'string' ? body.phoneNumber.trim() : body.phoneNumber;
+        member.phoneNumber = body.phoneNumber.trim();
       }
       if (body.points !== undefined) {
         member.points = body.points;
@@ -240,16 +231,7 @@ export async function PUT(request: NextRequest) {
         member.uaccount = body.uaccount;
       }
       if (body.gamingLocation !== undefined) {
-        const trimmedLocation = typeof body.gamingLocation === 'string' ? body.gamingLocation.trim() : '';
-        if (trimmedLocation) {
-          member.gamingLocation = trimmedLocation;
-        } else {
-          logRouteError(functionName, 'PUT', '/api/members/[id]', 'gamingLocation is required and cannot be empty', user);
-          return NextResponse.json(
-            { error: 'gamingLocation is required and cannot be empty' },
-            { status: 400 }
-          );
-        }
+        member.gamingLocation = body.gamingLocation;
       }
 
       if (!member.gamingLocation || member.gamingLocation.trim() === '') {
@@ -324,7 +306,7 @@ export async function DELETE(request: NextRequest) {
       // STEP 2: Validate member ID
       // ============================================================================
       if (!memberId) {
-        return NextResponse.json({ error: 'Member ID is required' }, { status: 400 });
+        return NextResponse.json({ success: false, error: 'Member ID is required' }, { status: 400 });
       }
 
       // ============================================================================
@@ -343,6 +325,7 @@ export async function DELETE(request: NextRequest) {
         );
         return NextResponse.json(
           {
+            success: false,
             error:
               'Forbidden: Only administrators and developers can delete members',
           },
@@ -357,7 +340,7 @@ export async function DELETE(request: NextRequest) {
 
       if (!success || !member) {
         logRouteError(functionName, 'DELETE', '/api/members/[id]', `Member not found: ${memberId}`, user);
-        return NextResponse.json({ error: 'Member not found' }, { status: 404 });
+        return NextResponse.json({ success: false, error: 'Member not found' }, { status: 404 });
       }
 
       // ============================================================================
@@ -7,17 +7,24 @@
  * - Pagination
  * - Optimized queries with indexing considerations
  * - Unique filter values for frontend dropdowns
+ * - Decoded SAS exception codes (`sasEvent`) on each event
  *
  * @module app/api/members/[id]/sessions/[machineId]/events/route
  */
 
 import { MachineEvent } from '@/app/api/lib/models/machineEvents';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listMemberMachineEventsRoute } from '@/app/api/lib/routeSchemas/members';
 import {
   logRouteFetch,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { withSasEvents } from '@/app/api/lib/utils/sasCodes';
 import type { MachineEventDocument } from '@shared/types';
 import { NextRequest, NextResponse } from 'next/server';
 
@@ -49,18 +56,18 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse route parameters
+      // STEP 1: Parse route parameters and validate query parameters
       // ============================================================================
-      const { pathname, searchParams } = request.nextUrl;
+      const parsed = parseQuery(listMemberMachineEventsRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const { pathname } = request.nextUrl;
       const parts = pathname.split('/');
       const machineId = parts[parts.length - 2];
       // id is not used in this route, so we don't need to extract it
 
-      const eventType = searchParams.get('eventType');
-      const event = searchParams.get('event');
-      const game = searchParams.get('game');
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = parseInt(searchParams.get('limit') || '10');
+      const { eventType, event, game, page, limit } = parsed.data;
 
       // ============================================================================
       // STEP 2: Build query with filters
@@ -159,7 +166,7 @@ export async function GET(request: NextRequest) {
     return NextResponse.json({
       success: true,
       data: {
-        events,
+        events: withSasEvents(events),
         pagination: {
           currentPage: page,
           totalPages: Math.ceil(totalEvents / limit),
@@ -14,11 +14,15 @@
 
 import {
   applyCurrencyConversionToMetrics,
-  getCurrencyFromQuery,
   shouldApplyCurrencyConversion,
 } from '@/app/api/lib/helpers/currency/helper';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listMemberSessionsRoute } from '@/app/api/lib/routeSchemas/members';
 import {
   logRouteFetch,
   logRouteError,
@@ -47,8 +51,8 @@ function getWeekNumber(date: Date): number {
  *
  * Fetches machine sessions for a specific member with optional date filtering,
  * time-period presets, and display-currency conversion. When `filter` is `session`
- * the response is paginated individual rows; any other value groups sessions by
- * day, week, or month and returns a single aggregated page.
+ * the response is paginated individual rows; `day`, `week` or `month` groups
+ * sessions by that period and returns a single aggregated page.
  *
  * URL params:
  * @param {string} id - Required (path). The string `_id` of the member whose sessions are fetched.
@@ -60,8 +64,8 @@ function getWeekNumber(date: Date): number {
  *                             Ignored when `filter` is not `session`.
  * @param {string} [filter] - Optional. Controls result shape. `session` (default) returns individual rows;
  *                             `day`, `week`, or `month` collapses sessions into aggregate groups.
- * @param {string} [displayCurrency] - Optional. ISO currency code to convert financial metrics into
- *                             (e.g. `"TTD"`, `"USD"`). Resolved via `getCurrencyFromQuery`.
+ * @param {string} [currency] - Optional. Currency code to convert financial metrics into
+ *                             (`USD`, `TTD`, `GYD` or `BBD`; default: `USD`).
  * @param {string} [licencee] - Optional. Licencee ID used to look up the currency conversion rate
  *                             for the location. If absent, conversion is skipped.
  * @param {string} [startDate] - Optional. ISO date string for the start of a custom date range.
@@ -79,8 +83,12 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse route parameters
+      // STEP 1: Parse route parameters and validate query parameters
       // ============================================================================
+      const parsed = parseQuery(listMemberSessionsRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
       const { pathname } = request.nextUrl;
       const parts = pathname.split('/');
       const id = parts[parts.length - 2]; // Extract [id] from /api/members/[id]/sessions
@@ -89,15 +97,16 @@ export async function GET(request: NextRequest) {
       // STEP 2: Build query for member sessions
       // ============================================================================
 
-    const { searchParams } = new URL(request.url);
-    const page = parseInt(searchParams.get('page') || '1');
-    const limit = parseInt(searchParams.get('limit') || '10');
-    const filter = searchParams.get('filter') || 'session';
-    const displayCurrency = getCurrencyFromQuery(searchParams);
-    const licencee = searchParams.get('licencee') || null;
-    const startDateParam = searchParams.get('startDate');
-    const endDateParam = searchParams.get('endDate');
-    const timePeriod = searchParams.get('timePeriod');
+    const {
+      page,
+      limit,
+      filter,
+      currency: displayCurrency,
+      startDate: startDateParam,
+      endDate: endDateParam,
+      timePeriod,
+    } = parsed.data;
+    const licencee = parsed.data.licencee || null;
 
     const query: Record<string, unknown> = { memberId: id };
 
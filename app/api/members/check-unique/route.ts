@@ -9,6 +9,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Member } from '@/app/api/lib/models/members';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { checkMemberUniqueRoute } from '@/app/api/lib/routeSchemas/members';
 import {
   logRouteFetch,
   logRouteError,
@@ -38,12 +43,14 @@ export async function GET(req: NextRequest) {
   return withApiAuth(req, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse query parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-      const username = searchParams.get('username');
-      const email = searchParams.get('email');
-      const excludeId = searchParams.get('excludeId'); // For edit mode - exclude current member
+      const query = parseQuery(checkMemberUniqueRoute, req);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      // excludeId: for edit mode - exclude current member
+      const { username, email, excludeId } = query.data;
 
       // ============================================================================
       // STEP 2: Check username uniqueness
@@ -18,6 +18,7 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { PipelineStage } from 'mongoose';
 import { NextRequest, NextResponse } from 'next/server';
 
@@ -96,13 +97,7 @@ export async function GET(req: NextRequest) {
     // Use aggregation to join members with locations for filtering
     const aggregationPipeline: PipelineStage[] = [
       {
-        $match: {
-          $or: [
-            { deletedAt: null },
-            { deletedAt: { $exists: false } },
-            { deletedAt: { $lt: new Date('2025-01-01') } }, // Include items deleted before 2025
-          ],
-        },
+        $match: { ...NOT_DELETED_FILTER },
       },
       {
         $lookup: {
@@ -47,7 +47,10 @@ export async function GET(request: NextRequest) {
         'Forbidden - insufficient permissions',
         user
       );
-      return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
     }
 
     try {
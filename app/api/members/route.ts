@@ -22,6 +22,16 @@ import {
   getUserLocationFilter,
 } from '@/app/api/lib/helpers/licenceeFilter';
 import { Member } from '@/app/api/lib/models/members';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createMemberRoute,
+  listMembersRoute,
+} from '@/app/api/lib/routeSchemas/members';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { generateMongoId } from '@/lib/utils/id';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import {
@@ -31,7 +41,6 @@ import {
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
 import type { PipelineStage } from 'mongoose';
-import type { CurrencyCode } from '@/shared/types/currency';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
@@ -57,20 +66,25 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async ({ user: currentUser, userRoles }) => {
     try {
       // ============================================================================
-      // STEP 1: Parse query parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const search = searchParams.get('search') || '';
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = parseInt(searchParams.get('limit') || '10');
-      const sortBy = searchParams.get('sortBy') || 'createdAt';
-      const sortOrder = searchParams.get('sortOrder') || 'desc';
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
-      const winLossFilter = searchParams.get('winLossFilter');
-      const locationFilter = searchParams.get('locationFilter');
-      const displayCurrency = searchParams.get('currency') || 'USD';
-      const licencee = searchParams.get('licencee') || null;
+      const parsed = parseQuery(listMembersRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const {
+        search,
+        page,
+        limit,
+        sortBy,
+        sortOrder,
+        startDate,
+        endDate,
+        winLossFilter,
+        locationFilter,
+        currency: displayCurrency,
+      } = parsed.data;
+      const licencee = parsed.data.licencee || null;
 
       // ============================================================================
       // STEP 2: Resolve multi-tenant location scope for the requesting user
@@ -112,13 +126,7 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 3: Build query filter
       // ============================================================================
-      const query: Record<string, unknown> = {
-        $or: [
-          { deletedAt: null },
-          { deletedAt: { $exists: false } },
-          { deletedAt: { $lt: new Date('2025-01-01') } },
-        ],
-      };
+      const query: Record<string, unknown> = { ...NOT_DELETED_FILTER };
 
       // Scope members to the user's accessible locations (multi-tenant isolation)
       if (allowedLocationIds !== 'all') {
@@ -340,7 +348,7 @@ export async function GET(request: NextRequest) {
       const convertedMembers = await applyCurrencyConversionToMetrics(
         members,
         licencee,
-        displayCurrency as CurrencyCode
+        displayCurrency
       );
 
       const duration = Date.now() - startTime;
@@ -400,24 +408,24 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
-      const trimmedFirstName = body.profile?.firstName?.trim();
-      const trimmedLastName = body.profile?.lastName?.trim();
-      const trimmedUsername = body.username?.trim();
-
-      if (!trimmedFirstName || !trimmedLastName || !trimmedUsername) {
+      const validation = parseBody(
+        createMemberRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/members',
-          'Missing required fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { error: 'First name, last name, and username are required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const body = validation.data;
+      const trimmedFirstName = body.profile.firstName;
+      const trimmedLastName = body.profile.lastName;
+      const trimmedUsername = body.username;
 
       // ============================================================================
       // STEP 2: Check username uniqueness
@@ -434,7 +442,7 @@ export async function POST(request: NextRequest) {
           user
         );
         return NextResponse.json(
-          { error: 'Username already exists' },
+          { success: false, error: 'Username already exists' },
           { status: 400 }
         );
       }
@@ -0,0 +1,269 @@
+/**
+ * Member Self-Exclusions API Route
+ *
+ * Lists and records self-excluded members (`selfexclusions`). Sessions
+ * played by an excluded member while the exclusion is in force are reported
+ * by `bun run self-exclusion:check`.
+ * It supports:
+ * - Role-based licencee and location access (the member's location)
+ * - Only exclusions in force now (`active=true`)
+ *
+ * @module app/api/members/self-exclusions/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  addSelfExclusion,
+  listSelfExclusions,
+} from '@/app/api/lib/helpers/members/selfExclusion';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  addSelfExclusionRoute,
+  listSelfExclusionsRoute,
+} from '@/app/api/lib/routeSchemas/members';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/members/self-exclusions
+ *
+ * Query params:
+ * @param licencee   {string}  Optional. Scopes results to this licencee.
+ * @param locationId {string}  Optional. Only members of this location.
+ * @param active     {boolean} Optional. Only exclusions in force now.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. List exclusions via `listSelfExclusions`
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/members/self-exclusions';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(listSelfExclusionsRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee, locationId } = query.data;
+      const activeOnly = query.data.active === 'true';
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: List exclusions
+      // ============================================================================
+      const exclusions = await listSelfExclusions({
+        allowedLocationIds,
+        activeOnly,
+      });
+
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/members/self-exclusions',
+        exclusions.length,
+        user,
+        duration
+      );
+      return NextResponse.json({ success: true, data: exclusions });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/members/self-exclusions',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * POST /api/members/self-exclusions
+ *
+ * @body {string} memberId  Required. Member to exclude.
+ * @body {string} startDate Optional. ISO start of the exclusion. Defaults to now.
+ * @body {string} endDate   Optional. ISO end; omit for an indefinite exclusion.
+ * @body {string} reason    Optional. Note for the compliance record.
+ *
+ * Flow:
+ * 1. Parse request body
+ * 2. Resolve the user's accessible locations
+ * 3. Record the exclusion via `addSelfExclusion`
+ * 4. Log activity
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/members/self-exclusions';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request body
+      // ============================================================================
+      const validation = parseBody(
+        addSelfExclusionRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      // ============================================================================
+      // STEP 3: Record the exclusion
+      // ============================================================================
+      const exclusion = await addSelfExclusion({
+        memberId: body.memberId,
+        startDate: body.startDate ? new Date(body.startDate) : undefined,
+        endDate: body.endDate ? new Date(body.endDate) : null,
+        reason: body.reason,
+        recordedBy: String(userPayload._id),
+        allowedLocationIds,
+      });
+
+      // ============================================================================
+      // STEP 4: Log activity
+      // ============================================================================
+      try {
+        await logActivity({
+          action: 'CREATE',
+          details: `Recorded self-exclusion for member ${exclusion.member}`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'member',
+            resourceId: exclusion.member,
+            resourceName: exclusion.member,
+            changes: [
+              {
+                field: 'selfExclusion',
+                oldValue: null,
+                newValue: {
+                  startDate: exclusion.startDate,
+                  endDate: exclusion.endDate,
+                },
+              },
+            ],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'POST',
+        '/api/members/self-exclusions',
+        1,
+        user,
+        duration
+      );
+      return NextResponse.json(
+        { success: true, data: exclusion },
+        { status: 201 }
+      );
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/members/self-exclusions',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
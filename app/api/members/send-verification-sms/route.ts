@@ -21,6 +21,11 @@ import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { getUserFromServer } from '@/app/api/lib/helpers/users';
 import { sendVerificationSMS } from '@/app/api/lib/helpers/sms';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { sendMemberVerificationSmsRoute } from '@/app/api/lib/routeSchemas/members';
 import {
   logRouteFetch,
   logRouteError,
@@ -36,44 +41,20 @@ export async function GET(req: NextRequest) {
   return withApiAuth(req, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse Query Parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(req.url);
-    const memberId = searchParams.get('memberId');
-    const phoneNumber = searchParams.get('phoneNumber');
-
-    if (!memberId || !phoneNumber) {
-      logRouteError(
-        functionName,
-        'GET',
-        '/api/members/send-verification-sms',
-        'Missing required parameters',
-        logUser
-      );
-      return NextResponse.json(
-        {
-          success: false,
-          error: 'Missing required parameters: memberId and phoneNumber',
-        },
-        { status: 400 }
-      );
-    }
-
-    const phoneRegex = /^\+?[1-9]\d{1,14}$/;
-
-    if (!phoneRegex.test(phoneNumber)) {
+      const query = parseQuery(sendMemberVerificationSmsRoute, req);
+    if (!query.success) {
       logRouteError(
         functionName,
         'GET',
         '/api/members/send-verification-sms',
-        'Invalid phone number format',
+        'Invalid query parameters',
         logUser
       );
-      return NextResponse.json(
-        { success: false, error: 'Invalid phone number format' },
-        { status: 400 }
-      );
+      return validationErrorResponse(query.error);
     }
+    const { memberId, phoneNumber } = query.data;
 
     // ============================================================================
     // STEP 2: Send SMS and update member record
@@ -16,11 +16,17 @@
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Member } from '@/app/api/lib/models/members';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { membersSummaryRoute } from '@/app/api/lib/routeSchemas/members';
 import {
   logRouteFetch,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
@@ -54,32 +60,25 @@ export async function GET(request: NextRequest) {
 
   try {
     // ============================================================================
-    // STEP 1: Parse query parameters
+    // STEP 1: Parse and validate query parameters
     // ============================================================================
 
     // Example: GET /api/members/summary?licencee=9a5db2cb29ffd2d962fd1d91&page=1&limit=10&search=John&location=6801f2a3b4c5d6e7f8901234
-    const { searchParams } = new URL(request.url);
+    const query = parseQuery(membersSummaryRoute, request);
+    if (!query.success) {
+      return validationErrorResponse(query.error);
+    }
     // Licencee filtering removed - show all members regardless of licencee
-    const dateFilter = searchParams.get('dateFilter') || 'all';
-    const startDate = searchParams.get('startDate');
-    const endDate = searchParams.get('endDate');
-    const searchTerm = searchParams.get('search') || '';
-    const locationFilter = searchParams.get('location') || '';
-    const page = parseInt(searchParams.get('page') || '1');
-    const limit = parseInt(searchParams.get('limit') || '10');
+    const { dateFilter, startDate, endDate, page, limit } = query.data;
+    const searchTerm = query.data.search;
+    const locationFilter = query.data.location || '';
     const skip = (page - 1) * limit;
 
     // ============================================================================
     // STEP 3: Build date filter conditions
     // ============================================================================
     // Base match conditions - exclude only members deleted in 2025 or later
-    const matchConditions: Record<string, unknown> = {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $exists: false } },
-        { deletedAt: { $lt: new Date('2025-01-01') } }, // Include items deleted before 2025
-      ],
-    };
+    const matchConditions: Record<string, unknown> = { ...NOT_DELETED_FILTER };
     const dateFilterConditions: Record<string, unknown> = {};
 
     // Build date filter for sessions (not for members - we want to show all members)
@@ -175,11 +174,7 @@ export async function GET(request: NextRequest) {
     // Get total members count (excluding date filter but including location filter if provided) for summary stats
     // totalMembers should count members filtered by location if location filter is provided
     const totalMembersConditions: Record<string, unknown> = {
-      $or: [
-        { deletedAt: null },
-        { deletedAt: { $exists: false } },
-        { deletedAt: { $lt: new Date('2025-01-01') } }, // Include items deleted before 2025
-      ],
+      ...NOT_DELETED_FILTER,
     };
 
     // Include location filter if specified (for location-specific pages)
@@ -396,13 +391,7 @@ export async function GET(request: NextRequest) {
           {
             $or: [{ membershipEnabled: true }, { enableMembership: true }],
           },
-          {
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $exists: false } },
-              { deletedAt: { $lt: new Date('2025-01-01') } }, // Include locations deleted before 2025
-            ],
-          },
+          { ...NOT_DELETED_FILTER },
         ],
       };
 
@@ -5,12 +5,15 @@
  * It supports:
  * - Fetching user metrics from casinoMetrics collection
  * - Validating time period parameter
+ * - An hourly breakdown of Today (`granularity=hourly`), computed from meters
  *
  * @module app/api/metrics/metricsByUser/route
  */
 
 import {
   getUserMetrics,
+  getUserTodayHourlyMetrics,
+  validateGranularity,
   validateTimePeriod,
 } from '@/app/api/lib/helpers/users/metrics';
 import {
@@ -25,11 +28,14 @@ import { NextRequest, NextResponse } from 'next/server';
  *
  * @param {string} userId - ID of the user to fetch metrics for (REQUIRED)
  * @param {string} timePeriod - Time range preset for calculations
+ * @param {string} granularity - Optional. 'hourly' (with timePeriod=Today) adds
+ *                               `TodayHourly`: 24 buckets of drop, cancelled
+ *                               credits and gross per location and in total
  *
  * Flow:
  * 1. Parse and validate request parameters
- * 2. Validate time period
- * 3. Fetch user metrics
+ * 2. Validate time period and granularity
+ * 3. Fetch user metrics (and the hourly breakdown when requested)
  * 4. Return user metrics
  */
 export async function GET(request: NextRequest) {
@@ -44,6 +50,7 @@ export async function GET(request: NextRequest) {
     const { searchParams } = new URL(request.url);
     const userIdStr = searchParams.get('userId');
     const timePeriod = searchParams.get('timePeriod');
+    const granularity = searchParams.get('granularity');
 
     if (!userIdStr) {
       logRouteError(
@@ -60,9 +67,11 @@ export async function GET(request: NextRequest) {
     }
 
     // ============================================================================
-    // STEP 2: Validate time period
+    // STEP 2: Validate time period and granularity
     // ============================================================================
-    const timePeriodError = validateTimePeriod(timePeriod);
+    const timePeriodError =
+      validateTimePeriod(timePeriod) ||
+      validateGranularity(granularity, timePeriod as string);
     if (timePeriodError) {
       logRouteError(
         functionName,
@@ -93,6 +102,13 @@ export async function GET(request: NextRequest) {
       );
     }
 
+    const response = granularity
+      ? {
+          ...(metricsForLocations as Record<string, unknown>),
+          TodayHourly: await getUserTodayHourlyMetrics(userIdStr),
+        }
+      : metricsForLocations;
+
     // ============================================================================
     // STEP 4: Return user metrics
     // ============================================================================
@@ -108,7 +124,7 @@ export async function GET(request: NextRequest) {
     if (duration > 1000) {
       console.warn(`[Metrics By User API] Completed in ${duration}ms`);
     }
-    return NextResponse.json(metricsForLocations, { status: 200 });
+    return NextResponse.json(response, { status: 200 });
   } catch (error) {
     const duration = Date.now() - startTime;
     const errorMessage =
@@ -0,0 +1,112 @@
+/**
+ * Pre-aggregation Status API Route
+ *
+ * Monitoring endpoint for the pre-aggregated `casinoMetrics` collection.
+ * Reports users whose metrics are older than a threshold and compares a sample
+ * of stored values with freshly computed ones. Responds with HTTP 503 when
+ * staleness or drift exceeds the limits so uptime monitors can alert on it.
+ * Hosts without a session run `bun run preaggregate -- status` instead, which
+ * exits 1 on the same conditions.
+ *
+ * @module app/api/metrics/preaggregate-status/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getMetricsFreshnessReport,
+  MAX_FRESHNESS_SAMPLE_SIZE,
+} from '@/app/api/lib/helpers/users/metricsFreshness';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { preaggregateStatusRoute } from '@/app/api/lib/routeSchemas/metrics';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+export const dynamic = 'force-dynamic';
+export const revalidate = 0;
+export const runtime = 'nodejs';
+
+/**
+ * GET /api/metrics/preaggregate-status
+ *
+ * Restricted to admin and developer roles.
+ *
+ * Query params:
+ * @param maxAgeMinutes   {number} Optional. Metrics older than this are stale. Defaults to 60.
+ * @param sampleSize      {number} Optional. Users to recompute for drift (max 50). Defaults to 5.
+ * @param maxStalePercent {number} Optional. Allowed share of stale users (%). Defaults to 0.
+ * @param maxDriftPercent {number} Optional. Allowed drift between stored and fresh values (%). Defaults to 1.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/metrics/preaggregate-status';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ isAdminOrDev }) => {
+    if (!isAdminOrDev) {
+      return NextResponse.json(
+        { success: false, error: 'Forbidden' },
+        { status: 403 }
+      );
+    }
+
+    try {
+      // ============================================================================
+      // STEP 1: Parse thresholds
+      // ============================================================================
+      const query = parseQuery(preaggregateStatusRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const options = {
+        ...query.data,
+        sampleSize: Math.min(query.data.sampleSize, MAX_FRESHNESS_SAMPLE_SIZE),
+      };
+
+      // ============================================================================
+      // STEP 2: Build freshness report
+      // ============================================================================
+      const report = await getMetricsFreshnessReport(options);
+
+      // ============================================================================
+      // STEP 3: Return report (503 when limits are exceeded)
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/metrics/preaggregate-status',
+        report.totalUsers,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json(
+        { success: report.healthy, ...report },
+        { status: report.healthy ? 200 : 503 }
+      );
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/metrics/preaggregate-status',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
@@ -13,7 +13,11 @@
 
 import { getTopMachinesDetailed } from '@/app/api/lib/helpers/reports/topMachines';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import type { TimePeriod } from '@/app/api/lib/types';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { topMachinesRoute } from '@/app/api/lib/routeSchemas/metrics';
 import {
   logRouteFetch,
   logRouteError,
@@ -45,12 +49,11 @@ export async function GET(req: NextRequest) {
     // ============================================================================
     // STEP 1: Parse and validate request parameters
     // ============================================================================
-    const { searchParams } = new URL(req.url);
-    const timePeriod =
-      (searchParams.get('timePeriod') as TimePeriod) || 'Today';
-    const licencee = searchParams.get('licencee');
-    const locationIds = searchParams.get('locationIds');
-    const limit = parseInt(searchParams.get('limit') || '5');
+    const query = parseQuery(topMachinesRoute, req);
+    if (!query.success) {
+      return validationErrorResponse(query.error);
+    }
+    const { timePeriod, licencee, locationIds, limit } = query.data;
 
     // ============================================================================
     // STEP 2: Fetch top machines data
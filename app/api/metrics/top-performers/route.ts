@@ -12,9 +12,21 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { topPerformerRoute } from '@/app/api/lib/routeSchemas/metrics';
 import type { TimePeriod } from '@/app/api/lib/types';
 import { getDatesForTimePeriod } from '@/app/api/lib/utils/dates';
+import {
+  buildMovementTotalsGroup,
+  calculateFinancialMetrics,
+  DEFAULT_FINANCIAL_FORMULA,
+  METER_MOVEMENT_FIELDS,
+} from '@/app/api/lib/utils/financialFormulas';
 // Note: Db type from mongodb not imported to avoid mongoose/mongodb version mismatch
 import type { PipelineStage } from 'mongoose';
 import {
@@ -22,7 +34,16 @@ import {
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import type { MovementTotals } from '@shared/types';
 import { NextRequest, NextResponse } from 'next/server';
+
+/** Per-machine movement totals from `buildTopPerformerPipeline` */
+type TopPerformerTotals = MovementTotals & {
+  machineId: string;
+  machineName: string;
+  gamesPlayed: number;
+};
+
 /**
  * Builds aggregation pipeline for top performer
  *
@@ -86,38 +107,10 @@ function buildTopPerformerPipeline(
         _id: '$machine',
         machineName: { $first: '$machineDetails.Custom.name' },
         serialNumber: { $first: '$machineDetails.serialNumber' },
-        revenue: {
-          $sum: {
-            $subtract: [
-              { $ifNull: ['$movement.drop', 0] },
-              { $ifNull: ['$movement.totalCancelledCredits', 0] },
-            ],
-          },
-        },
-        drop: { $sum: { $ifNull: ['$movement.drop', 0] } },
-        cancelledCredits: {
-          $sum: { $ifNull: ['$movement.totalCancelledCredits', 0] },
-        },
+        ...buildMovementTotalsGroup(),
         gamesPlayed: { $sum: { $ifNull: ['$movement.gamesPlayed', 0] } },
       },
     },
-    {
-      $addFields: {
-        holdPercentage: {
-          $cond: [
-            { $gt: ['$drop', 0] },
-            { $multiply: [{ $divide: ['$revenue', '$drop'] }, 100] },
-            0,
-          ],
-        },
-      },
-    },
-    {
-      $sort: { revenue: -1 },
-    },
-    {
-      $limit: 1,
-    },
     {
       $project: {
         _id: 0,
@@ -129,10 +122,7 @@ function buildTopPerformerPipeline(
             { $concat: ['Machine ', '$serialNumber'] },
           ],
         },
-        revenue: '$revenue',
-        holdPercentage: { $round: ['$holdPercentage', 1] },
-        drop: '$drop',
-        cancelledCredits: '$cancelledCredits',
+        ...Object.fromEntries(METER_MOVEMENT_FIELDS.map(field => [field, 1])),
         gamesPlayed: '$gamesPlayed',
       },
     }
@@ -184,12 +174,32 @@ async function getTopPerformer(
   );
 
   // Use cursor for Meters aggregation
-  const topPerformers: unknown[] = [];
+  const machines: TopPerformerTotals[] = [];
   const cursor = Meters.aggregate(pipeline).cursor({ batchSize: 1000 });
   for await (const doc of cursor) {
-    topPerformers.push(doc);
+    machines.push(doc as TopPerformerTotals);
   }
-  return topPerformers[0] || null;
+
+  // Compose revenue with the location's formula and keep the best machine
+  const formulas = await getLocationFinancialFormulas([locationId]);
+  const formula = formulas.get(locationId) || DEFAULT_FINANCIAL_FORMULA;
+  const [topPerformer] = machines
+    .map(machine => {
+      const metrics = calculateFinancialMetrics(machine, formula);
+      const holdPercentage =
+        metrics.moneyIn > 0 ? (metrics.gross / metrics.moneyIn) * 100 : 0;
+      return {
+        machineId: machine.machineId,
+        machineName: machine.machineName,
+        revenue: metrics.gross,
+        holdPercentage: Math.round(holdPercentage * 10) / 10,
+        drop: metrics.moneyIn,
+        cancelledCredits: metrics.moneyOut,
+        gamesPlayed: machine.gamesPlayed,
+      };
+    })
+    .sort((a, b) => b.revenue - a.revenue);
+  return topPerformer || null;
 }
 
 /**
@@ -217,27 +227,24 @@ export async function GET(req: NextRequest) {
     // ============================================================================
     // STEP 1: Parse and validate request parameters
     // ============================================================================
-    const { searchParams } = new URL(req.url);
-    const locationId = searchParams.get('locationId');
-    const timePeriod =
-      (searchParams.get('timePeriod') as TimePeriod) || 'Today';
-    const licencee = searchParams.get('licencee');
-    const startDateParam = searchParams.get('startDate');
-    const endDateParam = searchParams.get('endDate');
-
-    if (!locationId) {
+    const query = parseQuery(topPerformerRoute, req);
+    if (!query.success) {
       logRouteError(
         functionName,
         'GET',
         '/api/metrics/top-performers',
-        'Location ID is required',
+        'Invalid query parameters',
         user
       );
-      return NextResponse.json(
-        { error: 'Location ID is required' },
-        { status: 400 }
-      );
+      return validationErrorResponse(query.error);
     }
+    const {
+      locationId,
+      timePeriod,
+      licencee,
+      startDate: startDateParam,
+      endDate: endDateParam,
+    } = query.data;
 
     // ============================================================================
     // STEP 2: Fetch top performer data
@@ -266,7 +273,7 @@ export async function GET(req: NextRequest) {
       console.warn(`[Top Performers API] Completed in ${duration}ms`);
     }
     return NextResponse.json({
-      locationId: locationId || '',
+      locationId,
       timePeriod,
       topPerformer,
     });
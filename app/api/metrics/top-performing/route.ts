@@ -16,11 +16,14 @@ import type { TopPerformingItem } from '@/app/api/lib/helpers/currency/topPerfor
 import { convertTopPerformingCurrency } from '@/app/api/lib/helpers/currency/topPerforming';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import type { TimePeriod } from '@/app/api/lib/types';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { topPerformingRoute } from '@/app/api/lib/routeSchemas/metrics';
 import { resolveLicenceeId } from '@/lib/utils/licencee';
 import type { CurrencyCode } from '@/shared/types/currency';
 import { NextRequest, NextResponse } from 'next/server';
-import type { TopPerformingTab } from '@/shared/types/reports';
 import {
   logRouteFetch,
   logRouteError,
@@ -54,14 +57,21 @@ export async function GET(req: NextRequest) {
     // ============================================================================
     // STEP 1: Parse and validate request parameters
     // ============================================================================
-    const searchParams = req.nextUrl.searchParams;
-    const activeTab =
-      (searchParams.get('activeTab') as TopPerformingTab) || 'locations';
-    const timePeriod: TimePeriod =
-      (searchParams.get('timePeriod') as TimePeriod) || '7d';
+    const query = parseQuery(topPerformingRoute, req);
+    if (!query.success) {
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/metrics/top-performing',
+        'Invalid query parameters',
+        user
+      );
+      return validationErrorResponse(query.error);
+    }
+    const { activeTab, timePeriod } = query.data;
 
     // Raw licencee from query (name, id, or "all")
-    const rawLicencee = searchParams.get('licencee') || null;
+    const rawLicencee = query.data.licencee ?? null;
 
     // Normalize licencee for DB filtering:
     // - Resolve known names (TTG, Cabana, etc.) → ID
@@ -71,33 +81,16 @@ export async function GET(req: NextRequest) {
       licenceeForFilter = resolveLicenceeId(rawLicencee) || rawLicencee;
     }
 
-    const currencyParam = searchParams.get('currency') as CurrencyCode | null;
-    const displayCurrency: CurrencyCode = currencyParam || 'USD';
+    const displayCurrency: CurrencyCode = query.data.currency;
 
     // Parse custom date range for Custom time period
-    const startDateParam = searchParams.get('startDate');
-    const endDateParam = searchParams.get('endDate');
+    const { startDate: startDateParam, endDate: endDateParam } = query.data;
     let customStartDate: Date | undefined;
     let customEndDate: Date | undefined;
 
     if (timePeriod === 'Custom' && startDateParam && endDateParam) {
       customStartDate = new Date(startDateParam);
       customEndDate = new Date(endDateParam);
-
-      // Validate dates
-      if (isNaN(customStartDate.getTime()) || isNaN(customEndDate.getTime())) {
-        logRouteError(
-          functionName,
-          'GET',
-          '/api/metrics/top-performing',
-          'Invalid date parameters',
-          user
-        );
-        return NextResponse.json(
-          { error: 'Invalid date parameters' },
-          { status: 400 }
-        );
-      }
     }
 
     // ============================================================================
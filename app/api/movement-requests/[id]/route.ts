@@ -12,6 +12,16 @@
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { MovementRequest } from '@/app/api/lib/models/movementrequests';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  deleteMovementRequestRoute,
+  updateMovementRequestRoute,
+} from '@/app/api/lib/routeSchemas/movementRequests';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import {
   logRouteDelete,
@@ -55,6 +65,10 @@ export async function DELETE(request: NextRequest): Promise<Response> {
           { status: 400 }
         );
       }
+      const query = parseQuery(deleteMovementRequestRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
 
       // ============================================================================
       // STEP 2: Find movement request by ID
@@ -80,10 +94,7 @@ export async function DELETE(request: NextRequest): Promise<Response> {
       // ============================================================================
       // STEP 3: Permission check
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const deleteType = (searchParams.get('deleteType') || 'soft') as
-        | 'soft'
-        | 'hard';
+      const { deleteType } = query.data;
 
       const isAdminOrDev = userRoles.some(role =>
         ['admin', 'developer'].includes(String(role).toLowerCase())
@@ -276,7 +287,7 @@ export async function DELETE(request: NextRequest): Promise<Response> {
  * @param locationFrom {string} Optional. ID of the source location.
  * @param locationTo  {string}  Optional. ID of the destination location.
  * @param reason      {string}  Optional. Reason for the movement request.
- * @param status      {string}  Optional. Updated status of the request (e.g. 'pending', 'approved', 'completed').
+ * @param status      {string}  Optional. Updated status of the request ('pending' or 'completed').
  * @param requestTo   {string}  Optional. ID or email of the user the request is directed to.
  */
 export async function PATCH(request: NextRequest): Promise<Response> {
@@ -301,18 +312,14 @@ export async function PATCH(request: NextRequest): Promise<Response> {
         );
       }
 
-      let body;
-      try {
-        body = await request.json();
-      } catch {
-        return NextResponse.json(
-          {
-            success: false,
-            message: 'Invalid JSON in request body',
-          },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        updateMovementRequestRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const body = validation.data;
 
       // ============================================================================
       // STEP 2: Find original movement request
@@ -373,7 +380,7 @@ export async function PATCH(request: NextRequest): Promise<Response> {
       const updated = await MovementRequest.findOneAndUpdate(
         {
           _id: id,
-          $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
+          ...NOT_DELETED_FILTER,
         },
         body,
         { new: true }
@@ -14,6 +14,16 @@ import { getUserLocationFilter } from '@/app/api/lib/helpers/licenceeFilter';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { MovementRequest } from '@/app/api/lib/models/movementrequests';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createMovementRequestRoute,
+  listMovementRequestsRoute,
+} from '@/app/api/lib/routeSchemas/movementRequests';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import {
   logRouteFetch,
@@ -56,8 +66,11 @@ export async function GET(req: NextRequest) {
         // ============================================================================
         // STEP 2: Get user location permissions
         // ============================================================================
-        const { searchParams } = new URL(req.url);
-        const licencee = searchParams.get('licencee');
+        const query = parseQuery(listMovementRequestsRoute, req);
+        if (!query.success) {
+          return validationErrorResponse(query.error);
+        }
+        const { licencee } = query.data;
         const allowedLocationIds = await getUserLocationFilter(
           isAdminOrDev ? 'all' : assignedLicencees,
           licencee && licencee !== 'all' ? licencee : undefined,
@@ -71,9 +84,7 @@ export async function GET(req: NextRequest) {
         const stagingRequests = await MovementRequest.aggregate([
           // Match non-deleted requests
           {
-            $match: {
-              $or: [{ deletedAt: null }, { deletedAt: { $exists: false } }],
-            },
+            $match: { ...NOT_DELETED_FILTER },
           },
           // Lookup recipient user
           {
@@ -347,8 +358,8 @@ export async function POST(req: NextRequest) {
         );
         return NextResponse.json(
           {
-            error: 'Forbidden',
-            details: 'You do not have permission to create movement requests',
+            success: false,
+            error: 'You do not have permission to create movement requests',
           },
           { status: 403 }
         );
@@ -357,18 +368,14 @@ export async function POST(req: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate request body
       // ============================================================================
-      let data: Record<string, unknown>;
-      try {
-        data = await req.json();
-      } catch {
-        return NextResponse.json(
-          {
-            error: 'Invalid JSON in request body',
-            details: 'Request body must be valid JSON',
-          },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        createMovementRequestRoute,
+        await req.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const data = validation.data;
 
       // ============================================================================
       // STEP 3: Create movement request
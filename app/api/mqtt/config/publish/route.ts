@@ -9,8 +9,12 @@
  * @module app/api/mqtt/config/publish/route
  */
 
-import { validateMQTTConfigPublish } from '@/app/api/lib/helpers/mqtt';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { publishMqttConfigRoute } from '@/app/api/lib/routeSchemas/mqtt';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import {
   logRouteCreate,
@@ -27,10 +31,9 @@ import { NextRequest, NextResponse } from 'next/server';
  * @body {Object} config - REQUIRED. The configuration object to publish.
  *
  * Flow:
- * 1. Parse request body
- * 2. Validate request body
- * 3. Publish config update via MQTT
- * 4. Return success response
+ * 1. Parse and validate request body
+ * 2. Publish config update via MQTT
+ * 3. Return success response
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -40,39 +43,31 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const body = await request.json();
-      const { relayId, config } = body as {
-        relayId?: string;
-        config?: Record<string, unknown>;
-      };
-
-      // ============================================================================
-      // STEP 2: Validate request body
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      const validationError = validateMQTTConfigPublish(body);
-      if (validationError) {
+      const validation = parseBody(
+        publishMqttConfigRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/mqtt/config/publish',
-          validationError,
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: validationError },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { relayId, config } = validation.data;
 
       // ============================================================================
-      // STEP 3: Publish config update via MQTT
+      // STEP 2: Publish config update via MQTT
       // ============================================================================
-      await mqttService.publishConfig(relayId!, config!);
+      await mqttService.publishConfig(relayId, config);
 
       // ============================================================================
-      // STEP 4: Return success response
+      // STEP 3: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteCreate(
@@ -88,7 +83,7 @@ export async function POST(request: NextRequest) {
       }
       return NextResponse.json({
         success: true,
-        message: `Config update published for ${config!.comp} to relayId: ${relayId}`,
+        message: `Config update published for ${config.comp} to relayId: ${relayId}`,
         relayId,
         config,
         timestamp: new Date().toISOString(),
@@ -3,15 +3,19 @@
  *
  * This route handles requesting current configuration from SMIB devices via MQTT.
  * It supports:
- * - Validating config request body
+ * - Validating the config request body against its route schema
  * - Requesting config from SMIB via MQTT service
  * - Note: The callback will be handled by the SSE endpoint
  *
  * @module app/api/mqtt/config/request/route
  */
 
-import { validateMQTTConfigRequest } from '@/app/api/lib/helpers/mqtt';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { requestMqttConfigRoute } from '@/app/api/lib/routeSchemas/mqtt';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import {
   logRouteFetch,
@@ -28,10 +32,9 @@ import { NextRequest, NextResponse } from 'next/server';
  * @body {string} component - REQUIRED. The component code to request config for.
  *
  * Flow:
- * 1. Parse request body
- * 2. Validate request body
- * 3. Request config from SMIB via MQTT
- * 4. Return success response
+ * 1. Parse and validate request body
+ * 2. Request config from SMIB via MQTT
+ * 3. Return success response
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -41,39 +44,31 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const body = await request.json();
-      const { relayId, component } = body as {
-        relayId?: string;
-        component?: string;
-      };
-
-      // ============================================================================
-      // STEP 2: Validate request body
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      const validationError = validateMQTTConfigRequest(body);
-      if (validationError) {
+      const validation = parseBody(
+        requestMqttConfigRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/mqtt/config/request',
-          validationError,
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: validationError },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { relayId, component } = validation.data;
 
       // ============================================================================
-      // STEP 3: Request config from SMIB via MQTT
+      // STEP 2: Request config from SMIB via MQTT
       // ============================================================================
-      await mqttService.requestConfig(relayId!, component!);
+      await mqttService.requestConfig(relayId, component);
 
       // ============================================================================
-      // STEP 4: Return success response
+      // STEP 3: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       if (duration > 1000) {
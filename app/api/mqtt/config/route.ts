@@ -12,6 +12,11 @@
 import { extractMQTTConfig } from '@/app/api/lib/helpers/mqtt';
 import { Machine } from '@/app/api/lib/models/machines';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { getMqttConfigRoute } from '@/app/api/lib/routeSchemas/mqtt';
 import {
   logRouteFetch,
   logRouteError,
@@ -40,10 +45,8 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const cabinetId = searchParams.get('cabinetId');
-
-      if (!cabinetId) {
+      const query = parseQuery(getMqttConfigRoute, request);
+      if (!query.success) {
         logRouteError(
           functionName,
           'GET',
@@ -51,11 +54,9 @@ export async function GET(request: NextRequest) {
           'Cabinet ID is required',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Cabinet ID is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(query.error);
       }
+      const { cabinetId } = query.data;
 
       // ============================================================================
       // STEP 2: Find cabinet by ID
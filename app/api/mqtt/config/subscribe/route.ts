@@ -9,6 +9,11 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { subscribeMqttConfigRoute } from '@/app/api/lib/routeSchemas/mqtt';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import {
   logRouteFetch,
@@ -40,10 +45,8 @@ export async function GET(request: NextRequest) {
     // ============================================================================
     // STEP 1: Parse and validate relayId parameter
     // ============================================================================
-    const { searchParams } = new URL(request.url);
-    const relayId = searchParams.get('relayId');
-
-    if (!relayId) {
+    const query = parseQuery(subscribeMqttConfigRoute, request);
+    if (!query.success) {
       logRouteError(
         functionName,
         'GET',
@@ -51,14 +54,9 @@ export async function GET(request: NextRequest) {
         'relayId query parameter is required',
         user
       );
-      return new Response(
-        JSON.stringify({ error: 'relayId query parameter is required' }),
-        {
-          status: 400,
-          headers: { 'Content-Type': 'application/json' },
-        }
-      ) as unknown as import('next/server').NextResponse;
+      return validationErrorResponse(query.error);
     }
+    const { relayId } = query.data;
 
     // ============================================================================
     // STEP 2: Set up SSE headers
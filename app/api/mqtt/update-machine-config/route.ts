@@ -23,6 +23,11 @@ import {
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { getClientIP } from '@/lib/utils/ipAddress';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { updateMachineConfigRoute } from '@/app/api/lib/routeSchemas/mqtt';
 import {
   logRouteError,
   logRouteUpdate,
@@ -56,19 +61,14 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
-      const { relayId, smibConfig, smibVersion } = body as {
-        relayId?: string;
-        smibConfig?: Record<string, unknown>;
-        smibVersion?: Record<string, unknown>;
-      };
-
-      if (!relayId) {
-        return NextResponse.json(
-          { success: false, error: 'relayId is required' },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        updateMachineConfigRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { relayId, smibConfig, smibVersion } = validation.data;
 
       // ============================================================================
       // STEP 2: Find machine by relayId
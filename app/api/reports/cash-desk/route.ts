@@ -0,0 +1,162 @@
+/**
+ * Cash Desk Reconciliation API Route
+ *
+ * Compares each location's cash desk payouts and float for a gaming day
+ * with its machines' cancelled-credit and jackpot meters (see
+ * app/api/lib/helpers/cashDesk.ts).
+ * It supports:
+ * - Role-based licencee and location access
+ * - Variance threshold and flagged-only filtering
+ * - CSV export (`format=csv`)
+ *
+ * @module app/api/reports/cash-desk/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  exportCashDeskReconciliationToCSV,
+  reconcileCashDesk,
+} from '@/app/api/lib/helpers/cashDesk';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getDefaultRollupDay } from '@/app/api/lib/helpers/metersDaily';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { cashDeskReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/cash-desk
+ *
+ * Query params:
+ * @param day         {string} Optional. Gaming day, YYYY-MM-DD. Defaults to yesterday's gaming day.
+ * @param licencee    {string} Optional. Scopes results to this licencee.
+ * @param locationId  {string} Optional. Limits the report to one location.
+ * @param threshold   {number} Optional. Flag variances above this amount. Defaults to 1.
+ * @param flaggedOnly {boolean} Optional. Only flagged locations.
+ * @param format      {string} Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Reconcile via `reconcileCashDesk`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/cash-desk';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(cashDeskReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee = '', locationId, threshold, format } = query.data;
+      const day = query.data.day || getDefaultRollupDay();
+      const flaggedOnly = query.data.flaggedOnly === 'true';
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Reconcile
+      // ============================================================================
+      const report = await reconcileCashDesk({
+        day,
+        allowedLocationIds,
+        licenceeId: licencee || undefined,
+        threshold,
+        flaggedOnly,
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/cash-desk',
+        report.rows.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportCashDeskReconciliationToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': `attachment; filename="cash-desk-${day}.csv"`,
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/cash-desk',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -12,19 +12,17 @@ import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import CashierShiftModel from '@/app/api/lib/models/cashierShift';
 import { NextRequest, NextResponse } from 'next/server';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { cashierPerformanceReportRoute } from '@/app/api/lib/routeSchemas/reports';
 import {
   logRouteFetch,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
 
-type CashierPerformanceQuery = {
-  locationId?: string;
-  startDate?: string;
-  endDate?: string;
-  cashierId?: string;
-};
-
 /**
  * Main GET handler for fetching cashier performance report
  *
@@ -60,13 +58,9 @@ export async function GET(request: NextRequest) {
     // ============================================================================
     // STEP 2: Parse query parameters
     // ============================================================================
-    const { searchParams } = new URL(request.url);
-    const query: CashierPerformanceQuery = {
-      locationId: searchParams.get('locationId') || undefined,
-      startDate: searchParams.get('startDate') || undefined,
-      endDate: searchParams.get('endDate') || undefined,
-      cashierId: searchParams.get('cashierId') || undefined,
-    };
+    const parsed = parseQuery(cashierPerformanceReportRoute, request);
+    if (!parsed.success) return validationErrorResponse(parsed.error);
+    const query = parsed.data;
 
     // ============================================================================
     // STEP 3: Database connection
@@ -0,0 +1,170 @@
+/**
+ * Collection Schedule Compliance API Route
+ *
+ * Compares scheduled collections (`schedulers`) with submitted collection
+ * reports per location, flags late and missed collections and summarizes
+ * on-time compliance per licencee per month.
+ * It supports:
+ * - Date range on the scheduled start (defaults to the last three months)
+ * - Late tolerance (`lateHours`)
+ * - Role-based licencee and location access
+ *
+ * @module app/api/reports/collection-compliance/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getCollectionComplianceReport } from '@/app/api/lib/helpers/reports/collectionCompliance';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { collectionComplianceReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/collection-compliance
+ *
+ * Query params:
+ * @param licencee   {string} Optional. Scopes results to this licencee.
+ * @param locationId {string} Optional. Limits the report to one location.
+ * @param startDate  {string} Optional. Schedules starting on/after this. Defaults to the 1st of the month two months ago.
+ * @param endDate    {string} Optional. Schedules starting on/before this. Defaults to now.
+ * @param lateHours  {number} Optional. Hours after the window closes a collection still counts as late rather than missed. Defaults to 24.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getCollectionComplianceReport`
+ * 4. Return report
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/collection-compliance';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(collectionComplianceReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee = '', locationId, lateHours } = query.data;
+
+      const now = new Date();
+      const startDate = query.data.startDate
+        ? new Date(query.data.startDate)
+        : new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth() - 2, 1));
+      const endDate = query.data.endDate ? new Date(query.data.endDate) : now;
+
+      if (
+        Number.isNaN(startDate.getTime()) ||
+        Number.isNaN(endDate.getTime()) ||
+        startDate > endDate
+      ) {
+        return NextResponse.json(
+          { success: false, error: 'Invalid startDate or endDate' },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const data =
+        allowedLocationIds !== 'all' && allowedLocationIds.length === 0
+          ? {
+              startDate,
+              endDate,
+              lateHours,
+              months: [],
+              exceptions: [],
+            }
+          : await getCollectionComplianceReport({
+              allowedLocationIds,
+              startDate,
+              endDate,
+              lateHours,
+            });
+
+      // ============================================================================
+      // STEP 4: Return report
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/collection-compliance',
+        data.months.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, data });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/collection-compliance',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
@@ -0,0 +1,73 @@
+/**
+ * Export Profiles API Route
+ *
+ * Lists the export profiles (columns, display names, number formats) offered
+ * to the caller's licencees, so report pages can apply one to their CSV,
+ * Excel and PDF exports via `ExportUtils.exportData(data, format, profile)`.
+ * Profiles are configured in `export-profiles.json`.
+ *
+ * @module app/api/reports/export-profiles/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { getUserAccessibleLicenceesFromToken } from '@/app/api/lib/helpers/licenceeFilter';
+import { getExportProfilesForLicencees } from '@/app/api/lib/utils/exportProfiles';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/export-profiles
+ *
+ * Flow:
+ * 1. Resolve the user's accessible licencees
+ * 2. Return the profiles offered to them
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/export-profiles';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    try {
+      // ============================================================================
+      // STEP 1: Resolve the user's accessible licencees
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+
+      // ============================================================================
+      // STEP 2: Return the profiles offered to them
+      // ============================================================================
+      const profiles = getExportProfilesForLicencees(userAccessibleLicencees);
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/export-profiles',
+        profiles.length,
+        user,
+        duration
+      );
+      return NextResponse.json({ success: true, data: profiles });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/export-profiles',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
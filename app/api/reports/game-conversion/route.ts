@@ -0,0 +1,141 @@
+/**
+ * Game Conversion ROI API Route
+ *
+ * Before/after comparison of one machine around a game conversion: average
+ * daily gross, coin in and occupancy over N days on each side, with a
+ * significance hint per figure (see reports/gameConversion).
+ *
+ * @module app/api/reports/game-conversion/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { checkUserLocationAccess } from '@/app/api/lib/helpers/licenceeFilter';
+import { getGameConversionReport } from '@/app/api/lib/helpers/reports/gameConversion';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import { Machine } from '@/app/api/lib/models/machines';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { gameConversionReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/game-conversion
+ *
+ * Query params:
+ * @param machineId      {string} Required. Machine to report on.
+ * @param conversionDate {string} Optional. ISO time of the conversion. Defaults to the machine's latest recorded game change.
+ * @param days           {number} Optional. Days on each side. Defaults to 30, at most 180.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Check access to the machine's location
+ * 3. Build the report via `getGameConversionReport`
+ * 4. Return report
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/game-conversion';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(gameConversionReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { machineId, days } = query.data;
+      const conversionDate = query.data.conversionDate
+        ? new Date(query.data.conversionDate)
+        : undefined;
+
+      if (conversionDate && Number.isNaN(conversionDate.getTime())) {
+        return NextResponse.json(
+          { success: false, error: 'conversionDate must be a valid date' },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Check access to the machine's location
+      // ============================================================================
+      const machine = await Machine.findOne(
+        { _id: machineId },
+        { gamingLocation: 1 }
+      ).lean<{ gamingLocation?: string }>();
+      if (!machine) {
+        return NextResponse.json(
+          { success: false, error: 'Machine not found' },
+          { status: 404 }
+        );
+      }
+      if (
+        machine.gamingLocation &&
+        !(await checkUserLocationAccess(machine.gamingLocation))
+      ) {
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const data = await getGameConversionReport({
+        machineId,
+        conversionDate,
+        days,
+      });
+
+      // ============================================================================
+      // STEP 4: Return report
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/game-conversion',
+        data.daily.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, data });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/game-conversion',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
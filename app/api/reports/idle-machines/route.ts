@@ -0,0 +1,164 @@
+/**
+ * Idle Machines Report API Route
+ *
+ * Active machines with no meter movement for 24h, 72h or 7d, grouped by
+ * location with their last meter reading, last event and last activity, to
+ * drive field service dispatch.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Minimum idle bucket (`minBucket`) and lookback (`lookbackDays`)
+ * - CSV export of the idle machines (`format=csv`)
+ *
+ * @module app/api/reports/idle-machines/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportIdleMachinesToCSV,
+  getIdleMachinesReport,
+} from '@/app/api/lib/helpers/reports/idleMachines';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { idleMachinesReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/idle-machines
+ *
+ * Query params:
+ * @param licencee     {string} Optional. Scopes results to this licencee.
+ * @param locationId   {string} Optional. Limits the report to one location.
+ * @param minBucket    {'24h'|'72h'|'7d'} Optional. Only machines idle at least this long. Defaults to '24h'.
+ * @param lookbackDays {number} Optional. Days searched for movement and events (7-90). Defaults to 30.
+ * @param format       {string} Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getIdleMachinesReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/idle-machines';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(idleMachinesReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        licencee = '',
+        locationId,
+        minBucket,
+        lookbackDays,
+        format,
+      } = query.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getIdleMachinesReport({
+        allowedLocationIds,
+        lookbackDays,
+        minBucket,
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/idle-machines',
+        report.locations.reduce(
+          (sum, location) => sum + location.machines.length,
+          0
+        ),
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportIdleMachinesToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': 'attachment; filename="idle-machines.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/idle-machines',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
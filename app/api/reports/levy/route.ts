@@ -0,0 +1,134 @@
+/**
+ * Levy Report API Route
+ *
+ * Gaming levy owed per licencee for a calendar month, computed from the
+ * month's aggregated gross and the configured levy schedule (rates per
+ * licencee or jurisdiction, with rounding rules).
+ * It supports:
+ * - Role-based licencee and location access
+ * - Filing export (`format=csv`) with a total line
+ *
+ * @module app/api/reports/levy/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportLevyToCSV,
+  getLevyReport,
+} from '@/app/api/lib/helpers/reports/levy';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { levyReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/levy
+ *
+ * Query params:
+ * @param month    {string} Required. Calendar month, YYYY-MM.
+ * @param licencee {string} Optional. Scopes results to this licencee.
+ * @param format   {string} Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getLevyReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/levy';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(levyReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { month, licencee = '', format } = query.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getLevyReport({ allowedLocationIds, month });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/levy',
+        report.rows.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportLevyToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': `attachment; filename="levy-${month}.csv"`,
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/levy',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
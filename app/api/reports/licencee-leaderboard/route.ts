@@ -0,0 +1,185 @@
+/**
+ * Licencee Leaderboard API Route
+ *
+ * Ranks licencees by gross for a period with previous-period deltas and trend
+ * direction, machine utilization and offline machine counts.
+ * It supports:
+ * - Time period / custom date range per location gaming day
+ * - Role-based licencee and location access
+ * - Reviewer money scales
+ * - CSV export (`format=csv`)
+ *
+ * @module app/api/reports/licencee-leaderboard/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportLeaderboardToCSV,
+  getLicenceeLeaderboard,
+} from '@/app/api/lib/helpers/reports/licenceeLeaderboard';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { licenceeLeaderboardRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  getMoneyInScale,
+  getMoneyOutAndJackpotScale,
+} from '@/app/api/lib/utils/reviewerScale';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/licencee-leaderboard
+ *
+ * Query params:
+ * @param timePeriod {TimePeriod} Optional. Defaults to '7d'. The previous period has the same length.
+ * @param startDate  {string}     Optional. Custom range start (with timePeriod=Custom).
+ * @param endDate    {string}     Optional. Custom range end (with timePeriod=Custom).
+ * @param format     {string}     Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the leaderboard via `getLicenceeLeaderboard`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/licencee-leaderboard';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(licenceeLeaderboardRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { timePeriod, startDate, endDate, format } = query.data;
+      const customStartDate = startDate ? new Date(startDate) : undefined;
+      const customEndDate = endDate ? new Date(endDate) : undefined;
+
+      if (
+        timePeriod === 'Custom' &&
+        (!customStartDate ||
+          !customEndDate ||
+          Number.isNaN(customStartDate.getTime()) ||
+          Number.isNaN(customEndDate.getTime()))
+      ) {
+        return NextResponse.json(
+          {
+            success: false,
+            error: 'Valid startDate and endDate are required for Custom',
+          },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      // ============================================================================
+      // STEP 3: Build leaderboard
+      // ============================================================================
+      const referenceDate = customEndDate || new Date();
+      const rows =
+        allowedLocationIds !== 'all' && allowedLocationIds.length === 0
+          ? []
+          : await getLicenceeLeaderboard({
+              allowedLocationIds,
+              timePeriod,
+              customStartDate,
+              customEndDate,
+              scales: {
+                moneyInScale: getMoneyInScale(
+                  userPayload as {
+                    moneyInMultiplier?: number | null;
+                    roles?: string[];
+                    reviewerMultiplierStartTime?: Date | string | null;
+                  },
+                  referenceDate
+                ),
+                moneyOutScale: getMoneyOutAndJackpotScale(
+                  userPayload as {
+                    moneyOutAndJackpotMultiplier?: number | null;
+                    roles?: string[];
+                    reviewerMultiplierStartTime?: Date | string | null;
+                  },
+                  referenceDate
+                ),
+              },
+            });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/licencee-leaderboard',
+        rows.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportLeaderboardToCSV(rows), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': `attachment; filename="licencee-leaderboard-${timePeriod}.csv"`,
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, timePeriod, data: rows });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/licencee-leaderboard',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
@@ -0,0 +1,145 @@
+/**
+ * Location Onboarding Report API Route
+ *
+ * Door-to-floor coverage check for a new location: each machine's first
+ * meter reading, SMIB ID and SAS capability, so the install team can confirm
+ * every machine is reporting.
+ * It supports:
+ * - Role-based location access
+ * - CSV export of the machine list (`format=csv`)
+ *
+ * @module app/api/reports/location-onboarding/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportLocationOnboardingToCSV,
+  getLocationOnboardingReport,
+} from '@/app/api/lib/helpers/reports/locationOnboarding';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { locationOnboardingReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/location-onboarding
+ *
+ * Query params:
+ * @param locationId {string} Required. Location to check.
+ * @param format     {string} Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Check the user can access the location
+ * 3. Build the report via `getLocationOnboardingReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/location-onboarding';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(locationOnboardingReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { locationId, format } = query.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Check the user can access the location
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      if (
+        accessibleLocationIds !== 'all' &&
+        !accessibleLocationIds.includes(locationId)
+      ) {
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getLocationOnboardingReport(locationId);
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/location-onboarding',
+        report.machineCount,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportLocationOnboardingToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition':
+              'attachment; filename="location-onboarding.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/location-onboarding',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
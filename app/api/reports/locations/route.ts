@@ -14,6 +14,8 @@ import {
   getUserAccessibleLicenceesFromToken,
   getUserLocationFilter,
 } from '@/app/api/lib/helpers/licenceeFilter';
+import { getLocationFinancialFormulas } from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { getMemberCountsPerLocation } from '@/app/api/lib/helpers/membershipAggregation';
 import {
   applyLocationsCurrencyConversion,
@@ -25,7 +27,6 @@ import {
   buildLocationMatchStage,
   applySmibClassification,
   computeLocationMetrics,
-  getLicenceeJackpotSettings,
   buildLocationResults,
   applyNonSmibOfflineOverride,
   filterAndSortLocations,
@@ -35,6 +36,15 @@ import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Machine } from '@/app/api/lib/models/machines';
 import type { GamingMachine } from '@shared/types';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { locationsReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  DELETED_FILTER,
+  NOT_DELETED_FILTER,
+} from '@/app/api/lib/utils/softDeleteFilters';
 import type { LocationDocument } from '@/lib/types/common';
 import { getGamingDayRangesForLocations } from '@/lib/utils/gamingDayRange';
 import {
@@ -81,6 +91,8 @@ export async function GET(req: NextRequest) {
         // ============================================================================
         // STEP 1: Parse and validate request parameters
         // ============================================================================
+        const query = parseQuery(locationsReportRoute, req);
+        if (!query.success) return validationErrorResponse(query.error);
         const { searchParams } = new URL(req.url);
         const params = parseReportLocationsParams(searchParams);
 
@@ -135,10 +147,7 @@ export async function GET(req: NextRequest) {
         if (wowFilterActive) {
           const wowLocs = await Machine.distinct('gamingLocation', {
             'meta.dataSync.source': 'wow',
-            $or: [
-              { deletedAt: null },
-              { deletedAt: { $lt: new Date('2025-01-01') } },
-            ],
+            ...NOT_DELETED_FILTER,
           });
           wowLocationIds = wowLocs.map(id => String(id));
           console.log(
@@ -190,14 +199,12 @@ export async function GET(req: NextRequest) {
         // ============================================================================
         const machineMatch: Record<string, unknown> = {
           gamingLocation: { $in: allLocationIds },
+          ...NOT_RETIRED_FILTER,
         };
         if (!params.showArchived) {
-          machineMatch.$or = [
-            { deletedAt: null },
-            { deletedAt: { $lt: new Date('2025-01-01') } },
-          ];
+          machineMatch.deletedAt = NOT_DELETED_FILTER.deletedAt;
         } else {
-          machineMatch.deletedAt = { $gte: new Date('2025-01-01') };
+          machineMatch.deletedAt = DELETED_FILTER.deletedAt;
         }
 
         const allMachinesData =
@@ -247,7 +254,7 @@ export async function GET(req: NextRequest) {
         );
 
         const memberCountMap = await getMemberCountsPerLocation(allLocationIds);
-        const licenceeIncludeJackpotMap = await getLicenceeJackpotSettings(locations);
+        const locationFormulas = await getLocationFinancialFormulas(allLocationIds);
 
         const moneyInScale = getMoneyInScale(
           userPayload as {
@@ -271,7 +278,7 @@ export async function GET(req: NextRequest) {
           locationToMachines,
           metricsMap,
           memberCountMap,
-          licenceeIncludeJackpotMap,
+          locationFormulas,
           moneyInScale,
           moneyOutScale
         );
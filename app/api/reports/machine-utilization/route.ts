@@ -0,0 +1,171 @@
+/**
+ * Machine Utilization API Route
+ *
+ * Per-machine occupancy report built from machine sessions: hours in session
+ * per day, average session length and sessions per day, with underutilized
+ * machines flagged at each location.
+ *
+ * @module app/api/reports/machine-utilization/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getMachineUtilizationReport } from '@/app/api/lib/helpers/reports/machineUtilization';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { machineUtilizationReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/machine-utilization
+ *
+ * Query params:
+ * @param licencee           {string}     Optional. Scopes results to this licencee.
+ * @param locationId         {string}     Optional. Limits the report to one location.
+ * @param timePeriod         {TimePeriod} Optional. Defaults to '7d'.
+ * @param startDate          {string}     Optional. Custom range start (with timePeriod=Custom).
+ * @param endDate            {string}     Optional. Custom range end (with timePeriod=Custom).
+ * @param underutilizedRatio {number}     Optional. Flag machines below this share of the location average occupancy. Defaults to 0.5.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getMachineUtilizationReport`
+ * 4. Return report
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/machine-utilization';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(machineUtilizationReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        licencee = '',
+        locationId,
+        timePeriod,
+        startDate,
+        endDate,
+        underutilizedRatio,
+      } = query.data;
+      const customStartDate = startDate ? new Date(startDate) : undefined;
+      const customEndDate = endDate ? new Date(endDate) : undefined;
+
+      if (
+        timePeriod === 'Custom' &&
+        (!customStartDate ||
+          !customEndDate ||
+          Number.isNaN(customStartDate.getTime()) ||
+          Number.isNaN(customEndDate.getTime()))
+      ) {
+        return NextResponse.json(
+          {
+            success: false,
+            error: 'Valid startDate and endDate are required for Custom',
+          },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const data =
+        allowedLocationIds !== 'all' && allowedLocationIds.length === 0
+          ? []
+          : await getMachineUtilizationReport({
+              allowedLocationIds,
+              timePeriod,
+              customStartDate,
+              customEndDate,
+              underutilizedRatio,
+            });
+
+      // ============================================================================
+      // STEP 4: Return report
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/machine-utilization',
+        data.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, timePeriod, data });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/machine-utilization',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
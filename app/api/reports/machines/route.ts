@@ -25,11 +25,20 @@ import {
   getOverviewMachines,
 } from '@/app/api/lib/helpers/reports/machines';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import { getLicenceeMachineStatus } from '@/app/api/lib/helpers/licencees';
+import { NOT_RETIRED_FILTER } from '@/app/api/lib/helpers/machineLifecycle';
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { machinesReportRoute } from '@/app/api/lib/routeSchemas/reports';
 import { TimePeriod } from '@/app/api/lib/types';
 import { getDatesForTimePeriod } from '@/app/api/lib/utils/dates';
+import { getOnlineCutoff } from '@/app/api/lib/utils/machineStatus';
 import { shouldApplyReviewerMultipliers } from '@/app/api/lib/utils/reviewerScale';
 import type { GamingLocationDocument } from '@shared/types';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { CurrencyCode } from '@/shared/types/currency';
 import {
   logRouteFetch,
@@ -38,6 +47,11 @@ import {
 } from '@/app/api/lib/utils/routeLogger';
 import { NextRequest, NextResponse } from 'next/server';
 
+/** Escapes regex metacharacters so user input is matched literally. */
+function escapeRegex(input: string): string {
+  return input.replace(/[.*+?^${}()|[\]\\]/g, '\\$&');
+}
+
 /**
  * Main GET handler for fetching machines report
  *
@@ -46,6 +60,8 @@ import { NextRequest, NextResponse } from 'next/server';
  * @param {string} licencee - Filter by licencee name
  * @param {string} onlineStatus - Filter by connectivity ('online', 'offline', 'all')
  * @param {string} search - Search query for machine fields
+ * @param {string} smibId - Partial SMIB id (relayId or smibBoard), case-insensitive; overview only
+ * @param {string} customName - Partial custom machine name, case-insensitive; overview only
  * @param {string} locationId - Comma-separated location IDs to filter
  * @param {string} currency - Target display currency code
  * @param {number} page - Page number for pagination
@@ -66,6 +82,8 @@ export async function GET(req: NextRequest) {
         // STEP 1: Parse and validate request parameters
         // Example: GET /api/reports/machines?type=all&locationId=6801f2a3b4c5d6e7f8901234&licencee=9a5db2cb29ffd2d962fd1d91&timePeriod=Yesterday
         // ============================================================================
+        const query = parseQuery(machinesReportRoute, req);
+        if (!query.success) return validationErrorResponse(query.error);
         const { searchParams } = new URL(req.url);
         const type = searchParams.get('type');
         const timePeriod =
@@ -73,6 +91,8 @@ export async function GET(req: NextRequest) {
         const licencee = searchParams.get('licencee') || undefined;
         const onlineStatus = searchParams.get('onlineStatus') || 'all';
         const searchTerm = searchParams.get('search');
+        const smibId = searchParams.get('smibId')?.trim();
+        const customName = searchParams.get('customName')?.trim();
         const locationId = searchParams.get('locationId');
         const displayCurrency =
           (searchParams.get('currency') as CurrencyCode) || 'USD';
@@ -133,10 +153,8 @@ export async function GET(req: NextRequest) {
         // STEP 2: Build match filters
         // ============================================================================
         const machineMatchStage: Record<string, unknown> = {
-          $or: [
-            { deletedAt: null },
-            { deletedAt: { $lt: new Date('2025-01-01') } },
-          ],
+          ...NOT_DELETED_FILTER,
+          ...NOT_RETIRED_FILTER,
         };
 
         if (allowedLocationIds !== 'all') {
@@ -144,7 +162,9 @@ export async function GET(req: NextRequest) {
         }
 
         if (onlineStatus !== 'all') {
-          const threeMinutesAgo = new Date(Date.now() - 3 * 60 * 1000);
+          const onlineCutoff = getOnlineCutoff(
+            await getLicenceeMachineStatus(licencee)
+          );
 
           // Fetch aceEnabled location IDs — machines at these locations are always online
           const aceEnabledLocs = await GamingLocations.find(
@@ -158,7 +178,7 @@ export async function GET(req: NextRequest) {
             const onlineFilter: Record<string, unknown> = {
               $or: [
                 { 'meta.dataSync.source': 'wow' },
-                { lastActivity: { $gte: threeMinutesAgo } },
+                { lastActivity: { $gte: onlineCutoff } },
               ],
             };
             if (aceEnabledLocIds.length > 0) {
@@ -178,7 +198,7 @@ export async function GET(req: NextRequest) {
             });
             (machineMatchStage.$and as Array<Record<string, unknown>>).push({
               $or: [
-                { lastActivity: { $lt: threeMinutesAgo } },
+                { lastActivity: { $lt: onlineCutoff } },
                 { lastActivity: { $exists: false } },
               ],
             });
@@ -205,6 +225,22 @@ export async function GET(req: NextRequest) {
           });
         }
 
+        if (smibId) {
+          const smibRegex = { $regex: escapeRegex(smibId), $options: 'i' };
+          if (!machineMatchStage.$and) machineMatchStage.$and = [];
+          (machineMatchStage.$and as Array<Record<string, unknown>>).push({
+            $or: [{ relayId: smibRegex }, { smibBoard: smibRegex }],
+          });
+        }
+
+        if (customName) {
+          const nameRegex = { $regex: escapeRegex(customName), $options: 'i' };
+          if (!machineMatchStage.$and) machineMatchStage.$and = [];
+          (machineMatchStage.$and as Array<Record<string, unknown>>).push({
+            $or: [{ 'custom.name': nameRegex }, { 'Custom.name': nameRegex }],
+          });
+        }
+
         if (locationId && locationId !== 'all') {
           const requestedIds = locationId.split(',').filter(id => id.trim());
           if (allowedLocationIds !== 'all') {
@@ -224,10 +260,7 @@ export async function GET(req: NextRequest) {
         }
 
         const locationMatchStage: Record<string, unknown> = {
-          $or: [
-            { deletedAt: null },
-            { deletedAt: { $lt: new Date('2025-01-01') } },
-          ],
+          ...NOT_DELETED_FILTER,
         };
 
         if (allowedLocationIds !== 'all') {
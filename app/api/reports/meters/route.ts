@@ -36,6 +36,11 @@ import {
 import { Licencee } from '@/app/api/lib/models/licencee';
 import type { LicenceeDocument } from '@shared/types';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { metersReportRoute } from '@/app/api/lib/routeSchemas/reports';
 import {
   logRouteFetch,
   logRouteError,
@@ -130,6 +135,8 @@ export async function GET(req: NextRequest) {
         // ============================================================================
         // Example: GET /api/reports/meters?locations=Mapau,Stabroek&timePeriod=Yesterday&page=1&limit=50&licencee=9a5db2cb29ffd2d962fd1d91&currency=TTD&includeHourlyData=true&granularity=hourly
         console.log('[Meters Report API] STEP 1: Parsing parameters...');
+        const query = parseQuery(metersReportRoute, req);
+        if (!query.success) return validationErrorResponse(query.error);
         const { searchParams } = new URL(req.url);
         const params = parseMetersReportParams(searchParams);
 
@@ -0,0 +1,170 @@
+/**
+ * Profit Split Settlement API Route
+ *
+ * Each location's gross for a period split between the licencee and the
+ * venue owner on the location's configured profit split, for settling with
+ * venue owners.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Settlement export (`format=csv`) with a total line
+ * - Accounts payable export (`format=payables`): amount due per payee
+ *
+ * @module app/api/reports/profit-split/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportPayablesToCSV,
+  exportProfitSplitToCSV,
+  getProfitSplitReport,
+} from '@/app/api/lib/helpers/reports/profitSplit';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { profitSplitReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+
+/**
+ * GET /api/reports/profit-split
+ *
+ * Query params:
+ * @param from       {string} Required. First gaming day, YYYY-MM-DD.
+ * @param to         {string} Required. Last gaming day, YYYY-MM-DD.
+ * @param licencee   {string} Optional. Scopes results to this licencee.
+ * @param locationId {string} Optional. Restricts to a single location.
+ * @param format     {string} Optional. 'json' (default), 'csv' or 'payables'.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getProfitSplitReport`
+ * 4. Return JSON, settlement CSV or payables CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/profit-split';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(profitSplitReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { from, to, licencee = '', locationId = '', format } = query.data;
+
+      if (from > to) {
+        return NextResponse.json(
+          { success: false, error: 'from must not be after to' },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      let allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+      if (locationId) {
+        if (
+          allowedLocationIds !== 'all' &&
+          !allowedLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Unauthorized' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getProfitSplitReport({
+        allowedLocationIds,
+        from,
+        to,
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/profit-split',
+        report.rows.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportProfitSplitToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': `attachment; filename="profit-split-${from}-${to}.csv"`,
+          },
+        });
+      }
+      if (format === 'payables') {
+        return new NextResponse(exportPayablesToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': `attachment; filename="venue-payables-${from}-${to}.csv"`,
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/profit-split',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
@@ -0,0 +1,177 @@
+/**
+ * Query Builder API Route
+ *
+ * Builds and runs ad hoc aggregations from a structured spec (base entity,
+ * filters, group-by fields, metrics). Results are scoped to the caller's
+ * accessible locations, and the generated pipeline is returned in shell
+ * syntax for reuse.
+ *
+ * @module app/api/reports/query-builder/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  buildQueryPipeline,
+  formatPipelineForShell,
+  QUERY_BUILDER_CATALOG,
+  runQuerySpec,
+  validateQuerySpec,
+} from '@/app/api/lib/helpers/reports/queryBuilder';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { runQueryBuilderRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/query-builder
+ *
+ * Returns the entities, group-by fields, metrics and status values the
+ * builder accepts.
+ */
+export async function GET(request: NextRequest) {
+  return withApiAuth(request, async () => {
+    const entities = Object.entries(QUERY_BUILDER_CATALOG).map(
+      ([key, catalog]) => ({
+        key,
+        label: catalog.label,
+        dateField: catalog.dateField,
+        requiresDateRange: catalog.requiresDateRange,
+        statusValues: catalog.statusValues,
+        groupBy: Object.entries(catalog.groupBy).map(([field, entry]) => ({
+          key: field,
+          label: entry.label,
+        })),
+        metrics: Object.entries(catalog.metrics).map(([field, entry]) => ({
+          key: field,
+          label: entry.label,
+        })),
+      })
+    );
+    return NextResponse.json({ success: true, entities });
+  });
+}
+
+/**
+ * POST /api/reports/query-builder
+ *
+ * @body {QueryBuilderSpec} spec   Required. `{ entity, filters?, groupBy?, metrics?, limit? }`.
+ * @body {boolean}          dryRun Optional. Return the pipeline without running it.
+ *
+ * Flow:
+ * 1. Parse and validate the spec
+ * 2. Resolve the user's accessible locations
+ * 3. Build (and run) the pipeline
+ * 4. Return pipeline, shell form and rows
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/reports/query-builder';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate the spec
+      // ============================================================================
+      const validation = parseBody(
+        runQueryBuilderRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+      const spec = body.spec;
+      const validationError = validateQuerySpec(spec);
+      if (validationError) {
+        return NextResponse.json(
+          { success: false, error: validationError },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      // ============================================================================
+      // STEP 3: Build (and run) the pipeline
+      // ============================================================================
+      if (body.dryRun) {
+        const pipeline = await buildQueryPipeline(spec, allowedLocationIds);
+        return NextResponse.json({
+          success: true,
+          pipeline,
+          shell: formatPipelineForShell(spec.entity, pipeline),
+          rows: [],
+        });
+      }
+
+      const result = await runQuerySpec(spec, allowedLocationIds);
+
+      // ============================================================================
+      // STEP 4: Return pipeline, shell form and rows
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'POST',
+        '/api/reports/query-builder',
+        result.rows.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, ...result });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/reports/query-builder',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
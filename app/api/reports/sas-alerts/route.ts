@@ -0,0 +1,184 @@
+/**
+ * SAS Alerts Report API Route
+ *
+ * Machine events decoded by SAS exception code: counts per code with names
+ * and severities, and the machines raising the most critical and warning
+ * exceptions.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Minimum severity (`minSeverity`) and date range (`startDate`/`endDate`)
+ * - CSV export of the machine ranking (`format=csv`)
+ *
+ * @module app/api/reports/sas-alerts/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  DEFAULT_SAS_ALERT_HOURS,
+  exportSasAlertsToCSV,
+  getSasAlertsReport,
+} from '@/app/api/lib/helpers/reports/sasAlerts';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { sasAlertsReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/sas-alerts
+ *
+ * Query params:
+ * @param licencee    {string} Optional. Scopes results to this licencee.
+ * @param locationId  {string} Optional. Limits the report to one location.
+ * @param machineId   {string} Optional. Limits the report to one machine.
+ * @param minSeverity {'info'|'warning'|'critical'} Optional. Defaults to 'warning'.
+ * @param startDate   {string} Optional. ISO start. Defaults to 24 hours ago.
+ * @param endDate     {string} Optional. ISO end. Defaults to now.
+ * @param format      {string} Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getSasAlertsReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/sas-alerts';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(sasAlertsReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        licencee = '',
+        locationId,
+        machineId,
+        minSeverity,
+        format,
+        startDate,
+        endDate,
+      } = query.data;
+      const to = endDate ? new Date(endDate) : new Date();
+      const from = startDate
+        ? new Date(startDate)
+        : new Date(to.getTime() - DEFAULT_SAS_ALERT_HOURS * 60 * 60 * 1000);
+
+      if (
+        Number.isNaN(from.getTime()) ||
+        Number.isNaN(to.getTime()) ||
+        from > to
+      ) {
+        return NextResponse.json(
+          { success: false, error: 'Invalid startDate/endDate' },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getSasAlertsReport({
+        allowedLocationIds,
+        from,
+        to,
+        minSeverity,
+        machineId,
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/sas-alerts',
+        report.machines.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportSasAlertsToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': 'attachment; filename="sas-alerts.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/sas-alerts',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -0,0 +1,189 @@
+/**
+ * Session Attribution Report API Route
+ *
+ * Meter movement during carded sessions attributed to members: handle,
+ * actual win and theoretical win per member for loyalty tiering.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Date range (`startDate`/`endDate`) on session start, one member
+ *   (`memberId`) and the RTP for machines without one (`defaultRtp`)
+ * - CSV export (`format=csv`)
+ *
+ * @module app/api/reports/session-attribution/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  DEFAULT_ATTRIBUTION_DAYS,
+  DEFAULT_THEORETICAL_RTP,
+  exportSessionAttributionToCSV,
+  getSessionAttributionReport,
+  normalizeRtp,
+} from '@/app/api/lib/helpers/reports/sessionAttribution';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { sessionAttributionReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/session-attribution
+ *
+ * Query params:
+ * @param licencee   {string} Optional. Scopes results to this licencee.
+ * @param locationId {string} Optional. Limits the report to one location's machines.
+ * @param memberId   {string} Optional. Limits the report to one member.
+ * @param startDate  {string} Optional. ISO start (session start). Defaults to 30 days ago.
+ * @param endDate    {string} Optional. ISO end. Defaults to now.
+ * @param defaultRtp {number} Optional. RTP for machines without one, as 0.9 or 90. Defaults to 0.9.
+ * @param format     {string} Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getSessionAttributionReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/session-attribution';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(sessionAttributionReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee = '', locationId, memberId, format } = query.data;
+      const { startDate, endDate } = query.data;
+      const to = endDate ? new Date(endDate) : new Date();
+      const from = startDate
+        ? new Date(startDate)
+        : new Date(to.getTime() - DEFAULT_ATTRIBUTION_DAYS * 86400000);
+      const defaultRtp = query.data.defaultRtp
+        ? normalizeRtp(query.data.defaultRtp)
+        : DEFAULT_THEORETICAL_RTP;
+
+      if (
+        Number.isNaN(from.getTime()) ||
+        Number.isNaN(to.getTime()) ||
+        from > to
+      ) {
+        return NextResponse.json(
+          { success: false, error: 'Invalid startDate/endDate' },
+          { status: 400 }
+        );
+      }
+      if (defaultRtp === null) {
+        return NextResponse.json(
+          { success: false, error: 'defaultRtp must be between 0 and 1' },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getSessionAttributionReport({
+        allowedLocationIds,
+        from,
+        to,
+        defaultRtp,
+        memberId,
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/session-attribution',
+        report.members.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportSessionAttributionToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition':
+              'attachment; filename="session-attribution.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/session-attribution',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
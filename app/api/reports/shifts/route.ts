@@ -0,0 +1,170 @@
+/**
+ * Shift Report API Route
+ *
+ * Meter movements per shift per day for a location, used by finance to
+ * reconcile by shift. Accepts `shift:` ranges (e.g. `shift:today`) as well as
+ * the regular time periods.
+ *
+ * @module app/api/reports/shifts/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import { getShiftReport } from '@/app/api/lib/helpers/reports/shiftReport';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { shiftReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  getMoneyInScale,
+  getMoneyOutAndJackpotScale,
+} from '@/app/api/lib/utils/reviewerScale';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/shifts
+ *
+ * Query params:
+ * @param locationId {string} Required. Location to report on.
+ * @param range      {string} Optional. `shift:today|yesterday|current|<N>d|YYYY-MM-DD[..YYYY-MM-DD]` or a time period. Defaults to 'shift:today'.
+ * @param startDate  {string} Optional. Custom range start (with range=Custom).
+ * @param endDate    {string} Optional. Custom range end (with range=Custom).
+ * @param shift      {string} Optional. Only include this shift name.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Check the user can access the location
+ * 3. Build the report via `getShiftReport`
+ * 4. Return report
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/shifts';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(shiftReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { locationId, range, startDate, endDate } = query.data;
+      const shiftName = query.data.shift;
+      const customStartDate = startDate ? new Date(startDate) : undefined;
+      const customEndDate = endDate ? new Date(endDate) : undefined;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Check the user can access the location
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+      if (
+        allowedLocationIds !== 'all' &&
+        !allowedLocationIds.includes(locationId)
+      ) {
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const referenceDate = customEndDate || new Date();
+      const report = await getShiftReport({
+        locationId,
+        range,
+        shiftName,
+        customStartDate,
+        customEndDate,
+        scales: {
+          moneyInScale: getMoneyInScale(
+            userPayload as {
+              moneyInMultiplier?: number | null;
+              roles?: string[];
+              reviewerMultiplierStartTime?: Date | string | null;
+            },
+            referenceDate
+          ),
+          moneyOutScale: getMoneyOutAndJackpotScale(
+            userPayload as {
+              moneyOutAndJackpotMultiplier?: number | null;
+              roles?: string[];
+              reviewerMultiplierStartTime?: Date | string | null;
+            },
+            referenceDate
+          ),
+        },
+      });
+
+      if (!report) {
+        return NextResponse.json(
+          { success: false, error: 'Location not found' },
+          { status: 404 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 4: Return report
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/shifts',
+        report.rows.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      return NextResponse.json({ success: true, range, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/shifts',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) === 400 ? 400 : 500 }
+      );
+    }
+  });
+}
@@ -0,0 +1,157 @@
+/**
+ * SMIB Firmware Report API Route
+ *
+ * Firmware inventory of SMIB-equipped machines grouped by version per
+ * licencee and location, with machines below the minimum version (or
+ * reporting none) listed for the field team.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Minimum version override (`minimumVersion`, default `SMIB_MIN_FIRMWARE_VERSION`)
+ * - CSV export of the machines needing updates (`format=csv`)
+ *
+ * @module app/api/reports/smib-firmware/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportFirmwareUpdatesToCSV,
+  getSmibFirmwareReport,
+} from '@/app/api/lib/helpers/reports/smibFirmware';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { smibFirmwareReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/smib-firmware
+ *
+ * Query params:
+ * @param licencee       {string} Optional. Scopes results to this licencee.
+ * @param locationId     {string} Optional. Limits the report to one location.
+ * @param minimumVersion {string} Optional. Flag versions below this. Defaults to SMIB_MIN_FIRMWARE_VERSION.
+ * @param format         {string} Optional. 'json' (default) or 'csv' (machines needing updates only).
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getSmibFirmwareReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/smib-firmware';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(smibFirmwareReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee = '', locationId, minimumVersion, format } = query.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getSmibFirmwareReport({
+        allowedLocationIds,
+        minimumVersion,
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/smib-firmware',
+        report.machineCount,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(
+          exportFirmwareUpdatesToCSV(report.machinesNeedingUpdate),
+          {
+            headers: {
+              'Content-Type': 'text/csv',
+              'Content-Disposition':
+                'attachment; filename="smib-firmware-updates.csv"',
+            },
+          }
+        );
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/smib-firmware',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
@@ -0,0 +1,175 @@
+/**
+ * Report Template API Route
+ *
+ * Fetches or deletes a single saved report template by name.
+ *
+ * @module app/api/reports/templates/[name]/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  deleteReportTemplate,
+  getReportTemplateByName,
+} from '@/app/api/lib/helpers/reports/reportTemplates';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/templates/[name]
+ *
+ * Returns the saved template, or 404 when no template has that name.
+ */
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ name: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/templates/[name]';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    try {
+      const { name } = await params;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      const template = await getReportTemplateByName(name);
+      if (!template) {
+        return NextResponse.json(
+          { success: false, error: 'Template not found' },
+          { status: 404 }
+        );
+      }
+
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        `/api/reports/templates/${name}`,
+        1,
+        user,
+        duration
+      );
+      return NextResponse.json({ success: true, data: template });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/templates/[name]',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * DELETE /api/reports/templates/[name]
+ *
+ * Flow:
+ * 1. Delete the template
+ * 2. Log activity
+ * 3. Return the deleted template
+ */
+export async function DELETE(
+  request: NextRequest,
+  { params }: { params: Promise<{ name: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'DELETE /api/reports/templates/[name]';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload }) => {
+    try {
+      const { name } = await params;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 1: Delete the template
+      // ============================================================================
+      const template = await deleteReportTemplate(name);
+      if (!template) {
+        return NextResponse.json(
+          { success: false, error: 'Template not found' },
+          { status: 404 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Log activity
+      // ============================================================================
+      try {
+        await logActivity({
+          action: 'DELETE',
+          details: `Deleted report template ${template.name}`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'reportTemplate',
+            resourceId: template._id,
+            resourceName: template.name,
+            changes: [],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      // ============================================================================
+      // STEP 3: Return the deleted template
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'DELETE',
+        `/api/reports/templates/${name}`,
+        1,
+        user,
+        duration
+      );
+      return NextResponse.json({ success: true, data: template });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'DELETE',
+        '/api/reports/templates/[name]',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
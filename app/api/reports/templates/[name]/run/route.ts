@@ -0,0 +1,176 @@
+/**
+ * Report Template Run API Route
+ *
+ * Re-runs a saved report template by name, scoped to the caller's accessible
+ * locations and reviewer scales.
+ *
+ * @module app/api/reports/templates/[name]/run/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  getReportTemplateByName,
+  runReportTemplate,
+} from '@/app/api/lib/helpers/reports/reportTemplates';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { runReportTemplateRoute } from '@/app/api/lib/routeSchemas/reports';
+import { getExportProfile } from '@/app/api/lib/utils/exportProfiles';
+import {
+  getMoneyInScale,
+  getMoneyOutAndJackpotScale,
+} from '@/app/api/lib/utils/reviewerScale';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/templates/[name]/run
+ *
+ * @param format Optional. `json` or `csv`; defaults to the template's output format.
+ * @param profile Optional. Export profile to shape the output (see export-profiles.json).
+ *
+ * Flow:
+ * 1. Load the template
+ * 2. Resolve the user's accessible locations
+ * 3. Run the template
+ * 4. Return rows (JSON) or a CSV download
+ */
+export async function GET(
+  request: NextRequest,
+  { params }: { params: Promise<{ name: string }> }
+) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/templates/[name]/run';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Load the template
+      // ============================================================================
+      const { name } = await params;
+      const query = parseQuery(runReportTemplateRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      const template = await getReportTemplateByName(name);
+      if (!template) {
+        return NextResponse.json(
+          { success: false, error: 'Template not found' },
+          { status: 404 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const allowedLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      const profileName = query.data.profile;
+      const profile = profileName
+        ? getExportProfile(profileName, userAccessibleLicencees)
+        : undefined;
+
+      // ============================================================================
+      // STEP 3: Run the template
+      // ============================================================================
+      const referenceDate = new Date();
+      const result = await runReportTemplate(
+        template,
+        {
+          allowedLocationIds,
+          scales: {
+            moneyInScale: getMoneyInScale(
+              userPayload as {
+                moneyInMultiplier?: number | null;
+                roles?: string[];
+                reviewerMultiplierStartTime?: Date | string | null;
+              },
+              referenceDate
+            ),
+            moneyOutScale: getMoneyOutAndJackpotScale(
+              userPayload as {
+                moneyOutAndJackpotMultiplier?: number | null;
+                roles?: string[];
+                reviewerMultiplierStartTime?: Date | string | null;
+              },
+              referenceDate
+            ),
+          },
+          profile,
+        },
+        query.data.format
+      );
+
+      // ============================================================================
+      // STEP 4: Return rows (JSON) or a CSV download
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        `/api/reports/templates/${name}/run`,
+        result.rows.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (result.csv !== undefined) {
+        return new NextResponse(result.csv, {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': `attachment; filename="${template.name}.csv"`,
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, ...result });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/templates/[name]/run',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
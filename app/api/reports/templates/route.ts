@@ -0,0 +1,191 @@
+/**
+ * Report Templates API Route
+ *
+ * Lists and saves named report templates (report type, parameters and output
+ * format) stored in `reporttemplates`. Templates are re-run by name via
+ * `/api/reports/templates/[name]/run`.
+ *
+ * @module app/api/reports/templates/route
+ */
+
+import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  listReportTemplates,
+  saveReportTemplate,
+} from '@/app/api/lib/helpers/reports/reportTemplates';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { saveReportTemplateRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/templates
+ *
+ * Returns all saved templates ordered by name.
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/templates';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async () => {
+    try {
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      const templates = await listReportTemplates();
+
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/templates',
+        templates.length,
+        user,
+        duration
+      );
+      return NextResponse.json({ success: true, data: templates });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/templates',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: 500 }
+      );
+    }
+  });
+}
+
+/**
+ * POST /api/reports/templates
+ *
+ * @body {string} name         Required. Letters, digits, `-` or `_` (max 64).
+ * @body {string} reportType   Required. `query-builder`, `licencee-leaderboard`, `machine-utilization` or `shifts`.
+ * @body {object} parameters   Required. Report parameters (query-builder: the spec).
+ * @body {string} outputFormat Optional. `json` (default) or `csv`.
+ * @body {string} description  Optional.
+ *
+ * Saving an existing name replaces its configuration.
+ *
+ * Flow:
+ * 1. Parse request body
+ * 2. Validate and save the template
+ * 3. Log activity
+ * 4. Return the saved template
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/reports/templates';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request body
+      // ============================================================================
+      const validation = parseBody(
+        saveReportTemplateRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Validate and save the template
+      // ============================================================================
+      const { template, created } = await saveReportTemplate({
+        name: body.name,
+        description: body.description,
+        reportType: body.reportType,
+        parameters: body.parameters,
+        outputFormat: body.outputFormat,
+        createdBy: String(userPayload._id),
+      });
+
+      // ============================================================================
+      // STEP 3: Log activity
+      // ============================================================================
+      try {
+        await logActivity({
+          action: created ? 'CREATE' : 'UPDATE',
+          details: `${created ? 'Created' : 'Updated'} report template ${template.name}`,
+          userId: String(userPayload._id),
+          username: String(userPayload.emailAddress ?? userPayload._id),
+          metadata: {
+            resource: 'reportTemplate',
+            resourceId: template._id,
+            resourceName: template.name,
+            changes: [],
+          },
+        });
+      } catch (logError) {
+        console.error('Failed to log activity:', logError);
+      }
+
+      // ============================================================================
+      // STEP 4: Return the saved template
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'POST',
+        '/api/reports/templates',
+        1,
+        user,
+        duration
+      );
+      return NextResponse.json(
+        { success: true, data: template },
+        { status: created ? 201 : 200 }
+      );
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/reports/templates',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -0,0 +1,192 @@
+/**
+ * TITO Reconciliation API Route
+ *
+ * Ticket-in / ticket-out meter movement reconciled against cancelled credits
+ * and drop per location per gaming day, with imbalances beyond a threshold
+ * flagged down to the machine for the cage team.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Time period or custom range, and an imbalance `threshold`
+ * - CSV export (`format=csv`)
+ *
+ * @module app/api/reports/tito-reconciliation/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportTitoReconciliationToCSV,
+  getTitoReconciliationReport,
+} from '@/app/api/lib/helpers/reports/titoReconciliation';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { titoReconciliationReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/tito-reconciliation
+ *
+ * Query params:
+ * @param licencee    {string}     Optional. Scopes results to this licencee.
+ * @param locationId  {string}     Optional. Limits the report to one location.
+ * @param timePeriod  {TimePeriod} Optional. Defaults to '7d'.
+ * @param startDate   {string}     Optional. Custom range start (with timePeriod=Custom).
+ * @param endDate     {string}     Optional. Custom range end (with timePeriod=Custom).
+ * @param threshold   {number}     Optional. Imbalance tolerated before flagging, in meter units. Defaults to 1.
+ * @param flaggedOnly {boolean}    Optional. 'true' to return only flagged location-days.
+ * @param format      {string}     Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse and validate request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getTitoReconciliationReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/tito-reconciliation';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse and validate request parameters
+      // ============================================================================
+      const query = parseQuery(titoReconciliationReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const {
+        licencee = '',
+        locationId,
+        timePeriod,
+        startDate,
+        endDate,
+        threshold,
+        format,
+      } = query.data;
+      const customStartDate = startDate ? new Date(startDate) : undefined;
+      const customEndDate = endDate ? new Date(endDate) : undefined;
+      const flaggedOnly = query.data.flaggedOnly === 'true';
+
+      if (
+        timePeriod === 'Custom' &&
+        (!customStartDate ||
+          !customEndDate ||
+          Number.isNaN(customStartDate.getTime()) ||
+          Number.isNaN(customEndDate.getTime()))
+      ) {
+        return NextResponse.json(
+          {
+            success: false,
+            error: 'Valid startDate and endDate are required for Custom',
+          },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getTitoReconciliationReport({
+        allowedLocationIds,
+        timePeriod,
+        customStartDate,
+        customEndDate,
+        threshold,
+      });
+      if (flaggedOnly) {
+        report.days = report.days.filter(day => day.flagged);
+      }
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/tito-reconciliation',
+        report.days.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportTitoReconciliationToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition':
+              'attachment; filename="tito-reconciliation.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, timePeriod, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/tito-reconciliation',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -0,0 +1,192 @@
+/**
+ * Top Members Report API Route
+ *
+ * Highest-activity members per location (amount wagered, time played) with
+ * responsible-gaming flags for compliance follow-up.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Date range (`startDate`/`endDate`) on session start, members per
+ *   location (`limit`), ranking (`sortBy`) and flagged members only
+ * - Threshold overrides (`maxHoursPerDay`, `maxWeeklyLoss`)
+ * - CSV export (`format=csv`)
+ *
+ * @module app/api/reports/top-members/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  DEFAULT_TOP_MEMBERS_DAYS,
+  exportTopMembersToCSV,
+  getResponsibleGamingThresholds,
+  getTopMembersReport,
+} from '@/app/api/lib/helpers/reports/topMembers';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { topMembersReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/top-members
+ *
+ * Query params:
+ * @param licencee       {string}  Optional. Scopes results to this licencee.
+ * @param locationId     {string}  Optional. Limits the report to one location.
+ * @param startDate      {string}  Optional. ISO start (session start). Defaults to 7 days ago.
+ * @param endDate        {string}  Optional. ISO end. Defaults to now.
+ * @param limit          {number}  Optional. Members per location (1-100). Defaults to 10.
+ * @param sortBy         {string}  Optional. 'wagered' (default) or 'time'.
+ * @param flaggedOnly    {boolean} Optional. Only members with a responsible-gaming flag.
+ * @param maxHoursPerDay {number}  Optional. Overrides RG_MAX_HOURS_PER_DAY.
+ * @param maxWeeklyLoss  {number}  Optional. Overrides RG_MAX_WEEKLY_LOSS.
+ * @param format         {string}  Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getTopMembersReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/top-members';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(topMembersReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee = '', locationId, format, limit, sortBy } = query.data;
+      const { startDate, endDate, maxHoursPerDay, maxWeeklyLoss } = query.data;
+      const to = endDate ? new Date(endDate) : new Date();
+      const from = startDate
+        ? new Date(startDate)
+        : new Date(to.getTime() - DEFAULT_TOP_MEMBERS_DAYS * 86400000);
+      const flaggedOnly = query.data.flaggedOnly === 'true';
+      const thresholds = getResponsibleGamingThresholds({
+        maxHoursPerDay,
+        maxWeeklyLoss,
+      });
+
+      if (
+        Number.isNaN(from.getTime()) ||
+        Number.isNaN(to.getTime()) ||
+        from > to
+      ) {
+        return NextResponse.json(
+          { success: false, error: 'Invalid startDate/endDate' },
+          { status: 400 }
+        );
+      }
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const report = await getTopMembersReport({
+        allowedLocationIds,
+        from,
+        to,
+        limit,
+        sortBy,
+        thresholds,
+        flaggedOnly,
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/top-members',
+        report.locations.reduce(
+          (sum, location) => sum + location.members.length,
+          0
+        ),
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportTopMembersToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition': 'attachment; filename="top-members.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/top-members',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
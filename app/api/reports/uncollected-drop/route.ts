@@ -0,0 +1,178 @@
+/**
+ * Uncollected Drop Report API Route
+ *
+ * Meter movement of each machine since its last completed collection,
+ * summed per location and ranked by uncollected drop per day, so cash
+ * logistics can see which venues are building up cash fastest.
+ * It supports:
+ * - Role-based licencee and location access
+ * - Reviewer money scales
+ * - CSV export of the machine rows (`format=csv`)
+ *
+ * @module app/api/reports/uncollected-drop/route
+ */
+
+import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  getUserAccessibleLicenceesFromToken,
+  getUserLocationFilter,
+} from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  exportUncollectedDropToCSV,
+  getUncollectedDropReport,
+} from '@/app/api/lib/helpers/reports/uncollectedDrop';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { uncollectedDropReportRoute } from '@/app/api/lib/routeSchemas/reports';
+import {
+  getMoneyInScale,
+  getMoneyOutAndJackpotScale,
+} from '@/app/api/lib/utils/reviewerScale';
+import {
+  logRouteFetch,
+  logRouteError,
+  extractUserFromRequest,
+} from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * GET /api/reports/uncollected-drop
+ *
+ * Query params:
+ * @param licencee   {string} Optional. Scopes results to this licencee.
+ * @param locationId {string} Optional. Limits the report to one location.
+ * @param maxDays    {number} Optional. Days counted for machines never collected (1-365). Defaults to 90.
+ * @param format     {string} Optional. 'json' (default) or 'csv'.
+ *
+ * Flow:
+ * 1. Parse request parameters
+ * 2. Resolve the user's accessible locations
+ * 3. Build the report via `getUncollectedDropReport`
+ * 4. Return JSON or CSV
+ */
+export async function GET(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'GET /api/reports/uncollected-drop';
+  const user = extractUserFromRequest(request);
+
+  return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
+    try {
+      // ============================================================================
+      // STEP 1: Parse request parameters
+      // ============================================================================
+      const query = parseQuery(uncollectedDropReportRoute, request);
+      if (!query.success) return validationErrorResponse(query.error);
+      const { licencee = '', locationId, maxDays, format } = query.data;
+
+      const db = await connectDB();
+      if (!db) {
+        return NextResponse.json(
+          { success: false, error: 'DB connection failed' },
+          { status: 500 }
+        );
+      }
+
+      // ============================================================================
+      // STEP 2: Resolve the user's accessible locations
+      // ============================================================================
+      const userAccessibleLicencees =
+        await getUserAccessibleLicenceesFromToken();
+      const accessibleLocationIds = await getUserLocationFilter(
+        userAccessibleLicencees,
+        licencee || undefined,
+        Array.isArray(userPayload.assignedLocations)
+          ? userPayload.assignedLocations
+          : [],
+        userRoles
+      );
+
+      let allowedLocationIds = accessibleLocationIds;
+      if (locationId) {
+        if (
+          accessibleLocationIds !== 'all' &&
+          !accessibleLocationIds.includes(locationId)
+        ) {
+          return NextResponse.json(
+            { success: false, error: 'Forbidden' },
+            { status: 403 }
+          );
+        }
+        allowedLocationIds = [locationId];
+      }
+
+      // ============================================================================
+      // STEP 3: Build report
+      // ============================================================================
+      const referenceDate = new Date();
+      const report = await getUncollectedDropReport({
+        allowedLocationIds,
+        maxDays,
+        scales: {
+          moneyInScale: getMoneyInScale(
+            userPayload as {
+              moneyInMultiplier?: number | null;
+              roles?: string[];
+              reviewerMultiplierStartTime?: Date | string | null;
+            },
+            referenceDate
+          ),
+          moneyOutScale: getMoneyOutAndJackpotScale(
+            userPayload as {
+              moneyOutAndJackpotMultiplier?: number | null;
+              roles?: string[];
+              reviewerMultiplierStartTime?: Date | string | null;
+            },
+            referenceDate
+          ),
+        },
+      });
+
+      // ============================================================================
+      // STEP 4: Return JSON or CSV
+      // ============================================================================
+      const duration = Date.now() - startTime;
+      logRouteFetch(
+        functionName,
+        'GET',
+        '/api/reports/uncollected-drop',
+        report.locations.length,
+        user,
+        duration
+      );
+      if (duration > 1000) {
+        console.warn(`[${functionName}] Completed in ${duration}ms`);
+      }
+
+      if (format === 'csv') {
+        return new NextResponse(exportUncollectedDropToCSV(report), {
+          headers: {
+            'Content-Type': 'text/csv',
+            'Content-Disposition':
+              'attachment; filename="uncollected-drop.csv"',
+          },
+        });
+      }
+
+      return NextResponse.json({ success: true, data: report });
+    } catch (error) {
+      const errorMessage =
+        error instanceof Error ? error.message : 'Unknown error';
+      logRouteError(
+        functionName,
+        'GET',
+        '/api/reports/uncollected-drop',
+        errorMessage,
+        user
+      );
+      console.error(`[${functionName}] Error:`, errorMessage);
+      return NextResponse.json(
+        { success: false, error: errorMessage },
+        { status: getErrorStatus(error) }
+      );
+    }
+  });
+}
@@ -11,6 +11,11 @@
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { connectDB } from '@/app/api/lib/middleware/db';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { vaultActivityReportRoute } from '@/app/api/lib/routeSchemas/reports';
 import {
   logRouteFetch,
   logRouteError,
@@ -18,14 +23,6 @@ import {
 } from '@/app/api/lib/utils/routeLogger';
 import { NextRequest, NextResponse } from 'next/server';
 
-type VaultActivityQuery = {
-  locationId?: string;
-  startDate?: string;
-  endDate?: string;
-  type?: string;
-  performedBy?: string;
-};
-
 /**
  * Main GET handler for fetching vault activity report
  *
@@ -62,14 +59,9 @@ export async function GET(request: NextRequest) {
     // ============================================================================
     // STEP 2: Parse query parameters
     // ============================================================================
-    const { searchParams } = new URL(request.url);
-    const query: VaultActivityQuery = {
-      locationId: searchParams.get('locationId') || undefined,
-      startDate: searchParams.get('startDate') || undefined,
-      endDate: searchParams.get('endDate') || undefined,
-      type: searchParams.get('type') || undefined,
-      performedBy: searchParams.get('performedBy') || undefined,
-    };
+    const parsed = parseQuery(vaultActivityReportRoute, request);
+    if (!parsed.success) return validationErrorResponse(parsed.error);
+    const query = parsed.data;
 
     // ============================================================================
     // STEP 3: Database connection
@@ -17,12 +17,18 @@ import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { getUserFromServer } from '@/app/api/lib/helpers/users';
 import Scheduler from '@/app/api/lib/models/scheduler';
 import type { SchedulerDocument } from '@shared/types';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { updateSchedulerRoute } from '@/app/api/lib/routeSchemas/schedulers';
 import {
   logRouteUpdate,
   logRouteDelete,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { NextRequest, NextResponse } from 'next/server';
 
 const MANAGE_ROLES = [
@@ -79,12 +85,14 @@ export async function PATCH(request: NextRequest, context: RouteContext) {
       // STEP 2: Parse request body
       // ============================================================================
       const { schedulerId } = await context.params;
-      const body = await request.json();
-      const { startTime, endTime, status } = body as {
-        startTime?: string;
-        endTime?: string;
-        status?: string;
-      };
+      const validation = parseBody(
+        updateSchedulerRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const { startTime, endTime, status } = validation.data;
 
       // ============================================================================
       // STEP 3: Build update data
@@ -111,7 +119,7 @@ export async function PATCH(request: NextRequest, context: RouteContext) {
       // ============================================================================
       const existingScheduler = await Scheduler.findOne({
         _id: schedulerId,
-        deletedAt: { $exists: false },
+        ...NOT_DELETED_FILTER,
       }).lean<SchedulerDocument>();
       if (!existingScheduler) {
         return NextResponse.json(
@@ -129,7 +137,7 @@ export async function PATCH(request: NextRequest, context: RouteContext) {
       // STEP 5: Update scheduler
       // ============================================================================
       const updated = await Scheduler.findOneAndUpdate(
-        { _id: schedulerId, deletedAt: { $exists: false } },
+        { _id: schedulerId, ...NOT_DELETED_FILTER },
         { $set: updateData },
         { new: true }
       );
@@ -263,7 +271,7 @@ export async function DELETE(_request: NextRequest, context: RouteContext) {
       // STEP 3: Soft delete scheduler
       // ============================================================================
       const updated = await Scheduler.findOneAndUpdate(
-        { _id: schedulerId, deletedAt: { $exists: false } },
+        { _id: schedulerId, ...NOT_DELETED_FILTER },
         { $set: { deletedAt: new Date() } },
         { new: true }
       );
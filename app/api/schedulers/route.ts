@@ -14,6 +14,12 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import Scheduler from '@/app/api/lib/models/scheduler';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listSchedulersRoute } from '@/app/api/lib/routeSchemas/schedulers';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import type { MongoDBQueryValue } from '@/lib/types/common';
 import {
   logRouteFetch,
@@ -48,20 +54,19 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse query parameters
       // ============================================================================
-      const searchParams = request.nextUrl.searchParams;
-      const licencee = searchParams.get('licencee');
-      const location = searchParams.get('location');
-      const collector = searchParams.get('collector');
-      const status = searchParams.get('status');
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
+      const parsed = parseQuery(listSchedulersRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const { licencee, location, collector, status, startDate, endDate } =
+        parsed.data;
 
       // ============================================================================
       // STEP 2: Build query filters
       // ============================================================================
       // Always exclude soft-deleted records
       const query: Record<string, MongoDBQueryValue> = {
-        deletedAt: { $exists: false },
+        ...NOT_DELETED_FILTER,
       };
 
       if (licencee && licencee.toLowerCase() !== 'all') {
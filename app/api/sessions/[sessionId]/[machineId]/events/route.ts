@@ -16,15 +16,23 @@
  * 4. If `command` param is provided, resolve the cursor page (seek to position)
  * 5. Run $facet aggregation: metadata count, paginated data, filter options
  * 6. Return events + pagination + filter options
+ *
+ * Each event carries `sasEvent`, its decoded SAS exception code.
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { MachineEvent } from '@/app/api/lib/models/machineEvents';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listSessionEventsRoute } from '@/app/api/lib/routeSchemas/sessions';
 import {
   logRouteFetch,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
+import { withSasEvents } from '@/app/api/lib/utils/sasCodes';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
@@ -64,24 +72,16 @@ export async function GET(request: NextRequest) {
   try {
     // === STEP 1: Parse params ===
 
-    const { searchParams } = new URL(request.url);
-    const pageParam = parseInt(searchParams.get('page') || '1');
-    const page = Number.isFinite(pageParam) && pageParam > 0 ? pageParam : 1;
-
-    const limitParam = parseInt(searchParams.get('limit') || '20');
-    const limit =
-      Number.isFinite(limitParam) && limitParam > 0
-        ? Math.min(limitParam, 100)
-        : 20;
-
-    const timePeriod = searchParams.get('timePeriod');
-    const eventType = searchParams.get('eventType');
-    const eventLogLevel = searchParams.get('type');
-    const eventDescription = searchParams.get('event');
-    const game = searchParams.get('game');
-    const command = searchParams.get('command');
-    const startDateParam = searchParams.get('startDate');
-    const endDateParam = searchParams.get('endDate');
+    const query = parseQuery(listSessionEventsRoute, request);
+    if (!query.success) {
+      return validationErrorResponse(query.error);
+    }
+    const { page, timePeriod, eventType, game, command } = query.data;
+    const limit = Math.min(query.data.limit, 100);
+    const eventLogLevel = query.data.type;
+    const eventDescription = query.data.event;
+    const startDateParam = query.data.startDate;
+    const endDateParam = query.data.endDate;
 
     // === STEP 2: Build base match query ===
     // machine and currentSession are stored as Strings in this collection
@@ -245,7 +245,7 @@ export async function GET(request: NextRequest) {
     return NextResponse.json({
       success: true,
       data: {
-        events,
+        events: withSasEvents(events),
         pagination: {
           currentPage: resolvedPage,
           hasMore,
@@ -20,6 +20,11 @@ import {
   buildSessionMatchQuery,
 } from '@/app/api/lib/helpers/sessions';
 import { MachineSession } from '@/app/api/lib/models/machineSessions';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listSessionsRoute } from '@/app/api/lib/routeSchemas/sessions';
 import {
   logRouteFetch,
   logRouteError,
@@ -48,16 +53,14 @@ export async function GET(request: NextRequest) {
     const user = extractUserFromRequest(request);
 
     try {
-      const { searchParams } = new URL(request.url);
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = parseInt(searchParams.get('limit') || '10');
-      const search = searchParams.get('search') || '';
-      const sortBy = searchParams.get('sortBy') || 'startTime';
-      const sortOrder = searchParams.get('sortOrder') || 'desc';
-      const licencee = searchParams.get('licencee') || '';
-      const dateFilter = searchParams.get('dateFilter') || 'all';
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
+      const query = parseQuery(listSessionsRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const { page, limit, search, sortBy, sortOrder, licencee, dateFilter } =
+        query.data;
+      const startDateParam = query.data.startDate ?? null;
+      const endDateParam = query.data.endDate ?? null;
 
       // Step 1: Build match query from search and date params
       const matchQuery = buildSessionMatchQuery({
@@ -0,0 +1,122 @@
+/**
+ * SMIB Heartbeat API Route
+ *
+ * Receives heartbeat pings from SMIBs so machine online/offline status is
+ * driven by the devices themselves. Each ping advances the machine's
+ * `lastActivity` and is stored in `heartbeats` (see helpers/heartbeats).
+ *
+ * SMIBs have no user session, so this route authenticates with a shared
+ * token (`HEARTBEAT_TOKEN`) sent as `Authorization: Bearer <token>` instead
+ * of `withApiAuth`. Without a configured token the route is disabled (503).
+ *
+ * Body: a single ping `{ serial, timestamp?, firmware? }` or a batch
+ * `{ heartbeats: [...] }` of up to 500 pings.
+ *
+ * @module app/api/smib/heartbeat/route
+ */
+
+import {
+  ingestHeartbeats,
+  verifyHeartbeatToken,
+} from '@/app/api/lib/helpers/heartbeats';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import { logRouteCreate, logRouteError } from '@/app/api/lib/utils/routeLogger';
+import { getErrorStatus } from '@/app/api/lib/utils/statusErrors';
+import { getClientIP } from '@/lib/utils/ipAddress';
+import { NextRequest, NextResponse } from 'next/server';
+
+/**
+ * Main POST handler for SMIB heartbeats
+ *
+ * @header Authorization - REQUIRED. `Bearer <HEARTBEAT_TOKEN>`.
+ * @body {string} serial - REQUIRED. SMIB relay ID (or machine serial number).
+ * @body {string|number} timestamp - Optional. Device time (ISO or epoch); defaults to receive time.
+ * @body {string} firmware - Optional. SMIB firmware version.
+ * @body {Array} heartbeats - Optional. A batch of pings instead of a single one.
+ *
+ * Flow:
+ * 1. Verify the heartbeat token
+ * 2. Parse request body
+ * 3. Record the pings
+ * 4. Return counts of matched and unknown serials
+ */
+export async function POST(request: NextRequest) {
+  const startTime = Date.now();
+  const functionName = 'POST /api/smib/heartbeat';
+  const user = { _id: 'smib', username: 'smib' };
+
+  try {
+    // ============================================================================
+    // STEP 1: Verify the heartbeat token
+    // ============================================================================
+    const authorization = request.headers.get('authorization') || '';
+    const token = authorization.replace(/^Bearer\s+/i, '').trim();
+    const verified = await verifyHeartbeatToken(token);
+    if (verified === 'unconfigured') {
+      return NextResponse.json(
+        { success: false, error: 'Heartbeat ingestion is not configured' },
+        { status: 503 }
+      );
+    }
+    if (verified === 'invalid') {
+      return NextResponse.json(
+        { success: false, error: 'Invalid heartbeat token' },
+        { status: 401 }
+      );
+    }
+
+    // ============================================================================
+    // STEP 2: Parse request body
+    // ============================================================================
+    let body: unknown;
+    try {
+      body = await request.json();
+    } catch {
+      return NextResponse.json(
+        { success: false, error: 'Body must be JSON' },
+        { status: 400 }
+      );
+    }
+    const batch = (body as { heartbeats?: unknown } | null)?.heartbeats;
+    const pings = Array.isArray(batch) ? batch : [body];
+
+    // ============================================================================
+    // STEP 3: Record the pings
+    // ============================================================================
+    await connectDB();
+    const result = await ingestHeartbeats(pings, 'http', getClientIP(request));
+
+    // ============================================================================
+    // STEP 4: Return counts of matched and unknown serials
+    // ============================================================================
+    const duration = Date.now() - startTime;
+    logRouteCreate(
+      functionName,
+      'POST',
+      '/api/smib/heartbeat',
+      result.received,
+      user,
+      duration
+    );
+    if (duration > 1000) {
+      console.warn(`[${functionName}] Completed in ${duration}ms`);
+    }
+
+    return NextResponse.json({ success: true, data: result });
+  } catch (error) {
+    const errorMessage =
+      error instanceof Error ? error.message : 'Unknown error';
+    logRouteError(
+      functionName,
+      'POST',
+      '/api/smib/heartbeat',
+      errorMessage,
+      user
+    );
+    console.error(`[${functionName}] Error:`, errorMessage);
+    return NextResponse.json(
+      { success: false, error: errorMessage },
+      { status: getErrorStatus(error) }
+    );
+  }
+}
@@ -22,6 +22,11 @@ import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Machine } from '@/app/api/lib/models/machines';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { requestSmibMetersRoute } from '@/app/api/lib/routeSchemas/smib';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import {
   logRouteCreate,
@@ -37,13 +42,12 @@ import { NextRequest, NextResponse } from 'next/server';
  * @body {string} relayId - REQUIRED. The unique relay ID of the machine to read meters from.
  *
  * Flow:
- * 1. Parse request body
- * 2. Validate relayId
- * 3. Find machine by relayId
- * 4. Check for MQTT callbacks
- * 5. Send meter request via MQTT
- * 6. Log activity
- * 7. Return success response
+ * 1. Parse and validate request body
+ * 2. Find machine by relayId
+ * 3. Check for MQTT callbacks
+ * 4. Send meter request via MQTT
+ * 5. Log activity
+ * 6. Return success response
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -53,22 +57,19 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const { relayId } = await request.json();
-
-      // ============================================================================
-      // STEP 2: Validate relayId
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      if (!relayId) {
-        return NextResponse.json(
-          { success: false, error: 'RelayId is required' },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        requestSmibMetersRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { relayId } = validation.data;
 
       // ============================================================================
-      // STEP 3: Find machine by relayId
+      // STEP 2: Find machine by relayId
       // ============================================================================
       const machine = await Machine.findOne({
         $or: [{ relayId }, { smibBoard: relayId }],
@@ -82,7 +83,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 4: Check for MQTT callbacks
+      // STEP 3: Check for MQTT callbacks
       // ============================================================================
       const hasCallbacks = mqttService.hasCallbacksForRelayId(relayId);
       if (!hasCallbacks) {
@@ -92,7 +93,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 5: Send meter request via MQTT
+      // STEP 4: Send meter request via MQTT
       // ============================================================================
       try {
         await mqttService.requestMeterData(relayId);
@@ -104,7 +105,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 6: Log activity
+      // STEP 5: Log activity
       // ============================================================================
       const currentUser = await getUserFromServer();
       const clientIP = getClientIP(request);
@@ -132,7 +133,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 7: Return success response
+      // STEP 6: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteCreate(functionName, 'POST', '/api/smib/meters', 1, user, duration);
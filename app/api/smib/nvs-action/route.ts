@@ -16,6 +16,11 @@
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { smibNvsActionRoute } from '@/app/api/lib/routeSchemas/smib';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import {
   logRouteCreate,
@@ -32,11 +37,10 @@ import { NextRequest, NextResponse } from 'next/server';
  * @body {string} action - REQUIRED. The NVS clear action ('clear_nvs', 'clear_nvs_meters', etc.).
  *
  * Flow:
- * 1. Parse request body
- * 2. Validate relayId and action
- * 3. Send appropriate NVS command via MQTT
- * 4. Log activity
- * 5. Return success response
+ * 1. Parse and validate request body
+ * 2. Send appropriate NVS command via MQTT
+ * 3. Log activity
+ * 4. Return success response
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -46,45 +50,19 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const { relayId, action } = await request.json();
-
-      // ============================================================================
-      // STEP 2: Validate relayId and action
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      if (!relayId) {
-        return NextResponse.json(
-          { success: false, error: 'RelayId is required' },
-          { status: 400 }
-        );
-      }
-
-      if (!action) {
-        return NextResponse.json(
-          { success: false, error: 'Action is required' },
-          { status: 400 }
-        );
-      }
-
-      const validActions = [
-        'clear_nvs',
-        'clear_nvs_meters',
-        'clear_nvs_bv',
-        'clear_nvs_door',
-      ];
-      if (!validActions.includes(action)) {
-        return NextResponse.json(
-          {
-            success: false,
-            error: `Invalid action. Must be one of: ${validActions.join(', ')}`,
-          },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        smibNvsActionRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { relayId, action } = validation.data;
 
       // ============================================================================
-      // STEP 3: Send appropriate NVS command via MQTT
+      // STEP 2: Send appropriate NVS command via MQTT
       // ============================================================================
       try {
         switch (action) {
@@ -111,7 +89,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 4: Log activity
+      // STEP 3: Log activity
       // ============================================================================
       const currentUser = await getUserFromServer();
       const clientIP = getClientIP(request);
@@ -142,7 +120,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 5: Return success response
+      // STEP 4: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteCreate(
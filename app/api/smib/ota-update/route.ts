@@ -17,6 +17,11 @@ import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Machine } from '@/app/api/lib/models/machines';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { smibOtaUpdateRoute } from '@/app/api/lib/routeSchemas/smib';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import {
   logRouteCreate,
@@ -35,15 +40,14 @@ import { NextRequest, NextResponse } from 'next/server';
  * @body {string} firmwareId - REQUIRED. Firmware record ID
  *
  * Flow:
- * 1. Parse request body
- * 2. Validate relayId and firmwareId
- * 3. Prepare firmware file and get URL
- * 4. Build firmware URL from request headers
- * 5. Configure OTA URL on SMIB
- * 6. Send OTA update command
- * 7. Update firmwareUpdatedAt timestamp
- * 8. Log activity
- * 9. Return success response
+ * 1. Parse and validate request body
+ * 2. Prepare firmware file and get URL
+ * 3. Build firmware URL from request headers
+ * 4. Configure OTA URL on SMIB
+ * 5. Send OTA update command
+ * 6. Update firmwareUpdatedAt timestamp
+ * 7. Log activity
+ * 8. Return success response
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -53,22 +57,19 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const { relayId, firmwareId } = await request.json();
-
-      // ============================================================================
-      // STEP 2: Validate relayId and firmwareId
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      if (!relayId || !firmwareId) {
-        return NextResponse.json(
-          { success: false, error: 'RelayId and firmwareId are required' },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        smibOtaUpdateRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { relayId, firmwareId } = validation.data;
 
       // ============================================================================
-      // STEP 3: Prepare firmware file and get URL
+      // STEP 2: Prepare firmware file and get URL
       // ============================================================================
       const serveResponse = await axios.get(
         `${request.headers.get('origin') || 'http://localhost:3000'}/api/firmwares/${firmwareId}/serve`
@@ -84,7 +85,7 @@ export async function POST(request: NextRequest) {
       const { fileName } = serveResponse.data;
 
       // ============================================================================
-      // STEP 4: Build firmware URL from request headers
+      // STEP 3: Build firmware URL from request headers
       // ============================================================================
       let host = request.headers.get('host');
       const protocol = request.headers.get('x-forwarded-proto') || 'http';
@@ -106,7 +107,7 @@ export async function POST(request: NextRequest) {
       const firmwareBinUrl = `${baseUrl}/firmwares/`;
 
       // ============================================================================
-      // STEP 5: Configure OTA URL on SMIB and send update command
+      // STEP 4: Configure OTA URL on SMIB and send update command
       // ============================================================================
       try {
         await mqttService.configureOTAUrl(relayId, firmwareBinUrl);
@@ -115,12 +116,12 @@ export async function POST(request: NextRequest) {
         await new Promise(resolve => setTimeout(resolve, 1000));
 
         // ============================================================================
-        // STEP 6: Send OTA update command
+        // STEP 5: Send OTA update command
         // ============================================================================
         await mqttService.sendOTAUpdateCommand(relayId, firmwareBinUrl);
 
         // ============================================================================
-        // STEP 7: Update firmwareUpdatedAt timestamp
+        // STEP 6: Update firmwareUpdatedAt timestamp
         // ============================================================================
         const updateResult = await Machine.updateOne(
           { $or: [{ relayId }, { smibBoard: relayId }] },
@@ -139,7 +140,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 8: Log activity
+      // STEP 7: Log activity
       // ============================================================================
       const currentUser = await getUserFromServer();
       const clientIP = getClientIP(request);
@@ -174,7 +175,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 9: Return success response
+      // STEP 8: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteCreate(
@@ -15,6 +15,11 @@ import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Machine } from '@/app/api/lib/models/machines';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { restartSmibRoute } from '@/app/api/lib/routeSchemas/smib';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import {
@@ -30,12 +35,11 @@ import { NextRequest, NextResponse } from 'next/server';
  * @body {string} relayId - REQUIRED. The 12-char hex relay ID of the SMIB to restart
  *
  * Flow:
- * 1. Parse request body
- * 2. Validate relayId
- * 3. Find machine by relayId
- * 4. Send restart command via MQTT
- * 5. Log activity
- * 6. Return success response
+ * 1. Parse and validate request body
+ * 2. Find machine by relayId
+ * 3. Send restart command via MQTT
+ * 4. Log activity
+ * 5. Return success response
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -45,29 +49,19 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const { relayId } = await request.json();
-
-      // ============================================================================
-      // STEP 2: Validate relayId
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      if (!relayId) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/smib/restart',
-          'RelayId is required',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'RelayId is required' },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        restartSmibRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { relayId } = validation.data;
 
       // ============================================================================
-      // STEP 3: Find machine by relayId
+      // STEP 2: Find machine by relayId
       // ============================================================================
       const machine = await Machine.findOne({
         $or: [{ relayId }, { smibBoard: relayId }],
@@ -88,7 +82,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 4: Send restart command via MQTT
+      // STEP 3: Send restart command via MQTT
       // ============================================================================
       try {
         await mqttService.restartSmib(relayId);
@@ -107,7 +101,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 5: Log activity
+      // STEP 4: Log activity
       // ============================================================================
       const currentUser = await getUserFromServer();
       const clientIP = getClientIP(request);
@@ -136,7 +130,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 6: Return success response
+      // STEP 5: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteCreate(
@@ -12,6 +12,11 @@ import { logActivity } from '@/app/api/lib/helpers/activityLogger';
 import { getUserFromServer } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { Machine } from '@/app/api/lib/models/machines';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { updateSmibMetersRoute } from '@/app/api/lib/routeSchemas/smib';
 import { mqttService } from '@/app/api/lib/services/mqttService';
 import { getClientIP } from '@/lib/utils/ipAddress';
 import {
@@ -27,12 +32,11 @@ import { NextRequest, NextResponse } from 'next/server';
  * @body {string} relayId - REQUIRED. The 12-char hex relay ID of the target SMIB
  *
  * Flow:
- * 1. Parse request body
- * 2. Validate relayId
- * 3. Find machine by relayId
- * 4. Send Update Meters command via MQTT
- * 5. Log activity
- * 6. Return success response
+ * 1. Parse and validate request body
+ * 2. Find machine by relayId
+ * 3. Send Update Meters command via MQTT
+ * 4. Log activity
+ * 5. Return success response
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -42,29 +46,19 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async () => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
-      // ============================================================================
-      const { relayId } = await request.json();
-
-      // ============================================================================
-      // STEP 2: Validate relayId
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      if (!relayId) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/smib/update-meters',
-          'RelayId is required',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'RelayId is required' },
-          { status: 400 }
-        );
+      const validation = parseBody(
+        updateSmibMetersRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
       }
+      const { relayId } = validation.data;
 
       // ============================================================================
-      // STEP 3: Find machine by relayId
+      // STEP 2: Find machine by relayId
       // ============================================================================
       const machine = await Machine.findOne({
         $or: [{ relayId }, { smibBoard: relayId }],
@@ -85,7 +79,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 4: Send Update Meters command via MQTT
+      // STEP 3: Send Update Meters command via MQTT
       // ============================================================================
       try {
         await mqttService.sendUpdateMeters(relayId);
@@ -107,7 +101,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 5: Log activity
+      // STEP 4: Log activity
       // ============================================================================
       const currentUser = await getUserFromServer();
       const clientIP = getClientIP(request);
@@ -136,7 +130,7 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 6: Return success response
+      // STEP 5: Return success response
       // ============================================================================
       const duration = Date.now() - startTime;
       logRouteCreate(
@@ -17,6 +17,14 @@ import {
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { NextRequest, NextResponse } from 'next/server';
 import { apiLogger } from '../../lib/services/loggerService';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  patchUserRoute,
+  replaceUserRoute,
+} from '@/app/api/lib/routeSchemas/users';
 import {
   logRouteFetch,
   logRouteUpdate,
@@ -181,10 +189,16 @@ export async function PUT(request: NextRequest): Promise<Response> {
       }
 
       // ============================================================================
-      // STEP 2: Parse request body
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
-      const { _id, ...updateFields } = body;
+      const validation = parseBody(
+        replaceUserRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const { _id, ...updateFields } = validation.data;
       void _id;
 
       // ============================================================================
@@ -323,10 +337,16 @@ export async function PATCH(request: NextRequest): Promise<Response> {
       }
 
       // ============================================================================
-      // STEP 2: Parse request body
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
-      const { _id, ...updateFields } = body;
+      const validation = parseBody(
+        patchUserRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        return validationErrorResponse(validation.error);
+      }
+      const { _id, ...updateFields } = validation.data;
       void _id;
 
       // ============================================================================
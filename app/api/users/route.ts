@@ -8,10 +8,20 @@ import {
   updateUser as updateUserHelper,
 } from '@/app/api/lib/helpers/users/users';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
-import { validateEmail } from '@/lib/utils/validation/email';
 import { validatePasswordStrength } from '@/lib/utils/validation/password';
 import { NextRequest, NextResponse } from 'next/server';
 import { apiLogger } from '../lib/services/loggerService';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createUserRoute,
+  deleteUserRoute,
+  listUsersRoute,
+  updateUserRoute,
+} from '@/app/api/lib/routeSchemas/users';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -67,8 +77,11 @@ export async function GET(request: NextRequest) {
       // STEP 2: Parse query parameters
       // ============================================================================
       const { searchParams } = new URL(request.url);
-      const status = searchParams.get('status') || 'all';
-      const role = searchParams.get('role');
+      const query = parseQuery(listUsersRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const { status, role } = query.data;
 
       if (!getAllUsers) {
         logRouteError(
@@ -181,85 +194,42 @@ export async function POST(request: NextRequest) {
     const user = extractUserFromRequest(request);
 
     // ============================================================================
-    // STEP 1: Parse request body
+    // STEP 1: Parse and validate request body
     // ============================================================================
-    const body = await request.json();
+    const validation = parseBody(
+      createUserRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
+      logRouteError(
+        functionName,
+        'POST',
+        '/api/users',
+        'Invalid request body',
+        user
+      );
+      return validationErrorResponse(validation.error);
+    }
 
     const {
       username,
       emailAddress,
       password,
-      roles = [],
-      profile = {},
-      isEnabled = true,
-      profilePicture = null,
+      roles,
+      profile,
+      isEnabled,
+      profilePicture,
       assignedLocations,
       assignedLicencees,
       tempPassword,
       moneyInMultiplier,
       moneyOutAndJackpotMultiplier,
       reviewerMultiplierStartTime,
-    } = body;
+    } = validation.data;
 
     // ============================================================================
-    // STEP 2: Validate required fields and constraints
+    // STEP 2: Check password strength
     // ============================================================================
-    if (!username || typeof username !== 'string') {
-      logRouteError(
-        functionName,
-        'POST',
-        '/api/users',
-        'Username is required',
-        user
-      );
-      return NextResponse.json(
-        { success: false, message: 'Username is required' },
-        { status: 400 }
-      );
-    }
-
-    if (!emailAddress || !validateEmail(emailAddress)) {
-      logRouteError(
-        functionName,
-        'POST',
-        '/api/users',
-        'Valid email is required',
-        user
-      );
-      return NextResponse.json(
-        { success: false, message: 'Valid email is required' },
-        { status: 400 }
-      );
-    }
-
-    if (!profile || !profile.gender) {
-      logRouteError(
-        functionName,
-        'POST',
-        '/api/users',
-        'Gender is required',
-        user
-      );
-      return NextResponse.json(
-        { success: false, message: 'Gender is required' },
-        { status: 400 }
-      );
-    }
-
-    if (!password) {
-      logRouteError(
-        functionName,
-        'POST',
-        '/api/users',
-        'Password is required',
-        user
-      );
-      return NextResponse.json(
-        { success: false, message: 'Password is required' },
-        { status: 400 }
-      );
-    }
-
     const passwordValidation = validatePasswordStrength(password);
     if (!passwordValidation.isValid) {
       logRouteError(
@@ -333,30 +303,26 @@ export async function PUT(request: NextRequest) {
     const user = extractUserFromRequest(request);
 
     // ============================================================================
-    // STEP 1: Parse request body
+    // STEP 1: Parse and validate request body
     // ============================================================================
-    const body = await request.json();
-    const { _id, ...updateFields } = body;
-
-    // ============================================================================
-    // STEP 2: Validate ID
-    // ============================================================================
-    if (!_id) {
+    const validation = parseBody(
+      updateUserRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
       logRouteError(
         functionName,
         'PUT',
         '/api/users',
-        'User ID is required',
+        'Invalid request body',
         user
       );
-      return NextResponse.json(
-        { success: false, message: 'User ID is required' },
-        { status: 400 }
-      );
+      return validationErrorResponse(validation.error);
     }
+    const { _id, ...updateFields } = validation.data;
 
     // ============================================================================
-    // STEP 3: Process update
+    // STEP 2: Process update
     // ============================================================================
     try {
       const updatedUser = await updateUserHelper(_id, updateFields, request);
@@ -402,30 +368,26 @@ export async function DELETE(request: NextRequest) {
     const user = extractUserFromRequest(request);
 
     // ============================================================================
-    // STEP 1: Parse request body
-    // ============================================================================
-    const body = await request.json();
-    const { _id } = body;
-
-    // ============================================================================
-    // STEP 2: Validate ID
+    // STEP 1: Parse and validate request body
     // ============================================================================
-    if (!_id) {
+    const validation = parseBody(
+      deleteUserRoute,
+      await request.json().catch(() => null)
+    );
+    if (!validation.success) {
       logRouteError(
         functionName,
         'DELETE',
         '/api/users',
-        'User ID is required',
+        'Invalid request body',
         user
       );
-      return NextResponse.json(
-        { success: false, message: 'User ID is required' },
-        { status: 400 }
-      );
+      return validationErrorResponse(validation.error);
     }
+    const { _id } = validation.data;
 
     // ============================================================================
-    // STEP 3: Process deletion
+    // STEP 2: Process deletion
     // ============================================================================
     try {
       await deleteUserHelper(_id, request);
@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { vaultActivityLogRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -34,17 +39,22 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locId = searchParams.get('locationId'),
-        uid = searchParams.get('userId'),
-        cid = searchParams.get('cashierId'),
-        mid = searchParams.get('machineId'),
-        sid = searchParams.get('cashierShiftId'),
-        type = searchParams.get('type'),
-        start = searchParams.get('startDate'),
-        end = searchParams.get('endDate');
-      const limit = parseInt(searchParams.get('limit') || '50'),
-        skip = parseInt(searchParams.get('skip') || '0');
+      const parsed = parseQuery(vaultActivityLogRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const {
+        locationId: locId,
+        userId: uid,
+        cashierId: cid,
+        machineId: mid,
+        cashierShiftId: sid,
+        type,
+        startDate: start,
+        endDate: end,
+        limit,
+        skip,
+      } = parsed.data;
 
       if (!locId && !uid && !cid && !mid && !sid) {
         logRouteError(
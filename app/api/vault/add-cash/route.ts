@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { addVaultCashRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteCreate,
   logRouteError,
@@ -52,13 +57,22 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate request body
       // ============================================================================
-      const { source, amount, denominations, notes, bankDetails, machineIds } =
-        await request.json();
-      if (!source || !amount || !denominations)
-        return NextResponse.json(
-          { success: false, error: 'Missing required fields' },
-          { status: 400 }
+      const validation = parseBody(
+        addVaultCashRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        logRouteError(
+          functionName,
+          'POST',
+          '/api/vault/add-cash',
+          'Invalid request body',
+          user
         );
+        return validationErrorResponse(validation.error);
+      }
+      const { source, amount, denominations, notes, bankDetails, machineIds } =
+        validation.data;
 
       // ============================================================================
       // STEP 3: Check active vault shift and location permissions
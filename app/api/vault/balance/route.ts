@@ -3,6 +3,11 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { vaultBalanceRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -54,13 +59,11 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 2: Find active or last closed shift
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      if (!locationId)
-        return NextResponse.json(
-          { success: false, error: 'locationId is required' },
-          { status: 400 }
-        );
+      const query = parseQuery(vaultBalanceRoute, request);
+      if (!query.success) {
+        return validationErrorResponse(query.error);
+      }
+      const { locationId } = query.data;
 
       const activeShift = await VaultShiftModel.findOne({
         locationId,
@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { directOpenCashierShiftRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteCreate,
   logRouteError,
@@ -63,37 +68,41 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse and validate request
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const { locationId, cashierId, amount, denominations, notes } =
-        await request.json();
-      if (!locationId || !cashierId || amount === undefined || !denominations) {
+      const validation = parseBody(
+        directOpenCashierShiftRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/cashier-shift/direct-open',
-          'Missing fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { locationId, cashierId, amount, denominations, notes } =
+        validation.data;
 
-      const validation = validateDenominations(denominations);
-      if (!validation.valid || validation.total !== amount) {
+      const denominationValidation = validateDenominations(denominations);
+      if (
+        !denominationValidation.valid ||
+        denominationValidation.total !== amount
+      ) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/cashier-shift/direct-open',
-          `Denomination mismatch: $${validation.total} vs $${amount}`,
+          `Denomination mismatch: $${denominationValidation.total} vs $${amount}`,
           user
         );
         return NextResponse.json(
           {
             success: false,
-            error: `Denomination mismatch: $${validation.total} vs $${amount}`,
+            error: `Denomination mismatch: $${denominationValidation.total} vs $${amount}`,
           },
           { status: 400 }
         );
@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { forceCloseCashierShiftRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
@@ -15,11 +20,14 @@ import { calculateExpectedBalance } from '@/lib/helpers/vault/calculations';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
- * POST /api/vault/collection-session/finalize
+ * POST /api/vault/cashier-shift/force-close
  *
- * @body {string} sessionId - ID of the session to finalize (REQUIRED)
  * @body {string} locationId - ID of the location (REQUIRED)
- * @body {string} vaultShiftId - ID of the active vault shift (REQUIRED)
+ * @body {string} shiftId - ID of the shift to close
+ * @body {string} cashierId - Cashier whose open shift to close, without shiftId
+ * @body {number} physicalCount - Cash counted in the drawer (REQUIRED)
+ * @body {Array} denominations - Denomination breakdown of the count
+ * @body {string} notes - Review notes
  */
 export async function POST(request: NextRequest) {
   const startTime = Date.now();
@@ -51,33 +59,30 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse request
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const {
-        cashierId,
-        shiftId,
-        locationId,
-        denominations,
-        physicalCount,
-        notes,
-      } = await request.json();
-      if (
-        !locationId ||
-        physicalCount === undefined ||
-        (!cashierId && !shiftId)
-      ) {
+      const validation = parseBody(
+        forceCloseCashierShiftRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/cashier-shift/force-close',
-          'Missing fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const {
+        cashierId,
+        shiftId,
+        locationId,
+        denominations,
+        physicalCount,
+        notes,
+      } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate cashier shift
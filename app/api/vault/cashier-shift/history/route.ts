@@ -3,6 +3,11 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { cashierShiftHistoryRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -31,26 +36,19 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const cashierId = searchParams.get('cashierId'),
-        locationId = searchParams.get('locationId');
-      const limit = parseInt(searchParams.get('limit') || '50'),
-        skip = parseInt(searchParams.get('skip') || '0');
-      const varianceOnly = searchParams.get('variance') === 'true';
-
-      if (!cashierId) {
+      const parsed = parseQuery(cashierShiftHistoryRoute, request);
+      if (!parsed.success) {
         logRouteError(
           functionName,
           'GET',
           '/api/vault/cashier-shift/history',
-          'Missing cashierId',
+          'Invalid query parameters',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing cashierId' },
-          { status: 400 }
-        );
+        return validationErrorResponse(parsed.error);
       }
+      const { cashierId, locationId, limit, skip } = parsed.data;
+      const varianceOnly = parsed.data.variance === 'true';
 
       // ============================================================================
       // STEP 2: Enforce authorization
@@ -1,4 +1,9 @@
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { finalizeCollectionSessionRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
@@ -29,20 +34,21 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request
       // ============================================================================
-      const { sessionId, locationId, vaultShiftId } = await request.json();
-      if (!sessionId || !locationId || !vaultShiftId) {
+      const validation = parseBody(
+        finalizeCollectionSessionRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/collection-session/finalize',
-          'Missing parameters',
+          'Invalid request body',
           logUser
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing parameters' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { sessionId, locationId, vaultShiftId } = validation.data;
 
       // ============================================================================
       // STEP 2: Fetch and validate session
@@ -10,6 +10,15 @@
  * Supported POST actions: start | addEntry | removeEntry | cancel
  */
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  getCollectionSessionRoute,
+  updateCollectionSessionRoute,
+} from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -39,33 +48,26 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const vShiftId = searchParams.get('vaultShiftId'),
-        locId = searchParams.get('locationId'),
-        status = searchParams.get('status'),
-        type = searchParams.get('type') || 'soft_count',
-        isEOD = searchParams.get('isEndOfDay') === 'true';
-
-      if (!vShiftId || !locId) {
+      const parsed = parseQuery(getCollectionSessionRoute, request);
+      if (!parsed.success) {
         logRouteError(
           functionName,
           'GET',
           '/api/vault/collection-session',
-          'Vault Shift ID and Location ID required',
+          'Invalid query parameters',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Vault Shift ID and Location ID required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(parsed.error);
       }
+      const { vaultShiftId, locationId, status, type } = parsed.data;
+      const isEOD = parsed.data.isEndOfDay === 'true';
 
       // ============================================================================
       // STEP 2: Fetch collection session
       // ============================================================================
       const query: Record<string, unknown> = {
-        locationId: locId,
-        vaultShiftId: vShiftId,
+        locationId,
+        vaultShiftId,
         type,
         isEndOfDay: isEOD,
       };
@@ -131,35 +133,28 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request body
       // ============================================================================
-      const {
-        action,
-        locationId,
-        vaultShiftId,
-        machineId,
-        entryData,
-        sessionId,
-        type = 'soft_count',
-        isEndOfDay = false,
-      } = await request.json();
-      if (!locationId || !vaultShiftId) {
+      const validation = parseBody(
+        updateCollectionSessionRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/collection-session',
-          'Location and Vault Shift ID required',
+          'Invalid collection session data',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Location and Vault Shift ID required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const body = validation.data;
+      const { locationId, vaultShiftId, type, isEndOfDay } = body;
 
       let session;
       // ============================================================================
       // STEP 2: Handle "start" action
       // ============================================================================
-      if (action === 'start') {
+      if (body.action === 'start') {
         const existing = await VaultCollectionSession.findOne({
           locationId,
           vaultShiftId,
@@ -202,23 +197,11 @@ export async function POST(request: NextRequest) {
           user,
           duration
         );
-      } else if (action === 'addEntry') {
+      } else if (body.action === 'addEntry') {
         // ============================================================================
         // STEP 3: Handle "addEntry" action
         // ============================================================================
-        if (!sessionId || !entryData) {
-          logRouteError(
-            functionName,
-            'POST',
-            '/api/vault/collection-session',
-            'Session ID and Entry Data required',
-            user
-          );
-          return NextResponse.json(
-            { success: false, error: 'Session ID and Entry Data required' },
-            { status: 400 }
-          );
-        }
+        const { sessionId, entryData } = body;
         session = await VaultCollectionSession.findOne({ _id: sessionId });
         if (!session || session.status !== 'active') {
           logRouteError(
@@ -238,17 +221,10 @@ export async function POST(request: NextRequest) {
           (e: { machineId: string }) => e.machineId === entryData.machineId
         );
         const entry = {
-          machineId: entryData.machineId,
-          machineName: entryData.machineName,
-          source: entryData.source || 'manual',
-          totalAmount: entryData.totalAmount || 0,
-          denominations: entryData.denominations || [],
-          meters: entryData.meters || {},
-          expectedDrop: entryData.expectedDrop || 0,
-          variance: entryData.variance || 0,
-          notes: entryData.notes || '',
-          isEndOfDay: entryData.isEndOfDay || false,
-          collectedAt: entryData.collectedAt || new Date(),
+          ...entryData,
+          collectedAt: entryData.collectedAt
+            ? new Date(entryData.collectedAt)
+            : new Date(),
         };
 
         if (existingIdx >= 0) session.entries[existingIdx] = entry;
@@ -269,23 +245,11 @@ export async function POST(request: NextRequest) {
           user,
           duration
         );
-      } else if (action === 'removeEntry') {
+      } else if (body.action === 'removeEntry') {
         // ============================================================================
         // STEP 4: Handle "removeEntry" action
         // ============================================================================
-        if (!sessionId || !machineId) {
-          logRouteError(
-            functionName,
-            'POST',
-            '/api/vault/collection-session',
-            'Session ID and Machine ID required',
-            user
-          );
-          return NextResponse.json(
-            { success: false, error: 'Session ID and Machine ID required' },
-            { status: 400 }
-          );
-        }
+        const { sessionId, machineId } = body;
         session = await VaultCollectionSession.findOne({ _id: sessionId });
         if (!session) {
           logRouteError(
@@ -318,25 +282,12 @@ export async function POST(request: NextRequest) {
           user,
           duration
         );
-      } else if (action === 'cancel') {
+      } else {
         // ============================================================================
         // STEP 5: Handle "cancel" action
         // ============================================================================
-        if (!sessionId) {
-          logRouteError(
-            functionName,
-            'POST',
-            '/api/vault/collection-session',
-            'Session ID required',
-            user
-          );
-          return NextResponse.json(
-            { success: false, error: 'Session ID required' },
-            { status: 400 }
-          );
-        }
         session = await VaultCollectionSession.findOneAndUpdate(
-          { _id: sessionId },
+          { _id: body.sessionId },
           { status: 'cancelled' },
           { new: true }
         );
@@ -8,6 +8,15 @@ import {
   exportReportToCSV,
   generateEndOfDayReport,
 } from '@/app/api/lib/helpers/vault/endOfDay';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  exportEndOfDayReportRoute,
+  getEndOfDayReportRoute,
+} from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -31,22 +40,18 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate query params
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locId = searchParams.get('locationId'),
-        dateStr = searchParams.get('date');
-      if (!locId || !dateStr) {
+      const query = parseQuery(getEndOfDayReportRoute, request);
+      if (!query.success) {
         logRouteError(
           functionName,
           'GET',
           '/api/vault/end-of-day',
-          'locationId and date required',
+          'Invalid query parameters',
           logUser
         );
-        return NextResponse.json(
-          { error: 'locationId and date required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(query.error);
       }
+      const { locationId: locId, date: dateStr } = query.data;
 
       if (
         !(await canManageTransactions(
@@ -66,7 +71,10 @@ export async function GET(request: NextRequest) {
           'Forbidden',
           logUser
         );
-        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
       }
 
       // ============================================================================
@@ -119,24 +127,21 @@ export async function POST(request: NextRequest) {
 
   return withApiAuth(request, async ({ user }) => {
     try {
-      const {
-        locationId,
-        date: dateStr,
-        format = 'CSV',
-      } = await request.json();
-      if (!locationId || !dateStr) {
+      const validation = parseBody(
+        exportEndOfDayReportRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/end-of-day',
-          'locationId and date required',
+          'Invalid request body',
           logUser
         );
-        return NextResponse.json(
-          { error: 'locationId and date required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { locationId, date: dateStr, format } = validation.data;
 
       if (
         !(await canManageTransactions(
@@ -156,7 +161,10 @@ export async function POST(request: NextRequest) {
           'Forbidden',
           logUser
         );
-        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
       }
 
       const report = await generateEndOfDayReport(
@@ -12,6 +12,15 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createExpenseRoute,
+  listExpensesRoute,
+} from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -33,7 +42,6 @@ import VaultShiftModel from '@/app/api/lib/models/vaultShift';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
 import { generateMongoId } from '@/lib/utils/id';
 import { NextRequest, NextResponse } from 'next/server';
-import type { Denomination } from '@shared/types/vault';
 import type { VaultTransactionDocument } from '@shared/types';
 
 /**
@@ -64,34 +72,33 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse form data
+      // STEP 2: Parse and validate form data
       // ============================================================================
-      const formData = await request.formData();
-      const category = formData.get('category') as string;
-      const amount = parseFloat(formData.get('amount') as string);
-      const description = formData.get('description') as string;
-      const dateStr = formData.get('date') as string;
-      const denominationsStr = formData.get('denominations') as string;
-      const file = formData.get('file') as File | null;
-
-      if (!category || isNaN(amount)) {
-        logRouteError(functionName, 'POST', '/api/vault/expense', 'Missing required fields', user);
-        return NextResponse.json({ success: false, error: 'Missing required fields' }, { status: 400 });
-      }
-
-      let denominations: Denomination[] = [];
-      if (denominationsStr) {
-        try {
-          denominations = JSON.parse(denominationsStr)
-            .filter((denom: Denomination) => denom.quantity > 0)
-            .map((denom: Denomination) => ({
-              denomination: Number(denom.denomination) as Denomination['denomination'],
-              quantity: Math.max(0, Number(denom.quantity)),
-            }));
-        } catch {
-          console.error('[Expense POST] Denom parse error');
-        }
+      const formData = await request.formData().catch(() => null);
+      const validation = parseBody(
+        createExpenseRoute,
+        formData && Object.fromEntries(formData)
+      );
+      if (!validation.success) {
+        logRouteError(
+          functionName,
+          'POST',
+          '/api/vault/expense',
+          'Invalid expense data',
+          user
+        );
+        return validationErrorResponse(validation.error);
       }
+      const {
+        category,
+        amount,
+        denominations,
+        description,
+        date,
+        file,
+        bankDetails,
+        expenseDetails,
+      } = validation.data;
 
       // ============================================================================
       // STEP 3: Upload attachment (optional)
@@ -145,21 +152,8 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 6: Process expense details and create transaction
+      // STEP 6: Resolve repaired machines and create transaction
       // ============================================================================
-      let bankDetails: Record<string, unknown> | undefined;
-      let expenseDetails: Record<string, unknown> | undefined;
-      try {
-        bankDetails = formData.get('bankDetails')
-          ? JSON.parse(formData.get('bankDetails') as string)
-          : undefined;
-      } catch { /* ignore parse errors */ }
-      try {
-        expenseDetails = formData.get('expenseDetails')
-          ? JSON.parse(formData.get('expenseDetails') as string)
-          : undefined;
-      } catch { /* ignore parse errors */ }
-
       if (expenseDetails?.isMachineRepair && Array.isArray(expenseDetails?.machineIds)) {
         expenseDetails.machineDetails = await resolveExpenseMachineDetails(
           expenseDetails.machineIds as string[]
@@ -169,7 +163,7 @@ export async function POST(request: NextRequest) {
       const transactionDoc = new VaultTransactionModel({
         _id: await generateMongoId(),
         locationId: activeVaultShift.locationId,
-        timestamp: dateStr ? new Date(dateStr) : new Date(),
+        timestamp: date ? new Date(date) : new Date(),
         type: 'expense',
         from: { type: 'vault' },
         to: { type: 'external', id: category },
@@ -247,9 +241,12 @@ export async function GET(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Build query with location filter
+      // STEP 2: Parse query and build filter
       // ============================================================================
-      const { searchParams } = new URL(request.url);
+      const parsed = parseQuery(listExpensesRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
 
       const allowedLocs = await getUserLocationFilter(
         userPayload.assignedLicencees || [],
@@ -258,7 +255,10 @@ export async function GET(request: NextRequest) {
         userRoles
       );
 
-      const { query, error: queryError } = buildExpenseQuery(searchParams, allowedLocs);
+      const { query, error: queryError } = buildExpenseQuery(
+        parsed.data,
+        allowedLocs
+      );
       if (queryError) {
         logRouteError(functionName, 'GET', '/api/vault/expense', queryError, user);
         return NextResponse.json({ success: false, error: queryError }, { status: 403 });
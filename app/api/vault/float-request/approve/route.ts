@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { approveFloatRequestRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
@@ -52,28 +57,29 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse request body
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const {
-        requestId,
-        status,
-        approvedAmount,
-        approvedDenominations,
-        vmNotes,
-      } = await request.json();
-      if (!requestId || !status) {
+      const validation = parseBody(
+        approveFloatRequestRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/float-request/approve',
-          'Missing required fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing required fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const {
+        requestId,
+        status,
+        approvedAmount,
+        approvedDenominations,
+        vmNotes,
+      } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate request existence and status
@@ -3,6 +3,11 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { confirmFloatRequestRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
@@ -25,22 +30,23 @@ export async function POST(request: NextRequest) {
   return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
     try {
       // ============================================================================
-      // STEP 1: Parse request body
+      // STEP 1: Parse and validate request body
       // ============================================================================
-      const { requestId, notes } = await request.json();
-      if (!requestId) {
+      const validation = parseBody(
+        confirmFloatRequestRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/float-request/confirm',
-          'Missing requestId',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing requestId' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { requestId, notes } = validation.data;
 
       // ============================================================================
       // STEP 2: Validate float request
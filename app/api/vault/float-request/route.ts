@@ -12,6 +12,16 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  cancelFloatRequestRoute,
+  createFloatRequestRoute,
+  listFloatRequestsRoute,
+} from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteCreate,
@@ -23,7 +33,6 @@ import FloatRequestModel from '@/app/api/lib/models/floatRequest';
 import {
   buildFloatRequestQuery,
   fetchFloatRequestsWithDetails,
-  validateFloatRequestBody,
   getActiveVaultShift,
   createFloatRequestRecord,
   sendFloatRequestNotification,
@@ -53,23 +62,22 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
     try {
       // ============================================================================
-      // STEP 1: Parse query params and determine cashier filter
+      // STEP 1: Parse and validate query params, determine cashier filter
       // ============================================================================
       const normalizedRoles = userRoles.map(r => String(r).toLowerCase());
       const isVM = normalizedRoles.some(role =>
         ['developer', 'admin', 'manager', 'vault-manager'].includes(role)
       );
 
-      const { searchParams } = new URL(request.url);
-      const cashierIdFromParams = searchParams.get('cashierId');
-      const finalCashierId = isVM ? cashierIdFromParams : userPayload._id;
-      const locationId = searchParams.get('locationId');
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = Math.min(parseInt(searchParams.get('limit') || '20'), 100);
+      const parsed = parseQuery(listFloatRequestsRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const { cashierId, locationId, page, status, startDate, endDate } =
+        parsed.data;
+      const finalCashierId = isVM ? cashierId : userPayload._id;
+      const limit = Math.min(parsed.data.limit, 100);
       const skip = (page - 1) * limit;
-      const status = searchParams.get('status') || 'pending';
-      const startDate = searchParams.get('startDate');
-      const endDate = searchParams.get('endDate');
 
       // ============================================================================
       // STEP 2: Build query and fetch data
@@ -150,24 +158,22 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
-      const { type, amount, denominations, reason, locationId, cashierShiftId } =
-        body;
-
-      const validation = validateFloatRequestBody(body);
-      if (!validation.valid) {
+      const validation = parseBody(
+        createFloatRequestRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/float-request',
-          validation.error || 'Missing required fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: validation.error },
-          { status: validation.status || 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { type, amount, denominations, reason, locationId, cashierShiftId } =
+        validation.data;
 
       // ============================================================================
       // STEP 2: Fetch and validate active vault shift
@@ -197,7 +203,7 @@ export async function POST(request: NextRequest) {
         vaultShiftId: String(vaultShift._id),
         type,
         amount,
-        denominations: denominations || [],
+        denominations,
         reason,
       });
 
@@ -267,20 +273,21 @@ export async function DELETE(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate request body
       // ============================================================================
-      const { requestId } = await request.json();
-      if (!requestId) {
+      const validation = parseBody(
+        cancelFloatRequestRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'DELETE',
           '/api/vault/float-request',
-          'Request ID required',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Request ID required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { requestId } = validation.data;
 
       // ============================================================================
       // STEP 2: Fetch and validate float request
@@ -10,6 +10,11 @@ import {
   transformFloatRequestForResponse,
 } from '@/app/api/lib/helpers/vault/floatRequests';
 import { canEditFloatRequest } from '@/app/api/lib/helpers/vault/authorization';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { editLegacyFloatRequestRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteUpdate,
@@ -45,7 +50,7 @@ export async function GET(req: NextRequest) {
           user
         );
         return NextResponse.json(
-          { error: 'Float request ID is required' },
+          { success: false, error: 'Float request ID is required' },
           { status: 400 }
         );
       }
@@ -63,7 +68,7 @@ export async function GET(req: NextRequest) {
           user
         );
         return NextResponse.json(
-          { error: 'Float request not found' },
+          { success: false, error: 'Float request not found' },
           { status: 404 }
         );
       }
@@ -106,7 +111,7 @@ export async function GET(req: NextRequest) {
  * Main PUT handler for editing a float request
  *
  * @param {string} id - REQUIRED (path). The ID of the float request to update.
- * @body {number} requestedDenom - REQUIRED. The new denomination amount for the request.
+ * @body {Denomination[]} requestedDenom - REQUIRED. The new denomination breakdown for the request.
  */
 export async function PUT(req: NextRequest) {
   const startTime = Date.now();
@@ -130,25 +135,26 @@ export async function PUT(req: NextRequest) {
           user
         );
         return NextResponse.json(
-          { error: 'Float request ID is required' },
+          { success: false, error: 'Float request ID is required' },
           { status: 400 }
         );
       }
 
-      const body = await req.json();
-      if (!body.requestedDenom) {
+      const validation = parseBody(
+        editLegacyFloatRequestRoute,
+        await req.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'PUT',
           '/api/vault/float-requests/[id]',
-          'requestedDenom is required',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { error: 'requestedDenom is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { requestedDenom } = validation.data;
 
       // ============================================================================
       // STEP 2: Fetch and validate float request
@@ -163,7 +169,7 @@ export async function PUT(req: NextRequest) {
           user
         );
         return NextResponse.json(
-          { error: 'Float request not found' },
+          { success: false, error: 'Float request not found' },
           { status: 404 }
         );
       }
@@ -188,11 +194,14 @@ export async function PUT(req: NextRequest) {
           'Forbidden',
           user
         );
-        return NextResponse.json({ error: 'Forbidden' }, { status: 403 });
+        return NextResponse.json(
+          { success: false, error: 'Forbidden' },
+          { status: 403 }
+        );
       }
 
       const previousDenom = floatRequest.requestedDenom;
-      const updated = await editFloatRequest(requestId, body.requestedDenom);
+      const updated = await editFloatRequest(requestId, requestedDenom);
       if (!updated) {
         logRouteError(
           functionName,
@@ -211,7 +220,7 @@ export async function PUT(req: NextRequest) {
       // STEP 4: Log activity and return response
       // ============================================================================
       console.log(
-        `[Float Request PUT] Updated float request ${requestId} — denom: ${previousDenom} → ${body.requestedDenom}`
+        `[Float Request PUT] Updated float request ${requestId} — denom: ${previousDenom} → ${requestedDenom}`
       );
       logActivity({
         action: 'update',
@@ -230,11 +239,11 @@ export async function PUT(req: NextRequest) {
             {
               field: 'requestedDenom',
               oldValue: previousDenom,
-              newValue: body.requestedDenom,
+              newValue: requestedDenom,
             },
           ],
           previousData: { requestedDenom: previousDenom },
-          newData: { requestedDenom: body.requestedDenom },
+          newData: { requestedDenom },
         },
       }).catch(err =>
         console.error('[Float Request PUT] Activity log failed:', err)
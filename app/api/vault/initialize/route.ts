@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { initializeVaultRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteCreate,
   logRouteError,
@@ -56,22 +61,22 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate input
       // ============================================================================
-      const body = await request.json();
-      const { locationId, notes } = body;
-      let { openingBalance, denominations } = body;
-      if (!locationId) {
+      const validation = parseBody(
+        initializeVaultRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/initialize',
-          'locationId required',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'locationId required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { locationId, notes } = validation.data;
+      let { openingBalance, denominations } = validation.data;
 
       // ============================================================================
       // STEP 3: Determine opening balance and denominations
@@ -3,6 +3,11 @@
  */
 
 import { getUserLocationFilter } from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { vaultMetricsBreakdownRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -11,7 +16,6 @@ import {
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
-import type { TimePeriod } from '@/app/api/lib/types';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { NextRequest, NextResponse } from 'next/server';
 import type { LocationDocument } from '@/lib/types/common';
@@ -31,24 +35,20 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
     try {
       // ============================================================================
-      // STEP 1: Parse query params
+      // STEP 1: Parse and validate query params
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      const type = searchParams.get('type');
-      if (!locationId || !type) {
+      const parsed = parseQuery(vaultMetricsBreakdownRoute, request);
+      if (!parsed.success) {
         logRouteError(
           functionName,
           'GET',
           '/api/vault/metrics/breakdown',
-          'locationId and type are required',
+          'Invalid query parameters',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'locationId and type are required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(parsed.error);
       }
+      const { locationId, type } = parsed.data;
 
       // ============================================================================
       // STEP 2: Enforce permissions
@@ -85,15 +85,13 @@ export async function GET(request: NextRequest) {
         { gameDayOffset: 1 }
       ).lean<LocationDocument>();
       const gameDayOffset = locationInfo?.gameDayOffset ?? 8;
-      const timePeriod = searchParams.get('timePeriod') || 'Today';
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
+      const { timePeriod, startDate, endDate } = parsed.data;
 
       const { rangeStart, rangeEnd } = getGamingDayRangeForPeriod(
-        timePeriod as TimePeriod,
+        timePeriod,
         gameDayOffset,
-        startDateParam ? new Date(startDateParam) : undefined,
-        endDateParam ? new Date(endDateParam) : undefined
+        startDate ? new Date(startDate) : undefined,
+        endDate ? new Date(endDate) : undefined
       );
 
       // ============================================================================
@@ -10,7 +10,11 @@ import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Meters } from '@/app/api/lib/models/meters';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
-import type { TimePeriod } from '@/app/api/lib/types';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { vaultMetricsRoute } from '@/app/api/lib/routeSchemas/vault';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import {
   getMoneyInScale,
@@ -37,15 +41,13 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
     try {
       // ============================================================================
-      // STEP 1: Parse and validate location
+      // STEP 1: Parse and validate query params
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      if (!locationId)
-        return NextResponse.json(
-          { success: false, error: 'Location ID is required' },
-          { status: 400 }
-        );
+      const parsed = parseQuery(vaultMetricsRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const { locationId } = parsed.data;
 
       // ============================================================================
       // STEP 2: Enforce permissions
@@ -75,15 +77,13 @@ export async function GET(request: NextRequest) {
         { gameDayOffset: 1 }
       ).lean<LocationDocument>();
       const gameDayOffset = locationInfo?.gameDayOffset ?? 8;
-      const timePeriod = searchParams.get('timePeriod') || 'Today';
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
+      const { timePeriod, startDate, endDate } = parsed.data;
 
       const { rangeStart, rangeEnd } = getGamingDayRangeForPeriod(
-        timePeriod as TimePeriod,
+        timePeriod,
         gameDayOffset,
-        startDateParam ? new Date(startDateParam) : undefined,
-        endDateParam ? new Date(endDateParam) : undefined
+        startDate ? new Date(startDate) : undefined,
+        endDate ? new Date(endDate) : undefined
       );
 
       // ============================================================================
@@ -9,6 +9,15 @@ import {
   getRecentNotifications,
   markNotificationsAsRead,
 } from '@/lib/helpers/vault/notifications';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  listVaultNotificationsRoute,
+  updateVaultNotificationsRoute,
+} from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteUpdate,
@@ -32,21 +41,18 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate query params
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      if (!locationId) {
+      const parsed = parseQuery(listVaultNotificationsRoute, request);
+      if (!parsed.success) {
         logRouteError(
           functionName,
           'GET',
           '/api/vault/notifications',
-          'Missing locationId',
+          'Invalid query parameters',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing locationId' },
-          { status: 400 }
-        );
+        return validationErrorResponse(parsed.error);
       }
+      const { locationId } = parsed.data;
 
       // ============================================================================
       // STEP 2: Fetch notifications and counts
@@ -102,42 +108,29 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 1: Parse and validate body
       // ============================================================================
-      const body = await request.json();
-      const { action, notificationIds } = body;
-
-      if (!action || !notificationIds || !Array.isArray(notificationIds)) {
+      const validation = parseBody(
+        updateVaultNotificationsRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/notifications',
-          'Missing required fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing required fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { action, notificationIds } = validation.data;
 
       // ============================================================================
       // STEP 2: Process notification action
       // ============================================================================
       if (action === 'mark_read') {
         await markNotificationsAsRead(notificationIds);
-      } else if (action === 'dismiss') {
-        await dismissNotifications(notificationIds, userPayload._id as string);
       } else {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/vault/notifications',
-          'Invalid action',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'Invalid action' },
-          { status: 400 }
-        );
+        await dismissNotifications(notificationIds, userPayload._id as string);
       }
 
       const duration = Date.now() - startTime;
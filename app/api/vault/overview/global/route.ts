@@ -4,6 +4,12 @@
 
 import { GamingLocations } from '@/app/api/lib/models/gaminglocations';
 import { Meters } from '@/app/api/lib/models/meters';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { globalVaultOverviewRoute } from '@/app/api/lib/routeSchemas/vault';
+import { NOT_DELETED_FILTER } from '@/app/api/lib/utils/softDeleteFilters';
 import { getGamingDayRangeForPeriod } from '@/lib/utils/gamingDayRange';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import {
@@ -69,15 +75,17 @@ export async function GET(request: NextRequest) {
 
     try {
       // ============================================================================
-      // STEP 2: Parse query params
+      // STEP 2: Parse and validate query params
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const licenceeId =
-        searchParams.get('licenceeId') || searchParams.get('licencee');
+      const parsed = parseQuery(globalVaultOverviewRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const licenceeId = parsed.data.licenceeId || parsed.data.licencee;
 
       const locationQuery: Record<string, unknown> = {
         membershipEnabled: true,
-        deletedAt: { $lt: new Date('2025-01-01') },
+        ...NOT_DELETED_FILTER,
       };
       if (licenceeId && licenceeId !== 'all')
         locationQuery['rel.licencee'] = licenceeId;
@@ -186,15 +194,13 @@ export async function GET(request: NextRequest) {
         });
       });
 
-      const timePeriod = searchParams.get('timePeriod') || 'Today';
-      const startDateParam = searchParams.get('startDate');
-      const endDateParam = searchParams.get('endDate');
+      const { timePeriod, startDate, endDate } = parsed.data;
 
       const { rangeStart, rangeEnd } = getGamingDayRangeForPeriod(
         timePeriod,
         8,
-        startDateParam ? new Date(startDateParam) : undefined,
-        endDateParam ? new Date(endDateParam) : undefined
+        startDate ? new Date(startDate) : undefined,
+        endDate ? new Date(endDate) : undefined
       );
 
       // ============================================================================
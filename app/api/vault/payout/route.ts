@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { createVaultPayoutRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteCreate,
   logRouteError,
@@ -58,9 +63,22 @@ export async function POST(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse and validate body
+      // STEP 2: Parse and validate request body
       // ============================================================================
-      const body = await request.json();
+      const validation = parseBody(
+        createVaultPayoutRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        logRouteError(
+          functionName,
+          'POST',
+          '/api/vault/payout',
+          'Invalid payout data',
+          user
+        );
+        return validationErrorResponse(validation.error);
+      }
       const {
         cashierShiftId,
         type,
@@ -70,33 +88,7 @@ export async function POST(request: NextRequest) {
         printedAt,
         machineId,
         reason,
-      } = body;
-      if (!cashierShiftId || !type || amount === undefined) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/vault/payout',
-          'Missing fields',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'Missing fields' },
-          { status: 400 }
-        );
-      }
-      if (amount <= 0) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/vault/payout',
-          'Amount must be positive',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'Amount must be positive' },
-          { status: 400 }
-        );
-      }
+      } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate cashier shift
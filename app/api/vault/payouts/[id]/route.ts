@@ -12,13 +12,17 @@ import {
   updatePayout,
   transformPayoutForResponse,
 } from '@/app/api/lib/helpers/vault/payouts';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { updateCashDeskPayoutRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteUpdate,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
-import type { UpdatePayoutRequest } from '@/shared/types/vault';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
@@ -112,7 +116,9 @@ export async function GET(req: NextRequest) {
  * Main PUT handler for updating a payout
  *
  * @param {string} id - REQUIRED (path). The ID of the payout to update.
- * @body {UpdatePayoutRequest} data - The updated payout data fields.
+ * @body {number} amount - New amount
+ * @body {string} notes - New notes
+ * @body {string} status - New status
  */
 export async function PUT(req: NextRequest) {
   const startTime = Date.now();
@@ -141,7 +147,21 @@ export async function PUT(req: NextRequest) {
         );
       }
 
-      const body = (await req.json()) as UpdatePayoutRequest;
+      const validation = parseBody(
+        updateCashDeskPayoutRoute,
+        await req.json().catch(() => null)
+      );
+      if (!validation.success) {
+        logRouteError(
+          functionName,
+          'PUT',
+          '/api/vault/payouts/[id]',
+          'Invalid payout data',
+          user
+        );
+        return validationErrorResponse(validation.error);
+      }
+      const body = validation.data;
       console.log(
         `[Payout PUT] Request — payoutId: ${payoutId}, fields: ${Object.keys(body).join(', ')}`
       );
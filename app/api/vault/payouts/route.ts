@@ -3,6 +3,11 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listVaultPayoutsRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -52,26 +57,21 @@ export async function GET(request: NextRequest) {
       }
 
       // ============================================================================
-      // STEP 2: Parse query params
+      // STEP 2: Parse and validate query params
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      if (!locationId) {
+      const parsed = parseQuery(listVaultPayoutsRoute, request);
+      if (!parsed.success) {
         logRouteError(
           functionName,
           'GET',
           '/api/vault/payouts',
-          'Location ID is required',
+          'Invalid query parameters',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Location ID is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(parsed.error);
       }
-
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = Math.min(parseInt(searchParams.get('limit') || '20'), 100);
+      const { locationId, page, search, type } = parsed.data;
+      const limit = Math.min(parsed.data.limit, 100);
       const skip = (page - 1) * limit;
       const search = searchParams.get('search');
       const type = searchParams.get('type');
@@ -80,7 +80,7 @@ export async function GET(request: NextRequest) {
       // STEP 3: Build query
       // ============================================================================
       const query: Record<string, unknown> = { locationId };
-      if (type && type !== 'all') query.type = type;
+      if (type !== 'all') query.type = type;
       if (search) {
         const sr = { $regex: search, $options: 'i' };
         const orArray: Array<Record<string, unknown>> = [
@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { reconcileVaultRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteError,
   extractUserFromRequest,
@@ -57,8 +62,22 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate body
       // ============================================================================
+      const validation = parseBody(
+        reconcileVaultRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
+        logRouteError(
+          functionName,
+          'POST',
+          '/api/vault/reconcile',
+          'Invalid request body',
+          user
+        );
+        return validationErrorResponse(validation.error);
+      }
       const { vaultShiftId, newBalance, denominations, reason, comment } =
-        await request.json();
+        validation.data;
       const finalDesc = (reason || comment || '').trim();
 
       // ============================================================================
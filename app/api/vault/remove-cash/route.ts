@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { removeVaultCashRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteCreate,
   logRouteError,
@@ -58,20 +63,21 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate body
       // ============================================================================
-      const { reason, amount, denominations, notes } = await request.json();
-      if (!reason || !amount || !denominations) {
+      const validation = parseBody(
+        removeVaultCashRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/remove-cash',
-          'Missing required fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing required fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { reason, amount, denominations, notes } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate vault shift
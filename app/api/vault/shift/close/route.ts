@@ -3,6 +3,11 @@
  */
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { closeVaultShiftRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   extractUserFromRequest,
@@ -58,21 +63,21 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate body
       // ============================================================================
-      const { vaultShiftId, closingBalance, denominations } =
-        await request.json();
-      if (!vaultShiftId || closingBalance === undefined || !denominations) {
+      const validation = parseBody(
+        closeVaultShiftRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/shift/close',
-          'Missing fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { vaultShiftId, closingBalance, denominations } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate denominations
@@ -169,24 +174,10 @@ export async function POST(request: NextRequest) {
           vaultShift.openedAt,
           vaultShift.locationId
         );
-      const mappedDenoms = denominations.map(
-        (d: {
-          denomination: number | string;
-          count?: number;
-          quantity?: number;
-        }) => ({
-          denomination:
-            typeof d.denomination === 'string'
-              ? parseInt(d.denomination.replace('$', ''))
-              : d.denomination,
-          quantity: d.count ?? d.quantity ?? 0,
-        })
-      );
-
       vaultShift.status = 'closed';
       vaultShift.closedAt = attrDate;
       vaultShift.closingBalance = closingBalance;
-      vaultShift.closingDenominations = mappedDenoms;
+      vaultShift.closingDenominations = denominations;
       vaultShift.updatedAt = now;
       await vaultShift.save();
 
@@ -199,7 +190,7 @@ export async function POST(request: NextRequest) {
         from: { type: 'vault' },
         to: { type: 'external' },
         amount: closingBalance,
-        denominations: mappedDenoms,
+        denominations,
         vaultBalanceBefore: closingBalance,
         vaultBalanceAfter: 0,
         vaultShiftId,
@@ -4,6 +4,11 @@
 
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
 import { logActivity } from '@/app/api/lib/helpers/activityLogger';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { recordSoftCountRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteCreate,
   logRouteError,
@@ -60,20 +65,21 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate body
       // ============================================================================
-      const { amount, denominations, notes, isEndOfDay } = await request.json();
-      if (!amount || !denominations) {
+      const validation = parseBody(
+        recordSoftCountRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/soft-counts',
-          'Missing required fields',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Missing required fields' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { amount, denominations, notes, isEndOfDay } = validation.data;
 
       // ============================================================================
       // STEP 3: Validate vault shift
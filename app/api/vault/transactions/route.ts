@@ -3,6 +3,11 @@
  */
 
 import { getUserLocationFilter } from '@/app/api/lib/helpers/licenceeFilter';
+import {
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { listVaultTransactionsRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteFetch,
   logRouteError,
@@ -68,10 +73,12 @@ export async function GET(request: NextRequest) {
         );
       }
 
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = Math.min(parseInt(searchParams.get('limit') || '20'), 100);
+      const parsed = parseQuery(listVaultTransactionsRoute, request);
+      if (!parsed.success) {
+        return validationErrorResponse(parsed.error);
+      }
+      const { locationId, page, type, status, search } = parsed.data;
+      const limit = Math.min(parsed.data.limit, 100);
       const skip = (page - 1) * limit;
 
       const allowedLocationIds = await getUserLocationFilter(
@@ -105,9 +112,6 @@ export async function GET(request: NextRequest) {
         query.locationId = locationId;
       }
 
-      const type = searchParams.get('type');
-      const status = searchParams.get('status');
-      const search = searchParams.get('search');
 
       if (type && type !== 'all')
         query.type = type.includes(',') ? { $in: type.split(',') } : type;
@@ -14,12 +14,16 @@ import { InterLocationTransferModel } from '@/app/api/lib/models/interLocationTr
 import VaultShiftModel from '@/app/api/lib/models/vaultShift';
 import VaultTransactionModel from '@/app/api/lib/models/vaultTransaction';
 import { generateMongoId } from '@/lib/utils/id';
+import {
+  parseBody,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import { approveTransferRoute } from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteUpdate,
   logRouteError,
   extractUserFromRequest,
 } from '@/app/api/lib/utils/routeLogger';
-import type { ApproveInterLocationTransferRequest } from '@/shared/types/vault';
 import { NextRequest, NextResponse } from 'next/server';
 
 /**
@@ -60,22 +64,21 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate request body
       // ============================================================================
-      const body: ApproveInterLocationTransferRequest = await request.json();
-      const { transferId, approved, notes } = body;
-
-      if (!transferId) {
+      const validation = parseBody(
+        approveTransferRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/transfers/approve',
-          'Transfer ID is required',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Transfer ID is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { transferId, approved, notes } = validation.data;
 
       // ============================================================================
       // STEP 3: Find and validate transfer
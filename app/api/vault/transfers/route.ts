@@ -5,8 +5,16 @@
 import { getUserLocationFilter } from '@/app/api/lib/helpers/licenceeFilter';
 import { InterLocationTransferModel } from '@/app/api/lib/models/interLocationTransfer';
 import { generateMongoId } from '@/lib/utils/id';
-import type { CreateInterLocationTransferRequest } from '@/shared/types/vault';
 import { withApiAuth } from '@/app/api/lib/helpers/apiWrapper';
+import {
+  parseBody,
+  parseQuery,
+  validationErrorResponse,
+} from '@/app/api/lib/routeSchemas/common';
+import {
+  createTransferRoute,
+  listTransfersRoute,
+} from '@/app/api/lib/routeSchemas/vault';
 import {
   logRouteCreate,
   logRouteFetch,
@@ -58,40 +66,22 @@ export async function POST(request: NextRequest) {
       // ============================================================================
       // STEP 2: Parse and validate body
       // ============================================================================
-      const body: CreateInterLocationTransferRequest = await request.json();
-      const { fromLocationId, toLocationId, amount, denominations, notes } =
-        body;
-
-      if (!fromLocationId || !toLocationId || !amount || !denominations) {
-        logRouteError(
-          functionName,
-          'POST',
-          '/api/vault/transfers',
-          'Missing required fields',
-          user
-        );
-        return NextResponse.json(
-          { success: false, error: 'Missing required fields' },
-          { status: 400 }
-        );
-      }
-
-      if (fromLocationId === toLocationId) {
+      const validation = parseBody(
+        createTransferRoute,
+        await request.json().catch(() => null)
+      );
+      if (!validation.success) {
         logRouteError(
           functionName,
           'POST',
           '/api/vault/transfers',
-          'Source and destination locations must be different',
+          'Invalid request body',
           user
         );
-        return NextResponse.json(
-          {
-            success: false,
-            error: 'Source and destination locations must be different',
-          },
-          { status: 400 }
-        );
+        return validationErrorResponse(validation.error);
       }
+      const { fromLocationId, toLocationId, amount, denominations, notes } =
+        validation.data;
 
       // ============================================================================
       // STEP 3: Validate location access
@@ -196,23 +186,20 @@ export async function GET(request: NextRequest) {
   return withApiAuth(request, async ({ user: userPayload, userRoles }) => {
     try {
       // ============================================================================
-      // STEP 1: Validate parameters
+      // STEP 1: Parse and validate query parameters
       // ============================================================================
-      const { searchParams } = new URL(request.url);
-      const locationId = searchParams.get('locationId');
-      if (!locationId) {
+      const parsed = parseQuery(listTransfersRoute, request);
+      if (!parsed.success) {
         logRouteError(
           functionName,
           'GET',
           '/api/vault/transfers',
-          'Location ID is required',
+          'Invalid query parameters',
           user
         );
-        return NextResponse.json(
-          { success: false, error: 'Location ID is required' },
-          { status: 400 }
-        );
+        return validationErrorResponse(parsed.error);
       }
+      const { locationId, page } = parsed.data;
 
       // ============================================================================
       // STEP 2: Validate location access
@@ -244,8 +231,7 @@ export async function GET(request: NextRequest) {
       // ============================================================================
       // STEP 3: Fetch transfers
       // ============================================================================
-      const page = parseInt(searchParams.get('page') || '1');
-      const limit = Math.min(parseInt(searchParams.get('limit') || '20'), 100);
+      const limit = Math.min(parsed.data.limit, 100);
       const skip = (page - 1) * limit;
 
       const query = {
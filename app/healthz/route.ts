@@ -0,0 +1,34 @@
+/**
+ * Liveness Route
+ *
+ * GET /healthz - Whether this instance is up and reaches the database (see
+ * app/api/lib/helpers/healthChecks.ts). 200 when the database answers a
+ * ping, 503 otherwise. Public, for load balancer and uptime probes.
+ *
+ * @module app/healthz/route
+ */
+
+import {
+  getLivenessReport,
+  healthHttpStatus,
+} from '@/app/api/lib/helpers/healthChecks';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import { NextResponse } from 'next/server';
+
+export const dynamic = 'force-dynamic';
+export const revalidate = 0;
+export const runtime = 'nodejs';
+
+export async function GET() {
+  const report = await getLivenessReport({ connect: connectDB });
+  if (report.status === 'fail') {
+    console.error(
+      '[GET /healthz] Unhealthy:',
+      report.checks.map(check => check.message).join('; ')
+    );
+  }
+  return NextResponse.json(report, {
+    status: healthHttpStatus(report),
+    headers: { 'Cache-Control': 'no-store' },
+  });
+}
@@ -0,0 +1,18 @@
+/**
+ * OpenAPI Route
+ *
+ * GET /openapi.json - The OpenAPI 3.0 document for the HTTP API, generated
+ * from the route schemas in app/api/lib/routeSchemas. Public, so
+ * API clients and code generators can fetch it without a session.
+ *
+ * @module app/openapi.json/route
+ */
+
+import { buildOpenApiDocument } from '@/app/api/lib/helpers/openapi';
+import { NextResponse } from 'next/server';
+
+export const runtime = 'nodejs';
+
+export async function GET() {
+  return NextResponse.json(buildOpenApiDocument());
+}
@@ -0,0 +1,45 @@
+/**
+ * Readiness Route
+ *
+ * GET /readyz - Whether this instance should receive traffic: database
+ * connectivity, core collections, declared indexes and pre-aggregation
+ * freshness (see app/api/lib/helpers/healthChecks.ts). Public, for load
+ * balancer and monitoring probes.
+ *
+ * Query params:
+ * @param strict {boolean} Optional. `1` also answers 503 on warnings (missing
+ *                         indexes, stale pre-aggregations). Defaults to 0.
+ *
+ * Responds 200 unless a check fails (503); the body lists every check.
+ *
+ * @module app/readyz/route
+ */
+
+import {
+  getReadinessReport,
+  healthHttpStatus,
+} from '@/app/api/lib/helpers/healthChecks';
+import { connectDB } from '@/app/api/lib/middleware/db';
+import { NextRequest, NextResponse } from 'next/server';
+
+export const dynamic = 'force-dynamic';
+export const revalidate = 0;
+export const runtime = 'nodejs';
+
+export async function GET(request: NextRequest) {
+  const strict = request.nextUrl.searchParams.get('strict') === '1';
+  const report = await getReadinessReport({ connect: connectDB });
+  if (report.status !== 'ok') {
+    console.warn(
+      `[GET /readyz] ${report.status}:`,
+      report.checks
+        .filter(check => check.status !== 'ok')
+        .map(check => `${check.name}: ${check.message}`)
+        .join('; ')
+    );
+  }
+  return NextResponse.json(report, {
+    status: healthHttpStatus(report, strict),
+    headers: { 'Cache-Control': 'no-store' },
+  });
+}
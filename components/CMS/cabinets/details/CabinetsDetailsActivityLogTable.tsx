@@ -13,6 +13,7 @@
  * - Event code input for cursor seek (jump to first page where code appears)
  * - Server-side PaginationControls
  * - Success/failure indicators on sequence steps
+ * - SAS exception names and severities next to event codes
  */
 
 import { FC, Fragment, useEffect, useMemo, useRef, useState } from 'react';
@@ -38,6 +39,10 @@ import { Search } from 'lucide-react';
 
 import PaginationControls from '@/components/shared/ui/PaginationControls';
 import CabinetsDetailsActivityLogSkeleton from '@/components/CMS/cabinets/details/CabinetsDetailsActivityLogSkeleton';
+import type {
+  SasExceptionInfo,
+  SasSeverity,
+} from '@/lib/utils/sas/exceptionCodes';
 
 // ============================================================================
 // Types
@@ -76,6 +81,14 @@ export type CabinetsDetailsMachineEvent = {
   eventType?: string;
   eventLogLevel?: string;
   eventSuccess?: boolean;
+  /** Decoded SAS exception code, added by the events API */
+  sasEvent?: SasExceptionInfo | null;
+};
+
+const SAS_SEVERITY_CLASSES: Record<SasSeverity, string> = {
+  info: 'text-muted-foreground',
+  warning: 'text-amber-600',
+  critical: 'text-red-600',
 };
 
 type ActivityLogFilters = {
@@ -693,6 +706,15 @@ export const CabinetsDetailsActivityLogTable: FC<
                         }`}
                       >
                         {row.command || '00'}
+                        {row.sasEvent && (
+                          <div
+                            className={`font-sans text-xs ${
+                              SAS_SEVERITY_CLASSES[row.sasEvent.severity]
+                            }`}
+                          >
+                            {row.sasEvent.name}
+                          </div>
+                        )}
                       </TableCell>
                       <TableCell>{formatDate(row.date)}</TableCell>
                     </TableRow>
@@ -752,6 +774,20 @@ export const CabinetsDetailsActivityLogTable: FC<
                       {row.command || '00'}
                     </span>
                   </div>
+                  {row.sasEvent && (
+                    <div className="flex justify-between">
+                      <span className="font-medium text-foreground">
+                        SAS Event
+                      </span>
+                      <span
+                        className={`text-right text-sm ${
+                          SAS_SEVERITY_CLASSES[row.sasEvent.severity]
+                        }`}
+                      >
+                        {row.sasEvent.name}
+                      </span>
+                    </div>
+                  )}
                   <div className="flex justify-between">
                     <span className="font-medium text-foreground">Date</span>
                     <span className="text-sm font-medium">
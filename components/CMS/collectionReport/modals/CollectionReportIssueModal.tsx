@@ -88,6 +88,13 @@ const issueTypeConfig = {
     color: 'secondary',
     description: "Machine collection times don't match collection timestamps",
   },
+  sas_meters_mismatch: {
+    title: 'SAS Meters Mismatch',
+    icon: Database,
+    color: 'warning',
+    description:
+      "Stored SAS drop or cancelled credits don't match the raw meters",
+  },
 };
 
 export function CollectionReportIssueModal({
@@ -149,6 +156,8 @@ export function CollectionReportIssueModal({
         return "The collection history timestamp will be updated to match the collection's actual timestamp";
       case 'machine_time_mismatch':
         return "The machine's collection and previous collection times will be updated";
+      case 'sas_meters_mismatch':
+        return 'The SAS drop, cancelled credits and gross will be recalculated from the meters in the SAS time window';
       default:
         return 'The issue will be corrected based on the actual collection data';
     }
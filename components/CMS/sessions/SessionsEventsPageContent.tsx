@@ -881,6 +881,19 @@ export function SessionsEventsPageContent({
                     </td>
                     <td className="p-4 text-center font-mono text-sm text-gray-500">
                       {event.command || '-'}
+                      {event.sasEvent && (
+                        <div
+                          className={`font-sans text-xs ${
+                            event.sasEvent.severity === 'critical'
+                              ? 'text-red-600'
+                              : event.sasEvent.severity === 'warning'
+                                ? 'text-amber-600'
+                                : 'text-gray-500'
+                          }`}
+                        >
+                          {event.sasEvent.name}
+                        </div>
+                      )}
                     </td>
                     <td className="p-4 text-center text-sm text-gray-600">
                       {event.gameName || '-'}
@@ -1021,6 +1034,11 @@ export function SessionsEventsPageContent({
                   <span className="font-bold text-gray-800">
                     {event.command || '-'}
                   </span>
+                  {event.sasEvent && (
+                    <span className="text-gray-600">
+                      {event.sasEvent.name}
+                    </span>
+                  )}
                 </div>
                 <div className="flex flex-col gap-0.5">
                   <span className="text-gray-500">Game</span>
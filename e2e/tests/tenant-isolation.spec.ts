@@ -0,0 +1,241 @@
+import { test, expect, type APIRequestContext } from '@playwright/test';
+
+/**
+ * Tenant Isolation Tests
+ * ──────────────────────
+ * Licencee scoping (always runs): for one licencee — TENANT_LICENCEE_ID, or
+ * the first licencee the signed-in user can see — the report, cabinet and
+ * analytics pipelines only return that licencee's locations.
+ *
+ * Tenant isolation (pinned server only): a deployment pinned to one licencee
+ * (TENANT_LICENCEE_ID) never returns another licencee's data through the
+ * HTTP API: foreign licencee params, foreign location ids and raw collection
+ * routes are rejected, and unfiltered requests only return the tenant's
+ * locations.
+ *
+ * The pinned checks need the dev server started with TENANT_LICENCEE_ID and
+ * the same value in the test environment, plus FOREIGN_LICENCEE_ID (any
+ * other licencee) and FOREIGN_LOCATION_ID (one of its locations) for the
+ * foreign id checks.
+ */
+
+const TENANT = process.env.TENANT_LICENCEE_ID;
+const FOREIGN = process.env.FOREIGN_LICENCEE_ID || 'foreign-licencee';
+const FOREIGN_LOCATION = process.env.FOREIGN_LOCATION_ID;
+
+type LocationRow = { _id: string; rel?: { licencee?: string | string[] } };
+
+function belongsTo(location: LocationRow, licencee: string): boolean {
+  const rel = location.rel?.licencee;
+  return Array.isArray(rel)
+    ? rel.map(String).includes(licencee)
+    : String(rel) === licencee;
+}
+
+async function getJson<T>(request: APIRequestContext, url: string) {
+  const response = await request.get(url);
+  expect(response.ok(), `${url} -> ${response.status()}`).toBe(true);
+  return (await response.json()) as T;
+}
+
+/** Ids of every location (archived included) belonging to the licencee */
+async function licenceeLocationIds(
+  request: APIRequestContext,
+  licencee: string
+): Promise<Set<string>> {
+  const body = await getJson<{ locations: LocationRow[] }>(
+    request,
+    `/api/locations?licencee=${licencee}&archived=true`
+  );
+  return new Set(body.locations.map(location => String(location._id)));
+}
+
+/**
+ * Location ids the report, cabinet and analytics pipelines return, keyed by
+ * pipeline; unfiltered when no licencee is given.
+ */
+async function pipelineLocationIds(
+  request: APIRequestContext,
+  licencee?: string
+): Promise<Record<string, string[]>> {
+  const filter = licencee ? `licencee=${licencee}&` : '';
+  const report = await getJson<{ data: Array<{ location: string }> }>(
+    request,
+    `/api/reports/locations?${filter}timePeriod=7d&showAllLocations=true&limit=100`
+  );
+  const cabinets = await getJson<{ data: Array<{ locationId: string }> }>(
+    request,
+    `/api/cabinets/aggregation?${filter}timePeriod=Today&limit=100`
+  );
+  const machines = await getJson<{ data: Array<{ gamingLocation?: string }> }>(
+    request,
+    `/api/machines?${filter}limit=100`
+  );
+  const returned: Record<string, string[]> = {
+    'reports/locations': report.data.map(row => String(row.location)),
+    'cabinets/aggregation': cabinets.data.map(row => String(row.locationId)),
+    machines: machines.data
+      .map(row => row.gamingLocation)
+      .filter((id): id is string => Boolean(id)),
+  };
+  if (licencee) {
+    // Top locations always need a licencee
+    const analytics = await getJson<{ topLocations: Array<{ id: string }> }>(
+      request,
+      `/api/analytics/locations?licencee=${licencee}`
+    );
+    returned['analytics/locations'] = analytics.topLocations.map(row =>
+      String(row.id)
+    );
+  }
+  return returned;
+}
+
+test.describe('Licencee scoping', () => {
+  test('report, cabinet and analytics pipelines stay inside the licencee', async ({
+    page,
+  }) => {
+    let licencee = TENANT;
+    if (!licencee) {
+      const body = await getJson<{ licencees: Array<{ _id: string }> }>(
+        page.request,
+        '/api/licencees'
+      );
+      test.skip(body.licencees.length === 0, 'No licencees seeded');
+      licencee = String(body.licencees[0]._id);
+    }
+
+    const own = await licenceeLocationIds(page.request, licencee);
+    const returned = await pipelineLocationIds(page.request, licencee);
+    for (const [pipeline, ids] of Object.entries(returned)) {
+      const leaked = Array.from(new Set(ids)).filter(id => !own.has(id));
+      expect(leaked, pipeline).toEqual([]);
+    }
+  });
+});
+
+test.describe('Tenant isolation', () => {
+  test.skip(!TENANT, 'TENANT_LICENCEE_ID not set (server not pinned)');
+
+  test('rejects a foreign licencee param', async ({ page }) => {
+    for (const url of [
+      `/api/locations?licencee=${FOREIGN}`,
+      `/api/locations?licencees=${TENANT},${FOREIGN}`,
+      `/api/analytics/dashboard?licencee=${FOREIGN}`,
+      `/api/analytics/dashboard/trend?licencee=${FOREIGN}`,
+      `/api/analytics/charts?licencee=${FOREIGN}`,
+      `/api/analytics/locations?licencee=${FOREIGN}`,
+      `/api/reports/locations?licencee=${FOREIGN}`,
+      `/api/reports/machines?licencee=${FOREIGN}`,
+      `/api/reports/meters?licencee=${FOREIGN}`,
+      `/api/cabinets/aggregation?licencee=${FOREIGN}&timePeriod=Today`,
+      `/api/machines?licencee=${FOREIGN}`,
+      `/api/vault/overview/global?licenceeId=${FOREIGN}`,
+    ]) {
+      const response = await page.request.get(url);
+      expect(response.status(), url).toBe(403);
+    }
+  });
+
+  test('blocks raw collection routes', async ({ page }) => {
+    const response = await page.request.get('/api/dev/collections');
+    expect(response.status()).toBe(403);
+  });
+
+  test('unfiltered and forceAll location lists stay inside the tenant', async ({
+    page,
+  }) => {
+    for (const url of ['/api/locations', '/api/locations?forceAll=true']) {
+      const body = await getJson<{ locations: LocationRow[] }>(
+        page.request,
+        url
+      );
+      const leaked = body.locations.filter(
+        location => !belongsTo(location, String(TENANT))
+      );
+      expect(leaked.map(location => location._id), url).toEqual([]);
+    }
+  });
+
+  test('unfiltered pipelines stay inside the tenant', async ({ page }) => {
+    const own = await licenceeLocationIds(page.request, String(TENANT));
+    const returned = await pipelineLocationIds(page.request);
+    for (const [pipeline, ids] of Object.entries(returned)) {
+      const leaked = Array.from(new Set(ids)).filter(id => !own.has(id));
+      expect(leaked, pipeline).toEqual([]);
+    }
+
+    const heatmap = await getJson<{
+      data: {
+        cells: Array<{ locationCount: number }>;
+        locationsWithoutCoordinates: number;
+      };
+    }>(page.request, '/api/analytics/location-heatmap?timePeriod=7d');
+    const counted = heatmap.data.cells.reduce(
+      (sum, cell) => sum + cell.locationCount,
+      heatmap.data.locationsWithoutCoordinates
+    );
+    expect(counted, 'analytics/location-heatmap').toBeLessThanOrEqual(
+      own.size
+    );
+  });
+
+  test.describe('foreign location ids', () => {
+    test.skip(!FOREIGN_LOCATION, 'FOREIGN_LOCATION_ID not set');
+
+    test('location lists drop them', async ({ page }) => {
+      const body = await getJson<{ locations: LocationRow[] }>(
+        page.request,
+        `/api/locations?ids=${FOREIGN_LOCATION}`
+      );
+      expect(body.locations).toEqual([]);
+    });
+
+    test('cabinet and report pipelines drop them', async ({ page }) => {
+      const cabinets = await getJson<{ data: unknown[] }>(
+        page.request,
+        `/api/cabinets/aggregation?locationId=${FOREIGN_LOCATION}&timePeriod=Today`
+      );
+      expect(cabinets.data, 'cabinets/aggregation').toEqual([]);
+
+      const report = await getJson<{ data: unknown[] }>(
+        page.request,
+        `/api/reports/locations?locations=${FOREIGN_LOCATION}&timePeriod=7d`
+      );
+      expect(report.data, 'reports/locations').toEqual([]);
+    });
+
+    test('location-scoped reports and analytics refuse them', async ({
+      page,
+    }) => {
+      for (const url of [
+        `/api/analytics/location-trends?locationIds=${FOREIGN_LOCATION}`,
+        `/api/analytics/machine-hourly?locationIds=${FOREIGN_LOCATION}`,
+        `/api/analytics/hourly-revenue?locationId=${FOREIGN_LOCATION}`,
+        `/api/analytics/top-machines?locationId=${FOREIGN_LOCATION}`,
+        `/api/analytics/manufacturer-performance?locationId=${FOREIGN_LOCATION}`,
+        `/api/reports/cash-desk?locationId=${FOREIGN_LOCATION}`,
+        `/api/reports/idle-machines?locationId=${FOREIGN_LOCATION}`,
+        `/api/reports/shifts?locationId=${FOREIGN_LOCATION}`,
+        `/api/reports/uncollected-drop?locationId=${FOREIGN_LOCATION}`,
+      ]) {
+        const response = await page.request.get(url);
+        expect(response.status(), url).toBe(403);
+      }
+    });
+  });
+
+  test('query builder refuses a foreign licencee filter', async ({ page }) => {
+    const response = await page.request.post('/api/reports/query-builder', {
+      data: {
+        spec: {
+          entity: 'locations',
+          filters: { licencee: FOREIGN },
+          metrics: ['count'],
+        },
+        dryRun: true,
+      },
+    });
+    expect(response.status()).toBe(403);
+  });
+});
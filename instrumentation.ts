@@ -0,0 +1,45 @@
+/**
+ * Next.js Instrumentation Hook
+ *
+ * Runs once when the server starts. Hydrates secrets provided through
+ * `*_FILE` / `*_SECRET` (files, AWS/GCP secret managers) into process.env
+ * before any route reads them, and refuses to start when a database profiles
+ * file contains inline credentials. With `NEXT_MANUAL_SIG_HANDLE=true` (set
+ * by `npm run start`) it also installs the graceful shutdown: SIGTERM drains
+ * in-flight requests and aggregations before exiting (see gracefulShutdown).
+ * When an OTLP endpoint is configured it starts tracing (see tracing).
+ *
+ * @module instrumentation
+ */
+
+export async function register() {
+  if (process.env.NEXT_RUNTIME !== 'nodejs') return;
+
+  const { loadSecretsIntoEnv } = await import('@/app/api/lib/utils/secrets');
+  const hydrated = await loadSecretsIntoEnv();
+  if (hydrated.length > 0) {
+    console.log(`[instrumentation] Loaded secrets: ${hydrated.join(', ')}`);
+  }
+
+  const { startTracing, stopTracing } = await import(
+    '@/app/api/lib/utils/tracing'
+  );
+  await startTracing('casino-management-web');
+
+  if (process.env.DB_PROFILE) {
+    const { loadDbProfiles } = await import('@/app/api/lib/utils/dbProfiles');
+    loadDbProfiles();
+  }
+
+  if (process.env.NEXT_MANUAL_SIG_HANDLE === 'true') {
+    const { installShutdownHandlers } = await import(
+      '@/app/api/lib/utils/gracefulShutdown'
+    );
+    const { getApiQueryTag } = await import('@/app/api/lib/utils/queryTimeout');
+    installShutdownHandlers({
+      label: 'server',
+      tag: getApiQueryTag(),
+      beforeClose: stopTracing,
+    });
+  }
+}
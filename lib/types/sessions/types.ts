@@ -1,3 +1,5 @@
+import type { SasExceptionInfo } from '@/lib/utils/sas/exceptionCodes';
+
 export type Session = {
   _id: string;
   sessionId: string;
@@ -50,6 +52,8 @@ export type MachineEvent = {
   gameName?: string;
   date: string;
   sequence?: EventSequenceStep[];
+  /** Decoded SAS exception code, added by the events API */
+  sasEvent?: SasExceptionInfo | null;
 };
 
 export type PaginationData = {
@@ -6,6 +6,7 @@
  * Features:
  * - Report-specific exports (monthly, meters)
  * - Legacy/generic export utilities (class-based)
+ * - Export profiles (column selection, labels, number formats)
  * - Type definitions for export data structures
  */
 
@@ -19,3 +20,12 @@ export {
 
 // Legacy/generic export utilities
 export { ExportUtils, type ExtendedLegacyExportData } from './legacy';
+
+// Export profiles
+export {
+  applyExportProfile,
+  applyExportProfileToRows,
+  formatExportValue,
+  type ExportProfile,
+  type ExportProfileColumn,
+} from './profiles';
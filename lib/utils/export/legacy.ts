@@ -15,6 +15,8 @@ import { format } from 'date-fns';
 import jsPDF from 'jspdf';
 import autoTable from 'jspdf-autotable';
 import * as XLSX from 'xlsx';
+import { applyExportProfile } from './profiles';
+import type { ExportProfile } from './profiles';
 
 // jsPDF autotable typings are declared in lib/types/jspdf-autotable.d.ts
 
@@ -392,18 +394,21 @@ export class ExportUtils {
 
   static async exportData(
     data: ExtendedLegacyExportData,
-    format: ExportFormat
+    format: ExportFormat,
+    profile?: ExportProfile
   ): Promise<void> {
+    // Apply the licencee's export profile (columns, labels, number formats)
+    const exportData = profile ? applyExportProfile(data, profile) : data;
     try {
       switch (format) {
         case 'pdf':
-          await this.exportToPDF(data);
+          await this.exportToPDF(exportData);
           break;
         case 'csv':
-          this.exportToCSV(data);
+          this.exportToCSV(exportData);
           break;
         case 'excel':
-          this.exportToExcel(data);
+          this.exportToExcel(exportData);
           break;
         default:
           throw new Error(`Unsupported export format: ${format}`);
@@ -0,0 +1,226 @@
+/**
+ * Export Profiles
+ *
+ * An export profile picks which columns an export contains, in what order,
+ * under which display names and with which number format, so each licencee
+ * can get the layout they ask for. Profiles are defined in
+ * `export-profiles.json` (see app/api/lib/utils/exportProfiles.ts) and applied
+ * to CSV, Excel and PDF exports alike:
+ *
+ * - `applyExportProfile()` reshapes `ExportUtils` data (headers + rows)
+ * - `applyExportProfileToRows()` shapes report rows (objects) for the CLI
+ *
+ * Columns are matched by `field`: an object key for report rows, or a header
+ * (case-insensitive) for `ExportUtils` data. Columns missing from the data
+ * are exported empty so every file has the same layout.
+ */
+
+import type { ExtendedLegacyExportData } from './legacy';
+
+// ============================================================================
+// Type Definitions
+// ============================================================================
+
+export type ExportNumberFormat =
+  | 'text'
+  | 'number'
+  | 'integer'
+  | 'currency'
+  | 'percent';
+
+export type ExportProfileColumn = {
+  /** Row key or header to take the value from */
+  field: string;
+  /** Display name (default: the field) */
+  label?: string;
+  format?: ExportNumberFormat;
+  /** Decimal places for number, currency and percent (default 2) */
+  decimals?: number;
+};
+
+export type ExportProfile = {
+  name: string;
+  description?: string;
+  /** Licencees the profile is offered to (default: all) */
+  licencees?: string[];
+  /** Symbol for currency columns (default '$') */
+  currencySymbol?: string;
+  columns: ExportProfileColumn[];
+};
+
+export type ProfiledExport = {
+  headers: string[];
+  data: (string | number)[][];
+};
+
+export const EXPORT_NUMBER_FORMATS: ExportNumberFormat[] = [
+  'text',
+  'number',
+  'integer',
+  'currency',
+  'percent',
+];
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+/**
+ * Checks a profile read from configuration.
+ *
+ * @returns An error message, or null when the profile is valid
+ */
+export function validateExportProfile(profile: ExportProfile): string | null {
+  if (!profile.name?.trim()) return 'Profile name is required';
+  if (!Array.isArray(profile.columns) || profile.columns.length === 0) {
+    return `Profile '${profile.name}' has no columns`;
+  }
+  for (const column of profile.columns) {
+    if (!column.field?.trim()) {
+      return `Profile '${profile.name}' has a column without a field`;
+    }
+    if (column.format && !EXPORT_NUMBER_FORMATS.includes(column.format)) {
+      return `Profile '${profile.name}': unknown format '${column.format}' for ${column.field}`;
+    }
+    if (
+      column.decimals !== undefined &&
+      (!Number.isInteger(column.decimals) || column.decimals < 0)
+    ) {
+      return `Profile '${profile.name}': decimals for ${column.field} must be a whole number`;
+    }
+  }
+  return null;
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+function toNumber(value: unknown): number | null {
+  if (typeof value === 'number') return Number.isFinite(value) ? value : null;
+  if (typeof value !== 'string' || value.trim() === '') return null;
+  const parsed = Number(value.replace(/[$,%\s]/g, ''));
+  return Number.isFinite(parsed) ? parsed : null;
+}
+
+/**
+ * Formats a value for a profile column. Values that are not numbers are
+ * passed through as text whatever the column format.
+ */
+export function formatExportValue(
+  value: unknown,
+  column: ExportProfileColumn,
+  currencySymbol = '$'
+): string | number {
+  if (value === null || value === undefined) return '';
+  if (value instanceof Date) return value.toISOString();
+  if (typeof value === 'object') return JSON.stringify(value);
+
+  const format = column.format || 'text';
+  const number = format === 'text' ? null : toNumber(value);
+  if (number === null) return typeof value === 'number' ? value : String(value);
+
+  const decimals = column.decimals ?? 2;
+  const fixed = (digits: number) =>
+    Math.abs(number).toLocaleString('en-US', {
+      minimumFractionDigits: digits,
+      maximumFractionDigits: digits,
+    });
+  const sign = number < 0 ? '-' : '';
+  switch (format) {
+    case 'integer':
+      return Math.round(number);
+    case 'number':
+      return Number(number.toFixed(decimals));
+    case 'currency':
+      return `${sign}${currencySymbol}${fixed(decimals)}`;
+    case 'percent':
+      return `${sign}${fixed(decimals)}%`;
+    default:
+      return String(value);
+  }
+}
+
+// ============================================================================
+// Application
+// ============================================================================
+
+/**
+ * Shapes report rows (objects) into the profile's columns.
+ *
+ * @param rows - Report rows keyed by field
+ * @param profile - Export profile
+ * @returns Headers and formatted cells in profile order
+ */
+export function applyExportProfileToRows(
+  rows: Record<string, unknown>[],
+  profile: ExportProfile
+): ProfiledExport {
+  return {
+    headers: profile.columns.map(column => column.label || column.field),
+    data: rows.map(row =>
+      profile.columns.map(column =>
+        formatExportValue(row[column.field], column, profile.currencySymbol)
+      )
+    ),
+  };
+}
+
+/**
+ * Reshapes `ExportUtils` data into the profile's columns, matching each
+ * column's field against the existing headers.
+ *
+ * @param data - Export data as built by the report pages
+ * @param profile - Export profile
+ * @returns A copy with headers and rows in profile order
+ */
+export function applyExportProfile(
+  data: ExtendedLegacyExportData,
+  profile: ExportProfile
+): ExtendedLegacyExportData {
+  const headerIndex = new Map(
+    data.headers.map((header, index) => [header.trim().toLowerCase(), index])
+  );
+  const indexes = profile.columns.map(column =>
+    headerIndex.get(column.field.trim().toLowerCase())
+  );
+
+  return {
+    ...data,
+    headers: profile.columns.map(column => column.label || column.field),
+    data: data.data.map(row =>
+      profile.columns.map((column, position) => {
+        const index = indexes[position];
+        return index === undefined
+          ? ''
+          : formatExportValue(row[index], column, profile.currencySymbol);
+      })
+    ),
+  };
+}
+
+/**
+ * Converts profiled rows back to objects keyed by display name (JSON output).
+ */
+export function profiledExportToObjects(
+  profiled: ProfiledExport
+): Record<string, string | number>[] {
+  return profiled.data.map(row =>
+    Object.fromEntries(
+      profiled.headers.map((header, index) => [header, row[index]])
+    )
+  );
+}
+
+/**
+ * Serializes profiled rows as CSV.
+ */
+export function profiledExportToCsv(profiled: ProfiledExport): string {
+  const escape = (value: string | number) => {
+    const text = String(value);
+    return /[",\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+  };
+  return [profiled.headers, ...profiled.data]
+    .map(row => row.map(escape).join(','))
+    .join('\n');
+}
@@ -0,0 +1,234 @@
+/**
+ * SAS Exception Code Dictionary
+ *
+ * Names and severities for the SAS general exception codes that SMIBs
+ * forward into `machineevents.command` (e.g. `0x1A`), so event timelines and
+ * alert reports can show "Cashbox door was closed" instead of a raw byte.
+ * Codes follow SAS 6.02 (general exceptions, table 7.6a).
+ *
+ * The dictionary can be extended or overridden per deployment with
+ * `sas-codes.json` (see app/api/lib/utils/sasCodes.ts); pass the merged
+ * entries to `decodeSasException()`.
+ *
+ * Severities:
+ * - critical: tilts, memory/EPROM errors, power loss, power-off door access
+ * - warning: doors, hopper/bill/printer problems, handpays, lockouts
+ * - info: routine activity (bills accepted, games, tickets, menus)
+ */
+
+// ============================================================================
+// Types
+// ============================================================================
+
+export type SasSeverity = 'info' | 'warning' | 'critical';
+
+export type SasExceptionDefinition = {
+  name: string;
+  severity: SasSeverity;
+};
+
+/** Decoded exception as attached to events (`sasEvent`) */
+export type SasExceptionInfo = SasExceptionDefinition & {
+  /** Normalized code, two uppercase hex digits (e.g. '1A') */
+  code: string;
+  /** False when the code is not in the dictionary */
+  known: boolean;
+};
+
+export const SAS_SEVERITIES: SasSeverity[] = ['info', 'warning', 'critical'];
+
+// ============================================================================
+// Dictionary
+// ============================================================================
+
+const info = (name: string): SasExceptionDefinition => ({
+  name,
+  severity: 'info',
+});
+const warning = (name: string): SasExceptionDefinition => ({
+  name,
+  severity: 'warning',
+});
+const critical = (name: string): SasExceptionDefinition => ({
+  name,
+  severity: 'critical',
+});
+
+/** Built-in SAS general exception codes, keyed by two-digit hex code */
+export const SAS_EXCEPTION_CODES: Record<string, SasExceptionDefinition> = {
+  '00': info('No activity'),
+  '11': warning('Slot door was opened'),
+  '12': info('Slot door was closed'),
+  '13': warning('Drop door was opened'),
+  '14': info('Drop door was closed'),
+  '15': warning('Card cage was opened'),
+  '16': info('Card cage was closed'),
+  '17': info('AC power was applied to gaming machine'),
+  '18': critical('AC power was lost from gaming machine'),
+  '19': warning('Cashbox door was opened'),
+  '1A': info('Cashbox door was closed'),
+  '1B': warning('Cashbox was removed'),
+  '1C': info('Cashbox was installed'),
+  '1D': warning('Belly door was opened'),
+  '1E': info('Belly door was closed'),
+  '20': critical('General tilt'),
+  '21': critical('Coin in tilt'),
+  '22': critical('Coin out tilt'),
+  '23': warning('Hopper empty detected'),
+  '24': warning('Extra coin paid'),
+  '25': critical('Diverter malfunction'),
+  '27': warning('Cashbox full detected'),
+  '28': warning('Bill jam'),
+  '29': critical('Bill acceptor hardware failure'),
+  '2A': warning('Reverse bill detected'),
+  '2B': info('Bill rejected'),
+  '2C': critical('Counterfeit bill detected'),
+  '2D': warning('Reverse coin in detected'),
+  '2E': warning('Cashbox near full detected'),
+  '31': critical('CMOS RAM error (data recovered from EEPROM)'),
+  '32': critical('CMOS RAM error (no data recovered from EEPROM)'),
+  '33': critical('CMOS RAM error (bad device)'),
+  '34': critical('EEPROM error (data error)'),
+  '35': critical('EEPROM error (bad device)'),
+  '36': critical('EPROM error (different checksum, version changed)'),
+  '37': critical('EPROM error (bad checksum compare)'),
+  '38': critical('Partitioned EPROM error (checksum, version changed)'),
+  '39': critical('Partitioned EPROM error (bad checksum compare)'),
+  '3A': critical('Memory error reset'),
+  '3B': warning('Low backup battery detected'),
+  '3C': warning('Operator changed options'),
+  '3D': info('Cash out ticket printed'),
+  '3E': info('Handpay validated'),
+  '3F': warning('Validation ID not configured'),
+  '40': critical('Reel tilt'),
+  '41': critical('Reel 1 tilt'),
+  '42': critical('Reel 2 tilt'),
+  '43': critical('Reel 3 tilt'),
+  '44': critical('Reel 4 tilt'),
+  '45': critical('Reel 5 tilt'),
+  '46': critical('Reel mechanism disconnected'),
+  '47': info('$1.00 bill accepted'),
+  '48': info('$5.00 bill accepted'),
+  '49': info('$10.00 bill accepted'),
+  '4A': info('$20.00 bill accepted'),
+  '4B': info('$50.00 bill accepted'),
+  '4C': info('$100.00 bill accepted'),
+  '4D': info('$2.00 bill accepted'),
+  '4E': info('$500.00 bill accepted'),
+  '4F': info('Bill accepted'),
+  '50': info('$200.00 bill accepted'),
+  '51': warning('Handpay is pending'),
+  '52': info('Handpay was reset'),
+  '53': warning('No progressive information received for 5 seconds'),
+  '54': info('Progressive win'),
+  '55': info('Player cancelled the handpay request'),
+  '56': info('SAS progressive level hit'),
+  '57': info('System validation request'),
+  '60': critical('Printer communication error'),
+  '61': warning('Printer paper out'),
+  '66': info('Cash out button pressed'),
+  '67': info('Ticket inserted'),
+  '68': info('Ticket transfer complete'),
+  '69': info('AFT transfer complete'),
+  '6A': info('AFT request for host cashout'),
+  '6B': info('AFT request for host to cash out win'),
+  '6C': info('AFT request to register'),
+  '6D': info('AFT registration acknowledged'),
+  '6E': info('AFT registration cancelled'),
+  '6F': warning('Game locked'),
+  '70': warning('Exception buffer overflow'),
+  '71': info('Change lamp on'),
+  '72': info('Change lamp off'),
+  '74': warning('Printer paper low'),
+  '75': warning('Printer power off'),
+  '76': info('Printer power on'),
+  '77': warning('Replace printer ribbon'),
+  '78': warning('Printer carriage jammed'),
+  '79': critical('Coin in lockout malfunction'),
+  '7A': warning('Soft meters reset to zero'),
+  '7B': warning('Bill validator totals reset'),
+  '7C': info('Legacy bonus pay awarded or multiplied jackpot'),
+  '7E': info('Game started'),
+  '7F': info('Game ended'),
+  '80': warning('Hopper full detected'),
+  '81': warning('Hopper level low detected'),
+  '82': info('Display meters or attendant menu entered'),
+  '83': info('Display meters or attendant menu exited'),
+  '84': warning('Self test or operator menu entered'),
+  '85': info('Self test or operator menu exited'),
+  '86': warning('Gaming machine out of service (by attendant)'),
+  '87': info('Player requested draw cards'),
+  '88': info('Reel stopped'),
+  '89': info('Coin/credit wagered'),
+  '8A': info('Game recall entry displayed'),
+  '8B': info('Card held/not held'),
+  '8C': info('Game selected'),
+  '8E': warning('Component list changed'),
+  '8F': info('Authentication complete'),
+  '98': critical('Power off card cage access'),
+  '99': critical('Power off slot door access'),
+  '9A': critical('Power off cashbox door access'),
+  '9B': critical('Power off drop door access'),
+};
+
+// ============================================================================
+// Decoding
+// ============================================================================
+
+/**
+ * Normalizes a code as stored on events (`0x1A`, `1a`, `1A`) to two
+ * uppercase hex digits.
+ *
+ * @returns The code, or null when it is not a single hex byte
+ */
+export function normalizeSasCode(
+  raw: string | number | null | undefined
+): string | null {
+  if (raw === null || raw === undefined) return null;
+  const text =
+    typeof raw === 'number'
+      ? raw.toString(16)
+      : raw.trim().replace(/^0x/i, '');
+  if (!/^[0-9a-f]{1,2}$/i.test(text)) return null;
+  return text.toUpperCase().padStart(2, '0');
+}
+
+/**
+ * Looks up a code in the dictionary.
+ *
+ * @param raw - Code as stored on the event
+ * @param dictionary - Entries to use (default: the built-in codes)
+ * @returns Name and severity, a placeholder for codes not in the
+ * dictionary, or null when `raw` is not a code
+ */
+export function decodeSasException(
+  raw: string | number | null | undefined,
+  dictionary: Record<string, SasExceptionDefinition> = SAS_EXCEPTION_CODES
+): SasExceptionInfo | null {
+  const code = normalizeSasCode(raw);
+  if (!code) return null;
+  const definition = dictionary[code];
+  return definition
+    ? { code, ...definition, known: true }
+    : {
+        code,
+        name: `Unknown SAS exception 0x${code}`,
+        severity: 'info',
+        known: false,
+      };
+}
+
+/**
+ * Codes whose severity is at least `minimum`, for filtering events.
+ */
+export function sasCodesAtSeverity(
+  minimum: SasSeverity,
+  dictionary: Record<string, SasExceptionDefinition> = SAS_EXCEPTION_CODES
+): string[] {
+  const rank = SAS_SEVERITIES.indexOf(minimum);
+  return Object.entries(dictionary)
+    .filter(
+      ([, definition]) => SAS_SEVERITIES.indexOf(definition.severity) >= rank
+    )
+    .map(([code]) => code);
+}
@@ -0,0 +1,288 @@
+/**
+ * Shift Range Utility
+ *
+ * Resolves shift windows (e.g. 6am–2pm, 2pm–10pm, 10pm–6am) for a location.
+ * A shift day starts at the earliest shift start hour; shifts whose end hour is
+ * not after their start hour run into the next calendar day.
+ *
+ * Ranges accept the `shift:` syntax alongside the regular time periods:
+ * - `shift:today`, `shift:yesterday`, `shift:current`
+ * - `shift:7d`, `shift:30d` (last N shift days, including today)
+ * - `shift:2026-03-01` or `shift:2026-03-01..2026-03-07`
+ * - Any gaming-day period (`Today`, `7d`, `Custom`, ...) expanded into shifts
+ *
+ * @module lib/utils/shiftRange
+ */
+
+import type { LocationShift, ShiftWindow } from '@/shared/types/entities';
+import { getGamingDayRangeForPeriod } from './gamingDayRange';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+export type ShiftRangeOptions = {
+  timezoneOffset?: number;
+  customStartDate?: Date;
+  customEndDate?: Date;
+  now?: Date;
+};
+
+export const DEFAULT_SHIFTS: LocationShift[] = [
+  { name: 'Morning', startHour: 6, endHour: 14 },
+  { name: 'Evening', startHour: 14, endHour: 22 },
+  { name: 'Night', startHour: 22, endHour: 6 },
+];
+
+export const SHIFT_RANGE_PREFIX = 'shift:';
+
+/**
+ * Upper bound on shift days in one range to keep reports bounded.
+ */
+export const MAX_SHIFT_DAYS = 93;
+
+const DEFAULT_TIMEZONE_OFFSET = -4;
+const HOUR_MS = 60 * 60 * 1000;
+const DAY_MS = 24 * HOUR_MS;
+const DATE_PATTERN = /^\d{4}-\d{2}-\d{2}$/;
+
+// ============================================================================
+// Validation
+// ============================================================================
+
+function getShiftHours(shift: LocationShift): number[] {
+  const hours: number[] = [];
+  let hour = shift.startHour;
+  do {
+    hours.push(hour);
+    hour = (hour + 1) % 24;
+  } while (hour !== shift.endHour % 24);
+  return hours;
+}
+
+/**
+ * Validates shift definitions: unique names, whole hours 0–23 (end may be 24),
+ * non-zero length and no overlap.
+ *
+ * @param shifts - Candidate shift definitions
+ * @returns Error message, or null when valid
+ */
+export function validateShifts(shifts: unknown): string | null {
+  if (!Array.isArray(shifts) || shifts.length === 0) {
+    return 'shifts must be a non-empty array';
+  }
+
+  const names = new Set<string>();
+  const coveredHours = new Set<number>();
+  for (const candidate of shifts as Array<Partial<LocationShift>>) {
+    const name = typeof candidate?.name === 'string' ? candidate.name.trim() : '';
+    if (!name) return 'Each shift needs a name';
+    if (names.has(name)) return `Duplicate shift name '${name}'`;
+    names.add(name);
+
+    const { startHour, endHour } = candidate;
+    if (
+      !Number.isInteger(startHour) ||
+      !Number.isInteger(endHour) ||
+      (startHour as number) < 0 ||
+      (startHour as number) > 23 ||
+      (endHour as number) < 0 ||
+      (endHour as number) > 24
+    ) {
+      return `Shift '${name}' must use whole hours (start 0-23, end 0-24)`;
+    }
+    if ((startHour as number) === (endHour as number) % 24) {
+      return `Shift '${name}' has zero length`;
+    }
+
+    for (const hour of getShiftHours({
+      name,
+      startHour: startHour as number,
+      endHour: endHour as number,
+    })) {
+      if (coveredHours.has(hour)) {
+        return `Shift '${name}' overlaps another shift at ${hour}:00`;
+      }
+      coveredHours.add(hour);
+    }
+  }
+
+  return null;
+}
+
+// ============================================================================
+// Windows
+// ============================================================================
+
+function getDayStartHour(shifts: LocationShift[]): number {
+  return Math.min(...shifts.map(shift => shift.startHour));
+}
+
+function formatDay(date: Date): string {
+  return date.toISOString().slice(0, 10);
+}
+
+function addDays(day: string, days: number): string {
+  return formatDay(new Date(Date.parse(`${day}T00:00:00Z`) + days * DAY_MS));
+}
+
+/**
+ * Returns the shift day (YYYY-MM-DD) that contains the given instant.
+ */
+export function getShiftDay(
+  shifts: LocationShift[],
+  instant: Date,
+  timezoneOffset: number = DEFAULT_TIMEZONE_OFFSET
+): string {
+  const local = new Date(instant.getTime() + timezoneOffset * HOUR_MS);
+  const day = formatDay(local);
+  return local.getUTCHours() < getDayStartHour(shifts) ? addDays(day, -1) : day;
+}
+
+/**
+ * Builds the UTC windows for every shift of one shift day.
+ *
+ * @param shiftDay - Shift day as YYYY-MM-DD
+ * @param shifts - Shift definitions
+ * @param timezoneOffset - UTC offset of the location (default: -4)
+ * @returns Windows ordered by start time
+ */
+export function getShiftWindowsForDay(
+  shiftDay: string,
+  shifts: LocationShift[],
+  timezoneOffset: number = DEFAULT_TIMEZONE_OFFSET
+): ShiftWindow[] {
+  const dayStartUtc = Date.parse(`${shiftDay}T00:00:00Z`) - timezoneOffset * HOUR_MS;
+
+  return shifts
+    .map(shift => {
+      const endHour =
+        shift.endHour > shift.startHour ? shift.endHour : shift.endHour + 24;
+      return {
+        shift: shift.name,
+        shiftDay,
+        start: new Date(dayStartUtc + shift.startHour * HOUR_MS),
+        end: new Date(dayStartUtc + endHour * HOUR_MS),
+      };
+    })
+    .sort((a, b) => a.start.getTime() - b.start.getTime());
+}
+
+function getWindowsForDays(
+  firstDay: string,
+  lastDay: string,
+  shifts: LocationShift[],
+  timezoneOffset: number
+): ShiftWindow[] {
+  const dayCount =
+    Math.round(
+      (Date.parse(`${lastDay}T00:00:00Z`) - Date.parse(`${firstDay}T00:00:00Z`)) /
+        DAY_MS
+    ) + 1;
+  if (dayCount < 1) {
+    throw new Error('Shift range end is before its start');
+  }
+  if (dayCount > MAX_SHIFT_DAYS) {
+    throw new Error(`Shift ranges are limited to ${MAX_SHIFT_DAYS} days`);
+  }
+
+  const windows: ShiftWindow[] = [];
+  for (let offset = 0; offset < dayCount; offset++) {
+    windows.push(
+      ...getShiftWindowsForDay(addDays(firstDay, offset), shifts, timezoneOffset)
+    );
+  }
+  return windows;
+}
+
+/**
+ * Parses a `shift:` range into shift windows.
+ *
+ * @param range - Range string (e.g. 'shift:today')
+ * @param shifts - Shift definitions
+ * @param options - Timezone offset and reference time
+ * @returns Windows, or null when the range is not a `shift:` range
+ */
+export function parseShiftRange(
+  range: string,
+  shifts: LocationShift[],
+  options: ShiftRangeOptions = {}
+): ShiftWindow[] | null {
+  if (!range.startsWith(SHIFT_RANGE_PREFIX)) return null;
+
+  const timezoneOffset = options.timezoneOffset ?? DEFAULT_TIMEZONE_OFFSET;
+  const now = options.now ?? new Date();
+  const today = getShiftDay(shifts, now, timezoneOffset);
+  const spec = range.slice(SHIFT_RANGE_PREFIX.length).trim();
+
+  if (spec === 'today') {
+    return getWindowsForDays(today, today, shifts, timezoneOffset);
+  }
+  if (spec === 'yesterday') {
+    const yesterday = addDays(today, -1);
+    return getWindowsForDays(yesterday, yesterday, shifts, timezoneOffset);
+  }
+  if (spec === 'current') {
+    return getWindowsForDays(today, today, shifts, timezoneOffset).filter(
+      window => window.start <= now && now < window.end
+    );
+  }
+
+  const lastDaysMatch = spec.match(/^(\d+)d$/);
+  if (lastDaysMatch) {
+    const days = Number(lastDaysMatch[1]);
+    return getWindowsForDays(
+      addDays(today, -(days - 1)),
+      today,
+      shifts,
+      timezoneOffset
+    );
+  }
+
+  const [firstDay, lastDay = firstDay] = spec.split('..');
+  if (DATE_PATTERN.test(firstDay) && DATE_PATTERN.test(lastDay)) {
+    return getWindowsForDays(firstDay, lastDay, shifts, timezoneOffset);
+  }
+
+  throw new Error(
+    `Invalid shift range '${range}'. Use shift:today, shift:yesterday, shift:current, shift:<N>d or shift:YYYY-MM-DD[..YYYY-MM-DD]`
+  );
+}
+
+/**
+ * Resolves shift windows for either a `shift:` range or a regular time period.
+ * Regular periods are computed as gaming days starting at the first shift's
+ * start hour and expanded into the shifts they contain.
+ *
+ * @param range - `shift:` range or time period (Today, 7d, Custom, ...)
+ * @param shifts - Shift definitions
+ * @param options - Timezone offset, custom dates and reference time
+ * @returns Windows ordered by start time
+ */
+export function resolveShiftWindows(
+  range: string,
+  shifts: LocationShift[],
+  options: ShiftRangeOptions = {}
+): ShiftWindow[] {
+  const parsed = parseShiftRange(range, shifts, options);
+  if (parsed) return parsed;
+
+  if (range === 'All Time' || range === 'LastHour') {
+    throw new Error(`Time period '${range}' is not supported for shift reports`);
+  }
+
+  const timezoneOffset = options.timezoneOffset ?? DEFAULT_TIMEZONE_OFFSET;
+  const { rangeStart, rangeEnd } = getGamingDayRangeForPeriod(
+    range,
+    getDayStartHour(shifts),
+    options.customStartDate,
+    options.customEndDate,
+    timezoneOffset
+  );
+  return getWindowsForDays(
+    getShiftDay(shifts, rangeStart, timezoneOffset),
+    getShiftDay(shifts, new Date(rangeEnd.getTime() - 1), timezoneOffset),
+    shifts,
+    timezoneOffset
+  );
+}
@@ -13,6 +13,8 @@
  * - User account status checking (disabled accounts)
  * - Automatic cookie clearing on authentication failures
  * - Asset and API route bypassing
+ * - Tenant isolation: API requests naming another licencee are rejected
+ *   when TENANT_LICENCEE_ID is set
  *
  * @module proxy
  */
@@ -28,6 +30,12 @@ const publicPaths = [
   '/install',
 ];
 
+/** Load balancer / monitoring probes, answered without a session */
+const probePaths = ['/healthz', '/readyz'];
+
+/** Query parameters API routes read a licencee id from */
+const licenceeParams = ['licencee', 'licencees', 'licenceeId'];
+
 /**
  * Validates database context from JWT token
  */
@@ -69,6 +77,41 @@ function validateDatabaseContext(
   return true;
 }
 
+/**
+ * Tenant isolation check for API requests (Edge Runtime — cannot import the
+ * Mongoose-based tenantScope helpers). When TENANT_LICENCEE_ID is set, a
+ * `licencee` / `licencees` / `licenceeId` query parameter naming any other
+ * licencee, and the raw `/api/dev` collection routes, are rejected with 403.
+ * Request bodies are not readable here; `withApiAuth()` checks the same
+ * fields in JSON bodies. Deeper checks (location scope) happen in the
+ * licencee filter helpers.
+ */
+function checkTenantIsolation(request: NextRequest): NextResponse | null {
+  const pinned = process.env.TENANT_LICENCEE_ID?.trim();
+  if (!pinned) return null;
+
+  const { pathname, searchParams } = request.nextUrl;
+  const requested = licenceeParams
+    .flatMap(name => searchParams.getAll(name))
+    .flatMap(value => value.split(','))
+    .map(value => value.trim())
+    .filter(value => value && value !== 'all');
+  const foreign = requested.find(value => value !== pinned);
+
+  if (pathname.startsWith('/api/dev') || foreign) {
+    return NextResponse.json(
+      {
+        success: false,
+        error: foreign
+          ? `Tenant isolation: licencee ${foreign} is outside this deployment`
+          : 'Tenant isolation: raw collection access is disabled',
+      },
+      { status: 403 }
+    );
+  }
+  return null;
+}
+
 /**
  * Verifies JWT access token
  */
@@ -91,7 +134,7 @@ async function verifyAccessToken(token: string) {
  *
  * Flow:
  * 1. Extract pathname from request URL
- * 2. Skip processing for API routes and static assets
+ * 2. Skip processing for API routes (after the tenant isolation check), health probes and static assets
  * 3. Extract JWT token from cookies
  * 4. Verify JWT token if present
  * 5. Validate database context from token
@@ -110,10 +153,16 @@ export async function proxy(request: NextRequest) {
   const { pathname } = request.nextUrl;
 
   // ============================================================================
-  // STEP 2: Skip processing for API routes and static assets
+  // STEP 2: Skip processing for API routes, health probes and static assets
   // ============================================================================
+  if (pathname.startsWith('/api')) {
+    return checkTenantIsolation(request) ?? NextResponse.next();
+  }
+  // Health probes (app/healthz, app/readyz) are public
+  if (probePaths.includes(pathname)) {
+    return NextResponse.next();
+  }
   if (
-    pathname.startsWith('/api') ||
     pathname.startsWith('/_next') ||
     pathname.startsWith('/favicon.ico') ||
     pathname.match(
@@ -226,5 +275,5 @@ function createLogoutResponse(
 }
 
 export const config = {
-  matcher: ['/((?!_next/static|_next/image|favicon.ico|.*\\.|api/).*)'],
+  matcher: ['/((?!_next/static|_next/image|favicon.ico|.*\\.).*)'],
 };
@@ -0,0 +1,166 @@
+/**
+ * API Keys Command
+ *
+ * Lists, creates and revokes API keys for machine-to-machine clients and sets
+ * their quotas (see app/api/lib/helpers/apiKeys.ts):
+ * `bun run api-keys -- create kiosk --licencee <id> --scope machines --scope members:read`.
+ *
+ * Commands:
+ *   list                          List keys (never their value)
+ *   create <name>                 Create a key and print it once
+ *     --licencee <id>             Licencee the key can see (repeatable or
+ *                                 comma-separated)
+ *     --scope <group[:read]>      Endpoint group (repeatable or
+ *                                 comma-separated): machines, locations,
+ *                                 reports, collections, members, vault,
+ *                                 licencees; `:read` allows GET only
+ *     --requests-per-minute N     Quota overrides (default: the default
+ *                                 quota in api-quotas.json)
+ *     --burst N
+ *     --concurrent-aggregations N
+ *     --expires <date>            Refuse the key after this date
+ *     --description <text>
+ *   set-quota <name|id>           Replace a key's quota with the given
+ *     --requests-per-minute N     overrides, or clear it with --clear
+ *     --burst N
+ *     --concurrent-aggregations N
+ *     --clear
+ *   revoke <name|id>              Revoke a key (asks for confirmation)
+ *
+ * Options:
+ *   --env <profile>               Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --yes                         Skip the revoke confirmation
+ *   --read-only                   Block create, set-quota and revoke
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  createApiKey,
+  listApiKeys,
+  revokeApiKey,
+  setApiKeyQuota,
+} from '../app/api/lib/helpers/apiKeys';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import type { ApiKeyQuota } from '../shared/types';
+import { readFlag } from './lib/cliArgs';
+
+/** Every value of a repeatable, comma-separated flag */
+function readList(args: string[], name: string): string[] {
+  return args
+    .flatMap((arg, index) => {
+      if (arg.startsWith(`${name}=`)) return [arg.slice(name.length + 1)];
+      return arg === name && args[index + 1] ? [args[index + 1]] : [];
+    })
+    .flatMap(value => value.split(','))
+    .map(value => value.trim())
+    .filter(Boolean);
+}
+
+function readCount(args: string[], name: string): number | undefined {
+  const value = readFlag(args, name);
+  if (value === undefined) return undefined;
+  const count = Number(value);
+  if (!Number.isInteger(count) || count < 0) {
+    throw new Error(`${name} must be a whole number of 0 or more`);
+  }
+  return count;
+}
+
+/** The quota flags given, or undefined when there are none */
+function readQuota(args: string[]): ApiKeyQuota | undefined {
+  const quota: ApiKeyQuota = {
+    requestsPerMinute: readCount(args, '--requests-per-minute'),
+    burst: readCount(args, '--burst'),
+    concurrentAggregations: readCount(args, '--concurrent-aggregations'),
+  };
+  return Object.values(quota).some(value => value !== undefined)
+    ? quota
+    : undefined;
+}
+
+const audit = startCommandAudit('api-keys');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [command, name] = args;
+  if (!['list', 'create', 'set-quota', 'revoke'].includes(command)) {
+    throw new Error('Usage: api-keys <list|create|set-quota|revoke> [name]');
+  }
+  if (command !== 'list' && (!name || name.startsWith('--'))) {
+    throw new Error(`${command} requires a key name`);
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  if (command === 'list') {
+    const keys = await listApiKeys();
+    audit.addRows(keys.length);
+    console.table(
+      keys.map(key => ({
+        name: key.name,
+        prefix: key.prefix,
+        licencees: key.licencees.join(', '),
+        scopes: key.scopes.join(', '),
+        lastUsedAt: key.lastUsedAt?.toISOString() || '',
+        expiresAt: key.expiresAt?.toISOString() || '',
+        revokedAt: key.revokedAt?.toISOString() || '',
+        quota: key.quota ? JSON.stringify(key.quota) : 'default',
+      }))
+    );
+  } else if (command === 'create') {
+    const expires = readFlag(args, '--expires');
+    const expiresAt = expires ? new Date(expires) : null;
+    if (expiresAt && Number.isNaN(expiresAt.getTime())) {
+      throw new Error('--expires must be a valid date');
+    }
+    const { apiKey, key } = await createApiKey({
+      name,
+      licencees: readList(args, '--licencee'),
+      scopes: readList(args, '--scope'),
+      quota: readQuota(args),
+      description: readFlag(args, '--description'),
+      expiresAt,
+      createdBy: process.env.USER || null,
+    });
+    audit.addRows(1);
+    console.log(
+      `Created API key '${apiKey.name}' (${apiKey.scopes.join(', ')}) for licencee(s) ${apiKey.licencees.join(', ')}`
+    );
+    console.log(`Key (shown only now; send it as X-API-Key): ${key}`);
+  } else if (command === 'set-quota') {
+    const quota = readQuota(args);
+    const clear = args.includes('--clear');
+    if (!quota === !clear) {
+      throw new Error('set-quota takes quota flags or --clear (not both)');
+    }
+    const apiKey = await setApiKeyQuota(name, quota ?? null);
+    audit.addRows(1);
+    console.log(
+      apiKey.quota
+        ? `Set the quota of API key '${apiKey.name}' to ${JSON.stringify(apiKey.quota)}`
+        : `Cleared the quota of API key '${apiKey.name}'; it uses the default quota`
+    );
+  } else {
+    await confirmDestructiveOperation(target, `revoke API key '${name}'`);
+    const apiKey = await revokeApiKey(name, process.env.USER || null);
+    audit.addRows(1);
+    console.log(`Revoked API key '${apiKey.name}'`);
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+}
+
+main().catch(async error => {
+  console.error(
+    '[api-keys] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 1, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(1);
+});
@@ -0,0 +1,175 @@
+/**
+ * Backups Command
+ *
+ * Lists the server-side backup runs commands keep before bulk changes (size
+ * and contents per run) and prunes old runs by retention policy:
+ * `bun run backups -- list --env prod`
+ * `bun run backups -- prune --env prod --keep 5 --max-age-days 90 --dry-run`.
+ * Runs of the `documents` store (taken before fixes, re-syncs and status
+ * changes, see writeBackups) are put back with
+ * `bun run backups -- restore <run-id> --env prod`.
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --store <name>           Only this backup store (default: all)
+ *   --keep <N>               prune: keep the newest N runs per store (or BACKUP_KEEP_LAST)
+ *   --max-age-days <N>       prune: drop runs older than N days (or BACKUP_MAX_AGE_DAYS)
+ *   --max-size-mb <N>        prune: keep the newest runs within N MB per store (or BACKUP_MAX_SIZE_MB)
+ *   --dry-run                prune: list what would be deleted
+ *   --yes                    prune / restore: skip the confirmation prompt
+ *   --json                   Print the result as JSON
+ *
+ * The newest run of each store is never pruned.
+ *
+ * Exit codes: 0 = done, 1 = prune without any limit set, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  BACKUP_STORES,
+  formatBackupRuns,
+  getBackupRetentionPolicy,
+  hasRetentionLimits,
+  listBackupRuns,
+  pruneBackupRuns,
+  selectBackupRunsToPrune,
+} from '../app/api/lib/helpers/backupRetention';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DOCUMENT_BACKUP_COLLECTION,
+  restoreDocumentBackup,
+} from '../app/api/lib/helpers/writeBackups';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--env',
+    '--max-time-ms',
+    '--store',
+    '--keep',
+    '--max-age-days',
+    '--max-size-mb',
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('backups');
+
+async function finish(exitCode: number) {
+  await audit.finish({ success: exitCode !== 2, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const [action, runId] = readPositionals(args);
+  if (
+    (action !== 'list' && action !== 'prune' && action !== 'restore') ||
+    (action === 'restore' && !runId)
+  ) {
+    throw new Error(
+      'Usage: backups list | prune [--keep N] [--max-age-days N] [--max-size-mb N] [--dry-run] | restore <run-id>'
+    );
+  }
+  const store = readFlag(args, '--store');
+  if (store && !BACKUP_STORES.some(entry => entry.name === store)) {
+    throw new Error(
+      `Unknown store '${store}'. Available: ${BACKUP_STORES.map(entry => entry.name).join(', ')}`
+    );
+  }
+  const policy = getBackupRetentionPolicy({
+    keepLast: readFlag(args, '--keep'),
+    maxAgeDays: readFlag(args, '--max-age-days'),
+    maxSizeMb: readFlag(args, '--max-size-mb'),
+  });
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // Restore a documents run
+  if (action === 'restore') {
+    await confirmDestructiveOperation(
+      target,
+      `Restore the documents of backup run ${runId} from ${DOCUMENT_BACKUP_COLLECTION}`
+    );
+    const restored = await restoreDocumentBackup(mongoose.connection, runId);
+    audit.addRows(restored);
+    console.log(
+      asJson
+        ? JSON.stringify({ runId, restored }, null, 2)
+        : `Restored ${restored} document(s) from run ${runId}`
+    );
+    return finish(0);
+  }
+
+  const runs = await listBackupRuns(
+    mongoose.connection,
+    store ? [store] : undefined
+  );
+
+  // List
+  if (action === 'list') {
+    audit.addRows(runs.length);
+    console.log(
+      asJson ? JSON.stringify(runs, null, 2) : formatBackupRuns(runs)
+    );
+    return finish(0);
+  }
+
+  // Prune
+  if (!hasRetentionLimits(policy)) {
+    console.error(
+      '[backups] Set a limit: --keep, --max-age-days or --max-size-mb (or BACKUP_KEEP_LAST, BACKUP_MAX_AGE_DAYS, BACKUP_MAX_SIZE_MB)'
+    );
+    return finish(1);
+  }
+  const { prune, keep } = selectBackupRunsToPrune(runs, policy);
+  const freedBytes = prune.reduce((sum, run) => sum + run.sizeBytes, 0);
+  if (!asJson) {
+    console.log(
+      prune.length > 0
+        ? `Runs to prune (keeping ${keep.length}):\n${formatBackupRuns(prune)}`
+        : 'Nothing to prune'
+    );
+  }
+  if (prune.length === 0 || args.includes('--dry-run')) {
+    if (asJson) {
+      console.log(JSON.stringify({ prune, keep, dryRun: prune.length > 0 }, null, 2));
+    } else if (prune.length > 0) {
+      console.log('Dry run: nothing deleted.');
+    }
+    return finish(0);
+  }
+
+  await confirmDestructiveOperation(
+    target,
+    `Delete ${prune.length} backup run(s) (${(freedBytes / 1024 / 1024).toFixed(1)} MB)`
+  );
+  const deletedDocuments = await pruneBackupRuns(mongoose.connection, prune);
+  audit.addRows(deletedDocuments);
+  console.log(
+    asJson
+      ? JSON.stringify({ pruned: prune, kept: keep, deletedDocuments }, null, 2)
+      : `Pruned ${prune.length} run(s), ${deletedDocuments} backup document(s)`
+  );
+  return finish(0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[backups] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
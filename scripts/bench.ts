@@ -0,0 +1,147 @@
+/**
+ * Pipeline Benchmark Command
+ *
+ * Runs the core pipelines (dashboard stats, location aggregation, meters
+ * lookup) N times against a target environment, prints p50/p95 latency and
+ * documents scanned, and compares them with a stored baseline:
+ * `bun run bench -- --env reporting --iterations 20`.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --cases a,b           Cases to run (default: dashboard,locations,meters)
+ *   --iterations N        Timed runs per case (default 10)
+ *   --warmup N            Untimed runs per case first (default 1)
+ *   --licencee <id>       Licencee to benchmark (default: first by name)
+ *   --machine <id>        Machine for the meters lookup (default: most recently active)
+ *   --period <period>     Time period (default 7d)
+ *   --baseline <file>     Baseline file (default bench-baseline.json)
+ *   --save-baseline       Write this run to the baseline file instead of comparing
+ *   --tolerance N         Allowed regression in percent (default 25)
+ *   --json                Print the report as JSON
+ *
+ * Exit code: 0 = no regressions, 1 = regression vs baseline, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import {
+  BENCH_CASE_NAMES,
+  compareWithBaseline,
+  resolveBenchContext,
+  runBenchmarks,
+  toBenchBaseline,
+} from '../app/api/lib/helpers/benchmark';
+import type {
+  BenchBaseline,
+  BenchCaseName,
+} from '../app/api/lib/helpers/benchmark';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const DEFAULT_BASELINE_FILE = 'bench-baseline.json';
+
+function readNumberFlag(args: string[], name: string, fallback: number) {
+  const value = readFlag(args, name);
+  if (value === undefined) return fallback;
+  const parsed = Number(value);
+  if (!Number.isFinite(parsed) || parsed < 0) {
+    throw new Error(`${name} must be a non-negative number`);
+  }
+  return parsed;
+}
+
+const audit = startCommandAudit('bench');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const cases = (readFlag(args, '--cases') || BENCH_CASE_NAMES.join(','))
+    .split(',')
+    .map(name => name.trim())
+    .filter(Boolean) as BenchCaseName[];
+  const unknownCase = cases.find(name => !BENCH_CASE_NAMES.includes(name));
+  if (unknownCase) {
+    throw new Error(
+      `Unknown case '${unknownCase}' (expected ${BENCH_CASE_NAMES.join(', ')})`
+    );
+  }
+  const iterations = Math.max(1, readNumberFlag(args, '--iterations', 10));
+  const warmup = readNumberFlag(args, '--warmup', 1);
+  const tolerance = readNumberFlag(args, '--tolerance', 25);
+  const baselineFile = readFlag(args, '--baseline') || DEFAULT_BASELINE_FILE;
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  const context = await resolveBenchContext({
+    licencee: readFlag(args, '--licencee'),
+    machineId: readFlag(args, '--machine'),
+    timePeriod: readFlag(args, '--period'),
+  });
+  const report = await runBenchmarks(cases, context, { iterations, warmup });
+  audit.addRows(cases.length * iterations);
+
+  // Save or compare
+  if (args.includes('--save-baseline')) {
+    fs.writeFileSync(
+      baselineFile,
+      `${JSON.stringify(toBenchBaseline(report), null, 2)}\n`
+    );
+  }
+  const baseline =
+    !args.includes('--save-baseline') && fs.existsSync(baselineFile)
+      ? (JSON.parse(fs.readFileSync(baselineFile, 'utf8')) as BenchBaseline)
+      : null;
+  const regressions = baseline
+    ? compareWithBaseline(report, baseline, tolerance)
+    : [];
+
+  if (asJson) {
+    console.log(JSON.stringify({ target: target.name, report, regressions }));
+  } else {
+    console.log(
+      `Target: ${target.name}  licencee=${context.licencee} machine=${context.machineId} period=${context.timePeriod}`
+    );
+    console.table(
+      report.cases.map(result => ({
+        case: result.name,
+        runs: result.iterations,
+        'p50 ms': result.p50Ms,
+        'p95 ms': result.p95Ms,
+        'max ms': result.maxMs,
+        'docs scanned': result.docsScanned ?? 'n/a',
+        'keys scanned': result.keysScanned ?? 'n/a',
+      }))
+    );
+    if (args.includes('--save-baseline')) {
+      console.log(`Baseline written to ${baselineFile}`);
+    } else if (!baseline) {
+      console.log(
+        `No baseline at ${baselineFile}; run with --save-baseline to create one`
+      );
+    } else if (regressions.length === 0) {
+      console.log(`No regressions vs ${baselineFile} (tolerance ${tolerance}%)`);
+    } else {
+      console.log(`Regressions vs ${baselineFile} (tolerance ${tolerance}%):`);
+      regressions.forEach(regression =>
+        console.log(
+          `  ${regression.name} ${regression.metric}: ${regression.baseline} → ${regression.current} (+${regression.changePercent}%)`
+        )
+      );
+    }
+  }
+
+  const exitCode = regressions.length > 0 ? 1 : 0;
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error('[bench] Error:', error instanceof Error ? error.message : error);
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
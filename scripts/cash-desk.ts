@@ -0,0 +1,197 @@
+/**
+ * Cash Desk Command
+ *
+ * Records a location's cash desk float and manual payouts for a gaming day
+ * and reconciles the desk against the machines' cancelled-credit meters
+ * (see app/api/lib/helpers/cashDesk.ts):
+ * `bun run cash-desk -- payout <locationId> --amount 250 --machine SN1001`
+ * `bun run cash-desk -- reconcile --day 2026-10-16 --csv --out desk.csv`.
+ *
+ * Commands:
+ *   float <locationId>            Record the float
+ *     --opening N                 Opening float
+ *     --added N                   Float top-up (added to earlier top-ups)
+ *     --closing N                 Closing float
+ *     --notes <text>
+ *   payout <locationId>           Record a manual payout
+ *     --amount N                  Required. Amount paid
+ *     --machine <serial|id>       Machine the payout is for
+ *     --jackpot                   A jackpot payout (default: cancelled credits)
+ *     --note <text>
+ *   remove-payout <locationId> <payoutId>
+ *                                 Remove a payout recorded in error (asks
+ *                                 for confirmation)
+ *   show <locationId>             Print the day's float and payouts
+ *   reconcile                     Compare desks with meters
+ *     --licencee <id>             Only this licencee's locations
+ *     --location a,b              Only these locations
+ *     --threshold N               Flag variances above N (default 1)
+ *     --flagged-only              Only flagged locations
+ *     --json | --csv              Print as JSON or CSV
+ *     --out <path>                Write the JSON or CSV output to this file
+ *
+ * Options:
+ *   --day YYYY-MM-DD              Gaming day (default: yesterday's gaming day)
+ *   --env <profile>               Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --yes                         Skip the remove-payout confirmation
+ *   --read-only                   Block float, payout and remove-payout
+ *
+ * Exit codes: 0 = done (reconcile: nothing flagged), 1 = reconcile flagged
+ * locations or the run errored.
+ */
+
+import 'dotenv/config';
+import { writeFileSync } from 'fs';
+import mongoose from 'mongoose';
+import {
+  exportCashDeskReconciliationToCSV,
+  formatCashDeskReconciliation,
+  getCashDeskDay,
+  reconcileCashDesk,
+  recordCashDeskFloat,
+  recordCashDeskPayout,
+  removeCashDeskPayout,
+} from '../app/api/lib/helpers/cashDesk';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { getDefaultRollupDay } from '../app/api/lib/helpers/metersDaily';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { readFlag } from './lib/cliArgs';
+
+const COMMANDS = ['float', 'payout', 'remove-payout', 'show', 'reconcile'];
+
+function readAmount(args: string[], name: string): number | undefined {
+  const value = readFlag(args, name);
+  if (value === undefined) return undefined;
+  const amount = Number(value);
+  if (!Number.isFinite(amount) || amount < 0) {
+    throw new Error(`${name} must be a number of 0 or more`);
+  }
+  return amount;
+}
+
+const audit = startCommandAudit('cash-desk');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [command, locationId, payoutId] = args;
+  if (!COMMANDS.includes(command)) {
+    throw new Error(`Usage: cash-desk <${COMMANDS.join('|')}> [locationId]`);
+  }
+  if (command !== 'reconcile' && (!locationId || locationId.startsWith('--'))) {
+    throw new Error(`${command} requires a location ID`);
+  }
+  const day = readFlag(args, '--day') || getDefaultRollupDay();
+  const recordedBy = process.env.USER || null;
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  let exitCode = 0;
+
+  if (command === 'float') {
+    const deskDay = await recordCashDeskFloat({
+      locationId,
+      gamingDay: day,
+      openingFloat: readAmount(args, '--opening'),
+      floatAdded: readAmount(args, '--added'),
+      closingFloat: readAmount(args, '--closing'),
+      notes: readFlag(args, '--notes'),
+      recordedBy,
+    });
+    audit.addRows(1);
+    console.log(
+      `Float for ${locationId} on ${day}: opening ${deskDay.openingFloat ?? '-'}, added ${deskDay.floatAdded}, closing ${deskDay.closingFloat ?? '-'}`
+    );
+  } else if (command === 'payout') {
+    const amount = readAmount(args, '--amount');
+    if (!amount) throw new Error('--amount is required');
+    const { day: deskDay, payout } = await recordCashDeskPayout({
+      locationId,
+      gamingDay: day,
+      amount,
+      type: args.includes('--jackpot') ? 'jackpot' : 'cancelled-credit',
+      machine: readFlag(args, '--machine'),
+      note: readFlag(args, '--note'),
+      recordedBy,
+    });
+    audit.addRows(1);
+    console.log(
+      `Recorded ${payout.type} payout ${payout._id} of ${payout.amount}${payout.machineSerialNumber ? ` for ${payout.machineSerialNumber}` : ''} (${deskDay.payouts.length} payout(s) on ${day})`
+    );
+  } else if (command === 'remove-payout') {
+    if (!payoutId || payoutId.startsWith('--')) {
+      throw new Error('remove-payout requires a payout ID');
+    }
+    await confirmDestructiveOperation(
+      target,
+      `remove payout ${payoutId} from ${locationId} on ${day}`
+    );
+    const payout = await removeCashDeskPayout(locationId, day, payoutId);
+    audit.addRows(1);
+    console.log(`Removed payout ${payout._id} of ${payout.amount}`);
+  } else if (command === 'show') {
+    const deskDay = await getCashDeskDay(locationId, day);
+    if (!deskDay) {
+      console.log(`Nothing recorded for ${locationId} on ${day}`);
+    } else {
+      audit.addRows(deskDay.payouts.length);
+      console.log(
+        `Float: opening ${deskDay.openingFloat ?? '-'}, added ${deskDay.floatAdded}, closing ${deskDay.closingFloat ?? '-'}`
+      );
+      console.table(
+        deskDay.payouts.map(payout => ({
+          id: payout._id,
+          type: payout.type,
+          amount: payout.amount,
+          machine: payout.machineSerialNumber || '',
+          note: payout.note || '',
+          recordedBy: payout.recordedBy || '',
+          recordedAt: new Date(payout.recordedAt).toISOString(),
+        }))
+      );
+    }
+  } else {
+    const locationIds = (readFlag(args, '--location') || '')
+      .split(',')
+      .map(id => id.trim())
+      .filter(Boolean);
+    const report = await reconcileCashDesk({
+      day,
+      allowedLocationIds: locationIds.length > 0 ? locationIds : 'all',
+      licenceeId: readFlag(args, '--licencee'),
+      threshold: readAmount(args, '--threshold'),
+      flaggedOnly: args.includes('--flagged-only'),
+    });
+    audit.addRows(report.rows.length);
+
+    let output = formatCashDeskReconciliation(report);
+    if (args.includes('--csv')) {
+      output = exportCashDeskReconciliationToCSV(report);
+    } else if (args.includes('--json')) {
+      output = JSON.stringify(report, null, 2);
+    }
+    const outFile = readFlag(args, '--out');
+    if (outFile) {
+      writeFileSync(outFile, output);
+      console.log(formatCashDeskReconciliation(report));
+      console.log(`Written to ${outFile}`);
+    } else {
+      console.log(output);
+    }
+    exitCode = report.totals.flagged > 0 ? 1 : 0;
+  }
+
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[cash-desk] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 1, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(1);
+});
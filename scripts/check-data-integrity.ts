@@ -0,0 +1,438 @@
+/**
+ * Data Integrity Watchdog
+ *
+ * Runs the data integrity checks (app/api/lib/helpers/dataIntegrity.ts) and
+ * exits non-zero when any check exceeds its threshold — intended for nightly
+ * monitoring of production data: `bun run integrity -- --env prod --json`.
+ *
+ * Options:
+ *   --env <profile>           Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --checks a,b              Checks to run (default: all)
+ *   --threshold name=N        Allowed count for a check (repeatable, default 0)
+ *   --lookback-days N         Window for the negative meter, outlier and meter unit checks (default 7)
+ *   --outlier-sd N            Standard deviations that make a meter an outlier (default 4)
+ *   --outlier-min-samples N   Trailing readings a machine needs for the outlier check (default 20)
+ *   --unit-ratio N            Times above/below its location peers that flags a machine's meter unit (default 20)
+ *   --unit-min-peers N        Other machines a location needs for the meter unit check (default 3)
+ *   --sample N                Offending IDs to include per check (default 20)
+ *   --chronic-runs N          Consecutive runs a finding must appear in to count as chronic (default 3)
+ *   --no-track                Don't store the run in integrityRuns or compare it with earlier runs
+ *   --history N               Print the last N recorded runs instead of running the checks
+ *   --json                    Print the report as JSON
+ *   --webhook <url>           Post a summary to a webhook (or INTEGRITY_WEBHOOK_URL)
+ *   --report-file <path>      Also write the JSON report to this file
+ *   --html <path>             Also write an HTML report with charts (see integrityHtmlReport)
+ *
+ * Each run is stored in `integrityRuns` and compared with the previous runs of
+ * the same checks: new, resolved and chronic findings per check (see
+ * integrityTrends).
+ *
+ * JOB_WEBHOOK_URL / SLACK_WEBHOOK_URL are notified when the run finishes or
+ * errors (see jobNotifications).
+ *
+ * Issue queue (`integrityIssues`, see integrityIssues):
+ *   issues list [--status a,b] [--assignee <username> | --unassigned] [--check <name>] [--location <id>] [--limit N]
+ *   issues show <id>
+ *   issues claim <id> --user <username>          Assign to the user and start investigating
+ *   issues assign <id> <username|none>
+ *   issues release <id>                          Back to open, unassigned
+ *   issues resolve <id> [--note <text>]          Mark fixed
+ *   issues verify <id> [--note <text>]           Confirm the fix held
+ *   issues dismiss <id> [--note <text>]
+ *   issues reopen <id> [--note <text>]           Dismissed -> open; fixed/verified -> investigating
+ *   issues comment <id> <text>
+ * Status moves and comments are recorded as --user (default: the OS user).
+ *
+ * Exit codes: 0 = all checks passed, 1 = a check failed, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import { writeFile } from 'fs/promises';
+import { resolve } from 'path';
+import mongoose from 'mongoose';
+import {
+  DEFAULT_INTEGRITY_OPTIONS,
+  formatIntegritySummary,
+  INTEGRITY_CHECK_NAMES,
+  postIntegrityWebhook,
+  runIntegrityChecks,
+} from '../app/api/lib/helpers/dataIntegrity';
+import type {
+  IntegrityCheckName,
+  IntegrityOptions,
+} from '../app/api/lib/helpers/dataIntegrity';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  addIntegrityIssueComment,
+  assignIntegrityIssue,
+  claimIntegrityIssue,
+  formatIntegrityIssue,
+  getIntegrityIssue,
+  INTEGRITY_ISSUE_TRANSITIONS,
+  listIntegrityIssueQueue,
+  transitionIntegrityIssue,
+} from '../app/api/lib/helpers/integrityIssues';
+import {
+  buildIntegrityHtmlReportData,
+  formatIntegrityHtmlReport,
+} from '../app/api/lib/helpers/integrityHtmlReport';
+import {
+  formatIntegrityRunHistory,
+  formatIntegrityTrend,
+  getIntegrityRunHistory,
+} from '../app/api/lib/helpers/integrityTrends';
+import type { IntegrityTrend } from '../app/api/lib/helpers/integrityTrends';
+import type {
+  IntegrityIssueDocument,
+  IntegrityIssueStatus,
+} from '../shared/types';
+import {
+  notifyJobFinished,
+  writeJobReport,
+} from '../app/api/lib/helpers/jobNotifications';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlagValues } from './lib/cliArgs';
+
+function parseCheckName(value: string): IntegrityCheckName {
+  if (!INTEGRITY_CHECK_NAMES.includes(value as IntegrityCheckName)) {
+    throw new Error(
+      `Unknown check '${value}'. Available: ${INTEGRITY_CHECK_NAMES.join(', ')}`
+    );
+  }
+  return value as IntegrityCheckName;
+}
+
+function parseNonNegativeNumber(value: string, flag: string): number {
+  const parsed = Number(value);
+  if (!Number.isFinite(parsed) || parsed < 0) {
+    throw new Error(`${flag} must be a non-negative number`);
+  }
+  return parsed;
+}
+
+function parseOptions(args: string[]): IntegrityOptions {
+  const options: IntegrityOptions = {
+    ...DEFAULT_INTEGRITY_OPTIONS,
+    thresholds: {},
+  };
+
+  const checks = readFlagValues(args, '--checks');
+  if (checks.length > 0) {
+    options.checks = checks
+      .flatMap(value => value.split(','))
+      .map(value => parseCheckName(value.trim()));
+  }
+
+  readFlagValues(args, '--threshold').forEach(value => {
+    const [name, count] = value.split('=');
+    options.thresholds[parseCheckName(name)] = parseNonNegativeNumber(
+      count,
+      '--threshold'
+    );
+  });
+
+  const [lookback] = readFlagValues(args, '--lookback-days');
+  if (lookback) {
+    options.meterLookbackDays = parseNonNegativeNumber(lookback, '--lookback-days');
+  }
+  const [outlierSd] = readFlagValues(args, '--outlier-sd');
+  if (outlierSd) {
+    options.outlierStdDevs = parseNonNegativeNumber(outlierSd, '--outlier-sd');
+  }
+  const [minSamples] = readFlagValues(args, '--outlier-min-samples');
+  if (minSamples) {
+    options.outlierMinSamples = Math.floor(
+      parseNonNegativeNumber(minSamples, '--outlier-min-samples')
+    );
+  }
+  const [unitRatio] = readFlagValues(args, '--unit-ratio');
+  if (unitRatio) {
+    options.unitRatio = parseNonNegativeNumber(unitRatio, '--unit-ratio');
+  }
+  const [unitMinPeers] = readFlagValues(args, '--unit-min-peers');
+  if (unitMinPeers) {
+    options.unitMinPeers = Math.floor(
+      parseNonNegativeNumber(unitMinPeers, '--unit-min-peers')
+    );
+  }
+  const [sample] = readFlagValues(args, '--sample');
+  if (sample) {
+    options.sampleSize = Math.floor(parseNonNegativeNumber(sample, '--sample'));
+  }
+  const [chronicRuns] = readFlagValues(args, '--chronic-runs');
+  if (chronicRuns) {
+    options.chronicRuns = Math.floor(
+      parseNonNegativeNumber(chronicRuns, '--chronic-runs')
+    );
+    if (options.chronicRuns < 2) {
+      throw new Error('--chronic-runs must be at least 2');
+    }
+  }
+  options.trackRuns = !args.includes('--no-track');
+
+  return options;
+}
+
+function sumTrend(
+  trend: IntegrityTrend,
+  field: 'newCount' | 'resolvedCount' | 'chronicCount'
+): number {
+  return trend.checks.reduce((sum, check) => sum + check[field], 0);
+}
+
+/** Flags of the issues subcommand that take a value */
+const ISSUE_VALUE_FLAGS = [
+  '--env',
+  '--status',
+  '--assignee',
+  '--check',
+  '--location',
+  '--limit',
+  '--user',
+  '--note',
+];
+
+function positionalArgs(args: string[]): string[] {
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('--') && !ISSUE_VALUE_FLAGS.includes(args[index - 1])
+  );
+}
+
+function parseIssueStatuses(value: string): IntegrityIssueStatus[] {
+  const known = Object.keys(INTEGRITY_ISSUE_TRANSITIONS);
+  return value.split(',').map(status => {
+    const trimmed = status.trim();
+    if (!known.includes(trimmed)) {
+      throw new Error(
+        `Unknown status '${trimmed}'. Available: ${known.join(', ')}`
+      );
+    }
+    return trimmed as IntegrityIssueStatus;
+  });
+}
+
+function formatIssueRow(issue: IntegrityIssueDocument): string {
+  return `${issue._id}  ${issue.status.padEnd(13)}  ${(issue.assignedTo || '-').padEnd(12)}  ${issue.check}  ${issue.resourceId}${issue.details ? `  ${issue.details}` : ''}`;
+}
+
+/**
+ * Runs `issues <action>`. Assumes a database connection is open.
+ *
+ * @returns Rows touched, for the audit
+ */
+async function runIssuesCommand(args: string[]): Promise<number> {
+  const [action, id, ...rest] = positionalArgs(args);
+  const asJson = args.includes('--json');
+  const by = readFlagValues(args, '--user')[0] || getOperator();
+  const note = readFlagValues(args, '--note')[0];
+  const print = (issue: IntegrityIssueDocument) =>
+    console.log(
+      asJson ? JSON.stringify(issue, null, 2) : formatIntegrityIssue(issue)
+    );
+
+  if (action === 'list') {
+    const [statuses] = readFlagValues(args, '--status');
+    const [assignee] = readFlagValues(args, '--assignee');
+    const [limit] = readFlagValues(args, '--limit');
+    const issues = await listIntegrityIssueQueue({
+      statuses: statuses ? parseIssueStatuses(statuses) : undefined,
+      assignedTo: args.includes('--unassigned') ? null : assignee,
+      check: readFlagValues(args, '--check')[0],
+      location: readFlagValues(args, '--location')[0],
+      limit: limit ? Math.floor(parseNonNegativeNumber(limit, '--limit')) : 50,
+    });
+    if (asJson) console.log(JSON.stringify(issues, null, 2));
+    else if (issues.length === 0) console.log('No matching issues');
+    else issues.forEach(issue => console.log(formatIssueRow(issue)));
+    return issues.length;
+  }
+
+  if (!id) {
+    throw new Error(
+      'Usage: integrity issues <list|show|claim|assign|release|resolve|verify|dismiss|reopen|comment> <id>'
+    );
+  }
+
+  switch (action) {
+    case 'show': {
+      const issue = await getIntegrityIssue(id);
+      if (!issue) throw new Error(`Integrity issue '${id}' not found`);
+      print(issue);
+      return 1;
+    }
+    case 'claim': {
+      const [username] = readFlagValues(args, '--user');
+      if (!username) throw new Error('--user <username> is required');
+      print(await claimIntegrityIssue(id, username));
+      return 1;
+    }
+    case 'assign': {
+      const [assignee] = rest;
+      if (!assignee) {
+        throw new Error('Usage: integrity issues assign <id> <username|none>');
+      }
+      print(
+        await assignIntegrityIssue(id, assignee === 'none' ? null : assignee)
+      );
+      return 1;
+    }
+    case 'release':
+      print(await transitionIntegrityIssue(id, 'open', { by, note }));
+      return 1;
+    case 'resolve':
+      print(await transitionIntegrityIssue(id, 'fixed', { by, note }));
+      return 1;
+    case 'verify':
+      print(await transitionIntegrityIssue(id, 'verified', { by, note }));
+      return 1;
+    case 'dismiss':
+      print(await transitionIntegrityIssue(id, 'dismissed', { by, note }));
+      return 1;
+    case 'reopen': {
+      const issue = await getIntegrityIssue(id);
+      if (!issue) throw new Error(`Integrity issue '${id}' not found`);
+      const to = issue.status === 'dismissed' ? 'open' : 'investigating';
+      print(await transitionIntegrityIssue(id, to, { by, note }));
+      return 1;
+    }
+    case 'comment': {
+      const text = rest.join(' ');
+      if (!text) throw new Error('Usage: integrity issues comment <id> <text>');
+      print(await addIntegrityIssueComment(id, by, text));
+      return 1;
+    }
+    default:
+      throw new Error(`Unknown issues action '${action}'`);
+  }
+}
+
+const audit = startCommandAudit('integrity');
+const startedAt = new Date();
+const issuesMode = process.argv[2] === 'issues';
+let targetName: string | undefined;
+
+async function main() {
+  const args = process.argv.slice(2);
+
+  if (issuesMode) {
+    const target = await connectCommandDatabase();
+    audit.setTarget(target.name);
+    audit.addRows(await runIssuesCommand(args.slice(1)));
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    process.exit(0);
+  }
+
+  const options = parseOptions(args);
+  const asJson = args.includes('--json');
+  const webhookUrl =
+    readFlagValues(args, '--webhook')[0] || process.env.INTEGRITY_WEBHOOK_URL;
+
+  const reportFile = readFlagValues(args, '--report-file')[0];
+  const htmlFile = readFlagValues(args, '--html')[0];
+  const [historyFlag] = readFlagValues(args, '--history');
+
+  const target = await connectCommandDatabase();
+  targetName = target.name;
+  audit.setTarget(target.name);
+
+  if (historyFlag) {
+    const limit = Math.max(
+      Math.floor(parseNonNegativeNumber(historyFlag, '--history')),
+      1
+    );
+    const runs = await getIntegrityRunHistory(limit);
+    audit.addRows(runs.length);
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    console.log(
+      asJson ? JSON.stringify(runs, null, 2) : formatIntegrityRunHistory(runs)
+    );
+    process.exit(0);
+  }
+
+  const report = await runIntegrityChecks(options);
+  const findings = report.checks.reduce((sum, check) => sum + check.count, 0);
+  const html = htmlFile
+    ? formatIntegrityHtmlReport(await buildIntegrityHtmlReportData(report))
+    : null;
+  audit.addRows(findings);
+  await audit.finish({ success: true, exitCode: report.passed ? 0 : 1 });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    console.log(formatIntegritySummary(report));
+    report.checks
+      .filter(check => !check.passed && check.sample.length > 0)
+      .forEach(check => {
+        console.log(`\n${check.name} (first ${check.sample.length}):`);
+        check.sample.forEach(item => console.log(`  ${item}`));
+      });
+    if (report.trend) {
+      console.log(`\n${formatIntegrityTrend(report.trend)}`);
+    }
+  }
+
+  if (htmlFile && html) {
+    await writeFile(resolve(htmlFile), html);
+    if (!asJson) console.log(`\nHTML report written to ${resolve(htmlFile)}`);
+  }
+
+  if (webhookUrl) {
+    await postIntegrityWebhook(webhookUrl, report);
+  }
+  await notifyJobFinished({
+    job: 'integrity',
+    kind: 'detection',
+    status: 'completed',
+    target: target.name,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {
+      checks: report.checks.length,
+      failedChecks: report.checks.filter(check => !check.passed).length,
+      findings,
+      ...(report.trend
+        ? {
+            newFindings: sumTrend(report.trend, 'newCount'),
+            resolvedFindings: sumTrend(report.trend, 'resolvedCount'),
+            chronicFindings: sumTrend(report.trend, 'chronicCount'),
+          }
+        : {}),
+    },
+    summary: `Data integrity ${report.passed ? 'passed' : 'FAILED'}`,
+    report: reportFile ? await writeJobReport(reportFile, report) : undefined,
+  });
+
+  process.exit(report.passed ? 0 : 1);
+}
+
+main().catch(async error => {
+  console.error(
+    '[integrity] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  if (issuesMode) {
+    await mongoose.disconnect().catch(() => undefined);
+    process.exit(2);
+  }
+  await notifyJobFinished({
+    job: 'integrity',
+    kind: 'detection',
+    status: 'failed',
+    target: targetName,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {},
+    error: error instanceof Error ? error.message : String(error),
+  });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
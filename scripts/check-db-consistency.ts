@@ -0,0 +1,245 @@
+/**
+ * Cross-Database Consistency Checker
+ *
+ * Continuously compares recent writes between a source and destination MongoDB
+ * while both are live after a migration, and reports divergence so we know when
+ * it is safe to cut over: `bun run consistency -- --source prod --dest staging`.
+ *
+ * Options:
+ *   --source <profile>        Source database profile (default: SRC_MONGODB_URI)
+ *   --dest <profile>          Destination database profile (default: DST_MONGODB_URI)
+ *   --collections a,b:field   Collections to compare, optionally with the time field
+ *                             marking a write (default: meters,machines,gaminglocations)
+ *   --window-minutes N        Compare writes from the last N minutes (default 15)
+ *   --settle-seconds N        Ignore the newest N seconds of writes (default 30)
+ *   --interval-seconds N      Repeat every N seconds (default: run once)
+ *   --json                    Print each report as JSON
+ *   --report-file <path>      Also write the (last) JSON report to this file
+ *
+ * JOB_WEBHOOK_URL / SLACK_WEBHOOK_URL are notified when the run finishes or
+ * errors (see jobNotifications).
+ *
+ * Exit code (single run): 0 = consistent, 1 = divergence, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import type { Connection } from 'mongoose';
+import {
+  compareRecentWrites,
+  DEFAULT_CONSISTENCY_OPTIONS,
+  parseConsistencyTarget,
+} from '../app/api/lib/helpers/dbConsistency';
+import type { ConsistencyReport } from '../app/api/lib/helpers/dbConsistency';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  notifyJobFinished,
+  writeJobReport,
+} from '../app/api/lib/helpers/jobNotifications';
+import {
+  DEFAULT_CONNECT_OPTIONS,
+  redactMongoUri,
+  resolveDbProfile,
+} from '../app/api/lib/utils/dbProfiles';
+import { getSecret } from '../app/api/lib/utils/secrets';
+import { readFlag } from './lib/cliArgs';
+
+const DEFAULT_COLLECTIONS = 'meters,machines,gaminglocations';
+
+function readNumberFlag(args: string[], name: string, fallback: number) {
+  const value = readFlag(args, name);
+  if (value === undefined) return fallback;
+  const parsed = Number(value);
+  if (!Number.isFinite(parsed) || parsed < 0) {
+    throw new Error(`${name} must be a non-negative number`);
+  }
+  return parsed;
+}
+
+async function openConnection(
+  profile: string | undefined,
+  fallbackEnv: string
+): Promise<{ label: string; connection: Connection }> {
+  if (profile) {
+    const resolved = await resolveDbProfile(profile);
+    return {
+      label: resolved.name,
+      connection: await mongoose
+        .createConnection(resolved.uri, resolved.options)
+        .asPromise(),
+    };
+  }
+
+  const uri = await getSecret(fallbackEnv);
+  if (!uri) {
+    throw new Error(`Pass a profile or set ${fallbackEnv}`);
+  }
+  return {
+    label: redactMongoUri(uri),
+    connection: await mongoose
+      .createConnection(uri, DEFAULT_CONNECT_OPTIONS)
+      .asPromise(),
+  };
+}
+
+function printReport(report: ConsistencyReport, asJson: boolean) {
+  if (asJson) {
+    console.log(JSON.stringify(report));
+    return;
+  }
+
+  console.log(
+    `[${report.checkedAt.toISOString()}] window ${report.windowStart.toISOString()} → ${report.windowEnd.toISOString()}: ${report.consistent ? 'CONSISTENT' : 'DIVERGED'}`
+  );
+  report.collections.forEach(collection => {
+    console.log(
+      `  ${collection.consistent ? 'OK  ' : 'DIFF'} ${collection.collection} (${collection.timeField}): source=${collection.sourceCount} dest=${collection.destinationCount} missingOnDest=${collection.missingOnDestination} missingOnSource=${collection.missingOnSource} mismatched=${collection.mismatched}`
+    );
+    collection.sample.forEach(entry => console.log(`       ${entry}`));
+  });
+}
+
+const audit = startCommandAudit('consistency');
+const startedAt = new Date();
+let targetName: string | undefined;
+
+function countRows(report: ConsistencyReport): number {
+  return report.collections.reduce(
+    (sum, collection) => sum + collection.sourceCount,
+    0
+  );
+}
+
+async function notifyFinished(
+  report: ConsistencyReport,
+  runs: number,
+  divergedRuns: number,
+  reportFile: string | undefined
+) {
+  await notifyJobFinished({
+    job: 'consistency',
+    kind: 'detection',
+    status: 'completed',
+    target: targetName,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {
+      runs,
+      divergedRuns,
+      compared: countRows(report),
+      missingOnDest: report.collections.reduce(
+        (sum, collection) => sum + collection.missingOnDestination,
+        0
+      ),
+      mismatched: report.collections.reduce(
+        (sum, collection) => sum + collection.mismatched,
+        0
+      ),
+    },
+    summary: `Last window ${report.consistent ? 'consistent' : 'DIVERGED'}`,
+    report: reportFile ? await writeJobReport(reportFile, report) : undefined,
+  });
+}
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const targets = (readFlag(args, '--collections') || DEFAULT_COLLECTIONS)
+    .split(',')
+    .filter(Boolean)
+    .map(parseConsistencyTarget);
+  const options = {
+    ...DEFAULT_CONSISTENCY_OPTIONS,
+    windowMinutes: readNumberFlag(
+      args,
+      '--window-minutes',
+      DEFAULT_CONSISTENCY_OPTIONS.windowMinutes
+    ),
+    settleSeconds: readNumberFlag(
+      args,
+      '--settle-seconds',
+      DEFAULT_CONSISTENCY_OPTIONS.settleSeconds
+    ),
+  };
+  const intervalSeconds = readNumberFlag(args, '--interval-seconds', 0);
+  const reportFile = readFlag(args, '--report-file');
+
+  const source = await openConnection(
+    readFlag(args, '--source'),
+    'SRC_MONGODB_URI'
+  );
+  const destination = await openConnection(
+    readFlag(args, '--dest'),
+    'DST_MONGODB_URI'
+  );
+  targetName = `${source.label} -> ${destination.label}`;
+  audit.setTarget(targetName);
+  if (!asJson) {
+    console.log(`Source:      ${source.label}`);
+    console.log(`Destination: ${destination.label}`);
+  }
+
+  if (intervalSeconds === 0) {
+    const report = await compareRecentWrites(
+      source.connection,
+      destination.connection,
+      targets,
+      options
+    );
+    printReport(report, asJson);
+    audit.addRows(countRows(report));
+    await audit.finish(
+      { success: true, exitCode: report.consistent ? 0 : 1 },
+      source.connection
+    );
+    await notifyFinished(report, 1, report.consistent ? 0 : 1, reportFile);
+    await Promise.all([source.connection.close(), destination.connection.close()]);
+    process.exit(report.consistent ? 0 : 1);
+  }
+
+  let stopping = false;
+  let runs = 0;
+  let divergedRuns = 0;
+  let lastReport: ConsistencyReport | null = null;
+  process.on('SIGINT', () => {
+    stopping = true;
+  });
+  while (!stopping) {
+    const report = await compareRecentWrites(
+      source.connection,
+      destination.connection,
+      targets,
+      options
+    );
+    printReport(report, asJson);
+    audit.addRows(countRows(report));
+    runs++;
+    if (!report.consistent) divergedRuns++;
+    lastReport = report;
+    await new Promise(resolve => setTimeout(resolve, intervalSeconds * 1000));
+  }
+  await audit.finish({ success: true, exitCode: 0 }, source.connection);
+  if (lastReport) {
+    await notifyFinished(lastReport, runs, divergedRuns, reportFile);
+  }
+  await Promise.all([source.connection.close(), destination.connection.close()]);
+}
+
+main().catch(async error => {
+  console.error(
+    '[consistency] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await notifyJobFinished({
+    job: 'consistency',
+    kind: 'detection',
+    status: 'failed',
+    target: targetName,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {},
+    error: error instanceof Error ? error.message : String(error),
+  });
+  process.exit(2);
+});
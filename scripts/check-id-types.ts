@@ -0,0 +1,83 @@
+/**
+ * Id Type Diagnostics
+ *
+ * Reports how `_id` and id reference fields are stored per collection
+ * (string vs objectId) so mixed-type data can be found before it breaks
+ * lookups or a migration: `bun run id-types -- --env prod --sample 10000`.
+ *
+ * Options:
+ *   --env <profile>           Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --fields a,b:field        Collections (their `_id`) or collection:field pairs
+ *                             (default: `_id` and main references of core collections)
+ *   --sample N                Inspect N random documents per collection (default: all)
+ *   --json                    Print the report as JSON
+ *
+ * Exit codes: 0 = no mixed types, 1 = a field has mixed types, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DEFAULT_ID_TYPE_FIELDS,
+  formatIdTypeReport,
+  getIdTypeReport,
+  parseIdTypeField,
+} from '../app/api/lib/helpers/idTypeDiagnostics';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('id-types');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+
+  const fieldsFlag = readFlag(args, '--fields');
+  const fields = fieldsFlag
+    ? fieldsFlag.split(',').filter(Boolean).map(parseIdTypeField)
+    : DEFAULT_ID_TYPE_FIELDS;
+
+  const sampleFlag = readFlag(args, '--sample');
+  const sampleSize = sampleFlag ? Math.floor(Number(sampleFlag)) : null;
+  if (
+    sampleSize !== null &&
+    (!Number.isFinite(sampleSize) || sampleSize < 1)
+  ) {
+    throw new Error('--sample must be a positive number');
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const report = await getIdTypeReport(
+    mongoose.connection,
+    fields,
+    sampleSize
+  );
+  audit.addRows(report.fields.reduce((sum, field) => sum + field.examined, 0));
+  await audit.finish({ success: true, exitCode: report.mixed ? 1 : 0 });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    console.log(formatIdTypeReport(report));
+    if (report.mixed) {
+      console.log(
+        '\nMixed id types found; string and ObjectId queries will miss each other.'
+      );
+    }
+  }
+
+  process.exit(report.mixed ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[id-types] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,81 @@
+/**
+ * Inline Credentials Check
+ *
+ * Scans the repository for connection URIs with embedded `user:password@`
+ * credentials and exits non-zero when any are found. Run before committing or
+ * in CI: `bun run check:secrets`.
+ *
+ * Placeholders such as `mongodb://<user>:<pass>@<host>` are ignored.
+ * Move real credentials to `.env`, a `*_FILE` secret file or a secret manager
+ * reference (see app/api/lib/utils/secrets.ts).
+ */
+
+import fs from 'fs';
+import path from 'path';
+import { containsInlineCredentials } from '../app/api/lib/utils/secrets';
+
+const ROOT = path.resolve(__dirname, '..');
+const SKIP_DIRS = new Set([
+  'node_modules',
+  '.git',
+  '.next',
+  '.next-preview',
+  'playwright-report',
+  'migration_exports',
+]);
+const SCAN_EXTENSIONS = new Set([
+  '.ts',
+  '.tsx',
+  '.js',
+  '.mjs',
+  '.cjs',
+  '.json',
+  '.yml',
+  '.yaml',
+  '.md',
+  '.sh',
+]);
+const PLACEHOLDER_PATTERN = /:\/\/(<[^>]+>|\.\.\.|\$\{?[A-Z_]+\}?|user):/i;
+
+type Finding = { file: string; line: number; text: string };
+
+function walk(dir: string, findings: Finding[]) {
+  for (const entry of fs.readdirSync(dir, { withFileTypes: true })) {
+    if (SKIP_DIRS.has(entry.name)) continue;
+    const fullPath = path.join(dir, entry.name);
+    if (entry.isDirectory()) {
+      walk(fullPath, findings);
+      continue;
+    }
+    if (!SCAN_EXTENSIONS.has(path.extname(entry.name))) continue;
+
+    const lines = fs.readFileSync(fullPath, 'utf8').split('\n');
+    lines.forEach((text, index) => {
+      if (containsInlineCredentials(text) && !PLACEHOLDER_PATTERN.test(text)) {
+        findings.push({
+          file: path.relative(ROOT, fullPath),
+          line: index + 1,
+          text: text.trim().replace(/\/\/([^@/\s]+)@/, '//***@'),
+        });
+      }
+    });
+  }
+}
+
+function main() {
+  const findings: Finding[] = [];
+  walk(ROOT, findings);
+
+  if (findings.length === 0) {
+    console.log('No inline credentials found.');
+    return;
+  }
+
+  console.error(`Found ${findings.length} inline credential(s):`);
+  findings.forEach(finding => {
+    console.error(`  ${finding.file}:${finding.line}  ${finding.text}`);
+  });
+  process.exit(1);
+}
+
+main();
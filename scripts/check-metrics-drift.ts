@@ -0,0 +1,139 @@
+/**
+ * casinoMetrics Drift Check
+ *
+ * Recomputes the Today / 7d / 30d dashboard totals (or any configured
+ * timeframe, see metricsTimeframes) of a sample of users (or specific ones)
+ * directly from meters and compares them with the stored `casinoMetrics`
+ * documents, reporting drift per user and timeframe:
+ * `bun run metrics-drift -- --env prod --usernames alice,bob`.
+ *
+ * Options:
+ *   --env <profile>        Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --users a,b            User ids to check
+ *   --usernames a,b        Usernames to check
+ *   --sample N             Random users to check when none are named (default 10)
+ *   --timeframes a,b       Timeframe names, e.g. Today, 7d, 30d, MTD (default: Today,7d,30d)
+ *   --max-drift N          Allowed drift in percent (default 1)
+ *   --json                 Print the report as JSON
+ *
+ * Exit codes: 0 = within limits, 1 = drift over the limit or a user has no
+ * stored metrics, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  crossCheckUserMetrics,
+  DEFAULT_CROSS_CHECK_OPTIONS,
+} from '../app/api/lib/helpers/users/metricsFreshness';
+import type {
+  MetricsCrossCheckReport,
+  MetricsTimeframe,
+} from '../app/api/lib/helpers/users/metricsFreshness';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { loadMetricsTimeframes } from '../app/api/lib/utils/metricsTimeframes';
+import { readFlag } from './lib/cliArgs';
+
+function readList(args: string[], name: string): string[] {
+  return (readFlag(args, name) || '')
+    .split(',')
+    .map(value => value.trim())
+    .filter(Boolean);
+}
+
+function parseTimeframe(value: string): MetricsTimeframe {
+  const timeframes = loadMetricsTimeframes();
+  if (!timeframes[value]) {
+    throw new Error(
+      `Unknown timeframe '${value}'. Available: ${Object.keys(
+        timeframes
+      ).join(', ')}`
+    );
+  }
+  return value as MetricsTimeframe;
+}
+
+function formatAmount(value: number): string {
+  return value.toFixed(2).padStart(14);
+}
+
+function printReport(report: MetricsCrossCheckReport) {
+  console.log(
+    `casinoMetrics cross-check ${report.healthy ? 'passed' : 'FAILED'} at ${report.checkedAt.toISOString()} (max drift ${report.thresholds.maxDriftPercent}%)`
+  );
+  report.byTimeframe.forEach(entry => {
+    console.log(
+      `  ${entry.timeframe.padEnd(6)} users=${entry.users} drifted=${entry.driftedUsers} max=${entry.maxDriftPercent}%`
+    );
+  });
+  console.log(
+    `  ${report.computation.users} user(s) over ${report.computation.uniqueLocationSets} location set(s); ${report.computation.aggregations} meter aggregation(s)`
+  );
+
+  report.users.forEach(user => {
+    console.log(
+      `\n${user.username} (${user.userId}) lastUpdated=${
+        user.lastUpdated ? user.lastUpdated.toISOString() : 'never'
+      }${user.hasMetrics ? '' : '  NO casinoMetrics DOCUMENT'}`
+    );
+    user.timeframes.forEach(entry => {
+      const flag =
+        entry.maxDriftPercent > report.thresholds.maxDriftPercent
+          ? 'DRIFT'
+          : 'OK   ';
+      (['moneyIn', 'moneyOut', 'gross'] as const).forEach(field => {
+        console.log(
+          `  ${flag} ${entry.timeframe.padEnd(6)} ${field.padEnd(8)} stored=${
+            entry.stored ? formatAmount(entry.stored[field]) : '     (missing)'
+          } fresh=${formatAmount(entry.fresh[field])}  ${entry.driftPercent[field]}%`
+        );
+      });
+    });
+  });
+}
+
+const audit = startCommandAudit('metrics-drift');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const timeframes = readList(args, '--timeframes').map(parseTimeframe);
+  const sample = Number(readFlag(args, '--sample'));
+  const maxDrift = Number(readFlag(args, '--max-drift'));
+  const options = {
+    ...DEFAULT_CROSS_CHECK_OPTIONS,
+    userIds: readList(args, '--users'),
+    usernames: readList(args, '--usernames'),
+    ...(timeframes.length > 0 ? { timeframes } : {}),
+    ...(sample > 0 ? { sampleSize: Math.floor(sample) } : {}),
+    ...(maxDrift >= 0 && readFlag(args, '--max-drift')
+      ? { maxDriftPercent: maxDrift }
+      : {}),
+  };
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const report = await crossCheckUserMetrics(options);
+  audit.addRows(report.users.length);
+  const exitCode = report.healthy ? 0 : 1;
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    printReport(report);
+  }
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[metrics-drift] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,133 @@
+/**
+ * OpenAPI Sync Check
+ *
+ * Verifies that every operation in the OpenAPI document (the route schemas
+ * in app/api/lib/routeSchemas) still has a handler: the route file for
+ * its path exists and exports its method. Also fails when a route file
+ * that uses `withApiAuth` exports a handler the document does not cover.
+ * Run before committing or in CI: `bun run openapi:check`.
+ *
+ * Options:
+ *   --undocumented    Also list the route handlers the document does not cover
+ *   --out <file>      Write the generated document to a file
+ */
+
+import fs from 'fs';
+import path from 'path';
+import {
+  API_OPERATIONS,
+  buildOpenApiDocument,
+} from '../app/api/lib/helpers/openapi';
+import { readFlag } from './lib/cliArgs';
+
+const ROOT = path.resolve(__dirname, '..');
+const API_DIR = path.join(ROOT, 'app', 'api');
+const METHOD_PATTERN =
+  /export\s+(?:async\s+)?function\s+(GET|POST|PUT|PATCH|DELETE)\b/g;
+
+/** `/api/machines/{machineId}` -> app/api/machines/[machineId]/route.ts */
+function routeFile(apiPath: string): string {
+  return path.join(
+    ROOT,
+    'app',
+    ...apiPath
+      .split('/')
+      .filter(Boolean)
+      .map(segment => segment.replace(/^\{(\w+)\}$/, '[$1]')),
+    'route.ts'
+  );
+}
+
+function exportedMethods(file: string): string[] {
+  const source = fs.readFileSync(file, 'utf8');
+  return Array.from(source.matchAll(METHOD_PATTERN), match => match[1]);
+}
+
+type Handler = { label: string; authenticated: boolean };
+
+/** Every `METHOD /api/...` the route files export */
+function listHandlers(dir: string, handlers: Handler[]) {
+  for (const entry of fs.readdirSync(dir, { withFileTypes: true })) {
+    const fullPath = path.join(dir, entry.name);
+    if (entry.isDirectory()) {
+      if (entry.name !== 'lib') listHandlers(fullPath, handlers);
+      continue;
+    }
+    if (entry.name !== 'route.ts') continue;
+    const apiPath =
+      '/' +
+      path
+        .relative(path.join(ROOT, 'app'), dir)
+        .split(path.sep)
+        .map(segment => segment.replace(/^\[(\w+)\]$/, '{$1}'))
+        .join('/');
+    const authenticated = fs
+      .readFileSync(fullPath, 'utf8')
+      .includes('withApiAuth(');
+    exportedMethods(fullPath).forEach(method =>
+      handlers.push({ label: `${method} ${apiPath}`, authenticated })
+    );
+  }
+}
+
+function main() {
+  const args = process.argv.slice(2);
+  const missing: string[] = [];
+  API_OPERATIONS.forEach(operation => {
+    const file = routeFile(operation.path);
+    const label = `${operation.method} ${operation.path}`;
+    if (!fs.existsSync(file)) {
+      missing.push(`${label}: no ${path.relative(ROOT, file)}`);
+    } else if (!exportedMethods(file).includes(operation.method)) {
+      missing.push(
+        `${label}: ${path.relative(ROOT, file)} does not export ${operation.method}`
+      );
+    }
+  });
+
+  const outFile = readFlag(args, '--out');
+  if (outFile) {
+    fs.writeFileSync(
+      outFile,
+      `${JSON.stringify(buildOpenApiDocument(), null, 2)}\n`
+    );
+    console.log(`Wrote ${outFile}`);
+  }
+
+  const documented = new Set(
+    API_OPERATIONS.map(operation => `${operation.method} ${operation.path}`)
+  );
+  const handlers: Handler[] = [];
+  listHandlers(API_DIR, handlers);
+  const undocumented = handlers.filter(
+    handler => !documented.has(handler.label)
+  );
+  console.log(
+    `${API_OPERATIONS.length} documented operation(s); ${undocumented.length} of ${handlers.length} handler(s) undocumented.`
+  );
+  if (args.includes('--undocumented')) {
+    undocumented
+      .map(handler => handler.label)
+      .sort()
+      .forEach(label => console.log(`  ${label}`));
+  }
+
+  const uncovered = undocumented.filter(handler => handler.authenticated);
+
+  let failed = false;
+  if (missing.length > 0) {
+    console.error(`Found ${missing.length} operation(s) without a handler:`);
+    missing.forEach(entry => console.error(`  ${entry}`));
+    failed = true;
+  }
+  if (uncovered.length > 0) {
+    console.error(
+      `Found ${uncovered.length} withApiAuth handler(s) not in the document; declare them in app/api/lib/routeSchemas:`
+    );
+    uncovered.forEach(handler => console.error(`  ${handler.label}`));
+    failed = true;
+  }
+  if (failed) process.exit(1);
+}
+
+main();
@@ -0,0 +1,176 @@
+/**
+ * Date Coercion Command
+ *
+ * Converts dates stored as strings (e.g. meter `readAt`, event `date`) to
+ * BSON dates, after backing up the old values, so `$gte` / `$lte` range
+ * filters match them: `bun run coerce-dates -- --env staging --dry-run`.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --dry-run             Only count what would be converted
+ *   --fields a:b,c:d      collection:field pairs to convert (default: known date fields)
+ *   --run-id <id>         Backup id for this run (default: timestamp)
+ *   --restore <run-id>    Put back the strings a run backed up and exit
+ *   --yes                 Skip the confirmation prompt
+ *   --json                Print the report as JSON
+ *   --report-file <path>  Also write the JSON report to this file
+ *
+ * Strings that do not parse as dates are counted and left unchanged.
+ *
+ * After a run, older backup runs are pruned when BACKUP_KEEP_LAST,
+ * BACKUP_MAX_AGE_DAYS or BACKUP_MAX_SIZE_MB is set (see `bun run backups`).
+ *
+ * JOB_WEBHOOK_URL / SLACK_WEBHOOK_URL are notified when a run (not a dry run)
+ * finishes or errors (see jobNotifications).
+ *
+ * Exit codes: 0 = done (or nothing to do), 1 = dry run found values to convert, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { applyBackupRetention } from '../app/api/lib/helpers/backupRetention';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DATE_COERCION_BACKUP_COLLECTION,
+  coerceStringDates,
+  formatDateCoercionReport,
+  parseDateCoercionField,
+  restoreDateCoercionBackup,
+} from '../app/api/lib/helpers/dateCoercion';
+import {
+  notifyJobFinished,
+  writeJobReport,
+} from '../app/api/lib/helpers/jobNotifications';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('coerce-dates');
+const startedAt = new Date();
+let targetName: string | undefined;
+let notifyOnError = false;
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const dryRun = args.includes('--dry-run');
+  const reportFile = readFlag(args, '--report-file');
+  const fieldsFlag = readFlag(args, '--fields');
+  const fields = fieldsFlag
+    ? fieldsFlag.split(',').filter(Boolean).map(parseDateCoercionField)
+    : undefined;
+
+  const target = await connectCommandDatabase();
+  targetName = target.name;
+  audit.setTarget(target.name);
+
+  // Restore mode
+  const restoreRunId = readFlag(args, '--restore');
+  if (restoreRunId) {
+    await confirmDestructiveOperation(
+      target,
+      `Restore string dates backed up by run ${restoreRunId}`
+    );
+    const restored = await restoreDateCoercionBackup(
+      mongoose.connection,
+      restoreRunId
+    );
+    audit.addRows(restored);
+    console.log(`Restored ${restored} string date values`);
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    process.exit(0);
+  }
+
+  const runId = readFlag(args, '--run-id') || String(Date.now());
+  if (!dryRun) {
+    await confirmDestructiveOperation(
+      target,
+      `Convert string dates to BSON dates (backup run ${runId})`
+    );
+    notifyOnError = true;
+  }
+
+  const report = await coerceStringDates(mongoose.connection, {
+    runId,
+    dryRun,
+    fields,
+  });
+  const retention = dryRun
+    ? null
+    : await applyBackupRetention(mongoose.connection, 'date-coercion');
+  const exitCode = dryRun && report.total > 0 ? 1 : 0;
+  audit.addRows(report.total);
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+
+  const reportPath = reportFile
+    ? await writeJobReport(reportFile, report)
+    : undefined;
+  if (!dryRun) {
+    await notifyJobFinished({
+      job: 'coerce-dates',
+      kind: 'migration',
+      status: 'completed',
+      target: target.name,
+      startedAt,
+      finishedAt: report.finishedAt,
+      counts: {
+        fields: report.fields.length,
+        converted: report.total,
+        unparseable: report.fields.reduce(
+          (sum, result) => sum + result.unparseable,
+          0
+        ),
+      },
+      summary: `Backup run ${runId}`,
+      report: reportPath,
+    });
+  }
+
+  if (asJson) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    console.log(formatDateCoercionReport(report));
+    if (dryRun) {
+      console.log(`\n${report.total} values would be converted (dry run)`);
+    } else {
+      console.log(
+        [
+          '',
+          `${report.total} values converted; old strings in ${DATE_COERCION_BACKUP_COLLECTION} (run ${runId})`,
+          retention && retention.pruned.length > 0
+            ? `Pruned ${retention.pruned.length} older backup run(s) by retention policy`
+            : '',
+          `Undo with: bun run coerce-dates -- --env ${target.name} --restore ${runId}`,
+        ]
+          .filter(Boolean)
+          .join('\n')
+      );
+    }
+  }
+
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[coerce-dates] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  if (notifyOnError) {
+    await notifyJobFinished({
+      job: 'coerce-dates',
+      kind: 'migration',
+      status: 'failed',
+      target: targetName,
+      startedAt,
+      finishedAt: new Date(),
+      counts: {},
+      error: error instanceof Error ? error.message : String(error),
+    });
+  }
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
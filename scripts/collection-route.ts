@@ -0,0 +1,86 @@
+/**
+ * Collection Route Command
+ *
+ * Suggests a collector's route for a day: their pending scheduled locations
+ * in visit order with the distance of each leg (see
+ * app/api/lib/helpers/collectionRoutes.ts), exportable for the field app:
+ * `bun run collection-route -- --collector jdoe --day 2026-10-20`
+ * `bun run collection-route -- --collector jdoe --geojson --out route.geojson`.
+ *
+ * Options:
+ *   --collector <username|id>  Required. The collector the schedules are assigned to
+ *   --day <YYYY-MM-DD>         Day (UTC) whose schedules are due; default today
+ *   --start <lat,lng>          Where the collector sets off; default the
+ *                              location with the earliest window
+ *   --csv                      Print the route as CSV
+ *   --geojson                  Print the route as GeoJSON (stops and path)
+ *   --json                     Print the route as JSON
+ *   --out <path>               Write the CSV, GeoJSON or JSON output to this file
+ *   --env <profile>            Database profile (see dbProfiles); defaults to MONGODB_URI
+ */
+
+import 'dotenv/config';
+import { writeFileSync } from 'fs';
+import mongoose from 'mongoose';
+import {
+  exportCollectionRouteToCSV,
+  exportCollectionRouteToGeoJSON,
+  formatCollectionRoute,
+  parseCoordinates,
+  planCollectionRoute,
+} from '../app/api/lib/helpers/collectionRoutes';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('collection-route');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const collector = readFlag(args, '--collector');
+  if (!collector) {
+    throw new Error(
+      'Usage: collection-route --collector <username|id> [--day YYYY-MM-DD] [--start lat,lng] [--csv | --geojson | --json] [--out <path>]'
+    );
+  }
+  const day = readFlag(args, '--day') || new Date().toISOString().slice(0, 10);
+  const startFlag = readFlag(args, '--start');
+  const start = startFlag ? parseCoordinates(startFlag) : null;
+  const outFile = readFlag(args, '--out');
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const route = await planCollectionRoute({ collector, day, start });
+  audit.addRows(route.stops.length);
+
+  let output = formatCollectionRoute(route);
+  if (args.includes('--csv')) output = exportCollectionRouteToCSV(route);
+  else if (args.includes('--geojson')) {
+    output = JSON.stringify(exportCollectionRouteToGeoJSON(route), null, 2);
+  } else if (args.includes('--json')) output = JSON.stringify(route, null, 2);
+  if (outFile) {
+    writeFileSync(outFile, output);
+    console.log(formatCollectionRoute(route));
+    console.log(`Written to ${outFile}`);
+  } else {
+    console.log(output);
+  }
+  if (route.unrouted > 0) {
+    console.warn(
+      `${route.unrouted} location(s) have no coordinates and were not routed`
+    );
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+}
+
+main().catch(async error => {
+  console.error(
+    '[collection-route] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 1, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(1);
+});
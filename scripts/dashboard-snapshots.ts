@@ -0,0 +1,102 @@
+/**
+ * Dashboard Snapshots Command
+ *
+ * Takes a snapshot of every licencee's dashboard stats into
+ * `dashboardSnapshots` (for an hourly cron), or charts the stored series:
+ * `bun run dashboard-snapshots -- --env prod` /
+ * `bun run dashboard-snapshots -- --env prod --trend --licencee <id> --days 90`.
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --licencee a,b           Licencees to snapshot (default: all active); required with --trend
+ *   --trend                  Print the stored series instead of taking a snapshot
+ *   --days N                 Trend window in days (default 90)
+ *   --granularity day|hour   Trend granularity (default day)
+ *   --field <name>           Field to chart (default totalGross)
+ *   --json                   Print the result as JSON
+ *
+ * Exit codes: 0 = done, 1 = a licencee failed to snapshot, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  formatDashboardTrendChart,
+  getDashboardSnapshotTrend,
+  SNAPSHOT_FIELDS,
+  takeDashboardSnapshots,
+} from '../app/api/lib/helpers/dashboardSnapshots';
+import type { DashboardSnapshotField } from '../app/api/lib/helpers/dashboardSnapshots';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('dashboard-snapshots');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const licenceeIds = (readFlag(args, '--licencee') || '')
+    .split(',')
+    .map(id => id.trim())
+    .filter(Boolean);
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // Trend mode
+  if (args.includes('--trend')) {
+    const [licencee] = licenceeIds;
+    if (!licencee) throw new Error('--trend needs --licencee <id>');
+    const field = (readFlag(args, '--field') ||
+      'totalGross') as DashboardSnapshotField;
+    if (!SNAPSHOT_FIELDS.includes(field)) {
+      throw new Error(
+        `Unknown field '${field}'. Available: ${SNAPSHOT_FIELDS.join(', ')}`
+      );
+    }
+    const trend = await getDashboardSnapshotTrend(licencee, {
+      granularity: readFlag(args, '--granularity') === 'hour' ? 'hour' : 'day',
+      days: Number(readFlag(args, '--days')) || 90,
+    });
+    audit.addRows(trend.points.length);
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+
+    console.log(
+      asJson
+        ? JSON.stringify(trend, null, 2)
+        : formatDashboardTrendChart(trend, field)
+    );
+    process.exit(0);
+  }
+
+  // Snapshot mode
+  const result = await takeDashboardSnapshots(licenceeIds);
+  const exitCode = result.failed.length > 0 ? 1 : 0;
+  audit.addRows(result.written);
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify(result, null, 2));
+  } else {
+    console.log(
+      `Snapshot at ${result.takenAt.toISOString()}: ${result.licencees} licencees, ${result.written} documents written, ${result.pruned} old hourly snapshots pruned`
+    );
+    result.failed.forEach(failure =>
+      console.log(`  FAILED ${failure.licencee}: ${failure.error}`)
+    );
+  }
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[dashboard-snapshots] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -6,15 +6,15 @@
  */
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { Collections } from '../app/api/lib/models/collections';
 import { Meters } from '../app/api/lib/models/meters';
 
-const MONGODB_URI = process.env.MONGODB_URI as string;
 const REPORT_ID = process.argv[2] || 'c746e506-4523-4d11-bb5d-736317995cfd';
 const iso = (d?: Date | null) => (d ? new Date(d).toISOString() : 'null');
 
 async function main() {
-  await mongoose.connect(MONGODB_URI);
+  await connectCommandDatabase();
   const cols = await Collections.find({ locationReportId: REPORT_ID }).lean<any[]>();
   console.log(`Report ${REPORT_ID}: ${cols.length} collections\n`);
 
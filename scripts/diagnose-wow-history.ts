@@ -7,15 +7,15 @@
  */
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { Collections } from '../app/api/lib/models/collections';
 import { Machine } from '../app/api/lib/models/machines';
 
-const MONGODB_URI = process.env.MONGODB_URI as string;
 const REPORT_ID = process.argv[2] || 'c746e506-4523-4d11-bb5d-736317995cfd';
 const iso = (d?: Date | null) => (d ? new Date(d).toISOString() : 'null');
 
 async function main() {
-  await mongoose.connect(MONGODB_URI);
+  await connectCommandDatabase();
   const cols = await Collections.find({ locationReportId: REPORT_ID }, { machineId: 1 }).lean<any[]>();
   const ids = cols.map(c => c.machineId);
   const machines = await Machine.find({ _id: { $in: ids } }, { collectionMetersHistory: 1, collectionTime: 1, serialNumber: 1 }).lean<any[]>();
@@ -10,10 +10,10 @@
  */
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { Machine } from '../app/api/lib/models/machines';
 import { Meters } from '../app/api/lib/models/meters';
 
-const MONGODB_URI = process.env.MONGODB_URI as string;
 const iso = (d?: Date | null) => (d ? new Date(d).toISOString() : 'null');
 const num = (v?: number | null) => (v == null ? 'null' : v.toLocaleString());
 
@@ -27,7 +27,7 @@ async function resolveMachineId(arg0: string, arg1: string): Promise<{ id: strin
 }
 
 async function main() {
-  await mongoose.connect(MONGODB_URI);
+  await connectCommandDatabase();
   const { id, rest } = await resolveMachineId(process.argv[2], process.argv[3]);
   const start = new Date(rest[0]);
   const end = new Date(rest[1]);
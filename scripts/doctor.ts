@@ -0,0 +1,79 @@
+/**
+ * Doctor Command
+ *
+ * Runs the same dependency checks as `/readyz` (database ping, core
+ * collections, declared indexes, pre-aggregation freshness; see
+ * app/api/lib/helpers/healthChecks.ts) against a database from the command
+ * line: `bun run doctor -- --env production`.
+ *
+ * Options:
+ *   --env <profile>           Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --max-metrics-age N       casinoMetrics older than N minutes are stale (default 60)
+ *   --timeout-ms N            Time limit per check (default 15000)
+ *   --strict                  Treat warnings as failures
+ *   --json                    Print the report as JSON
+ *
+ * Exit codes: 0 = healthy (warnings allowed unless --strict), 1 = a check
+ * failed, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  formatHealthReport,
+  getReadinessReport,
+  healthHttpStatus,
+} from '../app/api/lib/helpers/healthChecks';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const DEFAULT_TIMEOUT_MS = 15000;
+
+function readPositive(args: string[], name: string): number | undefined {
+  const value = readFlag(args, name);
+  if (value === undefined) return undefined;
+  const parsed = Number(value);
+  if (!Number.isFinite(parsed) || parsed <= 0) {
+    throw new Error(`${name} must be a positive number`);
+  }
+  return parsed;
+}
+
+const audit = startCommandAudit('doctor');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const strict = args.includes('--strict');
+
+  const report = await getReadinessReport({
+    connect: async () => {
+      const target = await connectCommandDatabase();
+      audit.setTarget(target.name);
+    },
+    maxMetricsAgeMinutes: readPositive(args, '--max-metrics-age'),
+    timeoutMs: readPositive(args, '--timeout-ms') ?? DEFAULT_TIMEOUT_MS,
+    fresh: true,
+  });
+  const failed = healthHttpStatus(report, strict) !== 200;
+  audit.addRows(report.checks.length);
+  await audit.finish({ success: true, exitCode: failed ? 1 : 0 });
+  await mongoose.disconnect().catch(() => undefined);
+
+  console.log(
+    asJson ? JSON.stringify(report, null, 2) : formatHealthReport(report)
+  );
+
+  process.exit(failed ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[doctor] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,152 @@
+/**
+ * Dual-Write Conflict Command
+ *
+ * Lists documents written on both the source and the destination since a
+ * checkpoint whose content differs (the dual-write window during cutover),
+ * per collection, and optionally resolves them with a chosen winner per
+ * collection:
+ * `bun run conflicts -- --since 2026-10-01T09:00:00Z --source prod --dest staging`
+ * `bun run conflicts -- --since 2026-10-01T09:00:00Z --resolve machines=source-wins,users=destination-wins`.
+ *
+ * Options:
+ *   --since <timestamp>       Checkpoint; writes at or after it are compared (required)
+ *   --source <profile>        Source database profile (default: SRC_MONGODB_URI)
+ *   --dest <profile>          Destination database profile (default: DST_MONGODB_URI)
+ *   --collections a,b:field   Collections to check, optionally with the time field
+ *                             marking a write (default: machines,gaminglocations,licencees,users)
+ *   --resolve a=source-wins   Resolution per collection (source-wins overwrites the
+ *                             destination, destination-wins the source); others are listed only
+ *   --sample N                Conflicts listed per collection (default 20)
+ *   --dry-run                 Report what --resolve would do without writing
+ *   --json                    Print the report as JSON
+ *
+ * Exit codes: 0 = no unresolved conflicts, 1 = unresolved conflicts, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import type { Connection } from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { parseConsistencyTarget } from '../app/api/lib/helpers/dbConsistency';
+import {
+  DEFAULT_CONFLICT_MAX_DOCUMENTS,
+  DEFAULT_CONFLICT_SAMPLE_SIZE,
+  detectDualWriteConflicts,
+  formatConflictReport,
+  parseConflictResolutions,
+} from '../app/api/lib/helpers/dualWriteConflicts';
+import {
+  DEFAULT_CONNECT_OPTIONS,
+  redactMongoUri,
+  resolveDbProfile,
+} from '../app/api/lib/utils/dbProfiles';
+import { getSecret } from '../app/api/lib/utils/secrets';
+import { readFlag } from './lib/cliArgs';
+
+const DEFAULT_COLLECTIONS = 'machines,gaminglocations,licencees,users';
+
+async function openConnection(
+  profile: string | undefined,
+  fallbackEnv: string
+): Promise<{ label: string; connection: Connection }> {
+  if (profile) {
+    const resolved = await resolveDbProfile(profile);
+    return {
+      label: resolved.name,
+      connection: await mongoose
+        .createConnection(resolved.uri, resolved.options)
+        .asPromise(),
+    };
+  }
+
+  const uri = await getSecret(fallbackEnv);
+  if (!uri) {
+    throw new Error(`Pass a profile or set ${fallbackEnv}`);
+  }
+  return {
+    label: redactMongoUri(uri),
+    connection: await mongoose
+      .createConnection(uri, DEFAULT_CONNECT_OPTIONS)
+      .asPromise(),
+  };
+}
+
+const audit = startCommandAudit('conflicts');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const sinceFlag = readFlag(args, '--since');
+  if (!sinceFlag) {
+    throw new Error(
+      'Usage: conflicts --since <timestamp> [--source <profile>] [--dest <profile>] [--collections a,b:field] [--resolve a=source-wins,b=destination-wins] [--dry-run]'
+    );
+  }
+  const since = new Date(sinceFlag);
+  if (Number.isNaN(since.getTime())) {
+    throw new Error('--since must be a valid timestamp');
+  }
+  const targets = (readFlag(args, '--collections') || DEFAULT_COLLECTIONS)
+    .split(',')
+    .filter(Boolean)
+    .map(parseConsistencyTarget);
+  const resolutions = parseConflictResolutions(
+    readFlag(args, '--resolve') || ''
+  );
+  const sampleSize = Number(
+    readFlag(args, '--sample') || DEFAULT_CONFLICT_SAMPLE_SIZE
+  );
+  const asJson = args.includes('--json');
+
+  const source = await openConnection(
+    readFlag(args, '--source'),
+    'SRC_MONGODB_URI'
+  );
+  const destination = await openConnection(
+    readFlag(args, '--dest'),
+    'DST_MONGODB_URI'
+  );
+  audit.setTarget(`${source.label} <-> ${destination.label}`);
+  if (!asJson) {
+    console.log(`Source:      ${source.label}`);
+    console.log(`Destination: ${destination.label}`);
+  }
+
+  const report = await detectDualWriteConflicts(
+    source.connection,
+    destination.connection,
+    targets,
+    {
+      since,
+      resolutions,
+      dryRun: args.includes('--dry-run'),
+      sampleSize:
+        Number.isFinite(sampleSize) && sampleSize >= 0
+          ? Math.floor(sampleSize)
+          : DEFAULT_CONFLICT_SAMPLE_SIZE,
+      maxDocuments: DEFAULT_CONFLICT_MAX_DOCUMENTS,
+    }
+  );
+  console.log(
+    asJson ? JSON.stringify(report, null, 2) : formatConflictReport(report)
+  );
+  audit.addRows(
+    report.collections.reduce(
+      (sum, collection) => sum + collection.conflicts,
+      0
+    )
+  );
+
+  const exitCode = report.unresolved > 0 ? 1 : 0;
+  await audit.finish({ success: true, exitCode }, source.connection);
+  await Promise.all([source.connection.close(), destination.connection.close()]);
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[conflicts] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  process.exit(2);
+});
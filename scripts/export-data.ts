@@ -0,0 +1,112 @@
+/**
+ * Data Export Command
+ *
+ * Exports collections to NDJSON for sharing outside the platform, with an
+ * anonymized mode for game vendors that pseudonymizes members and location
+ * names (salted, deterministic, references kept):
+ * `bun run export-data -- --env prod --licencee <id> --anonymize --out vendor-export`.
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --out <dir>              Output directory or s3://bucket/prefix (default export-<YYYYMMDD>)
+ *   --collections a,b        Collections to export (default: all supported)
+ *   --licencee <id>          Only this licencee's locations
+ *   --location <id>          Only this location
+ *   --since YYYY-MM-DD       Only sessions, meters and events from this date
+ *   --anonymize              Pseudonymize members and location names; the salt
+ *                            comes from EXPORT_ANONYMIZE_SALT (_FILE, _SECRET)
+ *   --json                   Print the result as JSON
+ *
+ * Exit codes: 0 = exported, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  EXPORT_COLLECTIONS,
+  exportCollections,
+} from '../app/api/lib/helpers/dataExport';
+import type { ExportCollection } from '../app/api/lib/helpers/dataExport';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { getSecret } from '../app/api/lib/utils/secrets';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('export-data');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const anonymize = args.includes('--anonymize');
+  const collections = (readFlag(args, '--collections') || '')
+    .split(',')
+    .map(name => name.trim())
+    .filter(Boolean) as ExportCollection[];
+  const unknown = collections.filter(
+    name => !EXPORT_COLLECTIONS.includes(name)
+  );
+  if (unknown.length > 0) {
+    throw new Error(
+      `Unknown collection(s) ${unknown.join(', ')}. Available: ${EXPORT_COLLECTIONS.join(', ')}`
+    );
+  }
+
+  const sinceFlag = readFlag(args, '--since');
+  const since = sinceFlag ? new Date(sinceFlag) : undefined;
+  if (since && Number.isNaN(since.getTime())) {
+    throw new Error(`--since is not a date: '${sinceFlag}'`);
+  }
+
+  const salt = anonymize
+    ? await getSecret('EXPORT_ANONYMIZE_SALT')
+    : undefined;
+  if (anonymize && !salt) {
+    throw new Error(
+      '--anonymize needs EXPORT_ANONYMIZE_SALT; keep it private, anyone with it can re-link pseudonyms to known IDs'
+    );
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  const result = await exportCollections({
+    outDir:
+      readFlag(args, '--out') ||
+      `export-${new Date().toISOString().slice(0, 10).replace(/-/g, '')}`,
+    collections,
+    licenceeId: readFlag(args, '--licencee'),
+    locationId: readFlag(args, '--location'),
+    since,
+    anonymize,
+    salt,
+  });
+  const total = Object.values(result.counts).reduce(
+    (sum, count) => sum + (count || 0),
+    0
+  );
+  audit.addRows(total);
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify(result, null, 2));
+  } else {
+    console.log(
+      `Exported ${total} documents to ${result.outDir}${result.anonymized ? ' (anonymized)' : ''}`
+    );
+    Object.entries(result.counts).forEach(([collection, count]) =>
+      console.log(`  ${collection.padEnd(16)} ${count}`)
+    );
+  }
+  process.exit(0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[export-data] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -1,10 +1,11 @@
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { GamingLocations } from '../app/api/lib/models/gaminglocations';
 import { CollectionReport } from '../app/api/lib/models/collectionReport';
 
 async function main() {
-  await mongoose.connect(process.env.MONGODB_URI as string);
+  await connectCommandDatabase();
   const locs = await GamingLocations.find({ noSMIBLocation: true }).lean<any[]>();
   const ids = locs.map(l => String(l._id));
   const reports = await CollectionReport.find({ location: { '$in': ids } })
@@ -0,0 +1,134 @@
+/**
+ * Gross Variance Check
+ *
+ * Compares each location's gross for a gaming day with its average for the
+ * same weekday over the previous four weeks and raises an alert in
+ * `varianceAlerts` for every location that deviates by more than the
+ * threshold, to catch meter or collection problems early. Meant to run daily
+ * from cron after the gaming day closes:
+ * `bun run gross-variance -- --env prod --threshold 40`.
+ *
+ * Options:
+ *   --env <profile>        Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --day YYYY-MM-DD       Gaming day to check (default: yesterday)
+ *   --threshold <percent>  Alert threshold (default GROSS_VARIANCE_THRESHOLD_PERCENT, else 50)
+ *   --licencee <id>        Only this licencee's locations
+ *   --location a,b         Only these locations
+ *   --dry-run              Report without recording alerts
+ *   --json                 Print the report as JSON
+ *   --report-file <path>   Also write the JSON report to this file
+ *   --webhook <url>        Post the alerts when any are raised (or GROSS_VARIANCE_WEBHOOK_URL)
+ *
+ * JOB_WEBHOOK_URL / SLACK_WEBHOOK_URL are notified when the run finishes or
+ * errors (see jobNotifications).
+ *
+ * Exit codes: 0 = no alerts, 1 = alerts raised, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  detectGrossVariance,
+  formatGrossVarianceReport,
+  getGrossVarianceThreshold,
+  postGrossVarianceWebhook,
+} from '../app/api/lib/helpers/grossVariance';
+import {
+  notifyJobFinished,
+  writeJobReport,
+} from '../app/api/lib/helpers/jobNotifications';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('gross-variance');
+const startedAt = new Date();
+let targetName: string | undefined;
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const dryRun = args.includes('--dry-run');
+  const reportFile = readFlag(args, '--report-file');
+  const webhookUrl =
+    readFlag(args, '--webhook') || process.env.GROSS_VARIANCE_WEBHOOK_URL;
+
+  const yesterday = new Date(Date.now() - 24 * 60 * 60 * 1000);
+  const day = readFlag(args, '--day') || yesterday.toISOString().slice(0, 10);
+  if (!/^\d{4}-\d{2}-\d{2}$/.test(day)) {
+    throw new Error(`--day must be YYYY-MM-DD, got '${day}'`);
+  }
+  const thresholdFlag = readFlag(args, '--threshold');
+  const thresholdPercent = thresholdFlag
+    ? Number(thresholdFlag)
+    : getGrossVarianceThreshold();
+  if (!Number.isFinite(thresholdPercent) || thresholdPercent <= 0) {
+    throw new Error('--threshold must be a positive percent');
+  }
+  const locationIds = (readFlag(args, '--location') || '')
+    .split(',')
+    .map(id => id.trim())
+    .filter(Boolean);
+
+  const target = await connectCommandDatabase();
+  targetName = target.name;
+  audit.setTarget(target.name);
+  const report = await detectGrossVariance({
+    day,
+    thresholdPercent,
+    locationIds,
+    licenceeId: readFlag(args, '--licencee'),
+    dryRun,
+  });
+  const alerted = report.alerts.length > 0;
+  audit.addRows(report.rows.length);
+  await audit.finish({ success: true, exitCode: alerted ? 1 : 0 });
+  await mongoose.disconnect();
+
+  console.log(
+    asJson ? JSON.stringify(report, null, 2) : formatGrossVarianceReport(report)
+  );
+
+  if (webhookUrl && alerted && !dryRun) {
+    await postGrossVarianceWebhook(webhookUrl, report);
+  }
+  await notifyJobFinished({
+    job: 'gross-variance',
+    kind: 'detection',
+    status: 'completed',
+    target: target.name,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {
+      locations: report.rows.length,
+      alerts: report.alerts.length,
+      recorded: report.recorded,
+    },
+    summary: alerted
+      ? `${report.alerts.length} location(s) deviated more than ${thresholdPercent}% on ${day}`
+      : `No location deviated more than ${thresholdPercent}% on ${day}`,
+    report: reportFile ? await writeJobReport(reportFile, report) : undefined,
+  });
+
+  process.exit(alerted ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[gross-variance] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await notifyJobFinished({
+    job: 'gross-variance',
+    kind: 'detection',
+    status: 'failed',
+    target: targetName,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {},
+    error: error instanceof Error ? error.message : String(error),
+  });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,277 @@
+/**
+ * gRPC Server Command
+ *
+ * Long-running gRPC server for internal services: serves the
+ * `casino.v1.BackOffice` service (proto/casino/v1/back_office.proto) with
+ * machine lookup, location metrics and report template RPCs:
+ * `bun run grpc`
+ * `bun run grpc -- --port 50051 --env production`.
+ *
+ * Every call must send `authorization: Bearer <GRPC_AUTH_TOKEN>` metadata;
+ * calls without it fail with UNAUTHENTICATED. Set `GRPC_TLS_CERT_FILE` and
+ * `GRPC_TLS_KEY_FILE` to serve over TLS (plaintext otherwise). Requires the
+ * optional `@grpc/grpc-js` and `@grpc/proto-loader` packages. Calls and
+ * their aggregations are traced when `OTEL_EXPORTER_OTLP_ENDPOINT` is set
+ * (see tracing).
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --port N                 Port (default GRPC_PORT or 50051)
+ *   --host <address>         Address to bind (default 0.0.0.0)
+ *
+ * On SIGTERM it stops taking calls and waits up to
+ * `SHUTDOWN_DRAIN_TIMEOUT_MS` (default 30000) for in-flight calls, then
+ * cancels the rest and kills their aggregations on the server before closing
+ * Mongo (see gracefulShutdown). Exit codes: 0 = stopped, 1 = not configured,
+ * 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import path from 'path';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  GRPC_STATUS,
+  grpcGetLocationMetrics,
+  grpcLookupMachines,
+  grpcRunReport,
+  grpcStatusForError,
+  verifyGrpcToken,
+} from '../app/api/lib/helpers/grpcBackOffice';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import {
+  installInFlightTracking,
+  installShutdownHandlers,
+  isShuttingDown,
+  trackInFlight,
+} from '../app/api/lib/utils/gracefulShutdown';
+import { importOptional } from '../app/api/lib/utils/optionalImport';
+import { getCommandQueryTag } from '../app/api/lib/utils/queryTimeout';
+import { getSecret } from '../app/api/lib/utils/secrets';
+import {
+  installAggregationTracing,
+  startTracing,
+  stopTracing,
+  withSpan,
+} from '../app/api/lib/utils/tracing';
+import { readFlag } from './lib/cliArgs';
+
+// ============================================================================
+// Types & Constants
+// ============================================================================
+
+type GrpcCall = {
+  request: unknown;
+  metadata: { get: (key: string) => Array<string | Buffer> };
+  getPeer: () => string;
+};
+
+type GrpcCallback = (
+  error: { code: number; details: string } | null,
+  response?: unknown
+) => void;
+
+type GrpcServer = {
+  addService: (
+    service: unknown,
+    handlers: Record<string, (call: GrpcCall, callback: GrpcCallback) => void>
+  ) => void;
+  bindAsync: (
+    address: string,
+    credentials: unknown,
+    callback: (error: Error | null, port: number) => void
+  ) => void;
+  tryShutdown: (callback: (error?: Error) => void) => void;
+  forceShutdown: () => void;
+};
+
+type GrpcModule = {
+  Server: new () => GrpcServer;
+  ServerCredentials: {
+    createInsecure: () => unknown;
+    createSsl: (
+      rootCerts: Buffer | null,
+      keyCertPairs: Array<{ private_key: Buffer; cert_chain: Buffer }>
+    ) => unknown;
+  };
+  loadPackageDefinition: (definition: unknown) => {
+    casino: { v1: { BackOffice: { service: unknown } } };
+  };
+};
+
+type ProtoLoaderModule = {
+  loadSync: (file: string, options: Record<string, unknown>) => unknown;
+};
+
+const GRPC_MODULE = '@grpc/grpc-js';
+const PROTO_LOADER_MODULE = '@grpc/proto-loader';
+const PROTO_FILE = path.resolve(
+  __dirname,
+  '..',
+  'proto',
+  'casino',
+  'v1',
+  'back_office.proto'
+);
+const DEFAULT_PORT = 50051;
+
+function readPort(value: string | undefined, flag: string): number | null {
+  if (value === undefined || value === '') return null;
+  const port = Number(value);
+  if (!Number.isInteger(port) || port < 1 || port > 65535) {
+    throw new Error(`Invalid ${flag}: ${value}`);
+  }
+  return port;
+}
+
+const audit = startCommandAudit('grpc');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const port =
+    readPort(readFlag(args, '--port'), '--port') ??
+    readPort(process.env.GRPC_PORT, 'GRPC_PORT') ??
+    DEFAULT_PORT;
+  const host = readFlag(args, '--host') || '0.0.0.0';
+
+  const expectedToken = await getSecret('GRPC_AUTH_TOKEN');
+  if (!expectedToken) {
+    console.error(
+      '[grpc] GRPC_AUTH_TOKEN is not set; refusing to serve unauthenticated calls'
+    );
+    await audit.finish({ success: false, exitCode: 1 });
+    process.exit(1);
+  }
+  const certFile = process.env.GRPC_TLS_CERT_FILE;
+  const keyFile = process.env.GRPC_TLS_KEY_FILE;
+  if (Boolean(certFile) !== Boolean(keyFile)) {
+    console.error(
+      '[grpc] GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together'
+    );
+    await audit.finish({ success: false, exitCode: 1 });
+    process.exit(1);
+  }
+
+  const [grpc, protoLoader] = await Promise.all([
+    importOptional<GrpcModule>(GRPC_MODULE, 'The gRPC server'),
+    importOptional<ProtoLoaderModule>(PROTO_LOADER_MODULE, 'The gRPC server'),
+  ]);
+  const definition = protoLoader.loadSync(PROTO_FILE, {
+    longs: Number,
+    enums: String,
+    defaults: true,
+    oneofs: true,
+  });
+  const { BackOffice } = grpc.loadPackageDefinition(definition).casino.v1;
+
+  await startTracing('casino-management-grpc');
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  installInFlightTracking();
+  installAggregationTracing();
+
+  // Each RPC: check the token, run the handler, map its error to a status
+  let calls = 0;
+  let rejected = 0;
+  const unary =
+    (name: string, handler: (request: never) => Promise<unknown>) =>
+    async (call: GrpcCall, callback: GrpcCallback) => {
+      const authorization = call.metadata.get('authorization')[0];
+      const verified = await verifyGrpcToken(
+        authorization === undefined ? null : String(authorization),
+        expectedToken
+      );
+      if (verified !== 'ok') {
+        rejected += 1;
+        return callback({
+          code: GRPC_STATUS.UNAUTHENTICATED,
+          details: 'Invalid token',
+        });
+      }
+      if (isShuttingDown()) {
+        return callback({
+          code: GRPC_STATUS.UNAVAILABLE,
+          details: 'Server is shutting down',
+        });
+      }
+      calls += 1;
+      try {
+        const response = await trackInFlight('call', () =>
+          withSpan(
+            `grpc ${name}`,
+            { 'rpc.system': 'grpc', 'rpc.method': name },
+            () => handler(call.request as never),
+            'server'
+          )
+        );
+        callback(null, response);
+        audit.addRows(1);
+      } catch (error) {
+        const code = grpcStatusForError(error);
+        if (code === GRPC_STATUS.INTERNAL) {
+          console.error(
+            `[grpc] ${name} from ${call.getPeer()} failed:`,
+            error instanceof Error ? error.message : error
+          );
+        }
+        callback({
+          code,
+          details: error instanceof Error ? error.message : String(error),
+        });
+      }
+    };
+
+  const server = new grpc.Server();
+  server.addService(BackOffice.service, {
+    LookupMachines: unary('LookupMachines', grpcLookupMachines),
+    GetLocationMetrics: unary('GetLocationMetrics', grpcGetLocationMetrics),
+    RunReport: unary('RunReport', grpcRunReport),
+  });
+  const credentials =
+    certFile && keyFile
+      ? grpc.ServerCredentials.createSsl(null, [
+          {
+            private_key: fs.readFileSync(keyFile),
+            cert_chain: fs.readFileSync(certFile),
+          },
+        ])
+      : grpc.ServerCredentials.createInsecure();
+  await new Promise<void>((resolve, reject) =>
+    server.bindAsync(`${host}:${port}`, credentials, error =>
+      error ? reject(error) : resolve()
+    )
+  );
+  console.log(
+    `[grpc] Serving casino.v1.BackOffice on ${host}:${port}${
+      certFile ? ' (TLS)' : ''
+    }`
+  );
+
+  // SIGINT is left to the command Ctrl-C handler (see queryTimeout)
+  installShutdownHandlers({
+    label: 'grpc',
+    signals: ['SIGTERM'],
+    tag: getCommandQueryTag(),
+    stopAccepting: () =>
+      new Promise<void>(resolve => server.tryShutdown(() => resolve())),
+    onTimeout: () => server.forceShutdown(),
+    beforeClose: async () => {
+      console.log(
+        `[grpc] Served ${calls} call(s); rejected ${rejected} unauthenticated`
+      );
+      await audit.finish({ success: true, exitCode: 0 });
+      await stopTracing();
+    },
+  });
+}
+
+main().catch(async error => {
+  console.error(
+    '[grpc] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect();
+  process.exit(2);
+});
@@ -0,0 +1,223 @@
+/**
+ * Heartbeat Receiver Command
+ *
+ * Long-running receiver for SMIB heartbeats on sites where devices cannot
+ * reach the web app: listens for UDP datagrams (and optionally HTTP posts)
+ * and records them like `POST /api/smib/heartbeat` does, advancing each
+ * machine's `lastActivity` and storing the ping in `heartbeats`:
+ * `bun run heartbeats -- --udp-port 5140`
+ * `bun run heartbeats -- --udp-port 5140 --http-port 8081 --env production`.
+ *
+ * Datagrams are JSON (`{"serial":"..","ts":..,"fw":"..","token":".."}`) or
+ * text (`serial,timestamp,firmware,token`). HTTP posts go to `/heartbeat`
+ * with `Authorization: Bearer <token>` and the same body as the API route.
+ * Pings without a valid `HEARTBEAT_TOKEN` are dropped. Pings are written in
+ * batches every `--flush-ms` (or every 500 pings).
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --udp-port N             UDP port (default HEARTBEAT_UDP_PORT or 5140)
+ *   --http-port N            Also accept HTTP posts on this port
+ *   --host <address>         Address to bind (default 0.0.0.0)
+ *   --flush-ms N             Batch interval in ms (default 1000)
+ *
+ * Stops on SIGTERM after writing the pending batch. Exit codes: 0 = stopped,
+ * 1 = not configured, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import dgram from 'dgram';
+import http from 'http';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  MAX_HEARTBEATS_PER_BATCH,
+  ingestHeartbeats,
+  parseHeartbeatDatagram,
+  verifyHeartbeatToken,
+} from '../app/api/lib/helpers/heartbeats';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { getSecret } from '../app/api/lib/utils/secrets';
+import type { HeartbeatSource } from '../shared/types';
+import { readFlag } from './lib/cliArgs';
+
+const DEFAULT_UDP_PORT = 5140;
+const DEFAULT_FLUSH_MS = 1000;
+
+function readPort(value: string | undefined, flag: string): number | null {
+  if (value === undefined || value === '') return null;
+  const port = Number(value);
+  if (!Number.isInteger(port) || port < 1 || port > 65535) {
+    throw new Error(`Invalid ${flag}: ${value}`);
+  }
+  return port;
+}
+
+type PendingPing = { input: unknown; source: HeartbeatSource; ip: string };
+
+const audit = startCommandAudit('heartbeats');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const udpPort =
+    readPort(readFlag(args, '--udp-port'), '--udp-port') ??
+    readPort(process.env.HEARTBEAT_UDP_PORT, 'HEARTBEAT_UDP_PORT') ??
+    DEFAULT_UDP_PORT;
+  const httpPort = readPort(readFlag(args, '--http-port'), '--http-port');
+  const host = readFlag(args, '--host') || '0.0.0.0';
+  const flushMs = Number(readFlag(args, '--flush-ms') ?? DEFAULT_FLUSH_MS);
+  if (!Number.isInteger(flushMs) || flushMs < 100) {
+    throw new Error('--flush-ms must be a whole number of at least 100');
+  }
+
+  const expectedToken = await getSecret('HEARTBEAT_TOKEN');
+  if (!expectedToken) {
+    console.error(
+      '[heartbeats] HEARTBEAT_TOKEN is not set; refusing to accept unauthenticated pings'
+    );
+    await audit.finish({ success: false, exitCode: 1 });
+    process.exit(1);
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // Batch pings so a busy floor is one write per interval
+  const pending: PendingPing[] = [];
+  let dropped = 0;
+  let flushing: Promise<void> = Promise.resolve();
+  const flush = () => {
+    flushing = flushing.then(async () => {
+      while (pending.length > 0) {
+        const batch = pending.splice(0, MAX_HEARTBEATS_PER_BATCH);
+        // Group by source and sender so each stored ping keeps its origin
+        const groups = new Map<string, PendingPing[]>();
+        batch.forEach(ping => {
+          const key = `${ping.source}|${ping.ip}`;
+          groups.set(key, [...(groups.get(key) || []), ping]);
+        });
+        for (const group of groups.values()) {
+          try {
+            const result = await ingestHeartbeats(
+              group.map(ping => ping.input),
+              group[0].source,
+              group[0].ip
+            );
+            audit.addRows(result.received - result.rejected.length);
+            if (result.unknown.length > 0) {
+              console.warn(
+                `[heartbeats] Unknown serial(s) from ${group[0].ip}: ${result.unknown.join(', ')}`
+              );
+            }
+          } catch (error) {
+            console.error(
+              '[heartbeats] Failed to record batch:',
+              error instanceof Error ? error.message : error
+            );
+          }
+        }
+      }
+    });
+    return flushing;
+  };
+  const queue = (ping: PendingPing) => {
+    pending.push(ping);
+    if (pending.length >= MAX_HEARTBEATS_PER_BATCH) void flush();
+  };
+  const timer = setInterval(() => void flush(), flushMs);
+
+  // UDP listener
+  const socket = dgram.createSocket('udp4');
+  socket.on('message', async (message, remote) => {
+    const parsed = parseHeartbeatDatagram(message.toString('utf8'));
+    const verified =
+      parsed && (await verifyHeartbeatToken(parsed.token, expectedToken));
+    if (!parsed || verified !== 'ok') {
+      dropped += 1;
+      return;
+    }
+    queue({ input: parsed.input, source: 'udp', ip: remote.address });
+  });
+  socket.on('error', error => {
+    console.error('[heartbeats] UDP error:', error.message);
+  });
+  await new Promise<void>(resolve => socket.bind(udpPort, host, resolve));
+  console.log(
+    `[heartbeats] Listening for UDP heartbeats on ${host}:${udpPort}`
+  );
+
+  // Optional HTTP listener
+  let server: http.Server | null = null;
+  if (httpPort) {
+    const httpServer = http.createServer((request, response) => {
+      const reply = (status: number, body: Record<string, unknown>) => {
+        response.writeHead(status, { 'Content-Type': 'application/json' });
+        response.end(JSON.stringify(body));
+      };
+      if (request.method !== 'POST' || request.url !== '/heartbeat') {
+        return reply(404, { success: false, error: 'Not found' });
+      }
+      let raw = '';
+      request.on('data', chunk => {
+        raw += chunk;
+      });
+      request.on('end', async () => {
+        const token = (request.headers.authorization || '')
+          .replace(/^Bearer\s+/i, '')
+          .trim();
+        if ((await verifyHeartbeatToken(token, expectedToken)) !== 'ok') {
+          dropped += 1;
+          return reply(401, { success: false, error: 'Invalid token' });
+        }
+        let body: unknown;
+        try {
+          body = JSON.parse(raw);
+        } catch {
+          return reply(400, { success: false, error: 'Body must be JSON' });
+        }
+        const batch = (body as { heartbeats?: unknown } | null)?.heartbeats;
+        const pings = Array.isArray(batch) ? batch : [body];
+        if (pings.length > MAX_HEARTBEATS_PER_BATCH) {
+          return reply(400, {
+            success: false,
+            error: `At most ${MAX_HEARTBEATS_PER_BATCH} heartbeats per request`,
+          });
+        }
+        const ip = request.socket.remoteAddress || 'unknown';
+        pings.forEach(input => queue({ input, source: 'http', ip }));
+        reply(202, { success: true, queued: pings.length });
+      });
+    });
+    await new Promise<void>(resolve =>
+      httpServer.listen(httpPort, host, resolve)
+    );
+    server = httpServer;
+    console.log(
+      `[heartbeats] Listening for HTTP heartbeats on ${host}:${httpPort}/heartbeat`
+    );
+  }
+
+  process.once('SIGTERM', async () => {
+    console.log('[heartbeats] Stopping: writing pending heartbeats...');
+    clearInterval(timer);
+    socket.close();
+    server?.close();
+    await flush();
+    if (dropped > 0) {
+      console.warn(`[heartbeats] Dropped ${dropped} unauthenticated ping(s)`);
+    }
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    process.exit(0);
+  });
+}
+
+main().catch(async error => {
+  console.error(
+    '[heartbeats] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect();
+  process.exit(2);
+});
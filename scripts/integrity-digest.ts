@@ -0,0 +1,73 @@
+/**
+ * Integrity Digest Command
+ *
+ * Emails each licencee's operations contact a summary of the integrity issues
+ * newly detected at its locations: counts per check and the machines and
+ * locations with the most issues. Meant for a daily cron; issues already sent
+ * are not sent again.
+ *
+ * Usage:
+ *   bun run integrity-digest -- --env prod
+ *   bun run integrity-digest -- --env prod --licencee <id> --dry-run
+ *
+ * Options:
+ *   --env <profile>        Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --licencee a,b         Licencees to send to (default: all with new issues)
+ *   --since YYYY-MM-DD     Oldest detection included (default: last 7 days)
+ *   --dry-run              Print the digests without sending them
+ *   --json                 Print the result as JSON
+ *
+ * Exit codes: 0 = done, 1 = a digest failed to send, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  formatIntegrityDigestResult,
+  sendIntegrityDigests,
+} from '../app/api/lib/helpers/integrityDigest';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('integrity-digest');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const dryRun = args.includes('--dry-run');
+  const licenceeIds = (readFlag(args, '--licencee') || '')
+    .split(',')
+    .map(id => id.trim())
+    .filter(Boolean);
+
+  const sinceFlag = readFlag(args, '--since');
+  if (sinceFlag && !/^\d{4}-\d{2}-\d{2}$/.test(sinceFlag)) {
+    throw new Error(`--since must be YYYY-MM-DD, got '${sinceFlag}'`);
+  }
+  const since = sinceFlag ? new Date(`${sinceFlag}T00:00:00.000Z`) : undefined;
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const result = await sendIntegrityDigests({ since, licenceeIds, dryRun });
+  audit.addRows(result.issueCount);
+  await audit.finish({ success: true, exitCode: result.failed > 0 ? 1 : 0 });
+  await mongoose.disconnect();
+
+  console.log(
+    asJson
+      ? JSON.stringify(result, null, 2)
+      : formatIntegrityDigestResult(result)
+  );
+  process.exit(result.failed > 0 ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[integrity-digest] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -8,13 +8,14 @@
 
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 
 const MACHINE_ID = '6a0b3e15ad874aa2e816fbc5';
 const TARGET_DATE = new Date('2026-06-27T00:00:00.000Z');
 const TARGET_DATE_END = new Date('2026-06-28T00:00:00.000Z');
 
 async function main() {
-  await mongoose.connect(process.env.MONGODB_URI as string);
+  await connectCommandDatabase();
   const db = mongoose.connection.db!;
 
   console.log('============================================================');
@@ -13,10 +13,10 @@
 
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { Machine } from '../app/api/lib/models/machines';
 import { Meters } from '../app/api/lib/models/meters';
 
-const MONGODB_URI = process.env.MONGODB_URI as string;
 const NAME_FRAGMENT = process.argv[2] || 'WOW-250814-14';
 const LOOKBACK_DAYS = 60;
 
@@ -60,11 +60,7 @@ function dropAt(rows: MeterRow[], at: Date): MeterRow | null {
 }
 
 async function main(): Promise<void> {
-  if (!MONGODB_URI) {
-    console.error('MONGODB_URI not set');
-    process.exit(1);
-  }
-  await mongoose.connect(MONGODB_URI);
+  await connectCommandDatabase();
   const dbName = mongoose.connection.db?.databaseName;
   console.log(`Connected to ${dbName}\n`);
 
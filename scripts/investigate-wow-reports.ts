@@ -13,6 +13,7 @@
  */
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { CollectionReport } from '../app/api/lib/models/collectionReport';
 import { Collections } from '../app/api/lib/models/collections';
 import { GamingLocations } from '../app/api/lib/models/gaminglocations';
@@ -25,7 +26,6 @@ import {
 } from '../app/api/lib/helpers/collectionReport/variation';
 import type { MeterWindowQuery, MachineVariationFlags } from '../app/api/lib/helpers/collectionReport/variation';
 
-const MONGODB_URI = process.env.MONGODB_URI as string;
 const SINGLE_REPORT_ID = process.argv[2];
 
 const n = (v?: number | null) => (v == null ? 'null' : v.toLocaleString('en-US', { minimumFractionDigits: 2, maximumFractionDigits: 2 }));
@@ -189,7 +189,7 @@ async function analyzeReport(report: any): Promise<ReportAnalysis | null> {
 }
 
 async function main() {
-  await mongoose.connect(MONGODB_URI);
+  await connectCommandDatabase();
 
   if (SINGLE_REPORT_ID) {
     // Single report deep-dive
@@ -0,0 +1,31 @@
+/**
+ * CLI Arguments
+ *
+ * Flag readers shared by the command scripts. Flags take their value either
+ * as the next argument (`--env prod`) or inline (`--env=prod`).
+ *
+ * @module scripts/lib/cliArgs
+ */
+
+/** The value of `name`, or undefined when the flag is absent */
+export function readFlag(args: string[], name: string): string | undefined {
+  const index = args.findIndex(
+    arg => arg === name || arg.startsWith(`${name}=`)
+  );
+  if (index === -1) return undefined;
+  return args[index].includes('=')
+    ? args[index].slice(name.length + 1)
+    : args[index + 1];
+}
+
+/** Every value of a repeatable flag, in order */
+export function readFlagValues(args: string[], name: string): string[] {
+  const values: string[] = [];
+  args.forEach((arg, index) => {
+    if (arg === name && args[index + 1]) values.push(args[index + 1]);
+    else if (arg.startsWith(`${name}=`)) {
+      values.push(arg.slice(name.length + 1));
+    }
+  });
+  return values;
+}
@@ -0,0 +1,298 @@
+/**
+ * Licencees Command
+ *
+ * Creates, updates and deactivates licencees with the same checks as the app
+ * (unique, non-empty licence key; existing country; unique name), instead of
+ * editing documents by hand:
+ * `bun run licencees -- create --name "Cabana" --country Guyana --contact-email ops@cabana.gy`
+ * `bun run licencees -- update Cabana --licence-key LIC-CABANA-2026 --jurisdiction Guyana`
+ * `bun run licencees -- deactivate Cabana --reason "contract ended"`
+ * `bun run licencees -- check-keys --fix`.
+ *
+ * Actions:
+ *   list                      Every licencee with status and key
+ *   show <id|name>            One licencee's details
+ *   create                    New licencee (--name and --country required)
+ *   update <id|name>          Change the fields given
+ *   deactivate <id|name>      Set status inactive (--reason required)
+ *   check-keys                Report missing, placeholder or duplicate keys
+ *
+ * Options:
+ *   --env <profile>           Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --name <text>             Licencee name
+ *   --country <id|name>       Country
+ *   --licence-key <key>       Licence key (create: generated when omitted)
+ *   --jurisdiction <text>     Regulator jurisdiction, when not the country name
+ *   --contact-name <text>     Contact person
+ *   --contact-email <email>   Contact email
+ *   --contact-phone <text>    Contact phone
+ *   --start-date YYYY-MM-DD   Licence start (create: today)
+ *   --expiry-date YYYY-MM-DD  Licence expiry (create: 30 days after start)
+ *   --description <text>      Description
+ *   --reason <text>           deactivate: why
+ *   --fix                     check-keys: generate keys for missing/placeholder ones
+ *   --yes                     Skip the confirmation prompt
+ *   --json                    Print the result as JSON
+ *
+ * Pass an empty value (e.g. `--jurisdiction ""`) on update to clear a field.
+ *
+ * Exit codes: 0 = done, 1 = rejected (invalid input, not found, conflict) or
+ * check-keys found problems, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  createLicenceeRecord,
+  deactivateLicencee,
+  findLicenceeKeyProblems,
+  formatLicencee,
+  repairLicenceeKeys,
+  resolveLicencee,
+  updateLicenceeRecord,
+} from '../app/api/lib/helpers/licenceeManagement';
+import type { LicenceeInput } from '../app/api/lib/helpers/licenceeManagement';
+import { Licencee } from '../app/api/lib/models/licencee';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import type { LicenceeDocument } from '../shared/types';
+import { readFlag } from './lib/cliArgs';
+
+const VALUE_FLAGS = [
+  '--env',
+  '--max-time-ms',
+  '--name',
+  '--country',
+  '--licence-key',
+  '--jurisdiction',
+  '--contact-name',
+  '--contact-email',
+  '--contact-phone',
+  '--start-date',
+  '--expiry-date',
+  '--description',
+  '--reason',
+];
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !VALUE_FLAGS.includes(args[index - 1] ?? '')
+  );
+}
+
+/** undefined when the flag is absent, null when it is empty */
+function readDate(args: string[], name: string): Date | null | undefined {
+  const value = readFlag(args, name);
+  if (value === undefined) return undefined;
+  if (value === '') return null;
+  const date = new Date(value);
+  if (Number.isNaN(date.getTime())) {
+    throw new Error(`${name} is not a date: '${value}'`);
+  }
+  return date;
+}
+
+function readInput(args: string[]): LicenceeInput {
+  return {
+    name: readFlag(args, '--name'),
+    country: readFlag(args, '--country'),
+    licenceKey: readFlag(args, '--licence-key'),
+    jurisdiction: readFlag(args, '--jurisdiction'),
+    contactName: readFlag(args, '--contact-name'),
+    contactEmail: readFlag(args, '--contact-email'),
+    contactPhone: readFlag(args, '--contact-phone'),
+    startDate: readDate(args, '--start-date') ?? undefined,
+    expiryDate: readDate(args, '--expiry-date'),
+    description: readFlag(args, '--description'),
+  };
+}
+
+const audit = startCommandAudit('licencees');
+
+async function finish(exitCode: number) {
+  await audit.finish({ success: exitCode !== 2, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const [action, licencee] = readPositionals(args);
+  const print = (value: unknown, text: string) =>
+    console.log(asJson ? JSON.stringify(value, null, 2) : text);
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const operator = getOperator();
+  const actor = { userId: `cli:${operator}`, username: operator };
+
+  try {
+    switch (action) {
+      case 'list': {
+        const licencees = await Licencee.find({})
+          .sort({ name: 1 })
+          .lean<LicenceeDocument[]>();
+        audit.addRows(licencees.length);
+        print(
+          licencees,
+          licencees
+            .map(
+              entry =>
+                `${entry._id}  ${entry.name.padEnd(24)}  ${(
+                  entry.status || 'active'
+                ).padEnd(8)}  ${entry.licenceKey || '(no key)'}${
+                  entry.deletedAt ? '  (deleted)' : ''
+                }`
+            )
+            .join('\n') || 'No licencees'
+        );
+        return finish(0);
+      }
+
+      case 'show': {
+        if (!licencee) throw new Error('Usage: licencees show <id|name>');
+        const found = await resolveLicencee(licencee);
+        audit.addRows(1);
+        print(found, formatLicencee(found));
+        return finish(0);
+      }
+
+      case 'create': {
+        const input = readInput(args);
+        await confirmDestructiveOperation(
+          target,
+          `Create licencee '${input.name ?? ''}'`
+        );
+        const created = await createLicenceeRecord(input, actor);
+        audit.addRows(1);
+        print(created, `Created licencee\n${formatLicencee(created)}`);
+        return finish(0);
+      }
+
+      case 'update': {
+        if (!licencee) {
+          throw new Error('Usage: licencees update <id|name> [--field value]');
+        }
+        await confirmDestructiveOperation(
+          target,
+          `Update licencee '${licencee}'`
+        );
+        const result = await updateLicenceeRecord(
+          licencee,
+          readInput(args),
+          actor
+        );
+        audit.addRows(result.changes.length > 0 ? 1 : 0);
+        print(
+          result,
+          result.changes.length > 0
+            ? `Updated ${result.changes
+                .map(change => change.field)
+                .join(', ')}\n${formatLicencee(result.licencee)}`
+            : 'Nothing to change'
+        );
+        return finish(0);
+      }
+
+      case 'deactivate': {
+        if (!licencee) {
+          throw new Error(
+            'Usage: licencees deactivate <id|name> --reason <text>'
+          );
+        }
+        const reason = readFlag(args, '--reason') || '';
+        if (!reason.trim()) {
+          console.error('[licencees] A reason is required (--reason <text>)');
+          return finish(1);
+        }
+        await confirmDestructiveOperation(
+          target,
+          `Deactivate licencee '${licencee}'`
+        );
+        const updated = await deactivateLicencee(licencee, reason, actor);
+        audit.addRows(updated ? 1 : 0);
+        print(
+          updated,
+          updated
+            ? `Deactivated licencee ${updated.name}`
+            : 'Licencee is already inactive'
+        );
+        return finish(0);
+      }
+
+      case 'check-keys': {
+        const problems = await findLicenceeKeyProblems();
+        audit.addRows(problems.length);
+        if (!args.includes('--fix') || problems.length === 0) {
+          print(
+            problems,
+            problems.length > 0
+              ? problems
+                  .map(
+                    entry =>
+                      `${entry.problem.padEnd(11)}  ${entry.licenceeId}  ${
+                        entry.name
+                      }  ${entry.licenceKey ?? '(null)'}`
+                  )
+                  .join('\n')
+              : 'All licence keys are set and unique'
+          );
+          return finish(problems.length > 0 ? 1 : 0);
+        }
+        await confirmDestructiveOperation(
+          target,
+          'Generate licence keys for licencees with a missing or placeholder key'
+        );
+        const repaired = await repairLicenceeKeys(problems, actor);
+        const duplicates = problems.filter(
+          entry => entry.problem === 'duplicate'
+        );
+        print(
+          { repaired, duplicates },
+          [
+            ...repaired.map(
+              entry => `Assigned ${entry.newKey} to ${entry.name}`
+            ),
+            ...duplicates.map(
+              entry =>
+                `Duplicate key ${entry.licenceKey} on ${entry.name} (${entry.licenceeId}); fix with update --licence-key`
+            ),
+          ].join('\n')
+        );
+        return finish(duplicates.length > 0 ? 1 : 0);
+      }
+
+      default:
+        throw new Error(
+          'Usage: licencees list | show <licencee> | create | update <licencee> | deactivate <licencee> | check-keys [--fix]'
+        );
+    }
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 400 || statusCode === 404 || statusCode === 409) {
+      console.error(`[licencees] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+}
+
+main().catch(async error => {
+  console.error(
+    '[licencees] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
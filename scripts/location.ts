@@ -0,0 +1,124 @@
+/**
+ * Location Command
+ *
+ * Prints a full venue snapshot for the ops weekly review: machines with
+ * their statuses, meter totals for a range, the last collection report,
+ * open integrity issues and variance alerts, and recent significant events:
+ * `bun run location -- report <locationId> --env prod --period 7d`
+ * `bun run location -- report <locationId> --start 2026-06-01 --end 2026-06-07 --json`.
+ *
+ * Actions:
+ *   report <locationId>   Venue snapshot
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --period <period>     Time period (default 7d; Today, Yesterday, 30d, ...)
+ *   --start <date>        Custom range start (with --end)
+ *   --end <date>          Custom range end (with --start)
+ *   --events N            Recent events to list (default 25)
+ *   --json                Print the report as JSON
+ *
+ * Exit codes: 0 = printed, 1 = location not found, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DEFAULT_LOCATION_REPORT_EVENTS,
+  formatLocationReport,
+  getLocationReport,
+} from '../app/api/lib/helpers/locations/locationReport';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--env',
+    '--max-time-ms',
+    '--period',
+    '--start',
+    '--end',
+    '--events',
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('location');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [action, locationId] = readPositionals(args);
+  if (action !== 'report' || !locationId) {
+    throw new Error(
+      'Usage: location report <locationId> [--period 7d | --start <date> --end <date>] [--json]'
+    );
+  }
+
+  const start = readFlag(args, '--start');
+  const end = readFlag(args, '--end');
+  if (Boolean(start) !== Boolean(end)) {
+    throw new Error('--start and --end must be given together');
+  }
+  const customStartDate = start ? new Date(start) : undefined;
+  const customEndDate = end ? new Date(end) : undefined;
+  if (
+    (customStartDate && Number.isNaN(customStartDate.getTime())) ||
+    (customEndDate && Number.isNaN(customEndDate.getTime()))
+  ) {
+    throw new Error('--start and --end must be valid dates');
+  }
+  const events = Number(
+    readFlag(args, '--events') || DEFAULT_LOCATION_REPORT_EVENTS
+  );
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  try {
+    const report = await getLocationReport({
+      locationId,
+      timePeriod: customStartDate
+        ? 'Custom'
+        : readFlag(args, '--period') || '7d',
+      customStartDate,
+      customEndDate,
+      eventLimit:
+        Number.isFinite(events) && events >= 0
+          ? Math.floor(events)
+          : DEFAULT_LOCATION_REPORT_EVENTS,
+    });
+    audit.addRows(report.machines.length);
+    console.log(
+      args.includes('--json')
+        ? JSON.stringify(report, null, 2)
+        : formatLocationReport(report)
+    );
+  } catch (error) {
+    if (getErrorStatus(error) === 404) {
+      console.error(`[location] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+  process.exit(0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[location] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
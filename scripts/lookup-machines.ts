@@ -0,0 +1,91 @@
+/**
+ * Machine Lookup Command
+ *
+ * Looks up a batch of serial numbers and prints each machine's location,
+ * licencee, status and meter summary, then the serials that matched nothing:
+ * `bun run machines:lookup -- SN1001 SN1002`
+ * `bun run machines:lookup -- --serials-file serials.txt --csv --out lookup.csv`.
+ * Serials can also be piped in (`pbpaste | bun run machines:lookup -- -`).
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --serials-file <path>    Read serials from a file (lines, commas or spaces)
+ *   --json                   Print the result as JSON
+ *   --csv                    Print the result as CSV
+ *   --out <path>             Write the JSON or CSV output to this file
+ *
+ * Exit codes: 0 = all found, 1 = some serials not found, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import { readFileSync, writeFileSync } from 'fs';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  exportMachineLookupToCSV,
+  formatMachineLookup,
+  lookupMachinesBySerial,
+  parseSerialList,
+} from '../app/api/lib/helpers/machineLookup';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = ['--env', '--max-time-ms', '--serials-file', '--out'];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('machines:lookup');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const serials = readPositionals(args);
+  const serialsFile = readFlag(args, '--serials-file');
+  if (serialsFile) {
+    serials.push(...parseSerialList(readFileSync(serialsFile, 'utf8')));
+  }
+  if (args.includes('-')) {
+    serials.push(...parseSerialList(readFileSync(0, 'utf8')));
+  }
+  if (serials.length === 0) {
+    throw new Error(
+      'Usage: machines:lookup <serial...> | --serials-file <path> | - [--json | --csv] [--out <path>]'
+    );
+  }
+  const outFile = readFlag(args, '--out');
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const result = await lookupMachinesBySerial(serials);
+  audit.addRows(result.found.length);
+
+  let output = formatMachineLookup(result);
+  if (args.includes('--csv')) output = exportMachineLookupToCSV(result);
+  else if (args.includes('--json')) output = JSON.stringify(result, null, 2);
+  if (outFile) {
+    writeFileSync(outFile, output);
+    console.log(formatMachineLookup(result));
+    console.log(`Written to ${outFile}`);
+  } else {
+    console.log(output);
+  }
+
+  const exitCode = result.notFound.length > 0 ? 1 : 0;
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[machines:lookup] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
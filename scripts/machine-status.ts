@@ -0,0 +1,127 @@
+/**
+ * Machine Lifecycle Status Command
+ *
+ * Changes a machine's assetStatus along its lifecycle (active, in-repair,
+ * storage), validating the transition and recording who changed it and why
+ * in the machine's statusHistory:
+ * `bun run machine-status -- <machineId> in-repair --reason "bill validator jam"`.
+ * Retiring goes through `bun run machine -- retire`, which checks the final
+ * collection and records the decommission certificate.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --reason <text>       Why the status is changing (required for changes)
+ *   --history             Print the machine's status history instead
+ *
+ * Exit codes: 0 = changed, 1 = transition rejected, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  changeMachineAssetStatus,
+  MACHINE_LIFECYCLE_STATUSES,
+  normalizeAssetStatus,
+} from '../app/api/lib/helpers/machineLifecycle';
+import { Machine } from '../app/api/lib/models/machines';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import type {
+  MachineLifecycleStatus,
+  MachineStatusHistoryEntry,
+} from '../shared/types';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = ['--env', '--max-time-ms', '--reason'];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('machine-status');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [machineId, status] = readPositionals(args);
+  if (!machineId) {
+    throw new Error(
+      `Usage: machine-status <machineId> <${MACHINE_LIFECYCLE_STATUSES.join('|')}> --reason <text>`
+    );
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // History mode
+  if (args.includes('--history') || !status) {
+    const machine = await Machine.findOne(
+      { _id: machineId },
+      { assetStatus: 1, statusHistory: 1 }
+    ).lean<{
+      assetStatus?: string;
+      statusHistory?: MachineStatusHistoryEntry[];
+    }>();
+    if (!machine) throw new Error(`Machine ${machineId} not found`);
+    console.log(`Current: ${normalizeAssetStatus(machine.assetStatus)}`);
+    (machine.statusHistory || []).forEach(entry => {
+      console.log(
+        `  ${new Date(entry.changedAt).toISOString()}  ${entry.from} -> ${entry.to}  by ${entry.username}: ${entry.reason}`
+      );
+    });
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    process.exit(0);
+  }
+
+  if (status === 'retired') {
+    console.error(
+      `[machine-status] Retire machines with 'bun run machine -- retire ${machineId} --reason <text>'`
+    );
+    await audit.finish({ success: false, exitCode: 1 });
+    await mongoose.disconnect();
+    process.exit(1);
+  }
+
+  const operator = getOperator();
+  try {
+    const result = await changeMachineAssetStatus({
+      machineId,
+      to: status as MachineLifecycleStatus,
+      reason: readFlag(args, '--reason') || '',
+      userId: `cli:${operator}`,
+      username: operator,
+    });
+    audit.addRows(1);
+    console.log(`Machine ${machineId}: ${result.from} -> ${result.to}`);
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 400 || statusCode === 409) {
+      console.error(`[machine-status] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+  process.exit(0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[machine-status] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,170 @@
+/**
+ * Machine Views Command
+ *
+ * Saves, lists and runs a user's named machine views (see
+ * app/api/lib/helpers/machineViews.ts), the same views as
+ * `/api/machines/views`:
+ * `bun run machine-views -- show my-route --user jdoe`.
+ *
+ * Commands:
+ *   list                          List the user's views
+ *   show <name>                   Run a view and print its machines
+ *     --limit N                   Page size (default: the machine list's)
+ *     --cursor <cursor>           Next page, from the previous run
+ *     --json                      Print the page as JSON
+ *   save <name>                   Create or replace a view
+ *     --licencee <id>             Licencee filter
+ *     --location <id,...>         Location filter
+ *     --status <status,...>       Asset status filter
+ *     --columns <field,...>       Columns to show (default: serial number,
+ *                                 name, game, location, status, last activity)
+ *     --sort <[-]field>           Sort field, - for descending
+ *     --description <text>
+ *   delete <name>                 Delete a view (asks for confirmation)
+ *
+ * Options:
+ *   --user <username>             Required. The view owner; views run with
+ *                                 this user's location access
+ *   --env <profile>               Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --yes                         Skip the delete confirmation
+ *   --read-only                   Block save and delete
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import type { ApiAuthContext } from '../app/api/lib/helpers/apiWrapper';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  deleteMachineView,
+  getMachineView,
+  listMachineViews,
+  runMachineView,
+  saveMachineView,
+} from '../app/api/lib/helpers/machineViews';
+import UserModel from '../app/api/lib/models/user';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { readFlag } from './lib/cliArgs';
+
+function readList(args: string[], name: string): string[] | undefined {
+  const value = readFlag(args, name);
+  if (value === undefined) return undefined;
+  return value
+    .split(',')
+    .map(item => item.trim())
+    .filter(Boolean);
+}
+
+/** The user's auth context, as `withApiAuth()` would build it */
+async function loadUser(username: string): Promise<ApiAuthContext> {
+  const user = await UserModel.findOne({ username }).lean<{
+    _id: string;
+    username: string;
+    emailAddress?: string;
+    roles?: string[];
+    assignedLicencees?: string[];
+    assignedLocations?: string[];
+  }>();
+  if (!user) throw new Error(`User '${username}' not found`);
+  const userRoles = user.roles || [];
+  return {
+    user: { ...user, _id: String(user._id) },
+    userRoles,
+    isAdminOrDev: ['admin', 'developer', 'owner'].some(role =>
+      userRoles.includes(role)
+    ),
+  };
+}
+
+const audit = startCommandAudit('machine-views');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [command, name] = args;
+  if (!['list', 'show', 'save', 'delete'].includes(command)) {
+    throw new Error(
+      'Usage: machine-views <list|show|save|delete> [name] --user <username>'
+    );
+  }
+  if (command !== 'list' && (!name || name.startsWith('--'))) {
+    throw new Error(`${command} requires a view name`);
+  }
+  const username = readFlag(args, '--user');
+  if (!username) throw new Error('--user <username> is required');
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const auth = await loadUser(username);
+  const userId = auth.user._id;
+
+  if (command === 'list') {
+    const views = await listMachineViews(userId);
+    audit.addRows(views.length);
+    console.table(
+      views.map(view => ({
+        name: view.name,
+        licencee: view.filters.licencee || '',
+        locations: view.filters.locations.join(', '),
+        statuses: view.filters.statuses.join(', '),
+        columns: view.columns.join(', '),
+        sort: view.sort,
+        description: view.description || '',
+      }))
+    );
+  } else if (command === 'show') {
+    const view = await getMachineView(userId, name);
+    if (!view) throw new Error(`${username} has no view named '${name}'`);
+    const page = await runMachineView(view, auth, {
+      limit: readFlag(args, '--limit'),
+      cursor: readFlag(args, '--cursor'),
+    });
+    audit.addRows(page.data.length);
+    if (args.includes('--json')) {
+      console.log(JSON.stringify(page, null, 2));
+    } else {
+      console.table(page.data);
+      if (page.pagination.nextCursor) {
+        console.log(`More machines: --cursor ${page.pagination.nextCursor}`);
+      }
+    }
+  } else if (command === 'save') {
+    const { view, created } = await saveMachineView({
+      userId,
+      name,
+      description: readFlag(args, '--description'),
+      filters: {
+        licencee: readFlag(args, '--licencee') || null,
+        locations: readList(args, '--location'),
+        statuses: readList(args, '--status'),
+      },
+      columns: readList(args, '--columns'),
+      sort: readFlag(args, '--sort'),
+    });
+    audit.addRows(1);
+    console.log(
+      `${created ? 'Created' : 'Updated'} view '${view.name}' for ${username} (${view.columns.join(', ')}; sort ${view.sort})`
+    );
+  } else {
+    await confirmDestructiveOperation(
+      target,
+      `delete machine view '${name}' of ${username}`
+    );
+    const view = await deleteMachineView(userId, name);
+    if (!view) throw new Error(`${username} has no view named '${name}'`);
+    audit.addRows(1);
+    console.log(`Deleted view '${view.name}'`);
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+}
+
+main().catch(async error => {
+  console.error(
+    '[machine-views] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 1, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(1);
+});
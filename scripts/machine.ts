@@ -0,0 +1,218 @@
+/**
+ * Machine Command
+ *
+ * Prints a deep-dive on one cabinet: configuration, current SAS and
+ * collection meters, meter movement for a range, the last 20 events,
+ * collection history entries and open integrity issues:
+ * `bun run machine -- show <serial> --env prod --period 30d`
+ * `bun run machine -- show <machineId> --start 2026-06-01 --end 2026-06-07 --json`.
+ *
+ * Also retires machines for good (see machineDecommission): the machine must
+ * be in storage with a final collection and no play since, its final meters
+ * are recorded and the decommission certificate for the regulator is written:
+ * `bun run machine -- retire <serial> --reason "end of lease" --env prod`.
+ *
+ * Actions:
+ *   show <serial|id>         Machine deep-dive
+ *   retire <serial|id>       Retire the machine and write its certificate
+ *   certificate <serial|id>  Write the certificate of a retired machine again
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --period <period>     Time period (default 7d; Today, Yesterday, 30d, ...)
+ *   --start <date>        Custom range start (with --end)
+ *   --end <date>          Custom range end (with --start)
+ *   --events N            Recent events to list (default 20)
+ *   --history N           Collection history entries to list (default 10)
+ *   --json                Print the report as JSON
+ *   --reason <text>       Why the machine is retired (retire)
+ *   --dry-run             Check the machine and print its final meters (retire)
+ *   --yes                 Skip the confirmation prompt (retire)
+ *   --format <format>     Certificate format: text (default) or xml
+ *   --out <file>          Certificate file (default
+ *                         decommission-<certificate number>.<txt|xml>)
+ *
+ * Exit codes: 0 = done, 1 = machine not found, serial ambiguous or not
+ * retirable, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  CERTIFICATE_FORMATS,
+  formatRetirementPlan,
+  getDecommissionCertificate,
+  planMachineRetirement,
+  renderDecommissionCertificate,
+  retireMachine,
+} from '../app/api/lib/helpers/machineDecommission';
+import type { CertificateFormat } from '../app/api/lib/helpers/machineDecommission';
+import {
+  DEFAULT_MACHINE_EVENTS,
+  DEFAULT_MACHINE_HISTORY,
+  formatMachineDetails,
+  getMachineDetails,
+} from '../app/api/lib/helpers/machineDetails';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--env',
+    '--max-time-ms',
+    '--period',
+    '--start',
+    '--end',
+    '--events',
+    '--history',
+    '--reason',
+    '--format',
+    '--out',
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('machine');
+
+/** Writes the certificate of a retired machine and prints where */
+async function writeCertificate(
+  serialOrId: string,
+  format: CertificateFormat,
+  out: string | undefined
+): Promise<void> {
+  const certificate = await getDecommissionCertificate(serialOrId);
+  const file =
+    out ||
+    `decommission-${certificate.certificateNumber}.${
+      format === 'xml' ? 'xml' : 'txt'
+    }`;
+  fs.writeFileSync(file, renderDecommissionCertificate(certificate, format));
+  console.log(`Certificate ${certificate.certificateNumber} written to ${file}`);
+}
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [action, serialOrId] = readPositionals(args);
+  if (!['show', 'retire', 'certificate'].includes(action) || !serialOrId) {
+    throw new Error(
+      'Usage: machine show <serial|id> [--period 7d | --start <date> --end <date>] [--json]\n' +
+        '       machine retire <serial|id> --reason <text> [--dry-run] [--yes] [--format text|xml] [--out <file>]\n' +
+        '       machine certificate <serial|id> [--format text|xml] [--out <file>]'
+    );
+  }
+  const format = (readFlag(args, '--format') || 'text') as CertificateFormat;
+  if (!CERTIFICATE_FORMATS.includes(format)) {
+    throw new Error(
+      `Unknown format '${format}'. Available: ${CERTIFICATE_FORMATS.join(', ')}`
+    );
+  }
+  const reason = readFlag(args, '--reason') || '';
+  if (action === 'retire' && !reason.trim()) {
+    throw new Error('retire needs --reason <text>');
+  }
+
+  const start = readFlag(args, '--start');
+  const end = readFlag(args, '--end');
+  if (Boolean(start) !== Boolean(end)) {
+    throw new Error('--start and --end must be given together');
+  }
+  const customStartDate = start ? new Date(start) : undefined;
+  const customEndDate = end ? new Date(end) : undefined;
+  if (
+    (customStartDate && Number.isNaN(customStartDate.getTime())) ||
+    (customEndDate && Number.isNaN(customEndDate.getTime()))
+  ) {
+    throw new Error('--start and --end must be valid dates');
+  }
+  const readLimit = (name: string, fallback: number) => {
+    const value = Number(readFlag(args, name) || fallback);
+    return Number.isFinite(value) && value >= 0 ? Math.floor(value) : fallback;
+  };
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  try {
+    if (action === 'retire') {
+      const plan = await planMachineRetirement(serialOrId);
+      console.log(formatRetirementPlan(plan));
+      if (plan.problems.length === 0 && !args.includes('--dry-run')) {
+        await confirmDestructiveOperation(
+          target,
+          `Retire machine ${plan.serialNumber || plan.machineId}`
+        );
+        const operator = getOperator();
+        const record = await retireMachine(serialOrId, reason, {
+          userId: `cli:${operator}`,
+          username: operator,
+        });
+        audit.addRows(1);
+        console.log(
+          `Machine ${plan.serialNumber || plan.machineId} retired (${record.certificateNumber})`
+        );
+        await writeCertificate(serialOrId, format, readFlag(args, '--out'));
+      }
+      const exitCode = plan.problems.length > 0 ? 1 : 0;
+      await audit.finish({ success: true, exitCode });
+      await mongoose.disconnect();
+      process.exit(exitCode);
+    }
+    if (action === 'certificate') {
+      await writeCertificate(serialOrId, format, readFlag(args, '--out'));
+      audit.addRows(1);
+      await audit.finish({ success: true, exitCode: 0 });
+      await mongoose.disconnect();
+      process.exit(0);
+    }
+
+    const details = await getMachineDetails({
+      serialOrId,
+      timePeriod: customStartDate
+        ? 'Custom'
+        : readFlag(args, '--period') || '7d',
+      customStartDate,
+      customEndDate,
+      eventLimit: readLimit('--events', DEFAULT_MACHINE_EVENTS),
+      historyLimit: readLimit('--history', DEFAULT_MACHINE_HISTORY),
+    });
+    audit.addRows(1);
+    console.log(
+      args.includes('--json')
+        ? JSON.stringify(details, null, 2)
+        : formatMachineDetails(details)
+    );
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 400 || statusCode === 404 || statusCode === 409) {
+      console.error(`[machine] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+  process.exit(0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[machine] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
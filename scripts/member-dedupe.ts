@@ -0,0 +1,162 @@
+/**
+ * Member Deduplication Command
+ *
+ * Lists probable duplicate members (matching email, phone, or name and date
+ * of birth) and merges a group into one surviving member:
+ * `bun run members:dedupe -- scan --licencee <id>`
+ * `bun run members:dedupe -- merge <survivorId> <duplicateId...> --reason "double signup" --dry-run`.
+ *
+ * Merging re-points the duplicates' sessions and accepted bills to the
+ * survivor, adds their points to it and archives them (`mergedInto`).
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --licencee <id>          scan: only this licencee's members
+ *   --location <id>          scan: only this location's members
+ *   --reason <text>          merge: why the members are merged (required to write)
+ *   --allow-cross-location   merge: allow duplicates from other locations
+ *   --dry-run                merge: print the plan without writing
+ *   --yes                    merge: skip the confirmation prompt
+ *   --json                   Print the result as JSON
+ *
+ * Exit codes: 0 = no duplicates (scan) or merged (merge), 1 = duplicates
+ * found, or merge refused or incomplete, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  executeMemberMerge,
+  findDuplicateMembers,
+  formatDuplicateScan,
+  formatMemberMergePlan,
+  planMemberMerge,
+} from '../app/api/lib/helpers/members/deduplication';
+import type { MemberMergePlan } from '../app/api/lib/helpers/members/deduplication';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--env',
+    '--max-time-ms',
+    '--licencee',
+    '--location',
+    '--reason',
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('members:dedupe');
+
+async function finish(exitCode: number) {
+  await audit.finish({ success: exitCode !== 2, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const [action, survivorId, ...duplicateIds] = readPositionals(args);
+  if (action !== 'scan' && action !== 'merge') {
+    throw new Error(
+      'Usage: members:dedupe scan [--licencee <id> | --location <id>] | merge <survivorId> <duplicateId...> --reason <text> [--dry-run]'
+    );
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // Scan
+  if (action === 'scan') {
+    const scan = await findDuplicateMembers({
+      licenceeId: readFlag(args, '--licencee'),
+      locationId: readFlag(args, '--location'),
+    });
+    audit.addRows(scan.groups.length);
+    console.log(
+      asJson ? JSON.stringify(scan, null, 2) : formatDuplicateScan(scan)
+    );
+    return finish(scan.groups.length > 0 ? 1 : 0);
+  }
+
+  // Merge
+  if (!survivorId || duplicateIds.length === 0) {
+    throw new Error('merge needs <survivorId> <duplicateId...>');
+  }
+  let plan: MemberMergePlan;
+  try {
+    plan = await planMemberMerge(survivorId, duplicateIds, {
+      allowCrossLocation: args.includes('--allow-cross-location'),
+    });
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 400 || statusCode === 404 || statusCode === 409) {
+      console.error(`[members:dedupe] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+
+  console.log(formatMemberMergePlan(plan));
+  if (args.includes('--dry-run')) {
+    console.log('Dry run: nothing written.');
+    return finish(0);
+  }
+
+  const reason = readFlag(args, '--reason') || '';
+  if (!reason.trim()) {
+    console.error('[members:dedupe] A reason is required (--reason <text>)');
+    return finish(1);
+  }
+  await confirmDestructiveOperation(
+    target,
+    `Merge ${plan.duplicates.length} member(s) into ${plan.survivor.memberId}`
+  );
+
+  const operator = getOperator();
+  const result = await executeMemberMerge(plan, {
+    reason,
+    userId: `cli:${operator}`,
+    username: operator,
+  });
+  audit.addRows(result.merged.length);
+
+  if (asJson) {
+    console.log(JSON.stringify(result, null, 2));
+  } else {
+    console.log(
+      `Merged ${result.merged.length} member(s) into ${plan.survivor.memberId}: ${result.sessionsMoved} session(s), ${result.acceptedBillsMoved} accepted bill(s), ${result.pointsMoved} point(s) moved`
+    );
+    console.log(`Backup run: ${result.backupRunId}`);
+    if (result.skipped.length > 0) {
+      console.warn(
+        `Skipped (changed since planning): ${result.skipped.join(', ')}`
+      );
+    }
+  }
+  return finish(result.skipped.length > 0 ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[members:dedupe] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
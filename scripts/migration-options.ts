@@ -0,0 +1,70 @@
+/**
+ * Migration Options Command
+ *
+ * Recreates the indexes, validators, collation / capped settings and views a
+ * migration export read from the source (`collection-options.json`, written
+ * when the export runs with `includeCollectionOptions`) on the destination,
+ * and reports which collections, views and indexes were created:
+ * `bun run migration:options -- --env staging --dry-run`
+ * `bun run migration:options -- --env staging --dir migration_exports`.
+ *
+ * Options:
+ *   --env <profile>     Destination database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --dir <path>        Export directory (default migration_exports)
+ *   --dry-run           Report what would be created without writing
+ *   --json              Print the results as JSON
+ *
+ * Exit codes: 0 = applied, 1 = some indexes failed, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import path from 'path';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import {
+  applyCollectionDefinitions,
+  formatCollectionDefinitionResults,
+  readCollectionDefinitionsFile,
+} from '../app/api/lib/utils/migrationCollectionOptions';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('migration:options');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const dryRun = args.includes('--dry-run');
+  const dir = path.resolve(readFlag(args, '--dir') || 'migration_exports');
+  const definitions = await readCollectionDefinitionsFile(dir);
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const db = mongoose.connection.db;
+  if (!db) throw new Error('Database connection failed');
+
+  const results = await applyCollectionDefinitions(db, definitions, dryRun);
+  const failed = results.some(result => result.indexesFailed.length > 0);
+  audit.addRows(
+    results.reduce((sum, result) => sum + result.indexesCreated.length, 0)
+  );
+  console.log(
+    args.includes('--json')
+      ? JSON.stringify(results, null, 2)
+      : formatCollectionDefinitionResults(results, dryRun)
+  );
+
+  const exitCode = failed ? 1 : 0;
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[migration:options] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,145 @@
+/**
+ * Machine Move Command
+ *
+ * Reassigns a set of machines, or every machine at a source location, to a
+ * target location, recording completed movement requests:
+ * `bun run machines:move -- <toLocationId> SN1001 SN1002 --reason "venue consolidation"`
+ * `bun run machines:move -- <toLocationId> --from <locationId> --reason "venue closed" --dry-run`.
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --from <locationId>      Move every machine at this location instead
+ *   --serials-file <path>    Read serials from a file (whitespace or commas)
+ *   --reason <text>          Why the machines are moving (required to write)
+ *   --dry-run                Print the planned move without writing
+ *   --yes                    Skip the confirmation prompt
+ *
+ * Exit codes: 0 = moved (or dry run), 1 = move rejected or incomplete,
+ * 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import { readFileSync } from 'fs';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  executeMachineMove,
+  formatMachineMovePlan,
+  planMachineMove,
+} from '../app/api/lib/helpers/machineMove';
+import type { MachineMovePlan } from '../app/api/lib/helpers/machineMove';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--env',
+    '--max-time-ms',
+    '--from',
+    '--reason',
+    '--serials-file',
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('machines:move');
+
+async function finish(exitCode: number) {
+  await audit.finish({ success: exitCode !== 2, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [toLocationId, ...serials] = readPositionals(args);
+  const serialsFile = readFlag(args, '--serials-file');
+  if (serialsFile) {
+    serials.push(...readFileSync(serialsFile, 'utf8').split(/[\s,]+/));
+  }
+  const dryRun = args.includes('--dry-run');
+  if (!toLocationId) {
+    throw new Error(
+      'Usage: machines:move <toLocationId> <serial...> | --from <locationId> --reason <text> [--dry-run]'
+    );
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  let plan: MachineMovePlan;
+  try {
+    plan = await planMachineMove({
+      toLocationId,
+      serials,
+      fromLocationId: readFlag(args, '--from'),
+    });
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 400 || statusCode === 404) {
+      console.error(`[machines:move] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+
+  console.log(formatMachineMovePlan(plan));
+  const incomplete = plan.notFound.length > 0 || plan.ambiguous.length > 0;
+  if (dryRun || plan.moves.length === 0) {
+    if (dryRun) console.log('Dry run: nothing written.');
+    return finish(incomplete ? 1 : 0);
+  }
+
+  const reason = readFlag(args, '--reason') || '';
+  if (!reason.trim()) {
+    console.error('[machines:move] A reason is required (--reason <text>)');
+    return finish(1);
+  }
+  await confirmDestructiveOperation(
+    target,
+    `Move ${plan.moves.length} machine(s) to ${plan.toLocationName}`
+  );
+
+  const operator = getOperator();
+  const result = await executeMachineMove(plan, {
+    reason,
+    userId: `cli:${operator}`,
+    username: operator,
+  });
+  audit.addRows(result.moved.length);
+
+  console.log(
+    `Moved ${result.moved.length} machine(s) to ${plan.toLocationName}; movement request(s): ${result.movementRequestIds.join(', ') || 'none'}`
+  );
+  console.log(`Backup run: ${result.backupRunId}`);
+  if (result.skipped.length > 0) {
+    console.warn(
+      `Skipped (location changed since planning): ${result.skipped
+        .map(move => move.serialNumber)
+        .join(', ')}`
+    );
+  }
+
+  return finish(incomplete || result.skipped.length > 0 ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[machines:move] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
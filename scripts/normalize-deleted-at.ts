@@ -0,0 +1,179 @@
+/**
+ * deletedAt Normalization Command
+ *
+ * Rewrites every legacy "not deleted" `deletedAt` (the `-1` date or number
+ * sentinel, other pre-2025 dates, a missing field) to `null`, after backing up
+ * the old values: `bun run normalize-deleted-at -- --env staging --dry-run`.
+ *
+ * The shared soft-delete filters still accept the legacy shapes, so it can run
+ * at any time after a deploy. Its audit record is what allows tightening them
+ * to `{ deletedAt: null }` later.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --dry-run             Only count what would be rewritten
+ *   --collections a,b     Collections to normalize (default: all with deletedAt)
+ *   --run-id <id>         Backup id for this run (default: timestamp)
+ *   --restore <run-id>    Put back the values a run backed up and exit
+ *   --yes                 Skip the confirmation prompt
+ *   --json                Print the report as JSON
+ *   --report-file <path>  Also write the JSON report to this file
+ *
+ * After a run, older backup runs are pruned when BACKUP_KEEP_LAST,
+ * BACKUP_MAX_AGE_DAYS or BACKUP_MAX_SIZE_MB is set (see `bun run backups`).
+ *
+ * JOB_WEBHOOK_URL / SLACK_WEBHOOK_URL are notified when a run (not a dry run)
+ * finishes or errors (see jobNotifications).
+ *
+ * Exit codes: 0 = done (or nothing to do), 1 = dry run found values to rewrite, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { applyBackupRetention } from '../app/api/lib/helpers/backupRetention';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DELETED_AT_BACKUP_COLLECTION,
+  formatDeletedAtReport,
+  normalizeDeletedAt,
+  restoreDeletedAtBackup,
+} from '../app/api/lib/helpers/deletedAtNormalization';
+import {
+  notifyJobFinished,
+  writeJobReport,
+} from '../app/api/lib/helpers/jobNotifications';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('normalize-deleted-at');
+const startedAt = new Date();
+let targetName: string | undefined;
+let notifyOnError = false;
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const dryRun = args.includes('--dry-run');
+  const reportFile = readFlag(args, '--report-file');
+
+  const target = await connectCommandDatabase();
+  targetName = target.name;
+  audit.setTarget(target.name);
+
+  // Restore mode
+  const restoreRunId = readFlag(args, '--restore');
+  if (restoreRunId) {
+    await confirmDestructiveOperation(
+      target,
+      `Restore deletedAt values backed up by run ${restoreRunId}`
+    );
+    const restored = await restoreDeletedAtBackup(
+      mongoose.connection,
+      restoreRunId
+    );
+    audit.addRows(restored);
+    console.log(`Restored deletedAt on ${restored} documents`);
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    process.exit(0);
+  }
+
+  const collectionsFlag = readFlag(args, '--collections');
+  const runId = readFlag(args, '--run-id') || String(Date.now());
+  if (!dryRun) {
+    await confirmDestructiveOperation(
+      target,
+      `Rewrite legacy deletedAt values to null (backup run ${runId})`
+    );
+    notifyOnError = true;
+  }
+
+  const report = await normalizeDeletedAt(mongoose.connection, {
+    runId,
+    dryRun,
+    collections: collectionsFlag
+      ? collectionsFlag.split(',').map(name => name.trim()).filter(Boolean)
+      : undefined,
+  });
+  const retention = dryRun
+    ? null
+    : await applyBackupRetention(mongoose.connection, 'deleted-at');
+  const exitCode = dryRun && report.total > 0 ? 1 : 0;
+  audit.addRows(report.total);
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+
+  const reportPath = reportFile
+    ? await writeJobReport(reportFile, report)
+    : undefined;
+  if (!dryRun) {
+    await notifyJobFinished({
+      job: 'normalize-deleted-at',
+      kind: 'migration',
+      status: 'completed',
+      target: target.name,
+      startedAt,
+      finishedAt: report.finishedAt,
+      counts: {
+        collections: report.collections.length,
+        rewritten: report.total,
+      },
+      summary: `Backup run ${runId}${
+        report.locationIndexRebuilt
+          ? '; rebuilt unique_active_location_name'
+          : ''
+      }`,
+      report: reportPath,
+    });
+  }
+
+  if (asJson) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    console.log(formatDeletedAtReport(report));
+    if (dryRun) {
+      console.log(`\n${report.total} documents would be rewritten (dry run)`);
+    } else {
+      console.log(
+        [
+          '',
+          `${report.total} documents rewritten; old values in ${DELETED_AT_BACKUP_COLLECTION} (run ${runId})`,
+          report.locationIndexRebuilt
+            ? 'Rebuilt unique_active_location_name for deletedAt: null'
+            : '',
+          retention && retention.pruned.length > 0
+            ? `Pruned ${retention.pruned.length} older backup run(s) by retention policy`
+            : '',
+          `Undo with: bun run normalize-deleted-at -- --env ${target.name} --restore ${runId}`,
+        ]
+          .filter(Boolean)
+          .join('\n')
+      );
+    }
+  }
+
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[normalize-deleted-at] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  if (notifyOnError) {
+    await notifyJobFinished({
+      job: 'normalize-deleted-at',
+      kind: 'migration',
+      status: 'failed',
+      target: targetName,
+      startedAt,
+      finishedAt: new Date(),
+      counts: {},
+      error: error instanceof Error ? error.message : String(error),
+    });
+  }
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,143 @@
+/**
+ * Pre-aggregation Command
+ *
+ * Checks the `casinoMetrics` documents written by the pre-aggregation
+ * workers: users whose metrics are older than a threshold, and drift between
+ * a sample of stored "Yesterday" totals and values freshly computed from
+ * meters (see metricsFreshness). Meant for cron and monitoring hosts that
+ * cannot sign in to `GET /api/metrics/preaggregate-status`:
+ * `bun run preaggregate -- status --env prod --max-age 30`.
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --max-age N              Metrics older than N minutes are stale (default 60)
+ *   --sample N               Users to recompute for drift (default 5, max 50)
+ *   --max-stale N            Allowed share of stale users in percent (default 0)
+ *   --max-drift N            Allowed drift in percent (default 1)
+ *   --json                   Print the report as JSON
+ *
+ * Exit codes: 0 = healthy, 1 = stale or drifting over the limits, 2 = the
+ * run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DEFAULT_FRESHNESS_OPTIONS,
+  getMetricsFreshnessReport,
+  MAX_FRESHNESS_SAMPLE_SIZE,
+} from '../app/api/lib/helpers/users/metricsFreshness';
+import type {
+  MetricsFreshnessOptions,
+  MetricsFreshnessReport,
+} from '../app/api/lib/helpers/users/metricsFreshness';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--env',
+    '--max-time-ms',
+    '--max-age',
+    '--sample',
+    '--max-stale',
+    '--max-drift',
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+/** Non-negative number flag, or the fallback when absent */
+function readLimit(args: string[], name: string, fallback: number): number {
+  const value = readFlag(args, name);
+  if (value === undefined) return fallback;
+  const limit = Number(value);
+  if (value === '' || !Number.isFinite(limit) || limit < 0) {
+    throw new Error(`Invalid ${name}: ${value}`);
+  }
+  return limit;
+}
+
+function readOptions(args: string[]): MetricsFreshnessOptions {
+  const defaults = DEFAULT_FRESHNESS_OPTIONS;
+  return {
+    maxAgeMinutes: readLimit(args, '--max-age', defaults.maxAgeMinutes),
+    sampleSize: Math.min(
+      Math.floor(readLimit(args, '--sample', defaults.sampleSize)),
+      MAX_FRESHNESS_SAMPLE_SIZE
+    ),
+    maxStalePercent: readLimit(args, '--max-stale', defaults.maxStalePercent),
+    maxDriftPercent: readLimit(args, '--max-drift', defaults.maxDriftPercent),
+  };
+}
+
+function printReport(report: MetricsFreshnessReport) {
+  const { thresholds } = report;
+  console.log(
+    `casinoMetrics ${report.healthy ? 'healthy' : 'UNHEALTHY'} at ${report.checkedAt.toISOString()}`
+  );
+  console.log(
+    `  stale   ${report.staleUsers.length} of ${report.totalUsers} user(s) (${report.stalePercent}%, limit ${thresholds.maxStalePercent}%) older than ${thresholds.maxAgeMinutes} min`
+  );
+  console.log(
+    `  drift   max ${report.maxObservedDriftPercent}% over ${report.sampledUsers} sampled user(s) (limit ${thresholds.maxDriftPercent}%)`
+  );
+
+  report.staleUsers.forEach(user => {
+    console.log(
+      `  STALE ${user.userId} lastUpdated=${
+        user.lastUpdated ? user.lastUpdated.toISOString() : 'never'
+      }${user.ageMinutes === null ? '' : ` (${user.ageMinutes} min)`}`
+    );
+  });
+  report.drift.forEach(sample => {
+    const flag =
+      sample.driftPercent > thresholds.maxDriftPercent ? 'DRIFT' : 'OK   ';
+    console.log(
+      `  ${flag} ${sample.userId} ${sample.field.padEnd(8)} stored=${sample.stored.toFixed(2)} fresh=${sample.fresh.toFixed(2)}  ${sample.driftPercent}%`
+    );
+  });
+}
+
+const audit = startCommandAudit('preaggregate');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const [action] = readPositionals(args);
+  if (action !== 'status') {
+    throw new Error(
+      'Usage: preaggregate status [--max-age N] [--sample N] [--max-stale N] [--max-drift N] [--json]'
+    );
+  }
+  const options = readOptions(args);
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const report = await getMetricsFreshnessReport(options);
+  audit.addRows(report.totalUsers);
+  const exitCode = report.healthy ? 0 : 1;
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    printReport(report);
+  }
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[preaggregate] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,166 @@
+/**
+ * Interactive Query Builder
+ *
+ * Prompts for a base entity (machines/locations/meters), filters (licencee,
+ * status, date range), group-by fields and metrics, then prints the generated
+ * pipeline and runs it: `bun run query-builder -- --env reporting`.
+ *
+ * Options:
+ *   --env <profile>   Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --spec <file>     Skip the prompts and run a saved spec (JSON)
+ *   --print-only      Print the pipeline without running it
+ *   --json            Print rows as JSON instead of a table
+ *   --tenant <id>     Pin the query to one licencee (see tenantScope); also TENANT_LICENCEE_ID
+ */
+
+import 'dotenv/config';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import readline from 'readline/promises';
+import {
+  buildQueryPipeline,
+  formatPipelineForShell,
+  QUERY_BUILDER_CATALOG,
+  runQuerySpec,
+  validateQuerySpec,
+} from '../app/api/lib/helpers/reports/queryBuilder';
+import type {
+  QueryBuilderEntity,
+  QueryBuilderSpec,
+} from '../app/api/lib/helpers/reports/queryBuilder';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+function parseList(answer: string, allowed: string[]): string[] {
+  return answer
+    .split(',')
+    .map(value => value.trim())
+    .filter(Boolean)
+    .map(value => {
+      const byIndex = allowed[Number(value) - 1];
+      return /^\d+$/.test(value) && byIndex ? byIndex : value;
+    });
+}
+
+async function promptSpec(): Promise<QueryBuilderSpec> {
+  const rl = readline.createInterface({
+    input: process.stdin,
+    output: process.stdout,
+  });
+
+  try {
+    const entities = Object.keys(QUERY_BUILDER_CATALOG) as QueryBuilderEntity[];
+    entities.forEach((entity, index) =>
+      console.log(`  ${index + 1}. ${QUERY_BUILDER_CATALOG[entity].label}`)
+    );
+    const [entity] = parseList(
+      await rl.question('Base entity: '),
+      entities
+    ) as QueryBuilderEntity[];
+    const catalog = QUERY_BUILDER_CATALOG[entity];
+    if (!catalog) throw new Error(`Unknown entity '${entity}'`);
+
+    const licencee = (await rl.question('Licencee ID (blank = all): ')).trim();
+    const status =
+      entity === 'meters'
+        ? ''
+        : (
+            await rl.question(
+              `Status${catalog.statusValues.length ? ` (${catalog.statusValues.join('/')})` : ''} (blank = any): `
+            )
+          ).trim();
+    const dateHint = catalog.requiresDateRange ? 'required' : 'blank = none';
+    const startDate = (
+      await rl.question(`${catalog.dateField} from (YYYY-MM-DD, ${dateHint}): `)
+    ).trim();
+    const endDate = (
+      await rl.question(`${catalog.dateField} to (YYYY-MM-DD, ${dateHint}): `)
+    ).trim();
+
+    const groupKeys = Object.keys(catalog.groupBy);
+    groupKeys.forEach((key, index) =>
+      console.log(`  ${index + 1}. ${key} — ${catalog.groupBy[key].label}`)
+    );
+    const groupBy = parseList(
+      await rl.question('Group by (comma-separated, blank = none): '),
+      groupKeys
+    );
+
+    const metricKeys = Object.keys(catalog.metrics);
+    metricKeys.forEach((key, index) =>
+      console.log(`  ${index + 1}. ${key} — ${catalog.metrics[key].label}`)
+    );
+    const metrics = parseList(
+      await rl.question('Metrics (comma-separated, blank = count): '),
+      metricKeys
+    );
+    const limit = Number(
+      (await rl.question('Row limit (blank = 100): ')).trim() || 100
+    );
+
+    return {
+      entity,
+      filters: {
+        licencee: licencee || undefined,
+        status: status || undefined,
+        startDate: startDate || undefined,
+        endDate: endDate ? `${endDate}T23:59:59.999Z` : undefined,
+      },
+      groupBy,
+      metrics,
+      limit,
+    };
+  } finally {
+    rl.close();
+  }
+}
+
+const audit = startCommandAudit('query-builder');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const specFile = readFlag(args, '--spec');
+  const spec = specFile
+    ? (JSON.parse(fs.readFileSync(specFile, 'utf8')) as QueryBuilderSpec)
+    : await promptSpec();
+
+  const validationError = validateQuerySpec(spec);
+  if (validationError) throw new Error(validationError);
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  if (args.includes('--print-only')) {
+    const pipeline = await buildQueryPipeline(spec);
+    console.log(formatPipelineForShell(spec.entity, pipeline));
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    return;
+  }
+
+  const result = await runQuerySpec(spec);
+  audit.addRows(result.rows.length);
+  console.log('\nPipeline:');
+  console.log(result.shell);
+  console.log(`\nSpec (save and rerun with --spec):\n${JSON.stringify(spec)}`);
+  console.log(`\n${result.rows.length} row(s):`);
+  if (args.includes('--json')) {
+    console.log(JSON.stringify(result.rows, null, 2));
+  } else {
+    console.table(result.rows);
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+}
+
+main().catch(async error => {
+  console.error(
+    '[query-builder] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 1, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(1);
+});
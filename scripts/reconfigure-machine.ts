@@ -0,0 +1,238 @@
+/**
+ * Machine Reconfiguration Command
+ *
+ * Records a game / denomination change on a machine (appended to its
+ * configurationHistory and applied to its configuration), or reports the
+ * machine's meters split at those changes:
+ * `bun run reconfigure -- <machineId> --game "Buffalo Gold" --denomination 0.05 --at 2026-03-01T08:00 --reason "game conversion"`
+ * `bun run reconfigure -- <machineId> --compare`
+ * `bun run reconfigure -- <machineId> --roi --at 2026-03-01T08:00 --window-days 30`.
+ *
+ * Options:
+ *   --env <profile>          Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --game <name>            New game
+ *   --game-type <type>       New game type
+ *   --denomination N         New accounting denomination
+ *   --paytable <id>          New paytable ID
+ *   --rtp N                  New theoretical RTP
+ *   --max-bet <value>        New max bet
+ *   --at <date>              When the change took effect (default: now), or
+ *                            the conversion date for --roi
+ *   --reason <text>          Why (required when recording)
+ *   --history                Print the configuration history
+ *   --report                 Print the meters split at each change (--from / --to)
+ *   --compare [eventId]      Compare before/after a change (default: the latest)
+ *   --roi                    Game conversion ROI report: daily gross, coin in
+ *                            and occupancy before/after the conversion at --at
+ *                            (default: the latest game change)
+ *   --window-days N          Days on each side for --compare / --roi (default 30)
+ *   --json                   Print reports as JSON
+ *
+ * Exit codes: 0 = done, 1 = change rejected, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  compareReconfiguration,
+  DEFAULT_COMPARISON_WINDOW_DAYS,
+  formatReconfigurationComparison,
+  formatReconfigurationReport,
+  getReconfigurationSegments,
+  recordMachineReconfiguration,
+} from '../app/api/lib/helpers/machineReconfiguration';
+import {
+  formatGameConversionReport,
+  getGameConversionReport,
+} from '../app/api/lib/helpers/reports/gameConversion';
+import { Machine } from '../app/api/lib/models/machines';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import type {
+  MachineConfigField,
+  MachineReconfigurationEntry,
+} from '../shared/types';
+import { readFlag } from './lib/cliArgs';
+
+const VALUE_FLAGS: Record<string, MachineConfigField> = {
+  '--game': 'game',
+  '--game-type': 'gameType',
+  '--denomination': 'accountingDenomination',
+  '--paytable': 'payTableId',
+  '--rtp': 'theoreticalRtp',
+  '--max-bet': 'maxBet',
+};
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--env',
+    '--max-time-ms',
+    '--reason',
+    '--at',
+    '--from',
+    '--to',
+    '--window-days',
+    ...Object.keys(VALUE_FLAGS),
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+function parseDate(value: string | undefined, flag: string): Date | undefined {
+  if (!value) return undefined;
+  const date = new Date(value);
+  if (Number.isNaN(date.getTime())) throw new Error(`Invalid ${flag}: ${value}`);
+  return date;
+}
+
+const audit = startCommandAudit('reconfigure');
+
+async function finish(exitCode: number) {
+  await audit.finish({ success: exitCode !== 2, exitCode });
+  await mongoose.disconnect();
+  process.exit(exitCode);
+}
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [machineId] = readPositionals(args);
+  const asJson = args.includes('--json');
+  if (!machineId) {
+    throw new Error(
+      'Usage: reconfigure <machineId> [--game <name>] [--denomination N] ... --reason <text> | --history | --report | --compare [eventId] | --roi [--at <date>]'
+    );
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // History mode
+  if (args.includes('--history')) {
+    const machine = await Machine.findOne(
+      { _id: machineId },
+      { configurationHistory: 1 }
+    ).lean<{ configurationHistory?: MachineReconfigurationEntry[] }>();
+    if (!machine) throw new Error(`Machine ${machineId} not found`);
+    (machine.configurationHistory || []).forEach(entry => {
+      const changes = entry.changes
+        .map(
+          change =>
+            `${change.field} ${change.oldValue ?? '(unset)'} -> ${change.newValue}`
+        )
+        .join(', ');
+      console.log(
+        `  ${new Date(entry.changedAt).toISOString()}  [${entry._id}] ${changes}  by ${entry.username}: ${entry.reason}`
+      );
+    });
+    return finish(0);
+  }
+
+  // Report modes
+  if (args.includes('--report')) {
+    const report = await getReconfigurationSegments(machineId, {
+      from: parseDate(readFlag(args, '--from'), '--from'),
+      to: parseDate(readFlag(args, '--to'), '--to'),
+    });
+    audit.addRows(report.segments.length);
+    console.log(
+      asJson
+        ? JSON.stringify(report, null, 2)
+        : formatReconfigurationReport(report)
+    );
+    return finish(0);
+  }
+  if (args.includes('--compare')) {
+    const next = args[args.indexOf('--compare') + 1];
+    const eventId = next && !next.startsWith('-') ? next : undefined;
+    const comparison = await compareReconfiguration(
+      machineId,
+      eventId,
+      Number(readFlag(args, '--window-days')) || DEFAULT_COMPARISON_WINDOW_DAYS
+    );
+    audit.addRows(2);
+    console.log(
+      asJson
+        ? JSON.stringify(comparison, null, 2)
+        : formatReconfigurationComparison(comparison)
+    );
+    return finish(0);
+  }
+
+  if (args.includes('--roi')) {
+    const report = await getGameConversionReport({
+      machineId,
+      conversionDate: parseDate(readFlag(args, '--at'), '--at'),
+      days:
+        Number(readFlag(args, '--window-days')) ||
+        DEFAULT_COMPARISON_WINDOW_DAYS,
+    });
+    audit.addRows(report.daily.length);
+    console.log(
+      asJson
+        ? JSON.stringify(report, null, 2)
+        : formatGameConversionReport(report)
+    );
+    return finish(0);
+  }
+
+  // Record mode
+  const values: Partial<Record<MachineConfigField, string>> = {};
+  Object.entries(VALUE_FLAGS).forEach(([flag, field]) => {
+    const value = readFlag(args, flag);
+    if (value !== undefined) values[field] = value;
+  });
+  if (Object.keys(values).length === 0) {
+    throw new Error(
+      `Nothing to record; pass at least one of ${Object.keys(VALUE_FLAGS).join(', ')}`
+    );
+  }
+
+  const operator = getOperator();
+  try {
+    const entry = await recordMachineReconfiguration({
+      machineId,
+      values,
+      changedAt: parseDate(readFlag(args, '--at'), '--at'),
+      reason: readFlag(args, '--reason') || '',
+      userId: `cli:${operator}`,
+      username: operator,
+    });
+    audit.addRows(1);
+    console.log(
+      `Machine ${machineId} reconfigured from ${entry.changedAt.toISOString()} [${entry._id}]`
+    );
+    entry.changes.forEach(change =>
+      console.log(
+        `  ${change.field}: ${change.oldValue ?? '(unset)'} -> ${change.newValue}`
+      )
+    );
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 400 || statusCode === 409) {
+      console.error(`[reconfigure] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+
+  return finish(0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[reconfigure] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,103 @@
+/**
+ * Collection Report Regeneration Command
+ *
+ * Recomputes a collection report's totals (drop, cancelled, gross, SAS gross,
+ * variation, machines collected) from its member collections after machine
+ * data was corrected, prints the diff, and writes it after confirmation:
+ * `bun run regenerate-report -- --env prod <locationReportId>`.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --dry-run             Only print the diff
+ *   --yes                 Skip the confirmation prompt
+ *   --json                Print the preview as JSON
+ *
+ * Exit codes: 0 = written or already up to date, 1 = dry run found changes (or
+ * the report is missing / changed meanwhile), 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  applyReportRegeneration,
+  formatRegenerationPreview,
+  previewReportRegeneration,
+} from '../app/api/lib/helpers/collectionReport/regeneration';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = ['--env', '--max-time-ms'];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const audit = startCommandAudit('regenerate-report');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const dryRun = args.includes('--dry-run');
+  const [reportId] = readPositionals(args);
+  if (!reportId) {
+    throw new Error('Usage: regenerate-report <reportId> [--dry-run]');
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  try {
+    const preview = await previewReportRegeneration(reportId);
+    console.log(
+      asJson
+        ? JSON.stringify(preview, null, 2)
+        : formatRegenerationPreview(preview)
+    );
+
+    if (preview.changes.length > 0 && !dryRun) {
+      await confirmDestructiveOperation(
+        target,
+        `Overwrite ${preview.changes.length} totals on collection report ${preview.locationReportId}`
+      );
+      const operator = getOperator();
+      const written = await applyReportRegeneration(preview, {
+        _id: `cli:${operator}`,
+        username: operator,
+      });
+      audit.addRows(written);
+      if (!asJson) console.log(`\nUpdated ${written} fields`);
+    }
+
+    const exitCode = dryRun && preview.changes.length > 0 ? 1 : 0;
+    await audit.finish({ success: true, exitCode });
+    await mongoose.disconnect();
+    process.exit(exitCode);
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 404 || statusCode === 409) {
+      console.error(`[regenerate-report] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+}
+
+main().catch(async error => {
+  console.error(
+    '[regenerate-report] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,121 @@
+/**
+ * Regulator Submission Command
+ *
+ * Renders a licencee's monthly per-machine meter summary in the gaming
+ * commission's fixed-width or XML format, after a validation pass that
+ * rejects missing machines and negative values:
+ * `bun run regulator-submission -- --env prod --licencee <id> --month 2026-09`.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --licencee <id>       Licencee to submit for (required)
+ *   --month YYYY-MM       Calendar month (default: last month)
+ *   --format fixed|xml    Output format (default fixed)
+ *   --out <file>          Output file (default regulator-<licence key>-<YYYYMM>.<txt|xml>)
+ *   --json                Print the validation result as JSON
+ *
+ * Exit codes: 0 = file written, 1 = validation failed (no file written),
+ * 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  buildMonthlySubmission,
+  renderSubmission,
+  SUBMISSION_FORMATS,
+  validateSubmission,
+} from '../app/api/lib/helpers/regulatorSubmission';
+import type { SubmissionFormat } from '../app/api/lib/helpers/regulatorSubmission';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+function lastMonth(): string {
+  const now = new Date();
+  const date = new Date(Date.UTC(now.getUTCFullYear(), now.getUTCMonth() - 1));
+  return date.toISOString().slice(0, 7);
+}
+
+const audit = startCommandAudit('regulator-submission');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const licenceeId = readFlag(args, '--licencee');
+  const month = readFlag(args, '--month') || lastMonth();
+  const format = (readFlag(args, '--format') || 'fixed') as SubmissionFormat;
+
+  if (!licenceeId) throw new Error('--licencee <id> is required');
+  if (!/^\d{4}-(0[1-9]|1[0-2])$/.test(month)) {
+    throw new Error(`--month must be YYYY-MM (got '${month}')`);
+  }
+  if (!SUBMISSION_FORMATS.includes(format)) {
+    throw new Error(
+      `Unknown format '${format}'. Available: ${SUBMISSION_FORMATS.join(', ')}`
+    );
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // Build and validate
+  const submission = await buildMonthlySubmission(licenceeId, month);
+  const issues = validateSubmission(submission);
+  const out =
+    readFlag(args, '--out') ||
+    `regulator-${submission.licenceKey}-${month.replace('-', '')}.${
+      format === 'xml' ? 'xml' : 'txt'
+    }`;
+  const exitCode = issues.length > 0 ? 1 : 0;
+
+  if (exitCode === 0) {
+    fs.writeFileSync(out, renderSubmission(submission, format));
+  }
+  audit.addRows(submission.machines.length);
+  await audit.finish({ success: true, exitCode });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(
+      JSON.stringify(
+        {
+          licencee: submission.licenceeId,
+          month,
+          format,
+          machines: submission.machines.length,
+          totals: submission.totals,
+          file: exitCode === 0 ? out : null,
+          issues,
+        },
+        null,
+        2
+      )
+    );
+  } else if (exitCode === 0) {
+    console.log(
+      `Wrote ${out}: ${submission.licenceeName} ${month}, ${submission.machines.length} machines`
+    );
+  } else {
+    console.log(
+      `Submission rejected for ${submission.licenceeName} ${month}: ${issues.length} problem(s), no file written`
+    );
+    issues.forEach(issue =>
+      console.log(
+        `  ${issue.locationName} / ${issue.serialNumber || issue.machineId}: ${issue.problem}`
+      )
+    );
+  }
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[regulator-submission] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,138 @@
+/**
+ * Report Diff Command
+ *
+ * Compares two exported report files (CSV or JSON) and prints the rows added
+ * and removed and the values changed beyond a tolerance, for audit sign-off:
+ * `bun run report-diff -- september.csv october.csv --key "Serial Number"`
+ * `bun run report-diff -- before.json after.json --rows data.machines --tolerance 1 --json`.
+ * Either file may be an `s3://bucket/key` object (see objectStorage).
+ *
+ * Options:
+ *   --key <cols>             Comma-separated key columns (default: detected)
+ *   --ignore <cols>          Comma-separated columns to leave out
+ *   --tolerance N            Absolute change a number may move by (default 0.01)
+ *   --tolerance-pct N        Also require a change above N percent
+ *   --rows <path>            Dotted path to the rows in JSON files (e.g. data.machines)
+ *   --format csv|json        Input format (default: from the file extension)
+ *   --json                   Print the diff as JSON
+ *   --out <file>             Write the diff to a file (or s3:// object) instead of stdout
+ *
+ * Exit codes: 0 = no differences, 1 = differences found, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import path from 'path';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DEFAULT_DIFF_TOLERANCE,
+  diffReportRows,
+  formatReportDiff,
+  hasReportDifferences,
+  parseReportRows,
+} from '../app/api/lib/helpers/reports/reportDiff';
+import {
+  readStorageFile,
+  writeStorageFile,
+} from '../app/api/lib/utils/objectStorage';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = [
+    '--key',
+    '--ignore',
+    '--tolerance',
+    '--tolerance-pct',
+    '--rows',
+    '--format',
+    '--out',
+  ];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+function readList(value: string | undefined): string[] | undefined {
+  if (!value) return undefined;
+  return value
+    .split(',')
+    .map(column => column.trim())
+    .filter(Boolean);
+}
+
+function readNumber(args: string[], flag: string): number | undefined {
+  const value = readFlag(args, flag);
+  if (value === undefined) return undefined;
+  const number = Number(value);
+  if (!Number.isFinite(number) || number < 0) {
+    throw new Error(`Invalid ${flag}: ${value}`);
+  }
+  return number;
+}
+
+function readFormat(file: string, override?: string): 'csv' | 'json' {
+  const format = override || path.extname(file).slice(1).toLowerCase();
+  if (format !== 'csv' && format !== 'json') {
+    throw new Error(`Cannot tell the format of ${file}; pass --format`);
+  }
+  return format;
+}
+
+const audit = startCommandAudit('report-diff');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [beforeFile, afterFile] = readPositionals(args);
+  if (!beforeFile || !afterFile) {
+    throw new Error(
+      'Usage: report-diff <before-file> <after-file> [--key <cols>] [--tolerance N] [--json]'
+    );
+  }
+  audit.setTarget('files');
+
+  const rowsPath = readFlag(args, '--rows');
+  const formatOverride = readFlag(args, '--format');
+  const before = parseReportRows(
+    await readStorageFile(beforeFile),
+    readFormat(beforeFile, formatOverride),
+    rowsPath
+  );
+  const after = parseReportRows(
+    await readStorageFile(afterFile),
+    readFormat(afterFile, formatOverride),
+    rowsPath
+  );
+  audit.addRows(before.length + after.length);
+
+  const diff = diffReportRows(before, after, {
+    keyColumns: readList(readFlag(args, '--key')),
+    ignoreColumns: readList(readFlag(args, '--ignore')),
+    tolerance: readNumber(args, '--tolerance') ?? DEFAULT_DIFF_TOLERANCE,
+    tolerancePercent: readNumber(args, '--tolerance-pct'),
+  });
+
+  const output = args.includes('--json')
+    ? JSON.stringify(diff, null, 2)
+    : formatReportDiff(diff, { before: beforeFile, after: afterFile });
+  const outFile = readFlag(args, '--out');
+  if (outFile) {
+    await writeStorageFile(outFile, output);
+    console.log(`Wrote diff to ${outFile}`);
+  } else {
+    console.log(output);
+  }
+
+  const exitCode = hasReportDifferences(diff) ? 1 : 0;
+  await audit.finish({ success: true, exitCode });
+  process.exit(exitCode);
+}
+
+main().catch(async error => {
+  console.error(
+    '[report-diff] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  process.exit(2);
+});
@@ -0,0 +1,166 @@
+/**
+ * Report Templates Command
+ *
+ * Lists, saves, runs and deletes named report templates stored in
+ * `reporttemplates`: `bun run report-templates -- run weekly-leaderboard`.
+ *
+ * Commands:
+ *   list                          List saved templates
+ *   run <name>                    Run a template (all locations, unscaled)
+ *     --format json|csv|xlsx      Override the template's output format
+ *                                 (xlsx needs --out)
+ *     --profile <name>            Export profile for columns, labels and
+ *                                 number formats (see export-profiles.json)
+ *     --out <file>                Write the output to a file (or s3:// object)
+ *                                 instead of stdout
+ *   save <name>                   Create or replace a template
+ *     --type <reportType>         query-builder | licencee-leaderboard |
+ *                                 machine-utilization | shifts
+ *     --params <file>             Report parameters (JSON)
+ *     --format json|csv           Default output format (default json)
+ *     --description <text>
+ *   delete <name>                 Delete a template (asks for confirmation)
+ *
+ * Options:
+ *   --env <profile>               Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --yes                         Skip the delete confirmation
+ *   --read-only                   Block save and delete
+ */
+
+import 'dotenv/config';
+import fs from 'fs';
+import mongoose from 'mongoose';
+import * as XLSX from 'xlsx';
+import {
+  deleteReportTemplate,
+  getReportTemplateByName,
+  listReportTemplates,
+  runReportTemplate,
+  saveReportTemplate,
+} from '../app/api/lib/helpers/reports/reportTemplates';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { getExportProfile } from '../app/api/lib/utils/exportProfiles';
+import { writeStorageFile } from '../app/api/lib/utils/objectStorage';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import type { ReportTemplateType } from '../shared/types';
+import { readFlag } from './lib/cliArgs';
+
+function readFormat(args: string[]): 'json' | 'csv' | undefined {
+  const format = readFlag(args, '--format');
+  if (format && format !== 'json' && format !== 'csv') {
+    throw new Error('--format must be json or csv');
+  }
+  return format as 'json' | 'csv' | undefined;
+}
+
+const audit = startCommandAudit('report-templates');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [command, name] = args;
+  if (!['list', 'run', 'save', 'delete'].includes(command)) {
+    throw new Error('Usage: report-templates <list|run|save|delete> [name]');
+  }
+  if (command !== 'list' && (!name || name.startsWith('--'))) {
+    throw new Error(`${command} requires a template name`);
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  if (command === 'list') {
+    const templates = await listReportTemplates();
+    audit.addRows(templates.length);
+    console.table(
+      templates.map(template => ({
+        name: template.name,
+        reportType: template.reportType,
+        outputFormat: template.outputFormat,
+        lastRunAt: template.lastRunAt?.toISOString() || '',
+        description: template.description || '',
+      }))
+    );
+  } else if (command === 'run') {
+    const template = await getReportTemplateByName(name);
+    if (!template) throw new Error(`Template '${name}' not found`);
+
+    const outFile = readFlag(args, '--out');
+    const xlsx = readFlag(args, '--format') === 'xlsx';
+    if (xlsx && !outFile) throw new Error('--format xlsx requires --out');
+    const profileName = readFlag(args, '--profile');
+
+    const result = await runReportTemplate(
+      template,
+      {
+        allowedLocationIds: 'all',
+        profile: profileName ? getExportProfile(profileName) : undefined,
+      },
+      xlsx ? 'json' : readFormat(args)
+    );
+    audit.addRows(result.rows.length);
+    if (xlsx && outFile) {
+      const workbook = XLSX.utils.book_new();
+      XLSX.utils.book_append_sheet(
+        workbook,
+        XLSX.utils.json_to_sheet(result.rows),
+        template.name.slice(0, 31)
+      );
+      await writeStorageFile(
+        outFile,
+        XLSX.write(workbook, { type: 'buffer', bookType: 'xlsx' }) as Buffer,
+        'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet'
+      );
+      console.log(`Wrote ${result.rows.length} row(s) to ${outFile}`);
+    } else {
+      const output =
+        result.csv !== undefined
+          ? result.csv
+          : JSON.stringify(result.rows, null, 2);
+      if (outFile) {
+        await writeStorageFile(outFile, output);
+        console.log(`Wrote ${result.rows.length} row(s) to ${outFile}`);
+      } else {
+        console.log(output);
+      }
+    }
+  } else if (command === 'save') {
+    const paramsFile = readFlag(args, '--params');
+    if (!paramsFile) throw new Error('save requires --params <file>');
+
+    const { template, created } = await saveReportTemplate({
+      name,
+      description: readFlag(args, '--description'),
+      reportType: readFlag(args, '--type') as ReportTemplateType,
+      parameters: JSON.parse(fs.readFileSync(paramsFile, 'utf8')),
+      outputFormat: readFormat(args),
+      createdBy: process.env.USER || null,
+    });
+    audit.addRows(1);
+    console.log(
+      `${created ? 'Created' : 'Updated'} template '${template.name}' (${template.reportType})`
+    );
+  } else {
+    await confirmDestructiveOperation(
+      target,
+      `delete report template '${name}'`
+    );
+    const template = await deleteReportTemplate(name);
+    if (!template) throw new Error(`Template '${name}' not found`);
+    audit.addRows(1);
+    console.log(`Deleted template '${template.name}'`);
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+}
+
+main().catch(async error => {
+  console.error(
+    '[report-templates] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 1, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(1);
+});
@@ -0,0 +1,142 @@
+/**
+ * Migration Re-sync Command
+ *
+ * Tops up the destination after the initial migration by copying only the
+ * documents created or updated on the source since a given time, so it can
+ * be run repeatedly until cutover; each run prints the `--since` for the next:
+ * `bun run resync -- --since 2026-10-01T00:00:00Z --source prod --dest staging`
+ * `bun run resync -- --since 2026-10-01T00:00:00Z --collections meters,machines --dry-run`.
+ *
+ * Options:
+ *   --since <timestamp>       Copy documents changed at or after this time (required)
+ *   --source <profile>        Source database profile (default: SRC_MONGODB_URI)
+ *   --dest <profile>          Destination database profile (default: DST_MONGODB_URI)
+ *   --collections a,b:field   Collections to copy, optionally with the time field
+ *                             marking a change (default: licencees,gaminglocations,
+ *                             machines,meters,users; updatedAt / createdAt, readAt for meters)
+ *   --batch-size N            Documents per bulk write (default 1000)
+ *   --no-transforms           Copy documents as they are, without migration transforms
+ *   --dry-run                 Count the changed documents without writing
+ *   --json                    Print the report as JSON
+ *
+ * Exit codes: 0 = copied, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import type { Connection } from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DEFAULT_RESYNC_BATCH_SIZE,
+  DEFAULT_RESYNC_COLLECTIONS,
+  formatResyncReport,
+  parseResyncTarget,
+  resyncChangedDocuments,
+} from '../app/api/lib/helpers/migrationResync';
+import {
+  DEFAULT_CONNECT_OPTIONS,
+  redactMongoUri,
+  resolveDbProfile,
+} from '../app/api/lib/utils/dbProfiles';
+import { getSecret } from '../app/api/lib/utils/secrets';
+import { readFlag } from './lib/cliArgs';
+
+async function openConnection(
+  profile: string | undefined,
+  fallbackEnv: string
+): Promise<{ label: string; connection: Connection }> {
+  if (profile) {
+    const resolved = await resolveDbProfile(profile);
+    return {
+      label: resolved.name,
+      connection: await mongoose
+        .createConnection(resolved.uri, resolved.options)
+        .asPromise(),
+    };
+  }
+
+  const uri = await getSecret(fallbackEnv);
+  if (!uri) {
+    throw new Error(`Pass a profile or set ${fallbackEnv}`);
+  }
+  return {
+    label: redactMongoUri(uri),
+    connection: await mongoose
+      .createConnection(uri, DEFAULT_CONNECT_OPTIONS)
+      .asPromise(),
+  };
+}
+
+const audit = startCommandAudit('resync');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const sinceFlag = readFlag(args, '--since');
+  if (!sinceFlag) {
+    throw new Error(
+      'Usage: resync --since <timestamp> [--source <profile>] [--dest <profile>] [--collections a,b:field] [--dry-run]'
+    );
+  }
+  const since = new Date(sinceFlag);
+  if (Number.isNaN(since.getTime())) {
+    throw new Error('--since must be a valid timestamp');
+  }
+  const batchSize = Number(
+    readFlag(args, '--batch-size') || DEFAULT_RESYNC_BATCH_SIZE
+  );
+  if (!Number.isInteger(batchSize) || batchSize < 1) {
+    throw new Error('--batch-size must be a positive integer');
+  }
+  const collections = readFlag(args, '--collections');
+  const targets = (
+    collections ? collections.split(',') : DEFAULT_RESYNC_COLLECTIONS
+  )
+    .filter(Boolean)
+    .map(parseResyncTarget);
+  const asJson = args.includes('--json');
+
+  const source = await openConnection(
+    readFlag(args, '--source'),
+    'SRC_MONGODB_URI'
+  );
+  const destination = await openConnection(
+    readFlag(args, '--dest'),
+    'DST_MONGODB_URI'
+  );
+  audit.setTarget(`${source.label} -> ${destination.label}`);
+  if (!asJson) {
+    console.log(`Source:      ${source.label}`);
+    console.log(`Destination: ${destination.label}`);
+  }
+
+  const report = await resyncChangedDocuments(
+    source.connection,
+    destination.connection,
+    targets,
+    {
+      since,
+      batchSize,
+      transforms: !args.includes('--no-transforms'),
+      dryRun: args.includes('--dry-run'),
+    }
+  );
+  console.log(
+    asJson ? JSON.stringify(report, null, 2) : formatResyncReport(report)
+  );
+  audit.addRows(
+    report.collections.reduce((sum, collection) => sum + collection.read, 0)
+  );
+
+  await audit.finish({ success: true, exitCode: 0 }, source.connection);
+  await Promise.all([source.connection.close(), destination.connection.close()]);
+  process.exit(0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[resync] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  process.exit(2);
+});
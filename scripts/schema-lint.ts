@@ -0,0 +1,81 @@
+/**
+ * Schema Lint
+ *
+ * Scans collections for documents missing required fields or storing a
+ * field with the wrong type (e.g. `readAt` as a string), with counts per
+ * violation: `bun run schema:lint -- --env prod --collections meters,machines`.
+ *
+ * Options:
+ *   --env <profile>           Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --collections a,b         Collections to check (default: machines, meters,
+ *                             gaminglocations, collections, members)
+ *   --sample N                Inspect N random documents per collection (default: all)
+ *   --json                    Print the report as JSON
+ *
+ * Exit codes: 0 = no violations, 1 = violations found, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  SCHEMA_LINT_COLLECTIONS,
+  formatSchemaLintReport,
+  getSchemaLintReport,
+} from '../app/api/lib/helpers/schemaLint';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('schema:lint');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+
+  const collectionsFlag = readFlag(args, '--collections');
+  const collections = collectionsFlag
+    ? collectionsFlag
+        .split(',')
+        .map(name => name.trim())
+        .filter(Boolean)
+    : SCHEMA_LINT_COLLECTIONS;
+
+  const sampleFlag = readFlag(args, '--sample');
+  const sampleSize = sampleFlag ? Math.floor(Number(sampleFlag)) : null;
+  if (
+    sampleSize !== null &&
+    (!Number.isFinite(sampleSize) || sampleSize < 1)
+  ) {
+    throw new Error('--sample must be a positive number');
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const report = await getSchemaLintReport(
+    mongoose.connection,
+    collections,
+    sampleSize
+  );
+  const failed = report.totalViolations > 0;
+  audit.addRows(
+    report.collections.reduce((sum, result) => sum + result.examined, 0)
+  );
+  await audit.finish({ success: true, exitCode: failed ? 1 : 0 });
+  await mongoose.disconnect();
+
+  console.log(
+    asJson ? JSON.stringify(report, null, 2) : formatSchemaLintReport(report)
+  );
+
+  process.exit(failed ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[schema:lint] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
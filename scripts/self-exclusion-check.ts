@@ -0,0 +1,120 @@
+/**
+ * Self-Exclusion Check
+ *
+ * Finds sessions recorded for self-excluded members after their exclusion
+ * started (and before it ended, if it has an end date) and reports them for
+ * compliance follow-up: `bun run self-exclusion:check -- --env prod --since 2026-01-01`.
+ *
+ * Options:
+ *   --env <profile>           Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --licencee <id>           Only sessions on this licencee's machines
+ *   --location <id>           Only sessions on this location's machines
+ *   --since YYYY-MM-DD        Only sessions starting from this date
+ *   --json                    Print the report as JSON
+ *   --csv <path>              Also write the breaches as CSV
+ *   --report-file <path>      Also write the JSON report to this file
+ *   --webhook <url>           Post an alert when breaches are found (or SELF_EXCLUSION_WEBHOOK_URL)
+ *
+ * JOB_WEBHOOK_URL / SLACK_WEBHOOK_URL are notified when the run finishes or
+ * errors (see jobNotifications).
+ *
+ * Exit codes: 0 = no breaches, 1 = breaches found, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import { writeFile } from 'fs/promises';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  notifyJobFinished,
+  writeJobReport,
+} from '../app/api/lib/helpers/jobNotifications';
+import {
+  exportSelfExclusionBreachesToCSV,
+  findSelfExclusionBreaches,
+  formatSelfExclusionReport,
+  postSelfExclusionWebhook,
+} from '../app/api/lib/helpers/members/selfExclusion';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('self-exclusion:check');
+const startedAt = new Date();
+let targetName: string | undefined;
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const csvFile = readFlag(args, '--csv');
+  const reportFile = readFlag(args, '--report-file');
+  const webhookUrl =
+    readFlag(args, '--webhook') || process.env.SELF_EXCLUSION_WEBHOOK_URL;
+  const sinceFlag = readFlag(args, '--since');
+  const since = sinceFlag ? new Date(sinceFlag) : undefined;
+  if (since && Number.isNaN(since.getTime())) {
+    throw new Error(`--since is not a date: '${sinceFlag}'`);
+  }
+
+  const target = await connectCommandDatabase();
+  targetName = target.name;
+  audit.setTarget(target.name);
+  const report = await findSelfExclusionBreaches({
+    licenceeId: readFlag(args, '--licencee'),
+    locationId: readFlag(args, '--location'),
+    since,
+  });
+  const breached = report.breaches.length > 0;
+  audit.addRows(report.breaches.length);
+  await audit.finish({ success: true, exitCode: breached ? 1 : 0 });
+  await mongoose.disconnect();
+
+  console.log(
+    asJson ? JSON.stringify(report, null, 2) : formatSelfExclusionReport(report)
+  );
+  if (csvFile) {
+    await writeFile(csvFile, exportSelfExclusionBreachesToCSV(report));
+  }
+
+  if (webhookUrl && breached) {
+    await postSelfExclusionWebhook(webhookUrl, report);
+  }
+  await notifyJobFinished({
+    job: 'self-exclusion:check',
+    kind: 'detection',
+    status: 'completed',
+    target: target.name,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {
+      exclusions: report.exclusionsChecked,
+      members: report.membersInBreach,
+      sessions: report.breaches.length,
+    },
+    summary: breached
+      ? `Sessions found for ${report.membersInBreach} self-excluded member(s)`
+      : 'No sessions for self-excluded members',
+    report: reportFile ? await writeJobReport(reportFile, report) : undefined,
+  });
+
+  process.exit(breached ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[self-exclusion:check] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await notifyJobFinished({
+    job: 'self-exclusion:check',
+    kind: 'detection',
+    status: 'failed',
+    target: targetName,
+    startedAt,
+    finishedAt: new Date(),
+    counts: {},
+    error: error instanceof Error ? error.message : String(error),
+  });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
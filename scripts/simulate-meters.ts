@@ -0,0 +1,160 @@
+/**
+ * Meter Ingestion Simulator
+ *
+ * Writes synthetic meter documents at a configurable rate against a test
+ * database to validate index design, the metersDaily rollup and
+ * pre-aggregation before the SMIB fleet grows:
+ * `bun run simulate-meters -- --env staging --machines 2000 --rate 2 --minutes 10`.
+ *
+ * Options:
+ *   --env <profile>       Database profile (required; profiles named *prod* are refused)
+ *   --machines N          Synthetic machines (default 100)
+ *   --locations N         Synthetic locations the machines are spread over (default 10)
+ *   --rate N              Readings per machine per minute (default 1)
+ *   --minutes N           Live mode: minutes of load at that rate (default 5)
+ *   --backfill-hours N    Write N hours of history unpaced instead of live load
+ *   --batch-size N        Documents per insert (default 500)
+ *   --run-id <id>         Id for this run's synthetic ids (default: timestamp)
+ *   --cleanup [run-id]    Delete simulated meters (one run, or all) and exit
+ *   --yes                 Skip the confirmation prompt
+ *   --json                Print the result as JSON
+ *
+ * Exit codes: 0 = done, 1 = the target rate could not be sustained, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import {
+  DEFAULT_SIMULATION_OPTIONS,
+  deleteSimulatedMeters,
+  runMeterSimulation,
+  SIMULATED_ID_PREFIX,
+} from '../app/api/lib/helpers/meterSimulator';
+import {
+  connectCommandDatabase,
+  getSelectedDbProfileName,
+} from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { readFlag } from './lib/cliArgs';
+
+function readNumberFlag(args: string[], name: string, fallback: number) {
+  const value = readFlag(args, name);
+  if (value === undefined) return fallback;
+  const parsed = Number(value);
+  if (!Number.isFinite(parsed) || parsed <= 0) {
+    throw new Error(`${name} must be a positive number`);
+  }
+  return parsed;
+}
+
+const audit = startCommandAudit('simulate-meters');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+
+  // Load tests only run against an explicitly chosen, non-production profile
+  const profileName = getSelectedDbProfileName();
+  if (!profileName) {
+    throw new Error('Pass --env <profile> for a test database');
+  }
+  if (/prod/i.test(profileName)) {
+    throw new Error(`Refusing to simulate load on '${profileName}'`);
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+
+  // Cleanup mode
+  if (args.includes('--cleanup')) {
+    const next = args[args.indexOf('--cleanup') + 1];
+    const runId = next && !next.startsWith('--') ? next : undefined;
+    await confirmDestructiveOperation(
+      target,
+      `Delete simulated meters (${SIMULATED_ID_PREFIX}${runId ?? '*'})`
+    );
+    const deleted = await deleteSimulatedMeters(runId);
+    audit.addRows(deleted);
+    console.log(`Deleted ${deleted} simulated meters`);
+    await audit.finish({ success: true, exitCode: 0 });
+    await mongoose.disconnect();
+    process.exit(0);
+  }
+
+  const backfillFlag = readFlag(args, '--backfill-hours');
+  const options = {
+    machines: Math.floor(
+      readNumberFlag(args, '--machines', DEFAULT_SIMULATION_OPTIONS.machines)
+    ),
+    locations: Math.floor(
+      readNumberFlag(args, '--locations', DEFAULT_SIMULATION_OPTIONS.locations)
+    ),
+    readingsPerMinute: readNumberFlag(
+      args,
+      '--rate',
+      DEFAULT_SIMULATION_OPTIONS.readingsPerMinute
+    ),
+    durationMinutes: readNumberFlag(
+      args,
+      '--minutes',
+      DEFAULT_SIMULATION_OPTIONS.durationMinutes
+    ),
+    backfillHours: backfillFlag
+      ? readNumberFlag(args, '--backfill-hours', 0)
+      : null,
+    batchSize: Math.floor(
+      readNumberFlag(args, '--batch-size', DEFAULT_SIMULATION_OPTIONS.batchSize)
+    ),
+    runId: readFlag(args, '--run-id') || String(Date.now()),
+  };
+
+  await confirmDestructiveOperation(
+    target,
+    `Insert synthetic meters for ${options.machines} machines (run ${options.runId})`
+  );
+
+  const result = await runMeterSimulation(options, progress => {
+    if (!asJson && process.stdout.isTTY) {
+      process.stdout.write(
+        `\r${progress.inserted} inserted, ${progress.docsPerSecond.toFixed(1)} docs/s`
+      );
+    }
+  });
+  audit.addRows(result.inserted);
+
+  // Live runs fail when more than a tenth of the batches fell behind
+  const sustained =
+    options.backfillHours !== null || result.lagBatches <= result.batches / 10;
+  await audit.finish({ success: true, exitCode: sustained ? 0 : 1 });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify({ runId: options.runId, ...result }, null, 2));
+  } else {
+    if (process.stdout.isTTY) console.log('');
+    console.log(
+      [
+        `Run ${options.runId}: ${result.inserted} meters in ${(
+          result.elapsedMs / 1000
+        ).toFixed(1)}s`,
+        `Rate: ${result.docsPerSecond} docs/s (target ${result.targetDocsPerSecond})`,
+        `Batch latency: p50 ${result.batchP50Ms}ms, p95 ${result.batchP95Ms}ms over ${result.batches} batches`,
+        `Behind target: ${result.lagBatches} batches`,
+        `Clean up with: bun run simulate-meters -- --env ${target.name} --cleanup ${options.runId}`,
+      ].join('\n')
+    );
+  }
+
+  process.exit(sustained ? 0 : 1);
+}
+
+main().catch(async error => {
+  console.error(
+    '[simulate-meters] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
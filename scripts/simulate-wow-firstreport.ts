@@ -10,17 +10,17 @@
  */
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { Collections } from '../app/api/lib/models/collections';
 import { Meters } from '../app/api/lib/models/meters';
 
-const MONGODB_URI = process.env.MONGODB_URI as string;
 const REPORT_ID = process.argv[2] === 'report' ? process.argv[3] : 'c746e506-4523-4d11-bb5d-736317995cfd';
 const N_DAYS = 1;
 const iso = (d?: Date | null) => (d ? new Date(d).toISOString() : 'null');
 const num = (v?: number | null) => (v == null ? 'null' : v.toLocaleString());
 
 async function main() {
-  await mongoose.connect(MONGODB_URI);
+  await connectCommandDatabase();
   const cols = await Collections.find({ locationReportId: REPORT_ID }, { machineId: 1, serialNumber: 1 }).lean<any[]>();
   console.log(`Simulating NEW first-report baseline (N=${N_DAYS}d) for ${cols.length} machines\n`);
 
@@ -8,17 +8,17 @@
  */
 import 'dotenv/config';
 import mongoose from 'mongoose';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
 import { Collections } from '../app/api/lib/models/collections';
 import { Machine } from '../app/api/lib/models/machines';
 import { Meters } from '../app/api/lib/models/meters';
 
-const MONGODB_URI = process.env.MONGODB_URI as string;
 const PRIOR = process.argv[2] || 'c746e506-4523-4d11-bb5d-736317995cfd';
 const iso = (d?: Date | null) => (d ? new Date(d).toISOString() : 'null');
 const num = (v?: number | null) => (v == null ? 'null' : v.toLocaleString());
 
 async function main() {
-  await mongoose.connect(MONGODB_URI);
+  await connectCommandDatabase();
   const cols = await Collections.find({ locationReportId: PRIOR }, { machineId: 1, serialNumber: 1, metersIn: 1, metersOut: 1 }).lean<any[]>();
   const machines = await Machine.find({ _id: { $in: cols.map(c => c.machineId) } }, { collectionMetersHistory: 1, collectionTime: 1 }).lean<any[]>();
   const histMap = new Map(machines.map(m => [String(m._id), m]));
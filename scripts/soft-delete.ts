@@ -0,0 +1,104 @@
+/**
+ * Soft Delete / Restore Command
+ *
+ * Soft-deletes or restores a machine or location by setting or clearing
+ * `deletedAt` (never the legacy `-1` sentinel), with cascade checks and an
+ * activity log entry:
+ * `bun run delete -- location <id> --reason "closed"` /
+ * `bun run undelete -- machine <id> --reason "deleted by mistake"`.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --reason <text>       Why the record is deleted or restored (required)
+ *   --restore             Restore instead of delete (what `undelete` passes)
+ *   --yes                 Skip the confirmation prompt
+ *
+ * Exit codes: 0 = done, 1 = refused (cascade check, already deleted, ...), 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  getOperator,
+  startCommandAudit,
+} from '../app/api/lib/helpers/commandAudit';
+import {
+  restoreResource,
+  softDeleteResource,
+} from '../app/api/lib/helpers/softDelete';
+import type { SoftDeleteResource } from '../app/api/lib/helpers/softDelete';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { confirmDestructiveOperation } from '../app/api/lib/utils/safetyMode';
+import { getErrorStatus } from '../app/api/lib/utils/statusErrors';
+import { readFlag } from './lib/cliArgs';
+
+/** Positional arguments, skipping flags and their values */
+function readPositionals(args: string[]): string[] {
+  const valueFlags = ['--env', '--max-time-ms', '--reason'];
+  return args.filter(
+    (arg, index) =>
+      !arg.startsWith('-') && !valueFlags.includes(args[index - 1] ?? '')
+  );
+}
+
+const restore = process.argv.includes('--restore');
+const commandName = restore ? 'undelete' : 'delete';
+const audit = startCommandAudit(commandName);
+
+async function main() {
+  const args = process.argv.slice(2);
+  const [resource, id] = readPositionals(args);
+  if ((resource !== 'machine' && resource !== 'location') || !id) {
+    throw new Error(
+      `Usage: ${commandName} <machine|location> <id> --reason <text>`
+    );
+  }
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  await confirmDestructiveOperation(
+    target,
+    `${restore ? 'Restore' : 'Delete'} ${resource} ${id}`
+  );
+
+  const operator = getOperator();
+  const request = {
+    resource: resource as SoftDeleteResource,
+    id,
+    reason: readFlag(args, '--reason') || '',
+    userId: `cli:${operator}`,
+    username: operator,
+  };
+  try {
+    const result = restore
+      ? await restoreResource(request)
+      : await softDeleteResource(request);
+    audit.addRows(1);
+    console.log(
+      `${restore ? 'Restored' : 'Deleted'} ${result.resource} ${result.name} (${result.id})`
+    );
+  } catch (error) {
+    const statusCode = getErrorStatus(error);
+    if (statusCode === 400 || statusCode === 404 || statusCode === 409) {
+      console.error(`[${commandName}] ${(error as Error).message}`);
+      await audit.finish({ success: false, exitCode: 1, error });
+      await mongoose.disconnect();
+      process.exit(1);
+    }
+    throw error;
+  }
+
+  await audit.finish({ success: true, exitCode: 0 });
+  await mongoose.disconnect();
+  process.exit(0);
+}
+
+main().catch(async error => {
+  console.error(
+    `[${commandName}] Error:`,
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,121 @@
+/**
+ * SAS Meter Verification
+ *
+ * Recomputes the SAS drop and cancelled credits of completed collections from
+ * the raw meters between each collection's sasStartTime and sasEndTime, and
+ * flags collections whose stored sasMeters differ by more than the tolerance.
+ * The same rule runs as part of the collection report issue check
+ * (`checkCollectionReportIssues`); run `regenerate-report` on the report once
+ * a flagged collection has been corrected.
+ *
+ * Usage:
+ *   bun run verify-sas-meters -- --env prod --since 2026-09-01
+ *   bun run verify-sas-meters -- --report <locationReportId> --json
+ *   bun run verify-sas-meters -- --env prod --sample 20 --licencee <id>
+ *
+ * Options:
+ *   --env <profile>        Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --report <id>          Only this collection report
+ *   --since YYYY-MM-DD     Only collections on or after this day
+ *   --range FROM..TO       Only collections from FROM up to and including TO (YYYY-MM-DD)
+ *   --location <id>        Only this location
+ *   --licencee <id>        Only locations of this licencee
+ *   --sample N             Check N collection reports picked at random within the other filters
+ *   --tolerance <amount>   Allowed difference (default 1)
+ *   --json                 Print the verification as JSON
+ *
+ * Without --report, --since, --range or --sample, the last 30 days are
+ * checked. The output starts with the scope that was used.
+ *
+ * Exit codes: 0 = all snapshots match, 1 = mismatches found, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import {
+  SAS_METER_TOLERANCE,
+  formatSasMeterVerification,
+  verifySasMeterSnapshots,
+} from '../app/api/lib/helpers/collectionReport/issueChecker';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+const audit = startCommandAudit('verify-sas-meters');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const reportId = readFlag(args, '--report');
+
+  const toleranceFlag = readFlag(args, '--tolerance');
+  const tolerance = toleranceFlag ? Number(toleranceFlag) : SAS_METER_TOLERANCE;
+  if (!Number.isFinite(tolerance) || tolerance < 0) {
+    throw new Error('--tolerance must be a non-negative amount');
+  }
+
+  const location = readFlag(args, '--location');
+  const licencee = readFlag(args, '--licencee');
+  const sampleFlag = readFlag(args, '--sample');
+  const sample = sampleFlag ? Number(sampleFlag) : undefined;
+  if (sample !== undefined && (!Number.isInteger(sample) || sample < 1)) {
+    throw new Error('--sample must be a positive integer');
+  }
+
+  const sinceFlag = readFlag(args, '--since');
+  const rangeFlag = readFlag(args, '--range');
+  if (sinceFlag && rangeFlag) {
+    throw new Error('Use either --since or --range, not both');
+  }
+  const [fromDay, toDay] = rangeFlag ? rangeFlag.split('..') : [sinceFlag];
+  [fromDay, toDay].forEach(day => {
+    if (day && !/^\d{4}-\d{2}-\d{2}$/.test(day)) {
+      throw new Error(`Dates must be YYYY-MM-DD, got '${day}'`);
+    }
+  });
+  if (rangeFlag && (!fromDay || !toDay)) {
+    throw new Error(`--range must be FROM..TO, got '${rangeFlag}'`);
+  }
+  // TO is inclusive: stop at the start of the following day
+  const until = toDay
+    ? new Date(new Date(`${toDay}T00:00:00.000Z`).getTime() + 86400000)
+    : undefined;
+  const since = fromDay
+    ? new Date(`${fromDay}T00:00:00.000Z`)
+    : reportId || sample
+      ? undefined
+      : new Date(Date.now() - 30 * 24 * 60 * 60 * 1000);
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const verification = await verifySasMeterSnapshots({
+    reportId,
+    since,
+    until,
+    location,
+    licencee,
+    sample,
+    tolerance,
+  });
+  const mismatched = verification.issues.length > 0;
+  audit.addRows(verification.checked);
+  await audit.finish({ success: true, exitCode: mismatched ? 1 : 0 });
+  await mongoose.disconnect();
+
+  console.log(
+    asJson
+      ? JSON.stringify(verification, null, 2)
+      : formatSasMeterVerification(verification)
+  );
+  process.exit(mismatched ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error(
+    '[verify-sas-meters] Error:',
+    error instanceof Error ? error.message : error
+  );
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -0,0 +1,115 @@
+/**
+ * Negative Gross Investigation
+ *
+ * Explains a location's (negative) gross for a range: totals by machine and
+ * by gaming day, then the meter readings and collections dragging it down:
+ * `bun run why -- --env prod --location <id> --period 7d`.
+ *
+ * Options:
+ *   --env <profile>       Database profile (see dbProfiles); defaults to MONGODB_URI
+ *   --location <id>       Location to investigate (required)
+ *   --period <period>     Time period (default 7d; Today, Yesterday, 30d, ...)
+ *   --start <date>        Custom range start (with --end)
+ *   --end <date>          Custom range end (with --start)
+ *   --limit N             Suspect meters/collections to print (default 10)
+ *   --json                Print the investigation as JSON
+ *
+ * Exit codes: 0 = gross is not negative, 1 = gross is negative, 2 = the run errored.
+ */
+
+import 'dotenv/config';
+import mongoose from 'mongoose';
+import { startCommandAudit } from '../app/api/lib/helpers/commandAudit';
+import { investigateLocationGross } from '../app/api/lib/helpers/grossInvestigation';
+import type { GrossBreakdownRow } from '../app/api/lib/helpers/grossInvestigation';
+import { connectCommandDatabase } from '../app/api/lib/utils/dbProfiles';
+import { readFlag } from './lib/cliArgs';
+
+function formatRow(row: GrossBreakdownRow): string {
+  const flag = row.gross < 0 ? '  <-- negative' : '';
+  return `  ${row.label.padEnd(24)} in ${row.moneyIn.toFixed(2).padStart(12)}  out ${row.moneyOut
+    .toFixed(2)
+    .padStart(12)}  gross ${row.gross.toFixed(2).padStart(12)}  (${row.meterCount} meters)${flag}`;
+}
+
+const audit = startCommandAudit('why');
+
+async function main() {
+  const args = process.argv.slice(2);
+  const asJson = args.includes('--json');
+  const locationId = readFlag(args, '--location');
+  if (!locationId) throw new Error('--location <id> is required');
+
+  const start = readFlag(args, '--start');
+  const end = readFlag(args, '--end');
+  if (Boolean(start) !== Boolean(end)) {
+    throw new Error('--start and --end must be given together');
+  }
+  const customStartDate = start ? new Date(start) : undefined;
+  const customEndDate = end ? new Date(end) : undefined;
+  if (
+    (customStartDate && Number.isNaN(customStartDate.getTime())) ||
+    (customEndDate && Number.isNaN(customEndDate.getTime()))
+  ) {
+    throw new Error('--start and --end must be valid dates');
+  }
+  const limit = Number(readFlag(args, '--limit') || 10);
+
+  const target = await connectCommandDatabase();
+  audit.setTarget(target.name);
+  const result = await investigateLocationGross({
+    locationId,
+    timePeriod: customStartDate ? 'Custom' : readFlag(args, '--period') || '7d',
+    customStartDate,
+    customEndDate,
+    limit: Number.isFinite(limit) && limit > 0 ? Math.floor(limit) : 10,
+  });
+  const negative = result.totals.gross < 0;
+  audit.addRows(result.totals.meterCount);
+  await audit.finish({ success: true, exitCode: negative ? 1 : 0 });
+  await mongoose.disconnect();
+
+  if (asJson) {
+    console.log(JSON.stringify(result, null, 2));
+    process.exit(negative ? 1 : 0);
+  }
+
+  const { totals, formula } = result;
+  console.log(
+    [
+      `${result.locationName} (${result.locationId})`,
+      `${result.rangeStart.toISOString()} .. ${result.rangeEnd.toISOString()}`,
+      `Formula: in = ${formula.moneyInFields.join(' + ')}, out = ${formula.moneyOutFields.join(' + ')}${
+        formula.includeJackpot ? ' + jackpot' : ''
+      }`,
+      `Gross ${totals.gross.toFixed(2)} (in ${totals.moneyIn.toFixed(2)}, out ${totals.moneyOut.toFixed(2)}, ${totals.meterCount} meters)`,
+      '',
+      'By machine (most negative first):',
+      ...result.byMachine.map(formatRow),
+      '',
+      'By gaming day:',
+      ...result.byDay.map(formatRow),
+      '',
+      `Suspect meters (${result.suspectMeters.length}):`,
+      ...result.suspectMeters.map(
+        meter =>
+          `  ${meter._id}  ${meter.machineLabel}  ${new Date(meter.readAt).toISOString()}  gross ${meter.gross.toFixed(2)}  [${meter.reasons.join(', ')}]\n    ${JSON.stringify(meter.movement)}`
+      ),
+      '',
+      `Suspect collections (${result.suspectCollections.length}):`,
+      ...result.suspectCollections.map(
+        collection =>
+          `  ${collection._id}  ${collection.machineLabel}  ${new Date(collection.timestamp).toISOString()}  gross ${collection.gross.toFixed(2)}  report ${collection.locationReportId ?? '-'}  [${collection.reasons.join(', ')}]\n    in ${collection.prevIn ?? '-'} -> ${collection.metersIn ?? '-'}, out ${collection.prevOut ?? '-'} -> ${collection.metersOut ?? '-'}`
+      ),
+    ].join('\n')
+  );
+
+  process.exit(negative ? 1 : 0);
+}
+
+main().catch(async error => {
+  console.error('[why] Error:', error instanceof Error ? error.message : error);
+  await audit.finish({ success: false, exitCode: 2, error });
+  await mongoose.disconnect().catch(() => undefined);
+  process.exit(2);
+});
@@ -1,3 +1,5 @@
+import type { CurrencyCode } from './currency';
+
 export type StackedData = {
   hour: string;
   [locationKey: string]:
@@ -9,3 +11,35 @@ export type StackedData = {
       }
     | string;
 };
+
+/**
+ * Bucket sizes the chart series service supports.
+ */
+export type ChartSeriesGranularity = 'hourly' | 'daily' | 'weekly' | 'monthly';
+
+export type ChartSeriesPoint = {
+  /** Bucket start, ISO 8601 UTC (weeks start on Monday) */
+  bucket: string;
+  totalDrop: number;
+  cancelledCredits: number;
+  totalJackpot: number;
+  gross: number;
+};
+
+/**
+ * Time series returned by `/api/analytics/charts/series`: one point per
+ * bucket from `start` to `end`, buckets without meters filled with zeros.
+ */
+export type ChartSeries = {
+  granularity: ChartSeriesGranularity;
+  /** Granularity asked for; null when chosen automatically */
+  requestedGranularity: ChartSeriesGranularity | null;
+  /** True when a coarser granularity was used to stay within `maxPoints` */
+  downsampled: boolean;
+  maxPoints: number;
+  start: string;
+  end: string;
+  points: ChartSeriesPoint[];
+  currency: CurrencyCode;
+  converted: boolean;
+};
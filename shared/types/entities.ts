@@ -131,10 +131,39 @@ export type UpdateLocationData = {
   locationMembershipSettings?: LocationMembershipSettings;
   googleMapsLink?: string;
   googleMapsIframe?: string;
+  shifts?: LocationShift[];
+  profitSplits?: LocationProfitSplit[];
   updatedAt?: Date;
   [key: string]: unknown;
 };
 
+export type LocationShift = {
+  name: string;
+  startHour: number;
+  endHour: number;
+};
+
+/** Negotiated split of a location's gross, from a gaming day onwards */
+export type LocationProfitSplit = {
+  /** First gaming day the split applies to, YYYY-MM-DD */
+  effectiveFrom: string;
+  /** Venue owner's percent of gross; the licencee keeps the rest */
+  venueShare: number;
+  /** Venue owner paid by accounts payable */
+  venueOwner?: string;
+  /** Supplier / vendor reference in the accounts system */
+  payeeReference?: string;
+  updatedBy?: string;
+  updatedAt?: Date;
+};
+
+export type ShiftWindow = {
+  shift: string;
+  shiftDay: string;
+  start: Date;
+  end: Date;
+};
+
 export type TopLocation = {
   locationId: string;
   locationName: string;
@@ -237,6 +266,8 @@ export type GamingMachine = {
   collectionTime?: string | Date;
   previousCollectionTime?: Date;
   collectorDenomination?: number;
+  /** Unit the machine reports meters in (unset = dollars) */
+  meterUnit?: 'dollars' | 'cents' | 'credits';
   collectionMetersHistory?: CollectionMetersHistoryEntry[];
 
   billValidator?: BillValidatorData;
@@ -630,6 +661,8 @@ export type CollectionIssueValues = {
   movementMetersIn?: number;
   movementMetersOut?: number;
   movementGross?: number;
+  sasDrop?: number;
+  sasCancelledCredits?: number;
   entryIndex?: number;
   machineId?: string;
   metersIn?: number;
@@ -649,7 +682,8 @@ export type CollectionIssue = {
     | 'wrong_sas_end_time'
     | 'missing_sas_times'
     | 'history_mismatch'
-    | 'machine_time_mismatch';
+    | 'machine_time_mismatch'
+    | 'sas_meters_mismatch';
   details: {
     current: CollectionIssueValues | null;
     expected: CollectionIssueValues | null;
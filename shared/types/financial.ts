@@ -0,0 +1,42 @@
+export type MeterMovementField =
+  | 'drop'
+  | 'totalCancelledCredits'
+  | 'totalHandPaidCancelledCredits'
+  | 'jackpot'
+  | 'coinIn'
+  | 'coinOut'
+  | 'totalWonCredits';
+
+export type FinancialMetricName = 'moneyIn' | 'moneyOut' | 'gross' | 'netGross';
+
+export type FinancialMetricDefinition = {
+  name: FinancialMetricName;
+  label: string;
+  description: string;
+};
+
+export type FinancialFormula = {
+  moneyInFields: MeterMovementField[];
+  moneyOutFields: MeterMovementField[];
+  includeJackpot: boolean;
+};
+
+export type FinancialFormulaOverride = {
+  moneyInFields?: MeterMovementField[];
+  moneyOutFields?: MeterMovementField[];
+};
+
+export type MovementTotals = Partial<Record<MeterMovementField, number>>;
+
+export type FinancialMetrics = {
+  moneyIn: number;
+  moneyOut: number;
+  jackpot: number;
+  gross: number;
+  netGross: number;
+};
+
+export type FinancialScales = {
+  moneyInScale: number;
+  moneyOutScale: number;
+};
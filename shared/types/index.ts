@@ -22,6 +22,8 @@ export type { CollectionMetersHistoryEntry };
 
 export * from './entities';
 
+export * from './financial';
+
 export type { UserAuthPayload, AuthResult } from './auth';
 
 export * from './api';
@@ -31,24 +33,56 @@ export * from './components';
 export type {
   AcceptedBillDocument,
   ActivityLogDocument,
+  ApiKeyDocument,
+  ApiKeyQuota,
+  CashDeskDayDocument,
+  CashDeskDayPayout,
   CashDeskPayoutDocument,
   CashierShiftDocument,
   CollectionReportDocument,
   CollectionDocument,
+  CommandAuditLogDocument,
   CountryDocument,
+  DashboardSnapshotDocument,
+  DashboardSnapshotGranularity,
   DenominationDocument,
   FeedbackDocument,
   FirmwareDocument,
   FloatRequestDocument,
   FloatRequestsDocument,
   GamingLocationDocument,
+  HeartbeatDocument,
+  HeartbeatSource,
   LocationDocument,
+  IntegrityIssueComment,
+  IntegrityIssueDocument,
+  IntegrityIssueStatus,
+  IntegrityIssueStatusChange,
+  IntegrityRunCheck,
+  IntegrityRunDocument,
   InterLocationTransferDocument,
   LicenceeDocument,
   MachineEventDocument,
+  MachineConfigField,
+  MachineDecommissionMeters,
+  MachineDecommissionRecord,
+  MachineLifecycleStatus,
+  MachineReconfigurationEntry,
+  MachineStatusDefinitions,
+  MachineStatusHistoryEntry,
+  MachineStatusOverride,
   MachineSessionDocument,
+  MachineViewDocument,
+  MachineViewFilters,
   MemberDocument,
   MeterDocument,
+  MetersDailyDocument,
+  ReportTemplateDocument,
+  ReportTemplateType,
+  RollupCheckpointDocument,
+  RollupVerificationMismatch,
+  RollupVerificationResult,
+  SelfExclusionDocument,
   MovementRequestDocument,
   PayoutDocument,
   SchedulerDocument,
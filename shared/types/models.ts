@@ -1,7 +1,10 @@
+import type { FinancialFormulaOverride, MovementTotals } from './financial';
 import type { Denomination } from './vault';
 import type {
   BillMovement,
   LocationMembershipSettings,
+  LocationProfitSplit,
+  LocationShift,
   MeterMovement,
 } from './entities';
 
@@ -106,6 +109,36 @@ export type CashDeskPayoutDocument = {
   updatedAt: Date;
 };
 
+/** A manual payout recorded on a cash desk day (see CashDeskDayDocument) */
+export type CashDeskDayPayout = {
+  _id: string;
+  amount: number;
+  /** Hand pays and ticket redemptions count as cancelled credits */
+  type: 'cancelled-credit' | 'jackpot';
+  machineId: string | null;
+  machineSerialNumber: string | null;
+  note?: string;
+  recordedBy: string | null;
+  recordedAt: Date;
+};
+
+/** One location's cash desk float and manual payouts for a gaming day */
+export type CashDeskDayDocument = {
+  _id: string;
+  location: string;
+  /** Gaming day, YYYY-MM-DD */
+  gamingDay: string;
+  openingFloat: number | null;
+  /** Float top-ups during the day */
+  floatAdded: number;
+  closingFloat: number | null;
+  payouts: CashDeskDayPayout[];
+  notes?: string;
+  recordedBy: string | null;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
 export type CashierShiftDocument = {
   _id: string;
   locationId: string;
@@ -415,6 +448,8 @@ export type GamingLocationDocument = {
   semiSMIBs?: boolean;
   googleMapsLink?: string;
   googleMapsIframe?: string;
+  shifts?: LocationShift[];
+  profitSplits?: LocationProfitSplit[];
   createdAt?: Date;
   updatedAt?: Date;
   deletedAt?: Date | null;
@@ -453,6 +488,13 @@ export type LicenceeDocument = {
   prevExpiryDate?: Date;
   isPaid?: boolean;
   licenceKey: string;
+  /** Regulator jurisdiction, when it differs from the country name */
+  jurisdiction?: string;
+  contact?: {
+    name?: string;
+    email?: string;
+    phone?: string;
+  };
   status?: string;
   deletedAt?: Date;
   createdAt: Date;
@@ -464,6 +506,96 @@ export type LicenceeDocument = {
   };
   includeJackpot?: boolean;
   gameDayOffset?: number;
+  financialFormula?: FinancialFormulaOverride;
+  /** Overrides the deployment's machine status definitions */
+  machineStatus?: MachineStatusOverride;
+};
+
+export type MachineStatusOverride = {
+  /** Minutes since lastActivity a machine still counts as online */
+  onlineThresholdMinutes?: number;
+};
+
+/** Effective online/offline and status definitions, as served to reports */
+export type MachineStatusDefinitions = {
+  onlineThresholdMinutes: number;
+  /** Where the threshold comes from */
+  source: 'default' | 'env' | 'licencee';
+  /** Lifecycle statuses that take a machine out of service */
+  inactiveAssetStatuses: MachineLifecycleStatus[];
+  /** Stored assetStatus values read as active besides 'active' itself */
+  legacyActiveAssetStatuses: string[];
+};
+
+export type MachineLifecycleStatus =
+  | 'active'
+  | 'in-repair'
+  | 'storage'
+  | 'retired';
+
+export type MachineStatusHistoryEntry = {
+  from: MachineLifecycleStatus;
+  to: MachineLifecycleStatus;
+  reason: string;
+  userId: string;
+  username: string;
+  changedAt: Date;
+};
+
+export type MachineDecommissionMeters = {
+  coinIn: number;
+  coinOut: number;
+  drop: number;
+  cancelledCredits: number;
+  handPaid: number;
+  jackpot: number;
+  gamesPlayed: number;
+};
+
+/** Final state recorded when a machine is retired */
+export type MachineDecommissionRecord = {
+  certificateNumber: string;
+  retiredAt: Date;
+  reason: string;
+  userId: string;
+  username: string;
+  /** Last SAS meters reported by the machine */
+  finalMeters: MachineDecommissionMeters;
+  /** When those meters were last reported */
+  finalMetersAt: Date | null;
+  finalCollection: {
+    collectionId: string;
+    locationReportId: string;
+    collectedAt: Date;
+    metersIn: number;
+    metersOut: number;
+  };
+  /** Location and licencee at retirement */
+  locationId: string | null;
+  licenceeId: string | null;
+};
+
+export type MachineConfigField =
+  | 'game'
+  | 'gameType'
+  | 'accountingDenomination'
+  | 'payTableId'
+  | 'theoreticalRtp'
+  | 'maxBet';
+
+export type MachineReconfigurationEntry = {
+  _id: string;
+  /** When the machine started running the new configuration */
+  changedAt: Date;
+  changes: Array<{
+    field: MachineConfigField;
+    oldValue: string | number | null;
+    newValue: string | number;
+  }>;
+  reason: string;
+  userId: string;
+  username: string;
+  recordedAt: Date;
 };
 
 export type MachineDocument = {
@@ -478,6 +610,9 @@ export type MachineDocument = {
   gamingLocation?: string;
   cabinetType?: string;
   assetStatus?: string;
+  statusHistory?: MachineStatusHistoryEntry[];
+  decommission?: MachineDecommissionRecord;
+  configurationHistory?: MachineReconfigurationEntry[];
   lastActivity?: Date;
   [key: string]: unknown;
   sasMeters?: {
@@ -963,6 +1098,8 @@ export type MemberDocument = {
   lastPwUpdatedAt?: Date;
   lastfplAwardAt?: Date;
   memberId?: string;
+  /** Surviving member this duplicate was merged into (archived) */
+  mergedInto?: string | null;
   numFailedLoginAttempts?: number;
   relayId?: string;
   status?: string;
@@ -980,6 +1117,260 @@ export type MemberDocument = {
   updatedAt: Date;
 };
 
+export type MetersDailyDocument = {
+  _id: string;
+  machine: string;
+  location: string;
+  gamingDay: string;
+  rangeStart: Date;
+  rangeEnd: Date;
+  movement: MovementTotals & {
+    gamesPlayed: number;
+    gamesWon: number;
+  };
+  meterCount: number;
+  rolledUpAt: Date;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type RollupVerificationMismatch = {
+  location: string;
+  field: string;
+  raw: number;
+  rollup: number;
+};
+
+export type RollupVerificationResult = {
+  month: string;
+  locationsChecked: number;
+  mismatches: RollupVerificationMismatch[];
+  verifiedAt: Date;
+};
+
+export type RollupCheckpointDocument = {
+  _id: string;
+  job: string;
+  from: string;
+  to: string;
+  lastCompletedDay: string | null;
+  status: 'running' | 'completed' | 'failed';
+  verification: RollupVerificationResult[];
+  lastError?: string;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type DashboardSnapshotGranularity = 'hour' | 'day';
+
+export type DashboardSnapshotDocument = {
+  _id: string;
+  licencee: string;
+  granularity: DashboardSnapshotGranularity;
+  /** Start of the hour/day (UTC) the snapshot stands for */
+  bucket: Date;
+  takenAt: Date;
+  totalDrop: number;
+  totalCancelledCredits: number;
+  totalGross: number;
+  totalMachines: number;
+  onlineMachines: number;
+  sasMachines: number;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type HeartbeatSource = 'http' | 'udp';
+
+export type HeartbeatDocument = {
+  _id: string;
+  /** Machine the ping was matched to; null for unknown serials */
+  machine: string | null;
+  serialNumber: string;
+  relayId: string | null;
+  firmware: string | null;
+  /** Time reported by the SMIB (receivedAt when missing or implausible) */
+  reportedAt: Date;
+  receivedAt: Date;
+  source: HeartbeatSource;
+  ip: string | null;
+};
+
+export type ApiKeyQuota = {
+  requestsPerMinute?: number;
+  burst?: number;
+  concurrentAggregations?: number;
+};
+
+export type ApiKeyDocument = {
+  _id: string;
+  name: string;
+  /** First characters of the key, shown to tell keys apart */
+  prefix: string;
+  /** SHA-256 of the key, hex; the key itself is never stored */
+  keyHash: string;
+  /** Licencees the key can see */
+  licencees: string[];
+  /** Endpoint groups, `group` or `group:read` */
+  scopes: string[];
+  /** Overrides of the default API quota */
+  quota?: ApiKeyQuota;
+  description?: string;
+  createdBy: string | null;
+  expiresAt: Date | null;
+  lastUsedAt: Date | null;
+  revokedAt: Date | null;
+  revokedBy: string | null;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type CommandAuditLogDocument = {
+  _id: string;
+  timestamp: Date;
+  username: string;
+  host: string;
+  pid?: number;
+  command: string;
+  parameters: string[];
+  target: string | null;
+  durationMs: number;
+  rowCount: number;
+  success: boolean;
+  exitCode: number | null;
+  error: string | null;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type IntegrityIssueStatus =
+  | 'open'
+  | 'investigating'
+  | 'fixed'
+  | 'verified'
+  | 'confirmed'
+  | 'dismissed';
+
+export type IntegrityIssueStatusChange = {
+  from: IntegrityIssueStatus;
+  to: IntegrityIssueStatus;
+  by: string;
+  at: Date;
+  note: string | null;
+};
+
+export type IntegrityIssueComment = {
+  _id: string;
+  author: string;
+  body: string;
+  createdAt: Date;
+};
+
+export type IntegrityIssueDocument = {
+  _id: string;
+  check: string;
+  resourceType: string;
+  resourceId: string;
+  machine?: string;
+  location?: string;
+  field: string | null;
+  value: number | null;
+  mean: number | null;
+  stdDev: number | null;
+  zScore: number | null;
+  sampleSize: number | null;
+  readAt?: Date;
+  details?: string;
+  status: IntegrityIssueStatus;
+  detectedAt: Date;
+  reviewedBy: string | null;
+  reviewedAt: Date | null;
+  /** Username of the person working the issue */
+  assignedTo: string | null;
+  assignedAt: Date | null;
+  statusHistory: IntegrityIssueStatusChange[];
+  comments: IntegrityIssueComment[];
+  /** When the issue went out in a licencee's integrity digest email */
+  digestedAt: Date | null;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type IntegrityRunCheck = {
+  name: string;
+  count: number;
+  threshold: number;
+  passed: boolean;
+  /** Identifiers of the findings, capped at MAX_TRACKED_ISSUE_KEYS */
+  keys: string[];
+  /** True when `count` exceeded the number of stored keys */
+  truncated: boolean;
+};
+
+export type IntegrityRunDocument = {
+  _id: string;
+  checkedAt: Date;
+  passed: boolean;
+  checks: IntegrityRunCheck[];
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type ReportTemplateType =
+  | 'query-builder'
+  | 'licencee-leaderboard'
+  | 'machine-utilization'
+  | 'shifts';
+
+export type MachineViewFilters = {
+  licencee: string | null;
+  locations: string[];
+  /** `online`, `offline` or stored asset statuses */
+  statuses: string[];
+};
+
+export type MachineViewDocument = {
+  _id: string;
+  /** Owner; views are private to their user */
+  userId: string;
+  name: string;
+  description?: string;
+  filters: MachineViewFilters;
+  /** Machine list fields shown, in order */
+  columns: string[];
+  /** `field` or `-field` */
+  sort: string;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type ReportTemplateDocument = {
+  _id: string;
+  name: string;
+  description?: string;
+  reportType: ReportTemplateType;
+  parameters: Record<string, unknown>;
+  outputFormat: 'json' | 'csv';
+  createdBy?: string | null;
+  lastRunAt?: Date | null;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
+export type SelfExclusionDocument = {
+  _id: string;
+  member: string;
+  /** Member's location when the exclusion was recorded */
+  gamingLocation: string | null;
+  startDate: Date;
+  /** null: indefinite */
+  endDate: Date | null;
+  reason: string;
+  recordedBy: string | null;
+  createdAt: Date;
+  updatedAt: Date;
+};
+
 export type MeterDocument = {
   _id: string;
   machine: string;
@@ -995,6 +1386,8 @@ export type MeterDocument = {
     totalCancelledCredits?: number;
     handPaidCancelledCredits?: number;
     gross?: number;
+    ticketIn?: number;
+    ticketOut?: number;
   };
   coinIn?: number;
   coinOut?: number;
@@ -1006,6 +1399,8 @@ export type MeterDocument = {
   gamesWon?: number;
   currentCredits?: number;
   totalWonCredits?: number;
+  ticketIn?: number;
+  ticketOut?: number;
   meterSource?: 'COLLECTION_REPORT' | 'SAS_READ' | 'WOW_SYNC' | 'OTHER';
   isSupplemental?: boolean;
   isRamClear?: boolean;
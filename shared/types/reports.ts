@@ -583,6 +583,8 @@ export type HistoryEntry = {
 
 export type FixResults = {
   reportId?: string;
+  /** `documentBackups` run holding the documents as they were before the fix */
+  backupRunId?: string;
   collectionsProcessed: number;
   issuesFixed: {
     sasTimesFixed: number;